@@ -100,7 +100,7 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 		defer shutdownTelemetry(ctx)
 	}
 
-	client := analyzer.NewBAMLLiveClient(cfg.Provider)
+	client := analyzer.NewProviderClient(cfg.Provider)
 
 	judgeEnabled, err := cmd.Flags().GetBool("judge")
 	if err != nil {
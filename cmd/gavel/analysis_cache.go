@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+// analysisCache returns the disk cache selected by cache.backend, or nil to
+// keep the analyzer's per-run in-memory cache. Entries are namespaced by the
+// provider, model, and rule set, which the analyzer's own cache key does not
+// cover, so changing any of them never serves stale results. A cache
+// directory that cannot be written only costs the cache: analyze warns and
+// continues in memory.
+func analysisCache(cfg *config.Config, loadedRules []rules.Rule, policyDir string) cache.AnalysisCache {
+	if cfg.Cache.Backend != "disk" {
+		return nil
+	}
+	dir := cacheDir(cfg, policyDir)
+	if err := store.CheckWritable(dir); err != nil {
+		slog.Warn("analysis cache directory is not writable; caching in memory for this run", "dir", dir, "err", err)
+		return nil
+	}
+
+	prov, err := sarif.NewProvenance("", cfg, loadedRules, "")
+	if err != nil {
+		slog.Warn("cannot fingerprint cache settings; caching in memory for this run", "err", err)
+		return nil
+	}
+	opts := []cache.DiskOption{cache.WithDiskNamespace(prov.ProviderHash + prov.RulesHash)}
+	if d := cfg.Cache.TTLDuration(); d > 0 {
+		opts = append(opts, cache.WithDiskTTL(d))
+	}
+	if cfg.Cache.MaxEntries > 0 {
+		opts = append(opts, cache.WithDiskMaxSize(cfg.Cache.MaxEntries))
+	}
+	c, err := cache.NewDiskCache(dir, opts...)
+	if err != nil {
+		slog.Warn("opening analysis cache failed; caching in memory for this run", "dir", dir, "err", err)
+		return nil
+	}
+	return c
+}
+
+// cacheDir is the directory of the disk cache: cache.dir, or cache/ under
+// the policy directory.
+func cacheDir(cfg *config.Config, policyDir string) string {
+	if cfg.Cache.Dir != "" {
+		return cfg.Cache.Dir
+	}
+	return filepath.Join(policyDir, "cache")
+}
+
+// responseCache returns the cache of raw LLM responses selected by
+// cache.responses, or nil when it is disabled. With cache.backend disk the
+// responses persist under responses/ in the cache directory, so every
+// command run against the project shares them; otherwise they are kept in
+// memory for the life of the process. A directory that cannot be written
+// falls back to memory with a warning.
+func responseCache(cfg *config.Config, policyDir string) cache.AnalysisCache {
+	rc := cfg.Cache.Responses
+	if !rc.Enabled {
+		return nil
+	}
+	ttl := rc.TTLDuration()
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+	maxEntries := rc.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = 10000
+	}
+
+	if cfg.Cache.Backend == "disk" {
+		dir := filepath.Join(cacheDir(cfg, policyDir), "responses")
+		c, err := cache.NewDiskCache(dir, cache.WithDiskTTL(ttl), cache.WithDiskMaxSize(maxEntries))
+		if err == nil {
+			err = store.CheckWritable(dir)
+		}
+		if err == nil {
+			return c
+		}
+		slog.Warn("response cache directory is not writable; caching responses in memory", "dir", dir, "err", err)
+	}
+	return cache.New(cache.WithMaxSize(maxEntries), cache.WithTTL(ttl))
+}
+
+// cachingClient wraps client, which calls the model p selects, with the
+// response cache c. A nil client or cache returns client unchanged.
+func cachingClient(client analyzer.BAMLClient, c cache.AnalysisCache, p config.ProviderConfig) analyzer.BAMLClient {
+	if client == nil || c == nil {
+		return client
+	}
+	return analyzer.NewCachingClient(client, c, p)
+}
+
+// cachingClients returns a client factory that wraps every client from
+// newClient with the response cache c.
+func cachingClients(c cache.AnalysisCache, newClient func(config.ProviderConfig) analyzer.BAMLClient) func(config.ProviderConfig) analyzer.BAMLClient {
+	if c == nil {
+		return newClient
+	}
+	return func(p config.ProviderConfig) analyzer.BAMLClient {
+		return cachingClient(newClient(p), c, p)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func diskCacheConfig(dir string) *config.Config {
+	return &config.Config{
+		Provider: config.ProviderConfig{Name: "ollama", Ollama: config.OllamaConfig{Model: "m"}},
+		Cache:    config.AnalysisCacheConfig{Backend: "disk", Dir: dir},
+	}
+}
+
+func TestAnalysisCache_MemoryByDefault(t *testing.T) {
+	assert.Nil(t, analysisCache(&config.Config{}, nil, t.TempDir()))
+}
+
+func TestAnalysisCache_DiskDefaultsUnderPolicyDir(t *testing.T) {
+	policyDir := t.TempDir()
+	c := analysisCache(diskCacheConfig(""), nil, policyDir)
+	require.NotNil(t, c)
+
+	c.Set("k", []sarif.Result{{RuleID: "R"}})
+	files, err := filepath.Glob(filepath.Join(policyDir, "cache", "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestAnalysisCache_NamespacedBySettings(t *testing.T) {
+	dir := t.TempDir()
+	cfg := diskCacheConfig(dir)
+	ruleSet := []rules.Rule{{ID: "R1", RawPattern: "x"}}
+
+	first := analysisCache(cfg, ruleSet, "")
+	require.NotNil(t, first)
+	first.Set("k", []sarif.Result{{RuleID: "R"}})
+
+	_, ok := analysisCache(cfg, ruleSet, "").Get("k")
+	assert.True(t, ok, "same settings should share entries")
+
+	changedModel := diskCacheConfig(dir)
+	changedModel.Provider.Ollama.Model = "other"
+	_, ok = analysisCache(changedModel, ruleSet, "").Get("k")
+	assert.False(t, ok, "a different model must not reuse entries")
+
+	_, ok = analysisCache(cfg, []rules.Rule{{ID: "R2", RawPattern: "y"}}, "").Get("k")
+	assert.False(t, ok, "a different rule set must not reuse entries")
+}
+
+func TestAnalysisCache_UnwritableFallsBackToMemory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	c := analysisCache(diskCacheConfig(filepath.Join(file, "cache")), nil, "")
+	assert.Nil(t, c)
+}
@@ -10,19 +10,28 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/annotate"
+	"github.com/chris-regnier/gavel/internal/blame"
+	"github.com/chris-regnier/gavel/internal/blastradius"
 	"github.com/chris-regnier/gavel/internal/cache"
 	"github.com/chris-regnier/gavel/internal/calibration"
+	"github.com/chris-regnier/gavel/internal/codeowners"
 	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/deps"
 	"github.com/chris-regnier/gavel/internal/diffcontext"
+	"github.com/chris-regnier/gavel/internal/evaluator"
+	"github.com/chris-regnier/gavel/internal/incremental"
 	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/merge"
 	"github.com/chris-regnier/gavel/internal/rules"
 	"github.com/chris-regnier/gavel/internal/sarif"
-	"github.com/chris-regnier/gavel/internal/store"
 	"github.com/chris-regnier/gavel/internal/suppression"
 	"github.com/chris-regnier/gavel/internal/telemetry"
 
@@ -35,14 +44,45 @@ import (
 var analyzeTracer = otel.Tracer("github.com/chris-regnier/gavel/cmd/gavel")
 
 var (
-	flagFiles       []string
-	flagDiff        string
-	flagDir         string
-	flagOutput      string
-	flagPolicyDir   string
-	flagRulesDir    string
-	flagCacheServer string
-	flagBaseline    string
+	flagFiles          []string
+	flagDiff           string
+	flagGitRange       string
+	flagStaged         bool
+	flagDir            string
+	flagSBOM           string
+	flagOutput         string
+	flagPolicyDir      string
+	flagRulesDir       string
+	flagCacheServer    string
+	flagBaseline       string
+	flagIgnoreResolved bool
+	flagTimeout        time.Duration
+	flagRange          string
+	flagBlame          bool
+	flagOwners         bool
+	flagBlastRadius    bool
+	flagAnnotate       string
+	flagNoStore        bool
+	flagRequireLLM     bool
+	flagStream         bool
+	flagDumpApplied    string
+	flagProfileRules   bool
+	flagProfileTop     int
+	flagProfileBudget  time.Duration
+	flagFailOn         string
+	flagMaxFindings    int
+	flagMaxCost        float64
+	flagIncremental    bool
+	flagShard          string
+	flagDryRun         bool
+	flagDedup          string
+	flagMinConfidence  float64
+	flagIncludeGen     bool
+	flagArchive        string
+	flagStdin          bool
+	flagStdinPath      string
+	flagMaxFileSize    int64
+	flagTiers          []string
 )
 
 func init() {
@@ -55,18 +95,64 @@ func init() {
 	analyzeCmd.Flags().StringSliceVar(&flagFiles, "files", nil, "Files to analyze")
 	analyzeCmd.Flags().StringVar(&flagDiff, "diff", "", "Path to diff file (or - for stdin)")
 	analyzeCmd.Flags().StringVar(&flagDir, "dir", "", "Directory to analyze")
+	analyzeCmd.Flags().StringVar(&flagArchive, "archive", "", "Analyze the files in a tar, tar.gz or zip archive without extracting it")
+	analyzeCmd.Flags().BoolVar(&flagStdin, "stdin", false, "Analyze a single file read from stdin, named by --path")
+	analyzeCmd.Flags().StringVar(&flagStdinPath, "path", "", "With --stdin, the path the content is analyzed as (e.g. src/api.go); it need not exist")
+	analyzeCmd.Flags().BoolVar(&flagIncludeGen, "include-generated", false, "With --dir or --archive, also analyze files that look generated (a \"Code generated ... DO NOT EDIT\" or @generated header, or names like *.min.js)")
+	analyzeCmd.Flags().Int64Var(&flagMaxFileSize, "max-file-size", input.DefaultMaxFileSize, "With --dir or --archive, skip files larger than this many bytes (0 disables)")
+	analyzeCmd.Flags().BoolVar(&flagIncremental, "incremental", false, "With --dir, re-analyze only files whose content changed since the last incremental run and reuse the stored findings for the rest (state is kept under <policies>/state)")
+	analyzeCmd.Flags().StringVar(&flagShard, "shard", "", "Analyze only shard INDEX/COUNT (e.g. 2/4) of the input files, partitioned by path so every job agrees; combine the shards with gavel merge")
+	analyzeCmd.Flags().StringVar(&flagSBOM, "sbom", "", "CycloneDX JSON SBOM to check against dependency rules, alone or alongside another input (only dependency rules run on it)")
+	analyzeCmd.Flags().StringVar(&flagGitRange, "git-range", "", "Analyze the files changed in a git revision range (e.g. origin/main...HEAD), reporting only findings on changed lines")
+	analyzeCmd.Flags().BoolVar(&flagStaged, "staged", false, "Analyze the files staged in the git index, reporting only findings on changed lines")
 	analyzeCmd.Flags().StringVar(&flagOutput, "output", ".gavel/results", "Output directory for results")
+	analyzeCmd.Flags().BoolVar(&flagNoStore, "no-store", false, "Do not write results to --output; print the SARIF log in the summary instead (for read-only filesystems)")
 	analyzeCmd.Flags().StringVar(&flagPolicyDir, "policies", ".gavel", "Directory containing policies.yaml")
 	analyzeCmd.Flags().StringVar(&flagRulesDir, "rules-dir", "", "Directory containing custom rule YAML files")
 	analyzeCmd.Flags().StringVar(&flagCacheServer, "cache-server", "", "Remote cache server URL to upload results (e.g., https://gavel.company.com)")
-	analyzeCmd.Flags().StringVar(&flagBaseline, "baseline", "", "Baseline SARIF to compare against (result ID from the store or a path to a sarif.json file). Each result gets a baselineState (new|unchanged|absent).")
+	analyzeCmd.Flags().StringVar(&flagBaseline, "baseline", "", "Baseline to compare against: a file written by gavel baseline create, a result ID from the store, or a path to a sarif.json file. Each result gets a baselineState (new|unchanged|absent).")
+	analyzeCmd.Flags().BoolVar(&flagIgnoreResolved, "baseline-ignore-resolved", true, "Omit findings resolved since the baseline from the summary. Pass --baseline-ignore-resolved=false to list them in an informational \"resolved\" section (gating is unaffected either way).")
+
+	analyzeCmd.Flags().StringVar(&flagAnnotate, "annotate", "", "Write copies of files with findings to this directory, with each finding inserted as a comment above its line (originals are never modified)")
+	analyzeCmd.Flags().BoolVar(&flagBlastRadius, "blast-radius", false, "Record how many analyzed Go files import each finding's package (gavel/blast-radius) so --sort-by priority ranks widely used code first")
+	analyzeCmd.Flags().BoolVar(&flagBlame, "blame", false, "Enrich each finding with the git blame author, commit and age of the newest committed line in its region (gavel/blame-author, gavel/introduced-commit, gavel/introduced-at, gavel/blame-age-days). Files outside a git repository are left unenriched.")
+	analyzeCmd.Flags().BoolVar(&flagOwners, "owners", false, "Aggregate actionable findings per blamed author, and per CODEOWNERS owner when the repository has a CODEOWNERS file, in the summary (implies --blame)")
+	analyzeCmd.Flags().StringVar(&flagDumpApplied, "dump-applied-rules", "", "Write a JSON record of which instant-tier rules ran on each file, after language and path filtering, with their match counts")
+	analyzeCmd.Flags().BoolVar(&flagProfileRules, "profile-rules", false, "Run only the instant tier and print the slowest rules by cumulative match time instead of storing results")
+	analyzeCmd.Flags().IntVar(&flagProfileTop, "profile-top", 10, "Number of rules to list with --profile-rules (0 lists all)")
+	analyzeCmd.Flags().DurationVar(&flagProfileBudget, "profile-budget", 100*time.Millisecond, "Cumulative match time above which --profile-rules flags a rule as a potential performance problem (0 disables)")
+	analyzeCmd.Flags().BoolVar(&flagStream, "stream", false, "Write preliminary findings to stdout as NDJSON while analysis runs, then the final findings and a summary line, instead of printing only the summary at the end")
+	analyzeCmd.Flags().StringSliceVar(&flagTiers, "tiers", []string{"instant", "fast", "comprehensive"}, "Analysis tiers to run: instant (rules and AST checks), fast (the fast_provider, when configured) and comprehensive (the provider)")
+	analyzeCmd.Flags().BoolVar(&flagRequireLLM, "require-llm", false, "Fail if the LLM provider cannot be initialized instead of falling back to instant-tier rules only")
+	analyzeCmd.Flags().StringVar(&flagRange, "range", "", "Analyze only lines START:END (1-indexed, inclusive) of the single file given via --files")
+	analyzeCmd.Flags().StringVar(&flagFailOn, "fail-on", "", "Exit with status 2 when there are actionable findings at this level or above: error, warning, or note")
+	analyzeCmd.Flags().IntVar(&flagMaxFindings, "max-findings", -1, "Exit with status 2 when more than N actionable findings (at --fail-on level or above, if set) remain")
+	analyzeCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Stop comprehensive-tier analysis before the estimated LLM spend exceeds this many US dollars, reporting partial results (0 disables). Prices come from the pricing section of policies.yaml.")
+	analyzeCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Print, per file, the persona prompt, policy text, additional context, rule counts per tier and estimated tokens the run would use, without calling the LLM provider or storing results")
+	analyzeCmd.Flags().StringVar(&flagDedup, "dedup", "", "How duplicate findings are collapsed: strict (same rule, overlapping lines), fuzzy (also similar findings of different rules on nearby lines) or off; overrides the dedup config")
+	analyzeCmd.Flags().Float64Var(&flagMinConfidence, "min-confidence", 0, "Record findings below this confidence (0-1) as informational, so they stay in the SARIF log without affecting the verdict; overrides confidence.min")
+	analyzeCmd.Flags().DurationVar(&flagTimeout, "timeout", 0, "Overall time budget for the analysis run (0 disables). Individual provider calls are bounded separately by provider.request_timeout.")
 
 	rootCmd.AddCommand(analyzeCmd)
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
+	threshold, err := evaluator.NewFindingThreshold(flagFailOn, flagMaxFindings)
+	if err != nil {
+		return fmt.Errorf("--fail-on: %w", err)
+	}
+	tiers, err := analyzer.ParseTiers(flagTiers)
+	if err != nil {
+		return fmt.Errorf("--tiers: %w", err)
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
+	if flagTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, flagTimeout)
+		defer cancel()
+	}
 
 	// Load configuration
 	machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
@@ -89,15 +175,31 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
+	if err := registerPersonas(flagPolicyDir); err != nil {
+		return err
+	}
+
 	// Override persona from CLI flag if provided
 	if personaFlag, _ := cmd.Flags().GetString("persona"); personaFlag != "" {
 		cfg.Persona = personaFlag
 	}
 
-	// Validate configuration (including persona)
-	if err := cfg.Validate(); err != nil {
+	if flagDedup != "" {
+		cfg.Dedup = flagDedup
+	}
+	if cmd.Flags().Changed("min-confidence") {
+		cfg.Confidence.Min = flagMinConfidence
+	}
+
+	// Validate configuration (including persona). The provider is checked
+	// with the rest of LLM initialization below so that a missing or broken
+	// provider only disables the LLM tiers.
+	if err := cfg.ValidateSettings(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
+	if tiers[analyzer.TierFast] && cfg.FastProvider.Name == "" && cmd.Flags().Changed("tiers") {
+		return fmt.Errorf("--tiers: the fast tier needs a fast_provider in policies.yaml")
+	}
 
 	// Load rules (default + user + project overrides)
 	userRulesDir := os.ExpandEnv("$HOME/.config/gavel/rules")
@@ -110,10 +212,36 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading rules: %w", err)
 	}
 
-	// Get persona prompt from BAML
-	personaPrompt, err := analyzer.GetPersonaPrompt(ctx, cfg.Persona)
+	// Initialize the LLM tiers, if selected, degrading to instant-only
+	// analysis on failure unless --require-llm is set
+	var client analyzer.BAMLClient
+	var personaPrompt, llmSkipped string
+	if tiers[analyzer.TierFast] || tiers[analyzer.TierComprehensive] {
+		client, personaPrompt, llmSkipped, err = prepareLLM(ctx, cfg, flagRequireLLM, initLLM)
+		if err != nil {
+			return err
+		}
+	}
+	ranTiers := runTiers(tiers, cfg, client != nil)
+
+	// Price the comprehensive-tier calls and enforce --max-cost
+	costs, err := newCostTracker(cfg, flagMaxCost)
 	if err != nil {
-		return fmt.Errorf("loading persona %s: %w", cfg.Persona, err)
+		return err
+	}
+	// Answer repeated prompts from cached responses before pricing them, so
+	// cache hits cost nothing
+	responses := responseCache(cfg, flagPolicyDir)
+	client = cachingClient(client, responses, cfg.Provider)
+	newClient := cachingClients(responses, analyzer.NewProviderClient)
+	if costs != nil && client != nil {
+		client = costs.wrap(client, cfg.Provider)
+		providerClient := newClient
+		newClient = func(pc config.ProviderConfig) analyzer.BAMLClient {
+			return costs.wrap(providerClient(pc), pc)
+		}
+	} else {
+		costs = nil
 	}
 
 	// Append applicability filter if enabled (default).
@@ -128,7 +256,7 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 
 	// Calibration: retrieve thresholds + few-shot examples
 	var thresholdOverrides map[string]calibration.ThresholdOverride
-	if cfg.Calibration.Enabled && cfg.Calibration.Retrieve.Enabled && cfg.Calibration.ServerURL != "" {
+	if cfg.Calibration.Enabled && cfg.Calibration.Retrieve.Enabled && cfg.Calibration.ServerURL != "" && !flagDryRun {
 		apiKey := os.Getenv(cfg.Calibration.APIKeyEnv)
 		if apiKey != "" {
 			calClient := calibration.NewClient(
@@ -154,8 +282,9 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	}
 
 	// Read input
-	h := input.NewHandler()
+	h := input.NewHandler(input.WithMaxFileSize(flagMaxFileSize), input.WithIncludeGenerated(flagIncludeGen))
 	var artifacts []input.Artifact
+	var changed []input.ChangedFile // set by --git-range and --staged
 	var inputScope string
 
 	modeCount := 0
@@ -168,8 +297,53 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	if flagDir != "" {
 		modeCount++
 	}
+	if flagGitRange != "" {
+		modeCount++
+	}
+	if flagStaged {
+		modeCount++
+	}
+	if flagArchive != "" {
+		modeCount++
+	}
+	if flagStdin {
+		modeCount++
+	}
 	if modeCount > 1 {
-		return fmt.Errorf("specify only one of --files, --diff, or --dir")
+		return fmt.Errorf("specify only one of --files, --diff, --dir, --git-range, --staged, --archive, or --stdin")
+	}
+	if flagStdin != (flagStdinPath != "") {
+		return fmt.Errorf("--stdin and --path must be given together")
+	}
+
+	if flagIncremental && flagDir == "" {
+		return fmt.Errorf("--incremental requires --dir")
+	}
+
+	var shard *input.Shard
+	if flagShard != "" {
+		s, err := input.ParseShard(flagShard)
+		if err != nil {
+			return fmt.Errorf("invalid --shard: %w", err)
+		}
+		if flagIncremental {
+			return fmt.Errorf("--shard cannot be combined with --incremental")
+		}
+		if flagBaseline != "" {
+			return fmt.Errorf("--shard cannot be combined with --baseline; pass --baseline to gavel merge instead")
+		}
+		shard = &s
+	}
+
+	var lineRange lineRange
+	if flagRange != "" {
+		if len(flagFiles) != 1 {
+			return fmt.Errorf("--range requires exactly one file via --files")
+		}
+		lineRange, err = parseLineRange(flagRange)
+		if err != nil {
+			return fmt.Errorf("invalid --range: %w", err)
+		}
 	}
 
 	switch {
@@ -196,12 +370,50 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	case flagDir != "":
 		artifacts, err = h.ReadDirectory(flagDir)
 		inputScope = "directory"
+	case flagGitRange != "" || flagStaged:
+		if flagStaged {
+			changed, err = h.ReadGitStaged(ctx, ".")
+		} else {
+			changed, err = h.ReadGitRange(ctx, ".", flagGitRange)
+		}
+		for _, cf := range changed {
+			artifacts = append(artifacts, cf.Artifact)
+		}
+		inputScope = "diff"
+	case flagArchive != "":
+		artifacts, err = h.ReadArchive(flagArchive)
+		inputScope = "directory"
+	case flagStdin:
+		artifacts, err = h.ReadStream(os.Stdin, flagStdinPath)
+		inputScope = "files"
+	case flagSBOM != "":
+		inputScope = "sbom"
 	default:
-		return fmt.Errorf("specify --files, --diff, or --dir")
+		return fmt.Errorf("specify --files, --diff, --dir, --git-range, --staged, --archive, --stdin, or --sbom")
 	}
 	if err != nil {
 		return fmt.Errorf("reading input: %w", err)
 	}
+	if flagSBOM != "" {
+		sbom, err := readSBOM(flagSBOM)
+		if err != nil {
+			return fmt.Errorf("reading --sbom: %w", err)
+		}
+		artifacts = append(artifacts, sbom)
+	}
+	if shard != nil {
+		total := len(artifacts)
+		artifacts = shard.Select(artifacts)
+		slog.Info("analyzing shard", "shard", shard.String(), "files", len(artifacts), "of", total)
+	}
+
+	if flagProfileRules {
+		out, _ := json.MarshalIndent(map[string]interface{}{
+			"profile": profileRules(ctx, artifacts, loadedRules, flagProfileTop, flagProfileBudget),
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
 
 	// Root span for the analysis pipeline
 	ctx, span := analyzeTracer.Start(ctx, "analyze code",
@@ -215,11 +427,30 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	defer span.End()
 
 	// Analyze with tiered analyzer (instant pattern matching + LLM)
-	client := analyzer.NewBAMLLiveClient(cfg.Provider)
 	tieredOpts := []analyzer.TieredAnalyzerOption{analyzer.WithInstantPatterns(loadedRules)}
+	if d := cfg.Provider.RequestTimeoutDuration(); d > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredRequestTimeout(d))
+	}
+	if n := cfg.Provider.RequestTokenBudget(); n > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredTokenBudget(n))
+	}
+	if n := cfg.Provider.BatchTokens; n > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredBatching(n))
+	}
+	if len(cfg.PathOverrides) > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithPathOverrides(cfg.PathOverrides))
+	}
+	tieredOpts = append(tieredOpts, analyzer.WithSecretScanner(analyzer.SecretScanner(cfg.Secrets)))
+	if cfg.ParseErrors.Action != "" || cfg.ParseErrors.Retries > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithParseErrorPolicy(analyzer.ParseErrorAction(cfg.ParseErrors.Action), cfg.ParseErrors.Retries))
+	}
+	if cfg.Escalation.Enabled {
+		tieredOpts = append(tieredOpts, analyzer.WithEscalation(cfg.Escalation))
+	}
 
-	// Build diff context to reduce false positives when analyzing diffs
-	if inputScope == "diff" {
+	// Build diff context to reduce false positives when analyzing diffs.
+	// Git changes are analyzed as full files, so they need none.
+	if inputScope == "diff" && changed == nil {
 		repoDir, err := os.Getwd()
 		if err != nil {
 			return fmt.Errorf("getting working directory: %w", err)
@@ -231,14 +462,119 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	ta := analyzer.NewTieredAnalyzer(client, tieredOpts...)
-	results, err := ta.Analyze(ctx, artifacts, cfg.Policies, personaPrompt)
+	if c := analysisCache(cfg, loadedRules, flagPolicyDir); c != nil {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredCache(c))
+	}
+	if cfg.Cache.Normalize {
+		tieredOpts = append(tieredOpts, analyzer.WithNormalizedCacheKeys(true))
+	}
+	tieredOpts = append(tieredOpts, analyzer.WithContextResolver(contextResolver(cfg)))
+	if client != nil && tiers[analyzer.TierComprehensive] {
+		tieredOpts = append(tieredOpts, analyzer.WithPolicyRoutes(analyzer.PolicyRoutes(cfg, newClient)))
+	}
+	if client != nil && tiers[analyzer.TierFast] {
+		tieredOpts = append(tieredOpts, analyzer.FastTierOptions(cfg, newClient)...)
+	}
+	if !tiers[analyzer.TierInstant] {
+		tieredOpts = append(tieredOpts, analyzer.WithInstantEnabled(false))
+	}
+
+	var applied *analyzer.AppliedRules
+	if flagDumpApplied != "" || flagDryRun {
+		applied = analyzer.NewAppliedRules()
+		tieredOpts = append(tieredOpts, analyzer.WithAppliedRules(applied))
+	}
+
+	var stream *resultStream
+	if flagStream {
+		stream = newResultStream(os.Stdout)
+		tieredOpts = append(tieredOpts, analyzer.WithResultHandler(stream.Tier))
+	}
+
+	// Incremental runs analyze only changed files and reuse stored
+	// findings for the rest
+	var incrementalPlan *incremental.Plan
+	var incrementalSettingsHash string
+	toAnalyze := artifacts
+	if flagIncremental {
+		incrementalSettingsHash, err = incrementalSettings(cfg, loadedRules, personaPrompt, ranTiers)
+		if err != nil {
+			return err
+		}
+		state, err := incremental.Load(incrementalStateDir(flagPolicyDir))
+		if err != nil {
+			slog.Warn("ignoring unreadable incremental state; analyzing every file", "err", err)
+			state = &incremental.State{}
+		}
+		plan := state.Plan(artifacts, incrementalSettingsHash)
+		incrementalPlan = &plan
+		toAnalyze = plan.Changed
+		slog.Info("incremental analysis", "changed", len(plan.Changed), "unchanged", plan.Unchanged)
+	}
+
+	comprehensiveClient := client
+	if !tiers[analyzer.TierComprehensive] {
+		comprehensiveClient = nil
+	}
+	ta := analyzer.NewTieredAnalyzer(comprehensiveClient, tieredOpts...)
+	if flagDryRun {
+		out, _ := json.MarshalIndent(map[string]interface{}{
+			"dry_run": dryRun(ctx, ta, applied, cfg, personaPrompt, llmSkipped, toAnalyze, changed, lineRange),
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	var results []sarif.Result
+	var parseFailures []analyzer.ParseFailure
+	switch {
+	case flagRange != "":
+		results, err = ta.AnalyzeRange(ctx, artifacts[0], lineRange.start, lineRange.end,
+			analyzer.DefaultRangeContext, cfg.Policies, personaPrompt)
+		parseFailures = ta.ParseFailures()
+	case changed != nil:
+		// Like the MCP scoped flow: instant rules see each whole file, the
+		// LLM tiers only windows around the changed lines.
+		for _, cf := range changed {
+			fileResults, fileErr := ta.AnalyzeRanges(ctx, cf.Artifact, cf.Changed,
+				analyzer.DefaultRangeContext, cfg.Policies, personaPrompt)
+			results = append(results, fileResults...)
+			// Each call resets the analyzer's record, so gather them per file
+			parseFailures = append(parseFailures, ta.ParseFailures()...)
+			if fileErr != nil {
+				err = fileErr
+			}
+		}
+	default:
+		results, err = ta.Analyze(ctx, toAnalyze, cfg.Policies, personaPrompt)
+		parseFailures = ta.ParseFailures()
+	}
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("analyzing: %w", err)
 	}
 
+	if incrementalPlan != nil {
+		results = append(results, incrementalPlan.Reused...)
+		// A run cut short by --max-cost holds partial findings for some
+		// files, which must not be reused as if complete.
+		if ta.Stats().BudgetSkipped > 0 {
+			slog.Warn("not saving incremental state: the cost budget ran out before every file was analyzed")
+		} else if err := incremental.Record(artifacts, results, incrementalSettingsHash).Save(incrementalStateDir(flagPolicyDir)); err != nil {
+			slog.Warn("saving incremental state failed; the next run will analyze every file", "err", err)
+		}
+	}
+
+	var appliedPath string
+	if applied != nil {
+		data, err := json.MarshalIndent(map[string]interface{}{"files": applied.Files()}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding applied rules: %w", err)
+		}
+		appliedPath = writeOutputFile(flagDumpApplied, append(data, '\n'), "gavel-applied-", "applied rules")
+	}
+
 	descriptors := []sarif.ReportingDescriptor{}
 	for name, p := range cfg.Policies {
 		if p.Enabled {
@@ -252,9 +588,33 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	for _, r := range loadedRules {
 		descriptors = append(descriptors, r.ToSARIFDescriptor())
 	}
+	if analyzer.ParseErrorAction(cfg.ParseErrors.Action) == analyzer.ParseErrorDiagnostic {
+		descriptors = append(descriptors, analyzer.ParseErrorDescriptor())
+	}
+
+	// Assemble SARIF, recording what produced it for auditing
+	assembleOpts := []sarif.AssembleOption{
+		sarif.WithDedupWindow(cfg.DedupWindow),
+		sarif.WithDedupMode(sarif.DedupMode(cfg.Dedup), cfg.DedupSimilarity),
+	}
+	if prov, err := sarif.NewProvenance(version, cfg, loadedRules, personaPrompt); err != nil {
+		slog.Warn("omitting report provenance", "err", err)
+	} else {
+		assembleOpts = append(assembleOpts, sarif.WithProvenance(prov))
+	}
+	sarifLog := sarif.Assemble(results, descriptors, inputScope, cfg.Persona, assembleOpts...)
 
-	// Assemble SARIF
-	sarifLog := sarif.Assemble(results, descriptors, inputScope, cfg.Persona)
+	// Demote findings below their minimum confidence, keeping them on record
+	belowConfidence := sarif.DemoteBelowConfidence(sarifLog.Runs[0].Results, cfg.MinConfidenceFor)
+
+	if costs != nil {
+		costs.annotate(sarifLog, ta.Stats().BudgetSkipped)
+	}
+	ta.RecordEscalation(sarifLog)
+
+	if shard != nil && len(sarifLog.Runs) > 0 {
+		sarifLog.Runs[0].Properties[merge.PropShard] = shard.String()
+	}
 
 	// Stamp a stable automation guid so subsequent runs can reference this
 	// one via baselineGuid.
@@ -267,12 +627,9 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	// carries baselineState for downstream consumers to key off).
 	baselineNew, baselineUnchanged, baselineAbsent := 0, 0, 0
 	if flagBaseline != "" {
-		baselineStore := store.NewFileStore(flagOutput)
-		baselineLog, err := store.LoadBaseline(ctx, baselineStore, flagBaseline)
-		if err != nil {
-			return fmt.Errorf("loading baseline %q: %w", flagBaseline, err)
+		if err := applyBaseline(ctx, sarifLog, flagOutput, flagBaseline); err != nil {
+			return err
 		}
-		sarif.CompareBaseline(sarifLog, baselineLog)
 		if len(sarifLog.Runs) > 0 {
 			for _, r := range sarifLog.Runs[0].Results {
 				switch r.BaselineState {
@@ -313,13 +670,52 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Store results
-	fs := store.NewFileStore(flagOutput)
-	id, err := fs.WriteSARIF(ctx, sarifLog)
+	// Ownership enrichment for routing findings to authors
+	if flagBlame || flagOwners {
+		blame.Enrich(ctx, sarifLog, "")
+	}
+	ownersDir := flagDir
+	if ownersDir == "" {
+		ownersDir = "."
+	}
+	if owners, root, err := codeowners.Find(ownersDir); err != nil {
+		slog.Warn("ignoring CODEOWNERS", "err", err)
+	} else {
+		codeowners.Enrich(sarifLog, owners, root)
+	}
+
+	// Impact enrichment: weight findings in widely imported Go packages
+	if flagBlastRadius {
+		blastradius.Enrich(sarifLog, blastradius.Build(artifacts))
+	}
+
+	// Annotated review copies
+	var annotated annotate.Written
+	if flagAnnotate != "" && len(sarifLog.Runs) > 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		if dir := writableDir(flagAnnotate, "gavel-annotated-", "annotated copies"); dir != "" {
+			annotated, err = annotate.Write(dir, cwd, artifacts, sarifLog.Runs[0].Results)
+			if err != nil {
+				return fmt.Errorf("writing annotated copies: %w", err)
+			}
+		}
+		for _, p := range annotated.Skipped {
+			slog.Warn("no comment syntax known; file not annotated", "file", p)
+		}
+	}
+
+	// Record the analyzed commit so history can be queried by revision
+	recordRevision(ctx, sarifLog, flagDir)
+
+	// Store results, falling back to a temp dir when --output is not writable
+	id, storedIn, err := storeResults(ctx, sarifLog, flagOutput, flagNoStore)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return fmt.Errorf("storing SARIF: %w", err)
+		return err
 	}
 
 	// Calibration: upload events (non-blocking)
@@ -360,26 +756,167 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		findingCount = len(sarifLog.Runs[0].Results)
 	}
 	summary := map[string]interface{}{
-		"id":         id,
 		"findings":   findingCount,
 		"scope":      inputScope,
 		"persona":    cfg.Persona,
 		"suppressed": suppressedCount,
+		"tiers":      tierSummary(sarifLog, ranTiers),
+	}
+	recordStorage(summary, sarifLog, id, storedIn, flagOutput)
+	if belowConfidence > 0 {
+		summary["below_confidence"] = belowConfidence
+	}
+	if flagRange != "" {
+		summary["range"] = flagRange
+	}
+	if shard != nil {
+		summary["shard"] = shard.String()
+	}
+	if llmSkipped != "" {
+		summary["llm_skipped"] = llmSkipped
+	}
+	if flagOwners {
+		summary["owners"] = blame.Owners(sarifLog)
+		if teams := codeowners.GroupResults(actionableResults(sarifLog)); teams != nil {
+			summary["teams"] = teams
+		}
+	}
+	if incrementalPlan != nil {
+		summary["incremental"] = map[string]int{
+			"analyzed": len(incrementalPlan.Changed),
+			"reused":   incrementalPlan.Unchanged,
+		}
+	}
+	if costs != nil {
+		costs.summarize(summary, ta.Stats().BudgetSkipped)
+	}
+	if appliedPath != "" && appliedPath != flagDumpApplied {
+		summary["applied_rules"] = appliedPath
+	}
+	if flagAnnotate != "" {
+		files := annotated.Files
+		if files == nil {
+			files = []string{}
+		}
+		summary["annotated"] = files
+	}
+	if len(parseFailures) > 0 {
+		summary["parse_failures"] = parseFailures
+	}
+	if timeouts := ta.RequestTimeouts(); len(timeouts) > 0 {
+		summary["request_timeouts"] = timeouts
+	}
+	if cfg.Escalation.Enabled {
+		summary["escalation_skipped"] = len(ta.EscalationSkipped())
 	}
 	if flagBaseline != "" {
-		summary["baseline"] = map[string]interface{}{
+		baselineSummary := map[string]interface{}{
 			"source":    flagBaseline,
 			"new":       baselineNew,
 			"unchanged": baselineUnchanged,
 			"absent":    baselineAbsent,
 		}
+		if !flagIgnoreResolved {
+			baselineSummary["resolved"] = resolvedSummary(sarifLog)
+		}
+		summary["baseline"] = baselineSummary
+	}
+	if threshold != nil {
+		summary["threshold"] = thresholdSummary(threshold, sarifLog)
+	}
+	if stream != nil {
+		for _, run := range sarifLog.Runs {
+			stream.Results(run.Results)
+		}
+		stream.Summary(summary)
+		if err := stream.Err(); err != nil {
+			return err
+		}
+	} else {
+		out, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(out))
 	}
-	out, _ := json.MarshalIndent(summary, "", "  ")
-	fmt.Println(string(out))
 
+	if threshold != nil && threshold.Exceeded(sarifLog) {
+		return gateFailed(cmd, "%d actionable findings at level %s or above exceed the limit of %d",
+			threshold.Count(sarifLog), threshold.FailOn, threshold.MaxFindings)
+	}
 	return nil
 }
 
+// thresholdSummary reports how log measured up against t.
+func thresholdSummary(t *evaluator.FindingThreshold, log *sarif.Log) map[string]interface{} {
+	return map[string]interface{}{
+		"fail_on":      t.FailOn,
+		"max_findings": t.MaxFindings,
+		"findings":     t.Count(log),
+		"exceeded":     t.Exceeded(log),
+	}
+}
+
+// actionableResults returns the findings of log that count toward the
+// verdict: not suppressed, informational or resolved since the baseline.
+func actionableResults(log *sarif.Log) []sarif.Result {
+	var results []sarif.Result
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 || r.Kind == sarif.KindInformational || r.BaselineState == sarif.BaselineStateAbsent {
+				continue
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// resolvedFinding is the informational record listed for each baseline
+// finding that no longer appears in the current run.
+type resolvedFinding struct {
+	RuleID  string `json:"rule_id"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// resolvedSummary lists the findings that baseline comparison marked absent
+// in log. It only reports them; the gate already ignores absent results.
+func resolvedSummary(log *sarif.Log) []resolvedFinding {
+	resolved := []resolvedFinding{}
+	for _, r := range sarif.ResolvedResults(log) {
+		f := resolvedFinding{RuleID: r.RuleID, Message: r.Message.Text}
+		if len(r.Locations) > 0 {
+			f.File = r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+			f.Line = r.Locations[0].PhysicalLocation.Region.StartLine
+		}
+		resolved = append(resolved, f)
+	}
+	return resolved
+}
+
+// lineRange is a 1-indexed, inclusive span of lines parsed from --range.
+type lineRange struct {
+	start, end int
+}
+
+// parseLineRange parses a "START:END" line range as accepted by --range.
+func parseLineRange(s string) (lineRange, error) {
+	startStr, endStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return lineRange{}, fmt.Errorf("expected START:END, got %q", s)
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(startStr))
+	if err != nil {
+		return lineRange{}, fmt.Errorf("invalid start line %q", startStr)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endStr))
+	if err != nil {
+		return lineRange{}, fmt.Errorf("invalid end line %q", endStr)
+	}
+	if start < 1 || end < start {
+		return lineRange{}, fmt.Errorf("range %d:%d must satisfy 1 <= START <= END", start, end)
+	}
+	return lineRange{start: start, end: end}, nil
+}
 
 // uploadResultsToCache uploads analysis results to the remote cache server
 func uploadResultsToCache(ctx context.Context, cfg *config.Config, cacheURL string, artifacts []input.Artifact, results []sarif.Result) error {
@@ -390,7 +927,7 @@ func uploadResultsToCache(ctx context.Context, cfg *config.Config, cacheURL stri
 		return fmt.Errorf("getting cache token: %w", err)
 	}
 	if token != "" {
-		opts = append(opts, cache.WithToken(token))
+		opts = append(opts, cache.WithAuth(cfg.RemoteCache.Auth.Type, token))
 	}
 
 	remoteCache := cache.NewRemoteCache(cacheURL, opts...)
@@ -458,8 +995,22 @@ func getModelFromConfig(cfg *config.Config) string {
 		return cfg.Provider.Bedrock.Model
 	case "openai":
 		return cfg.Provider.OpenAI.Model
+	case "llamacpp":
+		return cfg.Provider.LlamaCpp.Model
 	default:
 		slog.Warn("unrecognized provider for model lookup", "provider", cfg.Provider.Name)
 		return cfg.Provider.Name
 	}
 }
+
+// readSBOM reads a CycloneDX SBOM as an artifact for dependency rules.
+func readSBOM(path string) (input.Artifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return input.Artifact{}, err
+	}
+	if _, err := deps.ParseCycloneDX(string(data)); err != nil {
+		return input.Artifact{}, err
+	}
+	return input.Artifact{Path: path, Content: string(data), Kind: input.KindSBOM}, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func baselineResult(ruleID, uri, snippet string, line int) sarif.Result {
+	r := sarif.Result{
+		RuleID:  ruleID,
+		Level:   "error",
+		Message: sarif.Message{Text: ruleID + " finding"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region: sarif.Region{
+				StartLine: line,
+				EndLine:   line,
+				Snippet:   &sarif.ArtifactContent{Text: snippet},
+			},
+		}}},
+	}
+	sarif.SetContentFingerprint(&r)
+	return r
+}
+
+func TestResolvedSummary(t *testing.T) {
+	baseline := &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3),
+		baselineResult("S3649", "b.go", "db.Query(\"SELECT \" + id)\n", 7),
+	}}}}
+	current := &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3),
+	}}}}
+	sarif.CompareBaseline(current, baseline)
+
+	resolved := resolvedSummary(current)
+	require.Len(t, resolved, 1)
+	assert.Equal(t, resolvedFinding{
+		RuleID:  "S3649",
+		File:    "b.go",
+		Line:    7,
+		Message: "S3649 finding",
+	}, resolved[0])
+}
+
+func TestResolvedSummary_NothingResolved(t *testing.T) {
+	current := &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3),
+	}}}}
+	sarif.CompareBaseline(current, &sarif.Log{Runs: []sarif.Run{{}}})
+
+	resolved := resolvedSummary(current)
+	assert.NotNil(t, resolved)
+	assert.Empty(t, resolved)
+}
+
+func TestBaselineIgnoreResolvedFlag_DefaultsToOmitting(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"analyze"})
+	require.NoError(t, err)
+
+	flag := cmd.Flags().Lookup("baseline-ignore-resolved")
+	require.NotNil(t, flag)
+	assert.Equal(t, "true", flag.DefValue)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLineRange(t *testing.T) {
+	r, err := parseLineRange("100:150")
+	require.NoError(t, err)
+	assert.Equal(t, lineRange{start: 100, end: 150}, r)
+
+	r, err = parseLineRange("7:7")
+	require.NoError(t, err)
+	assert.Equal(t, lineRange{start: 7, end: 7}, r)
+
+	for _, bad := range []string{"", "100", "a:b", "0:10", "20:10", "-1:3"} {
+		_, err := parseLineRange(bad)
+		assert.Error(t, err, "expected %q to be rejected", bad)
+	}
+}
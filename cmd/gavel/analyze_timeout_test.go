@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+// stuckLLM answers at once for every file except slow.go, whose call
+// blocks until its context ends.
+type stuckLLM struct{}
+
+func (stuckLLM) AnalyzeCode(ctx context.Context, code, policies, personaPrompt, additionalContext string) ([]analyzer.Finding, error) {
+	header, _, _ := strings.Cut(code, "\n")
+	if strings.HasSuffix(header, "slow.go") {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return []analyzer.Finding{{RuleID: "llm-finding", Level: "warning", Message: "Something to look at", StartLine: 1, EndLine: 1, Confidence: 0.9}}, nil
+}
+
+// findCommand returns the subcommand of rootCmd named name.
+func findCommand(t *testing.T, name string) *cobra.Command {
+	t.Helper()
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name {
+			return c
+		}
+	}
+	t.Fatalf("no %s command", name)
+	return nil
+}
+
+// captureStdout returns what fn prints to stdout.
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	prev := os.Stdout
+	os.Stdout = w
+	runErr := fn()
+	os.Stdout = prev
+	w.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out), runErr
+}
+
+func TestRunAnalyze_RequestTimeoutKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.MkdirAll(src, 0755))
+	for _, name := range []string{"slow.go", "fast.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(src, name), []byte("package src\n"), 0644))
+	}
+	policyDir := filepath.Join(dir, ".gavel")
+	require.NoError(t, os.MkdirAll(policyDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "policies.yaml"), []byte(`provider:
+  name: ollama
+  request_timeout: 50ms
+  ollama:
+    model: test-model
+    base_url: http://localhost:11434
+`), 0644))
+
+	prevDir, prevPolicies, prevOutput, prevInit := flagDir, flagPolicyDir, flagOutput, initLLM
+	t.Cleanup(func() { flagDir, flagPolicyDir, flagOutput, initLLM = prevDir, prevPolicies, prevOutput, prevInit })
+	t.Setenv("HOME", dir)
+	flagDir, flagPolicyDir, flagOutput = src, policyDir, filepath.Join(dir, "results")
+	initLLM = func(context.Context, *config.Config) (analyzer.BAMLClient, string, error) {
+		return stuckLLM{}, "persona", nil
+	}
+
+	out, err := captureStdout(t, func() error { return runAnalyze(findCommand(t, "analyze"), nil) })
+	require.NoError(t, err, "one file's request timeout must not fail the run")
+
+	var summary struct {
+		ID              string                    `json:"id"`
+		RequestTimeouts []analyzer.RequestTimeout `json:"request_timeouts"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &summary), out)
+	require.Len(t, summary.RequestTimeouts, 1)
+	assert.True(t, strings.HasSuffix(summary.RequestTimeouts[0].Path, "slow.go"))
+	assert.Equal(t, "comprehensive", summary.RequestTimeouts[0].Tier)
+
+	log, err := store.NewFileStore(flagOutput).ReadSARIF(context.Background(), summary.ID)
+	require.NoError(t, err, "the SARIF output should still be written")
+	var llmFiles []string
+	for _, r := range log.Runs[0].Results {
+		if r.RuleID == "llm-finding" {
+			llmFiles = append(llmFiles, filepath.Base(sarifURI(r)))
+		}
+	}
+	assert.Equal(t, []string{"fast.go"}, llmFiles)
+}
+
+func sarifURI(r sarif.Result) string {
+	if len(r.Locations) == 0 {
+		return ""
+	}
+	return r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/baseline"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+var (
+	flagBaselineResult    string
+	flagBaselineOutput    string
+	flagBaselineFile      string
+	flagBaselineForce     bool
+	flagBaselinePruneOnly bool
+)
+
+func init() {
+	baselineCmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Snapshot existing findings so analyze reports only new ones",
+		Long: `Record the findings of an analysis in a baseline file. Running
+analyze with --baseline pointing at that file marks baselined findings as
+unchanged, so judge gates only on findings introduced since.`,
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a baseline from an analysis result",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBaselineCreate(cmd.Context(), cmd.OutOrStdout(), flagBaselineOutput, flagBaselineResult, flagBaselineFile, flagBaselineForce)
+		},
+	}
+	createCmd.Flags().BoolVar(&flagBaselineForce, "force", false, "Overwrite an existing baseline file")
+
+	updateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Reconcile a baseline with an analysis result",
+		Long: `Drop baseline entries whose findings are gone and add findings that are
+new. With --prune-only, only drop entries, so the baseline can only shrink.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBaselineUpdate(cmd.Context(), cmd.OutOrStdout(), flagBaselineOutput, flagBaselineResult, flagBaselineFile, flagBaselinePruneOnly)
+		},
+	}
+	updateCmd.Flags().BoolVar(&flagBaselinePruneOnly, "prune-only", false, "Only remove entries for fixed findings; never add new ones")
+
+	for _, c := range []*cobra.Command{createCmd, updateCmd} {
+		c.Flags().StringVar(&flagBaselineResult, "result", "", "Analysis result ID or SARIF file to snapshot (default: most recent)")
+		c.Flags().StringVar(&flagBaselineOutput, "output", ".gavel/results", "Directory containing analysis results")
+		c.Flags().StringVar(&flagBaselineFile, "file", baseline.DefaultPath, "Baseline file to write")
+		baselineCmd.AddCommand(c)
+	}
+
+	rootCmd.AddCommand(baselineCmd)
+}
+
+// loadBaselineSource reads the SARIF log named by ref (a result ID or SARIF
+// file), or the most recent stored result when ref is empty.
+func loadBaselineSource(ctx context.Context, outputDir, ref string) (*sarif.Log, error) {
+	results := store.NewFileStore(outputDir)
+	if ref == "" {
+		ids, err := results.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing results: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no analysis results found in %s", outputDir)
+		}
+		ref = ids[0] // List returns newest first
+	}
+	log, err := store.LoadBaseline(ctx, results, ref)
+	if err != nil {
+		return nil, fmt.Errorf("reading result %s: %w", ref, err)
+	}
+	return log, nil
+}
+
+// applyBaseline marks the results in log against ref, which is either a
+// baseline file or a SARIF log (stored result ID or file) to compare with.
+func applyBaseline(ctx context.Context, log *sarif.Log, outputDir, ref string) error {
+	f, err := baseline.Load(ref)
+	if err == nil {
+		root, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		baseline.Apply(log, f, root)
+		return nil
+	}
+	if !errors.Is(err, baseline.ErrNotBaseline) && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("loading baseline %q: %w", ref, err)
+	}
+
+	baselineLog, err := store.LoadBaseline(ctx, store.NewFileStore(outputDir), ref)
+	if err != nil {
+		return fmt.Errorf("loading baseline %q: %w", ref, err)
+	}
+	sarif.CompareBaseline(log, baselineLog)
+	return nil
+}
+
+// runBaselineCreate snapshots the result ref (latest when empty) from
+// outputDir into the baseline file path.
+func runBaselineCreate(ctx context.Context, w io.Writer, outputDir, ref, path string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists; use baseline update or --force", path)
+	}
+	log, err := loadBaselineSource(ctx, outputDir, ref)
+	if err != nil {
+		return err
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+
+	f, skipped := baseline.FromLog(log, root)
+	if err := f.Write(path); err != nil {
+		return err
+	}
+	return writeBaselineSummary(w, map[string]interface{}{
+		"file":     path,
+		"findings": len(f.Findings),
+		"skipped":  skipped,
+	})
+}
+
+// runBaselineUpdate reconciles the baseline file path with the result ref
+// (latest when empty) from outputDir.
+func runBaselineUpdate(ctx context.Context, w io.Writer, outputDir, ref, path string, pruneOnly bool) error {
+	existing, err := baseline.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s does not exist; run baseline create first", path)
+	}
+	if err != nil {
+		return err
+	}
+	log, err := loadBaselineSource(ctx, outputDir, ref)
+	if err != nil {
+		return err
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+
+	updated, added, removed := baseline.Update(existing, log, root, pruneOnly)
+	if err := updated.Write(path); err != nil {
+		return err
+	}
+	return writeBaselineSummary(w, map[string]interface{}{
+		"file":     path,
+		"findings": len(updated.Findings),
+		"added":    added,
+		"removed":  removed,
+	})
+}
+
+func writeBaselineSummary(w io.Writer, summary map[string]interface{}) error {
+	out, _ := json.MarshalIndent(summary, "", "  ")
+	_, err := fmt.Fprintln(w, string(out))
+	return err
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/baseline"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+func storeBaselineLog(t *testing.T, outputDir string, results ...sarif.Result) string {
+	t.Helper()
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = results
+	id, err := store.NewFileStore(outputDir).WriteSARIF(context.Background(), log)
+	require.NoError(t, err)
+	return id
+}
+
+func TestBaselineCreateAndApply(t *testing.T) {
+	ctx := context.Background()
+	outputDir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	storeBaselineLog(t, outputDir,
+		baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3),
+		baselineResult("S3649", "b.go", "db.Query(\"SELECT \" + id)\n", 7),
+	)
+
+	var out bytes.Buffer
+	require.NoError(t, runBaselineCreate(ctx, &out, outputDir, "", path, false))
+	var summary map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.EqualValues(t, 2, summary["findings"])
+
+	err := runBaselineCreate(ctx, &out, outputDir, "", path, false)
+	require.Error(t, err, "create must not overwrite an existing baseline")
+	assert.Contains(t, err.Error(), "already exists")
+
+	current := sarif.NewLog("gavel", "0.1.0")
+	current.Runs[0].Results = []sarif.Result{
+		baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 30),
+		baselineResult("S1135", "c.go", "// TODO: fix\n", 1),
+	}
+	require.NoError(t, applyBaseline(ctx, current, outputDir, path))
+
+	states := map[string]string{}
+	for _, r := range current.Runs[0].Results {
+		states[r.RuleID] = r.BaselineState
+	}
+	assert.Equal(t, map[string]string{
+		"S2068": sarif.BaselineStateUnchanged,
+		"S1135": sarif.BaselineStateNew,
+		"S3649": sarif.BaselineStateAbsent,
+	}, states)
+}
+
+func TestApplyBaseline_StoredResultID(t *testing.T) {
+	ctx := context.Background()
+	outputDir := t.TempDir()
+	id := storeBaselineLog(t, outputDir, baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3))
+
+	current := sarif.NewLog("gavel", "0.1.0")
+	current.Runs[0].Results = []sarif.Result{baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3)}
+	require.NoError(t, applyBaseline(ctx, current, outputDir, id))
+	assert.Equal(t, sarif.BaselineStateUnchanged, current.Runs[0].Results[0].BaselineState)
+}
+
+func TestBaselineUpdate(t *testing.T) {
+	ctx := context.Background()
+	outputDir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	err := runBaselineUpdate(ctx, &bytes.Buffer{}, outputDir, "", path, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "baseline create")
+
+	first := storeBaselineLog(t, outputDir,
+		baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3),
+		baselineResult("S3649", "b.go", "db.Query(\"SELECT \" + id)\n", 7),
+	)
+	require.NoError(t, runBaselineCreate(ctx, &bytes.Buffer{}, outputDir, first, path, false))
+
+	second := storeBaselineLog(t, outputDir,
+		baselineResult("S2068", "a.go", "password := \"hunter2\"\n", 3),
+		baselineResult("S1135", "c.go", "// TODO: fix\n", 1),
+	)
+
+	var out bytes.Buffer
+	require.NoError(t, runBaselineUpdate(ctx, &out, outputDir, second, path, true))
+	var summary map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.EqualValues(t, 0, summary["added"])
+	assert.EqualValues(t, 1, summary["removed"])
+
+	f, err := baseline.Load(path)
+	require.NoError(t, err)
+	require.Len(t, f.Findings, 1)
+	assert.Equal(t, "S2068", f.Findings[0].RuleID)
+
+	out.Reset()
+	require.NoError(t, runBaselineUpdate(ctx, &out, outputDir, second, path, false))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.EqualValues(t, 1, summary["added"])
+	assert.EqualValues(t, 2, summary["findings"])
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/cacheserver"
+	"github.com/chris-regnier/gavel/internal/server"
+)
+
+var (
+	flagCacheAddr       string
+	flagCacheBackend    string
+	flagCacheDir        string
+	flagCacheS3Bucket   string
+	flagCacheS3Region   string
+	flagCacheS3Endpoint string
+	flagCacheRedisURL   string
+	flagCachePrefix     string
+	flagCacheTTL        time.Duration
+	flagCacheSweep      time.Duration
+	flagCacheAuthType   string
+	flagCacheTokenFile  string
+	flagCacheAuthKeys   string
+	flagCacheMaxEntry   int64
+	flagCacheMetrics    bool
+)
+
+func init() {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the shared analysis cache",
+	}
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run a remote cache server for remote_cache clients",
+		Long: `Serve the remote cache API that analyze and lsp use when remote_cache is
+configured, so CI and developer machines can share analysis results.
+
+Entries are stored in a directory (--backend fs), an S3 bucket (--backend s3,
+credentials from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optionally
+AWS_SESSION_TOKEN), or Redis (--backend redis). Entries older than --ttl are
+treated as missing and removed every --sweep-interval.
+
+With --auth-type bearer or api_key, clients must present one of the tokens in
+--token-file or --auth-keys, the same way remote_cache.auth sends it.`,
+		RunE: runCacheServe,
+	}
+
+	f := serveCmd.Flags()
+	f.StringVar(&flagCacheAddr, "addr", ":8090", "Listen address")
+	f.StringVar(&flagCacheBackend, "backend", "fs", "Storage backend: fs, s3, or redis")
+	f.StringVar(&flagCacheDir, "dir", ".gavel/cache-server", "Directory for the fs backend")
+	f.StringVar(&flagCacheS3Bucket, "s3-bucket", "", "Bucket for the s3 backend")
+	f.StringVar(&flagCacheS3Region, "s3-region", "", "Region for the s3 backend (default $AWS_REGION, else us-east-1)")
+	f.StringVar(&flagCacheS3Endpoint, "s3-endpoint", "", "Endpoint of an S3-compatible service such as MinIO (default AWS)")
+	f.StringVar(&flagCacheRedisURL, "redis-url", "", "redis://[user:password@]host[:port][/db] for the redis backend (default $REDIS_URL)")
+	f.StringVar(&flagCachePrefix, "prefix", "", "Key prefix for the s3 and redis backends (default gavel-cache/ for s3, gavel:cache: for redis)")
+	f.DurationVar(&flagCacheTTL, "ttl", 7*24*time.Hour, "How long entries stay valid after they are stored (0 keeps them forever)")
+	f.DurationVar(&flagCacheSweep, "sweep-interval", time.Hour, "How often expired entries are removed (0 disables the sweep)")
+	f.StringVar(&flagCacheAuthType, "auth-type", "", "Client authentication: bearer, api_key, or empty for none")
+	f.StringVar(&flagCacheTokenFile, "token-file", "", "File containing a token clients may present")
+	f.StringVar(&flagCacheAuthKeys, "auth-keys", "", "File of token:client-name lines; client names label the usage metrics")
+	f.Int64Var(&flagCacheMaxEntry, "max-entry-size", cacheserver.DefaultMaxEntryBytes, "Largest entry a client may upload, in bytes")
+	f.BoolVar(&flagCacheMetrics, "metrics", false, "Expose Prometheus usage metrics at /metrics")
+
+	cacheCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheServe(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	storage, err := cacheStorage()
+	if err != nil {
+		return err
+	}
+
+	keys, err := cacheAuthKeys()
+	if err != nil {
+		return err
+	}
+	if flagCacheAuthType == "" && len(keys) > 0 {
+		return fmt.Errorf("--token-file and --auth-keys need --auth-type bearer or api_key")
+	}
+
+	srv, err := cacheserver.New(cacheserver.Config{
+		Storage:       storage,
+		TTL:           flagCacheTTL,
+		Auth:          cacheserver.Auth{Type: flagCacheAuthType, Keys: keys},
+		MaxEntryBytes: flagCacheMaxEntry,
+		Metrics:       flagCacheMetrics,
+	})
+	if err != nil {
+		return err
+	}
+
+	if flagCacheTTL > 0 && flagCacheSweep > 0 {
+		go sweepCache(ctx, srv, flagCacheSweep)
+	}
+
+	slog.Info("gavel cache server", "backend", flagCacheBackend, "ttl", flagCacheTTL, "auth", flagCacheAuthType)
+	return server.New(srv, server.Config{
+		Addr:         flagCacheAddr,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}).Start(ctx)
+}
+
+// cacheStorage creates the storage backend selected by --backend.
+func cacheStorage() (cache.Storage, error) {
+	switch flagCacheBackend {
+	case "fs":
+		return cache.NewLocalStorage(flagCacheDir), nil
+	case "s3":
+		region := flagCacheS3Region
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		prefix := flagCachePrefix
+		if prefix == "" {
+			prefix = "gavel-cache/"
+		}
+		return cache.NewS3Storage(cache.S3Config{
+			Bucket:          flagCacheS3Bucket,
+			Region:          region,
+			Endpoint:        flagCacheS3Endpoint,
+			Prefix:          prefix,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		})
+	case "redis":
+		raw := flagCacheRedisURL
+		if raw == "" {
+			raw = os.Getenv("REDIS_URL")
+		}
+		if raw == "" {
+			return nil, fmt.Errorf("the redis backend needs --redis-url or $REDIS_URL")
+		}
+		cfg, err := cache.ParseRedisURL(raw)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Prefix = flagCachePrefix
+		if cfg.Prefix == "" {
+			cfg.Prefix = "gavel:cache:"
+		}
+		cfg.TTL = flagCacheTTL
+		return cache.NewRedisStorage(cfg)
+	}
+	return nil, fmt.Errorf("unknown --backend %q (want fs, s3, or redis)", flagCacheBackend)
+}
+
+// cacheAuthKeys collects the tokens from --token-file and --auth-keys.
+func cacheAuthKeys() (map[string]string, error) {
+	keys := map[string]string{}
+	if flagCacheAuthKeys != "" {
+		var err error
+		keys, err = loadAuthKeys(flagCacheAuthKeys)
+		if err != nil {
+			return nil, fmt.Errorf("loading auth keys: %w", err)
+		}
+	}
+	if flagCacheTokenFile != "" {
+		data, err := os.ReadFile(flagCacheTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading token file: %w", err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return nil, fmt.Errorf("token file %s is empty", flagCacheTokenFile)
+		}
+		keys[token] = "default"
+	}
+	return keys, nil
+}
+
+// sweepCache removes expired entries every interval until ctx is done.
+func sweepCache(ctx context.Context, srv *cacheserver.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := srv.Sweep(ctx)
+			if err != nil {
+				slog.Warn("cache sweep failed", "err", err)
+				continue
+			}
+			if removed > 0 {
+				slog.Info("removed expired cache entries", "count", removed)
+			}
+		}
+	}
+}
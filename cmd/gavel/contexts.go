@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/chris-regnier/gavel/internal/config"
+	gavelcontext "github.com/chris-regnier/gavel/internal/context"
+)
+
+// contextResolver selects policies' additional_contexts, relative to the
+// working directory, within the context budget. With context.embedding_model
+// set, equally close files are ranked by similarity using the Ollama server
+// from provider.ollama.base_url.
+func contextResolver(cfg *config.Config) *gavelcontext.Resolver {
+	opts := []gavelcontext.ResolverOption{gavelcontext.WithMaxBytes(cfg.Context.Budget())}
+	if cfg.Context.EmbeddingModel != "" {
+		baseURL := cfg.Provider.Ollama.BaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		opts = append(opts, gavelcontext.WithEmbedder(gavelcontext.NewOllamaEmbedder(baseURL, cfg.Context.EmbeddingModel)))
+	}
+	return gavelcontext.NewResolver(".", opts...)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/metrics"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// costTracker prices the comprehensive-tier calls of one analyze run and
+// enforces --max-cost.
+type costTracker struct {
+	prices    metrics.PriceTable
+	budget    *metrics.Budget
+	collector *metrics.Collector
+}
+
+// newCostTracker returns nil when there is nothing to track: no --max-cost
+// and no pricing configured. With --max-cost every provider and model the
+// enabled policies use must have a price, since spend on an unpriced model
+// cannot be bounded.
+func newCostTracker(cfg *config.Config, maxCost float64) (*costTracker, error) {
+	if maxCost < 0 {
+		return nil, fmt.Errorf("--max-cost must not be negative, got %g", maxCost)
+	}
+	if maxCost == 0 && len(cfg.Pricing) == 0 {
+		return nil, nil
+	}
+
+	t := &costTracker{prices: metrics.NewPriceTable(cfg.Pricing), collector: metrics.NewCollector()}
+	if maxCost > 0 {
+		t.budget = metrics.NewBudget(maxCost)
+	}
+
+	used := map[string]config.ProviderConfig{}
+	for _, pc := range append([]config.ProviderConfig{cfg.Provider}, enabledProviders(cfg)...) {
+		used[pc.Name+"/"+pc.ModelName()] = pc
+	}
+	var unpriced []string
+	for key, pc := range used {
+		if _, ok := t.prices.Lookup(pc.Name, pc.ModelName()); !ok {
+			unpriced = append(unpriced, key)
+		}
+	}
+	sort.Strings(unpriced)
+	for _, key := range unpriced {
+		if t.budget != nil {
+			return nil, fmt.Errorf("--max-cost: no price for %s; add it under pricing in policies.yaml", key)
+		}
+		slog.Warn("no price configured; its calls are counted as free", "model", key)
+	}
+	return t, nil
+}
+
+// wrap returns client with its calls to pc's provider and model priced and
+// counted against the budget.
+func (t *costTracker) wrap(client analyzer.BAMLClient, pc config.ProviderConfig) analyzer.BAMLClient {
+	price, _ := t.prices.Lookup(pc.Name, pc.ModelName())
+	return analyzer.NewCostTrackingClient(client, pc.Name, pc.ModelName(), price, t.budget, t.collector)
+}
+
+// annotate records the run's estimated cost on the SARIF run as
+// gavel/cost. When the budget ran out the run is also marked gavel/partial
+// with a warning naming how many files the comprehensive tier skipped.
+func (t *costTracker) annotate(log *sarif.Log, skippedFiles int64) {
+	stats := t.collector.GetStats()
+	cost := map[string]interface{}{"estimatedUSD": stats.TotalCostUSD}
+	byModel := make(map[string]interface{}, len(stats.ByModel))
+	for key, ms := range stats.ByModel {
+		byModel[key] = map[string]interface{}{
+			"calls":        ms.Calls,
+			"tokensIn":     ms.TokensIn,
+			"tokensOut":    ms.TokensOut,
+			"estimatedUSD": ms.CostUSD,
+		}
+	}
+	cost["byModel"] = byModel
+	if t.budget != nil {
+		cost["maxUSD"] = t.budget.Limit()
+	}
+
+	warning := t.warning(skippedFiles)
+	if warning != "" {
+		slog.Warn("cost budget exceeded; results are partial", "max_cost", t.budget.Limit(), "skipped_files", skippedFiles)
+	}
+	if len(log.Runs) == 0 {
+		return
+	}
+	props := log.Runs[0].Properties
+	if props == nil {
+		props = make(map[string]interface{})
+		log.Runs[0].Properties = props
+	}
+	props["gavel/cost"] = cost
+	if warning != "" {
+		props["gavel/partial"] = true
+		props["gavel/warnings"] = []string{warning}
+	}
+}
+
+// summarize adds the estimated cost, and the partial-results warning if
+// the budget ran out, to the analyze summary.
+func (t *costTracker) summarize(summary map[string]interface{}, skippedFiles int64) {
+	summary["estimated_cost_usd"] = t.collector.GetStats().TotalCostUSD
+	if warning := t.warning(skippedFiles); warning != "" {
+		summary["budget_exceeded"] = warning
+	}
+}
+
+func (t *costTracker) warning(skippedFiles int64) string {
+	if t.budget == nil || !t.budget.Exceeded() {
+		return ""
+	}
+	return fmt.Sprintf("comprehensive-tier analysis stopped at the $%.2f cost budget; %d file(s) were not fully analyzed by the LLM, so results are partial", t.budget.Limit(), skippedFiles)
+}
+
+// enabledProviders returns the provider settings of each enabled policy.
+func enabledProviders(cfg *config.Config) []config.ProviderConfig {
+	var pcs []config.ProviderConfig
+	for _, p := range cfg.Policies {
+		if p.Enabled {
+			pcs = append(pcs, cfg.ProviderFor(p))
+		}
+	}
+	return pcs
+}
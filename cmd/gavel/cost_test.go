@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+type fixedFindingClient struct{}
+
+func (fixedFindingClient) AnalyzeCode(context.Context, string, string, string, string) ([]analyzer.Finding, error) {
+	return []analyzer.Finding{{RuleID: "r", Level: "warning", StartLine: 1}}, nil
+}
+
+func costConfig() *config.Config {
+	return &config.Config{
+		Provider: config.ProviderConfig{Name: "anthropic", Anthropic: config.AnthropicConfig{Model: "claude-sonnet"}},
+		Policies: map[string]config.Policy{
+			"style":   {Enabled: true},
+			"secrets": {Enabled: true, Provider: "openai"},
+			"off":     {Enabled: false, Provider: "bedrock"},
+		},
+		Pricing: map[string]config.ModelPrice{"anthropic": {InputPer1K: 0.003, OutputPer1K: 0.015}},
+	}
+}
+
+func TestNewCostTracker(t *testing.T) {
+	tracker, err := newCostTracker(&config.Config{}, 0)
+	require.NoError(t, err)
+	assert.Nil(t, tracker, "nothing to track without --max-cost or pricing")
+
+	_, err = newCostTracker(costConfig(), -1)
+	assert.ErrorContains(t, err, "must not be negative")
+
+	_, err = newCostTracker(costConfig(), 5)
+	assert.ErrorContains(t, err, "no price for openai/", "an enabled policy's model needs a price under --max-cost")
+
+	cfg := costConfig()
+	cfg.Pricing["openai"] = config.ModelPrice{InputPer1K: 0.001}
+	tracker, err = newCostTracker(cfg, 5)
+	require.NoError(t, err)
+	assert.NotNil(t, tracker.budget)
+
+	captureLogs(t)
+	tracker, err = newCostTracker(costConfig(), 0)
+	require.NoError(t, err, "unpriced models are only a warning without --max-cost")
+	assert.Nil(t, tracker.budget)
+}
+
+func TestCostTracker_Annotate(t *testing.T) {
+	cfg := costConfig()
+	cfg.Pricing["openai"] = config.ModelPrice{}
+	tracker, err := newCostTracker(cfg, 0.0001)
+	require.NoError(t, err)
+	captureLogs(t)
+
+	client := tracker.wrap(fixedFindingClient{}, cfg.Provider)
+	_, err = client.AnalyzeCode(context.Background(), "code", "policies", "persona", "")
+	require.ErrorIs(t, err, analyzer.ErrBudgetExceeded)
+
+	log := sarif.Assemble(nil, nil, "files", "code-reviewer")
+	tracker.annotate(log, 3)
+	props := log.Runs[0].Properties
+	assert.Equal(t, true, props["gavel/partial"])
+	require.Len(t, props["gavel/warnings"], 1)
+	assert.Contains(t, props["gavel/warnings"].([]string)[0], "3 file(s)")
+	cost := props["gavel/cost"].(map[string]interface{})
+	assert.Equal(t, 0.0001, cost["maxUSD"])
+
+	summary := map[string]interface{}{}
+	tracker.summarize(summary, 3)
+	assert.Contains(t, summary, "estimated_cost_usd")
+	assert.Contains(t, summary["budget_exceeded"], "results are partial")
+}
@@ -1,3 +1,5 @@
+//go:build !nobaml
+
 package main
 
 import (
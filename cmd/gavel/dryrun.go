@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+)
+
+// dryRunReport is what analyze --dry-run prints: everything the LLM tiers
+// would be sent, without sending it.
+type dryRunReport struct {
+	Persona           string       `json:"persona"`
+	PersonaPrompt     string       `json:"persona_prompt,omitempty"`
+	Provider          string       `json:"provider,omitempty"`
+	Model             string       `json:"model,omitempty"`
+	LLMSkipped        string       `json:"llm_skipped,omitempty"`
+	AdditionalContext string       `json:"additional_context,omitempty"`
+	Files             []dryRunFile `json:"files"`
+	Totals            dryRunTotals `json:"totals"`
+}
+
+// dryRunFile is the plan for one input file.
+type dryRunFile struct {
+	File            string                 `json:"file"`
+	Rules           dryRunRules            `json:"rules"`
+	Comprehensive   []analyzer.PlannedCall `json:"comprehensive,omitempty"`
+	EstimatedTokens int                    `json:"estimated_tokens"`
+	Error           string                 `json:"error,omitempty"`
+}
+
+// dryRunRules counts the rules and policies each tier applies to a file.
+type dryRunRules struct {
+	Instant       int `json:"instant"`
+	Comprehensive int `json:"comprehensive"`
+}
+
+type dryRunTotals struct {
+	Files           int `json:"files"`
+	Requests        int `json:"requests"`
+	Cached          int `json:"cached"`
+	EstimatedTokens int `json:"estimated_tokens"`
+}
+
+// dryRun plans analysis of artifacts the way runAnalyze would perform it.
+// Instant-tier rules run locally so applied records which ones apply to
+// each file; LLM requests are only planned. changed and rng select the
+// same windows as --git-range, --staged and --range; rng is unset (zero)
+// without --range.
+func dryRun(ctx context.Context, ta *analyzer.TieredAnalyzer, applied *analyzer.AppliedRules, cfg *config.Config, personaPrompt, llmSkipped string, artifacts []input.Artifact, changed []input.ChangedFile, rng lineRange) *dryRunReport {
+	report := &dryRunReport{
+		Persona:           cfg.Persona,
+		PersonaPrompt:     personaPrompt,
+		LLMSkipped:        llmSkipped,
+		AdditionalContext: ta.AdditionalContext(),
+		Files:             []dryRunFile{},
+	}
+	if llmSkipped == "" {
+		report.Provider, report.Model = cfg.Provider.Name, cfg.Provider.ModelName()
+	}
+
+	windows := make(map[string][]input.LineRange)
+	for _, cf := range changed {
+		windows[cf.Artifact.Path] = cf.Changed
+	}
+
+	for _, art := range artifacts {
+		ta.RunPatternMatching(ctx, art)
+
+		f := dryRunFile{File: art.Path}
+		var err error
+		switch {
+		case rng.start > 0:
+			f.Comprehensive, err = ta.PlanRanges(ctx, art, []input.LineRange{{Start: rng.start, End: rng.end}}, analyzer.DefaultRangeContext, cfg.Policies, personaPrompt)
+		case changed != nil:
+			f.Comprehensive, err = ta.PlanRanges(ctx, art, windows[art.Path], analyzer.DefaultRangeContext, cfg.Policies, personaPrompt)
+		default:
+			f.Comprehensive = ta.PlanComprehensive(ctx, art, cfg.Policies, personaPrompt)
+		}
+		if err != nil {
+			f.Error = err.Error()
+		}
+
+		policies := make(map[string]bool)
+		for _, c := range f.Comprehensive {
+			for _, name := range c.Policies {
+				policies[name] = true
+			}
+			if c.Cached {
+				report.Totals.Cached += c.Requests
+				continue
+			}
+			f.EstimatedTokens += c.EstimatedTokens
+			report.Totals.Requests += c.Requests
+		}
+		f.Rules.Comprehensive = len(policies)
+		report.Totals.EstimatedTokens += f.EstimatedTokens
+		report.Files = append(report.Files, f)
+	}
+
+	instant := make(map[string]int)
+	for _, af := range applied.Files() {
+		instant[af.File] = len(af.Rules)
+	}
+	for i := range report.Files {
+		report.Files[i].Rules.Instant = instant[report.Files[i].File]
+	}
+	report.Totals.Files = len(report.Files)
+	return report
+}
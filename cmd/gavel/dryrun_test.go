@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+type unusedClient struct{ called bool }
+
+func (c *unusedClient) AnalyzeCode(ctx context.Context, code, policies, persona, additional string) ([]analyzer.Finding, error) {
+	c.called = true
+	return nil, nil
+}
+
+func TestDryRun_PlansWithoutCallingProvider(t *testing.T) {
+	loaded := []rules.Rule{
+		{ID: "go-only", Type: rules.RuleTypeRegex, Pattern: regexp.MustCompile(`TODO`), Languages: []string{"go"}, Level: "note", Message: "todo"},
+		{ID: "everywhere", Type: rules.RuleTypeRegex, Pattern: regexp.MustCompile(`FIXME`), Level: "note", Message: "fixme"},
+	}
+	cfg := &config.Config{
+		Persona:  "code-reviewer",
+		Provider: config.ProviderConfig{Name: "ollama", Ollama: config.OllamaConfig{Model: "qwen"}},
+		Policies: map[string]config.Policy{
+			"errors": {Severity: "warning", Instruction: "Check errors", Enabled: true},
+		},
+	}
+	client := &unusedClient{}
+	applied := analyzer.NewAppliedRules()
+	ta := analyzer.NewTieredAnalyzer(client,
+		analyzer.WithInstantPatterns(loaded),
+		analyzer.WithAppliedRules(applied),
+		analyzer.WithDiffContext("commit: fix handler"))
+
+	artifacts := []input.Artifact{
+		{Path: "a.go", Content: "package a\n", Kind: input.KindFile},
+		{Path: "b.py", Content: "x = 1\n", Kind: input.KindFile},
+	}
+	report := dryRun(context.Background(), ta, applied, cfg, "You are a reviewer.", "", artifacts, nil, lineRange{})
+
+	assert.False(t, client.called, "dry run must not call the provider")
+	assert.Equal(t, "ollama", report.Provider)
+	assert.Equal(t, "qwen", report.Model)
+	assert.Equal(t, "You are a reviewer.", report.PersonaPrompt)
+	assert.Equal(t, "commit: fix handler", report.AdditionalContext)
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, 2, report.Files[0].Rules.Instant)
+	assert.Equal(t, 1, report.Files[1].Rules.Instant)
+	assert.Equal(t, 1, report.Files[0].Rules.Comprehensive)
+	require.Len(t, report.Files[0].Comprehensive, 1)
+	assert.Contains(t, report.Files[0].Comprehensive[0].PolicyText, "Check errors")
+	assert.Equal(t, 2, report.Totals.Requests)
+	assert.Equal(t, report.Files[0].EstimatedTokens+report.Files[1].EstimatedTokens, report.Totals.EstimatedTokens)
+}
+
+func TestDryRun_Range(t *testing.T) {
+	cfg := &config.Config{Policies: map[string]config.Policy{
+		"errors": {Severity: "warning", Instruction: "Check errors", Enabled: true},
+	}}
+	applied := analyzer.NewAppliedRules()
+	ta := analyzer.NewTieredAnalyzer(&unusedClient{}, analyzer.WithAppliedRules(applied))
+	content := ""
+	for i := 0; i < 50; i++ {
+		content += "line\n"
+	}
+	artifacts := []input.Artifact{{Path: "a.go", Content: content, Kind: input.KindFile}}
+
+	report := dryRun(context.Background(), ta, applied, cfg, "", "", artifacts, nil, lineRange{start: 30, end: 31})
+	require.Len(t, report.Files[0].Comprehensive, 1)
+	assert.Equal(t, 30-analyzer.DefaultRangeContext, report.Files[0].Comprehensive[0].StartLine)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/explain"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+var (
+	flagExplainResult    string
+	flagExplainOutput    string
+	flagExplainPolicyDir string
+	flagExplainRoot      string
+	flagExplainRule      string
+	flagExplainFormat    string
+)
+
+func init() {
+	explainCmd := &cobra.Command{
+		Use:   "explain <fingerprint|file:line>",
+		Short: "Ask the LLM for an in-depth explanation of a stored finding",
+		Long: `Explain one finding from a stored analysis in depth. The finding is named by its
+fingerprint (or a unique prefix of at least 6 characters) or by file:line. Its file is
+re-read from --root and sent to the configured LLM with the finding, which returns an
+extended explanation, an exploitability assessment, and a concrete patch shown as a diff.
+
+The patch is only displayed; nothing is written to disk.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runExplain,
+	}
+
+	explainCmd.Flags().StringVar(&flagExplainResult, "result", "", "Analysis result ID to take the finding from (default: most recent)")
+	explainCmd.Flags().StringVar(&flagExplainOutput, "output", ".gavel/results", "Directory containing analysis results")
+	explainCmd.Flags().StringVar(&flagExplainPolicyDir, "policies", ".gavel", "Directory containing policies.yaml")
+	explainCmd.Flags().StringVar(&flagExplainRoot, "root", ".", "Directory that relative file paths in the SARIF are resolved against")
+	explainCmd.Flags().StringVar(&flagExplainRule, "rule", "", "Only match findings of this rule ID, to pick one of several findings on a line")
+	explainCmd.Flags().StringVar(&flagExplainFormat, "format", "pretty", "Output format: pretty or json")
+
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if flagExplainFormat != "pretty" && flagExplainFormat != "json" {
+		return fmt.Errorf("unknown format %q: use pretty or json", flagExplainFormat)
+	}
+
+	fs := store.NewFileStore(flagExplainOutput)
+	resultID := flagExplainResult
+	if resultID == "" {
+		ids, err := fs.List(ctx)
+		if err != nil {
+			return fmt.Errorf("listing results: %w", err)
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("no analysis results found in %s", flagExplainOutput)
+		}
+		resultID = ids[0] // List returns newest first
+	}
+	sarifLog, err := fs.ReadSARIF(ctx, resultID)
+	if err != nil {
+		return fmt.Errorf("reading SARIF for %s: %w", resultID, err)
+	}
+
+	result, err := explain.Find(sarifLog, args[0], flagExplainRule)
+	if err != nil {
+		return fmt.Errorf("result %s: %w", resultID, err)
+	}
+	uri := ""
+	if len(result.Locations) > 0 {
+		uri = result.Locations[0].PhysicalLocation.ArtifactLocation.URI
+	}
+	if uri == "" {
+		return fmt.Errorf("finding %s has no file location to explain", result.RuleID)
+	}
+	path := strings.TrimPrefix(uri, "file://")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(flagExplainRoot, filepath.FromSlash(path))
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
+	projectConfig := flagExplainPolicyDir + "/policies.yaml"
+	cfg, err := config.LoadTiered(machineConfig, projectConfig)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := registerPersonas(flagExplainPolicyDir); err != nil {
+		return err
+	}
+	if err := cfg.ValidateSettings(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	client, personaPrompt, _, err := prepareLLM(ctx, cfg, true, initLLM)
+	if err != nil {
+		return err
+	}
+
+	req := explain.NewRequest(result, ruleDescriptor(sarifLog, result.RuleID), string(source))
+	findings, err := client.AnalyzeCode(ctx, req.Code, req.Policy, personaPrompt, req.Context)
+	if err != nil {
+		return fmt.Errorf("explaining %s: %w", result.RuleID, err)
+	}
+	answers := make([]explain.Answer, len(findings))
+	for i, f := range findings {
+		answers[i] = explain.Answer{
+			RuleID:             f.RuleID,
+			Level:              f.Level,
+			Message:            f.Message,
+			Explanation:        f.Explanation,
+			Recommendation:     f.Recommendation,
+			Confidence:         f.Confidence,
+			StartLine:          f.StartLine,
+			EndLine:            f.EndLine,
+			FixReplacementText: f.FixReplacementText,
+		}
+	}
+	explanation, err := req.Explain(answers, flagExplainRoot)
+	if err != nil {
+		return err
+	}
+
+	if flagExplainFormat == "json" {
+		out, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Print(explain.Render(explanation, source))
+	return nil
+}
+
+// ruleDescriptor returns the rule a result was reported under from the
+// run's driver, or nil if the run does not describe it.
+func ruleDescriptor(log *sarif.Log, ruleID string) *sarif.ReportingDescriptor {
+	if len(log.Runs) == 0 {
+		return nil
+	}
+	rules := log.Runs[0].Tool.Driver.Rules
+	for i := range rules {
+		if rules[i].ID == ruleID {
+			return &rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/fix"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+	"github.com/chris-regnier/gavel/internal/suppression"
+)
+
+var (
+	flagFixResult      string
+	flagFixOutput      string
+	flagFixPolicyDir   string
+	flagFixRoot        string
+	flagFixRulesDir    string
+	flagFixInteractive bool
+	flagFixGenerate    bool
+	flagFixVerify      bool
+)
+
+func init() {
+	fixCmd := &cobra.Command{
+		Use:   "fix",
+		Short: "Apply the structured fixes attached to analysis findings",
+		Long: `Apply the machine-applicable fixes recorded in a stored SARIF analysis to the files on disk.
+By default every fix from the most recent analysis is applied. With --interactive, each fix is
+shown as a diff and can be accepted or skipped before anything is written.
+
+With --generate, the configured LLM is asked to write a patch for each finding that carries
+no fix; generated patches are offered after the recorded ones.
+
+Fixes that overlap a fix applied before them, or whose finding's code has changed since the
+analysis, are not applied and are listed as conflicts. After writing, the instant tier is
+re-run on the modified files to confirm each fixed finding is gone.`,
+		RunE: runFix,
+	}
+
+	fixCmd.Flags().StringVar(&flagFixResult, "result", "", "Analysis result ID to take fixes from (default: most recent)")
+	fixCmd.Flags().StringVar(&flagFixOutput, "output", ".gavel/results", "Directory containing analysis results")
+	fixCmd.Flags().StringVar(&flagFixPolicyDir, "policies", ".gavel", "Directory containing policies.yaml (suppressed findings are never fixed)")
+	fixCmd.Flags().StringVar(&flagFixRoot, "root", ".", "Directory that relative file paths in the SARIF are resolved against")
+	fixCmd.Flags().StringVar(&flagFixRulesDir, "rules-dir", "", "Directory containing custom rule YAML files for the re-check (default: <policies>/rules)")
+	fixCmd.Flags().BoolVarP(&flagFixInteractive, "interactive", "i", false, "Review each fix in a terminal UI and choose which to apply")
+	fixCmd.Flags().BoolVar(&flagFixGenerate, "generate", false, "Ask the configured LLM for patches for findings that carry no fix")
+	fixCmd.Flags().BoolVar(&flagFixVerify, "verify", true, "Re-run the instant tier on modified files to confirm the fixed findings are gone")
+
+	rootCmd.AddCommand(fixCmd)
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fs := store.NewFileStore(flagFixOutput)
+	resultID := flagFixResult
+	if resultID == "" {
+		ids, err := fs.List(ctx)
+		if err != nil {
+			return fmt.Errorf("listing results: %w", err)
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("no analysis results found in %s", flagFixOutput)
+		}
+		resultID = ids[0] // List returns newest first
+	}
+
+	sarifLog, err := fs.ReadSARIF(ctx, resultID)
+	if err != nil {
+		return fmt.Errorf("reading SARIF for %s: %w", resultID, err)
+	}
+
+	// Re-apply current suppressions so newly suppressed findings are skipped
+	supps, err := suppression.Load(filepath.Dir(flagFixPolicyDir))
+	if err != nil {
+		slog.Warn("failed to load suppressions", "err", err)
+	}
+	suppression.Apply(supps, sarifLog)
+
+	var cfg *config.Config
+	if flagFixGenerate || flagFixVerify {
+		machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
+		projectConfig := flagFixPolicyDir + "/policies.yaml"
+		cfg, err = config.LoadTiered(machineConfig, projectConfig)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+	}
+
+	candidates := fix.Candidates(sarifLog)
+	generated := 0
+	if flagFixGenerate {
+		patches, err := generateFixes(ctx, cfg, sarifLog)
+		if err != nil {
+			return err
+		}
+		generated = len(patches)
+		candidates = append(candidates, patches...)
+	}
+
+	selected := candidates
+	if flagFixInteractive && len(candidates) > 0 {
+		selected, err = reviewFixes(candidates)
+		if err != nil {
+			return err
+		}
+	}
+
+	applicable, conflicts := fix.Check(flagFixRoot, selected)
+	fixes := make([]sarif.Fix, len(applicable))
+	for i, c := range applicable {
+		fixes[i] = c.Fix
+	}
+	applied, err := fix.Apply(flagFixRoot, fixes)
+	if err != nil {
+		return fmt.Errorf("applying fixes: %w", err)
+	}
+
+	files := applied.Files
+	if files == nil {
+		files = []string{}
+	}
+	conflicted := make([]map[string]interface{}, len(conflicts))
+	for i, c := range conflicts {
+		conflicted[i] = map[string]interface{}{"rule_id": c.Candidate.Result.RuleID, "reason": c.Reason}
+		if len(c.Candidate.Result.Locations) > 0 {
+			loc := c.Candidate.Result.Locations[0].PhysicalLocation
+			conflicted[i]["file"] = loc.ArtifactLocation.URI
+			conflicted[i]["line"] = loc.Region.StartLine
+		}
+	}
+	summary := map[string]interface{}{
+		"id":           resultID,
+		"available":    len(candidates),
+		"generated":    generated,
+		"applied":      len(applicable),
+		"skipped":      len(candidates) - len(selected),
+		"conflicts":    conflicted,
+		"replacements": applied.Replacements,
+		"files":        files,
+	}
+	if flagFixVerify && len(applicable) > 0 {
+		verified, err := verifyFixes(ctx, cfg, applicable)
+		if err != nil {
+			return err
+		}
+		summary["verification"] = verified
+	}
+	out, _ := json.MarshalIndent(summary, "", "  ")
+	fmt.Println(string(out))
+	return nil
+}
+
+// generateFixes asks the LLM to write fixes for the findings in log that
+// carry none, one call per file. Files outside --root or that cannot be
+// read are skipped.
+func generateFixes(ctx context.Context, cfg *config.Config, log *sarif.Log) ([]fix.Candidate, error) {
+	order, byFile := fix.Unfixed(log)
+	if len(order) == 0 {
+		return nil, nil
+	}
+	if err := registerPersonas(flagFixPolicyDir); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateSettings(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	client, personaPrompt, _, err := prepareLLM(ctx, cfg, true, initLLM)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []fix.Candidate
+	for _, uri := range order {
+		path, err := fix.ResolvePath(flagFixRoot, uri)
+		if err != nil {
+			slog.Warn("file is outside --root; no fixes generated for it", "file", uri, "err", err)
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			slog.Warn("cannot read file; no fixes generated for it", "file", uri, "err", err)
+			continue
+		}
+		req := fix.NewPatchRequest(uri, byFile[uri], string(content))
+		findings, err := client.AnalyzeCode(ctx, req.Code, req.Policy, personaPrompt, req.Context)
+		if err != nil {
+			return nil, fmt.Errorf("generating fixes for %s: %w", uri, err)
+		}
+		patches := make([]fix.Patch, len(findings))
+		for i, f := range findings {
+			patches[i] = fix.Patch{
+				ID:                 f.RuleID,
+				StartLine:          f.StartLine,
+				EndLine:            f.EndLine,
+				FixReplacementText: f.FixReplacementText,
+				Recommendation:     f.Recommendation,
+			}
+		}
+		candidates = append(candidates, req.Candidates(patches)...)
+	}
+	return candidates, nil
+}
+
+// verifyFixes re-runs the instant tier on the files the applied fixes
+// changed and reports whether each fixed finding is gone.
+func verifyFixes(ctx context.Context, cfg *config.Config, applied []fix.Candidate) ([]fix.Verification, error) {
+	userRulesDir := os.ExpandEnv("$HOME/.config/gavel/rules")
+	projectRulesDir := filepath.Join(flagFixPolicyDir, "rules")
+	if flagFixRulesDir != "" {
+		projectRulesDir = flagFixRulesDir
+	}
+	loadedRules, err := rules.LoadRules(userRulesDir, projectRulesDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading rules: %w", err)
+	}
+	tiered := analyzer.NewTieredAnalyzer(nil,
+		analyzer.WithInstantPatterns(loadedRules),
+		analyzer.WithSecretScanner(analyzer.SecretScanner(cfg.Secrets)),
+	)
+
+	rerun := map[string][]sarif.Result{}
+	for _, c := range applied {
+		for _, ch := range c.Fix.ArtifactChanges {
+			uri := ch.ArtifactLocation.URI
+			if _, done := rerun[uri]; done {
+				continue
+			}
+			path, err := fix.ResolvePath(flagFixRoot, uri)
+			if err != nil {
+				slog.Warn("file is outside --root; not re-checked", "file", uri, "err", err)
+				continue
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("re-checking %s: %w", uri, err)
+			}
+			rerun[uri] = tiered.RunPatternMatching(ctx, input.Artifact{Path: uri, Content: string(content), Kind: input.KindFile})
+		}
+	}
+	return fix.Verify(applied, rerun), nil
+}
+
+// reviewFixes runs the interactive fix TUI and returns the accepted
+// candidates. Candidates that cannot be previewed are left out of the
+// review, so they are reported as skipped.
+func reviewFixes(candidates []fix.Candidate) ([]fix.Candidate, error) {
+	reviewable, previews := previewFixes(flagFixRoot, candidates)
+	if len(reviewable) == 0 {
+		return nil, nil
+	}
+
+	final, err := tea.NewProgram(newFixModel(reviewable, previews), tea.WithOutput(os.Stderr)).Run()
+	if err != nil {
+		return nil, fmt.Errorf("running fix review: %w", err)
+	}
+	return final.(fixModel).Accepted(), nil
+}
+
+// previewFixes renders a preview of each candidate's fix against root. It
+// returns the candidates that could be previewed alongside their previews,
+// warning about and dropping the rest, such as fixes for missing files or
+// files outside root.
+func previewFixes(root string, candidates []fix.Candidate) ([]fix.Candidate, []string) {
+	var reviewable []fix.Candidate
+	var previews []string
+	for _, c := range candidates {
+		p, err := fix.Preview(root, c.Fix)
+		if err != nil {
+			slog.Warn("cannot preview fix; skipping it", "rule", c.Result.RuleID, "err", err)
+			continue
+		}
+		reviewable = append(reviewable, c)
+		previews = append(previews, p)
+	}
+	return reviewable, previews
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/chris-regnier/gavel/internal/fix"
+)
+
+// fixDecision records what the user chose for one candidate.
+type fixDecision int
+
+const (
+	fixPending fixDecision = iota
+	fixAccepted
+	fixSkipped
+)
+
+// fixModel walks through autofixable findings one at a time, showing the
+// proposed diff and recording accept/skip decisions. It never writes files;
+// the caller applies Accepted() once the program exits.
+type fixModel struct {
+	candidates []fix.Candidate
+	previews   []string
+	decisions  []fixDecision
+	index      int
+	done       bool
+	cancelled  bool
+	width      int
+}
+
+func newFixModel(candidates []fix.Candidate, previews []string) fixModel {
+	return fixModel{
+		candidates: candidates,
+		previews:   previews,
+		decisions:  make([]fixDecision, len(candidates)),
+		done:       len(candidates) == 0,
+	}
+}
+
+func (m fixModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m fixModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.done {
+			return m, tea.Quit
+		}
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.cancelled = true
+			m.done = true
+			return m, tea.Quit
+		case "q":
+			// Stop reviewing; fixes accepted so far are still applied.
+			m.done = true
+			return m, tea.Quit
+		case "y", "enter":
+			return m.decide(fixAccepted)
+		case "n", "s":
+			return m.decide(fixSkipped)
+		case "a", "A":
+			for i := m.index; i < len(m.decisions); i++ {
+				m.decisions[i] = fixAccepted
+			}
+			m.index = len(m.candidates)
+			m.done = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// decide records d for the current candidate and advances.
+func (m fixModel) decide(d fixDecision) (tea.Model, tea.Cmd) {
+	m.decisions[m.index] = d
+	m.index++
+	if m.index >= len(m.candidates) {
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// Accepted returns the candidates the user accepted, in review order. It is
+// empty when the session was cancelled.
+func (m fixModel) Accepted() []fix.Candidate {
+	if m.cancelled {
+		return nil
+	}
+	var accepted []fix.Candidate
+	for i, d := range m.decisions {
+		if d == fixAccepted {
+			accepted = append(accepted, m.candidates[i])
+		}
+	}
+	return accepted
+}
+
+func (m fixModel) View() string {
+	if m.done {
+		if m.cancelled {
+			return errorStyle.Render("Cancelled — no fixes applied") + "\n"
+		}
+		return successStyle.Render(fmt.Sprintf("%d of %d fixes accepted", len(m.Accepted()), len(m.candidates))) + "\n"
+	}
+
+	c := m.candidates[m.index]
+	header := titleStyle.Render(fmt.Sprintf("Fix %d of %d", m.index+1, len(m.candidates)))
+
+	location := ""
+	if len(c.Result.Locations) > 0 {
+		loc := c.Result.Locations[0].PhysicalLocation
+		location = fmt.Sprintf("%s:%d", loc.ArtifactLocation.URI, loc.Region.StartLine)
+	}
+	finding := descriptionStyle.Render(fmt.Sprintf("[%s] %s %s\n%s", c.Result.Level, c.Result.RuleID, location, c.Result.Message.Text))
+
+	desc := ""
+	if c.Fix.Description.Text != "" {
+		desc = "\n\n" + descriptionStyle.Render(c.Fix.Description.Text)
+	}
+
+	diff := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(0, 1).
+		Render(renderFixDiff(m.previews[m.index]))
+
+	help := helpStyle.Render("y/enter: accept • n/s: skip • a: accept all remaining • q: finish • esc: cancel")
+
+	return fmt.Sprintf("%s\n\n%s%s\n\n%s\n%s", header, finding, desc, diff, help)
+}
+
+// renderFixDiff colours removed and inserted lines of a fix.Preview.
+func renderFixDiff(preview string) string {
+	lines := strings.Split(strings.TrimSuffix(preview, "\n"), "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "---"), strings.HasPrefix(l, "@@"):
+			lines[i] = helpStyle.UnsetMarginTop().Render(l)
+		case strings.HasPrefix(l, "-"):
+			lines[i] = errorStyle.UnsetBold().Render(l)
+		case strings.HasPrefix(l, "+"):
+			lines[i] = successStyle.UnsetBold().Render(l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/fix"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func fixCandidates(ids ...string) ([]fix.Candidate, []string) {
+	var candidates []fix.Candidate
+	var previews []string
+	for _, id := range ids {
+		candidates = append(candidates, fix.Candidate{
+			Result: sarif.Result{RuleID: id, Level: "warning", Message: sarif.Message{Text: id + " issue"}},
+			Fix: sarif.Fix{ArtifactChanges: []sarif.ArtifactChange{{
+				ArtifactLocation: sarif.ArtifactLocation{URI: id + ".go"},
+				Replacements: []sarif.Replacement{{
+					DeletedRegion:   sarif.Region{StartLine: 1, EndLine: 1},
+					InsertedContent: &sarif.ArtifactContent{Text: "fixed"},
+				}},
+			}}},
+		})
+		previews = append(previews, "--- "+id+".go\n@@ lines 1-1 @@\n-old\n+fixed\n")
+	}
+	return candidates, previews
+}
+
+// sendKeys feeds key presses to the model and returns the final model and
+// whether the last update asked the program to quit.
+func sendKeys(m fixModel, keys ...string) (fixModel, bool) {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		var next tea.Model
+		next, cmd = m.Update(msg)
+		m = next.(fixModel)
+	}
+	quit := cmd != nil && cmd() == tea.Quit()
+	return m, quit
+}
+
+func acceptedIDs(m fixModel) []string {
+	var ids []string
+	for _, c := range m.Accepted() {
+		ids = append(ids, c.Result.RuleID)
+	}
+	return ids
+}
+
+func TestFixModel_AcceptAndSkip(t *testing.T) {
+	m := newFixModel(fixCandidates("r1", "r2", "r3"))
+
+	m, quit := sendKeys(m, "y", "n")
+	assert.False(t, quit, "should keep reviewing until every fix is decided")
+	assert.Contains(t, m.View(), "Fix 3 of 3")
+
+	m, quit = sendKeys(m, "enter")
+	assert.True(t, quit)
+	assert.Equal(t, []string{"r1", "r3"}, acceptedIDs(m))
+}
+
+func TestFixModel_AcceptAllRemaining(t *testing.T) {
+	m := newFixModel(fixCandidates("r1", "r2", "r3"))
+
+	m, quit := sendKeys(m, "s", "a")
+	assert.True(t, quit)
+	assert.Equal(t, []string{"r2", "r3"}, acceptedIDs(m))
+}
+
+func TestFixModel_FinishEarlyKeepsAccepted(t *testing.T) {
+	m := newFixModel(fixCandidates("r1", "r2", "r3"))
+
+	m, quit := sendKeys(m, "y", "q")
+	assert.True(t, quit)
+	assert.Equal(t, []string{"r1"}, acceptedIDs(m))
+}
+
+func TestFixModel_CancelDiscardsAccepted(t *testing.T) {
+	m := newFixModel(fixCandidates("r1", "r2"))
+
+	m, quit := sendKeys(m, "y", "esc")
+	assert.True(t, quit)
+	assert.Empty(t, m.Accepted())
+	assert.Contains(t, m.View(), "Cancelled")
+}
+
+func TestFixModel_ViewShowsFindingAndDiff(t *testing.T) {
+	m := newFixModel(fixCandidates("r1"))
+	view := m.View()
+
+	require.Contains(t, view, "Fix 1 of 1")
+	assert.Contains(t, view, "r1 issue")
+	assert.Contains(t, view, "+fixed")
+	assert.Contains(t, view, "-old")
+}
+
+func TestPreviewFixes_SkipsUnpreviewable(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "A.go"), []byte("old\n"), 0644))
+	candidates, _ := fixCandidates("A", "B", "C")
+	candidates[2].Fix.ArtifactChanges[0].ArtifactLocation.URI = "../C.go"
+
+	reviewable, previews := previewFixes(root, candidates)
+	require.Len(t, reviewable, 1, "the missing file and the file outside root should be skipped")
+	assert.Equal(t, "A", reviewable[0].Result.RuleID)
+	assert.Equal(t, []string{"--- A.go\n@@ lines 1-1 @@\n-old\n+fixed\n"}, previews)
+}
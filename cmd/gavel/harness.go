@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -76,10 +77,14 @@ func runHarness(cmd *cobra.Command, args []string) error {
 		cfg.Packages = harnessPackages
 	}
 
-	// Validate configuration
+	// Validate configuration, after loading custom personas that variants
+	// may name
 	if len(cfg.Variants) == 0 {
 		return fmt.Errorf("no variants defined in %s", variantsPath)
 	}
+	if err := registerPersonas(filepath.Dir(harnessConfigPath)); err != nil {
+		return err
+	}
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid harness configuration: %w", err)
 	}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/store"
+	"github.com/chris-regnier/gavel/internal/trends"
+)
+
+var (
+	flagHistoryOutput string
+	flagHistoryFormat string
+	flagHistorySince  string
+	flagHistoryBranch string
+	flagHistoryLimit  int
+	flagTrendsLimit   int
+)
+
+func init() {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show finding counts of stored runs over time",
+		Long: `List the stored analysis runs, newest first, with the commit each analyzed, its
+verdict and its finding counts. Suppressed, informational and baseline-resolved
+findings are not counted.
+
+The runs are indexed in a SQLite database (history.db) in the results directory,
+which is brought up to date with the stored results on every invocation.`,
+		Args: cobra.NoArgs,
+		RunE: runHistory,
+	}
+	diffCmd := &cobra.Command{
+		Use:   "diff [from] [to]",
+		Short: "Show findings introduced and fixed between two runs",
+		Long: `Compare the findings of two stored runs. Each run is named by its result ID or by a
+commit SHA (or a prefix of at least 7 characters), which picks the newest run of that
+commit. Without arguments the two most recent runs are compared; with one, that run is
+compared with the most recent.`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: runHistoryDiff,
+	}
+	trendsCmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Show per-rule finding counts across recent runs",
+		Args:  cobra.NoArgs,
+		RunE:  runTrends,
+	}
+
+	for _, cmd := range []*cobra.Command{historyCmd, diffCmd, trendsCmd} {
+		cmd.Flags().StringVar(&flagHistoryOutput, "output", ".gavel/results", "Directory containing analysis results")
+		cmd.Flags().StringVar(&flagHistoryFormat, "format", "pretty", "Output format: pretty, markdown or json")
+	}
+	for _, cmd := range []*cobra.Command{historyCmd, trendsCmd} {
+		cmd.Flags().StringVar(&flagHistorySince, "since", "", "Only runs stored since a date (2026-03-01), a number of days (30d) or a duration (12h) ago")
+		cmd.Flags().StringVar(&flagHistoryBranch, "branch", "", "Only runs of this branch")
+	}
+	historyCmd.Flags().IntVar(&flagHistoryLimit, "limit", 20, "Maximum number of runs to show (0 for all)")
+	trendsCmd.Flags().IntVar(&flagTrendsLimit, "limit", 10, "Number of most recent runs to compare")
+
+	historyCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(historyCmd, trendsCmd)
+}
+
+// openHistory opens the history index of the results directory.
+func openHistory(ctx context.Context) (*store.History, error) {
+	if flagHistoryFormat != trends.Pretty && flagHistoryFormat != trends.Markdown && flagHistoryFormat != "json" {
+		return nil, fmt.Errorf("unknown format %q: use pretty, markdown or json", flagHistoryFormat)
+	}
+	h, err := store.NewFileStore(flagHistoryOutput).History(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("opening history in %s: %w", flagHistoryOutput, err)
+	}
+	return h, nil
+}
+
+// historyQuery builds the query selected by --since and --branch, with at
+// most limit runs.
+func historyQuery(limit int) (store.HistoryQuery, error) {
+	since, err := trends.ParseSince(flagHistorySince, time.Now())
+	if err != nil {
+		return store.HistoryQuery{}, err
+	}
+	return store.HistoryQuery{Since: since, Branch: flagHistoryBranch, Limit: limit}, nil
+}
+
+// printHistory prints v as JSON, or the output of render.
+func printHistory(v interface{}, render func(format string) (string, error)) error {
+	if flagHistoryFormat == "json" {
+		out, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	out, err := render(flagHistoryFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	q, err := historyQuery(flagHistoryLimit)
+	if err != nil {
+		return err
+	}
+	h, err := openHistory(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	runs, err := h.Runs(ctx, q)
+	if err != nil {
+		return fmt.Errorf("querying history: %w", err)
+	}
+	if runs == nil {
+		runs = []store.RunRecord{}
+	}
+	return printHistory(runs, func(format string) (string, error) {
+		return trends.RenderRuns(runs, format)
+	})
+}
+
+func runHistoryDiff(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	h, err := openHistory(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	refs := args
+	if len(refs) < 2 {
+		recent, err := h.Runs(ctx, store.HistoryQuery{Limit: 2})
+		if err != nil {
+			return fmt.Errorf("querying history: %w", err)
+		}
+		if len(refs) == 0 {
+			if len(recent) < 2 {
+				return fmt.Errorf("history diff needs two runs; %s has %d", flagHistoryOutput, len(recent))
+			}
+			refs = []string{recent[1].ID, recent[0].ID}
+		} else {
+			if len(recent) == 0 {
+				return fmt.Errorf("no runs stored in %s", flagHistoryOutput)
+			}
+			refs = []string{refs[0], recent[0].ID}
+		}
+	}
+
+	d, err := h.Diff(ctx, refs[0], refs[1])
+	if err != nil {
+		return err
+	}
+	return printHistory(d, func(format string) (string, error) {
+		return trends.RenderDiff(d, format)
+	})
+}
+
+func runTrends(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if flagTrendsLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	q, err := historyQuery(flagTrendsLimit)
+	if err != nil {
+		return err
+	}
+	h, err := openHistory(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	runs, err := h.Runs(ctx, q)
+	if err != nil {
+		return fmt.Errorf("querying history: %w", err)
+	}
+	// Oldest first, so the table reads left to right in time
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	rules, err := h.RuleTrends(ctx, runs)
+	if err != nil {
+		return fmt.Errorf("querying history: %w", err)
+	}
+	if runs == nil {
+		runs = []store.RunRecord{}
+	}
+	return printHistory(map[string]interface{}{"runs": runs, "rules": rules}, func(format string) (string, error) {
+		return trends.RenderRules(rules, runs, format)
+	})
+}
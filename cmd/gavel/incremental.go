@@ -0,0 +1,33 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// incrementalStateDir is where --incremental keeps its state, next to the
+// policies and the analysis cache.
+func incrementalStateDir(policyDir string) string {
+	return filepath.Join(policyDir, "state")
+}
+
+// incrementalSettings fingerprints everything other than file content that
+// decides a file's findings: the Gavel version, the effective config, the
+// rule set, the persona prompt, and which tiers are running. A run without
+// the LLM tiers must not reuse LLM findings, nor be reused by one with them.
+func incrementalSettings(cfg *config.Config, loadedRules []rules.Rule, personaPrompt string, tiers []string) (string, error) {
+	prov, err := sarif.NewProvenance(version, cfg, loadedRules, personaPrompt)
+	if err != nil {
+		return "", fmt.Errorf("fingerprinting settings for --incremental: %w", err)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%s\n%s\n%s",
+		prov.GavelVersion, prov.ConfigHash, prov.RulesHash, prov.PersonaHash, strings.Join(tiers, ","))))
+	return hex.EncodeToString(sum[:]), nil
+}
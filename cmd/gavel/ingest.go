@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/evaluator"
+	"github.com/chris-regnier/gavel/internal/ingest"
+	"github.com/chris-regnier/gavel/internal/store"
+	"github.com/chris-regnier/gavel/internal/suppression"
+)
+
+var (
+	flagIngestSARIF      string
+	flagIngestOutput     string
+	flagIngestNoStore    bool
+	flagIngestRegoDir    string
+	flagIngestPolicyDir  string
+	flagIngestRoot       string
+	flagIngestTriage     bool
+	flagIngestConfidence float64
+)
+
+func init() {
+	ingestCmd := &cobra.Command{
+		Use:   "ingest",
+		Short: "Evaluate SARIF from other scanners with Gavel's gate, optionally triaged by the LLM",
+		Long: `Load SARIF written by another tool (such as gosec or CodeQL), merge its runs
+into one Gavel run, and evaluate it with the Rego gate like gavel judge.
+
+Each finding is tagged with the tool that reported it (gavel/tool) and given
+a gavel/confidence: its own if it has one, else its rule's precision, else
+--default-confidence. Findings reported twice at the same line, by the same
+rule or for the same CWE, are kept once.
+
+With --triage the LLM reads each file and judges its findings, replacing
+gavel/confidence with its own estimate, marking each gavel/triage confirmed
+or false-positive, and dropping findings it identifies as duplicates.
+
+The enriched SARIF and verdict are stored in --output like gavel analyze.`,
+		RunE: runIngest,
+	}
+
+	ingestCmd.Flags().StringVar(&flagIngestSARIF, "sarif", "", "SARIF file to ingest (required)")
+	ingestCmd.Flags().StringVar(&flagIngestOutput, "output", ".gavel/results", "Output directory for results")
+	ingestCmd.Flags().BoolVar(&flagIngestNoStore, "no-store", false, "Do not write results to --output; print the SARIF log in the summary instead")
+	ingestCmd.Flags().StringVar(&flagIngestRegoDir, "rego", ".gavel/rego", "Directory containing Rego policies")
+	ingestCmd.Flags().StringVar(&flagIngestPolicyDir, "policies", ".gavel", "Directory containing policies.yaml")
+	ingestCmd.Flags().StringVar(&flagIngestRoot, "root", ".", "Directory relative artifact URIs in the SARIF are resolved against when --triage reads source files")
+	ingestCmd.Flags().BoolVar(&flagIngestTriage, "triage", false, "Ask the configured LLM to score each finding and flag false positives and duplicates")
+	ingestCmd.Flags().Float64Var(&flagIngestConfidence, "default-confidence", ingest.DefaultConfidence, "gavel/confidence for findings with no confidence or rule precision of their own")
+	_ = ingestCmd.MarkFlagRequired("sarif")
+
+	rootCmd.AddCommand(ingestCmd)
+}
+
+func runIngest(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if flagIngestConfidence < 0 || flagIngestConfidence > 1 {
+		return fmt.Errorf("--default-confidence must be between 0 and 1, got %g", flagIngestConfidence)
+	}
+
+	machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
+	projectConfig := flagIngestPolicyDir + "/policies.yaml"
+	cfg, err := config.LoadTiered(machineConfig, projectConfig)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	sarifLog, loaded, err := ingest.LoadFile(flagIngestSARIF, version, ingest.WithDefaultConfidence(flagIngestConfidence))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", flagIngestSARIF, err)
+	}
+
+	summary := map[string]interface{}{
+		"tools":      loaded.Tools,
+		"ingested":   loaded.Results,
+		"duplicates": loaded.Duplicates,
+	}
+
+	if flagIngestTriage {
+		if err := registerPersonas(flagIngestPolicyDir); err != nil {
+			return err
+		}
+		if err := cfg.ValidateSettings(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		client, personaPrompt, _, err := prepareLLM(ctx, cfg, true, initLLM)
+		if err != nil {
+			return err
+		}
+		triaged, err := ingest.Triage(ctx, sarifLog, client, personaPrompt, ingest.ReadFromDir(flagIngestRoot))
+		if err != nil {
+			return err
+		}
+		summary["triage"] = triaged
+	}
+
+	supps, err := suppression.Load(filepath.Dir(flagIngestPolicyDir))
+	if err != nil {
+		slog.Warn("failed to load suppressions", "err", err)
+	}
+	suppression.Apply(supps, sarifLog)
+
+	eval, err := evaluator.NewEvaluator(ctx, flagIngestRegoDir, evaluator.WithVerdictPolicy(cfg.Verdict))
+	if err != nil {
+		return fmt.Errorf("creating evaluator: %w", err)
+	}
+	verdict, err := eval.Evaluate(ctx, sarifLog)
+	if err != nil {
+		return fmt.Errorf("evaluating: %w", err)
+	}
+
+	id, storedIn, err := storeResults(ctx, sarifLog, flagIngestOutput, flagIngestNoStore)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if err := store.NewFileStore(storedIn).WriteVerdict(ctx, id, verdict); err != nil {
+			return fmt.Errorf("storing verdict: %w", err)
+		}
+	}
+
+	summary["findings"] = len(sarifLog.Runs[0].Results)
+	summary["decision"] = verdict.Decision
+	summary["reason"] = verdict.Reason
+	recordStorage(summary, sarifLog, id, storedIn, flagIngestOutput)
+
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialising summary: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
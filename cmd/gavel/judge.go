@@ -13,8 +13,11 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/chris-regnier/gavel/internal/codeowners"
 	"github.com/chris-regnier/gavel/internal/config"
 	"github.com/chris-regnier/gavel/internal/evaluator"
+	"github.com/chris-regnier/gavel/internal/output"
+	"github.com/chris-regnier/gavel/internal/sarif"
 	"github.com/chris-regnier/gavel/internal/store"
 	"github.com/chris-regnier/gavel/internal/suppression"
 	"github.com/chris-regnier/gavel/internal/telemetry"
@@ -32,20 +35,43 @@ var (
 	flagJudgeOutput    string
 	flagJudgeRegoDir   string
 	flagJudgePolicyDir string
+	flagJudgeSortBy    string
+	flagJudgeTaxonomy  string
+	flagJudgeOwner     string
+	flagJudgeExplain   bool
+	flagJudgeFailOn    string
+	flagJudgeMaxFind   int
+	flagJudgeFormat    string
+	flagJudgeTemplate  string
 )
 
 func init() {
 	judgeCmd := &cobra.Command{
 		Use:   "judge",
 		Short: "Evaluate a SARIF analysis with Rego policies to produce a verdict",
-		Long:  `Evaluate a previously generated SARIF analysis using Rego policies. By default evaluates the most recent analysis.`,
-		RunE:  runJudge,
+		Long: `Evaluate a previously generated SARIF analysis using Rego policies. By default evaluates the most recent analysis.
+
+The verdict is printed as JSON. --format renders it in another format
+instead: json (the verdict with findings and a summary), sarif, markdown,
+pretty, gitlab, junit, html, or template, which executes the Go
+text/template given by --template. Any other name is looked up as
+<name>.tmpl in the formats directory next to policies.yaml, so
+.gavel/formats/slack.tmpl adds --format=slack.`,
+		RunE: runJudge,
 	}
 
 	judgeCmd.Flags().StringVar(&flagJudgeResult, "result", "", "Analysis result ID to evaluate (default: most recent)")
 	judgeCmd.Flags().StringVar(&flagJudgeOutput, "output", ".gavel/results", "Directory containing analysis results")
 	judgeCmd.Flags().StringVar(&flagJudgeRegoDir, "rego", ".gavel/rego", "Directory containing Rego policies")
 	judgeCmd.Flags().StringVar(&flagJudgePolicyDir, "policies", ".gavel", "Directory containing policies.yaml")
+	judgeCmd.Flags().BoolVar(&flagJudgeExplain, "explain", true, "Attach the decision trace (gate rules fired, thresholds compared, blocking findings) to the verdict; --explain=false omits it")
+	judgeCmd.Flags().StringVar(&flagJudgeTaxonomy, "taxonomy", "", "Print only relevant findings tagged with a taxonomy (cwe or owasp, optionally =ID,... e.g. owasp=A03:2021,A07) and group them by its IDs")
+	judgeCmd.Flags().StringVar(&flagJudgeOwner, "filter-owner", "", "Print only findings whose files CODEOWNERS assigns to this owner (e.g. @acme/backend), or (unowned) for files without owners; the verdict still covers every finding")
+	judgeCmd.Flags().StringVar(&flagJudgeFailOn, "fail-on", "", "Reject, and exit with status 2, when there are actionable findings at this level or above: error, warning, or note")
+	judgeCmd.Flags().IntVar(&flagJudgeMaxFind, "max-findings", -1, "Reject, and exit with status 2, when more than N actionable findings (at --fail-on level or above, if set) remain")
+	judgeCmd.Flags().StringVar(&flagJudgeFormat, "format", "", "Render the verdict as json, sarif, markdown, pretty, gitlab, junit, html, template, or a template in .gavel/formats (default: the verdict as JSON)")
+	judgeCmd.Flags().StringVar(&flagJudgeTemplate, "template", "", "Go text/template file to render the verdict with; implies --format=template")
+	judgeCmd.Flags().StringVar(&flagJudgeSortBy, "sort-by", "", "Order relevant findings in the printed verdict: default or priority (errors first, then confidence descending, then file)")
 
 	rootCmd.AddCommand(judgeCmd)
 }
@@ -54,6 +80,23 @@ func runJudge(cmd *cobra.Command, args []string) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	sortMode, err := output.ParseSortMode(flagJudgeSortBy)
+	if err != nil {
+		return fmt.Errorf("--sort-by: %w", err)
+	}
+	taxonomy, err := output.ParseTaxonomyFilter(flagJudgeTaxonomy)
+	if err != nil {
+		return fmt.Errorf("--taxonomy: %w", err)
+	}
+	threshold, err := evaluator.NewFindingThreshold(flagJudgeFailOn, flagJudgeMaxFind)
+	if err != nil {
+		return fmt.Errorf("--fail-on: %w", err)
+	}
+	formatter, err := judgeFormatter(sortMode, taxonomy)
+	if err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+
 	// Load configuration (for telemetry settings)
 	machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
 	projectConfig := flagJudgePolicyDir + "/policies.yaml"
@@ -114,7 +157,18 @@ func runJudge(cmd *cobra.Command, args []string) error {
 	suppression.Apply(supps, sarifLog)
 
 	// Evaluate with Rego
-	eval, err := evaluator.NewEvaluator(ctx, flagJudgeRegoDir)
+	var evalOpts []evaluator.EvaluatorOption
+	if flagJudgeExplain {
+		evalOpts = append(evalOpts, evaluator.WithDecisionTrace())
+	}
+	if len(cfg.Gate.Categories) > 0 {
+		evalOpts = append(evalOpts, evaluator.WithCategoryThresholds(cfg.Gate.Categories))
+	}
+	evalOpts = append(evalOpts, evaluator.WithVerdictPolicy(cfg.Verdict))
+	if threshold != nil {
+		evalOpts = append(evalOpts, evaluator.WithFindingThreshold(threshold))
+	}
+	eval, err := evaluator.NewEvaluator(ctx, flagJudgeRegoDir, evalOpts...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -140,11 +194,73 @@ func runJudge(cmd *cobra.Command, args []string) error {
 	)
 
 	// Output verdict
-	out, err := json.MarshalIndent(verdict, "", "  ")
+	if flagJudgeOwner != "" {
+		sarifLog = filterLogByOwner(sarifLog, flagJudgeOwner)
+		verdict.RelevantFindings = codeowners.Filter(verdict.RelevantFindings, flagJudgeOwner)
+	}
+	if sortMode == output.SortPriority {
+		verdict.RelevantFindings = output.SortByPriority(verdict.RelevantFindings)
+	}
+	if formatter != nil {
+		data, err := formatter.Format(&output.AnalysisOutput{Verdict: verdict, SARIFLog: sarifLog})
+		if err != nil {
+			return fmt.Errorf("formatting verdict: %w", err)
+		}
+		os.Stdout.Write(data)
+		return judgeExit(cmd, verdict, threshold)
+	}
+	var printed interface{} = verdict
+	if taxonomy.Taxonomy != "" {
+		verdict.RelevantFindings = taxonomy.Filter(verdict.RelevantFindings)
+		printed = struct {
+			*store.Verdict
+			Taxonomy []output.TaxonomyGroup `json:"taxonomy"`
+		}{verdict, taxonomy.Group(verdict.RelevantFindings)}
+	}
+	out, err := json.MarshalIndent(printed, "", "  ")
 	if err != nil {
 		return fmt.Errorf("serialising verdict: %w", err)
 	}
 	fmt.Println(string(out))
+	return judgeExit(cmd, verdict, threshold)
+}
 
+// judgeExit fails the command when a threshold was set and the verdict
+// rejects: with a threshold the caller asked for the verdict as an exit
+// status, and any reject fails, whichever rule produced it.
+func judgeExit(cmd *cobra.Command, verdict *store.Verdict, threshold *evaluator.FindingThreshold) error {
+	if threshold != nil && verdict.Decision == "reject" {
+		return gateFailed(cmd, "verdict: reject (%s)", verdict.Reason)
+	}
 	return nil
 }
+
+// filterLogByOwner returns a copy of log whose first run keeps only the
+// findings owned by owner, for display; the stored log is left whole.
+func filterLogByOwner(log *sarif.Log, owner string) *sarif.Log {
+	if len(log.Runs) == 0 {
+		return log
+	}
+	filtered := *log
+	filtered.Runs = append([]sarif.Run(nil), log.Runs...)
+	filtered.Runs[0].Results = codeowners.Filter(log.Runs[0].Results, owner)
+	return &filtered
+}
+
+// judgeFormatter returns the formatter --format and --template select, or
+// nil to print the verdict as JSON
+func judgeFormatter(sortMode output.SortMode, taxonomy output.TaxonomyFilter) (output.Formatter, error) {
+	format := flagJudgeFormat
+	if format == "" && flagJudgeTemplate != "" {
+		format = "template"
+	}
+	if format == "" {
+		return nil, nil
+	}
+	return output.NewFormatter(format,
+		output.WithSortBy(sortMode),
+		output.WithTaxonomy(taxonomy),
+		output.WithTemplate(flagJudgeTemplate),
+		output.WithTemplateDir(filepath.Join(flagJudgePolicyDir, "formats")),
+	)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// llmInitFunc readies the LLM tiers: it checks the provider, resolves the
+// persona prompt, and creates the client that will call the model.
+type llmInitFunc func(ctx context.Context, cfg *config.Config) (analyzer.BAMLClient, string, error)
+
+// initLLM is the llmInitFunc used by analyze. Tests replace it to simulate a
+// provider that cannot be initialized.
+var initLLM llmInitFunc = func(ctx context.Context, cfg *config.Config) (analyzer.BAMLClient, string, error) {
+	if err := cfg.ValidateProvider(); err != nil {
+		return nil, "", fmt.Errorf("invalid provider configuration: %w", err)
+	}
+	personaPrompt, err := analyzer.GetPersonaPrompt(ctx, cfg.Persona)
+	if err != nil {
+		return nil, "", fmt.Errorf("loading persona %s: %w", cfg.Persona, err)
+	}
+	if err := analyzer.LLMRuntimeError(); err != nil {
+		return nil, "", err
+	}
+	return analyzer.NewProviderClient(cfg.Provider), personaPrompt, nil
+}
+
+// prepareLLM runs init. If it fails and requireLLM is false, the failure is
+// logged as a warning and a nil client is returned so analysis continues
+// with the instant tier alone; skipped then carries the reason for the
+// summary. With requireLLM the failure is returned as an error.
+func prepareLLM(ctx context.Context, cfg *config.Config, requireLLM bool, init llmInitFunc) (client analyzer.BAMLClient, personaPrompt, skipped string, err error) {
+	client, personaPrompt, err = init(ctx, cfg)
+	if err == nil {
+		return client, personaPrompt, "", nil
+	}
+	if requireLLM {
+		return nil, "", "", fmt.Errorf("initializing LLM (required by --require-llm): %w", err)
+	}
+	slog.Warn("LLM initialization failed; skipping LLM tiers and running instant-tier rules only", "err", err)
+	return nil, "", err.Error(), nil
+}
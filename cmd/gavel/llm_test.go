@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+)
+
+// failingLLM simulates a provider whose client cannot be created.
+func failingLLM(context.Context, *config.Config) (analyzer.BAMLClient, string, error) {
+	return nil, "", errors.New("loading native library: libbaml.so not found")
+}
+
+// captureLogs redirects the default slog logger to a buffer for the rest of
+// the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestPrepareLLM_FailureFallsBackToInstantTier(t *testing.T) {
+	logs := captureLogs(t)
+	ctx := context.Background()
+
+	client, personaPrompt, skipped, err := prepareLLM(ctx, &config.Config{}, false, failingLLM)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+	assert.Empty(t, personaPrompt)
+	assert.Contains(t, skipped, "libbaml.so not found")
+	assert.Contains(t, logs.String(), "level=WARN")
+	assert.Contains(t, logs.String(), "skipping LLM tiers")
+
+	ta := analyzer.NewTieredAnalyzer(client)
+	art := input.Artifact{Path: "app.go", Content: "package app\n\nvar password = \"hunter2\"\n", Kind: input.KindFile}
+	results, err := ta.Analyze(ctx, []input.Artifact{art}, nil, personaPrompt)
+	require.NoError(t, err)
+	require.NotEmpty(t, results, "expected instant-tier findings without an LLM client")
+	for _, r := range results {
+		assert.Equal(t, "instant", r.Properties["gavel/tier"], "rule %s", r.RuleID)
+	}
+}
+
+func TestPrepareLLM_RequireLLM(t *testing.T) {
+	logs := captureLogs(t)
+
+	_, _, _, err := prepareLLM(context.Background(), &config.Config{}, true, failingLLM)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--require-llm")
+	assert.Contains(t, err.Error(), "libbaml.so not found")
+	assert.Empty(t, logs.String())
+}
+
+func TestInitLLM_InvalidProvider(t *testing.T) {
+	cfg := &config.Config{Provider: config.ProviderConfig{Name: "nope"}, Persona: "code-reviewer"}
+	client, _, err := initLLM(context.Background(), cfg)
+	require.Error(t, err)
+	assert.Nil(t, client)
+	assert.Contains(t, err.Error(), "invalid provider configuration")
+}
+
+func TestInitLLM_Succeeds(t *testing.T) {
+	if err := analyzer.LLMRuntimeError(); err != nil {
+		t.Skip(err)
+	}
+	cfg := &config.Config{
+		Provider: config.ProviderConfig{Name: "ollama", Ollama: config.OllamaConfig{Model: "m"}},
+		Persona:  "code-reviewer",
+	}
+	client, personaPrompt, err := initLLM(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+	assert.NotEmpty(t, personaPrompt)
+}
+
+// TestPrepareLLM_MissingRuntime runs the real initLLM in a build without the
+// BAML runtime: go test -tags nobaml ./cmd/gavel
+func TestPrepareLLM_MissingRuntime(t *testing.T) {
+	if analyzer.LLMRuntimeError() == nil {
+		t.Skip("the BAML runtime is linked in; run with -tags nobaml")
+	}
+	captureLogs(t)
+	cfg := &config.Config{
+		Provider: config.ProviderConfig{Name: "ollama", Ollama: config.OllamaConfig{Model: "m"}},
+		Persona:  "code-reviewer",
+	}
+
+	client, _, skipped, err := prepareLLM(context.Background(), cfg, false, initLLM)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+	assert.Contains(t, skipped, "without the BAML runtime")
+
+	_, _, _, err = prepareLLM(context.Background(), cfg, true, initLLM)
+	require.ErrorIs(t, err, analyzer.LLMRuntimeError())
+}
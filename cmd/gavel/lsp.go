@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -15,6 +17,9 @@ import (
 	"github.com/chris-regnier/gavel/internal/config"
 	"github.com/chris-regnier/gavel/internal/input"
 	"github.com/chris-regnier/gavel/internal/lsp"
+	"github.com/chris-regnier/gavel/internal/metrics"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
 )
 
 var (
@@ -69,19 +74,20 @@ func runLSP(cmd *cobra.Command, args []string) error {
 		lspCacheDir = filepath.Join(home, ".cache", "gavel")
 	}
 
-	// Load tiered configuration
-	cfg, err := config.LoadTiered(lspMachineConfig, lspProjectConfig)
+	cfg, loadedRules, err := loadLSPConfig()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return err
 	}
 
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("invalid config: %w", err)
+	metricsCollector, err := startMetricsServer(ctx, cfg.Metrics)
+	if err != nil {
+		return err
 	}
 
-	// Create BAML client
-	client := analyzer.NewBAMLLiveClient(cfg.Provider)
+	// Create BAML client. The response cache is opened once, so cached
+	// responses survive configuration reloads
+	newClient := cachingClients(responseCache(cfg, filepath.Dir(lspProjectConfig)), analyzer.NewProviderClient)
+	client := newClient(cfg.Provider)
 
 	// Create analyzer wrapper with cache
 	wrapper := lsp.NewAnalyzerWrapper(client, cfg)
@@ -92,7 +98,9 @@ func runLSP(cmd *cobra.Command, args []string) error {
 	// Build local cache
 	var localCache cache.CacheManager
 	if lspCacheDir != "" {
-		localCache = cache.NewLocalCache(lspCacheDir)
+		if dir := writableDir(lspCacheDir, "gavel-cache-", "cache"); dir != "" {
+			localCache = cache.NewLocalCache(dir)
+		}
 	}
 
 	// Determine remote cache URL (flag overrides config)
@@ -110,7 +118,7 @@ func runLSP(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("getting remote cache token: %w", err)
 		}
 		if token != "" {
-			remoteOpts = append(remoteOpts, cache.WithToken(token))
+			remoteOpts = append(remoteOpts, cache.WithAuth(cfg.RemoteCache.Auth.Type, token))
 		}
 
 		remoteCache := cache.NewRemoteCache(remoteCacheURL, remoteOpts...)
@@ -162,17 +170,33 @@ func runLSP(cmd *cobra.Command, args []string) error {
 		server.SetCacheManager(cacheManager)
 	}
 
-	// Wire progressive analysis via TieredAnalyzer
-	tieredAnalyzer := analyzer.NewTieredAnalyzer(client)
-
-	personaPrompt, err := analyzer.GetPersonaPrompt(ctx, cfg.Persona)
+	// Wire progressive analysis via TieredAnalyzer. The analysis is swapped
+	// out when the project's configuration changes, so each call uses the
+	// one current when it starts.
+	var current atomic.Pointer[lspAnalysis]
+	initial, err := newLSPAnalysis(ctx, cfg, client, newClient, loadedRules, metricsCollector)
 	if err != nil {
-		return fmt.Errorf("getting persona prompt: %w", err)
+		return err
 	}
+	current.Store(initial)
+
+	// The "Analyze function" code lens sends only the function's lines to
+	// the comprehensive tier
+	server.SetRangeAnalyze(func(ctx context.Context, path, content string, startLine, endLine int) ([]sarif.Result, error) {
+		a := current.Load()
+		art := input.Artifact{Path: path, Content: content, Kind: input.KindFile}
+		return a.tiered.AnalyzeRange(ctx, art, startLine, endLine, 0, a.policies, a.personaPrompt)
+	})
+
+	// Unsaved edits get the instant tier only; LLM tiers run on save
+	server.SetInstantAnalyze(func(ctx context.Context, path, content string) ([]sarif.Result, error) {
+		return current.Load().tiered.RunPatternMatching(ctx, input.Artifact{Path: path, Content: content, Kind: input.KindFile}), nil
+	})
 
 	server.SetProgressiveAnalyze(func(ctx context.Context, path, content string) <-chan lsp.ProgressiveResult {
+		a := current.Load()
 		art := input.Artifact{Path: path, Content: content, Kind: input.KindFile}
-		tieredCh := tieredAnalyzer.AnalyzeProgressive(ctx, []input.Artifact{art}, cfg.Policies, personaPrompt)
+		tieredCh := a.tiered.AnalyzeProgressive(ctx, []input.Artifact{art}, a.policies, a.personaPrompt)
 
 		resultCh := make(chan lsp.ProgressiveResult, 3)
 		go func() {
@@ -188,6 +212,29 @@ func runLSP(cmd *cobra.Command, args []string) error {
 		return resultCh
 	})
 
+	// Reload policies, rules and personas when .gavel/ changes, and show
+	// open documents' findings under the new configuration. A broken edit
+	// keeps the previous configuration until it is fixed.
+	configDir := filepath.Dir(lspProjectConfig)
+	configWatcher := lsp.NewConfigWatcher(configDir, lsp.DefaultConfigPollInterval, func() {
+		cfg, loadedRules, err := loadLSPConfig()
+		if err != nil {
+			slog.Error("not reloading configuration", "dir", configDir, "err", err)
+			return
+		}
+		client := newClient(cfg.Provider)
+		a, err := newLSPAnalysis(ctx, cfg, client, newClient, loadedRules, metricsCollector)
+		if err != nil {
+			slog.Error("not reloading configuration", "dir", configDir, "err", err)
+			return
+		}
+		wrapper.Reload(client, cfg)
+		current.Store(a)
+		slog.Info("reloaded configuration", "dir", configDir)
+		server.ReanalyzeOpenDocuments()
+	})
+	go configWatcher.Run(ctx)
+
 	// Run server
 	if err := server.Run(ctx); err != nil {
 		return fmt.Errorf("LSP server error: %w", err)
@@ -195,3 +242,62 @@ func runLSP(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// loadLSPConfig loads and validates the tiered configuration, registers
+// custom personas and loads rules, for startup and for each reload
+func loadLSPConfig() (*config.Config, []rules.Rule, error) {
+	cfg, err := config.LoadTiered(lspMachineConfig, lspProjectConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	policyDir := filepath.Dir(lspProjectConfig)
+	if err := registerPersonas(policyDir); err != nil {
+		return nil, nil, err
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	var userRulesDir string
+	if home, err := os.UserHomeDir(); err == nil {
+		userRulesDir = filepath.Join(home, ".config", "gavel", "rules")
+	}
+	loadedRules, err := rules.LoadRules(userRulesDir, filepath.Join(policyDir, "rules"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading rules: %w", err)
+	}
+
+	return cfg, loadedRules, nil
+}
+
+// lspAnalysis is the tiered analyzer and the policies and persona it
+// analyzes with
+type lspAnalysis struct {
+	tiered        *analyzer.TieredAnalyzer
+	policies      map[string]config.Policy
+	personaPrompt string
+}
+
+func newLSPAnalysis(ctx context.Context, cfg *config.Config, client analyzer.BAMLClient, newClient func(config.ProviderConfig) analyzer.BAMLClient, loadedRules []rules.Rule, collector *metrics.Collector) (*lspAnalysis, error) {
+	personaPrompt, err := analyzer.GetPersonaPrompt(ctx, cfg.Persona)
+	if err != nil {
+		return nil, fmt.Errorf("getting persona prompt: %w", err)
+	}
+
+	opts := []analyzer.TieredAnalyzerOption{
+		analyzer.WithInstantPatterns(loadedRules),
+		analyzer.WithTieredRequestTimeout(cfg.Provider.RequestTimeoutDuration()),
+		analyzer.WithTieredTokenBudget(cfg.Provider.RequestTokenBudget()),
+		analyzer.WithPathOverrides(cfg.PathOverrides),
+		analyzer.WithSecretScanner(analyzer.SecretScanner(cfg.Secrets)),
+		analyzer.WithParseErrorPolicy(analyzer.ParseErrorAction(cfg.ParseErrors.Action), cfg.ParseErrors.Retries),
+		analyzer.WithPolicyRoutes(analyzer.PolicyRoutes(cfg, newClient)),
+		analyzer.WithMetricsCollector(collector),
+	}
+	tiered := analyzer.NewTieredAnalyzer(client, append(opts, analyzer.FastTierOptions(cfg, newClient)...)...)
+
+	return &lspAnalysis{tiered: tiered, policies: cfg.Policies, personaPrompt: personaPrompt}, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -49,7 +50,7 @@ func init() {
 	rootCmd.PersistentFlags().String(
 		"persona",
 		"",
-		"Persona to use for analysis (code-reviewer, architect, security). Overrides config.",
+		"Persona to use for analysis (code-reviewer, architect, security, or a custom persona). Overrides config.",
 	)
 
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress all log output")
@@ -59,9 +60,39 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 }
 
+// exitGateFailed is the exit code for a run whose findings exceed the
+// --fail-on/--max-findings threshold, distinct from 1 for errors.
+const exitGateFailed = 2
+
+// exitError ends the process with a specific exit code. Commands return it
+// after their normal output, so cobra neither repeats it nor prints usage.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string { return e.msg }
+
+// exitWith silences cobra's error handling for cmd and returns an
+// exitError with code.
+func exitWith(cmd *cobra.Command, code int, format string, args ...interface{}) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// gateFailed is exitWith for exitGateFailed.
+func gateFailed(cmd *cobra.Command, format string, args ...interface{}) error {
+	return exitWith(cmd, exitGateFailed, format, args...)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		os.Exit(1)
 	}
 }
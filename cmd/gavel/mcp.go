@@ -3,17 +3,23 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 
 	"github.com/chris-regnier/gavel/internal/config"
 	gavelmcp "github.com/chris-regnier/gavel/internal/mcp"
+	"github.com/chris-regnier/gavel/internal/metrics"
 	"github.com/chris-regnier/gavel/internal/rules"
+	gavelserver "github.com/chris-regnier/gavel/internal/server"
+	"github.com/chris-regnier/gavel/internal/server/middleware"
 	"github.com/chris-regnier/gavel/internal/store"
 )
 
@@ -23,6 +29,11 @@ var (
 	mcpOutputDir     string
 	mcpRegoDir       string
 	mcpRulesDir      string
+	mcpMaxConcurrent int
+	mcpQueueTimeout  time.Duration
+	mcpRejectBusy    bool
+	mcpHTTPAddr      string
+	mcpAuthKeys      string
 )
 
 func init() {
@@ -38,17 +49,26 @@ func newMCPCmd() *cobra.Command {
 
 The MCP server communicates over stdin/stdout using the Model Context Protocol,
 allowing AI assistants like Claude to analyze code, evaluate results, and query
-findings programmatically.
+findings programmatically. With --http it serves the Streamable HTTP transport
+instead, authenticated and limited by the server.auth section of policies.yaml.
 
 Tools provided:
-  analyze_file       Analyze a single source file
-  analyze_directory  Analyze all files in a directory
-  judge              Evaluate analysis results with Rego policies
-  list_results       List stored analysis results
-  get_result         Get full SARIF output for a result
+  analyze_file         Analyze a single source file
+  analyze_directory    Analyze all files in a directory
+  judge                Evaluate analysis results with Rego policies
+  list_results         List stored analysis results
+  get_result           Get full SARIF output for a result
+  get_finding_context  Get the source, guidance and fix for one finding
+  list_rules           List the instant-tier rules
+  create_rule          Generate a rule from a description (writes with confirm=true)
+  test_rule            Run one rule against a file or snippet
+  list_policies        List the configured policies
+  toggle_policy        Enable or disable a policy (writes with confirm=true)
+  server_info          Report the Gavel version, tools, and supported features
 
 Resources:
   gavel://policies       Current policy configuration
+  gavel://results        Stored results; subscribe to hear when an analysis completes
   gavel://results/{id}   SARIF output for a specific result
 
 Prompts:
@@ -63,6 +83,11 @@ Prompts:
 	cmd.Flags().StringVar(&mcpOutputDir, "output", ".gavel/results", "Output directory for results")
 	cmd.Flags().StringVar(&mcpRegoDir, "rego-dir", "", "Directory containing custom Rego policies (default: embedded policy)")
 	cmd.Flags().StringVar(&mcpRulesDir, "rules-dir", "", "Directory containing custom rule YAML files (default: sibling 'rules/' directory of --project-config)")
+	cmd.Flags().IntVar(&mcpMaxConcurrent, "max-concurrent", 4, "Maximum analyze_* tool calls running at once; further calls queue")
+	cmd.Flags().DurationVar(&mcpQueueTimeout, "queue-timeout", 0, "How long a queued analysis waits for a free slot before failing (0 waits until the request is cancelled)")
+	cmd.Flags().BoolVar(&mcpRejectBusy, "reject-when-busy", false, "Fail analyze_* calls immediately when --max-concurrent analyses are running instead of queueing them")
+	cmd.Flags().StringVar(&mcpHTTPAddr, "http", "", "Serve the Streamable HTTP transport at /mcp on this address instead of stdio")
+	cmd.Flags().StringVar(&mcpAuthKeys, "auth-keys", "", "Path to API keys file (key:tenant-id per line) for --http (default: server.auth.keys_file)")
 
 	return cmd
 }
@@ -86,13 +111,33 @@ func runMCP(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
+	if err := registerPersonas(filepath.Dir(mcpProjectConfig)); err != nil {
+		return err
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
+	metricsCollector, err := startMetricsServer(ctx, cfg.Metrics)
+	if err != nil {
+		return err
+	}
+
 	// Create file store
-	fs := store.NewFileStore(mcpOutputDir)
+	auth := cfg.Server.Auth
+	var fs store.Store = store.NewFileStore(mcpOutputDir)
+	if mcpHTTPAddr != "" && auth.IsolateTenants {
+		fs = store.NewTenantFileStore(mcpOutputDir)
+	}
+	var pricing metrics.PriceTable
+	if mcpHTTPAddr != "" && auth.HasQuotas() {
+		if metricsCollector == nil {
+			metricsCollector = metrics.NewCollector()
+		}
+		pricing = metrics.NewPriceTable(cfg.Pricing)
+	}
 
 	// Load rules (embedded defaults + user overrides + project overrides),
 	// mirroring the CLI's tier-merging behavior.
@@ -113,18 +158,71 @@ func runMCP(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create MCP server
+	subs := gavelmcp.NewSubscriptions()
 	mcpServer := gavelmcp.NewMCPServer(gavelmcp.ServerConfig{
 		Config:  cfg,
 		Store:   fs,
 		RegoDir: mcpRegoDir,
 		Rules:   loadedRules,
+		Version: version,
+
+		ProjectConfig: mcpProjectConfig,
+		RulesDir:      projectRulesDir,
+
+		MaxConcurrent:  mcpMaxConcurrent,
+		QueueTimeout:   mcpQueueTimeout,
+		RejectWhenBusy: mcpRejectBusy,
+
+		Metrics: metricsCollector,
+		Pricing: pricing,
+
+		Subscriptions: subs,
+		ResponseCache: responseCache(cfg, filepath.Dir(mcpProjectConfig)),
 	})
 
+	if mcpHTTPAddr != "" {
+		return serveMCPHTTP(ctx, mcpServer, subs, auth, metricsCollector)
+	}
+
 	// Serve over stdio
 	stdioServer := server.NewStdioServer(mcpServer)
-	if err := stdioServer.Listen(ctx, os.Stdin, os.Stdout); err != nil {
+	if err := subs.Stdio(ctx, stdioServer, os.Stdin, os.Stdout); err != nil {
 		return fmt.Errorf("MCP server error: %w", err)
 	}
 
 	return nil
 }
+
+// serveMCPHTTP serves mcpServer over the Streamable HTTP transport at /mcp,
+// authenticating, rate-limiting and applying quotas as gavel serve does.
+func serveMCPHTTP(ctx context.Context, mcpServer *server.MCPServer, subs *gavelmcp.Subscriptions, auth config.ServerAuthConfig, collector *metrics.Collector) error {
+	keysFile := mcpAuthKeys
+	if keysFile == "" {
+		keysFile = auth.KeysFile
+	}
+	var verifiers []middleware.TokenVerifier
+	if keysFile != "" {
+		keys, err := loadAuthKeys(keysFile)
+		if err != nil {
+			return fmt.Errorf("loading auth keys: %w", err)
+		}
+		verifiers = append(verifiers, middleware.StaticKeys(keys))
+	}
+	if auth.OIDC.Issuer != "" {
+		verifiers = append(verifiers, middleware.NewOIDCVerifier(auth.OIDC))
+	}
+
+	handler := subs.Handler(server.NewStreamableHTTPServer(mcpServer))
+	handler = middleware.Quota(collector, auth.LimitsFor)(handler)
+	handler = middleware.RateLimit(auth.LimitsFor)(handler)
+	if len(verifiers) > 0 {
+		handler = middleware.Authenticate(verifiers...)(handler)
+	} else {
+		slog.Warn("serving MCP over HTTP without authentication; set server.auth or --auth-keys")
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", middleware.RequestID()(handler))
+
+	// No write timeout: responses may stream for as long as an analysis runs
+	return gavelserver.New(mux, gavelserver.Config{Addr: mcpHTTPAddr, ReadTimeout: 30 * time.Second}).Start(ctx)
+}
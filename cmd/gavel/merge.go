@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/evaluator"
+	"github.com/chris-regnier/gavel/internal/merge"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+	"github.com/chris-regnier/gavel/internal/suppression"
+)
+
+var (
+	flagMergeOutput    string
+	flagMergeNoStore   bool
+	flagMergeBaseline  string
+	flagMergeRegoDir   string
+	flagMergePolicyDir string
+)
+
+func init() {
+	mergeCmd := &cobra.Command{
+		Use:   "merge <sarif>...",
+		Short: "Combine SARIF logs from shards, earlier runs, or other tools into one result",
+		Long: `Merge SARIF logs into a single Gavel run, evaluate it with the Rego gate like
+gavel judge, and store the result and verdict in --output.
+
+The logs may be the sarif.json files written by gavel analyze --shard, as if
+the whole input had been analyzed in one run; earlier Gavel runs over the same
+code; or SARIF from other tools alongside Gavel's. Each run's tool is listed
+in gavel/tools and results from other tools are tagged with theirs in
+gavel/tool. A finding reported more than once at the same line by the same
+rule is kept once, preferring the result from the highest analysis tier.
+
+When merging shards, every shard of the run must be given exactly once, and
+all shards must have been analyzed with the same config, rules, and persona.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runMerge,
+	}
+
+	mergeCmd.Flags().StringVar(&flagMergeOutput, "output", ".gavel/results", "Output directory for results")
+	mergeCmd.Flags().BoolVar(&flagMergeNoStore, "no-store", false, "Do not write results to --output; print the SARIF log in the summary instead")
+	mergeCmd.Flags().StringVar(&flagMergeBaseline, "baseline", "", "Baseline to compare the merged result against, as for gavel analyze --baseline")
+	mergeCmd.Flags().StringVar(&flagMergeRegoDir, "rego", ".gavel/rego", "Directory containing Rego policies")
+	mergeCmd.Flags().StringVar(&flagMergePolicyDir, "policies", ".gavel", "Directory containing policies.yaml")
+
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
+	projectConfig := flagMergePolicyDir + "/policies.yaml"
+	cfg, err := config.LoadTiered(machineConfig, projectConfig)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	logs, err := merge.LoadFiles(args)
+	if err != nil {
+		return fmt.Errorf("reading logs: %w", err)
+	}
+	merged, summary, err := merge.Logs(logs, version)
+	if err != nil {
+		return fmt.Errorf("merging: %w", err)
+	}
+
+	// The merged run is a new analysis with its own automation guid
+	sarif.EnsureAutomationDetails(merged)
+	if flagMergeBaseline != "" {
+		if err := applyBaseline(ctx, merged, flagMergeOutput, flagMergeBaseline); err != nil {
+			return err
+		}
+	}
+
+	supps, err := suppression.Load(filepath.Dir(flagMergePolicyDir))
+	if err != nil {
+		slog.Warn("failed to load suppressions", "err", err)
+	}
+	suppression.Apply(supps, merged)
+
+	var evalOpts []evaluator.EvaluatorOption
+	if len(cfg.Gate.Categories) > 0 {
+		evalOpts = append(evalOpts, evaluator.WithCategoryThresholds(cfg.Gate.Categories))
+	}
+	evalOpts = append(evalOpts, evaluator.WithVerdictPolicy(cfg.Verdict))
+	eval, err := evaluator.NewEvaluator(ctx, flagMergeRegoDir, evalOpts...)
+	if err != nil {
+		return fmt.Errorf("creating evaluator: %w", err)
+	}
+	verdict, err := eval.Evaluate(ctx, merged)
+	if err != nil {
+		return fmt.Errorf("evaluating: %w", err)
+	}
+
+	id, storedIn, err := storeResults(ctx, merged, flagMergeOutput, flagMergeNoStore)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if err := store.NewFileStore(storedIn).WriteVerdict(ctx, id, verdict); err != nil {
+			return fmt.Errorf("storing verdict: %w", err)
+		}
+	}
+
+	out := map[string]interface{}{
+		"merged":     summary.Logs,
+		"tools":      summary.Tools,
+		"duplicates": summary.Duplicates,
+		"findings":   len(merged.Runs[0].Results),
+		"decision":   verdict.Decision,
+		"reason":     verdict.Reason,
+	}
+	if summary.Shards > 0 {
+		out["shards"] = summary.Shards
+	}
+	recordStorage(out, merged, id, storedIn, flagMergeOutput)
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialising summary: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/metrics"
+)
+
+// startMetricsServer serves a Prometheus /metrics endpoint on its own port
+// for servers whose main channel is stdio (gavel mcp and gavel lsp). It
+// returns nil when metrics are disabled, and otherwise the collector to
+// record analyses to. The endpoint shuts down when ctx is done.
+func startMetricsServer(ctx context.Context, cfg config.MetricsConfig) (*metrics.Collector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	// Listen before returning so a port already in use fails startup
+	// instead of going unnoticed in a goroutine
+	ln, err := net.Listen("tcp", cfg.ListenAddr())
+	if err != nil {
+		return nil, fmt.Errorf("metrics endpoint: %w", err)
+	}
+
+	collector := metrics.NewCollector(metrics.WithPrometheus())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector.PrometheusHandler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		slog.Info("serving Prometheus metrics", "addr", ln.Addr().String())
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics endpoint stopped", "err", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	return collector, nil
+}
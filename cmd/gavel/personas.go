@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/chris-regnier/gavel/internal/persona"
+)
+
+// registerPersonas loads custom personas from ~/.config/gavel/personas and
+// the personas directory under policyDir so --persona and the config's
+// persona field can name them.
+func registerPersonas(policyDir string) error {
+	var userDir string
+	if home, err := os.UserHomeDir(); err == nil {
+		userDir = filepath.Join(home, ".config", "gavel", "personas")
+	}
+	personas, err := persona.Load(userDir, filepath.Join(policyDir, "personas"))
+	if err != nil {
+		return fmt.Errorf("loading personas: %w", err)
+	}
+	persona.Register(personas)
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/bundle"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/evaluator"
+)
+
+var (
+	flagPolicyPoliciesDir string
+	flagPolicyKey         string
+	flagPolicyDigest      string
+	flagPolicyPin         bool
+	flagPolicySignKey     string
+	flagPolicyPlainHTTP   bool
+)
+
+func init() {
+	policyCmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Share Rego verdict policies and rules as versioned bundles",
+	}
+
+	pullCmd := &cobra.Command{
+		Use:   "pull [source]",
+		Short: "Install policy bundles into the .gavel directory",
+		Long: `Fetch a policy bundle and install its rego/*.rego and rules/*.yaml files into
+the --policies directory, replacing files of the same name.
+
+A source is an OCI artifact (oci://ghcr.io/acme/gavel-policies:v1.2.0) or a
+Git repository laid out like a .gavel directory
+(git+https://github.com/acme/gavel-policies.git#v1.2.0). Without a source,
+every bundle listed under bundles in policies.yaml is pulled.
+
+A bundle pinned with a digest, in config or with --digest, is only installed
+if its contents match. With a cosign public key, from config or --key, the
+registry manifest must carry a valid cosign signature. Bundles whose Rego does
+not compile are rejected. Nothing is installed unless every bundle passes.
+--pin records the source and the pulled digest in policies.yaml.
+
+Registry credentials are read from GAVEL_REGISTRY_USERNAME and
+GAVEL_REGISTRY_PASSWORD; public repositories need none.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runPolicyPull,
+	}
+	pullCmd.Flags().StringVar(&flagPolicyPoliciesDir, "policies", ".gavel", "Directory containing policies.yaml to install the bundles into")
+	pullCmd.Flags().StringVar(&flagPolicyKey, "key", "", "Cosign public key the bundle must be signed with (overrides the configured key)")
+	pullCmd.Flags().StringVar(&flagPolicyDigest, "digest", "", "Content digest the bundle must have (overrides the configured digest)")
+	pullCmd.Flags().BoolVar(&flagPolicyPin, "pin", false, "Record the source and pulled digest in policies.yaml")
+	pullCmd.Flags().BoolVar(&flagPolicyPlainHTTP, "plain-http", false, "Reach the registry over HTTP instead of HTTPS")
+
+	pushCmd := &cobra.Command{
+		Use:   "push oci://<host>/<repository>:<tag>",
+		Short: "Publish the .gavel directory's Rego policies and rules as a bundle",
+		Long: `Pack the rego/*.rego and rules/*.yaml files of the --policies directory into a
+bundle and push it to an OCI registry as an artifact of type
+application/vnd.gavel.policy.bundle.v1.
+
+The output includes the bundle's content digest, which repositories pin with
+gavel policy pull --pin, and the manifest digest. With --sign-key the manifest
+is signed with cosign.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPolicyPush,
+	}
+	pushCmd.Flags().StringVar(&flagPolicyPoliciesDir, "policies", ".gavel", "Directory whose rego/ and rules/ subdirectories are bundled")
+	pushCmd.Flags().StringVar(&flagPolicySignKey, "sign-key", "", "Cosign private key to sign the pushed manifest with")
+	pushCmd.Flags().BoolVar(&flagPolicyPlainHTTP, "plain-http", false, "Reach the registry over HTTP instead of HTTPS")
+
+	policyCmd.AddCommand(pullCmd, pushCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+// pulledBundle is the summary printed for each installed bundle.
+type pulledBundle struct {
+	Source   string   `json:"source"`
+	Digest   string   `json:"digest"`
+	Revision string   `json:"revision"` // Manifest digest or Git commit
+	Verified bool     `json:"signature_verified,omitempty"`
+	Files    []string `json:"files"`
+	Pinned   bool     `json:"pinned,omitempty"`
+
+	bundle *bundle.Bundle
+	key    string
+}
+
+func runPolicyPull(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
+	projectConfig := filepath.Join(flagPolicyPoliciesDir, "policies.yaml")
+	cfg, err := config.LoadTiered(machineConfig, projectConfig)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	targets := cfg.Bundles
+	if len(args) == 1 {
+		target := config.BundleConfig{Source: args[0]}
+		for _, b := range cfg.Bundles {
+			if b.Source == args[0] {
+				target = b
+			}
+		}
+		targets = []config.BundleConfig{target}
+	}
+	if len(targets) == 0 {
+		return errors.New("no bundles configured in policies.yaml; pass a source to pull")
+	}
+	if len(targets) > 1 && (flagPolicyDigest != "" || flagPolicyKey != "") {
+		return errors.New("--digest and --key apply to a single source")
+	}
+
+	reg := registryClient()
+	var pulled []*pulledBundle
+	for _, target := range targets {
+		if flagPolicyDigest != "" {
+			target.Digest = flagPolicyDigest
+		}
+		if flagPolicyKey != "" {
+			target.Key = flagPolicyKey
+		}
+		p, err := pullBundle(ctx, reg, target)
+		if err != nil {
+			return err
+		}
+		pulled = append(pulled, p)
+	}
+
+	// Install only once every bundle has been fetched and checked
+	for _, p := range pulled {
+		files, err := p.bundle.Install(flagPolicyPoliciesDir)
+		if err != nil {
+			return fmt.Errorf("installing %s: %w", p.Source, err)
+		}
+		p.Files = files
+		if flagPolicyPin {
+			if err := config.PinBundle(projectConfig, config.BundleConfig{Source: p.Source, Digest: p.Digest, Key: p.key}); err != nil {
+				return fmt.Errorf("pinning %s: %w", p.Source, err)
+			}
+			p.Pinned = true
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{"bundles": pulled}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialising summary: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
+// pullBundle fetches target and checks its digest, signature and Rego.
+func pullBundle(ctx context.Context, reg *bundle.Registry, target config.BundleConfig) (*pulledBundle, error) {
+	src, err := bundle.ParseSource(target.Source)
+	if err != nil {
+		return nil, err
+	}
+	if target.Key != "" && src.OCI == nil {
+		return nil, fmt.Errorf("%s: signatures can only be verified for oci:// sources; pin Git sources by digest", target.Source)
+	}
+
+	res, err := bundle.Pull(ctx, src, reg)
+	if err != nil {
+		return nil, fmt.Errorf("pulling %s: %w", target.Source, err)
+	}
+	p := &pulledBundle{Source: target.Source, Digest: res.Bundle.Digest(), Revision: res.Revision, bundle: res.Bundle, key: target.Key}
+	if target.Digest != "" && p.Digest != target.Digest {
+		return nil, fmt.Errorf("%s: bundle digest %s does not match the pinned %s", target.Source, p.Digest, target.Digest)
+	}
+	if target.Key != "" {
+		if err := bundle.Verify(ctx, reg, src.OCI.WithDigest(res.Revision), target.Key); err != nil {
+			return nil, err
+		}
+		p.Verified = true
+	}
+	if err := checkBundleRego(ctx, res.Bundle); err != nil {
+		return nil, fmt.Errorf("%s: %w", target.Source, err)
+	}
+	return p, nil
+}
+
+// checkBundleRego compiles the bundle's Rego policies the way judge would,
+// so a broken bundle is rejected before it replaces working policies.
+func checkBundleRego(ctx context.Context, b *bundle.Bundle) error {
+	hasRego := false
+	for _, p := range b.Paths() {
+		hasRego = hasRego || strings.HasPrefix(p, "rego/")
+	}
+	if !hasRego {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "gavel-bundle-check-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if _, err := b.Install(dir); err != nil {
+		return err
+	}
+	if _, err := evaluator.NewEvaluator(ctx, filepath.Join(dir, "rego")); err != nil {
+		return fmt.Errorf("bundle Rego does not compile: %w", err)
+	}
+	return nil
+}
+
+func runPolicyPush(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	src, err := bundle.ParseSource(args[0])
+	if err != nil {
+		return err
+	}
+	if src.OCI == nil {
+		return fmt.Errorf("%s: bundles can only be pushed to oci:// references; commit Git bundles to their repository", args[0])
+	}
+
+	b, err := bundle.Load(flagPolicyPoliciesDir)
+	if err != nil {
+		return err
+	}
+	if err := checkBundleRego(ctx, b); err != nil {
+		return err
+	}
+
+	reg := registryClient()
+	manifestDigest, err := reg.Push(ctx, *src.OCI, b)
+	if err != nil {
+		return fmt.Errorf("pushing %s: %w", args[0], err)
+	}
+	signed := false
+	if flagPolicySignKey != "" {
+		if err := bundle.Sign(ctx, reg, src.OCI.WithDigest(manifestDigest), flagPolicySignKey); err != nil {
+			return err
+		}
+		signed = true
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"reference":       args[0],
+		"digest":          b.Digest(),
+		"manifest_digest": manifestDigest,
+		"files":           b.Paths(),
+		"signed":          signed,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialising summary: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
+// registryClient returns a registry client using the credentials in the
+// environment, if any.
+func registryClient() *bundle.Registry {
+	opts := []bundle.RegistryOption{bundle.WithPlainHTTP(flagPolicyPlainHTTP)}
+	if user := os.Getenv("GAVEL_REGISTRY_USERNAME"); user != "" {
+		opts = append(opts, bundle.WithCredentials(user, os.Getenv("GAVEL_REGISTRY_PASSWORD")))
+	}
+	return bundle.NewRegistry(opts...)
+}
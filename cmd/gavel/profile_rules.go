@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// ruleProfile is the --profile-rules report printed in place of the usual
+// analysis summary.
+type ruleProfile struct {
+	Files    int               `json:"files"`
+	BudgetMs float64           `json:"budget_ms"`
+	Rules    []ruleProfileItem `json:"rules"`
+}
+
+// ruleProfileItem is the cumulative cost of a single rule.
+type ruleProfileItem struct {
+	RuleID     string  `json:"rule_id"`
+	Type       string  `json:"type"`
+	DurationMs float64 `json:"duration_ms"`
+	Files      int     `json:"files"`
+	Matches    int     `json:"matches"`
+	OverBudget bool    `json:"over_budget"`
+}
+
+// profileRules runs only the instant tier over artifacts and returns the top
+// slowest rules by cumulative match time. Rules whose total exceeds budget
+// are flagged and logged as likely performance problems.
+func profileRules(ctx context.Context, artifacts []input.Artifact, loadedRules []rules.Rule, top int, budget time.Duration) ruleProfile {
+	profiler := analyzer.NewRuleProfiler()
+	ta := analyzer.NewTieredAnalyzer(nil,
+		analyzer.WithInstantPatterns(loadedRules),
+		analyzer.WithRuleProfiler(profiler),
+	)
+	for _, art := range artifacts {
+		ta.RunPatternMatching(ctx, art)
+	}
+
+	report := ruleProfile{
+		Files:    profiler.FileCount(),
+		BudgetMs: durationMs(budget),
+		Rules:    []ruleProfileItem{},
+	}
+	for _, t := range profiler.Report(top, budget) {
+		if t.OverBudget {
+			slog.Warn("rule exceeded profiling budget; check for an expensive pattern",
+				"rule", t.RuleID, "duration", t.Duration, "budget", budget)
+		}
+		report.Rules = append(report.Rules, ruleProfileItem{
+			RuleID:     t.RuleID,
+			Type:       string(t.Type),
+			DurationMs: durationMs(t.Duration),
+			Files:      t.Files,
+			Matches:    t.Matches,
+			OverBudget: t.OverBudget,
+		})
+	}
+	return report
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+func TestProfileRules_SlowRuleRankedFirstAndFlagged(t *testing.T) {
+	loaded := []rules.Rule{
+		{
+			ID: "fast-literal", Type: rules.RuleTypeRegex,
+			Pattern: regexp.MustCompile(`NEVER_PRESENT_TOKEN`),
+			Level:   "note", Message: "fast", Confidence: 0.5,
+		},
+		{
+			ID: "slow-greedy", Type: rules.RuleTypeRegex,
+			Pattern: regexp.MustCompile(`(?i)(?:[a-z]+\d*[a-z_]*\s*){1,30}=\s*(?:\w+\s*){1,10}\d{7}`),
+			Level:   "note", Message: "slow", Confidence: 0.5,
+		},
+	}
+	artifacts := []input.Artifact{
+		{Path: "a.go", Content: strings.Repeat("value = compute(alpha, beta) + offset\n", 2000), Kind: input.KindFile},
+		{Path: "b.go", Content: strings.Repeat("other = compute(gamma, delta) + offset\n", 2000), Kind: input.KindFile},
+	}
+
+	report := profileRules(context.Background(), artifacts, loaded, 1, time.Nanosecond)
+
+	assert.Equal(t, 2, report.Files)
+	require.Len(t, report.Rules, 1, "--profile-top 1 should keep only the slowest rule")
+	assert.Equal(t, "slow-greedy", report.Rules[0].RuleID)
+	assert.Equal(t, "regex", report.Rules[0].Type)
+	assert.Equal(t, 2, report.Rules[0].Files)
+	assert.True(t, report.Rules[0].OverBudget)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/output"
+	"github.com/chris-regnier/gavel/internal/publish/github"
+	"github.com/chris-regnier/gavel/internal/publish/gitlab"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+	"github.com/chris-regnier/gavel/internal/suppression"
+)
+
+var (
+	flagPublishResult    string
+	flagPublishOutput    string
+	flagPublishPolicyDir string
+	flagPublishRepo      string
+	flagPublishPR        int
+	flagPublishCommit    string
+	flagPublishAPIURL    string
+
+	flagPublishProject     string
+	flagPublishMR          int
+	flagPublishReport      string
+	flagPublishDiscussions bool
+)
+
+func init() {
+	publishCmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Publish analysis results to a code review platform",
+	}
+
+	githubPRCmd := &cobra.Command{
+		Use:   "github-pr",
+		Short: "Post findings as inline review comments on a GitHub pull request",
+		Long: `Post the findings of a judged analysis to a GitHub pull request as one review.
+Findings on lines the pull request changes become inline comments; the Markdown
+summary of the analysis becomes the review body. Findings commented on by an
+earlier run are not repeated.
+
+In GitHub Actions the repository, pull request number and head commit are read
+from the environment. The token is read from GITHUB_TOKEN (or GH_TOKEN) and
+needs pull-requests: write.`,
+		Args: cobra.NoArgs,
+		RunE: runPublishGitHubPR,
+	}
+	githubPRCmd.Flags().StringVar(&flagPublishRepo, "repo", "", "Repository as owner/repo (default: $GITHUB_REPOSITORY)")
+	githubPRCmd.Flags().IntVar(&flagPublishPR, "pr", 0, "Pull request number (default: from the GitHub Actions event)")
+	githubPRCmd.Flags().StringVar(&flagPublishCommit, "commit", "", "Commit to attach comments to (default: the pull request head)")
+	githubPRCmd.Flags().StringVar(&flagPublishAPIURL, "api-url", "", "GitHub API URL (default: $GITHUB_API_URL or https://api.github.com)")
+
+	gitlabMRCmd := &cobra.Command{
+		Use:   "gitlab-mr",
+		Short: "Post findings as discussions on a GitLab merge request",
+		Long: `Post the findings of a judged analysis to a GitLab merge request. Findings on
+lines the merge request changes become diff discussions; the Markdown summary
+of the analysis is posted as a general discussion. Findings commented on by an
+earlier run are not repeated.
+
+With --report, also write a GitLab Code Quality report for
+artifacts:reports:codequality. With --discussions=false, only the report is
+written and no token is needed.
+
+In a merge request pipeline the API URL, project and merge request are read
+from the CI environment. The token is read from GITLAB_TOKEN and needs the api
+scope; CI_JOB_TOKEN cannot create discussions.`,
+		Args: cobra.NoArgs,
+		RunE: runPublishGitLabMR,
+	}
+	gitlabMRCmd.Flags().StringVar(&flagPublishProject, "project", "", "Project ID or full path, e.g. group/app (default: $CI_PROJECT_ID)")
+	gitlabMRCmd.Flags().IntVar(&flagPublishMR, "mr", 0, "Merge request IID (default: $CI_MERGE_REQUEST_IID)")
+	gitlabMRCmd.Flags().StringVar(&flagPublishAPIURL, "api-url", "", "GitLab API URL (default: $CI_API_V4_URL or https://gitlab.com/api/v4)")
+	gitlabMRCmd.Flags().StringVar(&flagPublishReport, "report", "", "Write a GitLab Code Quality report to this file")
+	gitlabMRCmd.Flags().BoolVar(&flagPublishDiscussions, "discussions", true, "Post discussions to the merge request; set to false to only write --report")
+
+	for _, c := range []*cobra.Command{githubPRCmd, gitlabMRCmd} {
+		c.Flags().StringVar(&flagPublishResult, "result", "", "Analysis result ID to publish (default: most recent)")
+		c.Flags().StringVar(&flagPublishOutput, "output", ".gavel/results", "Directory containing analysis results")
+		c.Flags().StringVar(&flagPublishPolicyDir, "policies", ".gavel", "Directory containing policies.yaml (suppressed findings are never published)")
+		publishCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(publishCmd)
+}
+
+func runPublishGitHubPR(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	pr, err := github.PullRequestFromEnv()
+	if err != nil {
+		return err
+	}
+	if flagPublishRepo != "" {
+		if pr.Owner, pr.Repo, err = github.ParseRepository(flagPublishRepo); err != nil {
+			return err
+		}
+	}
+	if flagPublishPR != 0 {
+		pr.Number = flagPublishPR
+	}
+	if flagPublishCommit != "" {
+		pr.HeadSHA = flagPublishCommit
+	}
+	if err := pr.Validate(); err != nil {
+		return fmt.Errorf("%w (set --repo and --pr outside GitHub Actions)", err)
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("GITHUB_TOKEN or GH_TOKEN environment variable required")
+	}
+	apiURL := flagPublishAPIURL
+	if apiURL == "" {
+		apiURL = os.Getenv("GITHUB_API_URL")
+	}
+
+	resultID, sarifLog, summary, err := loadPublishResult(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	client := github.NewClient(token, github.WithBaseURL(apiURL))
+	report, err := github.Publish(ctx, client, pr, sarifLog, summary)
+	if err != nil {
+		return fmt.Errorf("publishing to %s/%s#%d: %w", pr.Owner, pr.Repo, pr.Number, err)
+	}
+
+	out, _ := json.MarshalIndent(map[string]interface{}{
+		"id":           resultID,
+		"pull_request": fmt.Sprintf("%s/%s#%d", pr.Owner, pr.Repo, pr.Number),
+		"report":       report,
+	}, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
+func runPublishGitLabMR(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if !flagPublishDiscussions && flagPublishReport == "" {
+		return fmt.Errorf("nothing to do: --discussions=false requires --report")
+	}
+
+	var mr gitlab.MergeRequest
+	var token string
+	if flagPublishDiscussions {
+		var err error
+		if mr, err = gitlab.MergeRequestFromEnv(); err != nil {
+			return err
+		}
+		if flagPublishProject != "" {
+			mr.Project = flagPublishProject
+		}
+		if flagPublishMR != 0 {
+			mr.IID = flagPublishMR
+		}
+		if err := mr.Validate(); err != nil {
+			return fmt.Errorf("%w (set --project and --mr outside a merge request pipeline)", err)
+		}
+		if token = os.Getenv("GITLAB_TOKEN"); token == "" {
+			return fmt.Errorf("GITLAB_TOKEN environment variable required")
+		}
+	}
+
+	// The summary needs a verdict; a report on its own does not.
+	resultID, sarifLog, summary, err := loadPublishResult(ctx, flagPublishDiscussions)
+	if err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{"id": resultID}
+	if flagPublishReport != "" {
+		data, err := (&output.GitLabFormatter{}).Format(&output.AnalysisOutput{SARIFLog: sarifLog})
+		if err != nil {
+			return fmt.Errorf("formatting code quality report: %w", err)
+		}
+		if err := os.WriteFile(flagPublishReport, data, 0644); err != nil {
+			return fmt.Errorf("writing code quality report: %w", err)
+		}
+		result["report_file"] = flagPublishReport
+	}
+
+	if flagPublishDiscussions {
+		apiURL := flagPublishAPIURL
+		if apiURL == "" {
+			apiURL = os.Getenv("CI_API_V4_URL")
+		}
+		client := gitlab.NewClient(token, gitlab.WithBaseURL(apiURL))
+		report, err := gitlab.Publish(ctx, client, mr, sarifLog, summary)
+		if err != nil {
+			return fmt.Errorf("publishing to %s!%d: %w", mr.Project, mr.IID, err)
+		}
+		result["merge_request"] = fmt.Sprintf("%s!%d", mr.Project, mr.IID)
+		result["report"] = report
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
+// loadPublishResult reads the result to publish (latest when --result is
+// empty) with current suppressions applied. With summarize, it also reads
+// the stored verdict and renders it with the findings as the Markdown
+// summary.
+func loadPublishResult(ctx context.Context, summarize bool) (id string, log *sarif.Log, summary string, err error) {
+	fs := store.NewFileStore(flagPublishOutput)
+	id = flagPublishResult
+	if id == "" {
+		ids, err := fs.List(ctx)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("listing results: %w", err)
+		}
+		if len(ids) == 0 {
+			return "", nil, "", fmt.Errorf("no analysis results found in %s", flagPublishOutput)
+		}
+		id = ids[0] // List returns newest first
+	}
+	log, err = fs.ReadSARIF(ctx, id)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("reading SARIF for %s: %w", id, err)
+	}
+
+	// Re-apply current suppressions so newly suppressed findings stay quiet
+	supps, err := suppression.Load(filepath.Dir(flagPublishPolicyDir))
+	if err != nil {
+		slog.Warn("failed to load suppressions", "err", err)
+	}
+	suppression.Apply(supps, log)
+	if !summarize {
+		return id, log, "", nil
+	}
+
+	verdict, err := fs.ReadVerdict(ctx, id)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("reading verdict for %s (run gavel judge first): %w", id, err)
+	}
+
+	md, err := (&output.MarkdownFormatter{}).Format(&output.AnalysisOutput{Verdict: verdict, SARIFLog: log})
+	if err != nil {
+		return "", nil, "", fmt.Errorf("formatting summary: %w", err)
+	}
+	return id, log, string(md), nil
+}
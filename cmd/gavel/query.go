@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/jsonpointer"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+var flagQueryOutput string
+
+func init() {
+	queryCmd := &cobra.Command{
+		Use:   "query <result-id|file|-> <json-pointer>",
+		Short: "Print part of a result selected by a JSON Pointer",
+		Long: `Print the subtree of a JSON document selected by an RFC 6901 JSON Pointer,
+e.g. /runs/0/results or /runs/0/results/0/ruleId.
+
+The document is a stored result ID, a path to any JSON file (such as a
+downloaded sarif.json), or - for stdin, which accepts the output of
+"gavel analyze --no-store". An empty pointer ('') prints the whole document.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQuery(cmd.Context(), cmd.OutOrStdout(), cmd.InOrStdin(), flagQueryOutput, args[0], args[1])
+		},
+	}
+	queryCmd.Flags().StringVar(&flagQueryOutput, "output", ".gavel/results", "Directory containing analysis results")
+
+	rootCmd.AddCommand(queryCmd)
+}
+
+func runQuery(ctx context.Context, w io.Writer, stdin io.Reader, outputDir, ref, pointer string) error {
+	if _, err := jsonpointer.Parse(pointer); err != nil {
+		return err
+	}
+
+	data, err := queryDocument(ctx, stdin, outputDir, ref)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("decoding %s: %w", ref, err)
+	}
+
+	value, err := jsonpointer.Resolve(doc, pointer)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding result: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// queryDocument reads the raw JSON named by ref: stdin for "-", an existing
+// file as is, and otherwise the SARIF log stored under that result ID.
+func queryDocument(ctx context.Context, stdin io.Reader, outputDir, ref string) ([]byte, error) {
+	if ref == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading stdin: %w", err)
+		}
+		return data, nil
+	}
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		return os.ReadFile(ref)
+	}
+	log, err := store.NewFileStore(outputDir).ReadSARIF(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("reading result %s: %w", ref, err)
+	}
+	return json.Marshal(log)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+func queryTestLog() *sarif.Log {
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = []sarif.Result{
+		{RuleID: "S2068", Level: "error", Message: sarif.Message{Text: "hardcoded password"}},
+		{RuleID: "S1135", Level: "note", Message: sarif.Message{Text: "todo"}},
+	}
+	return log
+}
+
+func TestRunQuery_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sarif.json")
+	data, err := json.Marshal(queryTestLog())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, runQuery(context.Background(), &out, nil, "", path, "/runs/0/results"))
+
+	var results []sarif.Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	assert.Equal(t, queryTestLog().Runs[0].Results, results)
+
+	out.Reset()
+	require.NoError(t, runQuery(context.Background(), &out, nil, "", path, "/runs/0/results/1/ruleId"))
+	assert.Equal(t, "\"S1135\"\n", out.String())
+}
+
+func TestRunQuery_StoredResultAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	id, err := store.NewFileStore(dir).WriteSARIF(context.Background(), queryTestLog())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, runQuery(context.Background(), &out, nil, dir, id, "/runs/0/results/0/level"))
+	assert.Equal(t, "\"error\"\n", out.String())
+
+	out.Reset()
+	stdin := strings.NewReader(`{"findings": 2, "sarif": {"version": "2.1.0"}}`)
+	require.NoError(t, runQuery(context.Background(), &out, stdin, dir, "-", "/sarif/version"))
+	assert.Equal(t, "\"2.1.0\"\n", out.String())
+}
+
+func TestRunQuery_InvalidPointer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sarif.json")
+	data, err := json.Marshal(queryTestLog())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	err = runQuery(context.Background(), &bytes.Buffer{}, nil, "", path, "runs/0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `must be empty or start with "/"`)
+
+	err = runQuery(context.Background(), &bytes.Buffer{}, nil, "", path, "/runs/0/results/5")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 5 out of range at /runs/0/results (length 2)")
+}
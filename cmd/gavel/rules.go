@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/rules/import/semgrep"
+)
+
+var (
+	flagRulesPolicyDir  string
+	flagRulesImportFrom string
+	flagRulesImportOut  string
+)
+
+func init() {
+	rulesCmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Work with custom analysis rules",
+	}
+
+	lintCmd := &cobra.Command{
+		Use:   "lint [file-or-dir...]",
+		Short: "Check custom rule files for problems",
+		Long: `Check custom rule files and report every problem found. Errors are rules that
+fail to load or can never produce a finding: missing required fields, invalid
+regexes or queries, unknown ast_check names or levels, confidence out of range,
+and IDs defined twice. Warnings flag rules that load but are probably wrong,
+such as unknown languages or an ID that replaces a built-in rule.
+
+Without arguments, lints the rules directory next to policies.yaml
+(.gavel/rules). Exits with status 1 when there are errors.`,
+		RunE: runRulesLint,
+	}
+	lintCmd.Flags().StringVar(&flagRulesPolicyDir, "policies", ".gavel", "Directory containing policies.yaml; its rules subdirectory is linted by default")
+
+	importCmd := &cobra.Command{
+		Use:   "import --from semgrep <file-or-dir...>",
+		Short: "Convert rules from another tool into Gavel rule YAML",
+		Long: `Convert rules written for another tool into a Gavel rule file. Directories are
+searched recursively for .yaml and .yml files.
+
+The semgrep importer converts rules whose matching logic is a pattern-regex,
+a single-line pattern, or a pattern-either of those, keeping severity,
+languages, CWE and OWASP IDs, confidence and references. Semgrep patterns are
+approximated as regexes, so review converted rules before relying on them.
+Rules that cannot be converted (patterns, pattern-not, pattern-inside, taint
+mode, unsupported languages) are listed on stderr with the reason.
+
+The result is written to stdout, or to --output.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runRulesImport,
+	}
+	importCmd.Flags().StringVar(&flagRulesImportFrom, "from", "", "Source rule format (semgrep)")
+	importCmd.Flags().StringVarP(&flagRulesImportOut, "output", "o", "", "Write the converted rules to this file instead of stdout")
+	importCmd.MarkFlagRequired("from")
+
+	rulesCmd.AddCommand(lintCmd, importCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
+
+func runRulesLint(cmd *cobra.Command, args []string) error {
+	paths := args
+	if len(paths) == 0 {
+		dir := filepath.Join(flagRulesPolicyDir, "rules")
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(cmd.OutOrStdout(), "no rules directory at %s\n", dir)
+			return nil
+		}
+		paths = []string{dir}
+	}
+
+	files, problems, err := rules.LintPaths(paths)
+	if err != nil {
+		return err
+	}
+
+	errCount := 0
+	for _, p := range problems {
+		if p.Severity == rules.LintError {
+			errCount++
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), p)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%d files checked: %d errors, %d warnings\n", files, errCount, len(problems)-errCount)
+
+	if errCount > 0 {
+		return exitWith(cmd, 1, "rules lint found %d errors", errCount)
+	}
+	return nil
+}
+
+func runRulesImport(cmd *cobra.Command, args []string) error {
+	if flagRulesImportFrom != "semgrep" {
+		return fmt.Errorf("unsupported --from %q (supported: semgrep)", flagRulesImportFrom)
+	}
+
+	var files []string
+	for _, arg := range args {
+		err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(path))
+			if !d.IsDir() && (path == arg || ext == ".yaml" || ext == ".yml") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var converted []rules.Rule
+	seen := make(map[string]bool)
+	skipped := 0
+	stderr := cmd.ErrOrStderr()
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", f, err)
+		}
+		res, err := semgrep.Convert(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		for _, s := range res.Skipped {
+			fmt.Fprintf(stderr, "%s: skipped %s: %s\n", f, s.ID, s.Reason)
+		}
+		skipped += len(res.Skipped)
+		for _, r := range res.Rules {
+			if seen[r.ID] {
+				fmt.Fprintf(stderr, "%s: skipped %s: duplicate rule ID\n", f, r.ID)
+				skipped++
+				continue
+			}
+			seen[r.ID] = true
+			converted = append(converted, r)
+		}
+	}
+
+	out, err := yaml.Marshal(rules.RuleFile{Rules: converted})
+	if err != nil {
+		return fmt.Errorf("encoding rules: %w", err)
+	}
+	if flagRulesImportOut == "" {
+		cmd.OutOrStdout().Write(out)
+	} else if err := os.WriteFile(flagRulesImportOut, out, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", flagRulesImportOut, err)
+	}
+	fmt.Fprintf(stderr, "%d files read: %d rules converted, %d skipped\n", len(files), len(converted), skipped)
+	return nil
+}
@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/metrics"
 	"github.com/chris-regnier/gavel/internal/server"
+	"github.com/chris-regnier/gavel/internal/server/middleware"
 	"github.com/chris-regnier/gavel/internal/service"
 	"github.com/chris-regnier/gavel/internal/store"
 )
@@ -25,6 +29,9 @@ var (
 	flagServeMaxConc      int
 	flagServeReadTimeout  time.Duration
 	flagServeWriteTimeout time.Duration
+	flagServeMetrics      bool
+	flagServeMachineCfg   string
+	flagServeProjectCfg   string
 )
 
 func init() {
@@ -42,6 +49,9 @@ func init() {
 	cmd.Flags().IntVar(&flagServeMaxConc, "max-concurrent", 10, "Max concurrent analysis jobs")
 	cmd.Flags().DurationVar(&flagServeReadTimeout, "read-timeout", 30*time.Second, "HTTP read timeout")
 	cmd.Flags().DurationVar(&flagServeWriteTimeout, "write-timeout", 5*time.Minute, "HTTP write timeout (long for SSE)")
+	cmd.Flags().BoolVar(&flagServeMetrics, "metrics", false, "Expose Prometheus metrics at /metrics")
+	cmd.Flags().StringVar(&flagServeMachineCfg, "machine-config", "", "Machine-level config file for the server section (default: $HOME/.config/gavel/policies.yaml)")
+	cmd.Flags().StringVar(&flagServeProjectCfg, "project-config", ".gavel/policies.yaml", "Project-level config file for the server section")
 
 	rootCmd.AddCommand(cmd)
 }
@@ -50,21 +60,61 @@ func runServe(cmd *cobra.Command, args []string) error {
 	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Load the server section and pricing; analysis settings come with each request
+	if flagServeMachineCfg == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("getting home directory: %w", err)
+		}
+		flagServeMachineCfg = filepath.Join(home, ".config", "gavel", "policies.yaml")
+	}
+	cfg, err := config.LoadTiered(flagServeMachineCfg, flagServeProjectCfg)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := registerPersonas(filepath.Dir(flagServeProjectCfg)); err != nil {
+		return err
+	}
+	if err := cfg.ValidateSettings(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	auth := cfg.Server.Auth
+
 	// Load auth keys
 	authKeys := map[string]string{}
-	if flagServeAuthKeys != "" {
-		var err error
-		authKeys, err = loadAuthKeys(flagServeAuthKeys)
+	keysFile := flagServeAuthKeys
+	if keysFile == "" {
+		keysFile = auth.KeysFile
+	}
+	if keysFile != "" {
+		authKeys, err = loadAuthKeys(keysFile)
 		if err != nil {
 			return fmt.Errorf("loading auth keys: %w", err)
 		}
 	}
+	var verifiers []middleware.TokenVerifier
+	if auth.OIDC.Issuer != "" {
+		verifiers = append(verifiers, middleware.NewOIDCVerifier(auth.OIDC))
+	}
 
 	// Create store
-	fs := store.NewFileStore(flagServeStoreDir)
+	var fs store.Store = store.NewFileStore(flagServeStoreDir)
+	if auth.IsolateTenants {
+		fs = store.NewTenantFileStore(flagServeStoreDir)
+	}
 
-	// Create services
-	analyzeSvc := service.NewAnalyzeService(fs)
+	// Create services. Quotas are enforced from the usage the collector
+	// records, so one is kept even without --metrics.
+	var collector *metrics.Collector
+	if flagServeMetrics {
+		collector = metrics.NewCollector(metrics.WithPrometheus())
+	} else if auth.HasQuotas() {
+		collector = metrics.NewCollector()
+	}
+	analyzeSvc := service.NewAnalyzeService(fs).WithVersion(version).WithMetrics(collector)
+	if auth.HasQuotas() {
+		analyzeSvc = analyzeSvc.WithPricing(metrics.NewPriceTable(cfg.Pricing))
+	}
 	judgeSvc := service.NewJudgeService(fs, flagServeRegoDir)
 
 	// Build router
@@ -73,7 +123,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 		JudgeService:   judgeSvc,
 		Store:          fs,
 		AuthKeys:       authKeys,
+		Verifiers:      verifiers,
+		Limits:         auth.LimitsFor,
 		MaxConcurrent:  flagServeMaxConc,
+		Metrics:        collector,
 	})
 
 	// Start server
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// streamEvent is one NDJSON line written by analyze --stream. Type is
+// "preliminary" for a finding as its tier reports it, "error" for a tier
+// that failed on a file, "result" for a finding of the final report, and
+// "summary" for the last line, which carries the usual analyze summary.
+type streamEvent struct {
+	Type    string                 `json:"type"`
+	Tier    string                 `json:"tier,omitempty"`
+	File    string                 `json:"file,omitempty"`
+	Result  *sarif.Result          `json:"result,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+	Summary map[string]interface{} `json:"summary,omitempty"`
+}
+
+// resultStream writes analyze --stream events to w. Encoding errors are
+// remembered rather than returned from each call so the analyzer's result
+// handler stays simple; Err reports the first one.
+type resultStream struct {
+	enc *json.Encoder
+	err error
+}
+
+func newResultStream(w io.Writer) *resultStream {
+	return &resultStream{enc: json.NewEncoder(w)}
+}
+
+// Tier writes one preliminary event per finding in tr, or a single error
+// event if the tier failed. Preliminary findings have not been deduplicated,
+// baselined, calibrated or suppressed yet. Tier has the signature expected
+// by analyzer.WithResultHandler.
+func (s *resultStream) Tier(tr analyzer.TieredResult) {
+	if tr.Error != nil {
+		s.write(streamEvent{Type: "error", Tier: tr.Tier.String(), File: tr.FilePath, Error: tr.Error.Error()})
+		return
+	}
+	for i := range tr.Results {
+		s.write(streamEvent{Type: "preliminary", Tier: tr.Tier.String(), File: tr.FilePath, Result: &tr.Results[i]})
+	}
+}
+
+// Results writes one result event per finding of the final report, taking
+// the tier and file from each result.
+func (s *resultStream) Results(results []sarif.Result) {
+	for i := range results {
+		r := &results[i]
+		ev := streamEvent{Type: "result", Result: r}
+		ev.Tier, _ = r.Properties["gavel/tier"].(string)
+		if len(r.Locations) > 0 {
+			ev.File = r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+		}
+		s.write(ev)
+	}
+}
+
+// Summary writes the final summary event.
+func (s *resultStream) Summary(summary map[string]interface{}) {
+	s.write(streamEvent{Type: "summary", Summary: summary})
+}
+
+// Err returns the first error encountered while writing events.
+func (s *resultStream) Err() error {
+	return s.err
+}
+
+func (s *resultStream) write(ev streamEvent) {
+	if s.err != nil {
+		return
+	}
+	if err := s.enc.Encode(ev); err != nil {
+		s.err = fmt.Errorf("writing stream event: %w", err)
+	}
+}
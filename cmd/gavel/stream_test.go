@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/suppression"
+)
+
+func decodeStream(t *testing.T, data []byte) []streamEvent {
+	t.Helper()
+	var events []streamEvent
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		var ev streamEvent
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &ev), "line %q", sc.Text())
+		events = append(events, ev)
+	}
+	require.NoError(t, sc.Err())
+	return events
+}
+
+func TestResultStream(t *testing.T) {
+	var buf bytes.Buffer
+	s := newResultStream(&buf)
+
+	s.Tier(analyzer.TieredResult{Tier: analyzer.TierInstant, FilePath: "a.go", Results: []sarif.Result{
+		{RuleID: "S2068", Message: sarif.Message{Text: "password"}},
+		{RuleID: "S1135", Message: sarif.Message{Text: "todo"}},
+	}})
+	s.Tier(analyzer.TieredResult{Tier: analyzer.TierComprehensive, FilePath: "a.go", Error: errors.New("timeout")})
+	s.Results([]sarif.Result{{
+		RuleID:     "S2068",
+		Message:    sarif.Message{Text: "password"},
+		Locations:  []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{ArtifactLocation: sarif.ArtifactLocation{URI: "a.go"}}}},
+		Properties: map[string]interface{}{"gavel/tier": "instant"},
+	}})
+	s.Summary(map[string]interface{}{"findings": 1})
+	require.NoError(t, s.Err())
+
+	events := decodeStream(t, buf.Bytes())
+	require.Len(t, events, 5)
+	assert.Equal(t, "preliminary", events[0].Type)
+	assert.Equal(t, "instant", events[0].Tier)
+	assert.Equal(t, "a.go", events[0].File)
+	require.NotNil(t, events[0].Result)
+	assert.Equal(t, "S2068", events[0].Result.RuleID)
+	assert.Equal(t, "S1135", events[1].Result.RuleID)
+	assert.Equal(t, streamEvent{Type: "error", Tier: "comprehensive", File: "a.go", Error: "timeout"}, events[2])
+	assert.Equal(t, "result", events[3].Type)
+	assert.Equal(t, "instant", events[3].Tier)
+	assert.Equal(t, "a.go", events[3].File)
+	assert.Equal(t, "S2068", events[3].Result.RuleID)
+	assert.Equal(t, "summary", events[4].Type)
+	assert.EqualValues(t, 1, events[4].Summary["findings"])
+}
+
+func TestResultStream_WithTieredAnalyzer(t *testing.T) {
+	var buf bytes.Buffer
+	s := newResultStream(&buf)
+	ta := analyzer.NewTieredAnalyzer(nil, analyzer.WithResultHandler(s.Tier))
+
+	arts := []input.Artifact{
+		{Path: "a.go", Content: "package a\n\nvar password = \"hunter2\"\n", Kind: input.KindFile},
+		{Path: "b.go", Content: "package b\n\n// TODO: remove\n", Kind: input.KindFile},
+	}
+	results, err := ta.Analyze(context.Background(), arts, nil, "")
+	require.NoError(t, err)
+	require.NoError(t, s.Err())
+
+	files := map[string]bool{}
+	for _, ev := range decodeStream(t, buf.Bytes()) {
+		assert.Equal(t, "preliminary", ev.Type)
+		files[ev.File] = true
+	}
+	assert.Equal(t, map[string]bool{"a.go": true, "b.go": true}, files)
+	assert.NotEmpty(t, results)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestResultStream_WriteError(t *testing.T) {
+	s := newResultStream(failingWriter{})
+	s.Summary(map[string]interface{}{})
+	s.Summary(map[string]interface{}{})
+	require.Error(t, s.Err())
+	assert.Contains(t, s.Err().Error(), "broken pipe")
+}
+
+func TestRunAnalyze_StreamEndsWithProcessedResults(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.MkdirAll(src, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "fast.go"), []byte("package src\n"), 0644))
+	policyDir := filepath.Join(dir, ".gavel")
+	require.NoError(t, os.MkdirAll(policyDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "policies.yaml"), []byte(`provider:
+  name: ollama
+  ollama:
+    model: test-model
+    base_url: http://localhost:11434
+`), 0644))
+	require.NoError(t, suppression.Save(dir, []suppression.Suppression{{RuleID: "llm-finding", Reason: "noise"}}))
+
+	prevDir, prevPolicies, prevOutput, prevStream, prevInit := flagDir, flagPolicyDir, flagOutput, flagStream, initLLM
+	t.Cleanup(func() {
+		flagDir, flagPolicyDir, flagOutput, flagStream, initLLM = prevDir, prevPolicies, prevOutput, prevStream, prevInit
+	})
+	t.Setenv("HOME", dir)
+	flagDir, flagPolicyDir, flagOutput, flagStream = src, policyDir, filepath.Join(dir, "results"), true
+	initLLM = func(context.Context, *config.Config) (analyzer.BAMLClient, string, error) {
+		return stuckLLM{}, "persona", nil
+	}
+
+	out, err := captureStdout(t, func() error { return runAnalyze(findCommand(t, "analyze"), nil) })
+	require.NoError(t, err)
+
+	var preliminary, final []streamEvent
+	for _, ev := range decodeStream(t, []byte(out)) {
+		if ev.Result == nil || ev.Result.RuleID != "llm-finding" {
+			continue
+		}
+		switch ev.Type {
+		case "preliminary":
+			preliminary = append(preliminary, ev)
+		case "result":
+			final = append(final, ev)
+		}
+	}
+	require.Len(t, preliminary, 1)
+	assert.Empty(t, preliminary[0].Result.Suppressions, "tier output is streamed before suppressions apply")
+	require.Len(t, final, 1)
+	assert.NotEmpty(t, final[0].Result.Suppressions, "final results carry the suppression")
+	assert.Equal(t, "comprehensive", final[0].Tier)
+}
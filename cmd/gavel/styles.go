@@ -0,0 +1,34 @@
+package main
+
+import "github.com/charmbracelet/lipgloss"
+
+// Styles for the create wizard and the fix TUI
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#7D56F4")).
+			MarginLeft(2)
+
+	itemStyle = lipgloss.NewStyle().
+			PaddingLeft(4)
+
+	selectedItemStyle = lipgloss.NewStyle().
+				PaddingLeft(2).
+				Foreground(lipgloss.Color("#7D56F4"))
+
+	descriptionStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#888888")).
+				MarginLeft(4)
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#626262")).
+			MarginTop(1)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF0000")).
+			Bold(true)
+
+	successStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#00AA00")).
+			Bold(true)
+)
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/evaluator"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func TestGateFailed(t *testing.T) {
+	cmd := &cobra.Command{}
+	err := gateFailed(cmd, "%d findings", 3)
+
+	var exit *exitError
+	require.True(t, errors.As(err, &exit))
+	assert.Equal(t, exitGateFailed, exit.code)
+	assert.Equal(t, "3 findings", err.Error())
+	assert.True(t, cmd.SilenceErrors)
+	assert.True(t, cmd.SilenceUsage)
+}
+
+func TestThresholdSummary(t *testing.T) {
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		{RuleID: "a", Level: "error"},
+		{RuleID: "b", Level: "warning"},
+		{RuleID: "c", Level: "warning", BaselineState: sarif.BaselineStateUnchanged},
+	}
+	th, err := evaluator.NewFindingThreshold("warning", 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"fail_on":      "warning",
+		"max_findings": 1,
+		"findings":     2,
+		"exceeded":     true,
+	}, thresholdSummary(th, log))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// runTiers returns the names of the selected tiers that a run can execute,
+// in tier order. The LLM tiers need a client, and the fast tier also needs
+// a fast_provider.
+func runTiers(selected map[analyzer.Tier]bool, cfg *config.Config, llm bool) []string {
+	var names []string
+	if selected[analyzer.TierInstant] {
+		names = append(names, analyzer.TierInstant.String())
+	}
+	if selected[analyzer.TierFast] && llm && cfg.FastProvider.Name != "" {
+		names = append(names, analyzer.TierFast.String())
+	}
+	if selected[analyzer.TierComprehensive] && llm {
+		names = append(names, analyzer.TierComprehensive.String())
+	}
+	return names
+}
+
+// tierSummary counts log's findings by their gavel/tier, listing every tier
+// in ran even when it found nothing. Results without a tier, such as
+// suppression diagnostics, are not counted.
+func tierSummary(log *sarif.Log, ran []string) map[string]int {
+	counts := make(map[string]int, len(ran))
+	for _, name := range ran {
+		counts[name] = 0
+	}
+	if log == nil || len(log.Runs) == 0 {
+		return counts
+	}
+	for _, r := range log.Runs[0].Results {
+		if tier, ok := r.Properties["gavel/tier"].(string); ok && tier != "" {
+			counts[tier]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func TestRunTiers(t *testing.T) {
+	all := map[analyzer.Tier]bool{analyzer.TierInstant: true, analyzer.TierFast: true, analyzer.TierComprehensive: true}
+	cfg := &config.Config{}
+
+	assert.Equal(t, []string{"instant", "comprehensive"}, runTiers(all, cfg, true), "the fast tier needs a fast_provider")
+	assert.Equal(t, []string{"instant"}, runTiers(all, cfg, false), "the LLM tiers need a client")
+
+	cfg.FastProvider.Name = "llamacpp"
+	assert.Equal(t, []string{"instant", "fast", "comprehensive"}, runTiers(all, cfg, true))
+	assert.Equal(t, []string{"fast"}, runTiers(map[analyzer.Tier]bool{analyzer.TierFast: true}, cfg, true))
+}
+
+func TestTierSummary(t *testing.T) {
+	log := &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		{RuleID: "a", Properties: map[string]interface{}{"gavel/tier": "instant"}},
+		{RuleID: "b", Properties: map[string]interface{}{"gavel/tier": "comprehensive"}},
+		{RuleID: "c", Properties: map[string]interface{}{"gavel/tier": "instant"}},
+		{RuleID: "d"},
+	}}}}
+
+	assert.Equal(t, map[string]int{"instant": 2, "fast": 0, "comprehensive": 1},
+		tierSummary(log, []string{"instant", "fast", "comprehensive"}))
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/baseline"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/lsp"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/suppression"
+)
+
+var (
+	flagWatchDir         string
+	flagWatchPolicyDir   string
+	flagWatchRulesDir    string
+	flagWatchFormat      string
+	flagWatchDebounce    time.Duration
+	flagWatchInterval    time.Duration
+	flagWatchInstantOnly bool
+	flagWatchBaseline    string
+)
+
+func init() {
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Re-analyze files in a directory as they change",
+		Long: `Watch a directory and run tiered analysis on each file that changes,
+printing findings as each tier completes. Changes are batched with the same
+debounce used by the language server, and files are selected by the
+lsp.watcher watch and ignore patterns in policies.yaml. Suppressed findings,
+and with --baseline those already in a baseline file, are not printed.
+
+Results are printed, not stored; run analyze for a stored SARIF log.`,
+		Args: cobra.NoArgs,
+		RunE: runWatch,
+	}
+
+	watchCmd.Flags().StringVar(&flagWatchDir, "dir", ".", "Directory to watch")
+	watchCmd.Flags().StringVar(&flagWatchPolicyDir, "policies", ".gavel", "Directory containing policies.yaml")
+	watchCmd.Flags().StringVar(&flagWatchRulesDir, "rules-dir", "", "Directory containing custom rule YAML files")
+	watchCmd.Flags().StringVar(&flagWatchFormat, "format", "pretty", "Output format: pretty or ndjson (the analyze --stream event format)")
+	watchCmd.Flags().DurationVar(&flagWatchDebounce, "debounce", 300*time.Millisecond, "Quiet period after the last change before analysis runs")
+	watchCmd.Flags().DurationVar(&flagWatchInterval, "interval", 500*time.Millisecond, "How often to scan the directory for changes")
+	watchCmd.Flags().BoolVar(&flagWatchInstantOnly, "instant-only", false, "Run only the instant tier (regex and AST rules) and skip the LLM tiers")
+	watchCmd.Flags().StringVar(&flagWatchBaseline, "baseline", "", "Baseline file written by gavel baseline create; findings it lists are not printed")
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if flagWatchFormat != "pretty" && flagWatchFormat != "ndjson" {
+		return fmt.Errorf("unknown format %q (supported: pretty, ndjson)", flagWatchFormat)
+	}
+	if flagWatchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	machineConfig := os.ExpandEnv("$HOME/.config/gavel/policies.yaml")
+	projectConfig := flagWatchPolicyDir + "/policies.yaml"
+	cfg, err := config.LoadTiered(machineConfig, projectConfig)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := registerPersonas(flagWatchPolicyDir); err != nil {
+		return err
+	}
+	if personaFlag, _ := cmd.Flags().GetString("persona"); personaFlag != "" {
+		cfg.Persona = personaFlag
+	}
+	if err := cfg.ValidateSettings(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	userRulesDir := os.ExpandEnv("$HOME/.config/gavel/rules")
+	projectRulesDir := filepath.Join(flagWatchPolicyDir, "rules")
+	if flagWatchRulesDir != "" {
+		projectRulesDir = flagWatchRulesDir
+	}
+	loadedRules, err := rules.LoadRules(userRulesDir, projectRulesDir)
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+
+	var client analyzer.BAMLClient
+	var personaPrompt string
+	responses := responseCache(cfg, flagWatchPolicyDir)
+	if !flagWatchInstantOnly {
+		client, personaPrompt, _, err = prepareLLM(ctx, cfg, false, initLLM)
+		if err != nil {
+			return err
+		}
+		client = cachingClient(client, responses, cfg.Provider)
+		if cfg.StrictFilter {
+			if analyzer.IsProsePersona(cfg.Persona) {
+				personaPrompt += analyzer.ProseApplicabilityFilterPrompt
+			} else {
+				personaPrompt += analyzer.ApplicabilityFilterPrompt
+			}
+		}
+	}
+
+	w := &watchSession{
+		policies:        cfg.Policies,
+		personaPrompt:   personaPrompt,
+		suppressionRoot: filepath.Dir(flagWatchPolicyDir),
+		out:             newWatchOutput(cmd.OutOrStdout(), flagWatchFormat),
+	}
+	if flagWatchBaseline != "" {
+		f, err := baseline.Load(flagWatchBaseline)
+		if err != nil {
+			return fmt.Errorf("loading baseline: %w", err)
+		}
+		root, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		w.out.filter.setBaseline(f, root)
+	}
+	tieredOpts := []analyzer.TieredAnalyzerOption{
+		analyzer.WithInstantPatterns(loadedRules),
+		analyzer.WithResultHandler(w.out.Tier),
+		analyzer.WithPathOverrides(cfg.PathOverrides),
+		analyzer.WithSecretScanner(analyzer.SecretScanner(cfg.Secrets)),
+		analyzer.WithParseErrorPolicy(analyzer.ParseErrorAction(cfg.ParseErrors.Action), cfg.ParseErrors.Retries),
+		analyzer.WithEscalation(cfg.Escalation),
+	}
+	if d := cfg.Provider.RequestTimeoutDuration(); d > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredRequestTimeout(d))
+	}
+	if n := cfg.Provider.RequestTokenBudget(); n > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredTokenBudget(n))
+	}
+	if n := cfg.Provider.BatchTokens; n > 0 {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredBatching(n))
+	}
+	if c := analysisCache(cfg, loadedRules, flagWatchPolicyDir); c != nil {
+		tieredOpts = append(tieredOpts, analyzer.WithTieredCache(c))
+	}
+	if cfg.Cache.Normalize {
+		tieredOpts = append(tieredOpts, analyzer.WithNormalizedCacheKeys(true))
+	}
+	tieredOpts = append(tieredOpts, analyzer.WithContextResolver(contextResolver(cfg)))
+	if client != nil {
+		tieredOpts = append(tieredOpts, analyzer.WithPolicyRoutes(analyzer.PolicyRoutes(cfg, cachingClients(responses, analyzer.NewProviderClient))))
+		tieredOpts = append(tieredOpts, analyzer.FastTierOptions(cfg, cachingClients(responses, analyzer.NewProviderClient))...)
+	}
+	w.ta = analyzer.NewTieredAnalyzer(client, tieredOpts...)
+
+	watcherCfg := lsp.DefaultWatcherConfig()
+	watcherCfg.DebounceDuration = flagWatchDebounce
+	watcherCfg.ParallelFiles = 1 // one batch per quiet period; the analyzer parallelizes within it
+	if len(cfg.LSP.Watcher.WatchPatterns) > 0 {
+		watcherCfg.WatchPatterns = cfg.LSP.Watcher.WatchPatterns
+	}
+	if len(cfg.LSP.Watcher.IgnorePatterns) > 0 {
+		watcherCfg.IgnorePatterns = cfg.LSP.Watcher.IgnorePatterns
+	}
+	watcher := lsp.NewDebouncedWatcherWithConfig(watcherCfg, func(files []string) {
+		w.analyzeFiles(ctx, files)
+	})
+	defer watcher.Stop()
+
+	poller, err := newDirPoller(flagWatchDir, watcherCfg.WatchPatterns, watcherCfg.IgnorePatterns)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Watching %s (%d files); press Ctrl-C to stop\n", flagWatchDir, poller.Len())
+
+	ticker := time.NewTicker(flagWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return w.out.Err()
+		case <-ticker.C:
+			changed, err := poller.Scan()
+			if err != nil {
+				slog.Warn("scanning for changes", "dir", flagWatchDir, "err", err)
+				continue
+			}
+			for _, path := range changed {
+				watcher.FileChanged(path)
+			}
+		}
+	}
+}
+
+// watchSession analyzes the files of each debounced batch. Batches run one
+// at a time so their output does not interleave.
+type watchSession struct {
+	ta              *analyzer.TieredAnalyzer
+	policies        map[string]config.Policy
+	personaPrompt   string
+	suppressionRoot string // project directory holding .gavel/suppressions.yaml
+	out             *watchOutput
+
+	mu sync.Mutex
+}
+
+// analyzeFiles reads files, skipping any that were removed or are not
+// UTF-8, and analyzes them. Findings reach the output through the
+// analyzer's result handler as each tier completes; a summary follows.
+// Suppressions are reloaded for each batch, so a gavel suppress run while
+// watching takes effect on the next change.
+func (w *watchSession) analyzeFiles(ctx context.Context, files []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sort.Strings(files)
+	var artifacts []input.Artifact
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				slog.Warn("reading changed file", "path", path, "err", err)
+			}
+			continue
+		}
+		if !utf8.Valid(data) {
+			continue
+		}
+		artifacts = append(artifacts, input.Artifact{Path: path, Content: string(data), Kind: input.KindFile})
+	}
+	if len(artifacts) == 0 {
+		return
+	}
+
+	supps, err := suppression.Load(w.suppressionRoot)
+	if err != nil {
+		slog.Warn("failed to load suppressions", "err", err)
+	}
+	w.out.filter.suppressions = supps
+	w.out.Start(artifacts)
+	results, err := w.ta.Analyze(ctx, artifacts, w.policies, w.personaPrompt)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("analysis failed", "err", err)
+		}
+		return
+	}
+	w.out.Done(len(artifacts), results)
+}
+
+// watchOutput renders watch events as pretty terminal text or as NDJSON
+// events in the analyze --stream format, leaving out the findings filter
+// hides. Start, Tier and Done all run on the goroutine analyzing a batch,
+// since the analyzer calls its result handler from the goroutine running
+// Analyze; mu orders them with Err, which runWatch calls when it stops.
+type watchOutput struct {
+	mu     sync.Mutex
+	w      io.Writer
+	stream *resultStream // set for ndjson
+	filter watchFilter
+}
+
+func newWatchOutput(w io.Writer, format string) *watchOutput {
+	o := &watchOutput{w: w}
+	if format == "ndjson" {
+		o.stream = newResultStream(w)
+	}
+	return o
+}
+
+// Start announces a batch of changed files.
+func (o *watchOutput) Start(artifacts []input.Artifact) {
+	if o.stream != nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	paths := make([]string, len(artifacts))
+	for i, a := range artifacts {
+		paths[i] = a.Path
+	}
+	fmt.Fprintf(o.w, "\n[%s] analyzing %d changed file(s): %v\n", time.Now().Format("15:04:05"), len(paths), paths)
+}
+
+// Tier reports one tier's findings for a file. It has the signature
+// expected by analyzer.WithResultHandler.
+func (o *watchOutput) Tier(tr analyzer.TieredResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tr.Results, _ = o.filter.visible(tr.Results)
+	if o.stream != nil {
+		o.stream.Tier(tr)
+		return
+	}
+	if tr.Error != nil {
+		fmt.Fprintf(o.w, "  %s [%s] failed: %v\n", tr.FilePath, tr.Tier, tr.Error)
+		return
+	}
+	for _, r := range tr.Results {
+		line := 0
+		if len(r.Locations) > 0 && r.Locations[0].PhysicalLocation.Region.StartLine > 0 {
+			line = r.Locations[0].PhysicalLocation.Region.StartLine
+		}
+		fmt.Fprintf(o.w, "  %s:%d  %-7s  %s  %s  [%s]\n", tr.FilePath, line, r.Level, r.RuleID, r.Message.Text, tr.Tier)
+	}
+}
+
+// Done reports the end of a batch with its deduplicated findings and how
+// many were hidden.
+func (o *watchOutput) Done(files int, results []sarif.Result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	results, hidden := o.filter.visible(results)
+	if o.stream != nil {
+		o.stream.Results(results)
+		summary := map[string]interface{}{"files": files, "findings": len(results)}
+		if hidden > 0 {
+			summary["hidden"] = hidden
+		}
+		o.stream.Summary(summary)
+		return
+	}
+	if hidden > 0 {
+		fmt.Fprintf(o.w, "  done: %d finding(s) in %d file(s), %d suppressed or baselined\n", len(results), files, hidden)
+		return
+	}
+	fmt.Fprintf(o.w, "  done: %d finding(s) in %d file(s)\n", len(results), files)
+}
+
+// Err returns the first error encountered while writing NDJSON events.
+func (o *watchOutput) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stream != nil {
+		return o.stream.Err()
+	}
+	return nil
+}
+
+// watchFilter hides the findings analyze would mark suppressed or, when a
+// baseline is set, unchanged since the baseline.
+type watchFilter struct {
+	suppressions []suppression.Suppression
+	baseline     map[string]bool // baseline fingerprints
+	root         string          // directory baseline paths are relative to
+}
+
+func (f *watchFilter) setBaseline(b *baseline.File, root string) {
+	f.baseline = make(map[string]bool, len(b.Findings))
+	for _, e := range b.Findings {
+		f.baseline[e.Fingerprint] = true
+	}
+	f.root = root
+}
+
+// visible returns the results f does not hide, and how many it hid.
+func (f *watchFilter) visible(results []sarif.Result) ([]sarif.Result, int) {
+	var out []sarif.Result
+	for _, r := range results {
+		if !f.hidden(r) {
+			out = append(out, r)
+		}
+	}
+	return out, len(results) - len(out)
+}
+
+func (f *watchFilter) hidden(r sarif.Result) bool {
+	path := ""
+	if len(r.Locations) > 0 {
+		path = r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+	}
+	if suppression.Match(f.suppressions, r.RuleID, path) != nil {
+		return true
+	}
+	if len(f.baseline) == 0 {
+		return false
+	}
+	// Tier results are not assembled yet, so compute the content
+	// fingerprint on a copy rather than sharing the result's map.
+	r.Fingerprints = nil
+	sarif.SetContentFingerprint(&r)
+	return f.baseline[baseline.Fingerprint(r, f.root)]
+}
+
+// dirPoller detects changed files by comparing modification times and
+// sizes between scans of a directory tree.
+type dirPoller struct {
+	root          string
+	watchPatterns []string
+	ignore        []string
+	seen          map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// newDirPoller records the current state of root so that only later
+// changes are reported. Files are selected with lsp.ShouldWatchPath, and
+// directories matching an ignore pattern are not descended into.
+func newDirPoller(root string, watchPatterns, ignorePatterns []string) (*dirPoller, error) {
+	p := &dirPoller{root: root, watchPatterns: watchPatterns, ignore: ignorePatterns}
+	stamps, err := p.walk()
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", root, err)
+	}
+	p.seen = stamps
+	return p, nil
+}
+
+// Len returns the number of watched files.
+func (p *dirPoller) Len() int {
+	return len(p.seen)
+}
+
+// Scan returns the watched files that were created or modified since the
+// previous scan, in lexical order.
+func (p *dirPoller) Scan() ([]string, error) {
+	stamps, err := p.walk()
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for path, s := range stamps {
+		if prev, ok := p.seen[path]; !ok || prev != s {
+			changed = append(changed, path)
+		}
+	}
+	p.seen = stamps
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func (p *dirPoller) walk() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	err := filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path != p.root {
+				return nil // removed mid-walk
+			}
+			return err
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Directory ignore patterns match on a path inside the directory.
+			if path != p.root && !lsp.ShouldWatchPath(abs+"/", nil, p.ignore) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !lsp.ShouldWatchPath(abs, p.watchPatterns, p.ignore) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return stamps, err
+}
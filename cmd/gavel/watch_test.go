@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/baseline"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/lsp"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/suppression"
+)
+
+func TestDirPoller(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) string {
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	main := write("main.go", "package main\n")
+	write("README.md", "# readme\n")
+
+	cfg := lsp.DefaultWatcherConfig()
+	p, err := newDirPoller(dir, cfg.WatchPatterns, cfg.IgnorePatterns)
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Len(), "only files matching the watch patterns are tracked")
+
+	changed, err := p.Scan()
+	require.NoError(t, err)
+	assert.Empty(t, changed, "files present at startup are not reported")
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	added := write("pkg/util.py", "x = 1\n")
+	write("node_modules/dep/index.js", "module.exports = 1\n")
+	write("notes.txt", "hello\n")
+
+	changed, err = p.Scan()
+	require.NoError(t, err)
+	assert.Equal(t, []string{main, added}, changed)
+
+	changed, err = p.Scan()
+	require.NoError(t, err)
+	assert.Empty(t, changed, "unchanged files are reported once")
+}
+
+func TestWatchSession_NDJSON(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "a.go")
+	require.NoError(t, os.WriteFile(secret, []byte("package a\n\nvar password = \"hunter2\"\n"), 0o644))
+
+	var buf bytes.Buffer
+	w := &watchSession{out: newWatchOutput(&buf, "ndjson")}
+	w.ta = analyzer.NewTieredAnalyzer(nil, analyzer.WithResultHandler(w.out.Tier))
+
+	w.analyzeFiles(context.Background(), []string{secret, filepath.Join(dir, "deleted.go")})
+	require.NoError(t, w.out.Err())
+
+	events := decodeStream(t, buf.Bytes())
+	require.NotEmpty(t, events)
+	summary := events[len(events)-1]
+	assert.Equal(t, "summary", summary.Type)
+	assert.EqualValues(t, 1, summary.Summary["files"], "removed files are skipped")
+	assert.NotZero(t, summary.Summary["findings"])
+
+	types := map[string]int{}
+	for _, ev := range events[:len(events)-1] {
+		types[ev.Type]++
+		assert.Equal(t, secret, ev.File)
+	}
+	assert.NotZero(t, types["preliminary"], "tier findings stream as they arrive")
+	assert.EqualValues(t, summary.Summary["findings"], types["result"], "each final finding gets a result event")
+}
+
+func TestWatchSession_Pretty(t *testing.T) {
+	dir := t.TempDir()
+	todo := filepath.Join(dir, "b.go")
+	require.NoError(t, os.WriteFile(todo, []byte("package b\n\n// TODO: remove\n"), 0o644))
+
+	var buf bytes.Buffer
+	w := &watchSession{out: newWatchOutput(&buf, "pretty")}
+	w.ta = analyzer.NewTieredAnalyzer(nil, analyzer.WithResultHandler(w.out.Tier))
+
+	w.analyzeFiles(context.Background(), []string{todo})
+
+	out := buf.String()
+	assert.Contains(t, out, "analyzing 1 changed file(s)")
+	assert.Contains(t, out, todo+":3")
+	assert.Contains(t, out, "[instant]")
+	assert.Contains(t, out, "done:")
+}
+
+func TestWatchSession_HidesSuppressedAndBaselined(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "b.go")
+	content := "package b\n\n// TODO: remove\n\nvar password = \"hunter2\"\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	var buf bytes.Buffer
+	w := &watchSession{out: newWatchOutput(&buf, "pretty"), suppressionRoot: dir}
+	w.ta = analyzer.NewTieredAnalyzer(nil, analyzer.WithResultHandler(w.out.Tier))
+	w.analyzeFiles(context.Background(), []string{path})
+	require.Contains(t, buf.String(), path+":3")
+	require.Contains(t, buf.String(), path+":5")
+
+	// Suppress the TODO finding and baseline the password finding
+	require.NoError(t, suppression.Save(dir, []suppression.Suppression{{RuleID: "S1135", Reason: "tracked elsewhere"}}))
+	results, err := analyzer.NewTieredAnalyzer(nil).Analyze(context.Background(),
+		[]input.Artifact{{Path: path, Content: content, Kind: input.KindFile}}, nil, "")
+	require.NoError(t, err)
+	var line5 []sarif.Result
+	for _, r := range results {
+		if r.Locations[0].PhysicalLocation.Region.StartLine == 5 {
+			sarif.SetContentFingerprint(&r)
+			line5 = append(line5, r)
+		}
+	}
+	require.NotEmpty(t, line5)
+	f, _ := baseline.FromLog(&sarif.Log{Runs: []sarif.Run{{Results: line5}}}, dir)
+	w.out.filter.setBaseline(f, dir)
+
+	buf.Reset()
+	w.analyzeFiles(context.Background(), []string{path})
+	out := buf.String()
+	assert.NotContains(t, out, path+":3", "suppressed findings are not printed")
+	assert.NotContains(t, out, path+":5", "baselined findings are not printed")
+	assert.Contains(t, out, "done: 0 finding(s) in 1 file(s)")
+	assert.Contains(t, out, "suppressed or baselined")
+}
@@ -1,3 +1,5 @@
+//go:build !nobaml
+
 package main
 
 import (
@@ -16,6 +18,7 @@ import (
 
 	baml_client "github.com/chris-regnier/gavel/baml_client"
 	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/persona"
 )
 
 // Wizard state types
@@ -32,37 +35,6 @@ const (
 	stateError
 )
 
-// Styles
-var (
-	titleStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
-		MarginLeft(2)
-
-	itemStyle = lipgloss.NewStyle().
-		PaddingLeft(4)
-
-	selectedItemStyle = lipgloss.NewStyle().
-		PaddingLeft(2).
-		Foreground(lipgloss.Color("#7D56F4"))
-
-	descriptionStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
-		MarginLeft(4)
-
-	helpStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
-		MarginTop(1)
-
-	errorStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF0000")).
-		Bold(true)
-
-	successStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00AA00")).
-		Bold(true)
-)
-
 // Menu item
 type menuItem struct {
 	title       string
@@ -478,7 +450,17 @@ func saveResult(content string, configType string) (string, error) {
 	case "rule":
 		return saveToFile(content, ".gavel/rules/generated.yaml")
 	case "persona":
-		return saveToFile(content, ".gavel/personas.yaml")
+		// Personas are loaded one per file, so each gets its own file
+		// named after it rather than being appended to a shared one.
+		p, err := persona.Parse([]byte(content))
+		if err != nil {
+			return "", fmt.Errorf("invalid persona: %w", err)
+		}
+		path := filepath.Join(".gavel", "personas", p.Name+".yaml")
+		if _, err := os.Stat(path); err == nil {
+			return "", fmt.Errorf("%s already exists", path)
+		}
+		return saveToFile(content, path)
 	case "config":
 		return saveToFile(content, ".gavel/policies.yaml")
 	default:
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+// writableDir returns dir if files can be written there. Otherwise, as in a
+// read-only container filesystem, it warns and returns a fresh directory
+// under the system temp dir whose name starts with pattern. It returns ""
+// when neither is writable; what names the data in log messages.
+func writableDir(dir, pattern, what string) string {
+	err := store.CheckWritable(dir)
+	if err == nil {
+		return dir
+	}
+	tmp, tmpErr := os.MkdirTemp("", pattern)
+	if tmpErr != nil {
+		slog.Warn("no writable location; "+what+" will not be written", "dir", dir, "err", err, "temp_err", tmpErr)
+		return ""
+	}
+	slog.Warn(what+" directory is not writable; using a temporary directory", "dir", dir, "temp_dir", tmp, "err", err)
+	return tmp
+}
+
+// writeOutputFile writes data to path or, if path's directory is not
+// writable, to a file of the same name in a temporary directory. It returns
+// the path actually written, or "" after a warning when nothing could be.
+func writeOutputFile(path string, data []byte, pattern, what string) string {
+	dir := writableDir(filepath.Dir(path), pattern, what)
+	if dir == "" {
+		return ""
+	}
+	dest := filepath.Join(dir, filepath.Base(path))
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		slog.Warn(what+" could not be written", "path", dest, "err", err)
+		return ""
+	}
+	return dest
+}
+
+// storeResults writes log to a FileStore under outputDir, or under a
+// temporary directory if outputDir is not writable. It returns the result ID
+// and the directory actually used. Both are empty when noStore is set or no
+// location is writable; the caller then emits the SARIF itself.
+func storeResults(ctx context.Context, log *sarif.Log, outputDir string, noStore bool) (id, storedIn string, err error) {
+	if noStore {
+		return "", "", nil
+	}
+	if storedIn = writableDir(outputDir, "gavel-results-", "results"); storedIn == "" {
+		return "", "", nil
+	}
+	id, err = store.NewFileStore(storedIn).WriteSARIF(ctx, log)
+	if err != nil {
+		return "", "", fmt.Errorf("storing SARIF in %s: %w", storedIn, err)
+	}
+	return id, storedIn, nil
+}
+
+// recordStorage adds where log was stored to an analyze summary: its ID,
+// plus the directory when it differs from outputDir. When nothing was
+// stored the SARIF log itself is included so the results still reach stdout.
+func recordStorage(summary map[string]interface{}, log *sarif.Log, id, storedIn, outputDir string) {
+	if id == "" {
+		summary["sarif"] = log
+		return
+	}
+	summary["id"] = id
+	if storedIn != outputDir {
+		summary["output"] = storedIn
+	}
+}
+
+// recordRevision records the git commit checked out in dir as the run's
+// version control provenance, unless the run already has one. Outside a
+// repository the run is left unchanged.
+func recordRevision(ctx context.Context, log *sarif.Log, dir string) {
+	if len(log.Runs) == 0 || len(log.Runs[0].VersionControlProvenance) > 0 {
+		return
+	}
+	rev, err := input.GitRevision(ctx, dir)
+	if err != nil {
+		slog.Debug("no git revision recorded", "dir", dir, "err", err)
+		return
+	}
+	log.Runs[0].VersionControlProvenance = []sarif.VersionControlDetails{{
+		RepositoryURI: rev.Remote,
+		RevisionID:    rev.Commit,
+		Branch:        rev.Branch,
+	}}
+}
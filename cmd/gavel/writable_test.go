@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// unwritableDir returns a path beneath a regular file. It can never be
+// created, even as root, so it stands in for a read-only mount.
+func unwritableDir(t *testing.T) string {
+	t.Helper()
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	require.NoError(t, os.WriteFile(blocker, nil, 0644))
+	return filepath.Join(blocker, "results")
+}
+
+func writableTestLog() *sarif.Log {
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = []sarif.Result{{RuleID: "S2068", Level: "error", Message: sarif.Message{Text: "hardcoded password"}}}
+	return log
+}
+
+func TestStoreResults_Writable(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "results")
+
+	id, storedIn, err := storeResults(context.Background(), writableTestLog(), out, false)
+	require.NoError(t, err)
+	assert.Equal(t, out, storedIn)
+	assert.FileExists(t, filepath.Join(out, id, "sarif.json"))
+}
+
+func TestStoreResults_UnwritableFallsBackToTempDir(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	id, storedIn, err := storeResults(context.Background(), writableTestLog(), unwritableDir(t), false)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	assert.True(t, strings.HasPrefix(storedIn, os.TempDir()), "expected fallback under %s, got %s", os.TempDir(), storedIn)
+	assert.FileExists(t, filepath.Join(storedIn, id, "sarif.json"))
+}
+
+func TestStoreResults_NothingWritableEmitsToStdout(t *testing.T) {
+	t.Setenv("TMPDIR", unwritableDir(t))
+	log := writableTestLog()
+
+	id, storedIn, err := storeResults(context.Background(), log, unwritableDir(t), false)
+	require.NoError(t, err, "an unwritable filesystem must not fail the analysis")
+	assert.Empty(t, id)
+	assert.Empty(t, storedIn)
+
+	summary := map[string]interface{}{"findings": 1}
+	recordStorage(summary, log, id, storedIn, "unused")
+	assert.NotContains(t, summary, "id")
+	out, err := json.Marshal(summary)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"ruleId":"S2068"`, "the SARIF log should be emitted with the summary")
+}
+
+func TestRecordStorage(t *testing.T) {
+	summary := map[string]interface{}{}
+	recordStorage(summary, writableTestLog(), "abc", ".gavel/results", ".gavel/results")
+	assert.Equal(t, map[string]interface{}{"id": "abc"}, summary)
+
+	summary = map[string]interface{}{}
+	recordStorage(summary, writableTestLog(), "abc", "/tmp/gavel-results-1", ".gavel/results")
+	assert.Equal(t, map[string]interface{}{"id": "abc", "output": "/tmp/gavel-results-1"}, summary)
+}
+
+func TestStoreResults_NoStore(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "results")
+
+	id, storedIn, err := storeResults(context.Background(), writableTestLog(), out, true)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+	assert.Empty(t, storedIn)
+	assert.NoDirExists(t, out)
+}
+
+func TestWriteOutputFile_Writable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "applied.json")
+
+	assert.Equal(t, path, writeOutputFile(path, []byte("{}\n"), "gavel-applied-", "applied rules"))
+	assert.FileExists(t, path)
+}
+
+func TestWriteOutputFile_UnwritableFallsBackToTempDir(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	got := writeOutputFile(filepath.Join(unwritableDir(t), "applied.json"), []byte("{}\n"), "gavel-applied-", "applied rules")
+	require.NotEmpty(t, got)
+	assert.True(t, strings.HasPrefix(got, os.TempDir()), "expected fallback under %s, got %s", os.TempDir(), got)
+	assert.Equal(t, "applied.json", filepath.Base(got))
+	data, err := os.ReadFile(got)
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+}
+
+func TestWriteOutputFile_NothingWritableSkips(t *testing.T) {
+	t.Setenv("TMPDIR", unwritableDir(t))
+
+	assert.Empty(t, writeOutputFile(filepath.Join(unwritableDir(t), "applied.json"), []byte("{}\n"), "gavel-applied-", "applied rules"))
+}
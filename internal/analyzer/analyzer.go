@@ -2,8 +2,10 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/chris-regnier/gavel/internal/astcheck"
 	"github.com/chris-regnier/gavel/internal/config"
@@ -16,6 +18,16 @@ type BAMLClient interface {
 	AnalyzeCode(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]Finding, error)
 }
 
+// NewProviderClient creates the live client for cfg, wrapped in a
+// RateLimitedClient when cfg.RateLimit sets any limit or retries.
+func NewProviderClient(cfg config.ProviderConfig) BAMLClient {
+	live := NewBAMLLiveClient(cfg)
+	if cfg.RateLimit == (config.RateLimitConfig{}) {
+		return live
+	}
+	return NewRateLimitedClient(live, cfg.RateLimit)
+}
+
 // Finding represents a single finding returned by the BAML analysis.
 type Finding struct {
 	RuleID             string            `json:"ruleId"`
@@ -46,6 +58,8 @@ type RelatedLocation struct {
 type Analyzer struct {
 	client            BAMLClient
 	additionalContext string
+	requestTimeout    time.Duration
+	tokenBudget       int
 
 	// Cached function index for logical location enrichment. Avoids
 	// re-parsing and re-traversing the same file when Analyze is called
@@ -66,6 +80,31 @@ func WithAdditionalContext(ctx string) AnalyzerOption {
 	}
 }
 
+// ErrRequestTimeout marks an AnalyzeCode call cut off by
+// WithRequestTimeout, as opposed to one that hit the run's own deadline.
+var ErrRequestTimeout = errors.New("request timed out")
+
+// WithRequestTimeout bounds each AnalyzeCode call to d. A call that
+// exceeds the budget fails with ErrRequestTimeout, which also matches
+// context.DeadlineExceeded, without cancelling the parent context. Zero
+// disables the limit.
+func WithRequestTimeout(d time.Duration) AnalyzerOption {
+	return func(a *Analyzer) {
+		a.requestTimeout = d
+	}
+}
+
+// WithTokenBudget caps the estimated tokens of each AnalyzeCode call,
+// counting the code, policies, persona and additional context. An artifact
+// that does not fit is split into chunks (see ChunkContent) analyzed one
+// request each, and the findings are mapped back to the artifact's line
+// numbers. Zero or less sends every artifact whole.
+func WithTokenBudget(maxTokens int) AnalyzerOption {
+	return func(a *Analyzer) {
+		a.tokenBudget = maxTokens
+	}
+}
+
 // NewAnalyzer creates an Analyzer with the given BAMLClient and optional configuration.
 func NewAnalyzer(client BAMLClient, opts ...AnalyzerOption) *Analyzer {
 	a := &Analyzer{client: client}
@@ -100,89 +139,213 @@ func (a *Analyzer) Analyze(ctx context.Context, artifacts []input.Artifact, poli
 	var allResults []sarif.Result
 
 	for _, art := range artifacts {
-		// Prepend the filename so the LLM knows which file it's analyzing.
-		// Without this, models hallucinate conventional filenames (e.g. "handlers.go"
-		// instead of the actual "server.go"), causing ~50% of findings to reference
-		// nonexistent paths. See https://github.com/chris-regnier/gavel/issues/34.
-		code := art.Content
-		if art.Path != "" {
-			code = fmt.Sprintf("// File: %s\n%s", art.Path, art.Content)
-		}
-		findings, err := a.client.AnalyzeCode(ctx, code, policyText, personaPrompt, a.additionalContext)
+		findings, err := a.analyzeArtifact(ctx, art, policyText, personaPrompt)
 		if err != nil {
 			return nil, fmt.Errorf("analyzing %s: %w", art.Path, err)
 		}
 
-		// Build a function index once per artifact (cached across calls)
-		// so logical location lookups use pure Go without CGO overhead.
-		idx := a.getOrBuildIndex(art.Path, []byte(art.Content))
+		allResults = append(allResults, a.buildResults(art, findings)...)
+	}
 
-		for _, f := range findings {
-			path := f.FilePath
-			if path == "" {
-				path = art.Path
-			}
+	return allResults, nil
+}
 
-			region := sarif.Region{
-				StartLine: f.StartLine,
-				EndLine:   f.EndLine,
-				Snippet:   sarif.ExtractSnippet(art.Content, f.StartLine, f.EndLine),
-			}
+// buildResults converts the findings reported for art into SARIF results,
+// with snippets and logical locations taken from art's content.
+func (a *Analyzer) buildResults(art input.Artifact, findings []Finding) []sarif.Result {
+	var results []sarif.Result
+	// Build a function index once per artifact (cached across calls)
+	// so logical location lookups use pure Go without CGO overhead.
+	idx := a.getOrBuildIndex(art.Path, []byte(art.Content))
 
-			physLoc := sarif.PhysicalLocation{
-				ArtifactLocation: sarif.ArtifactLocation{URI: path},
-				Region:           region,
-				ContextRegion:    sarif.ExtractContextRegion(art.Content, f.StartLine, f.EndLine),
-			}
+	for _, f := range findings {
+		path := f.FilePath
+		if path == "" {
+			path = art.Path
+		}
 
-			loc := sarif.Location{
-				PhysicalLocation: physLoc,
-			}
-			if idx != nil {
-				if ll := astcheck.LogicalLocationFromIndex(idx, f.StartLine); ll != nil {
-					loc.LogicalLocations = []sarif.LogicalLocation{*ll}
-				}
-			}
+		region := sarif.Region{
+			StartLine: f.StartLine,
+			EndLine:   f.EndLine,
+			Snippet:   sarif.ExtractSnippet(art.Content, f.StartLine, f.EndLine),
+		}
 
-			result := sarif.Result{
-				RuleID:    f.RuleID,
-				Level:     f.Level,
-				Message:   sarif.Message{Text: f.Message},
-				Locations: []sarif.Location{loc},
-				Properties: map[string]interface{}{
-					"gavel/recommendation": f.Recommendation,
-					"gavel/explanation":    f.Explanation,
-					"gavel/confidence":     f.Confidence,
-				},
-			}
+		physLoc := sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: path},
+			Region:           region,
+			ContextRegion:    sarif.ExtractContextRegion(art.Content, f.StartLine, f.EndLine),
+		}
 
-			if related := buildRelatedLocations(f.RelatedLocations); len(related) > 0 {
-				result.RelatedLocations = related
+		loc := sarif.Location{
+			PhysicalLocation: physLoc,
+		}
+		if idx != nil {
+			if ll := astcheck.LogicalLocationFromIndex(idx, f.StartLine); ll != nil {
+				loc.LogicalLocations = []sarif.LogicalLocation{*ll}
 			}
+		}
 
-			if f.FixReplacementText != "" {
-				result.Fixes = []sarif.Fix{{
-					Description: sarif.Message{Text: f.Recommendation},
-					ArtifactChanges: []sarif.ArtifactChange{{
-						ArtifactLocation: sarif.ArtifactLocation{URI: path},
-						Replacements: []sarif.Replacement{{
-							DeletedRegion: sarif.Region{
-								StartLine: f.StartLine,
-								EndLine:   f.EndLine,
-							},
-							InsertedContent: &sarif.ArtifactContent{
-								Text: f.FixReplacementText,
-							},
-						}},
+		result := sarif.Result{
+			RuleID:    f.RuleID,
+			Level:     f.Level,
+			Message:   sarif.Message{Text: f.Message},
+			Locations: []sarif.Location{loc},
+			Properties: map[string]interface{}{
+				"gavel/recommendation": f.Recommendation,
+				"gavel/explanation":    f.Explanation,
+				"gavel/confidence":     f.Confidence,
+			},
+		}
+
+		if related := buildRelatedLocations(f.RelatedLocations); len(related) > 0 {
+			result.RelatedLocations = related
+		}
+
+		if f.FixReplacementText != "" {
+			result.Fixes = []sarif.Fix{{
+				Description: sarif.Message{Text: f.Recommendation},
+				ArtifactChanges: []sarif.ArtifactChange{{
+					ArtifactLocation: sarif.ArtifactLocation{URI: path},
+					Replacements: []sarif.Replacement{{
+						DeletedRegion: sarif.Region{
+							StartLine: f.StartLine,
+							EndLine:   f.EndLine,
+						},
+						InsertedContent: &sarif.ArtifactContent{
+							Text: f.FixReplacementText,
+						},
 					}},
-				}}
-			}
+				}},
+			}}
+		}
 
-			allResults = append(allResults, result)
+		results = append(results, result)
+	}
+	return results
+}
+
+// analyzeArtifact returns the findings for one artifact, splitting it into
+// chunks when it does not fit the token budget.
+func (a *Analyzer) analyzeArtifact(ctx context.Context, art input.Artifact, policyText, personaPrompt string) ([]Finding, error) {
+	reqs, err := a.requests(art, policyText, personaPrompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqs) == 1 && reqs[0].chunk == nil {
+		return a.analyzeCode(ctx, reqs[0].code, policyText, personaPrompt, reqs[0].additionalContext)
+	}
+
+	var findings []Finding
+	for _, r := range reqs {
+		chunkFindings, err := a.analyzeCode(ctx, r.code, policyText, personaPrompt, r.additionalContext)
+		if err != nil {
+			return nil, fmt.Errorf("lines %d-%d: %w", r.chunk.StartLine, r.chunk.EndLine, err)
+		}
+		for _, f := range chunkFindings {
+			findings = append(findings, shiftFinding(f, art.Path, r.chunk.StartLine-1))
 		}
 	}
+	return findings, nil
+}
 
-	return allResults, nil
+// request is one AnalyzeCode call made for an artifact.
+type request struct {
+	code              string
+	additionalContext string
+	chunk             *Chunk // The part of the artifact sent, or nil for all of it
+}
+
+// tokens estimates the request's prompt size, excluding the fixed BAML
+// template.
+func (r request) tokens(policyText, personaPrompt string) int {
+	return estimateTokens(r.code, policyText, personaPrompt, r.additionalContext)
+}
+
+// requests returns the AnalyzeCode calls analyzeArtifact makes for art: one
+// for the whole file, or one per chunk when it does not fit the token
+// budget.
+func (a *Analyzer) requests(art input.Artifact, policyText, personaPrompt string) ([]request, error) {
+	if a.tokenBudget <= 0 || estimateTokens(fileHeader(art.Path), art.Content, policyText, personaPrompt, a.additionalContext) <= a.tokenBudget {
+		return []request{{code: fileHeader(art.Path) + art.Content, additionalContext: a.additionalContext}}, nil
+	}
+
+	// Size the chunks for the worst case, a note naming the last of many
+	// parts.
+	overhead := estimateTokens(fileHeader(art.Path), policyText, personaPrompt, a.additionalContext, chunkNote(999, 999))
+	if overhead >= a.tokenBudget {
+		return nil, fmt.Errorf("token budget of %d leaves no room for code after %d tokens of policies, persona and context", a.tokenBudget, overhead)
+	}
+	chunks := ChunkContent(art.Path, art.Content, a.tokenBudget-overhead)
+
+	reqs := make([]request, len(chunks))
+	for i := range chunks {
+		extra := chunkNote(i+1, len(chunks))
+		if a.additionalContext != "" {
+			extra = a.additionalContext + "\n\n" + extra
+		}
+		reqs[i] = request{code: fileHeader(art.Path) + chunks[i].Content, additionalContext: extra, chunk: &chunks[i]}
+	}
+	return reqs, nil
+}
+
+// fileHeader is prepended to the code so the LLM knows which file it's
+// analyzing, including each chunk of a split file. Without this, models hallucinate conventional filenames (e.g.
+// "handlers.go" instead of the actual "server.go"), causing ~50% of findings
+// to reference nonexistent paths. See
+// https://github.com/chris-regnier/gavel/issues/34.
+func fileHeader(path string) string {
+	if path == "" {
+		return ""
+	}
+	return fmt.Sprintf("// File: %s\n", path)
+}
+
+// chunkNote tells the LLM it is seeing part of a file, so it does not
+// report code that lives in another chunk as missing.
+func chunkNote(part, total int) string {
+	return fmt.Sprintf("This is part %d of %d of the file; the other parts are analyzed separately. Report line numbers within this part and do not report definitions that appear to be missing from it.", part, total)
+}
+
+// shiftFinding moves a finding from a chunk's line numbers to the
+// artifact's. Related locations in other files are left alone.
+func shiftFinding(f Finding, path string, offset int) Finding {
+	if f.StartLine > 0 {
+		f.StartLine += offset
+	}
+	if f.EndLine > 0 {
+		f.EndLine += offset
+	}
+	if len(f.RelatedLocations) > 0 {
+		related := make([]RelatedLocation, len(f.RelatedLocations))
+		for i, r := range f.RelatedLocations {
+			if r.FilePath == "" || r.FilePath == path {
+				if r.StartLine > 0 {
+					r.StartLine += offset
+				}
+				if r.EndLine > 0 {
+					r.EndLine += offset
+				}
+			}
+			related[i] = r
+		}
+		f.RelatedLocations = related
+	}
+	return f
+}
+
+// analyzeCode invokes the client, applying the per-request timeout when
+// one is configured.
+func (a *Analyzer) analyzeCode(ctx context.Context, code, policyText, personaPrompt, additionalContext string) ([]Finding, error) {
+	if a.requestTimeout <= 0 {
+		return a.client.AnalyzeCode(ctx, code, policyText, personaPrompt, additionalContext)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, a.requestTimeout)
+	defer cancel()
+	findings, err := a.client.AnalyzeCode(callCtx, code, policyText, personaPrompt, additionalContext)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w after %s: %w", ErrRequestTimeout, a.requestTimeout, context.DeadlineExceeded)
+	}
+	return findings, err
 }
 
 // getOrBuildIndex returns a cached or freshly built function index for the
@@ -2,8 +2,10 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/chris-regnier/gavel/internal/config"
 	"github.com/chris-regnier/gavel/internal/input"
@@ -352,3 +354,22 @@ func TestFormatPolicies(t *testing.T) {
 		t.Error("did not expect disabled rule-b in output")
 	}
 }
+
+func TestAnalyzer_RequestTimeout(t *testing.T) {
+	mock := &tieredMockClient{delay: time.Second}
+	a := NewAnalyzer(mock, WithRequestTimeout(20*time.Millisecond))
+	artifacts := []input.Artifact{{Path: "slow.go", Content: "package slow", Kind: input.KindFile}}
+	policies := map[string]config.Policy{"p": {Instruction: "Check", Enabled: true}}
+
+	start := time.Now()
+	_, err := a.Analyze(context.Background(), artifacts, policies, "persona")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "timed out after 20ms") {
+		t.Errorf("expected error to mention the per-request budget, got %q", err.Error())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected call to be cut off near 20ms, took %v", elapsed)
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// AppliedRule is one instant-tier rule that actually ran against a file.
+// Matches counts raw pattern or AST check matches, before allowlists and
+// result-level filtering.
+type AppliedRule struct {
+	RuleID   string         `json:"rule_id"`
+	Type     rules.RuleType `json:"type"`
+	ASTCheck string         `json:"ast_check,omitempty"`
+	Matches  int            `json:"matches"`
+}
+
+// AppliedFile lists the rules that ran against one file, sorted by rule ID.
+type AppliedFile struct {
+	File  string        `json:"file"`
+	Rules []AppliedRule `json:"rules"`
+}
+
+// AppliedRules records which instant-tier rules ran on each file, after
+// language, include/exclude path, and path override filtering. AST rules
+// are absent for files that could not be parsed or have no grammar. It is
+// safe for concurrent use.
+type AppliedRules struct {
+	mu    sync.Mutex
+	files map[string]map[string]*AppliedRule
+}
+
+// NewAppliedRules returns an empty recorder.
+func NewAppliedRules() *AppliedRules {
+	return &AppliedRules{files: make(map[string]map[string]*AppliedRule)}
+}
+
+// WithAppliedRules records the instant-tier rules that run on each file.
+func WithAppliedRules(a *AppliedRules) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.appliedRules = a
+	}
+}
+
+// start registers path so files on which no rule ran are still listed.
+func (a *AppliedRules) start(path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.files[path] == nil {
+		a.files[path] = make(map[string]*AppliedRule)
+	}
+}
+
+// record adds one evaluation of rule against path.
+func (a *AppliedRules) record(rule rules.Rule, path string, matches int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	byID := a.files[path]
+	if byID == nil {
+		byID = make(map[string]*AppliedRule)
+		a.files[path] = byID
+	}
+	r, ok := byID[rule.ID]
+	if !ok {
+		r = &AppliedRule{RuleID: rule.ID, Type: rule.Type, ASTCheck: rule.ASTCheck}
+		byID[rule.ID] = r
+	}
+	r.Matches += matches
+}
+
+// Files returns the record for every file seen, sorted by path.
+func (a *AppliedRules) Files() []AppliedFile {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	files := make([]AppliedFile, 0, len(a.files))
+	for path, byID := range a.files {
+		f := AppliedFile{File: path, Rules: make([]AppliedRule, 0, len(byID))}
+		for _, r := range byID {
+			f.Rules = append(f.Rules, *r)
+		}
+		sort.Slice(f.Rules, func(i, j int) bool { return f.Rules[i].RuleID < f.Rules[j].RuleID })
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].File < files[j].File })
+	return files
+}
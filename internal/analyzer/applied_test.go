@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+func appliedByID(f AppliedFile) map[string]AppliedRule {
+	m := make(map[string]AppliedRule)
+	for _, r := range f.Rules {
+		m[r.RuleID] = r
+	}
+	return m
+}
+
+func TestAppliedRules_FiltersByLanguage(t *testing.T) {
+	applied := NewAppliedRules()
+	ta := NewTieredAnalyzer(&tieredMockClient{}, WithAppliedRules(applied))
+
+	ta.RunPatternMatching(context.Background(), input.Artifact{
+		Path:    "main.go",
+		Content: "package main\n\n// TODO: one\n// TODO: two\nfunc main() {}\n",
+		Kind:    input.KindFile,
+	})
+	ta.RunPatternMatching(context.Background(), input.Artifact{
+		Path:    "load.py",
+		Content: "import pickle\nobj = pickle.loads(data)\n",
+		Kind:    input.KindFile,
+	})
+	ta.RunPatternMatching(context.Background(), input.Artifact{Path: "notes.txt", Content: "", Kind: input.KindFile})
+
+	files := applied.Files()
+	if len(files) != 3 || files[0].File != "load.py" || files[1].File != "main.go" || files[2].File != "notes.txt" {
+		t.Fatalf("expected records for load.py, main.go and notes.txt, got %+v", files)
+	}
+
+	goRules := appliedByID(files[1])
+	if r, ok := goRules["S1135"]; !ok || r.Matches != 2 || r.Type != rules.RuleTypeRegex {
+		t.Errorf("expected language-agnostic S1135 with 2 matches on main.go, got %+v (present=%v)", r, ok)
+	}
+	if r, ok := goRules["S2068"]; !ok || r.Matches != 0 {
+		t.Errorf("expected S2068 to run on main.go with no matches, got %+v (present=%v)", r, ok)
+	}
+	if r, ok := goRules["AST001"]; !ok || r.Type != rules.RuleTypeAST || r.ASTCheck != "function-length" {
+		t.Errorf("expected AST001 to run on main.go, got %+v (present=%v)", r, ok)
+	}
+	for _, id := range []string{"S5135", "S1523"} {
+		if _, ok := goRules[id]; ok {
+			t.Errorf("expected %s to be skipped on a Go file", id)
+		}
+	}
+
+	pyRules := appliedByID(files[0])
+	if r, ok := pyRules["S5135"]; !ok || r.Matches != 1 {
+		t.Errorf("expected python-only S5135 with 1 match on load.py, got %+v (present=%v)", r, ok)
+	}
+	if _, ok := pyRules["S4830"]; ok {
+		t.Error("expected Go-only S4830 to be skipped on a Python file")
+	}
+
+	if _, ok := appliedByID(files[2])["AST001"]; ok {
+		t.Error("expected no AST rules on a file without a grammar")
+	}
+}
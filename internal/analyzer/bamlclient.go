@@ -1,13 +1,18 @@
+//go:build !nobaml
+
 package analyzer
 
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	baml "github.com/boundaryml/baml/engine/language_client_go/pkg"
+
 	baml_client "github.com/chris-regnier/gavel/baml_client"
 	"github.com/chris-regnier/gavel/baml_client/types"
 	"github.com/chris-regnier/gavel/internal/config"
@@ -28,6 +33,14 @@ func NewBAMLLiveClient(cfg config.ProviderConfig) *BAMLLiveClient {
 	}
 }
 
+// LLMRuntimeError reports why the BAML runtime is unavailable, or nil. This
+// build links the runtime in, whose package init panics when the native
+// library is missing, so the process never gets here without it; only a
+// build with -tags nobaml can report the runtime as unavailable.
+func LLMRuntimeError() error {
+	return nil
+}
+
 // modelName returns the configured model name for the current provider.
 func (c *BAMLLiveClient) modelName() string {
 	switch c.providerConfig.Name {
@@ -41,6 +54,11 @@ func (c *BAMLLiveClient) modelName() string {
 		return c.providerConfig.Bedrock.Model
 	case "openai":
 		return c.providerConfig.OpenAI.Model
+	case "llamacpp":
+		if c.providerConfig.LlamaCpp.Model == "" {
+			return "llamacpp"
+		}
+		return c.providerConfig.LlamaCpp.Model
 	default:
 		return "unknown"
 	}
@@ -78,6 +96,8 @@ func (c *BAMLLiveClient) AnalyzeCode(ctx context.Context, code string, policies
 		results, err = c.analyzeWithBedrock(ctx, code, policies, personaPrompt, additionalContext)
 	case "openai":
 		results, err = c.analyzeWithOpenAI(ctx, code, policies, personaPrompt, additionalContext)
+	case "llamacpp":
+		results, err = c.analyzeWithLlamaCpp(ctx, code, policies, personaPrompt, additionalContext)
 	default:
 		return nil, fmt.Errorf("unknown provider: %s", c.providerConfig.Name)
 	}
@@ -147,6 +167,35 @@ func (c *BAMLLiveClient) analyzeWithOpenAI(ctx context.Context, code string, pol
 	)
 }
 
+// defaultLlamaCppBaseURL is where llama.cpp's server listens by default.
+const defaultLlamaCppBaseURL = "http://localhost:8080/v1"
+
+func (c *BAMLLiveClient) analyzeWithLlamaCpp(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]types.Finding, error) {
+	// baml_src has no llama.cpp client, so one is built per call from the
+	// config, sending optional settings such as temperature only when set
+	cfg := c.providerConfig.LlamaCpp
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultLlamaCppBaseURL
+	}
+	options := map[string]any{
+		"base_url": baseURL,
+		"model":    c.modelName(),
+	}
+	if key := os.Getenv("LLAMACPP_API_KEY"); key != "" {
+		options["api_key"] = key
+	}
+	if cfg.Temperature != nil {
+		options["temperature"] = *cfg.Temperature
+	}
+	registry := baml.NewClientRegistry()
+	registry.AddLlmClient("LlamaCpp", "openai-generic", options)
+	registry.SetPrimaryClient("LlamaCpp")
+	return baml_client.AnalyzeCode(ctx, code, policies, personaPrompt, additionalContext,
+		baml_client.WithClientRegistry(registry),
+	)
+}
+
 func convertFindings(bamlFindings []types.Finding) []Finding {
 	findings := make([]Finding, len(bamlFindings))
 	for i, f := range bamlFindings {
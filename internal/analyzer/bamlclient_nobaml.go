@@ -0,0 +1,41 @@
+//go:build nobaml
+
+package analyzer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// ErrNoLLMRuntime is returned in place of model results by builds without
+// the BAML runtime.
+var ErrNoLLMRuntime = errors.New("gavel was built without the BAML runtime (nobaml build tag); LLM tiers are unavailable")
+
+// Ensure BAMLLiveClient satisfies the BAMLClient interface at compile time.
+var _ BAMLClient = (*BAMLLiveClient)(nil)
+
+// BAMLLiveClient stands in for the live client in builds without the BAML
+// runtime. Every call fails with ErrNoLLMRuntime.
+type BAMLLiveClient struct {
+	providerConfig config.ProviderConfig
+}
+
+// NewBAMLLiveClient creates a client whose calls fail with ErrNoLLMRuntime.
+func NewBAMLLiveClient(cfg config.ProviderConfig) *BAMLLiveClient {
+	return &BAMLLiveClient{
+		providerConfig: cfg,
+	}
+}
+
+// AnalyzeCode returns ErrNoLLMRuntime.
+func (c *BAMLLiveClient) AnalyzeCode(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]Finding, error) {
+	return nil, ErrNoLLMRuntime
+}
+
+// LLMRuntimeError reports why the BAML runtime is unavailable: this build
+// leaves it out, so it always returns ErrNoLLMRuntime.
+func LLMRuntimeError() error {
+	return ErrNoLLMRuntime
+}
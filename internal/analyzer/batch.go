@@ -0,0 +1,368 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/metrics"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// WithTieredBatching packs files small enough to share a request into one
+// comprehensive-tier call of at most maxTokens estimated tokens, instead of
+// one call per file. Findings are split back into per-file results. Files
+// with cached results, with additional contexts, or too large to share a
+// call are analyzed alone as before. Zero disables batching.
+func WithTieredBatching(maxTokens int) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.batchTokens = maxTokens
+	}
+}
+
+// batchFile is one artifact's place in the code of a batched request.
+type batchFile struct {
+	art   input.Artifact
+	start int // Line of the code holding the file's header
+	lines int // Lines of the file's content, which follow the header
+}
+
+// batchLayout lays out the files of a batched request in order.
+type batchLayout []batchFile
+
+// batchCode joins artifacts, which must have paths, into the code of one
+// request, each file preceded by its fileHeader and followed by an end
+// marker, and returns where each file landed.
+func batchCode(artifacts []input.Artifact) (string, batchLayout) {
+	var sb strings.Builder
+	layout := make(batchLayout, len(artifacts))
+	line := 1
+	for i, art := range artifacts {
+		content := strings.TrimSuffix(art.Content, "\n")
+		n := strings.Count(content, "\n") + 1
+		layout[i] = batchFile{art: art, start: line, lines: n}
+		sb.WriteString(fileHeader(art.Path))
+		sb.WriteString(content)
+		sb.WriteString("\n")
+		sb.WriteString(batchFooter(art.Path))
+		line += n + 2
+	}
+	return sb.String(), layout
+}
+
+// batchFooter closes a file in a batch, so the model does not read the next
+// file's header as part of it.
+func batchFooter(path string) string {
+	return fmt.Sprintf("// End of file: %s\n", path)
+}
+
+// batchNote tells the LLM how to report findings in a batch so they can be
+// split back to their files.
+func batchNote(files int) string {
+	return fmt.Sprintf("The code holds %d separate files, each starting with a \"// File: <path>\" line and ending with a \"// End of file: <path>\" line. Analyze each file on its own. Report each finding's filePath exactly as written in its file's header, and its line numbers within that file, counting the line after the header as line 1.", files)
+}
+
+// batchTokens estimates the prompt size of a batch holding files of
+// codeTokens estimated tokens in total, excluding the fixed BAML template.
+func batchTokens(codeTokens, files int, policyText, personaPrompt, additionalContext string) int {
+	return codeTokens + estimateTokens(policyText, personaPrompt, additionalContext, batchNote(files))
+}
+
+// batchCodeTokens estimates the tokens art adds to a batch's code.
+func batchCodeTokens(art input.Artifact) int {
+	return estimateTokens(fileHeader(art.Path), art.Content, batchFooter(art.Path))
+}
+
+// match returns the index of the file p names, or -1. Models sometimes
+// drop or add leading directories, so a path that is not exact matches a
+// file it is a path suffix of, or that is a path suffix of it, when only
+// one file does.
+func (l batchLayout) match(p string) int {
+	if p == "" {
+		return -1
+	}
+	p = strings.TrimPrefix(path.Clean(strings.TrimPrefix(p, "file://")), "./")
+	for i, f := range l {
+		if f.art.Path == p || path.Clean(f.art.Path) == p {
+			return i
+		}
+	}
+	found := -1
+	for i, f := range l {
+		name := path.Clean(f.art.Path)
+		if strings.HasSuffix(name, "/"+p) || strings.HasSuffix(p, "/"+name) {
+			if found >= 0 {
+				return -1
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+// locate returns the file a location reported as p and line belongs to,
+// and the line within that file. A line past the end of a named file that
+// falls inside the file's part of the batch was counted from the top of
+// the batch and is moved into the file. Without a known path, a location
+// is placed by its line in the batch if it has one.
+func (l batchLayout) locate(p string, line int) (int, int, bool) {
+	if i := l.match(p); i >= 0 {
+		f := l[i]
+		if line > f.lines && line > f.start && line <= f.start+f.lines {
+			line -= f.start
+		}
+		return i, line, true
+	}
+	for i, f := range l {
+		if line > f.start && line <= f.start+f.lines {
+			return i, line - f.start, true
+		}
+	}
+	return -1, 0, false
+}
+
+// split assigns a finding from a batched call to its file, correcting its
+// path and line numbers. Related locations in the batch's files are
+// corrected too; those elsewhere are left alone. A finding that cannot be
+// placed returns false.
+func (l batchLayout) split(f Finding) (int, Finding, bool) {
+	i, start, ok := l.locate(f.FilePath, f.StartLine)
+	if !ok {
+		return -1, f, false
+	}
+	if offset := f.StartLine - start; offset != 0 && f.EndLine > 0 {
+		f.EndLine -= offset
+	}
+	f.StartLine = start
+	f.FilePath = l[i].art.Path
+
+	if len(f.RelatedLocations) > 0 {
+		related := make([]RelatedLocation, len(f.RelatedLocations))
+		for k, r := range f.RelatedLocations {
+			p := r.FilePath
+			if p == "" {
+				p = f.FilePath
+			}
+			if j := l.match(p); j >= 0 {
+				_, line, _ := l.locate(p, r.StartLine)
+				if offset := r.StartLine - line; offset != 0 && r.EndLine > 0 {
+					r.EndLine -= offset
+				}
+				r.FilePath = l[j].art.Path
+				r.StartLine = line
+			}
+			related[k] = r
+		}
+		f.RelatedLocations = related
+	}
+	return i, f, true
+}
+
+// AnalyzeBatch analyzes artifacts in a single AnalyzeCode call, joined with
+// file delimiters, and returns the results of each artifact at its index.
+// Findings the model does not attribute to one of the files are dropped.
+// Unlike Analyze, the batch is never chunked; callers size it to fit.
+func (a *Analyzer) AnalyzeBatch(ctx context.Context, artifacts []input.Artifact, policies map[string]config.Policy, personaPrompt string) ([][]sarif.Result, error) {
+	out := make([][]sarif.Result, len(artifacts))
+	policyText := FormatPolicies(policies)
+	if policyText == "" || len(artifacts) == 0 {
+		return out, nil
+	}
+
+	code, layout := batchCode(artifacts)
+	extra := batchNote(len(artifacts))
+	if a.additionalContext != "" {
+		extra = a.additionalContext + "\n\n" + extra
+	}
+	findings, err := a.analyzeCode(ctx, code, policyText, personaPrompt, extra)
+	if err != nil {
+		return nil, fmt.Errorf("analyzing batch of %d files: %w", len(artifacts), err)
+	}
+
+	perFile := make([][]Finding, len(artifacts))
+	for _, f := range findings {
+		i, f, ok := layout.split(f)
+		if !ok {
+			slog.Debug("dropping batched finding outside the batch's files", "rule", f.RuleID, "file", f.FilePath, "line", f.StartLine)
+			continue
+		}
+		perFile[i] = append(perFile[i], f)
+	}
+	for i, art := range artifacts {
+		if len(perFile[i]) > 0 {
+			out[i] = a.buildResults(art, perFile[i])
+		}
+	}
+	return out, nil
+}
+
+// batchLimit is the token cap for a batched call: the batching budget,
+// lowered to the per-call token budget when that is smaller.
+func (ta *TieredAnalyzer) batchLimit() int {
+	if ta.tokenBudget > 0 && ta.tokenBudget < ta.batchTokens {
+		return ta.tokenBudget
+	}
+	return ta.batchTokens
+}
+
+// planBatches groups the artifacts the comprehensive tier can analyze
+// together, in order, under the batching budget. It returns the groups of
+// two or more files and, separately, the artifacts to analyze alone.
+func (ta *TieredAnalyzer) planBatches(ctx context.Context, artifacts []input.Artifact, policies map[string]config.Policy, personaPrompt, policyText string) ([][]input.Artifact, []input.Artifact) {
+	limit := ta.batchLimit()
+	routes := ta.comprehensiveBatches(policies)
+	var groups [][]input.Artifact
+	var alone, group []input.Artifact
+	groupTokens := 0
+	flush := func() {
+		if len(group) > 1 {
+			groups = append(groups, group)
+		} else {
+			alone = append(alone, group...)
+		}
+		group, groupTokens = nil, 0
+	}
+
+	for _, art := range artifacts {
+		if art.Kind == input.KindSBOM || art.Path == "" {
+			alone = append(alone, art)
+			continue
+		}
+		// Files with their own contexts cannot share a prompt, and cached
+		// files need no call
+		contexts := ta.resolveContexts(ctx, art, routes)
+		if contextSignature(contexts) != "" {
+			alone = append(alone, art)
+			continue
+		}
+		cacheKey, _ := ta.contentCacheKey(art, policyText+routeSignature(routes), personaPrompt)
+		if _, ok := ta.cacheForComprehensive(cacheKey); ok {
+			alone = append(alone, art)
+			continue
+		}
+
+		tokens := batchCodeTokens(art)
+		if batchTokens(tokens, 2, policyText, personaPrompt, ta.additionalContext) > limit {
+			alone = append(alone, art)
+			continue
+		}
+		if batchTokens(groupTokens+tokens, len(group)+1, policyText, personaPrompt, ta.additionalContext) > limit {
+			flush()
+		}
+		group = append(group, art)
+		groupTokens += tokens
+	}
+	flush()
+	return groups, alone
+}
+
+// runComprehensiveBatch analyzes group in one call per policy route and
+// reports each file's results as runComprehensiveTier would.
+func (ta *TieredAnalyzer) runComprehensiveBatch(ctx context.Context, group []input.Artifact, policies map[string]config.Policy, personaPrompt, policyText string, resultChan chan<- TieredResult) {
+	ctx, span := analyzerTracer.Start(ctx, "analyze batch",
+		trace.WithAttributes(
+			attribute.Int("gavel.file_count", len(group)),
+			attribute.String("gavel.tier", "comprehensive"),
+		),
+	)
+	defer span.End()
+
+	batches := ta.comprehensiveBatches(policies)
+	all := make([][]sarif.Result, len(group))
+	out := make([][]TieredResult, len(group))
+	failed, budgetSkipped := false, false
+	findingCount := 0
+	for _, b := range batches {
+		batchStart := time.Now()
+		ta.comprehensiveCalls.Add(1)
+
+		client, batchText := ta.comprehensiveClient, policyText
+		if b.routed {
+			if b.route.Client != nil {
+				client = b.route.Client
+			}
+			batchText = FormatPolicies(b.policies)
+		}
+		analyzer := ta.newAnalyzerForClient(client)
+		results, err := analyzer.AnalyzeBatch(ctx, group, b.policies, personaPrompt)
+		duration := time.Since(batchStart)
+		if errors.Is(err, ErrBudgetExceeded) {
+			budgetSkipped = true
+			failed = true
+			err = nil
+		}
+		if err != nil && b.routed {
+			err = fmt.Errorf("policies %s on %s: %w", strings.Join(b.names, ", "), b.route.key(), err)
+		}
+		if err != nil {
+			failed = true
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		if results == nil {
+			results = make([][]sarif.Result, len(group))
+		}
+
+		// The call's fixed prompt is shared evenly between its files
+		shared := estimateTokens(batchText, personaPrompt, analyzer.additionalContext, batchNote(len(group))) / len(group)
+		for i, art := range group {
+			fileResults := results[i]
+			for k := range fileResults {
+				if fileResults[k].Properties == nil {
+					fileResults[k].Properties = make(map[string]interface{})
+				}
+				fileResults[k].Properties["gavel/tier"] = "comprehensive"
+				fileResults[k].Properties["gavel/prompt_hash"] = cache.PromptHash(personaPrompt, batchText)
+				if b.routed {
+					fileResults[k].Properties["gavel/provider"] = b.route.Provider
+					fileResults[k].Properties["gavel/model"] = b.route.Model
+					fileResults[k].Properties["gavel/policies"] = b.names
+				}
+			}
+			all[i] = append(all[i], fileResults...)
+			fileResults = ta.filterPathOverrides(art.Path, fileResults)
+			findingCount += len(fileResults)
+
+			ta.recordMetrics(art, metrics.TierComprehensive, duration, len(fileResults), batchCodeTokens(art)+shared, metrics.CacheMiss, err)
+			out[i] = append(out[i], TieredResult{
+				Tier:     TierComprehensive,
+				FilePath: art.Path,
+				Results:  fileResults,
+				Error:    err,
+				Duration: duration,
+			})
+		}
+	}
+	span.SetAttributes(attribute.Int("gavel.finding_count", findingCount))
+	if budgetSkipped {
+		ta.budgetSkipped.Add(int64(len(group)))
+		span.SetAttributes(attribute.Bool("gavel.budget_exceeded", true))
+	}
+
+	for i, art := range group {
+		if !failed {
+			// Cached under the same key as a file analyzed alone, so later
+			// runs hit whether or not they batch
+			cacheKey, tokens := ta.contentCacheKey(art, policyText+routeSignature(batches), personaPrompt)
+			results := all[i]
+			if tokens != nil {
+				results = anchorResults(results, art, tokens)
+			}
+			ta.cache.Set(cacheKey, results)
+		}
+		for _, tr := range out[i] {
+			resultChan <- tr
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+)
+
+func TestBatchLayout_Split(t *testing.T) {
+	code, layout := batchCode([]input.Artifact{
+		{Path: "pkg/a.go", Content: "package a\n\nfunc A() {}\n"},
+		{Path: "pkg/b.go", Content: "package b\n\nfunc B() {}\nfunc C() {}"},
+	})
+	if !strings.HasPrefix(code, "// File: pkg/a.go\npackage a\n") || !strings.Contains(code, "// End of file: pkg/a.go\n// File: pkg/b.go\n") {
+		t.Fatalf("unexpected batch code:\n%s", code)
+	}
+
+	tests := []struct {
+		name     string
+		in       Finding
+		wantFile int
+		wantPath string
+		start    int
+		end      int
+	}{
+		{"exact path", Finding{FilePath: "pkg/b.go", StartLine: 3, EndLine: 4}, 1, "pkg/b.go", 3, 4},
+		{"leading dot", Finding{FilePath: "./pkg/a.go", StartLine: 1}, 0, "pkg/a.go", 1, 0},
+		{"dropped directory", Finding{FilePath: "b.go", StartLine: 4}, 1, "pkg/b.go", 4, 0},
+		{"added directory", Finding{FilePath: "repo/pkg/a.go", StartLine: 3}, 0, "pkg/a.go", 3, 0},
+		// b.go's header is line 6 of the batch, so line 9 is its line 3
+		{"batch line numbers", Finding{FilePath: "pkg/b.go", StartLine: 9, EndLine: 10}, 1, "pkg/b.go", 3, 4},
+		{"unknown path", Finding{FilePath: "handlers.go", StartLine: 8}, 1, "pkg/b.go", 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, f, ok := layout.split(tt.in)
+			if !ok || i != tt.wantFile || f.FilePath != tt.wantPath || f.StartLine != tt.start || f.EndLine != tt.end {
+				t.Errorf("split = %d %s:%d-%d %v, want %d %s:%d-%d", i, f.FilePath, f.StartLine, f.EndLine, ok, tt.wantFile, tt.wantPath, tt.start, tt.end)
+			}
+		})
+	}
+
+	if _, _, ok := layout.split(Finding{FilePath: "handlers.go", StartLine: 50}); ok {
+		t.Error("expected a finding outside every file to be dropped")
+	}
+
+	_, f, _ := layout.split(Finding{FilePath: "pkg/a.go", StartLine: 3, RelatedLocations: []RelatedLocation{
+		{StartLine: 1},
+		{FilePath: "b.go", StartLine: 2},
+		{FilePath: "vendor/x.go", StartLine: 7},
+	}})
+	want := []RelatedLocation{{FilePath: "pkg/a.go", StartLine: 1}, {FilePath: "pkg/b.go", StartLine: 2}, {FilePath: "vendor/x.go", StartLine: 7}}
+	for i, r := range f.RelatedLocations {
+		if r != want[i] {
+			t.Errorf("related location %d = %+v, want %+v", i, r, want[i])
+		}
+	}
+}
+
+// batchEchoClient reports a finding on line 1 of every file header it is
+// sent, and records each call's code.
+type batchEchoClient struct {
+	mu    sync.Mutex
+	codes []string
+}
+
+func (c *batchEchoClient) AnalyzeCode(ctx context.Context, code, policies, personaPrompt, additionalContext string) ([]Finding, error) {
+	c.mu.Lock()
+	c.codes = append(c.codes, code)
+	c.mu.Unlock()
+	var findings []Finding
+	for _, line := range strings.Split(code, "\n") {
+		if path, ok := strings.CutPrefix(line, "// File: "); ok {
+			findings = append(findings, Finding{RuleID: "r", Level: "warning", Message: "m", FilePath: path, StartLine: 1, EndLine: 1})
+		}
+	}
+	return findings, nil
+}
+
+func TestTieredAnalyzer_Batching(t *testing.T) {
+	policies := map[string]config.Policy{"p": {Instruction: "check", Enabled: true}}
+	large := "package big\n\n" + strings.Repeat("var x = 1 // padding\n", 200)
+	artifacts := []input.Artifact{
+		{Path: "a.go", Content: "package a\n", Kind: input.KindFile},
+		{Path: "b.go", Content: "package b\n", Kind: input.KindFile},
+		{Path: "big.go", Content: large, Kind: input.KindFile},
+		{Path: "c.go", Content: "package c\n", Kind: input.KindFile},
+	}
+	client := &batchEchoClient{}
+	ta := NewTieredAnalyzer(client, WithInstantEnabled(false), WithTieredBatching(500), WithTieredCache(cache.New()))
+
+	byFile := make(map[string][]string)
+	for r := range ta.AnalyzeProgressive(context.Background(), artifacts, policies, "persona") {
+		if r.Error != nil {
+			t.Fatalf("%s: %v", r.FilePath, r.Error)
+		}
+		for _, res := range r.Results {
+			loc := res.Locations[0].PhysicalLocation
+			byFile[r.FilePath] = append(byFile[r.FilePath], loc.ArtifactLocation.URI)
+			if loc.Region.Snippet == nil || !strings.HasPrefix(loc.Region.Snippet.Text, "package") {
+				t.Errorf("%s: snippet %+v, want the file's first line", r.FilePath, loc.Region.Snippet)
+			}
+		}
+	}
+
+	if len(client.codes) != 2 {
+		t.Fatalf("expected one batched call and one call for the large file, got %d", len(client.codes))
+	}
+	if !strings.Contains(client.codes[0], "// File: a.go") || !strings.Contains(client.codes[0], "// File: c.go") || strings.Contains(client.codes[0], "big.go") {
+		t.Errorf("unexpected batch:\n%s", client.codes[0])
+	}
+	for _, path := range []string{"a.go", "b.go", "big.go", "c.go"} {
+		if got := byFile[path]; len(got) != 1 || got[0] != path {
+			t.Errorf("%s: got findings at %v, want one in the file", path, got)
+		}
+	}
+
+	// Batched results are cached per file, so a second run makes no calls
+	for range ta.AnalyzeProgressive(context.Background(), artifacts, policies, "persona") {
+	}
+	if len(client.codes) != 2 {
+		t.Errorf("expected every file to hit the cache, got %d calls", len(client.codes))
+	}
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+)
+
+// Chunk is a run of whole lines from an artifact, sent to the LLM as one
+// request when the artifact does not fit the token budget.
+type Chunk struct {
+	StartLine int // 1-indexed line of the first line in the artifact
+	EndLine   int
+	Content   string
+}
+
+// ChunkContent splits content into chunks of at most maxTokens estimated
+// tokens. Chunks break between declarations (see
+// astcheck.DeclarationStarts) so functions and classes are analyzed whole;
+// a declaration that alone exceeds the budget, or a file in a language
+// without AST support, is split between lines instead. A line longer than
+// the budget becomes a chunk of its own. Content that fits, or a
+// non-positive maxTokens, yields a single chunk.
+func ChunkContent(path, content string, maxTokens int) []Chunk {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if maxTokens <= 0 || estimateTokens(content) <= maxTokens || len(lines) <= 1 {
+		return []Chunk{{StartLine: 1, EndLine: len(lines), Content: content}}
+	}
+
+	// Units are the runs of lines between declaration boundaries, as
+	// 0-indexed [start, end) pairs.
+	var units [][2]int
+	start := 0
+	for _, line := range astcheck.DeclarationStarts(path, []byte(content)) {
+		if i := line - 1; i > start && i < len(lines) {
+			units = append(units, [2]int{start, i})
+			start = i
+		}
+	}
+	units = append(units, [2]int{start, len(lines)})
+
+	var chunks []Chunk
+	cur := [2]int{0, 0}
+	flush := func() {
+		if cur[1] > cur[0] {
+			chunks = append(chunks, Chunk{
+				StartLine: cur[0] + 1,
+				EndLine:   cur[1],
+				Content:   strings.Join(lines[cur[0]:cur[1]], ""),
+			})
+		}
+		cur = [2]int{cur[1], cur[1]}
+	}
+	fits := func(from, to int) bool {
+		return estimateTokens(lines[from:to]...) <= maxTokens
+	}
+
+	for _, u := range units {
+		if fits(cur[0], u[1]) {
+			cur[1] = u[1]
+			continue
+		}
+		flush()
+		if fits(u[0], u[1]) {
+			cur[1] = u[1]
+			continue
+		}
+		for i := u[0]; i < u[1]; i++ {
+			if cur[1] > cur[0] && !fits(cur[0], i+1) {
+				flush()
+			}
+			cur[1] = i + 1
+		}
+	}
+	flush()
+	return chunks
+}
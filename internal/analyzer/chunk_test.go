@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+)
+
+// goFile returns a Go file with n functions of body lines each, and the
+// 1-indexed line on which each function (with its doc comment) starts.
+func goFile(n, body int) (string, []int) {
+	var sb strings.Builder
+	sb.WriteString("package big\n")
+	line := 2
+	var starts []int
+	for i := 0; i < n; i++ {
+		sb.WriteString("\n")
+		line++
+		starts = append(starts, line)
+		fmt.Fprintf(&sb, "// F%d does work.\nfunc F%d() {\n", i, i)
+		for j := 0; j < body; j++ {
+			fmt.Fprintf(&sb, "\tprintln(%d, %d)\n", i, j)
+		}
+		sb.WriteString("}\n")
+		line += body + 3
+	}
+	return sb.String(), starts
+}
+
+func TestChunkContent_FitsWhole(t *testing.T) {
+	src, _ := goFile(3, 2)
+	for _, budget := range []int{0, 10000} {
+		chunks := ChunkContent("a.go", src, budget)
+		if len(chunks) != 1 || chunks[0].Content != src || chunks[0].StartLine != 1 {
+			t.Errorf("budget %d: expected the whole file as one chunk, got %+v", budget, chunks)
+		}
+	}
+}
+
+func TestChunkContent_DeclarationBoundaries(t *testing.T) {
+	src, starts := goFile(6, 10)
+	chunks := ChunkContent("a.go", src, estimateTokens(src)/3)
+	if len(chunks) < 3 {
+		t.Fatalf("expected at least 3 chunks, got %d", len(chunks))
+	}
+
+	isStart := map[int]bool{}
+	for _, s := range starts {
+		isStart[s] = true
+	}
+	var rebuilt strings.Builder
+	next := 1
+	for i, c := range chunks {
+		if c.StartLine != next {
+			t.Errorf("chunk %d starts at %d, want %d", i, c.StartLine, next)
+		}
+		if i > 0 && !isStart[c.StartLine] {
+			t.Errorf("chunk %d starts at line %d, which is not a function's doc comment", i, c.StartLine)
+		}
+		if got := strings.Count(c.Content, "\n"); got != c.EndLine-c.StartLine+1 {
+			t.Errorf("chunk %d holds %d lines, want %d", i, got, c.EndLine-c.StartLine+1)
+		}
+		if estimateTokens(c.Content) > estimateTokens(src)/3 {
+			t.Errorf("chunk %d exceeds the budget", i)
+		}
+		rebuilt.WriteString(c.Content)
+		next = c.EndLine + 1
+	}
+	if rebuilt.String() != src {
+		t.Error("chunks do not reassemble into the original content")
+	}
+}
+
+func TestChunkContent_OversizedDeclaration(t *testing.T) {
+	src, _ := goFile(1, 200)
+	budget := estimateTokens(src) / 4
+	chunks := ChunkContent("a.go", src, budget)
+	if len(chunks) < 4 {
+		t.Fatalf("expected the function to be split between lines, got %d chunks", len(chunks))
+	}
+	for i, c := range chunks {
+		if estimateTokens(c.Content) > budget {
+			t.Errorf("chunk %d exceeds the budget", i)
+		}
+	}
+}
+
+func TestChunkContent_UnsupportedLanguage(t *testing.T) {
+	src := strings.Repeat("some prose that is not code\n", 100)
+	chunks := ChunkContent("notes.txt", src, 100)
+	if len(chunks) < 2 {
+		t.Fatalf("expected line-based chunks, got %d", len(chunks))
+	}
+	if last := chunks[len(chunks)-1]; last.EndLine != 100 {
+		t.Errorf("last chunk ends at %d, want 100", last.EndLine)
+	}
+}
+
+// chunkEchoClient reports one finding on line 2 of whatever it is sent,
+// after the // File: header, and records each call's code.
+type chunkEchoClient struct {
+	codes    []string
+	contexts []string
+}
+
+func (c *chunkEchoClient) AnalyzeCode(ctx context.Context, code, policies, personaPrompt, additionalContext string) ([]Finding, error) {
+	c.codes = append(c.codes, code)
+	c.contexts = append(c.contexts, additionalContext)
+	return []Finding{{
+		RuleID:           "r",
+		Level:            "warning",
+		StartLine:        2,
+		EndLine:          3,
+		RelatedLocations: []RelatedLocation{{FilePath: "a.go", StartLine: 1}, {FilePath: "other.go", StartLine: 1}},
+	}}, nil
+}
+
+func TestAnalyzer_TokenBudgetChunks(t *testing.T) {
+	src, _ := goFile(6, 10)
+	policies := map[string]config.Policy{"p": {Instruction: "check", Enabled: true}}
+	client := &chunkEchoClient{}
+	a := NewAnalyzer(client, WithTokenBudget(estimateTokens(src)/2), WithAdditionalContext("diff context"))
+
+	results, err := a.Analyze(context.Background(), []input.Artifact{{Path: "a.go", Content: src}}, policies, "persona")
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(client.codes) < 2 || len(results) != len(client.codes) {
+		t.Fatalf("expected one call and one finding per chunk, got %d calls and %d results", len(client.codes), len(results))
+	}
+
+	lines := strings.Split(src, "\n")
+	offset := 0
+	for i, code := range client.codes {
+		if !strings.HasPrefix(code, "// File: a.go\n") {
+			t.Errorf("chunk %d lacks the file header", i)
+		}
+		if !strings.Contains(client.contexts[i], "diff context") || !strings.Contains(client.contexts[i], fmt.Sprintf("part %d of %d", i+1, len(client.codes))) {
+			t.Errorf("chunk %d context = %q", i, client.contexts[i])
+		}
+
+		r := results[i]
+		region := r.Locations[0].PhysicalLocation.Region
+		if region.StartLine != offset+2 || region.EndLine != offset+3 {
+			t.Errorf("chunk %d finding at %d-%d, want %d-%d", i, region.StartLine, region.EndLine, offset+2, offset+3)
+		}
+		if region.Snippet == nil || strings.TrimSuffix(region.Snippet.Text, "\n") != strings.Join(lines[offset+1:offset+3], "\n") {
+			t.Errorf("chunk %d snippet = %+v, want lines from the original file", i, region.Snippet)
+		}
+		if got := r.RelatedLocations[0].PhysicalLocation.Region.StartLine; got != offset+1 {
+			t.Errorf("chunk %d same-file related location at %d, want %d", i, got, offset+1)
+		}
+		if got := r.RelatedLocations[1].PhysicalLocation.Region.StartLine; got != 1 {
+			t.Errorf("chunk %d other-file related location moved to %d", i, got)
+		}
+		offset += strings.Count(strings.TrimPrefix(code, "// File: a.go\n"), "\n")
+	}
+}
+
+func TestAnalyzer_TokenBudgetTooSmall(t *testing.T) {
+	src, _ := goFile(6, 10)
+	policies := map[string]config.Policy{"p": {Instruction: strings.Repeat("long instruction ", 100), Enabled: true}}
+	a := NewAnalyzer(&chunkEchoClient{}, WithTokenBudget(50))
+
+	_, err := a.Analyze(context.Background(), []input.Artifact{{Path: "a.go", Content: src}}, policies, "persona")
+	if err == nil || !strings.Contains(err.Error(), "leaves no room for code") {
+		t.Errorf("expected a budget error, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/config"
+	gavelcontext "github.com/chris-regnier/gavel/internal/context"
+	"github.com/chris-regnier/gavel/internal/input"
+)
+
+// WithContextResolver sends each policy's additional_contexts with the
+// comprehensive-tier analysis of the files they apply to, selected and
+// capped by r. Without a resolver, additional_contexts are ignored.
+func WithContextResolver(r *gavelcontext.Resolver) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.contextResolver = r
+	}
+}
+
+// resolveContexts returns the additional context for each batch's call on
+// art, from the additional_contexts of the batch's enabled policies. A
+// batch whose context cannot be loaded is analyzed without it.
+func (ta *TieredAnalyzer) resolveContexts(ctx context.Context, art input.Artifact, batches []policyBatch) []gavelcontext.Resolution {
+	out := make([]gavelcontext.Resolution, len(batches))
+	if ta.contextResolver == nil {
+		return out
+	}
+	for i, b := range batches {
+		selectors := contextSelectors(b.policies)
+		if len(selectors) == 0 {
+			continue
+		}
+		res, err := ta.contextResolver.Resolve(ctx, art.Path, art.Content, selectors)
+		if err != nil {
+			slog.Warn("resolving additional contexts failed; analyzing without them", "file", art.Path, "err", err)
+			continue
+		}
+		out[i] = res
+	}
+	return out
+}
+
+// contextSelectors collects the additional_contexts of the enabled
+// policies, in policy name order.
+func contextSelectors(policies map[string]config.Policy) []config.ContextSelector {
+	names := make([]string, 0, len(policies))
+	for name, p := range policies {
+		if p.Enabled && len(p.AdditionalContexts) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var selectors []config.ContextSelector
+	for _, name := range names {
+		selectors = append(selectors, policies[name].AdditionalContexts...)
+	}
+	return selectors
+}
+
+// contextSignature describes the resolved contexts so cached results are
+// not reused after a context file changes.
+func contextSignature(contexts []gavelcontext.Resolution) string {
+	var sig string
+	for _, res := range contexts {
+		if text := res.Text(); text != "" {
+			sig += "\n#context " + cache.GenerateKey(text)
+		}
+	}
+	return sig
+}
+
+// contextAttributes summarizes the resolved contexts for a span.
+func contextAttributes(contexts []gavelcontext.Resolution) []attribute.KeyValue {
+	files, bytes, dropped, truncated := 0, 0, 0, false
+	for _, res := range contexts {
+		files += len(res.Files)
+		bytes += res.Bytes
+		dropped += len(res.Dropped)
+		truncated = truncated || res.Truncated()
+	}
+	return []attribute.KeyValue{
+		attribute.Int("gavel.context_files", files),
+		attribute.Int("gavel.context_bytes", bytes),
+		attribute.Int("gavel.context_dropped", dropped),
+		attribute.Bool("gavel.context_truncated", truncated),
+	}
+}
+
+// newAnalyzerWithContext is newAnalyzerForClient with extra context sent
+// after the TieredAnalyzer's own, such as a policy's additional contexts.
+func (ta *TieredAnalyzer) newAnalyzerWithContext(client BAMLClient, extra string) *Analyzer {
+	a := ta.newAnalyzerForClient(client)
+	switch {
+	case extra == "":
+	case a.additionalContext == "":
+		a.additionalContext = extra
+	default:
+		a.additionalContext += "\n\n" + extra
+	}
+	return a
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/metrics"
+	"github.com/chris-regnier/gavel/internal/tenant"
+)
+
+// ErrBudgetExceeded is returned by a CostTrackingClient instead of making a
+// call that would take the run past its cost budget. The comprehensive
+// tier treats it as a skipped file rather than a failure.
+var ErrBudgetExceeded = errors.New("cost budget exceeded")
+
+// expectedOutputTokens is the response size assumed when checking a call
+// against the budget before it is made.
+const expectedOutputTokens = 1000
+
+// CostTrackingClient wraps a BAMLClient to estimate the cost of each call
+// from its prompt and response size, record it to a metrics collector, and
+// enforce an optional budget shared by every client of a run.
+type CostTrackingClient struct {
+	client   BAMLClient
+	price    metrics.Price
+	budget   *metrics.Budget
+	recorder *metrics.Recorder
+}
+
+// NewCostTrackingClient wraps client, which calls model on provider at
+// price. A nil budget tracks cost without limiting it; a nil collector
+// skips recording.
+func NewCostTrackingClient(client BAMLClient, provider, model string, price metrics.Price, budget *metrics.Budget, collector *metrics.Collector) *CostTrackingClient {
+	c := &CostTrackingClient{client: client, price: price, budget: budget}
+	if collector != nil {
+		c.recorder = metrics.NewRecorder(collector, provider, model)
+	}
+	return c
+}
+
+// AnalyzeCode reserves the call's expected cost, makes the call, and
+// settles the estimate once the response size is known. A failed call is
+// charged for its prompt, which most providers bill regardless. The call is
+// recorded for the tenant carried by ctx, if any.
+func (c *CostTrackingClient) AnalyzeCode(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]Finding, error) {
+	tokensIn := estimateTokens(code, policies, personaPrompt, additionalContext)
+	reserved := c.price.Cost(tokensIn, expectedOutputTokens)
+	if c.budget != nil && !c.budget.Reserve(reserved) {
+		return nil, fmt.Errorf("%w: $%.4f of $%.2f spent", ErrBudgetExceeded, c.budget.Spent(), c.budget.Limit())
+	}
+
+	var builder *metrics.AnalysisBuilder
+	if c.recorder != nil {
+		builder = c.recorder.StartAnalysis(metrics.AnalysisTypeFull, metrics.TierComprehensive).WithTenant(tenant.FromContext(ctx)).MarkStarted()
+	}
+
+	findings, err := c.client.AnalyzeCode(ctx, code, policies, personaPrompt, additionalContext)
+	tokensOut := 0
+	if err == nil {
+		data, _ := json.Marshal(findings)
+		tokensOut = estimateTokens(string(data))
+	}
+	cost := c.price.Cost(tokensIn, tokensOut)
+	if c.budget != nil {
+		c.budget.Settle(reserved, cost)
+	}
+
+	if builder != nil {
+		builder.WithCost(cost)
+		if err != nil {
+			builder.CompleteWithError(err)
+		} else {
+			builder.Complete(len(findings), tokensIn, tokensOut)
+		}
+	}
+	return findings, err
+}
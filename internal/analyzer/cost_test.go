@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/metrics"
+)
+
+func TestCostTrackingClient(t *testing.T) {
+	collector := metrics.NewCollector()
+	budget := metrics.NewBudget(1.0)
+	// Only output is priced, so each call reserves exactly $1 for the
+	// expected 1,000 output tokens and settles at far less.
+	price := metrics.Price{OutputPer1K: 1}
+	client := NewCostTrackingClient(&policyRecordingClient{name: "r"}, "anthropic", "claude", price, budget, collector)
+
+	if _, err := client.AnalyzeCode(context.Background(), "code", "policies", "persona", ""); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	spent := budget.Spent()
+	if spent <= 0 || spent >= 1 {
+		t.Errorf("spent = %v, want the settled cost of one small response", spent)
+	}
+
+	_, err := client.AnalyzeCode(context.Background(), "code", "policies", "persona", "")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	ms := collector.GetStats().ByModel["anthropic/claude"]
+	if ms == nil || ms.Calls != 1 || math.Abs(ms.CostUSD-spent) > 1e-9 {
+		t.Errorf("recorded %+v, want one call costing %v", ms, spent)
+	}
+}
+
+func TestTieredAnalyzer_BudgetExceededSkipsFiles(t *testing.T) {
+	budget := metrics.NewBudget(1.0)
+	client := NewCostTrackingClient(&policyRecordingClient{name: "r"}, "anthropic", "claude", metrics.Price{OutputPer1K: 1}, budget, nil)
+	ta := NewTieredAnalyzer(client, WithInstantEnabled(false))
+
+	artifacts := []input.Artifact{
+		{Path: "a.go", Content: "package a\n", Kind: input.KindFile},
+		{Path: "b.go", Content: "package b\n", Kind: input.KindFile},
+	}
+	policies := map[string]config.Policy{"p": {Instruction: "check", Enabled: true}}
+	results, err := ta.Analyze(context.Background(), artifacts, policies, "persona")
+	if err != nil {
+		t.Fatalf("running out of budget should not fail the run: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected findings from the one file analyzed within budget, got %d", len(results))
+	}
+	if got := ta.Stats().BudgetSkipped; got != 1 {
+		t.Errorf("BudgetSkipped = %d, want 1", got)
+	}
+	if !budget.Exceeded() {
+		t.Error("budget should be marked exceeded")
+	}
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/deps"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// runDependencyRules checks the dependencies declared by a manifest or SBOM
+// artifact against dependency rules, anchoring each finding to the line
+// declaring the dependency. Other artifacts yield nothing.
+func (ta *TieredAnalyzer) runDependencyRules(art input.Artifact, depRules []rules.Rule) []sarif.Result {
+	if len(depRules) == 0 {
+		return nil
+	}
+	declared, ok := deps.Parse(art.Path, art.Content)
+	if art.Kind == input.KindSBOM && !ok {
+		// --sbom accepts any file name, so the content decides
+		var err error
+		declared, err = deps.ParseCycloneDX(art.Content)
+		ok = err == nil
+	}
+	if !ok {
+		return nil
+	}
+
+	var results []sarif.Result
+	for _, rule := range depRules {
+		if rule.Dependency == nil || !rule.AppliesToPath(art.Path) {
+			continue
+		}
+
+		matchStart := time.Now()
+		var found []sarif.Result
+		for _, d := range declared {
+			reason := deps.Violation(*rule.Dependency, d)
+			if reason == "" {
+				continue
+			}
+			line := d.Line
+			if line < 1 {
+				line = 1
+			}
+
+			props := map[string]interface{}{
+				"gavel/explanation": rule.Explanation,
+				"gavel/confidence":  rule.Confidence,
+				"gavel/tier":        "instant",
+				"gavel/rule-type":   string(rule.Type),
+				"gavel/rule-source": string(rule.Source),
+				"gavel/dependency":  d.Name,
+				"gavel/category":    string(rules.CategoryDependency),
+			}
+			if d.Version != "" {
+				props["gavel/dependency-version"] = d.Version
+			}
+			if rule.Remediation != "" {
+				props["gavel/remediation"] = rule.Remediation
+			}
+			if len(rule.References) > 0 {
+				props["gavel/references"] = rule.References
+			}
+			if rule.Category != "" {
+				props["gavel/category"] = string(rule.Category)
+			}
+			if len(rule.CWE) > 0 {
+				props["gavel/cwe"] = rule.CWE
+			}
+			if len(rule.OWASP) > 0 {
+				props["gavel/owasp"] = rule.OWASP
+			}
+
+			found = append(found, sarif.Result{
+				RuleID:  rule.ID,
+				Level:   rule.Level,
+				Message: sarif.Message{Text: rule.Message + ": " + reason},
+				Locations: []sarif.Location{{
+					PhysicalLocation: sarif.PhysicalLocation{
+						ArtifactLocation: sarif.ArtifactLocation{URI: art.Path},
+						Region: sarif.Region{
+							StartLine: line,
+							EndLine:   line,
+							Snippet:   sarif.ExtractSnippet(art.Content, line, line),
+						},
+						ContextRegion: sarif.ExtractContextRegion(art.Content, line, line),
+					},
+				}},
+				Properties: props,
+			})
+		}
+		if ta.ruleProfiler != nil {
+			ta.ruleProfiler.record(rule, art.Path, time.Since(matchStart), len(found))
+		}
+		if ta.appliedRules != nil {
+			ta.appliedRules.record(rule, art.Path, len(found))
+		}
+		results = append(results, found...)
+	}
+	return results
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"context"
+	"sort"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// PlannedCall describes the comprehensive-tier analysis of one file for one
+// group of policies: what Analyze would send, without sending it.
+type PlannedCall struct {
+	Provider   string   `json:"provider,omitempty"` // Set only for policies routed away from the top-level provider
+	Model      string   `json:"model,omitempty"`
+	StartLine  int      `json:"start_line,omitempty"` // Set when only a window of the file is sent
+	EndLine    int      `json:"end_line,omitempty"`
+	Policies   []string `json:"policies"`
+	PolicyText string   `json:"policy_text"`
+	// Requests is the number of AnalyzeCode calls, more than one when the
+	// file is split to fit provider.max_request_tokens.
+	Requests int `json:"requests"`
+	// EstimatedTokens is the prompt size summed over the requests, at four
+	// characters per token and excluding the fixed prompt template.
+	EstimatedTokens int `json:"estimated_tokens"`
+	// Cached means the analysis cache already holds results for the file,
+	// so no request would be sent.
+	Cached bool   `json:"cached,omitempty"`
+	Error  string `json:"error,omitempty"` // Why the file cannot be sent, e.g. a token budget too small for the policies
+	// Contexts are the policies' additional_contexts sent with the file, in
+	// rank order; DroppedContexts matched but did not fit the budget.
+	Contexts        []PlannedContext `json:"additional_contexts,omitempty"`
+	DroppedContexts []string         `json:"dropped_contexts,omitempty"`
+}
+
+// PlannedContext is an additional context file sent with a call.
+type PlannedContext struct {
+	Path      string `json:"path"`
+	Bytes     int    `json:"bytes"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// PlanComprehensive returns the comprehensive-tier calls Analyze would make
+// for art, one per policy route, without calling any client. Additional
+// contexts are resolved as for a real call, which may embed them with a
+// local model when the resolver ranks by similarity. It returns nil
+// when the tier has no client, no policy is enabled, or the artifact is not
+// analyzed by the LLM tiers.
+func (ta *TieredAnalyzer) PlanComprehensive(ctx context.Context, art input.Artifact, policies map[string]config.Policy, personaPrompt string) []PlannedCall {
+	if ta.comprehensiveClient == nil || art.Kind == input.KindSBOM {
+		return nil
+	}
+	policyText := FormatPolicies(policies)
+	if policyText == "" {
+		return nil
+	}
+
+	batches := ta.comprehensiveBatches(policies)
+	contexts := ta.resolveContexts(ctx, art, batches)
+	key, _ := ta.contentCacheKey(art, policyText+routeSignature(batches)+contextSignature(contexts), personaPrompt)
+	cached := false
+	if v, ok := ta.cacheForComprehensive(key); ok {
+		_, cached = v.([]sarif.Result)
+	}
+
+	var calls []PlannedCall
+	for i, b := range batches {
+		call := PlannedCall{Policies: b.names, PolicyText: policyText, Cached: cached}
+		for _, f := range contexts[i].Files {
+			call.Contexts = append(call.Contexts, PlannedContext{Path: f.Path, Bytes: len(f.Content), Truncated: f.Truncated})
+		}
+		call.DroppedContexts = contexts[i].Dropped
+		if b.routed {
+			call.Provider, call.Model = b.route.Provider, b.route.Model
+			call.PolicyText = FormatPolicies(b.policies)
+		} else {
+			for name, p := range b.policies {
+				if p.Enabled {
+					call.Policies = append(call.Policies, name)
+				}
+			}
+			sort.Strings(call.Policies)
+		}
+
+		a := ta.newAnalyzerWithContext(ta.comprehensiveClient, contexts[i].Text())
+		reqs, err := a.requests(art, call.PolicyText, personaPrompt)
+		if err != nil {
+			call.Error = err.Error()
+		}
+		call.Requests = len(reqs)
+		for _, r := range reqs {
+			call.EstimatedTokens += r.tokens(call.PolicyText, personaPrompt)
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// AdditionalContext returns the context sent alongside every file, such as
+// the diff enrichment set by WithDiffContext.
+func (ta *TieredAnalyzer) AdditionalContext() string {
+	return ta.additionalContext
+}
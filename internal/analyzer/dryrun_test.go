@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+)
+
+func TestPlanComprehensive(t *testing.T) {
+	mock := &mockBAMLClient{}
+	ta := NewTieredAnalyzer(mock, WithInstantEnabled(false), WithTieredCache(cache.New()))
+	policies := map[string]config.Policy{
+		"errors": {Severity: "warning", Instruction: "Check errors", Enabled: true},
+		"off":    {Severity: "note", Instruction: "Disabled", Enabled: false},
+	}
+	art := input.Artifact{Path: "a.go", Content: "package a\n", Kind: input.KindFile}
+
+	calls := ta.PlanComprehensive(context.Background(), art, policies, "persona")
+	if len(calls) != 1 {
+		t.Fatalf("expected one planned call, got %+v", calls)
+	}
+	c := calls[0]
+	if len(c.Policies) != 1 || c.Policies[0] != "errors" || !strings.Contains(c.PolicyText, "Check errors") {
+		t.Errorf("unexpected policies %v / %q", c.Policies, c.PolicyText)
+	}
+	if c.Requests != 1 || c.EstimatedTokens == 0 || c.Cached {
+		t.Errorf("unexpected plan %+v", c)
+	}
+	if mock.lastCode != "" {
+		t.Error("planning must not call the client")
+	}
+
+	// After a real run the file is answered from the cache
+	if _, err := ta.Analyze(context.Background(), []input.Artifact{art}, policies, "persona"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := ta.PlanComprehensive(context.Background(), art, policies, "persona"); len(calls) != 1 || !calls[0].Cached {
+		t.Errorf("expected the plan to report a cache hit, got %+v", calls)
+	}
+
+	if NewTieredAnalyzer(nil).PlanComprehensive(context.Background(), art, policies, "persona") != nil {
+		t.Error("expected no plan without a comprehensive client")
+	}
+}
+
+func TestPlanComprehensive_Chunked(t *testing.T) {
+	ta := NewTieredAnalyzer(&mockBAMLClient{}, WithTieredTokenBudget(200))
+	policies := map[string]config.Policy{"errors": {Severity: "warning", Instruction: "Check errors", Enabled: true}}
+	art := input.Artifact{Path: "big.go", Content: strings.Repeat("x := compute(alpha, beta)\n", 100), Kind: input.KindFile}
+
+	calls := ta.PlanComprehensive(context.Background(), art, policies, "persona")
+	if len(calls) != 1 || calls[0].Requests < 2 {
+		t.Errorf("expected the file to be split into several requests, got %+v", calls)
+	}
+}
+
+func TestPlanRanges(t *testing.T) {
+	ta := NewTieredAnalyzer(&mockBAMLClient{})
+	policies := map[string]config.Policy{"errors": {Severity: "warning", Instruction: "Check errors", Enabled: true}}
+	art := input.Artifact{Path: "a.go", Content: strings.Repeat("line\n", 100), Kind: input.KindFile}
+
+	calls, err := ta.PlanRanges(context.Background(), art, []input.LineRange{{Start: 5, End: 5}, {Start: 80, End: 81}}, 2, policies, "persona")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0].StartLine != 3 || calls[0].EndLine != 7 || calls[1].StartLine != 78 {
+		t.Errorf("unexpected windows %+v", calls)
+	}
+	if _, err := ta.PlanRanges(context.Background(), art, []input.LineRange{{Start: 500, End: 501}}, 2, policies, "persona"); err == nil {
+		t.Error("expected an error for a range past the end of the file")
+	}
+}
@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// runDuplicateCode reports code copied between the run's artifacts, for
+// each enabled rule running the duplicate-code check. Copies within a file
+// are already reported by the check on that file, so only clone groups
+// spanning files are reported here: one finding per copy, with the copies
+// in other files as related locations. The results are never cached, since
+// they depend on every artifact in the run.
+func (ta *TieredAnalyzer) runDuplicateCode(ctx context.Context, artifacts []input.Artifact) []TieredResult {
+	if len(artifacts) < 2 {
+		return nil
+	}
+	ta.mu.RLock()
+	patterns := ta.instantPatterns
+	ta.mu.RUnlock()
+
+	contents := make(map[string]string, len(artifacts))
+	byPath := make(map[string][]sarif.Result)
+	for _, rule := range patterns {
+		if rule.Type != rules.RuleTypeAST || rule.ASTCheck != astcheck.DuplicateCodeName {
+			continue
+		}
+		index := astcheck.NewCloneIndex(rule.ASTConfig)
+		for _, art := range artifacts {
+			if art.Kind == input.KindSBOM || !rule.AppliesToPath(art.Path) {
+				continue
+			}
+			if len(rule.Languages) > 0 && !matchesLanguage(art.Path, rule.Languages) {
+				continue
+			}
+			if len(ta.pathOverrides) > 0 && ta.disablesFor(art.Path).ruleDisabled(rule) {
+				continue
+			}
+			lang, langName, ok := astcheck.Detect(art.Path)
+			if !ok {
+				continue
+			}
+			// Parse failures are reported by the file's own AST rules
+			tree, _ := ta.parseForAST(ctx, art, lang)
+			if tree == nil {
+				continue
+			}
+			index.Add(art.Path, tree, []byte(art.Content), langName)
+			contents[art.Path] = art.Content
+		}
+
+		for _, group := range index.Groups() {
+			if group.Paths() < 2 {
+				continue
+			}
+			for _, f := range group.Fragments {
+				var others []astcheck.Fragment
+				for _, o := range group.Fragments {
+					if o.Path != f.Path {
+						others = append(others, o)
+					}
+				}
+				byPath[f.Path] = append(byPath[f.Path], cloneResult(rule, group, f, others, contents[f.Path]))
+			}
+		}
+	}
+
+	var results []TieredResult
+	for _, art := range artifacts {
+		if found := byPath[art.Path]; len(found) > 0 {
+			results = append(results, TieredResult{Tier: TierInstant, FilePath: art.Path, Results: found})
+			delete(byPath, art.Path)
+		}
+	}
+	return results
+}
+
+// cloneResult builds the finding for fragment f of group, a copy of others.
+func cloneResult(rule rules.Rule, group astcheck.CloneGroup, f astcheck.Fragment, others []astcheck.Fragment, content string) sarif.Result {
+	related := make([]sarif.Location, 0, len(others))
+	for _, o := range others {
+		related = append(related, sarif.Location{
+			PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: o.Path},
+				Region:           sarif.Region{StartLine: o.StartLine, EndLine: o.EndLine},
+			},
+			Message: &sarif.Message{Text: "duplicate"},
+		})
+	}
+	return sarif.Result{
+		RuleID:  rule.ID,
+		Level:   rule.Level,
+		Message: sarif.Message{Text: astcheck.CloneMessage(f, others)},
+		Locations: []sarif.Location{{
+			PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: f.Path},
+				Region: sarif.Region{
+					StartLine: f.StartLine,
+					EndLine:   f.EndLine,
+					Snippet:   sarif.ExtractSnippet(content, f.StartLine, f.EndLine),
+				},
+				ContextRegion: sarif.ExtractContextRegion(content, f.StartLine, f.EndLine),
+			},
+		}},
+		RelatedLocations: related,
+		Properties:       astResultProperties(rule, group.Extra(f)),
+	}
+}
@@ -0,0 +1,166 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// escalationLevels ranks the SARIF levels that count towards escalation.
+var escalationLevels = map[string]int{"note": 1, "warning": 2, "error": 3}
+
+// SkippedEscalation records a file the comprehensive tier did not analyze
+// because its instant- and fast-tier findings stayed below the escalation
+// threshold.
+type SkippedEscalation struct {
+	Path     string `json:"path"`
+	Findings int    `json:"findings"` // Lower-tier findings at the minimum level or above
+	Reason   string `json:"reason"`
+}
+
+// WithEscalation runs the comprehensive tier only on files the instant and
+// fast tiers flag: those with at least e.MinFindings findings at e.MinLevel
+// or above, or with any finding in one of e.Categories. Files no lower tier
+// screened, or whose fast-tier call failed, are always escalated. Skipped
+// files are reported by EscalationSkipped.
+func WithEscalation(e config.EscalationConfig) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		if !e.Enabled {
+			ta.escalation = nil
+			return
+		}
+		ta.escalation = &e
+	}
+}
+
+// EscalationSkipped returns the files the comprehensive tier skipped under
+// WithEscalation, sorted by path.
+func (ta *TieredAnalyzer) EscalationSkipped() []SkippedEscalation {
+	ta.escalationMu.Lock()
+	defer ta.escalationMu.Unlock()
+	skipped := make([]SkippedEscalation, 0, len(ta.escalationSkipped))
+	for _, s := range ta.escalationSkipped {
+		skipped = append(skipped, s)
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Path < skipped[j].Path })
+	return skipped
+}
+
+// escalationScreen collects the lower-tier findings of one progressive run
+// per file, to decide which files the comprehensive tier analyzes.
+type escalationScreen struct {
+	screened map[string]bool
+	failed   map[string]bool
+	found    map[string][]sarif.Result
+}
+
+func newEscalationScreen() *escalationScreen {
+	return &escalationScreen{
+		screened: make(map[string]bool),
+		failed:   make(map[string]bool),
+		found:    make(map[string][]sarif.Result),
+	}
+}
+
+// observe records a lower-tier result. A nil screen ignores it.
+func (s *escalationScreen) observe(tr TieredResult) {
+	if s == nil {
+		return
+	}
+	s.screened[tr.FilePath] = true
+	if tr.Error != nil {
+		s.failed[tr.FilePath] = true
+	}
+	s.found[tr.FilePath] = append(s.found[tr.FilePath], tr.Results...)
+}
+
+// escalate splits artifacts into those the comprehensive tier analyzes and
+// those it skips under e.
+func (s *escalationScreen) escalate(e *config.EscalationConfig, artifacts []input.Artifact) ([]input.Artifact, []SkippedEscalation) {
+	minLevel := escalationLevels[e.MinLevel]
+	if minLevel == 0 {
+		minLevel = escalationLevels["warning"]
+	}
+	minFindings := max(e.MinFindings, 1)
+	categories := make(map[string]bool, len(e.Categories))
+	for _, c := range e.Categories {
+		categories[c] = true
+	}
+
+	var escalated []input.Artifact
+	var skipped []SkippedEscalation
+	for _, art := range artifacts {
+		if art.Kind == input.KindSBOM || !s.screened[art.Path] || s.failed[art.Path] {
+			escalated = append(escalated, art)
+			continue
+		}
+		count, flagged := 0, false
+		for _, r := range s.found[art.Path] {
+			if escalationLevels[r.Level] >= minLevel {
+				count++
+			}
+			if c, _ := r.Properties["gavel/category"].(string); categories[c] {
+				flagged = true
+			}
+		}
+		if flagged || count >= minFindings {
+			escalated = append(escalated, art)
+			continue
+		}
+		level := e.MinLevel
+		if level == "" {
+			level = "warning"
+		}
+		skipped = append(skipped, SkippedEscalation{
+			Path:     art.Path,
+			Findings: count,
+			Reason:   fmt.Sprintf("%d lower-tier findings at %s or above, below the threshold of %d", count, level, minFindings),
+		})
+	}
+	return escalated, skipped
+}
+
+// recordSkippedEscalations remembers skipped files for EscalationSkipped,
+// keyed by path so a file analyzed in several windows is reported once.
+func (ta *TieredAnalyzer) recordSkippedEscalations(skipped []SkippedEscalation) {
+	if len(skipped) == 0 {
+		return
+	}
+	ta.escalationMu.Lock()
+	defer ta.escalationMu.Unlock()
+	if ta.escalationSkipped == nil {
+		ta.escalationSkipped = make(map[string]SkippedEscalation)
+	}
+	for _, s := range skipped {
+		ta.escalationSkipped[s.Path] = s
+	}
+}
+
+// RecordEscalation notes the escalation thresholds and the files the
+// comprehensive tier skipped in log's run properties, under
+// gavel/escalation, so a report shows which files had no LLM review. It
+// does nothing without WithEscalation.
+func (ta *TieredAnalyzer) RecordEscalation(log *sarif.Log) {
+	if ta.escalation == nil || log == nil || len(log.Runs) == 0 {
+		return
+	}
+	level := ta.escalation.MinLevel
+	if level == "" {
+		level = "warning"
+	}
+	escalation := map[string]interface{}{
+		"minLevel":    level,
+		"minFindings": max(ta.escalation.MinFindings, 1),
+		"skipped":     ta.EscalationSkipped(),
+	}
+	if len(ta.escalation.Categories) > 0 {
+		escalation["categories"] = ta.escalation.Categories
+	}
+	if log.Runs[0].Properties == nil {
+		log.Runs[0].Properties = make(map[string]interface{})
+	}
+	log.Runs[0].Properties["gavel/escalation"] = escalation
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func TestTieredAnalyzer_Escalation(t *testing.T) {
+	patterns := []rules.Rule{
+		{ID: "todo", Pattern: regexp.MustCompile(`TODO`), Level: "note", Message: "todo", Confidence: 0.9, Category: rules.CategoryMaintainability},
+		{ID: "exec", Pattern: regexp.MustCompile(`exec\(`), Level: "warning", Message: "exec", Confidence: 0.9, Category: rules.CategorySecurity},
+	}
+	artifacts := []input.Artifact{
+		{Path: "clean.go", Content: "package a\n", Kind: input.KindFile},
+		{Path: "todo.go", Content: "package b // TODO\n", Kind: input.KindFile},
+		{Path: "exec.go", Content: "package c\n\nvar _ = exec(x)\n", Kind: input.KindFile},
+	}
+	policies := map[string]config.Policy{"p": {Instruction: "check", Enabled: true}}
+
+	tests := []struct {
+		name      string
+		opts      []TieredAnalyzerOption
+		escalated []string
+		skipped   []string
+	}{
+		{"warnings escalate", []TieredAnalyzerOption{WithEscalation(config.EscalationConfig{Enabled: true})}, []string{"exec.go"}, []string{"clean.go", "todo.go"}},
+		{"categories escalate at any level", []TieredAnalyzerOption{WithEscalation(config.EscalationConfig{Enabled: true, MinFindings: 2, Categories: []string{"maintainability"}})}, []string{"todo.go"}, []string{"clean.go", "exec.go"}},
+		{"notes count", []TieredAnalyzerOption{WithEscalation(config.EscalationConfig{Enabled: true, MinLevel: "note"})}, []string{"todo.go", "exec.go"}, []string{"clean.go"}},
+		{"disabled", []TieredAnalyzerOption{WithEscalation(config.EscalationConfig{})}, []string{"clean.go", "todo.go", "exec.go"}, nil},
+		{"nothing screened", []TieredAnalyzerOption{WithEscalation(config.EscalationConfig{Enabled: true}), WithInstantEnabled(false)}, []string{"clean.go", "todo.go", "exec.go"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &batchEchoClient{}
+			ta := NewTieredAnalyzer(client, append([]TieredAnalyzerOption{WithInstantPatterns(patterns)}, tt.opts...)...)
+			if _, err := ta.Analyze(context.Background(), artifacts, policies, "persona"); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(client.codes) != len(tt.escalated) {
+				t.Errorf("expected %d comprehensive calls, got %d", len(tt.escalated), len(client.codes))
+			}
+			skipped := ta.EscalationSkipped()
+			if len(skipped) != len(tt.skipped) {
+				t.Fatalf("expected %v skipped, got %+v", tt.skipped, skipped)
+			}
+			for i, s := range skipped {
+				if s.Path != tt.skipped[i] || s.Reason == "" {
+					t.Errorf("skipped[%d] = %+v, want %s", i, s, tt.skipped[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTieredAnalyzer_EscalationRanges(t *testing.T) {
+	patterns := []rules.Rule{{ID: "exec", Pattern: regexp.MustCompile(`exec\(`), Level: "warning", Message: "exec", Confidence: 0.9}}
+	art := input.Artifact{Path: "a.go", Content: "package a\n\nvar _ = exec(x)\n\nvar y = 1\n", Kind: input.KindFile}
+	policies := map[string]config.Policy{"p": {Instruction: "check", Enabled: true}}
+	client := &batchEchoClient{}
+	ta := NewTieredAnalyzer(client, WithInstantPatterns(patterns), WithEscalation(config.EscalationConfig{Enabled: true}))
+
+	// Only findings on the changed lines escalate
+	if _, err := ta.AnalyzeRanges(context.Background(), art, []input.LineRange{{Start: 5, End: 5}}, 0, policies, "persona"); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.codes) != 0 || len(ta.EscalationSkipped()) != 1 {
+		t.Errorf("expected the clean range to be skipped, got %d calls", len(client.codes))
+	}
+	if _, err := ta.AnalyzeRanges(context.Background(), art, []input.LineRange{{Start: 3, End: 3}}, 0, policies, "persona"); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.codes) != 1 {
+		t.Errorf("expected the flagged range to escalate, got %d calls", len(client.codes))
+	}
+
+	log := &sarif.Log{Runs: []sarif.Run{{}}}
+	ta.RecordEscalation(log)
+	escalation, _ := log.Runs[0].Properties["gavel/escalation"].(map[string]interface{})
+	if skipped, _ := escalation["skipped"].([]SkippedEscalation); escalation["minLevel"] != "warning" || len(skipped) != 1 || skipped[0].Path != "a.go" {
+		t.Errorf("unexpected gavel/escalation %+v", escalation)
+	}
+}
@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// propCachedSource records, on a result stored under a normalized key, the
+// hash of the exact content it was produced from.
+const propCachedSource = "gavel/cached_source"
+
+// WithNormalizedCacheKeys keys comprehensive-tier results by the file's
+// token stream instead of its raw content, so a renamed, reformatted, or
+// re-commented file still hits the cache. Results are stored against token
+// positions and mapped back onto the lines of the file being analyzed.
+// Files in languages tree-sitter cannot parse keep raw content keys.
+func WithNormalizedCacheKeys(enabled bool) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.normalizeCacheKeys = enabled
+	}
+}
+
+// contentCacheKey returns the cache key for a file's comprehensive-tier
+// results, and the token stream it was derived from when the key is
+// normalized.
+func (ta *TieredAnalyzer) contentCacheKey(art input.Artifact, policies, persona string) (string, *astcheck.TokenStream) {
+	if ta.normalizeCacheKeys {
+		if tokens := astcheck.Tokens(art.Path, []byte(art.Content)); tokens != nil && tokens.Len() > 0 {
+			return cache.NormalizedContentKey(tokens.Text, policies, persona), tokens
+		}
+	}
+	return cache.ContentKey(art.Content, policies, persona), nil
+}
+
+// anchorResults prepares results for a normalized cache entry. Locations in
+// the analyzed file lose their URI, snippet, and context, and their lines
+// become 1-based token indexes, none of which depend on the file's name or
+// layout.
+func anchorResults(results []sarif.Result, art input.Artifact, tokens *astcheck.TokenStream) []sarif.Result {
+	anchor := func(r sarif.Region) sarif.Region {
+		if r.StartLine < 1 {
+			return sarif.Region{}
+		}
+		end := r.EndLine
+		if end < r.StartLine {
+			end = r.StartLine
+		}
+		return sarif.Region{
+			StartLine: tokens.FirstToken(r.StartLine) + 1,
+			EndLine:   tokens.LastToken(end) + 1,
+		}
+	}
+	anchorLocs := func(locs []sarif.Location) []sarif.Location {
+		out := make([]sarif.Location, len(locs))
+		for i, loc := range locs {
+			if loc.PhysicalLocation.ArtifactLocation.URI == art.Path {
+				loc.PhysicalLocation = sarif.PhysicalLocation{Region: anchor(loc.PhysicalLocation.Region)}
+				loc.LogicalLocations = nil
+			}
+			out[i] = loc
+		}
+		return out
+	}
+
+	source := cache.GenerateKey(art.Content)
+	out := make([]sarif.Result, len(results))
+	for i, r := range results {
+		r.Locations = anchorLocs(r.Locations)
+		r.RelatedLocations = anchorLocs(r.RelatedLocations)
+		r.Fixes = anchorFixes(r.Fixes, art.Path, anchor)
+		r.Properties = copyProperties(mapSnapRegions(r.Properties, anchor))
+		r.Properties[propCachedSource] = source
+		out[i] = r
+	}
+	return out
+}
+
+// placeResults maps results from a normalized cache entry onto the file
+// being analyzed, recomputing what anchorResults dropped. Fixes are kept
+// only when the file is byte-for-byte what they were written against.
+func placeResults(results []sarif.Result, art input.Artifact, tokens *astcheck.TokenStream) []sarif.Result {
+	idx, _ := astcheck.BuildIndex(art.Path, []byte(art.Content))
+	place := func(r sarif.Region) sarif.Region {
+		if r.StartLine < 1 {
+			return r
+		}
+		start := tokens.Line(clampToken(tokens, r.StartLine-1))
+		end := tokens.Line(clampToken(tokens, r.EndLine-1))
+		if end < start {
+			end = start
+		}
+		return sarif.Region{StartLine: start, EndLine: end}
+	}
+	placeLocs := func(locs []sarif.Location) []sarif.Location {
+		out := make([]sarif.Location, len(locs))
+		for i, loc := range locs {
+			if loc.PhysicalLocation.ArtifactLocation.URI == "" {
+				region := place(loc.PhysicalLocation.Region)
+				region.Snippet = sarif.ExtractSnippet(art.Content, region.StartLine, region.EndLine)
+				loc.PhysicalLocation = sarif.PhysicalLocation{
+					ArtifactLocation: sarif.ArtifactLocation{URI: art.Path},
+					Region:           region,
+					ContextRegion:    sarif.ExtractContextRegion(art.Content, region.StartLine, region.EndLine),
+				}
+				if idx != nil {
+					if ll := astcheck.LogicalLocationFromIndex(idx, region.StartLine); ll != nil {
+						loc.LogicalLocations = []sarif.LogicalLocation{*ll}
+					}
+				}
+			}
+			out[i] = loc
+		}
+		return out
+	}
+
+	source := cache.GenerateKey(art.Content)
+	out := make([]sarif.Result, len(results))
+	for i, r := range results {
+		r.Locations = placeLocs(r.Locations)
+		r.RelatedLocations = placeLocs(r.RelatedLocations)
+		r.Properties = copyProperties(mapSnapRegions(r.Properties, place))
+		if r.Properties[propCachedSource] == source {
+			r.Fixes = placeFixes(r.Fixes, art.Path, place)
+		} else {
+			r.Fixes = nil
+		}
+		delete(r.Properties, propCachedSource)
+		out[i] = r
+	}
+	return out
+}
+
+func anchorFixes(fixes []sarif.Fix, path string, anchor func(sarif.Region) sarif.Region) []sarif.Fix {
+	return mapFixRegions(fixes, path, "", anchor)
+}
+
+func placeFixes(fixes []sarif.Fix, path string, place func(sarif.Region) sarif.Region) []sarif.Fix {
+	return mapFixRegions(fixes, "", path, place)
+}
+
+// mapFixRegions copies fixes, rewriting the replacements of changes to the
+// artifact at from so they apply to the artifact at to.
+func mapFixRegions(fixes []sarif.Fix, from, to string, fn func(sarif.Region) sarif.Region) []sarif.Fix {
+	if fixes == nil {
+		return nil
+	}
+	out := make([]sarif.Fix, len(fixes))
+	for i, fix := range fixes {
+		changes := make([]sarif.ArtifactChange, len(fix.ArtifactChanges))
+		for j, change := range fix.ArtifactChanges {
+			if change.ArtifactLocation.URI == from {
+				change.ArtifactLocation.URI = to
+				replacements := make([]sarif.Replacement, len(change.Replacements))
+				for k, rep := range change.Replacements {
+					rep.DeletedRegion = fn(rep.DeletedRegion)
+					replacements[k] = rep
+				}
+				change.Replacements = replacements
+			}
+			changes[j] = change
+		}
+		fix.ArtifactChanges = changes
+		out[i] = fix
+	}
+	return out
+}
+
+// clampToken bounds a stored token index to the stream, which only differs
+// in length from the one the index came from if the cache was tampered with.
+func clampToken(tokens *astcheck.TokenStream, i int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= tokens.Len() {
+		return tokens.Len() - 1
+	}
+	return i
+}
+
+func copyProperties(props map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(props)+1)
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
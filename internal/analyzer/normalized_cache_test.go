@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func TestNormalizedCacheKeys_SurviveReformatting(t *testing.T) {
+	original := input.Artifact{Path: "old.go", Content: `package main
+
+func Run() error {
+	return nil
+}
+`}
+	moved := input.Artifact{Path: "pkg/new.go", Content: `package main
+
+// Run does nothing.
+
+func Run() error { return nil }
+`}
+
+	ta := NewTieredAnalyzer(nil, WithNormalizedCacheKeys(true))
+	key, tokens := ta.contentCacheKey(original, "policies", "persona")
+	movedKey, movedTokens := ta.contentCacheKey(moved, "policies", "persona")
+	if tokens == nil || movedTokens == nil {
+		t.Fatal("expected Go files to get normalized keys")
+	}
+	if key != movedKey {
+		t.Fatal("expected a renamed, reformatted file to share the cache key")
+	}
+	if key == cache.ContentKey(original.Content, "policies", "persona") {
+		t.Error("expected the normalized key to differ from the raw key")
+	}
+
+	results := []sarif.Result{{
+		RuleID: "R1",
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: original.Path},
+			Region:           sarif.Region{StartLine: 4, EndLine: 4},
+		}}},
+		Fixes: []sarif.Fix{{ArtifactChanges: []sarif.ArtifactChange{{
+			ArtifactLocation: sarif.ArtifactLocation{URI: original.Path},
+			Replacements:     []sarif.Replacement{{DeletedRegion: sarif.Region{StartLine: 4, EndLine: 4}}},
+		}}}},
+		Properties: map[string]interface{}{"gavel/tier": "comprehensive"},
+	}}
+	stored := anchorResults(results, original, tokens)
+	if results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != original.Path {
+		t.Fatal("anchorResults must not modify its input")
+	}
+
+	got := placeResults(stored, moved, movedTokens)
+	loc := got[0].Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != moved.Path {
+		t.Errorf("URI = %q, want %q", loc.ArtifactLocation.URI, moved.Path)
+	}
+	if loc.Region.StartLine != 5 || loc.Region.EndLine != 5 {
+		t.Errorf("region = %d-%d, want 5-5", loc.Region.StartLine, loc.Region.EndLine)
+	}
+	if loc.Region.Snippet == nil || strings.TrimSpace(loc.Region.Snippet.Text) != "func Run() error { return nil }" {
+		t.Errorf("unexpected snippet %+v", loc.Region.Snippet)
+	}
+	if len(got[0].Fixes) != 0 {
+		t.Error("expected fixes to be dropped for reformatted content")
+	}
+	if _, ok := got[0].Properties[propCachedSource]; ok {
+		t.Error("expected the cached source hash to be removed")
+	}
+
+	same := placeResults(stored, original, tokens)
+	if len(same[0].Fixes) != 1 || same[0].Fixes[0].ArtifactChanges[0].ArtifactLocation.URI != original.Path {
+		t.Errorf("expected fixes to be kept for identical content, got %+v", same[0].Fixes)
+	}
+	if same[0].Locations[0].PhysicalLocation.Region.StartLine != 4 {
+		t.Errorf("expected identical content to keep its lines, got %+v", same[0].Locations[0].PhysicalLocation.Region)
+	}
+}
+
+func TestNormalizedCacheKeys_FallBackToRawContent(t *testing.T) {
+	art := input.Artifact{Path: "notes.txt", Content: "hello"}
+	ta := NewTieredAnalyzer(nil, WithNormalizedCacheKeys(true))
+	key, tokens := ta.contentCacheKey(art, "p", "c")
+	if tokens != nil || key != cache.ContentKey(art.Content, "p", "c") {
+		t.Error("expected unsupported languages to keep raw content keys")
+	}
+}
@@ -0,0 +1,184 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	sitter "github.com/smacker/go-tree-sitter"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// ParseErrorAction selects how the instant tier reacts when tree-sitter
+// cannot parse a file for AST rules.
+type ParseErrorAction string
+
+const (
+	// ParseErrorWarn logs a warning and records the file (the default).
+	ParseErrorWarn ParseErrorAction = "warn"
+	// ParseErrorDiagnostic additionally emits a gavel/parse-error result.
+	ParseErrorDiagnostic ParseErrorAction = "diagnostic"
+	// ParseErrorIgnore records the file without logging or reporting it.
+	ParseErrorIgnore ParseErrorAction = "ignore"
+)
+
+// ParseErrorRuleID is the rule ID of the diagnostic emitted under
+// ParseErrorDiagnostic.
+const ParseErrorRuleID = "gavel/parse-error"
+
+// ParseFailure records a file whose AST rules were affected by a parse
+// failure. Skipped is true when the parser returned an error and AST rules
+// did not run at all; otherwise the file had syntax errors and AST rules ran
+// against the partial tree.
+type ParseFailure struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Reason  string `json:"reason"`
+	Skipped bool   `json:"skipped"`
+}
+
+// WithParseErrorPolicy sets how unparseable files are handled and how many
+// extra attempts are made when the parser itself returns an error. An empty
+// action means ParseErrorWarn.
+func WithParseErrorPolicy(action ParseErrorAction, retries int) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.parseErrorAction = action
+		ta.parseRetries = retries
+	}
+}
+
+// ParseFailures returns the files that failed to parse during the latest
+// AnalyzeProgressive, Analyze or AnalyzeRanges call, sorted by path.
+func (ta *TieredAnalyzer) ParseFailures() []ParseFailure {
+	ta.parseMu.Lock()
+	defer ta.parseMu.Unlock()
+	out := make([]ParseFailure, 0, len(ta.parseFailures))
+	for _, f := range ta.parseFailures {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// resetParseFailures forgets the failures of the previous analysis, so a
+// long-lived analyzer reports only those of the one starting.
+func (ta *TieredAnalyzer) resetParseFailures() {
+	ta.parseMu.Lock()
+	ta.parseFailures = nil
+	ta.parseMu.Unlock()
+}
+
+// parseForAST parses art, retrying parser errors up to the configured count
+// until ctx ends.
+// It returns a nil tree when the file could not be parsed at all, and a
+// non-nil failure when the file was skipped or contains syntax errors.
+func (ta *TieredAnalyzer) parseForAST(ctx context.Context, art input.Artifact, lang *sitter.Language) (*sitter.Tree, *ParseFailure) {
+	parser := sitter.NewParser()
+	parser.SetLanguage(lang)
+
+	var err error
+	for attempt := 0; attempt <= ta.parseRetries; attempt++ {
+		var tree *sitter.Tree
+		tree, err = parser.ParseCtx(ctx, nil, []byte(art.Content))
+		if err != nil {
+			continue
+		}
+		if line, ok := firstSyntaxError(tree.RootNode()); ok {
+			return tree, &ParseFailure{Path: art.Path, Line: line, Reason: fmt.Sprintf("syntax error at line %d", line)}
+		}
+		return tree, nil
+	}
+	return nil, &ParseFailure{Path: art.Path, Reason: err.Error(), Skipped: true}
+}
+
+// firstSyntaxError returns the 1-based line of the first ERROR or MISSING
+// node under root.
+func firstSyntaxError(root *sitter.Node) (int, bool) {
+	if !root.HasError() {
+		return 0, false
+	}
+	var walk func(n *sitter.Node) (int, bool)
+	walk = func(n *sitter.Node) (int, bool) {
+		if n.IsError() || n.IsMissing() {
+			return int(n.StartPoint().Row) + 1, true
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			c := n.Child(i)
+			if c != nil && (c.HasError() || c.IsMissing()) {
+				if line, ok := walk(c); ok {
+					return line, true
+				}
+			}
+		}
+		return 0, false
+	}
+	if line, ok := walk(root); ok {
+		return line, true
+	}
+	return int(root.StartPoint().Row) + 1, true
+}
+
+// reportParseFailure records f and applies the configured action, returning
+// the gavel/parse-error result to emit, if any.
+func (ta *TieredAnalyzer) reportParseFailure(art input.Artifact, f ParseFailure) []sarif.Result {
+	ta.parseMu.Lock()
+	if ta.parseFailures == nil {
+		ta.parseFailures = make(map[string]ParseFailure)
+	}
+	ta.parseFailures[f.Path] = f
+	ta.parseMu.Unlock()
+
+	action := ta.parseErrorAction
+	if action == "" {
+		action = ParseErrorWarn
+	}
+	if action == ParseErrorIgnore {
+		return nil
+	}
+	slog.Warn("AST parse failed", "file", f.Path, "reason", f.Reason, "ast_rules_skipped", f.Skipped)
+	if action != ParseErrorDiagnostic {
+		return nil
+	}
+
+	line := f.Line
+	if line < 1 {
+		line = 1
+	}
+	msg := fmt.Sprintf("Could not parse %s: %s; AST rules ran on a partial syntax tree", f.Path, f.Reason)
+	if f.Skipped {
+		msg = fmt.Sprintf("Could not parse %s: %s; AST rules were skipped", f.Path, f.Reason)
+	}
+	return []sarif.Result{{
+		RuleID:  ParseErrorRuleID,
+		Level:   "note",
+		Message: sarif.Message{Text: msg},
+		Locations: []sarif.Location{{
+			PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: art.Path},
+				Region: sarif.Region{
+					StartLine: line,
+					EndLine:   line,
+					Snippet:   sarif.ExtractSnippet(art.Content, line, line),
+				},
+			},
+		}},
+		Properties: map[string]interface{}{
+			"gavel/explanation": "Tree-sitter could not fully parse this file, so AST-based rules may have missed findings in it.",
+			"gavel/confidence":  1.0,
+			"gavel/tier":        "instant",
+		},
+	}}
+}
+
+// ParseErrorDescriptor describes the gavel/parse-error rule for inclusion in
+// a SARIF run's rule list.
+func ParseErrorDescriptor() sarif.ReportingDescriptor {
+	return sarif.ReportingDescriptor{
+		ID:               ParseErrorRuleID,
+		ShortDescription: sarif.Message{Text: "File could not be parsed for AST rules"},
+		DefaultConfig:    &sarif.ReportingConfiguration{Level: "note"},
+	}
+}
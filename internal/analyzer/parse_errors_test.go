@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// parseErrorRules returns a regex rule and an AST rule that both apply to
+// Go files.
+func parseErrorRules() []rules.Rule {
+	return []rules.Rule{
+		{
+			ID: "TODO1", Type: rules.RuleTypeRegex,
+			Pattern: regexp.MustCompile(`TODO`),
+			Level:   "note", Message: "todo", Confidence: 0.5,
+		},
+		{
+			ID: "AST1", Type: rules.RuleTypeAST, ASTCheck: "function-length",
+			ASTConfig: map[string]interface{}{"max_lines": 50},
+			Level:     "note", Message: "long function", Confidence: 0.5,
+		},
+	}
+}
+
+// brokenGo has an unterminated function signature on line 4.
+var brokenGo = input.Artifact{
+	Path:    "broken.go",
+	Content: "package x\n\n// TODO: finish\nfunc broken( {\n\treturn\n}\n",
+	Kind:    input.KindFile,
+}
+
+func findRule(results []sarif.Result, id string) *sarif.Result {
+	for i := range results {
+		if results[i].RuleID == id {
+			return &results[i]
+		}
+	}
+	return nil
+}
+
+func TestParseErrors_WarnByDefault(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	ta := NewTieredAnalyzer(nil, WithInstantPatterns(parseErrorRules()))
+	results := ta.RunPatternMatching(context.Background(), brokenGo)
+
+	if findRule(results, "TODO1") == nil {
+		t.Error("expected regex rule to still run on an unparseable file")
+	}
+	if findRule(results, ParseErrorRuleID) != nil {
+		t.Error("expected no parse-error diagnostic in warn mode")
+	}
+	if !strings.Contains(logs.String(), "AST parse failed") || !strings.Contains(logs.String(), "broken.go") {
+		t.Errorf("expected parse warning to be logged, got %q", logs.String())
+	}
+
+	failures := ta.ParseFailures()
+	if len(failures) != 1 || failures[0].Path != "broken.go" {
+		t.Fatalf("expected one parse failure for broken.go, got %+v", failures)
+	}
+	if failures[0].Skipped {
+		t.Error("syntax errors should not mark AST rules as skipped")
+	}
+}
+
+func TestParseErrors_Diagnostic(t *testing.T) {
+	ta := NewTieredAnalyzer(nil,
+		WithInstantPatterns(parseErrorRules()),
+		WithParseErrorPolicy(ParseErrorDiagnostic, 0),
+	)
+	results := ta.RunPatternMatching(context.Background(), brokenGo)
+
+	if findRule(results, "TODO1") == nil {
+		t.Error("expected regex rule to still run on an unparseable file")
+	}
+	diag := findRule(results, ParseErrorRuleID)
+	if diag == nil {
+		t.Fatal("expected a gavel/parse-error diagnostic")
+	}
+	if diag.Level != "note" {
+		t.Errorf("expected note level, got %q", diag.Level)
+	}
+	loc := diag.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "broken.go" || loc.Region.StartLine != 4 {
+		t.Errorf("expected diagnostic at broken.go:4, got %s:%d", loc.ArtifactLocation.URI, loc.Region.StartLine)
+	}
+}
+
+func TestParseErrors_Ignore(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	ta := NewTieredAnalyzer(nil,
+		WithInstantPatterns(parseErrorRules()),
+		WithParseErrorPolicy(ParseErrorIgnore, 0),
+	)
+	results := ta.RunPatternMatching(context.Background(), brokenGo)
+
+	if findRule(results, ParseErrorRuleID) != nil {
+		t.Error("expected no parse-error diagnostic in ignore mode")
+	}
+	if strings.Contains(logs.String(), "AST parse failed") {
+		t.Errorf("expected no warning in ignore mode, got %q", logs.String())
+	}
+	if len(ta.ParseFailures()) != 1 {
+		t.Errorf("expected ignored failures to still be counted, got %+v", ta.ParseFailures())
+	}
+}
+
+func TestParseErrors_ValidFile(t *testing.T) {
+	ta := NewTieredAnalyzer(nil,
+		WithInstantPatterns(parseErrorRules()),
+		WithParseErrorPolicy(ParseErrorDiagnostic, 0),
+	)
+	art := input.Artifact{Path: "ok.go", Content: "package x\n\nfunc ok() {}\n", Kind: input.KindFile}
+	if results := ta.RunPatternMatching(context.Background(), art); findRule(results, ParseErrorRuleID) != nil {
+		t.Error("expected no parse-error diagnostic for a valid file")
+	}
+	if failures := ta.ParseFailures(); len(failures) != 0 {
+		t.Errorf("expected no parse failures, got %+v", failures)
+	}
+}
+
+func TestParseErrors_ResetPerAnalysis(t *testing.T) {
+	ta := NewTieredAnalyzer(nil,
+		WithInstantPatterns(parseErrorRules()),
+		WithParseErrorPolicy(ParseErrorIgnore, 0),
+	)
+	if _, err := ta.Analyze(context.Background(), []input.Artifact{brokenGo}, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if failures := ta.ParseFailures(); len(failures) != 1 {
+		t.Fatalf("expected one parse failure from the first analysis, got %+v", failures)
+	}
+
+	art := input.Artifact{Path: "ok.go", Content: "package x\n\nfunc ok() {}\n", Kind: input.KindFile}
+	if _, err := ta.Analyze(context.Background(), []input.Artifact{art}, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if failures := ta.ParseFailures(); len(failures) != 0 {
+		t.Errorf("expected the second analysis to forget the first one's failures, got %+v", failures)
+	}
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/pathglob"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// WithPathOverrides disables rule categories or rule IDs for artifacts whose
+// path matches an override. Instant-tier rules are skipped outright; results
+// from every tier (including cached ones) are dropped when their rule ID is
+// disabled or belongs to a disabled instant-rule category.
+func WithPathOverrides(overrides []config.PathOverride) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.pathOverrides = overrides
+	}
+}
+
+// pathDisables is the set of categories and rule IDs disabled for one path.
+type pathDisables struct {
+	categories map[string]bool
+	ruleIDs    map[string]bool
+}
+
+func (d pathDisables) empty() bool {
+	return len(d.categories) == 0 && len(d.ruleIDs) == 0
+}
+
+// disablesFor collects the categories and rule IDs disabled for path by all
+// matching overrides.
+func (ta *TieredAnalyzer) disablesFor(path string) pathDisables {
+	d := pathDisables{categories: map[string]bool{}, ruleIDs: map[string]bool{}}
+	for _, o := range ta.pathOverrides {
+		matched := false
+		for _, glob := range o.Paths {
+			if pathglob.Match(glob, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		for _, c := range o.DisableCategories {
+			d.categories[c] = true
+		}
+		for _, id := range o.DisableRules {
+			d.ruleIDs[id] = true
+		}
+	}
+	return d
+}
+
+// ruleDisabled reports whether rule is switched off by d.
+func (d pathDisables) ruleDisabled(rule rules.Rule) bool {
+	return d.ruleIDs[rule.ID] || d.categories[string(rule.Category)]
+}
+
+// filterPathOverrides drops results for path that a path override disables.
+// Results are matched by rule ID and, for IDs that name an instant rule, by
+// that rule's category. Secrets findings belong to the secret category.
+func (ta *TieredAnalyzer) filterPathOverrides(path string, results []sarif.Result) []sarif.Result {
+	if len(ta.pathOverrides) == 0 || len(results) == 0 {
+		return results
+	}
+	d := ta.disablesFor(path)
+	if d.empty() {
+		return results
+	}
+
+	ta.mu.RLock()
+	categories := make(map[string]string, len(ta.instantPatterns))
+	for _, r := range ta.instantPatterns {
+		categories[r.ID] = string(r.Category)
+	}
+	ta.mu.RUnlock()
+
+	kept := make([]sarif.Result, 0, len(results))
+	for _, r := range results {
+		category := categories[r.RuleID]
+		if strings.HasPrefix(r.RuleID, secretRulePrefix) {
+			category = string(rules.CategorySecret)
+		}
+		if d.ruleIDs[r.RuleID] || d.categories[category] {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
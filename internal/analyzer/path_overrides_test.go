@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// overrideRules returns one security and one maintainability rule that both
+// match overrideContent.
+func overrideRules() []rules.Rule {
+	return []rules.Rule{
+		{
+			ID: "SEC1", Type: rules.RuleTypeRegex, Category: rules.CategorySecurity,
+			Pattern: regexp.MustCompile(`password = "`),
+			Level:   "error", Message: "hardcoded password", Confidence: 0.9,
+		},
+		{
+			ID: "MNT1", Type: rules.RuleTypeRegex, Category: rules.CategoryMaintainability,
+			Pattern: regexp.MustCompile(`TODO`),
+			Level:   "note", Message: "todo", Confidence: 0.5,
+		},
+	}
+}
+
+const overrideContent = "package x\n\n// TODO: rotate\nvar password = \"hunter2\"\n"
+
+func ruleIDs(results []TieredResult) map[string]bool {
+	ids := map[string]bool{}
+	for _, tr := range results {
+		for _, r := range tr.Results {
+			ids[r.RuleID] = true
+		}
+	}
+	return ids
+}
+
+func resultRuleIDs(results []sarif.Result) []string {
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.RuleID)
+	}
+	return ids
+}
+
+func TestTieredAnalyzer_PathOverrides_DisableCategory(t *testing.T) {
+	ta := NewTieredAnalyzer(&tieredMockClient{},
+		WithInstantPatterns(overrideRules()),
+		WithPathOverrides([]config.PathOverride{{
+			Paths:             []string{"vendor/**", "third_party/**"},
+			DisableCategories: []string{"maintainability"},
+		}}),
+	)
+
+	vendored := ta.RunPatternMatching(context.Background(), input.Artifact{Path: "vendor/lib/x.go", Content: overrideContent, Kind: input.KindFile})
+	if len(vendored) != 1 || vendored[0].RuleID != "SEC1" {
+		t.Errorf("expected only SEC1 under vendor/, got %v", resultRuleIDs(vendored))
+	}
+
+	own := ta.RunPatternMatching(context.Background(), input.Artifact{Path: "internal/x.go", Content: overrideContent, Kind: input.KindFile})
+	if len(own) != 2 {
+		t.Errorf("expected both rules outside vendor/, got %v", resultRuleIDs(own))
+	}
+}
+
+func TestTieredAnalyzer_PathOverrides_DisableRuleAcrossTiers(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{
+		{RuleID: "error-handling", Level: "warning", Message: "unchecked error", StartLine: 1, EndLine: 1, Confidence: 0.8},
+		{RuleID: "naming", Level: "note", Message: "rename", StartLine: 1, EndLine: 1, Confidence: 0.8},
+	}}
+	ta := NewTieredAnalyzer(mock,
+		WithInstantPatterns(overrideRules()),
+		WithPathOverrides([]config.PathOverride{{
+			Paths:        []string{"generated/**"},
+			DisableRules: []string{"naming", "SEC1"},
+		}}),
+	)
+	policies := map[string]config.Policy{
+		"error-handling": {Instruction: "check errors", Enabled: true},
+		"naming":         {Instruction: "check names", Enabled: true},
+	}
+
+	art := input.Artifact{Path: "generated/api.go", Content: overrideContent, Kind: input.KindFile}
+	var results []TieredResult
+	for tr := range ta.AnalyzeProgressive(context.Background(), []input.Artifact{art}, policies, "persona") {
+		results = append(results, tr)
+	}
+
+	ids := ruleIDs(results)
+	if ids["naming"] || ids["SEC1"] {
+		t.Errorf("expected naming and SEC1 disabled under generated/, got %v", ids)
+	}
+	if !ids["error-handling"] || !ids["MNT1"] {
+		t.Errorf("expected error-handling and MNT1 to still fire, got %v", ids)
+	}
+}
@@ -3,6 +3,9 @@ package analyzer
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	personas "github.com/chris-regnier/gavel/internal/persona"
 )
 
 // Persona prompts define expert perspectives for code analysis.
@@ -195,7 +198,8 @@ func IsProsePersona(persona string) bool {
 }
 
 // GetPersonaPrompt returns the system prompt string for the given persona.
-// Valid personas are: "code-reviewer", "code-reviewer-verbose", "architect", "security", "research-assistant", "sharp-editor".
+// Valid personas are: "code-reviewer", "code-reviewer-verbose", "architect", "security", "research-assistant", "sharp-editor",
+// plus any custom personas registered with persona.Register.
 //
 // This function does NOT make LLM calls - it returns static strings.
 // Personas are fixed expert perspectives, not dynamic content.
@@ -214,6 +218,9 @@ func GetPersonaPrompt(ctx context.Context, persona string) (string, error) {
 	case "sharp-editor":
 		return sharpEditorPrompt, nil
 	default:
-		return "", fmt.Errorf("unknown persona: %s (valid options: code-reviewer, code-reviewer-verbose, architect, security, research-assistant, sharp-editor)", persona)
+		if p, ok := personas.Lookup(persona); ok {
+			return p.SystemPrompt, nil
+		}
+		return "", fmt.Errorf("unknown persona: %s (valid options: %s)", persona, strings.Join(personas.Names(), ", "))
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 	"testing"
+
+	"github.com/chris-regnier/gavel/internal/persona"
 )
 
 func TestGetPersonaPrompt(t *testing.T) {
@@ -134,6 +136,25 @@ func TestGetPersonaPromptIsStatic(t *testing.T) {
 	}
 }
 
+func TestGetPersonaPrompt_Custom(t *testing.T) {
+	ctx := context.Background()
+	persona.Register([]persona.Persona{{Name: "react-expert", SystemPrompt: "You review React hooks."}})
+	defer persona.Register(nil)
+
+	prompt, err := GetPersonaPrompt(ctx, "react-expert")
+	if err != nil {
+		t.Fatalf("GetPersonaPrompt() unexpected error: %v", err)
+	}
+	if prompt != "You review React hooks." {
+		t.Errorf("GetPersonaPrompt() = %q, want the custom system prompt", prompt)
+	}
+
+	_, err = GetPersonaPrompt(ctx, "vue-expert")
+	if err == nil || !strings.Contains(err.Error(), "react-expert") {
+		t.Errorf("expected an error listing the custom persona, got %v", err)
+	}
+}
+
 func TestApplicabilityFilterPrompt_NotEmpty(t *testing.T) {
 	if ApplicabilityFilterPrompt == "" {
 		t.Error("ApplicabilityFilterPrompt should not be empty")
@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// RuleTiming is the cumulative cost of one instant-tier rule across every
+// artifact it ran against.
+type RuleTiming struct {
+	RuleID   string
+	Type     rules.RuleType
+	Duration time.Duration
+	Files    int
+	Matches  int
+	// OverBudget is set by Report when Duration exceeds the budget.
+	OverBudget bool
+}
+
+// RuleProfiler accumulates per-rule match time for the instant tier. It is
+// safe for concurrent use, since files are analyzed in parallel.
+type RuleProfiler struct {
+	mu      sync.Mutex
+	timings map[string]*RuleTiming
+	files   map[string]bool
+}
+
+// NewRuleProfiler returns an empty profiler.
+func NewRuleProfiler() *RuleProfiler {
+	return &RuleProfiler{
+		timings: make(map[string]*RuleTiming),
+		files:   make(map[string]bool),
+	}
+}
+
+// WithRuleProfiler records how long each instant-tier rule spends matching.
+func WithRuleProfiler(p *RuleProfiler) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.ruleProfiler = p
+	}
+}
+
+// record adds one rule evaluation against path.
+func (p *RuleProfiler) record(rule rules.Rule, path string, d time.Duration, matches int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, ok := p.timings[rule.ID]
+	if !ok {
+		t = &RuleTiming{RuleID: rule.ID, Type: rule.Type}
+		p.timings[rule.ID] = t
+	}
+	t.Duration += d
+	t.Files++
+	t.Matches += matches
+	p.files[path] = true
+}
+
+// FileCount returns the number of distinct artifacts profiled.
+func (p *RuleProfiler) FileCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.files)
+}
+
+// Report returns the topN slowest rules by cumulative match time, slowest
+// first, with OverBudget set on every rule whose total exceeds budget. A
+// topN of zero or less returns all rules; a zero budget flags nothing.
+func (p *RuleProfiler) Report(topN int, budget time.Duration) []RuleTiming {
+	p.mu.Lock()
+	report := make([]RuleTiming, 0, len(p.timings))
+	for _, t := range p.timings {
+		entry := *t
+		entry.OverBudget = budget > 0 && entry.Duration > budget
+		report = append(report, entry)
+	}
+	p.mu.Unlock()
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Duration != report[j].Duration {
+			return report[i].Duration > report[j].Duration
+		}
+		return report[i].RuleID < report[j].RuleID
+	})
+	if topN > 0 && len(report) > topN {
+		report = report[:topN]
+	}
+	return report
+}
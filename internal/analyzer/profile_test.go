@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// slowProfileRules returns a cheap literal rule and a deliberately expensive
+// rule whose nested, unanchored repetition has to be tried at every offset.
+func slowProfileRules() []rules.Rule {
+	return []rules.Rule{
+		{
+			ID: "fast-literal", Type: rules.RuleTypeRegex,
+			Pattern: regexp.MustCompile(`NEVER_PRESENT_TOKEN`),
+			Level:   "note", Message: "fast", Confidence: 0.5,
+		},
+		{
+			ID: "slow-greedy", Type: rules.RuleTypeRegex,
+			Pattern: regexp.MustCompile(`(?i)(?:[a-z]+\d*[a-z_]*\s*){1,30}=\s*(?:\w+\s*){1,10}\d{7}`),
+			Level:   "note", Message: "slow", Confidence: 0.5,
+		},
+	}
+}
+
+func profileArtifact() input.Artifact {
+	return input.Artifact{
+		Path:    "big.go",
+		Content: strings.Repeat("value = compute(alpha, beta, gamma) + offset\n", 2000),
+		Kind:    input.KindFile,
+	}
+}
+
+func TestRuleProfiler_RanksSlowRuleFirst(t *testing.T) {
+	profiler := NewRuleProfiler()
+	ta := NewTieredAnalyzer(&tieredMockClient{},
+		WithInstantPatterns(slowProfileRules()),
+		WithRuleProfiler(profiler),
+	)
+
+	art := profileArtifact()
+	ta.RunPatternMatching(context.Background(), art)
+	ta.RunPatternMatching(context.Background(), art)
+
+	report := profiler.Report(0, 0)
+	if len(report) != 2 {
+		t.Fatalf("expected 2 profiled rules, got %d", len(report))
+	}
+	if report[0].RuleID != "slow-greedy" {
+		t.Errorf("expected slow-greedy ranked first, got %s (%v) before %s (%v)",
+			report[0].RuleID, report[0].Duration, report[1].RuleID, report[1].Duration)
+	}
+	if report[0].Files != 2 {
+		t.Errorf("expected 2 evaluations recorded, got %d", report[0].Files)
+	}
+	if got := profiler.FileCount(); got != 1 {
+		t.Errorf("expected 1 distinct file, got %d", got)
+	}
+	for _, r := range report {
+		if r.OverBudget {
+			t.Errorf("expected no budget flags with a zero budget, got %s flagged", r.RuleID)
+		}
+	}
+}
+
+func TestRuleProfiler_TopNAndBudget(t *testing.T) {
+	profiler := NewRuleProfiler()
+	profiler.record(rules.Rule{ID: "a"}, "x.go", 5*time.Millisecond, 0)
+	profiler.record(rules.Rule{ID: "b"}, "x.go", 50*time.Millisecond, 3)
+	profiler.record(rules.Rule{ID: "c"}, "y.go", 20*time.Millisecond, 1)
+
+	report := profiler.Report(2, 10*time.Millisecond)
+	if len(report) != 2 {
+		t.Fatalf("expected top 2 rules, got %d", len(report))
+	}
+	if report[0].RuleID != "b" || report[1].RuleID != "c" {
+		t.Errorf("expected order b, c; got %s, %s", report[0].RuleID, report[1].RuleID)
+	}
+	if !report[0].OverBudget || !report[1].OverBudget {
+		t.Errorf("expected both rules over a 10ms budget, got %+v", report)
+	}
+	if full := profiler.Report(0, time.Second); full[2].RuleID != "a" || full[2].OverBudget {
+		t.Errorf("expected a last and within budget, got %+v", full[2])
+	}
+}
+
+func TestRuleProfiler_NotRecordedWithoutOption(t *testing.T) {
+	ta := NewTieredAnalyzer(&tieredMockClient{}, WithInstantPatterns(slowProfileRules()))
+	if ta.ruleProfiler != nil {
+		t.Fatal("expected no profiler by default")
+	}
+}
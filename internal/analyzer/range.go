@@ -0,0 +1,280 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// DefaultRangeContext is the number of lines on either side of a requested
+// range that AnalyzeRange includes in the slice sent to the LLM tiers.
+const DefaultRangeContext = 10
+
+// AnalyzeRange analyzes only lines [start, end] (1-indexed, inclusive) of art.
+// Instant-tier rules run against the full file so multi-line patterns and
+// AST checks see complete syntax; the fast and comprehensive tiers receive a
+// slice of the file padded by contextLines on either side. LLM findings are
+// mapped back to original file line numbers, and only findings whose start
+// line falls inside the range are returned.
+//
+// Like Analyze, a tier error does not discard results from the other tiers;
+// the last error is returned alongside whatever results were collected.
+func (ta *TieredAnalyzer) AnalyzeRange(ctx context.Context, art input.Artifact, start, end, contextLines int, policies map[string]config.Policy, personaPrompt string) ([]sarif.Result, error) {
+	return ta.AnalyzeRanges(ctx, art, []input.LineRange{{Start: start, End: end}}, contextLines, policies, personaPrompt)
+}
+
+// AnalyzeRanges is AnalyzeRange for several ranges of one file, such as
+// the hunks of a diff. The instant tier runs once; ranges whose padded
+// windows overlap share a single LLM request. Only findings starting
+// inside one of ranges are returned.
+func (ta *TieredAnalyzer) AnalyzeRanges(ctx context.Context, art input.Artifact, ranges []input.LineRange, contextLines int, policies map[string]config.Policy, personaPrompt string) ([]sarif.Result, error) {
+	ranges, windows, err := rangeWindows(art, ranges, contextLines)
+	if err != nil {
+		return nil, err
+	}
+	ta.resetParseFailures()
+
+	var allResults []sarif.Result
+	var screened []TieredResult
+	if ta.instantEnabled {
+		instant := ta.runPatternMatching(ctx, art)
+		allResults = append(allResults, instant...)
+		tr := TieredResult{Tier: TierInstant, FilePath: art.Path, Results: FilterByLineRanges(instant, ranges)}
+		screened = append(screened, tr)
+		if ta.onResult != nil {
+			ta.onResult(tr)
+		}
+	}
+
+	idx, _ := astcheck.BuildIndex(art.Path, []byte(art.Content))
+
+	var lastError error
+	for _, w := range windows {
+		// The LLM saw the window starting at line 1; shift back to real file
+		// line numbers and re-resolve logical locations against the full file.
+		offset := w.start - 1
+		for tr := range ta.analyzeProgressive(ctx, []input.Artifact{w.art}, policies, personaPrompt, false, screened) {
+			if tr.Error != nil && !ta.recordRequestTimeout(tr) {
+				if ta.onResult != nil {
+					ta.onResult(tr)
+				}
+				lastError = tr.Error
+				continue
+			}
+			shifted := make([]sarif.Result, 0, len(tr.Results))
+			for _, r := range tr.Results {
+				r = shiftResultLines(r, offset)
+				for i := range r.Locations {
+					r.Locations[i].LogicalLocations = nil
+					if ll := astcheck.LogicalLocationFromIndex(idx, r.Locations[i].PhysicalLocation.Region.StartLine); ll != nil {
+						r.Locations[i].LogicalLocations = []sarif.LogicalLocation{*ll}
+					}
+				}
+				shifted = append(shifted, r)
+			}
+			allResults = append(allResults, shifted...)
+			if ta.onResult != nil {
+				tr.Results = FilterByLineRanges(shifted, ranges)
+				ta.onResult(tr)
+			}
+		}
+	}
+
+	return FilterByLineRanges(ta.deduplicateResults(allResults), ranges), lastError
+}
+
+// PlanRanges is PlanComprehensive for AnalyzeRanges: the calls made for
+// each window of the file sent to the LLM tiers, with StartLine and EndLine
+// set to the window.
+func (ta *TieredAnalyzer) PlanRanges(ctx context.Context, art input.Artifact, ranges []input.LineRange, contextLines int, policies map[string]config.Policy, personaPrompt string) ([]PlannedCall, error) {
+	_, windows, err := rangeWindows(art, ranges, contextLines)
+	if err != nil {
+		return nil, err
+	}
+	var calls []PlannedCall
+	for _, w := range windows {
+		for _, c := range ta.PlanComprehensive(ctx, w.art, policies, personaPrompt) {
+			c.StartLine, c.EndLine = w.start, w.end
+			calls = append(calls, c)
+		}
+	}
+	return calls, nil
+}
+
+// rangeWindow is a slice of a file sent to the LLM tiers in place of the
+// whole file.
+type rangeWindow struct {
+	art        input.Artifact
+	start, end int // 1-indexed lines of the file the window covers
+}
+
+// rangeWindows validates ranges, clamping their ends to the file, and
+// returns them with the windows AnalyzeRanges sends to the LLM tiers.
+func rangeWindows(art input.Artifact, ranges []input.LineRange, contextLines int) ([]input.LineRange, []rangeWindow, error) {
+	lines := strings.Split(art.Content, "\n")
+	clamped := make([]input.LineRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Start <= 0 || r.End < r.Start {
+			return nil, nil, fmt.Errorf("invalid line range [%d, %d]", r.Start, r.End)
+		}
+		if r.Start > len(lines) {
+			return nil, nil, fmt.Errorf("line range starts at %d but %s has %d lines", r.Start, art.Path, len(lines))
+		}
+		if r.End > len(lines) {
+			r.End = len(lines)
+		}
+		clamped = append(clamped, r)
+	}
+
+	var windows []rangeWindow
+	for _, w := range mergeWindows(clamped, contextLines, len(lines)) {
+		windowed, scopeStart := windowLines(lines, w.Start, w.End, 0)
+		windows = append(windows, rangeWindow{
+			art:   input.Artifact{Path: art.Path, Content: windowed, Kind: art.Kind},
+			start: scopeStart,
+			end:   w.End,
+		})
+	}
+	return clamped, windows, nil
+}
+
+// mergeWindows pads each range by contextLines, clamped to [1, total], and
+// merges windows that overlap or touch so no line is sent twice.
+func mergeWindows(ranges []input.LineRange, contextLines, total int) []input.LineRange {
+	windows := make([]input.LineRange, 0, len(ranges))
+	for _, r := range ranges {
+		windows = append(windows, input.LineRange{Start: max(r.Start-contextLines, 1), End: min(r.End+contextLines, total)})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start < windows[j].Start })
+
+	var merged []input.LineRange
+	for _, w := range windows {
+		if n := len(merged); n > 0 && w.Start <= merged[n-1].End+1 {
+			merged[n-1].End = max(merged[n-1].End, w.End)
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
+
+// FilterByLineRange keeps only results whose first location's StartLine
+// falls within [start, end] inclusive. Results without a location are
+// dropped.
+func FilterByLineRange(results []sarif.Result, start, end int) []sarif.Result {
+	var out []sarif.Result
+	for _, r := range results {
+		if len(r.Locations) == 0 {
+			continue
+		}
+		line := r.Locations[0].PhysicalLocation.Region.StartLine
+		if line >= start && line <= end {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// FilterByLineRanges keeps only results whose first location's StartLine
+// falls within any of ranges.
+func FilterByLineRanges(results []sarif.Result, ranges []input.LineRange) []sarif.Result {
+	var out []sarif.Result
+	for _, r := range results {
+		if len(r.Locations) == 0 {
+			continue
+		}
+		line := r.Locations[0].PhysicalLocation.Region.StartLine
+		for _, lr := range ranges {
+			if line >= lr.Start && line <= lr.End {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// windowLines joins lines [start-window, end+window] (clamped to the file
+// bounds) and returns the 1-indexed line where the window begins.
+func windowLines(lines []string, start, end, window int) (string, int) {
+	scopeStart := start - window
+	if scopeStart < 1 {
+		scopeStart = 1
+	}
+	scopeEnd := end + window
+	if scopeEnd > len(lines) {
+		scopeEnd = len(lines)
+	}
+	return strings.Join(lines[scopeStart-1:scopeEnd], "\n"), scopeStart
+}
+
+// shiftResultLines returns a copy of r with every region that refers to the
+// result's own file moved down by offset lines. Related locations in other
+// files are left untouched since the LLM only saw the windowed file.
+func shiftResultLines(r sarif.Result, offset int) sarif.Result {
+	if offset == 0 || len(r.Locations) == 0 {
+		return r
+	}
+	uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+
+	locs := make([]sarif.Location, len(r.Locations))
+	copy(locs, r.Locations)
+	for i := range locs {
+		locs[i].PhysicalLocation.Region = shiftRegion(locs[i].PhysicalLocation.Region, offset)
+		if cr := locs[i].PhysicalLocation.ContextRegion; cr != nil {
+			shifted := shiftRegion(*cr, offset)
+			locs[i].PhysicalLocation.ContextRegion = &shifted
+		}
+	}
+	r.Locations = locs
+
+	if len(r.RelatedLocations) > 0 {
+		related := make([]sarif.Location, len(r.RelatedLocations))
+		copy(related, r.RelatedLocations)
+		for i := range related {
+			if related[i].PhysicalLocation.ArtifactLocation.URI == uri {
+				related[i].PhysicalLocation.Region = shiftRegion(related[i].PhysicalLocation.Region, offset)
+			}
+		}
+		r.RelatedLocations = related
+	}
+
+	if len(r.Fixes) > 0 {
+		fixes := make([]sarif.Fix, len(r.Fixes))
+		for i, fix := range r.Fixes {
+			changes := make([]sarif.ArtifactChange, len(fix.ArtifactChanges))
+			for j, ch := range fix.ArtifactChanges {
+				reps := make([]sarif.Replacement, len(ch.Replacements))
+				copy(reps, ch.Replacements)
+				if ch.ArtifactLocation.URI == uri {
+					for k := range reps {
+						reps[k].DeletedRegion = shiftRegion(reps[k].DeletedRegion, offset)
+					}
+				}
+				ch.Replacements = reps
+				changes[j] = ch
+			}
+			fix.ArtifactChanges = changes
+			fixes[i] = fix
+		}
+		r.Fixes = fixes
+	}
+	r.Properties = mapSnapRegions(r.Properties, func(reg sarif.Region) sarif.Region { return shiftRegion(reg, offset) })
+	return r
+}
+
+func shiftRegion(reg sarif.Region, offset int) sarif.Region {
+	if reg.StartLine > 0 {
+		reg.StartLine += offset
+	}
+	if reg.EndLine > 0 {
+		reg.EndLine += offset
+	}
+	return reg
+}
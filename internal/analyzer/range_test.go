@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// rangeFixture builds a 40-line Go file with hard-coded credentials on
+// lines 5 and 25 so instant-tier findings land both inside and outside a
+// 20:30 range.
+func rangeFixture() string {
+	lines := make([]string, 40)
+	lines[0] = "package main"
+	for i := 1; i < len(lines); i++ {
+		lines[i] = fmt.Sprintf("// line %d", i+1)
+	}
+	lines[4] = `var password = "outside-range-secret"`
+	lines[24] = `var password = "inside-range-secret"`
+	return strings.Join(lines, "\n")
+}
+
+func TestTieredAnalyzer_AnalyzeRange(t *testing.T) {
+	// Window for 20:30 with 2 lines of context starts at line 18, so
+	// window line 8 is file line 25 and window line 1 is file line 18.
+	mock := &mockBAMLClient{findings: []Finding{
+		{RuleID: "llm-in-range", Level: "warning", Message: "in range", StartLine: 8, EndLine: 8, Confidence: 0.9},
+		{RuleID: "llm-context", Level: "warning", Message: "in padding", StartLine: 1, EndLine: 1, Confidence: 0.9},
+	}}
+	ta := NewTieredAnalyzer(mock)
+	art := input.Artifact{Path: "creds.go", Content: rangeFixture(), Kind: input.KindFile}
+	policies := map[string]config.Policy{"p": {Instruction: "Check", Enabled: true}}
+
+	results, err := ta.AnalyzeRange(context.Background(), art, 20, 30, 2, policies, "persona")
+	if err != nil {
+		t.Fatalf("AnalyzeRange: %v", err)
+	}
+
+	lineOf := func(r sarif.Result) int { return r.Locations[0].PhysicalLocation.Region.StartLine }
+	got := make(map[string][]int)
+	for _, r := range results {
+		got[r.RuleID] = append(got[r.RuleID], lineOf(r))
+		if line := lineOf(r); line < 20 || line > 30 {
+			t.Errorf("result %s at line %d is outside the requested range", r.RuleID, line)
+		}
+	}
+
+	if lines := got["S2068"]; len(lines) != 1 || lines[0] != 25 {
+		t.Errorf("expected S2068 only at line 25, got %v", lines)
+	}
+	if lines := got["llm-in-range"]; len(lines) != 1 || lines[0] != 25 {
+		t.Errorf("expected LLM finding mapped to original line 25, got %v", lines)
+	}
+	if _, ok := got["llm-context"]; ok {
+		t.Error("expected LLM finding in the context padding to be filtered out")
+	}
+
+	if strings.Contains(mock.lastCode, "outside-range-secret") {
+		t.Error("expected LLM input to exclude lines far outside the range")
+	}
+	if !strings.Contains(mock.lastCode, "inside-range-secret") {
+		t.Error("expected LLM input to include the requested range")
+	}
+}
+
+func TestTieredAnalyzer_AnalyzeRange_ResultHandler(t *testing.T) {
+	mock := &mockBAMLClient{findings: []Finding{
+		{RuleID: "llm-in-range", Level: "warning", Message: "in range", StartLine: 8, EndLine: 8, Confidence: 0.9},
+		{RuleID: "llm-context", Level: "warning", Message: "in padding", StartLine: 1, EndLine: 1, Confidence: 0.9},
+	}}
+	var streamed []TieredResult
+	ta := NewTieredAnalyzer(mock, WithResultHandler(func(tr TieredResult) { streamed = append(streamed, tr) }))
+	art := input.Artifact{Path: "creds.go", Content: rangeFixture(), Kind: input.KindFile}
+	policies := map[string]config.Policy{"p": {Instruction: "Check", Enabled: true}}
+
+	if _, err := ta.AnalyzeRange(context.Background(), art, 20, 30, 2, policies, "persona"); err != nil {
+		t.Fatalf("AnalyzeRange: %v", err)
+	}
+
+	if len(streamed) != 2 || streamed[0].Tier != TierInstant || streamed[1].Tier != TierComprehensive {
+		t.Fatalf("expected instant then comprehensive results, got %+v", streamed)
+	}
+	for _, tr := range streamed {
+		if len(tr.Results) != 1 || tr.Results[0].Locations[0].PhysicalLocation.Region.StartLine != 25 {
+			t.Errorf("expected one %s result at line 25, got %+v", tr.Tier, tr.Results)
+		}
+	}
+}
+
+func TestTieredAnalyzer_AnalyzeRange_ShiftsContextRegion(t *testing.T) {
+	mock := &mockBAMLClient{findings: []Finding{
+		{RuleID: "llm", Level: "note", Message: "m", StartLine: 3, EndLine: 4, Confidence: 0.9},
+	}}
+	ta := NewTieredAnalyzer(mock, WithInstantEnabled(false))
+	art := input.Artifact{Path: "creds.go", Content: rangeFixture(), Kind: input.KindFile}
+	policies := map[string]config.Policy{"p": {Instruction: "Check", Enabled: true}}
+
+	results, err := ta.AnalyzeRange(context.Background(), art, 20, 30, 0, policies, "persona")
+	if err != nil {
+		t.Fatalf("AnalyzeRange: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	pl := results[0].Locations[0].PhysicalLocation
+	if pl.Region.StartLine != 22 || pl.Region.EndLine != 23 {
+		t.Errorf("expected region 22-23, got %d-%d", pl.Region.StartLine, pl.Region.EndLine)
+	}
+	if pl.ContextRegion == nil || pl.ContextRegion.StartLine < 20 {
+		t.Errorf("expected context region shifted into the file's coordinates, got %+v", pl.ContextRegion)
+	}
+}
+
+func TestTieredAnalyzer_AnalyzeRange_InvalidRange(t *testing.T) {
+	ta := NewTieredAnalyzer(&mockBAMLClient{})
+	art := input.Artifact{Path: "a.go", Content: "package a\n"}
+	policies := map[string]config.Policy{"p": {Instruction: "Check", Enabled: true}}
+
+	for _, tc := range []struct{ start, end int }{{0, 5}, {5, 4}, {50, 60}} {
+		if _, err := ta.AnalyzeRange(context.Background(), art, tc.start, tc.end, 0, policies, "p"); err == nil {
+			t.Errorf("expected error for range %d:%d", tc.start, tc.end)
+		}
+	}
+}
+
+func TestTieredAnalyzer_AnalyzeRanges(t *testing.T) {
+	// Each range gets its own window ([2,4] and [29,31]); window line 2 is
+	// the changed line in both.
+	mock := &mockBAMLClient{findings: []Finding{
+		{RuleID: "llm", Level: "warning", Message: "changed", StartLine: 2, EndLine: 2, Confidence: 0.9},
+	}}
+	ta := NewTieredAnalyzer(mock)
+	art := input.Artifact{Path: "creds.go", Content: rangeFixture(), Kind: input.KindFile}
+	policies := map[string]config.Policy{"p": {Instruction: "Check", Enabled: true}}
+
+	ranges := []input.LineRange{{Start: 3, End: 3}, {Start: 30, End: 30}}
+	results, err := ta.AnalyzeRanges(context.Background(), art, ranges, 1, policies, "persona")
+	if err != nil {
+		t.Fatalf("AnalyzeRanges: %v", err)
+	}
+
+	var lines []int
+	for _, r := range results {
+		if r.RuleID != "llm" {
+			t.Errorf("unexpected result %s at line %d outside the ranges", r.RuleID, r.Locations[0].PhysicalLocation.Region.StartLine)
+			continue
+		}
+		lines = append(lines, r.Locations[0].PhysicalLocation.Region.StartLine)
+	}
+	sort.Ints(lines)
+	if len(lines) != 2 || lines[0] != 3 || lines[1] != 30 {
+		t.Errorf("expected LLM findings mapped to lines 3 and 30, got %v", lines)
+	}
+}
+
+func TestMergeWindows(t *testing.T) {
+	ranges := []input.LineRange{{Start: 20, End: 20}, {Start: 1, End: 2}, {Start: 5, End: 6}, {Start: 39, End: 40}}
+	got := mergeWindows(ranges, 1, 40)
+	want := []input.LineRange{{Start: 1, End: 7}, {Start: 19, End: 21}, {Start: 38, End: 40}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("mergeWindows = %v, want %v", got, want)
+	}
+}
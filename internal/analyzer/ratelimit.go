@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// Ensure RateLimitedClient satisfies the BAMLClient interface at compile time.
+var _ BAMLClient = (*RateLimitedClient)(nil)
+
+// RateLimitedClient wraps a BAMLClient, pacing calls to stay within the
+// provider's request and token budgets and retrying calls that fail with a
+// rate limit (429) or server error (5xx) using exponential backoff with
+// jitter. It is safe for concurrent use; all callers share the budgets.
+type RateLimitedClient struct {
+	client         BAMLClient
+	requests       *tokenBucket
+	tokens         *tokenBucket
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	// sleep waits for d or until ctx is done; tests replace it.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRateLimitedClient wraps client with the limits and retry policy in cfg.
+func NewRateLimitedClient(client BAMLClient, cfg config.RateLimitConfig) *RateLimitedClient {
+	c := &RateLimitedClient{
+		client:         client,
+		maxRetries:     cfg.MaxRetries,
+		initialBackoff: cfg.InitialBackoffDuration(),
+		maxBackoff:     cfg.MaxBackoffDuration(),
+		sleep:          sleepContext,
+	}
+	if cfg.RequestsPerMinute > 0 {
+		c.requests = newTokenBucket(cfg.RequestsPerMinute, time.Now)
+	}
+	if cfg.TokensPerMinute > 0 {
+		c.tokens = newTokenBucket(cfg.TokensPerMinute, time.Now)
+	}
+	return c
+}
+
+// AnalyzeCode waits for room in the request and token budgets, then calls
+// the wrapped client, retrying retryable failures up to the configured
+// number of times. Every attempt, including retries, counts against the
+// budgets.
+func (c *RateLimitedClient) AnalyzeCode(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]Finding, error) {
+	tokens := estimateTokens(code, policies, personaPrompt, additionalContext)
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx, tokens); err != nil {
+			return nil, err
+		}
+		findings, err := c.client.AnalyzeCode(ctx, code, policies, personaPrompt, additionalContext)
+		if err == nil || attempt >= c.maxRetries || !isRetryable(err) || ctx.Err() != nil {
+			return findings, err
+		}
+		delay := c.backoff(attempt)
+		slog.Warn("provider call failed; retrying", "attempt", attempt+1, "max_retries", c.maxRetries, "delay", delay, "err", err)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait blocks until the request and token budgets admit a call of the
+// given estimated size.
+func (c *RateLimitedClient) wait(ctx context.Context, tokens int) error {
+	for _, b := range []struct {
+		bucket *tokenBucket
+		n      int
+	}{{c.requests, 1}, {c.tokens, tokens}} {
+		if b.bucket == nil {
+			continue
+		}
+		if d := b.bucket.reserve(b.n); d > 0 {
+			if err := c.sleep(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backoff returns the delay before retry attempt+1: the initial backoff
+// doubled per attempt, capped at the maximum, with the upper half jittered
+// so concurrent callers do not retry in lockstep.
+func (c *RateLimitedClient) backoff(attempt int) time.Duration {
+	d := c.initialBackoff
+	for i := 0; i < attempt && d < c.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// retryablePattern matches provider errors worth retrying: HTTP 429 and
+// 5xx status codes, and the phrases providers use for them.
+var retryablePattern = regexp.MustCompile(`(?i)(status(\s+code)?|code|http)\D{0,3}(429|5\d\d)\b|rate.?limit|too many requests|overloaded|service unavailable|bad gateway|gateway timeout|internal server error`)
+
+// isRetryable reports whether err is a transient provider failure. The
+// BAML runtime surfaces HTTP failures as plain messages, so the decision
+// is made on the error text. Context cancellation is never retried.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return retryablePattern.MatchString(err.Error())
+}
+
+// estimateTokens approximates the prompt size of a call at four bytes per
+// token, which is close enough for budgeting across common tokenizers.
+func estimateTokens(parts ...string) int {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	return n/4 + 1
+}
+
+// tokenBucket is a token bucket that refills perMinute tokens per minute up
+// to a capacity of perMinute.
+type tokenBucket struct {
+	mu        sync.Mutex
+	capacity  float64
+	available float64
+	rate      float64 // tokens per second
+	last      time.Time
+	now       func() time.Time
+}
+
+func newTokenBucket(perMinute int, now func() time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		rate:      float64(perMinute) / 60,
+		last:      now(),
+		now:       now,
+	}
+}
+
+// reserve takes n tokens and returns how long the caller must wait before
+// they are available. Tokens are reserved immediately, so concurrent
+// callers queue behind one another. A request larger than the capacity is
+// clamped to it so it can still proceed once the bucket is full.
+func (b *tokenBucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.available += now.Sub(b.last).Seconds() * b.rate
+	if b.available > b.capacity {
+		b.available = b.capacity
+	}
+	b.last = now
+
+	need := float64(n)
+	if need > b.capacity {
+		need = b.capacity
+	}
+	b.available -= need
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.rate * float64(time.Second))
+}
+
+// sleepContext waits for d, returning early with the context's error if it
+// is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
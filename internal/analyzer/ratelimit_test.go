@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// flakyClient fails with errs in order, then returns findings.
+type flakyClient struct {
+	errs     []error
+	findings []Finding
+	calls    int
+}
+
+func (f *flakyClient) AnalyzeCode(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]Finding, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return f.findings, nil
+}
+
+// recordSleeps replaces c's sleep with one that records the requested
+// delays without waiting.
+func recordSleeps(c *RateLimitedClient) *[]time.Duration {
+	var sleeps []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+	return &sleeps
+}
+
+func TestRateLimitedClient_RetriesRetryableErrors(t *testing.T) {
+	inner := &flakyClient{
+		errs: []error{
+			errors.New("LLM call failed: status code: 429, Too Many Requests"),
+			errors.New("request failed with status 503"),
+		},
+		findings: []Finding{{RuleID: "x"}},
+	}
+	c := NewRateLimitedClient(inner, config.RateLimitConfig{MaxRetries: 3, InitialBackoff: "100ms", MaxBackoff: "150ms"})
+	sleeps := recordSleeps(c)
+
+	findings, err := c.AnalyzeCode(context.Background(), "code", "", "", "")
+	if err != nil {
+		t.Fatalf("AnalyzeCode: %v", err)
+	}
+	if len(findings) != 1 || inner.calls != 3 {
+		t.Fatalf("expected success on the third call, got %d findings after %d calls", len(findings), inner.calls)
+	}
+	if len(*sleeps) != 2 {
+		t.Fatalf("expected 2 backoff sleeps, got %v", *sleeps)
+	}
+	if d := (*sleeps)[0]; d < 50*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("first backoff %v outside jittered range [50ms, 100ms]", d)
+	}
+	if d := (*sleeps)[1]; d < 75*time.Millisecond || d > 150*time.Millisecond {
+		t.Errorf("second backoff %v outside capped range [75ms, 150ms]", d)
+	}
+}
+
+func TestRateLimitedClient_GivesUp(t *testing.T) {
+	overloaded := errors.New("anthropic: overloaded_error")
+	inner := &flakyClient{errs: []error{overloaded, overloaded, overloaded}}
+	c := NewRateLimitedClient(inner, config.RateLimitConfig{MaxRetries: 2})
+	recordSleeps(c)
+
+	if _, err := c.AnalyzeCode(context.Background(), "code", "", "", ""); !errors.Is(err, overloaded) {
+		t.Fatalf("expected the last provider error, got %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected 1 call plus 2 retries, got %d calls", inner.calls)
+	}
+}
+
+func TestRateLimitedClient_DoesNotRetryOtherErrors(t *testing.T) {
+	inner := &flakyClient{errs: []error{errors.New("status code: 401, invalid api key")}}
+	c := NewRateLimitedClient(inner, config.RateLimitConfig{MaxRetries: 5})
+	sleeps := recordSleeps(c)
+
+	if _, err := c.AnalyzeCode(context.Background(), "code", "", "", ""); err == nil {
+		t.Fatal("expected error")
+	}
+	if inner.calls != 1 || len(*sleeps) != 0 {
+		t.Errorf("expected a single call without backoff, got %d calls and sleeps %v", inner.calls, *sleeps)
+	}
+}
+
+func TestRateLimitedClient_StopsWhenContextDone(t *testing.T) {
+	inner := &flakyClient{errs: []error{errors.New("429 rate limit exceeded")}}
+	c := NewRateLimitedClient(inner, config.RateLimitConfig{MaxRetries: 3})
+	ctx, cancel := context.WithCancel(context.Background())
+	c.sleep = func(context.Context, time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	if _, err := c.AnalyzeCode(ctx, "code", "", "", ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected no retry after cancellation, got %d calls", inner.calls)
+	}
+}
+
+func TestRateLimitedClient_RequestsPerMinute(t *testing.T) {
+	inner := &flakyClient{}
+	c := NewRateLimitedClient(inner, config.RateLimitConfig{RequestsPerMinute: 2})
+	now := time.Unix(0, 0)
+	c.requests = newTokenBucket(2, func() time.Time { return now })
+	sleeps := recordSleeps(c)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.AnalyzeCode(context.Background(), "code", "", "", ""); err != nil {
+			t.Fatalf("AnalyzeCode: %v", err)
+		}
+	}
+	// Two requests fit the burst; the third waits for one request's worth
+	// of refill at 2 per minute.
+	if len(*sleeps) != 1 || (*sleeps)[0] != 30*time.Second {
+		t.Fatalf("expected a single 30s wait, got %v", *sleeps)
+	}
+
+	now = now.Add(time.Minute)
+	*sleeps = nil
+	if _, err := c.AnalyzeCode(context.Background(), "code", "", "", ""); err != nil {
+		t.Fatalf("AnalyzeCode: %v", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("expected the bucket to have refilled, got waits %v", *sleeps)
+	}
+}
+
+func TestRateLimitedClient_TokensPerMinute(t *testing.T) {
+	inner := &flakyClient{}
+	c := NewRateLimitedClient(inner, config.RateLimitConfig{TokensPerMinute: 600})
+	now := time.Unix(0, 0)
+	c.tokens = newTokenBucket(600, func() time.Time { return now })
+	sleeps := recordSleeps(c)
+
+	code := string(make([]byte, 1596)) // estimated at 400 tokens
+	for i := 0; i < 2; i++ {
+		if _, err := c.AnalyzeCode(context.Background(), code, "", "", ""); err != nil {
+			t.Fatalf("AnalyzeCode: %v", err)
+		}
+	}
+	// 800 tokens against a 600 budget leaves a 200 token deficit, refilled
+	// at 10 tokens per second.
+	if len(*sleeps) != 1 || (*sleeps)[0] != 20*time.Second {
+		t.Fatalf("expected a single 20s wait, got %v", *sleeps)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  string
+		want bool
+	}{
+		{"status code: 429", true},
+		{"HTTP 502 Bad Gateway", true},
+		{"Request failed: Rate limit reached for requests", true},
+		{"overloaded_error: Overloaded", true},
+		{"status code: 400, invalid request", false},
+		{"failed to parse response at line 500", false},
+		{"context deadline exceeded", false},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(errors.New(tt.err)); got != tt.want {
+			t.Errorf("isRetryable(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+	if isRetryable(context.DeadlineExceeded) {
+		t.Error("context.DeadlineExceeded must not be retried")
+	}
+}
+
+func TestNewProviderClient_WrapsOnlyWhenConfigured(t *testing.T) {
+	if _, ok := NewProviderClient(config.ProviderConfig{Name: "ollama"}).(*BAMLLiveClient); !ok {
+		t.Error("expected the live client when rate_limit is unset")
+	}
+	cfg := config.ProviderConfig{Name: "ollama", RateLimit: config.RateLimitConfig{MaxRetries: 2}}
+	if _, ok := NewProviderClient(cfg).(*RateLimitedClient); !ok {
+		t.Error("expected a RateLimitedClient when rate_limit is set")
+	}
+}
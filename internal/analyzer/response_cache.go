@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// CachingClient wraps a BAMLClient to answer repeated prompts from a cache
+// of raw LLM responses, keyed by a hash of everything the prompt holds. It
+// sits below the analyzers' result caches, so identical calls made through
+// different paths (analyze, watch, the language server, MCP) share one
+// completion. Only successful responses are cached.
+type CachingClient struct {
+	client    BAMLClient
+	cache     cache.AnalysisCache
+	namespace string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingClient wraps client, which calls the model provider selects,
+// with c. Responses are kept apart per provider, model and endpoint, so a
+// cache shared by several clients never answers one model's prompt with
+// another's response. A cache.DiskCache persists responses between runs.
+func NewCachingClient(client BAMLClient, c cache.AnalysisCache, provider config.ProviderConfig) *CachingClient {
+	return &CachingClient{client: client, cache: c, namespace: responseNamespace(provider)}
+}
+
+// responseNamespace identifies the model a provider config calls.
+func responseNamespace(p config.ProviderConfig) string {
+	var endpoint string
+	switch p.Name {
+	case "ollama":
+		endpoint = p.Ollama.BaseURL
+	case "bedrock":
+		endpoint = p.Bedrock.Region
+	case "llamacpp":
+		endpoint = p.LlamaCpp.BaseURL
+	}
+	return cache.GenerateKey(p.Name, p.ModelName(), endpoint)
+}
+
+// AnalyzeCode returns the cached findings for an identical earlier prompt,
+// or calls the wrapped client and caches its findings.
+func (c *CachingClient) AnalyzeCode(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]Finding, error) {
+	key := cache.ResponseKey(c.namespace, code, policies, personaPrompt, additionalContext)
+	if cached, ok := c.cache.Get(key); ok {
+		if raw, ok := cached.(json.RawMessage); ok {
+			var findings []Finding
+			if json.Unmarshal(raw, &findings) == nil {
+				c.hits.Add(1)
+				return findings, nil
+			}
+		}
+	}
+	c.misses.Add(1)
+
+	findings, err := c.client.AnalyzeCode(ctx, code, policies, personaPrompt, additionalContext)
+	if err != nil {
+		return nil, err
+	}
+	// Stored as JSON, so later hits never share slices with this caller
+	if data, err := json.Marshal(findings); err == nil {
+		c.cache.Set(key, json.RawMessage(data))
+	}
+	return findings, nil
+}
+
+// Stats returns the client's hits and misses, and the size of its cache.
+func (c *CachingClient) Stats() cache.CacheStats {
+	stats := c.cache.Stats()
+	stats.Hits, stats.Misses = c.hits.Load(), c.misses.Load()
+	stats.HitRate = 0
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+type failingClient struct{ calls int }
+
+func (c *failingClient) AnalyzeCode(ctx context.Context, code, policies, personaPrompt, additionalContext string) ([]Finding, error) {
+	c.calls++
+	return nil, errors.New("provider down")
+}
+
+func TestCachingClient_SharesResponsesAcrossClients(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := cache.NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := config.ProviderConfig{Name: "ollama", Ollama: config.OllamaConfig{Model: "m"}}
+	mock := &countingMockClient{findings: []Finding{{RuleID: "r", Level: "warning", StartLine: 3, EndLine: 4}}}
+	ctx := context.Background()
+
+	first := NewCachingClient(mock, disk, provider)
+	if _, err := first.AnalyzeCode(ctx, "code", "policies", "persona", ""); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := first.AnalyzeCode(ctx, "code", "policies", "persona", "")
+	if err != nil || len(findings) != 1 || findings[0].StartLine != 3 {
+		t.Fatalf("expected the cached finding, got %+v, %v", findings, err)
+	}
+	if mock.callCount.Load() != 1 {
+		t.Errorf("expected one provider call, got %d", mock.callCount.Load())
+	}
+
+	// Another process opening the same directory reuses the response, but
+	// any change to the prompt or the model misses
+	reopened, err := cache.NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := NewCachingClient(mock, reopened, provider)
+	second.AnalyzeCode(ctx, "code", "policies", "persona", "")
+	if mock.callCount.Load() != 1 {
+		t.Errorf("expected the persisted response to be reused, got %d calls", mock.callCount.Load())
+	}
+	second.AnalyzeCode(ctx, "code", "policies", "persona", "diff context")
+	other := provider
+	other.Ollama.Model = "bigger"
+	NewCachingClient(mock, reopened, other).AnalyzeCode(ctx, "code", "policies", "persona", "")
+	if mock.callCount.Load() != 3 {
+		t.Errorf("expected a changed prompt and model to call the provider, got %d calls", mock.callCount.Load())
+	}
+	if stats := second.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCachingClient_DoesNotCacheErrors(t *testing.T) {
+	failing := &failingClient{}
+	c := NewCachingClient(failing, cache.New(), config.ProviderConfig{Name: "ollama"})
+	for i := 0; i < 2; i++ {
+		if _, err := c.AnalyzeCode(context.Background(), "code", "p", "persona", ""); err == nil {
+			t.Fatal("expected the provider error")
+		}
+	}
+	if failing.calls != 2 {
+		t.Errorf("expected every failed call to reach the provider, got %d", failing.calls)
+	}
+}
@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// ModelRoute is where a policy's comprehensive-tier analysis is sent: the
+// provider and model, recorded on findings for attribution, and the client
+// that calls them. A nil Client means the TieredAnalyzer's comprehensive
+// client.
+type ModelRoute struct {
+	Provider string
+	Model    string
+	Client   BAMLClient
+}
+
+func (r ModelRoute) key() string {
+	return r.Provider + "/" + r.Model
+}
+
+// PolicyRoutes returns the route for every policy in cfg when at least one
+// enabled policy overrides the provider or model, and nil otherwise.
+// Policies on the top-level provider and model get a route with a nil
+// Client so they share the comprehensive client; each other provider and
+// model pair gets one client from newClient.
+func PolicyRoutes(cfg *config.Config, newClient func(config.ProviderConfig) BAMLClient) map[string]ModelRoute {
+	defaultRoute := ModelRoute{Provider: cfg.Provider.Name, Model: cfg.Provider.ModelName()}
+
+	routed := false
+	routes := make(map[string]ModelRoute, len(cfg.Policies))
+	clients := make(map[string]BAMLClient)
+	for name, p := range cfg.Policies {
+		pc := cfg.ProviderFor(p)
+		route := ModelRoute{Provider: pc.Name, Model: pc.ModelName()}
+		if route.key() == defaultRoute.key() {
+			routes[name] = defaultRoute
+			continue
+		}
+		if p.Enabled {
+			routed = true
+		}
+		if clients[route.key()] == nil {
+			clients[route.key()] = newClient(pc)
+		}
+		route.Client = clients[route.key()]
+		routes[name] = route
+	}
+	if !routed {
+		return nil
+	}
+	return routes
+}
+
+// WithPolicyRoutes sends each policy's comprehensive-tier analysis to its
+// route (see PolicyRoutes). Policies sharing a route are analyzed in one
+// call per file and the results merged; each finding is tagged with the
+// gavel/provider and gavel/model that produced it and the gavel/policies in
+// that call. Policies without a route use the comprehensive client. A nil
+// map leaves the tier unchanged.
+func WithPolicyRoutes(routes map[string]ModelRoute) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.policyRoutes = routes
+	}
+}
+
+// policyBatch is a set of policies analyzed together in one
+// comprehensive-tier call.
+type policyBatch struct {
+	route    ModelRoute
+	routed   bool
+	names    []string
+	policies map[string]config.Policy
+}
+
+// comprehensiveBatches groups the enabled policies by route, ordered by
+// provider and model. Without routes every policy goes in a single
+// unrouted batch for the comprehensive client.
+func (ta *TieredAnalyzer) comprehensiveBatches(policies map[string]config.Policy) []policyBatch {
+	if len(ta.policyRoutes) == 0 {
+		return []policyBatch{{policies: policies}}
+	}
+
+	byKey := make(map[string]*policyBatch)
+	for name, p := range policies {
+		if !p.Enabled {
+			continue
+		}
+		route := ta.policyRoutes[name]
+		b, ok := byKey[route.key()]
+		if !ok {
+			b = &policyBatch{route: route, routed: true, policies: make(map[string]config.Policy)}
+			byKey[route.key()] = b
+		}
+		b.names = append(b.names, name)
+		b.policies[name] = p
+	}
+
+	batches := make([]policyBatch, 0, len(byKey))
+	for _, b := range byKey {
+		sort.Strings(b.names)
+		batches = append(batches, *b)
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].route.key() < batches[j].route.key() })
+	return batches
+}
+
+// routeSignature describes which policies go to which route, so cached
+// comprehensive-tier results are not reused after the routing changes.
+func routeSignature(batches []policyBatch) string {
+	var sb strings.Builder
+	for _, b := range batches {
+		if !b.routed {
+			continue
+		}
+		sb.WriteString("\n@" + b.route.key() + ": " + strings.Join(b.names, ","))
+	}
+	return sb.String()
+}
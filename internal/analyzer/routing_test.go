@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+)
+
+// policyRecordingClient returns one finding per call, named after the
+// client, and records the policy text of each call.
+type policyRecordingClient struct {
+	name     string
+	err      error
+	policies []string
+}
+
+func (c *policyRecordingClient) AnalyzeCode(ctx context.Context, code, policies, personaPrompt, additionalContext string) ([]Finding, error) {
+	c.policies = append(c.policies, policies)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []Finding{{RuleID: c.name, Level: "warning", Message: c.name, StartLine: 1, EndLine: 1, Confidence: 0.9}}, nil
+}
+
+func routingConfig() *config.Config {
+	return &config.Config{
+		Provider: config.ProviderConfig{
+			Name:      "ollama",
+			Ollama:    config.OllamaConfig{Model: "qwen"},
+			Anthropic: config.AnthropicConfig{Model: "claude-sonnet"},
+		},
+		Policies: map[string]config.Policy{
+			"style":    {Instruction: "Check style", Enabled: true},
+			"security": {Instruction: "Check security", Enabled: true, Provider: "anthropic"},
+			"secrets":  {Instruction: "Check secrets", Enabled: true, Provider: "anthropic"},
+			"naming":   {Instruction: "Check naming", Enabled: true, Model: "llama"},
+			"off":      {Instruction: "Disabled", Enabled: false, Provider: "openai"},
+		},
+	}
+}
+
+func TestPolicyRoutes(t *testing.T) {
+	var created []config.ProviderConfig
+	newClient := func(pc config.ProviderConfig) BAMLClient {
+		created = append(created, pc)
+		return &policyRecordingClient{name: pc.Name + "/" + pc.ModelName()}
+	}
+
+	routes := PolicyRoutes(routingConfig(), newClient)
+	if got := routes["style"]; got != (ModelRoute{Provider: "ollama", Model: "qwen"}) {
+		t.Errorf("style route = %+v, want the default provider with a nil client", got)
+	}
+	if r := routes["security"]; r.Provider != "anthropic" || r.Model != "claude-sonnet" || r.Client == nil {
+		t.Errorf("security route = %+v", r)
+	}
+	if routes["security"].Client != routes["secrets"].Client {
+		t.Error("policies on the same provider and model should share a client")
+	}
+	if r := routes["naming"]; r.Provider != "ollama" || r.Model != "llama" {
+		t.Errorf("naming route = %+v", r)
+	}
+	if len(created) != 3 {
+		t.Errorf("created %d clients, want 3 (anthropic, ollama/llama, and the disabled openai policy)", len(created))
+	}
+
+	cfg := routingConfig()
+	for name, p := range cfg.Policies {
+		if p.Enabled {
+			p.Provider, p.Model = "", ""
+			cfg.Policies[name] = p
+		}
+	}
+	if routes := PolicyRoutes(cfg, newClient); routes != nil {
+		t.Errorf("expected no routes when only a disabled policy overrides the provider, got %+v", routes)
+	}
+}
+
+func TestTieredAnalyzer_PolicyRoutes(t *testing.T) {
+	cfg := routingConfig()
+	clients := map[string]*policyRecordingClient{}
+	routes := PolicyRoutes(cfg, func(pc config.ProviderConfig) BAMLClient {
+		c := &policyRecordingClient{name: pc.Name + "/" + pc.ModelName()}
+		clients[c.name] = c
+		return c
+	})
+	def := &policyRecordingClient{name: "default"}
+
+	ta := NewTieredAnalyzer(def, WithInstantEnabled(false), WithPolicyRoutes(routes))
+	art := input.Artifact{Path: "a.go", Content: "package a\n", Kind: input.KindFile}
+	results, err := ta.Analyze(context.Background(), []input.Artifact{art}, cfg.Policies, "persona")
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	if len(def.policies) != 1 || !strings.Contains(def.policies[0], "style") || strings.Contains(def.policies[0], "security") {
+		t.Errorf("default client policies = %q, want only style", def.policies)
+	}
+	anthropic := clients["anthropic/claude-sonnet"]
+	if len(anthropic.policies) != 1 || !strings.Contains(anthropic.policies[0], "security") || !strings.Contains(anthropic.policies[0], "secrets") {
+		t.Errorf("anthropic client policies = %q, want security and secrets in one call", anthropic.policies)
+	}
+	if len(clients["openai/"].policies) != 0 {
+		t.Error("disabled policy should not be analyzed")
+	}
+	if got := ta.Stats().ComprehensiveCalls; got != 3 {
+		t.Errorf("comprehensive calls = %d, want 3", got)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected one finding per route, got %d", len(results))
+	}
+	attribution := map[string][]string{}
+	for _, r := range results {
+		key := r.Properties["gavel/provider"].(string) + "/" + r.Properties["gavel/model"].(string)
+		attribution[key] = r.Properties["gavel/policies"].([]string)
+	}
+	want := map[string][]string{
+		"ollama/qwen":             {"style"},
+		"anthropic/claude-sonnet": {"secrets", "security"},
+		"ollama/llama":            {"naming"},
+	}
+	if !reflect.DeepEqual(attribution, want) {
+		t.Errorf("attribution = %v, want %v", attribution, want)
+	}
+}
+
+func TestTieredAnalyzer_PolicyRoutes_PartialFailure(t *testing.T) {
+	cfg := routingConfig()
+	routes := PolicyRoutes(cfg, func(pc config.ProviderConfig) BAMLClient {
+		c := &policyRecordingClient{name: pc.Name}
+		if pc.Name == "anthropic" {
+			c.err = errors.New("quota exceeded")
+		}
+		return c
+	})
+	ta := NewTieredAnalyzer(&policyRecordingClient{name: "default"}, WithInstantEnabled(false), WithPolicyRoutes(routes))
+	art := input.Artifact{Path: "a.go", Content: "package a\n", Kind: input.KindFile}
+
+	results, err := ta.Analyze(context.Background(), []input.Artifact{art}, cfg.Policies, "persona")
+	if err == nil || !strings.Contains(err.Error(), "secrets, security on anthropic/claude-sonnet") {
+		t.Errorf("expected an error naming the failed route, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected findings from the routes that succeeded, got %d", len(results))
+	}
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// regexRuleFix builds the SARIF fix for a regex rule match, where match holds
+// the submatch byte offsets returned by FindAllStringSubmatchIndex. It
+// returns nil when the rule has no fix.
+func regexRuleFix(rule rules.Rule, path, content string, match []int) []sarif.Fix {
+	if rule.Fix == nil {
+		return nil
+	}
+
+	// Widen the match to the whole lines it touches. A match that ends with
+	// its line's newline does not extend onto the next line.
+	lineStart := strings.LastIndex(content[:match[0]], "\n") + 1
+	matchEnd := match[1]
+	if matchEnd > match[0] && content[matchEnd-1] == '\n' {
+		matchEnd--
+	}
+	lineEnd := len(content)
+	if i := strings.Index(content[matchEnd:], "\n"); i >= 0 {
+		lineEnd = matchEnd + i
+	}
+	startLine := strings.Count(content[:lineStart], "\n") + 1
+	endLine := strings.Count(content[:lineEnd], "\n") + 1
+
+	var inserted string
+	if !rule.Fix.Delete {
+		expanded := rule.Pattern.ExpandString(nil, rule.Fix.Replacement, content, match)
+		suffix := ""
+		if match[1] < lineEnd {
+			suffix = content[match[1]:lineEnd]
+		}
+		inserted = content[lineStart:match[0]] + string(expanded) + suffix
+	}
+	return ruleFix(rule, path, startLine, endLine, inserted)
+}
+
+// astRuleFix builds the SARIF fix for an AST rule match spanning startLine
+// to endLine. ${match} in the replacement expands to the matched lines. It
+// returns nil when the rule has no fix.
+func astRuleFix(rule rules.Rule, path, content string, startLine, endLine int) []sarif.Fix {
+	if rule.Fix == nil {
+		return nil
+	}
+	var inserted string
+	if !rule.Fix.Delete {
+		lines := strings.Split(content, "\n")
+		var matched string
+		if startLine >= 1 && endLine <= len(lines) && startLine <= endLine {
+			matched = strings.Join(lines[startLine-1:endLine], "\n")
+		}
+		inserted = strings.ReplaceAll(rule.Fix.Replacement, "${match}", matched)
+	}
+	return ruleFix(rule, path, startLine, endLine, inserted)
+}
+
+// ruleFix wraps a whole-line replacement of startLine..endLine in a SARIF
+// fix. An empty inserted text deletes the lines.
+func ruleFix(rule rules.Rule, path string, startLine, endLine int, inserted string) []sarif.Fix {
+	repl := sarif.Replacement{
+		DeletedRegion: sarif.Region{StartLine: startLine, EndLine: endLine},
+	}
+	if inserted != "" {
+		if !strings.HasSuffix(inserted, "\n") {
+			inserted += "\n"
+		}
+		repl.InsertedContent = &sarif.ArtifactContent{Text: inserted}
+	}
+
+	desc := rule.Fix.Description
+	if desc == "" {
+		desc = rule.Remediation
+	}
+	if desc == "" {
+		desc = rule.Message
+	}
+	return []sarif.Fix{{
+		Description: sarif.Message{Text: desc},
+		ArtifactChanges: []sarif.ArtifactChange{{
+			ArtifactLocation: sarif.ArtifactLocation{URI: path},
+			Replacements:     []sarif.Replacement{repl},
+		}},
+	}}
+}
@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/secrets"
+)
+
+// secretRulePrefix starts the rule ID of every secrets finding; the rest is
+// the detector ID, as in "secret/aws-access-key-id".
+const secretRulePrefix = "secret/"
+
+// WithSecretScanner sets the scanner the instant tier runs for secrets. The
+// default is secrets.NewScanner(); nil turns secrets detection off.
+func WithSecretScanner(s *secrets.Scanner) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.secretScanner = s
+	}
+}
+
+// SecretScanner builds the secrets scanner cfg describes, or returns nil
+// when cfg disables it. Verification uses the default GitHub and Slack
+// verifiers.
+func SecretScanner(cfg config.SecretsConfig) *secrets.Scanner {
+	if cfg.Disabled {
+		return nil
+	}
+	var opts []secrets.Option
+	if cfg.EntropyThreshold > 0 {
+		opts = append(opts, secrets.WithEntropyThreshold(cfg.EntropyThreshold))
+	}
+	if cfg.Verify {
+		client := &http.Client{Timeout: 10 * time.Second}
+		for id, v := range secrets.DefaultVerifiers(client) {
+			opts = append(opts, secrets.WithVerifier(id, v))
+		}
+	}
+	return secrets.NewScanner(opts...)
+}
+
+// runSecretScan reports the secrets in art as instant-tier results with
+// rule IDs "secret/<detector>" and category "secret". Secrets are masked in
+// messages and snippets. Detectors disabled by a path override, by rule ID
+// or through the secret category, are skipped.
+func (ta *TieredAnalyzer) runSecretScan(ctx context.Context, art input.Artifact, disabled pathDisables) []sarif.Result {
+	if ta.secretScanner == nil || disabled.categories[string(rules.CategorySecret)] {
+		return nil
+	}
+	found := ta.secretScanner.Scan(ctx, art.Path, art.Content)
+	if len(found) == 0 {
+		return nil
+	}
+	redacted := secrets.RedactSecrets(art.Content, found)
+
+	var results []sarif.Result
+	for _, f := range found {
+		ruleID := secretRulePrefix + f.DetectorID
+		if disabled.ruleIDs[ruleID] {
+			continue
+		}
+
+		level, message := "error", "Possible "+f.Description+" in source: "+secrets.Mask(f.Secret)
+		if f.DetectorID == secrets.EntropyDetectorID {
+			level, message = "warning", "High-entropy string that may be a secret: "+secrets.Mask(f.Secret)
+		}
+		confidence := f.Confidence
+		switch f.Verification {
+		case secrets.Live:
+			confidence = 1.0
+			message = "Live " + f.Description + " in source: " + secrets.Mask(f.Secret)
+		case secrets.Revoked:
+			level = "note"
+			message = "Revoked " + f.Description + " in source: " + secrets.Mask(f.Secret)
+		}
+
+		props := map[string]interface{}{
+			"gavel/explanation":     "Credentials committed to source control can be read by anyone with access to the repository or its history, and stay valid until they are revoked.",
+			"gavel/confidence":      confidence,
+			"gavel/tier":            "instant",
+			"gavel/rule-source":     string(rules.SourceCWE),
+			"gavel/category":        string(rules.CategorySecret),
+			"gavel/cwe":             []string{"CWE-798"},
+			"gavel/owasp":           []string{"A07:2021"},
+			"gavel/remediation":     "Revoke the credential, remove it from the code and its history, and load it at runtime from an environment variable or a secrets manager.",
+			"gavel/secret-detector": f.DetectorID,
+			"gavel/secret-entropy":  math.Round(f.Entropy*100) / 100,
+		}
+		if f.Verification != secrets.Unverified {
+			props["gavel/secret-verified"] = string(f.Verification)
+		}
+
+		startLine := f.Line
+		endLine := startLine + strings.Count(strings.TrimRight(f.Secret, "\n"), "\n")
+		results = append(results, sarif.Result{
+			RuleID:  ruleID,
+			Level:   level,
+			Message: sarif.Message{Text: message},
+			Locations: []sarif.Location{{
+				PhysicalLocation: sarif.PhysicalLocation{
+					ArtifactLocation: sarif.ArtifactLocation{URI: art.Path},
+					Region: sarif.Region{
+						StartLine: startLine,
+						EndLine:   endLine,
+						Snippet:   sarif.ExtractSnippet(redacted, startLine, endLine),
+					},
+					ContextRegion: sarif.ExtractContextRegion(redacted, startLine, endLine),
+				},
+			}},
+			Properties: props,
+		})
+	}
+	return results
+}
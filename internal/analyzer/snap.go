@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+const (
+	// snapMaxDistance is how far, in lines, a comprehensive-tier finding is
+	// moved to reach the code its message names.
+	snapMaxDistance = 10
+
+	propOriginalRegion = "gavel/original_region"
+	propSnappedRegion  = "gavel/snapped_region"
+	propSnapSymbol     = "gavel/snap_symbol"
+)
+
+var (
+	backtickSymbol = regexp.MustCompile("`([^`]+)`")
+	callSymbol     = regexp.MustCompile(`([A-Za-z_][\w.]*)\(`)
+	namedSymbol    = regexp.MustCompile(`\b(?:function|method|func|variable|field|parameter|class|type)\s+([A-Za-z_][\w.]*)`)
+	leadingSymbol  = regexp.MustCompile(`^[A-Za-z_][\w.]*`)
+)
+
+// mentionedSymbols returns the identifiers a finding's message names, in
+// order of appearance: code in backticks, calls such as foo() and names
+// after words like "function". Qualified names are reduced to their last
+// part, which is what appears as an identifier in the syntax tree.
+func mentionedSymbols(message string) []string {
+	type mention struct {
+		at   int
+		name string
+	}
+	var mentions []mention
+	for _, re := range []*regexp.Regexp{backtickSymbol, callSymbol, namedSymbol} {
+		for _, m := range re.FindAllStringSubmatchIndex(message, -1) {
+			text := leadingSymbol.FindString(message[m[2]:m[3]])
+			if i := strings.LastIndex(text, "."); i >= 0 {
+				text = text[i+1:]
+			}
+			if text != "" {
+				mentions = append(mentions, mention{m[2], text})
+			}
+		}
+	}
+
+	// Order by position, keeping the first mention of each name
+	for i := 1; i < len(mentions); i++ {
+		for j := i; j > 0 && mentions[j].at < mentions[j-1].at; j-- {
+			mentions[j], mentions[j-1] = mentions[j-1], mentions[j]
+		}
+	}
+	seen := make(map[string]bool, len(mentions))
+	var symbols []string
+	for _, m := range mentions {
+		if !seen[m.name] {
+			seen[m.name] = true
+			symbols = append(symbols, m.name)
+		}
+	}
+	return symbols
+}
+
+// snapResults re-anchors LLM findings in art whose reported lines miss the
+// code their message names, moving each to the nearest statement or
+// function that mentions the symbol. Moved results record the region the
+// LLM reported and the one it was moved to in their properties. Results
+// with a fix are left alone, since the replacement was written for the
+// reported lines.
+func snapResults(results []sarif.Result, art input.Artifact) []sarif.Result {
+	source := []byte(art.Content)
+	var tree *sitter.Tree
+	parsed := false
+	for i, r := range results {
+		if len(r.Locations) == 0 || len(r.Fixes) > 0 {
+			continue
+		}
+		loc := r.Locations[0]
+		region := loc.PhysicalLocation.Region
+		if loc.PhysicalLocation.ArtifactLocation.URI != art.Path || region.StartLine < 1 {
+			continue
+		}
+		symbols := mentionedSymbols(r.Message.Text)
+		if len(symbols) == 0 {
+			continue
+		}
+		if !parsed {
+			tree, parsed = astcheck.ParseTree(art.Path, source), true
+		}
+		snap, ok := astcheck.SnapRegion(tree, source, symbols, region.StartLine, region.EndLine, snapMaxDistance)
+		if !ok {
+			continue
+		}
+
+		loc.PhysicalLocation.Region = sarif.Region{
+			StartLine: snap.StartLine,
+			EndLine:   snap.EndLine,
+			Snippet:   sarif.ExtractSnippet(art.Content, snap.StartLine, snap.EndLine),
+		}
+		loc.PhysicalLocation.ContextRegion = sarif.ExtractContextRegion(art.Content, snap.StartLine, snap.EndLine)
+		loc.LogicalLocations = nil
+		_, lang, _ := astcheck.Detect(art.Path)
+		if ll := astcheck.ResolveLogicalLocationFromTree(tree, source, lang, snap.StartLine); ll != nil {
+			loc.LogicalLocations = []sarif.LogicalLocation{*ll}
+		}
+		locs := make([]sarif.Location, len(r.Locations))
+		copy(locs, r.Locations)
+		locs[0] = loc
+		r.Locations = locs
+
+		r.Properties = copyProperties(r.Properties)
+		r.Properties[propOriginalRegion] = sarif.Region{StartLine: region.StartLine, EndLine: region.EndLine}
+		r.Properties[propSnappedRegion] = sarif.Region{StartLine: snap.StartLine, EndLine: snap.EndLine}
+		r.Properties[propSnapSymbol] = snap.Symbol
+		results[i] = r
+	}
+	return results
+}
+
+// mapSnapRegions returns props with fn applied to the regions snapResults
+// recorded, so they stay in step with the result's locations when those are
+// shifted or normalized.
+func mapSnapRegions(props map[string]interface{}, fn func(sarif.Region) sarif.Region) map[string]interface{} {
+	if _, ok := props[propSnapSymbol]; !ok {
+		return props
+	}
+	out := copyProperties(props)
+	for _, key := range []string{propOriginalRegion, propSnappedRegion} {
+		if region, ok := propertyRegion(out[key]); ok {
+			out[key] = fn(region)
+		}
+	}
+	return out
+}
+
+// propertyRegion reads a region property, which is a generic map once the
+// result has been through JSON, as in a disk cache.
+func propertyRegion(v interface{}) (sarif.Region, bool) {
+	switch v := v.(type) {
+	case sarif.Region:
+		return v, true
+	case map[string]interface{}:
+		start, _ := v["startLine"].(float64)
+		end, _ := v["endLine"].(float64)
+		if start < 1 {
+			return sarif.Region{}, false
+		}
+		return sarif.Region{StartLine: int(start), EndLine: int(end)}, true
+	}
+	return sarif.Region{}, false
+}
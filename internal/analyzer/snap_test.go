@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+const snapSource = `package main
+
+import "os"
+
+func load(path string) []byte {
+	data, _ := os.ReadFile(path)
+	return data
+}
+
+func save(path string, data []byte) {
+	os.WriteFile(path, data, 0o644)
+}
+`
+
+func TestMentionedSymbols(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{"Error from `os.ReadFile` is discarded", []string{"ReadFile"}},
+		{"The function save ignores the error returned by WriteFile()", []string{"save", "WriteFile"}},
+		{"`data` is returned even when load() fails to read `data`", []string{"data", "load"}},
+		{"Consider adding more tests", nil},
+	}
+	for _, tt := range tests {
+		if got := mentionedSymbols(tt.message); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("mentionedSymbols(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestSnapResults(t *testing.T) {
+	art := input.Artifact{Path: "main.go", Content: snapSource, Kind: input.KindFile}
+	results := NewAnalyzer(nil).buildResults(art, []Finding{
+		{RuleID: "off-by-two", Level: "warning", Message: "Error from `os.WriteFile` is ignored", StartLine: 9, EndLine: 9},
+		{RuleID: "on-target", Level: "warning", Message: "Error from `os.ReadFile` is ignored", StartLine: 6, EndLine: 6},
+		{RuleID: "no-symbol", Level: "note", Message: "Consider documenting this function", StartLine: 9, EndLine: 9},
+		{RuleID: "with-fix", Level: "warning", Message: "Error from `os.WriteFile` is ignored", StartLine: 9, EndLine: 9, FixReplacementText: "x"},
+	})
+
+	results = snapResults(results, art)
+
+	moved := results[0]
+	region := moved.Locations[0].PhysicalLocation.Region
+	if region.StartLine != 11 || region.EndLine != 11 {
+		t.Fatalf("expected finding to snap to line 11, got %d-%d", region.StartLine, region.EndLine)
+	}
+	if region.Snippet == nil || !strings.Contains(region.Snippet.Text, "os.WriteFile") {
+		t.Errorf("expected snippet of the snapped line, got %+v", region.Snippet)
+	}
+	if ll := moved.Locations[0].LogicalLocations; len(ll) != 1 || ll[0].Name != "save" {
+		t.Errorf("expected logical location save, got %+v", ll)
+	}
+	if got := moved.Properties[propOriginalRegion]; got != (sarif.Region{StartLine: 9, EndLine: 9}) {
+		t.Errorf("expected original region 9-9, got %v", got)
+	}
+	if got := moved.Properties[propSnappedRegion]; got != (sarif.Region{StartLine: 11, EndLine: 11}) {
+		t.Errorf("expected snapped region 11-11, got %v", got)
+	}
+	if got := moved.Properties[propSnapSymbol]; got != "WriteFile" {
+		t.Errorf("expected snap symbol WriteFile, got %v", got)
+	}
+
+	for _, r := range results[1:] {
+		if _, ok := r.Properties[propOriginalRegion]; ok {
+			t.Errorf("%s: expected result to stay put, got %+v", r.RuleID, r.Properties)
+		}
+	}
+	if line := results[3].Locations[0].PhysicalLocation.Region.StartLine; line != 9 {
+		t.Errorf("expected result with a fix to stay on line 9, got %d", line)
+	}
+}
+
+func TestMapSnapRegions(t *testing.T) {
+	r := sarif.Result{
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: "main.go"},
+			Region:           sarif.Region{StartLine: 11, EndLine: 11},
+		}}},
+		Properties: map[string]interface{}{
+			propOriginalRegion: sarif.Region{StartLine: 9, EndLine: 9},
+			propSnappedRegion:  sarif.Region{StartLine: 11, EndLine: 11},
+			propSnapSymbol:     "WriteFile",
+		},
+	}
+	// Results read back from a disk cache hold regions as generic maps
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded sarif.Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, in := range []sarif.Result{r, decoded} {
+		shifted := shiftResultLines(in, 100)
+		if got, _ := propertyRegion(shifted.Properties[propOriginalRegion]); got.StartLine != 109 || got.EndLine != 109 {
+			t.Errorf("expected shifted original region 109-109, got %+v", got)
+		}
+		if got, _ := propertyRegion(shifted.Properties[propSnappedRegion]); got.StartLine != 111 {
+			t.Errorf("expected shifted snapped region to start at 111, got %+v", got)
+		}
+		if got, _ := propertyRegion(in.Properties[propOriginalRegion]); got.StartLine != 9 {
+			t.Errorf("expected input properties to be left alone, got %+v", got)
+		}
+	}
+}
+
+func TestTieredAnalyzer_SnapsComprehensiveFindings(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{
+		{RuleID: "err", Level: "warning", Message: "Error from `os.WriteFile` is ignored", StartLine: 9, EndLine: 9},
+	}}
+	ta := NewTieredAnalyzer(mock, WithInstantEnabled(false))
+	art := input.Artifact{Path: "main.go", Content: snapSource, Kind: input.KindFile}
+	policies := map[string]config.Policy{"test": {Instruction: "Check code", Enabled: true}}
+
+	results, err := ta.Analyze(context.Background(), []input.Artifact{art}, policies, "persona")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, r := range results {
+		if r.RuleID != "err" {
+			continue
+		}
+		found = true
+		if line := r.Locations[0].PhysicalLocation.Region.StartLine; line != 11 {
+			t.Errorf("expected comprehensive finding snapped to line 11, got %d", line)
+		}
+		if r.Properties[propOriginalRegion] == nil {
+			t.Errorf("expected original region to be recorded, got %+v", r.Properties)
+		}
+	}
+	if !found {
+		t.Fatalf("expected the comprehensive finding, got %+v", results)
+	}
+}
@@ -2,13 +2,15 @@ package analyzer
 
 import (
 	"context"
-	"strconv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	sitter "github.com/smacker/go-tree-sitter"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -17,10 +19,12 @@ import (
 	"github.com/chris-regnier/gavel/internal/astcheck"
 	"github.com/chris-regnier/gavel/internal/cache"
 	"github.com/chris-regnier/gavel/internal/config"
+	gavelcontext "github.com/chris-regnier/gavel/internal/context"
 	"github.com/chris-regnier/gavel/internal/input"
 	"github.com/chris-regnier/gavel/internal/metrics"
 	"github.com/chris-regnier/gavel/internal/rules"
 	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/secrets"
 )
 
 var analyzerTracer = otel.Tracer("github.com/chris-regnier/gavel/internal/analyzer")
@@ -50,6 +54,28 @@ func (t Tier) String() string {
 	}
 }
 
+// ParseTiers parses tier names, such as the values of analyze --tiers,
+// into the set of tiers to run. At least one tier must be named.
+func ParseTiers(names []string) (map[Tier]bool, error) {
+	tiers := make(map[Tier]bool)
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case "instant":
+			tiers[TierInstant] = true
+		case "fast":
+			tiers[TierFast] = true
+		case "comprehensive":
+			tiers[TierComprehensive] = true
+		default:
+			return nil, fmt.Errorf("unknown tier %q (want instant, fast or comprehensive)", name)
+		}
+	}
+	if len(tiers) == 0 {
+		return nil, errors.New("no tiers selected")
+	}
+	return tiers, nil
+}
+
 // TieredResult represents a result from a specific tier
 type TieredResult struct {
 	Tier      Tier
@@ -63,29 +89,54 @@ type TieredResult struct {
 // TieredAnalyzer provides progressive analysis across multiple tiers
 type TieredAnalyzer struct {
 	// Tier analyzers
-	cache            *cache.Cache
-	instantPatterns  []rules.Rule
-	fastClient       BAMLClient // Optional fast/local model
+	cache               cache.AnalysisCache
+	reuseComprehensive  bool // Serve comprehensive-tier results from cache; set by WithTieredCache
+	instantPatterns     []rules.Rule
+	fastClient          BAMLClient // Optional fast/local model
 	comprehensiveClient BAMLClient // Full model
 
 	// AST analysis
 	astRegistry *astcheck.Registry
 
+	// Secrets detection; nil when disabled
+	secretScanner *secrets.Scanner
+
 	// Configuration
-	fastModel         string
-	fastEnabled       bool
-	instantEnabled    bool
-	additionalContext string // Diff enrichment context (commit messages, full files, cross-file awareness)
+	fastModel          string
+	fastEnabled        bool
+	instantEnabled     bool
+	additionalContext  string                   // Diff enrichment context (commit messages, full files, cross-file awareness)
+	requestTimeout     time.Duration            // Per-call budget for fast/comprehensive clients
+	tokenBudget        int                      // Per-call estimated token cap; larger files are chunked
+	batchTokens        int                      // Comprehensive-tier cap for calls packing several small files; 0 disables batching
+	fastTokenBudget    int                      // Fast-tier override of tokenBudget, for a model with a smaller context
+	ruleProfiler       *RuleProfiler            // Optional per-rule instant-tier timing
+	appliedRules       *AppliedRules            // Optional per-file record of rules that ran
+	onResult           func(TieredResult)       // Optional hook for each tier result as it arrives
+	pathOverrides      []config.PathOverride    // Per-path disabled categories and rule IDs
+	parseErrorAction   ParseErrorAction         // How AST parse failures are reported
+	parseRetries       int                      // Extra attempts when the parser returns an error
+	policyRoutes       map[string]ModelRoute    // Per-policy comprehensive-tier provider and model
+	normalizeCacheKeys bool                     // Key comprehensive results by token stream rather than raw content
+	contextResolver    *gavelcontext.Resolver   // Selects policies' additional_contexts; nil ignores them
+	escalation         *config.EscalationConfig // Limits the comprehensive tier to flagged files; nil analyzes every file
 
 	// Metrics
 	metricsCollector *metrics.Collector
 	metricsEnabled   bool
 
 	// Stats
-	instantHits       atomic.Int64
-	instantMisses     atomic.Int64
-	fastCalls         atomic.Int64
+	instantHits        atomic.Int64
+	instantMisses      atomic.Int64
+	fastCalls          atomic.Int64
 	comprehensiveCalls atomic.Int64
+	budgetSkipped      atomic.Int64
+	parseFailures      map[string]ParseFailure
+	parseMu            sync.Mutex
+	escalationSkipped  map[string]SkippedEscalation
+	escalationMu       sync.Mutex
+	requestTimeouts    map[RequestTimeout]bool
+	timeoutMu          sync.Mutex
 
 	mu sync.RWMutex
 }
@@ -108,10 +159,36 @@ func WithFastClient(client BAMLClient) TieredAnalyzerOption {
 	}
 }
 
-// WithTieredCache sets a custom cache
-func WithTieredCache(c *cache.Cache) TieredAnalyzerOption {
+// WithFastTokenBudget caps the estimated tokens of each fast-tier call in
+// place of WithTieredTokenBudget, for a fast model whose context window is
+// smaller than the comprehensive model's. Zero uses the shared budget.
+func WithFastTokenBudget(maxTokens int) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.fastTokenBudget = maxTokens
+	}
+}
+
+// FastTierOptions returns the options that run cfg.FastProvider as the
+// fast tier, calling it through a client from newClient, or nil when no
+// fast provider is configured.
+func FastTierOptions(cfg *config.Config, newClient func(config.ProviderConfig) BAMLClient) []TieredAnalyzerOption {
+	if cfg.FastProvider.Name == "" {
+		return nil
+	}
+	return []TieredAnalyzerOption{
+		WithFastClient(newClient(cfg.FastProvider)),
+		WithFastTokenBudget(cfg.FastProvider.RequestTokenBudget()),
+	}
+}
+
+// WithTieredCache sets a custom cache, such as a cache.DiskCache that keeps
+// comprehensive-tier results between runs. Unlike the default in-memory
+// cache, which that tier only writes, a cache set here also serves its
+// results, so identical content skips the model call.
+func WithTieredCache(c cache.AnalysisCache) TieredAnalyzerOption {
 	return func(ta *TieredAnalyzer) {
 		ta.cache = c
+		ta.reuseComprehensive = true
 	}
 }
 
@@ -139,13 +216,44 @@ func WithDiffContext(ctx string) TieredAnalyzerOption {
 	}
 }
 
-// NewTieredAnalyzer creates a new tiered analyzer
+// WithTieredRequestTimeout bounds each fast/comprehensive AnalyzeCode call.
+// A file whose call exceeds the budget reports a tier error while the
+// remaining artifacts continue to be analyzed. Zero disables the limit.
+func WithTieredRequestTimeout(d time.Duration) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.requestTimeout = d
+	}
+}
+
+// WithTieredTokenBudget caps the estimated tokens of each fast and
+// comprehensive AnalyzeCode call; files that do not fit are analyzed in
+// chunks (see WithTokenBudget). Zero sends every file whole.
+func WithTieredTokenBudget(maxTokens int) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.tokenBudget = maxTokens
+	}
+}
+
+// WithResultHandler registers fn to receive each TieredResult from Analyze
+// and AnalyzeRange as soon as its tier finishes a file, before cross-tier
+// deduplication. It lets callers stream findings while the run is still in
+// progress. fn is called from a single goroutine.
+func WithResultHandler(fn func(TieredResult)) TieredAnalyzerOption {
+	return func(ta *TieredAnalyzer) {
+		ta.onResult = fn
+	}
+}
+
+// NewTieredAnalyzer creates a new tiered analyzer. A nil comprehensiveClient
+// skips the comprehensive tier, leaving only the instant tier (and the fast
+// tier, if configured).
 func NewTieredAnalyzer(comprehensiveClient BAMLClient, opts ...TieredAnalyzerOption) *TieredAnalyzer {
 	ta := &TieredAnalyzer{
 		cache:               cache.New(cache.WithMaxSize(1000), cache.WithTTL(1*time.Hour)),
 		instantPatterns:     defaultPatterns(),
 		comprehensiveClient: comprehensiveClient,
 		astRegistry:         astcheck.DefaultRegistry(),
+		secretScanner:       secrets.NewScanner(),
 		instantEnabled:      true,
 		fastEnabled:         false,
 	}
@@ -170,6 +278,15 @@ func defaultPatterns() []rules.Rule {
 // Instant-tier results for ALL artifacts are emitted first (providing immediate feedback),
 // followed by fast and comprehensive tiers per artifact.
 func (ta *TieredAnalyzer) AnalyzeProgressive(ctx context.Context, artifacts []input.Artifact, policies map[string]config.Policy, personaPrompt string) <-chan TieredResult {
+	ta.resetParseFailures()
+	return ta.analyzeProgressive(ctx, artifacts, policies, personaPrompt, ta.instantEnabled, nil)
+}
+
+// analyzeProgressive implements AnalyzeProgressive. runInstant lets callers
+// that already ran the instant tier against different content (such as
+// AnalyzeRange) skip it for these artifacts, passing its results as
+// screened for the escalation decision.
+func (ta *TieredAnalyzer) analyzeProgressive(ctx context.Context, artifacts []input.Artifact, policies map[string]config.Policy, personaPrompt string, runInstant bool, screened []TieredResult) <-chan TieredResult {
 	resultChan := make(chan TieredResult, len(artifacts)*3) // Up to 3 tiers per artifact
 
 	go func() {
@@ -177,8 +294,16 @@ func (ta *TieredAnalyzer) AnalyzeProgressive(ctx context.Context, artifacts []in
 
 		policyText := FormatPolicies(policies)
 
+		var screen *escalationScreen
+		if ta.escalation != nil {
+			screen = newEscalationScreen()
+			for _, tr := range screened {
+				screen.observe(tr)
+			}
+		}
+
 		// Phase 1: Run instant tier for ALL artifacts first (~0-100ms total)
-		if ta.instantEnabled {
+		if runInstant {
 			instantCtx, instantSpan := analyzerTracer.Start(ctx, "run instant tier",
 				trace.WithAttributes(
 					attribute.String("gavel.tier", "instant"),
@@ -197,7 +322,14 @@ func (ta *TieredAnalyzer) AnalyzeProgressive(ctx context.Context, artifacts []in
 					return
 				default:
 				}
-				ta.runInstantTier(instantCtx, art, policyText, personaPrompt, resultChan)
+				tr := ta.runInstantTier(instantCtx, art, policyText, personaPrompt)
+				screen.observe(tr)
+				resultChan <- tr
+			}
+			// Clones spanning files need every artifact, so they follow the per-file results
+			for _, tr := range ta.runDuplicateCode(instantCtx, artifacts) {
+				screen.observe(tr)
+				resultChan <- tr
 			}
 			instantSpan.End()
 		}
@@ -221,18 +353,48 @@ func (ta *TieredAnalyzer) AnalyzeProgressive(ctx context.Context, artifacts []in
 					return
 				default:
 				}
-				ta.runFastTier(fastCtx, art, policies, personaPrompt, resultChan)
+				if art.Kind == input.KindSBOM {
+					continue
+				}
+				tr := ta.runFastTier(fastCtx, art, policies, personaPrompt)
+				screen.observe(tr)
+				resultChan <- tr
 			}
 			fastSpan.End()
 		}
 
 		// Phase 2b: Run comprehensive tier
+		if ta.comprehensiveClient == nil {
+			return
+		}
 		comprehensiveCtx, comprehensiveSpan := analyzerTracer.Start(ctx, "run comprehensive tier",
 			trace.WithAttributes(
 				attribute.String("gavel.tier", "comprehensive"),
 			),
 		)
-		for _, art := range artifacts {
+		pending := artifacts
+		if screen != nil {
+			// Only files the lower tiers flagged are worth the LLM call
+			var skipped []SkippedEscalation
+			pending, skipped = screen.escalate(ta.escalation, artifacts)
+			ta.recordSkippedEscalations(skipped)
+			comprehensiveSpan.SetAttributes(attribute.Int("gavel.escalation_skipped", len(skipped)))
+		}
+		if ta.batchTokens > 0 {
+			var groups [][]input.Artifact
+			groups, pending = ta.planBatches(comprehensiveCtx, pending, policies, personaPrompt, policyText)
+			for _, group := range groups {
+				if err := comprehensiveCtx.Err(); err != nil {
+					for _, art := range group {
+						resultChan <- TieredResult{Tier: TierComprehensive, FilePath: art.Path, Error: err}
+					}
+					comprehensiveSpan.End()
+					return
+				}
+				ta.runComprehensiveBatch(comprehensiveCtx, group, policies, personaPrompt, policyText, resultChan)
+			}
+		}
+		for _, art := range pending {
 			select {
 			case <-comprehensiveCtx.Done():
 				resultChan <- TieredResult{
@@ -244,6 +406,9 @@ func (ta *TieredAnalyzer) AnalyzeProgressive(ctx context.Context, artifacts []in
 				return
 			default:
 			}
+			if art.Kind == input.KindSBOM {
+				continue
+			}
 			ta.runComprehensiveTier(comprehensiveCtx, art, policies, personaPrompt, policyText, resultChan)
 		}
 		comprehensiveSpan.End()
@@ -253,7 +418,7 @@ func (ta *TieredAnalyzer) AnalyzeProgressive(ctx context.Context, artifacts []in
 }
 
 // runInstantTier executes instant-tier analysis
-func (ta *TieredAnalyzer) runInstantTier(ctx context.Context, art input.Artifact, policyText, personaPrompt string, resultChan chan<- TieredResult) {
+func (ta *TieredAnalyzer) runInstantTier(ctx context.Context, art input.Artifact, policyText, personaPrompt string) TieredResult {
 	ctx, span := analyzerTracer.Start(ctx, "analyze file",
 		trace.WithAttributes(
 			attribute.String("gavel.file_path", art.Path),
@@ -271,25 +436,24 @@ func (ta *TieredAnalyzer) runInstantTier(ctx context.Context, art input.Artifact
 	if cached, ok := ta.cache.Get(cacheKey); ok {
 		ta.instantHits.Add(1)
 		duration := time.Since(start)
-		
-		ta.recordMetrics(art, metrics.TierInstant, duration, 0, metrics.CacheHit, nil)
-		
+
+		ta.recordMetrics(art, metrics.TierInstant, duration, 0, 0, metrics.CacheHit, nil)
+
 		if results, ok := cached.([]sarif.Result); ok {
-			resultChan <- TieredResult{
+			return TieredResult{
 				Tier:      TierInstant,
 				FilePath:  art.Path,
-				Results:   results,
+				Results:   ta.filterPathOverrides(art.Path, results),
 				FromCache: true,
 				Duration:  duration,
 			}
-			return
 		}
 	}
 
 	ta.instantMisses.Add(1)
 
 	// Run pattern matching
-	results := ta.runPatternMatching(art)
+	results := ta.runPatternMatching(ctx, art)
 	// Add prompt hash to instant tier results
 	promptHash := cache.PromptHash(personaPrompt, policyText)
 	for i := range results {
@@ -300,11 +464,11 @@ func (ta *TieredAnalyzer) runInstantTier(ctx context.Context, art input.Artifact
 	}
 	duration := time.Since(start)
 
-	ta.recordMetrics(art, metrics.TierInstant, duration, len(results), metrics.CacheMiss, nil)
+	ta.recordMetrics(art, metrics.TierInstant, duration, len(results), 0, metrics.CacheMiss, nil)
 
 	span.SetAttributes(attribute.Int("gavel.finding_count", len(results)))
 
-	resultChan <- TieredResult{
+	return TieredResult{
 		Tier:      TierInstant,
 		FilePath:  art.Path,
 		Results:   results,
@@ -314,28 +478,48 @@ func (ta *TieredAnalyzer) runInstantTier(ctx context.Context, art input.Artifact
 }
 
 // RunPatternMatching executes instant checks (regex + AST) and returns matching SARIF results.
-func (ta *TieredAnalyzer) RunPatternMatching(art input.Artifact) []sarif.Result {
-	return ta.runPatternMatching(art)
+func (ta *TieredAnalyzer) RunPatternMatching(ctx context.Context, art input.Artifact) []sarif.Result {
+	return ta.runPatternMatching(ctx, art)
 }
 
-// runPatternMatching executes instant checks by partitioning rules into regex and AST types
-func (ta *TieredAnalyzer) runPatternMatching(art input.Artifact) []sarif.Result {
+// runPatternMatching executes instant checks by partitioning rules into regex and AST types,
+// alongside the secrets scanner
+func (ta *TieredAnalyzer) runPatternMatching(ctx context.Context, art input.Artifact) []sarif.Result {
 	ta.mu.RLock()
 	patterns := ta.instantPatterns
 	ta.mu.RUnlock()
 
-	var regexRules, astRules []rules.Rule
+	if ta.appliedRules != nil {
+		ta.appliedRules.start(art.Path)
+	}
+
+	var disabled pathDisables
+	if len(ta.pathOverrides) > 0 {
+		disabled = ta.disablesFor(art.Path)
+	}
+
+	var regexRules, astRules, depRules []rules.Rule
 	for _, rule := range patterns {
+		if disabled.ruleDisabled(rule) {
+			continue
+		}
 		switch rule.Type {
-		case rules.RuleTypeAST:
+		case rules.RuleTypeAST, rules.RuleTypeASTQuery:
 			astRules = append(astRules, rule)
+		case rules.RuleTypeDependency:
+			depRules = append(depRules, rule)
 		default:
 			regexRules = append(regexRules, rule)
 		}
 	}
 
-	results := ta.runRegexRules(art, regexRules)
-	results = append(results, ta.runASTRules(art, astRules)...)
+	results := ta.runDependencyRules(art, depRules)
+	if art.Kind == input.KindSBOM {
+		return results
+	}
+	results = append(results, ta.runSecretScan(ctx, art, disabled)...)
+	results = append(results, ta.runRegexRules(art, regexRules)...)
+	results = append(results, ta.runASTRules(ctx, art, astRules)...)
 	return results
 }
 
@@ -348,13 +532,30 @@ func (ta *TieredAnalyzer) runRegexRules(art input.Artifact, regexRules []rules.R
 	idx, _ := astcheck.BuildIndex(art.Path, []byte(art.Content))
 
 	for _, rule := range regexRules {
-		// Skip rules that don't apply to this file's language
+		// Skip rules that don't apply to this file's language or path
 		if len(rule.Languages) > 0 && !matchesLanguage(art.Path, rule.Languages) {
 			continue
 		}
+		if !rule.AppliesToPath(art.Path) {
+			continue
+		}
 
-		matches := rule.Pattern.FindAllStringIndex(art.Content, -1)
+		matchStart := time.Now()
+		matches := rule.Pattern.FindAllStringSubmatchIndex(art.Content, -1)
+		if ta.ruleProfiler != nil {
+			ta.ruleProfiler.record(rule, art.Path, time.Since(matchStart), len(matches))
+		}
+		if ta.appliedRules != nil {
+			ta.appliedRules.record(rule, art.Path, len(matches))
+		}
 		for _, match := range matches {
+			if !rule.InScope(art.Content, match[0]) {
+				continue
+			}
+			if len(rule.AllowlistPatterns) > 0 && rule.Allowed(rule.MatchValue(art.Content, match)) {
+				continue
+			}
+
 			// Calculate line number from byte offset
 			lineNum := 1
 			for i := range lines {
@@ -365,10 +566,10 @@ func (ta *TieredAnalyzer) runRegexRules(art input.Artifact, regexRules []rules.R
 			}
 
 			props := map[string]interface{}{
-				"gavel/explanation":  rule.Explanation,
-				"gavel/confidence":   rule.Confidence,
-				"gavel/tier":         "instant",
-				"gavel/rule-source":  string(rule.Source),
+				"gavel/explanation": rule.Explanation,
+				"gavel/confidence":  rule.Confidence,
+				"gavel/tier":        "instant",
+				"gavel/rule-source": string(rule.Source),
 			}
 
 			if rule.Remediation != "" {
@@ -377,6 +578,15 @@ func (ta *TieredAnalyzer) runRegexRules(art input.Artifact, regexRules []rules.R
 			if len(rule.References) > 0 {
 				props["gavel/references"] = rule.References
 			}
+			if rule.Category != "" {
+				props["gavel/category"] = string(rule.Category)
+			}
+			if len(rule.CWE) > 0 {
+				props["gavel/cwe"] = rule.CWE
+			}
+			if len(rule.OWASP) > 0 {
+				props["gavel/owasp"] = rule.OWASP
+			}
 
 			loc := sarif.Location{
 				PhysicalLocation: sarif.PhysicalLocation{
@@ -399,6 +609,7 @@ func (ta *TieredAnalyzer) runRegexRules(art input.Artifact, regexRules []rules.R
 				Message:    sarif.Message{Text: rule.Message},
 				Locations:  []sarif.Location{loc},
 				Properties: props,
+				Fixes:      regexRuleFix(rule, art.Path, art.Content, match),
 			})
 		}
 	}
@@ -407,7 +618,7 @@ func (ta *TieredAnalyzer) runRegexRules(art input.Artifact, regexRules []rules.R
 }
 
 // runASTRules executes tree-sitter AST-based instant checks
-func (ta *TieredAnalyzer) runASTRules(art input.Artifact, astRules []rules.Rule) []sarif.Result {
+func (ta *TieredAnalyzer) runASTRules(ctx context.Context, art input.Artifact, astRules []rules.Rule) []sarif.Result {
 	if len(astRules) == 0 {
 		return nil
 	}
@@ -417,14 +628,15 @@ func (ta *TieredAnalyzer) runASTRules(art input.Artifact, astRules []rules.Rule)
 		return nil
 	}
 
-	parser := sitter.NewParser()
-	parser.SetLanguage(lang)
-	tree, err := parser.ParseCtx(context.Background(), nil, []byte(art.Content))
-	if err != nil {
-		return nil
+	var results []sarif.Result
+	tree, failure := ta.parseForAST(ctx, art, lang)
+	if failure != nil {
+		results = append(results, ta.reportParseFailure(art, *failure)...)
+	}
+	if tree == nil {
+		return results
 	}
 
-	var results []sarif.Result
 	sourceBytes := []byte(art.Content)
 
 	// Build function index once for logical location lookups across all matches.
@@ -434,38 +646,29 @@ func (ta *TieredAnalyzer) runASTRules(art input.Artifact, astRules []rules.Rule)
 		if len(rule.Languages) > 0 && !matchesLanguage(art.Path, rule.Languages) {
 			continue
 		}
+		if !rule.AppliesToPath(art.Path) {
+			continue
+		}
 
-		check, ok := ta.astRegistry.Get(rule.ASTCheck)
+		check, ok := ta.astCheckFor(rule)
 		if !ok {
 			continue
 		}
 
+		matchStart := time.Now()
 		matches := check.Run(tree, sourceBytes, langName, rule.ASTConfig)
+		if ta.ruleProfiler != nil {
+			ta.ruleProfiler.record(rule, art.Path, time.Since(matchStart), len(matches))
+		}
+		if ta.appliedRules != nil {
+			ta.appliedRules.record(rule, art.Path, len(matches))
+		}
 		for _, m := range matches {
 			msg := rule.Message
 			if m.Message != "" {
 				msg = m.Message
 			}
 
-			props := map[string]interface{}{
-				"gavel/explanation": rule.Explanation,
-				"gavel/confidence":  rule.Confidence,
-				"gavel/tier":        "instant",
-				"gavel/rule-type":   "ast",
-				"gavel/rule-source": string(rule.Source),
-			}
-			if rule.Remediation != "" {
-				props["gavel/remediation"] = rule.Remediation
-			}
-			if len(rule.References) > 0 {
-				props["gavel/references"] = rule.References
-			}
-			if m.Extra != nil {
-				for k, v := range m.Extra {
-					props["gavel/"+k] = v
-				}
-			}
-
 			loc := sarif.Location{
 				PhysicalLocation: sarif.PhysicalLocation{
 					ArtifactLocation: sarif.ArtifactLocation{URI: art.Path},
@@ -486,13 +689,54 @@ func (ta *TieredAnalyzer) runASTRules(art input.Artifact, astRules []rules.Rule)
 				Level:      rule.Level,
 				Message:    sarif.Message{Text: msg},
 				Locations:  []sarif.Location{loc},
-				Properties: props,
+				Properties: astResultProperties(rule, m.Extra),
+				Fixes:      astRuleFix(rule, art.Path, art.Content, m.StartLine, m.EndLine),
 			})
 		}
 	}
 	return results
 }
 
+// astResultProperties returns the properties of an AST rule's finding,
+// including the check's extra match details.
+func astResultProperties(rule rules.Rule, extra map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{
+		"gavel/explanation": rule.Explanation,
+		"gavel/confidence":  rule.Confidence,
+		"gavel/tier":        "instant",
+		"gavel/rule-type":   string(rule.Type),
+		"gavel/rule-source": string(rule.Source),
+	}
+	if rule.Remediation != "" {
+		props["gavel/remediation"] = rule.Remediation
+	}
+	if len(rule.References) > 0 {
+		props["gavel/references"] = rule.References
+	}
+	if rule.Category != "" {
+		props["gavel/category"] = string(rule.Category)
+	}
+	if len(rule.CWE) > 0 {
+		props["gavel/cwe"] = rule.CWE
+	}
+	if len(rule.OWASP) > 0 {
+		props["gavel/owasp"] = rule.OWASP
+	}
+	for k, v := range extra {
+		props["gavel/"+k] = v
+	}
+	return props
+}
+
+// astCheckFor returns the check an AST rule runs: its compiled query for
+// ast-query rules, otherwise the registered check it names.
+func (ta *TieredAnalyzer) astCheckFor(rule rules.Rule) (astcheck.Check, bool) {
+	if rule.Type == rules.RuleTypeASTQuery {
+		return rule.QueryCheck, rule.QueryCheck != nil
+	}
+	return ta.astRegistry.Get(rule.ASTCheck)
+}
+
 // matchesLanguage checks if a file path matches any of the specified languages
 func matchesLanguage(path string, languages []string) bool {
 	for _, lang := range languages {
@@ -525,13 +769,29 @@ func matchesLanguage(path string, languages []string) bool {
 			if strings.HasSuffix(path, ".rs") {
 				return true
 			}
+		case "ruby":
+			if strings.HasSuffix(path, ".rb") {
+				return true
+			}
+		case "php":
+			if strings.HasSuffix(path, ".php") {
+				return true
+			}
+		case "kotlin":
+			if strings.HasSuffix(path, ".kt") || strings.HasSuffix(path, ".kts") {
+				return true
+			}
+		case "csharp", "cs":
+			if strings.HasSuffix(path, ".cs") {
+				return true
+			}
 		}
 	}
 	return false
 }
 
 // runFastTier executes fast-tier analysis with local model
-func (ta *TieredAnalyzer) runFastTier(ctx context.Context, art input.Artifact, policies map[string]config.Policy, personaPrompt string, resultChan chan<- TieredResult) {
+func (ta *TieredAnalyzer) runFastTier(ctx context.Context, art input.Artifact, policies map[string]config.Policy, personaPrompt string) TieredResult {
 	ctx, span := analyzerTracer.Start(ctx, "analyze file",
 		trace.WithAttributes(
 			attribute.String("gavel.file_path", art.Path),
@@ -546,6 +806,9 @@ func (ta *TieredAnalyzer) runFastTier(ctx context.Context, art input.Artifact, p
 	ta.fastCalls.Add(1)
 
 	analyzer := ta.newAnalyzerForClient(ta.fastClient)
+	if ta.fastTokenBudget > 0 {
+		analyzer.tokenBudget = ta.fastTokenBudget
+	}
 	results, err := analyzer.Analyze(ctx, []input.Artifact{art}, policies, personaPrompt)
 	duration := time.Since(start)
 
@@ -557,6 +820,7 @@ func (ta *TieredAnalyzer) runFastTier(ctx context.Context, art input.Artifact, p
 		results[i].Properties["gavel/tier"] = "fast"
 		results[i].Properties["gavel/prompt_hash"] = cache.PromptHash(personaPrompt, FormatPolicies(policies))
 	}
+	results = ta.filterPathOverrides(art.Path, results)
 
 	if err != nil {
 		span.RecordError(err)
@@ -564,9 +828,9 @@ func (ta *TieredAnalyzer) runFastTier(ctx context.Context, art input.Artifact, p
 	}
 	span.SetAttributes(attribute.Int("gavel.finding_count", len(results)))
 
-	ta.recordMetrics(art, metrics.TierFast, duration, len(results), metrics.CacheMiss, err)
+	ta.recordMetrics(art, metrics.TierFast, duration, len(results), estimateTokens(art.Content, FormatPolicies(policies), personaPrompt, ta.additionalContext), metrics.CacheMiss, err)
 
-	resultChan <- TieredResult{
+	return TieredResult{
 		Tier:     TierFast,
 		FilePath: art.Path,
 		Results:  results,
@@ -575,6 +839,15 @@ func (ta *TieredAnalyzer) runFastTier(ctx context.Context, art input.Artifact, p
 	}
 }
 
+// cacheForComprehensive looks key up for the comprehensive tier, which
+// reads the cache only when it was set with WithTieredCache.
+func (ta *TieredAnalyzer) cacheForComprehensive(key string) (interface{}, bool) {
+	if !ta.reuseComprehensive {
+		return nil, false
+	}
+	return ta.cache.Get(key)
+}
+
 // runComprehensiveTier executes full LLM analysis
 func (ta *TieredAnalyzer) runComprehensiveTier(ctx context.Context, art input.Artifact, policies map[string]config.Policy, personaPrompt, policyText string, resultChan chan<- TieredResult) {
 	ctx, span := analyzerTracer.Start(ctx, "analyze file",
@@ -588,47 +861,124 @@ func (ta *TieredAnalyzer) runComprehensiveTier(ctx context.Context, art input.Ar
 	defer span.End()
 
 	start := time.Now()
-	cacheKey := cache.ContentKey(art.Content, policyText, personaPrompt)
+	batches := ta.comprehensiveBatches(policies)
+	contexts := ta.resolveContexts(ctx, art, batches)
+	span.SetAttributes(contextAttributes(contexts)...)
+	cacheKey, tokens := ta.contentCacheKey(art, policyText+routeSignature(batches)+contextSignature(contexts), personaPrompt)
+
+	// Reuse an earlier run's results for identical content when the cache
+	// was chosen for it with WithTieredCache, as a disk cache that persists
+	// between invocations is. Results are still written to the default
+	// in-memory cache below but never read back from it.
+	if cached, ok := ta.cacheForComprehensive(cacheKey); ok {
+		if results, ok := cached.([]sarif.Result); ok {
+			duration := time.Since(start)
+			if tokens != nil {
+				results = placeResults(results, art, tokens)
+			}
+			results = ta.filterPathOverrides(art.Path, results)
+			span.SetAttributes(attribute.Int("gavel.finding_count", len(results)))
+			ta.recordMetrics(art, metrics.TierComprehensive, duration, len(results), 0, metrics.CacheHit, nil)
+			resultChan <- TieredResult{
+				Tier:      TierComprehensive,
+				FilePath:  art.Path,
+				Results:   results,
+				FromCache: true,
+				Duration:  duration,
+			}
+			return
+		}
+	}
+
+	// Policies routed to different providers or models are analyzed in
+	// one call per route, each reported as its own result so a failed
+	// route does not discard the findings of the others
+	var all []sarif.Result
+	var out []TieredResult
+	failed, budgetSkipped := false, false
+	findingCount := 0
+	for i, b := range batches {
+		batchStart := time.Now()
+		ta.comprehensiveCalls.Add(1)
+
+		client, batchText := ta.comprehensiveClient, policyText
+		if b.routed {
+			if b.route.Client != nil {
+				client = b.route.Client
+			}
+			batchText = FormatPolicies(b.policies)
+		}
+		analyzer := ta.newAnalyzerWithContext(client, contexts[i].Text())
+		results, err := analyzer.Analyze(ctx, []input.Artifact{art}, b.policies, personaPrompt)
+		duration := time.Since(batchStart)
+		if errors.Is(err, ErrBudgetExceeded) {
+			// Out of budget: report the file as skipped rather than failed
+			// so the run keeps the findings it already has
+			budgetSkipped = true
+			failed = true
+			err = nil
+		}
+		if err != nil && b.routed {
+			err = fmt.Errorf("policies %s on %s: %w", strings.Join(b.names, ", "), b.route.key(), err)
+		}
 
-	ta.comprehensiveCalls.Add(1)
+		// Tag results with tier, and with the route when policies are routed
+		for i := range results {
+			if results[i].Properties == nil {
+				results[i].Properties = make(map[string]interface{})
+			}
+			results[i].Properties["gavel/tier"] = "comprehensive"
+			results[i].Properties["gavel/prompt_hash"] = cache.PromptHash(personaPrompt, batchText)
+			if b.routed {
+				results[i].Properties["gavel/provider"] = b.route.Provider
+				results[i].Properties["gavel/model"] = b.route.Model
+				results[i].Properties["gavel/policies"] = b.names
+			}
+		}
+		// LLM line numbers drift; move findings onto the code they name
+		results = snapResults(results, art)
+		all = append(all, results...)
+		results = ta.filterPathOverrides(art.Path, results)
+
+		if err != nil {
+			failed = true
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		findingCount += len(results)
 
-	analyzer := ta.newAnalyzerForClient(ta.comprehensiveClient)
-	results, err := analyzer.Analyze(ctx, []input.Artifact{art}, policies, personaPrompt)
-	duration := time.Since(start)
+		ta.recordMetrics(art, metrics.TierComprehensive, duration, len(results), estimateTokens(art.Content, batchText, personaPrompt, analyzer.additionalContext), metrics.CacheMiss, err)
 
-	if err == nil {
-		// Cache successful results
-		ta.cache.Set(cacheKey, results)
+		out = append(out, TieredResult{
+			Tier:     TierComprehensive,
+			FilePath: art.Path,
+			Results:  results,
+			Error:    err,
+			Duration: duration,
+		})
+	}
+	span.SetAttributes(attribute.Int("gavel.finding_count", findingCount))
+	if budgetSkipped {
+		ta.budgetSkipped.Add(1)
+		span.SetAttributes(attribute.Bool("gavel.budget_exceeded", true))
 	}
 
-	// Tag results with tier
-	for i := range results {
-		if results[i].Properties == nil {
-			results[i].Properties = make(map[string]interface{})
+	if !failed {
+		// Cache successful results, tagged but before path overrides so a
+		// changed override takes effect on a hit
+		if tokens != nil {
+			all = anchorResults(all, art, tokens)
 		}
-		results[i].Properties["gavel/tier"] = "comprehensive"
-		results[i].Properties["gavel/prompt_hash"] = cache.PromptHash(personaPrompt, policyText)
+		ta.cache.Set(cacheKey, all)
 	}
-
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-	}
-	span.SetAttributes(attribute.Int("gavel.finding_count", len(results)))
-
-	ta.recordMetrics(art, metrics.TierComprehensive, duration, len(results), metrics.CacheMiss, err)
-
-	resultChan <- TieredResult{
-		Tier:     TierComprehensive,
-		FilePath: art.Path,
-		Results:  results,
-		Error:    err,
-		Duration: duration,
+	for _, tr := range out {
+		resultChan <- tr
 	}
 }
 
-// recordMetrics records an analysis event to the metrics collector
-func (ta *TieredAnalyzer) recordMetrics(art input.Artifact, tier metrics.TierLevel, duration time.Duration, findingCount int, cacheResult metrics.CacheResult, err error) {
+// recordMetrics records an analysis event to the metrics collector.
+// tokensIn is the estimated prompt size of an LLM call, or 0 when none was made.
+func (ta *TieredAnalyzer) recordMetrics(art input.Artifact, tier metrics.TierLevel, duration time.Duration, findingCount, tokensIn int, cacheResult metrics.CacheResult, err error) {
 	if !ta.metricsEnabled || ta.metricsCollector == nil {
 		return
 	}
@@ -643,6 +993,7 @@ func (ta *TieredAnalyzer) recordMetrics(art input.Artifact, tier metrics.TierLev
 		AnalysisDuration: duration,
 		TotalDuration:    duration,
 		FindingCount:     findingCount,
+		TokensIn:         tokensIn,
 		CacheResult:      cacheResult,
 	}
 
@@ -672,7 +1023,10 @@ func (ta *TieredAnalyzer) Analyze(ctx context.Context, artifacts []input.Artifac
 	var lastError error
 
 	for result := range ta.AnalyzeProgressive(ctx, artifacts, policies, personaPrompt) {
-		if result.Error != nil {
+		if ta.onResult != nil {
+			ta.onResult(result)
+		}
+		if result.Error != nil && !ta.recordRequestTimeout(result) {
 			lastError = result.Error
 			continue
 		}
@@ -685,45 +1039,51 @@ func (ta *TieredAnalyzer) Analyze(ctx context.Context, artifacts []input.Artifac
 	return deduplicated, lastError
 }
 
-// deduplicateResults removes duplicate findings, preferring higher-tier results
-func (ta *TieredAnalyzer) deduplicateResults(results []sarif.Result) []sarif.Result {
-	// Key: ruleID + file + line
-	seen := make(map[string]sarif.Result)
-	tierPriority := map[string]int{"comprehensive": 3, "fast": 2, "instant": 1}
-
-	for _, r := range results {
-		if len(r.Locations) == 0 {
-			continue
-		}
-
-		loc := r.Locations[0].PhysicalLocation
-		key := r.RuleID + "|" + loc.ArtifactLocation.URI + "|" + strconv.Itoa(loc.Region.StartLine)
-
-		tier := "instant"
-		if t, ok := r.Properties["gavel/tier"].(string); ok {
-			tier = t
-		}
+// RequestTimeout is a file whose LLM call in one tier ran past the
+// per-request budget set with WithTieredRequestTimeout.
+type RequestTimeout struct {
+	Path string `json:"path"`
+	Tier string `json:"tier"`
+}
 
-		if existing, ok := seen[key]; ok {
-			existingTier := "instant"
-			if t, ok := existing.Properties["gavel/tier"].(string); ok {
-				existingTier = t
-			}
-			// Keep higher-tier result
-			if tierPriority[tier] > tierPriority[existingTier] {
-				seen[key] = r
-			}
-		} else {
-			seen[key] = r
-		}
+// recordRequestTimeout records tr's file when its error is a per-request
+// timeout and reports whether it was one. Such a file keeps the results of
+// its other tiers, and the run carries on with the remaining files.
+func (ta *TieredAnalyzer) recordRequestTimeout(tr TieredResult) bool {
+	if !errors.Is(tr.Error, ErrRequestTimeout) {
+		return false
 	}
+	slog.Warn("LLM request timed out; continuing without this tier's findings for the file", "file", tr.FilePath, "tier", tr.Tier.String(), "err", tr.Error)
+	ta.timeoutMu.Lock()
+	defer ta.timeoutMu.Unlock()
+	if ta.requestTimeouts == nil {
+		ta.requestTimeouts = make(map[RequestTimeout]bool)
+	}
+	ta.requestTimeouts[RequestTimeout{Path: tr.FilePath, Tier: tr.Tier.String()}] = true
+	return true
+}
 
-	deduplicated := make([]sarif.Result, 0, len(seen))
-	for _, r := range seen {
-		deduplicated = append(deduplicated, r)
+// RequestTimeouts returns the files whose LLM calls timed out, sorted by
+// path and tier.
+func (ta *TieredAnalyzer) RequestTimeouts() []RequestTimeout {
+	ta.timeoutMu.Lock()
+	defer ta.timeoutMu.Unlock()
+	out := make([]RequestTimeout, 0, len(ta.requestTimeouts))
+	for t := range ta.requestTimeouts {
+		out = append(out, t)
 	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Tier < out[j].Tier
+	})
+	return out
+}
 
-	return deduplicated
+// deduplicateResults removes duplicate findings, preferring higher-tier results
+func (ta *TieredAnalyzer) deduplicateResults(results []sarif.Result) []sarif.Result {
+	return sarif.DeduplicateByTier(results)
 }
 
 // TieredAnalyzerStats holds statistics for the tiered analyzer
@@ -732,6 +1092,7 @@ type TieredAnalyzerStats struct {
 	InstantMisses      int64            `json:"instant_misses"`
 	FastCalls          int64            `json:"fast_calls"`
 	ComprehensiveCalls int64            `json:"comprehensive_calls"`
+	BudgetSkipped      int64            `json:"budget_skipped"` // Files the comprehensive tier skipped, wholly or partly, after the cost budget ran out
 	CacheStats         cache.CacheStats `json:"cache_stats"`
 }
 
@@ -742,6 +1103,7 @@ func (ta *TieredAnalyzer) Stats() TieredAnalyzerStats {
 		InstantMisses:      ta.instantMisses.Load(),
 		FastCalls:          ta.fastCalls.Load(),
 		ComprehensiveCalls: ta.comprehensiveCalls.Load(),
+		BudgetSkipped:      ta.budgetSkipped.Load(),
 		CacheStats:         ta.cache.Stats(),
 	}
 }
@@ -753,6 +1115,12 @@ func (ta *TieredAnalyzer) newAnalyzerForClient(client BAMLClient) *Analyzer {
 	if ta.additionalContext != "" {
 		opts = append(opts, WithAdditionalContext(ta.additionalContext))
 	}
+	if ta.requestTimeout > 0 {
+		opts = append(opts, WithRequestTimeout(ta.requestTimeout))
+	}
+	if ta.tokenBudget > 0 {
+		opts = append(opts, WithTokenBudget(ta.tokenBudget))
+	}
 	return NewAnalyzer(client, opts...)
 }
 
@@ -129,3 +129,117 @@ func medium() {
 		t.Error("expected AST001 finding with max_lines=3 threshold")
 	}
 }
+
+func TestTieredAnalyzer_ASTRules_Fix(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+
+	customRules := []rules.Rule{{
+		ID:          "AST001",
+		Name:        "function-length",
+		Type:        rules.RuleTypeAST,
+		ASTCheck:    "function-length",
+		ASTConfig:   map[string]interface{}{"max_lines": 3},
+		Level:       "warning",
+		Message:     "Function too long",
+		Remediation: "Split the function",
+		Confidence:  1.0,
+		Fix:         &rules.RuleFix{Replacement: "// TODO: split\n${match}"},
+	}}
+	ta := NewTieredAnalyzer(mock, WithInstantPatterns(customRules))
+
+	source := "package main\n\nfunc medium() {\n\ta := 1\n\tb := 2\n\tc := 3\n}\n"
+	results := ta.RunPatternMatching(context.Background(), input.Artifact{Path: "test.go", Content: source, Kind: input.KindFile})
+	if len(results) != 1 || len(results[0].Fixes) != 1 {
+		t.Fatalf("expected 1 finding with a fix, got %+v", results)
+	}
+
+	f := results[0].Fixes[0]
+	if f.Description.Text != "Split the function" {
+		t.Errorf("expected description to fall back to the remediation, got %q", f.Description.Text)
+	}
+	repl := f.ArtifactChanges[0].Replacements[0]
+	if repl.DeletedRegion.StartLine != 3 || repl.DeletedRegion.EndLine != 7 {
+		t.Errorf("expected lines 3-7 to be replaced, got %+v", repl.DeletedRegion)
+	}
+	want := "// TODO: split\nfunc medium() {\n\ta := 1\n\tb := 2\n\tc := 3\n}\n"
+	if repl.InsertedContent == nil || repl.InsertedContent.Text != want {
+		t.Errorf("inserted content = %+v, want %q", repl.InsertedContent, want)
+	}
+}
+
+func TestTieredAnalyzer_ASTQueryRule(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+	rf, err := rules.ParseRuleFile([]byte(`rules:
+  - id: "Q001"
+    type: "ast-query"
+    languages: ["go"]
+    query: '(call_expression function: (identifier) @fn (#eq? @fn "panic")) @call'
+    capture: "call"
+    level: "warning"
+    confidence: 0.7
+    message: "avoid ${fn} in library code"
+`))
+	if err != nil {
+		t.Fatalf("ParseRuleFile: %v", err)
+	}
+	ta := NewTieredAnalyzer(mock, WithInstantPatterns(rf.Rules))
+
+	source := "package lib\n\nfunc F() {\n\tpanic(\"boom\")\n}\n"
+	results := ta.RunPatternMatching(context.Background(), input.Artifact{Path: "lib.go", Content: source, Kind: input.KindFile})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", results)
+	}
+	r := results[0]
+	if r.Message.Text != "avoid panic in library code" {
+		t.Errorf("unexpected message %q", r.Message.Text)
+	}
+	if line := r.Locations[0].PhysicalLocation.Region.StartLine; line != 4 {
+		t.Errorf("expected finding on line 4, got %d", line)
+	}
+	if rt := r.Properties["gavel/rule-type"]; rt != "ast-query" {
+		t.Errorf("expected rule-type 'ast-query', got %v", rt)
+	}
+
+	if got := ta.RunPatternMatching(context.Background(), input.Artifact{Path: "lib.py", Content: "panic()\n", Kind: input.KindFile}); len(got) != 0 {
+		t.Errorf("expected the go-only query to skip Python files, got %d findings", len(got))
+	}
+}
+
+func TestTieredAnalyzer_DuplicateCodeAcrossFiles(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+	ta := NewTieredAnalyzer(mock)
+
+	body := func(name, v string) string {
+		return "\nfunc " + name + "(items []int) int {\n\t" + v + " := 0\n\tfor _, item := range items {\n\t\tif item > 0 {\n\t\t\t" + v + " += item\n\t\t} else {\n\t\t\t" + v + " -= item\n\t\t}\n\t}\n\tfor i := range items {\n\t\titems[i] = " + v + "\n\t}\n\treturn " + v + "\n}\n"
+	}
+	artifacts := []input.Artifact{
+		{Path: "a.go", Content: "package a\n" + body("total", "sum"), Kind: input.KindFile},
+		{Path: "b.go", Content: "package b\n" + body("count", "n"), Kind: input.KindFile},
+		{Path: "c.go", Content: "package c\n\nfunc other() {}\n", Kind: input.KindFile},
+	}
+	policies := map[string]config.Policy{
+		"test": {Instruction: "Check code", Enabled: true},
+	}
+
+	clones := make(map[string][]string)
+	for result := range ta.AnalyzeProgressive(context.Background(), artifacts, policies, "persona") {
+		if result.Tier != TierInstant {
+			continue
+		}
+		for _, r := range result.Results {
+			if r.RuleID != "AST014" {
+				continue
+			}
+			if len(r.RelatedLocations) != 1 {
+				t.Fatalf("%s: expected the other copy as a related location, got %+v", result.FilePath, r.RelatedLocations)
+			}
+			clones[result.FilePath] = append(clones[result.FilePath], r.RelatedLocations[0].PhysicalLocation.ArtifactLocation.URI)
+			if r.Properties["gavel/clone_id"] == nil || r.Locations[0].PhysicalLocation.Region.StartLine != 3 {
+				t.Errorf("%s: unexpected result %+v", result.FilePath, r)
+			}
+		}
+	}
+	if len(clones) != 2 || clones["a.go"][0] != "b.go" || clones["b.go"][0] != "a.go" {
+		t.Errorf("expected a.go and b.go to be reported as copies of each other, got %v", clones)
+	}
+}
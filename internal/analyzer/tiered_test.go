@@ -2,14 +2,20 @@ package analyzer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/chris-regnier/gavel/internal/cache"
 	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/fix"
 	"github.com/chris-regnier/gavel/internal/input"
 	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
 )
 
 type tieredMockClient struct {
@@ -205,6 +211,39 @@ func TestTieredAnalyzer_FastTier(t *testing.T) {
 	}
 }
 
+func TestParseTiers(t *testing.T) {
+	tiers, err := ParseTiers([]string{"instant", " comprehensive"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tiers[TierInstant] || tiers[TierFast] || !tiers[TierComprehensive] {
+		t.Errorf("unexpected tiers %v", tiers)
+	}
+	for _, names := range [][]string{nil, {"slow"}} {
+		if _, err := ParseTiers(names); err == nil {
+			t.Errorf("expected an error for %q", names)
+		}
+	}
+}
+
+func TestFastTierOptions(t *testing.T) {
+	cfg := &config.Config{Provider: config.ProviderConfig{Name: "anthropic"}}
+	if opts := FastTierOptions(cfg, nil); opts != nil {
+		t.Errorf("expected no options without a fast provider, got %d", len(opts))
+	}
+
+	cfg.FastProvider = config.ProviderConfig{Name: "llamacpp", LlamaCpp: config.LlamaCppConfig{ContextWindow: 4096}}
+	fastMock := &tieredMockClient{}
+	var got config.ProviderConfig
+	ta := NewTieredAnalyzer(&tieredMockClient{}, FastTierOptions(cfg, func(pc config.ProviderConfig) BAMLClient {
+		got = pc
+		return fastMock
+	})...)
+	if got.Name != "llamacpp" || ta.fastClient != fastMock || ta.fastTokenBudget != 3072 {
+		t.Errorf("expected the fast tier to use the fast provider, got %s with budget %d", got.Name, ta.fastTokenBudget)
+	}
+}
+
 func TestTieredAnalyzer_ProgressiveOrder(t *testing.T) {
 	fastMock := &tieredMockClient{
 		findings: []Finding{{RuleID: "fast"}},
@@ -357,6 +396,62 @@ func TestTieredAnalyzer_DisableInstant(t *testing.T) {
 	}
 }
 
+func TestTieredAnalyzer_NilClientRunsInstantOnly(t *testing.T) {
+	ta := NewTieredAnalyzer(nil)
+
+	artifacts := []input.Artifact{{
+		Path:    "test.go",
+		Content: "// TODO: test",
+		Kind:    input.KindFile,
+	}}
+	policies := map[string]config.Policy{
+		"test": {Instruction: "Check", Enabled: true},
+	}
+
+	var instant int
+	for result := range ta.AnalyzeProgressive(context.Background(), artifacts, policies, "") {
+		if result.Tier != TierInstant {
+			t.Errorf("unexpected %s tier result without a client", result.Tier)
+		}
+		instant++
+	}
+
+	if instant != 1 {
+		t.Errorf("expected 1 instant tier result, got %d", instant)
+	}
+}
+
+func TestTieredAnalyzer_ResultHandler(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{
+		{RuleID: "llm-rule", Level: "warning", Message: "llm", StartLine: 1, EndLine: 1, Confidence: 0.9},
+	}}
+	var tiers []Tier
+	ta := NewTieredAnalyzer(mock, WithResultHandler(func(tr TieredResult) { tiers = append(tiers, tr.Tier) }))
+
+	artifacts := []input.Artifact{
+		{Path: "a.go", Content: "// TODO: a", Kind: input.KindFile},
+		{Path: "b.go", Content: "// TODO: b", Kind: input.KindFile},
+	}
+	policies := map[string]config.Policy{
+		"test": {Instruction: "Check", Enabled: true},
+	}
+
+	if _, err := ta.Analyze(context.Background(), artifacts, policies, "persona"); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	want := []Tier{TierInstant, TierInstant, TierComprehensive, TierComprehensive}
+	if len(tiers) != len(want) {
+		t.Fatalf("expected handler calls %v, got %v", want, tiers)
+	}
+	for i := range want {
+		if tiers[i] != want[i] {
+			t.Errorf("expected handler calls %v, got %v", want, tiers)
+			break
+		}
+	}
+}
+
 func TestTieredAnalyzer_ContextCancellation(t *testing.T) {
 	mock := &tieredMockClient{
 		findings: []Finding{},
@@ -388,6 +483,90 @@ func TestTieredAnalyzer_ContextCancellation(t *testing.T) {
 	}
 }
 
+// stuckPathClient blocks until the context is done for a single path and
+// returns findings immediately for everything else.
+type stuckPathClient struct {
+	stuckPath string
+	findings  []Finding
+}
+
+func (m *stuckPathClient) AnalyzeCode(ctx context.Context, code string, policies string, personaPrompt string, additionalContext string) ([]Finding, error) {
+	if strings.HasPrefix(code, "// File: "+m.stuckPath+"\n") {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return m.findings, nil
+}
+
+func TestTieredAnalyzer_RequestTimeout_OtherFilesProceed(t *testing.T) {
+	mock := &stuckPathClient{
+		stuckPath: "slow.go",
+		findings:  []Finding{{RuleID: "test", Level: "warning", StartLine: 1, EndLine: 1}},
+	}
+	ta := NewTieredAnalyzer(mock,
+		WithInstantEnabled(false),
+		WithTieredRequestTimeout(50*time.Millisecond),
+	)
+
+	artifacts := []input.Artifact{
+		{Path: "slow.go", Content: "package slow"},
+		{Path: "fast.go", Content: "package fast"},
+	}
+	policies := map[string]config.Policy{
+		"test": {Instruction: "Check", Enabled: true},
+	}
+
+	start := time.Now()
+	errByFile := make(map[string]error)
+	resultsByFile := make(map[string]int)
+	for result := range ta.AnalyzeProgressive(context.Background(), artifacts, policies, "persona") {
+		errByFile[result.FilePath] = result.Error
+		resultsByFile[result.FilePath] += len(result.Results)
+	}
+	elapsed := time.Since(start)
+
+	if !errors.Is(errByFile["slow.go"], context.DeadlineExceeded) {
+		t.Errorf("expected slow.go to fail with DeadlineExceeded, got %v", errByFile["slow.go"])
+	}
+	if errByFile["fast.go"] != nil {
+		t.Errorf("expected fast.go to succeed, got %v", errByFile["fast.go"])
+	}
+	if resultsByFile["fast.go"] != 1 {
+		t.Errorf("expected 1 result for fast.go, got %d", resultsByFile["fast.go"])
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("expected run to finish near the per-request budget, took %v", elapsed)
+	}
+}
+
+func TestTieredAnalyzer_RequestTimeout_RecordedNotFatal(t *testing.T) {
+	mock := &stuckPathClient{
+		stuckPath: "slow.go",
+		findings:  []Finding{{RuleID: "test", Level: "warning", StartLine: 1, EndLine: 1}},
+	}
+	ta := NewTieredAnalyzer(mock,
+		WithInstantEnabled(false),
+		WithTieredRequestTimeout(50*time.Millisecond),
+	)
+	artifacts := []input.Artifact{
+		{Path: "slow.go", Content: "package slow"},
+		{Path: "fast.go", Content: "package fast"},
+	}
+	policies := map[string]config.Policy{"test": {Instruction: "Check", Enabled: true}}
+
+	results, err := ta.Analyze(context.Background(), artifacts, policies, "persona")
+	if err != nil {
+		t.Fatalf("expected a request timeout not to fail the run, got %v", err)
+	}
+	if len(results) != 1 || results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "fast.go" {
+		t.Errorf("expected fast.go's finding, got %+v", results)
+	}
+	want := []RequestTimeout{{Path: "slow.go", Tier: "comprehensive"}}
+	if got := ta.RequestTimeouts(); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("RequestTimeouts() = %+v, want %+v", got, want)
+	}
+}
+
 func TestTieredAnalyzer_MultipleArtifacts(t *testing.T) {
 	mock := &tieredMockClient{
 		findings: []Finding{{RuleID: "test", Level: "warning"}},
@@ -450,6 +629,80 @@ func TestTieredAnalyzer_Stats(t *testing.T) {
 	}
 }
 
+func TestTieredAnalyzer_TieredCacheServesComprehensive(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+	ta := NewTieredAnalyzer(mock, WithTieredCache(cache.New()))
+
+	artifacts := []input.Artifact{{
+		Path:    "test.go",
+		Content: "package main",
+		Kind:    input.KindFile,
+	}}
+	policies := map[string]config.Policy{
+		"test": {Instruction: "Check", Enabled: true},
+	}
+
+	for i := 0; i < 2; i++ {
+		for range ta.AnalyzeProgressive(context.Background(), artifacts, policies, "persona") {
+		}
+	}
+
+	// A cache set with WithTieredCache serves the second run's
+	// comprehensive tier instead of calling the model again
+	if calls := ta.Stats().ComprehensiveCalls; calls != 1 {
+		t.Errorf("expected 1 comprehensive call, got %d", calls)
+	}
+	if n := mock.callCount.Load(); n != 1 {
+		t.Errorf("expected 1 model call, got %d", n)
+	}
+}
+
+func TestTieredAnalyzer_DiskCacheSkipsModelOnNextRun(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := []input.Artifact{{
+		Path:    "test.go",
+		Content: "package main",
+		Kind:    input.KindFile,
+	}}
+	policies := map[string]config.Policy{
+		"test": {Instruction: "Check", Enabled: true},
+	}
+	run := func() (*tieredMockClient, []sarif.Result) {
+		t.Helper()
+		dc, err := cache.NewDiskCache(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock := &tieredMockClient{findings: []Finding{
+			{RuleID: "llm-rule", Level: "warning", Message: "llm", StartLine: 1, EndLine: 1, Confidence: 0.9},
+		}}
+		results, err := NewTieredAnalyzer(mock, WithTieredCache(dc)).Analyze(context.Background(), artifacts, policies, "persona")
+		if err != nil {
+			t.Fatalf("Analyze: %v", err)
+		}
+		return mock, results
+	}
+
+	first, _ := run()
+	if first.callCount.Load() != 1 {
+		t.Fatalf("expected the first run to call the model once, got %d", first.callCount.Load())
+	}
+
+	second, results := run()
+	if second.callCount.Load() != 0 {
+		t.Errorf("expected the second run to be served from disk, got %d model calls", second.callCount.Load())
+	}
+	var found bool
+	for _, r := range results {
+		if r.RuleID == "llm-rule" && r.Properties["gavel/tier"] == "comprehensive" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the cached comprehensive finding, got %+v", results)
+	}
+}
+
 func TestTier_String(t *testing.T) {
 	tests := []struct {
 		tier Tier
@@ -488,7 +741,7 @@ func process() {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		results := ta.runPatternMatching(artifact)
+		results := ta.runPatternMatching(context.Background(), artifact)
 		_ = results
 	}
 }
@@ -514,3 +767,207 @@ func BenchmarkTieredAnalyzer_ProgressiveAnalysis(b *testing.B) {
 		}
 	}
 }
+
+func TestTieredAnalyzer_InstantTier_Allowlist(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+	rule := rules.Rule{
+		ID:                "magic",
+		Pattern:           regexp.MustCompile(`(?m)^\s*return (?P<value>\d+)$`),
+		Level:             "note",
+		Message:           "magic number",
+		Confidence:        0.5,
+		AllowlistPatterns: []*regexp.Regexp{regexp.MustCompile(`^(?:0|443)$`)},
+	}
+	ta := NewTieredAnalyzer(mock, WithInstantPatterns([]rules.Rule{rule}))
+
+	art := input.Artifact{
+		Path:    "test.go",
+		Content: "func a() int {\n\treturn 0\n}\nfunc b() int {\n\treturn 443\n}\nfunc c() int {\n\treturn 1234\n}\n",
+		Kind:    input.KindFile,
+	}
+
+	results := ta.RunPatternMatching(context.Background(), art)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(results))
+	}
+	if line := results[0].Locations[0].PhysicalLocation.Region.StartLine; line != 8 {
+		t.Errorf("expected finding on line 8, got %d", line)
+	}
+}
+
+func TestTieredAnalyzer_InstantTier_PathScopedRule(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+	rule := rules.Rule{
+		ID:           "auth-skip-verify",
+		Pattern:      regexp.MustCompile(`InsecureSkipVerify: true`),
+		Level:        "error",
+		Message:      "TLS verification disabled",
+		Confidence:   0.9,
+		IncludePaths: []string{"internal/auth/**"},
+	}
+	ta := NewTieredAnalyzer(mock, WithInstantPatterns([]rules.Rule{rule}))
+
+	content := "package x\n\nvar cfg = tls.Config{InsecureSkipVerify: true}\n"
+	for _, tc := range []struct {
+		path string
+		want int
+	}{
+		{"internal/auth/client.go", 1},
+		{"internal/server/client.go", 0},
+	} {
+		art := input.Artifact{Path: tc.path, Content: content, Kind: input.KindFile}
+		if got := len(ta.RunPatternMatching(context.Background(), art)); got != tc.want {
+			t.Errorf("%s: expected %d findings, got %d", tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestTieredAnalyzer_InstantTier_RuleFix(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+	content := "package x\n\nfunc h() hash.Hash {\n\treturn md5.New()\n}\n\nfunc debug() {\n\tfmt.Println(\"here\")\n}\n"
+
+	tests := []struct {
+		name string
+		rule rules.Rule
+		want string
+	}{
+		{
+			name: "replacement",
+			rule: rules.Rule{
+				ID:      "weak-hash",
+				Pattern: regexp.MustCompile(`(\w+)\.New\(\)`),
+				Fix:     &rules.RuleFix{Replacement: "sha256.New() // was $1"},
+			},
+			want: "package x\n\nfunc h() hash.Hash {\n\treturn sha256.New() // was md5\n}\n\nfunc debug() {\n\tfmt.Println(\"here\")\n}\n",
+		},
+		{
+			name: "delete",
+			rule: rules.Rule{
+				ID:      "debug-print",
+				Pattern: regexp.MustCompile(`(?m)^\s*fmt\.Println\(.*\)\n`),
+				Fix:     &rules.RuleFix{Delete: true},
+			},
+			want: "package x\n\nfunc h() hash.Hash {\n\treturn md5.New()\n}\n\nfunc debug() {\n}\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.rule.Level = "warning"
+			tc.rule.Message = "finding"
+			tc.rule.Confidence = 0.9
+			ta := NewTieredAnalyzer(mock, WithInstantPatterns([]rules.Rule{tc.rule}))
+
+			results := ta.RunPatternMatching(context.Background(), input.Artifact{Path: "x.go", Content: content, Kind: input.KindFile})
+			if len(results) != 1 || len(results[0].Fixes) != 1 {
+				t.Fatalf("expected 1 finding with a fix, got %+v", results)
+			}
+			f := results[0].Fixes[0]
+			if f.Description.Text != "finding" {
+				t.Errorf("expected description to fall back to the message, got %q", f.Description.Text)
+			}
+			if uri := f.ArtifactChanges[0].ArtifactLocation.URI; uri != "x.go" {
+				t.Errorf("expected fix for x.go, got %q", uri)
+			}
+			got, err := fix.ApplyToContent(content, f.ArtifactChanges[0].Replacements)
+			if err != nil {
+				t.Fatalf("ApplyToContent: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("fixed content = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTieredAnalyzer_InstantTier_DependencyRule(t *testing.T) {
+	mock := &tieredMockClient{findings: []Finding{}}
+	banned := rules.Rule{
+		ID:         "no-pkg-errors",
+		Type:       rules.RuleTypeDependency,
+		Level:      "warning",
+		Message:    "Use the standard library's errors package",
+		Confidence: 1.0,
+		Dependency: &rules.DependencyConstraint{Packages: []string{"github.com/pkg/errors"}},
+	}
+	// Matches everything, to show regex rules do not run on an SBOM
+	anything := rules.Rule{
+		ID:         "anything",
+		Pattern:    regexp.MustCompile(`.+`),
+		Level:      "note",
+		Message:    "any line",
+		Confidence: 0.5,
+	}
+	ta := NewTieredAnalyzer(mock, WithInstantPatterns([]rules.Rule{banned}))
+
+	gomod := input.Artifact{
+		Path:    "go.mod",
+		Content: "module example.com/app\n\ngo 1.22\n\nrequire (\n\tgithub.com/google/uuid v1.6.0\n\tgithub.com/pkg/errors v0.9.1\n)\n",
+		Kind:    input.KindFile,
+	}
+	results := ta.RunPatternMatching(context.Background(), gomod)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(results))
+	}
+	r := results[0]
+	if line := r.Locations[0].PhysicalLocation.Region.StartLine; line != 7 {
+		t.Errorf("expected finding on line 7, got %d", line)
+	}
+	if want := "Use the standard library's errors package: github.com/pkg/errors v0.9.1 is banned"; r.Message.Text != want {
+		t.Errorf("message = %q, want %q", r.Message.Text, want)
+	}
+	if r.Properties["gavel/dependency"] != "github.com/pkg/errors" || r.Properties["gavel/category"] != "dependency" {
+		t.Errorf("unexpected properties: %v", r.Properties)
+	}
+
+	// Source files declare no dependencies
+	if got := ta.RunPatternMatching(context.Background(), input.Artifact{Path: "main.go", Content: "package main\n", Kind: input.KindFile}); len(got) != 0 {
+		t.Errorf("expected no findings for a source file, got %d", len(got))
+	}
+
+	ta.SetPatterns([]rules.Rule{banned, anything})
+	sbom := input.Artifact{
+		Path:    "bom.cdx",
+		Content: `{"bomFormat": "CycloneDX", "components": [{"name": "errors", "purl": "pkg:golang/github.com/pkg/errors@v0.9.1"}]}`,
+		Kind:    input.KindSBOM,
+	}
+	results = ta.RunPatternMatching(context.Background(), sbom)
+	if len(results) != 1 || results[0].RuleID != "no-pkg-errors" {
+		t.Errorf("expected only the dependency finding for an SBOM, got %+v", results)
+	}
+}
+
+func TestTieredAnalyzer_DeserializationReportedOnce(t *testing.T) {
+	ta := NewTieredAnalyzer(nil)
+	artifacts := []input.Artifact{
+		{Path: "load.py", Content: "import pickle\n\nobj = pickle.loads(data)\n", Kind: input.KindFile},
+		{Path: "load.js", Content: "const cfg = eval(jsonText);\n", Kind: input.KindFile},
+	}
+	policies := map[string]config.Policy{
+		"test": {Instruction: "Check", Enabled: true},
+	}
+
+	results, err := ta.Analyze(context.Background(), artifacts, policies, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// S5135 and S1523 cover these calls; AST018 must not report them again
+	got := make(map[string][]string)
+	for _, r := range results {
+		switch r.RuleID {
+		case "S5135", "S1523", "AST018":
+			loc := r.Locations[0].PhysicalLocation
+			key := fmt.Sprintf("%s:%d", loc.ArtifactLocation.URI, loc.Region.StartLine)
+			got[key] = append(got[key], r.RuleID)
+		}
+	}
+	want := map[string]string{"load.py:3": "S5135", "load.js:1": "S1523"}
+	for key, id := range want {
+		if ids := got[key]; len(ids) != 1 || ids[0] != id {
+			t.Errorf("%s: expected a single %s finding, got %v", key, id, ids)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("unexpected deserialization findings: %v", got)
+	}
+}
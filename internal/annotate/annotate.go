@@ -0,0 +1,138 @@
+// Package annotate writes review copies of analyzed files with findings
+// inserted as comments above the lines they refer to. Originals are never
+// modified: copies go to a separate output directory that mirrors the
+// source layout.
+package annotate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// commentPrefix returns the line-comment marker for path's language, as
+// identified by astcheck.Detect.
+func commentPrefix(path string) (string, bool) {
+	_, lang, ok := astcheck.Detect(path)
+	if !ok {
+		return "", false
+	}
+	if lang == "python" || lang == "ruby" {
+		return "#", true
+	}
+	return "//", true
+}
+
+// Written reports the outcome of Write.
+type Written struct {
+	Files   []string // annotated copies that were written, sorted
+	Skipped []string // files with findings whose language has no known comment syntax
+}
+
+// Write creates an annotated copy under outDir of every file artifact with
+// at least one active finding (not suppressed, informational or resolved
+// since a baseline). Artifact paths are mirrored beneath outDir; absolute
+// paths are first made relative to root. Paths that would land outside
+// outDir are rejected, as is an outDir that is the source root itself.
+func Write(outDir, root string, artifacts []input.Artifact, results []sarif.Result) (Written, error) {
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		return Written{}, fmt.Errorf("resolving %s: %w", outDir, err)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return Written{}, fmt.Errorf("resolving %s: %w", root, err)
+	}
+	if absOut == absRoot {
+		return Written{}, fmt.Errorf("annotate directory %s must differ from the source directory", outDir)
+	}
+
+	byFile := make(map[string][]sarif.Result)
+	for _, r := range results {
+		if len(r.Suppressions) > 0 || r.BaselineState == sarif.BaselineStateAbsent || r.Kind == sarif.KindInformational || len(r.Locations) == 0 {
+			continue
+		}
+		uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+		byFile[uri] = append(byFile[uri], r)
+	}
+
+	var w Written
+	for _, art := range artifacts {
+		found := byFile[art.Path]
+		if art.Kind != input.KindFile || len(found) == 0 {
+			continue
+		}
+		prefix, ok := commentPrefix(art.Path)
+		if !ok {
+			w.Skipped = append(w.Skipped, art.Path)
+			continue
+		}
+
+		rel := art.Path
+		if filepath.IsAbs(rel) {
+			if rel, err = filepath.Rel(absRoot, rel); err != nil {
+				return Written{}, fmt.Errorf("relativizing %s: %w", art.Path, err)
+			}
+		}
+		dest := filepath.Join(absOut, filepath.Clean(rel))
+		if dest == absOut || !strings.HasPrefix(dest, absOut+string(filepath.Separator)) {
+			return Written{}, fmt.Errorf("%s would be written outside %s", art.Path, outDir)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return Written{}, fmt.Errorf("creating %s: %w", filepath.Dir(dest), err)
+		}
+		if err := os.WriteFile(dest, []byte(Annotate(art.Content, prefix, found)), 0o644); err != nil {
+			return Written{}, fmt.Errorf("writing %s: %w", dest, err)
+		}
+		w.Files = append(w.Files, dest)
+	}
+	sort.Strings(w.Files)
+	sort.Strings(w.Skipped)
+	return w, nil
+}
+
+// Annotate returns content with one comment per finding inserted above the
+// finding's start line, indented to match that line. Findings on the same
+// line keep their input order; findings without a valid line are placed
+// above line 1.
+func Annotate(content, prefix string, results []sarif.Result) string {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	above := make(map[int][]sarif.Result)
+	for _, r := range results {
+		line := 1
+		if len(r.Locations) > 0 {
+			line = r.Locations[0].PhysicalLocation.Region.StartLine
+		}
+		if line < 1 || line > len(lines) {
+			line = 1
+		}
+		above[line] = append(above[line], r)
+	}
+
+	var b strings.Builder
+	for i, l := range lines {
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		for _, r := range above[i+1] {
+			fmt.Fprintf(&b, "%s%s gavel: [%s] %s: %s\n", indent, prefix, r.RuleID, r.Level, oneLine(r.Message.Text))
+		}
+		b.WriteString(l)
+	}
+	return b.String()
+}
+
+// oneLine collapses whitespace runs, including newlines, so a message fits
+// in a single-line comment.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
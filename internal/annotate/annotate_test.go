@@ -0,0 +1,122 @@
+package annotate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func finding(ruleID, uri string, line int, msg string) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   "warning",
+		Message: sarif.Message{Text: msg},
+		Locations: []sarif.Location{{
+			PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+				Region:           sarif.Region{StartLine: line, EndLine: line},
+			},
+		}},
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	content := "package x\n\nfunc f() {\n\tpassword := \"hunter2\"\n\t_ = password\n}\n"
+	got := Annotate(content, "//", []sarif.Result{
+		finding("S2068", "x.go", 4, "Hardcoded\npassword"),
+		finding("S1135", "x.go", 4, "second"),
+	})
+	want := "package x\n\nfunc f() {\n" +
+		"\t// gavel: [S2068] warning: Hardcoded password\n" +
+		"\t// gavel: [S1135] warning: second\n" +
+		"\tpassword := \"hunter2\"\n\t_ = password\n}\n"
+	if got != want {
+		t.Errorf("Annotate mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAnnotate_OutOfRangeLineGoesFirst(t *testing.T) {
+	got := Annotate("a = 1\n", "#", []sarif.Result{finding("R1", "a.py", 99, "m")})
+	if got != "# gavel: [R1] warning: m\na = 1\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	original := "def f():\n    eval(x)\n"
+	srcPath := filepath.Join(root, "pkg", "mod.py")
+	if err := os.MkdirAll(filepath.Dir(srcPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(srcPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	suppressed := finding("SUPP", srcPath, 1, "suppressed")
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	artifacts := []input.Artifact{
+		{Path: srcPath, Content: original, Kind: input.KindFile},
+		{Path: filepath.Join(root, "clean.go"), Content: "package clean\n", Kind: input.KindFile},
+		{Path: filepath.Join(root, "notes.txt"), Content: "todo\n", Kind: input.KindFile},
+	}
+	results := []sarif.Result{
+		finding("S1523", srcPath, 2, "eval is dangerous"),
+		suppressed,
+		finding("TXT1", filepath.Join(root, "notes.txt"), 1, "prose"),
+	}
+
+	outDir := filepath.Join(root, "annotated")
+	w, err := Write(outDir, root, artifacts, results)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	dest := filepath.Join(outDir, "pkg", "mod.py")
+	if len(w.Files) != 1 || w.Files[0] != dest {
+		t.Fatalf("expected only %s to be written, got %v", dest, w.Files)
+	}
+	if len(w.Skipped) != 1 || !strings.HasSuffix(w.Skipped[0], "notes.txt") {
+		t.Errorf("expected notes.txt to be skipped, got %v", w.Skipped)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	if lines[1] != "    # gavel: [S1523] warning: eval is dangerous" || lines[2] != "    eval(x)" {
+		t.Errorf("expected comment directly above the eval line, got:\n%s", data)
+	}
+	if strings.Contains(string(data), "SUPP") {
+		t.Error("suppressed findings should not be annotated")
+	}
+
+	after, err := os.ReadFile(srcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != original {
+		t.Errorf("original file was modified:\n%s", after)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "clean.go")); !os.IsNotExist(err) {
+		t.Error("files without findings should not be copied")
+	}
+}
+
+func TestWrite_RefusesSourceDirAndEscapes(t *testing.T) {
+	root := t.TempDir()
+	art := input.Artifact{Path: "../x.go", Content: "package x\n", Kind: input.KindFile}
+	results := []sarif.Result{finding("R", "../x.go", 1, "m")}
+
+	if _, err := Write(root, root, []input.Artifact{art}, results); err == nil {
+		t.Error("expected an error when the output directory is the source root")
+	}
+	if _, err := Write(filepath.Join(root, "out"), root, []input.Artifact{art}, results); err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Errorf("expected an error for a path escaping the output directory, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package astcheck
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	sitter "github.com/smacker/go-tree-sitter"
@@ -76,7 +77,7 @@ func TestRegistryBasics(t *testing.T) {
 func TestDefaultRegistry(t *testing.T) {
 	r := DefaultRegistry()
 	names := r.Names()
-	expected := []string{"empty-handler", "function-length", "nesting-depth", "param-count"}
+	expected := []string{"blocking-in-handler", "cognitive-complexity", "concurrent-map-write", "context-propagation", "cyclomatic-complexity", "duplicate-code", "empty-handler", "function-length", "goroutine-leak", "missing-auth-route", "nesting-depth", "param-count", "tainted-command", "tainted-path", "tainted-sql", "timing-unsafe-compare", "unbounded-read", "unsafe-deserialization"}
 	if len(names) != len(expected) {
 		t.Fatalf("expected %d checks, got %d: %v", len(expected), len(names), names)
 	}
@@ -107,6 +108,11 @@ func TestDetect(t *testing.T) {
 		{"lib.c", "c", true},
 		{"lib.h", "c", true},
 		{"main.rs", "rust", true},
+		{"app.rb", "ruby", true},
+		{"index.php", "php", true},
+		{"Main.kt", "kotlin", true},
+		{"build.gradle.kts", "kotlin", true},
+		{"Program.cs", "csharp", true},
 		{"readme.md", "", false},
 		{"data.json", "", false},
 		{"/path/to/file.GO", "go", true}, // case insensitive
@@ -387,6 +393,90 @@ func main() {
 	}
 }
 
+func TestNestingDepthGoSelectAndSwitch(t *testing.T) {
+	src := `package main
+
+func main() {
+	for {
+		select {
+		case v := <-ch:
+			switch x := v.(type) {
+			case int:
+				switch {
+				case x > 0:
+					if x > 10 {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+`
+	tree := parseGo(t, src)
+	c := &NestingDepth{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match for nesting through select/switch, got %d", len(matches))
+	}
+	if matches[0].Extra["depth"] != 5 {
+		t.Errorf("expected depth 5, got %v", matches[0].Extra["depth"])
+	}
+	if matches[0].StartLine != 11 {
+		t.Errorf("expected match on line 11, got %d", matches[0].StartLine)
+	}
+}
+
+func TestNestingDepthGoShallowSwitch(t *testing.T) {
+	src := `package main
+
+func main() {
+	select {
+	case <-done:
+		switch mode {
+		case 1:
+			return
+		}
+	}
+}
+`
+	tree := parseGo(t, src)
+	c := &NestingDepth{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 0 {
+		t.Errorf("expected no matches for shallow select/switch, got %d", len(matches))
+	}
+}
+
+func TestNestingDepthGoCountCase(t *testing.T) {
+	src := `package main
+
+func main() {
+	switch mode {
+	case 1:
+		if ready {
+			return
+		}
+	}
+}
+`
+	tree := parseGo(t, src)
+	c := &NestingDepth{}
+
+	matches := c.Run(tree, []byte(src), "go", map[string]interface{}{"max_depth": 2})
+	if len(matches) != 0 {
+		t.Errorf("expected no matches without count_case, got %d", len(matches))
+	}
+
+	matches = c.Run(tree, []byte(src), "go", map[string]interface{}{"max_depth": 2, "count_case": true})
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match with count_case, got %d", len(matches))
+	}
+	if matches[0].Extra["depth"] != 3 {
+		t.Errorf("expected depth 3, got %v", matches[0].Extra["depth"])
+	}
+}
+
 func TestNestingDepthUnknownLang(t *testing.T) {
 	tree := parseGo(t, "package main")
 	c := &NestingDepth{}
@@ -678,52 +768,1228 @@ func ok(a int) {
 }
 
 // ---------------------------------------------------------------------------
-// Integration-style test: DefaultRegistry runs all checks
+// UnboundedRead tests
 // ---------------------------------------------------------------------------
 
-func TestDefaultRegistryRunsAllChecks(t *testing.T) {
-	reg := DefaultRegistry()
+func TestUnboundedReadName(t *testing.T) {
+	c := &UnboundedRead{}
+	if c.Name() != "unbounded-read" {
+		t.Errorf("expected name 'unbounded-read', got %q", c.Name())
+	}
+}
 
+func TestUnboundedReadRequestBody(t *testing.T) {
 	src := `package main
 
-import "fmt"
+func handler(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	_ = data
+	_ = err
+}
+`
+	tree := parseGo(t, src)
+	c := &UnboundedRead{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	if matches[0].StartLine != 4 {
+		t.Errorf("expected match on line 4, got %d", matches[0].StartLine)
+	}
+	if matches[0].Extra["source"] != "r.Body" {
+		t.Errorf("expected source r.Body, got %v", matches[0].Extra["source"])
+	}
+}
 
-func longFunc(a, b, c, d, e, f int) {
-` + repeatLines("\tx := 1\n", 55) + `}
+func TestUnboundedReadIoutil(t *testing.T) {
+	src := `package main
 
-func nested() {
-	if true {
-		if true {
-			if true {
-				if true {
-					if true {
-						return
-					}
-				}
-			}
+func fetch(resp *http.Response) {
+	ioutil.ReadAll(resp.Body)
+}
+`
+	tree := parseGo(t, src)
+	c := &UnboundedRead{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Errorf("expected 1 match, got %d", len(matches))
+	}
+}
+
+func TestUnboundedReadLimitReader(t *testing.T) {
+	src := `package main
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	_ = data
+	_ = err
+}
+`
+	tree := parseGo(t, src)
+	c := &UnboundedRead{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 0 {
+		t.Errorf("expected no matches for limited reader, got %d", len(matches))
+	}
+}
+
+func TestUnboundedReadMaxBytesReaderAssigned(t *testing.T) {
+	src := `package main
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	data, _ := io.ReadAll(r.Body)
+	_ = data
+}
+`
+	tree := parseGo(t, src)
+	c := &UnboundedRead{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 0 {
+		t.Errorf("expected no matches after MaxBytesReader, got %d", len(matches))
+	}
+}
+
+func TestUnboundedReadBoundedBuffer(t *testing.T) {
+	src := `package main
+
+func decode(payload []byte) {
+	buf := bytes.NewReader(payload)
+	data, _ := io.ReadAll(buf)
+	_ = data
+}
+`
+	tree := parseGo(t, src)
+	c := &UnboundedRead{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 0 {
+		t.Errorf("expected no matches for in-memory buffer, got %d", len(matches))
+	}
+}
+
+func TestUnboundedReadCustomSources(t *testing.T) {
+	src := `package main
+
+func consume(stream io.Reader) {
+	io.ReadAll(stream)
+}
+`
+	tree := parseGo(t, src)
+	c := &UnboundedRead{}
+	config := map[string]interface{}{"sources": []interface{}{"stream"}}
+	matches := c.Run(tree, []byte(src), "go", config)
+	if len(matches) != 1 {
+		t.Errorf("expected 1 match with custom sources, got %d", len(matches))
+	}
+}
+
+func TestUnboundedReadUnknownLang(t *testing.T) {
+	src := `data = request.body.read()`
+	tree := parsePython(t, src)
+	c := &UnboundedRead{}
+	matches := c.Run(tree, []byte(src), "python", nil)
+	if matches != nil {
+		t.Errorf("expected nil for non-Go language, got %v", matches)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ConcurrentMapWrite tests
+// ---------------------------------------------------------------------------
+
+func TestConcurrentMapWriteName(t *testing.T) {
+	c := &ConcurrentMapWrite{}
+	if c.Name() != "concurrent-map-write" {
+		t.Errorf("expected name 'concurrent-map-write', got %q", c.Name())
+	}
+}
+
+func TestConcurrentMapWriteUnsynchronized(t *testing.T) {
+	src := `package main
+
+var cache = map[string]int{}
+
+func fill(keys []string) {
+	for _, k := range keys {
+		go func(k string) {
+			cache[k] = len(k)
+		}(k)
+	}
+}
+`
+	tree := parseGo(t, src)
+	c := &ConcurrentMapWrite{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	if matches[0].StartLine != 8 {
+		t.Errorf("expected match on line 8, got %d", matches[0].StartLine)
+	}
+	if matches[0].Extra["map"] != "cache" {
+		t.Errorf("expected map 'cache', got %v", matches[0].Extra["map"])
+	}
+}
+
+func TestConcurrentMapWriteStructField(t *testing.T) {
+	src := `package main
+
+type Server struct {
+	sessions map[string]string
+}
+
+func (s *Server) track(id string) {
+	go func() {
+		delete(s.sessions, id)
+	}()
+}
+`
+	tree := parseGo(t, src)
+	c := &ConcurrentMapWrite{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match for struct field map, got %d", len(matches))
+	}
+	if matches[0].Extra["map"] != "s.sessions" {
+		t.Errorf("expected map 's.sessions', got %v", matches[0].Extra["map"])
+	}
+}
+
+func TestConcurrentMapWriteLocked(t *testing.T) {
+	src := `package main
+
+var cache = make(map[string]int)
+
+func fill(k string) {
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+		cache[k]++
+	}()
+}
+`
+	tree := parseGo(t, src)
+	c := &ConcurrentMapWrite{}
+	for _, config := range []map[string]interface{}{nil, {"strict": true}} {
+		matches := c.Run(tree, []byte(src), "go", config)
+		if len(matches) != 0 {
+			t.Errorf("expected no matches when goroutine locks (config %v), got %d", config, len(matches))
 		}
 	}
 }
 
-func emptyErr() {
-	var err error
-	if err != nil {
+func TestConcurrentMapWriteAssociatedMutex(t *testing.T) {
+	src := `package main
+
+type Cache struct {
+	mu    sync.Mutex
+	items map[string]int
+}
+
+func (c *Cache) warm(k string) {
+	go func() {
+		c.set(k)
+		c.items[k] = 1
+	}()
+}
+`
+	tree := parseGo(t, src)
+	c := &ConcurrentMapWrite{}
+
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 0 {
+		t.Errorf("expected no matches with a mutex in the struct by default, got %d", len(matches))
+	}
+
+	matches = c.Run(tree, []byte(src), "go", map[string]interface{}{"strict": true})
+	if len(matches) != 1 {
+		t.Errorf("expected 1 match in strict mode without Lock() in the goroutine, got %d", len(matches))
+	}
+}
+
+func TestConcurrentMapWriteSyncMap(t *testing.T) {
+	src := `package main
+
+var cache sync.Map
+
+func fill(k string) {
+	go func() {
+		cache.Store(k, 1)
+	}()
+}
+`
+	tree := parseGo(t, src)
+	c := &ConcurrentMapWrite{}
+	matches := c.Run(tree, []byte(src), "go", map[string]interface{}{"strict": true})
+	if len(matches) != 0 {
+		t.Errorf("expected no matches for sync.Map, got %d", len(matches))
 	}
 }
+
+func TestConcurrentMapWriteLocalMapIgnored(t *testing.T) {
+	src := `package main
+
+func fill() {
+	local := map[string]int{}
+	go func() {
+		local["x"] = 1
+	}()
+}
 `
 	tree := parseGo(t, src)
-	sourceBytes := []byte(src)
+	c := &ConcurrentMapWrite{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 0 {
+		t.Errorf("expected no matches for function-local map, got %d", len(matches))
+	}
+}
 
-	totalMatches := 0
-	for _, name := range reg.Names() {
-		check, _ := reg.Get(name)
-		matches := check.Run(tree, sourceBytes, "go", nil)
-		totalMatches += len(matches)
+// ---------------------------------------------------------------------------
+// TimingUnsafeCompare tests
+// ---------------------------------------------------------------------------
+
+func TestTimingUnsafeCompareName(t *testing.T) {
+	c := &TimingUnsafeCompare{}
+	if c.Name() != "timing-unsafe-compare" {
+		t.Errorf("expected name 'timing-unsafe-compare', got %q", c.Name())
 	}
+}
 
-	// We expect at least 1 match from function-length, nesting-depth, empty-handler, param-count
-	if totalMatches < 4 {
-		t.Errorf("expected at least 4 total matches across all checks, got %d", totalMatches)
+func TestTimingUnsafeCompareHMACNamed(t *testing.T) {
+	src := `package main
+
+func verify(hmacSum, expected []byte) bool {
+	if string(hmacSum) == string(expected) {
+		return true
+	}
+	return false
+}
+`
+	tree := parseGo(t, src)
+	c := &TimingUnsafeCompare{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	if matches[0].StartLine != 4 {
+		t.Errorf("expected match on line 4, got %d", matches[0].StartLine)
+	}
+	if matches[0].Extra["operand"] != "string(hmacSum)" {
+		t.Errorf("expected operand string(hmacSum), got %v", matches[0].Extra["operand"])
+	}
+}
+
+func TestTimingUnsafeCompareConstantTime(t *testing.T) {
+	src := `package main
+
+func verify(mac, expected []byte, token, want string) bool {
+	return hmac.Equal(mac, expected) &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+`
+	tree := parseGo(t, src)
+	c := &TimingUnsafeCompare{}
+	if matches := c.Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected no matches for constant-time comparisons, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestTimingUnsafeCompareDerivedFromHMAC(t *testing.T) {
+	src := `package main
+
+func verify(key, body []byte, header string) bool {
+	h := hmac.New(sha256.New, key)
+	h.Write(body)
+	computed := h.Sum(nil)
+	if hex.EncodeToString(computed) != header {
+		return false
+	}
+	return bytes.Equal(h.Sum(nil), []byte(header))
+}
+`
+	tree := parseGo(t, src)
+	c := &TimingUnsafeCompare{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	// hex.EncodeToString(computed) is a call that hides the operand; the
+	// bytes.Equal on h.Sum(nil) is caught through the hmac.New hasher.
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %v", len(matches), matches)
+	}
+	if matches[0].Extra["comparison"] != "bytes.Equal" {
+		t.Errorf("expected bytes.Equal comparison, got %v", matches[0].Extra["comparison"])
+	}
+}
+
+func TestTimingUnsafeCompareDerivedVariable(t *testing.T) {
+	src := `package main
+
+func verify(key, body, received []byte) bool {
+	h := hmac.New(sha256.New, key)
+	h.Write(body)
+	sum := h.Sum(nil)
+	return string(sum) == string(received)
+}
+`
+	tree := parseGo(t, src)
+	c := &TimingUnsafeCompare{}
+	if matches := c.Run(tree, []byte(src), "go", nil); len(matches) != 1 {
+		t.Errorf("expected 1 match for Sum-derived variable, got %d", len(matches))
+	}
+}
+
+func TestTimingUnsafeCompareIgnoresTrivialAndUnrelated(t *testing.T) {
+	src := `package main
+
+func check(r *Request, count int, name string) bool {
+	if r.Token == "" || len(r.Token) == 0 || r.APISecret == nil {
+		return false
+	}
+	return count == 3 && name == r.Username
+}
+`
+	tree := parseGo(t, src)
+	c := &TimingUnsafeCompare{}
+	if matches := c.Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected no matches, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestTimingUnsafeCompareIgnoresAmbiguousNames(t *testing.T) {
+	src := `package main
+
+func wait(sigs chan os.Signal) bool {
+	sig := <-sigs
+	return sig == os.Interrupt
+}
+
+func sameTarget(p, target Policy) bool {
+	return p.Digest != target.Digest
+}
+`
+	tree := parseGo(t, src)
+	c := &TimingUnsafeCompare{}
+	if matches := c.Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected no matches for signals and content digests, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestTimingUnsafeCompareCustomNames(t *testing.T) {
+	src := `package main
+
+func check(apiKey, provided string) bool {
+	return apiKey == provided
+}
+`
+	tree := parseGo(t, src)
+	c := &TimingUnsafeCompare{}
+	if matches := c.Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Fatalf("expected no matches with default names, got %d", len(matches))
+	}
+	config := map[string]interface{}{"names": []interface{}{"key"}}
+	if matches := c.Run(tree, []byte(src), "go", config); len(matches) != 1 {
+		t.Errorf("expected 1 match with custom names, got %d", len(matches))
+	}
+}
+
+func TestIdentifierWords(t *testing.T) {
+	tests := map[string][]string{
+		"expectedHMACSig": {"expected", "hmac", "sig"},
+		"csrf_token":      {"csrf", "token"},
+		"Machine":         {"machine"},
+		"sha256Digest":    {"sha", "digest"},
+	}
+	for in, want := range tests {
+		got := identifierWords(in)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("identifierWords(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestTimingUnsafeCompareUnknownLang(t *testing.T) {
+	c := &TimingUnsafeCompare{}
+	if matches := c.Run(nil, nil, "python", nil); matches != nil {
+		t.Errorf("expected nil for non-Go source, got %v", matches)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Integration-style test: DefaultRegistry runs all checks
+// ---------------------------------------------------------------------------
+
+func TestDefaultRegistryRunsAllChecks(t *testing.T) {
+	reg := DefaultRegistry()
+
+	src := `package main
+
+import "fmt"
+
+func longFunc(a, b, c, d, e, f int) {
+` + repeatLines("\tx := 1\n", 55) + `}
+
+func nested() {
+	if true {
+		if true {
+			if true {
+				if true {
+					if true {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func emptyErr() {
+	var err error
+	if err != nil {
+	}
+}
+`
+	tree := parseGo(t, src)
+	sourceBytes := []byte(src)
+
+	totalMatches := 0
+	for _, name := range reg.Names() {
+		check, _ := reg.Get(name)
+		matches := check.Run(tree, sourceBytes, "go", nil)
+		totalMatches += len(matches)
+	}
+
+	// We expect at least 1 match from function-length, nesting-depth, empty-handler, param-count
+	if totalMatches < 4 {
+		t.Errorf("expected at least 4 total matches across all checks, got %d", totalMatches)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Ruby, PHP, Kotlin and C# tests
+// ---------------------------------------------------------------------------
+
+// parseFile parses source with the grammar Detect picks for path and returns
+// the tree and language name.
+func parseFile(t *testing.T, path, source string) (*sitter.Tree, string) {
+	t.Helper()
+	lang, name, ok := Detect(path)
+	if !ok {
+		t.Fatalf("Detect(%q) did not recognize the file", path)
+	}
+	return parseWith(t, source, lang), name
+}
+
+func TestFunctionLengthOtherLanguages(t *testing.T) {
+	tests := []struct {
+		path, src, want string
+	}{
+		{"a.rb", "def process(a)\n  x = 1\n  y = 2\n  z = 3\nend\n", "process"},
+		{"a.php", "<?php\nfunction process($a) {\n  $x = 1;\n  $y = 2;\n  $z = 3;\n}\n", "process"},
+		{"a.kt", "fun process(a: Int) {\n    val x = 1\n    val y = 2\n    val z = 3\n}\n", "process"},
+		{"a.cs", "class C {\n  void Process(int a) {\n    int x = 1;\n    int y = 2;\n    int z = 3;\n  }\n}\n", "Process"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tree, lang := parseFile(t, tt.path, tt.src)
+			matches := (&FunctionLength{}).Run(tree, []byte(tt.src), lang, map[string]interface{}{"max_lines": 3})
+			if len(matches) != 1 {
+				t.Fatalf("expected 1 match, got %d", len(matches))
+			}
+			if got := matches[0].Extra["function"]; got != tt.want {
+				t.Errorf("function = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNestingDepthOtherLanguages(t *testing.T) {
+	tests := []struct {
+		path, src string
+	}{
+		{"a.rb", "def f\n  if a\n    while b\n      case c\n      when 1 then d\n      end\n    end\n  end\nend\n"},
+		{"a.php", "<?php\nfunction f() {\n  if ($a) {\n    foreach ($b as $c) {\n      while ($d) { }\n    }\n  }\n}\n"},
+		{"a.kt", "fun f() {\n    if (a) {\n        for (c in b) {\n            when (c) { else -> {} }\n        }\n    }\n}\n"},
+		{"a.cs", "class C {\n  void F() {\n    if (a) {\n      foreach (var c in b) {\n        switch (c) { default: break; }\n      }\n    }\n  }\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tree, lang := parseFile(t, tt.path, tt.src)
+			matches := (&NestingDepth{}).Run(tree, []byte(tt.src), lang, map[string]interface{}{"max_depth": 2})
+			if len(matches) != 1 {
+				t.Fatalf("expected 1 match, got %d", len(matches))
+			}
+			if got := matches[0].Extra["depth"]; got != 3 {
+				t.Errorf("depth = %v, want 3", got)
+			}
+		})
+	}
+}
+
+func TestEmptyHandlerOtherLanguages(t *testing.T) {
+	tests := []struct {
+		path, src string
+		wantLine  int
+	}{
+		{"a.rb", "begin\n  a\nrescue StandardError\nend\nbegin\n  b\nrescue StandardError => e\n  log(e)\nend\n", 3},
+		{"a.php", "<?php\ntry { a(); } catch (Exception $e) { }\ntry { b(); } catch (Exception $e) { log($e); }\n", 2},
+		{"a.kt", "fun f() {\n    try { a() } catch (e: Exception) { }\n    try { b() } catch (e: Exception) { log(e) }\n}\n", 2},
+		{"a.cs", "class C {\n  void F() {\n    try { A(); } catch (Exception e) { }\n    try { B(); } catch { Log(); }\n  }\n}\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tree, lang := parseFile(t, tt.path, tt.src)
+			matches := (&EmptyHandler{}).Run(tree, []byte(tt.src), lang, nil)
+			if len(matches) != 1 {
+				t.Fatalf("expected 1 match for the empty handler only, got %d", len(matches))
+			}
+			if matches[0].StartLine != tt.wantLine {
+				t.Errorf("StartLine = %d, want %d", matches[0].StartLine, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestParamCountOtherLanguages(t *testing.T) {
+	tests := []struct {
+		path, src string
+		want      int
+	}{
+		{"a.rb", "def f(a, b = 1, *c, d:, **e, &f)\nend\n", 6},
+		{"a.php", "<?php\nfunction f(int $a, $b = 1, ...$c) {}\n", 3},
+		{"a.kt", "fun f(a: Int, b: String = \"x\", vararg c: Int) {}\n", 3},
+		{"a.cs", "class C {\n  void F(int a, string b = \"x\", params int[] c) {}\n}\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tree, lang := parseFile(t, tt.path, tt.src)
+			matches := (&ParamCount{}).Run(tree, []byte(tt.src), lang, map[string]interface{}{"max_params": 2})
+			if len(matches) != 1 {
+				t.Fatalf("expected 1 match, got %d", len(matches))
+			}
+			if got := matches[0].Extra["param_count"]; got != tt.want {
+				t.Errorf("param_count = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// MissingAuthRoute tests
+// ---------------------------------------------------------------------------
+
+func TestMissingAuthRouteName(t *testing.T) {
+	c := &MissingAuthRoute{}
+	if c.Name() != "missing-auth-route" {
+		t.Errorf("expected name 'missing-auth-route', got %q", c.Name())
+	}
+}
+
+func TestMissingAuthRouteAdminWithoutAuth(t *testing.T) {
+	src := `package main
+
+func routes(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", health)
+	mux.HandleFunc("/admin/users", deleteUser)
+}
+
+func deleteUser(w http.ResponseWriter, r *http.Request) {
+	db.Delete(r.URL.Query().Get("id"))
+}
+`
+	tree := parseGo(t, src)
+	c := &MissingAuthRoute{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %v", len(matches), matches)
+	}
+	if matches[0].StartLine != 5 {
+		t.Errorf("expected match on line 5, got %d", matches[0].StartLine)
+	}
+	if matches[0].Extra["route"] != "/admin/users" || matches[0].Extra["pattern"] != "/admin" {
+		t.Errorf("unexpected extra: %v", matches[0].Extra)
+	}
+}
+
+func TestMissingAuthRouteWrappedInMiddleware(t *testing.T) {
+	src := `package main
+
+func routes(mux *http.ServeMux) {
+	mux.Handle("/admin/users", requireAuth(http.HandlerFunc(deleteUser)))
+	mux.Handle("GET /internal/stats", middleware.BasicAuth(statsHandler))
+}
+`
+	tree := parseGo(t, src)
+	c := &MissingAuthRoute{}
+	if matches := c.Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected no matches for wrapped handlers, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestMissingAuthRouteGuardedByUseOrHandlerBody(t *testing.T) {
+	src := `package main
+
+func routes(r chi.Router) {
+	r.Use(jwtauth.Verifier(tokenAuth))
+	r.Get("/admin", adminIndex)
+}
+
+func otherRoutes(mux *http.ServeMux, h *Handlers) {
+	mux.HandleFunc("/admin/reports", h.Reports)
+}
+
+func (h *Handlers) Reports(w http.ResponseWriter, r *http.Request) {
+	if !h.sessions.IsAdmin(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+}
+`
+	tree := parseGo(t, src)
+	c := &MissingAuthRoute{}
+	if matches := c.Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected no matches, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestMissingAuthRouteSegmentMatching(t *testing.T) {
+	src := `package main
+
+func routes(mux *http.ServeMux) {
+	mux.HandleFunc("/administrators", list)
+	mux.HandleFunc("/api/internal/", dump)
+}
+`
+	tree := parseGo(t, src)
+	c := &MissingAuthRoute{}
+	matches := c.Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %v", len(matches), matches)
+	}
+	if matches[0].Extra["route"] != "/api/internal/" {
+		t.Errorf("expected /api/internal/ to be flagged, got %v", matches[0].Extra["route"])
+	}
+}
+
+func TestMissingAuthRouteCustomConfig(t *testing.T) {
+	src := `package main
+
+func routes(e *echo.Echo) {
+	e.GET("/ops/restart", restart)
+	e.GET("/ops/status", status, guard.Check)
+}
+`
+	tree := parseGo(t, src)
+	c := &MissingAuthRoute{}
+	config := map[string]interface{}{
+		"paths":           []interface{}{"/ops"},
+		"auth_indicators": []interface{}{"guard"},
+	}
+	matches := c.Run(tree, []byte(src), "go", config)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %v", len(matches), matches)
+	}
+	if matches[0].Extra["route"] != "/ops/restart" {
+		t.Errorf("expected /ops/restart, got %v", matches[0].Extra["route"])
+	}
+}
+
+func TestMissingAuthRouteUnknownLang(t *testing.T) {
+	src := `app.get("/admin", handler)`
+	tree := parseJS(t, src)
+	c := &MissingAuthRoute{}
+	if matches := c.Run(tree, []byte(src), "javascript", nil); len(matches) != 0 {
+		t.Errorf("expected no matches for non-Go source, got %d", len(matches))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Complexity tests
+// ---------------------------------------------------------------------------
+
+func TestComplexityNames(t *testing.T) {
+	if got := (&CyclomaticComplexity{}).Name(); got != "cyclomatic-complexity" {
+		t.Errorf("expected 'cyclomatic-complexity', got %q", got)
+	}
+	if got := (&CognitiveComplexity{}).Name(); got != "cognitive-complexity" {
+		t.Errorf("expected 'cognitive-complexity', got %q", got)
+	}
+}
+
+// complexityOf runs check with a zero threshold, so every function is
+// reported, and returns the score of the function named name.
+func complexityOf(t *testing.T, check Check, path, src, name string) int {
+	t.Helper()
+	tree, lang := parseFile(t, path, src)
+	for _, m := range check.Run(tree, []byte(src), lang, map[string]interface{}{"max_complexity": 0}) {
+		if m.Extra["function"] == name {
+			return m.Extra["complexity"].(int)
+		}
+	}
+	t.Fatalf("%s: no %s match for %q", path, check.Name(), name)
+	return 0
+}
+
+func TestComplexityScores(t *testing.T) {
+	tests := []struct {
+		path, src             string
+		cyclomatic, cognitive int
+	}{
+		{"a.go", `package p
+
+func f(a, b int) int {
+	if a > 0 && b > 0 {
+		for i := 0; i < a; i++ {
+			if i == b {
+				return i
+			}
+		}
+	} else if a < 0 || b < 0 {
+		return -1
+	} else {
+		return 0
+	}
+	switch a {
+	case 1:
+		return 1
+	case 2:
+		return 2
+	default:
+	}
+	return b
+}
+`, 9, 11},
+		{"a.py", "def f(a, b):\n    if a and b:\n        for x in a:\n            pass\n    elif b:\n        pass\n    else:\n        pass\n    return a if b else None\n", 6, 7},
+		{"a.js", `function f(a) {
+  if (a) {
+    return 1;
+  } else if (a > 2) {
+    return 2;
+  } else {
+    if (a < 0) {
+      return 3;
+    }
+  }
+  const g = () => { if (a) { return 1; } };
+  return a && b && c;
+}
+`, 6, 6},
+		{"A.java", "class A {\n  void f() {\n    for (int i = 0; i < n; i++) {\n      if (x && y || z) { }\n    }\n  }\n}\n", 5, 5},
+		{"a.kt", "fun f(x: Int): Int {\n    if (x > 1) { return 1 } else if (x < 0) { return 2 } else { return 3 }\n    return when (x) { 1 -> 2\n else -> 3 }\n}\n", 4, 4},
+		{"a.rb", "def f(x)\n  if x\n    1\n  elsif y\n    2\n  else\n    3\n  end\nend\n", 3, 3},
+		{"a.cs", "class A {\n  int f(int x) {\n    switch (x) { case 1: return 1; default: return 0; }\n  }\n}\n", 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := complexityOf(t, &CyclomaticComplexity{}, tt.path, tt.src, "f"); got != tt.cyclomatic {
+				t.Errorf("cyclomatic complexity = %d, want %d", got, tt.cyclomatic)
+			}
+			if got := complexityOf(t, &CognitiveComplexity{}, tt.path, tt.src, "f"); got != tt.cognitive {
+				t.Errorf("cognitive complexity = %d, want %d", got, tt.cognitive)
+			}
+		})
+	}
+}
+
+func TestComplexityNestedElseIf(t *testing.T) {
+	// An if alone inside an else block is nested, not an else-if
+	src := "package p\n\nfunc f(a, b bool) {\n\tif a {\n\t} else {\n\t\tif b {\n\t\t}\n\t}\n}\n"
+	if got := complexityOf(t, &CognitiveComplexity{}, "a.go", src, "f"); got != 4 {
+		t.Errorf("cognitive complexity = %d, want 4", got)
+	}
+}
+
+func TestComplexityThreshold(t *testing.T) {
+	src := `package p
+
+func small(a bool) {
+	if a {
+	}
+}
+
+func branchy(a, b, c, d int) {
+	if a > 0 {
+	}
+	if b > 0 {
+	}
+	if c > 0 || d > 0 {
+	}
+}
+`
+	tree := parseGo(t, src)
+	if matches := (&CyclomaticComplexity{}).Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected no matches at the default threshold, got %v", matches)
+	}
+
+	matches := (&CyclomaticComplexity{}).Run(tree, []byte(src), "go", map[string]interface{}{"max_complexity": 3})
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %v", len(matches), matches)
+	}
+	m := matches[0]
+	if m.StartLine != 8 || m.EndLine != 15 {
+		t.Errorf("expected lines 8-15, got %d-%d", m.StartLine, m.EndLine)
+	}
+	if m.Extra["function"] != "branchy" || m.Extra["complexity"] != 5 || m.Extra["max_complexity"] != 3 {
+		t.Errorf("unexpected extra %v", m.Extra)
+	}
+	if m.Message != `function "branchy" has cyclomatic complexity 5 (max 3)` {
+		t.Errorf("unexpected message %q", m.Message)
+	}
+}
+
+func TestComplexityUnknownLang(t *testing.T) {
+	src := "package p\n\nfunc f() {}\n"
+	tree := parseGo(t, src)
+	if matches := (&CognitiveComplexity{}).Run(tree, []byte(src), "cobol", map[string]interface{}{"max_complexity": 0}); len(matches) != 0 {
+		t.Errorf("expected no matches for an unsupported language, got %d", len(matches))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Go context and goroutine tests
+// ---------------------------------------------------------------------------
+
+func TestGoContextCheckNames(t *testing.T) {
+	checks := map[string]Check{
+		"context-propagation": &ContextPropagation{},
+		"blocking-in-handler": &BlockingInHandler{},
+		"goroutine-leak":      &GoroutineLeak{},
+	}
+	for want, c := range checks {
+		if got := c.Name(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestContextPropagation(t *testing.T) {
+	src := `package main
+
+func fetch(ctx context.Context, db *sql.DB, url string) error {
+	req, _ := http.NewRequest("GET", url, nil)
+	rows, _ := db.Query("SELECT 1")
+	bg := context.Background()
+	ok, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	db.QueryContext(ctx, "SELECT 1")
+	work(ctx)
+	go func() {
+		exec.Command("ls").Run()
+	}()
+	return nil
+}
+
+func noContext(db *sql.DB) {
+	db.Query("SELECT 1")
+	context.Background()
+}
+
+func ignored(_ context.Context, db *sql.DB) {
+	db.Query("SELECT 1")
+}
+`
+	tree := parseGo(t, src)
+	matches := (&ContextPropagation{}).Run(tree, []byte(src), "go", nil)
+	want := []struct {
+		line        int
+		call        string
+		replacement string
+	}{
+		{4, "http.NewRequest", "http.NewRequestWithContext"},
+		{5, "db.Query", ".QueryContext"},
+		{6, "context.Background", "ctx"},
+		{11, "exec.Command", "exec.CommandContext"},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("expected %d matches, got %d: %v", len(want), len(matches), matches)
+	}
+	for i, w := range want {
+		m := matches[i]
+		if m.StartLine != w.line || m.Extra["call"] != w.call || m.Extra["replacement"] != w.replacement || m.Extra["context"] != "ctx" {
+			t.Errorf("match %d = line %d %v, want line %d %s -> %s", i, m.StartLine, m.Extra, w.line, w.call, w.replacement)
+		}
+	}
+	if !strings.Contains(matches[2].Message, "although ctx is in scope") {
+		t.Errorf("unexpected message %q", matches[2].Message)
+	}
+}
+
+func TestContextPropagationShutdownAfterDone(t *testing.T) {
+	src := `package main
+
+func serve(ctx context.Context, srv *http.Server) {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+}
+
+func drain(ctx context.Context, srv *http.Server) {
+	select {
+	case <-ctx.Done():
+		srv.Shutdown(context.TODO())
+	}
+}
+
+func early(ctx context.Context) {
+	bg := context.Background()
+	<-ctx.Done()
+	go func() {
+		_ = context.Background()
+	}()
+	_ = context.TODO()
+}
+`
+	tree := parseGo(t, src)
+	matches := (&ContextPropagation{}).Run(tree, []byte(src), "go", nil)
+	var lines []int
+	for _, m := range matches {
+		lines = append(lines, m.StartLine)
+	}
+	// Only the Background before the receive and the one in a func literal
+	// that never waits on ctx are reported.
+	if len(lines) != 2 || lines[0] != 20 || lines[1] != 23 {
+		t.Errorf("expected matches on lines 20 and 23, got %v: %v", lines, matches)
+	}
+}
+
+func TestContextPropagationCustomVariants(t *testing.T) {
+	src := "package main\n\nfunc f(c context.Context) {\n\tclient.Fetch(\"x\")\n\thttp.NewRequest(\"GET\", \"x\", nil)\n}\n"
+	tree := parseGo(t, src)
+	config := map[string]interface{}{
+		"context_variants": map[string]interface{}{".Fetch": ".FetchContext"},
+	}
+	matches := (&ContextPropagation{}).Run(tree, []byte(src), "go", config)
+	if len(matches) != 1 || matches[0].Extra["call"] != "client.Fetch" || matches[0].Extra["context"] != "c" {
+		t.Fatalf("expected only client.Fetch, got %v", matches)
+	}
+}
+
+func TestBlockingInHandler(t *testing.T) {
+	src := `package main
+
+func handle(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(2 * time.Second)
+	resp, _ := http.Get("http://backend")
+	go func() {
+		time.Sleep(time.Minute)
+	}()
+}
+
+func routes(r *gin.Engine) {
+	r.GET("/slow", func(c *gin.Context) {
+		data, _ := os.ReadFile("big.json")
+	})
+}
+
+func worker() {
+	time.Sleep(time.Second)
+}
+`
+	tree := parseGo(t, src)
+	matches := (&BlockingInHandler{}).Run(tree, []byte(src), "go", nil)
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %d: %v", len(matches), matches)
+	}
+	if matches[0].StartLine != 4 || matches[0].Extra["call"] != "time.Sleep" || matches[0].Extra["handler"] != "handle" {
+		t.Errorf("unexpected first match %+v", matches[0])
+	}
+	if matches[1].Extra["call"] != "http.Get" || matches[2].StartLine != 13 || matches[2].Extra["handler"] != "<anonymous>" {
+		t.Errorf("unexpected matches %v", matches[1:])
+	}
+}
+
+func TestBlockingInHandlerCustomConfig(t *testing.T) {
+	src := "package main\n\nfunc h(ctx *fasthttp.RequestCtx) {\n\ttime.Sleep(1)\n\tdb.Wait()\n}\n"
+	tree := parseGo(t, src)
+	config := map[string]interface{}{
+		"handler_types":  []interface{}{"*fasthttp.RequestCtx"},
+		"blocking_funcs": []interface{}{"db.Wait"},
+	}
+	matches := (&BlockingInHandler{}).Run(tree, []byte(src), "go", config)
+	if len(matches) != 1 || matches[0].Extra["call"] != "db.Wait" {
+		t.Fatalf("expected only db.Wait, got %v", matches)
+	}
+}
+
+func TestGoroutineLeak(t *testing.T) {
+	src := `package main
+
+func withTimeout(ctx context.Context) (int, error) {
+	ch := make(chan int)
+	go func() {
+		ch <- compute()
+	}()
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func buffered(ctx context.Context) {
+	ch := make(chan int, 1)
+	go func() { ch <- compute() }()
+	select {
+	case <-ch:
+	case <-ctx.Done():
+	}
+}
+
+func waited() int {
+	ch := make(chan int)
+	go func() { ch <- compute() }()
+	return <-ch
+}
+
+func generator() <-chan int {
+	ch := make(chan int)
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func selected(done chan struct{}) {
+	var ch = make(chan int, 0)
+	go func() {
+		select {
+		case ch <- 1:
+		case <-done:
+		}
+	}()
+}
+`
+	tree := parseGo(t, src)
+	matches := (&GoroutineLeak{}).Run(tree, []byte(src), "go", nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %v", len(matches), matches)
+	}
+	if matches[0].StartLine != 6 || matches[0].Extra["channel"] != "ch" {
+		t.Errorf("unexpected match %+v", matches[0])
+	}
+}
+
+func TestGoContextChecksUnknownLang(t *testing.T) {
+	src := "import time\ntime.sleep(1)\n"
+	tree := parsePython(t, src)
+	for _, c := range []Check{&ContextPropagation{}, &BlockingInHandler{}, &GoroutineLeak{}} {
+		if matches := c.Run(tree, []byte(src), "python", nil); len(matches) != 0 {
+			t.Errorf("%s: expected no matches for non-Go source, got %d", c.Name(), len(matches))
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Unsafe deserialization tests
+// ---------------------------------------------------------------------------
+
+func deserializationCalls(matches []Match) []string {
+	var calls []string
+	for _, m := range matches {
+		calls = append(calls, m.Extra["call"].(string))
+	}
+	return calls
+}
+
+func TestUnsafeDeserializationGo(t *testing.T) {
+	src := `package main
+
+type Config struct{ Name string }
+
+type Plugin struct{ Path string }
+
+func (p *Plugin) UnmarshalYAML(unmarshal func(interface{}) error) error { return nil }
+
+func load(data []byte, r io.Reader) {
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+	var cfg Config
+	json.Unmarshal(data, &cfg)
+	anything := make([]any, 0)
+	json.NewDecoder(r).Decode(&anything)
+	dec := json.NewDecoder(r)
+	var v interface{}
+	dec.Decode(&v)
+	dec.Decode(&cfg)
+	p := &Plugin{}
+	yaml.Unmarshal(data, p)
+	yaml.Unmarshal(data, &cfg)
+}
+`
+	tree := parseGo(t, src)
+	matches := (&UnsafeDeserialization{}).Run(tree, []byte(src), "go", nil)
+	got := deserializationCalls(matches)
+	want := []string{"json.Unmarshal", "json.NewDecoder(r).Decode", "dec.Decode", "yaml.Unmarshal"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected calls %v, got %v", want, got)
+	}
+	if matches[0].StartLine != 11 {
+		t.Errorf("expected first match on line 11, got %d", matches[0].StartLine)
+	}
+	if !strings.Contains(matches[3].Message, "UnmarshalYAML") {
+		t.Errorf("expected yaml message to mention UnmarshalYAML, got %q", matches[3].Message)
+	}
+}
+
+func TestUnsafeDeserializationPython(t *testing.T) {
+	src := `import pickle, yaml, torch, jsonpickle
+import numpy as np
+
+obj = pickle.loads(data)
+o = jsonpickle.decode(data)
+a = yaml.load(stream)
+b = yaml.load(stream, Loader=yaml.SafeLoader)
+c = yaml.load(stream, yaml.CSafeLoader)
+d = yaml.safe_load(stream)
+e = torch.load(path)
+f = torch.load(path, weights_only=True)
+g = np.load(path)
+h = np.load(path, allow_pickle=True)
+`
+	tree := parsePython(t, src)
+	got := deserializationCalls((&UnsafeDeserialization{}).Run(tree, []byte(src), "python", nil))
+	// pickle.loads is reported by S5135
+	want := []string{"jsonpickle.decode", "yaml.load", "torch.load", "np.load"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected calls %v, got %v", want, got)
+	}
+}
+
+func TestUnsafeDeserializationJS(t *testing.T) {
+	src := `const data = eval("(" + xhr.responseText + ")");
+const cfg = eval(jsonText);
+const sum = eval("1 + 2");
+const obj = serialize.unserialize(req.body.profile);
+const ok = JSON.parse(xhr.responseText);
+`
+	tree := parseJS(t, src)
+	matches := (&UnsafeDeserialization{}).Run(tree, []byte(src), "javascript", nil)
+	got := deserializationCalls(matches)
+	// eval is reported by S1523
+	want := []string{"serialize.unserialize"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected calls %v, got %v", want, got)
+	}
+}
+
+func TestUnsafeDeserializationCustomCalls(t *testing.T) {
+	src := "import ruamel\nobj = ruamel.load(data)\n"
+	tree := parsePython(t, src)
+	config := map[string]interface{}{"calls": []interface{}{"ruamel.load"}}
+	if got := deserializationCalls((&UnsafeDeserialization{}).Run(tree, []byte(src), "python", config)); len(got) != 1 || got[0] != "ruamel.load" {
+		t.Errorf("expected configured call to be reported, got %v", got)
+	}
+}
+
+func TestUnsafeDeserializationUnknownLang(t *testing.T) {
+	src := "package p\n\nfunc f() { pickle.loads(x) }\n"
+	tree := parseGo(t, src)
+	if matches := (&UnsafeDeserialization{}).Run(tree, []byte(src), "cobol", nil); len(matches) != 0 {
+		t.Errorf("expected no matches for an unsupported language, got %d", len(matches))
 	}
 }
 
@@ -0,0 +1,70 @@
+package astcheck
+
+import (
+	"fmt"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+var (
+	defaultHandlerTypes  = []string{"*http.Request", "*gin.Context", "echo.Context", "*fiber.Ctx"}
+	defaultBlockingFuncs = []string{
+		"time.Sleep",
+		"http.Get", "http.Head", "http.Post", "http.PostForm", "http.DefaultClient.Do",
+		"net.Dial",
+		"os.ReadFile", "ioutil.ReadFile", "os.WriteFile", "ioutil.WriteFile",
+	}
+)
+
+// BlockingInHandler flags Go HTTP handlers that sleep or do blocking IO
+// that ignores the request's context, so a client that disconnects or
+// times out keeps the handler's goroutine busy. Calls inside a goroutine the
+// handler starts are not reported.
+//
+// Config keys:
+//   - handler_types: parameter types that make a function a handler
+//     (default *http.Request, *gin.Context, echo.Context, *fiber.Ctx)
+//   - blocking_funcs: fully qualified calls to report
+type BlockingInHandler struct{}
+
+func (b *BlockingInHandler) Name() string { return "blocking-in-handler" }
+
+func (b *BlockingInHandler) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	if lang != "go" {
+		return nil
+	}
+	handlerTypes := toStringSet(config, "handler_types", defaultHandlerTypes)
+	blocking := toStringSet(config, "blocking_funcs", defaultBlockingFuncs)
+
+	var matches []Match
+	findNodes(tree.RootNode(), goFuncTypes, func(fn *sitter.Node) {
+		body := fn.ChildByFieldName("body")
+		if body == nil || !hasGoParamType(fn, source, handlerTypes) {
+			return
+		}
+		handler := funcName(fn, source)
+		// Goroutines don't hold up the response, and nested handlers are
+		// checked on their own
+		skip := func(n *sitter.Node) bool {
+			return n.Type() == "go_statement" || (n.Type() == "func_literal" && hasGoParamType(n, source, handlerTypes))
+		}
+		forEachCall(body, skip, func(call *sitter.Node) {
+			callee := call.ChildByFieldName("function")
+			if callee == nil || !blocking[callee.Content(source)] {
+				return
+			}
+			name := callee.Content(source)
+			line := int(call.StartPoint().Row) + 1
+			matches = append(matches, Match{
+				StartLine: line,
+				EndLine:   int(call.EndPoint().Row) + 1,
+				Message:   fmt.Sprintf("%s blocks HTTP handler %q without honoring the request context", name, handler),
+				Extra: map[string]interface{}{
+					"call":    name,
+					"handler": handler,
+				},
+			})
+		})
+	})
+	return matches
+}
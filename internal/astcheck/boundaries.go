@@ -0,0 +1,78 @@
+package astcheck
+
+import (
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// DeclarationStarts returns the 1-indexed lines where a file can be split
+// without cutting through a declaration: the start of every top-level node
+// and of every function or method not nested in another function, such as
+// the methods of a class. A boundary moves up to include comments directly
+// above the declaration so doc comments stay with it. Returns nil if the
+// language is unsupported or parsing fails.
+func DeclarationStarts(path string, source []byte) []int {
+	tree := ParseTree(path, source)
+	if tree == nil {
+		return nil
+	}
+	_, lang, _ := Detect(path)
+	root := tree.RootNode()
+
+	seen := make(map[int]bool)
+	add := func(n *sitter.Node) {
+		seen[int(leadingCommentStart(n).StartPoint().Row)+1] = true
+	}
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		// Comments are not boundaries of their own; those above a
+		// declaration start it, the rest stay with what precedes them.
+		if child := root.NamedChild(i); !isComment(child) {
+			add(child)
+		}
+	}
+	findOutermostNodes(root, funcNodeTypes(lang), add)
+
+	lines := make([]int, 0, len(seen))
+	for line := range seen {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// findOutermostNodes calls fn for every named node in nodeTypes that is not
+// inside another such node.
+func findOutermostNodes(node *sitter.Node, nodeTypes map[string]bool, fn func(*sitter.Node)) {
+	if node == nil {
+		return
+	}
+	if node.IsNamed() && nodeTypes[node.Type()] {
+		fn(node)
+		return
+	}
+	for i := 0; i < int(node.ChildCount()); i++ {
+		findOutermostNodes(node.Child(i), nodeTypes, fn)
+	}
+}
+
+// leadingCommentStart returns the first of the comments on their own lines
+// immediately above n, or n itself when there are none.
+func leadingCommentStart(n *sitter.Node) *sitter.Node {
+	first := n
+	for prev := first.PrevNamedSibling(); prev != nil; prev = prev.PrevNamedSibling() {
+		if !isComment(prev) || prev.EndPoint().Row+1 < first.StartPoint().Row {
+			break
+		}
+		if before := prev.PrevNamedSibling(); before != nil && before.EndPoint().Row == prev.StartPoint().Row {
+			break // trailing comment on the previous line's code
+		}
+		first = prev
+	}
+	return first
+}
+
+func isComment(n *sitter.Node) bool {
+	return strings.Contains(n.Type(), "comment")
+}
@@ -0,0 +1,56 @@
+package astcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeclarationStarts_Go(t *testing.T) {
+	src := `package main
+
+import "fmt"
+
+// Hello greets.
+// It has a two-line doc comment.
+func Hello() {
+	f := func() {}
+	f()
+}
+
+var x = 1 // trailing comment
+func Bye() {
+	fmt.Println("bye")
+}
+`
+	got := DeclarationStarts("main.go", []byte(src))
+	want := []int{1, 3, 5, 12, 13}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeclarationStarts = %v, want %v", got, want)
+	}
+}
+
+func TestDeclarationStarts_PythonMethods(t *testing.T) {
+	src := `import os
+
+class Service:
+    def start(self):
+        def helper():
+            pass
+        helper()
+
+    # Stop the service.
+    def stop(self):
+        pass
+`
+	got := DeclarationStarts("svc.py", []byte(src))
+	want := []int{1, 3, 4, 9}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeclarationStarts = %v, want %v", got, want)
+	}
+}
+
+func TestDeclarationStarts_Unsupported(t *testing.T) {
+	if got := DeclarationStarts("notes.txt", []byte("hello\n")); got != nil {
+		t.Errorf("expected nil for an unsupported language, got %v", got)
+	}
+}
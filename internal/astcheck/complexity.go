@@ -0,0 +1,358 @@
+package astcheck
+
+import (
+	"fmt"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+const (
+	defaultMaxCyclomatic = 10
+	defaultMaxCognitive  = 15
+)
+
+// CyclomaticComplexity checks that a function's cyclomatic complexity, one
+// plus the number of decision points in its body, stays under a threshold.
+// Decision points are if and else-if branches, loops, catch clauses,
+// ternaries, non-default switch cases and && / || operators. Nested
+// functions are scored on their own.
+//
+// Config keys:
+//   - max_complexity: maximum allowed complexity (default 10)
+type CyclomaticComplexity struct{}
+
+func (c *CyclomaticComplexity) Name() string { return "cyclomatic-complexity" }
+
+func (c *CyclomaticComplexity) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	maxComplexity := toInt(config["max_complexity"], defaultMaxCyclomatic)
+	return complexityMatches(tree, source, lang, "cyclomatic", maxComplexity, func(s complexityScore) int {
+		return s.cyclomatic
+	})
+}
+
+// CognitiveComplexity checks that a function's cognitive complexity stays
+// under a threshold. The score follows SonarSource's definition: each
+// branch, loop, switch and catch adds one plus its nesting level, else and
+// else-if branches, gotos and each run of the same logical operator add one,
+// and lambdas deepen the nesting of the code inside them.
+//
+// Config keys:
+//   - max_complexity: maximum allowed complexity (default 15)
+type CognitiveComplexity struct{}
+
+func (c *CognitiveComplexity) Name() string { return "cognitive-complexity" }
+
+func (c *CognitiveComplexity) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	maxComplexity := toInt(config["max_complexity"], defaultMaxCognitive)
+	return complexityMatches(tree, source, lang, "cognitive", maxComplexity, func(s complexityScore) int {
+		return s.cognitive
+	})
+}
+
+// complexityMatches scores every function in tree and reports those whose
+// score exceeds maxComplexity.
+func complexityMatches(tree *sitter.Tree, source []byte, lang, metric string, maxComplexity int, score func(complexityScore) int) []Match {
+	funcs := funcNodeTypes(lang)
+	grammar := complexityGrammars[lang]
+	if funcs == nil || grammar == nil {
+		return nil
+	}
+
+	var matches []Match
+	findNodes(tree.RootNode(), funcs, func(node *sitter.Node) {
+		counter := &complexityCounter{complexityScore: complexityScore{cyclomatic: 1}, grammar: grammar, funcs: funcs}
+		counter.walk(node, 0)
+		complexity := score(counter.complexityScore)
+		if complexity <= maxComplexity {
+			return
+		}
+		name := funcName(node, source)
+		matches = append(matches, Match{
+			StartLine: int(node.StartPoint().Row) + 1,
+			EndLine:   int(node.EndPoint().Row) + 1,
+			Message:   fmt.Sprintf("function %q has %s complexity %d (max %d)", name, metric, complexity, maxComplexity),
+			Extra: map[string]interface{}{
+				"function":       name,
+				"complexity":     complexity,
+				"max_complexity": maxComplexity,
+			},
+		})
+	})
+	return matches
+}
+
+// complexityGrammar lists the node types the complexity checks score in one
+// language's grammar.
+type complexityGrammar struct {
+	ifs      map[string]bool // if statements, whose else branches are scored flat
+	elseIfs  map[string]bool // else-if clauses the grammar models as their own node
+	elses    map[string]bool // else clauses
+	branches map[string]bool // loops, catch clauses and ternaries
+	switches map[string]bool // switch and match statements
+	cases    map[string]bool // case clauses; defaults add no decision
+	logical  map[string]bool // binary expressions that may join conditions
+	nests    map[string]bool // lambdas and closures, which deepen nesting
+	jumps    map[string]bool // goto statements
+}
+
+func nodeSet(types ...string) map[string]bool {
+	set := make(map[string]bool, len(types))
+	for _, t := range types {
+		set[t] = true
+	}
+	return set
+}
+
+var complexityGrammars = map[string]*complexityGrammar{
+	"go": {
+		ifs:      nodeSet("if_statement"),
+		branches: nodeSet("for_statement"),
+		switches: nodeSet("expression_switch_statement", "type_switch_statement", "select_statement"),
+		cases:    nodeSet("expression_case", "type_case", "communication_case"),
+		logical:  nodeSet("binary_expression"),
+		nests:    nodeSet("func_literal"),
+		jumps:    nodeSet("goto_statement"),
+	},
+	"python": {
+		ifs:      nodeSet("if_statement"),
+		elseIfs:  nodeSet("elif_clause"),
+		elses:    nodeSet("else_clause"),
+		branches: nodeSet("for_statement", "while_statement", "except_clause", "conditional_expression"),
+		switches: nodeSet("match_statement"),
+		cases:    nodeSet("case_clause"),
+		logical:  nodeSet("boolean_operator"),
+		nests:    nodeSet("lambda"),
+	},
+	"javascript": jsComplexityGrammar,
+	"typescript": jsComplexityGrammar,
+	"java": {
+		ifs:      nodeSet("if_statement"),
+		branches: nodeSet("for_statement", "enhanced_for_statement", "while_statement", "do_statement", "catch_clause", "ternary_expression"),
+		switches: nodeSet("switch_expression"),
+		cases:    nodeSet("switch_label"),
+		logical:  nodeSet("binary_expression"),
+		nests:    nodeSet("lambda_expression"),
+	},
+	"c": {
+		ifs:      nodeSet("if_statement"),
+		elses:    nodeSet("else_clause"),
+		branches: nodeSet("for_statement", "while_statement", "do_statement", "conditional_expression"),
+		switches: nodeSet("switch_statement"),
+		cases:    nodeSet("case_statement"),
+		logical:  nodeSet("binary_expression"),
+		jumps:    nodeSet("goto_statement"),
+	},
+	"rust": {
+		ifs:      nodeSet("if_expression"),
+		elses:    nodeSet("else_clause"),
+		branches: nodeSet("for_expression", "while_expression", "loop_expression"),
+		switches: nodeSet("match_expression"),
+		cases:    nodeSet("match_arm"),
+		logical:  nodeSet("binary_expression"),
+		nests:    nodeSet("closure_expression"),
+	},
+	"ruby": {
+		ifs:      nodeSet("if", "unless"),
+		elseIfs:  nodeSet("elsif"),
+		elses:    nodeSet("else"),
+		branches: nodeSet("while", "until", "for", "rescue", "conditional", "if_modifier", "unless_modifier", "while_modifier", "until_modifier"),
+		switches: nodeSet("case"),
+		cases:    nodeSet("when"),
+		logical:  nodeSet("binary"),
+		nests:    nodeSet("block", "do_block", "lambda"),
+	},
+	"php": {
+		ifs:      nodeSet("if_statement"),
+		elseIfs:  nodeSet("else_if_clause"),
+		elses:    nodeSet("else_clause"),
+		branches: nodeSet("for_statement", "foreach_statement", "while_statement", "do_statement", "catch_clause", "conditional_expression"),
+		switches: nodeSet("switch_statement"),
+		cases:    nodeSet("case_statement"),
+		logical:  nodeSet("binary_expression"),
+		nests:    nodeSet("anonymous_function_creation_expression", "arrow_function"),
+	},
+	"kotlin": {
+		ifs:      nodeSet("if_expression"),
+		branches: nodeSet("for_statement", "while_statement", "do_while_statement", "catch_block"),
+		switches: nodeSet("when_expression"),
+		cases:    nodeSet("when_entry"),
+		logical:  nodeSet("conjunction_expression", "disjunction_expression"),
+		nests:    nodeSet("lambda_literal", "anonymous_function"),
+	},
+	"csharp": {
+		ifs:      nodeSet("if_statement"),
+		branches: nodeSet("for_statement", "foreach_statement", "while_statement", "do_statement", "catch_clause", "conditional_expression"),
+		switches: nodeSet("switch_statement", "switch_expression"),
+		cases:    nodeSet("switch_section", "switch_expression_arm"),
+		logical:  nodeSet("binary_expression"),
+		nests:    nodeSet("lambda_expression", "anonymous_method_expression"),
+	},
+}
+
+// jsComplexityGrammar is shared by JavaScript and TypeScript. Arrow functions
+// are scored as functions of their own, so only function expressions nest.
+var jsComplexityGrammar = &complexityGrammar{
+	ifs:      nodeSet("if_statement"),
+	elses:    nodeSet("else_clause"),
+	branches: nodeSet("for_statement", "for_in_statement", "while_statement", "do_statement", "catch_clause", "ternary_expression"),
+	switches: nodeSet("switch_statement"),
+	cases:    nodeSet("switch_case"),
+	logical:  nodeSet("binary_expression"),
+	nests:    nodeSet("function_expression", "function"),
+}
+
+// logicalOperators are the operator tokens that join conditions.
+var logicalOperators = nodeSet("&&", "||", "and", "or")
+
+type complexityScore struct {
+	cyclomatic int
+	cognitive  int
+}
+
+// complexityCounter scores the body of one function.
+type complexityCounter struct {
+	complexityScore
+	grammar *complexityGrammar
+	funcs   map[string]bool
+}
+
+// walk scores the children of node, which sit at the given nesting level.
+func (c *complexityCounter) walk(node *sitter.Node, nesting int) {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		c.visit(node.Child(i), nesting)
+	}
+}
+
+func (c *complexityCounter) visit(node *sitter.Node, nesting int) {
+	if node == nil || !node.IsNamed() {
+		return
+	}
+	g := c.grammar
+	switch t := node.Type(); {
+	case c.funcs[t]:
+		// Nested functions are scored on their own
+		return
+	case g.ifs[t]:
+		c.cyclomatic++
+		c.cognitive += 1 + nesting
+		c.walkIf(node, nesting+1)
+		return
+	case g.elseIfs[t]:
+		c.cyclomatic++
+		c.cognitive++
+		c.walkIf(node, nesting)
+		return
+	case g.branches[t]:
+		c.cyclomatic++
+		c.cognitive += 1 + nesting
+		nesting++
+	case g.switches[t]:
+		c.cognitive += 1 + nesting
+		nesting++
+	case g.cases[t]:
+		if !isDefaultCase(node) {
+			c.cyclomatic++
+		}
+	case g.logical[t]:
+		if op, n := logicalOperator(node); n > 0 {
+			c.cyclomatic += n
+			// A run of the same operator counts once: a && b && c adds one
+			if parent := node.Parent(); parent == nil || !g.logical[parent.Type()] {
+				c.cognitive++
+			} else if parentOp, _ := logicalOperator(parent); parentOp != op {
+				c.cognitive++
+			}
+		}
+	case g.nests[t]:
+		nesting++
+	case g.jumps[t]:
+		c.cognitive++
+	}
+	c.walk(node, nesting)
+}
+
+// walkIf scores the children of an if or else-if node. Its else branch, the
+// node after an else keyword or an else clause, adds one without nesting, and
+// an if that is the whole else branch is an else-if.
+func (c *complexityCounter) walkIf(node *sitter.Node, nesting int) {
+	afterElse := false
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil || child.IsExtra() {
+			continue
+		}
+		if !child.IsNamed() {
+			afterElse = child.Type() == "else"
+			continue
+		}
+		if afterElse || c.grammar.elses[child.Type()] {
+			c.visitElse(child, nesting)
+		} else {
+			c.visit(child, nesting)
+		}
+		afterElse = false
+	}
+}
+
+func (c *complexityCounter) visitElse(node *sitter.Node, nesting int) {
+	elseIf := node
+	if !c.grammar.ifs[node.Type()] {
+		elseIf = c.soleIf(node)
+	}
+	if elseIf != nil {
+		c.cyclomatic++
+		c.cognitive++
+		c.walkIf(elseIf, nesting)
+		return
+	}
+	c.cognitive++
+	c.visit(node, nesting)
+}
+
+// soleIf returns the if that makes up the whole of an else clause and starts
+// on its line, as in "else if", or nil. An if alone inside a braced else
+// block is nested, not an else-if.
+func (c *complexityCounter) soleIf(node *sitter.Node) *sitter.Node {
+	var sole *sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child == nil || child.IsExtra() {
+			continue
+		}
+		if sole != nil {
+			return nil
+		}
+		sole = child
+	}
+	if sole == nil || !c.grammar.ifs[sole.Type()] || sole.StartPoint().Row != node.StartPoint().Row {
+		return nil
+	}
+	return sole
+}
+
+// logicalOperator returns the logical operator joining a binary expression's
+// operands, and how many times it appears, or zero for other operators.
+func logicalOperator(node *sitter.Node) (string, int) {
+	var op string
+	n := 0
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child != nil && !child.IsNamed() && logicalOperators[child.Type()] {
+			op = child.Type()
+			n++
+		}
+	}
+	return op, n
+}
+
+// isDefaultCase reports whether a case clause is a default or else branch,
+// which adds no decision of its own.
+func isDefaultCase(node *sitter.Node) bool {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child != nil && !child.IsNamed() && (child.Type() == "default" || child.Type() == "else") {
+			return true
+		}
+	}
+	return false
+}
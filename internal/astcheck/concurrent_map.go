@@ -0,0 +1,267 @@
+package astcheck
+
+import (
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ConcurrentMapWrite flags writes to package-level variables or struct
+// fields of map type from inside `go func() { ... }` bodies when nothing
+// suggests the access is synchronized. It is a heuristic: it does not track
+// aliases or maps passed as arguments.
+//
+// Config keys:
+//   - strict: when true, only a Lock()/RLock() call inside the goroutine
+//     body counts as synchronization. When false (default), a sync.Mutex or
+//     sync.RWMutex declared alongside the map (at package level for package
+//     maps, in the same struct for fields) is also accepted.
+type ConcurrentMapWrite struct{}
+
+func (c *ConcurrentMapWrite) Name() string { return "concurrent-map-write" }
+
+func (c *ConcurrentMapWrite) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	if lang != "go" {
+		return nil
+	}
+	strict, _ := config["strict"].(bool)
+
+	root := tree.RootNode()
+	shared := collectSharedMaps(root, source)
+	if len(shared.pkgMaps) == 0 && len(shared.fieldMaps) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	findNodes(root, map[string]bool{"go_statement": true}, func(goStmt *sitter.Node) {
+		body := goroutineBody(goStmt)
+		if body == nil || callsLock(body, source) {
+			return
+		}
+
+		reported := make(map[string]bool)
+		forEachMapWrite(body, source, func(write *sitter.Node, target *sitter.Node) {
+			name, guarded, ok := shared.lookup(target, source)
+			if !ok || (!strict && guarded) || reported[name] {
+				return
+			}
+			reported[name] = true
+			line := int(write.StartPoint().Row) + 1
+			matches = append(matches, Match{
+				StartLine: line,
+				EndLine:   int(write.EndPoint().Row) + 1,
+				Message:   fmt.Sprintf("map %q written inside a goroutine without synchronization at line %d", name, line),
+				Extra: map[string]interface{}{
+					"map": name,
+				},
+			})
+		})
+	})
+
+	return matches
+}
+
+// sharedMaps records the package-level and struct-field maps in a file and
+// whether each has an associated mutex.
+type sharedMaps struct {
+	pkgMaps     map[string]bool // package-level map variable names
+	pkgHasMutex bool
+	fieldMaps   map[string]bool // field name -> guarded by a mutex in its struct
+}
+
+// lookup resolves the operand of a map write to a tracked map. It returns the
+// display name, whether the map has an associated mutex, and whether the
+// operand refers to a tracked map at all.
+func (s sharedMaps) lookup(target *sitter.Node, source []byte) (string, bool, bool) {
+	switch target.Type() {
+	case "identifier":
+		name := target.Content(source)
+		if s.pkgMaps[name] {
+			return name, s.pkgHasMutex, true
+		}
+	case "selector_expression":
+		field := target.ChildByFieldName("field")
+		if field == nil {
+			return "", false, false
+		}
+		if guarded, ok := s.fieldMaps[field.Content(source)]; ok {
+			return target.Content(source), guarded, true
+		}
+	}
+	return "", false, false
+}
+
+func collectSharedMaps(root *sitter.Node, source []byte) sharedMaps {
+	s := sharedMaps{pkgMaps: make(map[string]bool), fieldMaps: make(map[string]bool)}
+
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		decl := root.NamedChild(i)
+		if decl == nil || decl.Type() != "var_declaration" {
+			continue
+		}
+		findNodes(decl, map[string]bool{"var_spec": true}, func(spec *sitter.Node) {
+			typ := spec.ChildByFieldName("type")
+			if typ != nil && isMutexType(typ.Content(source)) {
+				s.pkgHasMutex = true
+				return
+			}
+			if !isMapSpec(spec, source) {
+				return
+			}
+			for j := 0; j < int(spec.NamedChildCount()); j++ {
+				if n := spec.NamedChild(j); n != nil && n.Type() == "identifier" {
+					s.pkgMaps[n.Content(source)] = true
+				}
+			}
+		})
+	}
+
+	findNodes(root, map[string]bool{"struct_type": true}, func(st *sitter.Node) {
+		var mapFields []string
+		hasMutex := false
+		findNodes(st, map[string]bool{"field_declaration": true}, func(fd *sitter.Node) {
+			typ := fd.ChildByFieldName("type")
+			if typ == nil {
+				return
+			}
+			if isMutexType(typ.Content(source)) {
+				hasMutex = true
+				return
+			}
+			if typ.Type() != "map_type" {
+				return
+			}
+			for j := 0; j < int(fd.NamedChildCount()); j++ {
+				if n := fd.NamedChild(j); n != nil && n.Type() == "field_identifier" {
+					mapFields = append(mapFields, n.Content(source))
+				}
+			}
+		})
+		for _, name := range mapFields {
+			// A field name shared by several structs is only considered
+			// guarded if every declaring struct has a mutex.
+			if guarded, seen := s.fieldMaps[name]; seen {
+				s.fieldMaps[name] = guarded && hasMutex
+			} else {
+				s.fieldMaps[name] = hasMutex
+			}
+		}
+	})
+
+	return s
+}
+
+// isMapSpec reports whether a var_spec declares a map, either by type or by
+// a map literal / make(map...) initializer.
+func isMapSpec(spec *sitter.Node, source []byte) bool {
+	if typ := spec.ChildByFieldName("type"); typ != nil {
+		return typ.Type() == "map_type"
+	}
+	value := spec.ChildByFieldName("value")
+	if value == nil || value.NamedChildCount() == 0 {
+		return false
+	}
+	v := value.NamedChild(0)
+	switch v.Type() {
+	case "composite_literal":
+		t := v.ChildByFieldName("type")
+		return t != nil && t.Type() == "map_type"
+	case "call_expression":
+		fn := v.ChildByFieldName("function")
+		args := v.ChildByFieldName("arguments")
+		if fn == nil || fn.Content(source) != "make" || args == nil || args.NamedChildCount() == 0 {
+			return false
+		}
+		return args.NamedChild(0).Type() == "map_type"
+	}
+	return false
+}
+
+// isMutexType reports whether a type expression names a sync mutex,
+// including pointers to one.
+func isMutexType(typ string) bool {
+	typ = strings.TrimPrefix(strings.TrimSpace(typ), "*")
+	return typ == "sync.Mutex" || typ == "sync.RWMutex"
+}
+
+// goroutineBody returns the body of `go func() { ... }()`, or nil when the
+// go statement calls a named function.
+func goroutineBody(goStmt *sitter.Node) *sitter.Node {
+	for i := 0; i < int(goStmt.NamedChildCount()); i++ {
+		call := goStmt.NamedChild(i)
+		if call == nil || call.Type() != "call_expression" {
+			continue
+		}
+		fn := call.ChildByFieldName("function")
+		if fn != nil && fn.Type() == "func_literal" {
+			return fn.ChildByFieldName("body")
+		}
+	}
+	return nil
+}
+
+// callsLock reports whether body contains a x.Lock() or x.RLock() call.
+func callsLock(body *sitter.Node, source []byte) bool {
+	found := false
+	findNodes(body, map[string]bool{"call_expression": true}, func(call *sitter.Node) {
+		fn := call.ChildByFieldName("function")
+		if fn == nil || fn.Type() != "selector_expression" {
+			return
+		}
+		if field := fn.ChildByFieldName("field"); field != nil {
+			switch field.Content(source) {
+			case "Lock", "RLock":
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// forEachMapWrite calls fn for every statement in body that writes through a
+// map index (m[k] = v, m[k]++, delete(m, k)), passing the statement and the
+// map operand.
+func forEachMapWrite(body *sitter.Node, source []byte, fn func(write, target *sitter.Node)) {
+	writeTypes := map[string]bool{
+		"assignment_statement": true,
+		"inc_statement":        true,
+		"dec_statement":        true,
+		"call_expression":      true,
+	}
+	findNodes(body, writeTypes, func(n *sitter.Node) {
+		switch n.Type() {
+		case "assignment_statement":
+			left := n.ChildByFieldName("left")
+			if left == nil {
+				return
+			}
+			for i := 0; i < int(left.NamedChildCount()); i++ {
+				if target := indexOperand(left.NamedChild(i)); target != nil {
+					fn(n, target)
+				}
+			}
+		case "inc_statement", "dec_statement":
+			if n.NamedChildCount() > 0 {
+				if target := indexOperand(n.NamedChild(0)); target != nil {
+					fn(n, target)
+				}
+			}
+		case "call_expression":
+			callee := n.ChildByFieldName("function")
+			args := n.ChildByFieldName("arguments")
+			if callee == nil || callee.Content(source) != "delete" || args == nil || args.NamedChildCount() == 0 {
+				return
+			}
+			fn(n, args.NamedChild(0))
+		}
+	})
+}
+
+// indexOperand returns the operand of an index expression, or nil.
+func indexOperand(n *sitter.Node) *sitter.Node {
+	if n == nil || n.Type() != "index_expression" {
+		return nil
+	}
+	return n.ChildByFieldName("operand")
+}
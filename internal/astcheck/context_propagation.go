@@ -0,0 +1,228 @@
+package astcheck
+
+import (
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// defaultContextVariants maps calls that cannot be cancelled to the variant
+// that takes a context. Keys starting with "." match a method of any
+// receiver.
+var defaultContextVariants = map[string]string{
+	"context.Background": "ctx",
+	"context.TODO":       "ctx",
+	"http.NewRequest":    "http.NewRequestWithContext",
+	"http.Get":           "http.NewRequestWithContext",
+	"http.Head":          "http.NewRequestWithContext",
+	"http.Post":          "http.NewRequestWithContext",
+	"http.PostForm":      "http.NewRequestWithContext",
+	"exec.Command":       "exec.CommandContext",
+	"net.Dial":           "net.Dialer.DialContext",
+	"net.DialTimeout":    "net.Dialer.DialContext",
+	".Query":             ".QueryContext",
+	".QueryRow":          ".QueryRowContext",
+	".Exec":              ".ExecContext",
+	".Prepare":           ".PrepareContext",
+	".Begin":             ".BeginTx",
+	".Ping":              ".PingContext",
+}
+
+// goFuncTypes are the Go nodes with a parameter list and a body.
+var goFuncTypes = nodeSet("function_declaration", "method_declaration", "func_literal")
+
+// ContextPropagation flags Go functions that take a context.Context but make
+// calls that drop it: context.Background() or context.TODO(), or an API
+// whose context-aware variant would have honored the caller's cancellation
+// and deadline. Calls that pass the context along are not reported, and a
+// blank `_ context.Context` parameter opts a function out. Neither is a new
+// context started after `<-ctx.Done()` in the same function or func
+// literal, the graceful-shutdown idiom that needs a deadline the cancelled
+// context can no longer give.
+//
+// Config keys:
+//   - context_variants: map of call (e.g. "http.NewRequest", or ".Query"
+//     for a method) to the variant to use instead
+type ContextPropagation struct{}
+
+func (c *ContextPropagation) Name() string { return "context-propagation" }
+
+func (c *ContextPropagation) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	if lang != "go" {
+		return nil
+	}
+	variants := toStringMap(config, "context_variants", defaultContextVariants)
+
+	var matches []Match
+	findNodes(tree.RootNode(), goFuncTypes, func(fn *sitter.Node) {
+		ctxNames := goParamNames(fn, source, nodeSet("context.Context"))
+		body := fn.ChildByFieldName("body")
+		if len(ctxNames) == 0 || body == nil {
+			return
+		}
+		ctxName := ctxNames[0]
+		// A nested function with its own context is checked on its own
+		skip := func(n *sitter.Node) bool {
+			return n.Type() == "func_literal" && len(goParamNames(n, source, nodeSet("context.Context"))) > 0
+		}
+		forEachCall(body, skip, func(call *sitter.Node) {
+			callee := call.ChildByFieldName("function")
+			if callee == nil {
+				return
+			}
+			name := callee.Content(source)
+			replacement, ok := variants[name]
+			if !ok && callee.Type() == "selector_expression" {
+				if field := callee.ChildByFieldName("field"); field != nil {
+					replacement, ok = variants["."+field.Content(source)]
+				}
+			}
+			if !ok || mentionsIdentifier(call.ChildByFieldName("arguments"), source, ctxNames) {
+				return
+			}
+			if replacement == "ctx" && followsDoneReceive(call, source, ctxNames) {
+				return
+			}
+
+			line := int(call.StartPoint().Row) + 1
+			msg := fmt.Sprintf("%s ignores %s; use %s", name, ctxName, replacement)
+			if replacement == "ctx" {
+				replacement = ctxName
+				msg = fmt.Sprintf("%s() starts a new context although %s is in scope", name, ctxName)
+			}
+			matches = append(matches, Match{
+				StartLine: line,
+				EndLine:   int(call.EndPoint().Row) + 1,
+				Message:   msg,
+				Extra: map[string]interface{}{
+					"call":        name,
+					"replacement": replacement,
+					"context":     ctxName,
+				},
+			})
+		})
+	})
+	return matches
+}
+
+// followsDoneReceive reports whether call comes after a `<-ctx.Done()`
+// receive on one of ctxNames within the same function or func literal.
+func followsDoneReceive(call *sitter.Node, source []byte, ctxNames []string) bool {
+	fn := enclosingGoFunc(call)
+	if fn == nil {
+		return false
+	}
+	found := false
+	findNodes(fn, nodeSet("unary_expression"), func(recv *sitter.Node) {
+		if found || recv.StartByte() >= call.StartByte() || enclosingGoFunc(recv) != fn {
+			return
+		}
+		op := recv.ChildByFieldName("operator")
+		operand := recv.ChildByFieldName("operand")
+		if op == nil || op.Content(source) != "<-" || operand == nil || operand.Type() != "call_expression" {
+			return
+		}
+		callee := operand.ChildByFieldName("function")
+		if callee == nil || callee.Type() != "selector_expression" {
+			return
+		}
+		field := callee.ChildByFieldName("field")
+		if field == nil || field.Content(source) != "Done" {
+			return
+		}
+		found = mentionsIdentifier(callee.ChildByFieldName("operand"), source, ctxNames)
+	})
+	return found
+}
+
+// goParamNames returns the names of fn's parameters whose declared type is
+// in types, skipping blank and unnamed parameters.
+func goParamNames(fn *sitter.Node, source []byte, types map[string]bool) []string {
+	params := fn.ChildByFieldName("parameters")
+	if params == nil {
+		return nil
+	}
+	var names []string
+	for i := 0; i < int(params.NamedChildCount()); i++ {
+		decl := params.NamedChild(i)
+		if decl == nil || decl.Type() != "parameter_declaration" {
+			continue
+		}
+		typ := decl.ChildByFieldName("type")
+		if typ == nil || !types[typ.Content(source)] {
+			continue
+		}
+		for j := 0; j < int(decl.NamedChildCount()); j++ {
+			if id := decl.NamedChild(j); id != nil && id.Type() == "identifier" && id.Content(source) != "_" {
+				names = append(names, id.Content(source))
+			}
+		}
+	}
+	return names
+}
+
+// hasGoParamType reports whether any of fn's parameters, named or not, has a
+// declared type in types.
+func hasGoParamType(fn *sitter.Node, source []byte, types map[string]bool) bool {
+	params := fn.ChildByFieldName("parameters")
+	if params == nil {
+		return false
+	}
+	for i := 0; i < int(params.NamedChildCount()); i++ {
+		decl := params.NamedChild(i)
+		if decl == nil || decl.Type() != "parameter_declaration" {
+			continue
+		}
+		if typ := decl.ChildByFieldName("type"); typ != nil && types[typ.Content(source)] {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachCall calls fn for each call expression under node, without
+// descending into nodes for which skip returns true.
+func forEachCall(node *sitter.Node, skip func(*sitter.Node) bool, fn func(*sitter.Node)) {
+	if node == nil || skip(node) {
+		return
+	}
+	if node.Type() == "call_expression" {
+		fn(node)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		forEachCall(node.NamedChild(i), skip, fn)
+	}
+}
+
+// mentionsIdentifier reports whether an identifier under node has one of
+// the given names.
+func mentionsIdentifier(node *sitter.Node, source []byte, names []string) bool {
+	found := false
+	findNodes(node, nodeSet("identifier"), func(id *sitter.Node) {
+		for _, name := range names {
+			if id.Content(source) == name {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// toStringMap reads a string-to-string map from config[key], falling back
+// to defaults when the key is absent or not a map.
+func toStringMap(config map[string]interface{}, key string, defaults map[string]string) map[string]string {
+	switch v := config[key].(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		m := make(map[string]string, len(v))
+		for k, val := range v {
+			if s, ok := val.(string); ok {
+				m[k] = strings.TrimSpace(s)
+			}
+		}
+		return m
+	}
+	return defaults
+}
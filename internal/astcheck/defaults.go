@@ -7,5 +7,19 @@ func DefaultRegistry() *Registry {
 	r.Register(&NestingDepth{})
 	r.Register(&EmptyHandler{})
 	r.Register(&ParamCount{})
+	r.Register(&UnboundedRead{})
+	r.Register(&ConcurrentMapWrite{})
+	r.Register(&TimingUnsafeCompare{})
+	r.Register(&TaintedSQL{})
+	r.Register(&TaintedCommand{})
+	r.Register(&TaintedPath{})
+	r.Register(&MissingAuthRoute{})
+	r.Register(&CyclomaticComplexity{})
+	r.Register(&CognitiveComplexity{})
+	r.Register(&DuplicateCode{})
+	r.Register(&ContextPropagation{})
+	r.Register(&BlockingInHandler{})
+	r.Register(&GoroutineLeak{})
+	r.Register(&UnsafeDeserialization{})
 	return r
 }
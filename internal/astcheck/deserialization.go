@@ -0,0 +1,367 @@
+package astcheck
+
+import (
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// deserializationPattern describes calls that deserialize data unsafely,
+// optionally only under some condition on their arguments.
+type deserializationPattern struct {
+	calls  []string // Callees as written; a leading "." matches a method on any receiver
+	hazard string   // Why the call is unsafe, for the finding message
+
+	// Go: index of the argument decoded into, or -1 for the receiver's
+	// Decode(&v) argument on a decoder built by one of calls
+	target int
+	// Go: report only when the target's type is a generic container such
+	// as map[string]interface{}
+	weakTarget bool
+	// Go: report only when the target's type declares this method in the file
+	customMethod string
+
+	// Python: the call is safe when keyword arg (or the positional arg at
+	// safeIndex) has one of the safe values
+	safeKeyword string
+	safeIndex   int
+	safeValues  []string
+	// Python: report only when keyword arg unsafeKeyword is unsafeValue
+	unsafeKeyword string
+	unsafeValue   string
+}
+
+// deserializationPatterns holds the built-in patterns per language.
+var deserializationPatterns = map[string][]deserializationPattern{
+	"go": {
+		{calls: []string{"json.Unmarshal"}, target: 1, weakTarget: true,
+			hazard: "decodes untyped input into a generic container, skipping the validation a struct's fields and tags give"},
+		{calls: []string{"json.NewDecoder"}, target: -1, weakTarget: true,
+			hazard: "decodes untyped input into a generic container, skipping the validation a struct's fields and tags give"},
+		{calls: []string{"yaml.Unmarshal"}, target: 1, customMethod: "UnmarshalYAML",
+			hazard: "runs a custom UnmarshalYAML method on the input"},
+		{calls: []string{"yaml.NewDecoder"}, target: -1, customMethod: "UnmarshalYAML",
+			hazard: "runs a custom UnmarshalYAML method on the input"},
+	},
+	// pickle, marshal, shelve and yaml.unsafe_load are left to the regex
+	// rule S5135, and bare eval to S1523, so a call is reported only once.
+	"python": {
+		{calls: []string{"jsonpickle.decode", "pandas.read_pickle", "pd.read_pickle"},
+			hazard: "can construct arbitrary objects and run code while loading"},
+		{calls: []string{"yaml.load", "yaml.load_all"}, safeKeyword: "Loader", safeIndex: 1,
+			safeValues: []string{"SafeLoader", "CSafeLoader", "BaseLoader", "yaml.SafeLoader", "yaml.CSafeLoader", "yaml.BaseLoader"},
+			hazard:     "can construct arbitrary objects without a safe Loader"},
+		{calls: []string{"torch.load"}, safeKeyword: "weights_only", safeIndex: -1, safeValues: []string{"True"},
+			hazard: "unpickles the file without weights_only=True"},
+		{calls: []string{"numpy.load", "np.load"}, unsafeKeyword: "allow_pickle", unsafeValue: "True",
+			hazard: "unpickles object arrays with allow_pickle=True"},
+	},
+	"javascript": jsDeserializationPatterns,
+	"typescript": jsDeserializationPatterns,
+}
+
+var jsDeserializationPatterns = []deserializationPattern{
+	{calls: []string{"unserialize", ".unserialize"},
+		hazard: "can revive functions embedded in the input and run them"},
+}
+
+// weakGoTypes are the generic containers a JSON document can be decoded
+// into without any structure being enforced.
+var weakGoTypes = nodeSet(
+	"interface{}", "any",
+	"map[string]interface{}", "map[string]any",
+	"[]interface{}", "[]any",
+	"[]map[string]interface{}", "[]map[string]any",
+)
+
+// UnsafeDeserialization flags deserialization hazards (CWE-502) found by
+// per-language call patterns: in Go, json.Unmarshal or a json.Decoder into
+// map[string]interface{} and similar containers, and yaml decoding into a
+// type with a custom UnmarshalYAML; in Python, yaml.load without a safe
+// Loader and pickle-based loaders such as torch.load and pandas.read_pickle;
+// in JavaScript, node-serialize's unserialize.
+//
+// Config keys:
+//   - calls: additional callees to always report, for the file's language
+type UnsafeDeserialization struct{}
+
+func (u *UnsafeDeserialization) Name() string { return "unsafe-deserialization" }
+
+func (u *UnsafeDeserialization) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	patterns := deserializationPatterns[lang]
+	if patterns == nil {
+		return nil
+	}
+	if extra := toStringSet(config, "calls", nil); len(extra) > 0 {
+		custom := deserializationPattern{hazard: "is configured as an unsafe deserializer"}
+		for call := range extra {
+			custom.calls = append(custom.calls, call)
+		}
+		patterns = append(append([]deserializationPattern(nil), patterns...), custom)
+	}
+
+	root := tree.RootNode()
+	callTypes := nodeSet("call_expression", "call")
+	var matches []Match
+	findNodes(root, callTypes, func(call *sitter.Node) {
+		callee := call.ChildByFieldName("function")
+		args := call.ChildByFieldName("arguments")
+		if callee == nil || args == nil {
+			return
+		}
+		for _, p := range patterns {
+			name, ok := p.match(call, callee, args, root, source)
+			if !ok {
+				continue
+			}
+			line := int(call.StartPoint().Row) + 1
+			matches = append(matches, Match{
+				StartLine: line,
+				EndLine:   int(call.EndPoint().Row) + 1,
+				Message:   fmt.Sprintf("%s %s", name, p.hazard),
+				Extra: map[string]interface{}{
+					"call": name,
+				},
+			})
+			return
+		}
+	})
+	return matches
+}
+
+// match reports whether call matches p, returning the callee as written.
+func (p deserializationPattern) match(call, callee, args, root *sitter.Node, source []byte) (string, bool) {
+	name := callee.Content(source)
+	target := (*sitter.Node)(nil)
+	if p.target < 0 {
+		// decoder.Decode(&v), where the decoder is built by one of p.calls
+		if !isDecodeOn(callee, source, p.calls, call) {
+			return "", false
+		}
+		target = args.NamedChild(0)
+	} else {
+		if !calleeMatches(callee, source, p.calls) {
+			return "", false
+		}
+		if p.weakTarget || p.customMethod != "" {
+			target = args.NamedChild(p.target)
+		}
+	}
+
+	if p.weakTarget || p.customMethod != "" {
+		typ := goTargetType(target, call, source)
+		if p.weakTarget && !weakGoTypes[typ] {
+			return "", false
+		}
+		if p.customMethod != "" && (typ == "" || !hasGoMethod(root, source, typ, p.customMethod)) {
+			return "", false
+		}
+	}
+	if len(p.safeValues) > 0 && pythonArgIn(args, source, p.safeKeyword, p.safeIndex, p.safeValues) {
+		return "", false
+	}
+	if p.unsafeKeyword != "" && !pythonArgIn(args, source, p.unsafeKeyword, -1, []string{p.unsafeValue}) {
+		return "", false
+	}
+	return name, true
+}
+
+// calleeMatches reports whether callee is one of calls. A call starting with
+// "." matches the method name of a member or attribute callee.
+func calleeMatches(callee *sitter.Node, source []byte, calls []string) bool {
+	name := callee.Content(source)
+	for _, c := range calls {
+		if c == name {
+			return true
+		}
+		if strings.HasPrefix(c, ".") {
+			if field := memberName(callee, source); field != "" && "."+field == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// memberName returns the trailing name of a selector, member or attribute
+// expression, or "".
+func memberName(callee *sitter.Node, source []byte) string {
+	for _, field := range []string{"field", "property", "attribute"} {
+		if n := callee.ChildByFieldName(field); n != nil {
+			return n.Content(source)
+		}
+	}
+	return ""
+}
+
+// isDecodeOn reports whether callee is x.Decode where x is a call to one of
+// constructors, or a variable assigned from one in the function around call.
+func isDecodeOn(callee *sitter.Node, source []byte, constructors []string, call *sitter.Node) bool {
+	if callee.Type() != "selector_expression" || memberName(callee, source) != "Decode" {
+		return false
+	}
+	operand := callee.ChildByFieldName("operand")
+	if operand == nil {
+		return false
+	}
+	if operand.Type() == "call_expression" {
+		fn := operand.ChildByFieldName("function")
+		return fn != nil && calleeMatches(fn, source, constructors)
+	}
+	if operand.Type() != "identifier" {
+		return false
+	}
+	value := goAssignedValue(operand.Content(source), call, source)
+	if value == nil || value.Type() != "call_expression" {
+		return false
+	}
+	fn := value.ChildByFieldName("function")
+	return fn != nil && calleeMatches(fn, source, constructors)
+}
+
+// goAssignedValue returns the expression name is declared or assigned from
+// in the function enclosing at, or at file level.
+func goAssignedValue(name string, at *sitter.Node, source []byte) *sitter.Node {
+	scope := enclosingNode(at, goFuncTypes)
+	if scope == nil {
+		scope = at
+		for scope.Parent() != nil {
+			scope = scope.Parent()
+		}
+	}
+	var value *sitter.Node
+	findNodes(scope, nodeSet("short_var_declaration", "assignment_statement", "var_spec"), func(n *sitter.Node) {
+		left, right := n.ChildByFieldName("left"), n.ChildByFieldName("right")
+		if n.Type() == "var_spec" {
+			left, right = n, n.ChildByFieldName("value")
+		}
+		if left == nil || right == nil {
+			return
+		}
+		idx := 0
+		for i := 0; i < int(left.NamedChildCount()); i++ {
+			id := left.NamedChild(i)
+			if id == nil || id.Type() != "identifier" {
+				continue
+			}
+			if id.Content(source) == name && idx < int(right.NamedChildCount()) {
+				value = right.NamedChild(idx)
+			}
+			idx++
+		}
+	})
+	return value
+}
+
+// goTargetType returns the declared type of a decode target such as &v, with
+// any pointer stripped, or "" when it cannot be told from the source.
+func goTargetType(target, call *sitter.Node, source []byte) string {
+	if target == nil {
+		return ""
+	}
+	if target.Type() == "unary_expression" {
+		target = target.ChildByFieldName("operand")
+	}
+	if target == nil || target.Type() != "identifier" {
+		return ""
+	}
+	name := target.Content(source)
+
+	scope := enclosingNode(call, goFuncTypes)
+	if scope == nil {
+		return ""
+	}
+	typ := ""
+	// Parameters and var declarations name the type outright
+	findNodes(scope, nodeSet("parameter_declaration", "var_spec"), func(n *sitter.Node) {
+		t := n.ChildByFieldName("type")
+		if t == nil {
+			return
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if id := n.NamedChild(i); id != nil && id.Type() == "identifier" && id.Content(source) == name {
+				typ = t.Content(source)
+			}
+		}
+	})
+	if typ == "" {
+		typ = goValueType(goAssignedValue(name, call, source), source)
+	}
+	return strings.TrimPrefix(strings.Join(strings.Fields(typ), ""), "*")
+}
+
+// goValueType returns the type of a composite literal, &T{...} or make(T),
+// or "".
+func goValueType(value *sitter.Node, source []byte) string {
+	if value == nil {
+		return ""
+	}
+	if value.Type() == "unary_expression" {
+		value = value.ChildByFieldName("operand")
+		if value == nil {
+			return ""
+		}
+	}
+	switch value.Type() {
+	case "composite_literal":
+		if t := value.ChildByFieldName("type"); t != nil {
+			return t.Content(source)
+		}
+	case "call_expression":
+		fn, args := value.ChildByFieldName("function"), value.ChildByFieldName("arguments")
+		if fn != nil && fn.Content(source) == "make" && args != nil && args.NamedChildCount() > 0 {
+			return args.NamedChild(0).Content(source)
+		}
+	}
+	return ""
+}
+
+// hasGoMethod reports whether the file declares method on typ or *typ.
+func hasGoMethod(root *sitter.Node, source []byte, typ, method string) bool {
+	found := false
+	findNodes(root, nodeSet("method_declaration"), func(n *sitter.Node) {
+		name, receiver := n.ChildByFieldName("name"), n.ChildByFieldName("receiver")
+		if name == nil || receiver == nil || name.Content(source) != method {
+			return
+		}
+		findNodes(receiver, nodeSet("type_identifier"), func(t *sitter.Node) {
+			if t.Content(source) == typ {
+				found = true
+			}
+		})
+	})
+	return found
+}
+
+// pythonArgIn reports whether the keyword argument keyword, or the
+// positional argument at index when index >= 0, has one of values.
+func pythonArgIn(args *sitter.Node, source []byte, keyword string, index int, values []string) bool {
+	positional := 0
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		arg := args.NamedChild(i)
+		if arg == nil {
+			continue
+		}
+		var value *sitter.Node
+		if arg.Type() == "keyword_argument" {
+			if name := arg.ChildByFieldName("name"); name != nil && name.Content(source) == keyword {
+				value = arg.ChildByFieldName("value")
+			}
+		} else {
+			if positional == index {
+				value = arg
+			}
+			positional++
+		}
+		if value == nil {
+			continue
+		}
+		for _, v := range values {
+			if value.Content(source) == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package astcheck
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// DuplicateCodeName is the name of the duplicate-code check. Analyzers look
+// for rules naming it to detect clones across every file in a run.
+const DuplicateCodeName = "duplicate-code"
+
+const (
+	defaultMinCloneTokens = 50
+	defaultMinCloneLines  = 6
+)
+
+// DuplicateCode reports functions and blocks whose syntax trees are
+// identical once identifiers, literals and comments are ignored, so copies
+// that were renamed or reformatted are still found. Run compares fragments
+// within one file; a CloneIndex compares them across files.
+//
+// Config keys:
+//   - min_tokens: minimum size of a fragment, in syntax tree leaves (default 50)
+//   - min_lines: minimum length of a fragment, in lines (default 6)
+type DuplicateCode struct{}
+
+func (d *DuplicateCode) Name() string { return DuplicateCodeName }
+
+func (d *DuplicateCode) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	index := NewCloneIndex(config)
+	index.Add("", tree, source, lang)
+
+	var matches []Match
+	for _, group := range index.Groups() {
+		for i, f := range group.Fragments {
+			others := make([]Fragment, 0, len(group.Fragments)-1)
+			others = append(others, group.Fragments[:i]...)
+			others = append(others, group.Fragments[i+1:]...)
+			matches = append(matches, Match{
+				StartLine: f.StartLine,
+				EndLine:   f.EndLine,
+				Message:   CloneMessage(f, others),
+				Extra:     group.Extra(f),
+			})
+		}
+	}
+	return matches
+}
+
+// Fragment is a function or block a CloneIndex fingerprinted.
+type Fragment struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Tokens    int // Leaves of the fragment's syntax tree, comments excluded
+
+	hash uint64
+}
+
+// CloneGroup is a set of fragments with identical normalized syntax trees.
+type CloneGroup struct {
+	ID        string // Hex fingerprint of the shared tree
+	Fragments []Fragment
+}
+
+// Paths returns the number of distinct files the group's fragments are in.
+func (g CloneGroup) Paths() int {
+	paths := make(map[string]bool, len(g.Fragments))
+	for _, f := range g.Fragments {
+		paths[f.Path] = true
+	}
+	return len(paths)
+}
+
+// Extra returns the match properties describing f as a member of g.
+func (g CloneGroup) Extra(f Fragment) map[string]interface{} {
+	return map[string]interface{}{
+		"clone_id":     g.ID,
+		"clone_tokens": f.Tokens,
+		"clone_count":  len(g.Fragments),
+	}
+}
+
+// CloneMessage describes f as a copy of others, naming the first of them.
+// Fragments in f's own file are named by line range alone.
+func CloneMessage(f Fragment, others []Fragment) string {
+	if len(others) == 0 {
+		return fmt.Sprintf("%d-line block is duplicated", f.EndLine-f.StartLine+1)
+	}
+	first := others[0]
+	where := fmt.Sprintf("lines %d-%d", first.StartLine, first.EndLine)
+	if first.Path != f.Path {
+		where = fmt.Sprintf("%s:%d-%d", first.Path, first.StartLine, first.EndLine)
+	}
+	msg := fmt.Sprintf("%d-line block duplicates %s (%d tokens)", f.EndLine-f.StartLine+1, where, f.Tokens)
+	if n := len(others) - 1; n == 1 {
+		msg += " and 1 other fragment"
+	} else if n > 1 {
+		msg += fmt.Sprintf(" and %d other fragments", n)
+	}
+	return msg
+}
+
+// CloneIndex fingerprints the functions and large blocks of the files added
+// to it and groups the ones that are copies of each other. Each syntax tree
+// is hashed bottom-up from node types alone, so identifier names, literal
+// values, comments and formatting do not affect a fingerprint. It is safe
+// for concurrent use.
+type CloneIndex struct {
+	minTokens int
+	minLines  int
+
+	mu     sync.Mutex
+	byHash map[uint64][]Fragment
+}
+
+// NewCloneIndex returns an empty index using the min_tokens and min_lines
+// thresholds in config, which may be nil.
+func NewCloneIndex(config map[string]interface{}) *CloneIndex {
+	return &CloneIndex{
+		minTokens: toInt(config["min_tokens"], defaultMinCloneTokens),
+		minLines:  toInt(config["min_lines"], defaultMinCloneLines),
+		byHash:    make(map[uint64][]Fragment),
+	}
+}
+
+// Add fingerprints the fragments of tree that meet the index's thresholds.
+// Languages without a known grammar are ignored.
+func (x *CloneIndex) Add(path string, tree *sitter.Tree, source []byte, lang string) {
+	funcs, blocks := funcNodeTypes(lang), cloneBlockTypes[lang]
+	if tree == nil || funcs == nil {
+		return
+	}
+
+	var found []Fragment
+	var walk func(n *sitter.Node) (uint64, int)
+	walk = func(n *sitter.Node) (uint64, int) {
+		h := fnv.New64a()
+		h.Write([]byte(n.Type()))
+		h.Write([]byte{0})
+		tokens := 0
+		if n.ChildCount() == 0 {
+			tokens = 1
+		}
+		var buf [8]byte
+		for i := 0; i < int(n.ChildCount()); i++ {
+			child := n.Child(i)
+			if child == nil || child.IsExtra() || strings.Contains(child.Type(), "comment") {
+				continue
+			}
+			childHash, childTokens := walk(child)
+			binary.LittleEndian.PutUint64(buf[:], childHash)
+			h.Write(buf[:])
+			tokens += childTokens
+		}
+		sum := h.Sum64()
+
+		if (funcs[n.Type()] || blocks[n.Type()]) && tokens >= x.minTokens {
+			start, end := int(n.StartPoint().Row)+1, int(n.EndPoint().Row)+1
+			if end-start+1 >= x.minLines {
+				found = append(found, Fragment{Path: path, StartLine: start, EndLine: end, Tokens: tokens, hash: sum})
+			}
+		}
+		return sum, tokens
+	}
+	walk(tree.RootNode())
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, f := range found {
+		x.byHash[f.hash] = append(x.byHash[f.hash], f)
+	}
+}
+
+// Groups returns the fragments added more than once, sorted by path and
+// line. Only the largest copy is reported: a fragment inside a larger one
+// that is itself cloned, such as the body of a copied function, is left out.
+func (x *CloneIndex) Groups() []CloneGroup {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	cloned := make(map[string][]Fragment)
+	for _, frags := range x.byHash {
+		if len(frags) < 2 {
+			continue
+		}
+		for _, f := range frags {
+			cloned[f.Path] = append(cloned[f.Path], f)
+		}
+	}
+	contained := func(f Fragment) bool {
+		for _, g := range cloned[f.Path] {
+			if g.StartLine <= f.StartLine && g.EndLine >= f.EndLine && g.Tokens > f.Tokens {
+				return true
+			}
+		}
+		return false
+	}
+
+	var groups []CloneGroup
+	for hash, frags := range x.byHash {
+		if len(frags) < 2 {
+			continue
+		}
+		var kept []Fragment
+		for _, f := range frags {
+			if !contained(f) {
+				kept = append(kept, f)
+			}
+		}
+		if len(kept) < 2 {
+			continue
+		}
+		sort.Slice(kept, func(i, j int) bool { return fragmentLess(kept[i], kept[j]) })
+		groups = append(groups, CloneGroup{ID: fmt.Sprintf("%016x", hash), Fragments: kept})
+	}
+	sort.Slice(groups, func(i, j int) bool { return fragmentLess(groups[i].Fragments[0], groups[j].Fragments[0]) })
+	return groups
+}
+
+func fragmentLess(a, b Fragment) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.StartLine < b.StartLine
+}
+
+// cloneBlockTypes lists the block node types fingerprinted alongside whole
+// functions, so a copied loop or branch body is found too.
+var cloneBlockTypes = map[string]map[string]bool{
+	"go":         nodeSet("block"),
+	"python":     nodeSet("block"),
+	"javascript": nodeSet("statement_block"),
+	"typescript": nodeSet("statement_block"),
+	"java":       nodeSet("block"),
+	"c":          nodeSet("compound_statement"),
+	"rust":       nodeSet("block"),
+	"ruby":       nodeSet("body_statement", "then", "do_block"),
+	"php":        nodeSet("compound_statement"),
+	"kotlin":     nodeSet("statements"),
+	"csharp":     nodeSet("block"),
+}
@@ -0,0 +1,97 @@
+package astcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+const cloneA = `package p
+
+func total(items []int) int {
+	sum := 0
+	for _, item := range items {
+		if item > 0 {
+			sum += item
+		}
+	}
+	return sum
+}
+`
+
+// cloneB is cloneA renamed, with different literals, a comment and other
+// formatting.
+const cloneB = `package q
+
+// count adds up the scores above the cut-off.
+func count(scores []int) int {
+	n := 10
+	for _, s := range scores {
+		if s > 5 {
+			n += s // keep it
+		}
+	}
+
+	return n
+}
+`
+
+var smallClones = map[string]interface{}{"min_tokens": 20, "min_lines": 4}
+
+func TestDuplicateCodeName(t *testing.T) {
+	if got := (&DuplicateCode{}).Name(); got != "duplicate-code" {
+		t.Errorf("expected 'duplicate-code', got %q", got)
+	}
+}
+
+func TestDuplicateCodeWithinFile(t *testing.T) {
+	src := cloneA + strings.TrimPrefix(cloneB, "package q\n")
+	tree := parseGo(t, src)
+	matches := (&DuplicateCode{}).Run(tree, []byte(src), "go", smallClones)
+	if len(matches) != 2 {
+		t.Fatalf("expected the two functions to match, got %d: %v", len(matches), matches)
+	}
+	if matches[0].StartLine != 3 || matches[0].EndLine != 11 || matches[1].StartLine != 14 {
+		t.Errorf("unexpected lines %d-%d and %d", matches[0].StartLine, matches[0].EndLine, matches[1].StartLine)
+	}
+	if !strings.Contains(matches[0].Message, "duplicates lines 14-23") {
+		t.Errorf("unexpected message %q", matches[0].Message)
+	}
+	if matches[0].Extra["clone_id"] != matches[1].Extra["clone_id"] || matches[0].Extra["clone_count"] != 2 {
+		t.Errorf("unexpected extra %v and %v", matches[0].Extra, matches[1].Extra)
+	}
+}
+
+func TestDuplicateCodeThresholds(t *testing.T) {
+	src := cloneA + strings.TrimPrefix(cloneB, "package q\n")
+	tree := parseGo(t, src)
+	if matches := (&DuplicateCode{}).Run(tree, []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected functions under the default min_tokens to be ignored, got %v", matches)
+	}
+
+	different := cloneA + "\nfunc other(items []int) int {\n\tfor i := range items {\n\t\titems[i] = 0\n\t}\n\treturn len(items)\n}\n"
+	tree = parseGo(t, different)
+	if matches := (&DuplicateCode{}).Run(tree, []byte(different), "go", smallClones); len(matches) != 0 {
+		t.Errorf("expected no clones between different functions, got %v", matches)
+	}
+}
+
+func TestCloneIndexAcrossFiles(t *testing.T) {
+	index := NewCloneIndex(smallClones)
+	index.Add("a.go", parseGo(t, cloneA), []byte(cloneA), "go")
+	index.Add("b.go", parseGo(t, cloneB), []byte(cloneB), "go")
+	python := "def total(items):\n    s = 0\n    for i in items:\n        if i > 0:\n            s += i\n    return s\n"
+	index.Add("c.py", parsePython(t, python), []byte(python), "python")
+
+	groups := index.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 clone group, got %d: %+v", len(groups), groups)
+	}
+	g := groups[0]
+	if g.Paths() != 2 || g.Fragments[0].Path != "a.go" || g.Fragments[1].Path != "b.go" {
+		t.Fatalf("unexpected group %+v", g)
+	}
+	msg := CloneMessage(g.Fragments[0], g.Fragments[1:])
+	if msg != "9-line block duplicates b.go:4-13 (39 tokens)" {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
@@ -18,8 +18,12 @@ func (e *EmptyHandler) Run(tree *sitter.Tree, source []byte, lang string, config
 		return e.checkGo(tree.RootNode(), source)
 	case "python":
 		return e.checkPython(tree.RootNode(), source)
-	case "javascript", "typescript", "java":
+	case "javascript", "typescript", "java", "php", "csharp":
 		return e.checkCatchClause(tree.RootNode(), source)
+	case "ruby":
+		return e.checkRuby(tree.RootNode(), source)
+	case "kotlin":
+		return e.checkKotlin(tree.RootNode(), source)
 	default:
 		return nil
 	}
@@ -125,3 +129,48 @@ func (e *EmptyHandler) checkCatchClause(root *sitter.Node, source []byte) []Matc
 
 	return matches
 }
+
+// checkRuby finds rescue clauses with empty bodies.
+func (e *EmptyHandler) checkRuby(root *sitter.Node, source []byte) []Match {
+	var matches []Match
+	nodeTypes := map[string]bool{"rescue": true}
+
+	findNodes(root, nodeTypes, func(node *sitter.Node) {
+		if body := node.ChildByFieldName("body"); body != nil && body.NamedChildCount() > 0 {
+			return
+		}
+		matches = append(matches, Match{
+			StartLine: int(node.StartPoint().Row) + 1,
+			EndLine:   int(node.EndPoint().Row) + 1,
+			Message:   fmt.Sprintf("empty rescue handler at line %d", node.StartPoint().Row+1),
+			Extra: map[string]interface{}{
+				"pattern": "rescue; end",
+			},
+		})
+	})
+
+	return matches
+}
+
+// checkKotlin finds catch blocks with empty bodies. The Kotlin grammar has no
+// body field, so a catch block is empty when it has no statements child.
+func (e *EmptyHandler) checkKotlin(root *sitter.Node, source []byte) []Match {
+	var matches []Match
+	nodeTypes := map[string]bool{"catch_block": true}
+
+	findNodes(root, nodeTypes, func(node *sitter.Node) {
+		if childOfType(node, "statements") != nil {
+			return
+		}
+		matches = append(matches, Match{
+			StartLine: int(node.StartPoint().Row) + 1,
+			EndLine:   int(node.EndPoint().Row) + 1,
+			Message:   fmt.Sprintf("empty catch handler at line %d", node.StartPoint().Row+1),
+			Extra: map[string]interface{}{
+				"pattern": "catch {}",
+			},
+		})
+	})
+
+	return matches
+}
@@ -0,0 +1,41 @@
+package astcheck
+
+import (
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Function is a function or method declared in a source file.
+type Function struct {
+	Name      string
+	StartLine int // 1-indexed
+	EndLine   int // 1-indexed, inclusive
+}
+
+// Functions returns the functions and methods of a file that are not nested
+// in another function, in source order. Methods of a class are included;
+// closures inside a function are part of it. Returns nil if the language is
+// unsupported or parsing fails.
+func Functions(path string, source []byte) []Function {
+	tree := ParseTree(path, source)
+	if tree == nil {
+		return nil
+	}
+	_, lang, _ := Detect(path)
+
+	var fns []Function
+	findOutermostNodes(tree.RootNode(), funcNodeTypes(lang), func(n *sitter.Node) {
+		name := funcName(n, source)
+		// const handler = () => {...} names the arrow function by its variable
+		if parent := n.Parent(); name == "<anonymous>" && parent != nil && parent.Type() == "variable_declarator" {
+			if nameNode := parent.ChildByFieldName("name"); nameNode != nil {
+				name = nameNode.Content(source)
+			}
+		}
+		fns = append(fns, Function{
+			Name:      name,
+			StartLine: int(n.StartPoint().Row) + 1,
+			EndLine:   int(n.EndPoint().Row) + 1,
+		})
+	})
+	return fns
+}
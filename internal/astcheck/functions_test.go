@@ -0,0 +1,49 @@
+package astcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFunctions_Go(t *testing.T) {
+	src := `package main
+
+func Hello() {
+	f := func() {}
+	f()
+}
+
+type S struct{}
+
+func (s S) Bye() {}
+`
+	got := Functions("main.go", []byte(src))
+	want := []Function{{Name: "Hello", StartLine: 3, EndLine: 6}, {Name: "Bye", StartLine: 10, EndLine: 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Functions = %+v, want %+v", got, want)
+	}
+}
+
+func TestFunctions_JavaScript(t *testing.T) {
+	src := `const handler = (req) => {
+  return req;
+};
+
+class Service {
+  start() {
+    return [1].map((x) => x);
+  }
+}
+`
+	got := Functions("app.js", []byte(src))
+	want := []Function{{Name: "handler", StartLine: 1, EndLine: 3}, {Name: "start", StartLine: 6, EndLine: 8}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Functions = %+v, want %+v", got, want)
+	}
+}
+
+func TestFunctions_Unsupported(t *testing.T) {
+	if got := Functions("notes.txt", []byte("hello\n")); got != nil {
+		t.Errorf("expected nil for an unsupported language, got %v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package astcheck
+
+import (
+	"fmt"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// GoroutineLeak flags goroutines that send on an unbuffered channel outside
+// a select when nothing guarantees the send is received. Such a goroutine
+// blocks forever once its receiver stops listening, as when the enclosing
+// function selects on the channel alongside a timeout or ctx.Done() and
+// returns early.
+//
+// Only channels made with make(chan T) or make(chan T, 0) in the function
+// that starts the goroutine are considered. The send is assumed to be
+// received when that function also receives from the channel outside any
+// select (a plain <-ch or a range loop), or lets the channel escape by
+// returning it or passing it to another call.
+type GoroutineLeak struct{}
+
+func (g *GoroutineLeak) Name() string { return "goroutine-leak" }
+
+func (g *GoroutineLeak) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	if lang != "go" {
+		return nil
+	}
+
+	var matches []Match
+	findNodes(tree.RootNode(), nodeSet("go_statement"), func(goStmt *sitter.Node) {
+		body := goroutineBody(goStmt)
+		enclosing := enclosingNode(goStmt, goFuncTypes)
+		if body == nil || enclosing == nil {
+			return
+		}
+
+		reported := make(map[string]bool)
+		findNodes(body, nodeSet("send_statement"), func(send *sitter.Node) {
+			ch := send.ChildByFieldName("channel")
+			if ch == nil || ch.Type() != "identifier" || insideSelect(send, body) {
+				return
+			}
+			name := ch.Content(source)
+			if reported[name] || !makesUnbufferedChan(enclosing, source, name) || receivedElsewhere(enclosing, goStmt, source, name) {
+				return
+			}
+			reported[name] = true
+			line := int(send.StartPoint().Row) + 1
+			matches = append(matches, Match{
+				StartLine: line,
+				EndLine:   int(send.EndPoint().Row) + 1,
+				Message:   fmt.Sprintf("goroutine sends on unbuffered channel %q outside a select; it blocks forever if the receiver gives up", name),
+				Extra: map[string]interface{}{
+					"channel": name,
+				},
+			})
+		})
+	})
+	return matches
+}
+
+// enclosingNode returns the nearest ancestor of node whose type is in types.
+func enclosingNode(node *sitter.Node, types map[string]bool) *sitter.Node {
+	for p := node.Parent(); p != nil; p = p.Parent() {
+		if types[p.Type()] {
+			return p
+		}
+	}
+	return nil
+}
+
+// insideSelect reports whether node sits in a select statement below root.
+func insideSelect(node, root *sitter.Node) bool {
+	for p := node.Parent(); p != nil && !p.Equal(root); p = p.Parent() {
+		if p.Type() == "select_statement" {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether node lies inside outer.
+func within(node, outer *sitter.Node) bool {
+	return node.StartByte() >= outer.StartByte() && node.EndByte() <= outer.EndByte()
+}
+
+// makesUnbufferedChan reports whether fn assigns name from make(chan T) or
+// make(chan T, 0).
+func makesUnbufferedChan(fn *sitter.Node, source []byte, name string) bool {
+	found := false
+	findNodes(fn, nodeSet("short_var_declaration", "assignment_statement", "var_spec"), func(n *sitter.Node) {
+		left, right := n.ChildByFieldName("left"), n.ChildByFieldName("right")
+		if n.Type() == "var_spec" {
+			left, right = n, n.ChildByFieldName("value")
+		}
+		if left == nil || right == nil {
+			return
+		}
+		var names []*sitter.Node
+		for i := 0; i < int(left.NamedChildCount()); i++ {
+			if id := left.NamedChild(i); id != nil && id.Type() == "identifier" {
+				names = append(names, id)
+			}
+		}
+		for i, id := range names {
+			if id.Content(source) != name || i >= int(right.NamedChildCount()) {
+				continue
+			}
+			if isUnbufferedMake(right.NamedChild(i), source) {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+func isUnbufferedMake(call *sitter.Node, source []byte) bool {
+	if call == nil || call.Type() != "call_expression" {
+		return false
+	}
+	fn, args := call.ChildByFieldName("function"), call.ChildByFieldName("arguments")
+	if fn == nil || fn.Content(source) != "make" || args == nil || args.NamedChildCount() == 0 {
+		return false
+	}
+	if typ := args.NamedChild(0); typ == nil || typ.Type() != "channel_type" {
+		return false
+	}
+	switch args.NamedChildCount() {
+	case 1:
+		return true
+	case 2:
+		return args.NamedChild(1).Content(source) == "0"
+	}
+	return false
+}
+
+// receivedElsewhere reports whether fn, outside goStmt, receives from the
+// channel name where the receive cannot be abandoned, or lets the channel
+// escape to code that might.
+func receivedElsewhere(fn, goStmt *sitter.Node, source []byte, name string) bool {
+	found := false
+	findNodes(fn, nodeSet("unary_expression", "range_clause", "return_statement", "argument_list"), func(n *sitter.Node) {
+		if found || within(n, goStmt) {
+			return
+		}
+		switch n.Type() {
+		case "unary_expression":
+			op, operand := n.ChildByFieldName("operator"), n.ChildByFieldName("operand")
+			found = op != nil && op.Type() == "<-" && operand != nil && operand.Content(source) == name && !insideSelect(n, fn)
+		case "range_clause":
+			right := n.ChildByFieldName("right")
+			found = right != nil && right.Content(source) == name
+		case "return_statement":
+			found = mentionsIdentifier(n, source, []string{name})
+		case "argument_list":
+			call := n.Parent()
+			if callee := call.ChildByFieldName("function"); callee != nil && callee.Content(source) == "close" {
+				return
+			}
+			for i := 0; i < int(n.NamedChildCount()); i++ {
+				if arg := n.NamedChild(i); arg != nil && arg.Content(source) == name {
+					found = true
+				}
+			}
+		}
+	})
+	return found
+}
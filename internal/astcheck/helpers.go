@@ -2,13 +2,15 @@ package astcheck
 
 import sitter "github.com/smacker/go-tree-sitter"
 
-// findNodes performs a recursive DFS and calls fn for every node whose Type()
-// is in the nodeTypes set.
+// findNodes performs a recursive DFS and calls fn for every named node whose
+// Type() is in the nodeTypes set. Anonymous nodes are skipped because some
+// grammars (e.g. Ruby) give keyword tokens the same type as the construct
+// they open.
 func findNodes(node *sitter.Node, nodeTypes map[string]bool, fn func(*sitter.Node)) {
 	if node == nil {
 		return
 	}
-	if nodeTypes[node.Type()] {
+	if node.IsNamed() && nodeTypes[node.Type()] {
 		fn(node)
 	}
 	for i := 0; i < int(node.ChildCount()); i++ {
@@ -48,6 +50,26 @@ func funcNodeTypes(lang string) map[string]bool {
 		return map[string]bool{
 			"function_item": true,
 		}
+	case "ruby":
+		return map[string]bool{
+			"method":           true,
+			"singleton_method": true,
+		}
+	case "php":
+		return map[string]bool{
+			"function_definition": true,
+			"method_declaration":  true,
+		}
+	case "kotlin":
+		return map[string]bool{
+			"function_declaration": true,
+		}
+	case "csharp":
+		return map[string]bool{
+			"method_declaration":       true,
+			"constructor_declaration":  true,
+			"local_function_statement": true,
+		}
 	default:
 		return nil
 	}
@@ -56,12 +78,35 @@ func funcNodeTypes(lang string) map[string]bool {
 // funcName extracts a human-readable function name from a function node.
 func funcName(node *sitter.Node, source []byte) string {
 	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		// The Kotlin grammar has no field names; the name is the first
+		// simple_identifier child.
+		nameNode = childOfType(node, "simple_identifier")
+	}
 	if nameNode != nil {
 		return nameNode.Content(source)
 	}
 	return "<anonymous>"
 }
 
+// funcParams returns the parameter list node of a function node, or nil.
+func funcParams(node *sitter.Node) *sitter.Node {
+	if params := node.ChildByFieldName("parameters"); params != nil {
+		return params
+	}
+	return childOfType(node, "function_value_parameters") // Kotlin
+}
+
+// childOfType returns the first named child of node with the given type.
+func childOfType(node *sitter.Node, nodeType string) *sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child != nil && child.Type() == nodeType {
+			return child
+		}
+	}
+	return nil
+}
+
 // FunctionContext describes the enclosing function (and optional class) for a
 // source line. It is used to populate SARIF logicalLocations.
 type FunctionContext struct {
@@ -180,6 +225,10 @@ var classContainerTypes = map[string]map[string]bool{
 	"typescript": {"class_declaration": true, "class": true},
 	"java":       {"class_declaration": true, "interface_declaration": true},
 	"rust":       {"impl_item": true},
+	"ruby":       {"class": true, "module": true},
+	"php":        {"class_declaration": true, "interface_declaration": true, "trait_declaration": true},
+	"kotlin":     {"class_declaration": true, "object_declaration": true},
+	"csharp":     {"class_declaration": true, "struct_declaration": true, "interface_declaration": true, "record_declaration": true},
 }
 
 // findEnclosingClass walks up from node to find the nearest class/struct container.
@@ -215,6 +264,11 @@ func extractNodeName(node *sitter.Node, source []byte, lang string) string {
 	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
 		return nameNode.Content(source)
 	}
+	if lang == "kotlin" {
+		if nameNode := childOfType(node, "type_identifier"); nameNode != nil {
+			return nameNode.Content(source)
+		}
+	}
 	return ""
 }
 
@@ -0,0 +1,110 @@
+package astcheck
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TokenKind classifies a span of source for syntax highlighting.
+type TokenKind string
+
+const (
+	TokenKeyword TokenKind = "keyword"
+	TokenString  TokenKind = "string"
+	TokenNumber  TokenKind = "number"
+	TokenComment TokenKind = "comment"
+	TokenType    TokenKind = "type"
+)
+
+// HighlightSpan is a byte range of source and how to highlight it.
+type HighlightSpan struct {
+	Start int
+	End   int
+	Kind  TokenKind
+}
+
+// Highlight classifies the keywords, literals, comments and type names of a
+// source file, returning non-overlapping spans in source order. Text outside
+// the spans is left plain. Returns nil if the language is unsupported or
+// parsing fails; files with syntax errors are still highlighted as far as
+// the parser recovered.
+func Highlight(path string, source []byte) []HighlightSpan {
+	tree := ParseTree(path, source)
+	if tree == nil {
+		return nil
+	}
+	var spans []HighlightSpan
+	highlightNode(tree.RootNode(), source, &spans)
+	return spans
+}
+
+func highlightNode(n *sitter.Node, source []byte, spans *[]HighlightSpan) {
+	if n.StartByte() == n.EndByte() {
+		return
+	}
+	if kind, ok := tokenKind(n, source); ok {
+		*spans = append(*spans, HighlightSpan{Start: int(n.StartByte()), End: int(n.EndByte()), Kind: kind})
+		return
+	}
+	for i := 0; i < int(n.ChildCount()); i++ {
+		highlightNode(n.Child(i), source, spans)
+	}
+}
+
+// literalConstants are named nodes for literal values such as true and nil,
+// which are highlighted like keywords.
+var literalConstants = map[string]bool{
+	"true": true, "false": true, "nil": true, "null": true, "none": true,
+	"undefined": true, "self": true, "this": true, "iota": true,
+}
+
+// typeNodes are node types that name a type.
+var typeNodes = map[string]bool{
+	"type_identifier": true, "primitive_type": true, "predefined_type": true,
+	"integral_type": true, "floating_point_type": true, "boolean_type": true,
+	"void_type": true, "builtin_type": true,
+}
+
+// tokenKind returns how n is highlighted as a whole, or false to look at its
+// children instead.
+func tokenKind(n *sitter.Node, source []byte) (TokenKind, bool) {
+	t := n.Type()
+	switch {
+	case isComment(n):
+		return TokenComment, true
+	case n.IsNamed() && (strings.Contains(t, "string") || t == "char_literal" || t == "character_literal" || t == "rune_literal"):
+		// Whole literals, including interpolations and escape sequences
+		return TokenString, true
+	case n.IsNamed() && isNumberNode(t):
+		return TokenNumber, true
+	case typeNodes[t]:
+		return TokenType, true
+	case literalConstants[t]:
+		return TokenKeyword, true
+	case !n.IsNamed() && n.ChildCount() == 0 && isWord(n.Content(source)):
+		// Anonymous word tokens are the grammar's keywords: func, return, if
+		return TokenKeyword, true
+	}
+	return "", false
+}
+
+func isNumberNode(t string) bool {
+	switch t {
+	case "integer", "float", "number", "decimal", "hex_integer_literal", "octal_integer_literal", "binary_integer_literal":
+		return true
+	}
+	return strings.HasSuffix(t, "_literal") && (strings.Contains(t, "int") || strings.Contains(t, "float") || strings.Contains(t, "number") || strings.Contains(t, "imaginary"))
+}
+
+func isWord(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
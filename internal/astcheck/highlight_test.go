@@ -0,0 +1,38 @@
+package astcheck
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	src := "package main\n\n// greet says hello\nfunc greet(n int) string {\n\tif n > 1 {\n\t\treturn \"hi\\n\"\n\t}\n\treturn nil\n}\n"
+	got := map[string]TokenKind{}
+	last := -1
+	for _, s := range Highlight("main.go", []byte(src)) {
+		if s.Start < last {
+			t.Fatalf("spans overlap or are out of order at %d", s.Start)
+		}
+		last = s.End
+		got[src[s.Start:s.End]] = s.Kind
+	}
+	for text, want := range map[string]TokenKind{
+		"package":             TokenKeyword,
+		"func":                TokenKeyword,
+		"return":              TokenKeyword,
+		"nil":                 TokenKeyword,
+		"// greet says hello": TokenComment,
+		`"hi\n"`:              TokenString,
+		"1":                   TokenNumber,
+		"int":                 TokenType,
+		"string":              TokenType,
+	} {
+		if got[text] != want {
+			t.Errorf("%q highlighted as %q, want %q", text, got[text], want)
+		}
+	}
+	if _, ok := got["greet"]; ok {
+		t.Error("identifiers should be left plain")
+	}
+
+	if Highlight("notes.txt", []byte("plain text")) != nil {
+		t.Error("expected no spans for an unsupported language")
+	}
+}
@@ -6,10 +6,14 @@ import (
 
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/csharp"
 	"github.com/smacker/go-tree-sitter/golang"
 	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/kotlin"
+	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/ruby"
 	"github.com/smacker/go-tree-sitter/rust"
 	typescript "github.com/smacker/go-tree-sitter/typescript/typescript"
 )
@@ -33,6 +37,11 @@ func init() {
 		".c":   {language: c.GetLanguage(), name: "c"},
 		".h":   {language: c.GetLanguage(), name: "c"},
 		".rs":  {language: rust.GetLanguage(), name: "rust"},
+		".rb":  {language: ruby.GetLanguage(), name: "ruby"},
+		".php": {language: php.GetLanguage(), name: "php"},
+		".kt":  {language: kotlin.GetLanguage(), name: "kotlin"},
+		".kts": {language: kotlin.GetLanguage(), name: "kotlin"},
+		".cs":  {language: csharp.GetLanguage(), name: "csharp"},
 	}
 }
 
@@ -46,3 +55,26 @@ func Detect(path string) (*sitter.Language, string, bool) {
 	}
 	return entry.language, entry.name, true
 }
+
+// languageAliases maps the short names rule files may use in languages to
+// the names Detect returns.
+var languageAliases = map[string]string{
+	"js": "javascript",
+	"ts": "typescript",
+	"cs": "csharp",
+}
+
+// LanguageByName returns the tree-sitter Language for a language name as
+// returned by Detect (or a short alias such as "js"), along with the
+// canonical name.
+func LanguageByName(name string) (*sitter.Language, string, bool) {
+	if canonical, ok := languageAliases[name]; ok {
+		name = canonical
+	}
+	for _, entry := range extToLang {
+		if entry.name == name {
+			return entry.language, entry.name, true
+		}
+	}
+	return nil, "", false
+}
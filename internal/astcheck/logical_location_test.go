@@ -161,6 +161,31 @@ function freeFunction() {
 	}
 }
 
+func TestFindEnclosingFunction_OtherLanguages(t *testing.T) {
+	tests := []struct {
+		path, src           string
+		line                int
+		wantFunc, wantClass string
+	}{
+		{"a.rb", "module Billing\n  def charge(amount)\n    amount\n  end\nend\n", 3, "charge", "Billing"},
+		{"a.php", "<?php\nclass Invoice {\n  public function total() {\n    return 1;\n  }\n}\n", 4, "total", "Invoice"},
+		{"a.kt", "class Invoice {\n    fun total(): Int {\n        return 1\n    }\n}\n", 3, "total", "Invoice"},
+		{"a.cs", "record Invoice {\n  int Total() {\n    return 1;\n  }\n}\n", 3, "Total", "Invoice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tree, lang := parseFile(t, tt.path, tt.src)
+			fc := FindEnclosingFunction(tree.RootNode(), []byte(tt.src), lang, tt.line)
+			if fc == nil {
+				t.Fatal("expected non-nil FunctionContext")
+			}
+			if fc.FuncName != tt.wantFunc || fc.ClassName != tt.wantClass {
+				t.Errorf("got %q in %q, want %q in %q", fc.FuncName, fc.ClassName, tt.wantFunc, tt.wantClass)
+			}
+		})
+	}
+}
+
 func TestFindEnclosingFunction_UnsupportedLanguage(t *testing.T) {
 	src := `package main
 func foo() {}
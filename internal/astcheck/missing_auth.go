@@ -0,0 +1,223 @@
+package astcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+var (
+	defaultSensitivePaths = []string{"/admin", "/internal", "/debug", "/manage"}
+	defaultAuthIndicators = []string{"auth", "jwt", "session", "token", "permission", "rbac"}
+	defaultRouteFuncs     = []string{
+		"Handle", "HandleFunc", "Group", "Route", "Mount", "Any",
+		"Get", "Post", "Put", "Patch", "Delete",
+		"GET", "POST", "PUT", "PATCH", "DELETE",
+	}
+)
+
+// MissingAuthRoute flags HTTP handlers registered on sensitive paths (such
+// as /admin or /internal) when nothing in the registration suggests an
+// authentication guard. A registration counts as guarded when an identifier
+// containing one of the auth indicators (case-insensitive) appears in:
+//   - the registration call's arguments, e.g. requireAuth(adminHandler)
+//     or a middleware passed to r.Group("/admin", authMiddleware)
+//   - the handler body, for func literals and for functions or methods
+//     declared in the same file
+//   - a .Use(...) call in the function that registers the route
+//
+// It is advisory: guards applied in another file or by a reverse proxy are
+// not visible to it.
+//
+// Config keys:
+//   - paths: path prefixes treated as sensitive, matched on whole segments
+//     anywhere in the pattern ("/admin" matches "GET /api/admin/{id}")
+//   - auth_indicators: name fragments that mark an identifier as an auth check
+//   - route_funcs: method or function names that register a route
+type MissingAuthRoute struct{}
+
+func (m *MissingAuthRoute) Name() string { return "missing-auth-route" }
+
+func (m *MissingAuthRoute) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	if lang != "go" {
+		return nil
+	}
+
+	paths := toStringSet(config, "paths", defaultSensitivePaths)
+	indicators := toStringSet(config, "auth_indicators", defaultAuthIndicators)
+	routeFuncs := toStringSet(config, "route_funcs", defaultRouteFuncs)
+
+	root := tree.RootNode()
+	handlers := collectGoFuncBodies(root, source)
+
+	var matches []Match
+	findNodes(root, map[string]bool{"call_expression": true}, func(call *sitter.Node) {
+		fn := call.ChildByFieldName("function")
+		args := call.ChildByFieldName("arguments")
+		if fn == nil || args == nil || args.NamedChildCount() == 0 || !routeFuncs[lastSegment(fn, source)] {
+			return
+		}
+		route, ok := stringLiteralValue(args.NamedChild(0), source)
+		if !ok {
+			return
+		}
+		pattern := sensitivePathMatch(route, paths)
+		if pattern == "" {
+			return
+		}
+		if referencesAuth(args, source, indicators) ||
+			handlerReferencesAuth(args, source, handlers, indicators) ||
+			usesAuthMiddleware(enclosingGoFunc(call), source, indicators) {
+			return
+		}
+
+		line := int(call.StartPoint().Row) + 1
+		matches = append(matches, Match{
+			StartLine: line,
+			EndLine:   int(call.EndPoint().Row) + 1,
+			Message:   fmt.Sprintf("route %q registered at line %d has no visible authentication check", route, line),
+			Extra: map[string]interface{}{
+				"route":    route,
+				"pattern":  pattern,
+				"function": fn.Content(source),
+			},
+		})
+	})
+
+	return matches
+}
+
+// stringLiteralValue returns the unquoted value of a Go string literal node.
+func stringLiteralValue(n *sitter.Node, source []byte) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	switch n.Type() {
+	case "interpreted_string_literal":
+		s, err := strconv.Unquote(n.Content(source))
+		return s, err == nil
+	case "raw_string_literal":
+		return strings.Trim(n.Content(source), "`"), true
+	}
+	return "", false
+}
+
+// sensitivePathMatch returns the configured path that route falls under, or
+// "". A leading method ("GET /admin") is ignored, and paths match on whole
+// segments so "/admin" does not match "/administrators".
+func sensitivePathMatch(route string, paths map[string]bool) string {
+	if fields := strings.Fields(route); len(fields) > 0 {
+		route = fields[len(fields)-1]
+	}
+	normalized := "/" + strings.Trim(route, "/") + "/"
+	best := ""
+	for p := range paths {
+		trimmed := strings.Trim(p, "/")
+		if trimmed == "" {
+			continue
+		}
+		// Prefer the longest match so the result is deterministic.
+		if strings.Contains(normalized, "/"+trimmed+"/") && len(p) > len(best) {
+			best = p
+		}
+	}
+	return best
+}
+
+// referencesAuth reports whether any identifier under n contains one of the
+// auth indicators.
+func referencesAuth(n *sitter.Node, source []byte, indicators map[string]bool) bool {
+	if n == nil {
+		return false
+	}
+	identTypes := map[string]bool{
+		"identifier":         true,
+		"field_identifier":   true,
+		"type_identifier":    true,
+		"package_identifier": true,
+	}
+	found := false
+	findNodes(n, identTypes, func(id *sitter.Node) {
+		if found {
+			return
+		}
+		name := strings.ToLower(id.Content(source))
+		for ind := range indicators {
+			if strings.Contains(name, strings.ToLower(ind)) {
+				found = true
+				return
+			}
+		}
+	})
+	return found
+}
+
+// handlerReferencesAuth reports whether a handler argument declared in this
+// file references an auth indicator in its body. Func literals are already
+// covered by referencesAuth on the arguments, so only named handlers
+// (adminHandler, h.Admin) are resolved here.
+func handlerReferencesAuth(args *sitter.Node, source []byte, bodies map[string]*sitter.Node, indicators map[string]bool) bool {
+	for i := 1; i < int(args.NamedChildCount()); i++ {
+		arg := args.NamedChild(i)
+		if arg == nil {
+			continue
+		}
+		// http.HandlerFunc(adminPage) wraps the handler in a conversion.
+		if arg.Type() == "call_expression" {
+			if inner := arg.ChildByFieldName("arguments"); inner != nil && inner.NamedChildCount() == 1 {
+				arg = inner.NamedChild(0)
+			}
+		}
+		if body := bodies[lastSegment(arg, source)]; body != nil && referencesAuth(body, source, indicators) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesAuthMiddleware reports whether fn installs an auth middleware with a
+// .Use(...) call, as in `r.Use(authMiddleware)` before routes are added.
+func usesAuthMiddleware(fn *sitter.Node, source []byte, indicators map[string]bool) bool {
+	if fn == nil {
+		return false
+	}
+	found := false
+	findNodes(fn, map[string]bool{"call_expression": true}, func(call *sitter.Node) {
+		if found {
+			return
+		}
+		callee := call.ChildByFieldName("function")
+		if callee == nil || lastSegment(callee, source) != "Use" {
+			return
+		}
+		found = referencesAuth(call.ChildByFieldName("arguments"), source, indicators)
+	})
+	return found
+}
+
+// collectGoFuncBodies maps the names of functions and methods declared in
+// the file to their bodies. Methods are keyed by name alone, so h.Admin
+// resolves to any method named Admin.
+func collectGoFuncBodies(root *sitter.Node, source []byte) map[string]*sitter.Node {
+	bodies := make(map[string]*sitter.Node)
+	findNodes(root, funcNodeTypes("go"), func(fn *sitter.Node) {
+		if body := fn.ChildByFieldName("body"); body != nil {
+			bodies[funcName(fn, source)] = body
+		}
+	})
+	return bodies
+}
+
+// enclosingGoFunc returns the innermost Go function, method or func literal
+// containing n, or nil at package level.
+func enclosingGoFunc(n *sitter.Node) *sitter.Node {
+	fnTypes := funcNodeTypes("go")
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if fnTypes[p.Type()] || p.Type() == "func_literal" {
+			return p
+		}
+	}
+	return nil
+}
@@ -9,6 +9,11 @@ import (
 const defaultMaxDepth = 4
 
 // NestingDepth checks that control-flow nesting does not exceed a configurable depth.
+//
+// Config keys:
+//   - max_depth: maximum allowed nesting depth (default 4)
+//   - count_case: when true, each case/default clause of a switch or select
+//     adds a nesting level on top of the switch itself (default false)
 type NestingDepth struct{}
 
 func (n *NestingDepth) Name() string { return "nesting-depth" }
@@ -25,6 +30,11 @@ func (n *NestingDepth) Run(tree *sitter.Tree, source []byte, lang string, config
 	if nodeTypes == nil {
 		return nil
 	}
+	if countCase, _ := config["count_case"].(bool); countCase {
+		for t := range caseNodeTypes(lang) {
+			nodeTypes[t] = true
+		}
+	}
 
 	var matches []Match
 	walkNesting(tree.RootNode(), nodeTypes, 0, maxDepth, &matches)
@@ -35,10 +45,11 @@ func nestingNodeTypes(lang string) map[string]bool {
 	switch lang {
 	case "go":
 		return map[string]bool{
-			"if_statement":     true,
-			"for_statement":    true,
-			"switch_statement": true,
-			"select_statement": true,
+			"if_statement":                true,
+			"for_statement":               true,
+			"expression_switch_statement": true,
+			"type_switch_statement":       true,
+			"select_statement":            true,
 		}
 	case "python":
 		return map[string]bool{
@@ -78,6 +89,57 @@ func nestingNodeTypes(lang string) map[string]bool {
 			"match_expression": true,
 			"loop_expression":  true,
 		}
+	case "ruby":
+		return map[string]bool{
+			"if":     true,
+			"unless": true,
+			"while":  true,
+			"until":  true,
+			"for":    true,
+			"case":   true,
+		}
+	case "php":
+		return map[string]bool{
+			"if_statement":      true,
+			"for_statement":     true,
+			"foreach_statement": true,
+			"while_statement":   true,
+			"do_statement":      true,
+			"switch_statement":  true,
+		}
+	case "kotlin":
+		return map[string]bool{
+			"if_expression":      true,
+			"for_statement":      true,
+			"while_statement":    true,
+			"do_while_statement": true,
+			"when_expression":    true,
+		}
+	case "csharp":
+		return map[string]bool{
+			"if_statement":      true,
+			"for_statement":     true,
+			"foreach_statement": true,
+			"while_statement":   true,
+			"do_statement":      true,
+			"switch_statement":  true,
+		}
+	default:
+		return nil
+	}
+}
+
+// caseNodeTypes returns the clause node types that add a nesting level when
+// count_case is enabled.
+func caseNodeTypes(lang string) map[string]bool {
+	switch lang {
+	case "go":
+		return map[string]bool{
+			"expression_case":    true,
+			"type_case":          true,
+			"communication_case": true,
+			"default_case":       true,
+		}
 	default:
 		return nil
 	}
@@ -89,7 +151,7 @@ func walkNesting(node *sitter.Node, nodeTypes map[string]bool, depth, maxDepth i
 	}
 
 	currentDepth := depth
-	if nodeTypes[node.Type()] {
+	if node.IsNamed() && nodeTypes[node.Type()] {
 		currentDepth++
 		if currentDepth > maxDepth {
 			*matches = append(*matches, Match{
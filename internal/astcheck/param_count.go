@@ -28,7 +28,7 @@ func (p *ParamCount) Run(tree *sitter.Tree, source []byte, lang string, config m
 
 	var matches []Match
 	findNodes(tree.RootNode(), nodeTypes, func(node *sitter.Node) {
-		paramsNode := node.ChildByFieldName("parameters")
+		paramsNode := funcParams(node)
 		if paramsNode == nil {
 			return
 		}
@@ -56,6 +56,8 @@ func countParams(paramsNode *sitter.Node, lang string, source []byte) int {
 	switch lang {
 	case "go":
 		return countGoParams(paramsNode)
+	case "csharp":
+		return countCSharpParams(paramsNode)
 	default:
 		return countGenericParams(paramsNode, lang)
 	}
@@ -88,6 +90,23 @@ func countGoParams(paramsNode *sitter.Node) int {
 	return count
 }
 
+// countCSharpParams counts parameter nodes plus a trailing `params` array,
+// which the C# grammar inlines into the parameter list as bare type and name
+// fields instead of wrapping it in a parameter node.
+func countCSharpParams(paramsNode *sitter.Node) int {
+	count := 0
+	for i := 0; i < int(paramsNode.ChildCount()); i++ {
+		child := paramsNode.Child(i)
+		if child == nil {
+			continue
+		}
+		if child.Type() == "parameter" || paramsNode.FieldNameForChild(i) == "name" {
+			count++
+		}
+	}
+	return count
+}
+
 // countGenericParams counts parameter nodes for non-Go languages.
 func countGenericParams(paramsNode *sitter.Node, lang string) int {
 	paramTypes := paramNodeTypes(lang)
@@ -142,6 +161,25 @@ func paramNodeTypes(lang string) map[string]bool {
 		return map[string]bool{
 			"parameter": true,
 		}
+	case "ruby":
+		return map[string]bool{
+			"identifier":           true,
+			"optional_parameter":   true,
+			"splat_parameter":      true,
+			"hash_splat_parameter": true,
+			"keyword_parameter":    true,
+			"block_parameter":      true,
+		}
+	case "php":
+		return map[string]bool{
+			"simple_parameter":             true,
+			"variadic_parameter":           true,
+			"property_promotion_parameter": true,
+		}
+	case "kotlin":
+		return map[string]bool{
+			"parameter": true,
+		}
 	default:
 		return nil
 	}
@@ -0,0 +1,124 @@
+package astcheck
+
+import (
+	"fmt"
+	"regexp"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// captureRef matches ${name} references to query captures in a message.
+var captureRef = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// QueryCheck reports every match of a tree-sitter query, letting rules
+// describe structural patterns without a compiled-in check. Queries are
+// grammar-specific, so the query is compiled once per language.
+type QueryCheck struct {
+	queries map[string]*sitter.Query
+	capture string
+	message string
+}
+
+// NewQueryCheck compiles query for each of languages (see LanguageByName).
+// capture names the capture whose node a finding points at; when empty, a
+// finding spans all of a match's captures. message may reference captures
+// as ${name}, which expand to the captured source text.
+func NewQueryCheck(query string, languages []string, capture, message string) (*QueryCheck, error) {
+	if len(languages) == 0 {
+		return nil, fmt.Errorf("no languages to compile the query for")
+	}
+	c := &QueryCheck{queries: make(map[string]*sitter.Query), capture: capture, message: message}
+	for _, name := range languages {
+		lang, canonical, ok := LanguageByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported language %q", name)
+		}
+		if _, done := c.queries[canonical]; done {
+			continue
+		}
+		q, err := sitter.NewQuery([]byte(query), lang)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if capture != "" && !hasCapture(q, capture) {
+			return nil, fmt.Errorf("capture %q is not defined in the query", capture)
+		}
+		c.queries[canonical] = q
+	}
+	return c, nil
+}
+
+func (c *QueryCheck) Name() string { return "ast-query" }
+
+func (c *QueryCheck) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	q := c.queries[lang]
+	if q == nil {
+		return nil
+	}
+
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(q, tree.RootNode())
+
+	var matches []Match
+	seen := make(map[string]bool)
+	for {
+		m, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		m = qc.FilterPredicates(m, source)
+		if len(m.Captures) == 0 {
+			continue
+		}
+
+		texts := make(map[string]string, len(m.Captures))
+		startRow, endRow := -1, -1
+		for _, capt := range m.Captures {
+			name := q.CaptureNameForId(capt.Index)
+			if _, ok := texts[name]; !ok {
+				texts[name] = capt.Node.Content(source)
+			}
+			if c.capture != "" && name != c.capture {
+				continue
+			}
+			if s := int(capt.Node.StartPoint().Row); startRow < 0 || s < startRow {
+				startRow = s
+			}
+			if e := int(capt.Node.EndPoint().Row); e > endRow {
+				endRow = e
+			}
+		}
+		if startRow < 0 {
+			// The reported capture is optional and absent from this match.
+			continue
+		}
+
+		msg := captureRef.ReplaceAllStringFunc(c.message, func(ref string) string {
+			if text, ok := texts[ref[2:len(ref)-1]]; ok {
+				return text
+			}
+			return ref
+		})
+		key := fmt.Sprintf("%d:%d:%s", startRow, endRow, msg)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		matches = append(matches, Match{
+			StartLine: startRow + 1,
+			EndLine:   endRow + 1,
+			Message:   msg,
+		})
+	}
+	return matches
+}
+
+func hasCapture(q *sitter.Query, name string) bool {
+	for i := uint32(0); i < q.CaptureCount(); i++ {
+		if q.CaptureNameForId(i) == name {
+			return true
+		}
+	}
+	return false
+}
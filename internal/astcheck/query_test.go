@@ -0,0 +1,100 @@
+package astcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryCheck(t *testing.T) {
+	src := `package main
+
+func main() {
+	fmt.Println("debug")
+	log.Println("kept")
+	fmt.Println(
+		"multi",
+	)
+}
+`
+	query := `(call_expression
+  function: (selector_expression
+    operand: (identifier) @pkg
+    field: (field_identifier) @fn)
+  (#eq? @pkg "fmt")) @call`
+
+	tests := []struct {
+		name      string
+		capture   string
+		wantLines [][2]int
+	}{
+		{"whole match", "", [][2]int{{4, 4}, {6, 8}}},
+		{"reported capture", "fn", [][2]int{{4, 4}, {6, 6}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewQueryCheck(query, []string{"go"}, tt.capture, "avoid ${pkg}.${fn} in ${missing}")
+			if err != nil {
+				t.Fatalf("NewQueryCheck: %v", err)
+			}
+			matches := c.Run(parseGo(t, src), []byte(src), "go", nil)
+			if len(matches) != len(tt.wantLines) {
+				t.Fatalf("expected %d matches, got %+v", len(tt.wantLines), matches)
+			}
+			for i, m := range matches {
+				if m.StartLine != tt.wantLines[i][0] || m.EndLine != tt.wantLines[i][1] {
+					t.Errorf("match %d spans %d-%d, want %v", i, m.StartLine, m.EndLine, tt.wantLines[i])
+				}
+				if m.Message != "avoid fmt.Println in ${missing}" {
+					t.Errorf("unexpected message %q", m.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryCheck_OtherLanguage(t *testing.T) {
+	c, err := NewQueryCheck(`(function_definition) @fn`, []string{"python"}, "", "found")
+	if err != nil {
+		t.Fatalf("NewQueryCheck: %v", err)
+	}
+	src := "package main\n\nfunc main() {}\n"
+	if matches := c.Run(parseGo(t, src), []byte(src), "go", nil); len(matches) != 0 {
+		t.Errorf("expected no matches for a language the query was not compiled for, got %d", len(matches))
+	}
+}
+
+func TestNewQueryCheck_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		languages []string
+		capture   string
+		wantErr   string
+	}{
+		{"no languages", `(identifier) @id`, nil, "", "no languages"},
+		{"unknown language", `(identifier) @id`, []string{"cobol"}, "", "unsupported language"},
+		{"bad syntax", `(identifier @id`, []string{"go"}, "", "go:"},
+		{"bad node type", `(no_such_node) @id`, []string{"go"}, "", "go:"},
+		{"unknown capture", `(identifier) @id`, []string{"go"}, "name", "capture"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewQueryCheck(tt.query, tt.languages, tt.capture, "m")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLanguageByName(t *testing.T) {
+	for name, want := range map[string]string{"go": "go", "js": "javascript", "ts": "typescript", "cs": "csharp", "kotlin": "kotlin"} {
+		lang, canonical, ok := LanguageByName(name)
+		if !ok || lang == nil || canonical != want {
+			t.Errorf("LanguageByName(%q) = %v, %q, %v; want %q", name, lang, canonical, ok, want)
+		}
+	}
+	if _, _, ok := LanguageByName("cobol"); ok {
+		t.Error("expected cobol to be unsupported")
+	}
+}
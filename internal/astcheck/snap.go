@@ -0,0 +1,103 @@
+package astcheck
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Snap is a region moved onto the code construct that mentions a symbol.
+type Snap struct {
+	StartLine int
+	EndLine   int
+	Symbol    string // The symbol whose occurrence the region was moved to
+}
+
+// SnapRegion re-anchors the 1-indexed region [startLine, endLine] to the
+// nearest occurrence of one of symbols, so a finding whose reported lines are
+// a few lines off lands on the function or statement it names. The new
+// region spans the outermost statement, declaration or definition that
+// starts on the occurrence's line, or just that line when there is none.
+//
+// It returns false when no symbol occurs within maxDistance lines of the
+// region, or when one already occurs inside it. Earlier symbols win ties.
+func SnapRegion(tree *sitter.Tree, source []byte, symbols []string, startLine, endLine, maxDistance int) (Snap, bool) {
+	if tree == nil || len(symbols) == 0 || startLine < 1 {
+		return Snap{}, false
+	}
+	if endLine < startLine {
+		endLine = startLine
+	}
+	rank := make(map[string]int, len(symbols))
+	for i, s := range symbols {
+		if _, ok := rank[s]; !ok {
+			rank[s] = i
+		}
+	}
+
+	var best *sitter.Node
+	bestDist, bestRank := 0, 0
+	var walk func(n *sitter.Node) bool
+	walk = func(n *sitter.Node) bool {
+		if n.ChildCount() == 0 {
+			if !n.IsNamed() || !isSymbolNode(n) {
+				return false
+			}
+			r, ok := rank[n.Content(source)]
+			if !ok {
+				return false
+			}
+			line := int(n.StartPoint().Row) + 1
+			dist := 0
+			switch {
+			case line < startLine:
+				dist = startLine - line
+			case line > endLine:
+				dist = line - endLine
+			default:
+				return true // Already anchored on a mentioned symbol
+			}
+			if dist <= maxDistance && (best == nil || dist < bestDist || (dist == bestDist && r < bestRank)) {
+				best, bestDist, bestRank = n, dist, r
+			}
+			return false
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			if child := n.Child(i); child != nil && walk(child) {
+				return true
+			}
+		}
+		return false
+	}
+	if walk(tree.RootNode()) || best == nil {
+		return Snap{}, false
+	}
+
+	line := int(best.StartPoint().Row) + 1
+	snap := Snap{StartLine: line, EndLine: line, Symbol: best.Content(source)}
+	for p := best.Parent(); p != nil && int(p.StartPoint().Row)+1 == line; p = p.Parent() {
+		if isStatementNode(p) {
+			snap.EndLine = int(p.EndPoint().Row) + 1
+		}
+	}
+	return snap, true
+}
+
+// isSymbolNode reports whether a leaf names something: an identifier of any
+// kind, or the name and constant leaves some grammars use instead.
+func isSymbolNode(n *sitter.Node) bool {
+	t := n.Type()
+	return strings.HasSuffix(t, "identifier") || t == "name" || t == "constant"
+}
+
+// isStatementNode reports whether n is a statement or declaration, judged by
+// the type naming conventions the grammars share.
+func isStatementNode(n *sitter.Node) bool {
+	t := n.Type()
+	for _, suffix := range []string{"statement", "declaration", "definition", "assignment"} {
+		if strings.HasSuffix(t, suffix) {
+			return true
+		}
+	}
+	return false
+}
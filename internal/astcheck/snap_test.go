@@ -0,0 +1,62 @@
+package astcheck
+
+import "testing"
+
+const snapSource = `package main
+
+func load(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func save(path string, data []byte) {
+	os.WriteFile(path, data, 0o644)
+}
+`
+
+func TestSnapRegion(t *testing.T) {
+	tree := ParseTree("main.go", []byte(snapSource))
+	tests := []struct {
+		name       string
+		symbols    []string
+		start, end int
+		want       Snap
+		ok         bool
+	}{
+		{"statement below", []string{"ReadFile"}, 2, 2, Snap{StartLine: 4, EndLine: 4, Symbol: "ReadFile"}, true},
+		{"statement above", []string{"WriteFile"}, 13, 14, Snap{StartLine: 12, EndLine: 12, Symbol: "WriteFile"}, true},
+		{"function name", []string{"save"}, 13, 13, Snap{StartLine: 11, EndLine: 13, Symbol: "save"}, true},
+		{"multi-line statement", []string{"err"}, 1, 1, Snap{StartLine: 4, EndLine: 4, Symbol: "err"}, true},
+		{"earlier symbol wins a tie", []string{"WriteFile", "ReadFile"}, 8, 8, Snap{StartLine: 12, EndLine: 12, Symbol: "WriteFile"}, true},
+		{"already anchored", []string{"ReadFile"}, 3, 5, Snap{}, false},
+		{"too far", []string{"WriteFile"}, 1, 1, Snap{}, false},
+		{"unknown symbol", []string{"Unmarshal"}, 4, 4, Snap{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SnapRegion(tree, []byte(snapSource), tt.symbols, tt.start, tt.end, 5)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("SnapRegion(%v, %d, %d) = %+v, %v; want %+v, %v", tt.symbols, tt.start, tt.end, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSnapRegion_IfStatement(t *testing.T) {
+	src := "def check(user):\n    x = 1\n\n    if not authorized(user):\n        raise Forbidden()\n    return x\n"
+	tree := ParseTree("check.py", []byte(src))
+	got, ok := SnapRegion(tree, []byte(src), []string{"authorized"}, 2, 2, 5)
+	want := Snap{StartLine: 4, EndLine: 5, Symbol: "authorized"}
+	if !ok || got != want {
+		t.Errorf("SnapRegion = %+v, %v; want %+v", got, ok, want)
+	}
+}
+
+func TestSnapRegion_Unsupported(t *testing.T) {
+	if _, ok := SnapRegion(nil, nil, []string{"x"}, 1, 1, 5); ok {
+		t.Error("expected no snap without a tree")
+	}
+}
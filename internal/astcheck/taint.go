@@ -0,0 +1,490 @@
+package astcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// The taint engine is a lightweight intra-procedural dataflow analysis over
+// the tree-sitter parse. Each function is walked once in source order:
+// values read from a source (a request parameter, the environment, stdin)
+// taint the variables they are assigned to, taint spreads through any
+// expression that mentions a tainted variable, and a sanitizer call yields a
+// clean value. A call to a sink with a tainted argument is reported.
+//
+// The analysis does not follow calls into other functions, and branches are
+// merged in source order, so a variable assigned a clean value on one path
+// is treated as clean afterwards. Closures share the taint state of the
+// function they are defined in.
+//
+// Sources, sinks and sanitizers are patterns matched against dotted names
+// such as "r.FormValue" or "cursor.execute". Segments compare exactly,
+// except that "*" matches any one segment and a leading "*." matches any
+// number of leading segments, so "*.Query" matches both db.Query and
+// s.db.Query. A pattern without a dot, such as "input", only matches calls.
+// A sink may end in "#N" to consider only its Nth argument (0-based), for
+// example the query string of db.QueryContext(ctx, query, args...).
+
+// taintSyntax describes how the taint engine reads one language's syntax.
+type taintSyntax struct {
+	funcTypes   map[string]bool      // nodes that start a new scope
+	callType    string               // call expression node type
+	memberTypes map[string]bool      // dotted access nodes matched against sources
+	identTypes  map[string]bool      // nodes naming a variable
+	binders     map[string][2]string // node type -> {target field, value field}
+	weakBinders map[string]bool      // binders that add taint but never clear it
+	accessTypes map[string]bool      // element or field targets, e.g. m[k] = v
+	skipFields  map[string]string    // node type -> child field that never names a variable
+	keywordArg  string               // keyword argument node, not counted as positional
+}
+
+var taintSyntaxes = map[string]*taintSyntax{
+	"go": {
+		funcTypes:   map[string]bool{"function_declaration": true, "method_declaration": true, "func_literal": true},
+		callType:    "call_expression",
+		memberTypes: map[string]bool{"selector_expression": true},
+		identTypes:  map[string]bool{"identifier": true},
+		binders: map[string][2]string{
+			"assignment_statement":  {"left", "right"},
+			"short_var_declaration": {"left", "right"},
+			"var_spec":              {"name", "value"},
+			"range_clause":          {"left", "right"},
+		},
+		accessTypes: map[string]bool{"index_expression": true, "selector_expression": true},
+	},
+	"python": {
+		funcTypes:   map[string]bool{"function_definition": true, "lambda": true},
+		callType:    "call",
+		memberTypes: map[string]bool{"attribute": true},
+		identTypes:  map[string]bool{"identifier": true},
+		binders: map[string][2]string{
+			"assignment":           {"left", "right"},
+			"augmented_assignment": {"left", "right"},
+			"for_statement":        {"left", "right"},
+		},
+		weakBinders: map[string]bool{"augmented_assignment": true},
+		accessTypes: map[string]bool{"subscript": true, "attribute": true},
+		skipFields:  map[string]string{"attribute": "attribute", "keyword_argument": "name"},
+		keywordArg:  "keyword_argument",
+	},
+	"javascript": jsTaintSyntax,
+	"typescript": jsTaintSyntax,
+}
+
+var jsTaintSyntax = &taintSyntax{
+	funcTypes: map[string]bool{
+		"function_declaration":           true,
+		"generator_function_declaration": true,
+		"function_expression":            true,
+		"function":                       true,
+		"arrow_function":                 true,
+		"method_definition":              true,
+	},
+	callType:    "call_expression",
+	memberTypes: map[string]bool{"member_expression": true},
+	identTypes:  map[string]bool{"identifier": true, "shorthand_property_identifier": true, "shorthand_property_identifier_pattern": true},
+	binders: map[string][2]string{
+		"variable_declarator":             {"name", "value"},
+		"assignment_expression":           {"left", "right"},
+		"augmented_assignment_expression": {"left", "right"},
+		"for_in_statement":                {"left", "right"},
+	},
+	weakBinders: map[string]bool{"augmented_assignment_expression": true},
+	accessTypes: map[string]bool{"subscript_expression": true, "member_expression": true},
+}
+
+// taintSpec holds the patterns one taint check runs with for a language.
+type taintSpec struct {
+	sources    []string
+	sinks      []taintSink
+	sanitizers []string
+}
+
+type taintSink struct {
+	pattern string
+	arg     int // -1 for any argument
+}
+
+// taintDefaults lists a check's default patterns by language.
+type taintDefaults struct {
+	sinks      map[string][]string
+	sanitizers map[string][]string
+}
+
+// Sources and sanitizers shared by all taint checks.
+var (
+	defaultTaintSources = map[string][]string{
+		"go": {
+			"*.FormValue", "*.PostFormValue", "*.Form", "*.PostForm", "*.MultipartForm",
+			"*.URL.Query", "*.URL.RawQuery", "*.URL.Path", "*.Header.Get", "*.Cookie", "*.PathValue",
+			"mux.Vars", "os.Getenv", "os.LookupEnv", "os.Args", "os.Stdin", "flag.Arg", "flag.Args",
+		},
+		"python": {
+			"request.args", "request.form", "request.values", "request.json", "request.data",
+			"request.cookies", "request.headers", "request.files", "request.GET", "request.POST",
+			"request.COOKIES", "request.META", "request.query_params",
+			"sys.argv", "sys.stdin", "os.environ", "os.getenv", "input",
+		},
+		"javascript": {
+			"req.query", "req.body", "req.params", "req.headers", "req.cookies",
+			"request.query", "request.body", "request.params", "ctx.query", "ctx.params", "ctx.request.body",
+			"process.argv", "process.env", "location.search", "location.hash", "document.cookie",
+		},
+	}
+	defaultTaintSanitizers = map[string][]string{
+		"go":         {"strconv.Atoi", "strconv.ParseInt", "strconv.ParseUint", "strconv.ParseFloat", "strconv.ParseBool", "len"},
+		"python":     {"int", "float", "bool", "len"},
+		"javascript": {"parseInt", "parseFloat", "Number", "Boolean"},
+	}
+)
+
+// languageDefaults returns the entry for lang, using the JavaScript entry
+// for TypeScript.
+func languageDefaults(defaults map[string][]string, lang string) []string {
+	if lang == "typescript" {
+		if _, ok := defaults[lang]; !ok {
+			lang = "javascript"
+		}
+	}
+	return defaults[lang]
+}
+
+// languageList reads a pattern list from config[key]: either a list applied
+// to every language or a map from language name to list. Languages missing
+// from the map, and an absent key, fall back to defaults.
+func languageList(config map[string]interface{}, key, lang string, defaults []string) []string {
+	v, ok := config[key]
+	if !ok {
+		return defaults
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		v, ok = m[lang]
+		if !ok && lang == "typescript" {
+			v, ok = m["javascript"]
+		}
+		if !ok {
+			return defaults
+		}
+	}
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		var out []string
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return defaults
+}
+
+// newTaintSpec builds the spec for lang from config, falling back to the
+// shared sources and sanitizers plus the check's own defaults.
+func newTaintSpec(config map[string]interface{}, lang string, d taintDefaults) taintSpec {
+	sanitizers := append(append([]string{}, languageDefaults(defaultTaintSanitizers, lang)...), languageDefaults(d.sanitizers, lang)...)
+	spec := taintSpec{
+		sources:    languageList(config, "sources", lang, languageDefaults(defaultTaintSources, lang)),
+		sanitizers: languageList(config, "sanitizers", lang, sanitizers),
+	}
+	for _, s := range languageList(config, "sinks", lang, languageDefaults(d.sinks, lang)) {
+		sink := taintSink{pattern: s, arg: -1}
+		if i := strings.LastIndex(s, "#"); i >= 0 {
+			if n, err := strconv.Atoi(s[i+1:]); err == nil && n >= 0 {
+				sink = taintSink{pattern: s[:i], arg: n}
+			}
+		}
+		spec.sinks = append(spec.sinks, sink)
+	}
+	return spec
+}
+
+// runTaint reports every call to one of spec's sinks that receives a
+// tainted argument. kind describes the sink in messages, e.g. "SQL query".
+func runTaint(tree *sitter.Tree, source []byte, lang string, spec taintSpec, kind string) []Match {
+	syn := taintSyntaxes[lang]
+	if syn == nil || len(spec.sinks) == 0 {
+		return nil
+	}
+	e := &taintEngine{syn: syn, spec: spec, source: source, kind: kind, reported: make(map[uint32]bool)}
+
+	root := tree.RootNode()
+	// Top-level statements (e.g. a Python script) form their own scope.
+	e.newScope(true).walk(root)
+	var funcs []*sitter.Node
+	collectOutermost(root, syn.funcTypes, &funcs)
+	for _, fn := range funcs {
+		e.newScope(false).walk(fn)
+	}
+	return e.matches
+}
+
+// collectOutermost appends the nodes of the given types that are not nested
+// inside another such node.
+func collectOutermost(node *sitter.Node, types map[string]bool, out *[]*sitter.Node) {
+	if node.IsNamed() && types[node.Type()] {
+		*out = append(*out, node)
+		return
+	}
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if child := node.Child(i); child != nil {
+			collectOutermost(child, types, out)
+		}
+	}
+}
+
+type taintEngine struct {
+	syn      *taintSyntax
+	spec     taintSpec
+	source   []byte
+	kind     string
+	reported map[uint32]bool // sink calls already reported, by start byte
+	matches  []Match
+}
+
+func (e *taintEngine) newScope(skipFuncs bool) *taintScope {
+	return &taintScope{e: e, tainted: make(map[string]string), skipFuncs: skipFuncs}
+}
+
+// taintScope tracks the tainted variables of one function, mapped to the
+// source their taint came from.
+type taintScope struct {
+	e         *taintEngine
+	tainted   map[string]string
+	skipFuncs bool
+}
+
+func (s *taintScope) walk(node *sitter.Node) {
+	if node == nil || !node.IsNamed() {
+		return
+	}
+	syn := s.e.syn
+	t := node.Type()
+	if s.skipFuncs && syn.funcTypes[t] {
+		return
+	}
+
+	if binder, ok := syn.binders[t]; ok {
+		value := node.ChildByFieldName(binder[1])
+		s.walk(value)
+		origin, tainted := s.taint(value)
+		weak := syn.weakBinders[t]
+		if op := node.ChildByFieldName("operator"); op != nil && op.Type() != "=" && op.Type() != ":=" {
+			weak = true // compound assignment such as q += x
+		}
+		s.bind(node, binder[0], origin, tainted, weak)
+		for i := 0; i < int(node.ChildCount()); i++ {
+			field := node.FieldNameForChild(i)
+			if field != binder[0] && field != binder[1] {
+				s.walk(node.Child(i))
+			}
+		}
+		return
+	}
+
+	if t == syn.callType {
+		s.checkSink(node)
+	}
+	for i := 0; i < int(node.ChildCount()); i++ {
+		s.walk(node.Child(i))
+	}
+}
+
+// bind records the taint of an assigned value on every target of node named
+// by field. Plain variables take the value's state; element and field
+// targets (and weak binders such as +=) only ever gain taint.
+func (s *taintScope) bind(node *sitter.Node, field, origin string, tainted, weak bool) {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.FieldNameForChild(i) != field {
+			continue
+		}
+		s.bindTarget(node.Child(i), origin, tainted, weak)
+	}
+}
+
+func (s *taintScope) bindTarget(target *sitter.Node, origin string, tainted, weak bool) {
+	if target == nil || !target.IsNamed() {
+		return
+	}
+	syn := s.e.syn
+	switch {
+	case syn.identTypes[target.Type()]:
+		name := target.Content(s.e.source)
+		if tainted {
+			s.tainted[name] = origin
+		} else if !weak {
+			delete(s.tainted, name)
+		}
+	case syn.accessTypes[target.Type()]:
+		if tainted {
+			if base := accessBase(target, syn); base != nil {
+				s.tainted[base.Content(s.e.source)] = origin
+			}
+		}
+	default:
+		// Destructuring and multiple assignment: bind every element.
+		for i := 0; i < int(target.NamedChildCount()); i++ {
+			s.bindTarget(target.NamedChild(i), origin, tainted, weak)
+		}
+	}
+}
+
+// accessBase returns the variable at the root of an element or field
+// access such as m[k] or obj.field.value.
+func accessBase(node *sitter.Node, syn *taintSyntax) *sitter.Node {
+	for node != nil && syn.accessTypes[node.Type()] {
+		node = node.NamedChild(0)
+	}
+	if node != nil && syn.identTypes[node.Type()] {
+		return node
+	}
+	return nil
+}
+
+// taint reports whether the expression node carries taint and, if so, the
+// source it came from.
+func (s *taintScope) taint(node *sitter.Node) (string, bool) {
+	if node == nil || !node.IsNamed() {
+		return "", false
+	}
+	syn := s.e.syn
+	t := node.Type()
+	switch {
+	case t == syn.callType:
+		name := s.calleeName(node)
+		if matchesAnyPattern(s.e.spec.sanitizers, name, true) {
+			return "", false
+		}
+		if matchesAnyPattern(s.e.spec.sources, name, true) {
+			return name, true
+		}
+	case syn.memberTypes[t]:
+		if name := compactText(node, s.e.source); matchesAnyPattern(s.e.spec.sources, name, false) {
+			return name, true
+		}
+	case syn.identTypes[t]:
+		if origin, ok := s.tainted[node.Content(s.e.source)]; ok {
+			return origin, true
+		}
+		return "", false
+	}
+
+	skip := syn.skipFields[t]
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if skip != "" && node.FieldNameForChild(i) == skip {
+			continue
+		}
+		if origin, ok := s.taint(node.Child(i)); ok {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// checkSink reports call if it calls a sink with a tainted argument.
+func (s *taintScope) checkSink(call *sitter.Node) {
+	name := s.calleeName(call)
+	if name == "" || s.e.reported[call.StartByte()] {
+		return
+	}
+	for _, sink := range s.e.spec.sinks {
+		if !matchesPattern(sink.pattern, name, true) {
+			continue
+		}
+		for i, arg := range s.positionalArgs(call) {
+			if sink.arg >= 0 && i != sink.arg {
+				continue
+			}
+			if origin, ok := s.taint(arg); ok {
+				s.report(call, name, origin)
+				return
+			}
+		}
+	}
+}
+
+func (s *taintScope) report(call *sitter.Node, sink, origin string) {
+	s.e.reported[call.StartByte()] = true
+	line := int(call.StartPoint().Row) + 1
+	s.e.matches = append(s.e.matches, Match{
+		StartLine: line,
+		EndLine:   int(call.EndPoint().Row) + 1,
+		Message:   fmt.Sprintf("untrusted input from %s reaches the %s in %s at line %d", origin, s.e.kind, sink, line),
+		Extra: map[string]interface{}{
+			"source": origin,
+			"sink":   sink,
+		},
+	})
+}
+
+func (s *taintScope) calleeName(call *sitter.Node) string {
+	fn := call.ChildByFieldName("function")
+	if fn == nil {
+		return ""
+	}
+	return compactText(fn, s.e.source)
+}
+
+// positionalArgs returns the positional arguments of call, leaving out
+// keyword arguments and comments.
+func (s *taintScope) positionalArgs(call *sitter.Node) []*sitter.Node {
+	args := call.ChildByFieldName("arguments")
+	if args == nil {
+		return nil
+	}
+	var out []*sitter.Node
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		arg := args.NamedChild(i)
+		if arg == nil || arg.Type() == "comment" || arg.Type() == s.e.syn.keywordArg {
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+// compactText returns node's source text with whitespace removed, so a
+// selector split across lines still matches its pattern.
+func compactText(node *sitter.Node, source []byte) string {
+	return strings.Join(strings.Fields(node.Content(source)), "")
+}
+
+func matchesAnyPattern(patterns []string, name string, call bool) bool {
+	for _, p := range patterns {
+		if matchesPattern(p, name, call) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPattern reports whether the dotted name matches pattern (see the
+// pattern syntax at the top of this file). Patterns without a dot only match
+// when call is set, so a source like "input" does not taint every variable
+// named input.
+func matchesPattern(pattern, name string, call bool) bool {
+	if !strings.Contains(pattern, ".") {
+		return call && pattern == name
+	}
+	anyPrefix := strings.HasPrefix(pattern, "*.")
+	if anyPrefix {
+		pattern = pattern[2:]
+	}
+	want := strings.Split(pattern, ".")
+	got := strings.Split(name, ".")
+	if len(got) < len(want) || (!anyPrefix && len(got) != len(want)) {
+		return false
+	}
+	got = got[len(got)-len(want):]
+	for i := range want {
+		if want[i] != "*" && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
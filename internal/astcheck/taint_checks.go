@@ -0,0 +1,89 @@
+package astcheck
+
+import sitter "github.com/smacker/go-tree-sitter"
+
+// Taint checks run the taint engine in taint.go for one class of sink.
+// They support Go, Python, JavaScript and TypeScript.
+//
+// Config keys (each either a list for every language or a map from language
+// name to list; TypeScript falls back to the javascript entry):
+//   - sources: where untrusted data comes from
+//   - sinks: calls that must not receive untrusted data, optionally with a
+//     "#N" argument index
+//   - sanitizers: calls whose result is considered clean
+
+var sqlTaintDefaults = taintDefaults{
+	sinks: map[string][]string{
+		"go": {
+			"*.Query#0", "*.QueryRow#0", "*.Exec#0", "*.Prepare#0", "*.Raw#0",
+			"*.QueryContext#1", "*.QueryRowContext#1", "*.ExecContext#1", "*.PrepareContext#1",
+		},
+		"python":     {"*.execute#0", "*.executemany#0", "*.executescript#0", "*.raw#0"},
+		"javascript": {"*.query#0", "*.execute#0", "*.raw#0"},
+	},
+	sanitizers: map[string][]string{
+		"go": {"pq.QuoteLiteral", "pq.QuoteIdentifier"},
+	},
+}
+
+var commandTaintDefaults = taintDefaults{
+	sinks: map[string][]string{
+		"go": {"exec.Command", "exec.CommandContext", "syscall.Exec"},
+		"python": {
+			"os.system#0", "os.popen#0", "subprocess.call#0", "subprocess.run#0",
+			"subprocess.Popen#0", "subprocess.check_call#0", "subprocess.check_output#0",
+		},
+		"javascript": {"exec#0", "execSync#0", "child_process.exec#0", "child_process.execSync#0"},
+	},
+	sanitizers: map[string][]string{
+		"python": {"shlex.quote", "pipes.quote"},
+	},
+}
+
+var pathTaintDefaults = taintDefaults{
+	sinks: map[string][]string{
+		"go": {
+			"os.Open#0", "os.OpenFile#0", "os.Create#0", "os.ReadFile#0", "os.WriteFile#0",
+			"os.Remove#0", "os.RemoveAll#0", "os.Mkdir#0", "os.MkdirAll#0",
+			"ioutil.ReadFile#0", "ioutil.WriteFile#0", "http.ServeFile#2",
+		},
+		"python": {"open#0", "os.open#0", "os.remove#0", "os.unlink#0", "os.rmdir#0", "shutil.rmtree#0", "send_file#0", "flask.send_file#0"},
+		"javascript": {
+			"fs.readFile#0", "fs.readFileSync#0", "fs.writeFile#0", "fs.writeFileSync#0",
+			"fs.createReadStream#0", "fs.createWriteStream#0", "fs.unlink#0", "fs.unlinkSync#0",
+			"fs.promises.readFile#0", "fs.promises.writeFile#0", "res.sendFile#0",
+		},
+	},
+	sanitizers: map[string][]string{
+		"go":         {"filepath.Base", "path.Base"},
+		"python":     {"os.path.basename", "secure_filename", "werkzeug.utils.secure_filename"},
+		"javascript": {"path.basename"},
+	},
+}
+
+// TaintedSQL flags SQL queries built from untrusted input.
+type TaintedSQL struct{}
+
+func (c *TaintedSQL) Name() string { return "tainted-sql" }
+
+func (c *TaintedSQL) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	return runTaint(tree, source, lang, newTaintSpec(config, lang, sqlTaintDefaults), "SQL query")
+}
+
+// TaintedCommand flags OS commands built from untrusted input.
+type TaintedCommand struct{}
+
+func (c *TaintedCommand) Name() string { return "tainted-command" }
+
+func (c *TaintedCommand) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	return runTaint(tree, source, lang, newTaintSpec(config, lang, commandTaintDefaults), "command")
+}
+
+// TaintedPath flags file system calls whose path comes from untrusted input.
+type TaintedPath struct{}
+
+func (c *TaintedPath) Name() string { return "tainted-path" }
+
+func (c *TaintedPath) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	return runTaint(tree, source, lang, newTaintSpec(config, lang, pathTaintDefaults), "file path")
+}
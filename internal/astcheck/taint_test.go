@@ -0,0 +1,216 @@
+package astcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func runTaintCheck(t *testing.T, c Check, path, src string, config map[string]interface{}) []Match {
+	t.Helper()
+	tree, lang := parseFile(t, path, src)
+	return c.Run(tree, []byte(src), lang, config)
+}
+
+func TestTaintedSQL(t *testing.T) {
+	tests := []struct {
+		name, path, src string
+		wantLines       []int
+	}{
+		{
+			name: "go concatenation through Sprintf",
+			path: "h.go",
+			src: `package h
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	q := fmt.Sprintf("SELECT * FROM users WHERE id = %s", id)
+	db.Query(q)
+	db.Query("SELECT * FROM users WHERE id = $1", id)
+	s.db.QueryContext(r.Context(), "SELECT 1 WHERE x = " + id)
+}
+`,
+			wantLines: []int{6, 8},
+		},
+		{
+			name: "go sanitizer and reassignment",
+			path: "h.go",
+			src: `package h
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.FormValue("n"))
+	db.Query(fmt.Sprintf("SELECT * FROM t LIMIT %d", n))
+	name := r.FormValue("name")
+	name = "fixed"
+	db.Query("SELECT * FROM t WHERE name = '" + name + "'")
+	q := "SELECT * FROM t WHERE a = "
+	q += r.FormValue("a")
+	q += " LIMIT 1"
+	db.Exec(q)
+}
+`,
+			wantLines: []int{12},
+		},
+		{
+			name: "go taint is per function",
+			path: "h.go",
+			src: `package h
+
+func a(r *http.Request) {
+	id := r.FormValue("id")
+	_ = id
+}
+
+func b() {
+	id := "1"
+	db.Query("SELECT " + id)
+}
+`,
+		},
+		{
+			name: "python f-string and params",
+			path: "app.py",
+			src: `def view():
+    name = request.args.get("name")
+    cursor.execute(f"SELECT * FROM users WHERE name = '{name}'")
+    cursor.execute("SELECT * FROM users WHERE name = %s", (name,))
+    limit = int(request.args["limit"])
+    cursor.execute("SELECT * FROM users LIMIT %d" % limit)
+`,
+			wantLines: []int{3},
+		},
+		{
+			name: "javascript destructuring and template string",
+			path: "app.js",
+			src: "app.get('/u', async (req, res) => {\n" +
+				"  const { id } = req.query;\n" +
+				"  await pool.query(`SELECT * FROM users WHERE id = ${id}`);\n" +
+				"  await pool.query('SELECT * FROM users WHERE id = $1', [id]);\n" +
+				"});\n",
+			wantLines: []int{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := runTaintCheck(t, &TaintedSQL{}, tt.path, tt.src, nil)
+			var got []int
+			for _, m := range matches {
+				got = append(got, m.StartLine)
+			}
+			if len(got) != len(tt.wantLines) {
+				t.Fatalf("expected findings on lines %v, got %v (%+v)", tt.wantLines, got, matches)
+			}
+			for i := range got {
+				if got[i] != tt.wantLines[i] {
+					t.Errorf("expected findings on lines %v, got %v", tt.wantLines, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestTaintedSQL_Message(t *testing.T) {
+	src := `package h
+
+func handler(r *http.Request) {
+	db.Query("DELETE FROM t WHERE id = " + r.FormValue("id"))
+}
+`
+	matches := runTaintCheck(t, &TaintedSQL{}, "h.go", src, nil)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	m := matches[0]
+	if m.Extra["source"] != "r.FormValue" || m.Extra["sink"] != "db.Query" {
+		t.Errorf("unexpected source/sink %v", m.Extra)
+	}
+	if !strings.Contains(m.Message, "r.FormValue") || !strings.Contains(m.Message, "SQL query in db.Query") {
+		t.Errorf("unexpected message %q", m.Message)
+	}
+}
+
+func TestTaintedCommand(t *testing.T) {
+	tests := []struct {
+		name, path, src string
+		want            int
+	}{
+		{"go exec args", "main.go", "package main\n\nfunc main() {\n\tcmd := exec.Command(\"sh\", \"-c\", os.Args[1])\n\t_ = cmd\n}\n", 1},
+		{"go range over tainted slice", "main.go", "package main\n\nfunc main() {\n\tfor _, a := range os.Args {\n\t\texec.Command(a).Run()\n\t}\n}\n", 1},
+		{"go constant command", "main.go", "package main\n\nfunc main() {\n\texec.Command(\"ls\", \"-l\").Run()\n}\n", 0},
+		{"python script scope", "run.py", "import os\nname = input()\nos.system(\"echo \" + name)\n", 1},
+		{"python quoted", "run.py", "import os, shlex\nname = shlex.quote(input())\nos.system(\"echo \" + name)\n", 0},
+		{"python keyword argument is not positional", "run.py", "def f():\n    subprocess.run([\"ls\"], cwd=os.environ[\"HOME\"])\n", 0},
+		{"javascript exec", "run.js", "const { exec } = require('child_process');\nfunction f(req) {\n  exec('ls ' + req.query.dir);\n}\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runTaintCheck(t, &TaintedCommand{}, tt.path, tt.src, nil); len(got) != tt.want {
+				t.Errorf("expected %d matches, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTaintedPath(t *testing.T) {
+	tests := []struct {
+		name, path, src string
+		want            int
+	}{
+		{"go join", "h.go", "package h\n\nfunc h(w http.ResponseWriter, r *http.Request) {\n\tp := filepath.Join(\"/srv\", r.URL.Query().Get(\"f\"))\n\tdata, _ := os.ReadFile(p)\n\t_ = data\n}\n", 1},
+		{"go base", "h.go", "package h\n\nfunc h(w http.ResponseWriter, r *http.Request) {\n\tp := filepath.Join(\"/srv\", filepath.Base(r.FormValue(\"f\")))\n\tos.ReadFile(p)\n}\n", 0},
+		{"go tainted data not path", "h.go", "package h\n\nfunc h(r *http.Request) {\n\tos.WriteFile(\"/tmp/out\", []byte(r.FormValue(\"x\")), 0o644)\n}\n", 0},
+		{"go ServeFile argument index", "h.go", "package h\n\nfunc h(w http.ResponseWriter, r *http.Request) {\n\thttp.ServeFile(w, r, r.URL.Path)\n}\n", 1},
+		{"python open", "a.py", "def f():\n    path = request.args[\"p\"]\n    with open(path) as fh:\n        return fh.read()\n", 1},
+		{"typescript sendFile", "a.ts", "function f(req: any, res: any) {\n  res.sendFile(req.params.name);\n}\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runTaintCheck(t, &TaintedPath{}, tt.path, tt.src, nil); len(got) != tt.want {
+				t.Errorf("expected %d matches, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTaint_Config(t *testing.T) {
+	src := "package h\n\nfunc h(c *gin.Context) {\n\tdb.Query(\"SELECT \" + c.Param(\"id\"))\n\tstore.Find(\"SELECT \" + c.Param(\"id\"))\n}\n"
+	if got := runTaintCheck(t, &TaintedSQL{}, "h.go", src, nil); len(got) != 0 {
+		t.Fatalf("expected no matches without a gin source, got %d", len(got))
+	}
+
+	config := map[string]interface{}{
+		"sources": map[string]interface{}{"go": []interface{}{"*.Param"}},
+		"sinks":   []interface{}{"store.Find#0"},
+	}
+	got := runTaintCheck(t, &TaintedSQL{}, "h.go", src, config)
+	if len(got) != 1 || got[0].StartLine != 5 {
+		t.Errorf("expected only the configured sink on line 5, got %+v", got)
+	}
+
+	pySrc := "def f():\n    cursor.execute(request.args[\"q\"])\n"
+	if got := runTaintCheck(t, &TaintedSQL{}, "a.py", pySrc, config); len(got) != 0 {
+		t.Errorf("expected the list of sinks to replace the defaults for every language, got %+v", got)
+	}
+}
+
+func TestMatchesPattern(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		call, want    bool
+	}{
+		{"*.Query", "db.Query", true, true},
+		{"*.Query", "s.db.Query", true, true},
+		{"*.URL.Query", "r.URL.Query", true, true},
+		{"*.URL.Query", "u.Query", true, false},
+		{"os.Getenv", "os.Getenv", true, true},
+		{"os.Getenv", "x.os.Getenv", true, false},
+		{"request.*", "request.args", false, true},
+		{"input", "input", true, true},
+		{"input", "input", false, false},
+	}
+	for _, tt := range tests {
+		if got := matchesPattern(tt.pattern, tt.name, tt.call); got != tt.want {
+			t.Errorf("matchesPattern(%q, %q, %v) = %v, want %v", tt.pattern, tt.name, tt.call, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package astcheck
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// defaultSecretNames leaves out words such as "sig", "mac", "token" and
+// "digest" that also name signals, machines, lexer tokens and content
+// hashes; add them through the names config key where they mean secrets.
+var (
+	defaultSecretNames  = []string{"hmac", "signature", "secret", "password", "passwd"}
+	defaultCompareFuncs = []string{"bytes.Equal"}
+)
+
+// TimingUnsafeCompare flags comparisons of secret-looking values that do not
+// run in constant time: `==`/`!=` and variable-time helpers such as
+// bytes.Equal. An operand counts as secret when one of the words in its name
+// (split on camelCase and underscores) is a configured secret name, or when
+// it is derived from hmac.New in the same function (the hasher's Sum, or a
+// variable assigned from it). Comparisons against nil, literals and len()
+// are ignored, so `password == ""` is not reported.
+//
+// Config keys:
+//   - names: words that mark an identifier as secret
+//   - compare_funcs: fully qualified functions that compare in variable time
+type TimingUnsafeCompare struct{}
+
+func (c *TimingUnsafeCompare) Name() string { return "timing-unsafe-compare" }
+
+func (c *TimingUnsafeCompare) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	if lang != "go" {
+		return nil
+	}
+
+	names := toStringSet(config, "names", defaultSecretNames)
+	compareFuncs := toStringSet(config, "compare_funcs", defaultCompareFuncs)
+
+	var matches []Match
+	report := func(node *sitter.Node, how string, operand string) {
+		line := int(node.StartPoint().Row) + 1
+		matches = append(matches, Match{
+			StartLine: line,
+			EndLine:   int(node.EndPoint().Row) + 1,
+			Message:   fmt.Sprintf("%s compares secret %q in variable time at line %d; use hmac.Equal or subtle.ConstantTimeCompare", how, operand, line),
+			Extra: map[string]interface{}{
+				"operand":    operand,
+				"comparison": how,
+			},
+		})
+	}
+
+	root := tree.RootNode()
+	nodeTypes := map[string]bool{"binary_expression": true, "call_expression": true}
+	findNodes(root, nodeTypes, func(node *sitter.Node) {
+		var left, right *sitter.Node
+		var how string
+		switch node.Type() {
+		case "binary_expression":
+			op := node.ChildByFieldName("operator")
+			if op == nil || (op.Content(source) != "==" && op.Content(source) != "!=") {
+				return
+			}
+			left, right = node.ChildByFieldName("left"), node.ChildByFieldName("right")
+			how = op.Content(source)
+		case "call_expression":
+			fn := node.ChildByFieldName("function")
+			args := node.ChildByFieldName("arguments")
+			if fn == nil || !compareFuncs[fn.Content(source)] || args == nil || args.NamedChildCount() != 2 {
+				return
+			}
+			left, right = args.NamedChild(0), args.NamedChild(1)
+			how = fn.Content(source)
+		}
+		if left == nil || right == nil || isTrivialOperand(left, source) || isTrivialOperand(right, source) {
+			return
+		}
+
+		derived := hmacDerived(enclosingGoFunc(node), source)
+		for _, operand := range []*sitter.Node{left, right} {
+			if isSecretOperand(operand, source, names, derived) {
+				report(node, how, operand.Content(source))
+				return
+			}
+		}
+	})
+
+	return matches
+}
+
+// isSecretOperand reports whether n names a secret, either by identifier
+// words or by being derived from an HMAC in the enclosing function.
+func isSecretOperand(n *sitter.Node, source []byte, names map[string]bool, derived hmacSources) bool {
+	n = unwrapConversion(n, source)
+	switch n.Type() {
+	case "identifier", "selector_expression":
+		if derived.values[n.Content(source)] {
+			return true
+		}
+		for _, w := range identifierWords(lastSegment(n, source)) {
+			if names[w] {
+				return true
+			}
+		}
+	case "call_expression":
+		// mac.Sum(nil) where mac came from hmac.New
+		fn := n.ChildByFieldName("function")
+		if fn == nil || fn.Type() != "selector_expression" {
+			return false
+		}
+		operand, field := fn.ChildByFieldName("operand"), fn.ChildByFieldName("field")
+		return operand != nil && field != nil && field.Content(source) == "Sum" && derived.hashers[operand.Content(source)]
+	}
+	return false
+}
+
+// unwrapConversion strips string(...) and []byte(...) conversions.
+func unwrapConversion(n *sitter.Node, source []byte) *sitter.Node {
+	for n.Type() == "call_expression" || n.Type() == "parenthesized_expression" {
+		if n.Type() == "parenthesized_expression" {
+			if n.NamedChildCount() == 0 {
+				return n
+			}
+			n = n.NamedChild(0)
+			continue
+		}
+		fn := n.ChildByFieldName("function")
+		args := n.ChildByFieldName("arguments")
+		if fn == nil || args == nil || args.NamedChildCount() != 1 {
+			return n
+		}
+		if t := fn.Content(source); t != "string" && t != "[]byte" {
+			return n
+		}
+		n = args.NamedChild(0)
+	}
+	return n
+}
+
+// isTrivialOperand reports whether n is nil, a literal or a len/cap call,
+// none of which expose a secret's contents to a timing attack.
+func isTrivialOperand(n *sitter.Node, source []byte) bool {
+	switch n.Type() {
+	case "nil", "true", "false", "interpreted_string_literal", "raw_string_literal",
+		"int_literal", "float_literal", "rune_literal":
+		return true
+	case "identifier":
+		return n.Content(source) == "nil"
+	case "call_expression":
+		// len(token) == 0 compares a length, not the secret.
+		fn := n.ChildByFieldName("function")
+		return fn != nil && (fn.Content(source) == "len" || fn.Content(source) == "cap")
+	}
+	return false
+}
+
+// hmacSources records names tied to hmac.New inside one function: the hasher
+// variables themselves and values assigned from hasher.Sum(...).
+type hmacSources struct {
+	hashers map[string]bool
+	values  map[string]bool
+}
+
+// hmacDerived scans fn for `h := hmac.New(...)` and `sum := h.Sum(...)`.
+func hmacDerived(fn *sitter.Node, source []byte) hmacSources {
+	s := hmacSources{hashers: map[string]bool{}, values: map[string]bool{}}
+	if fn == nil {
+		return s
+	}
+	assignTypes := map[string]bool{"assignment_statement": true, "short_var_declaration": true}
+	findNodes(fn, assignTypes, func(node *sitter.Node) {
+		left := node.ChildByFieldName("left")
+		right := node.ChildByFieldName("right")
+		if left == nil || right == nil || left.NamedChildCount() != right.NamedChildCount() {
+			return
+		}
+		for i := 0; i < int(left.NamedChildCount()); i++ {
+			l, r := left.NamedChild(i), right.NamedChild(i)
+			if l == nil || r == nil || r.Type() != "call_expression" {
+				continue
+			}
+			callee := r.ChildByFieldName("function")
+			if callee == nil {
+				continue
+			}
+			name := l.Content(source)
+			if callee.Content(source) == "hmac.New" {
+				s.hashers[name] = true
+				continue
+			}
+			if callee.Type() == "selector_expression" {
+				operand, field := callee.ChildByFieldName("operand"), callee.ChildByFieldName("field")
+				if operand != nil && field != nil && field.Content(source) == "Sum" && s.hashers[operand.Content(source)] {
+					s.values[name] = true
+				}
+			}
+		}
+	})
+	return s
+}
+
+// identifierWords splits a Go identifier into lower-case words on
+// underscores and camelCase boundaries, keeping acronyms together:
+// "expectedHMACSig" -> ["expected", "hmac", "sig"].
+func identifierWords(name string) []string {
+	var words []string
+	var cur []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(cur) > 0:
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
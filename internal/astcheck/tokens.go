@@ -0,0 +1,107 @@
+package astcheck
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TokenStream is a file reduced to its syntax: comments and layout are
+// dropped, so reformatting a file or editing its comments leaves the stream
+// unchanged.
+type TokenStream struct {
+	// Text serializes the tokens together with the nesting of the syntax
+	// tree, so edits that change structure without changing tokens, such
+	// as re-indenting a Python block, still change it.
+	Text  string
+	lines []int // 1-indexed start line of each token
+}
+
+// Tokens returns the token stream of a source file. String literals and
+// other nodes whose text is not fully covered by child nodes are kept whole,
+// including their inner whitespace. Returns nil if the language is
+// unsupported or the file does not parse cleanly, since a tree with errors
+// does not reliably describe the code.
+func Tokens(path string, source []byte) *TokenStream {
+	tree := ParseTree(path, source)
+	if tree == nil || tree.RootNode().HasError() {
+		return nil
+	}
+	s := &TokenStream{}
+	var b strings.Builder
+	writeTokens(&b, s, tree.RootNode(), source)
+	s.Text = b.String()
+	return s
+}
+
+func writeTokens(b *strings.Builder, s *TokenStream, n *sitter.Node, source []byte) {
+	if isComment(n) || n.StartByte() == n.EndByte() {
+		return
+	}
+	if n.ChildCount() == 0 || hasUncoveredText(n, source) {
+		text := n.Content(source)
+		if strings.TrimFunc(text, unicode.IsSpace) == "" {
+			return // statement terminators such as Go's newlines
+		}
+		b.WriteString(text)
+		b.WriteByte(0)
+		s.lines = append(s.lines, int(n.StartPoint().Row)+1)
+		return
+	}
+	if n.IsNamed() {
+		b.WriteString("(" + n.Type() + "\x00")
+	}
+	for i := 0; i < int(n.ChildCount()); i++ {
+		writeTokens(b, s, n.Child(i), source)
+	}
+	if n.IsNamed() {
+		b.WriteString(")\x00")
+	}
+}
+
+// hasUncoveredText reports whether n contains source text outside its
+// children, as string literals do in grammars that only expose their quotes
+// and escape sequences as nodes.
+func hasUncoveredText(n *sitter.Node, source []byte) bool {
+	pos := n.StartByte()
+	for i := 0; i < int(n.ChildCount()); i++ {
+		child := n.Child(i)
+		if strings.TrimFunc(string(source[pos:child.StartByte()]), unicode.IsSpace) != "" {
+			return true
+		}
+		pos = child.EndByte()
+	}
+	return strings.TrimFunc(string(source[pos:n.EndByte()]), unicode.IsSpace) != ""
+}
+
+// Len returns the number of tokens.
+func (s *TokenStream) Len() int {
+	return len(s.lines)
+}
+
+// Line returns the 1-indexed line the i-th token starts on.
+func (s *TokenStream) Line(i int) int {
+	return s.lines[i]
+}
+
+// FirstToken returns the index of the first token starting on or after
+// line, or of the last token when none does.
+func (s *TokenStream) FirstToken(line int) int {
+	i := sort.SearchInts(s.lines, line)
+	if i == len(s.lines) {
+		return len(s.lines) - 1
+	}
+	return i
+}
+
+// LastToken returns the index of the last token starting on or before line,
+// or of the first token when none does.
+func (s *TokenStream) LastToken(line int) int {
+	i := sort.SearchInts(s.lines, line+1) - 1
+	if i < 0 {
+		return 0
+	}
+	return i
+}
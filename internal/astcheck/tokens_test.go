@@ -0,0 +1,79 @@
+package astcheck
+
+import "testing"
+
+func TestTokens_IgnoresLayoutAndComments(t *testing.T) {
+	a := `package main
+
+func Hello(name string) string {
+	return "hello,  " + name
+}
+`
+	b := `package main
+
+// Hello greets name.
+func Hello(name string) string { return "hello,  " + name }
+`
+	ta, tb := Tokens("a.go", []byte(a)), Tokens("b.go", []byte(b))
+	if ta == nil || tb == nil {
+		t.Fatal("expected both files to tokenize")
+	}
+	if ta.Text != tb.Text {
+		t.Errorf("reformatting changed the token stream:\n%q\n%q", ta.Text, tb.Text)
+	}
+
+	c := `package main
+
+func Hello(name string) string {
+	return "hello, " + name
+}
+`
+	if tc := Tokens("c.go", []byte(c)); tc == nil || tc.Text == ta.Text {
+		t.Error("expected whitespace inside a string literal to change the token stream")
+	}
+}
+
+func TestTokens_PythonIndentation(t *testing.T) {
+	inside := "if a:\n    x()\n    y()\n"
+	outside := "if a:\n    x()\ny()\n"
+	ti, to := Tokens("a.py", []byte(inside)), Tokens("b.py", []byte(outside))
+	if ti == nil || to == nil {
+		t.Fatal("expected both files to tokenize")
+	}
+	if ti.Text == to.Text {
+		t.Error("expected moving a statement out of a block to change the token stream")
+	}
+}
+
+func TestTokens_Unsupported(t *testing.T) {
+	if Tokens("notes.txt", []byte("hello")) != nil {
+		t.Error("expected nil for an unsupported language")
+	}
+	if Tokens("broken.go", []byte("package main\nfunc (")) != nil {
+		t.Error("expected nil for a file that does not parse")
+	}
+}
+
+func TestTokenStream_Lines(t *testing.T) {
+	src := `package main
+
+// comment
+
+func F() {}
+`
+	s := Tokens("a.go", []byte(src))
+	if s == nil {
+		t.Fatal("expected tokens")
+	}
+	first := s.FirstToken(2)
+	if s.Line(first) != 5 {
+		t.Errorf("FirstToken(2) is on line %d, want 5", s.Line(first))
+	}
+	last := s.LastToken(4)
+	if s.Line(last) != 1 {
+		t.Errorf("LastToken(4) is on line %d, want 1", s.Line(last))
+	}
+	if s.Line(s.FirstToken(99)) != 5 || s.Line(s.LastToken(0)) != 1 {
+		t.Error("expected out-of-range lines to clamp to the first and last tokens")
+	}
+}
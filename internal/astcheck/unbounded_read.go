@@ -0,0 +1,161 @@
+package astcheck
+
+import (
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+var (
+	defaultReadFuncs    = []string{"io.ReadAll", "ioutil.ReadAll"}
+	defaultReadSources  = []string{"Body", "Conn", "conn", "Stdin"}
+	defaultReadLimiters = []string{"io.LimitReader", "http.MaxBytesReader"}
+)
+
+// UnboundedRead flags whole-stream reads (io.ReadAll and friends) of request
+// bodies or network readers that are not wrapped in a size limiter.
+//
+// Config keys:
+//   - read_funcs: fully qualified read functions to inspect
+//   - sources: identifier or field names treated as untrusted streams
+//     (matched against the last segment of the argument, e.g. "Body" for r.Body)
+//   - limiters: functions that bound a reader, either inline or assigned
+//     back to the source earlier in the same function
+type UnboundedRead struct{}
+
+func (u *UnboundedRead) Name() string { return "unbounded-read" }
+
+func (u *UnboundedRead) Run(tree *sitter.Tree, source []byte, lang string, config map[string]interface{}) []Match {
+	if lang != "go" {
+		return nil
+	}
+
+	readFuncs := toStringSet(config, "read_funcs", defaultReadFuncs)
+	sources := toStringSet(config, "sources", defaultReadSources)
+	limiters := toStringSet(config, "limiters", defaultReadLimiters)
+
+	var matches []Match
+	findNodes(tree.RootNode(), map[string]bool{"call_expression": true}, func(node *sitter.Node) {
+		fn := node.ChildByFieldName("function")
+		if fn == nil || !readFuncs[fn.Content(source)] {
+			return
+		}
+		args := node.ChildByFieldName("arguments")
+		if args == nil || args.NamedChildCount() == 0 {
+			return
+		}
+		arg := args.NamedChild(0)
+		if arg == nil || isLimiterCall(arg, source, limiters) {
+			return
+		}
+		if !sources[lastSegment(arg, source)] {
+			return
+		}
+		argText := arg.Content(source)
+		if limitedEarlier(node, argText, source, limiters) {
+			return
+		}
+
+		line := int(node.StartPoint().Row) + 1
+		matches = append(matches, Match{
+			StartLine: line,
+			EndLine:   int(node.EndPoint().Row) + 1,
+			Message:   fmt.Sprintf("%s(%s) reads without a size limit at line %d", fn.Content(source), argText, line),
+			Extra: map[string]interface{}{
+				"function": fn.Content(source),
+				"source":   argText,
+			},
+		})
+	})
+
+	return matches
+}
+
+// isLimiterCall reports whether node is a call to one of the limiter functions.
+func isLimiterCall(node *sitter.Node, source []byte, limiters map[string]bool) bool {
+	if node.Type() != "call_expression" {
+		return false
+	}
+	fn := node.ChildByFieldName("function")
+	return fn != nil && limiters[fn.Content(source)]
+}
+
+// lastSegment returns the trailing name of an identifier or selector
+// expression ("Body" for r.Body), or "" for any other expression.
+func lastSegment(node *sitter.Node, source []byte) string {
+	switch node.Type() {
+	case "identifier":
+		return node.Content(source)
+	case "selector_expression":
+		if field := node.ChildByFieldName("field"); field != nil {
+			return field.Content(source)
+		}
+	}
+	return ""
+}
+
+// limitedEarlier reports whether, inside the function enclosing call, the
+// expression argText was reassigned from a limiter call before call, as in
+// `r.Body = http.MaxBytesReader(w, r.Body, n)`.
+func limitedEarlier(call *sitter.Node, argText string, source []byte, limiters map[string]bool) bool {
+	fnTypes := funcNodeTypes("go")
+	fnTypes["func_literal"] = true
+
+	var body *sitter.Node
+	for p := call.Parent(); p != nil; p = p.Parent() {
+		if fnTypes[p.Type()] {
+			body = p
+			break
+		}
+	}
+	if body == nil {
+		return false
+	}
+
+	limited := false
+	assignTypes := map[string]bool{"assignment_statement": true, "short_var_declaration": true}
+	findNodes(body, assignTypes, func(node *sitter.Node) {
+		if limited || node.StartByte() >= call.StartByte() {
+			return
+		}
+		left := node.ChildByFieldName("left")
+		right := node.ChildByFieldName("right")
+		if left == nil || right == nil || left.NamedChildCount() != right.NamedChildCount() {
+			return
+		}
+		for i := 0; i < int(left.NamedChildCount()); i++ {
+			l, r := left.NamedChild(i), right.NamedChild(i)
+			if l != nil && r != nil && strings.TrimSpace(l.Content(source)) == argText && isLimiterCall(r, source, limiters) {
+				limited = true
+				return
+			}
+		}
+	})
+	return limited
+}
+
+// toStringSet reads a string list from config[key], falling back to defaults
+// when the key is absent or not a list of strings.
+func toStringSet(config map[string]interface{}, key string, defaults []string) map[string]bool {
+	values := defaults
+	if config != nil {
+		switch v := config[key].(type) {
+		case []string:
+			values = v
+		case []interface{}:
+			values = nil
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+
+	set := make(map[string]bool, len(values))
+	for _, s := range values {
+		set[s] = true
+	}
+	return set
+}
@@ -0,0 +1,246 @@
+// Package baseline snapshots the findings present in a codebase into a
+// compact file so later analyses can report only what is new. It is meant
+// for adopting Gavel on existing code without first fixing every
+// pre-existing finding.
+//
+// Each finding is identified by its rule ID, the file it is in, and a hash
+// of the source region it covers, so entries survive unrelated edits that
+// move code up or down a file.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Version is the baseline file format version written by this package.
+const Version = 1
+
+// DefaultPath is where the baseline commands read and write by default.
+const DefaultPath = ".gavel/baseline.json"
+
+// ErrNotBaseline is returned by Load for JSON files that are not baseline
+// files, such as SARIF logs, so callers can fall back to other formats.
+var ErrNotBaseline = errors.New("not a gavel baseline file")
+
+// File is the on-disk baseline format.
+type File struct {
+	Version  int       `json:"version"`
+	Created  time.Time `json:"created"`
+	Findings []Entry   `json:"findings"`
+}
+
+// Entry is one baselined finding. Only Fingerprint is used for matching;
+// the other fields make the file reviewable and let resolved findings be
+// reported.
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	RuleID      string `json:"ruleId"`
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// Fingerprint returns the baseline fingerprint of r: a hash of its rule ID,
+// its file path relative to root, and the content hash of its region. It
+// returns "" for results without a region snippet, which cannot be matched
+// reliably.
+func Fingerprint(r sarif.Result, root string) string {
+	content := r.Fingerprints[sarif.ContentFingerprintV1]
+	if content == "" || len(r.Locations) == 0 {
+		return ""
+	}
+	file := relPath(r.Locations[0].PhysicalLocation.ArtifactLocation.URI, root)
+	sum := sha256.Sum256([]byte(r.RuleID + "\n" + file + "\n" + content))
+	return fmt.Sprintf("%x", sum[:16])
+}
+
+// FromLog builds a baseline from the active findings in log: suppressed
+// results and results already marked absent are left out. It also returns
+// how many findings were skipped because they have no fingerprint.
+func FromLog(log *sarif.Log, root string) (*File, int) {
+	f := &File{Version: Version, Created: time.Now().UTC(), Findings: []Entry{}}
+	skipped := 0
+	seen := make(map[string]bool)
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 || r.BaselineState == sarif.BaselineStateAbsent {
+				continue
+			}
+			fp := Fingerprint(r, root)
+			if fp == "" {
+				skipped++
+				continue
+			}
+			if seen[fp] {
+				continue
+			}
+			seen[fp] = true
+			e := Entry{Fingerprint: fp, RuleID: r.RuleID, Message: r.Message.Text}
+			if len(r.Locations) > 0 {
+				loc := r.Locations[0].PhysicalLocation
+				e.File = relPath(loc.ArtifactLocation.URI, root)
+				e.Line = loc.Region.StartLine
+			}
+			f.Findings = append(f.Findings, e)
+		}
+	}
+	f.sort()
+	return f, skipped
+}
+
+// Update returns a copy of f reconciled with the findings in log. Entries
+// whose finding is gone are dropped. New findings are added unless
+// pruneOnly is set, which lets a baseline only ever shrink. added and
+// removed count the changes.
+func Update(f *File, log *sarif.Log, root string, pruneOnly bool) (updated *File, added, removed int) {
+	current, _ := FromLog(log, root)
+	inCurrent := make(map[string]bool, len(current.Findings))
+	for _, e := range current.Findings {
+		inCurrent[e.Fingerprint] = true
+	}
+
+	updated = &File{Version: Version, Created: f.Created, Findings: []Entry{}}
+	kept := make(map[string]bool, len(f.Findings))
+	for _, e := range f.Findings {
+		if !inCurrent[e.Fingerprint] {
+			removed++
+			continue
+		}
+		kept[e.Fingerprint] = true
+		updated.Findings = append(updated.Findings, e)
+	}
+	if !pruneOnly {
+		for _, e := range current.Findings {
+			if !kept[e.Fingerprint] {
+				updated.Findings = append(updated.Findings, e)
+				added++
+			}
+		}
+	}
+	updated.sort()
+	return updated, added, removed
+}
+
+// Apply marks each fingerprinted result in log's first run as unchanged if
+// it is in f and new otherwise, then appends an absent result for every
+// baseline entry that no longer appears, mirroring sarif.CompareBaseline.
+// Results without a fingerprint are left untouched.
+func Apply(log *sarif.Log, f *File, root string) {
+	if log == nil || f == nil || len(log.Runs) == 0 {
+		return
+	}
+	inBaseline := make(map[string]bool, len(f.Findings))
+	for _, e := range f.Findings {
+		inBaseline[e.Fingerprint] = true
+	}
+
+	run := &log.Runs[0]
+	seen := make(map[string]bool)
+	for i := range run.Results {
+		r := &run.Results[i]
+		fp := Fingerprint(*r, root)
+		if fp == "" {
+			continue
+		}
+		if inBaseline[fp] {
+			r.BaselineState = sarif.BaselineStateUnchanged
+		} else {
+			r.BaselineState = sarif.BaselineStateNew
+		}
+		seen[fp] = true
+	}
+
+	for _, e := range f.Findings {
+		if seen[e.Fingerprint] {
+			continue
+		}
+		run.Results = append(run.Results, sarif.Result{
+			RuleID:  e.RuleID,
+			Level:   "none",
+			Message: sarif.Message{Text: e.Message},
+			Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: e.File},
+				Region:           sarif.Region{StartLine: e.Line, EndLine: e.Line},
+			}}},
+			BaselineState: sarif.BaselineStateAbsent,
+		})
+	}
+}
+
+// Load reads a baseline file. It returns an error wrapping ErrNotBaseline
+// if path holds JSON in another format.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var probe struct {
+		Version  int             `json:"version"`
+		Findings json.RawMessage `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil || probe.Version == 0 || probe.Findings == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotBaseline)
+	}
+	if probe.Version > Version {
+		return nil, fmt.Errorf("%s: baseline version %d is newer than supported version %d", path, probe.Version, Version)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decoding baseline %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Write saves f to path as indented JSON, creating parent directories.
+func (f *File) Write(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding baseline: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing baseline %s: %w", path, err)
+	}
+	return nil
+}
+
+// sort orders entries by file, line, and rule so baseline diffs stay small.
+func (f *File) sort() {
+	sort.Slice(f.Findings, func(i, j int) bool {
+		a, b := f.Findings[i], f.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+}
+
+// relPath returns uri relative to root with forward slashes, so baselines
+// created on one machine match findings from another. Paths outside root
+// are kept as they are.
+func relPath(uri, root string) string {
+	if root != "" && filepath.IsAbs(uri) {
+		if rel, err := filepath.Rel(root, uri); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			uri = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(uri))
+}
@@ -0,0 +1,163 @@
+package baseline
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func result(ruleID, uri, snippet string, line int) sarif.Result {
+	r := sarif.Result{
+		RuleID:  ruleID,
+		Level:   "error",
+		Message: sarif.Message{Text: ruleID + " finding"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region: sarif.Region{
+				StartLine: line,
+				EndLine:   line,
+				Snippet:   &sarif.ArtifactContent{Text: snippet},
+			},
+		}}},
+	}
+	sarif.SetContentFingerprint(&r)
+	return r
+}
+
+func logOf(results ...sarif.Result) *sarif.Log {
+	return &sarif.Log{Runs: []sarif.Run{{Results: results}}}
+}
+
+func TestFingerprint(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	base := Fingerprint(result("S2068", filepath.FromSlash("/repo/a.go"), "password := \"x\"", 3), root)
+	if base == "" {
+		t.Fatal("expected a fingerprint")
+	}
+	if got := Fingerprint(result("S2068", "a.go", "  password := \"x\"  ", 40), root); got != base {
+		t.Error("expected relative paths, line moves and whitespace not to change the fingerprint")
+	}
+	if Fingerprint(result("S2068", "b.go", "password := \"x\"", 3), root) == base {
+		t.Error("expected the file to be part of the fingerprint")
+	}
+	if Fingerprint(result("S3649", "a.go", "password := \"x\"", 3), root) == base {
+		t.Error("expected the rule to be part of the fingerprint")
+	}
+	if Fingerprint(result("S2068", "a.go", "", 3), root) != "" {
+		t.Error("expected no fingerprint without a snippet")
+	}
+}
+
+func TestFromLog(t *testing.T) {
+	suppressed := result("S1", "a.go", "x()", 1)
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	f, skipped := FromLog(logOf(
+		result("S2", "b.go", "y()", 9),
+		result("S2", "b.go", "y()", 9), // duplicate
+		result("S3", "a.go", "z()", 2),
+		result("S4", "a.go", "", 5), // no snippet
+		suppressed,
+	), "")
+
+	if skipped != 1 {
+		t.Errorf("expected 1 skipped finding, got %d", skipped)
+	}
+	if len(f.Findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", f.Findings)
+	}
+	if f.Findings[0].File != "a.go" || f.Findings[0].RuleID != "S3" || f.Findings[1].File != "b.go" {
+		t.Errorf("expected findings sorted by file, got %+v", f.Findings)
+	}
+	if f.Version != Version {
+		t.Errorf("expected version %d, got %d", Version, f.Version)
+	}
+}
+
+func TestApply(t *testing.T) {
+	f, _ := FromLog(logOf(
+		result("S2068", "a.go", "password := \"x\"", 3),
+		result("S3649", "b.go", "db.Query(q)", 7),
+	), "")
+
+	current := logOf(
+		result("S2068", "a.go", "password := \"x\"", 10), // moved
+		result("S1135", "a.go", "// TODO", 1),
+	)
+	Apply(current, f, "")
+
+	states := map[string]string{}
+	for _, r := range current.Runs[0].Results {
+		states[r.RuleID] = r.BaselineState
+	}
+	want := map[string]string{
+		"S2068": sarif.BaselineStateUnchanged,
+		"S1135": sarif.BaselineStateNew,
+		"S3649": sarif.BaselineStateAbsent,
+	}
+	for rule, state := range want {
+		if states[rule] != state {
+			t.Errorf("%s: baselineState = %q, want %q", rule, states[rule], state)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	f, _ := FromLog(logOf(
+		result("S2068", "a.go", "password := \"x\"", 3),
+		result("S3649", "b.go", "db.Query(q)", 7),
+	), "")
+	current := logOf(
+		result("S2068", "a.go", "password := \"x\"", 3),
+		result("S1135", "a.go", "// TODO", 1),
+	)
+
+	updated, added, removed := Update(f, current, "", false)
+	if added != 1 || removed != 1 || len(updated.Findings) != 2 {
+		t.Errorf("expected 1 added, 1 removed, 2 findings; got %d, %d, %+v", added, removed, updated.Findings)
+	}
+
+	pruned, added, removed := Update(f, current, "", true)
+	if added != 0 || removed != 1 || len(pruned.Findings) != 1 || pruned.Findings[0].RuleID != "S2068" {
+		t.Errorf("expected prune-only to drop S3649 and add nothing; got %d, %d, %+v", added, removed, pruned.Findings)
+	}
+	if len(f.Findings) != 2 {
+		t.Error("Update must not modify its input")
+	}
+}
+
+func TestWriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "baseline.json")
+	f, _ := FromLog(logOf(result("S2068", "a.go", "password := \"x\"", 3)), "")
+	if err := f.Write(path); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got.Findings) != 1 || got.Findings[0] != f.Findings[0] {
+		t.Errorf("round trip mismatch: %+v vs %+v", got.Findings, f.Findings)
+	}
+}
+
+func TestLoad_NotBaseline(t *testing.T) {
+	dir := t.TempDir()
+	sarifPath := filepath.Join(dir, "sarif.json")
+	if err := os.WriteFile(sarifPath, []byte(`{"version":"2.1.0","runs":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(sarifPath); !errors.Is(err, ErrNotBaseline) {
+		t.Errorf("expected ErrNotBaseline for a SARIF file, got %v", err)
+	}
+
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"version":99,"findings":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(future); err == nil || errors.Is(err, ErrNotBaseline) {
+		t.Errorf("expected an unsupported version error, got %v", err)
+	}
+}
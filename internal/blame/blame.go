@@ -0,0 +1,260 @@
+// Package blame enriches SARIF findings with git blame ownership data so
+// findings can be routed to the author of the offending line.
+package blame
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+const (
+	// PropAuthor is the SARIF property holding the blamed author's name.
+	PropAuthor = "gavel/blame-author"
+	// PropCommit is the SARIF property holding the hash of the commit that
+	// last changed the finding's region.
+	PropCommit = "gavel/introduced-commit"
+	// PropIntroducedAt is the SARIF property holding that commit's author
+	// date in RFC 3339 form.
+	PropIntroducedAt = "gavel/introduced-at"
+	// PropAgeDays is the SARIF property holding how many whole days ago that
+	// commit was authored.
+	PropAgeDays = "gavel/blame-age-days"
+)
+
+// gitTimeout limits how long a single git blame invocation may run.
+const gitTimeout = 10 * time.Second
+
+// uncommittedHash is the hash git blame reports for lines not yet committed.
+const uncommittedHash = "0000000000000000000000000000000000000000"
+
+// Line is the blame information for a single source line.
+type Line struct {
+	Author string
+	Commit string
+	Time   time.Time
+}
+
+// File runs git blame once for path and returns blame information keyed by
+// 1-based line number. Relative paths are resolved against repoDir. git is
+// run from the file's own directory, so files inside any working tree are
+// handled; an error is returned when the file is not tracked by git.
+func File(ctx context.Context, repoDir, path string) (map[int]Line, error) {
+	if !filepath.IsAbs(path) && repoDir != "" {
+		path = filepath.Join(repoDir, path)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "blame", "--line-porcelain", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return parsePorcelain(out)
+}
+
+// parsePorcelain parses `git blame --line-porcelain` output. Every line is
+// introduced by a header "<hash> <orig-line> <final-line> [<count>]",
+// followed by key/value lines and finally the content line prefixed by a tab.
+func parsePorcelain(out []byte) (map[int]Line, error) {
+	lines := make(map[int]Line)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var (
+		current   Line
+		finalLine int
+		inHeader  bool
+	)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			if inHeader {
+				lines[finalLine] = current
+			}
+			inHeader = false
+		case !inHeader:
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("malformed blame header: %q", text)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("malformed blame header %q: %w", text, err)
+			}
+			current = Line{Commit: fields[0]}
+			finalLine = n
+			inHeader = true
+		case strings.HasPrefix(text, "author "):
+			current.Author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-time "):
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64); err == nil {
+				current.Time = time.Unix(sec, 0).UTC()
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading blame output: %w", err)
+	}
+	return lines, nil
+}
+
+// Enrich attributes every result to the most recent commit among the
+// committed lines of its region, setting gavel/blame-author,
+// gavel/introduced-commit, gavel/introduced-at and gavel/blame-age-days.
+// git blame runs once per distinct file. Files outside a git repository,
+// untracked files and regions with no committed lines are left without
+// enrichment; failures are logged at debug level and never returned.
+func Enrich(ctx context.Context, log *sarif.Log, repoDir string) {
+	now := time.Now()
+	cache := make(map[string]map[int]Line)
+	for ri := range log.Runs {
+		results := log.Runs[ri].Results
+		for i := range results {
+			if len(results[i].Locations) == 0 {
+				continue
+			}
+			loc := results[i].Locations[0].PhysicalLocation
+			uri := loc.ArtifactLocation.URI
+			if uri == "" || loc.Region.StartLine < 1 {
+				continue
+			}
+
+			blamed, ok := cache[uri]
+			if !ok {
+				var err error
+				blamed, err = File(ctx, repoDir, uri)
+				if err != nil {
+					slog.Debug("skipping blame enrichment", "file", uri, "err", err)
+				}
+				cache[uri] = blamed
+			}
+
+			line, ok := newest(blamed, loc.Region.StartLine, loc.Region.EndLine)
+			if !ok {
+				continue
+			}
+			if results[i].Properties == nil {
+				results[i].Properties = make(map[string]interface{})
+			}
+			results[i].Properties[PropAuthor] = line.Author
+			results[i].Properties[PropCommit] = line.Commit
+			if !line.Time.IsZero() {
+				results[i].Properties[PropIntroducedAt] = line.Time.Format(time.RFC3339)
+				results[i].Properties[PropAgeDays] = int(now.Sub(line.Time).Hours() / 24)
+			}
+		}
+	}
+}
+
+// newest returns the blame of the most recently authored committed line in
+// start..end, so a finding is attributed to whoever last changed its region.
+// An end before start is treated as a single-line region.
+func newest(blamed map[int]Line, start, end int) (Line, bool) {
+	end = max(end, start)
+	var (
+		best  Line
+		found bool
+	)
+	for n := start; n <= end; n++ {
+		line, ok := blamed[n]
+		if !ok || line.Commit == uncommittedHash {
+			continue
+		}
+		if !found || line.Time.After(best.Time) {
+			best, found = line, true
+		}
+	}
+	return best, found
+}
+
+// Owner aggregates the actionable findings attributed to one author.
+type Owner struct {
+	Author   string `json:"author"`
+	Findings int    `json:"findings"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Notes    int    `json:"notes"`
+	// Oldest is the age in days of the oldest commit among the findings.
+	Oldest int `json:"oldest_days"`
+}
+
+// Unattributed is the Owner.Author of findings that Enrich could not blame.
+const Unattributed = "(unattributed)"
+
+// Owners groups the actionable findings of an enriched log by blamed author,
+// most findings first. Suppressed, informational and baseline-resolved
+// findings are not counted; findings without blame data are grouped under
+// Unattributed.
+func Owners(log *sarif.Log) []Owner {
+	byAuthor := make(map[string]*Owner)
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 || r.Kind == sarif.KindInformational || r.BaselineState == sarif.BaselineStateAbsent {
+				continue
+			}
+			author, _ := r.Properties[PropAuthor].(string)
+			if author == "" {
+				author = Unattributed
+			}
+			o, ok := byAuthor[author]
+			if !ok {
+				o = &Owner{Author: author}
+				byAuthor[author] = o
+			}
+			o.Findings++
+			switch r.Level {
+			case "error":
+				o.Errors++
+			case "warning":
+				o.Warnings++
+			case "note":
+				o.Notes++
+			}
+			if age := ageDays(r.Properties[PropAgeDays]); age > o.Oldest {
+				o.Oldest = age
+			}
+		}
+	}
+
+	owners := make([]Owner, 0, len(byAuthor))
+	for _, o := range byAuthor {
+		owners = append(owners, *o)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Findings != owners[j].Findings {
+			return owners[i].Findings > owners[j].Findings
+		}
+		return owners[i].Author < owners[j].Author
+	})
+	return owners
+}
+
+// ageDays reads a gavel/blame-age-days value, which is a float64 once the log
+// has been through JSON.
+func ageDays(v interface{}) int {
+	switch age := v.(type) {
+	case int:
+		return age
+	case float64:
+		return int(age)
+	}
+	return 0
+}
@@ -0,0 +1,202 @@
+package blame
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// gitRun runs git in dir with a fixed identity and returns trimmed stdout.
+func gitRun(t *testing.T, dir string, env []string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+	cmd.Env = append(cmd.Env, env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitAs writes content to name and commits it as author.
+func commitAs(t *testing.T, dir, name, content, author string) string {
+	t.Helper()
+	return commitAt(t, dir, name, content, author, "")
+}
+
+// commitAt is commitAs with a fixed author date, when date is not empty.
+func commitAt(t *testing.T, dir, name, content, author, date string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := []string{
+		"GIT_AUTHOR_NAME=" + author, "GIT_AUTHOR_EMAIL=" + strings.ToLower(author) + "@example.com",
+		"GIT_COMMITTER_NAME=" + author, "GIT_COMMITTER_EMAIL=" + strings.ToLower(author) + "@example.com",
+	}
+	if date != "" {
+		env = append(env, "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	}
+	gitRun(t, dir, env, "add", name)
+	gitRun(t, dir, env, "commit", "-q", "-m", "update "+name)
+	return gitRun(t, dir, nil, "rev-parse", "HEAD")
+}
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	gitRun(t, dir, nil, "init", "-q")
+	return dir
+}
+
+func resultAt(uri string, line int) sarif.Result {
+	return sarif.Result{
+		RuleID: "r", Level: "warning", Message: sarif.Message{Text: "m"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region:           sarif.Region{StartLine: line, EndLine: line},
+		}}},
+	}
+}
+
+func TestEnrich_SetsAuthorAndCommit(t *testing.T) {
+	dir := newRepo(t)
+	first := commitAs(t, dir, "main.go", "package main\n\nfunc a() {}\n", "Alice")
+	second := commitAs(t, dir, "main.go", "package main\n\nfunc a() {}\n\nfunc b() {}\n", "Bob")
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{resultAt("main.go", 3), resultAt("main.go", 5)}
+
+	Enrich(context.Background(), log, dir)
+
+	got := log.Runs[0].Results
+	if got[0].Properties[PropAuthor] != "Alice" || got[0].Properties[PropCommit] != first {
+		t.Errorf("line 3: expected Alice/%s, got %v/%v", first, got[0].Properties[PropAuthor], got[0].Properties[PropCommit])
+	}
+	if got[1].Properties[PropAuthor] != "Bob" || got[1].Properties[PropCommit] != second {
+		t.Errorf("line 5: expected Bob/%s, got %v/%v", second, got[1].Properties[PropAuthor], got[1].Properties[PropCommit])
+	}
+}
+
+func TestEnrich_AttributesRegionToNewestCommit(t *testing.T) {
+	dir := newRepo(t)
+	commitAt(t, dir, "main.go", "package main\n\nfunc a() {\n}\n", "Alice", "2026-01-01T12:00:00Z")
+	fix := commitAt(t, dir, "main.go", "package main\n\nfunc a() {\n\tpanic(1)\n}\n", "Bob", "2026-02-01T12:00:00Z")
+
+	log := sarif.NewLog("gavel", "test")
+	region := resultAt("main.go", 3)
+	region.Locations[0].PhysicalLocation.Region.EndLine = 5
+	log.Runs[0].Results = []sarif.Result{region, resultAt("main.go", 1)}
+
+	Enrich(context.Background(), log, dir)
+
+	props := log.Runs[0].Results[0].Properties
+	if props[PropAuthor] != "Bob" || props[PropCommit] != fix {
+		t.Errorf("expected the region blamed to Bob/%s, got %v", fix, props)
+	}
+	if props[PropIntroducedAt] != "2026-02-01T12:00:00Z" {
+		t.Errorf("%s = %v", PropIntroducedAt, props[PropIntroducedAt])
+	}
+	if age, ok := props[PropAgeDays].(int); !ok || age < 1 {
+		t.Errorf("%s = %v, want a positive number of days", PropAgeDays, props[PropAgeDays])
+	}
+	if got := log.Runs[0].Results[1].Properties[PropAuthor]; got != "Alice" {
+		t.Errorf("expected line 1 blamed to Alice, got %v", got)
+	}
+}
+
+func TestOwners(t *testing.T) {
+	owned := func(author, level string, age float64) sarif.Result {
+		r := resultAt("main.go", 1)
+		r.Level = level
+		if author != "" {
+			r.Properties = map[string]interface{}{PropAuthor: author, PropAgeDays: age}
+		}
+		return r
+	}
+	suppressed := owned("Alice", "error", 900)
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	informational := owned("Alice", "note", 0)
+	informational.Kind = sarif.KindInformational
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		owned("Bob", "warning", 3),
+		owned("Alice", "error", 40),
+		owned("Alice", "note", 12),
+		owned("", "warning", 0),
+		suppressed,
+		informational,
+	}
+
+	got := Owners(log)
+	want := []Owner{
+		{Author: "Alice", Findings: 2, Errors: 1, Notes: 1, Oldest: 40},
+		{Author: Unattributed, Findings: 1, Warnings: 1},
+		{Author: "Bob", Findings: 1, Warnings: 1, Oldest: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Owners = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Owners[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEnrich_NonRepoFileOmitsEnrichment(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "loose.go"), []byte("package loose\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{resultAt("loose.go", 1)}
+
+	Enrich(context.Background(), log, dir)
+
+	props := log.Runs[0].Results[0].Properties
+	if _, ok := props[PropAuthor]; ok {
+		t.Errorf("expected no author outside a repo, got %v", props)
+	}
+	if _, ok := props[PropCommit]; ok {
+		t.Errorf("expected no commit outside a repo, got %v", props)
+	}
+}
+
+func TestEnrich_UncommittedLinesOmitted(t *testing.T) {
+	dir := newRepo(t)
+	commitAs(t, dir, "main.go", "package main\n", "Alice")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nvar x = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{resultAt("main.go", 1), resultAt("main.go", 3)}
+
+	Enrich(context.Background(), log, dir)
+
+	if log.Runs[0].Results[0].Properties[PropAuthor] != "Alice" {
+		t.Errorf("expected committed line blamed to Alice, got %v", log.Runs[0].Results[0].Properties)
+	}
+	if _, ok := log.Runs[0].Results[1].Properties[PropAuthor]; ok {
+		t.Errorf("expected uncommitted line to have no author, got %v", log.Runs[0].Results[1].Properties)
+	}
+}
+
+func TestParsePorcelain_Malformed(t *testing.T) {
+	if _, err := parsePorcelain([]byte("garbage\n")); err == nil {
+		t.Error("expected error for malformed header")
+	}
+}
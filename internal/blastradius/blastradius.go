@@ -0,0 +1,176 @@
+// Package blastradius estimates how widely a Go file's package is used
+// within the analyzed set, so findings in heavily imported packages can be
+// prioritised over findings in leaf files.
+package blastradius
+
+import (
+	"bufio"
+	"bytes"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Property is the SARIF result property holding the number of analyzed
+// files, outside the finding's own package, that import the finding's
+// package.
+const Property = "gavel/blast-radius"
+
+// Graph is a lightweight import graph over a set of analyzed Go files. Only
+// imports between analyzed files are counted; dependencies outside the set
+// are ignored.
+type Graph struct {
+	radius map[string]int // cleaned artifact path -> blast radius
+}
+
+// Build parses the import blocks of the Go files in artifacts and counts,
+// for each file, how many analyzed files in other directories import its
+// package. A file's import path is derived from the nearest go.mod above
+// it on disk; files outside a module get a radius of zero.
+func Build(artifacts []input.Artifact) *Graph {
+	modules := make(map[string]module) // directory -> enclosing module
+	pkgOf := make(map[string]string)   // cleaned artifact path -> import path
+	importers := make(map[string]map[string]bool)
+
+	fset := token.NewFileSet()
+	for _, art := range artifacts {
+		if art.Kind != input.KindFile || !strings.HasSuffix(art.Path, ".go") {
+			continue
+		}
+		p := filepath.Clean(art.Path)
+		dir := filepath.Dir(p)
+		if pkg, ok := importPath(modules, dir); ok {
+			pkgOf[p] = pkg
+		}
+
+		f, err := parser.ParseFile(fset, p, art.Content, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range f.Imports {
+			ip, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			if importers[ip] == nil {
+				importers[ip] = make(map[string]bool)
+			}
+			importers[ip][p] = true
+		}
+	}
+
+	g := &Graph{radius: make(map[string]int, len(pkgOf))}
+	for p, pkg := range pkgOf {
+		n := 0
+		for importer := range importers[pkg] {
+			if filepath.Dir(importer) != filepath.Dir(p) {
+				n++
+			}
+		}
+		g.radius[p] = n
+	}
+	return g
+}
+
+// Radius returns the blast radius of the file at path, or zero when the
+// file was not part of the graph.
+func (g *Graph) Radius(path string) int {
+	if g == nil {
+		return 0
+	}
+	return g.radius[filepath.Clean(path)]
+}
+
+// Weight converts a blast radius into a priority multiplier: 1 for a leaf
+// file, growing logarithmically so a package imported by 3 files weighs
+// 1.5 and one imported by 15 weighs 2.
+func Weight(radius int) float64 {
+	if radius <= 0 {
+		return 1
+	}
+	return 1 + math.Log2(float64(radius+1))/4
+}
+
+// Enrich records each result's file blast radius in its properties. Results
+// in files with a radius of zero are left untouched.
+func Enrich(log *sarif.Log, g *Graph) {
+	for ri := range log.Runs {
+		results := log.Runs[ri].Results
+		for i := range results {
+			if len(results[i].Locations) == 0 {
+				continue
+			}
+			n := g.Radius(results[i].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+			if n == 0 {
+				continue
+			}
+			if results[i].Properties == nil {
+				results[i].Properties = make(map[string]interface{})
+			}
+			results[i].Properties[Property] = n
+		}
+	}
+}
+
+// module is a go.mod location and its declared module path.
+type module struct {
+	dir  string
+	path string
+}
+
+// importPath returns the Go import path of the package in dir, using and
+// filling the per-directory module cache.
+func importPath(cache map[string]module, dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	mod := findModule(cache, dir)
+	if mod.path == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(mod.dir, dir)
+	if err != nil {
+		return "", false
+	}
+	if rel == "." {
+		return mod.path, true
+	}
+	return path.Join(mod.path, filepath.ToSlash(rel)), true
+}
+
+// findModule walks up from dir to the nearest go.mod. A zero module means
+// none was found.
+func findModule(cache map[string]module, dir string) module {
+	if m, ok := cache[dir]; ok {
+		return m
+	}
+	var m module
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		m = module{dir: dir, path: modulePath(data)}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		m = findModule(cache, parent)
+	}
+	cache[dir] = m
+	return m
+}
+
+// modulePath extracts the module directive from go.mod contents.
+func modulePath(data []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package blastradius
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// testModule writes a go.mod for example.com/m into a temp dir and returns
+// artifacts for a core package imported by three other packages (plus its
+// own external test file) and a leaf package nobody imports. File contents
+// only need to exist in memory; Build reads nothing but go.mod from disk.
+func testModule(t *testing.T) (string, []input.Artifact) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/m\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := func(rel, content string) input.Artifact {
+		return input.Artifact{Path: filepath.Join(root, rel), Content: content, Kind: input.KindFile}
+	}
+	importsCore := "import \"example.com/m/pkg/core\"\n\nvar _ = core.X\n"
+	return root, []input.Artifact{
+		file("pkg/core/core.go", "package core\n\nvar X = 1\n"),
+		file("pkg/core/core_test.go", "package core_test\n\n"+importsCore),
+		file("cmd/a/main.go", "package main\n\n"+importsCore),
+		file("cmd/b/main.go", "package main\n\n"+importsCore),
+		file("internal/c/c.go", "package c\n\n"+importsCore),
+		file("internal/leaf/leaf.go", "package leaf\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint\n"),
+	}
+}
+
+func TestBuild_Radius(t *testing.T) {
+	root, arts := testModule(t)
+	g := Build(arts)
+
+	tests := []struct {
+		rel  string
+		want int
+	}{
+		{"pkg/core/core.go", 3},
+		{"pkg/core/core_test.go", 3},
+		{"cmd/a/main.go", 0},
+		{"internal/leaf/leaf.go", 0},
+		{"not/analyzed.go", 0},
+	}
+	for _, tc := range tests {
+		if got := g.Radius(filepath.Join(root, tc.rel)); got != tc.want {
+			t.Errorf("Radius(%s) = %d, want %d", tc.rel, got, tc.want)
+		}
+	}
+}
+
+func TestBuild_NoModule(t *testing.T) {
+	dir := t.TempDir()
+	g := Build([]input.Artifact{
+		{Path: filepath.Join(dir, "a.go"), Content: "package a\n", Kind: input.KindFile},
+	})
+	if got := g.Radius(filepath.Join(dir, "a.go")); got != 0 {
+		t.Errorf("expected radius 0 outside a module, got %d", got)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	if Weight(0) != 1 {
+		t.Errorf("Weight(0) = %v, want 1", Weight(0))
+	}
+	if Weight(3) != 1.5 || Weight(15) != 2 {
+		t.Errorf("Weight(3), Weight(15) = %v, %v, want 1.5, 2", Weight(3), Weight(15))
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	root, arts := testModule(t)
+	g := Build(arts)
+
+	result := func(rel string) sarif.Result {
+		return sarif.Result{RuleID: "R", Locations: []sarif.Location{{
+			PhysicalLocation: sarif.PhysicalLocation{ArtifactLocation: sarif.ArtifactLocation{URI: filepath.Join(root, rel)}},
+		}}}
+	}
+	log := &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		result("pkg/core/core.go"),
+		result("internal/leaf/leaf.go"),
+	}}}}
+	Enrich(log, g)
+
+	got := log.Runs[0].Results
+	if got[0].Properties[Property] != 3 {
+		t.Errorf("expected core finding radius 3, got %v", got[0].Properties[Property])
+	}
+	if _, ok := got[1].Properties[Property]; ok {
+		t.Errorf("expected leaf finding to be left unenriched, got %v", got[1].Properties)
+	}
+}
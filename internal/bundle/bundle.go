@@ -0,0 +1,192 @@
+// Package bundle distributes centrally governed Gavel policy: Rego verdict
+// policies and custom rule YAML packed into a versioned archive that is
+// pushed to an OCI registry or kept in a Git repository, and pulled into a
+// project's .gavel directory.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MaxSize bounds the unpacked contents of a bundle.
+const MaxSize = 16 << 20
+
+// Bundle holds the files of a policy bundle, keyed by slash-separated path
+// relative to the .gavel directory: rego/*.rego and rules/*.yaml.
+type Bundle struct {
+	Files map[string][]byte
+}
+
+// bundleDirs maps each directory a bundle may contain to the file
+// extensions allowed in it. Both are read non-recursively, matching how
+// judge loads Rego policies and analyze loads rules.
+var bundleDirs = map[string][]string{
+	"rego":  {".rego"},
+	"rules": {".yaml", ".yml"},
+}
+
+// Load collects the bundle files in dir, a .gavel directory or a checkout
+// laid out like one. It fails if dir holds no Rego policies or rules.
+func Load(dir string) (*Bundle, error) {
+	b := &Bundle{Files: make(map[string][]byte)}
+	size := 0
+	for sub := range bundleDirs {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := sub + "/" + e.Name()
+			if e.IsDir() || !allowed(name) {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, sub, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if size += len(data); size > MaxSize {
+				return nil, fmt.Errorf("bundle exceeds %d bytes", MaxSize)
+			}
+			b.Files[name] = data
+		}
+	}
+	if len(b.Files) == 0 {
+		return nil, fmt.Errorf("no rego/*.rego or rules/*.yaml files in %s", dir)
+	}
+	return b, nil
+}
+
+// allowed reports whether name is a path a bundle may contain.
+func allowed(name string) bool {
+	dir, file := path.Split(name)
+	exts, ok := bundleDirs[strings.TrimSuffix(dir, "/")]
+	if !ok || file == "" || strings.HasPrefix(file, ".") {
+		return false
+	}
+	for _, ext := range exts {
+		if strings.EqualFold(path.Ext(file), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Paths returns the bundle's file paths, sorted.
+func (b *Bundle) Paths() []string {
+	paths := make([]string, 0, len(b.Files))
+	for p := range b.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Digest identifies the bundle's contents independently of how they were
+// archived or compressed, so the same files pinned from a registry or a Git
+// repository have the same digest. It is the SHA-256 of each file's path
+// and content digest, in path order.
+func (b *Bundle) Digest() string {
+	h := sha256.New()
+	for _, p := range b.Paths() {
+		sum := sha256.Sum256(b.Files[p])
+		fmt.Fprintf(h, "%s\x00%s\n", p, hex.EncodeToString(sum[:]))
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Archive packs the bundle as a gzipped tar with fixed metadata, so the
+// same files always produce the same bytes.
+func (b *Bundle) Archive() ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, p := range b.Paths() {
+		data := b.Files[p]
+		if err := tw.WriteHeader(&tar.Header{Name: p, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg, Format: tar.FormatPAX}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Read unpacks an archive written by Archive. Entries other than regular
+// files under rego/ and rules/ with the expected extensions are rejected,
+// so a bundle cannot write anywhere else.
+func Read(archive []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	b := &Bundle{Files: make(map[string][]byte)}
+	size := int64(0)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || name != hdr.Name || !allowed(name) {
+			return nil, fmt.Errorf("bundle entry %q is not a rego/*.rego or rules/*.yaml file", hdr.Name)
+		}
+		if size += hdr.Size; size > MaxSize {
+			return nil, fmt.Errorf("bundle exceeds %d bytes", MaxSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle entry %s: %w", name, err)
+		}
+		b.Files[name] = data
+	}
+	if len(b.Files) == 0 {
+		return nil, errors.New("bundle is empty")
+	}
+	return b, nil
+}
+
+// Install writes the bundle's files under dir, replacing files of the same
+// name, and returns the paths written. Other files in dir are kept.
+func (b *Bundle) Install(dir string) ([]string, error) {
+	var written []string
+	for _, p := range b.Paths() {
+		dest := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(dest, b.Files[p], 0644); err != nil {
+			return written, err
+		}
+		written = append(written, dest)
+	}
+	return written, nil
+}
@@ -0,0 +1,102 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const gateRego = "package gavel.gate\n\nimport rego.v1\n\ndefault decision := \"review\"\n"
+
+func TestLoadArchiveRead(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"rego/gate.rego":      gateRego,
+		"rules/org.yaml":      "rules: []\n",
+		"rules/notes.txt":     "ignored",
+		"rules/nested/x.yaml": "ignored",
+		"policies.yaml":       "persona: code-reviewer\n",
+	})
+
+	b, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(b.Paths(), ","); got != "rego/gate.rego,rules/org.yaml" {
+		t.Fatalf("Paths = %s", got)
+	}
+
+	archive, err := b.Archive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := b.Archive()
+	if !bytes.Equal(archive, again) {
+		t.Error("expected archiving to be deterministic")
+	}
+
+	read, err := Read(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Digest() != b.Digest() || !strings.HasPrefix(b.Digest(), "sha256:") {
+		t.Errorf("digest changed through archiving: %s vs %s", read.Digest(), b.Digest())
+	}
+
+	out := t.TempDir()
+	writeTree(t, out, map[string]string{"rego/local.rego": gateRego})
+	files, err := read.Install(out)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Install = %v, %v", files, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "rules", "org.yaml")); string(data) != "rules: []\n" {
+		t.Errorf("installed rules = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(out, "rego", "local.rego")); err != nil {
+		t.Error("expected files outside the bundle to be kept")
+	}
+
+	b.Files["rules/org.yaml"] = []byte("rules: [changed]\n")
+	if b.Digest() == read.Digest() {
+		t.Error("expected the digest to change with the contents")
+	}
+}
+
+func TestLoad_Empty(t *testing.T) {
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without policies or rules")
+	}
+}
+
+func TestRead_RejectsUnexpectedEntries(t *testing.T) {
+	for _, name := range []string{"../evil.rego", "rego/../../evil.rego", "policies.yaml", "rego/run.sh", "/etc/passwd"} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+		tw.Write([]byte("x"))
+		tw.Close()
+		gz.Close()
+
+		if _, err := Read(buf.Bytes()); err == nil {
+			t.Errorf("expected entry %q to be rejected", name)
+		}
+	}
+}
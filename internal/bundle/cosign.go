@@ -0,0 +1,53 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// CosignBinary is the cosign executable used to sign and verify bundles.
+var CosignBinary = "cosign"
+
+// Verify checks the cosign signature of the manifest ref names, which
+// should be pinned by digest so the signature covers what was pulled,
+// against the public key at keyPath.
+func Verify(ctx context.Context, reg *Registry, ref Reference, keyPath string) error {
+	args := []string{"verify", "--key", keyPath}
+	if reg.PlainHTTP() {
+		args = append(args, "--allow-http-registry")
+	}
+	if err := cosign(ctx, append(args, ref.String())...); err != nil {
+		return fmt.Errorf("verifying signature of %s: %w", ref, err)
+	}
+	return nil
+}
+
+// Sign signs the manifest ref names with the cosign private key at keyPath
+// and stores the signature in the registry next to it.
+func Sign(ctx context.Context, reg *Registry, ref Reference, keyPath string) error {
+	args := []string{"sign", "--yes", "--key", keyPath}
+	if reg.PlainHTTP() {
+		args = append(args, "--allow-http-registry")
+	}
+	if err := cosign(ctx, append(args, ref.String())...); err != nil {
+		return fmt.Errorf("signing %s: %w", ref, err)
+	}
+	return nil
+}
+
+func cosign(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, CosignBinary, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s not found; install cosign to sign and verify bundles", CosignBinary)
+		}
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
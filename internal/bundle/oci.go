@@ -0,0 +1,498 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Media types of a bundle stored as an OCI artifact.
+const (
+	ArtifactType      = "application/vnd.gavel.policy.bundle.v1"
+	LayerMediaType    = "application/vnd.gavel.policy.bundle.v1.tar+gzip"
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	emptyMediaType    = "application/vnd.oci.empty.v1+json"
+
+	// AnnotationDigest records the bundle's content digest on its manifest.
+	AnnotationDigest = "dev.gavel.bundle.digest"
+)
+
+// emptyConfig is the OCI empty descriptor's content, used as the config of
+// artifacts that have none.
+var emptyConfig = []byte("{}")
+
+// Reference names a bundle in an OCI registry, e.g.
+// ghcr.io/acme/gavel-policies:v1.2.0 or ...@sha256:<digest>.
+type Reference struct {
+	Host       string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+var repositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+
+// ParseReference parses host/repository[:tag][@digest]. Without a tag or
+// digest the tag is "latest".
+func ParseReference(s string) (Reference, error) {
+	var ref Reference
+	rest := s
+	if i := strings.Index(rest, "@"); i >= 0 {
+		ref.Digest = rest[i+1:]
+		rest = rest[:i]
+		if !validDigest(ref.Digest) {
+			return Reference{}, fmt.Errorf("reference %q: invalid digest %q", s, ref.Digest)
+		}
+	}
+	slash := strings.Index(rest, "/")
+	if slash <= 0 {
+		return Reference{}, fmt.Errorf("reference %q: expected host/repository[:tag]", s)
+	}
+	ref.Host, rest = rest[:slash], rest[slash+1:]
+	if i := strings.LastIndex(rest, ":"); i >= 0 {
+		ref.Tag, rest = rest[i+1:], rest[:i]
+	}
+	ref.Repository = rest
+	if !repositoryPattern.MatchString(ref.Repository) {
+		return Reference{}, fmt.Errorf("reference %q: invalid repository %q", s, ref.Repository)
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// validDigest reports whether d is a sha256:<hex> digest.
+func validDigest(d string) bool {
+	hexPart, ok := strings.CutPrefix(d, "sha256:")
+	if !ok || len(hexPart) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(hexPart)
+	return err == nil
+}
+
+// String formats the reference as ParseReference accepts it.
+func (r Reference) String() string {
+	s := r.Host + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// WithDigest returns the reference pinned to a manifest digest.
+func (r Reference) WithDigest(d string) Reference {
+	return Reference{Host: r.Host, Repository: r.Repository, Digest: d}
+}
+
+// manifestRef is the tag or digest to request the manifest by.
+func (r Reference) manifestRef() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// Registry is a minimal client for the OCI distribution API, enough to
+// push and pull bundles.
+type Registry struct {
+	client    *http.Client
+	plainHTTP bool
+	username  string
+	password  string
+	tokens    map[string]string // Bearer token per scope
+}
+
+// RegistryOption configures a Registry.
+type RegistryOption func(*Registry)
+
+// WithPlainHTTP talks to the registry over HTTP instead of HTTPS, for local
+// test registries.
+func WithPlainHTTP(plain bool) RegistryOption {
+	return func(r *Registry) {
+		r.plainHTTP = plain
+	}
+}
+
+// WithCredentials authenticates to the registry, or its token service,
+// with a username and password or access token.
+func WithCredentials(username, password string) RegistryOption {
+	return func(r *Registry) {
+		r.username = username
+		r.password = password
+	}
+}
+
+// WithHTTPClient replaces the default HTTP client.
+func WithHTTPClient(c *http.Client) RegistryOption {
+	return func(r *Registry) {
+		r.client = c
+	}
+}
+
+// NewRegistry creates a registry client.
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
+		client: &http.Client{Timeout: 2 * time.Minute},
+		tokens: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// PlainHTTP reports whether the registry is reached over HTTP.
+func (r *Registry) PlainHTTP() bool {
+	return r.plainHTTP
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        descriptor        `json:"config"`
+	Layers        []descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Pull fetches the bundle at ref and returns it with the digest of the
+// manifest it was read from. Downloaded content is checked against the
+// digests the manifest names.
+func (r *Registry) Pull(ctx context.Context, ref Reference) (*Bundle, string, error) {
+	resp, err := r.do(ctx, ref, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url(ref, "manifests/"+ref.manifestRef()), nil)
+		if err == nil {
+			req.Header.Set("Accept", manifestMediaType)
+		}
+		return req, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	body, err := readBody(resp, http.StatusOK, 4<<20)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching manifest for %s: %w", ref, err)
+	}
+	manifestDigest := digestOf(body)
+	if ref.Digest != "" && manifestDigest != ref.Digest {
+		return nil, "", fmt.Errorf("manifest for %s has digest %s", ref, manifestDigest)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, "", fmt.Errorf("parsing manifest for %s: %w", ref, err)
+	}
+	var layer *descriptor
+	for i := range m.Layers {
+		if m.Layers[i].MediaType == LayerMediaType {
+			layer = &m.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return nil, "", fmt.Errorf("%s is not a Gavel policy bundle: no %s layer", ref, LayerMediaType)
+	}
+	if layer.Size > MaxSize {
+		return nil, "", fmt.Errorf("bundle layer of %s is %d bytes, over the %d byte limit", ref, layer.Size, MaxSize)
+	}
+
+	resp, err = r.do(ctx, ref, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, r.url(ref, "blobs/"+layer.Digest), nil)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	archive, err := readBody(resp, http.StatusOK, MaxSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching bundle layer of %s: %w", ref, err)
+	}
+	if got := digestOf(archive); got != layer.Digest {
+		return nil, "", fmt.Errorf("bundle layer of %s has digest %s, manifest says %s", ref, got, layer.Digest)
+	}
+	b, err := Read(archive)
+	if err != nil {
+		return nil, "", err
+	}
+	return b, manifestDigest, nil
+}
+
+// Push uploads b to ref's repository and tags it, returning the digest of
+// the manifest written.
+func (r *Registry) Push(ctx context.Context, ref Reference, b *Bundle) (string, error) {
+	if ref.Tag == "" {
+		return "", fmt.Errorf("pushing %s: a tag is required", ref)
+	}
+	archive, err := b.Archive()
+	if err != nil {
+		return "", err
+	}
+	if err := r.pushBlob(ctx, ref, emptyConfig); err != nil {
+		return "", err
+	}
+	if err := r.pushBlob(ctx, ref, archive); err != nil {
+		return "", err
+	}
+
+	m := manifest{
+		SchemaVersion: 2,
+		MediaType:     manifestMediaType,
+		ArtifactType:  ArtifactType,
+		Config:        descriptor{MediaType: emptyMediaType, Digest: digestOf(emptyConfig), Size: int64(len(emptyConfig))},
+		Layers: []descriptor{{
+			MediaType:   LayerMediaType,
+			Digest:      digestOf(archive),
+			Size:        int64(len(archive)),
+			Annotations: map[string]string{"org.opencontainers.image.title": "bundle.tar.gz"},
+		}},
+		Annotations: map[string]string{AnnotationDigest: b.Digest()},
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.do(ctx, ref, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.url(ref, "manifests/"+ref.Tag), bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", manifestMediaType)
+		}
+		return req, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := readBody(resp, http.StatusCreated, 1<<20); err != nil {
+		return "", fmt.Errorf("pushing manifest for %s: %w", ref, err)
+	}
+	return digestOf(body), nil
+}
+
+// pushBlob uploads data unless the repository already has it.
+func (r *Registry) pushBlob(ctx context.Context, ref Reference, data []byte) error {
+	digest := digestOf(data)
+	resp, err := r.do(ctx, ref, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodHead, r.url(ref, "blobs/"+digest), nil)
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = r.do(ctx, ref, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, r.url(ref, "blobs/uploads/"), nil)
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := readBody(resp, http.StatusAccepted, 1<<20); err != nil {
+		return fmt.Errorf("starting upload to %s: %w", ref, err)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return fmt.Errorf("starting upload to %s: no upload location", ref)
+	}
+	q := location.Query()
+	q.Set("digest", digest)
+	location.RawQuery = q.Encode()
+
+	resp, err = r.do(ctx, ref, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, location.String(), bytes.NewReader(data))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/octet-stream")
+		}
+		return req, err
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := readBody(resp, http.StatusCreated, 1<<20); err != nil {
+		return fmt.Errorf("uploading %s to %s: %w", digest, ref, err)
+	}
+	return nil
+}
+
+// url returns the distribution API URL of path in ref's repository.
+func (r *Registry) url(ref Reference, path string) string {
+	scheme := "https"
+	if r.plainHTTP {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s", scheme, ref.Host, ref.Repository, path)
+}
+
+// do sends the request newReq builds, answering an authentication
+// challenge once: with basic credentials, or with a bearer token from the
+// registry's token service.
+func (r *Registry) do(ctx context.Context, ref Reference, newReq func() (*http.Request, error)) (*http.Response, error) {
+	scope := "repository:" + ref.Repository + ":pull,push"
+	req, err := newReq()
+	if err != nil {
+		return nil, err
+	}
+	r.authorize(req, scope)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contacting registry %s: %w", ref.Host, err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "bearer":
+		if params["scope"] != "" {
+			scope = params["scope"]
+		}
+		token, err := r.fetchToken(ctx, params["realm"], params["service"], scope)
+		if err != nil {
+			return nil, err
+		}
+		r.tokens[scope] = token
+	case "basic":
+		if r.username == "" {
+			return nil, fmt.Errorf("registry %s requires credentials", ref.Host)
+		}
+	default:
+		return nil, fmt.Errorf("registry %s: unsupported authentication %q", ref.Host, challenge)
+	}
+
+	req, err = newReq()
+	if err != nil {
+		return nil, err
+	}
+	r.authorize(req, scope)
+	resp, err = r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contacting registry %s: %w", ref.Host, err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry %s denied access to %s: %s", ref.Host, ref.Repository, resp.Status)
+	}
+	return resp, nil
+}
+
+// authorize adds the token for scope, or basic credentials, to req.
+func (r *Registry) authorize(req *http.Request, scope string) {
+	if token, ok := r.tokens[scope]; ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+}
+
+// fetchToken obtains a bearer token from a registry token service,
+// anonymously unless credentials are configured.
+func (r *Registry) fetchToken(ctx context.Context, realm, service, scope string) (string, error) {
+	if realm == "" {
+		return "", errors.New("registry token challenge has no realm")
+	}
+	u, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("registry token realm %q: %w", realm, err)
+	}
+	q := u.Query()
+	if service != "" {
+		q.Set("service", service)
+	}
+	q.Set("scope", scope)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching registry token: %w", err)
+	}
+	body, err := readBody(resp, http.StatusOK, 1<<20)
+	if err != nil {
+		return "", fmt.Errorf("fetching registry token: %w", err)
+	}
+	var out struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("parsing registry token: %w", err)
+	}
+	if out.Token != "" {
+		return out.Token, nil
+	}
+	if out.AccessToken != "" {
+		return out.AccessToken, nil
+	}
+	return "", errors.New("registry token service returned no token")
+}
+
+// challengeParam matches one key="value" pair of a WWW-Authenticate header.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseChallenge splits a WWW-Authenticate header into its lowercased
+// scheme and parameters.
+func parseChallenge(h string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(h), " ")
+	params := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(rest, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	return strings.ToLower(scheme), params
+}
+
+// readBody reads and closes resp's body, at most limit bytes, failing
+// unless the status is want.
+func readBody(resp *http.Response, want int, limit int64) ([]byte, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != want {
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, msg)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
+	}
+	return body, nil
+}
+
+// digestOf returns the OCI sha256 digest of data.
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,172 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry is an in-memory OCI registry that requires a bearer token
+// from its own token endpoint.
+type fakeRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte // by tag and by digest
+	uploads   int
+	srv       *httptest.Server
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	r := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	r.srv = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.srv.Close)
+	return r
+}
+
+func (r *fakeRegistry) host() string {
+	return strings.TrimPrefix(r.srv.URL, "http://")
+}
+
+func (r *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		if !strings.HasPrefix(req.URL.Query().Get("scope"), "repository:acme/policies:") {
+			http.Error(w, "bad scope", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"token":"secret"}`)
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:acme/policies:pull,push"`, r.srv.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	const prefix = "/v2/acme/policies/"
+	path := strings.TrimPrefix(req.URL.Path, prefix)
+	body, _ := io.ReadAll(req.Body)
+	switch {
+	case req.Method == http.MethodHead && strings.HasPrefix(path, "blobs/"):
+		if _, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "blobs/"):
+		data, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	case req.Method == http.MethodPost && path == "blobs/uploads/":
+		r.uploads++
+		w.Header().Set("Location", fmt.Sprintf("%supload/%d?state=x", prefix, r.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "upload/"):
+		digest := req.URL.Query().Get("digest")
+		if req.URL.Query().Get("state") != "x" || digestOf(body) != digest {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = body
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+		r.manifests[strings.TrimPrefix(path, "manifests/")] = body
+		r.manifests[digestOf(body)] = body
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "manifests/"):
+		data, ok := r.manifests[strings.TrimPrefix(path, "manifests/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", manifestMediaType)
+		w.Write(data)
+	default:
+		http.Error(w, "unexpected "+req.Method+" "+req.URL.Path, http.StatusBadRequest)
+	}
+}
+
+func TestRegistry_PushPull(t *testing.T) {
+	fake := newFakeRegistry(t)
+	reg := NewRegistry(WithPlainHTTP(true))
+	ref, err := ParseReference(fake.host() + "/acme/policies:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bundle{Files: map[string][]byte{"rego/gate.rego": []byte(gateRego)}}
+
+	manifestDigest, err := reg.Push(context.Background(), ref, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(fake.manifests["v1"]), b.Digest()) {
+		t.Error("expected the manifest to record the content digest")
+	}
+
+	pulled, digest, err := NewRegistry(WithPlainHTTP(true)).Pull(context.Background(), ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != manifestDigest || pulled.Digest() != b.Digest() {
+		t.Errorf("pulled %s / %s, want %s / %s", digest, pulled.Digest(), manifestDigest, b.Digest())
+	}
+
+	if _, _, err := reg.Pull(context.Background(), ref.WithDigest(manifestDigest)); err != nil {
+		t.Errorf("pull by digest: %v", err)
+	}
+
+	// Pushing the same bundle again reuses the stored blobs
+	uploads := fake.uploads
+	if _, err := reg.Push(context.Background(), ref, b); err != nil {
+		t.Fatal(err)
+	}
+	if fake.uploads != uploads {
+		t.Errorf("expected existing blobs to be skipped, got %d new uploads", fake.uploads-uploads)
+	}
+}
+
+func TestRegistry_PullRejectsTamperedLayer(t *testing.T) {
+	fake := newFakeRegistry(t)
+	reg := NewRegistry(WithPlainHTTP(true))
+	ref, _ := ParseReference(fake.host() + "/acme/policies:v1")
+	b := &Bundle{Files: map[string][]byte{"rego/gate.rego": []byte(gateRego)}}
+	if _, err := reg.Push(context.Background(), ref, b); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, _ := b.Archive()
+	fake.blobs[digestOf(archive)] = []byte("tampered")
+	if _, _, err := reg.Pull(context.Background(), ref); err == nil || !strings.Contains(err.Error(), "digest") {
+		t.Errorf("expected a digest mismatch, got %v", err)
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	for _, tc := range []struct {
+		in   string
+		want Reference
+	}{
+		{"ghcr.io/acme/policies:v1.2.0", Reference{Host: "ghcr.io", Repository: "acme/policies", Tag: "v1.2.0"}},
+		{"localhost:5000/policies", Reference{Host: "localhost:5000", Repository: "policies", Tag: "latest"}},
+		{"ghcr.io/acme/policies@" + digest, Reference{Host: "ghcr.io", Repository: "acme/policies", Digest: digest}},
+	} {
+		got, err := ParseReference(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseReference(%q) = %+v, %v, want %+v", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"policies", "ghcr.io/Acme/policies", "ghcr.io/acme/policies@sha256:abc"} {
+		if _, err := ParseReference(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Source is where a bundle is pulled from: oci://host/repository[:tag]
+// for a registry, or git+<url>[#ref] for a Git repository laid out like a
+// .gavel directory.
+type Source struct {
+	Raw    string
+	OCI    *Reference // Set for registry sources
+	GitURL string     // Set for Git sources
+	GitRef string     // Branch, tag or commit; empty for the default branch
+}
+
+// ParseSource parses a bundle source.
+func ParseSource(s string) (Source, error) {
+	switch {
+	case strings.HasPrefix(s, "oci://"):
+		ref, err := ParseReference(strings.TrimPrefix(s, "oci://"))
+		if err != nil {
+			return Source{}, err
+		}
+		return Source{Raw: s, OCI: &ref}, nil
+	case strings.HasPrefix(s, "git+"):
+		u, ref, _ := strings.Cut(strings.TrimPrefix(s, "git+"), "#")
+		if u == "" {
+			return Source{}, fmt.Errorf("bundle source %q: missing repository URL", s)
+		}
+		return Source{Raw: s, GitURL: u, GitRef: ref}, nil
+	default:
+		return Source{}, fmt.Errorf("bundle source %q: expected oci://host/repository[:tag] or git+<url>[#ref]", s)
+	}
+}
+
+// Pulled is a fetched bundle and the exact revision it came from.
+type Pulled struct {
+	Bundle *Bundle
+	// Revision is the manifest digest for registry sources, or the commit
+	// for Git sources.
+	Revision string
+}
+
+// Pull fetches the bundle src names, using reg for registry sources.
+func Pull(ctx context.Context, src Source, reg *Registry) (*Pulled, error) {
+	if src.OCI != nil {
+		b, digest, err := reg.Pull(ctx, *src.OCI)
+		if err != nil {
+			return nil, err
+		}
+		return &Pulled{Bundle: b, Revision: digest}, nil
+	}
+	return pullGit(ctx, src.GitURL, src.GitRef)
+}
+
+// pullGit fetches one commit of a Git repository into a temporary
+// directory and loads the bundle from its rego/ and rules/ directories.
+func pullGit(ctx context.Context, repoURL, ref string) (*Pulled, error) {
+	// git would read either as an option, such as --upload-pack=<command>
+	if strings.HasPrefix(repoURL, "-") {
+		return nil, fmt.Errorf("bundle repository URL %q must not start with -", repoURL)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("bundle ref %q must not start with -", ref)
+	}
+
+	dir, err := os.MkdirTemp("", "gavel-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", "--", repoURL, ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		if _, err := git(ctx, dir, args...); err != nil {
+			return nil, err
+		}
+	}
+	commit, err := git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	b, err := Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s#%s: %w", repoURL, ref, err)
+	}
+	return &Pulled{Bundle: b, Revision: commit}, nil
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,95 @@
+package bundle
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	src, err := ParseSource("oci://ghcr.io/acme/policies:v1")
+	if err != nil || src.OCI == nil || src.OCI.Tag != "v1" {
+		t.Errorf("unexpected OCI source %+v, %v", src, err)
+	}
+	src, err = ParseSource("git+https://github.com/acme/policies.git#v1.2.0")
+	if err != nil || src.GitURL != "https://github.com/acme/policies.git" || src.GitRef != "v1.2.0" {
+		t.Errorf("unexpected Git source %+v, %v", src, err)
+	}
+	if _, err := ParseSource("https://example.com/policies.tar.gz"); err == nil {
+		t.Error("expected an unknown scheme to be rejected")
+	}
+}
+
+func TestPull_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	writeTree(t, repo, map[string]string{"rego/gate.rego": gateRego, "README.md": "policies"})
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "policies"},
+		{"tag", "v1"},
+	} {
+		if _, err := git(context.Background(), repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src, _ := ParseSource("git+file://" + repo + "#v1")
+	pulled, err := Pull(context.Background(), src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pulled.Bundle.Paths(), ","); got != "rego/gate.rego" {
+		t.Errorf("Paths = %s", got)
+	}
+	head, _ := git(context.Background(), repo, "rev-parse", "HEAD")
+	if pulled.Revision != head {
+		t.Errorf("Revision = %s, want %s", pulled.Revision, head)
+	}
+}
+
+func TestPull_GitRejectsOptions(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	for _, s := range []string{
+		"git+--upload-pack=touch " + marker + "#main",
+		"git+https://github.com/acme/policies.git#--upload-pack=touch " + marker,
+	} {
+		src, err := ParseSource(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Pull(context.Background(), src, nil); err == nil || !strings.Contains(err.Error(), "must not start with -") {
+			t.Errorf("%s: expected an option-like value to be rejected, got %v", s, err)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("expected no command to run")
+	}
+}
+
+func TestVerify_RunsCosign(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "cosign")
+	log := filepath.Join(dir, "args")
+	os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+log+"\n[ \"$3\" = cosign.pub ]\n"), 0755)
+	defer func(orig string) { CosignBinary = orig }(CosignBinary)
+	CosignBinary = script
+
+	ref := Reference{Host: "localhost:5000", Repository: "policies", Digest: "sha256:" + strings.Repeat("a", 64)}
+	if err := Verify(context.Background(), NewRegistry(WithPlainHTTP(true)), ref, "cosign.pub"); err != nil {
+		t.Fatal(err)
+	}
+	args, _ := os.ReadFile(log)
+	if want := "verify --key cosign.pub --allow-http-registry " + ref.String(); strings.TrimSpace(string(args)) != want {
+		t.Errorf("cosign args = %q, want %q", args, want)
+	}
+	if err := Verify(context.Background(), NewRegistry(), ref, "other.pub"); err == nil {
+		t.Error("expected a failed verification to be reported")
+	}
+}
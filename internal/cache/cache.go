@@ -236,6 +236,21 @@ func ContentKey(content, policies, persona string) string {
 	return GenerateKey(content, policies, persona)
 }
 
+// NormalizedContentKey creates a cache key for code analysis from a
+// normalized form of the content, such as its token stream. It never equals
+// a ContentKey, because results cached under it are not tied to the exact
+// text they were produced from.
+func NormalizedContentKey(normalized, policies, persona string) string {
+	return GenerateKey("normalized", normalized, policies, persona)
+}
+
+// ResponseKey creates a cache key for one LLM call from everything sent in
+// the prompt, so identical prompts share a cached response whichever path
+// made them. namespace separates providers and models.
+func ResponseKey(namespace, code, policies, persona, additionalContext string) string {
+	return GenerateKey("response", namespace, code, policies, persona, additionalContext)
+}
+
 // PromptHash computes a SHA256 hash of the combined persona prompt and policy text.
 func PromptHash(personaPrompt, policyText string) string {
 	return GenerateKey(personaPrompt, policyText)
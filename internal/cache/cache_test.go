@@ -236,6 +236,15 @@ func TestContentKey(t *testing.T) {
 	}
 }
 
+func TestNormalizedContentKey(t *testing.T) {
+	if NormalizedContentKey("a", "p", "c") == ContentKey("a", "p", "c") {
+		t.Error("normalized and raw keys for the same text should differ")
+	}
+	if NormalizedContentKey("a", "p", "c") != NormalizedContentKey("a", "p", "c") {
+		t.Error("same normalized content should produce same key")
+	}
+}
+
 func TestEntry_IsExpired(t *testing.T) {
 	// Entry with zero time (never expires)
 	e1 := &Entry{ExpiresAt: time.Time{}}
@@ -0,0 +1,249 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// AnalysisCache is the cache used by the tiered analyzer. Cache keeps
+// entries in memory for the life of the process; DiskCache persists them
+// between runs.
+type AnalysisCache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+	Stats() CacheStats
+	Clear()
+}
+
+var (
+	_ AnalysisCache = (*Cache)(nil)
+	_ AnalysisCache = (*DiskCache)(nil)
+)
+
+// diskEntry is the JSON document stored for each DiskCache entry.
+type diskEntry struct {
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at,omitempty"`
+	Results   []sarif.Result  `json:"results"`
+	Response  json.RawMessage `json:"response,omitempty"` // Raw LLM response, when the entry holds one instead of results
+}
+
+// DiskCache is an AnalysisCache that stores analysis results as one JSON
+// file per entry in a directory, so they survive between CLI invocations.
+// Only []sarif.Result values, as the analyzer caches, and json.RawMessage
+// values, as the response cache stores, are persisted; Set ignores values
+// of other types. Entries older than the TTL are
+// treated as misses, and the oldest entries are evicted once the cache
+// holds its maximum number of entries.
+type DiskCache struct {
+	dir       string
+	namespace string
+	maxSize   int
+	ttl       time.Duration
+
+	mu    sync.Mutex
+	index map[string]time.Time // file name -> creation time, for eviction
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// DiskOption configures a DiskCache
+type DiskOption func(*DiskCache)
+
+// WithDiskMaxSize sets the maximum number of entries kept on disk
+func WithDiskMaxSize(n int) DiskOption {
+	return func(c *DiskCache) {
+		c.maxSize = n
+	}
+}
+
+// WithDiskTTL sets how long entries stay valid. Zero keeps them until they
+// are evicted.
+func WithDiskTTL(d time.Duration) DiskOption {
+	return func(c *DiskCache) {
+		c.ttl = d
+	}
+}
+
+// WithDiskNamespace mixes ns into every key. Callers use it to separate
+// results produced under different settings that are not part of the key
+// itself, such as the model or the rule set, so a changed setting never
+// serves stale results.
+func WithDiskNamespace(ns string) DiskOption {
+	return func(c *DiskCache) {
+		c.namespace = ns
+	}
+}
+
+// NewDiskCache opens (creating if needed) a disk cache in dir.
+func NewDiskCache(dir string, opts ...DiskOption) (*DiskCache, error) {
+	c := &DiskCache{
+		dir:     dir,
+		maxSize: 10000,
+		ttl:     7 * 24 * time.Hour,
+		index:   make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory %s: %w", dir, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading cache directory %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		c.index[e.Name()] = info.ModTime()
+	}
+	return c, nil
+}
+
+// fileName returns the entry file name for key within the namespace.
+func (c *DiskCache) fileName(key string) string {
+	if c.namespace != "" {
+		key = GenerateKey(c.namespace, key)
+	}
+	return key + ".json"
+}
+
+// Get returns the cached results or raw response for key, or false if there
+// is no unexpired entry.
+func (c *DiskCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := c.fileName(key)
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		c.misses++
+		return nil, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || (!entry.ExpiresAt.IsZero() && time.Now().After(entry.ExpiresAt)) {
+		c.remove(name)
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	if entry.Response != nil {
+		return entry.Response, true
+	}
+	return entry.Results, true
+}
+
+// Set stores value under key if it is a []sarif.Result or a
+// json.RawMessage. Write errors are ignored: a cache that cannot be written
+// only costs a later miss.
+func (c *DiskCache) Set(key string, value interface{}) {
+	now := time.Now()
+	entry := diskEntry{CreatedAt: now}
+	switch v := value.(type) {
+	case []sarif.Result:
+		entry.Results = v
+	case json.RawMessage:
+		entry.Response = v
+	default:
+		return
+	}
+	if c.ttl > 0 {
+		entry.ExpiresAt = now.Add(c.ttl)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := c.fileName(key)
+	if _, exists := c.index[name]; !exists {
+		for c.maxSize > 0 && len(c.index) >= c.maxSize {
+			c.evictOldest()
+		}
+	}
+
+	// Write to a temp file and rename so concurrent runs never read a
+	// partially written entry.
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil || os.Rename(tmp.Name(), filepath.Join(c.dir, name)) != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	c.index[name] = now
+}
+
+// Stats returns cache statistics. Size counts entries on disk, including
+// expired ones that have not been looked up since they expired.
+func (c *DiskCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := c.hits + c.misses
+	hitRate := 0.0
+	if total > 0 {
+		hitRate = float64(c.hits) / float64(total)
+	}
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		HitRate:   hitRate,
+		Size:      len(c.index),
+		MaxSize:   c.maxSize,
+		Evictions: c.evictions,
+	}
+}
+
+// Clear removes every entry from the cache directory.
+func (c *DiskCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.index {
+		c.remove(name)
+	}
+}
+
+// evictOldest removes the entry created first. Must be called with lock held.
+func (c *DiskCache) evictOldest() {
+	var oldest string
+	var oldestTime time.Time
+	for name, created := range c.index {
+		if oldest == "" || created.Before(oldestTime) {
+			oldest = name
+			oldestTime = created
+		}
+	}
+	if oldest != "" {
+		c.remove(oldest)
+		c.evictions++
+	}
+}
+
+// remove deletes an entry file. Must be called with lock held.
+func (c *DiskCache) remove(name string) {
+	os.Remove(filepath.Join(c.dir, name))
+	delete(c.index, name)
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func diskResults(ruleID string) []sarif.Result {
+	return []sarif.Result{{
+		RuleID:     ruleID,
+		Level:      "warning",
+		Message:    sarif.Message{Text: ruleID + " finding"},
+		Properties: map[string]interface{}{"gavel/tier": "comprehensive"},
+	}}
+}
+
+func TestDiskCache_PersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache: %v", err)
+	}
+	c.Set("key1", diskResults("R1"))
+
+	reopened, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache: %v", err)
+	}
+	val, ok := reopened.Get("key1")
+	if !ok {
+		t.Fatal("expected key1 to survive reopening the cache")
+	}
+	results, ok := val.([]sarif.Result)
+	if !ok || len(results) != 1 || results[0].RuleID != "R1" || results[0].Properties["gavel/tier"] != "comprehensive" {
+		t.Errorf("unexpected cached value %#v", val)
+	}
+
+	stats := reopened.Stats()
+	if stats.Hits != 1 || stats.Size != 1 {
+		t.Errorf("expected 1 hit and size 1, got %+v", stats)
+	}
+}
+
+func TestDiskCache_IgnoresOtherValueTypes(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("key1", "not results")
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected non-result values not to be cached")
+	}
+}
+
+func TestDiskCache_PersistsRawResponses(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("key1", json.RawMessage(`[{"ruleId":"R1"}]`))
+
+	reopened, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, ok := reopened.Get("key1")
+	if raw, isRaw := val.(json.RawMessage); !ok || !isRaw || string(raw) != `[{"ruleId":"R1"}]` {
+		t.Errorf("expected the raw response back, got %#v", val)
+	}
+}
+
+func TestDiskCache_TTL(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir, WithDiskTTL(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("key1", diskResults("R1"))
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected expired entry to miss")
+	}
+	if c.Stats().Size != 0 {
+		t.Errorf("expected expired entry to be removed, size %d", c.Stats().Size)
+	}
+}
+
+func TestDiskCache_EvictsOldest(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), WithDiskMaxSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("key1", diskResults("R1"))
+	time.Sleep(2 * time.Millisecond)
+	c.Set("key2", diskResults("R2"))
+	time.Sleep(2 * time.Millisecond)
+	c.Set("key3", diskResults("R3"))
+
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+	for _, k := range []string{"key2", "key3"} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("expected %s to remain", k)
+		}
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("expected 1 eviction and size 2, got %+v", stats)
+	}
+}
+
+func TestDiskCache_Namespace(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewDiskCache(dir, WithDiskNamespace("model-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Set("key1", diskResults("R1"))
+
+	b, err := NewDiskCache(dir, WithDiskNamespace("model-b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.Get("key1"); ok {
+		t.Error("expected entries from another namespace to miss")
+	}
+	if _, ok := a.Get("key1"); !ok {
+		t.Error("expected entry to hit in its own namespace")
+	}
+}
+
+func TestDiskCache_Clear(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("key1", diskResults("R1"))
+	c.Set("key2", diskResults("R2"))
+	c.Clear()
+
+	if c.Stats().Size != 0 {
+		t.Errorf("expected empty cache after Clear, size %d", c.Stats().Size)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 0 {
+		t.Errorf("expected no files after Clear, got %v", files)
+	}
+}
+
+func TestDiskCache_CorruptEntryMisses(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "key1.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected corrupt entry to miss")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "key1.json")); !os.IsNotExist(err) {
+		t.Error("expected corrupt entry to be removed")
+	}
+}
@@ -0,0 +1,312 @@
+// internal/cache/redis.go
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisConfig configures a RedisStorage.
+type RedisConfig struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	DB       int
+	// Prefix is prepended to every key, e.g. "gavel:cache:".
+	Prefix string
+	// TTL, when positive, is set as each key's expiry so Redis drops
+	// entries on its own.
+	TTL time.Duration
+}
+
+// ParseRedisURL parses a redis://[user:password@]host[:port][/db] URL.
+func ParseRedisURL(raw string) (RedisConfig, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return RedisConfig{}, fmt.Errorf("redis: invalid URL: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return RedisConfig{}, fmt.Errorf("redis: unsupported URL scheme %q (want redis)", u.Scheme)
+	}
+	cfg := RedisConfig{Addr: u.Host}
+	if u.Port() == "" {
+		cfg.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		cfg.Username = u.User.Username()
+		cfg.Password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		cfg.DB, err = strconv.Atoi(db)
+		if err != nil {
+			return RedisConfig{}, fmt.Errorf("redis: invalid database %q", db)
+		}
+	}
+	return cfg, nil
+}
+
+// RedisStorage implements Storage on a Redis server, speaking the RESP
+// protocol over a single connection that is re-established after errors.
+type RedisStorage struct {
+	cfg RedisConfig
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisStorage creates a Redis-backed storage. The connection is opened
+// on first use.
+func NewRedisStorage(cfg RedisConfig) (*RedisStorage, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("redis: address is required")
+	}
+	return &RedisStorage{cfg: cfg}, nil
+}
+
+// Get retrieves data for the given key
+func (s *RedisStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := s.do(ctx, "GET", s.cfg.Prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrCacheMiss
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return data, nil
+}
+
+// Put stores data for the given key
+func (s *RedisStorage) Put(ctx context.Context, key string, data []byte) error {
+	args := []string{"SET", s.cfg.Prefix + key, string(data)}
+	if s.cfg.TTL > 0 {
+		args = append(args, "PX", strconv.FormatInt(s.cfg.TTL.Milliseconds(), 10))
+	}
+	_, err := s.do(ctx, args...)
+	return err
+}
+
+// Delete removes data for the given key
+func (s *RedisStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.do(ctx, "DEL", s.cfg.Prefix+key)
+	return err
+}
+
+// List returns all keys matching the given prefix
+func (s *RedisStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := s.do(ctx, "SCAN", cursor, "MATCH", escapeGlob(s.cfg.Prefix+prefix)+"*", "COUNT", "500")
+		if err != nil {
+			return nil, err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return nil, fmt.Errorf("redis: unexpected SCAN reply")
+		}
+		next, _ := parts[0].([]byte)
+		batch, _ := parts[1].([]interface{})
+		for _, k := range batch {
+			if b, ok := k.([]byte); ok {
+				keys = append(keys, strings.TrimPrefix(string(b), s.cfg.Prefix))
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return keys, nil
+		}
+	}
+}
+
+// Usage returns the number of stored entries and their total size.
+func (s *RedisStorage) Usage(ctx context.Context) (int, int64, error) {
+	keys, err := s.List(ctx, "")
+	if err != nil {
+		return 0, 0, err
+	}
+	var size int64
+	for _, key := range keys {
+		reply, err := s.do(ctx, "STRLEN", s.cfg.Prefix+key)
+		if err != nil {
+			return 0, 0, err
+		}
+		n, _ := reply.(int64)
+		size += n
+	}
+	return len(keys), size, nil
+}
+
+// Close closes the connection.
+func (s *RedisStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.reader = nil, nil
+	return err
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// do sends one command and reads its reply: nil, int64, string, []byte or
+// []interface{}. Any error other than an error reply drops the connection
+// so the next command reconnects.
+func (s *RedisStorage) do(ctx context.Context, args ...string) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetDeadline(deadline)
+	} else {
+		s.conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+
+	reply, err := s.roundTrip(args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		s.conn.Close()
+		s.conn, s.reader = nil, nil
+	}
+	return reply, err
+}
+
+// connect dials the server, authenticates and selects the database.
+func (s *RedisStorage) connect(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("redis: connecting to %s: %w", s.cfg.Addr, err)
+	}
+	s.conn, s.reader = conn, bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	var setup [][]string
+	if s.cfg.Password != "" {
+		if s.cfg.Username != "" {
+			setup = append(setup, []string{"AUTH", s.cfg.Username, s.cfg.Password})
+		} else {
+			setup = append(setup, []string{"AUTH", s.cfg.Password})
+		}
+	}
+	if s.cfg.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(s.cfg.DB)})
+	}
+	for _, cmd := range setup {
+		if _, err := s.roundTrip(cmd); err != nil {
+			conn.Close()
+			s.conn, s.reader = nil, nil
+			return fmt.Errorf("redis: %s: %w", cmd[0], err)
+		}
+	}
+	return nil
+}
+
+// roundTrip writes a command as a RESP array of bulk strings and reads the
+// reply.
+func (s *RedisStorage) roundTrip(args []string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := s.conn.Write([]byte(b.String())); err != nil {
+		return nil, fmt.Errorf("redis: writing command: %w", err)
+	}
+	return readRESP(s.reader)
+}
+
+// readRESP reads one RESP reply.
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: reading reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid integer reply %q", line)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("redis: reading bulk reply: %w", err)
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readRESP(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as a pattern.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
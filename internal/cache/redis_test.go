@@ -0,0 +1,205 @@
+// internal/cache/redis_test.go
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves the handful of commands RedisStorage sends from an
+// in-memory map.
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	ttls     map[string]string
+	password string
+	commands []string
+}
+
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeRedis{data: map[string]string{}, ttls: map[string]string{}, password: password}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		reply, err := readRESP(r)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, it := range items {
+			args[i] = string(it.([]byte))
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, args[0])
+		var out string
+		switch {
+		case args[0] == "AUTH":
+			authed = args[len(args)-1] == f.password
+			out = "+OK\r\n"
+			if !authed {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required\r\n"
+		case args[0] == "SELECT":
+			out = "+OK\r\n"
+		case args[0] == "GET":
+			v, ok := f.data[args[1]]
+			if !ok {
+				out = "$-1\r\n"
+			} else {
+				out = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			}
+		case args[0] == "SET":
+			f.data[args[1]] = args[2]
+			if len(args) == 5 {
+				f.ttls[args[1]] = args[4]
+			}
+			out = "+OK\r\n"
+		case args[0] == "DEL":
+			delete(f.data, args[1])
+			out = ":1\r\n"
+		case args[0] == "STRLEN":
+			out = fmt.Sprintf(":%d\r\n", len(f.data[args[1]]))
+		case args[0] == "SCAN":
+			pattern := strings.ReplaceAll(args[3], `\`, "")
+			var keys []string
+			for k := range f.data {
+				if ok, _ := path.Match(pattern, k); ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			var b strings.Builder
+			fmt.Fprintf(&b, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+			for _, k := range keys {
+				fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(k), k)
+			}
+			out = b.String()
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+		conn.Write([]byte(out))
+	}
+}
+
+func TestRedisStorage_PutGetDelete(t *testing.T) {
+	fake, addr := startFakeRedis(t, "")
+	s, err := NewRedisStorage(RedisConfig{Addr: addr, Prefix: "gavel:", TTL: 2 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "abc"); err != ErrCacheMiss {
+		t.Fatalf("Get() before Put error = %v, want ErrCacheMiss", err)
+	}
+	data := "{\"a\":\"line\\r\\n\"}\r\n"
+	if err := s.Put(ctx, "abc", []byte(data)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if fake.ttls["gavel:abc"] != "7200000" {
+		t.Errorf("expected a 2h expiry in ms, got %q", fake.ttls["gavel:abc"])
+	}
+	got, err := s.Get(ctx, "abc")
+	if err != nil || string(got) != data {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if err := s.Delete(ctx, "abc"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ctx, "abc"); err != ErrCacheMiss {
+		t.Errorf("Get() after Delete error = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestRedisStorage_ListAndUsage(t *testing.T) {
+	fake, addr := startFakeRedis(t, "")
+	fake.data["other:key"] = "x"
+	s, _ := NewRedisStorage(RedisConfig{Addr: addr, Prefix: "gavel:"})
+	defer s.Close()
+	ctx := context.Background()
+	s.Put(ctx, "aa1", []byte("12345"))
+	s.Put(ctx, "aa2", []byte("123"))
+	s.Put(ctx, "bb1", []byte("1"))
+
+	keys, err := s.List(ctx, "aa")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if strings.Join(keys, ",") != "aa1,aa2" {
+		t.Errorf("List() = %v, want [aa1 aa2]", keys)
+	}
+	entries, size, err := s.Usage(ctx)
+	if err != nil || entries != 3 || size != 9 {
+		t.Errorf("Usage() = %d, %d, %v; want 3, 9", entries, size, err)
+	}
+}
+
+func TestRedisStorage_Auth(t *testing.T) {
+	fake, addr := startFakeRedis(t, "pw")
+	ctx := context.Background()
+
+	s, _ := NewRedisStorage(RedisConfig{Addr: addr, Password: "pw", DB: 2})
+	defer s.Close()
+	if err := s.Put(ctx, "k", []byte("v")); err != nil {
+		t.Fatalf("Put() with password error = %v", err)
+	}
+	if strings.Join(fake.commands[:2], ",") != "AUTH,SELECT" {
+		t.Errorf("expected AUTH then SELECT on connect, got %v", fake.commands)
+	}
+
+	bad, _ := NewRedisStorage(RedisConfig{Addr: addr, Password: "wrong"})
+	defer bad.Close()
+	if _, err := bad.Get(ctx, "k"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("expected an auth error, got %v", err)
+	}
+}
+
+func TestParseRedisURL(t *testing.T) {
+	cfg, err := ParseRedisURL("redis://user:pw@cache.internal/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != "cache.internal:6379" || cfg.Username != "user" || cfg.Password != "pw" || cfg.DB != 3 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if _, err := ParseRedisURL("http://host"); err == nil {
+		t.Error("expected an error for a non-redis scheme")
+	}
+	if _, err := ParseRedisURL("redis://host/x"); err == nil {
+		t.Error("expected an error for an invalid database")
+	}
+}
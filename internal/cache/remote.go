@@ -17,6 +17,7 @@ type RemoteCache struct {
 	baseURL    string
 	httpClient *http.Client
 	token      string
+	apiKey     string
 }
 
 // RemoteCacheOption configures a RemoteCache
@@ -36,6 +37,23 @@ func WithToken(token string) RemoteCacheOption {
 	}
 }
 
+// WithAPIKey sets a key sent in the X-API-Key header, for servers whose
+// auth type is api_key
+func WithAPIKey(key string) RemoteCacheOption {
+	return func(c *RemoteCache) {
+		c.apiKey = key
+	}
+}
+
+// WithAuth sends token the way a server with the given auth type expects
+// it: in the X-API-Key header for "api_key", else as a bearer token
+func WithAuth(authType, token string) RemoteCacheOption {
+	if authType == "api_key" {
+		return WithAPIKey(token)
+	}
+	return WithToken(token)
+}
+
 // WithTimeout sets the HTTP client timeout
 func WithTimeout(timeout time.Duration) RemoteCacheOption {
 	return func(c *RemoteCache) {
@@ -209,11 +227,14 @@ func (c *RemoteCache) Ping(ctx context.Context) error {
 	return nil
 }
 
-// setAuthHeader adds the authentication header if a token is configured
+// setAuthHeader adds the authentication header if a token or key is configured
 func (c *RemoteCache) setAuthHeader(req *http.Request) {
 	if c.token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
 }
 
 // RemoteCacheStats holds statistics from the remote cache server
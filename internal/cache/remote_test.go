@@ -152,6 +152,24 @@ func TestRemoteCache_WithToken(t *testing.T) {
 	cache.Get(ctx, CacheKey{FileHash: "any"})
 }
 
+func TestRemoteCache_WithAuthAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("X-API-Key"); key != "test-key" {
+			t.Errorf("X-API-Key = %s, want test-key", key)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization = %s, want none", auth)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cache := NewRemoteCache(server.URL, WithAuth("api_key", "test-key"))
+	ctx := context.Background()
+
+	cache.Get(ctx, CacheKey{FileHash: "any"})
+}
+
 func TestRemoteCache_Ping(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/health" {
@@ -110,3 +110,31 @@ func (s *LocalStorage) List(ctx context.Context, prefix string) ([]string, error
 func (s *LocalStorage) Dir() string {
 	return s.dir
 }
+
+// Usage returns the number of stored entries and their total size.
+func (s *LocalStorage) Usage(ctx context.Context) (int, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	entries, size := 0, int64(0)
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries++
+		size += info.Size()
+		return nil
+	})
+	if err != nil && os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	return entries, size, err
+}
@@ -175,3 +175,22 @@ func TestLocalStorage_CreatesDirectory(t *testing.T) {
 		t.Error("Put() did not create directory")
 	}
 }
+
+func TestLocalStorage_Usage(t *testing.T) {
+	storage := NewLocalStorage(filepath.Join(t.TempDir(), "missing"))
+	ctx := context.Background()
+
+	if entries, size, err := storage.Usage(ctx); err != nil || entries != 0 || size != 0 {
+		t.Fatalf("Usage() on a missing directory = %d, %d, %v; want 0, 0, nil", entries, size, err)
+	}
+	storage.Put(ctx, "a", []byte("12345"))
+	storage.Put(ctx, "b", []byte("123"))
+
+	entries, size, err := storage.Usage(ctx)
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	if entries != 2 || size != 8 {
+		t.Errorf("Usage() = %d entries, %d bytes; want 2, 8", entries, size)
+	}
+}
@@ -0,0 +1,67 @@
+package cacheserver
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// promMetrics records cache server usage for Prometheus. Its methods are
+// no-ops on a nil receiver, so handlers can record unconditionally.
+type promMetrics struct {
+	registry   *prometheus.Registry
+	requests   *prometheus.CounterVec
+	bytesIO    *prometheus.CounterVec
+	sweptTotal prometheus.Counter
+}
+
+func newPromMetrics() *promMetrics {
+	m := &promMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gavel_cache_server_requests_total",
+			Help: "Cache server requests, by operation, result and client.",
+		}, []string{"op", "result", "client"}),
+		bytesIO: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gavel_cache_server_bytes_total",
+			Help: "Entry bytes received (in) and served (out), by client.",
+		}, []string{"direction", "client"}),
+		sweptTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gavel_cache_server_swept_total",
+			Help: "Expired entries removed by the periodic sweep.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.requests, m.bytesIO, m.sweptTotal,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+func (m *promMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *promMetrics) request(op, result, client string) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(op, result, client).Inc()
+}
+
+func (m *promMetrics) bytes(direction, client string, n int) {
+	if m == nil {
+		return
+	}
+	m.bytesIO.WithLabelValues(direction, client).Add(float64(n))
+}
+
+func (m *promMetrics) swept(n int) {
+	if m == nil {
+		return
+	}
+	m.sweptTotal.Add(float64(n))
+}
@@ -0,0 +1,381 @@
+// Package cacheserver implements the HTTP API that cache.RemoteCache talks
+// to, so a team can share analysis results through `gavel cache serve`.
+//
+// Entries are stored under their CacheKey hash in a cache.Storage backend
+// (a directory, an S3 bucket, or Redis). Each entry is stamped with the
+// time the server stored it and treated as missing once it is older than
+// the configured TTL.
+package cacheserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+)
+
+// DefaultMaxEntryBytes bounds the size of an uploaded entry.
+const DefaultMaxEntryBytes = 10 << 20
+
+// keyPattern matches a CacheKey hash: hex-encoded SHA-256.
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// UsageReporter is implemented by storage backends that can report how
+// many entries they hold and their total size.
+type UsageReporter interface {
+	Usage(ctx context.Context) (entries int, sizeBytes int64, err error)
+}
+
+// Auth configures how clients authenticate, matching the client's
+// RemoteCacheAuth settings.
+type Auth struct {
+	// Type is "bearer" (Authorization: Bearer <token>), "api_key"
+	// (X-API-Key: <token>), or empty for no authentication.
+	Type string
+	// Keys maps each accepted token to a client name, which labels the
+	// usage metrics.
+	Keys map[string]string
+}
+
+// Config holds the server's settings.
+type Config struct {
+	Storage cache.Storage
+	// TTL is how long entries stay valid after they are stored. Zero keeps
+	// them until they are deleted.
+	TTL  time.Duration
+	Auth Auth
+	// MaxEntryBytes bounds the size of an uploaded entry
+	// (default DefaultMaxEntryBytes).
+	MaxEntryBytes int64
+	// Metrics serves Prometheus usage metrics at /metrics.
+	Metrics bool
+}
+
+// Server serves the cache API.
+type Server struct {
+	storage  cache.Storage
+	ttl      time.Duration
+	auth     Auth
+	maxBytes int64
+	router   *chi.Mux
+	prom     *promMetrics
+	now      func() time.Time
+
+	hits    atomic.Int64
+	misses  atomic.Int64
+	expired atomic.Int64
+}
+
+// New creates a Server from cfg.
+func New(cfg Config) (*Server, error) {
+	if cfg.Storage == nil {
+		return nil, fmt.Errorf("cache server: storage is required")
+	}
+	switch cfg.Auth.Type {
+	case "":
+	case "bearer", "api_key":
+		if len(cfg.Auth.Keys) == 0 {
+			return nil, fmt.Errorf("cache server: auth type %q needs at least one token", cfg.Auth.Type)
+		}
+	default:
+		return nil, fmt.Errorf("cache server: unknown auth type %q (want bearer or api_key)", cfg.Auth.Type)
+	}
+
+	s := &Server{
+		storage:  cfg.Storage,
+		ttl:      cfg.TTL,
+		auth:     cfg.Auth,
+		maxBytes: cfg.MaxEntryBytes,
+		now:      time.Now,
+	}
+	if s.maxBytes <= 0 {
+		s.maxBytes = DefaultMaxEntryBytes
+	}
+	if cfg.Metrics {
+		s.prom = newPromMetrics()
+	}
+	s.router = s.routes()
+	return s, nil
+}
+
+func (s *Server) routes() *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(chimiddleware.Recoverer)
+	r.Use(chimiddleware.RealIP)
+
+	// Health and metrics endpoints (no auth)
+	r.Get("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	if s.prom != nil {
+		r.Handle("/metrics", s.prom.handler())
+	}
+
+	r.Group(func(r chi.Router) {
+		if s.auth.Type != "" {
+			r.Use(s.authenticate)
+		}
+		r.Get("/api/cache/stats", s.handleStats)
+		r.Get("/api/cache/{key}", s.handleGet)
+		r.Put("/api/cache/{key}", s.handlePut)
+		r.Delete("/api/cache/{key}", s.handleDelete)
+	})
+	return r
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+type clientKey struct{}
+
+// clientName returns the authenticated client's name, or "anonymous".
+func clientName(ctx context.Context) string {
+	if name, ok := ctx.Value(clientKey{}).(string); ok && name != "" {
+		return name
+	}
+	return "anonymous"
+}
+
+// authenticate checks the request's token according to the auth type.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var token string
+		if s.auth.Type == "api_key" {
+			token = r.Header.Get("X-API-Key")
+		} else if auth := r.Header.Get("Authorization"); len(auth) > len("Bearer ") && auth[:len("Bearer ")] == "Bearer " {
+			token = auth[len("Bearer "):]
+		}
+
+		name, ok := s.auth.Keys[token]
+		if token == "" || !ok {
+			s.prom.request("auth", "unauthorized", "anonymous")
+			writeError(w, http.StatusUnauthorized, "missing or invalid credentials")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, name)))
+	})
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	client := clientName(r.Context())
+	key, ok := s.key(w, r)
+	if !ok {
+		return
+	}
+
+	entry, data, err := s.load(r.Context(), key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		s.misses.Add(1)
+		s.prom.request("get", "miss", client)
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		slog.Error("cache server: reading entry", "key", key, "err", err)
+		s.prom.request("get", "error", client)
+		writeError(w, http.StatusInternalServerError, "reading entry failed")
+		return
+	}
+	if s.isExpired(entry) {
+		if err := s.storage.Delete(r.Context(), key); err != nil {
+			slog.Warn("cache server: deleting expired entry", "key", key, "err", err)
+		}
+		s.misses.Add(1)
+		s.expired.Add(1)
+		s.prom.request("get", "expired", client)
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	s.hits.Add(1)
+	s.prom.request("get", "hit", client)
+	s.prom.bytes("out", client, len(data))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) handlePut(w http.ResponseWriter, r *http.Request) {
+	client := clientName(r.Context())
+	key, ok := s.key(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.prom.request("put", "rejected", client)
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("entry exceeds %d bytes", s.maxBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, "reading body failed")
+		return
+	}
+
+	var entry cache.CacheEntry
+	if err := json.Unmarshal(body, &entry); err != nil {
+		s.prom.request("put", "rejected", client)
+		writeError(w, http.StatusBadRequest, "invalid cache entry: "+err.Error())
+		return
+	}
+	// The key must be the hash of the entry's own cache key, so a client
+	// cannot store results under inputs they were not produced for.
+	if entry.Key.Hash() != key {
+		s.prom.request("put", "rejected", client)
+		writeError(w, http.StatusBadRequest, "key does not match the entry's cache key")
+		return
+	}
+	entry.Timestamp = s.now().Unix()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "encoding entry failed")
+		return
+	}
+	if err := s.storage.Put(r.Context(), key, data); err != nil {
+		slog.Error("cache server: storing entry", "key", key, "err", err)
+		s.prom.request("put", "error", client)
+		writeError(w, http.StatusInternalServerError, "storing entry failed")
+		return
+	}
+
+	s.prom.request("put", "stored", client)
+	s.prom.bytes("in", client, len(body))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	client := clientName(r.Context())
+	key, ok := s.key(w, r)
+	if !ok {
+		return
+	}
+	if err := s.storage.Delete(r.Context(), key); err != nil {
+		slog.Error("cache server: deleting entry", "key", key, "err", err)
+		s.prom.request("delete", "error", client)
+		writeError(w, http.StatusInternalServerError, "deleting entry failed")
+		return
+	}
+	s.prom.request("delete", "deleted", client)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.prom.request("stats", "ok", clientName(r.Context()))
+	stats, err := s.Stats(r.Context())
+	if err != nil {
+		slog.Error("cache server: computing stats", "err", err)
+		writeError(w, http.StatusInternalServerError, "computing stats failed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// Stats reports the stored entries and the hits and misses served since
+// the server started.
+func (s *Server) Stats(ctx context.Context) (*cache.RemoteCacheStats, error) {
+	stats := &cache.RemoteCacheStats{
+		Hits:   s.hits.Load(),
+		Misses: s.misses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+
+	if u, ok := s.storage.(UsageReporter); ok {
+		entries, size, err := u.Usage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stats.Entries, stats.SizeBytes = int64(entries), size
+		return stats, nil
+	}
+	keys, err := s.storage.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	stats.Entries = int64(len(keys))
+	return stats, nil
+}
+
+// Sweep deletes every expired entry and returns how many it removed. It
+// does nothing when no TTL is set.
+func (s *Server) Sweep(ctx context.Context) (int, error) {
+	if s.ttl <= 0 {
+		return 0, nil
+	}
+	keys, err := s.storage.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, key := range keys {
+		entry, _, err := s.load(ctx, key)
+		if errors.Is(err, cache.ErrCacheMiss) {
+			continue
+		}
+		// Unreadable entries can never be served, so they go too
+		if err != nil || s.isExpired(entry) {
+			if err := s.storage.Delete(ctx, key); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	s.expired.Add(int64(removed))
+	s.prom.swept(removed)
+	return removed, nil
+}
+
+// load reads and decodes the entry stored under key.
+func (s *Server) load(ctx context.Context, key string) (*cache.CacheEntry, []byte, error) {
+	data, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	var entry cache.CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil, fmt.Errorf("decoding entry %s: %w", key, err)
+	}
+	return &entry, data, nil
+}
+
+func (s *Server) isExpired(entry *cache.CacheEntry) bool {
+	if s.ttl <= 0 {
+		return false
+	}
+	return s.now().Sub(time.Unix(entry.Timestamp, 0)) > s.ttl
+}
+
+// key returns the validated {key} URL parameter, writing a 400 response if
+// it is not a cache key hash.
+func (s *Server) key(w http.ResponseWriter, r *http.Request) (string, bool) {
+	key := chi.URLParam(r, "key")
+	if !keyPattern.MatchString(key) {
+		writeError(w, http.StatusBadRequest, "invalid cache key")
+		return "", false
+	}
+	return key, true
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
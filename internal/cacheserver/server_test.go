@@ -0,0 +1,207 @@
+package cacheserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func newTestServer(t *testing.T, cfg Config) (*Server, *httptest.Server) {
+	t.Helper()
+	if cfg.Storage == nil {
+		cfg.Storage = cache.NewLocalStorage(t.TempDir())
+	}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func testEntry(file string) *cache.CacheEntry {
+	return &cache.CacheEntry{
+		Key:     cache.CacheKey{FileHash: "hash-" + file, FilePath: file, Provider: "ollama", Model: "m"},
+		Results: []sarif.Result{{RuleID: "R1", Level: "warning", Message: sarif.Message{Text: "finding"}}},
+	}
+}
+
+func TestServer_RoundTrip(t *testing.T) {
+	_, ts := newTestServer(t, Config{})
+	client := cache.NewRemoteCache(ts.URL)
+	ctx := context.Background()
+
+	entry := testEntry("a.go")
+	if _, err := client.Get(ctx, entry.Key); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Fatalf("expected a miss before Put, got %v", err)
+	}
+	if err := client.Put(ctx, entry); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := client.Get(ctx, entry.Key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(got.Results) != 1 || got.Results[0].RuleID != "R1" || got.Timestamp == 0 {
+		t.Errorf("unexpected entry %+v", got)
+	}
+
+	stats, err := client.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Entries != 1 || stats.Hits != 1 || stats.Misses != 1 || stats.HitRate != 0.5 || stats.SizeBytes == 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if err := client.Delete(ctx, entry.Key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := client.Get(ctx, entry.Key); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected a miss after Delete, got %v", err)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	keys := map[string]string{"secret": "ci"}
+	tests := []struct {
+		authType string
+		good     cache.RemoteCacheOption
+		bad      cache.RemoteCacheOption
+	}{
+		{"bearer", cache.WithToken("secret"), cache.WithAPIKey("secret")},
+		{"api_key", cache.WithAPIKey("secret"), cache.WithToken("secret")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.authType, func(t *testing.T) {
+			_, ts := newTestServer(t, Config{Auth: Auth{Type: tt.authType, Keys: keys}})
+			ctx := context.Background()
+			entry := testEntry("a.go")
+
+			if err := cache.NewRemoteCache(ts.URL, tt.good).Put(ctx, entry); err != nil {
+				t.Errorf("Put with valid credentials: %v", err)
+			}
+			if err := cache.NewRemoteCache(ts.URL, tt.bad).Put(ctx, entry); err == nil || !strings.Contains(err.Error(), "401") {
+				t.Errorf("expected 401 for the wrong auth scheme, got %v", err)
+			}
+			if err := cache.NewRemoteCache(ts.URL, cache.WithAuth(tt.authType, "wrong")).Put(ctx, entry); err == nil {
+				t.Error("expected an invalid token to be rejected")
+			}
+			// Health checks need no credentials
+			if err := cache.NewRemoteCache(ts.URL).Ping(ctx); err != nil {
+				t.Errorf("Ping without credentials: %v", err)
+			}
+		})
+	}
+
+	if _, err := New(Config{Storage: cache.NewLocalStorage(t.TempDir()), Auth: Auth{Type: "bearer"}}); err == nil {
+		t.Error("expected bearer auth without tokens to be rejected")
+	}
+	if _, err := New(Config{Storage: cache.NewLocalStorage(t.TempDir()), Auth: Auth{Type: "oauth", Keys: keys}}); err == nil {
+		t.Error("expected an unknown auth type to be rejected")
+	}
+}
+
+func TestServer_TTL(t *testing.T) {
+	s, ts := newTestServer(t, Config{TTL: time.Hour})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	client := cache.NewRemoteCache(ts.URL)
+	ctx := context.Background()
+	older, newer := testEntry("old.go"), testEntry("new.go")
+	if err := client.Put(ctx, older); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(50 * time.Minute)
+	if err := client.Put(ctx, newer); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(20 * time.Minute)
+
+	if _, err := client.Get(ctx, older.Key); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("expected the expired entry to miss, got %v", err)
+	}
+	if _, err := client.Get(ctx, newer.Key); err != nil {
+		t.Errorf("expected the fresh entry to hit, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	removed, err := s.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected the sweep to remove the remaining entry, removed %d", removed)
+	}
+	stats, _ := s.Stats(ctx)
+	if stats.Entries != 0 {
+		t.Errorf("expected no entries left, got %d", stats.Entries)
+	}
+}
+
+func TestServer_RejectsBadPuts(t *testing.T) {
+	_, ts := newTestServer(t, Config{MaxEntryBytes: 512})
+
+	put := func(key, body string) int {
+		req, _ := http.NewRequest(http.MethodPut, ts.URL+"/api/cache/"+key, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	entry := testEntry("a.go")
+	if code := put("../../etc/passwd", `{}`); code != http.StatusBadRequest && code != http.StatusNotFound {
+		t.Errorf("expected a path outside the key space to be rejected, got %d", code)
+	}
+	if code := put(strings.Repeat("a", 64), `{"key":{"file_hash":"x"}}`); code != http.StatusBadRequest {
+		t.Errorf("expected a key that does not match the entry to be rejected, got %d", code)
+	}
+	if code := put(entry.Key.Hash(), `not json`); code != http.StatusBadRequest {
+		t.Errorf("expected invalid JSON to be rejected, got %d", code)
+	}
+	if code := put(entry.Key.Hash(), `{"key":{}, "pad":"`+strings.Repeat("x", 1024)+`"}`); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected an oversized entry to be rejected, got %d", code)
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	_, ts := newTestServer(t, Config{Metrics: true, Auth: Auth{Type: "bearer", Keys: map[string]string{"tok": "ci"}}})
+	client := cache.NewRemoteCache(ts.URL, cache.WithToken("tok"))
+	ctx := context.Background()
+	entry := testEntry("a.go")
+	client.Put(ctx, entry)
+	client.Get(ctx, entry.Key)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`gavel_cache_server_requests_total{client="ci",op="get",result="hit"} 1`,
+		`gavel_cache_server_requests_total{client="ci",op="put",result="stored"} 1`,
+		`gavel_cache_server_bytes_total{client="ci",direction="in"}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+// Package codeowners reads CODEOWNERS files and attributes findings to the
+// teams and people that own their files, so results can be grouped, filtered
+// and routed to the right reviewers.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Prop is the SARIF property listing the owners of a finding's file.
+const Prop = "gavel/owners"
+
+// Unowned groups findings whose files no CODEOWNERS rule covers.
+const Unowned = "(unowned)"
+
+// Locations are the paths, relative to the repository root, searched for a
+// CODEOWNERS file in order. GitHub reads the first three, GitLab reads
+// CODEOWNERS, docs/ and .gitlab/.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// Rule is one pattern line of a CODEOWNERS file.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	// Section is the GitLab section the rule belongs to, empty outside one.
+	Section string
+	Line    int
+
+	re *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	// Path is where the file was read from, empty when parsed from a reader.
+	Path  string
+	Rules []Rule
+}
+
+// sectionHeader matches a GitLab section heading such as "[Backend]",
+// "^[Docs][2]" or "[Backend] @acme/backend", capturing the name and the
+// default owners.
+var sectionHeader = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// Parse reads a CODEOWNERS file. Blank lines and comments are skipped, GitLab
+// sections are recorded on their rules and a section's default owners apply
+// to its rules that list none. A rule with no owners marks its files as
+// unowned.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	var section string
+	var sectionOwners []string
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := sectionHeader.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			sectionOwners = fields(m[2])
+			continue
+		}
+
+		parts := fields(line)
+		pattern := parts[0]
+		if strings.HasPrefix(pattern, "!") {
+			return nil, fmt.Errorf("line %d: negated pattern %q is not supported", n, pattern)
+		}
+		owners := parts[1:]
+		if len(owners) == 0 {
+			owners = sectionOwners
+		}
+		f.Rules = append(f.Rules, Rule{Pattern: pattern, Owners: owners, Section: section, Line: n, re: compile(pattern)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// fields splits a line on whitespace, dropping a trailing "# comment".
+func fields(s string) []string {
+	parts := strings.Fields(s)
+	for i, p := range parts {
+		if strings.HasPrefix(p, "#") {
+			return parts[:i]
+		}
+	}
+	return parts
+}
+
+// compile translates a gitignore-style CODEOWNERS pattern into a regular
+// expression over slash-separated paths relative to the repository root.
+// Patterns containing a slash other than a trailing one are anchored at the
+// root; others match at any depth. A pattern matching a directory matches
+// everything beneath it, except that "dir/*" covers only dir's direct
+// children, as GitHub documents.
+func compile(pattern string) *regexp.Regexp {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var re strings.Builder
+	re.WriteString("^")
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case p[i] == '*':
+			re.WriteString("[^/]*")
+		case p[i] == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		re.WriteString("/.*")
+	case !strings.HasSuffix(p, "/*"):
+		re.WriteString("(?:/.*)?")
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
+
+// Owners returns the owners of path, given relative to the repository root.
+// As on GitHub the last matching rule wins; with GitLab sections the last
+// match in each section counts and their owners are combined, without
+// duplicates. Nil means no rule covers path or the winning rules list no
+// owners.
+func (f *File) Owners(p string) []string {
+	p = strings.TrimPrefix(path.Clean(filepath.ToSlash(p)), "./")
+	matched := make(map[string]*Rule)
+	var sections []string
+	for i := range f.Rules {
+		r := &f.Rules[i]
+		if !r.re.MatchString(p) {
+			continue
+		}
+		if _, ok := matched[r.Section]; !ok {
+			sections = append(sections, r.Section)
+		}
+		matched[r.Section] = r
+	}
+
+	var owners []string
+	seen := make(map[string]bool)
+	for _, s := range sections {
+		for _, o := range matched[s].Owners {
+			if !seen[strings.ToLower(o)] {
+				seen[strings.ToLower(o)] = true
+				owners = append(owners, o)
+			}
+		}
+	}
+	return owners
+}
+
+// Find looks for a CODEOWNERS file in the repository containing dir: the
+// nearest ancestor of dir holding a .git entry, or dir itself outside a
+// repository. It returns the parsed file and the root its patterns are
+// relative to, or a nil File when the repository has none.
+func Find(dir string) (*File, string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	root := abs
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			root = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	for _, loc := range Locations {
+		p := filepath.Join(root, filepath.FromSlash(loc))
+		fh, err := os.Open(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, root, err
+		}
+		f, err := Parse(fh)
+		fh.Close()
+		if err != nil {
+			return nil, root, fmt.Errorf("%s: %w", p, err)
+		}
+		f.Path = p
+		return f, root, nil
+	}
+	return nil, root, nil
+}
+
+// Enrich sets gavel/owners on every result whose file f assigns owners.
+// Artifact URIs are resolved against the working directory and matched
+// relative to root; files outside root are left alone.
+func Enrich(log *sarif.Log, f *File, root string) {
+	if f == nil {
+		return
+	}
+	cache := make(map[string][]string)
+	for ri := range log.Runs {
+		results := log.Runs[ri].Results
+		for i := range results {
+			if len(results[i].Locations) == 0 {
+				continue
+			}
+			uri := results[i].Locations[0].PhysicalLocation.ArtifactLocation.URI
+			if uri == "" {
+				continue
+			}
+			owners, ok := cache[uri]
+			if !ok {
+				if rel, ok := relative(root, uri); ok {
+					owners = f.Owners(rel)
+				}
+				cache[uri] = owners
+			}
+			if len(owners) == 0 {
+				continue
+			}
+			if results[i].Properties == nil {
+				results[i].Properties = make(map[string]interface{})
+			}
+			results[i].Properties[Prop] = owners
+		}
+	}
+}
+
+// relative converts an artifact URI to a slash-separated path under root.
+func relative(root, uri string) (string, bool) {
+	p, err := filepath.Abs(filepath.FromSlash(strings.TrimPrefix(uri, "file://")))
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Of returns the owners recorded on r. It accepts the []string Enrich sets
+// and the []interface{} a stored log decodes to.
+func Of(r sarif.Result) []string {
+	switch v := r.Properties[Prop].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		owners := make([]string, 0, len(v))
+		for _, o := range v {
+			if s, ok := o.(string); ok {
+				owners = append(owners, s)
+			}
+		}
+		return owners
+	}
+	return nil
+}
+
+// Filter returns the results owned by owner, in their original order.
+// Owners compare case-insensitively and the leading "@" is optional, so
+// "acme/backend" selects findings owned by "@acme/backend". Unowned selects
+// the findings without owners.
+func Filter(results []sarif.Result, owner string) []sarif.Result {
+	want := strings.ToLower(strings.TrimPrefix(owner, "@"))
+	kept := []sarif.Result{}
+	for _, r := range results {
+		owners := Of(r)
+		if owner == Unowned && len(owners) == 0 {
+			kept = append(kept, r)
+			continue
+		}
+		for _, o := range owners {
+			if strings.ToLower(strings.TrimPrefix(o, "@")) == want {
+				kept = append(kept, r)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// Group is the set of findings owned by one owner.
+type Group struct {
+	Owner    string `json:"owner"`
+	Findings int    `json:"findings"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Notes    int    `json:"notes"`
+}
+
+// GroupResults counts results per owner, most findings first. A finding with
+// several owners counts toward each; findings without owners are grouped
+// under Unowned. It returns nil when no result has owners, so callers can
+// leave the grouping out for repositories without CODEOWNERS.
+func GroupResults(results []sarif.Result) []Group {
+	byOwner := make(map[string]*Group)
+	var order []string
+	owned := false
+	for _, r := range results {
+		owners := Of(r)
+		if len(owners) > 0 {
+			owned = true
+		} else {
+			owners = []string{Unowned}
+		}
+		for _, o := range owners {
+			g, ok := byOwner[o]
+			if !ok {
+				g = &Group{Owner: o}
+				byOwner[o] = g
+				order = append(order, o)
+			}
+			g.Findings++
+			switch r.Level {
+			case "error":
+				g.Errors++
+			case "warning":
+				g.Warnings++
+			case "note":
+				g.Notes++
+			}
+		}
+	}
+	if !owned {
+		return nil
+	}
+
+	groups := make([]Group, 0, len(order))
+	for _, o := range order {
+		groups = append(groups, *byOwner[o])
+	}
+	// Unowned goes last, whatever its count
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Owner == Unowned) != (b.Owner == Unowned) {
+			return b.Owner == Unowned
+		}
+		if a.Findings != b.Findings {
+			return a.Findings > b.Findings
+		}
+		return a.Owner < b.Owner
+	})
+	return groups
+}
@@ -0,0 +1,169 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+const githubFile = `# Default owners
+*       @acme/everyone
+
+*.go    @acme/backend   # Go code
+/build/ @acme/release
+docs/*  docs@example.com
+apps/   @octocat
+**/logs @acme/ops
+/scripts/generated.sh
+`
+
+func mustParse(t *testing.T, s string) *File {
+	t.Helper()
+	f, err := Parse(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestOwners(t *testing.T) {
+	f := mustParse(t, githubFile)
+	for path, want := range map[string][]string{
+		"README.md":               {"@acme/everyone"},
+		"internal/server/main.go": {"@acme/backend"},
+		"build/ci/release.yaml":   {"@acme/release"},
+		"src/build/notes.txt":     {"@acme/everyone"}, // /build/ is anchored
+		"docs/getting-started.md": {"docs@example.com"},
+		"docs/api/index.md":       {"@acme/everyone"}, // docs/* covers direct children only
+		"apps/web/page.tsx":       {"@octocat"},
+		"deep/apps/x.txt":         {"@octocat"},
+		"svc/logs/today.txt":      {"@acme/ops"},
+		"./scripts/generated.sh":  nil, // a rule without owners leaves the file unowned
+	} {
+		if got := f.Owners(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("Owners(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestOwners_GitLabSections(t *testing.T) {
+	f := mustParse(t, `
+*.go @dev
+
+[Backend] @acme/backend
+internal/
+internal/legacy/ @acme/legacy
+
+^[Security][2]
+internal/auth/ @acme/security @dev
+`)
+	if f.Rules[1].Section != "Backend" || !reflect.DeepEqual(f.Rules[1].Owners, []string{"@acme/backend"}) {
+		t.Errorf("expected the section's default owners on its rules, got %+v", f.Rules[1])
+	}
+	for path, want := range map[string][]string{
+		"cmd/main.go":               {"@dev"},
+		"internal/store/store.go":   {"@dev", "@acme/backend"},
+		"internal/legacy/old.go":    {"@dev", "@acme/legacy"},
+		"internal/auth/session.go":  {"@dev", "@acme/backend", "@acme/security"},
+		"internal/auth/README.md":   {"@acme/backend", "@acme/security", "@dev"},
+		"unrelated/readme/file.txt": nil,
+	} {
+		if got := f.Owners(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("Owners(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestParse_RejectsNegation(t *testing.T) {
+	if _, err := Parse(strings.NewReader("*.go @a\n!vendor/ @b\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a line 2 error, got %v", err)
+	}
+}
+
+func result(uri, level string) sarif.Result {
+	return sarif.Result{
+		RuleID: "r", Level: level, Message: sarif.Message{Text: "m"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region:           sarif.Region{StartLine: 1},
+		}}},
+	}
+}
+
+func TestFindAndEnrich(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".git"), 0o755)
+	os.MkdirAll(filepath.Join(root, ".github"), 0o755)
+	os.MkdirAll(filepath.Join(root, "svc", "api"), 0o755)
+	if err := os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte("/svc/ @acme/backend\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, gotRoot, err := Find(filepath.Join(root, "svc", "api"))
+	if err != nil || f == nil {
+		t.Fatalf("Find = %v, %v", f, err)
+	}
+	if gotRoot != root || f.Path != filepath.Join(root, ".github", "CODEOWNERS") {
+		t.Errorf("Find root %q, path %q", gotRoot, f.Path)
+	}
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		result(filepath.Join(root, "svc", "api", "main.go"), "error"),
+		result(filepath.Join(root, "main.go"), "warning"),
+		result("/elsewhere/svc/x.go", "note"),
+	}
+	Enrich(log, f, root)
+
+	got := log.Runs[0].Results
+	if !reflect.DeepEqual(Of(got[0]), []string{"@acme/backend"}) {
+		t.Errorf("expected svc/api/main.go owned by @acme/backend, got %v", got[0].Properties)
+	}
+	if _, ok := got[1].Properties[Prop]; ok {
+		t.Errorf("expected main.go unowned, got %v", got[1].Properties)
+	}
+	if _, ok := got[2].Properties[Prop]; ok {
+		t.Errorf("expected a file outside the root unowned, got %v", got[2].Properties)
+	}
+
+	if none, _, err := Find(t.TempDir()); none != nil || err != nil {
+		t.Errorf("expected no CODEOWNERS, got %v, %v", none, err)
+	}
+}
+
+func TestFilterAndGroup(t *testing.T) {
+	owned := func(level string, owners ...interface{}) sarif.Result {
+		r := result("a.go", level)
+		r.Properties = map[string]interface{}{Prop: owners} // as decoded from a stored log
+		return r
+	}
+	results := []sarif.Result{
+		owned("error", "@acme/backend", "@dev"),
+		owned("warning", "@acme/backend"),
+		owned("note", "@dev"),
+		result("b.go", "warning"),
+	}
+
+	if got := Filter(results, "acme/BACKEND"); len(got) != 2 {
+		t.Errorf("Filter(acme/BACKEND) kept %d findings, want 2", len(got))
+	}
+	if got := Filter(results, Unowned); len(got) != 1 || got[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "b.go" {
+		t.Errorf("Filter(Unowned) = %+v", got)
+	}
+
+	want := []Group{
+		{Owner: "@acme/backend", Findings: 2, Errors: 1, Warnings: 1},
+		{Owner: "@dev", Findings: 2, Errors: 1, Notes: 1},
+		{Owner: Unowned, Findings: 1, Warnings: 1},
+	}
+	if got := GroupResults(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupResults = %+v, want %+v", got, want)
+	}
+	if got := GroupResults(results[3:]); got != nil {
+		t.Errorf("expected no groups without owners, got %+v", got)
+	}
+}
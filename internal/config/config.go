@@ -3,9 +3,18 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/evaluator/library"
+	"github.com/chris-regnier/gavel/internal/pathglob"
+	"github.com/chris-regnier/gavel/internal/persona"
 )
 
 // Policy defines a single analysis policy.
@@ -26,13 +35,25 @@ type Policy struct {
 	Instruction        string            `yaml:"instruction"`
 	Enabled            bool              `yaml:"enabled"`
 	AdditionalContexts []ContextSelector `yaml:"additional_contexts,omitempty"`
+
+	// Provider and Model route this policy's LLM analysis away from the
+	// top-level provider, e.g. security policies to Anthropic while style
+	// policies stay on a local Ollama model. Provider names one of the
+	// provider sections, whose settings are used; Model replaces that
+	// provider's model. Either may be set alone.
+	Provider string `yaml:"provider,omitempty"`
+	Model    string `yaml:"model,omitempty"`
+
+	// MinConfidence demotes this policy's findings below the given
+	// confidence to informational, overriding confidence.min.
+	MinConfidence float64 `yaml:"min_confidence,omitempty"`
 }
 
 // TelemetryConfig holds OpenTelemetry configuration.
 type TelemetryConfig struct {
 	Enabled        bool              `yaml:"enabled"`
 	Endpoint       string            `yaml:"endpoint"`
-	Protocol       string            `yaml:"protocol"`        // "grpc" or "http"
+	Protocol       string            `yaml:"protocol"` // "grpc" or "http"
 	Insecure       bool              `yaml:"insecure"`
 	ServiceName    string            `yaml:"service_name"`
 	ServiceVersion string            `yaml:"service_version"`
@@ -40,24 +61,355 @@ type TelemetryConfig struct {
 	Headers        map[string]string `yaml:"headers"`
 }
 
+// MetricsConfig controls the Prometheus /metrics endpoint that gavel mcp
+// and gavel lsp serve on a side HTTP port.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr,omitempty"` // host:port to listen on; defaults to DefaultMetricsAddr
+}
+
+// DefaultMetricsAddr is where the metrics endpoint listens when Addr is unset.
+const DefaultMetricsAddr = "127.0.0.1:9464"
+
+// ListenAddr returns Addr, or DefaultMetricsAddr when it is unset.
+func (c MetricsConfig) ListenAddr() string {
+	if c.Addr == "" {
+		return DefaultMetricsAddr
+	}
+	return c.Addr
+}
+
+// ServerConfig configures the HTTP services, gavel serve and gavel mcp --http.
+type ServerConfig struct {
+	Auth ServerAuthConfig `yaml:"auth,omitempty"`
+}
+
+// ServerAuthConfig authenticates callers of the HTTP services and limits
+// what each may use. A request is accepted when any configured method
+// accepts its bearer token.
+type ServerAuthConfig struct {
+	// KeysFile holds static API keys as "key:tenant-id" lines.
+	KeysFile string     `yaml:"keys_file,omitempty"`
+	OIDC     OIDCConfig `yaml:"oidc,omitempty"`
+	// IsolateTenants keeps each tenant's results in its own directory under
+	// the store directory, so tenants cannot read each other's results.
+	IsolateTenants bool `yaml:"isolate_tenants,omitempty"`
+	// Limits apply to every tenant without an entry in Tenants.
+	Limits TenantLimits `yaml:"limits,omitempty"`
+	// Tenants overrides Limits per tenant ID; unset fields fall back to Limits.
+	Tenants map[string]TenantLimits `yaml:"tenants,omitempty"`
+}
+
+// OIDCConfig accepts JWTs issued by an OpenID Connect provider.
+type OIDCConfig struct {
+	// Issuer is the provider's issuer URL; the token's iss claim must equal it.
+	Issuer string `yaml:"issuer,omitempty"`
+	// Audience must appear in the token's aud claim, so tokens the issuer
+	// grants to other clients are refused.
+	Audience string `yaml:"audience,omitempty"`
+	// JWKSURL overrides the signing keys URL read from the issuer's
+	// /.well-known/openid-configuration.
+	JWKSURL string `yaml:"jwks_url,omitempty"`
+	// TenantClaim names the claim holding the tenant ID (default "sub").
+	TenantClaim string `yaml:"tenant_claim,omitempty"`
+}
+
+// TenantClaimName returns TenantClaim, defaulting to "sub".
+func (c OIDCConfig) TenantClaimName() string {
+	if c.TenantClaim == "" {
+		return "sub"
+	}
+	return c.TenantClaim
+}
+
+// TenantLimits caps one tenant's use of the service. Zero means unlimited.
+type TenantLimits struct {
+	// RequestsPerMinute is the sustained request rate allowed per token;
+	// Burst is how many requests a token may make at once (default: the
+	// per-minute rate).
+	RequestsPerMinute int `yaml:"requests_per_minute,omitempty"`
+	Burst             int `yaml:"burst,omitempty"`
+	// DailyTokens and DailyCostUSD cap the LLM tokens and estimated cost,
+	// priced from pricing, a tenant may use per UTC day.
+	DailyTokens  int64   `yaml:"daily_tokens,omitempty"`
+	DailyCostUSD float64 `yaml:"daily_cost_usd,omitempty"`
+}
+
+// LimitsFor returns the limits of tenant: its entry in Tenants with unset
+// fields taken from Limits.
+func (c ServerAuthConfig) LimitsFor(tenant string) TenantLimits {
+	l := c.Limits
+	t, ok := c.Tenants[tenant]
+	if !ok {
+		return l
+	}
+	if t.RequestsPerMinute != 0 {
+		l.RequestsPerMinute = t.RequestsPerMinute
+	}
+	if t.Burst != 0 {
+		l.Burst = t.Burst
+	}
+	if t.DailyTokens != 0 {
+		l.DailyTokens = t.DailyTokens
+	}
+	if t.DailyCostUSD != 0 {
+		l.DailyCostUSD = t.DailyCostUSD
+	}
+	return l
+}
+
+// HasQuotas reports whether any tenant has a daily token or cost quota.
+func (c ServerAuthConfig) HasQuotas() bool {
+	quota := func(l TenantLimits) bool { return l.DailyTokens > 0 || l.DailyCostUSD > 0 }
+	if quota(c.Limits) {
+		return true
+	}
+	for _, l := range c.Tenants {
+		if quota(l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l TenantLimits) validate(field string) error {
+	if l.RequestsPerMinute < 0 || l.Burst < 0 || l.DailyTokens < 0 || l.DailyCostUSD < 0 {
+		return fmt.Errorf("%s: limits must not be negative", field)
+	}
+	return nil
+}
+
 // Config holds the full gavel configuration.
 type Config struct {
-	Provider     ProviderConfig    `yaml:"provider"`
-	Persona      string            `yaml:"persona"`       // AI expert role
-	StrictFilter bool              `yaml:"strict_filter"` // When true, only report findings relevant to the analyzed artifact
-	Policies     map[string]Policy `yaml:"policies"`
-	LSP          LSPConfig         `yaml:"lsp"`
-	RemoteCache  RemoteCacheConfig `yaml:"remote_cache"`
-	Telemetry    TelemetryConfig   `yaml:"telemetry"`
-	Calibration  CalibrationConfig `yaml:"calibration"`
+	Provider        ProviderConfig        `yaml:"provider"`
+	FastProvider    ProviderConfig        `yaml:"fast_provider,omitempty"`    // Optional provider for the fast tier, typically a small local model
+	Persona         string                `yaml:"persona"`                    // AI expert role
+	StrictFilter    bool                  `yaml:"strict_filter"`              // When true, only report findings relevant to the analyzed artifact
+	DedupWindow     int                   `yaml:"dedup_window"`               // Collapse same-rule findings in a file whose ranges are within this many lines
+	Bundles         []BundleConfig        `yaml:"bundles,omitempty"`          // Policy bundles gavel policy pull installs, pinned by digest
+	Confidence      ConfidenceConfig      `yaml:"confidence,omitempty"`       // Minimum confidence for findings to count towards the verdict
+	Dedup           string                `yaml:"dedup,omitempty"`            // "strict" (default), "fuzzy" to also collapse similar findings of different rules, or "off"
+	DedupSimilarity float64               `yaml:"dedup_similarity,omitempty"` // Minimum message similarity (0-1) for fuzzy dedup; defaults to 0.5
+	PathOverrides   []PathOverride        `yaml:"path_overrides,omitempty"`   // Disable rule categories or rule IDs under matching paths
+	ParseErrors     ParseErrorConfig      `yaml:"parse_errors"`               // How AST rules handle files tree-sitter cannot parse
+	Gate            GateConfig            `yaml:"gate,omitempty"`             // Per-category finding thresholds applied by judge
+	Verdict         VerdictConfig         `yaml:"verdict,omitempty"`          // Built-in Rego verdict policy and its thresholds
+	Secrets         SecretsConfig         `yaml:"secrets,omitempty"`          // Secrets detection in the instant tier
+	Escalation      EscalationConfig      `yaml:"escalation,omitempty"`       // Run the comprehensive tier only on files the lower tiers flag
+	Cache           AnalysisCacheConfig   `yaml:"cache,omitempty"`            // Where analyze keeps LLM results between runs
+	Context         ContextConfig         `yaml:"context,omitempty"`          // Budget and ranking for policies' additional_contexts
+	Pricing         map[string]ModelPrice `yaml:"pricing,omitempty"`          // LLM prices by "provider/model" or "provider", for cost tracking
+	Policies        map[string]Policy     `yaml:"policies"`
+	LSP             LSPConfig             `yaml:"lsp"`
+	RemoteCache     RemoteCacheConfig     `yaml:"remote_cache"`
+	Telemetry       TelemetryConfig       `yaml:"telemetry"`
+	Metrics         MetricsConfig         `yaml:"metrics,omitempty"` // Prometheus endpoint for long-running servers
+	Server          ServerConfig          `yaml:"server,omitempty"`  // Authentication and quotas for gavel serve and gavel mcp --http
+	Calibration     CalibrationConfig     `yaml:"calibration"`
+}
+
+// ModelPrice is what a model charges, in US dollars per 1,000 tokens.
+type ModelPrice struct {
+	InputPer1K  float64 `yaml:"input_per_1k"`
+	OutputPer1K float64 `yaml:"output_per_1k"`
+}
+
+// PathOverride disables rule categories or individual rules for artifacts
+// whose path matches one of Paths. Paths are globs with "**" support, matched
+// the same way as a rule's include_paths (see package pathglob).
+type PathOverride struct {
+	Paths             []string `yaml:"paths"`
+	DisableCategories []string `yaml:"disable_categories,omitempty"` // security, reliability, maintainability, secret
+	DisableRules      []string `yaml:"disable_rules,omitempty"`      // rule IDs or policy names
+}
+
+// ParseErrorConfig controls what happens when tree-sitter cannot parse a
+// file for AST rules.
+type ParseErrorConfig struct {
+	Action  string `yaml:"action"`  // "warn" (default), "diagnostic", or "ignore"
+	Retries int    `yaml:"retries"` // Extra parse attempts after the parser itself errors
+}
+
+// BundleConfig pins a policy bundle that gavel policy pull installs into
+// the .gavel directory.
+type BundleConfig struct {
+	Source string `yaml:"source"`           // oci://host/repository:tag or git+<url>#ref
+	Digest string `yaml:"digest,omitempty"` // sha256 content digest the pulled bundle must have
+	Key    string `yaml:"key,omitempty"`    // cosign public key that must have signed the bundle (OCI sources)
+}
+
+// ConfidenceConfig sets the confidence below which findings are recorded as
+// informational instead of counting towards the verdict.
+type ConfidenceConfig struct {
+	Min   float64            `yaml:"min,omitempty"`   // Applies to every finding without a more specific minimum
+	Rules map[string]float64 `yaml:"rules,omitempty"` // Per rule ID; overrides policies' min_confidence and min
+}
+
+// MinConfidenceFor returns the minimum confidence for findings of ruleID:
+// the rule's entry under confidence.rules, else the min_confidence of the
+// policy of that name, else confidence.min. Zero means no minimum.
+func (c *Config) MinConfidenceFor(ruleID string) float64 {
+	if threshold, ok := c.Confidence.Rules[ruleID]; ok {
+		return threshold
+	}
+	if p, ok := c.Policies[ruleID]; ok && p.MinConfidence > 0 {
+		return p.MinConfidence
+	}
+	return c.Confidence.Min
+}
+
+// ContextConfig bounds the additional_contexts sent with each file. Files
+// matching a policy's selectors are ranked by how close they are to the
+// analyzed file and added until the budget is spent.
+type ContextConfig struct {
+	MaxBytes       int    `yaml:"max_bytes,omitempty"`       // Per-file budget; defaults to 64 KiB
+	MaxTokens      int    `yaml:"max_tokens,omitempty"`      // Per-file budget in estimated tokens (four bytes each); the smaller budget applies
+	EmbeddingModel string `yaml:"embedding_model,omitempty"` // Ollama embedding model that ranks equally close files by similarity
+}
+
+// Budget returns the per-file context budget in bytes, or zero for the
+// default.
+func (c ContextConfig) Budget() int {
+	budget := c.MaxBytes
+	if t := c.MaxTokens * 4; t > 0 && (budget == 0 || t < budget) {
+		budget = t
+	}
+	return budget
+}
+
+// AnalysisCacheConfig selects the cache analyze uses for LLM results.
+type AnalysisCacheConfig struct {
+	Backend    string `yaml:"backend,omitempty"`     // "memory" (default, per run) or "disk" (persists between runs)
+	Dir        string `yaml:"dir,omitempty"`         // Disk cache directory; defaults to .gavel/cache
+	TTL        string `yaml:"ttl,omitempty"`         // How long disk entries stay valid, e.g. "168h"
+	MaxEntries int    `yaml:"max_entries,omitempty"` // Entries kept on disk before the oldest are evicted
+	Normalize  bool   `yaml:"normalize,omitempty"`   // Key results by token stream so renames and reformatting still hit
+
+	// Responses caches raw LLM responses by prompt, below the result cache
+	Responses ResponseCacheConfig `yaml:"responses,omitempty"`
+}
+
+// ResponseCacheConfig controls the cache of raw LLM responses, keyed by a
+// hash of the prompt and shared by analyze, watch, lsp and mcp. Responses
+// are kept under responses/ in the cache directory when cache.backend is
+// disk, and in memory for the life of the process otherwise.
+type ResponseCacheConfig struct {
+	Enabled    bool   `yaml:"enabled,omitempty"`
+	TTL        string `yaml:"ttl,omitempty"`         // How long responses stay valid; defaults to 24h
+	MaxEntries int    `yaml:"max_entries,omitempty"` // Responses kept before the oldest are evicted; defaults to 10000
+}
+
+// TTLDuration parses TTL, returning zero when it is empty or invalid.
+func (c ResponseCacheConfig) TTLDuration() time.Duration {
+	if c.TTL == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(c.TTL)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// TTLDuration parses TTL. It returns zero when the field is empty or
+// unparseable; Validate reports the latter.
+func (c AnalysisCacheConfig) TTLDuration() time.Duration {
+	if c.TTL == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(c.TTL)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// EscalationConfig limits the comprehensive tier to files whose instant- and
+// fast-tier findings make them worth a full LLM review, so clean files cost
+// no LLM calls.
+type EscalationConfig struct {
+	Enabled     bool     `yaml:"enabled,omitempty"`
+	MinLevel    string   `yaml:"min_level,omitempty"`    // Lowest level that counts: "error", "warning" (default) or "note"
+	MinFindings int      `yaml:"min_findings,omitempty"` // Findings at min_level or above needed to escalate; defaults to 1
+	Categories  []string `yaml:"categories,omitempty"`   // Rule categories whose findings escalate a file at any level
+}
+
+// SecretsConfig tunes the secrets scanner the instant tier runs alongside
+// the rules: provider token formats plus entropy analysis of string
+// literals. See internal/secrets.
+type SecretsConfig struct {
+	Disabled         bool    `yaml:"disabled,omitempty"`          // Turn the scanner off; the S2068 rule still runs
+	EntropyThreshold float64 `yaml:"entropy_threshold,omitempty"` // Bits per character above which a string is reported; defaults to 4.5
+	Verify           bool    `yaml:"verify,omitempty"`            // Check GitHub and Slack tokens against their APIs
+}
+
+// GateConfig holds thresholds the gate applies alongside its Rego policy.
+type GateConfig struct {
+	// Categories maps a rule category, as recorded in a result's
+	// gavel/category property, to the thresholds that gate its findings.
+	// Findings in a listed category are judged by these thresholds instead
+	// of the Rego policy.
+	Categories map[string]CategoryThreshold `yaml:"categories,omitempty"`
+}
+
+// VerdictConfig selects a built-in Rego verdict policy and sets the
+// thresholds it reads from input.verdict. Unset thresholds take the
+// policy's own defaults.
+type VerdictConfig struct {
+	Policy      string   `yaml:"policy,omitempty"`       // default, fail-on-error, max-warnings, cwe, review-low-confidence or strict
+	MaxWarnings *Limit   `yaml:"max_warnings,omitempty"` // Warning-level findings allowed before max-warnings and strict reject
+	RejectCWEs  []string `yaml:"reject_cwes,omitempty"`  // CWE classes that cwe and strict reject on at any level
+	ReviewBelow *float64 `yaml:"review_below,omitempty"` // Confidence below which findings only require review
+}
+
+// CategoryThreshold caps the actionable findings a category may contain.
+// Exceeding MaxErrors rejects; exceeding MaxWarnings requires review. A nil
+// limit leaves that level unbounded.
+type CategoryThreshold struct {
+	MaxErrors   *Limit `yaml:"max_errors,omitempty"`
+	MaxWarnings *Limit `yaml:"max_warnings,omitempty"`
+}
+
+// Limit is a finding count threshold. In YAML it is a non-negative integer
+// or the string "unlimited".
+type Limit int
+
+// Unlimited is the Limit that no count exceeds.
+const Unlimited Limit = -1
+
+// Exceeded reports whether n findings exceed the limit.
+func (l Limit) Exceeded(n int) bool {
+	return l != Unlimited && n > int(l)
+}
+
+// UnmarshalYAML accepts a non-negative integer or "unlimited".
+func (l *Limit) UnmarshalYAML(node *yaml.Node) error {
+	if node.Value == "unlimited" {
+		*l = Unlimited
+		return nil
+	}
+	var n int
+	if err := node.Decode(&n); err != nil || n < 0 {
+		return fmt.Errorf("line %d: limit must be a non-negative integer or \"unlimited\", got %q", node.Line, node.Value)
+	}
+	*l = Limit(n)
+	return nil
+}
+
+// MarshalYAML writes Unlimited back as "unlimited".
+func (l Limit) MarshalYAML() (interface{}, error) {
+	if l == Unlimited {
+		return "unlimited", nil
+	}
+	return int(l), nil
 }
 
 // RemoteCacheConfig holds remote cache server settings
 type RemoteCacheConfig struct {
-	Enabled  bool               `yaml:"enabled"`
-	URL      string             `yaml:"url"`
-	Auth     RemoteCacheAuth    `yaml:"auth"`
-	Strategy CacheStrategy      `yaml:"strategy"`
+	Enabled  bool            `yaml:"enabled"`
+	URL      string          `yaml:"url"`
+	Auth     RemoteCacheAuth `yaml:"auth"`
+	Strategy CacheStrategy   `yaml:"strategy"`
 }
 
 // RemoteCacheAuth holds authentication settings for the remote cache
@@ -77,12 +429,180 @@ type CacheStrategy struct {
 
 // ProviderConfig specifies which LLM provider to use
 type ProviderConfig struct {
-	Name       string            `yaml:"name"`
-	Ollama     OllamaConfig      `yaml:"ollama"`
-	OpenRouter OpenRouterConfig  `yaml:"openrouter"`
-	Anthropic  AnthropicConfig   `yaml:"anthropic"`
-	Bedrock    BedrockConfig     `yaml:"bedrock"`
-	OpenAI     OpenAIConfig      `yaml:"openai"`
+	Name       string           `yaml:"name"`
+	Ollama     OllamaConfig     `yaml:"ollama"`
+	OpenRouter OpenRouterConfig `yaml:"openrouter"`
+	Anthropic  AnthropicConfig  `yaml:"anthropic"`
+	Bedrock    BedrockConfig    `yaml:"bedrock"`
+	OpenAI     OpenAIConfig     `yaml:"openai"`
+	LlamaCpp   LlamaCppConfig   `yaml:"llamacpp,omitempty"`
+
+	// RequestTimeout bounds each individual AnalyzeCode call to the
+	// provider (e.g. "90s", "2m"). It is independent of the overall run
+	// budget so a single stuck file fails fast while the rest proceed.
+	// Empty disables the per-request limit.
+	RequestTimeout string `yaml:"request_timeout,omitempty"`
+
+	// MaxRequestTokens caps the estimated size of each LLM request, at
+	// about four bytes per token. Files that would exceed it are split
+	// along function and class boundaries and analyzed in chunks. Zero
+	// sends every file whole.
+	MaxRequestTokens int `yaml:"max_request_tokens,omitempty"`
+
+	// BatchTokens packs small files into shared comprehensive-tier
+	// requests of up to this many estimated tokens, with findings split
+	// back to their files. Zero analyzes each file in its own request.
+	BatchTokens int `yaml:"batch_tokens,omitempty"`
+
+	// RateLimit throttles calls to the provider and retries rate-limited
+	// or failed requests.
+	RateLimit RateLimitConfig `yaml:"rate_limit,omitempty"`
+}
+
+// ModelName returns the model configured for the selected provider.
+func (p ProviderConfig) ModelName() string {
+	switch p.Name {
+	case "ollama":
+		return p.Ollama.Model
+	case "openrouter":
+		return p.OpenRouter.Model
+	case "anthropic":
+		return p.Anthropic.Model
+	case "bedrock":
+		return p.Bedrock.Model
+	case "openai":
+		return p.OpenAI.Model
+	case "llamacpp":
+		return p.LlamaCpp.Model
+	default:
+		return ""
+	}
+}
+
+// WithModel returns a copy of p whose selected provider uses model.
+func (p ProviderConfig) WithModel(model string) ProviderConfig {
+	switch p.Name {
+	case "ollama":
+		p.Ollama.Model = model
+	case "openrouter":
+		p.OpenRouter.Model = model
+	case "anthropic":
+		p.Anthropic.Model = model
+	case "bedrock":
+		p.Bedrock.Model = model
+	case "openai":
+		p.OpenAI.Model = model
+	case "llamacpp":
+		p.LlamaCpp.Model = model
+	}
+	return p
+}
+
+// ProviderFor returns the provider settings used for a policy's LLM
+// analysis: c.Provider with the policy's Provider and Model applied.
+func (c *Config) ProviderFor(p Policy) ProviderConfig {
+	pc := c.Provider
+	if p.Provider != "" {
+		pc.Name = p.Provider
+	}
+	if p.Model != "" {
+		pc = pc.WithModel(p.Model)
+	}
+	return pc
+}
+
+// RequestTokenBudget returns the estimated token cap for each request:
+// MaxRequestTokens when set, otherwise three quarters of a llama.cpp
+// server's context window, or zero for no cap.
+func (p ProviderConfig) RequestTokenBudget() int {
+	if p.MaxRequestTokens > 0 {
+		return p.MaxRequestTokens
+	}
+	if p.Name == "llamacpp" && p.LlamaCpp.ContextWindow > 0 {
+		return p.LlamaCpp.ContextWindow * 3 / 4
+	}
+	return 0
+}
+
+// RequestTimeoutDuration parses RequestTimeout. It returns zero when the
+// field is empty or unparseable; Validate reports the latter.
+func (p ProviderConfig) RequestTimeoutDuration() time.Duration {
+	if p.RequestTimeout == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(p.RequestTimeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// RateLimitConfig throttles and retries provider calls. The zero value
+// disables both.
+type RateLimitConfig struct {
+	// RequestsPerMinute caps how many calls start per minute; 0 is unlimited.
+	RequestsPerMinute int `yaml:"requests_per_minute,omitempty"`
+	// TokensPerMinute caps the estimated prompt tokens sent per minute;
+	// 0 is unlimited.
+	TokensPerMinute int `yaml:"tokens_per_minute,omitempty"`
+	// MaxRetries is how many times a call that failed with a rate limit
+	// (429) or server error (5xx) is retried; 0 disables retries.
+	MaxRetries int `yaml:"max_retries,omitempty"`
+	// InitialBackoff is the delay before the first retry (default 1s). Each
+	// further retry doubles it, up to MaxBackoff (default 30s), with jitter.
+	InitialBackoff string `yaml:"initial_backoff,omitempty"`
+	MaxBackoff     string `yaml:"max_backoff,omitempty"`
+}
+
+// InitialBackoffDuration parses InitialBackoff, defaulting to one second.
+func (r RateLimitConfig) InitialBackoffDuration() time.Duration {
+	return parseDurationOr(r.InitialBackoff, time.Second)
+}
+
+// MaxBackoffDuration parses MaxBackoff, defaulting to thirty seconds.
+func (r RateLimitConfig) MaxBackoffDuration() time.Duration {
+	return parseDurationOr(r.MaxBackoff, 30*time.Second)
+}
+
+func (r RateLimitConfig) validate() error {
+	if r.RequestsPerMinute < 0 {
+		return fmt.Errorf("provider.rate_limit.requests_per_minute must not be negative, got %d", r.RequestsPerMinute)
+	}
+	if r.TokensPerMinute < 0 {
+		return fmt.Errorf("provider.rate_limit.tokens_per_minute must not be negative, got %d", r.TokensPerMinute)
+	}
+	if r.MaxRetries < 0 {
+		return fmt.Errorf("provider.rate_limit.max_retries must not be negative, got %d", r.MaxRetries)
+	}
+	for _, f := range []struct{ name, value string }{
+		{"initial_backoff", r.InitialBackoff},
+		{"max_backoff", r.MaxBackoff},
+	} {
+		if f.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f.value)
+		if err != nil {
+			return fmt.Errorf("provider.rate_limit.%s: %w", f.name, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("provider.rate_limit.%s must be positive, got %s", f.name, f.value)
+		}
+	}
+	return nil
+}
+
+// parseDurationOr parses s, returning def when s is empty or not a
+// positive duration; Validate reports the latter.
+func parseDurationOr(s string, def time.Duration) time.Duration {
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
 }
 
 // OllamaConfig holds Ollama-specific settings
@@ -112,6 +632,22 @@ type OpenAIConfig struct {
 	Model string `yaml:"model"`
 }
 
+// LlamaCppConfig holds settings for a llama.cpp server, or another server
+// with its OpenAI-compatible API such as LM Studio. The API key, if the
+// server requires one, is read from LLAMACPP_API_KEY.
+type LlamaCppConfig struct {
+	BaseURL string `yaml:"base_url"` // Defaults to http://localhost:8080/v1
+	Model   string `yaml:"model"`    // Optional for llama.cpp, which serves the model it was started with
+
+	// ContextWindow is the context size the server was started with. When
+	// max_request_tokens is unset, requests are capped to three quarters
+	// of it, leaving the rest for the response.
+	ContextWindow int `yaml:"context_window,omitempty"`
+
+	// Temperature overrides the server's sampling temperature (0-2).
+	Temperature *float64 `yaml:"temperature,omitempty"`
+}
+
 // LSPConfig holds LSP-specific configuration
 type LSPConfig struct {
 	Watcher  WatcherConfig  `yaml:"watcher"`
@@ -165,21 +701,64 @@ type CalibrationUploadConfig struct {
 
 // Validate checks that the configuration is valid and ready to use
 func (c *Config) Validate() error {
-	validProviders := map[string]bool{
-		"ollama":     true,
-		"openrouter": true,
-		"anthropic":  true,
-		"bedrock":    true,
-		"openai":     true,
+	if err := c.ValidateProvider(); err != nil {
+		return err
+	}
+	return c.ValidateSettings()
+}
+
+// ValidateProvider checks the provider selection and the model and
+// credentials it needs. Failures here only affect the LLM tiers, so callers
+// that can run without them may check this separately from ValidateSettings.
+func (c *Config) ValidateProvider() error {
+	if err := c.Provider.validate(); err != nil {
+		return err
 	}
 
-	if !validProviders[c.Provider.Name] {
-		return fmt.Errorf("provider.name must be one of: ollama, openrouter, anthropic, bedrock, openai; got: %s", c.Provider.Name)
+	if c.FastProvider.Name != "" {
+		if err := c.FastProvider.validate(); err != nil {
+			return fmt.Errorf("fast_provider: %w", err)
+		}
 	}
 
-	switch c.Provider.Name {
+	// Policies routed to another provider or model need that provider's
+	// settings and credentials too.
+	names := make([]string, 0, len(c.Policies))
+	for name, p := range c.Policies {
+		if p.Enabled && (p.Provider != "" || p.Model != "") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		policy := c.Policies[name]
+		if policy.Provider != "" && !validProviders[policy.Provider] {
+			return fmt.Errorf("policies.%s.provider must be one of: ollama, openrouter, anthropic, bedrock, openai, llamacpp; got: %s", name, policy.Provider)
+		}
+		if err := c.ProviderFor(policy).validate(); err != nil {
+			return fmt.Errorf("policies.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+var validProviders = map[string]bool{
+	"ollama":     true,
+	"openrouter": true,
+	"anthropic":  true,
+	"bedrock":    true,
+	"openai":     true,
+	"llamacpp":   true,
+}
+
+func (p ProviderConfig) validate() error {
+	if !validProviders[p.Name] {
+		return fmt.Errorf("provider.name must be one of: ollama, openrouter, anthropic, bedrock, openai, llamacpp; got: %s", p.Name)
+	}
+
+	switch p.Name {
 	case "ollama":
-		if c.Provider.Ollama.Model == "" {
+		if p.Ollama.Model == "" {
 			return fmt.Errorf("provider.ollama.model is required when using Ollama")
 		}
 	case "openrouter":
@@ -187,41 +766,255 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("OPENROUTER_API_KEY environment variable required for OpenRouter")
 		}
 	case "anthropic":
-		if c.Provider.Anthropic.Model == "" {
+		if p.Anthropic.Model == "" {
 			return fmt.Errorf("provider.anthropic.model is required when using Anthropic")
 		}
 		if os.Getenv("ANTHROPIC_API_KEY") == "" {
 			return fmt.Errorf("ANTHROPIC_API_KEY environment variable required for Anthropic")
 		}
 	case "bedrock":
-		if c.Provider.Bedrock.Model == "" {
+		if p.Bedrock.Model == "" {
 			return fmt.Errorf("provider.bedrock.model is required when using Bedrock")
 		}
-		if c.Provider.Bedrock.Region == "" {
+		if p.Bedrock.Region == "" {
 			return fmt.Errorf("provider.bedrock.region is required when using Bedrock")
 		}
 		// AWS credentials are typically loaded from environment or ~/.aws/credentials
 		// We'll validate them at runtime when making the actual call
 	case "openai":
-		if c.Provider.OpenAI.Model == "" {
+		if p.OpenAI.Model == "" {
 			return fmt.Errorf("provider.openai.model is required when using OpenAI")
 		}
 		if os.Getenv("OPENAI_API_KEY") == "" {
 			return fmt.Errorf("OPENAI_API_KEY environment variable required for OpenAI")
 		}
+	case "llamacpp":
+		if p.LlamaCpp.ContextWindow < 0 {
+			return fmt.Errorf("provider.llamacpp.context_window must not be negative, got %d", p.LlamaCpp.ContextWindow)
+		}
+		if t := p.LlamaCpp.Temperature; t != nil && (*t < 0 || *t > 2) {
+			return fmt.Errorf("provider.llamacpp.temperature must be between 0 and 2, got %g", *t)
+		}
 	}
 
-	// Validate persona field
-	validPersonas := map[string]bool{
-		"code-reviewer":         true,
-		"code-reviewer-verbose": true,
-		"architect":             true,
-		"security":              true,
-		"research-assistant":    true,
-		"sharp-editor":          true,
+	return nil
+}
+
+// ValidateSettings checks everything in the configuration except the
+// provider.
+func (c *Config) ValidateSettings() error {
+	if c.Provider.RequestTimeout != "" {
+		d, err := time.ParseDuration(c.Provider.RequestTimeout)
+		if err != nil {
+			return fmt.Errorf("provider.request_timeout: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("provider.request_timeout must not be negative, got %s", c.Provider.RequestTimeout)
+		}
+	}
+
+	if c.Provider.MaxRequestTokens < 0 {
+		return fmt.Errorf("provider.max_request_tokens must not be negative, got %d", c.Provider.MaxRequestTokens)
+	}
+	if c.Provider.BatchTokens < 0 {
+		return fmt.Errorf("provider.batch_tokens must not be negative, got %d", c.Provider.BatchTokens)
+	}
+
+	if err := c.Provider.RateLimit.validate(); err != nil {
+		return err
+	}
+
+	if c.DedupWindow < 0 {
+		return fmt.Errorf("dedup_window must not be negative, got %d", c.DedupWindow)
+	}
+
+	for i, b := range c.Bundles {
+		if !strings.HasPrefix(b.Source, "oci://") && !strings.HasPrefix(b.Source, "git+") {
+			return fmt.Errorf("bundles[%d].source must start with oci:// or git+, got %q", i, b.Source)
+		}
+		if b.Digest != "" && !strings.HasPrefix(b.Digest, "sha256:") {
+			return fmt.Errorf("bundles[%d].digest must be a sha256: digest, got %q", i, b.Digest)
+		}
+	}
+
+	if c.Confidence.Min < 0 || c.Confidence.Min > 1 {
+		return fmt.Errorf("confidence.min must be between 0 and 1, got %v", c.Confidence.Min)
+	}
+	for id, threshold := range c.Confidence.Rules {
+		if threshold < 0 || threshold > 1 {
+			return fmt.Errorf("confidence.rules.%s must be between 0 and 1, got %v", id, threshold)
+		}
+	}
+	for name, p := range c.Policies {
+		if p.MinConfidence < 0 || p.MinConfidence > 1 {
+			return fmt.Errorf("policy %s: min_confidence must be between 0 and 1, got %v", name, p.MinConfidence)
+		}
+	}
+
+	switch c.Dedup {
+	case "", "strict", "fuzzy", "off":
+	default:
+		return fmt.Errorf("dedup must be strict, fuzzy or off, got %q", c.Dedup)
+	}
+	if c.DedupSimilarity < 0 || c.DedupSimilarity > 1 {
+		return fmt.Errorf("dedup_similarity must be between 0 and 1, got %v", c.DedupSimilarity)
+	}
+
+	for key, price := range c.Pricing {
+		if price.InputPer1K < 0 || price.OutputPer1K < 0 {
+			return fmt.Errorf("pricing.%s: prices must not be negative", key)
+		}
+	}
+
+	for i, o := range c.PathOverrides {
+		if len(o.Paths) == 0 {
+			return fmt.Errorf("path_overrides[%d]: paths must not be empty", i)
+		}
+		if err := pathglob.Validate(o.Paths); err != nil {
+			return fmt.Errorf("path_overrides[%d]: invalid path glob: %w", i, err)
+		}
+		if len(o.DisableCategories) == 0 && len(o.DisableRules) == 0 {
+			return fmt.Errorf("path_overrides[%d]: set disable_categories or disable_rules", i)
+		}
+		for _, cat := range o.DisableCategories {
+			switch cat {
+			case "security", "reliability", "maintainability", "secret":
+			default:
+				return fmt.Errorf("path_overrides[%d]: unknown category %q (valid: security, reliability, maintainability, secret)", i, cat)
+			}
+		}
+	}
+
+	if c.Secrets.EntropyThreshold < 0 {
+		return fmt.Errorf("secrets.entropy_threshold must not be negative, got %g", c.Secrets.EntropyThreshold)
+	}
+
+	switch c.Escalation.MinLevel {
+	case "", "error", "warning", "note":
+	default:
+		return fmt.Errorf("escalation.min_level: unknown level %q (valid: error, warning, note)", c.Escalation.MinLevel)
+	}
+	if c.Escalation.MinFindings < 0 {
+		return fmt.Errorf("escalation.min_findings must not be negative, got %d", c.Escalation.MinFindings)
+	}
+	for _, cat := range c.Escalation.Categories {
+		switch cat {
+		case "security", "reliability", "maintainability", "dependency", "secret":
+		default:
+			return fmt.Errorf("escalation.categories: unknown category %q (valid: security, reliability, maintainability, dependency, secret)", cat)
+		}
+	}
+
+	switch c.ParseErrors.Action {
+	case "", "warn", "diagnostic", "ignore":
+	default:
+		return fmt.Errorf("parse_errors.action: unknown action %q (valid: warn, diagnostic, ignore)", c.ParseErrors.Action)
+	}
+	if c.ParseErrors.Retries < 0 {
+		return fmt.Errorf("parse_errors.retries must not be negative, got %d", c.ParseErrors.Retries)
+	}
+
+	switch c.Cache.Backend {
+	case "", "memory", "disk":
+	default:
+		return fmt.Errorf("cache.backend: unknown backend %q (valid: memory, disk)", c.Cache.Backend)
+	}
+	if c.Cache.TTL != "" {
+		d, err := time.ParseDuration(c.Cache.TTL)
+		if err != nil {
+			return fmt.Errorf("cache.ttl: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("cache.ttl must not be negative, got %s", c.Cache.TTL)
+		}
+	}
+	if c.Cache.MaxEntries < 0 {
+		return fmt.Errorf("cache.max_entries must not be negative, got %d", c.Cache.MaxEntries)
+	}
+	if c.Cache.Responses.TTL != "" {
+		d, err := time.ParseDuration(c.Cache.Responses.TTL)
+		if err != nil {
+			return fmt.Errorf("cache.responses.ttl: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("cache.responses.ttl must not be negative, got %s", c.Cache.Responses.TTL)
+		}
+	}
+	if c.Cache.Responses.MaxEntries < 0 {
+		return fmt.Errorf("cache.responses.max_entries must not be negative, got %d", c.Cache.Responses.MaxEntries)
 	}
-	if c.Persona != "" && !validPersonas[c.Persona] {
-		return fmt.Errorf("unknown persona: %s (valid: code-reviewer, code-reviewer-verbose, architect, security, research-assistant, sharp-editor)", c.Persona)
+	if c.Context.MaxBytes < 0 {
+		return fmt.Errorf("context.max_bytes must not be negative, got %d", c.Context.MaxBytes)
+	}
+	if c.Context.MaxTokens < 0 {
+		return fmt.Errorf("context.max_tokens must not be negative, got %d", c.Context.MaxTokens)
+	}
+
+	if c.Metrics.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Addr); err != nil {
+			return fmt.Errorf("metrics.addr: %w", err)
+		}
+	}
+
+	if err := c.Server.Auth.Limits.validate("server.auth.limits"); err != nil {
+		return err
+	}
+	for tenant, l := range c.Server.Auth.Tenants {
+		if err := l.validate("server.auth.tenants." + tenant); err != nil {
+			return err
+		}
+	}
+	if o := c.Server.Auth.OIDC; o.Issuer != "" || o.JWKSURL != "" {
+		if o.Issuer == "" {
+			return fmt.Errorf("server.auth.oidc.issuer is required")
+		}
+		if o.Audience == "" {
+			return fmt.Errorf("server.auth.oidc.audience is required")
+		}
+		for _, f := range []struct{ name, value string }{{"issuer", o.Issuer}, {"jwks_url", o.JWKSURL}} {
+			if f.value == "" {
+				continue
+			}
+			if u, err := url.Parse(f.value); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("server.auth.oidc.%s: %q is not an http(s) URL", f.name, f.value)
+			}
+		}
+	}
+
+	for cat, t := range c.Gate.Categories {
+		switch cat {
+		case "security", "reliability", "maintainability":
+		default:
+			return fmt.Errorf("gate.categories: unknown category %q (valid: security, reliability, maintainability)", cat)
+		}
+		if t.MaxErrors == nil && t.MaxWarnings == nil {
+			return fmt.Errorf("gate.categories.%s: set max_errors or max_warnings", cat)
+		}
+		for _, l := range []*Limit{t.MaxErrors, t.MaxWarnings} {
+			if l != nil && *l < Unlimited {
+				return fmt.Errorf("gate.categories.%s: limits must be non-negative or unlimited, got %d", cat, *l)
+			}
+		}
+	}
+
+	if c.Verdict.Policy != "" && !library.Known(c.Verdict.Policy) {
+		return fmt.Errorf("verdict.policy: unknown policy %q (valid: %s)", c.Verdict.Policy, strings.Join(library.Names(), ", "))
+	}
+	if c.Verdict.MaxWarnings != nil && *c.Verdict.MaxWarnings < Unlimited {
+		return fmt.Errorf("verdict.max_warnings must be non-negative or unlimited, got %d", *c.Verdict.MaxWarnings)
+	}
+	for _, cwe := range c.Verdict.RejectCWEs {
+		if !strings.HasPrefix(cwe, "CWE-") {
+			return fmt.Errorf("verdict.reject_cwes: %q is not a CWE ID such as CWE-89", cwe)
+		}
+	}
+	if r := c.Verdict.ReviewBelow; r != nil && (*r < 0 || *r > 1) {
+		return fmt.Errorf("verdict.review_below must be between 0 and 1, got %v", *r)
+	}
+
+	// Validate persona field
+	if c.Persona != "" && !persona.Known(c.Persona) {
+		return fmt.Errorf("unknown persona: %s (valid: %s)", c.Persona, strings.Join(persona.Names(), ", "))
 	}
 
 	return nil
@@ -265,12 +1058,153 @@ func MergeConfigs(configs ...*Config) *Config {
 		if cfg.Provider.OpenAI.Model != "" {
 			result.Provider.OpenAI.Model = cfg.Provider.OpenAI.Model
 		}
+		if cfg.Provider.LlamaCpp.BaseURL != "" {
+			result.Provider.LlamaCpp.BaseURL = cfg.Provider.LlamaCpp.BaseURL
+		}
+		if cfg.Provider.LlamaCpp.Model != "" {
+			result.Provider.LlamaCpp.Model = cfg.Provider.LlamaCpp.Model
+		}
+		if cfg.Provider.LlamaCpp.ContextWindow != 0 {
+			result.Provider.LlamaCpp.ContextWindow = cfg.Provider.LlamaCpp.ContextWindow
+		}
+		if cfg.Provider.LlamaCpp.Temperature != nil {
+			result.Provider.LlamaCpp.Temperature = cfg.Provider.LlamaCpp.Temperature
+		}
+
+		// The fast tier's provider is replaced as a whole, since its
+		// settings only make sense together
+		if cfg.FastProvider.Name != "" {
+			result.FastProvider = cfg.FastProvider
+		}
+		if cfg.Provider.RequestTimeout != "" {
+			result.Provider.RequestTimeout = cfg.Provider.RequestTimeout
+		}
+		if cfg.Provider.MaxRequestTokens != 0 {
+			result.Provider.MaxRequestTokens = cfg.Provider.MaxRequestTokens
+		}
+		if cfg.Provider.BatchTokens != 0 {
+			result.Provider.BatchTokens = cfg.Provider.BatchTokens
+		}
+		if rl := cfg.Provider.RateLimit; rl.RequestsPerMinute != 0 {
+			result.Provider.RateLimit.RequestsPerMinute = rl.RequestsPerMinute
+		}
+		if rl := cfg.Provider.RateLimit; rl.TokensPerMinute != 0 {
+			result.Provider.RateLimit.TokensPerMinute = rl.TokensPerMinute
+		}
+		if rl := cfg.Provider.RateLimit; rl.MaxRetries != 0 {
+			result.Provider.RateLimit.MaxRetries = rl.MaxRetries
+		}
+		if rl := cfg.Provider.RateLimit; rl.InitialBackoff != "" {
+			result.Provider.RateLimit.InitialBackoff = rl.InitialBackoff
+		}
+		if rl := cfg.Provider.RateLimit; rl.MaxBackoff != "" {
+			result.Provider.RateLimit.MaxBackoff = rl.MaxBackoff
+		}
 
 		// Merge persona - non-empty string overrides
 		if cfg.Persona != "" {
 			result.Persona = cfg.Persona
 		}
 
+		// Merge dedup_window - non-zero overrides
+		if cfg.DedupWindow != 0 {
+			result.DedupWindow = cfg.DedupWindow
+		}
+		// Merge bundles - entries replace those with the same source
+		for _, b := range cfg.Bundles {
+			replaced := false
+			for i := range result.Bundles {
+				if result.Bundles[i].Source == b.Source {
+					result.Bundles[i], replaced = b, true
+				}
+			}
+			if !replaced {
+				result.Bundles = append(result.Bundles, b)
+			}
+		}
+
+		// Merge confidence - non-zero min overrides; rule entries merge by ID
+		if cfg.Confidence.Min != 0 {
+			result.Confidence.Min = cfg.Confidence.Min
+		}
+		for id, threshold := range cfg.Confidence.Rules {
+			if result.Confidence.Rules == nil {
+				result.Confidence.Rules = make(map[string]float64)
+			}
+			result.Confidence.Rules[id] = threshold
+		}
+		if cfg.Dedup != "" {
+			result.Dedup = cfg.Dedup
+		}
+		if cfg.DedupSimilarity != 0 {
+			result.DedupSimilarity = cfg.DedupSimilarity
+		}
+
+		// Merge parse_errors - non-zero fields override
+		if cfg.ParseErrors.Action != "" {
+			result.ParseErrors.Action = cfg.ParseErrors.Action
+		}
+		if cfg.ParseErrors.Retries != 0 {
+			result.ParseErrors.Retries = cfg.ParseErrors.Retries
+		}
+
+		// Merge gate categories - per-category entries from higher tiers replace lower ones
+		for cat, t := range cfg.Gate.Categories {
+			if result.Gate.Categories == nil {
+				result.Gate.Categories = make(map[string]CategoryThreshold)
+			}
+			result.Gate.Categories[cat] = t
+		}
+
+		if cfg.Verdict.Policy != "" {
+			result.Verdict.Policy = cfg.Verdict.Policy
+		}
+		if cfg.Verdict.MaxWarnings != nil {
+			result.Verdict.MaxWarnings = cfg.Verdict.MaxWarnings
+		}
+		if cfg.Verdict.RejectCWEs != nil {
+			result.Verdict.RejectCWEs = cfg.Verdict.RejectCWEs
+		}
+		if cfg.Verdict.ReviewBelow != nil {
+			result.Verdict.ReviewBelow = cfg.Verdict.ReviewBelow
+		}
+
+		// Merge secrets - disabling or verifying in any tier sticks
+		if cfg.Secrets.Disabled {
+			result.Secrets.Disabled = true
+		}
+		if cfg.Secrets.EntropyThreshold != 0 {
+			result.Secrets.EntropyThreshold = cfg.Secrets.EntropyThreshold
+		}
+		if cfg.Secrets.Verify {
+			result.Secrets.Verify = true
+		}
+
+		// Merge escalation - non-zero fields override; categories are replaced
+		if cfg.Escalation.Enabled {
+			result.Escalation.Enabled = true
+		}
+		if cfg.Escalation.MinLevel != "" {
+			result.Escalation.MinLevel = cfg.Escalation.MinLevel
+		}
+		if cfg.Escalation.MinFindings > 0 {
+			result.Escalation.MinFindings = cfg.Escalation.MinFindings
+		}
+		if len(cfg.Escalation.Categories) > 0 {
+			result.Escalation.Categories = cfg.Escalation.Categories
+		}
+
+		// Merge pricing - per-model entries from higher tiers replace lower ones
+		for key, price := range cfg.Pricing {
+			if result.Pricing == nil {
+				result.Pricing = make(map[string]ModelPrice)
+			}
+			result.Pricing[key] = price
+		}
+
+		// Merge path_overrides - entries accumulate across tiers
+		result.PathOverrides = append(result.PathOverrides, cfg.PathOverrides...)
+
 		// Merge strict_filter - only override if this config appears intentional
 		// (has at least one non-zero field set, indicating it was loaded from a file).
 		// This prevents an empty/nil config's zero-value false from clearing the default.
@@ -302,6 +1236,43 @@ func MergeConfigs(configs ...*Config) *Config {
 			result.LSP.Cache.MaxSizeMB = cfg.LSP.Cache.MaxSizeMB
 		}
 
+		// Merge analysis cache config - non-zero fields override
+		if cfg.Cache.Backend != "" {
+			result.Cache.Backend = cfg.Cache.Backend
+		}
+		if cfg.Cache.Dir != "" {
+			result.Cache.Dir = cfg.Cache.Dir
+		}
+		if cfg.Cache.TTL != "" {
+			result.Cache.TTL = cfg.Cache.TTL
+		}
+		if cfg.Cache.MaxEntries > 0 {
+			result.Cache.MaxEntries = cfg.Cache.MaxEntries
+		}
+		if cfg.Cache.Normalize {
+			result.Cache.Normalize = true
+		}
+		if cfg.Cache.Responses.Enabled {
+			result.Cache.Responses.Enabled = true
+		}
+		if cfg.Cache.Responses.TTL != "" {
+			result.Cache.Responses.TTL = cfg.Cache.Responses.TTL
+		}
+		if cfg.Cache.Responses.MaxEntries > 0 {
+			result.Cache.Responses.MaxEntries = cfg.Cache.Responses.MaxEntries
+		}
+
+		// Merge context config - non-zero fields override
+		if cfg.Context.MaxBytes > 0 {
+			result.Context.MaxBytes = cfg.Context.MaxBytes
+		}
+		if cfg.Context.MaxTokens > 0 {
+			result.Context.MaxTokens = cfg.Context.MaxTokens
+		}
+		if cfg.Context.EmbeddingModel != "" {
+			result.Context.EmbeddingModel = cfg.Context.EmbeddingModel
+		}
+
 		// Merge remote cache config
 		if cfg.RemoteCache.Enabled {
 			result.RemoteCache.Enabled = true
@@ -354,6 +1325,36 @@ func MergeConfigs(configs ...*Config) *Config {
 			result.Telemetry.Headers = cfg.Telemetry.Headers
 		}
 
+		// Merge metrics config - an explicit addr marks the section present,
+		// so enabled is applied as-is to allow disabling
+		if cfg.Metrics.Addr != "" || cfg.Metrics.Enabled {
+			result.Metrics.Enabled = cfg.Metrics.Enabled
+		}
+		if cfg.Metrics.Addr != "" {
+			result.Metrics.Addr = cfg.Metrics.Addr
+		}
+
+		// Merge server auth config
+		auth := cfg.Server.Auth
+		if auth.KeysFile != "" {
+			result.Server.Auth.KeysFile = auth.KeysFile
+		}
+		if auth.OIDC.Issuer != "" {
+			result.Server.Auth.OIDC = auth.OIDC
+		}
+		if auth.IsolateTenants {
+			result.Server.Auth.IsolateTenants = true
+		}
+		if auth.Limits != (TenantLimits{}) {
+			result.Server.Auth.Limits = auth.Limits
+		}
+		for tenant, l := range auth.Tenants {
+			if result.Server.Auth.Tenants == nil {
+				result.Server.Auth.Tenants = make(map[string]TenantLimits)
+			}
+			result.Server.Auth.Tenants[tenant] = l
+		}
+
 		// Merge calibration config
 		calPresent := cfg.Calibration.ServerURL != "" || cfg.Calibration.Enabled
 		if calPresent {
@@ -407,9 +1408,18 @@ func MergeConfigs(configs ...*Config) *Config {
 			// an unset default.
 			if policy.Enabled {
 				existing.Enabled = true
-			} else if policy.Description == "" && policy.Severity == "" && policy.Instruction == "" {
+			} else if policy.Description == "" && policy.Severity == "" && policy.Instruction == "" && policy.Provider == "" && policy.Model == "" && policy.MinConfidence == 0 {
 				existing.Enabled = false
 			}
+			if policy.Provider != "" {
+				existing.Provider = policy.Provider
+			}
+			if policy.Model != "" {
+				existing.Model = policy.Model
+			}
+			if policy.MinConfidence != 0 {
+				existing.MinConfidence = policy.MinConfidence
+			}
 			// AdditionalContexts: if specified, override completely
 			if len(policy.AdditionalContexts) > 0 {
 				existing.AdditionalContexts = policy.AdditionalContexts
@@ -439,8 +1449,30 @@ func LoadFromFile(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// EnvConfigVar names the environment variable that can carry a complete
+// YAML config for ephemeral runs (e.g. containerized CI without a .gavel
+// directory).
+const EnvConfigVar = "GAVEL_CONFIG"
+
+// LoadFromEnv parses the YAML config held in the named environment variable.
+// Returns nil, nil if the variable is unset or blank.
+func LoadFromEnv(name string) (*Config, error) {
+	data := os.Getenv(name)
+	if strings.TrimSpace(data) == "" {
+		return nil, nil
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return &cfg, nil
+}
+
 // LoadTiered loads system defaults, then machine config, then project config,
-// and merges them in order of increasing precedence.
+// then the config in $GAVEL_CONFIG, and merges them in order of increasing
+// precedence.
 func LoadTiered(machinePath, projectPath string) (*Config, error) {
 	system := SystemDefaults()
 
@@ -454,7 +1486,12 @@ func LoadTiered(machinePath, projectPath string) (*Config, error) {
 		return nil, fmt.Errorf("loading project config: %w", err)
 	}
 
-	return MergeConfigs(system, machine, project), nil
+	env, err := LoadFromEnv(EnvConfigVar)
+	if err != nil {
+		return nil, fmt.Errorf("loading env config: %w", err)
+	}
+
+	return MergeConfigs(system, machine, project, env), nil
 }
 
 // GetRemoteCacheToken returns the authentication token for the remote cache.
@@ -2,8 +2,14 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/persona"
 )
 
 func TestMergePolicies_HigherTierOverrides(t *testing.T) {
@@ -119,6 +125,454 @@ func TestLoadTiered(t *testing.T) {
 	}
 }
 
+func TestLoadTiered_EnvConfigOverridesFiles(t *testing.T) {
+	dir := t.TempDir()
+	projectConf := dir + "/project.yaml"
+	os.WriteFile(projectConf, []byte("persona: architect\npolicies:\n  error-handling:\n    severity: \"warning\"\n"), 0644)
+
+	t.Setenv(EnvConfigVar, `
+provider:
+  name: ollama
+  ollama:
+    model: qwen2.5-coder:7b
+persona: security
+policies:
+  error-handling:
+    severity: "error"
+  env-only:
+    description: "From env"
+    severity: "note"
+    instruction: "Check env thing"
+    enabled: true
+`)
+
+	cfg, err := LoadTiered(dir+"/missing.yaml", projectConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Persona != "security" {
+		t.Errorf("expected env persona 'security' to override project, got %q", cfg.Persona)
+	}
+	if cfg.Policies["error-handling"].Severity != "error" {
+		t.Errorf("expected env severity 'error', got %q", cfg.Policies["error-handling"].Severity)
+	}
+	if _, ok := cfg.Policies["env-only"]; !ok {
+		t.Error("expected env policy 'env-only'")
+	}
+	if _, ok := cfg.Policies["function-length"]; !ok {
+		t.Error("expected system default 'function-length'")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected env-provided config to validate, got %v", err)
+	}
+}
+
+func TestLoadTiered_EnvConfigInvalidYAML(t *testing.T) {
+	t.Setenv(EnvConfigVar, "policies: [unclosed")
+
+	_, err := LoadTiered("/nonexistent/machine.yaml", "/nonexistent/project.yaml")
+	if err == nil {
+		t.Fatal("expected error for malformed GAVEL_CONFIG")
+	}
+	if !strings.Contains(err.Error(), EnvConfigVar) {
+		t.Errorf("expected error to mention %s, got %v", EnvConfigVar, err)
+	}
+}
+
+func TestLoadTiered_EnvConfigFailsValidation(t *testing.T) {
+	t.Setenv(EnvConfigVar, "provider:\n  name: not-a-provider\n")
+
+	cfg, err := LoadTiered("/nonexistent/machine.yaml", "/nonexistent/project.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for invalid provider from GAVEL_CONFIG")
+	}
+}
+
+func TestLoadFromEnv_Unset(t *testing.T) {
+	t.Setenv(EnvConfigVar, "  ")
+
+	cfg, err := LoadFromEnv(EnvConfigVar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg != nil {
+		t.Error("expected nil config for blank env var")
+	}
+}
+
+func TestMergeConfigs_DedupWindow(t *testing.T) {
+	system := &Config{DedupWindow: 2}
+	project := &Config{DedupWindow: 5}
+
+	if got := MergeConfigs(system, project).DedupWindow; got != 5 {
+		t.Errorf("expected project dedup_window 5, got %d", got)
+	}
+	if got := MergeConfigs(system, &Config{}).DedupWindow; got != 2 {
+		t.Errorf("expected unset dedup_window to keep 2, got %d", got)
+	}
+}
+
+func TestConfig_Dedup(t *testing.T) {
+	merged := MergeConfigs(&Config{Dedup: "fuzzy", DedupSimilarity: 0.7}, &Config{Dedup: "off"})
+	if merged.Dedup != "off" || merged.DedupSimilarity != 0.7 {
+		t.Errorf("unexpected merged dedup %q / %v", merged.Dedup, merged.DedupSimilarity)
+	}
+
+	for _, tc := range []struct {
+		cfg     Config
+		wantErr string
+	}{
+		{Config{Dedup: "fuzzy", DedupSimilarity: 0.4}, ""},
+		{Config{Dedup: "loose"}, "dedup must be"},
+		{Config{Dedup: "fuzzy", DedupSimilarity: 1.5}, "dedup_similarity"},
+	} {
+		tc.cfg.Provider = ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}}
+		tc.cfg.Persona = "code-reviewer"
+		err := tc.cfg.Validate()
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("expected %+v to be valid, got %v", tc.cfg.Dedup, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("expected %s error, got %v", tc.wantErr, err)
+		}
+	}
+}
+
+func TestConfig_Validate_DedupWindow(t *testing.T) {
+	cfg := &Config{
+		Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+		Persona:  "code-reviewer",
+	}
+	cfg.DedupWindow = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "dedup_window") {
+		t.Errorf("expected dedup_window validation error, got %v", err)
+	}
+	cfg.DedupWindow = 3
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+}
+
+func TestConfig_ValidateProviderAndSettings(t *testing.T) {
+	cfg := &Config{
+		Provider: ProviderConfig{Name: "ollama"},
+		Persona:  "code-reviewer",
+	}
+	if err := cfg.ValidateProvider(); err == nil || !strings.Contains(err.Error(), "provider.ollama.model") {
+		t.Errorf("expected provider validation error, got %v", err)
+	}
+	if err := cfg.ValidateSettings(); err != nil {
+		t.Errorf("expected settings to be valid without a usable provider, got %v", err)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected Validate to include the provider check")
+	}
+
+	cfg.Provider.Ollama.Model = "m"
+	cfg.Persona = "nope"
+	if err := cfg.ValidateProvider(); err != nil {
+		t.Errorf("expected valid provider, got %v", err)
+	}
+	if err := cfg.ValidateSettings(); err == nil || !strings.Contains(err.Error(), "persona") {
+		t.Errorf("expected persona validation error, got %v", err)
+	}
+}
+
+func TestConfig_Validate_Cache(t *testing.T) {
+	tests := []struct {
+		name    string
+		cache   AnalysisCacheConfig
+		wantErr string
+	}{
+		{"default", AnalysisCacheConfig{}, ""},
+		{"disk", AnalysisCacheConfig{Backend: "disk", TTL: "24h", MaxEntries: 100}, ""},
+		{"unknown backend", AnalysisCacheConfig{Backend: "redis"}, "cache.backend"},
+		{"bad ttl", AnalysisCacheConfig{Backend: "disk", TTL: "soon"}, "cache.ttl"},
+		{"negative max", AnalysisCacheConfig{MaxEntries: -1}, "cache.max_entries"},
+		{"responses", AnalysisCacheConfig{Responses: ResponseCacheConfig{Enabled: true, TTL: "1h", MaxEntries: 10}}, ""},
+		{"bad responses ttl", AnalysisCacheConfig{Responses: ResponseCacheConfig{Enabled: true, TTL: "later"}}, "cache.responses.ttl"},
+		{"negative responses max", AnalysisCacheConfig{Responses: ResponseCacheConfig{MaxEntries: -1}}, "cache.responses.max_entries"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{Cache: tc.cache}
+			err := cfg.ValidateSettings()
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("expected valid config, got %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected %s error, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMergeConfigs_Cache(t *testing.T) {
+	machine := &Config{Cache: AnalysisCacheConfig{Backend: "disk", TTL: "24h"}}
+	project := &Config{Cache: AnalysisCacheConfig{Dir: "/tmp/gavel-cache", MaxEntries: 50, Normalize: true, Responses: ResponseCacheConfig{Enabled: true, TTL: "2h"}}}
+
+	got := MergeConfigs(machine, project).Cache
+	want := AnalysisCacheConfig{Backend: "disk", Dir: "/tmp/gavel-cache", TTL: "24h", MaxEntries: 50, Normalize: true, Responses: ResponseCacheConfig{Enabled: true, TTL: "2h"}}
+	if got != want {
+		t.Errorf("merged cache config = %+v, want %+v", got, want)
+	}
+	if got.TTLDuration().Hours() != 24 {
+		t.Errorf("TTLDuration = %v, want 24h", got.TTLDuration())
+	}
+}
+
+func TestMergeConfigs_ParseErrors(t *testing.T) {
+	system := &Config{ParseErrors: ParseErrorConfig{Action: "diagnostic", Retries: 2}}
+	project := &Config{ParseErrors: ParseErrorConfig{Action: "ignore"}}
+
+	merged := MergeConfigs(system, project)
+	if merged.ParseErrors.Action != "ignore" {
+		t.Errorf("expected project action ignore, got %q", merged.ParseErrors.Action)
+	}
+	if merged.ParseErrors.Retries != 2 {
+		t.Errorf("expected unset retries to keep 2, got %d", merged.ParseErrors.Retries)
+	}
+}
+
+func TestConfig_Validate_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pe      ParseErrorConfig
+		wantErr string
+	}{
+		{"default", ParseErrorConfig{}, ""},
+		{"diagnostic", ParseErrorConfig{Action: "diagnostic", Retries: 1}, ""},
+		{"unknown action", ParseErrorConfig{Action: "fail"}, "parse_errors.action"},
+		{"negative retries", ParseErrorConfig{Retries: -1}, "parse_errors.retries"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Provider:    ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+				Persona:     "code-reviewer",
+				ParseErrors: tc.pe,
+			}
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGateCategoriesFromYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gate.yaml")
+	yml := "gate:\n  categories:\n    security:\n      max_errors: 0\n    maintainability:\n      max_errors: unlimited\n      max_warnings: 10\n"
+	if err := os.WriteFile(path, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sec := cfg.Gate.Categories["security"]
+	if sec.MaxErrors == nil || *sec.MaxErrors != 0 || sec.MaxWarnings != nil {
+		t.Errorf("unexpected security threshold: %+v", sec)
+	}
+	maint := cfg.Gate.Categories["maintainability"]
+	if maint.MaxErrors == nil || *maint.MaxErrors != Unlimited || maint.MaxWarnings == nil || *maint.MaxWarnings != 10 {
+		t.Errorf("unexpected maintainability threshold: %+v", maint)
+	}
+	if maint.MaxErrors.Exceeded(1000) || !sec.MaxErrors.Exceeded(1) || sec.MaxErrors.Exceeded(0) {
+		t.Error("Exceeded disagrees with the configured limits")
+	}
+
+	out, err := yaml.Marshal(cfg.Gate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "max_errors: unlimited") {
+		t.Errorf("expected unlimited to round-trip, got:\n%s", out)
+	}
+
+	if err := os.WriteFile(path, []byte("gate:\n  categories:\n    security:\n      max_errors: -1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(path); err == nil {
+		t.Error("expected a negative limit to be rejected")
+	}
+}
+
+func TestMergeConfigs_GateCategories(t *testing.T) {
+	zero, unlimited := Limit(0), Unlimited
+	system := &Config{Gate: GateConfig{Categories: map[string]CategoryThreshold{
+		"security":        {MaxErrors: &zero},
+		"maintainability": {MaxErrors: &zero},
+	}}}
+	project := &Config{Gate: GateConfig{Categories: map[string]CategoryThreshold{
+		"maintainability": {MaxErrors: &unlimited},
+	}}}
+
+	merged := MergeConfigs(system, project)
+	if got := merged.Gate.Categories["maintainability"].MaxErrors; got == nil || *got != Unlimited {
+		t.Errorf("expected project maintainability threshold to win, got %v", got)
+	}
+	if got := merged.Gate.Categories["security"].MaxErrors; got == nil || *got != 0 {
+		t.Errorf("expected system security threshold to be kept, got %v", got)
+	}
+}
+
+func TestConfig_Validate_GateCategories(t *testing.T) {
+	zero := Limit(0)
+	tests := []struct {
+		name    string
+		cats    map[string]CategoryThreshold
+		wantErr string
+	}{
+		{"valid", map[string]CategoryThreshold{"security": {MaxErrors: &zero}}, ""},
+		{"unknown category", map[string]CategoryThreshold{"style": {MaxErrors: &zero}}, "unknown category"},
+		{"no limits", map[string]CategoryThreshold{"security": {}}, "set max_errors or max_warnings"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+				Persona:  "code-reviewer",
+				Gate:     GateConfig{Categories: tc.cats},
+			}
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMergeConfigs_Escalation(t *testing.T) {
+	system := &Config{Escalation: EscalationConfig{Enabled: true, MinLevel: "error", Categories: []string{"security"}}}
+	project := &Config{Escalation: EscalationConfig{MinFindings: 3, Categories: []string{"secret"}}}
+
+	merged := MergeConfigs(system, project)
+	if !merged.Escalation.Enabled || merged.Escalation.MinLevel != "error" || merged.Escalation.MinFindings != 3 {
+		t.Errorf("expected unset fields to keep lower-tier values, got %+v", merged.Escalation)
+	}
+	if len(merged.Escalation.Categories) != 1 || merged.Escalation.Categories[0] != "secret" {
+		t.Errorf("expected project categories to replace system ones, got %v", merged.Escalation.Categories)
+	}
+}
+
+func TestConfig_Validate_Escalation(t *testing.T) {
+	tests := []struct {
+		name    string
+		e       EscalationConfig
+		wantErr string
+	}{
+		{"default", EscalationConfig{}, ""},
+		{"thresholds", EscalationConfig{Enabled: true, MinLevel: "note", MinFindings: 2, Categories: []string{"security", "secret"}}, ""},
+		{"unknown level", EscalationConfig{MinLevel: "critical"}, "escalation.min_level"},
+		{"negative count", EscalationConfig{MinFindings: -1}, "escalation.min_findings"},
+		{"unknown category", EscalationConfig{Categories: []string{"style"}}, "escalation.categories"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Provider:   ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+				Persona:    "code-reviewer",
+				Escalation: tc.e,
+			}
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMergeConfigs_PathOverridesAccumulate(t *testing.T) {
+	system := &Config{PathOverrides: []PathOverride{{Paths: []string{"vendor/**"}, DisableCategories: []string{"maintainability"}}}}
+	project := &Config{PathOverrides: []PathOverride{{Paths: []string{"gen/**"}, DisableRules: []string{"S109"}}}}
+
+	merged := MergeConfigs(system, project)
+	if len(merged.PathOverrides) != 2 {
+		t.Fatalf("expected 2 path overrides, got %d", len(merged.PathOverrides))
+	}
+	if merged.PathOverrides[0].Paths[0] != "vendor/**" || merged.PathOverrides[1].Paths[0] != "gen/**" {
+		t.Errorf("expected overrides in tier order, got %+v", merged.PathOverrides)
+	}
+}
+
+func TestConfig_Validate_PathOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		override PathOverride
+		wantErr  string
+	}{
+		{"valid", PathOverride{Paths: []string{"vendor/**"}, DisableCategories: []string{"maintainability"}}, ""},
+		{"no paths", PathOverride{DisableRules: []string{"S109"}}, "paths must not be empty"},
+		{"nothing disabled", PathOverride{Paths: []string{"vendor/**"}}, "disable_categories or disable_rules"},
+		{"unknown category", PathOverride{Paths: []string{"vendor/**"}, DisableCategories: []string{"style"}}, "unknown category"},
+		{"bad glob", PathOverride{Paths: []string{"vendor/[x/**"}, DisableRules: []string{"S109"}}, "invalid path glob"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Provider:      ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+				Persona:       "code-reviewer",
+				PathOverrides: []PathOverride{tc.override},
+			}
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMergeConfigs_Secrets(t *testing.T) {
+	system := &Config{Secrets: SecretsConfig{EntropyThreshold: 4.5}}
+	project := &Config{Secrets: SecretsConfig{EntropyThreshold: 5, Verify: true}}
+	local := &Config{}
+
+	merged := MergeConfigs(system, project, local)
+	if merged.Secrets.EntropyThreshold != 5 || !merged.Secrets.Verify || merged.Secrets.Disabled {
+		t.Errorf("unexpected secrets config %+v", merged.Secrets)
+	}
+}
+
+func TestConfig_Validate_Secrets(t *testing.T) {
+	cfg := &Config{
+		Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+		Persona:  "code-reviewer",
+		Secrets:  SecretsConfig{EntropyThreshold: -1},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "secrets.entropy_threshold") {
+		t.Errorf("expected entropy threshold error, got %v", err)
+	}
+}
+
 func TestLoadFromFile_WithProvider(t *testing.T) {
 	dir := t.TempDir()
 	path := dir + "/policies.yaml"
@@ -220,6 +674,122 @@ func TestConfig_Validate_OllamaMissingModel(t *testing.T) {
 	}
 }
 
+func TestConfig_LlamaCppAndFastProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/policies.yaml"
+	yaml := `provider:
+  name: anthropic
+  anthropic:
+    model: claude-sonnet
+fast_provider:
+  name: llamacpp
+  llamacpp:
+    base_url: http://localhost:1234/v1
+    context_window: 8192
+    temperature: 0.1
+`
+	os.WriteFile(path, []byte(yaml), 0644)
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast := cfg.FastProvider
+	if fast.Name != "llamacpp" || fast.LlamaCpp.BaseURL != "http://localhost:1234/v1" || fast.LlamaCpp.Temperature == nil || *fast.LlamaCpp.Temperature != 0.1 {
+		t.Errorf("unexpected fast provider %+v", fast)
+	}
+	if got := fast.RequestTokenBudget(); got != 6144 {
+		t.Errorf("expected three quarters of the context window, got %d", got)
+	}
+	fast.MaxRequestTokens = 4000
+	if got := fast.RequestTokenBudget(); got != 4000 {
+		t.Errorf("expected max_request_tokens to take precedence, got %d", got)
+	}
+
+	// The fast provider is validated with the provider, and replaced as a
+	// whole when merged
+	t.Setenv("ANTHROPIC_API_KEY", "key")
+	hot := 3.0
+	cfg.FastProvider.LlamaCpp.Temperature = &hot
+	if err := cfg.ValidateProvider(); err == nil || !strings.Contains(err.Error(), "fast_provider: provider.llamacpp.temperature") {
+		t.Errorf("expected a fast_provider temperature error, got %v", err)
+	}
+	merged := MergeConfigs(cfg, &Config{FastProvider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "qwen"}}})
+	if merged.FastProvider.Name != "ollama" || merged.FastProvider.LlamaCpp.BaseURL != "" {
+		t.Errorf("expected the fast provider to be replaced, got %+v", merged.FastProvider)
+	}
+}
+
+func TestConfig_ProviderFor(t *testing.T) {
+	cfg := &Config{Provider: ProviderConfig{
+		Name:      "ollama",
+		Ollama:    OllamaConfig{Model: "qwen"},
+		Anthropic: AnthropicConfig{Model: "claude-sonnet"},
+	}}
+
+	tests := []struct {
+		policy       Policy
+		wantProvider string
+		wantModel    string
+	}{
+		{Policy{}, "ollama", "qwen"},
+		{Policy{Model: "llama"}, "ollama", "llama"},
+		{Policy{Provider: "anthropic"}, "anthropic", "claude-sonnet"},
+		{Policy{Provider: "anthropic", Model: "claude-opus"}, "anthropic", "claude-opus"},
+	}
+	for _, tt := range tests {
+		pc := cfg.ProviderFor(tt.policy)
+		if pc.Name != tt.wantProvider || pc.ModelName() != tt.wantModel {
+			t.Errorf("ProviderFor(%+v) = %s/%s, want %s/%s", tt.policy, pc.Name, pc.ModelName(), tt.wantProvider, tt.wantModel)
+		}
+	}
+	if cfg.Provider.Ollama.Model != "qwen" {
+		t.Error("ProviderFor must not modify the config")
+	}
+}
+
+func TestConfig_Validate_PolicyProvider(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	base := func(p Policy) *Config {
+		return &Config{
+			Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "qwen"}},
+			Policies: map[string]Policy{"sec": p},
+		}
+	}
+
+	err := base(Policy{Enabled: true, Provider: "gemini"}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "policies.sec.provider must be one of") {
+		t.Errorf("expected an unknown provider error, got: %v", err)
+	}
+
+	err = base(Policy{Enabled: true, Provider: "anthropic", Model: "claude-sonnet"}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "policies.sec: ANTHROPIC_API_KEY") {
+		t.Errorf("expected the routed provider's credentials to be checked, got: %v", err)
+	}
+
+	if err := base(Policy{Enabled: false, Provider: "gemini"}).Validate(); err != nil {
+		t.Errorf("disabled policies should not be checked, got: %v", err)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "key")
+	if err := base(Policy{Enabled: true, Provider: "anthropic", Model: "claude-sonnet"}).Validate(); err != nil {
+		t.Errorf("expected a valid routed policy, got: %v", err)
+	}
+}
+
+func TestMergePolicies_ProviderOverride(t *testing.T) {
+	system := &Config{Policies: map[string]Policy{
+		"sec": {Instruction: "Check security", Enabled: true},
+	}}
+	project := &Config{Policies: map[string]Policy{
+		"sec": {Provider: "anthropic", Model: "claude-sonnet"},
+	}}
+
+	p := MergeConfigs(system, project).Policies["sec"]
+	if !p.Enabled || p.Instruction != "Check security" || p.Provider != "anthropic" || p.Model != "claude-sonnet" {
+		t.Errorf("expected routing added without disabling the policy, got %+v", p)
+	}
+}
+
 func TestConfig_Validate_OpenRouterMissingAPIKey(t *testing.T) {
 	os.Unsetenv("OPENROUTER_API_KEY")
 
@@ -323,6 +893,179 @@ func TestMergeConfigs_ProviderPartialOverride(t *testing.T) {
 	}
 }
 
+func TestMergeConfigs_RequestTimeout(t *testing.T) {
+	system := &Config{Provider: ProviderConfig{Name: "ollama", RequestTimeout: "2m"}}
+	project := &Config{Provider: ProviderConfig{RequestTimeout: "30s"}}
+
+	merged := MergeConfigs(system, project)
+	if merged.Provider.RequestTimeout != "30s" {
+		t.Errorf("expected request_timeout overridden to 30s, got %q", merged.Provider.RequestTimeout)
+	}
+	if got := merged.Provider.RequestTimeoutDuration(); got != 30*time.Second {
+		t.Errorf("expected 30s duration, got %v", got)
+	}
+
+	merged = MergeConfigs(system, &Config{Persona: "security"})
+	if merged.Provider.RequestTimeout != "2m" {
+		t.Errorf("expected request_timeout preserved, got %q", merged.Provider.RequestTimeout)
+	}
+}
+
+func TestConfig_Validate_RequestTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"valid", "45s", false},
+		{"unparseable", "soon", true},
+		{"negative", "-5s", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Provider: ProviderConfig{
+				Name:           "ollama",
+				Ollama:         OllamaConfig{Model: "m"},
+				RequestTimeout: tt.timeout,
+			}}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_MaxRequestTokens(t *testing.T) {
+	cfg := &Config{Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}, MaxRequestTokens: -1}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "max_request_tokens") {
+		t.Errorf("expected a max_request_tokens error, got %v", err)
+	}
+
+	system := &Config{Provider: ProviderConfig{Name: "ollama", MaxRequestTokens: 8000}}
+	if merged := MergeConfigs(system, &Config{Provider: ProviderConfig{MaxRequestTokens: 4000}}); merged.Provider.MaxRequestTokens != 4000 {
+		t.Errorf("expected max_request_tokens overridden to 4000, got %d", merged.Provider.MaxRequestTokens)
+	}
+	if merged := MergeConfigs(system, &Config{Persona: "security"}); merged.Provider.MaxRequestTokens != 8000 {
+		t.Errorf("expected max_request_tokens preserved, got %d", merged.Provider.MaxRequestTokens)
+	}
+}
+
+func TestConfig_BatchTokens(t *testing.T) {
+	cfg := &Config{Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}, BatchTokens: -1}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "batch_tokens") {
+		t.Errorf("expected a batch_tokens error, got %v", err)
+	}
+
+	system := &Config{Provider: ProviderConfig{Name: "ollama", BatchTokens: 4000}}
+	if merged := MergeConfigs(system, &Config{Provider: ProviderConfig{BatchTokens: 2000}}); merged.Provider.BatchTokens != 2000 {
+		t.Errorf("expected batch_tokens overridden to 2000, got %d", merged.Provider.BatchTokens)
+	}
+	if merged := MergeConfigs(system, &Config{Persona: "security"}); merged.Provider.BatchTokens != 4000 {
+		t.Errorf("expected batch_tokens preserved, got %d", merged.Provider.BatchTokens)
+	}
+}
+
+func TestConfig_Pricing(t *testing.T) {
+	cfg := &Config{
+		Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+		Pricing:  map[string]ModelPrice{"anthropic/claude": {InputPer1K: -0.003}},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "pricing.anthropic/claude") {
+		t.Errorf("expected a pricing error, got %v", err)
+	}
+
+	system := &Config{Pricing: map[string]ModelPrice{"anthropic": {InputPer1K: 0.003}, "openai": {InputPer1K: 0.002}}}
+	project := &Config{Pricing: map[string]ModelPrice{"anthropic": {InputPer1K: 0.001}}}
+	merged := MergeConfigs(system, project)
+	if merged.Pricing["anthropic"].InputPer1K != 0.001 || merged.Pricing["openai"].InputPer1K != 0.002 {
+		t.Errorf("merged pricing = %+v", merged.Pricing)
+	}
+}
+
+func TestConfig_Metrics(t *testing.T) {
+	cfg := &Config{
+		Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}},
+		Metrics:  MetricsConfig{Enabled: true, Addr: "9464"},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "metrics.addr") {
+		t.Errorf("expected a metrics.addr error, got %v", err)
+	}
+	if got := (MetricsConfig{}).ListenAddr(); got != DefaultMetricsAddr {
+		t.Errorf("default ListenAddr = %q, want %q", got, DefaultMetricsAddr)
+	}
+
+	system := &Config{Metrics: MetricsConfig{Enabled: true}}
+	project := &Config{Metrics: MetricsConfig{Addr: ":9000"}}
+	merged := MergeConfigs(system, project)
+	if merged.Metrics.Enabled || merged.Metrics.ListenAddr() != ":9000" {
+		t.Errorf("merged metrics = %+v, want disabled on :9000", merged.Metrics)
+	}
+	if merged := MergeConfigs(system, &Config{}); !merged.Metrics.Enabled {
+		t.Error("a config without a metrics section should not disable metrics")
+	}
+}
+
+func TestConfig_ServerAuth(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(`
+server:
+  auth:
+    keys_file: /etc/gavel/keys
+    oidc:
+      issuer: https://login.example.com
+      audience: gavel
+      tenant_claim: org
+    limits:
+      requests_per_minute: 60
+      daily_tokens: 100000
+    tenants:
+      acme:
+        daily_cost_usd: 5
+`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	auth := cfg.Server.Auth
+	if auth.OIDC.TenantClaimName() != "org" || (OIDCConfig{}).TenantClaimName() != "sub" {
+		t.Errorf("unexpected tenant claim %q", auth.OIDC.TenantClaimName())
+	}
+	want := TenantLimits{RequestsPerMinute: 60, DailyTokens: 100000, DailyCostUSD: 5}
+	if got := auth.LimitsFor("acme"); got != want {
+		t.Errorf("LimitsFor(acme) = %+v, want %+v", got, want)
+	}
+	if got := auth.LimitsFor("other"); got != auth.Limits {
+		t.Errorf("LimitsFor(other) = %+v, want the defaults", got)
+	}
+	if !auth.HasQuotas() || (ServerAuthConfig{Limits: TenantLimits{RequestsPerMinute: 1}}).HasQuotas() {
+		t.Error("unexpected HasQuotas")
+	}
+	if err := cfg.ValidateSettings(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for field, bad := range map[string]ServerAuthConfig{
+		"server.auth.limits":        {Limits: TenantLimits{Burst: -1}},
+		"server.auth.tenants.acme":  {Tenants: map[string]TenantLimits{"acme": {DailyTokens: -1}}},
+		"server.auth.oidc.issuer":   {OIDC: OIDCConfig{Issuer: "login.example.com", Audience: "gavel"}},
+		"server.auth.oidc.audience": {OIDC: OIDCConfig{Issuer: "https://x"}},
+		"server.auth.oidc.jwks_url": {OIDC: OIDCConfig{Issuer: "https://x", Audience: "gavel", JWKSURL: "file:///keys"}},
+	} {
+		c := Config{Server: ServerConfig{Auth: bad}}
+		if err := c.ValidateSettings(); err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("expected a %s error, got %v", field, err)
+		}
+	}
+
+	merged := MergeConfigs(&cfg, &Config{Server: ServerConfig{Auth: ServerAuthConfig{
+		IsolateTenants: true,
+		Tenants:        map[string]TenantLimits{"beta": {DailyTokens: 10}},
+	}}})
+	if m := merged.Server.Auth; !m.IsolateTenants || m.KeysFile != "/etc/gavel/keys" || len(m.Tenants) != 2 || m.OIDC.Issuer == "" {
+		t.Errorf("unexpected merged auth %+v", m)
+	}
+}
+
 func TestConfigValidation_Persona(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -364,6 +1107,22 @@ func TestConfigValidation_Persona(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_CustomPersona(t *testing.T) {
+	cfg := &Config{
+		Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m", BaseURL: "http://localhost:11434"}},
+		Persona:  "react-expert",
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an unregistered persona to be rejected")
+	}
+
+	persona.Register([]persona.Persona{{Name: "react-expert", SystemPrompt: "p"}})
+	defer persona.Register(nil)
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected a registered persona to be accepted, got: %v", err)
+	}
+}
+
 func TestSystemDefaults_IncludesPersona(t *testing.T) {
 	cfg := SystemDefaults()
 	if cfg.Persona != "code-reviewer" {
@@ -484,3 +1243,248 @@ func TestCalibrationConfigFromYAML(t *testing.T) {
 		t.Errorf("batch_size = %d, want 50", cfg.Calibration.Upload.BatchSize)
 	}
 }
+
+func TestConfig_Validate_RateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit RateLimitConfig
+		wantErr   bool
+	}{
+		{"empty", RateLimitConfig{}, false},
+		{"valid", RateLimitConfig{RequestsPerMinute: 60, TokensPerMinute: 100000, MaxRetries: 3, InitialBackoff: "500ms", MaxBackoff: "20s"}, false},
+		{"negative rpm", RateLimitConfig{RequestsPerMinute: -1}, true},
+		{"negative tpm", RateLimitConfig{TokensPerMinute: -1}, true},
+		{"negative retries", RateLimitConfig{MaxRetries: -1}, true},
+		{"unparseable backoff", RateLimitConfig{InitialBackoff: "soon"}, true},
+		{"zero max backoff", RateLimitConfig{MaxBackoff: "0s"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Provider: ProviderConfig{
+				Name:      "ollama",
+				Ollama:    OllamaConfig{Model: "m"},
+				RateLimit: tt.rateLimit,
+			}}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeConfigs_RateLimit(t *testing.T) {
+	system := &Config{Provider: ProviderConfig{Name: "ollama", RateLimit: RateLimitConfig{RequestsPerMinute: 60, MaxRetries: 3}}}
+	project := &Config{Provider: ProviderConfig{RateLimit: RateLimitConfig{MaxRetries: 5, MaxBackoff: "10s"}}}
+	merged := MergeConfigs(system, project)
+
+	want := RateLimitConfig{RequestsPerMinute: 60, MaxRetries: 5, MaxBackoff: "10s"}
+	if merged.Provider.RateLimit != want {
+		t.Errorf("merged rate_limit = %+v, want %+v", merged.Provider.RateLimit, want)
+	}
+	if got := merged.Provider.RateLimit.InitialBackoffDuration(); got != time.Second {
+		t.Errorf("expected default initial backoff of 1s, got %v", got)
+	}
+	if got := merged.Provider.RateLimit.MaxBackoffDuration(); got != 10*time.Second {
+		t.Errorf("expected max backoff of 10s, got %v", got)
+	}
+}
+
+func TestMergeConfigs_Context(t *testing.T) {
+	machine := &Config{Context: ContextConfig{MaxBytes: 32768, EmbeddingModel: "nomic-embed-text"}}
+	project := &Config{Context: ContextConfig{MaxTokens: 2000}}
+
+	got := MergeConfigs(machine, project).Context
+	want := ContextConfig{MaxBytes: 32768, MaxTokens: 2000, EmbeddingModel: "nomic-embed-text"}
+	if got != want {
+		t.Errorf("merged context config = %+v, want %+v", got, want)
+	}
+	if got.Budget() != 8000 {
+		t.Errorf("Budget = %d, want the smaller max_tokens budget of 8000", got.Budget())
+	}
+	if (ContextConfig{MaxBytes: 1000, MaxTokens: 2000}).Budget() != 1000 {
+		t.Error("expected max_bytes to apply when it is smaller")
+	}
+	if (ContextConfig{}).Budget() != 0 {
+		t.Error("expected a zero budget to select the default")
+	}
+
+	cfg := &Config{Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}}, Context: ContextConfig{MaxTokens: -1}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "context.max_tokens") {
+		t.Errorf("expected a negative max_tokens to be rejected, got %v", err)
+	}
+}
+
+func TestConfig_MinConfidenceFor(t *testing.T) {
+	machine := &Config{Confidence: ConfidenceConfig{Min: 0.6, Rules: map[string]float64{"S1135": 0.9}}}
+	project := &Config{
+		Confidence: ConfidenceConfig{Rules: map[string]float64{"S2068": 0.3}},
+		Policies:   map[string]Policy{"security": {Description: "Security", Enabled: true}},
+	}
+	local := &Config{Policies: map[string]Policy{"security": {MinConfidence: 0.5}}}
+
+	cfg := MergeConfigs(machine, project, local)
+	if !cfg.Policies["security"].Enabled {
+		t.Error("setting only min_confidence must not disable a policy")
+	}
+	for rule, want := range map[string]float64{"S1135": 0.9, "S2068": 0.3, "security": 0.5, "other": 0.6} {
+		if got := cfg.MinConfidenceFor(rule); got != want {
+			t.Errorf("MinConfidenceFor(%s) = %v, want %v", rule, got, want)
+		}
+	}
+
+	cfg.Provider = ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}}
+	cfg.Persona = "code-reviewer"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	cfg.Confidence.Rules["S1135"] = 2
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "confidence.rules.S1135") {
+		t.Errorf("expected an out-of-range rule minimum to be rejected, got %v", err)
+	}
+}
+
+func TestMergeConfigs_Bundles(t *testing.T) {
+	machine := &Config{Bundles: []BundleConfig{{Source: "oci://ghcr.io/acme/policies:v1"}, {Source: "git+https://example.com/p.git#main"}}}
+	project := &Config{Bundles: []BundleConfig{{Source: "oci://ghcr.io/acme/policies:v1", Digest: "sha256:abc"}}}
+
+	got := MergeConfigs(machine, project).Bundles
+	if len(got) != 2 || got[0].Digest != "sha256:abc" || got[1].Source != "git+https://example.com/p.git#main" {
+		t.Errorf("merged bundles = %+v", got)
+	}
+
+	cfg := &Config{Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}}, Bundles: []BundleConfig{{Source: "https://example.com/p.tar.gz"}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "bundles[0].source") {
+		t.Errorf("expected an unknown source scheme to be rejected, got %v", err)
+	}
+	cfg.Bundles[0] = BundleConfig{Source: "oci://ghcr.io/acme/policies:v1", Digest: "md5:abc"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "bundles[0].digest") {
+		t.Errorf("expected a non-sha256 digest to be rejected, got %v", err)
+	}
+}
+
+func TestPinBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	original := `# Project settings
+persona: code-reviewer # reviewer persona
+
+bundles:
+  # Shared org policies
+  - source: oci://ghcr.io/acme/policies:v1
+    digest: sha256:old
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PinBundle(path, BundleConfig{Source: "oci://ghcr.io/acme/policies:v1", Digest: "sha256:new"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PinBundle(path, BundleConfig{Source: "git+https://example.com/p.git#v2", Digest: "sha256:git"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# Project settings", "# reviewer persona", "# Shared org policies", "digest: sha256:new", "source: git+https://example.com/p.git#v2"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in pinned config:\n%s", want, data)
+		}
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Bundles) != 2 || cfg.Bundles[0].Digest != "sha256:new" || cfg.Persona != "code-reviewer" {
+		t.Errorf("pinned config = %+v", cfg)
+	}
+
+	missing := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := PinBundle(missing, BundleConfig{Source: "oci://ghcr.io/acme/policies:v1"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := LoadFromFile(missing); err != nil || len(cfg.Bundles) != 1 {
+		t.Errorf("expected the missing file to be created, got %+v, %v", cfg, err)
+	}
+}
+
+func TestSetPolicyEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	original := `# Project settings
+policies:
+  # Keep functions small
+  function-length:
+    severity: note
+    enabled: true # on for now
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetPolicyEnabled(path, "function-length", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPolicyEnabled(path, "error-handling", true); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# Project settings", "# Keep functions small", "enabled: false # on for now", "severity: note"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in edited config:\n%s", want, data)
+		}
+	}
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Policies["function-length"].Enabled || !cfg.Policies["error-handling"].Enabled {
+		t.Errorf("edited policies = %+v", cfg.Policies)
+	}
+
+	missing := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := SetPolicyEnabled(missing, "error-handling", false); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := LoadFromFile(missing); err != nil || len(cfg.Policies) != 1 {
+		t.Errorf("expected the missing file to be created, got %+v, %v", cfg, err)
+	}
+
+	if err := os.WriteFile(path, []byte("policies: [a, b]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPolicyEnabled(path, "a", true); err == nil {
+		t.Error("expected an error for a policies list")
+	}
+}
+
+func TestConfig_Verdict(t *testing.T) {
+	var machine Config
+	if err := yaml.Unmarshal([]byte("verdict:\n  policy: strict\n  max_warnings: unlimited\n"), &machine); err != nil {
+		t.Fatal(err)
+	}
+	half := 0.5
+	project := &Config{Verdict: VerdictConfig{RejectCWEs: []string{"CWE-89"}, ReviewBelow: &half}}
+
+	got := MergeConfigs(&machine, project).Verdict
+	if got.Policy != "strict" || *got.MaxWarnings != Unlimited || len(got.RejectCWEs) != 1 || *got.ReviewBelow != 0.5 {
+		t.Errorf("merged verdict config = %+v", got)
+	}
+
+	for _, tc := range []struct {
+		verdict VerdictConfig
+		want    string
+	}{
+		{VerdictConfig{Policy: "lenient"}, "verdict.policy"},
+		{VerdictConfig{RejectCWEs: []string{"89"}}, "verdict.reject_cwes"},
+		{VerdictConfig{ReviewBelow: new(float64)}, ""},
+	} {
+		cfg := &Config{Provider: ProviderConfig{Name: "ollama", Ollama: OllamaConfig{Model: "m"}}, Verdict: tc.verdict}
+		err := cfg.Validate()
+		if tc.want == "" && err != nil {
+			t.Errorf("expected %+v to be valid, got %v", tc.verdict, err)
+		}
+		if tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)) {
+			t.Errorf("expected %+v to be rejected with %s, got %v", tc.verdict, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PinBundle records b in the bundles list of the config file at path,
+// replacing the entry with the same source, and leaves the rest of the
+// file, including comments, as it was. The file is created if missing.
+func PinBundle(path string, b BundleConfig) error {
+	doc, root, err := readYAML(path)
+	if err != nil {
+		return err
+	}
+
+	var bundles *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "bundles" {
+			bundles = root.Content[i+1]
+		}
+	}
+	if bundles == nil {
+		bundles = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "bundles"}, bundles)
+	} else if bundles.Kind != yaml.SequenceNode {
+		// An empty "bundles:" key
+		*bundles = yaml.Node{Kind: yaml.SequenceNode}
+	}
+
+	var entry yaml.Node
+	if err := entry.Encode(b); err != nil {
+		return err
+	}
+	for i, item := range bundles.Content {
+		var existing BundleConfig
+		if item.Decode(&existing) == nil && existing.Source == b.Source {
+			entry.HeadComment = item.HeadComment
+			entry.LineComment = item.LineComment
+			bundles.Content[i] = &entry
+			return writeYAML(path, doc)
+		}
+	}
+	bundles.Content = append(bundles.Content, &entry)
+	return writeYAML(path, doc)
+}
+
+// SetPolicyEnabled sets policies.<name>.enabled in the config file at path,
+// adding the entry if the file does not define the policy, and leaves the
+// rest of the file, including comments, as it was. The file is created if
+// missing.
+func SetPolicyEnabled(path, name string, enabled bool) error {
+	doc, root, err := readYAML(path)
+	if err != nil {
+		return err
+	}
+
+	policies := mappingValue(root, "policies")
+	if policies == nil {
+		return fmt.Errorf("config file %s: policies is not a mapping", path)
+	}
+	policy := mappingValue(policies, name)
+	if policy == nil {
+		return fmt.Errorf("config file %s: policy %s is not a mapping", path, name)
+	}
+	value := strconv.FormatBool(enabled)
+	for i := 0; i+1 < len(policy.Content); i += 2 {
+		if policy.Content[i].Value == "enabled" {
+			v := policy.Content[i+1]
+			v.Kind, v.Tag, v.Value, v.Style = yaml.ScalarNode, "!!bool", value, 0
+			return writeYAML(path, doc)
+		}
+	}
+	policy.Content = append(policy.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "enabled"},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value})
+	return writeYAML(path, doc)
+}
+
+// mappingValue returns the mapping under key in m, adding an empty one when
+// key is missing or empty. It returns nil when key holds something else.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		v := m.Content[i+1]
+		if v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+			*v = yaml.Node{Kind: yaml.MappingNode}
+		}
+		if v.Kind != yaml.MappingNode {
+			return nil
+		}
+		return v
+	}
+	v := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+// readYAML parses the config file at path into a node tree, returning the
+// document and its top-level mapping. A missing file yields an empty one.
+func readYAML(path string) (*yaml.Node, *yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config file %s: top level is not a mapping", path)
+	}
+	return &doc, root, nil
+}
+
+func writeYAML(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
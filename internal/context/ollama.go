@@ -0,0 +1,63 @@
+package context
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OllamaEmbedder embeds texts with a local Ollama embedding model, such as
+// nomic-embed-text.
+type OllamaEmbedder struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewOllamaEmbedder creates an embedder for the Ollama server at baseURL.
+// The OpenAI-compatible /v1 suffix used by provider.ollama.base_url is
+// accepted and stripped, since embeddings use Ollama's native API.
+func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+	return &OllamaEmbedder{
+		baseURL: baseURL,
+		model:   model,
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Embed implements Embedder using POST /api/embed.
+func (e *OllamaEmbedder) Embed(ctx stdcontext.Context, texts []string) ([][]float64, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama embed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("ollama embed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("ollama embed: decoding response: %w", err)
+	}
+	return out.Embeddings, nil
+}
@@ -0,0 +1,329 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// DefaultMaxBytes is the context budget per artifact when none is configured.
+const DefaultMaxBytes = 64 * 1024
+
+// embedSampleBytes is how much of each file is embedded for ranking. The
+// start of a file is usually enough to tell what it is about.
+const embedSampleBytes = 8 * 1024
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are.
+type Embedder interface {
+	Embed(ctx stdcontext.Context, texts []string) ([][]float64, error)
+}
+
+// Resolver selects the additional context files sent with an artifact. The
+// files matched by the selectors are ranked by relevance to the artifact
+// and added in that order until the budget is spent; the file that crosses
+// the budget is truncated at a line boundary and the rest are dropped, so
+// the same inputs always produce the same context. It is safe for
+// concurrent use.
+type Resolver struct {
+	loader   *Loader
+	maxBytes int
+	embedder Embedder
+
+	mu       sync.Mutex
+	patterns map[string][]ContextFile // Loaded files by selector pattern
+	vectors  map[string][]float64     // Embeddings by file content
+}
+
+// ResolverOption configures a Resolver.
+type ResolverOption func(*Resolver)
+
+// WithMaxBytes caps the context sent with each artifact. Zero or less uses
+// DefaultMaxBytes.
+func WithMaxBytes(n int) ResolverOption {
+	return func(r *Resolver) {
+		if n > 0 {
+			r.maxBytes = n
+		}
+	}
+}
+
+// WithEmbedder breaks ties between equally close files by the similarity of
+// their content to the artifact's. Without one, ties are broken by path.
+func WithEmbedder(e Embedder) ResolverOption {
+	return func(r *Resolver) {
+		r.embedder = e
+	}
+}
+
+// NewResolver creates a Resolver that loads context files relative to
+// baseDir.
+func NewResolver(baseDir string, opts ...ResolverOption) *Resolver {
+	r := &Resolver{
+		loader:   NewLoader(baseDir),
+		maxBytes: DefaultMaxBytes,
+		patterns: make(map[string][]ContextFile),
+		vectors:  make(map[string][]float64),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// SelectedFile is a context file chosen for an artifact.
+type SelectedFile struct {
+	ContextFile
+	Size      int  // Bytes in the file before truncation
+	Truncated bool // Content was cut to fit the budget
+}
+
+// Resolution is the additional context chosen for one artifact.
+type Resolution struct {
+	Files   []SelectedFile // In rank order
+	Dropped []string       // Matching files left out because the budget was spent
+	Bytes   int            // Content bytes sent
+}
+
+// Text formats the selected files for the prompt, or returns "" when there
+// are none.
+func (res Resolution) Text() string {
+	files := make([]ContextFile, len(res.Files))
+	for i, f := range res.Files {
+		files[i] = f.ContextFile
+	}
+	return FormatContext(files)
+}
+
+// Truncated reports whether any selected file was cut short.
+func (res Resolution) Truncated() bool {
+	for _, f := range res.Files {
+		if f.Truncated {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the context for the artifact at artifactPath (relative to
+// the base directory) and its content, from the files matched by
+// selectors. The artifact itself is never included. Embedding failures
+// only lose the similarity ranking and are logged.
+func (r *Resolver) Resolve(ctx stdcontext.Context, artifactPath, artifactContent string, selectors []config.ContextSelector) (Resolution, error) {
+	seen := make(map[string]bool)
+	var candidates []ContextFile
+	for _, sel := range selectors {
+		if sel.OnlyFor != "" {
+			matched, err := filepath.Match(sel.OnlyFor, filepath.Base(artifactPath))
+			if err != nil {
+				return Resolution{}, fmt.Errorf("invalid only_for pattern %q: %w", sel.OnlyFor, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		files, err := r.load(sel.Pattern)
+		if err != nil {
+			return Resolution{}, err
+		}
+		for _, f := range files {
+			if seen[f.Path] || filepath.Clean(f.Path) == filepath.Clean(artifactPath) {
+				continue
+			}
+			seen[f.Path] = true
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 0 {
+		return Resolution{}, nil
+	}
+
+	r.rank(ctx, artifactPath, artifactContent, candidates)
+	return r.fit(candidates), nil
+}
+
+// load returns the files matching pattern, reading them once per Resolver.
+func (r *Resolver) load(pattern string) ([]ContextFile, error) {
+	r.mu.Lock()
+	files, ok := r.patterns[pattern]
+	r.mu.Unlock()
+	if ok {
+		return files, nil
+	}
+	files, err := r.loader.loadPattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("loading pattern %q: %w", pattern, err)
+	}
+	r.mu.Lock()
+	r.patterns[pattern] = files
+	r.mu.Unlock()
+	return files, nil
+}
+
+// rank orders candidates by how many leading directories they share with
+// the artifact, so files in its own package come first, then by embedding
+// similarity when an embedder is set, then by path.
+func (r *Resolver) rank(ctx stdcontext.Context, artifactPath, artifactContent string, candidates []ContextFile) {
+	proximity := make(map[string]int, len(candidates))
+	for _, c := range candidates {
+		proximity[c.Path] = sharedDirs(artifactPath, c.Path)
+	}
+	similarity := r.similarities(ctx, artifactContent, candidates)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].Path, candidates[j].Path
+		if proximity[a] != proximity[b] {
+			return proximity[a] > proximity[b]
+		}
+		if similarity[a] != similarity[b] {
+			return similarity[a] > similarity[b]
+		}
+		return a < b
+	})
+}
+
+// similarities returns the cosine similarity of each candidate to the
+// artifact, or nil without an embedder or when embedding fails.
+func (r *Resolver) similarities(ctx stdcontext.Context, artifactContent string, candidates []ContextFile) map[string]float64 {
+	if r.embedder == nil {
+		return nil
+	}
+	texts := []string{artifactContent}
+	for _, c := range candidates {
+		texts = append(texts, c.Content)
+	}
+	vectors, err := r.embed(ctx, texts)
+	if err != nil {
+		slog.Warn("embedding context files failed; ranking by path only", "err", err)
+		return nil
+	}
+	sims := make(map[string]float64, len(candidates))
+	for i, c := range candidates {
+		sims[c.Path] = cosine(vectors[0], vectors[i+1])
+	}
+	return sims
+}
+
+// embed returns a vector per text, embedding only texts not seen before.
+func (r *Resolver) embed(ctx stdcontext.Context, texts []string) ([][]float64, error) {
+	samples := make([]string, len(texts))
+	for i, t := range texts {
+		if len(t) > embedSampleBytes {
+			t = t[:embedSampleBytes]
+		}
+		samples[i] = t
+	}
+
+	r.mu.Lock()
+	var missing []string
+	for _, s := range samples {
+		if _, ok := r.vectors[s]; !ok {
+			missing = append(missing, s)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(missing) > 0 {
+		vectors, err := r.embedder.Embed(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != len(missing) {
+			return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(missing))
+		}
+		r.mu.Lock()
+		for i, s := range missing {
+			r.vectors[s] = vectors[i]
+		}
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([][]float64, len(samples))
+	for i, s := range samples {
+		out[i] = r.vectors[s]
+	}
+	return out, nil
+}
+
+// fit takes ranked candidates until the budget is spent.
+func (r *Resolver) fit(ranked []ContextFile) Resolution {
+	var res Resolution
+	for _, c := range ranked {
+		remaining := r.maxBytes - res.Bytes
+		if remaining <= 0 {
+			res.Dropped = append(res.Dropped, c.Path)
+			continue
+		}
+		f := SelectedFile{ContextFile: c, Size: len(c.Content)}
+		if len(c.Content) > remaining {
+			f.Content = truncateLines(c.Content, remaining-truncationMarkerBytes)
+			f.Truncated = true
+			if f.Content == "" {
+				res.Dropped = append(res.Dropped, c.Path)
+				continue
+			}
+		}
+		res.Files = append(res.Files, f)
+		res.Bytes += len(f.Content)
+	}
+	return res
+}
+
+// truncationMarkerBytes is room left for the note truncateLines appends,
+// so a truncated file stays within the budget.
+const truncationMarkerBytes = 64
+
+// truncateLines cuts s to at most n bytes, ending at a line boundary, and
+// marks the cut. It returns "" when not even one line fits.
+func truncateLines(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	i := strings.LastIndexByte(s[:n], '\n')
+	if i < 0 {
+		return ""
+	}
+	cut := s[:i+1]
+	return cut + fmt.Sprintf("... (truncated: %d of %d bytes)\n", len(cut), len(s))
+}
+
+// sharedDirs scores how close two paths are: the number of leading
+// directories they share, plus one when they are in the same directory so
+// a file's own package outranks its subpackages.
+func sharedDirs(a, b string) int {
+	dirA, dirB := filepath.ToSlash(filepath.Dir(filepath.Clean(a))), filepath.ToSlash(filepath.Dir(filepath.Clean(b)))
+	da, db := strings.Split(dirA, "/"), strings.Split(dirB, "/")
+	n := 0
+	for n < len(da) && n < len(db) && da[n] == db[n] {
+		n++
+	}
+	if dirA == dirB {
+		n++
+	}
+	return n
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,196 @@
+package context
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func paths(files []SelectedFile) []string {
+	var out []string
+	for _, f := range files {
+		out = append(out, f.Path)
+	}
+	return out
+}
+
+func TestResolver_RanksByProximity(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/handlers/users.go":     "package handlers\n",
+		"api/handlers/types.go":     "package handlers\n",
+		"api/handlers/sub/inner.go": "package sub\n",
+		"api/schema.go":             "package api\n",
+		"docs/guide.go":             "package docs\n",
+	})
+	r := NewResolver(dir)
+	selectors := []config.ContextSelector{{Pattern: "docs/*.go"}, {Pattern: "api/*.go"}, {Pattern: "api/handlers/*.go"}, {Pattern: "api/handlers/sub/*.go"}}
+
+	res, err := r.Resolve(stdcontext.Background(), "api/handlers/users.go", "package handlers\n", selectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "api/handlers/types.go,api/handlers/sub/inner.go,api/schema.go,docs/guide.go"
+	if got := strings.Join(paths(res.Files), ","); got != want {
+		t.Errorf("ranked files = %s, want %s (the artifact itself excluded)", got, want)
+	}
+}
+
+func TestResolver_BudgetTruncatesDeterministically(t *testing.T) {
+	dir := t.TempDir()
+	big := strings.Repeat("0123456789abcdef0123456789abcdef\n", 10) // 330 bytes
+	writeFiles(t, dir, map[string]string{
+		"pkg/a.txt": big,
+		"pkg/b.txt": big,
+		"pkg/c.txt": big,
+	})
+	r := NewResolver(dir, WithMaxBytes(500))
+	selectors := []config.ContextSelector{{Pattern: "pkg/*.txt"}, {Pattern: "pkg/a.txt"}}
+
+	res, err := r.Resolve(stdcontext.Background(), "pkg/main.go", "", selectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(paths(res.Files), ","); got != "pkg/a.txt,pkg/b.txt" {
+		t.Fatalf("selected files = %s", got)
+	}
+	if res.Files[0].Truncated || !res.Files[1].Truncated || !res.Truncated() {
+		t.Errorf("expected only the file crossing the budget to be truncated: %+v", res.Files)
+	}
+	if res.Bytes > 500 {
+		t.Errorf("context of %d bytes exceeds the 500-byte budget", res.Bytes)
+	}
+	if !strings.HasSuffix(res.Files[1].Content, "... (truncated: 99 of 330 bytes)\n") {
+		t.Errorf("unexpected truncated content %q", res.Files[1].Content)
+	}
+	if len(res.Dropped) != 1 || res.Dropped[0] != "pkg/c.txt" {
+		t.Errorf("dropped = %v, want [pkg/c.txt]", res.Dropped)
+	}
+
+	again, _ := r.Resolve(stdcontext.Background(), "pkg/main.go", "", selectors)
+	if again.Text() != res.Text() {
+		t.Error("expected the same inputs to produce the same context")
+	}
+}
+
+func TestResolver_OnlyFor(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"spec.yaml": "openapi: 3.0\n"})
+	r := NewResolver(dir)
+	selectors := []config.ContextSelector{{Pattern: "spec.yaml", OnlyFor: "*.go"}}
+
+	if res, _ := r.Resolve(stdcontext.Background(), "main.py", "", selectors); len(res.Files) != 0 {
+		t.Error("expected only_for to skip non-matching artifacts")
+	}
+	if res, _ := r.Resolve(stdcontext.Background(), "main.go", "", selectors); len(res.Files) != 1 || res.Text() == "" {
+		t.Errorf("expected spec.yaml for a Go file, got %+v", res)
+	}
+}
+
+type fakeEmbedder struct {
+	vectors map[string][]float64
+	calls   int
+	err     error
+}
+
+func (f *fakeEmbedder) Embed(ctx stdcontext.Context, texts []string) ([][]float64, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([][]float64, len(texts))
+	for i, t := range texts {
+		out[i] = f.vectors[t]
+	}
+	return out, nil
+}
+
+func TestResolver_EmbeddingBreaksTies(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"docs/auth.md":    "authentication",
+		"docs/billing.md": "billing",
+	})
+	emb := &fakeEmbedder{vectors: map[string][]float64{
+		"login handler":  {1, 0},
+		"authentication": {0.9, 0.1},
+		"billing":        {0, 1},
+	}}
+	selectors := []config.ContextSelector{{Pattern: "docs/*.md"}}
+
+	r := NewResolver(dir, WithEmbedder(emb))
+	res, err := r.Resolve(stdcontext.Background(), "billing/zz.go", "login handler", selectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(paths(res.Files), ","); got != "docs/auth.md,docs/billing.md" {
+		t.Errorf("ranked files = %s, want auth first by similarity", got)
+	}
+	r.Resolve(stdcontext.Background(), "billing/zz.go", "login handler", selectors)
+	if emb.calls != 1 {
+		t.Errorf("expected embeddings to be reused, got %d calls", emb.calls)
+	}
+
+	failing := NewResolver(dir, WithEmbedder(&fakeEmbedder{err: errors.New("connection refused")}))
+	res, err = failing.Resolve(stdcontext.Background(), "billing/zz.go", "login handler", selectors)
+	if err != nil {
+		t.Fatalf("embedding failures should not fail resolution: %v", err)
+	}
+	if got := strings.Join(paths(res.Files), ","); got != "docs/auth.md,docs/billing.md" {
+		t.Errorf("expected path order without embeddings, got %s", got)
+	}
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": [][]float64{{1, 2}, {3, 4}}})
+	}))
+	defer srv.Close()
+
+	e := NewOllamaEmbedder(srv.URL+"/v1", "nomic-embed-text")
+	vectors, err := e.Embed(stdcontext.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 2 || vectors[1][1] != 4 {
+		t.Errorf("unexpected vectors %v", vectors)
+	}
+
+	if _, err := NewOllamaEmbedder(srv.URL, "other").Embed(stdcontext.Background(), []string{"a"}); err == nil {
+		t.Error("expected an error status to be reported")
+	}
+}
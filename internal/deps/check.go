@@ -0,0 +1,159 @@
+package deps
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// Violation returns why d breaks c, for use in a finding's message, or ""
+// when it does not. See rules.DependencyConstraint for the semantics.
+func Violation(c rules.DependencyConstraint, d Dependency) string {
+	if c.Ecosystem != "" && !strings.EqualFold(c.Ecosystem, string(d.Ecosystem)) {
+		return ""
+	}
+	if len(c.Packages) > 0 && !matchesAny(c.Packages, d) {
+		return ""
+	}
+	label := d.Name
+	if d.Version != "" {
+		label += " " + d.Version
+	}
+
+	if c.MinVersion == "" && len(c.Licenses) == 0 {
+		return label + " is banned"
+	}
+	if c.MinVersion != "" && d.Version != "" && CompareVersions(d.Version, c.MinVersion) < 0 {
+		return fmt.Sprintf("%s is older than %s", label, c.MinVersion)
+	}
+	for _, license := range d.Licenses {
+		for _, id := range licenseIDs(license) {
+			for _, pattern := range c.Licenses {
+				if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(id)); ok {
+					return fmt.Sprintf("%s is licensed under %s", label, license)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// matchesAny reports whether d's name matches one of the patterns. PyPI
+// names are compared in their normalized form, since pip treats "Foo_Bar"
+// and "foo-bar" as the same package.
+func matchesAny(patterns []string, d Dependency) bool {
+	name := d.Name
+	if d.Ecosystem == EcosystemPyPI {
+		name = normalizePyPI(name)
+	}
+	for _, p := range patterns {
+		if d.Ecosystem == EcosystemPyPI {
+			p = normalizePyPI(p)
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+func normalizePyPI(name string) string {
+	return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// licenseIDs splits an SPDX expression such as "(MIT OR GPL-3.0-only)"
+// into its license IDs. A plain license name is returned as is.
+func licenseIDs(expression string) []string {
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression))
+	hasOperator := false
+	for _, f := range fields {
+		switch f {
+		case "AND", "OR", "WITH":
+			hasOperator = true
+		}
+	}
+	if !hasOperator {
+		return []string{strings.TrimSpace(expression)}
+	}
+	var ids []string
+	for i, f := range fields {
+		switch {
+		case f == "AND" || f == "OR" || f == "WITH":
+		case i > 0 && fields[i-1] == "WITH":
+			// an exception, not a license
+		default:
+			ids = append(ids, f)
+		}
+	}
+	return ids
+}
+
+// CompareVersions compares two dotted versions numerically, returning -1,
+// 0 or 1. A leading "v" is ignored, so Go and npm versions compare alike,
+// and a version with a pre-release suffix ("1.2.0-rc1", "1.2.0rc1") sorts
+// before the release. Build metadata after "+" is ignored.
+func CompareVersions(a, b string) int {
+	an, apre := splitVersion(a)
+	bn, bpre := splitVersion(b)
+	for i := 0; i < len(an) || i < len(bn); i++ {
+		var x, y int
+		if i < len(an) {
+			x = an[i]
+		}
+		if i < len(bn) {
+			y = bn[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	case apre < bpre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitVersion returns a version's numeric components and its pre-release
+// suffix, if any.
+func splitVersion(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	var nums []int
+	for v != "" {
+		end := 0
+		for end < len(v) && v[end] >= '0' && v[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, _ := strconv.Atoi(v[:end])
+		nums = append(nums, n)
+		v = v[end:]
+		if len(v) > 1 && v[0] == '.' && v[1] >= '0' && v[1] <= '9' {
+			v = v[1:]
+			continue
+		}
+		break
+	}
+	return nums, strings.TrimLeft(v, "-.")
+}
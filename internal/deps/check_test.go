@@ -0,0 +1,73 @@
+package deps
+
+import (
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+func TestViolation(t *testing.T) {
+	crypto := Dependency{Name: "golang.org/x/crypto", Ecosystem: EcosystemGo, Version: "v0.14.0"}
+	gpl := Dependency{Name: "readline", Ecosystem: EcosystemNPM, Version: "1.3.0", Licenses: []string{"(MIT OR GPL-3.0-only)"}}
+	pyyaml := Dependency{Name: "PyYAML", Ecosystem: EcosystemPyPI, Version: "5.3"}
+
+	for _, tc := range []struct {
+		name string
+		c    rules.DependencyConstraint
+		d    Dependency
+		want string
+	}{
+		{"banned", rules.DependencyConstraint{Packages: []string{"golang.org/x/*"}}, crypto, "golang.org/x/crypto v0.14.0 is banned"},
+		{"other package", rules.DependencyConstraint{Packages: []string{"github.com/*"}}, crypto, ""},
+		{"other ecosystem", rules.DependencyConstraint{Ecosystem: "npm", Packages: []string{"*"}}, crypto, ""},
+		{"too old", rules.DependencyConstraint{Packages: []string{"golang.org/x/crypto"}, MinVersion: "0.17.0"}, crypto, "golang.org/x/crypto v0.14.0 is older than 0.17.0"},
+		{"new enough", rules.DependencyConstraint{Packages: []string{"golang.org/x/crypto"}, MinVersion: "0.14"}, crypto, ""},
+		{"unpinned", rules.DependencyConstraint{Packages: []string{"*"}, MinVersion: "1.0"}, Dependency{Name: "numpy", Ecosystem: EcosystemPyPI}, ""},
+		{"denied license", rules.DependencyConstraint{Licenses: []string{"gpl-*"}}, gpl, "readline 1.3.0 is licensed under (MIT OR GPL-3.0-only)"},
+		{"allowed license", rules.DependencyConstraint{Licenses: []string{"AGPL-*"}}, gpl, ""},
+		{"pypi normalized", rules.DependencyConstraint{Packages: []string{"pyyaml"}, MinVersion: "5.4"}, pyyaml, "PyYAML 5.3 is older than 5.4"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Violation(tc.c, tc.d); got != tc.want {
+				t.Errorf("Violation = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLicenseIDs(t *testing.T) {
+	got := licenseIDs("(Apache-2.0 WITH LLVM-exception OR MIT) AND BSD-2-Clause")
+	want := []string{"Apache-2.0", "MIT", "BSD-2-Clause"}
+	if len(got) != len(want) {
+		t.Fatalf("licenseIDs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("licenseIDs = %v, want %v", got, want)
+		}
+	}
+	if got := licenseIDs("Apache License 2.0"); len(got) != 1 || got[0] != "Apache License 2.0" {
+		t.Errorf("a license name should be kept whole, got %v", got)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"0.14.0", "0.17.0", -1},
+		{"1.2.0-rc1", "1.2.0", -1},
+		{"1.2.0rc1", "1.2.0", -1},
+		{"1.2.0", "1.2.0-beta", 1},
+		{"v0.0.0-20230101000000-abcdef", "0.0.1", -1},
+		{"1.2.3+build5", "1.2.3", 0},
+	} {
+		if got := CompareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// cdxBOM is the part of a CycloneDX JSON document Gavel reads.
+type cdxBOM struct {
+	BOMFormat  string         `json:"bomFormat"`
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Group      string         `json:"group"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version"`
+	PURL       string         `json:"purl"`
+	Licenses   []cdxLicense   `json:"licenses"`
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxLicense struct {
+	License *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"license"`
+	Expression string `json:"expression"`
+}
+
+// IsCycloneDX reports whether content is a CycloneDX JSON document.
+func IsCycloneDX(content string) bool {
+	var head struct {
+		BOMFormat string `json:"bomFormat"`
+	}
+	return json.Unmarshal([]byte(content), &head) == nil && head.BOMFormat == "CycloneDX"
+}
+
+// ParseCycloneDX returns the components of a CycloneDX JSON SBOM, nested
+// components included. A component's name and ecosystem come from its
+// package URL when it has one, so "pkg:golang/golang.org/x/net@v0.1.0" is
+// the Go module golang.org/x/net. Each dependency's line is where its
+// package URL, or failing that its name, appears in the document.
+func ParseCycloneDX(content string) ([]Dependency, error) {
+	var bom cdxBOM
+	if err := json.Unmarshal([]byte(content), &bom); err != nil {
+		return nil, fmt.Errorf("parsing CycloneDX SBOM: %w", err)
+	}
+	if bom.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("not a CycloneDX SBOM (bomFormat %q)", bom.BOMFormat)
+	}
+
+	var deps []Dependency
+	offset := 0
+	var walk func([]cdxComponent)
+	walk = func(components []cdxComponent) {
+		for _, c := range components {
+			d := Dependency{Name: c.Name, Version: c.Version}
+			if c.Group != "" {
+				d.Name = c.Group + "/" + c.Name
+			}
+			if eco, name, version, ok := parsePURL(c.PURL); ok {
+				d.Ecosystem, d.Name = eco, name
+				if d.Version == "" {
+					d.Version = version
+				}
+			}
+			for _, l := range c.Licenses {
+				switch {
+				case l.Expression != "":
+					d.Licenses = append(d.Licenses, l.Expression)
+				case l.License != nil && l.License.ID != "":
+					d.Licenses = append(d.Licenses, l.License.ID)
+				case l.License != nil && l.License.Name != "":
+					d.Licenses = append(d.Licenses, l.License.Name)
+				}
+			}
+
+			// Components appear in document order, so each is searched
+			// for after the previous one
+			needle := `"` + c.Name + `"`
+			if c.PURL != "" {
+				needle = `"` + c.PURL + `"`
+			}
+			if i := strings.Index(content[offset:], needle); i >= 0 {
+				offset += i
+				d.Line = lineOf(content, offset)
+			}
+
+			deps = append(deps, d)
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return deps, nil
+}
+
+// parsePURL splits a package URL, pkg:type/namespace/name@version, into
+// its ecosystem, name (namespace included) and version.
+func parsePURL(purl string) (Ecosystem, string, string, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return "", "", "", false
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	typ, rest, ok := strings.Cut(rest, "/")
+	if !ok || rest == "" {
+		return "", "", "", false
+	}
+	version := ""
+	if i := strings.LastIndex(rest, "@"); i > 0 {
+		rest, version = rest[:i], rest[i+1:]
+	}
+	name, err := url.PathUnescape(rest)
+	if err != nil {
+		return "", "", "", false
+	}
+	if v, err := url.PathUnescape(version); err == nil {
+		version = v
+	}
+
+	eco := Ecosystem(strings.ToLower(typ))
+	if eco == "golang" {
+		eco = EcosystemGo
+	}
+	return eco, name, version, true
+}
@@ -0,0 +1,75 @@
+package deps
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testSBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {
+      "name": "crypto",
+      "version": "v0.14.0",
+      "purl": "pkg:golang/golang.org/x/crypto@v0.14.0",
+      "licenses": [{"license": {"id": "BSD-3-Clause"}}]
+    },
+    {
+      "group": "@babel",
+      "name": "core",
+      "purl": "pkg:npm/%40babel/core@7.22.0",
+      "licenses": [{"expression": "MIT OR GPL-3.0-only"}],
+      "components": [
+        {"name": "internal-lib", "version": "1.0", "licenses": [{"license": {"name": "Proprietary"}}]}
+      ]
+    }
+  ]
+}
+`
+
+func TestParseCycloneDX(t *testing.T) {
+	got, err := ParseCycloneDX(testSBOM)
+	if err != nil {
+		t.Fatalf("ParseCycloneDX: %v", err)
+	}
+	want := []Dependency{
+		{Name: "golang.org/x/crypto", Ecosystem: EcosystemGo, Version: "v0.14.0", Licenses: []string{"BSD-3-Clause"}, Line: 8},
+		{Name: "@babel/core", Ecosystem: EcosystemNPM, Version: "7.22.0", Licenses: []string{"MIT OR GPL-3.0-only"}, Line: 14},
+		{Name: "internal-lib", Version: "1.0", Licenses: []string{"Proprietary"}, Line: 17},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCycloneDX =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if parsed, ok := Parse("sbom/bom.cdx.json", testSBOM); !ok || len(parsed) != 3 {
+		t.Errorf("Parse did not recognize the SBOM by its content: %v, %v", parsed, ok)
+	}
+}
+
+func TestParseCycloneDX_NotCycloneDX(t *testing.T) {
+	if _, err := ParseCycloneDX(`{"spdxVersion": "SPDX-2.3"}`); err == nil {
+		t.Error("expected error for an SPDX document")
+	}
+	if _, err := ParseCycloneDX(`not json`); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParsePURL(t *testing.T) {
+	for _, tc := range []struct {
+		purl, eco, name, version string
+		ok                       bool
+	}{
+		{"pkg:golang/github.com/pkg/errors@v0.9.1", "go", "github.com/pkg/errors", "v0.9.1", true},
+		{"pkg:npm/%40scope/name@1.0.0?arch=x64", "npm", "@scope/name", "1.0.0", true},
+		{"pkg:pypi/Django@4.2#sub", "pypi", "Django", "4.2", true},
+		{"pkg:maven/org.apache/commons-text", "maven", "org.apache/commons-text", "", true},
+		{"https://example.com", "", "", "", false},
+	} {
+		eco, name, version, ok := parsePURL(tc.purl)
+		if string(eco) != tc.eco || name != tc.name || version != tc.version || ok != tc.ok {
+			t.Errorf("parsePURL(%q) = %q, %q, %q, %v", tc.purl, eco, name, version, ok)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package deps reads the dependencies a project declares, from go.mod,
+// package.json and requirements.txt manifests or a CycloneDX SBOM, and
+// checks them against dependency rules: banned packages, minimum versions
+// and license denylists.
+package deps
+
+import (
+	"path"
+	"strings"
+)
+
+// Ecosystem is the package registry a dependency comes from. Manifests
+// yield the three constants below; SBOM components take the type of their
+// package URL, so other ecosystems, such as "maven", appear as is.
+type Ecosystem string
+
+const (
+	EcosystemGo   Ecosystem = "go"
+	EcosystemNPM  Ecosystem = "npm"
+	EcosystemPyPI Ecosystem = "pypi"
+)
+
+// Dependency is one package a manifest or SBOM declares.
+type Dependency struct {
+	Name      string
+	Ecosystem Ecosystem
+	// Version is the version required, or the lower bound of a range such
+	// as ">=1.2" or "^1.2.0". It is empty when the manifest does not pin
+	// one.
+	Version string
+	// Licenses are SPDX IDs, names or expressions. Only SBOMs record them.
+	Licenses []string
+	// Line is the 1-indexed line of the manifest declaring the dependency.
+	Line int
+}
+
+// Parse returns the dependencies declared in the file at path with the
+// given content, and false when it is not a file Parse reads: a go.mod,
+// package.json or requirements*.txt manifest, or a CycloneDX SBOM in JSON.
+func Parse(filePath, content string) ([]Dependency, bool) {
+	base := path.Base(strings.ReplaceAll(filePath, "\\", "/"))
+	switch {
+	case base == "go.mod":
+		return parseGoMod(content), true
+	case base == "package.json":
+		return parsePackageJSON(content)
+	case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+		return parseRequirements(content), true
+	case strings.HasSuffix(base, ".json") && IsCycloneDX(content):
+		d, err := ParseCycloneDX(content)
+		return d, err == nil
+	}
+	return nil, false
+}
+
+// lineOf returns the 1-indexed line holding byte offset i of content.
+func lineOf(content string, i int) int {
+	return strings.Count(content[:i], "\n") + 1
+}
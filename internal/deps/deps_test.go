@@ -0,0 +1,100 @@
+package deps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_GoMod(t *testing.T) {
+	content := `module example.com/app
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	// a comment
+	golang.org/x/crypto v0.14.0
+)
+
+replace example.com/old => example.com/new v1.0.0
+`
+	got, ok := Parse("go.mod", content)
+	if !ok {
+		t.Fatal("go.mod not recognized")
+	}
+	want := []Dependency{
+		{Name: "github.com/spf13/cobra", Ecosystem: EcosystemGo, Version: "v1.8.0", Line: 5},
+		{Name: "github.com/google/uuid", Ecosystem: EcosystemGo, Version: "v1.6.0", Line: 8},
+		{Name: "golang.org/x/crypto", Ecosystem: EcosystemGo, Version: "v0.14.0", Line: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(go.mod) =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParse_PackageJSON(t *testing.T) {
+	content := `{
+  "name": "app",
+  "dependencies": {
+    "lodash": "^4.17.20",
+    "left-pad": "*"
+  },
+  "devDependencies": {
+    "@types/node": "~20.1",
+    "local": "file:../local"
+  }
+}
+`
+	got, ok := Parse("web/package.json", content)
+	if !ok {
+		t.Fatal("package.json not recognized")
+	}
+	want := []Dependency{
+		{Name: "lodash", Ecosystem: EcosystemNPM, Version: "4.17.20", Line: 4},
+		{Name: "left-pad", Ecosystem: EcosystemNPM, Line: 5},
+		{Name: "@types/node", Ecosystem: EcosystemNPM, Version: "20.1", Line: 8},
+		{Name: "local", Ecosystem: EcosystemNPM, Line: 9},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(package.json) =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if _, ok := Parse("package.json", "{not json"); ok {
+		t.Error("invalid package.json should not be recognized")
+	}
+}
+
+func TestParse_Requirements(t *testing.T) {
+	content := `# pinned
+Django==4.2.1
+requests[security] >= 2.28, < 3  # comment
+numpy
+-r other.txt
+--index-url https://example.com/simple
+pyyaml~=6.0 ; python_version >= "3.8"
+git+https://github.com/org/repo.git#egg=repo
+`
+	got, ok := Parse("requirements-dev.txt", content)
+	if !ok {
+		t.Fatal("requirements file not recognized")
+	}
+	want := []Dependency{
+		{Name: "Django", Ecosystem: EcosystemPyPI, Version: "4.2.1", Line: 2},
+		{Name: "requests", Ecosystem: EcosystemPyPI, Version: "2.28", Line: 3},
+		{Name: "numpy", Ecosystem: EcosystemPyPI, Line: 4},
+		{Name: "pyyaml", Ecosystem: EcosystemPyPI, Version: "6.0", Line: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(requirements) =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParse_Unrecognized(t *testing.T) {
+	for _, path := range []string{"main.go", "config.json", "notes.txt"} {
+		if _, ok := Parse(path, `{"name": "x"}`); ok {
+			t.Errorf("Parse(%q) recognized a file that is not a manifest", path)
+		}
+	}
+}
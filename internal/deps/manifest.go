@@ -0,0 +1,166 @@
+package deps
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// parseGoMod reads the require directives of a go.mod file, both single
+// line and parenthesized, indirect requirements included.
+func parseGoMod(content string) []Dependency {
+	var deps []Dependency
+	inBlock := false
+	for i, line := range strings.Split(content, "\n") {
+		if j := strings.Index(line, "//"); j >= 0 {
+			line = line[:j]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      strings.Trim(fields[0], `"`),
+			Ecosystem: EcosystemGo,
+			Version:   fields[1],
+			Line:      i + 1,
+		})
+	}
+	return deps
+}
+
+// packageJSONSections are the package.json fields that declare
+// dependencies.
+var packageJSONSections = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+
+// parsePackageJSON reads the dependency sections of a package.json file,
+// reporting false when it is not valid JSON.
+func parsePackageJSON(content string) ([]Dependency, bool) {
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &pkg); err != nil {
+		return nil, false
+	}
+
+	var deps []Dependency
+	for _, section := range packageJSONSections {
+		raw, ok := pkg[section]
+		if !ok {
+			continue
+		}
+		var specs map[string]string
+		if err := json.Unmarshal(raw, &specs); err != nil {
+			continue
+		}
+		// Lines are found by searching for each name after the section's
+		// key, since encoding/json does not report positions
+		start := strings.Index(content, `"`+section+`"`)
+		names := make([]string, 0, len(specs))
+		for name := range specs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			line := 0
+			if start >= 0 {
+				if i := strings.Index(content[start:], `"`+name+`"`); i >= 0 {
+					line = lineOf(content, start+i)
+				}
+			}
+			deps = append(deps, Dependency{
+				Name:      name,
+				Ecosystem: EcosystemNPM,
+				Version:   rangeLowerBound(specs[name]),
+				Line:      line,
+			})
+		}
+	}
+	sort.SliceStable(deps, func(i, j int) bool { return deps[i].Line < deps[j].Line })
+	return deps, true
+}
+
+var (
+	requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+	versionPattern     = regexp.MustCompile(`v?\d+(?:\.\d+)*(?:[-+.]?[0-9A-Za-z]+)*`)
+)
+
+// parseRequirements reads a pip requirements file. Options (-r, -e,
+// --index-url) and URL requirements are skipped.
+func parseRequirements(content string) []Dependency {
+	var deps []Dependency
+	for i, line := range strings.Split(content, "\n") {
+		if j := strings.Index(line, "#"); j >= 0 {
+			line = line[:j]
+		}
+		if j := strings.Index(line, ";"); j >= 0 {
+			line = line[:j] // environment markers
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		m := requirementPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:      m[1],
+			Ecosystem: EcosystemPyPI,
+			Version:   specifierLowerBound(m[2]),
+			Line:      i + 1,
+		})
+	}
+	return deps
+}
+
+// specifierLowerBound returns the version a pip specifier such as
+// ">=1.2,<2" or "==1.4.2" requires at least, or "" when it sets no lower
+// bound.
+func specifierLowerBound(spec string) string {
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.TrimSpace(clause)
+		for _, op := range []string{"===", "==", "~=", ">="} {
+			if strings.HasPrefix(clause, op) {
+				v := strings.TrimSpace(clause[len(op):])
+				if !strings.Contains(v, "*") {
+					return v
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// rangeLowerBound returns the lowest version an npm range such as "^1.2.0",
+// "~1.2" or ">=1.0.0 <2" allows, or "" for ranges that are not versions:
+// "*", "latest", tags, and git, file and URL references.
+func rangeLowerBound(spec string) string {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.Contains(spec, ":") || strings.Contains(spec, "/") {
+		return ""
+	}
+	first := strings.Fields(strings.Split(spec, "||")[0])
+	if len(first) == 0 || strings.HasPrefix(first[0], "<") {
+		return ""
+	}
+	v := strings.TrimLeft(first[0], "^~>=v")
+	v = strings.TrimSuffix(strings.TrimSuffix(v, ".x"), ".*")
+	if v == "" || v[0] < '0' || v[0] > '9' {
+		return ""
+	}
+	return versionPattern.FindString(v)
+}
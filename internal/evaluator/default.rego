@@ -1,5 +1,6 @@
 package gavel.gate
 
+import data.gavel.lib
 import rego.v1
 
 default decision := "review"
@@ -25,6 +26,12 @@ _is_fixed(result) if {
 	object.get(result, "baselineState", "") == "absent"
 }
 
+# _informational returns true for results recorded for reference
+# only, such as findings below the configured minimum confidence.
+_informational(result) if {
+	object.get(result, "kind", "") == "informational"
+}
+
 # unsuppressed_results is all results in the current run that do not
 # carry an explicit suppression. It is kept as a primitive that custom
 # policies can consume if they want to reason about the full
@@ -35,23 +42,97 @@ unsuppressed_results contains result if {
 }
 
 # actionable_results is the set that gating decisions should consider:
-# unsuppressed findings that are not pre-existing noise or already-fixed
-# findings. When analyze is run without --baseline no result carries a
-# baselineState, so _is_pre_existing/_is_fixed never match and this set
-# equals unsuppressed_results — preserving existing semantics for
-# callers that have not adopted baseline comparison.
+# unsuppressed findings that are not informational, pre-existing noise or
+# already-fixed findings. When analyze is run without --baseline no
+# result carries a baselineState, so _is_pre_existing/_is_fixed never
+# match and this set is the non-informational unsuppressed_results —
+# preserving existing semantics for callers that have not adopted
+# baseline comparison.
 actionable_results contains result if {
 	some result in unsuppressed_results
+	not _informational(result)
 	not _is_pre_existing(result)
 	not _is_fixed(result)
 }
 
-decision := "reject" if {
+_reject_confidence := 0.85
+
+# reject_triggers is the set of actionable results that satisfy the reject
+# rule: errors with confidence above _reject_confidence.
+reject_triggers contains result if {
 	some result in actionable_results
 	result.level == "error"
-	result.properties["gavel/confidence"] > 0.85
+	result.properties["gavel/confidence"] > _reject_confidence
+}
+
+decision := "reject" if {
+	count(reject_triggers) > 0
 }
 
+# input.gate is set when judge runs with --fail-on or --max-findings.
+# threshold_results is the actionable results at or above input.gate.fail_on;
+# more than input.gate.max_findings of them rejects.
+_level_rank := {"note": 1, "warning": 2, "error": 3}
+
+threshold_results contains result if {
+	some result in actionable_results
+	_level_rank[result.level] >= _level_rank[input.gate.fail_on]
+}
+
+_threshold_exceeded if {
+	count(threshold_results) > input.gate.max_findings
+}
+
+decision := "reject" if _threshold_exceeded
+
 decision := "merge" if {
 	count(actionable_results) == 0
 }
+
+# decision_trace records which gate rule produced the decision and the
+# counts it was based on. The evaluator attaches it to the verdict when
+# decision tracing is enabled; custom policies may define their own.
+_decision_rule := "reject-high-confidence-error" if {
+	decision == "reject"
+	count(reject_triggers) > 0
+}
+
+_decision_rule := "finding-threshold" if {
+	decision == "reject"
+	count(reject_triggers) == 0
+}
+
+_decision_rule := "merge-no-actionable-results" if decision == "merge"
+
+_decision_rule := "default-review" if decision == "review"
+
+# fired_rules is every gate rule whose conditions held; _decision_rule is
+# the one reported as the cause.
+fired_rules contains "reject-high-confidence-error" if count(reject_triggers) > 0
+
+fired_rules contains "finding-threshold" if _threshold_exceeded
+
+fired_rules contains _decision_rule
+
+_all_results := object.get(input.runs[0], "results", [])
+
+_triggers := reject_triggers | lib.threshold_triggers
+
+decision_trace := {
+	"rule": _decision_rule,
+	"fired": sort(fired_rules),
+	"inputs": {
+		"total_results": count(_all_results),
+		"suppressed_results": count([r | some r in _all_results; _suppressed(r)]),
+		"informational_results": count([r | some r in unsuppressed_results; _informational(r)]),
+		"pre_existing_results": count([r | some r in unsuppressed_results; _is_pre_existing(r)]),
+		"fixed_results": count([r | some r in unsuppressed_results; _is_fixed(r)]),
+		"actionable_results": count(actionable_results),
+		"actionable_errors": count([r | some r in actionable_results; r.level == "error"]),
+		"reject_triggers": count(reject_triggers),
+		"threshold_results": count(threshold_results),
+	},
+	"reject_confidence_threshold": _reject_confidence,
+	"thresholds": [t | some t in lib.gate_thresholds],
+	"triggers": [lib.trigger(r) | some r in _triggers],
+}
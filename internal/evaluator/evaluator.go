@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/open-policy-agent/opa/v1/rego"
@@ -14,6 +15,8 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/evaluator/library"
 	"github.com/chris-regnier/gavel/internal/sarif"
 	"github.com/chris-regnier/gavel/internal/store"
 )
@@ -25,61 +28,146 @@ var defaultPolicy string
 
 type Evaluator struct {
 	query rego.PreparedEvalQuery
+
+	// traceQuery evaluates data.gavel.gate.decision_trace; it is only
+	// prepared when decision tracing is enabled.
+	traceQuery  *rego.PreparedEvalQuery
+	trace       bool
+	moduleNames []string
+
+	// categories gates findings by their gavel/category property instead of
+	// the Rego policy; see WithCategoryThresholds.
+	categories map[string]config.CategoryThreshold
+
+	// threshold is exposed to the policy as input.gate; see
+	// WithFindingThreshold.
+	threshold *FindingThreshold
+
+	// verdict selects the built-in policy and is exposed to it as
+	// input.verdict; see WithVerdictPolicy.
+	verdict config.VerdictConfig
+}
+
+// EvaluatorOption configures an Evaluator.
+type EvaluatorOption func(*Evaluator)
+
+// WithDecisionTrace attaches a store.DecisionTrace to every verdict,
+// recording which gate rule fired and the counts it evaluated.
+func WithDecisionTrace() EvaluatorOption {
+	return func(e *Evaluator) {
+		e.trace = true
+	}
+}
+
+// WithCategoryThresholds gates findings whose gavel/category is a key of
+// thresholds by that category's limits rather than by the Rego policy: they
+// are withheld from the policy input, a category over its max_errors rejects,
+// and one over its max_warnings requires at least review.
+func WithCategoryThresholds(thresholds map[string]config.CategoryThreshold) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.categories = thresholds
+	}
 }
 
-// NewEvaluator creates an evaluator. If policyDir is empty, uses the default policy.
+// WithVerdictPolicy gates with the built-in policy v.Policy instead of the
+// embedded default, and passes v's thresholds to the policy as
+// input.verdict. Custom .rego files in the policy directory still take
+// precedence.
+func WithVerdictPolicy(v config.VerdictConfig) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.verdict = v
+	}
+}
+
+// NewEvaluator creates an evaluator. If policyDir is empty, uses the default policy
+// or the built-in policy selected with WithVerdictPolicy.
 // If policyDir is set, loads all .rego files from that directory (overriding default).
-func NewEvaluator(ctx context.Context, policyDir string) (*Evaluator, error) {
+// The gavel.lib helpers are loaded alongside every policy.
+func NewEvaluator(ctx context.Context, policyDir string, opts ...EvaluatorOption) (*Evaluator, error) {
+	e := &Evaluator{}
+	for _, opt := range opts {
+		opt(e)
+	}
 
 	modules := []func(*rego.Rego){
-		rego.Query("data.gavel.gate.decision"),
 		rego.Module("default.rego", defaultPolicy),
 	}
+	e.moduleNames = []string{"default.rego"}
+	if name := e.verdict.Policy; name != "" && name != library.Default {
+		src, ok := library.Policy(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown verdict policy %q (valid: %s)", name, strings.Join(library.Names(), ", "))
+		}
+		modules = []func(*rego.Rego){rego.Module("library/"+name+".rego", src)}
+		e.moduleNames = []string{"library/" + name + ".rego"}
+	}
 
 	if policyDir != "" {
 		entries, err := os.ReadDir(policyDir)
 		if err != nil && !os.IsNotExist(err) {
 			return nil, fmt.Errorf("reading policy dir: %w", err)
 		}
-		for _, e := range entries {
-			if !e.IsDir() && strings.HasSuffix(e.Name(), ".rego") {
-				data, err := os.ReadFile(filepath.Join(policyDir, e.Name()))
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".rego") {
+				data, err := os.ReadFile(filepath.Join(policyDir, entry.Name()))
 				if err != nil {
 					return nil, err
 				}
 				// Custom policies override the default
 				modules = []func(*rego.Rego){
-					rego.Query("data.gavel.gate.decision"),
-					rego.Module(e.Name(), string(data)),
+					rego.Module(entry.Name(), string(data)),
 				}
+				e.moduleNames = []string{entry.Name()}
 			}
 		}
 	}
 
-	query, err := rego.New(modules...).PrepareForEval(ctx)
+	modules = append(modules, rego.Module(library.LibModule, library.Lib()))
+
+	query, err := rego.New(append(modules, rego.Query("data.gavel.gate.decision"))...).PrepareForEval(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("preparing rego query: %w", err)
 	}
+	e.query = query
 
-	return &Evaluator{query: query}, nil
+	if e.trace {
+		traceQuery, err := rego.New(append(modules, rego.Query("data.gavel.gate.decision_trace"))...).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("preparing rego trace query: %w", err)
+		}
+		e.traceQuery = &traceQuery
+	}
+
+	return e, nil
 }
 
 func (e *Evaluator) Evaluate(ctx context.Context, log *sarif.Log) (*store.Verdict, error) {
 	ctx, span := evalTracer.Start(ctx, "evaluate rego")
 	defer span.End()
 
-	data, err := json.Marshal(log)
+	policyLog, governed := e.splitByCategory(log)
+	data, err := json.Marshal(policyLog)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
-	var input interface{}
+	var input map[string]interface{}
 	if err := json.Unmarshal(data, &input); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	if e.threshold != nil {
+		input["gate"] = map[string]interface{}{
+			"fail_on":      e.threshold.FailOn,
+			"max_findings": e.threshold.MaxFindings,
+		}
+	}
+
+	if v := e.verdictInput(); len(v) > 0 {
+		input["verdict"] = v
+	}
 
 	results, err := e.query.Eval(ctx, rego.EvalInput(input))
 	if err != nil {
@@ -95,6 +183,14 @@ func (e *Evaluator) Evaluate(ctx context.Context, log *sarif.Log) (*store.Verdic
 		}
 	}
 
+	breaches := e.categoryBreaches(governed)
+	ruleDecision := decision
+	for _, b := range breaches {
+		if decisionRank[b.decision] > decisionRank[decision] {
+			decision = b.decision
+		}
+	}
+
 	var relevant []sarif.Result
 	suppressedCount := 0
 	if len(log.Runs) > 0 {
@@ -103,6 +199,9 @@ func (e *Evaluator) Evaluate(ctx context.Context, log *sarif.Log) (*store.Verdic
 				suppressedCount++
 				continue
 			}
+			if cat := resultCategory(r); governed[cat] != nil && !breaches.has(cat) {
+				continue
+			}
 			if decision == "reject" && r.Level == "error" {
 				relevant = append(relevant, r)
 			} else if decision == "review" && (r.Level == "warning" || r.Level == "error") {
@@ -128,10 +227,213 @@ func (e *Evaluator) Evaluate(ctx context.Context, log *sarif.Log) (*store.Verdic
 	if suppressedCount > 0 {
 		reason += fmt.Sprintf(", %d suppressed", suppressedCount)
 	}
+	for _, b := range breaches {
+		reason += "; " + b.String()
+	}
 
-	return &store.Verdict{
+	verdict := &store.Verdict{
 		Decision:         decision,
 		Reason:           reason,
 		RelevantFindings: relevant,
-	}, nil
+	}
+
+	if e.trace {
+		trace, err := e.decisionTrace(ctx, input, decision, resultCount, suppressedCount)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if decision != ruleDecision {
+			trace.Rule = "category-threshold"
+		}
+		if len(breaches) > 0 {
+			trace.Fired = append(trace.Fired, "category-threshold")
+		}
+		for _, b := range breaches {
+			trace.Inputs["category_"+b.category+"_"+b.level+"s"] = b.count
+		}
+		trace.Thresholds = append(trace.Thresholds, e.categoryThresholds(governed)...)
+		verdict.Trace = trace
+	}
+
+	return verdict, nil
+}
+
+// decisionTrace evaluates the policy's decision_trace rule. Policies that do
+// not define one get a minimal trace with rule "unknown" and the counts the
+// evaluator computed itself.
+func (e *Evaluator) decisionTrace(ctx context.Context, input interface{}, decision string, total, suppressed int) (*store.DecisionTrace, error) {
+	trace := &store.DecisionTrace{
+		Decision: decision,
+		Rule:     "unknown",
+		Policies: e.moduleNames,
+		Inputs: map[string]int{
+			"total_results":      total,
+			"suppressed_results": suppressed,
+		},
+	}
+
+	results, err := e.traceQuery.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("evaluating rego decision trace: %w", err)
+	}
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return trace, nil
+	}
+
+	data, err := json.Marshal(results[0].Expressions[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding decision trace: %w", err)
+	}
+	if err := json.Unmarshal(data, trace); err != nil {
+		return nil, fmt.Errorf("decoding decision trace: %w", err)
+	}
+	// The decision and policy list come from the evaluator, not the policy.
+	trace.Decision = decision
+	trace.Policies = e.moduleNames
+	return trace, nil
+}
+
+// verdictInput returns the configured verdict thresholds as the policy
+// sees them in input.verdict; unset thresholds are left out so the policy
+// applies its own defaults.
+func (e *Evaluator) verdictInput() map[string]interface{} {
+	v := map[string]interface{}{}
+	if e.verdict.MaxWarnings != nil {
+		v["max_warnings"] = int(*e.verdict.MaxWarnings)
+	}
+	if e.verdict.RejectCWEs != nil {
+		v["reject_cwes"] = e.verdict.RejectCWEs
+	}
+	if e.verdict.ReviewBelow != nil {
+		v["review_below"] = *e.verdict.ReviewBelow
+	}
+	return v
+}
+
+// decisionRank orders decisions from least to most restrictive.
+var decisionRank = map[string]int{"merge": 0, "review": 1, "reject": 2}
+
+// splitByCategory returns log without the results whose category has a
+// threshold, and those results grouped by category. Without thresholds log
+// is returned unchanged.
+func (e *Evaluator) splitByCategory(log *sarif.Log) (*sarif.Log, map[string][]sarif.Result) {
+	if len(e.categories) == 0 || len(log.Runs) == 0 {
+		return log, nil
+	}
+	governed := make(map[string][]sarif.Result)
+	var rest []sarif.Result
+	for _, r := range log.Runs[0].Results {
+		cat := resultCategory(r)
+		if _, ok := e.categories[cat]; ok {
+			governed[cat] = append(governed[cat], r)
+			continue
+		}
+		rest = append(rest, r)
+	}
+	filtered := *log
+	filtered.Runs = append([]sarif.Run(nil), log.Runs...)
+	filtered.Runs[0].Results = rest
+	return &filtered, governed
+}
+
+// categoryBreach records a category whose actionable findings at one level
+// exceeded the configured limit.
+type categoryBreach struct {
+	category string
+	level    string
+	count    int
+	limit    config.Limit
+	decision string
+}
+
+func (b categoryBreach) String() string {
+	return fmt.Sprintf("%s: %d %s-level findings exceed max_%ss %d", b.category, b.count, b.level, b.level, b.limit)
+}
+
+type categoryBreaches []categoryBreach
+
+func (bs categoryBreaches) has(category string) bool {
+	for _, b := range bs {
+		if b.category == category {
+			return true
+		}
+	}
+	return false
+}
+
+// categoryBreaches counts the actionable errors and warnings in each governed
+// category, ignoring suppressed findings and those baseline comparison marked
+// pre-existing or fixed, and returns the limits they exceed sorted by
+// category.
+func (e *Evaluator) categoryBreaches(governed map[string][]sarif.Result) categoryBreaches {
+	cats := make([]string, 0, len(governed))
+	for cat := range governed {
+		cats = append(cats, cat)
+	}
+	sort.Strings(cats)
+
+	var breaches categoryBreaches
+	for _, cat := range cats {
+		counts := actionableCounts(governed[cat])
+		t := e.categories[cat]
+		if t.MaxErrors != nil && t.MaxErrors.Exceeded(counts["error"]) {
+			breaches = append(breaches, categoryBreach{cat, "error", counts["error"], *t.MaxErrors, "reject"})
+		}
+		if t.MaxWarnings != nil && t.MaxWarnings.Exceeded(counts["warning"]) {
+			breaches = append(breaches, categoryBreach{cat, "warning", counts["warning"], *t.MaxWarnings, "review"})
+		}
+	}
+	return breaches
+}
+
+// categoryThresholds reports each governed category's bounded limits with
+// the counts compared against them, for the decision trace.
+func (e *Evaluator) categoryThresholds(governed map[string][]sarif.Result) []store.TraceThreshold {
+	cats := make([]string, 0, len(e.categories))
+	for cat := range e.categories {
+		cats = append(cats, cat)
+	}
+	sort.Strings(cats)
+
+	var thresholds []store.TraceThreshold
+	for _, cat := range cats {
+		counts := actionableCounts(governed[cat])
+		t := e.categories[cat]
+		for _, l := range []struct {
+			level string
+			limit *config.Limit
+		}{{"error", t.MaxErrors}, {"warning", t.MaxWarnings}} {
+			if l.limit == nil || *l.limit == config.Unlimited {
+				continue
+			}
+			thresholds = append(thresholds, store.TraceThreshold{
+				Name:     cat + ".max_" + l.level + "s",
+				Count:    counts[l.level],
+				Limit:    int(*l.limit),
+				Exceeded: l.limit.Exceeded(counts[l.level]),
+			})
+		}
+	}
+	return thresholds
+}
+
+// actionableCounts counts results by level, ignoring suppressed findings
+// and those baseline comparison marked pre-existing or fixed.
+func actionableCounts(results []sarif.Result) map[string]int {
+	counts := map[string]int{}
+	for _, r := range results {
+		if len(r.Suppressions) > 0 || r.BaselineState == sarif.BaselineStateUnchanged || r.BaselineState == sarif.BaselineStateAbsent {
+			continue
+		}
+		counts[r.Level]++
+	}
+	return counts
+}
+
+// resultCategory returns r's gavel/category property, or "" if it has none.
+func resultCategory(r sarif.Result) string {
+	cat, _ := r.Properties["gavel/category"].(string)
+	return cat
 }
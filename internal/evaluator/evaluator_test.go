@@ -4,9 +4,13 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/chris-regnier/gavel/internal/config"
 	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
 )
 
 func TestEvaluator_Reject(t *testing.T) {
@@ -243,3 +247,318 @@ func TestEvaluator_BaselineMixedNewAndPreExisting(t *testing.T) {
 		t.Errorf("expected 'reject' when PR adds a new regression on top of pre-existing noise, got %q", verdict.Decision)
 	}
 }
+
+func TestEvaluator_DecisionTrace_Reject(t *testing.T) {
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = []sarif.Result{
+		{
+			RuleID:     "sql-injection",
+			Level:      "error",
+			Message:    sarif.Message{Text: "injection"},
+			Properties: map[string]interface{}{"gavel/confidence": 0.95},
+		},
+		{
+			RuleID:     "weak-crypto",
+			Level:      "error",
+			Message:    sarif.Message{Text: "md5"},
+			Properties: map[string]interface{}{"gavel/confidence": 0.5},
+		},
+		{
+			RuleID:        "old-issue",
+			Level:         "warning",
+			Message:       sarif.Message{Text: "pre-existing"},
+			BaselineState: sarif.BaselineStateUnchanged,
+			Properties:    map[string]interface{}{"gavel/confidence": 0.9},
+		},
+		{
+			RuleID:       "suppressed",
+			Level:        "error",
+			Message:      sarif.Message{Text: "accepted risk"},
+			Suppressions: []sarif.SARIFSuppression{{Kind: "external"}},
+			Properties:   map[string]interface{}{"gavel/confidence": 0.99},
+		},
+	}
+
+	e, err := NewEvaluator(context.Background(), "", WithDecisionTrace())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace := verdict.Trace
+	if trace == nil {
+		t.Fatal("expected decision trace")
+	}
+	if trace.Decision != "reject" || trace.Rule != "reject-high-confidence-error" {
+		t.Errorf("expected reject via reject-high-confidence-error, got %q via %q", trace.Decision, trace.Rule)
+	}
+	if len(trace.Policies) != 1 || trace.Policies[0] != "default.rego" {
+		t.Errorf("expected policies [default.rego], got %v", trace.Policies)
+	}
+	if trace.RejectConfidenceThreshold != 0.85 {
+		t.Errorf("expected threshold 0.85, got %v", trace.RejectConfidenceThreshold)
+	}
+
+	wantInputs := map[string]int{
+		"total_results":        4,
+		"suppressed_results":   1,
+		"pre_existing_results": 1,
+		"fixed_results":        0,
+		"actionable_results":   2,
+		"actionable_errors":    2,
+		"reject_triggers":      1,
+	}
+	for k, want := range wantInputs {
+		if got := trace.Inputs[k]; got != want {
+			t.Errorf("inputs[%q] = %d, want %d", k, got, want)
+		}
+	}
+
+	if len(trace.Triggers) != 1 {
+		t.Fatalf("expected 1 trigger, got %d", len(trace.Triggers))
+	}
+	if trace.Triggers[0].RuleID != "sql-injection" || trace.Triggers[0].Confidence != 0.95 || trace.Triggers[0].Message != "injection" {
+		t.Errorf("unexpected trigger: %+v", trace.Triggers[0])
+	}
+	if len(trace.Fired) != 1 || trace.Fired[0] != "reject-high-confidence-error" {
+		t.Errorf("expected only the reject rule to fire, got %v", trace.Fired)
+	}
+}
+
+func TestEvaluator_DecisionTrace_ThresholdsAndLocations(t *testing.T) {
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = []sarif.Result{
+		{
+			RuleID:  "sql-injection",
+			Level:   "error",
+			Message: sarif.Message{Text: "injection"},
+			Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: "db/query.go"},
+				Region:           sarif.Region{StartLine: 17},
+			}}},
+			Properties: map[string]interface{}{"gavel/confidence": 0.95},
+		},
+		categorized("S2068", "warning", "security"),
+	}
+	threshold, _ := NewFindingThreshold("error", 0)
+	one := config.Limit(1)
+	e, err := NewEvaluator(context.Background(), "", WithDecisionTrace(), WithFindingThreshold(threshold),
+		WithCategoryThresholds(map[string]config.CategoryThreshold{"security": {MaxWarnings: &one, MaxErrors: limit(config.Unlimited)}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace := verdict.Trace
+	if strings.Join(trace.Fired, ",") != "finding-threshold,reject-high-confidence-error" {
+		t.Errorf("fired = %v", trace.Fired)
+	}
+	want := []store.TraceThreshold{
+		{Name: "max_findings", Count: 1, Limit: 0, Exceeded: true},
+		{Name: "security.max_warnings", Count: 1, Limit: 1, Exceeded: false},
+	}
+	if !reflect.DeepEqual(trace.Thresholds, want) {
+		t.Errorf("thresholds = %+v, want %+v", trace.Thresholds, want)
+	}
+	if len(trace.Triggers) != 1 || trace.Triggers[0].File != "db/query.go" || trace.Triggers[0].Line != 17 {
+		t.Errorf("expected the trigger's location, got %+v", trace.Triggers)
+	}
+}
+
+func TestEvaluator_DecisionTrace_Merge(t *testing.T) {
+	e, err := NewEvaluator(context.Background(), "", WithDecisionTrace())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), sarif.NewLog("gavel", "0.1.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if verdict.Trace == nil {
+		t.Fatal("expected decision trace")
+	}
+	if verdict.Trace.Rule != "merge-no-actionable-results" {
+		t.Errorf("expected merge-no-actionable-results, got %q", verdict.Trace.Rule)
+	}
+	if verdict.Trace.Inputs["actionable_results"] != 0 {
+		t.Errorf("expected 0 actionable results, got %d", verdict.Trace.Inputs["actionable_results"])
+	}
+	if len(verdict.Trace.Triggers) != 0 {
+		t.Errorf("expected no triggers, got %v", verdict.Trace.Triggers)
+	}
+}
+
+func TestEvaluator_InformationalIgnored(t *testing.T) {
+	e, err := NewEvaluator(context.Background(), "", WithDecisionTrace())
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := sarif.NewLog("gavel", "0.1.0")
+	results := []sarif.Result{{
+		RuleID: "sql-injection", Level: "error", Message: sarif.Message{Text: "possible injection"},
+		Properties: map[string]interface{}{"gavel/confidence": 0.95},
+	}}
+	sarif.DemoteBelowConfidence(results, func(string) float64 { return 0.99 })
+	log.Runs[0].Results = results
+
+	verdict, err := e.Evaluate(context.Background(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Decision != "merge" {
+		t.Errorf("expected informational findings to be ignored, got %s", verdict.Decision)
+	}
+	if verdict.Trace.Inputs["informational_results"] != 1 {
+		t.Errorf("expected the trace to count the informational finding, got %v", verdict.Trace.Inputs)
+	}
+}
+
+func TestEvaluator_DecisionTrace_CustomPolicyWithoutTrace(t *testing.T) {
+	dir := t.TempDir()
+	policy := `package gavel.gate
+
+import rego.v1
+
+default decision := "reject"
+`
+	if err := os.WriteFile(filepath.Join(dir, "strict.rego"), []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewEvaluator(context.Background(), dir, WithDecisionTrace())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), sarif.NewLog("gavel", "0.1.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if verdict.Trace == nil {
+		t.Fatal("expected fallback decision trace")
+	}
+	if verdict.Trace.Rule != "unknown" || verdict.Trace.Decision != "reject" {
+		t.Errorf("expected unknown rule with reject decision, got %+v", verdict.Trace)
+	}
+	if len(verdict.Trace.Policies) != 1 || verdict.Trace.Policies[0] != "strict.rego" {
+		t.Errorf("expected policies [strict.rego], got %v", verdict.Trace.Policies)
+	}
+}
+
+func TestEvaluator_NoTraceByDefault(t *testing.T) {
+	e, err := NewEvaluator(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), sarif.NewLog("gavel", "0.1.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Trace != nil {
+		t.Errorf("expected no trace without WithDecisionTrace, got %+v", verdict.Trace)
+	}
+}
+
+func categorized(ruleID, level, category string) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   level,
+		Message: sarif.Message{Text: ruleID},
+		Properties: map[string]interface{}{
+			"gavel/confidence": 0.95,
+			"gavel/category":   category,
+		},
+	}
+}
+
+func categoryThresholds() map[string]config.CategoryThreshold {
+	zero, unlimited := config.Limit(0), config.Unlimited
+	return map[string]config.CategoryThreshold{
+		"security":        {MaxErrors: &zero},
+		"maintainability": {MaxErrors: &unlimited},
+	}
+}
+
+func TestEvaluator_CategoryThresholdRejectsSecurityError(t *testing.T) {
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = []sarif.Result{categorized("S2068", "error", "security")}
+
+	e, err := NewEvaluator(context.Background(), "", WithCategoryThresholds(categoryThresholds()), WithDecisionTrace())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if verdict.Decision != "reject" {
+		t.Errorf("expected 'reject', got %q", verdict.Decision)
+	}
+	if !strings.Contains(verdict.Reason, "security: 1 error-level findings exceed max_errors 0") {
+		t.Errorf("expected reason to name the security breach, got %q", verdict.Reason)
+	}
+	if len(verdict.RelevantFindings) != 1 || verdict.RelevantFindings[0].RuleID != "S2068" {
+		t.Errorf("expected S2068 as the relevant finding, got %v", verdict.RelevantFindings)
+	}
+	if verdict.Trace.Rule != "category-threshold" || verdict.Trace.Inputs["category_security_errors"] != 1 {
+		t.Errorf("expected category-threshold trace, got %+v", verdict.Trace)
+	}
+}
+
+func TestEvaluator_CategoryThresholdUnlimitedPasses(t *testing.T) {
+	log := sarif.NewLog("gavel", "0.1.0")
+	// Without thresholds this high-confidence error would reject.
+	log.Runs[0].Results = []sarif.Result{categorized("S3776", "error", "maintainability")}
+
+	e, err := NewEvaluator(context.Background(), "", WithCategoryThresholds(categoryThresholds()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if verdict.Decision != "merge" {
+		t.Errorf("expected 'merge', got %q", verdict.Decision)
+	}
+	if len(verdict.RelevantFindings) != 0 {
+		t.Errorf("expected no relevant findings, got %v", verdict.RelevantFindings)
+	}
+}
+
+func TestEvaluator_CategoryThresholdIgnoresSuppressedAndUncategorized(t *testing.T) {
+	suppressed := categorized("S2068", "error", "security")
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	uncategorized := categorized("llm-policy", "warning", "")
+	delete(uncategorized.Properties, "gavel/category")
+
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = []sarif.Result{suppressed, uncategorized}
+
+	e, err := NewEvaluator(context.Background(), "", WithCategoryThresholds(categoryThresholds()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The suppressed security error is within max_errors 0; the
+	// uncategorized warning still goes to the Rego policy, which reviews it.
+	if verdict.Decision != "review" {
+		t.Errorf("expected 'review', got %q", verdict.Decision)
+	}
+	if len(verdict.RelevantFindings) != 1 || verdict.RelevantFindings[0].RuleID != "llm-policy" {
+		t.Errorf("expected only the uncategorized finding to be relevant, got %v", verdict.RelevantFindings)
+	}
+}
@@ -0,0 +1,70 @@
+package gavel.gate
+
+import data.gavel.lib
+import rego.v1
+
+# cwe rejects on actionable findings of any level in one of the CWE classes
+# listed in verdict.reject_cwes, and otherwise gates like the default
+# policy: errors with confidence above 0.85 reject.
+
+default decision := "review"
+
+_reject_cwes := {c | some c in lib.param("reject_cwes", lib.default_reject_cwes)}
+
+_review_below := lib.param("review_below", 0)
+
+_reject_confidence := 0.85
+
+cwe_triggers contains result if {
+	some result in lib.actionable_results
+	some c in lib.cwes(result)
+	c in _reject_cwes
+	not lib.low_confidence(result, _review_below)
+}
+
+error_triggers contains result if {
+	some result in lib.actionable_errors
+	lib.confidence(result) > _reject_confidence
+}
+
+decision := "reject" if count(cwe_triggers) > 0
+
+decision := "reject" if count(error_triggers) > 0
+
+decision := "reject" if lib.threshold_exceeded
+
+decision := "merge" if count(lib.actionable_results) == 0
+
+_decision_rule := "reject-cwe" if {
+	decision == "reject"
+	count(cwe_triggers) > 0
+} else := "reject-high-confidence-error" if {
+	decision == "reject"
+	count(error_triggers) > 0
+} else := "finding-threshold" if {
+	decision == "reject"
+} else := "merge-no-actionable-results" if {
+	decision == "merge"
+} else := "default-review"
+
+fired_rules contains "reject-cwe" if count(cwe_triggers) > 0
+
+fired_rules contains "reject-high-confidence-error" if count(error_triggers) > 0
+
+fired_rules contains "finding-threshold" if lib.threshold_exceeded
+
+fired_rules contains _decision_rule
+
+_triggers := (cwe_triggers | error_triggers) | lib.threshold_triggers
+
+decision_trace := {
+	"rule": _decision_rule,
+	"fired": sort(fired_rules),
+	"inputs": object.union(lib.trace_inputs, {
+		"cwe_triggers": count(cwe_triggers),
+		"reject_triggers": count(error_triggers),
+	}),
+	"reject_confidence_threshold": _reject_confidence,
+	"thresholds": [t | some t in lib.gate_thresholds],
+	"triggers": [lib.trigger(r) | some r in _triggers],
+}
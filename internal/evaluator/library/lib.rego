@@ -0,0 +1,127 @@
+package gavel.lib
+
+import rego.v1
+
+# Helpers shared by the built-in verdict policies. Custom policies in
+# .gavel/rego can use them too:
+#
+#   import data.gavel.lib
+#   decision := "reject" if count(lib.actionable_errors) > 0
+
+suppressed(result) if {
+	count(object.get(result, "suppressions", [])) > 0
+}
+
+informational(result) if {
+	object.get(result, "kind", "") == "informational"
+}
+
+# pre_existing and fixed match results that baseline comparison marked as
+# already in the baseline ("unchanged") or gone from this run ("absent").
+pre_existing(result) if {
+	object.get(result, "baselineState", "") == "unchanged"
+}
+
+fixed(result) if {
+	object.get(result, "baselineState", "") == "absent"
+}
+
+# confidence is a result's gavel/confidence; results without one, such as
+# findings from deterministic rules, count as fully confident.
+confidence(result) := object.get(result, ["properties", "gavel/confidence"], 1)
+
+# cwes is the CWE IDs recorded on a result, such as ["CWE-89"].
+cwes(result) := object.get(result, ["properties", "gavel/cwe"], [])
+
+# actionable_results is the unsuppressed findings that are not
+# informational, pre-existing or fixed: the set verdicts are based on.
+actionable_results contains result if {
+	some result in input.runs[0].results
+	not suppressed(result)
+	not informational(result)
+	not pre_existing(result)
+	not fixed(result)
+}
+
+# default_reject_cwes is the CWE classes verdict.reject_cwes defaults to.
+default_reject_cwes := [
+	"CWE-22", # Path traversal
+	"CWE-78", # OS command injection
+	"CWE-79", # Cross-site scripting
+	"CWE-89", # SQL injection
+	"CWE-94", # Code injection
+	"CWE-502", # Deserialization of untrusted data
+	"CWE-798", # Hard-coded credentials
+]
+
+# input.verdict holds the verdict settings from policies.yaml. param
+# returns the setting name, or default when it is not configured.
+param(name, default_value) := object.get(object.get(input, "verdict", {}), name, default_value)
+
+# low_confidence matches actionable results below verdict.review_below.
+# The built-in policies never reject on them; they only require review.
+low_confidence(result, review_below) if {
+	confidence(result) < review_below
+}
+
+actionable_errors contains result if {
+	some result in actionable_results
+	result.level == "error"
+}
+
+actionable_warnings contains result if {
+	some result in actionable_results
+	result.level == "warning"
+}
+
+# threshold_exceeded is true when judge runs with --fail-on or
+# --max-findings and more actionable findings than allowed reach the level.
+_level_rank := {"note": 1, "warning": 2, "error": 3}
+
+threshold_results contains result if {
+	some result in actionable_results
+	_level_rank[result.level] >= _level_rank[input.gate.fail_on]
+}
+
+threshold_exceeded if {
+	count(threshold_results) > input.gate.max_findings
+}
+
+# trace_inputs is the counts every built-in policy reports in its
+# decision_trace.
+_all_results := [r | some r in object.get(input.runs[0], "results", [])]
+
+trace_inputs := {
+	"total_results": count(_all_results),
+	"suppressed_results": count([r | some r in _all_results; suppressed(r)]),
+	"informational_results": count([r | some r in _all_results; not suppressed(r); informational(r)]),
+	"actionable_results": count(actionable_results),
+	"actionable_errors": count(actionable_errors),
+	"actionable_warnings": count(actionable_warnings),
+	"threshold_results": count(threshold_results),
+}
+
+# threshold_triggers is the results counted against --fail-on and
+# --max-findings, when they exceed the limit.
+threshold_triggers contains result if {
+	threshold_exceeded
+	some result in threshold_results
+}
+
+# threshold records a count compared against a limit for decision_trace.
+threshold(name, n, limit) := {"name": name, "count": n, "limit": limit, "exceeded": n > limit}
+
+# gate_thresholds is the --max-findings comparison, when judge runs with a
+# threshold.
+gate_thresholds contains threshold("max_findings", count(threshold_results), input.gate.max_findings) if input.gate
+
+# trigger describes a result that caused a rejection, with enough of its
+# location and message to act on.
+trigger(result) := {
+	"rule_id": object.get(result, "ruleId", ""),
+	"level": result.level,
+	"confidence": confidence(result),
+	"file": object.get(result, ["locations", 0, "physicalLocation", "artifactLocation", "uri"], ""),
+	"line": object.get(result, ["locations", 0, "physicalLocation", "region", "startLine"], 0),
+	"message": object.get(result, ["message", "text"], ""),
+}
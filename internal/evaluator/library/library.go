@@ -0,0 +1,59 @@
+// Package library holds the built-in Rego verdict policies that
+// verdict.policy selects by name, and the gavel.lib helpers they share.
+package library
+
+import (
+	"embed"
+	"sort"
+	"strings"
+)
+
+//go:embed *.rego
+var files embed.FS
+
+// Default is the name of the embedded default policy, which lives in the
+// evaluator package and is used when no policy is selected.
+const Default = "default"
+
+// LibModule is the module name of the shared helpers.
+const LibModule = "gavel/lib.rego"
+
+// Lib returns the source of the gavel.lib package. It is loaded alongside
+// every gate policy, including custom ones, which may import data.gavel.lib.
+func Lib() string {
+	data, _ := files.ReadFile("lib.rego")
+	return string(data)
+}
+
+// Policy returns the source of the built-in policy name. It returns false
+// for Default and for names that are not built in.
+func Policy(name string) (string, bool) {
+	if name == Default || name == "lib" || strings.ContainsAny(name, "/.") {
+		return "", false
+	}
+	data, err := files.ReadFile(name + ".rego")
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Known reports whether name selects a built-in policy.
+func Known(name string) bool {
+	_, ok := Policy(name)
+	return ok || name == Default
+}
+
+// Names returns the built-in policy names in alphabetical order.
+func Names() []string {
+	entries, _ := files.ReadDir(".")
+	names := []string{Default}
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".rego")
+		if name != "lib" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
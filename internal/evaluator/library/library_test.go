@@ -0,0 +1,24 @@
+package library
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	want := "cwe,default,fail-on-error,max-warnings,review-low-confidence,strict"
+	if got := strings.Join(Names(), ","); got != want {
+		t.Errorf("Names = %s, want %s", got, want)
+	}
+	for _, name := range []string{"lib", "../lib", "strict.rego", "unknown"} {
+		if Known(name) {
+			t.Errorf("expected %q not to be a policy", name)
+		}
+	}
+	if _, ok := Policy(Default); ok {
+		t.Error("the default policy lives in the evaluator package")
+	}
+	if !strings.Contains(Lib(), "package gavel.lib") {
+		t.Error("expected Lib to return the gavel.lib package")
+	}
+}
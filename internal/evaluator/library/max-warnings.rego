@@ -0,0 +1,63 @@
+package gavel.gate
+
+import data.gavel.lib
+import rego.v1
+
+# max-warnings rejects when more than verdict.max_warnings (0 unless
+# configured) actionable findings are at warning level or above, so that
+# N+1 warnings or errors fail the run. Findings below verdict.review_below
+# are not counted.
+
+default decision := "review"
+
+_max_warnings := lib.param("max_warnings", 0)
+
+_review_below := lib.param("review_below", 0)
+
+counted_results contains result if {
+	some result in lib.actionable_results
+	result.level in {"warning", "error"}
+	not lib.low_confidence(result, _review_below)
+}
+
+# max_warnings is -1 when configured as "unlimited".
+_warnings_exceeded if {
+	_max_warnings >= 0
+	count(counted_results) > _max_warnings
+}
+
+decision := "reject" if _warnings_exceeded
+
+decision := "reject" if lib.threshold_exceeded
+
+decision := "merge" if count(lib.actionable_results) == 0
+
+_decision_rule := "reject-warnings" if {
+	decision == "reject"
+	_warnings_exceeded
+} else := "finding-threshold" if {
+	decision == "reject"
+} else := "merge-no-actionable-results" if {
+	decision == "merge"
+} else := "default-review"
+
+fired_rules contains "reject-warnings" if _warnings_exceeded
+
+fired_rules contains "finding-threshold" if lib.threshold_exceeded
+
+fired_rules contains _decision_rule
+
+_thresholds := lib.gate_thresholds | {lib.threshold("max_warnings", count(counted_results), _max_warnings) | _max_warnings >= 0}
+
+_triggers := {r | some r in counted_results; _warnings_exceeded} | lib.threshold_triggers
+
+decision_trace := {
+	"rule": _decision_rule,
+	"fired": sort(fired_rules),
+	"inputs": object.union(lib.trace_inputs, {
+		"counted_results": count(counted_results),
+		"max_warnings": _max_warnings,
+	}),
+	"thresholds": [t | some t in _thresholds],
+	"triggers": [lib.trigger(r) | some r in _triggers],
+}
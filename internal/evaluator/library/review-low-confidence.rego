@@ -0,0 +1,52 @@
+package gavel.gate
+
+import data.gavel.lib
+import rego.v1
+
+# review-low-confidence rejects on actionable errors the model is confident
+# about and allows everything else with review: errors below
+# verdict.review_below (0.7 unless configured) never reject.
+
+default decision := "review"
+
+_review_below := lib.param("review_below", 0.7)
+
+reject_triggers contains result if {
+	some result in lib.actionable_errors
+	not lib.low_confidence(result, _review_below)
+}
+
+decision := "reject" if count(reject_triggers) > 0
+
+decision := "reject" if lib.threshold_exceeded
+
+decision := "merge" if count(lib.actionable_results) == 0
+
+_decision_rule := "reject-error" if {
+	decision == "reject"
+	count(reject_triggers) > 0
+} else := "finding-threshold" if {
+	decision == "reject"
+} else := "merge-no-actionable-results" if {
+	decision == "merge"
+} else := "default-review"
+
+fired_rules contains "reject-error" if count(reject_triggers) > 0
+
+fired_rules contains "finding-threshold" if lib.threshold_exceeded
+
+fired_rules contains _decision_rule
+
+_triggers := reject_triggers | lib.threshold_triggers
+
+decision_trace := {
+	"rule": _decision_rule,
+	"fired": sort(fired_rules),
+	"inputs": object.union(lib.trace_inputs, {
+		"reject_triggers": count(reject_triggers),
+		"low_confidence_results": count([r | some r in lib.actionable_results; lib.low_confidence(r, _review_below)]),
+	}),
+	"reject_confidence_threshold": _review_below,
+	"thresholds": [t | some t in lib.gate_thresholds],
+	"triggers": [lib.trigger(r) | some r in _triggers],
+}
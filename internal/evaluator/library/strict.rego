@@ -0,0 +1,95 @@
+package gavel.gate
+
+import data.gavel.lib
+import rego.v1
+
+# strict rejects on any actionable error, on more than verdict.max_warnings
+# (0 unless configured) warnings, and on findings in the CWE classes listed
+# in verdict.reject_cwes. Findings below verdict.review_below only require
+# review.
+
+default decision := "review"
+
+_max_warnings := lib.param("max_warnings", 0)
+
+_review_below := lib.param("review_below", 0)
+
+_reject_cwes := {c | some c in lib.param("reject_cwes", lib.default_reject_cwes)}
+
+_confident contains result if {
+	some result in lib.actionable_results
+	not lib.low_confidence(result, _review_below)
+}
+
+error_triggers contains result if {
+	some result in _confident
+	result.level == "error"
+}
+
+warning_results contains result if {
+	some result in _confident
+	result.level == "warning"
+}
+
+_warnings_exceeded if {
+	_max_warnings >= 0
+	count(warning_results) > _max_warnings
+}
+
+cwe_triggers contains result if {
+	some result in _confident
+	some c in lib.cwes(result)
+	c in _reject_cwes
+}
+
+decision := "reject" if count(error_triggers) > 0
+
+decision := "reject" if _warnings_exceeded
+
+decision := "reject" if count(cwe_triggers) > 0
+
+decision := "reject" if lib.threshold_exceeded
+
+decision := "merge" if count(lib.actionable_results) == 0
+
+_decision_rule := "reject-cwe" if {
+	decision == "reject"
+	count(cwe_triggers) > 0
+} else := "reject-error" if {
+	decision == "reject"
+	count(error_triggers) > 0
+} else := "reject-warnings" if {
+	decision == "reject"
+	_warnings_exceeded
+} else := "finding-threshold" if {
+	decision == "reject"
+} else := "merge-no-actionable-results" if {
+	decision == "merge"
+} else := "default-review"
+
+fired_rules contains "reject-cwe" if count(cwe_triggers) > 0
+
+fired_rules contains "reject-error" if count(error_triggers) > 0
+
+fired_rules contains "reject-warnings" if _warnings_exceeded
+
+fired_rules contains "finding-threshold" if lib.threshold_exceeded
+
+fired_rules contains _decision_rule
+
+_thresholds := lib.gate_thresholds | {lib.threshold("max_warnings", count(warning_results), _max_warnings) | _max_warnings >= 0}
+
+_triggers := ((cwe_triggers | error_triggers) | {r | some r in warning_results; _warnings_exceeded}) | lib.threshold_triggers
+
+decision_trace := {
+	"rule": _decision_rule,
+	"fired": sort(fired_rules),
+	"inputs": object.union(lib.trace_inputs, {
+		"cwe_triggers": count(cwe_triggers),
+		"reject_triggers": count(error_triggers),
+		"counted_warnings": count(warning_results),
+		"max_warnings": _max_warnings,
+	}),
+	"thresholds": [t | some t in _thresholds],
+	"triggers": [lib.trigger(r) | some r in _triggers],
+}
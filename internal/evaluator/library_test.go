@@ -0,0 +1,140 @@
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/evaluator/library"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func finding(level string, confidence float64, cwes ...string) sarif.Result {
+	props := map[string]interface{}{"gavel/confidence": confidence}
+	if len(cwes) > 0 {
+		props["gavel/cwe"] = cwes
+	}
+	return sarif.Result{RuleID: "r-" + level, Level: level, Message: sarif.Message{Text: level}, Properties: props}
+}
+
+func limit(n config.Limit) *config.Limit { return &n }
+
+func TestEvaluator_VerdictPolicies(t *testing.T) {
+	lowError := finding("error", 0.4)
+	warning := finding("warning", 0.9)
+	other := finding("warning", 0.8)
+	sqlNote := finding("note", 0.9, "CWE-89")
+	half := 0.5
+
+	for _, tc := range []struct {
+		name    string
+		verdict config.VerdictConfig
+		results []sarif.Result
+		want    string
+		rule    string
+	}{
+		{"default ignores low-confidence errors", config.VerdictConfig{Policy: "default"}, []sarif.Result{lowError}, "review", "default-review"},
+		{"fail-on-error rejects any error", config.VerdictConfig{Policy: "fail-on-error"}, []sarif.Result{lowError}, "reject", "reject-error"},
+		{"fail-on-error reviews below review_below", config.VerdictConfig{Policy: "fail-on-error", ReviewBelow: &half}, []sarif.Result{lowError}, "review", "default-review"},
+		{"fail-on-error merges when clean", config.VerdictConfig{Policy: "fail-on-error"}, nil, "merge", "merge-no-actionable-results"},
+		{"max-warnings rejects past the limit", config.VerdictConfig{Policy: "max-warnings", MaxWarnings: limit(1)}, []sarif.Result{warning, other}, "reject", "reject-warnings"},
+		{"max-warnings allows up to the limit", config.VerdictConfig{Policy: "max-warnings", MaxWarnings: limit(2)}, []sarif.Result{warning, other}, "review", "default-review"},
+		{"max-warnings unlimited", config.VerdictConfig{Policy: "max-warnings", MaxWarnings: limit(config.Unlimited)}, []sarif.Result{warning, finding("error", 1)}, "review", "default-review"},
+		{"cwe rejects listed classes at any level", config.VerdictConfig{Policy: "cwe"}, []sarif.Result{sqlNote}, "reject", "reject-cwe"},
+		{"cwe uses configured classes", config.VerdictConfig{Policy: "cwe", RejectCWEs: []string{"CWE-798"}}, []sarif.Result{sqlNote}, "review", "default-review"},
+		{"review-low-confidence reviews uncertain errors", config.VerdictConfig{Policy: "review-low-confidence"}, []sarif.Result{finding("error", 0.6)}, "review", "default-review"},
+		{"review-low-confidence rejects confident errors", config.VerdictConfig{Policy: "review-low-confidence"}, []sarif.Result{finding("error", 0.75)}, "reject", "reject-error"},
+		{"strict rejects any warning", config.VerdictConfig{Policy: "strict"}, []sarif.Result{warning}, "reject", "reject-warnings"},
+		{"strict rejects listed classes", config.VerdictConfig{Policy: "strict", MaxWarnings: limit(5)}, []sarif.Result{sqlNote}, "reject", "reject-cwe"},
+		{"strict reviews low confidence", config.VerdictConfig{Policy: "strict", ReviewBelow: &half}, []sarif.Result{lowError}, "review", "default-review"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			log := sarif.NewLog("gavel", "0.1.0")
+			log.Runs[0].Results = tc.results
+
+			e, err := NewEvaluator(context.Background(), "", WithVerdictPolicy(tc.verdict), WithDecisionTrace())
+			if err != nil {
+				t.Fatal(err)
+			}
+			verdict, err := e.Evaluate(context.Background(), log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verdict.Decision != tc.want {
+				t.Errorf("decision = %q, want %q", verdict.Decision, tc.want)
+			}
+			if tc.verdict.Policy != "default" && verdict.Trace.Rule != tc.rule {
+				t.Errorf("trace rule = %q, want %q", verdict.Trace.Rule, tc.rule)
+			}
+		})
+	}
+}
+
+func TestEvaluator_VerdictPolicyHonoursGateAndSuppressions(t *testing.T) {
+	suppressed := finding("error", 1)
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	info := finding("error", 0.2)
+	info.Kind = sarif.KindInformational
+
+	for _, name := range library.Names() {
+		log := sarif.NewLog("gavel", "0.1.0")
+		log.Runs[0].Results = []sarif.Result{suppressed, info}
+		e, err := NewEvaluator(context.Background(), "", WithVerdictPolicy(config.VerdictConfig{Policy: name}))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		verdict, err := e.Evaluate(context.Background(), log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verdict.Decision != "merge" {
+			t.Errorf("%s: expected suppressed and informational findings to be ignored, got %q", name, verdict.Decision)
+		}
+
+		log.Runs[0].Results = []sarif.Result{finding("note", 0.9)}
+		threshold, _ := NewFindingThreshold("note", 0)
+		e, _ = NewEvaluator(context.Background(), "", WithVerdictPolicy(config.VerdictConfig{Policy: name}), WithFindingThreshold(threshold))
+		if verdict, _ := e.Evaluate(context.Background(), log); verdict.Decision != "reject" {
+			t.Errorf("%s: expected --fail-on note to reject, got %q", name, verdict.Decision)
+		}
+	}
+}
+
+func TestEvaluator_VerdictPolicyUnknown(t *testing.T) {
+	if _, err := NewEvaluator(context.Background(), "", WithVerdictPolicy(config.VerdictConfig{Policy: "lenient"})); err == nil {
+		t.Error("expected an unknown policy to be rejected")
+	}
+}
+
+func TestEvaluator_CustomPolicyUsesLib(t *testing.T) {
+	dir := t.TempDir()
+	policy := `package gavel.gate
+
+import data.gavel.lib
+import rego.v1
+
+default decision := "merge"
+
+decision := "reject" if count(lib.actionable_errors) > 0
+`
+	if err := os.WriteFile(filepath.Join(dir, "custom.rego"), []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Results = []sarif.Result{finding("error", 0.1)}
+
+	// Custom policies take precedence over verdict.policy
+	e, err := NewEvaluator(context.Background(), dir, WithVerdictPolicy(config.VerdictConfig{Policy: "max-warnings"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Decision != "reject" {
+		t.Errorf("expected the custom policy to reject, got %q", verdict.Decision)
+	}
+}
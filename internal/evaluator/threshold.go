@@ -0,0 +1,73 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// levelRank orders SARIF levels for FindingThreshold.FailOn; levels not
+// listed, such as "none", never count.
+var levelRank = map[string]int{"note": 1, "warning": 2, "error": 3}
+
+// FindingThreshold fails a run that has more than MaxFindings actionable
+// findings at level FailOn or above. Actionable findings are those the
+// default policy gates on: unsuppressed, and not marked unchanged or absent
+// by baseline comparison.
+type FindingThreshold struct {
+	FailOn      string `json:"fail_on"`
+	MaxFindings int    `json:"max_findings"`
+}
+
+// NewFindingThreshold builds a threshold from CLI-style settings: failOn is
+// "", "error", "warning" or "note", and maxFindings is negative when unset.
+// It returns nil when neither is set. With only failOn, any counted finding
+// fails; with only maxFindings, findings of every level count.
+func NewFindingThreshold(failOn string, maxFindings int) (*FindingThreshold, error) {
+	if failOn == "" && maxFindings < 0 {
+		return nil, nil
+	}
+	if failOn == "" {
+		failOn = "note"
+	}
+	if _, ok := levelRank[failOn]; !ok {
+		return nil, fmt.Errorf("invalid level %q (valid: error, warning, note)", failOn)
+	}
+	if maxFindings < 0 {
+		maxFindings = 0
+	}
+	return &FindingThreshold{FailOn: failOn, MaxFindings: maxFindings}, nil
+}
+
+// Count returns the number of actionable findings in log at or above
+// t.FailOn.
+func (t *FindingThreshold) Count(log *sarif.Log) int {
+	if len(log.Runs) == 0 {
+		return 0
+	}
+	n := 0
+	for _, r := range log.Runs[0].Results {
+		if len(r.Suppressions) > 0 || r.BaselineState == sarif.BaselineStateUnchanged || r.BaselineState == sarif.BaselineStateAbsent {
+			continue
+		}
+		if rank, ok := levelRank[r.Level]; ok && rank >= levelRank[t.FailOn] {
+			n++
+		}
+	}
+	return n
+}
+
+// Exceeded reports whether log has more counted findings than allowed.
+func (t *FindingThreshold) Exceeded(log *sarif.Log) bool {
+	return t.Count(log) > t.MaxFindings
+}
+
+// WithFindingThreshold passes t to the Rego policy as input.gate. The
+// default policy rejects when the threshold is exceeded; custom policies
+// may read input.gate.fail_on and input.gate.max_findings themselves. A nil
+// t leaves input.gate unset.
+func WithFindingThreshold(t *FindingThreshold) EvaluatorOption {
+	return func(e *Evaluator) {
+		e.threshold = t
+	}
+}
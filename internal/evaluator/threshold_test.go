@@ -0,0 +1,111 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// thresholdLog has one actionable warning, one actionable note, a
+// suppressed error and an error that predates the baseline.
+func thresholdLog() *sarif.Log {
+	log := sarif.NewLog("gavel", "0.1.0")
+	conf := map[string]interface{}{"gavel/confidence": 0.5}
+	log.Runs[0].Results = []sarif.Result{
+		{RuleID: "w", Level: "warning", Message: sarif.Message{Text: "w"}, Properties: conf},
+		{RuleID: "n", Level: "note", Message: sarif.Message{Text: "n"}, Properties: conf},
+		{RuleID: "s", Level: "error", Message: sarif.Message{Text: "s"}, Properties: conf,
+			Suppressions: []sarif.SARIFSuppression{{Kind: "external"}}},
+		{RuleID: "u", Level: "error", Message: sarif.Message{Text: "u"}, Properties: conf,
+			BaselineState: sarif.BaselineStateUnchanged},
+	}
+	return log
+}
+
+func TestNewFindingThreshold(t *testing.T) {
+	th, err := NewFindingThreshold("", -1)
+	if err != nil || th != nil {
+		t.Errorf("expected no threshold when unset, got %+v, %v", th, err)
+	}
+	th, err = NewFindingThreshold("warning", -1)
+	if err != nil || *th != (FindingThreshold{FailOn: "warning", MaxFindings: 0}) {
+		t.Errorf("NewFindingThreshold(warning) = %+v, %v", th, err)
+	}
+	th, err = NewFindingThreshold("", 3)
+	if err != nil || *th != (FindingThreshold{FailOn: "note", MaxFindings: 3}) {
+		t.Errorf("NewFindingThreshold(max 3) = %+v, %v", th, err)
+	}
+	if _, err := NewFindingThreshold("critical", -1); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestFindingThreshold_Count(t *testing.T) {
+	log := thresholdLog()
+	tests := []struct {
+		failOn string
+		max    int
+		count  int
+		exceed bool
+	}{
+		{"error", 0, 0, false},
+		{"warning", 0, 1, true},
+		{"note", 1, 2, true},
+		{"note", 2, 2, false},
+	}
+	for _, tt := range tests {
+		th := &FindingThreshold{FailOn: tt.failOn, MaxFindings: tt.max}
+		if got := th.Count(log); got != tt.count {
+			t.Errorf("%+v: Count = %d, want %d", th, got, tt.count)
+		}
+		if got := th.Exceeded(log); got != tt.exceed {
+			t.Errorf("%+v: Exceeded = %v, want %v", th, got, tt.exceed)
+		}
+	}
+}
+
+func TestEvaluator_FindingThreshold(t *testing.T) {
+	// Without a threshold the warning only requires review.
+	e, err := NewEvaluator(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := e.Evaluate(context.Background(), thresholdLog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Decision != "review" {
+		t.Fatalf("expected review without a threshold, got %q", verdict.Decision)
+	}
+
+	e, err = NewEvaluator(context.Background(), "", WithDecisionTrace(),
+		WithFindingThreshold(&FindingThreshold{FailOn: "warning", MaxFindings: 0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err = e.Evaluate(context.Background(), thresholdLog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Decision != "reject" || verdict.Trace.Rule != "finding-threshold" {
+		t.Errorf("expected reject via finding-threshold, got %q via %q", verdict.Decision, verdict.Trace.Rule)
+	}
+	if got := verdict.Trace.Inputs["threshold_results"]; got != 1 {
+		t.Errorf("threshold_results = %d, want 1", got)
+	}
+
+	// Within the allowance the default rules decide.
+	e, err = NewEvaluator(context.Background(), "",
+		WithFindingThreshold(&FindingThreshold{FailOn: "note", MaxFindings: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err = e.Evaluate(context.Background(), thresholdLog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Decision != "review" {
+		t.Errorf("expected review within the allowance, got %q", verdict.Decision)
+	}
+}
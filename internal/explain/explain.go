@@ -0,0 +1,266 @@
+// Package explain produces an in-depth explanation of a single stored
+// finding: what the problem is, whether it can be exploited, and a concrete
+// patch, by asking the LLM about the finding against the current code.
+package explain
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/fix"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// minFingerprintPrefix is the shortest fingerprint prefix Find accepts.
+const minFingerprintPrefix = 6
+
+// Find returns the result in log that ref identifies. ref is a fingerprint,
+// or an unambiguous prefix of one, from a result's fingerprints or
+// partialFingerprints, or a location of the form file:line, which matches
+// results whose region covers the line. ruleID, if set, narrows the
+// matches. Suppressed results are found too.
+func Find(log *sarif.Log, ref, ruleID string) (sarif.Result, error) {
+	if len(log.Runs) == 0 {
+		return sarif.Result{}, fmt.Errorf("no finding matches %s: the result has no runs", ref)
+	}
+	results := log.Runs[0].Results
+
+	var matches []sarif.Result
+	if file, line, ok := parseLocation(ref); ok {
+		for _, r := range results {
+			if resultFile(r) != "" && sameFile(resultFile(r), file) && covers(r, line) {
+				matches = append(matches, r)
+			}
+		}
+	} else {
+		if len(ref) < minFingerprintPrefix {
+			return sarif.Result{}, fmt.Errorf("%q is not a file:line location or a fingerprint of at least %d characters", ref, minFingerprintPrefix)
+		}
+		for _, r := range results {
+			if hasFingerprint(r, ref) {
+				matches = append(matches, r)
+			}
+		}
+	}
+
+	if ruleID != "" {
+		kept := matches[:0]
+		for _, r := range matches {
+			if r.RuleID == ruleID {
+				kept = append(kept, r)
+			}
+		}
+		matches = kept
+	}
+
+	switch len(matches) {
+	case 0:
+		return sarif.Result{}, fmt.Errorf("no finding matches %s", ref)
+	case 1:
+		return matches[0], nil
+	}
+	var ids []string
+	for _, r := range matches {
+		ids = append(ids, fmt.Sprintf("%s at %s:%d", r.RuleID, resultFile(r), startLine(r)))
+	}
+	sort.Strings(ids)
+	return sarif.Result{}, fmt.Errorf("%d findings match %s (%s); narrow it with a longer fingerprint or the rule ID", len(matches), ref, strings.Join(ids, ", "))
+}
+
+// parseLocation splits "file:line". Windows drive letters and fingerprints
+// have no numeric suffix, so they are not mistaken for locations.
+func parseLocation(ref string) (string, int, bool) {
+	i := strings.LastIndex(ref, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(ref[i+1:])
+	if err != nil || line < 1 {
+		return "", 0, false
+	}
+	return ref[:i], line, true
+}
+
+// sameFile reports whether a result's artifact URI names file, allowing
+// either to be relative to the other.
+func sameFile(uri, file string) bool {
+	uri = path.Clean(strings.TrimPrefix(uri, "file://"))
+	file = path.Clean(strings.ReplaceAll(file, "\\", "/"))
+	return uri == file || strings.HasSuffix(uri, "/"+file) || strings.HasSuffix(file, "/"+uri)
+}
+
+func covers(r sarif.Result, line int) bool {
+	start := startLine(r)
+	end := r.Locations[0].PhysicalLocation.Region.EndLine
+	if end < start {
+		end = start
+	}
+	return line >= start && line <= end
+}
+
+func hasFingerprint(r sarif.Result, ref string) bool {
+	for _, fps := range []map[string]string{r.Fingerprints, r.PartialFingerprints} {
+		for _, fp := range fps {
+			if strings.HasPrefix(fp, ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func resultFile(r sarif.Result) string {
+	if len(r.Locations) == 0 {
+		return ""
+	}
+	return r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+}
+
+func startLine(r sarif.Result) int {
+	if len(r.Locations) == 0 {
+		return 0
+	}
+	return r.Locations[0].PhysicalLocation.Region.StartLine
+}
+
+// explainPolicy is passed as the policy text, so the analysis prompt asks
+// for a single assessment of the listed finding instead of new findings.
+const explainPolicy = `- explain [error]: Another analysis reported the finding described in the additional context against this file. Study it against the code and report exactly one finding with ruleId EXPLAIN. In explanation, explain in depth what the problem is, why it matters here, and how it could be triggered, referring to the code by line. Set level to how exploitable or harmful it is in this code: error if an attacker or ordinary use can trigger it, warning if it needs unusual conditions, note if it cannot realistically cause harm. In message, give that assessment in one sentence. Set confidence to the probability (0.0 to 1.0) that the finding is a real problem. Set startLine and endLine to the lines the fix replaces, fixReplacementText to the complete replacement for exactly those lines, and recommendation to a short description of the fix.
+`
+
+// Request is the LLM call that explains a finding. Code, Policy and Context
+// are passed to the analyzer's AnalyzeCode as the code, the policy text and
+// the additional context.
+type Request struct {
+	Result  sarif.Result
+	Rule    *sarif.ReportingDescriptor
+	Code    string
+	Policy  string
+	Context string
+}
+
+// NewRequest builds the request that explains r, whose file currently holds
+// code. rule is r's rule descriptor from the SARIF log, or nil.
+func NewRequest(r sarif.Result, rule *sarif.ReportingDescriptor, code string) *Request {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Finding to explain:\nRule: %s\nLevel: %s\nFile: %s\n", r.RuleID, r.Level, resultFile(r))
+	if len(r.Locations) > 0 {
+		region := r.Locations[0].PhysicalLocation.Region
+		fmt.Fprintf(&b, "Lines: %d-%d\n", region.StartLine, max(region.EndLine, region.StartLine))
+	}
+	fmt.Fprintf(&b, "Message: %s\n", r.Message.Text)
+	if rule != nil && rule.ShortDescription.Text != "" {
+		fmt.Fprintf(&b, "Rule description: %s\n", rule.ShortDescription.Text)
+	}
+	for _, key := range []string{"gavel/explanation", "gavel/recommendation"} {
+		if v, ok := r.Properties[key].(string); ok && v != "" {
+			fmt.Fprintf(&b, "Earlier %s: %s\n", strings.TrimPrefix(key, "gavel/"), v)
+		}
+	}
+	if cwes := sarif.StringsProperty(r, "gavel/cwe"); len(cwes) > 0 {
+		fmt.Fprintf(&b, "CWE: %s\n", strings.Join(cwes, ", "))
+	}
+	return &Request{Result: r, Rule: rule, Code: code, Policy: explainPolicy, Context: b.String()}
+}
+
+// Answer is the part of an analyzer finding the explanation is built from.
+type Answer struct {
+	RuleID             string
+	Level              string
+	Message            string
+	Explanation        string
+	Recommendation     string
+	Confidence         float64
+	StartLine          int
+	EndLine            int
+	FixReplacementText string
+}
+
+// Exploitability ratings, from the level the model assigns.
+const (
+	Exploitable    = "exploitable"
+	Conditional    = "conditional"
+	NotExploitable = "unlikely"
+)
+
+var exploitability = map[string]string{"error": Exploitable, "warning": Conditional, "note": NotExploitable}
+
+// Explanation is the in-depth account of a finding.
+type Explanation struct {
+	RuleID         string     `json:"rule_id"`
+	Level          string     `json:"level"`
+	File           string     `json:"file"`
+	StartLine      int        `json:"start_line"`
+	EndLine        int        `json:"end_line"`
+	Message        string     `json:"message"`
+	Explanation    string     `json:"explanation"`
+	Exploitability string     `json:"exploitability"` // exploitable, conditional or unlikely
+	Assessment     string     `json:"assessment"`
+	Confidence     float64    `json:"confidence"`
+	Recommendation string     `json:"recommendation,omitempty"`
+	Patch          *sarif.Fix `json:"patch,omitempty"`
+	Diff           string     `json:"diff,omitempty"`
+}
+
+// Explain turns the model's answers into an Explanation. It uses the answer
+// for the EXPLAIN rule, or the first answer if the model named it
+// differently. root is the directory the finding's file is resolved
+// against to render the patch as a diff.
+func (req *Request) Explain(answers []Answer, root string) (*Explanation, error) {
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("the model did not explain the finding")
+	}
+	a := answers[0]
+	for _, ans := range answers {
+		if strings.EqualFold(strings.TrimSpace(ans.RuleID), "EXPLAIN") {
+			a = ans
+			break
+		}
+	}
+
+	r := req.Result
+	e := &Explanation{
+		RuleID:         r.RuleID,
+		Level:          r.Level,
+		File:           resultFile(r),
+		StartLine:      startLine(r),
+		Message:        r.Message.Text,
+		Explanation:    strings.TrimSpace(a.Explanation),
+		Exploitability: exploitability[strings.ToLower(a.Level)],
+		Assessment:     strings.TrimSpace(a.Message),
+		Confidence:     min(max(a.Confidence, 0), 1),
+		Recommendation: strings.TrimSpace(a.Recommendation),
+	}
+	if len(r.Locations) > 0 {
+		e.EndLine = max(r.Locations[0].PhysicalLocation.Region.EndLine, e.StartLine)
+	}
+	if e.Exploitability == "" {
+		e.Exploitability = Conditional
+	}
+
+	if a.FixReplacementText != "" && e.File != "" {
+		start, end := a.StartLine, a.EndLine
+		if start < 1 {
+			start, end = e.StartLine, e.EndLine
+		}
+		e.Patch = &sarif.Fix{
+			Description: sarif.Message{Text: e.Recommendation},
+			ArtifactChanges: []sarif.ArtifactChange{{
+				ArtifactLocation: sarif.ArtifactLocation{URI: e.File},
+				Replacements: []sarif.Replacement{{
+					DeletedRegion:   sarif.Region{StartLine: start, EndLine: max(end, start)},
+					InsertedContent: &sarif.ArtifactContent{Text: a.FixReplacementText},
+				}},
+			}},
+		}
+		diff, err := fix.Preview(root, *e.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("rendering patch: %w", err)
+		}
+		e.Diff = diff
+	}
+	return e, nil
+}
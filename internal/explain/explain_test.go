@@ -0,0 +1,158 @@
+package explain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func result(rule, file string, start, end int, fingerprint string) sarif.Result {
+	r := sarif.Result{
+		RuleID:  rule,
+		Level:   "error",
+		Message: sarif.Message{Text: rule + " message"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: file},
+			Region:           sarif.Region{StartLine: start, EndLine: end},
+		}}},
+	}
+	if fingerprint != "" {
+		r.Fingerprints = map[string]string{sarif.ContentFingerprintV1: fingerprint}
+	}
+	return r
+}
+
+func testLog() *sarif.Log {
+	return &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		result("sql-injection", "internal/db/query.go", 10, 12, "a1b2c3d4e5f6"),
+		result("error-handling", "internal/db/query.go", 11, 11, "a1b2c3ffffff"),
+		result("hardcoded-secret", "cmd/main.go", 4, 0, "0f0f0f0f0f0f"),
+	}}}}
+}
+
+func TestFind(t *testing.T) {
+	log := testLog()
+	for _, tc := range []struct {
+		ref, rule, want string
+	}{
+		{"a1b2c3d4", "", "sql-injection"},
+		{"0f0f0f0f0f0f", "", "hardcoded-secret"},
+		{"cmd/main.go:4", "", "hardcoded-secret"},
+		{"main.go:4", "", "hardcoded-secret"},
+		{"/work/repo/internal/db/query.go:10", "", "sql-injection"},
+		{"internal/db/query.go:11", "error-handling", "error-handling"},
+		{"a1b2c3", "sql-injection", "sql-injection"},
+	} {
+		got, err := Find(log, tc.ref, tc.rule)
+		if err != nil || got.RuleID != tc.want {
+			t.Errorf("Find(%q, %q) = %s, %v, want %s", tc.ref, tc.rule, got.RuleID, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct{ ref, wantErr string }{
+		{"internal/db/query.go:11", "2 findings match"},
+		{"a1b2c3", "2 findings match"},
+		{"a1b2", "at least 6 characters"},
+		{"cmd/main.go:5", "no finding matches"},
+		{"deadbeef", "no finding matches"},
+	} {
+		if _, err := Find(log, tc.ref, ""); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("Find(%q) error = %v, want %q", tc.ref, err, tc.wantErr)
+		}
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	r := result("sql-injection", "query.go", 3, 3, "")
+	r.Properties = map[string]interface{}{
+		"gavel/explanation": "user input reaches the query",
+		"gavel/cwe":         []interface{}{"CWE-89"},
+	}
+	rule := &sarif.ReportingDescriptor{ID: "sql-injection", ShortDescription: sarif.Message{Text: "SQL built from input"}}
+
+	req := NewRequest(r, rule, "package db\n")
+	for _, want := range []string{"Rule: sql-injection", "Lines: 3-3", "Rule description: SQL built from input", "Earlier explanation: user input reaches the query", "CWE: CWE-89"} {
+		if !strings.Contains(req.Context, want) {
+			t.Errorf("context missing %q:\n%s", want, req.Context)
+		}
+	}
+	if req.Code != "package db\n" || !strings.Contains(req.Policy, "ruleId EXPLAIN") {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	root := t.TempDir()
+	src := "package db\n\nfunc q(id string) string {\n\treturn \"SELECT * FROM t WHERE id = \" + id\n}\n"
+	os.WriteFile(filepath.Join(root, "query.go"), []byte(src), 0644)
+	req := NewRequest(result("sql-injection", "query.go", 4, 4, ""), nil, src)
+
+	e, err := req.Explain([]Answer{
+		{RuleID: "other", Level: "note"},
+		{RuleID: "EXPLAIN", Level: "error", Message: " Any caller can inject SQL. ", Explanation: "The id is concatenated.", Recommendation: "Use a placeholder", Confidence: 1.4, StartLine: 4, EndLine: 4, FixReplacementText: "\treturn \"SELECT * FROM t WHERE id = ?\""},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Exploitability != Exploitable || e.Assessment != "Any caller can inject SQL." || e.Confidence != 1 {
+		t.Errorf("unexpected assessment %+v", e)
+	}
+	if e.Patch == nil || !strings.Contains(e.Diff, "-\treturn \"SELECT * FROM t WHERE id = \" + id") || !strings.Contains(e.Diff, "+\treturn \"SELECT * FROM t WHERE id = ?\"") {
+		t.Errorf("unexpected diff:\n%s", e.Diff)
+	}
+
+	if _, err := req.Explain(nil, root); err == nil {
+		t.Error("expected an error when the model returns nothing")
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	src := "package db\n\n// q builds the query\nfunc q(id string) string {\n\treturn \"SELECT \" + id\n}\n"
+	e := &Explanation{
+		RuleID: "sql-injection", Level: "error", File: "query.go", StartLine: 5, EndLine: 5,
+		Message: "SQL built from input", Explanation: "The id is concatenated.",
+		Exploitability: Exploitable, Assessment: "Any caller can inject SQL.", Confidence: 0.9,
+		Recommendation: "Use a placeholder", Diff: "--- query.go\n@@ lines 5-5 @@\n-old\n+new\n",
+	}
+	out := Render(e, []byte(src))
+	for _, want := range []string{
+		"error sql-injection  query.go:5",
+		"  > 5 │ \treturn \"SELECT \" + id",
+		"    2 │ ",
+		"Exploitability: exploitable (confidence 0.90)",
+		"The id is concatenated.",
+		"    +new",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Error("expected no escape codes with NO_COLOR set")
+	}
+}
+
+func TestHighlightLine_ClipsSpansToTheLine(t *testing.T) {
+	// A raw string spanning lines 2-3 and a comment on line 3
+	src := "package p\nvar s = `a\nb` // c\n"
+	str, comment := strings.Index(src, "`a"), strings.Index(src, "//")
+	spans := []astcheck.HighlightSpan{{Start: str, End: str + 5, Kind: astcheck.TokenString}, {Start: comment, End: comment + 4, Kind: astcheck.TokenComment}}
+	s := newStyles()
+	for _, tc := range []struct {
+		text   string
+		offset int
+	}{
+		{"var s = `a", 10},
+		{"b` // c", 21},
+	} {
+		// Test output has no colour profile, so styling must leave the text intact
+		if got := highlightLine(s, tc.text, tc.offset, spans); got != tc.text {
+			t.Errorf("highlightLine(%q) = %q", tc.text, got)
+		}
+	}
+}
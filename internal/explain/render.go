@@ -0,0 +1,176 @@
+package explain
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+)
+
+// contextLines is how many lines around the finding the snippet shows.
+const contextLines = 3
+
+type styles struct {
+	header, dim, marker, added, removed lipgloss.Style
+	level                               map[string]lipgloss.Style
+	token                               map[astcheck.TokenKind]lipgloss.Style
+}
+
+func newStyles() styles {
+	if os.Getenv("NO_COLOR") != "" {
+		plain := lipgloss.NewStyle()
+		return styles{header: plain, dim: plain, marker: plain, added: plain, removed: plain}
+	}
+	return styles{
+		header:  lipgloss.NewStyle().Bold(true),
+		dim:     lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+		marker:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+		added:   lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+		removed: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+		level: map[string]lipgloss.Style{
+			"error":        lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+			"warning":      lipgloss.NewStyle().Foreground(lipgloss.Color("11")),
+			"note":         lipgloss.NewStyle().Foreground(lipgloss.Color("12")),
+			Exploitable:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+			Conditional:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+			NotExploitable: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")),
+		},
+		token: map[astcheck.TokenKind]lipgloss.Style{
+			astcheck.TokenKeyword: lipgloss.NewStyle().Foreground(lipgloss.Color("13")),
+			astcheck.TokenString:  lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+			astcheck.TokenNumber:  lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+			astcheck.TokenComment: lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+			astcheck.TokenType:    lipgloss.NewStyle().Foreground(lipgloss.Color("12")),
+		},
+	}
+}
+
+func (s styles) forLevel(level string) lipgloss.Style {
+	if st, ok := s.level[level]; ok {
+		return st
+	}
+	return lipgloss.NewStyle()
+}
+
+// Render formats e for the terminal: the finding, the surrounding code with
+// syntax highlighting, the exploitability assessment, the explanation and
+// the suggested patch as a diff. source is the current content of the
+// finding's file, or nil to leave out the snippet. Respects the NO_COLOR
+// environment variable (https://no-color.org/).
+func Render(e *Explanation, source []byte) string {
+	s := newStyles()
+	var b strings.Builder
+
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "  %s %s  %s\n", s.forLevel(e.Level).Render(e.Level), s.header.Render(e.RuleID), s.dim.Render(fmt.Sprintf("%s:%d", e.File, e.StartLine)))
+	fmt.Fprintf(&b, "  %s\n", e.Message)
+
+	if snippet := renderSnippet(s, e, source); snippet != "" {
+		b.WriteString("\n" + snippet)
+	}
+
+	fmt.Fprintf(&b, "\n  %s %s %s\n", s.header.Render("Exploitability:"), s.forLevel(e.Exploitability).Render(e.Exploitability), s.dim.Render(fmt.Sprintf("(confidence %.2f)", e.Confidence)))
+	if e.Assessment != "" {
+		b.WriteString(indent(e.Assessment))
+	}
+
+	if e.Explanation != "" {
+		b.WriteString("\n  " + s.header.Render("Explanation") + "\n")
+		b.WriteString(indent(e.Explanation))
+	}
+
+	if e.Recommendation != "" || e.Diff != "" {
+		b.WriteString("\n  " + s.header.Render("Suggested patch") + "\n")
+		if e.Recommendation != "" {
+			b.WriteString(indent(e.Recommendation))
+		}
+		if e.Diff != "" {
+			b.WriteString("\n")
+			for _, l := range strings.Split(strings.TrimSuffix(e.Diff, "\n"), "\n") {
+				switch {
+				case strings.HasPrefix(l, "---"), strings.HasPrefix(l, "@@"):
+					l = s.dim.Render(l)
+				case strings.HasPrefix(l, "-"):
+					l = s.removed.Render(l)
+				case strings.HasPrefix(l, "+"):
+					l = s.added.Render(l)
+				}
+				b.WriteString("    " + l + "\n")
+			}
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// renderSnippet shows the finding's lines with a few lines of context,
+// numbered and highlighted, marking the lines the finding covers.
+func renderSnippet(s styles, e *Explanation, source []byte) string {
+	if len(source) == 0 || e.StartLine < 1 {
+		return ""
+	}
+	lines := strings.SplitAfter(string(source), "\n")
+	if e.StartLine > len(lines) {
+		return ""
+	}
+	first := max(e.StartLine-contextLines, 1)
+	last := min(max(e.EndLine, e.StartLine)+contextLines, len(lines))
+
+	var spans []astcheck.HighlightSpan
+	if s.token != nil {
+		spans = astcheck.Highlight(e.File, source)
+	}
+
+	offset := 0
+	for i := 0; i < first-1; i++ {
+		offset += len(lines[i])
+	}
+	width := len(fmt.Sprint(last))
+
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		line := lines[n-1]
+		text := strings.TrimRight(line, "\r\n")
+		marker := " "
+		if n >= e.StartLine && n <= max(e.EndLine, e.StartLine) {
+			marker = s.marker.Render(">")
+		}
+		fmt.Fprintf(&b, "  %s %s %s %s\n", marker, s.dim.Render(fmt.Sprintf("%*d", width, n)), s.dim.Render("│"), highlightLine(s, text, offset, spans))
+		offset += len(line)
+	}
+	return b.String()
+}
+
+// highlightLine styles the parts of text, which starts at byte offset of the
+// file, covered by spans.
+func highlightLine(s styles, text string, offset int, spans []astcheck.HighlightSpan) string {
+	if len(spans) == 0 {
+		return text
+	}
+	end := offset + len(text)
+	var b strings.Builder
+	pos := offset
+	for _, sp := range spans {
+		if sp.End <= pos || sp.Start >= end {
+			continue
+		}
+		start := max(sp.Start, pos)
+		b.WriteString(text[pos-offset : start-offset])
+		stop := min(sp.End, end)
+		b.WriteString(s.token[sp.Kind].Render(text[start-offset : stop-offset]))
+		pos = stop
+	}
+	b.WriteString(text[pos-offset:])
+	return b.String()
+}
+
+func indent(text string) string {
+	var b strings.Builder
+	for _, l := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString("  " + l + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package fix
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Conflict is a candidate that Check held back, and why.
+type Conflict struct {
+	Candidate Candidate
+	Reason    string
+}
+
+// Check splits candidates into those that can be applied together and
+// those that conflict. A candidate conflicts when its file is missing or
+// outside root, when the finding's recorded snippet no longer matches the
+// file (the code changed since the analysis, so the fix would land on the
+// wrong lines), or when one of its replacements overlaps a replacement of
+// an earlier candidate that was kept. Earlier candidates win, so callers
+// list the fixes they trust most first.
+func Check(root string, candidates []Candidate) ([]Candidate, []Conflict) {
+	type span struct {
+		path       string
+		start, end int
+	}
+	contents := map[string][]string{}
+	var taken []span
+
+	var ok []Candidate
+	var conflicts []Conflict
+	for _, c := range candidates {
+		reason := ""
+		var claimed []span
+		for _, ch := range c.Fix.ArtifactChanges {
+			path, err := ResolvePath(root, ch.ArtifactLocation.URI)
+			if err != nil {
+				reason = err.Error()
+				break
+			}
+			lines, loaded := contents[path]
+			if !loaded {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					reason = fmt.Sprintf("cannot read %s", ch.ArtifactLocation.URI)
+					break
+				}
+				lines = splitLines(string(data))
+				contents[path] = lines
+			}
+			for _, r := range ch.Replacements {
+				start, end := lineSpan(r.DeletedRegion)
+				if start < 1 || start > len(lines)+1 {
+					reason = fmt.Sprintf("line %d is outside %s (%d lines)", start, ch.ArtifactLocation.URI, len(lines))
+					break
+				}
+				for _, t := range taken {
+					if t.path == path && start <= t.end && t.start <= end {
+						reason = fmt.Sprintf("overlaps another fix at lines %d-%d of %s", t.start, t.end, ch.ArtifactLocation.URI)
+						break
+					}
+				}
+				if reason != "" {
+					break
+				}
+				claimed = append(claimed, span{path, start, end})
+			}
+			if reason != "" {
+				break
+			}
+		}
+		if reason == "" && stale(root, c.Result, contents) {
+			reason = "the code changed since the analysis"
+		}
+		if reason != "" {
+			conflicts = append(conflicts, Conflict{Candidate: c, Reason: reason})
+			continue
+		}
+		taken = append(taken, claimed...)
+		ok = append(ok, c)
+	}
+	return ok, conflicts
+}
+
+// stale reports whether r's recorded snippet differs from its lines on disk,
+// ignoring whitespace. Results without a snippet are never stale.
+func stale(root string, r sarif.Result, contents map[string][]string) bool {
+	if len(r.Locations) == 0 {
+		return false
+	}
+	loc := r.Locations[0].PhysicalLocation
+	if loc.Region.Snippet == nil || strings.TrimSpace(loc.Region.Snippet.Text) == "" {
+		return false
+	}
+	path, err := ResolvePath(root, loc.ArtifactLocation.URI)
+	if err != nil {
+		return true
+	}
+	lines, ok := contents[path]
+	if !ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return true
+		}
+		lines = splitLines(string(data))
+		contents[path] = lines
+	}
+	current := sarif.ExtractSnippet(strings.Join(lines, ""), loc.Region.StartLine, loc.Region.EndLine)
+	return current == nil || normalize(current.Text) != normalize(loc.Region.Snippet.Text)
+}
+
+// normalize trims each line and drops blank lines.
+func normalize(s string) string {
+	var out []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return strings.Join(out, "\n")
+}
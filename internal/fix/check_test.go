@@ -0,0 +1,53 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func located(rule, uri string, start, end int, snippet string) sarif.Result {
+	r := sarif.Result{RuleID: rule, Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+		ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+		Region:           sarif.Region{StartLine: start, EndLine: end},
+	}}}}
+	if snippet != "" {
+		r.Locations[0].PhysicalLocation.Region.Snippet = &sarif.ArtifactContent{Text: snippet}
+	}
+	return r
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("one\ntwo\nthree\nfour\n"), 0o644)
+
+	candidates := []Candidate{
+		{Result: located("first", "a.go", 1, 2, "one\n  two\n"), Fix: fileFix("a.go", replacement(1, 2, "ONE"))},
+		{Result: located("overlap", "a.go", 2, 2, ""), Fix: fileFix("a.go", replacement(2, 3, "X"))},
+		{Result: located("stale", "a.go", 3, 3, "3rd\n"), Fix: fileFix("a.go", replacement(3, 3, "THREE"))},
+		{Result: located("missing", "b.go", 1, 1, ""), Fix: fileFix("b.go", replacement(1, 1, "x"))},
+		{Result: located("escape", "../a.go", 1, 1, ""), Fix: fileFix("../a.go", replacement(1, 1, "x"))},
+		{Result: located("last", "a.go", 4, 4, "four\n"), Fix: fileFix("a.go", replacement(4, 4, "FOUR"))},
+	}
+	ok, conflicts := Check(dir, candidates)
+
+	var kept []string
+	for _, c := range ok {
+		kept = append(kept, c.Result.RuleID)
+	}
+	if strings.Join(kept, ",") != "first,last" {
+		t.Errorf("kept %v, want first,last", kept)
+	}
+	want := map[string]string{"overlap": "overlaps another fix at lines 1-2", "stale": "code changed", "missing": "cannot read b.go", "escape": "../a.go is outside"}
+	if len(conflicts) != len(want) {
+		t.Fatalf("conflicts = %+v", conflicts)
+	}
+	for _, c := range conflicts {
+		if !strings.Contains(c.Reason, want[c.Candidate.Result.RuleID]) {
+			t.Errorf("%s: reason %q, want %q", c.Candidate.Result.RuleID, c.Reason, want[c.Candidate.Result.RuleID])
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Package fix applies the structured SARIF fixes attached to findings to the
+// files on disk.
+//
+// Replacement regions are line-based, matching how the LSP server exposes
+// fixes: a replacement deletes lines StartLine through EndLine (inclusive)
+// and inserts its content in their place. Column information is not used.
+package fix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Candidate is a single applicable fix together with the finding it
+// remediates.
+type Candidate struct {
+	Result    sarif.Result
+	Fix       sarif.Fix
+	Generated bool // written by the LLM for a finding that carried no fix
+}
+
+// Candidates returns every fix in log that can be applied: one candidate per
+// fix on results that are not suppressed, informational or resolved since a
+// baseline.
+// Fixes without any replacements are skipped.
+func Candidates(log *sarif.Log) []Candidate {
+	var out []Candidate
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 || r.BaselineState == sarif.BaselineStateAbsent || r.Kind == sarif.KindInformational {
+				continue
+			}
+			for _, f := range r.Fixes {
+				if hasReplacements(f) {
+					out = append(out, Candidate{Result: r, Fix: f})
+				}
+			}
+		}
+	}
+	return out
+}
+
+func hasReplacements(f sarif.Fix) bool {
+	for _, c := range f.ArtifactChanges {
+		if len(c.Replacements) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Applied reports the outcome of Apply.
+type Applied struct {
+	Files        []string // files that were rewritten, sorted
+	Replacements int      // total replacements written
+}
+
+// Apply writes fixes to disk. Relative artifact URIs are resolved against
+// root, and a URI outside root is an error. All files are computed before
+// anything is written, so a conflict (overlapping replacements in one
+// file) or an unreadable file leaves the tree untouched.
+func Apply(root string, fixes []sarif.Fix) (Applied, error) {
+	byFile := make(map[string][]sarif.Replacement)
+	for _, f := range fixes {
+		for _, c := range f.ArtifactChanges {
+			path, err := ResolvePath(root, c.ArtifactLocation.URI)
+			if err != nil {
+				return Applied{}, err
+			}
+			byFile[path] = append(byFile[path], c.Replacements...)
+		}
+	}
+
+	type pending struct {
+		content string
+		mode    os.FileMode
+	}
+	updates := make(map[string]pending, len(byFile))
+	var applied Applied
+	for path, repls := range byFile {
+		info, err := os.Stat(path)
+		if err != nil {
+			return Applied{}, fmt.Errorf("reading %s: %w", path, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Applied{}, fmt.Errorf("reading %s: %w", path, err)
+		}
+		content, err := ApplyToContent(string(data), repls)
+		if err != nil {
+			return Applied{}, fmt.Errorf("%s: %w", path, err)
+		}
+		updates[path] = pending{content: content, mode: info.Mode().Perm()}
+		applied.Replacements += len(repls)
+	}
+
+	for path, u := range updates {
+		if err := os.WriteFile(path, []byte(u.content), u.mode); err != nil {
+			return Applied{}, fmt.Errorf("writing %s: %w", path, err)
+		}
+		applied.Files = append(applied.Files, path)
+	}
+	sort.Strings(applied.Files)
+	return applied, nil
+}
+
+// ApplyToContent applies line-based replacements to content. Replacements
+// may be given in any order but must not overlap.
+func ApplyToContent(content string, repls []sarif.Replacement) (string, error) {
+	sorted := make([]sarif.Replacement, len(repls))
+	copy(sorted, repls)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DeletedRegion.StartLine > sorted[j].DeletedRegion.StartLine
+	})
+
+	lines := splitLines(content)
+	prevStart := len(lines) + 1
+	for _, r := range sorted {
+		start, end := lineSpan(r.DeletedRegion)
+		if start < 1 || start > len(lines)+1 {
+			return "", fmt.Errorf("replacement at line %d is outside the file (%d lines)", start, len(lines))
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+		if end >= prevStart {
+			return "", fmt.Errorf("overlapping replacements at lines %d-%d", start, end)
+		}
+		prevStart = start
+
+		inserted := insertedLines(r, end == len(lines) && !strings.HasSuffix(content, "\n"))
+		tail := append(inserted, lines[end:]...)
+		lines = append(lines[:start-1], tail...)
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// Preview renders fix as a line diff against the files under root: a header
+// per artifact, then removed lines prefixed with "-" and inserted lines
+// prefixed with "+".
+func Preview(root string, f sarif.Fix) (string, error) {
+	var b strings.Builder
+	for _, c := range f.ArtifactChanges {
+		path, err := ResolvePath(root, c.ArtifactLocation.URI)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", path, err)
+		}
+		lines := splitLines(string(data))
+		fmt.Fprintf(&b, "--- %s\n", c.ArtifactLocation.URI)
+		for _, r := range c.Replacements {
+			start, end := lineSpan(r.DeletedRegion)
+			if end > len(lines) {
+				end = len(lines)
+			}
+			fmt.Fprintf(&b, "@@ lines %d-%d @@\n", start, end)
+			for i := start; i <= end && i >= 1; i++ {
+				b.WriteString("-" + strings.TrimSuffix(lines[i-1], "\n") + "\n")
+			}
+			for _, l := range insertedLines(r, false) {
+				b.WriteString("+" + strings.TrimSuffix(l, "\n") + "\n")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// ResolvePath returns the file under root that an artifact URI names.
+// Relative URIs are resolved against root. A URI that lands outside root,
+// whether absolute or through "..", is an error, so a SARIF log cannot
+// direct a fix at arbitrary files.
+func ResolvePath(root, uri string) (string, error) {
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", root, err)
+	}
+	path := filepath.FromSlash(strings.TrimPrefix(uri, "file://"))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(absRoot, path)
+	}
+	rel, err := filepath.Rel(absRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside %s", uri, root)
+	}
+	return filepath.Join(root, rel), nil
+}
+
+// lineSpan returns the inclusive 1-based line range a region deletes. A
+// missing end line means a single-line region.
+func lineSpan(r sarif.Region) (int, int) {
+	end := r.EndLine
+	if end < r.StartLine {
+		end = r.StartLine
+	}
+	return r.StartLine, end
+}
+
+// splitLines splits content into lines that keep their trailing newline.
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// insertedLines splits a replacement's inserted text into newline-terminated
+// lines. noFinalNewline drops the terminator from the last line, used when
+// the replaced region ran to the end of a file without a trailing newline.
+func insertedLines(r sarif.Replacement, noFinalNewline bool) []string {
+	if r.InsertedContent == nil || r.InsertedContent.Text == "" {
+		return nil
+	}
+	text := r.InsertedContent.Text
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	lines := splitLines(text)
+	if noFinalNewline {
+		lines[len(lines)-1] = strings.TrimSuffix(lines[len(lines)-1], "\n")
+	}
+	return lines
+}
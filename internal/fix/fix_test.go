@@ -0,0 +1,169 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func replacement(start, end int, text string) sarif.Replacement {
+	r := sarif.Replacement{DeletedRegion: sarif.Region{StartLine: start, EndLine: end}}
+	if text != "" {
+		r.InsertedContent = &sarif.ArtifactContent{Text: text}
+	}
+	return r
+}
+
+func fileFix(uri string, repls ...sarif.Replacement) sarif.Fix {
+	return sarif.Fix{ArtifactChanges: []sarif.ArtifactChange{{
+		ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+		Replacements:     repls,
+	}}}
+}
+
+func TestApplyToContent(t *testing.T) {
+	content := "a\nb\nc\nd\n"
+	tests := []struct {
+		name  string
+		repls []sarif.Replacement
+		want  string
+	}{
+		{"single line", []sarif.Replacement{replacement(2, 2, "B")}, "a\nB\nc\nd\n"},
+		{"multi line shrink", []sarif.Replacement{replacement(2, 3, "BC\n")}, "a\nBC\nd\n"},
+		{"grow", []sarif.Replacement{replacement(1, 1, "x\ny")}, "x\ny\nb\nc\nd\n"},
+		{"delete", []sarif.Replacement{replacement(3, 3, "")}, "a\nb\nd\n"},
+		{"unordered", []sarif.Replacement{replacement(1, 1, "A"), replacement(4, 4, "D")}, "A\nb\nc\nD\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ApplyToContent(content, tc.repls)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyToContent_NoTrailingNewline(t *testing.T) {
+	got, err := ApplyToContent("a\nb", []sarif.Replacement{replacement(2, 2, "B")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "a\nB" {
+		t.Errorf("got %q, want %q", got, "a\nB")
+	}
+}
+
+func TestApplyToContent_Errors(t *testing.T) {
+	if _, err := ApplyToContent("a\nb\nc\n", []sarif.Replacement{replacement(1, 2, "x"), replacement(2, 3, "y")}); err == nil || !strings.Contains(err.Error(), "overlapping") {
+		t.Errorf("expected overlap error, got %v", err)
+	}
+	if _, err := ApplyToContent("a\n", []sarif.Replacement{replacement(5, 5, "x")}); err == nil {
+		t.Error("expected out-of-range error")
+	}
+}
+
+func TestApply_WritesFilesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.go")
+	if err := os.WriteFile(good, []byte("one\ntwo\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A missing file in the same batch must prevent any write.
+	_, err := Apply(dir, []sarif.Fix{fileFix("good.go", replacement(1, 1, "ONE")), fileFix("missing.go", replacement(1, 1, "x"))})
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+	if data, _ := os.ReadFile(good); string(data) != "one\ntwo\n" {
+		t.Errorf("expected good.go untouched after failed batch, got %q", data)
+	}
+
+	applied, err := Apply(dir, []sarif.Fix{fileFix("good.go", replacement(1, 1, "ONE")), fileFix("good.go", replacement(2, 2, "TWO"))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(good); string(data) != "ONE\nTWO\n" {
+		t.Errorf("got %q", data)
+	}
+	if applied.Replacements != 2 || len(applied.Files) != 1 || applied.Files[0] != good {
+		t.Errorf("unexpected summary %+v", applied)
+	}
+	if info, _ := os.Stat(good); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode preserved, got %v", info.Mode().Perm())
+	}
+}
+
+func TestCandidates_SkipsSuppressedAndAbsent(t *testing.T) {
+	f := fileFix("a.go", replacement(1, 1, "x"))
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		{RuleID: "keep", Fixes: []sarif.Fix{f}},
+		{RuleID: "suppressed", Fixes: []sarif.Fix{f}, Suppressions: []sarif.SARIFSuppression{{Kind: "external"}}},
+		{RuleID: "absent", Fixes: []sarif.Fix{f}, BaselineState: sarif.BaselineStateAbsent},
+		{RuleID: "nofix"},
+		{RuleID: "empty", Fixes: []sarif.Fix{{Description: sarif.Message{Text: "no changes"}}}},
+	}
+
+	got := Candidates(log)
+	if len(got) != 1 || got[0].Result.RuleID != "keep" {
+		t.Errorf("expected only keep, got %+v", got)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("x := 1\ny := 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Preview(dir, fileFix("a.go", replacement(2, 2, "y := two")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--- a.go\n@@ lines 2-2 @@\n-y := 2\n+y := two\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestApply_RejectsPathsOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "repo")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(parent, "outside.go")
+	if err := os.WriteFile(outside, []byte("keep\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inside := filepath.Join(root, "in.go")
+	if err := os.WriteFile(inside, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uri := range []string{"../outside.go", "sub/../../outside.go", outside, "file://" + outside} {
+		if _, err := Apply(root, []sarif.Fix{fileFix(uri, replacement(1, 1, "pwned"))}); err == nil || !strings.Contains(err.Error(), "outside") {
+			t.Errorf("Apply(%q): expected an outside-root error, got %v", uri, err)
+		}
+		if _, err := Preview(root, fileFix(uri, replacement(1, 1, "pwned"))); err == nil {
+			t.Errorf("Preview(%q): expected an outside-root error", uri)
+		}
+	}
+	if data, _ := os.ReadFile(outside); string(data) != "keep\n" {
+		t.Errorf("expected the file outside root untouched, got %q", data)
+	}
+
+	// An absolute URI inside root is still accepted
+	if _, err := Apply(root, []sarif.Fix{fileFix(inside, replacement(1, 1, "new"))}); err != nil {
+		t.Fatalf("unexpected error for an absolute path inside root: %v", err)
+	}
+	if data, _ := os.ReadFile(inside); string(data) != "new\n" {
+		t.Errorf("got %q", data)
+	}
+}
@@ -0,0 +1,119 @@
+package fix
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Unfixed returns the results in log that Candidates would consider but that
+// carry no applicable fix, grouped by artifact URI in the order the files
+// first appear. Results without a file location are left out.
+func Unfixed(log *sarif.Log) (order []string, byFile map[string][]sarif.Result) {
+	byFile = map[string][]sarif.Result{}
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 || r.BaselineState == sarif.BaselineStateAbsent || r.Kind == sarif.KindInformational {
+				continue
+			}
+			if len(r.Locations) == 0 || r.Locations[0].PhysicalLocation.ArtifactLocation.URI == "" {
+				continue
+			}
+			fixed := false
+			for _, f := range r.Fixes {
+				fixed = fixed || hasReplacements(f)
+			}
+			if fixed {
+				continue
+			}
+			uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+			if _, ok := byFile[uri]; !ok {
+				order = append(order, uri)
+			}
+			byFile[uri] = append(byFile[uri], r)
+		}
+	}
+	return order, byFile
+}
+
+// patchPolicy is passed as the policy text, so the analysis prompt asks for
+// one patch per listed finding instead of new findings.
+const patchPolicy = `- patch [error]: Earlier analysis reported the findings listed in the additional context against this file, each with an ID such as F1. Write a minimal fix for each one. Report exactly one finding per listed ID and nothing else: set ruleId to the ID, startLine and endLine to the lines the fix replaces, fixReplacementText to the complete replacement for exactly those lines with the file's indentation, and recommendation to a one-line description of the change. Keep each fix to the lines it needs and do not let fixes for different IDs touch the same lines. If a finding cannot be fixed safely without wider changes, leave fixReplacementText empty and say why in explanation.
+`
+
+// PatchRequest asks the LLM to write fixes for the unfixed findings in one
+// file. Code, Policy and Context are passed to the analyzer's AnalyzeCode as
+// the code, the policy text and the additional context.
+type PatchRequest struct {
+	URI     string
+	Results []sarif.Result
+	Code    string
+	Policy  string
+	Context string
+}
+
+// NewPatchRequest builds the request for results, all in the file uri
+// whose current content is code.
+func NewPatchRequest(uri string, results []sarif.Result, code string) *PatchRequest {
+	var b strings.Builder
+	b.WriteString("Findings to fix:\n")
+	for n, r := range results {
+		region := r.Locations[0].PhysicalLocation.Region
+		fmt.Fprintf(&b, "F%d [%s] lines %d-%d (%s): %s\n", n+1, r.RuleID, region.StartLine, max(region.EndLine, region.StartLine), r.Level, r.Message.Text)
+		if rec, ok := r.Properties["gavel/recommendation"].(string); ok && rec != "" {
+			fmt.Fprintf(&b, "   Suggested approach: %s\n", rec)
+		}
+	}
+	return &PatchRequest{URI: uri, Results: results, Code: code, Policy: patchPolicy, Context: b.String()}
+}
+
+// Patch is the part of an analyzer finding a generated fix is built from.
+type Patch struct {
+	ID                 string
+	StartLine          int
+	EndLine            int
+	FixReplacementText string
+	Recommendation     string
+}
+
+// Candidates turns the model's patches into fix candidates, one per listed
+// finding the model wrote a fix for, in listing order. Patches for unknown
+// IDs, without replacement text, or outside the file are dropped.
+func (req *PatchRequest) Candidates(patches []Patch) []Candidate {
+	byID := map[string]Patch{}
+	for _, p := range patches {
+		id := strings.ToUpper(strings.TrimSpace(p.ID))
+		if _, ok := byID[id]; !ok {
+			byID[id] = p
+		}
+	}
+	lines := len(splitLines(req.Code))
+
+	var out []Candidate
+	for n, r := range req.Results {
+		p, ok := byID[fmt.Sprintf("F%d", n+1)]
+		if !ok || p.FixReplacementText == "" || p.StartLine < 1 || p.StartLine > lines+1 {
+			continue
+		}
+		description := "LLM-generated fix"
+		if d := strings.TrimSpace(p.Recommendation); d != "" {
+			description += ": " + d
+		}
+		out = append(out, Candidate{
+			Result:    r,
+			Generated: true,
+			Fix: sarif.Fix{
+				Description: sarif.Message{Text: description},
+				ArtifactChanges: []sarif.ArtifactChange{{
+					ArtifactLocation: sarif.ArtifactLocation{URI: req.URI},
+					Replacements: []sarif.Replacement{{
+						DeletedRegion:   sarif.Region{StartLine: p.StartLine, EndLine: max(p.EndLine, p.StartLine)},
+						InsertedContent: &sarif.ArtifactContent{Text: p.FixReplacementText},
+					}},
+				}},
+			},
+		})
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package fix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func TestUnfixed(t *testing.T) {
+	hasFix := located("has-fix", "a.go", 1, 1, "")
+	hasFix.Fixes = []sarif.Fix{fileFix("a.go", replacement(1, 1, "x"))}
+	informational := located("informational", "a.go", 1, 1, "")
+	informational.Kind = sarif.KindInformational
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		located("b-first", "b.go", 1, 1, ""),
+		hasFix,
+		located("a-only", "a.go", 2, 2, ""),
+		{RuleID: "no-location"},
+		located("b-second", "b.go", 3, 3, ""),
+		informational,
+	}
+
+	order, byFile := Unfixed(log)
+	if strings.Join(order, ",") != "b.go,a.go" || len(byFile["b.go"]) != 2 || len(byFile["a.go"]) != 1 || byFile["a.go"][0].RuleID != "a-only" {
+		t.Errorf("Unfixed = %v, %+v", order, byFile)
+	}
+}
+
+func TestPatchRequest(t *testing.T) {
+	r1 := located("sql-injection", "q.go", 2, 2, "")
+	r1.Properties = map[string]interface{}{"gavel/recommendation": "use a placeholder"}
+	r2 := located("error-handling", "q.go", 3, 4, "")
+	req := NewPatchRequest("q.go", []sarif.Result{r1, r2}, "package q\nx\ny\nz\n")
+
+	for _, want := range []string{"F1 [sql-injection] lines 2-2", "Suggested approach: use a placeholder", "F2 [error-handling] lines 3-4"} {
+		if !strings.Contains(req.Context, want) {
+			t.Errorf("context missing %q:\n%s", want, req.Context)
+		}
+	}
+
+	got := req.Candidates([]Patch{
+		{ID: " f2 ", StartLine: 3, EndLine: 4, FixReplacementText: "Y", Recommendation: "check the error"},
+		{ID: "F1", StartLine: 2, EndLine: 2}, // no replacement text
+		{ID: "F9", StartLine: 2, EndLine: 2, FixReplacementText: "X"},
+	})
+	if len(got) != 1 || got[0].Result.RuleID != "error-handling" || !got[0].Generated {
+		t.Fatalf("Candidates = %+v", got)
+	}
+	if got[0].Fix.Description.Text != "LLM-generated fix: check the error" || got[0].Fix.ArtifactChanges[0].ArtifactLocation.URI != "q.go" {
+		t.Errorf("unexpected fix %+v", got[0].Fix)
+	}
+}
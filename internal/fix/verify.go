@@ -0,0 +1,85 @@
+package fix
+
+import "github.com/chris-regnier/gavel/internal/sarif"
+
+// Verification statuses.
+const (
+	Resolved     = "resolved"      // the instant tier no longer reports the finding
+	StillPresent = "still-present" // the instant tier reports it in the fixed lines
+	Unverified   = "unverified"    // an LLM finding, which the instant tier cannot re-check
+)
+
+// Verification is the re-check of one applied fix.
+type Verification struct {
+	RuleID    string `json:"rule_id"`
+	File      string `json:"file"`
+	Line      int    `json:"line"` // where the fix's text now starts
+	Generated bool   `json:"generated,omitempty"`
+	Status    string `json:"status"`
+}
+
+// Verify re-checks applied fixes against rerun, the instant-tier results
+// for each modified file keyed by artifact URI. A fix resolved its finding
+// when no rerun result of the same rule overlaps the lines the fix wrote,
+// after shifting for the other fixes applied above it in the same file.
+// Findings that did not come from the instant tier are reported as
+// Unverified.
+func Verify(applied []Candidate, rerun map[string][]sarif.Result) []Verification {
+	byFile := map[string][]sarif.Replacement{}
+	for _, c := range applied {
+		for _, ch := range c.Fix.ArtifactChanges {
+			byFile[ch.ArtifactLocation.URI] = append(byFile[ch.ArtifactLocation.URI], ch.Replacements...)
+		}
+	}
+
+	out := make([]Verification, 0, len(applied))
+	for _, c := range applied {
+		v := Verification{RuleID: c.Result.RuleID, Generated: c.Generated, Status: Resolved}
+		if tier, _ := c.Result.Properties["gavel/tier"].(string); tier != "instant" {
+			v.Status = Unverified
+		}
+		for _, ch := range c.Fix.ArtifactChanges {
+			uri := ch.ArtifactLocation.URI
+			for _, r := range ch.Replacements {
+				start, end := writtenSpan(r, byFile[uri])
+				if v.File == "" {
+					v.File, v.Line = uri, start
+				}
+				if v.Status == Resolved && reports(rerun[uri], c.Result.RuleID, start, end) {
+					v.Status = StillPresent
+				}
+			}
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// writtenSpan returns the lines r's inserted text occupies once all of
+// siblings, the replacements applied to the same file, are in place. A
+// deletion occupies the line after it.
+func writtenSpan(r sarif.Replacement, siblings []sarif.Replacement) (int, int) {
+	start, _ := lineSpan(r.DeletedRegion)
+	shift := 0
+	for _, q := range siblings {
+		qStart, qEnd := lineSpan(q.DeletedRegion)
+		if qEnd < start {
+			shift += len(insertedLines(q, false)) - (qEnd - qStart + 1)
+		}
+	}
+	start += shift
+	return start, start + max(len(insertedLines(r, false)), 1) - 1
+}
+
+func reports(results []sarif.Result, ruleID string, start, end int) bool {
+	for _, r := range results {
+		if r.RuleID != ruleID || len(r.Locations) == 0 {
+			continue
+		}
+		region := r.Locations[0].PhysicalLocation.Region
+		if region.StartLine <= end && max(region.EndLine, region.StartLine) >= start {
+			return true
+		}
+	}
+	return false
+}
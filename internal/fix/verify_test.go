@@ -0,0 +1,42 @@
+package fix
+
+import (
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func TestVerify(t *testing.T) {
+	instant := func(rule string, line int) sarif.Result {
+		r := located(rule, "a.go", line, line, "")
+		r.Properties = map[string]interface{}{"gavel/tier": "instant"}
+		return r
+	}
+	applied := []Candidate{
+		// Grows lines 1-1 to three lines, shifting the later fixes down by two
+		{Result: instant("grown", 1), Fix: fileFix("a.go", replacement(1, 1, "a\nb\nc"))},
+		{Result: instant("resolved", 5), Fix: fileFix("a.go", replacement(5, 5, "fixed"))},
+		{Result: instant("still", 8), Fix: fileFix("a.go", replacement(8, 8, "not fixed"))},
+		{Result: located("llm", "a.go", 9, 9, ""), Fix: fileFix("a.go", replacement(9, 9, "x")), Generated: true},
+	}
+	rerun := map[string][]sarif.Result{"a.go": {
+		located("resolved", "a.go", 5, 5, ""), // where the fix used to be, not where it is now
+		located("still", "a.go", 10, 10, ""),
+	}}
+
+	got := Verify(applied, rerun)
+	want := []Verification{
+		{RuleID: "grown", File: "a.go", Line: 1, Status: Resolved},
+		{RuleID: "resolved", File: "a.go", Line: 7, Status: Resolved},
+		{RuleID: "still", File: "a.go", Line: 10, Status: StillPresent},
+		{RuleID: "llm", File: "a.go", Line: 11, Generated: true, Status: Unverified},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Verify = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Verify[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
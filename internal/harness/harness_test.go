@@ -463,6 +463,17 @@ packages:
 `,
 			wantErr: "baseline variant",
 		},
+		{
+			name: "unknown persona",
+			yaml: `
+variants:
+  - name: baseline
+    persona: no-such-persona
+packages:
+  - internal/mcp
+`,
+			wantErr: "variant baseline: unknown persona",
+		},
 		{
 			name: "target with both path and repo",
 			yaml: `
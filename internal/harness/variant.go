@@ -9,6 +9,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/persona"
 )
 
 // VariantConfig represents a single variant configuration for comparison.
@@ -166,6 +167,9 @@ func (c *HarnessConfig) Validate() error {
 			return fmt.Errorf("duplicate variant name: %s", v.Name)
 		}
 		seenNames[v.Name] = true
+		if v.Persona != "" && !persona.Known(v.Persona) {
+			return fmt.Errorf("variant %s: unknown persona: %s (valid: %s)", v.Name, v.Persona, strings.Join(persona.Names(), ", "))
+		}
 	}
 
 	// Validate baseline exists if specified
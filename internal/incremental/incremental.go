@@ -0,0 +1,156 @@
+// Package incremental lets repeated directory analyses skip unchanged
+// files. After each run it records every analyzed file's content hash
+// together with that file's findings; the next run re-analyzes only the
+// files whose hash changed and reuses the stored findings for the rest.
+//
+// Stored findings are only valid for the settings that produced them, so
+// the state carries a settings fingerprint (config, rules, persona, Gavel
+// version, and whether the LLM tiers ran). A run with a different
+// fingerprint re-analyzes everything.
+package incremental
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Version is the state file format version written by this package.
+const Version = 1
+
+// FileName is the name of the state file inside the state directory.
+const FileName = "incremental.json"
+
+// State is the on-disk record of the last incremental run.
+type State struct {
+	Version int `json:"version"`
+	// Settings fingerprints everything besides file content that affects
+	// findings. Files are only reused when it matches.
+	Settings string               `json:"settings"`
+	Files    map[string]FileState `json:"files"`
+}
+
+// FileState is one analyzed file: its content hash and its findings.
+type FileState struct {
+	Hash    string         `json:"hash"`
+	Results []sarif.Result `json:"results"`
+}
+
+// Plan splits a run's artifacts into those to analyze and the findings
+// reused for the rest.
+type Plan struct {
+	Changed []input.Artifact
+	Reused  []sarif.Result
+	// Unchanged counts the artifacts whose findings were reused.
+	Unchanged int
+}
+
+// ContentHash returns the hash recorded for an artifact's content.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the state in dir. A missing state file yields an empty state,
+// so the first incremental run analyzes everything.
+func Load(dir string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{Version: Version, Files: map[string]FileState{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing incremental state: %w", err)
+	}
+	if s.Version != Version {
+		// An unknown format is discarded rather than misread.
+		return &State{Version: Version, Files: map[string]FileState{}}, nil
+	}
+	if s.Files == nil {
+		s.Files = map[string]FileState{}
+	}
+	return &s, nil
+}
+
+// Save writes s to dir, creating the directory if needed. The file is
+// written to a temporary name and renamed so an interrupted save never
+// leaves a truncated state behind.
+func (s *State) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encoding incremental state: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, FileName+".*")
+	if err != nil {
+		return fmt.Errorf("writing incremental state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing incremental state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing incremental state: %w", err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, FileName))
+}
+
+// Plan compares artifacts against s. When settings differ from the
+// fingerprint s was recorded with, every artifact is changed.
+func (s *State) Plan(artifacts []input.Artifact, settings string) Plan {
+	var p Plan
+	for _, art := range artifacts {
+		prev, ok := s.Files[art.Path]
+		if !ok || s.Settings != settings || prev.Hash != ContentHash(art.Content) {
+			p.Changed = append(p.Changed, art)
+			continue
+		}
+		p.Unchanged++
+		p.Reused = append(p.Reused, prev.Results...)
+	}
+	return p
+}
+
+// Record returns the state for a completed run over artifacts: each
+// artifact's hash and the results located in it. Files that are no longer
+// part of the run are dropped. results should cover every artifact, both
+// freshly analyzed and reused.
+func Record(artifacts []input.Artifact, results []sarif.Result, settings string) *State {
+	byFile := make(map[string][]sarif.Result)
+	for _, r := range results {
+		if len(r.Locations) == 0 {
+			continue
+		}
+		uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+		byFile[uri] = append(byFile[uri], r)
+	}
+
+	s := &State{Version: Version, Settings: settings, Files: make(map[string]FileState, len(artifacts))}
+	for _, art := range artifacts {
+		fileResults := byFile[art.Path]
+		if fileResults == nil {
+			fileResults = []sarif.Result{}
+		}
+		sort.SliceStable(fileResults, func(i, j int) bool {
+			return fileResults[i].Locations[0].PhysicalLocation.Region.StartLine <
+				fileResults[j].Locations[0].PhysicalLocation.Region.StartLine
+		})
+		s.Files[art.Path] = FileState{Hash: ContentHash(art.Content), Results: fileResults}
+	}
+	return s
+}
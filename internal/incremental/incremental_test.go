@@ -0,0 +1,106 @@
+package incremental
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func result(ruleID, uri string, line int) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   "warning",
+		Message: sarif.Message{Text: ruleID + " finding"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region:           sarif.Region{StartLine: line, EndLine: line},
+		}}},
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	s, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p := s.Plan([]input.Artifact{{Path: "a.go", Content: "package a"}}, "settings")
+	if len(p.Changed) != 1 || p.Unchanged != 0 {
+		t.Errorf("expected every file to be analyzed on the first run, got %+v", p)
+	}
+}
+
+func TestRecordPlanRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := []input.Artifact{
+		{Path: "a.go", Content: "package a"},
+		{Path: "b.go", Content: "package b"},
+		{Path: "clean.go", Content: "package clean"},
+	}
+	results := []sarif.Result{result("S1", "a.go", 3), result("S1", "a.go", 1), result("S2", "b.go", 2)}
+	if err := Record(artifacts, results, "v1").Save(dir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	next := []input.Artifact{
+		{Path: "a.go", Content: "package a"},
+		{Path: "b.go", Content: "package b // edited"},
+		{Path: "clean.go", Content: "package clean"},
+		{Path: "new.go", Content: "package new"},
+	}
+	p := s.Plan(next, "v1")
+	if p.Unchanged != 2 {
+		t.Errorf("expected 2 unchanged files, got %d", p.Unchanged)
+	}
+	var changed []string
+	for _, a := range p.Changed {
+		changed = append(changed, a.Path)
+	}
+	if len(changed) != 2 || changed[0] != "b.go" || changed[1] != "new.go" {
+		t.Errorf("expected b.go and new.go to be re-analyzed, got %v", changed)
+	}
+	if len(p.Reused) != 2 || p.Reused[0].Locations[0].PhysicalLocation.Region.StartLine != 1 {
+		t.Errorf("expected a.go's two findings in line order, got %+v", p.Reused)
+	}
+
+	if p := s.Plan(next, "v2"); len(p.Changed) != len(next) || len(p.Reused) != 0 {
+		t.Errorf("expected a settings change to re-analyze everything, got %+v", p)
+	}
+}
+
+func TestRecordDropsRemovedFiles(t *testing.T) {
+	s := Record([]input.Artifact{{Path: "a.go", Content: "x"}}, []sarif.Result{result("S1", "gone.go", 1)}, "v1")
+	if _, ok := s.Files["gone.go"]; ok {
+		t.Error("expected findings in files outside the run to be dropped")
+	}
+	if fs := s.Files["a.go"]; fs.Results == nil || len(fs.Results) != 0 {
+		t.Errorf("expected a.go recorded with no findings, got %+v", fs)
+	}
+}
+
+func TestLoadUnknownVersion(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(`{"version": 99, "settings": "v1", "files": {"a.go": {"hash": "x"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Files) != 0 {
+		t.Errorf("expected an unknown format to be discarded, got %+v", s.Files)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("expected an error for a corrupt state file")
+	}
+}
@@ -0,0 +1,294 @@
+// Package ingest loads SARIF written by other scanners, such as gosec or
+// CodeQL, into a single Gavel run so the Rego gate can evaluate it and the
+// LLM can triage it.
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// DefaultConfidence is the gavel/confidence given to an external result
+// that carries none and whose rule declares no precision. It is below the
+// default gate's reject threshold, so unscored findings ask for review
+// rather than reject.
+const DefaultConfidence = 0.7
+
+// precisionConfidence maps the precision CodeQL and other tools declare on
+// their rules to a confidence
+var precisionConfidence = map[string]float64{
+	"very-high": 0.95,
+	"high":      0.9,
+	"medium":    0.7,
+	"low":       0.5,
+}
+
+// Option configures Load
+type Option func(*loader)
+
+type loader struct {
+	defaultConfidence float64
+}
+
+// WithDefaultConfidence sets the confidence of results that declare none
+func WithDefaultConfidence(c float64) Option {
+	return func(l *loader) {
+		l.defaultConfidence = c
+	}
+}
+
+// Summary counts what Load read
+type Summary struct {
+	Tools      []string `json:"tools"`
+	Results    int      `json:"results"`
+	Duplicates int      `json:"duplicates"`
+}
+
+// The external log is decoded into these, rather than the sarif types
+// alone, to read the rule references and rule properties Gavel does not
+// emit itself
+type rawLog struct {
+	Version string   `json:"version"`
+	Runs    []rawRun `json:"runs"`
+}
+
+type rawRun struct {
+	Tool struct {
+		Driver rawDriver `json:"driver"`
+	} `json:"tool"`
+	Results []rawResult `json:"results"`
+}
+
+type rawDriver struct {
+	sarif.Driver
+	Rules []rawRule `json:"rules"`
+}
+
+type rawRule struct {
+	sarif.ReportingDescriptor
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type rawResult struct {
+	sarif.Result
+	RuleIndex *int `json:"ruleIndex"`
+	Rule      *struct {
+		ID    string `json:"id"`
+		Index *int   `json:"index"`
+	} `json:"rule"`
+}
+
+// LoadFile reads the SARIF log at path. See Load.
+func LoadFile(path, version string, opts ...Option) (*sarif.Log, Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	defer f.Close()
+	return Load(f, version, opts...)
+}
+
+// Load reads a SARIF 2.1.0 log and returns its results as one Gavel run,
+// whatever number of runs and tools it held. Each result records the tool
+// that reported it in gavel/tool and gets a gavel/confidence, taken from
+// the result if it has one, else from its rule's precision, else the
+// default. Results reported more than once, at the same line by the same
+// rule or for the same CWE, are kept once, listing the others in
+// gavel/duplicates.
+func Load(r io.Reader, version string, opts ...Option) (*sarif.Log, Summary, error) {
+	l := &loader{defaultConfidence: DefaultConfidence}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	var raw rawLog
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, Summary{}, fmt.Errorf("parsing SARIF: %w", err)
+	}
+	if raw.Version != sarif.Version {
+		return nil, Summary{}, fmt.Errorf("unsupported SARIF version %q (want %s)", raw.Version, sarif.Version)
+	}
+
+	log := sarif.NewLog("gavel", version)
+	run := &log.Runs[0]
+	var summary Summary
+	tools := map[string]bool{}
+	ruleSeen := map[string]bool{}
+	kept := map[string]int{} // dedup key -> index in run.Results
+
+	for _, rr := range raw.Runs {
+		driver := rr.Tool.Driver
+		tools[driver.Name] = true
+		rules := make(map[string]rawRule, len(driver.Rules))
+		for _, rule := range driver.Rules {
+			rules[rule.ID] = rule
+			if !ruleSeen[rule.ID] {
+				ruleSeen[rule.ID] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule.ReportingDescriptor)
+			}
+		}
+
+		for _, res := range rr.Results {
+			summary.Results++
+			result, rule := l.normalize(res, driver, rules)
+			l.enrich(&result, rule)
+
+			keys := dedupKeys(result)
+			if i, dup := firstKept(kept, keys); dup {
+				summary.Duplicates++
+				addDuplicate(&run.Results[i], result)
+				continue
+			}
+			for _, k := range keys {
+				kept[k] = len(run.Results)
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	for name := range tools {
+		summary.Tools = append(summary.Tools, name)
+	}
+	sort.Strings(summary.Tools)
+	run.Properties = map[string]interface{}{"gavel/ingested": summary.Tools}
+	return log, summary, nil
+}
+
+// normalize resolves a result's rule and fills the fields the gate and
+// other Gavel commands rely on. It returns the rule the result refers to,
+// by index or by ID, if the driver declares it.
+func (l *loader) normalize(res rawResult, driver rawDriver, rules map[string]rawRule) (sarif.Result, rawRule) {
+	result := res.Result
+	var rule rawRule
+
+	index := res.RuleIndex
+	if index == nil && res.Rule != nil {
+		index = res.Rule.Index
+	}
+	if index != nil && *index >= 0 && *index < len(driver.Rules) {
+		rule = driver.Rules[*index]
+	}
+	if result.RuleID == "" && res.Rule != nil {
+		result.RuleID = res.Rule.ID
+	}
+	if result.RuleID == "" {
+		result.RuleID = rule.ID
+	}
+	if rule.ID == "" {
+		rule = rules[result.RuleID]
+	}
+
+	if result.Level == "" {
+		result.Level = "warning" // the SARIF default
+		if rule.DefaultConfig != nil && rule.DefaultConfig.Level != "" {
+			result.Level = rule.DefaultConfig.Level
+		}
+	}
+	for i := range result.Locations {
+		region := &result.Locations[i].PhysicalLocation.Region
+		if region.EndLine < region.StartLine {
+			region.EndLine = region.StartLine
+		}
+	}
+
+	props := make(map[string]interface{}, len(result.Properties)+3)
+	for k, v := range result.Properties {
+		props[k] = v
+	}
+	props["gavel/tool"] = driver.Name
+	result.Properties = props
+	return result, rule
+}
+
+// enrich adds gavel/confidence and, from the rule, gavel/cwe
+func (l *loader) enrich(result *sarif.Result, rule rawRule) {
+	if _, ok := result.Properties["gavel/confidence"].(float64); !ok {
+		confidence := l.defaultConfidence
+		if precision, ok := rule.Properties["precision"].(string); ok {
+			if c, ok := precisionConfidence[precision]; ok {
+				confidence = c
+			}
+		}
+		result.Properties["gavel/confidence"] = confidence
+	}
+
+	if _, ok := result.Properties["gavel/cwe"]; !ok {
+		if cwes := ruleCWEs(rule); len(cwes) > 0 {
+			result.Properties["gavel/cwe"] = cwes
+		}
+	}
+}
+
+// ruleCWEs reads CWE IDs from a rule's tags, as CodeQL writes them
+// ("external/cwe/cwe-079"), and from relationships to a CWE taxonomy, as
+// gosec writes them
+func ruleCWEs(rule rawRule) []string {
+	seen := map[string]bool{}
+	var cwes []string
+	add := func(id string) {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return
+		}
+		cwe := fmt.Sprintf("CWE-%d", n)
+		if !seen[cwe] {
+			seen[cwe] = true
+			cwes = append(cwes, cwe)
+		}
+	}
+
+	if tags, ok := rule.Properties["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok && strings.HasPrefix(strings.ToLower(s), "external/cwe/cwe-") {
+				add(s[len("external/cwe/cwe-"):])
+			}
+		}
+	}
+	for _, rel := range rule.Relationships {
+		if rel.Target.ToolComponent != nil && strings.EqualFold(rel.Target.ToolComponent.Name, "CWE") {
+			add(strings.TrimPrefix(strings.ToUpper(rel.Target.ID), "CWE-"))
+		}
+	}
+	return cwes
+}
+
+// dedupKeys returns the keys under which a result counts as the same
+// finding as another: its rule at its line, and each of its CWEs at its
+// line, so different tools reporting one weakness match
+func dedupKeys(result sarif.Result) []string {
+	if len(result.Locations) == 0 {
+		return nil
+	}
+	loc := result.Locations[0].PhysicalLocation
+	at := fmt.Sprintf("%s:%d", loc.ArtifactLocation.URI, loc.Region.StartLine)
+	keys := []string{at + "|rule:" + result.RuleID}
+	if cwes, ok := result.Properties["gavel/cwe"].([]string); ok {
+		for _, cwe := range cwes {
+			keys = append(keys, at+"|"+cwe)
+		}
+	}
+	return keys
+}
+
+func firstKept(kept map[string]int, keys []string) (int, bool) {
+	for _, k := range keys {
+		if i, ok := kept[k]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// addDuplicate records dup on the result kept in its place
+func addDuplicate(kept *sarif.Result, dup sarif.Result) {
+	dups, _ := kept.Properties["gavel/duplicates"].([]string)
+	tool, _ := dup.Properties["gavel/tool"].(string)
+	kept.Properties["gavel/duplicates"] = append(dups, tool+":"+dup.RuleID)
+}
@@ -0,0 +1,124 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// externalSARIF has a gosec run that identifies rules by ruleId and a
+// CodeQL run that identifies them by ruleIndex, both reporting the same
+// SQL injection at main.go:12
+const externalSARIF = `{
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {"driver": {"name": "gosec", "rules": [
+        {"id": "G202", "relationships": [{"target": {"id": "89", "toolComponent": {"name": "CWE"}}}]},
+        {"id": "G101", "defaultConfiguration": {"level": "error"}}
+      ]}},
+      "results": [
+        {"ruleId": "G202", "level": "warning", "message": {"text": "SQL string concatenation"},
+         "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 12}}}]},
+        {"ruleId": "G101", "message": {"text": "Potential hardcoded credentials"},
+         "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 3, "endLine": 3}}}]}
+      ]
+    },
+    {
+      "tool": {"driver": {"name": "CodeQL", "rules": [
+        {"id": "go/sql-injection", "properties": {"precision": "high", "tags": ["security", "external/cwe/cwe-089"]}}
+      ]}},
+      "results": [
+        {"ruleIndex": 0, "level": "error", "message": {"text": "This query depends on a user-provided value."},
+         "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 12}}}]},
+        {"rule": {"index": 0}, "level": "error", "message": {"text": "This query depends on a user-provided value."},
+         "locations": [{"physicalLocation": {"artifactLocation": {"uri": "db.go"}, "region": {"startLine": 40}}}],
+         "properties": {"gavel/confidence": 0.3}}
+      ]
+    }
+  ]
+}`
+
+func TestLoad(t *testing.T) {
+	log, summary, err := Load(strings.NewReader(externalSARIF), "1.2.3")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if !reflect.DeepEqual(summary, Summary{Tools: []string{"CodeQL", "gosec"}, Results: 4, Duplicates: 1}) {
+		t.Errorf("summary = %+v", summary)
+	}
+	if len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "gavel" || log.Runs[0].Tool.Driver.Version != "1.2.3" {
+		t.Fatalf("expected a single gavel run, got %+v", log.Runs)
+	}
+	if n := len(log.Runs[0].Tool.Driver.Rules); n != 3 {
+		t.Errorf("expected the 3 external rules to be carried over, got %d", n)
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 3 {
+		t.Fatalf("expected the duplicate SQL injection to be merged, got %d results", len(results))
+	}
+
+	sqli := results[0]
+	if sqli.RuleID != "G202" || sqli.Properties["gavel/tool"] != "gosec" {
+		t.Errorf("expected gosec's report to be kept, got %s from %v", sqli.RuleID, sqli.Properties["gavel/tool"])
+	}
+	if got := sqli.Properties["gavel/duplicates"]; !reflect.DeepEqual(got, []string{"CodeQL:go/sql-injection"}) {
+		t.Errorf("gavel/duplicates = %v", got)
+	}
+	if got := sqli.Properties["gavel/cwe"]; !reflect.DeepEqual(got, []string{"CWE-89"}) {
+		t.Errorf("gavel/cwe = %v", got)
+	}
+	if got := sqli.Properties["gavel/confidence"]; got != DefaultConfidence {
+		t.Errorf("expected the default confidence, got %v", got)
+	}
+	if end := sqli.Locations[0].PhysicalLocation.Region.EndLine; end != 12 {
+		t.Errorf("expected endLine to default to startLine, got %d", end)
+	}
+
+	creds := results[1]
+	if creds.Level != "error" {
+		t.Errorf("expected the rule's default level, got %q", creds.Level)
+	}
+
+	codeql := results[2]
+	if codeql.RuleID != "go/sql-injection" {
+		t.Errorf("expected rule.index to resolve the rule ID, got %q", codeql.RuleID)
+	}
+	if got := codeql.Properties["gavel/confidence"]; got != 0.3 {
+		t.Errorf("expected the result's own confidence to be kept, got %v", got)
+	}
+}
+
+func TestLoad_PrecisionAndDefaultConfidence(t *testing.T) {
+	sarif := `{"version": "2.1.0", "runs": [{
+	  "tool": {"driver": {"name": "CodeQL", "rules": [{"id": "go/xss", "properties": {"precision": "very-high"}}, {"id": "go/log"}]}},
+	  "results": [
+	    {"ruleId": "go/xss", "message": {"text": "xss"}, "locations": [{"physicalLocation": {"artifactLocation": {"uri": "a.go"}, "region": {"startLine": 1}}}]},
+	    {"ruleId": "go/log", "message": {"text": "log"}, "locations": [{"physicalLocation": {"artifactLocation": {"uri": "a.go"}, "region": {"startLine": 2}}}]}
+	  ]}]}`
+	log, _, err := Load(strings.NewReader(sarif), "dev", WithDefaultConfidence(0.4))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	results := log.Runs[0].Results
+	if got := results[0].Properties["gavel/confidence"]; got != 0.95 {
+		t.Errorf("expected very-high precision to give 0.95, got %v", got)
+	}
+	if got := results[1].Properties["gavel/confidence"]; got != 0.4 {
+		t.Errorf("expected the configured default confidence, got %v", got)
+	}
+	if results[0].Level != "warning" {
+		t.Errorf("expected the SARIF default level, got %q", results[0].Level)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	if _, _, err := Load(strings.NewReader("not json"), "dev"); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+	if _, _, err := Load(strings.NewReader(`{"version": "1.0.0", "runs": []}`), "dev"); err == nil || !strings.Contains(err.Error(), "unsupported SARIF version") {
+		t.Errorf("expected an unsupported version error, got %v", err)
+	}
+}
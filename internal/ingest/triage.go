@@ -0,0 +1,196 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// confirmThreshold is the triage confidence at or above which a finding is
+// recorded as confirmed rather than a likely false positive
+const confirmThreshold = 0.5
+
+// triagePolicy is passed as the policy text, so the analysis prompt asks
+// for one verdict per listed finding instead of new findings
+const triagePolicy = `- triage [error]: Other static analysis tools reported the findings listed in the additional context against this file, each with an ID such as F1. Judge each one against the code. Report exactly one finding per listed ID and nothing else: set ruleId to the ID, copy its startLine and endLine, and set confidence to the probability (0.0 to 1.0) that it is a real problem in this code. Say why in explanation. If a finding reports the same problem as an earlier listed finding, set its message to "duplicate of F<n>" naming that finding.
+`
+
+var duplicateOfPattern = regexp.MustCompile(`(?i)duplicate of (F\d+)`)
+
+// TriageSummary counts the verdicts Triage recorded
+type TriageSummary struct {
+	Files          int `json:"files"`
+	Confirmed      int `json:"confirmed"`
+	FalsePositives int `json:"false_positives"`
+	Duplicates     int `json:"duplicates"`
+	Untriaged      int `json:"untriaged"`
+}
+
+// Triage asks the LLM to judge the results of each file in the log's first
+// run. Each judged result gets the model's gavel/confidence, a gavel/triage
+// of "confirmed" or "false-positive", and its explanation when the result
+// had none. Results the model marks as duplicates are dropped and listed in
+// gavel/duplicates on the result they repeat. Results whose file cannot be
+// read, or that the model skips, are left as they were.
+func Triage(ctx context.Context, log *sarif.Log, client analyzer.BAMLClient, personaPrompt string, readFile func(uri string) (string, error)) (TriageSummary, error) {
+	var summary TriageSummary
+	if len(log.Runs) == 0 {
+		return summary, nil
+	}
+	run := &log.Runs[0]
+
+	var order []string
+	byFile := map[string][]int{}
+	for i, r := range run.Results {
+		uri := resultURI(r)
+		if _, ok := byFile[uri]; !ok {
+			order = append(order, uri)
+		}
+		byFile[uri] = append(byFile[uri], i)
+	}
+
+	dropped := map[int]bool{}
+	for _, uri := range order {
+		indexes := byFile[uri]
+		if uri == "" {
+			summary.Untriaged += len(indexes)
+			continue
+		}
+		content, err := readFile(uri)
+		if err != nil {
+			slog.Warn("cannot read file; its findings are not triaged", "file", uri, "err", err)
+			summary.Untriaged += len(indexes)
+			continue
+		}
+
+		findings, err := client.AnalyzeCode(ctx, content, triagePolicy, personaPrompt, triageContext(run.Results, indexes))
+		if err != nil {
+			return summary, fmt.Errorf("triaging %s: %w", uri, err)
+		}
+		summary.Files++
+
+		verdicts := map[string]analyzer.Finding{}
+		for _, f := range findings {
+			id := strings.ToUpper(strings.TrimSpace(f.RuleID))
+			if _, ok := verdicts[id]; !ok {
+				verdicts[id] = f
+			}
+		}
+		for n, i := range indexes {
+			f, ok := verdicts[fmt.Sprintf("F%d", n+1)]
+			if !ok {
+				summary.Untriaged++
+				continue
+			}
+			if orig, ok := duplicateOf(f.Message, indexes); ok && orig != i && !dropped[orig] {
+				addDuplicate(&run.Results[orig], run.Results[i])
+				dropped[i] = true
+				summary.Duplicates++
+				continue
+			}
+			if applyVerdict(&run.Results[i], f) {
+				summary.Confirmed++
+			} else {
+				summary.FalsePositives++
+			}
+		}
+	}
+
+	if len(dropped) > 0 {
+		kept := run.Results[:0]
+		for i, r := range run.Results {
+			if !dropped[i] {
+				kept = append(kept, r)
+			}
+		}
+		run.Results = kept
+	}
+	return summary, nil
+}
+
+// triageContext lists a file's findings under the IDs the model reports
+// its verdicts against
+func triageContext(results []sarif.Result, indexes []int) string {
+	var b strings.Builder
+	b.WriteString("Findings to triage:\n")
+	for n, i := range indexes {
+		r := results[i]
+		tool, _ := r.Properties["gavel/tool"].(string)
+		start, end := 0, 0
+		if len(r.Locations) > 0 {
+			start = r.Locations[0].PhysicalLocation.Region.StartLine
+			end = r.Locations[0].PhysicalLocation.Region.EndLine
+		}
+		fmt.Fprintf(&b, "F%d [%s %s] lines %d-%d (%s): %s\n", n+1, tool, r.RuleID, start, end, r.Level, r.Message.Text)
+	}
+	return b.String()
+}
+
+// duplicateOf returns the result index named by a "duplicate of Fn" message
+func duplicateOf(message string, indexes []int) (int, bool) {
+	m := duplicateOfPattern.FindStringSubmatch(message)
+	if m == nil {
+		return 0, false
+	}
+	var n int
+	if _, err := fmt.Sscanf(strings.ToUpper(m[1]), "F%d", &n); err != nil || n < 1 || n > len(indexes) {
+		return 0, false
+	}
+	return indexes[n-1], true
+}
+
+// applyVerdict records the model's verdict on r and reports whether it
+// confirmed the finding
+func applyVerdict(r *sarif.Result, f analyzer.Finding) bool {
+	confidence := f.Confidence
+	if confidence < 0 {
+		confidence = 0
+	} else if confidence > 1 {
+		confidence = 1
+	}
+	r.Properties["gavel/confidence"] = confidence
+
+	confirmed := confidence >= confirmThreshold
+	if confirmed {
+		r.Properties["gavel/triage"] = "confirmed"
+	} else {
+		r.Properties["gavel/triage"] = "false-positive"
+	}
+	if _, ok := r.Properties["gavel/explanation"]; !ok && f.Explanation != "" {
+		r.Properties["gavel/explanation"] = f.Explanation
+	}
+	if _, ok := r.Properties["gavel/recommendation"]; !ok && f.Recommendation != "" {
+		r.Properties["gavel/recommendation"] = f.Recommendation
+	}
+	return confirmed
+}
+
+func resultURI(r sarif.Result) string {
+	if len(r.Locations) == 0 {
+		return ""
+	}
+	return r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+}
+
+// ReadFromDir returns a readFile for Triage that resolves relative artifact
+// URIs against root
+func ReadFromDir(root string) func(uri string) (string, error) {
+	return func(uri string) (string, error) {
+		path := filepath.FromSlash(strings.TrimPrefix(uri, "file://"))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
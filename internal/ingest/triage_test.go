@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+type triageClient struct {
+	findings []analyzer.Finding
+	contexts []string
+}
+
+func (c *triageClient) AnalyzeCode(ctx context.Context, code, policies, personaPrompt, additionalContext string) ([]analyzer.Finding, error) {
+	c.contexts = append(c.contexts, additionalContext)
+	return c.findings, nil
+}
+
+func triageLog() *sarif.Log {
+	at := func(uri string, line int) []sarif.Location {
+		return []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region:           sarif.Region{StartLine: line, EndLine: line},
+		}}}
+	}
+	log := sarif.NewLog("gavel", "dev")
+	log.Runs[0].Results = []sarif.Result{
+		{RuleID: "G101", Level: "error", Message: sarif.Message{Text: "hardcoded credentials"}, Locations: at("main.go", 3), Properties: map[string]interface{}{"gavel/tool": "gosec", "gavel/confidence": 0.7}},
+		{RuleID: "G202", Level: "warning", Message: sarif.Message{Text: "SQL concatenation"}, Locations: at("main.go", 12), Properties: map[string]interface{}{"gavel/tool": "gosec", "gavel/confidence": 0.7}},
+		{RuleID: "go/sql-injection", Level: "error", Message: sarif.Message{Text: "user-controlled query"}, Locations: at("main.go", 13), Properties: map[string]interface{}{"gavel/tool": "CodeQL", "gavel/confidence": 0.9}},
+		{RuleID: "G104", Level: "warning", Message: sarif.Message{Text: "unhandled error"}, Locations: at("main.go", 20), Properties: map[string]interface{}{"gavel/tool": "gosec", "gavel/confidence": 0.7}},
+		{RuleID: "G304", Level: "warning", Message: sarif.Message{Text: "file inclusion"}, Locations: at("missing.go", 1), Properties: map[string]interface{}{"gavel/tool": "gosec", "gavel/confidence": 0.7}},
+	}
+	return log
+}
+
+func TestTriage(t *testing.T) {
+	client := &triageClient{findings: []analyzer.Finding{
+		{RuleID: "F1", Confidence: 0.1, Explanation: "The string is a test fixture."},
+		{RuleID: "f2", Confidence: 0.95, Explanation: "The query concatenates request input."},
+		{RuleID: "F3", Confidence: 0.9, Message: "Duplicate of F2"},
+	}}
+	readFile := func(uri string) (string, error) {
+		if uri == "main.go" {
+			return "package main\n", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	log := triageLog()
+	summary, err := Triage(context.Background(), log, client, "", readFile)
+	if err != nil {
+		t.Fatalf("Triage: %v", err)
+	}
+
+	want := TriageSummary{Files: 1, Confirmed: 1, FalsePositives: 1, Duplicates: 1, Untriaged: 2}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+	if len(client.contexts) != 1 || !strings.Contains(client.contexts[0], "F2 [gosec G202] lines 12-12 (warning): SQL concatenation") {
+		t.Errorf("unexpected triage context: %q", client.contexts)
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 4 {
+		t.Fatalf("expected the duplicate to be dropped, got %d results", len(results))
+	}
+	creds, sqli, unjudged := results[0], results[1], results[2]
+	if creds.Properties["gavel/triage"] != "false-positive" || creds.Properties["gavel/confidence"] != 0.1 {
+		t.Errorf("expected a scored false positive, got %v", creds.Properties)
+	}
+	if creds.Properties["gavel/explanation"] != "The string is a test fixture." {
+		t.Errorf("expected the triage explanation, got %v", creds.Properties["gavel/explanation"])
+	}
+	if sqli.Properties["gavel/triage"] != "confirmed" || sqli.Properties["gavel/confidence"] != 0.95 {
+		t.Errorf("expected a confirmed finding, got %v", sqli.Properties)
+	}
+	if got := sqli.Properties["gavel/duplicates"]; !reflect.DeepEqual(got, []string{"CodeQL:go/sql-injection"}) {
+		t.Errorf("gavel/duplicates = %v", got)
+	}
+	if _, ok := unjudged.Properties["gavel/triage"]; ok || unjudged.Properties["gavel/confidence"] != 0.7 {
+		t.Errorf("expected a finding the model skipped to be unchanged, got %v", unjudged.Properties)
+	}
+}
+
+func TestReadFromDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "a.go"), []byte("package pkg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	read := ReadFromDir(root)
+	for _, uri := range []string{"pkg/a.go", "file://" + filepath.ToSlash(filepath.Join(root, "pkg", "a.go"))} {
+		content, err := read(uri)
+		if err != nil || content != "package pkg\n" {
+			t.Errorf("read(%q) = %q, %v", uri, content, err)
+		}
+	}
+}
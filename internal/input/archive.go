@@ -0,0 +1,209 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReadArchive reads the files in a tar archive, optionally gzip-compressed,
+// or a zip archive, without extracting it to disk. The format is detected
+// from the content, not the file name. Artifact paths are the entry names.
+// Files are skipped as ReadDirectory skips them, with the .gitignore and
+// .gavelignore files inside the archive applying from the directory that
+// holds them; entries that are not regular files or whose names escape the
+// archive root are skipped as well.
+func (h *Handler) ReadArchive(name string) ([]Artifact, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files := make(map[string][]byte)
+	add := func(entry string, size int64, open func() (io.Reader, error)) error {
+		p, ok := cleanEntry(entry)
+		if !ok {
+			slog.Warn("skipping archive entry outside the archive root", "entry", entry)
+			return nil
+		}
+		if h.maxFileSize > 0 && size > h.maxFileSize && !isIgnoreFile(p) {
+			slog.Debug("skipping file", "path", p, "reason", "too large", "size", size, "max", h.maxFileSize)
+			return nil
+		}
+		r, err := open()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", entry, err)
+		}
+		limit := h.maxFileSize
+		if limit <= 0 {
+			limit = size
+		}
+		data, err := io.ReadAll(io.LimitReader(r, limit+1))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", entry, err)
+		}
+		files[p] = data
+		return nil
+	}
+
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("reading zip archive %s: %w", name, err)
+		}
+		for _, zf := range zr.File {
+			if !zf.Mode().IsRegular() {
+				continue
+			}
+			if err := add(zf.Name, int64(zf.UncompressedSize64), func() (io.Reader, error) { return zf.Open() }); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		var r io.Reader = br
+		if bytes.HasPrefix(magic, []byte{0x1f, 0x8b}) {
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("reading gzip archive %s: %w", name, err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("reading tar archive %s: %w", name, err)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := add(hdr.Name, hdr.Size, func() (io.Reader, error) { return tr, nil }); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return h.archiveArtifacts(files), nil
+}
+
+// archiveArtifacts filters the files read from an archive, keyed by their
+// slash-separated paths, into artifacts sorted by path.
+func (h *Handler) archiveArtifacts(files map[string][]byte) []Artifact {
+	root := string(filepath.Separator)
+	ig := &ignorer{
+		top: root,
+		read: func(p string) ([]byte, error) {
+			if data, ok := files[filepath.ToSlash(strings.TrimPrefix(p, root))]; ok {
+				return data, nil
+			}
+			return nil, os.ErrNotExist
+		},
+		rules: make(map[string][]ignoreRule),
+	}
+
+	names := make([]string, 0, len(files))
+	for p := range files {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+
+	skipDir := make(map[string]bool)
+	var artifacts []Artifact
+	for _, p := range names {
+		if h.skipArchiveDir(ig, path.Dir(p), skipDir) {
+			continue
+		}
+		if ignored, source := ig.match(root+filepath.FromSlash(p), false); ignored {
+			slog.Debug("skipping ignored path", "path", p, "reason", "ignored", "pattern", source)
+			continue
+		}
+		if !h.accept(p, files[p]) {
+			continue
+		}
+		artifacts = append(artifacts, Artifact{Path: filepath.FromSlash(p), Content: string(files[p]), Kind: KindFile})
+	}
+	return artifacts
+}
+
+// skipArchiveDir reports whether files in dir are skipped because dir or a
+// directory above it is hidden or ignored, memoizing the answer per
+// directory as a walk would by not entering it.
+func (h *Handler) skipArchiveDir(ig *ignorer, dir string, memo map[string]bool) bool {
+	if dir == "." {
+		return false
+	}
+	if skip, ok := memo[dir]; ok {
+		return skip
+	}
+	skip := h.skipArchiveDir(ig, path.Dir(dir), memo)
+	if !skip && strings.HasPrefix(path.Base(dir), ".") {
+		skip = true
+	}
+	if !skip {
+		if ignored, source := ig.match(string(filepath.Separator)+filepath.FromSlash(dir), true); ignored {
+			slog.Debug("skipping ignored path", "path", dir, "reason", "ignored", "pattern", source)
+			skip = true
+		}
+	}
+	memo[dir] = skip
+	return skip
+}
+
+// cleanEntry normalizes an archive entry name to a slash-separated relative
+// path, rejecting names that would escape the archive root.
+func cleanEntry(name string) (string, bool) {
+	p := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(p) || p == "." || p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	return p, true
+}
+
+func isIgnoreFile(p string) bool {
+	for _, name := range IgnoreFiles {
+		if path.Base(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadStream reads a single file from r, such as standard input, as an
+// artifact named path. The path need not exist; it decides the language
+// and how rules and path overrides apply. Unlike ReadDirectory, binary or
+// invalid UTF-8 content is an error rather than a skip.
+func (h *Handler) ReadStream(r io.Reader, path string) ([]Artifact, error) {
+	if path == "" {
+		return nil, fmt.Errorf("a path is required to name the content")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if Binary(data) || !utf8.Valid(data) {
+		return nil, fmt.Errorf("%s: content is not UTF-8 text", path)
+	}
+	return []Artifact{{Path: filepath.FromSlash(path), Content: string(data), Kind: KindFile}}, nil
+}
@@ -0,0 +1,107 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var archiveFiles = []struct{ name, content string }{
+	{"src/main.go", "package main\n"},
+	{"src/util/util.go", "package util\n"},
+	{".gitignore", "dist/\n"},
+	{"dist/bundle.js", "var x = 1;\n"},
+	{".git/config", "[core]\n"},
+	{"gen/api.go", "// Code generated by oapi-codegen. DO NOT EDIT.\npackage gen\n"},
+	{"logo.png", "\x89PNG\x00"},
+	{"../escape.go", "package evil\n"},
+}
+
+func writeTarGz(t *testing.T, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, f := range archiveFiles {
+		tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.content))})
+		tw.Write([]byte(f.content))
+	}
+	tw.WriteHeader(&tar.Header{Name: "link.go", Typeflag: tar.TypeSymlink, Linkname: "src/main.go"})
+	tw.Close()
+	gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range archiveFiles {
+		w, _ := zw.Create(f.name)
+		w.Write([]byte(f.content))
+	}
+	zw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandler_ReadArchive(t *testing.T) {
+	dir := t.TempDir()
+	tgz := filepath.Join(dir, "project.tar.gz")
+	zipped := filepath.Join(dir, "project.bin") // detected by content, not name
+	writeTarGz(t, tgz)
+	writeZip(t, zipped)
+
+	want := []string{".gitignore", "src/main.go", "src/util/util.go"}
+	for _, archive := range []string{tgz, zipped} {
+		artifacts, err := NewHandler().ReadArchive(archive)
+		if err != nil {
+			t.Fatalf("%s: %v", archive, err)
+		}
+		var got []string
+		for _, a := range artifacts {
+			got = append(got, filepath.ToSlash(a.Path))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ReadArchive = %v, want %v", filepath.Base(archive), got, want)
+		}
+		if len(artifacts) > 1 && artifacts[1].Content != "package main\n" {
+			t.Errorf("unexpected content %q", artifacts[1].Content)
+		}
+	}
+
+	if _, err := NewHandler().ReadArchive(filepath.Join(dir, "missing.tar")); err == nil {
+		t.Error("expected a missing archive to be an error")
+	}
+	os.WriteFile(filepath.Join(dir, "junk.tar"), []byte("not an archive at all, just some text that is long enough"), 0644)
+	if _, err := NewHandler().ReadArchive(filepath.Join(dir, "junk.tar")); err == nil {
+		t.Error("expected a malformed archive to be an error")
+	}
+}
+
+func TestHandler_ReadStream(t *testing.T) {
+	artifacts, err := NewHandler().ReadStream(strings.NewReader("package api\n"), "virtual/api.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 1 || artifacts[0].Path != filepath.FromSlash("virtual/api.go") || artifacts[0].Content != "package api\n" || artifacts[0].Kind != KindFile {
+		t.Errorf("unexpected artifacts %+v", artifacts)
+	}
+
+	if _, err := NewHandler().ReadStream(strings.NewReader("x"), ""); err == nil {
+		t.Error("expected a missing path to be an error")
+	}
+	if _, err := NewHandler().ReadStream(strings.NewReader("\x00\x01"), "blob.bin"); err == nil {
+		t.Error("expected binary content to be an error")
+	}
+}
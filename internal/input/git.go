@@ -0,0 +1,250 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// gitTimeout limits how long a single git invocation may run.
+const gitTimeout = 30 * time.Second
+
+// LineRange is a 1-indexed, inclusive range of lines.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ChangedFile is a full-file artifact together with the lines a git diff
+// changed in it.
+type ChangedFile struct {
+	Artifact Artifact
+	Changed  []LineRange
+}
+
+// ReadGitRange reads the files changed by revRange in the git repository
+// containing dir. revRange takes the forms git diff accepts: "A...B" and
+// "A..B" read files as of B, while a single revision compares against the
+// working tree and reads files from disk. Deleted files and files without
+// added or modified lines are omitted. Paths are relative to dir.
+func (h *Handler) ReadGitRange(ctx context.Context, dir, revRange string) ([]ChangedFile, error) {
+	if revRange == "" || strings.HasPrefix(revRange, "-") {
+		return nil, fmt.Errorf("invalid git range %q", revRange)
+	}
+	rev := "" // empty reads the working tree
+	switch {
+	case strings.Contains(revRange, "..."):
+		rev = revRange[strings.Index(revRange, "...")+3:]
+	case strings.Contains(revRange, ".."):
+		rev = revRange[strings.Index(revRange, "..")+2:]
+	}
+	if rev == "" && strings.Contains(revRange, "..") {
+		rev = "HEAD" // git reads an omitted end of a range as HEAD
+	}
+	if rev != "" {
+		rev += ":"
+	}
+	return readGitChanges(ctx, dir, rev, revRange)
+}
+
+// ReadGitStaged reads the files staged in the index of the git repository
+// containing dir, with their staged content.
+func (h *Handler) ReadGitStaged(ctx context.Context, dir string) ([]ChangedFile, error) {
+	return readGitChanges(ctx, dir, ":", "--cached")
+}
+
+// readGitChanges runs git diff with diffArgs and reads each changed file
+// from the working tree when prefix is empty, or else as the object named
+// prefix+path: ":" for the index or "<rev>:" for a revision.
+func readGitChanges(ctx context.Context, dir, prefix string, diffArgs ...string) ([]ChangedFile, error) {
+	if dir == "" {
+		dir = "."
+	}
+	top, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSpace(string(top))
+
+	args := append([]string{"diff", "--unified=0", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", "--diff-filter=d"}, diffArgs...)
+	out, err := runGit(ctx, root, append(args, "--")...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report paths relative to dir like the other readers, resolving
+	// symlinks so git's absolute toplevel and dir agree.
+	base, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(base); err == nil {
+		base = resolved
+	}
+
+	var files []ChangedFile
+	for _, fd := range parseUnifiedDiff(string(out)) {
+		if len(fd.changed) == 0 {
+			continue
+		}
+		var data []byte
+		if prefix == "" {
+			data, err = os.ReadFile(filepath.Join(root, filepath.FromSlash(fd.path)))
+		} else {
+			data, err = runGit(ctx, root, "show", prefix+fd.path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", fd.path, err)
+		}
+		if !utf8.Valid(data) {
+			slog.Warn("skipping file with invalid UTF-8", "path", fd.path)
+			continue
+		}
+		path := filepath.Join(root, filepath.FromSlash(fd.path))
+		if rel, err := filepath.Rel(base, path); err == nil {
+			path = rel
+		}
+		files = append(files, ChangedFile{
+			Artifact: Artifact{Path: path, Content: string(data), Kind: KindFile},
+			Changed:  fd.changed,
+		})
+	}
+	return files, nil
+}
+
+// Revision is the commit checked out in a git repository.
+type Revision struct {
+	Commit string
+	Branch string // empty when HEAD is detached
+	Remote string // the origin URL without credentials, if the repository has one
+}
+
+// GitRevision returns the commit checked out in the git repository
+// containing dir. It returns an error when dir is not in a repository or
+// the repository has no commits.
+func GitRevision(ctx context.Context, dir string) (Revision, error) {
+	if dir == "" {
+		dir = "."
+	}
+	out, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return Revision{}, err
+	}
+	rev := Revision{Commit: strings.TrimSpace(string(out))}
+	if out, err := runGit(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		rev.Branch = strings.TrimSpace(string(out))
+	}
+	if out, err := runGit(ctx, dir, "remote", "get-url", "origin"); err == nil {
+		rev.Remote = strings.TrimSpace(string(out))
+		// Tokens embedded in HTTPS remotes must not end up in stored results
+		if u, err := url.Parse(rev.Remote); err == nil && u.User != nil {
+			u.User = nil
+			rev.Remote = u.String()
+		}
+	}
+	return rev, nil
+}
+
+// runGit runs git in dir and returns its stdout, with stderr in the error.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	// Unquoted paths keep non-ASCII file names readable in diff headers.
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "core.quotePath=false"}, args...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+type fileDiff struct {
+	path    string
+	changed []LineRange
+}
+
+// parseUnifiedDiff extracts each file's new path and changed line ranges
+// from git diff output produced with --unified=0. A hunk that only deletes
+// lines marks the lines on either side of the deletion as changed. A "+++ "
+// line is only a file header when it follows the "--- " line before the
+// file's first hunk; inside a hunk it is an added line starting with "++ ".
+func parseUnifiedDiff(diff string) []fileDiff {
+	var files []fileDiff
+	var inHunk, afterOldHeader bool
+	for _, line := range strings.Split(diff, "\n") {
+		followsOldHeader := afterOldHeader
+		afterOldHeader = false
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, fileDiff{})
+			inHunk = false
+		case strings.HasPrefix(line, "--- ") && !inHunk:
+			afterOldHeader = true
+		case strings.HasPrefix(line, "+++ ") && followsOldHeader && len(files) > 0:
+			p := strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\t")
+			if p == "/dev/null" {
+				continue
+			}
+			files[len(files)-1].path = strings.TrimPrefix(p, "b/")
+		case strings.HasPrefix(line, "@@ ") && len(files) > 0:
+			inHunk = true
+			if r, ok := parseHunkHeader(line); ok {
+				f := &files[len(files)-1]
+				f.changed = append(f.changed, r)
+			}
+		}
+	}
+
+	out := files[:0]
+	for _, f := range files {
+		if f.path != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// parseHunkHeader returns the new-file lines covered by a hunk header
+// "@@ -a,b +c,d @@".
+func parseHunkHeader(line string) (LineRange, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return LineRange{}, false
+	}
+	spec := strings.TrimPrefix(fields[2], "+")
+	count := 1
+	if i := strings.IndexByte(spec, ','); i >= 0 {
+		n, err := strconv.Atoi(spec[i+1:])
+		if err != nil {
+			return LineRange{}, false
+		}
+		count = n
+		spec = spec[:i]
+	}
+	start, err := strconv.Atoi(spec)
+	if err != nil {
+		return LineRange{}, false
+	}
+	if count == 0 {
+		// Pure deletion: start is the line before the removed lines.
+		if start < 1 {
+			return LineRange{Start: 1, End: 1}, true
+		}
+		return LineRange{Start: start, End: start + 1}, true
+	}
+	return LineRange{Start: start, End: start + count - 1}, true
+}
@@ -1,9 +1,11 @@
 package input
 
 import (
+	"bytes"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"unicode/utf8"
 )
@@ -13,6 +15,9 @@ type Kind int
 const (
 	KindFile Kind = iota
 	KindDiff
+	// KindSBOM is a software bill of materials, given with analyze --sbom.
+	// Only dependency rules run on it.
+	KindSBOM
 )
 
 type Artifact struct {
@@ -21,10 +26,39 @@ type Artifact struct {
 	Kind    Kind
 }
 
-type Handler struct{}
+// DefaultMaxFileSize is the size in bytes above which ReadDirectory skips a
+// file unless WithMaxFileSize says otherwise.
+const DefaultMaxFileSize = 1 << 20
 
-func NewHandler() *Handler {
-	return &Handler{}
+type Handler struct {
+	maxFileSize      int64
+	includeGenerated bool
+}
+
+// HandlerOption configures a Handler.
+type HandlerOption func(*Handler)
+
+// WithMaxFileSize makes ReadDirectory skip files larger than n bytes; 0
+// disables the limit.
+func WithMaxFileSize(n int64) HandlerOption {
+	return func(h *Handler) {
+		h.maxFileSize = n
+	}
+}
+
+// WithIncludeGenerated makes ReadDirectory keep files that look generated.
+func WithIncludeGenerated(include bool) HandlerOption {
+	return func(h *Handler) {
+		h.includeGenerated = include
+	}
+}
+
+func NewHandler(opts ...HandlerOption) *Handler {
+	h := &Handler{maxFileSize: DefaultMaxFileSize}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *Handler) ReadFiles(paths []string) ([]Artifact, error) {
@@ -67,7 +101,8 @@ func (h *Handler) ReadDiff(diff string) ([]Artifact, error) {
 			flush()
 			parts := strings.Fields(line)
 			if len(parts) >= 4 {
-				currentPath = strings.TrimPrefix(parts[len(parts)-1], "b/")
+				// Git always separates with '/'; artifact paths use the OS separator
+				currentPath = filepath.FromSlash(strings.TrimPrefix(parts[len(parts)-1], "b/"))
 			}
 			currentLines = nil
 		} else {
@@ -79,24 +114,48 @@ func (h *Handler) ReadDiff(diff string) ([]Artifact, error) {
 	return artifacts, nil
 }
 
+// ReadDirectory reads the files under dir. Hidden directories are not
+// entered, and files are skipped when they are ignored by a .gitignore or
+// .gavelignore (see IgnoreFiles), binary, larger than the maximum file size,
+// or generated (see Generated) unless WithIncludeGenerated is set. Each skip
+// is logged at debug level with its reason.
 func (h *Handler) ReadDirectory(dir string) ([]Artifact, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	ig := newIgnorer(absDir)
+
 	var artifacts []Artifact
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
-			if strings.HasPrefix(info.Name(), ".") && path != dir {
+		if path == dir && info.IsDir() {
+			return nil
+		}
+		if info.IsDir() && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if ignored, source := ig.match(filepath.Join(absDir, mustRel(dir, path)), info.IsDir()); ignored {
+			slog.Debug("skipping ignored path", "path", path, "reason", "ignored", "pattern", source)
+			if info.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+		if h.maxFileSize > 0 && info.Size() > h.maxFileSize {
+			slog.Debug("skipping file", "path", path, "reason", "too large", "size", info.Size(), "max", h.maxFileSize)
+			return nil
+		}
 		data, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		if !utf8.Valid(data) {
-			slog.Warn("skipping file with invalid UTF-8", "path", path)
+		if !h.accept(path, data) {
 			return nil
 		}
 		artifacts = append(artifacts, Artifact{
@@ -108,3 +167,77 @@ func (h *Handler) ReadDirectory(dir string) ([]Artifact, error) {
 	})
 	return artifacts, err
 }
+
+// accept reports whether a file found by ReadDirectory or ReadArchive should
+// be analyzed, logging why when it is skipped.
+func (h *Handler) accept(path string, data []byte) bool {
+	if h.maxFileSize > 0 && int64(len(data)) > h.maxFileSize {
+		slog.Debug("skipping file", "path", path, "reason", "too large", "size", len(data), "max", h.maxFileSize)
+		return false
+	}
+	if Binary(data) {
+		slog.Debug("skipping file", "path", path, "reason", "binary")
+		return false
+	}
+	if !utf8.Valid(data) {
+		slog.Warn("skipping file with invalid UTF-8", "path", path)
+		return false
+	}
+	if !h.includeGenerated {
+		if generated, why := Generated(path, data); generated {
+			slog.Debug("skipping file", "path", path, "reason", "generated", "marker", why)
+			return false
+		}
+	}
+	return true
+}
+
+// mustRel returns path relative to dir; Walk only yields paths below dir.
+func mustRel(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// binarySniffLen is how much of a file Binary inspects, as git does.
+const binarySniffLen = 8000
+
+// Binary reports whether data looks like a binary file: like git, it checks
+// for a NUL byte near the start.
+func Binary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binarySniffLen)], 0) >= 0
+}
+
+// generatedHeaderLines is how many leading lines Generated searches for a
+// generated-code marker.
+const generatedHeaderLines = 20
+
+// generatedMarker matches the comment tools put at the top of generated
+// files: Go's "Code generated ... DO NOT EDIT." convention, which other
+// generators (protoc, sqlc, mockgen, BAML) follow, and "@generated".
+var generatedMarker = regexp.MustCompile(`(?i)^\s*(?://|#|/?\*+|--|<!--)\s*(?:code generated .*do not edit|@generated\b|auto-?generated .*do not (?:edit|modify))`)
+
+// generatedSuffixes name files that are build output rather than source.
+var generatedSuffixes = []string{".min.js", ".min.css", "_pb2.py", ".g.dart", ".designer.cs"}
+
+// Generated reports whether the file at path with content data looks
+// generated, and the marker or file name that gave it away.
+func Generated(path string, data []byte) (bool, string) {
+	name := strings.ToLower(filepath.Base(path))
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true, "*" + suffix
+		}
+	}
+	for i, line := range bytes.SplitN(data, []byte("\n"), generatedHeaderLines+1) {
+		if i == generatedHeaderLines {
+			break
+		}
+		if generatedMarker.Match(line) {
+			return true, strings.TrimSpace(string(line))
+		}
+	}
+	return false, ""
+}
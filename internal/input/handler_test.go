@@ -3,6 +3,10 @@ package input
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -50,6 +54,19 @@ func TestHandler_ReadDiff(t *testing.T) {
 	}
 }
 
+func TestHandler_ReadDiff_NestedPath(t *testing.T) {
+	diff := "diff --git a/internal/app/main.go b/internal/app/main.go\n--- a/internal/app/main.go\n+++ b/internal/app/main.go\n@@ -1 +1 @@\n-package app\n+package main\n"
+
+	artifacts, err := NewHandler().ReadDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join("internal", "app", "main.go")
+	if len(artifacts) != 1 || artifacts[0].Path != want {
+		t.Fatalf("expected one artifact at %q, got %+v", want, artifacts)
+	}
+}
+
 func TestHandler_ReadDirectory(t *testing.T) {
 	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0644)
@@ -65,3 +82,75 @@ func TestHandler_ReadDirectory(t *testing.T) {
 		t.Errorf("expected at least 2 artifacts, got %d", len(artifacts))
 	}
 }
+
+func TestHandler_ReadDirectory_Skips(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":           "*.log\nbuild/\n/vendor\n!keep.log\n",
+		".gavelignore":         "testdata/\n!vendor\n",
+		"main.go":              "package main\n",
+		"debug.log":            "log\n",
+		"keep.log":             "kept\n",
+		"build/out.go":         "package out\n",
+		"vendor/lib/lib.go":    "package lib\n",
+		"pkg/build/x.go":       "package build\n",
+		"pkg/testdata/case.go": "package testdata\n",
+		"pkg/.gitignore":       "local.go\n",
+		"pkg/local.go":         "package pkg\n",
+		"pkg/api.go":           "package pkg\n",
+		"pkg/api.pb.go":        "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pkg\n",
+		"web/app.min.js":       "var a=1;\n",
+		"image.png":            "\x89PNG\x00\x00",
+		"big.txt":              strings.Repeat("x", 64),
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(opts ...HandlerOption) []string {
+		t.Helper()
+		artifacts, err := NewHandler(opts...).ReadDirectory(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, a := range artifacts {
+			rel, _ := filepath.Rel(dir, a.Path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	want := []string{".gavelignore", ".gitignore", "keep.log", "main.go", "pkg/.gitignore", "pkg/api.go", "vendor/lib/lib.go"}
+	if got := read(WithMaxFileSize(32)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDirectory = %v, want %v", got, want)
+	}
+
+	withGenerated := read(WithIncludeGenerated(true))
+	for _, name := range []string{"big.txt", "pkg/api.pb.go", "web/app.min.js"} {
+		if !slices.Contains(withGenerated, name) {
+			t.Errorf("expected %s with the size limit lifted and generated files included, got %v", name, withGenerated)
+		}
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	for content, want := range map[string]bool{
+		"// Code generated by mockgen. DO NOT EDIT.\npackage m\n": true,
+		"# @generated by pants\nx = 1\n":                          true,
+		"/* Auto-generated file, do not modify */\n":              true,
+		"package m\n// this code generated nothing\n":             false,
+	} {
+		if got, _ := Generated("f.go", []byte(content)); got != want {
+			t.Errorf("Generated(%q) = %v, want %v", content, got, want)
+		}
+	}
+	if got, _ := Generated("dist/app.min.js", []byte("x")); !got {
+		t.Error("expected a minified bundle to count as generated")
+	}
+}
@@ -0,0 +1,163 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IgnoreFiles are the files ReadDirectory reads ignore patterns from, in
+// every directory it walks and in the directories above it up to the root
+// of the enclosing git repository. .gavelignore is read after .gitignore, so
+// it can re-include ("!pattern") what git ignores.
+var IgnoreFiles = []string{".gitignore", ".gavelignore"}
+
+// ignoreRule is one pattern line of an ignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	source  string // file:line, for debug logging
+}
+
+// parseIgnore reads gitignore-format patterns. Blank lines and comments are
+// skipped; "\#" and "\!" escape a leading "#" or "!".
+func parseIgnore(data []byte, source string) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{source: source + ":" + strconv.Itoa(n)}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.re = compileIgnore(line)
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// compileIgnore translates a gitignore pattern, without its trailing slash,
+// into a regular expression over slash-separated paths relative to the
+// directory holding the ignore file. A pattern containing a slash is
+// anchored there; one without matches a name at any depth.
+func compileIgnore(pattern string) *regexp.Regexp {
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var re strings.Builder
+	re.WriteString("^")
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			re.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			re.WriteString("[^/]*")
+		case pattern[i] == '?':
+			re.WriteString("[^/]")
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
+
+// ignorer answers whether paths under a walk are ignored, reading each
+// directory's ignore files once.
+type ignorer struct {
+	top   string // highest directory whose ignore files apply
+	read  func(path string) ([]byte, error)
+	rules map[string][]ignoreRule
+}
+
+// newIgnorer returns an ignorer for a walk of dir. Ignore files in the
+// directories between the enclosing git repository's root and dir apply as
+// well; outside a repository only those in dir and below do.
+func newIgnorer(dir string) *ignorer {
+	top := dir
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			top = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return &ignorer{top: top, read: os.ReadFile, rules: make(map[string][]ignoreRule)}
+}
+
+// load returns the rules of the ignore files in dir.
+func (ig *ignorer) load(dir string) []ignoreRule {
+	if rules, ok := ig.rules[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	for _, name := range IgnoreFiles {
+		p := filepath.Join(dir, name)
+		if data, err := ig.read(p); err == nil {
+			rules = append(rules, parseIgnore(data, p)...)
+		}
+	}
+	ig.rules[dir] = rules
+	return rules
+}
+
+// match reports whether path, an absolute path below ig.top, is ignored,
+// and the pattern that decided. As in git, the last matching pattern wins,
+// patterns in deeper directories override shallower ones, and a "!" pattern
+// re-includes.
+func (ig *ignorer) match(path string, isDir bool) (bool, string) {
+	rel, err := filepath.Rel(ig.top, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false, ""
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	ignored, source := false, ""
+	dir := ig.top
+	for i := range parts {
+		sub := strings.Join(parts[i:], "/")
+		for _, r := range ig.load(dir) {
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if r.re.MatchString(sub) {
+				ignored, source = !r.negate, r.source
+			}
+		}
+		dir = filepath.Join(dir, parts[i])
+	}
+	return ignored, source
+}
@@ -0,0 +1,62 @@
+package input
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Shard selects one of Count disjoint slices of a run's artifacts, so a
+// large analysis can be split across CI jobs. Index is 1-based.
+type Shard struct {
+	Index int
+	Count int
+}
+
+// ParseShard parses an "INDEX/COUNT" shard such as "2/4", where
+// 1 <= INDEX <= COUNT.
+func ParseShard(s string) (Shard, error) {
+	indexStr, countStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("expected INDEX/COUNT, got %q", s)
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(indexStr))
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard index %q", indexStr)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard count %q", countStr)
+	}
+	if count < 1 || index < 1 || index > count {
+		return Shard{}, fmt.Errorf("shard %d/%d must satisfy 1 <= INDEX <= COUNT", index, count)
+	}
+	return Shard{Index: index, Count: count}, nil
+}
+
+// String formats s as accepted by ParseShard.
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// Contains reports whether the artifact at path belongs to s. Assignment
+// hashes the slash-separated path, so it is the same on every host and a
+// file stays in its shard as other files are added or removed.
+func (s Shard) Contains(path string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(filepath.ToSlash(path)))
+	return int(h.Sum32()%uint32(s.Count)) == s.Index-1
+}
+
+// Select returns the artifacts that belong to s, in their original order.
+func (s Shard) Select(artifacts []Artifact) []Artifact {
+	var selected []Artifact
+	for _, art := range artifacts {
+		if s.Contains(art.Path) {
+			selected = append(selected, art)
+		}
+	}
+	return selected
+}
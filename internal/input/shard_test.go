@@ -0,0 +1,54 @@
+package input
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	s, err := ParseShard("2/4")
+	if err != nil {
+		t.Fatalf("ParseShard: %v", err)
+	}
+	if s.Index != 2 || s.Count != 4 || s.String() != "2/4" {
+		t.Errorf("unexpected shard %+v", s)
+	}
+	for _, bad := range []string{"", "2", "0/4", "5/4", "a/4", "1/0", "-1/2"} {
+		if _, err := ParseShard(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestShardSelectPartitions(t *testing.T) {
+	var artifacts []Artifact
+	for i := 0; i < 100; i++ {
+		artifacts = append(artifacts, Artifact{Path: fmt.Sprintf("pkg%d/file%d.go", i%7, i)})
+	}
+
+	seen := make(map[string]int)
+	for i := 1; i <= 3; i++ {
+		selected := Shard{Index: i, Count: 3}.Select(artifacts)
+		if len(selected) == 0 {
+			t.Errorf("shard %d/3 selected no artifacts", i)
+		}
+		for _, a := range selected {
+			seen[a.Path]++
+		}
+	}
+	if len(seen) != len(artifacts) {
+		t.Errorf("expected every artifact in some shard, got %d of %d", len(seen), len(artifacts))
+	}
+	for path, n := range seen {
+		if n != 1 {
+			t.Errorf("%s selected by %d shards", path, n)
+		}
+	}
+
+	// Assignment depends only on the path, not on the other artifacts.
+	a := Artifact{Path: "pkg1/file8.go"}
+	want := Shard{Index: 2, Count: 3}.Contains(a.Path)
+	if got := len(Shard{Index: 2, Count: 3}.Select([]Artifact{a})) == 1; got != want {
+		t.Error("expected shard membership to be independent of the artifact set")
+	}
+}
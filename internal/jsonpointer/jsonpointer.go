@@ -0,0 +1,103 @@
+// Package jsonpointer resolves RFC 6901 JSON Pointers against decoded JSON
+// values, so a subtree of a SARIF log (or any JSON document) can be
+// extracted without external tools.
+package jsonpointer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse splits pointer into its unescaped reference tokens. The empty
+// pointer refers to the whole document and yields no tokens; any other
+// pointer must start with "/". Within a token "~1" stands for "/" and "~0"
+// for "~"; any other use of "~" is an error.
+func Parse(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with \"/\"", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tok := range tokens {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || (tok[j+1] != '0' && tok[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid JSON pointer %q: \"~\" must be followed by 0 or 1", pointer)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// Resolve returns the value pointer refers to within doc, which must be made
+// of the types encoding/json decodes into an interface{}: maps with string
+// keys, slices, and scalars. Errors name the deepest prefix of the pointer
+// that did resolve.
+func Resolve(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := Parse(pointer)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	at := ""
+	for _, tok := range tokens {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[tok]
+			if !ok {
+				return nil, fmt.Errorf("%s: no member %q at %s", pointer, tok, location(at))
+			}
+			cur = next
+		case []interface{}:
+			i, err := arrayIndex(tok)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w at %s", pointer, err, location(at))
+			}
+			if i >= len(v) {
+				return nil, fmt.Errorf("%s: index %d out of range at %s (length %d)", pointer, i, location(at), len(v))
+			}
+			cur = v[i]
+		default:
+			return nil, fmt.Errorf("%s: cannot descend into %s at %s", pointer, kind(cur), location(at))
+		}
+		at += "/" + strings.ReplaceAll(strings.ReplaceAll(tok, "~", "~0"), "/", "~1")
+	}
+	return cur, nil
+}
+
+// arrayIndex parses an array reference token: a non-negative decimal with
+// no leading zeros. The "-" token (one past the end) never resolves.
+func arrayIndex(tok string) (int, error) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') || strings.TrimLeft(tok, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	return i, nil
+}
+
+func location(at string) string {
+	if at == "" {
+		return "document root"
+	}
+	return at
+}
+
+func kind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,75 @@
+package jsonpointer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestResolve(t *testing.T) {
+	doc := decode(t, `{
+		"runs": [{"results": [{"ruleId": "S2068"}, {"ruleId": "S1135"}]}],
+		"a/b": 1,
+		"m~n": 2,
+		"": 3
+	}`)
+
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{"", `{"runs":[{"results":[{"ruleId":"S2068"},{"ruleId":"S1135"}]}],"a/b":1,"m~n":2,"":3}`},
+		{"/runs/0/results", `[{"ruleId":"S2068"},{"ruleId":"S1135"}]`},
+		{"/runs/0/results/1/ruleId", `"S1135"`},
+		{"/a~1b", `1`},
+		{"/m~0n", `2`},
+		{"/", `3`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pointer, func(t *testing.T) {
+			got, err := Resolve(doc, tc.pointer)
+			if err != nil {
+				t.Fatalf("Resolve(%q): %v", tc.pointer, err)
+			}
+			if want := decode(t, tc.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("Resolve(%q) = %v, want %v", tc.pointer, got, want)
+			}
+		})
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	doc := decode(t, `{"runs": [{"results": [], "tool": "gavel"}]}`)
+
+	tests := []struct {
+		pointer string
+		wantErr string
+	}{
+		{"runs/0", `must be empty or start with "/"`},
+		{"/runs~2", `"~" must be followed by 0 or 1`},
+		{"/runs/0/tool~", `"~" must be followed by 0 or 1`},
+		{"/missing", `no member "missing" at document root`},
+		{"/runs/1", "index 1 out of range at /runs (length 1)"},
+		{"/runs/01", `invalid array index "01" at /runs`},
+		{"/runs/-", `invalid array index "-" at /runs`},
+		{"/runs/0/tool/name", "cannot descend into a string at /runs/0/tool"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pointer, func(t *testing.T) {
+			_, err := Resolve(doc, tc.pointer)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Resolve(%q): expected error containing %q, got %v", tc.pointer, tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chris-regnier/gavel/internal/analyzer"
@@ -17,6 +18,7 @@ import (
 
 // AnalyzerWrapper wraps the BAML analyzer with caching
 type AnalyzerWrapper struct {
+	mu     sync.RWMutex // guards client and cfg, which Reload replaces
 	client analyzer.BAMLClient
 	cfg    *config.Config
 	cache  cache.CacheManager
@@ -36,8 +38,24 @@ func (w *AnalyzerWrapper) WithCache(c cache.CacheManager) *AnalyzerWrapper {
 	return w
 }
 
+// Reload replaces the client and configuration used by later analyses.
+// Analyses already running finish with the ones they started with.
+func (w *AnalyzerWrapper) Reload(client analyzer.BAMLClient, cfg *config.Config) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.client = client
+	w.cfg = cfg
+}
+
 // Analyze runs analysis on a file with optional caching
 func (w *AnalyzerWrapper) Analyze(ctx context.Context, path, content string) ([]sarif.Result, error) {
+	w.mu.RLock()
+	snapshot := &AnalyzerWrapper{client: w.client, cfg: w.cfg, cache: w.cache}
+	w.mu.RUnlock()
+	return snapshot.analyze(ctx, path, content)
+}
+
+func (w *AnalyzerWrapper) analyze(ctx context.Context, path, content string) ([]sarif.Result, error) {
 	// Build cache key
 	cacheKey := w.buildCacheKey(path, content)
 
@@ -131,6 +149,8 @@ func (w *AnalyzerWrapper) getModel() string {
 		return w.cfg.Provider.Bedrock.Model
 	case "openai":
 		return w.cfg.Provider.OpenAI.Model
+	case "llamacpp":
+		return w.cfg.Provider.LlamaCpp.Model
 	default:
 		return "unknown"
 	}
@@ -335,3 +335,32 @@ func TestFormatPolicies(t *testing.T) {
 		t.Error("Did not expect formatted policies to contain disabled instruction")
 	}
 }
+
+func TestAnalyzerWrapperReload(t *testing.T) {
+	cfg := &config.Config{
+		Provider: config.ProviderConfig{Name: "ollama", Ollama: config.OllamaConfig{Model: "test-model"}},
+		Persona:  "code-reviewer",
+		Policies: map[string]config.Policy{},
+	}
+	wrapper := NewAnalyzerWrapper(&mockBAMLClient{}, cfg)
+
+	// No enabled policies means no LLM call and no findings
+	results, err := wrapper.Analyze(context.Background(), "/test.go", "package main\n")
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected no findings before reload, got %v (err %v)", results, err)
+	}
+
+	reloaded := *cfg
+	reloaded.Policies = map[string]config.Policy{
+		"security": {Enabled: true, Severity: "error", Instruction: "Check for security issues"},
+	}
+	wrapper.Reload(&mockBAMLClient{findings: []analyzer.Finding{{RuleID: "SEC001", Level: "error", StartLine: 1, EndLine: 1}}}, &reloaded)
+
+	results, err = wrapper.Analyze(context.Background(), "/test.go", "package main\n")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(results) != 1 || results[0].RuleID != "SEC001" {
+		t.Errorf("expected the reloaded client and policies to be used, got %v", results)
+	}
+}
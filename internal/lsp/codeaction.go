@@ -172,7 +172,7 @@ func sarifRegionToLSPRange(region sarif.Region) Range {
 //   - Already a `file://` URI → return as-is.
 //   - Otherwise, if the artifact path matches the document's path (by suffix),
 //     reuse the document URI verbatim so editors don't see a divergent URI.
-//   - Absolute paths get a `file://` prefix.
+//   - Absolute paths are converted to a `file://` URI.
 //   - Relative paths are resolved against the document URI's directory.
 func resolveArtifactURI(documentURI, artifactURI string) string {
 	if artifactURI == "" {
@@ -188,14 +188,14 @@ func resolveArtifactURI(documentURI, artifactURI string) string {
 	}
 
 	if filepath.IsAbs(artifactURI) {
-		return "file://" + artifactURI
+		return pathToURI(artifactURI)
 	}
 
 	if docPath == "" {
 		return ""
 	}
-	resolved := filepath.Join(filepath.Dir(docPath), artifactURI)
-	return "file://" + resolved
+	resolved := filepath.Join(filepath.Dir(docPath), filepath.FromSlash(artifactURI))
+	return pathToURI(resolved)
 }
 
 // pathsMatch reports whether two paths refer to the same file. It accepts
@@ -0,0 +1,45 @@
+// internal/lsp/codelens.go
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+)
+
+// GetCodeLenses returns two lenses above each top-level function or method
+// of a document: the number of diagnostics inside it, and a command that
+// runs analysis on the function's lines alone.
+func GetCodeLenses(uri, path, content string, diagnostics []Diagnostic) []CodeLens {
+	var lenses []CodeLens
+	for _, fn := range astcheck.Functions(path, []byte(content)) {
+		// Diagnostics are 0-indexed; functions are 1-indexed
+		count := 0
+		for _, d := range diagnostics {
+			if line := d.Range.Start.Line + 1; line >= fn.StartLine && line <= fn.EndLine {
+				count++
+			}
+		}
+
+		r := Range{
+			Start: Position{Line: fn.StartLine - 1},
+			End:   Position{Line: fn.StartLine - 1},
+		}
+		lenses = append(lenses,
+			CodeLens{Range: r, Command: &Command{Title: findingsTitle(count)}},
+			CodeLens{Range: r, Command: &Command{
+				Title:     "Analyze function",
+				Command:   CommandAnalyzeFunction,
+				Arguments: []interface{}{uri, fn.StartLine, fn.EndLine, fn.Name},
+			}},
+		)
+	}
+	return lenses
+}
+
+func findingsTitle(n int) string {
+	if n == 1 {
+		return "Gavel: 1 finding"
+	}
+	return fmt.Sprintf("Gavel: %d findings", n)
+}
@@ -0,0 +1,65 @@
+// internal/lsp/codelens_test.go
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGetCodeLenses(t *testing.T) {
+	content := "package main\n\nfunc A() {\n\tx()\n\ty()\n}\n\nfunc B() {}\n"
+	diags := []Diagnostic{
+		{Range: Range{Start: Position{Line: 3}}},
+		{Range: Range{Start: Position{Line: 4}}},
+		{Range: Range{Start: Position{Line: 0}}},
+	}
+
+	lenses := GetCodeLenses("file:///main.go", "/main.go", content, diags)
+	if len(lenses) != 4 {
+		t.Fatalf("expected two lenses per function, got %d", len(lenses))
+	}
+	if got := lenses[0].Command.Title; got != "Gavel: 2 findings" {
+		t.Errorf("A count lens = %q", got)
+	}
+	if lenses[0].Range.Start.Line != 2 {
+		t.Errorf("A lens on line %d, want 2", lenses[0].Range.Start.Line)
+	}
+	if got := lenses[2].Command.Title; got != "Gavel: 0 findings" {
+		t.Errorf("B count lens = %q", got)
+	}
+	analyze := lenses[1].Command
+	if analyze.Command != CommandAnalyzeFunction || analyze.Arguments[1] != 3 || analyze.Arguments[2] != 6 || analyze.Arguments[3] != "A" {
+		t.Errorf("A analyze lens = %+v", analyze)
+	}
+
+	if got := GetCodeLenses("file:///notes.txt", "/notes.txt", "hello\n", nil); len(got) != 0 {
+		t.Errorf("expected no lenses for an unsupported language, got %d", len(got))
+	}
+}
+
+func TestServerCodeLens(t *testing.T) {
+	params := CodeLensParams{TextDocument: TextDocumentIdentifier{URI: "file:///main.go"}}
+	input := makeJSONRPCMessage(MethodTextDocumentCodeLens, params, 7)
+
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(strings.NewReader(input)), bufio.NewWriter(&output), nil)
+	server.documents["file:///main.go"] = "package main\n\nfunc A() {}\n"
+
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("codeLens: %v", err)
+	}
+	var resp struct {
+		Result []CodeLens `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(output.String()[strings.Index(output.String(), "{"):]), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	// Without range analysis only the count lens is offered
+	if len(resp.Result) != 1 || resp.Result[0].Command.Title != "Gavel: 0 findings" {
+		t.Errorf("lenses = %+v", resp.Result)
+	}
+}
@@ -4,6 +4,9 @@ package lsp
 import (
 	"context"
 	"fmt"
+	"log/slog"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
 )
 
 // CommandResult represents the result of executing a command
@@ -34,6 +37,8 @@ func (h *CommandHandler) Execute(ctx context.Context, params ExecuteCommandParam
 		return h.clearCache(ctx, params.Arguments)
 	case CommandShowRecommendation:
 		return h.showRecommendation(ctx, params.Arguments)
+	case CommandAnalyzeFunction:
+		return h.analyzeFunction(ctx, params.Arguments)
 	default:
 		return nil, fmt.Errorf("unknown command: %s", params.Command)
 	}
@@ -124,6 +129,103 @@ func (h *CommandHandler) analyzeWorkspace(ctx context.Context, args []interface{
 	}, nil
 }
 
+// analyzeFunction analyzes one function's lines and replaces the document's
+// findings inside them with the results
+func (h *CommandHandler) analyzeFunction(ctx context.Context, args []interface{}) (*CommandResult, error) {
+	if len(args) < 3 {
+		return &CommandResult{Success: false, Message: "requires uri, startLine, endLine arguments"}, nil
+	}
+	uri, ok := args[0].(string)
+	if !ok {
+		return &CommandResult{Success: false, Message: "file URI must be a string"}, nil
+	}
+	start, okStart := intArg(args[1])
+	end, okEnd := intArg(args[2])
+	if !okStart || !okEnd || start <= 0 || end < start {
+		return &CommandResult{Success: false, Message: "startLine and endLine must be a 1-indexed line range"}, nil
+	}
+	name := fmt.Sprintf("lines %d-%d", start, end)
+	if len(args) > 3 {
+		if fn, ok := args[3].(string); ok && fn != "" {
+			name = fn
+		}
+	}
+
+	if h.server.rangeAnalyze == nil {
+		return &CommandResult{Success: false, Message: "function analysis is not available"}, nil
+	}
+
+	h.server.docMu.RLock()
+	content, ok := h.server.documents[uri]
+	h.server.docMu.RUnlock()
+	if !ok {
+		return &CommandResult{
+			Success: false,
+			Message: fmt.Sprintf("document not open: %s", uri),
+		}, nil
+	}
+
+	results, err := h.server.rangeAnalyze(ctx, uriToPath(uri), content, start, end)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		if len(results) == 0 {
+			return nil, fmt.Errorf("analyzing %s: %w", name, err)
+		}
+		// A failed tier still leaves the others' findings worth showing
+		slog.Warn("function analysis incomplete", "uri", uri, "function", name, "err", err)
+	}
+
+	// Findings elsewhere in the file are kept; those inside the function
+	// are replaced by the new analysis
+	h.server.resultsMu.Lock()
+	merged := append([]sarif.Result{}, results...)
+	for _, r := range h.server.resultsCache[uri].results {
+		if line := resultStartLine(r); line < start || line > end {
+			merged = append(merged, r)
+		}
+	}
+	diagnostics := SarifResultsToDiagnostics(merged)
+	h.server.resultsCache[uri] = resultsCacheEntry{
+		results:     merged,
+		diagnostics: diagnostics,
+	}
+	h.server.resultsMu.Unlock()
+
+	if err := h.server.publishDiagnostics(uri, diagnostics); err != nil {
+		slog.Error("failed to publish diagnostics", "uri", uri, "err", err)
+	}
+
+	return &CommandResult{
+		Success: true,
+		Message: fmt.Sprintf("Analyzed %s: %d finding(s)", name, len(results)),
+		Data:    map[string]int{"findings": len(results)},
+	}, nil
+}
+
+// intArg converts a command argument to an int. Arguments decoded from
+// JSON arrive as float64.
+func intArg(arg interface{}) (int, bool) {
+	switch v := arg.(type) {
+	case float64:
+		return int(v), v == float64(int(v))
+	case int:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// resultStartLine returns the 1-indexed line a result starts on, or 0 when
+// it has no location.
+func resultStartLine(r sarif.Result) int {
+	if len(r.Locations) == 0 {
+		return 0
+	}
+	return r.Locations[0].PhysicalLocation.Region.StartLine
+}
+
 // showRecommendation returns the recommendation text for a finding
 func (h *CommandHandler) showRecommendation(ctx context.Context, args []interface{}) (*CommandResult, error) {
 	if len(args) < 3 {
@@ -201,3 +201,60 @@ func TestCommandHandler_ClearCache(t *testing.T) {
 		t.Errorf("Expected message 'Cache not configured', got %q", cmdResult.Message)
 	}
 }
+
+func resultAt(ruleID string, line int) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   "warning",
+		Message: sarif.Message{Text: ruleID},
+		Locations: []sarif.Location{{
+			PhysicalLocation: sarif.PhysicalLocation{Region: sarif.Region{StartLine: line, EndLine: line}},
+		}},
+	}
+}
+
+func TestCommandHandler_AnalyzeFunction(t *testing.T) {
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(bytes.NewReader(nil)), bufio.NewWriter(&output), nil)
+	uri := "file:///test.go"
+	server.documents[uri] = "package main\n\nfunc A() {\n}\n\nfunc B() {\n}\n"
+	server.resultsCache[uri] = resultsCacheEntry{results: []sarif.Result{resultAt("OLD-A", 3), resultAt("OLD-B", 6)}}
+
+	handler := NewCommandHandler(server)
+	params := ExecuteCommandParams{Command: CommandAnalyzeFunction, Arguments: []interface{}{uri, float64(3), float64(4), "A"}}
+
+	result, err := handler.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if cr := result.(*CommandResult); cr.Success {
+		t.Error("expected failure without a range analyze function")
+	}
+
+	var gotRange [2]int
+	server.SetRangeAnalyze(func(ctx context.Context, path, content string, startLine, endLine int) ([]sarif.Result, error) {
+		gotRange = [2]int{startLine, endLine}
+		return []sarif.Result{resultAt("NEW-A", 4)}, nil
+	})
+	result, err = handler.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if cr := result.(*CommandResult); !cr.Success {
+		t.Fatalf("expected success, got %q", cr.Message)
+	}
+	if gotRange != [2]int{3, 4} {
+		t.Errorf("analyzed lines %v, want [3 4]", gotRange)
+	}
+
+	var ids []string
+	for _, r := range server.resultsCache[uri].results {
+		ids = append(ids, r.RuleID)
+	}
+	if len(ids) != 2 || ids[0] != "NEW-A" || ids[1] != "OLD-B" {
+		t.Errorf("expected the function's findings replaced and others kept, got %v", ids)
+	}
+	if !bytes.Contains(output.Bytes(), []byte(MethodTextDocumentPublishDiagnostics)) {
+		t.Error("expected diagnostics to be published")
+	}
+}
@@ -0,0 +1,96 @@
+// internal/lsp/configwatch.go
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultConfigPollInterval is how often a ConfigWatcher checks for changes
+const DefaultConfigPollInterval = 2 * time.Second
+
+// ConfigWatcher polls a project's .gavel directory and calls onChange when
+// policies.yaml, or a rule or persona file, is created, modified or
+// removed. Polling works whether or not the client supports
+// workspace/didChangeWatchedFiles.
+type ConfigWatcher struct {
+	dir      string
+	interval time.Duration
+	onChange func()
+	last     string
+}
+
+// NewConfigWatcher creates a watcher for dir, taking its current contents
+// as the starting point
+func NewConfigWatcher(dir string, interval time.Duration, onChange func()) *ConfigWatcher {
+	if onChange == nil {
+		panic("onChange callback cannot be nil")
+	}
+	if interval <= 0 {
+		interval = DefaultConfigPollInterval
+	}
+	return &ConfigWatcher{
+		dir:      dir,
+		interval: interval,
+		onChange: onChange,
+		last:     configFingerprint(dir),
+	}
+}
+
+// Run polls until ctx is done
+func (w *ConfigWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if w.changed() {
+				w.onChange()
+			}
+		}
+	}
+}
+
+// changed reports whether the watched files differ from the last check
+func (w *ConfigWatcher) changed() bool {
+	fp := configFingerprint(w.dir)
+	if fp == w.last {
+		return false
+	}
+	w.last = fp
+	return true
+}
+
+// configFingerprint summarizes the path, size and modification time of
+// each configuration file under dir
+func configFingerprint(dir string) string {
+	paths := []string{filepath.Join(dir, "policies.yaml")}
+	for _, sub := range []string{"rules", "personas"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				paths = append(paths, filepath.Join(dir, sub, entry.Name()))
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+// internal/lsp/configwatch_test.go
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher_Changed(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "rules"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("policies.yaml", "policies: {}\n")
+
+	w := NewConfigWatcher(dir, time.Hour, func() {})
+	if w.changed() {
+		t.Fatal("expected no change right after creation")
+	}
+
+	write("rules/custom.yaml", "rules: []\n")
+	if !w.changed() {
+		t.Error("expected a new rule file to be a change")
+	}
+	if w.changed() {
+		t.Error("expected the change to be reported once")
+	}
+
+	write("policies.yaml", "policies: {shadowed-variable: {enabled: true}}\n")
+	if !w.changed() {
+		t.Error("expected an edited policies.yaml to be a change")
+	}
+
+	write("rules/notes.txt", "not a rule file")
+	if w.changed() {
+		t.Error("expected files other than YAML to be ignored")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "rules", "custom.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if !w.changed() {
+		t.Error("expected a removed rule file to be a change")
+	}
+}
+
+func TestConfigWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+	reloaded := make(chan struct{}, 1)
+	w := NewConfigWatcher(dir, 10*time.Millisecond, func() { reloaded <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	if err := os.WriteFile(filepath.Join(dir, "policies.yaml"), []byte("policies: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected onChange after policies.yaml was created")
+	}
+}
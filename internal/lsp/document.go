@@ -0,0 +1,65 @@
+// internal/lsp/document.go
+package lsp
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// applyContentChanges applies textDocument/didChange events to text in the
+// order they were sent, as the protocol requires
+func applyContentChanges(text string, changes []TextDocumentContentChangeEvent) (string, error) {
+	for i, change := range changes {
+		if change.Range == nil {
+			text = change.Text
+			continue
+		}
+		start, err := offsetAt(text, change.Range.Start)
+		if err != nil {
+			return "", fmt.Errorf("change %d: start: %w", i, err)
+		}
+		end, err := offsetAt(text, change.Range.End)
+		if err != nil {
+			return "", fmt.Errorf("change %d: end: %w", i, err)
+		}
+		if end < start {
+			return "", fmt.Errorf("change %d: range end precedes its start", i)
+		}
+		text = text[:start] + change.Text + text[end:]
+	}
+	return text, nil
+}
+
+// offsetAt converts a position to a byte offset in text. Characters are
+// counted in UTF-16 code units, the protocol's default encoding, and a
+// character past the end of its line means the end of the line.
+func offsetAt(text string, pos Position) (int, error) {
+	if pos.Line < 0 || pos.Character < 0 {
+		return 0, fmt.Errorf("invalid position %d:%d", pos.Line, pos.Character)
+	}
+
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is past the end of the document", pos.Line)
+		}
+		offset += i + 1
+	}
+
+	units := 0
+	for offset < len(text) && units < pos.Character {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if r == '\n' || (r == '\r' && offset+1 < len(text) && text[offset+1] == '\n') {
+			break
+		}
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+		offset += size
+	}
+	return offset, nil
+}
@@ -0,0 +1,77 @@
+// internal/lsp/document_test.go
+package lsp
+
+import "testing"
+
+func rng(sl, sc, el, ec int) *Range {
+	return &Range{Start: Position{Line: sl, Character: sc}, End: Position{Line: el, Character: ec}}
+}
+
+func TestApplyContentChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		changes []TextDocumentContentChangeEvent
+		want    string
+	}{
+		{
+			name:    "insert",
+			text:    "package main\n\nfunc main() {}\n",
+			changes: []TextDocumentContentChangeEvent{{Range: rng(2, 13, 2, 13), Text: " println() "}},
+			want:    "package main\n\nfunc main() { println() }\n",
+		},
+		{
+			name:    "delete across lines",
+			text:    "a\nb\nc\n",
+			changes: []TextDocumentContentChangeEvent{{Range: rng(0, 1, 2, 0), Text: ""}},
+			want:    "ac\n",
+		},
+		{
+			name: "applied in order",
+			text: "abc\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(0, 0, 0, 1), Text: "xy"},
+				{Range: rng(0, 2, 0, 3), Text: "Z"},
+			},
+			want: "xyZc\n",
+		},
+		{
+			name:    "full replacement",
+			text:    "old\n",
+			changes: []TextDocumentContentChangeEvent{{Text: "new\n"}, {Range: rng(1, 0, 1, 0), Text: "tail"}},
+			want:    "new\ntail",
+		},
+		{
+			name:    "utf-16 characters",
+			text:    "s := \"é😀x\"\n",
+			changes: []TextDocumentContentChangeEvent{{Range: rng(0, 9, 0, 10), Text: "y"}},
+			want:    "s := \"é😀y\"\n",
+		},
+		{
+			name:    "character past line end",
+			text:    "ab\r\ncd\n",
+			changes: []TextDocumentContentChangeEvent{{Range: rng(0, 99, 0, 99), Text: "!"}},
+			want:    "ab!\r\ncd\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyContentChanges(tt.text, tt.changes)
+			if err != nil {
+				t.Fatalf("applyContentChanges: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyContentChanges_Invalid(t *testing.T) {
+	if _, err := applyContentChanges("one line", []TextDocumentContentChangeEvent{{Range: rng(3, 0, 3, 0), Text: "x"}}); err == nil {
+		t.Error("expected an error for a line past the end")
+	}
+	if _, err := applyContentChanges("abc", []TextDocumentContentChangeEvent{{Range: rng(0, 2, 0, 1), Text: "x"}}); err == nil {
+		t.Error("expected an error for a reversed range")
+	}
+}
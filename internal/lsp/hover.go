@@ -0,0 +1,98 @@
+// internal/lsp/hover.go
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// GetHover returns Markdown describing every finding whose lines include
+// pos, or nil when there are none. Each finding shows its rule, level,
+// confidence, explanation, remediation, and CWE, OWASP and reference links,
+// which are otherwise only visible in the SARIF output.
+func GetHover(pos Position, results []sarif.Result) *Hover {
+	var sections []string
+	var hoverRange *Range
+	for _, r := range results {
+		if len(r.Locations) == 0 {
+			continue
+		}
+		region := r.Locations[0].PhysicalLocation.Region
+		// SARIF is 1-indexed, LSP positions are 0-indexed
+		start, end := region.StartLine-1, region.EndLine-1
+		if end < start {
+			end = start
+		}
+		if pos.Line < start || pos.Line > end {
+			continue
+		}
+		sections = append(sections, hoverMarkdown(r))
+		if hoverRange == nil {
+			hoverRange = &Range{Start: Position{Line: start}, End: Position{Line: end + 1}}
+		}
+	}
+	if len(sections) == 0 {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: strings.Join(sections, "\n\n---\n\n")},
+		Range:    hoverRange,
+	}
+}
+
+// hoverMarkdown renders one finding
+func hoverMarkdown(r sarif.Result) string {
+	var b strings.Builder
+
+	header := fmt.Sprintf("**%s** · %s", r.RuleID, r.Level)
+	if c, ok := r.Properties["gavel/confidence"].(float64); ok && c > 0 {
+		header += fmt.Sprintf(" · %.0f%% confidence", c*100)
+	}
+	b.WriteString(header)
+	if r.Message.Text != "" {
+		b.WriteString("\n\n" + r.Message.Text)
+	}
+
+	if explanation := stringProp(r, "gavel/explanation"); explanation != "" {
+		b.WriteString("\n\n**Why it matters:** " + explanation)
+	}
+	remediation := stringProp(r, "gavel/remediation")
+	if remediation == "" {
+		remediation = stringProp(r, "gavel/recommendation")
+	}
+	if remediation != "" {
+		b.WriteString("\n\n**Remediation:** " + remediation)
+	}
+
+	var links []string
+	for _, id := range sarif.StringsProperty(r, "gavel/cwe") {
+		links = append(links, fmt.Sprintf("[%s](%s)", id, rules.CWEURL(id)))
+	}
+	for _, id := range sarif.StringsProperty(r, "gavel/owasp") {
+		if url := rules.OWASPURL(id); url != "" {
+			links = append(links, fmt.Sprintf("[OWASP %s](%s)", id, url))
+		} else {
+			links = append(links, "OWASP "+id)
+		}
+	}
+	if len(links) > 0 {
+		b.WriteString("\n\n" + strings.Join(links, " · "))
+	}
+
+	if refs := sarif.StringsProperty(r, "gavel/references"); len(refs) > 0 {
+		b.WriteString("\n\n**References:**")
+		for _, ref := range refs {
+			b.WriteString("\n- " + ref)
+		}
+	}
+
+	return b.String()
+}
+
+func stringProp(r sarif.Result, key string) string {
+	s, _ := r.Properties[key].(string)
+	return s
+}
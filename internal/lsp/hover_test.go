@@ -0,0 +1,88 @@
+// internal/lsp/hover_test.go
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func hoverResult() sarif.Result {
+	r := resultAt("S2068", 3)
+	r.Locations[0].PhysicalLocation.Region.EndLine = 4
+	r.Message.Text = "Hardcoded password"
+	r.Properties = map[string]interface{}{
+		"gavel/confidence":  0.9,
+		"gavel/explanation": "Credentials in source leak through version control.",
+		"gavel/remediation": "Load the password from the environment.",
+		"gavel/cwe":         []string{"CWE-798"},
+		"gavel/owasp":       []interface{}{"A07:2021"},
+		"gavel/references":  []string{"https://example.com/secrets"},
+	}
+	return r
+}
+
+func TestGetHover(t *testing.T) {
+	results := []sarif.Result{hoverResult(), resultAt("OTHER", 10)}
+
+	h := GetHover(Position{Line: 3, Character: 5}, results)
+	if h == nil {
+		t.Fatal("expected a hover on the finding's second line")
+	}
+	if h.Contents.Kind != "markdown" {
+		t.Errorf("kind = %q", h.Contents.Kind)
+	}
+	for _, want := range []string{
+		"**S2068** · warning · 90% confidence",
+		"Hardcoded password",
+		"**Why it matters:** Credentials in source",
+		"**Remediation:** Load the password",
+		"[CWE-798](https://cwe.mitre.org/data/definitions/798.html)",
+		"[OWASP A07:2021](https://owasp.org/Top10/A07_2021-Identification_and_Authentication_Failures/)",
+		"- https://example.com/secrets",
+	} {
+		if !strings.Contains(h.Contents.Value, want) {
+			t.Errorf("hover missing %q:\n%s", want, h.Contents.Value)
+		}
+	}
+	if strings.Contains(h.Contents.Value, "OTHER") {
+		t.Error("hover includes a finding on another line")
+	}
+	if h.Range == nil || h.Range.Start.Line != 2 || h.Range.End.Line != 4 {
+		t.Errorf("range = %+v, want lines 2-3", h.Range)
+	}
+
+	if GetHover(Position{Line: 0}, results) != nil {
+		t.Error("expected no hover away from findings")
+	}
+}
+
+func TestGetHover_RecommendationFallback(t *testing.T) {
+	r := resultAt("LLM001", 1)
+	r.Properties = map[string]interface{}{"gavel/recommendation": "Check the error."}
+	h := GetHover(Position{Line: 0}, []sarif.Result{r})
+	if h == nil || !strings.Contains(h.Contents.Value, "**Remediation:** Check the error.") {
+		t.Errorf("expected the recommendation as remediation, got %+v", h)
+	}
+}
+
+func TestServerHover(t *testing.T) {
+	params := HoverParams{TextDocument: TextDocumentIdentifier{URI: "file:///main.go"}, Position: Position{Line: 9}}
+	input := makeJSONRPCMessage(MethodTextDocumentHover, params, 3)
+
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(strings.NewReader(input)), bufio.NewWriter(&output), nil)
+	server.resultsCache["file:///main.go"] = resultsCacheEntry{results: []sarif.Result{hoverResult()}}
+
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("hover: %v", err)
+	}
+	// Nothing under the cursor is a null result, not a missing one
+	if !strings.Contains(output.String(), `"result":null`) {
+		t.Errorf("expected a null result, got %s", output.String())
+	}
+}
@@ -10,22 +10,34 @@ const (
 	MethodShutdown                       = "shutdown"
 	MethodExit                           = "exit"
 	MethodTextDocumentDidOpen            = "textDocument/didOpen"
+	MethodTextDocumentDidChange          = "textDocument/didChange"
 	MethodTextDocumentDidClose           = "textDocument/didClose"
 	MethodTextDocumentDidSave            = "textDocument/didSave"
 	MethodTextDocumentPublishDiagnostics = "textDocument/publishDiagnostics"
 	MethodTextDocumentCodeAction         = "textDocument/codeAction"
+	MethodTextDocumentCodeLens           = "textDocument/codeLens"
+	MethodTextDocumentHover              = "textDocument/hover"
+	MethodTextDocumentDiagnostic         = "textDocument/diagnostic"
+	MethodWorkspaceDiagnostic            = "workspace/diagnostic"
+	MethodWorkspaceDiagnosticRefresh     = "workspace/diagnostic/refresh"
 	MethodWorkspaceExecuteCommand        = "workspace/executeCommand"
 	MethodWorkspaceDidChangeConfig       = "workspace/didChangeConfiguration"
 	MethodWindowWorkDoneProgressCreate   = "window/workDoneProgress/create"
 	MethodProgress                       = "$/progress"
+	MethodCancelRequest                  = "$/cancelRequest"
 )
 
+// errorCodeRequestCancelled is the JSON-RPC error code for a request the
+// client cancelled with $/cancelRequest
+const errorCodeRequestCancelled = -32800
+
 // Gavel custom command names
 const (
 	CommandAnalyzeFile        = "gavel.analyzeFile"
 	CommandAnalyzeWorkspace   = "gavel.analyzeWorkspace"
 	CommandClearCache         = "gavel.clearCache"
 	CommandShowRecommendation = "gavel.showRecommendation"
+	CommandAnalyzeFunction    = "gavel.analyzeFunction"
 )
 
 // InitializeParams represents the parameters for the initialize request
@@ -46,11 +58,31 @@ type ClientInfo struct {
 // ClientCapabilities defines the capabilities provided by the client
 type ClientCapabilities struct {
 	TextDocument *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+	Workspace    *WorkspaceClientCapabilities    `json:"workspace,omitempty"`
+}
+
+// WorkspaceClientCapabilities defines workspace specific client capabilities
+type WorkspaceClientCapabilities struct {
+	Diagnostics *DiagnosticWorkspaceClientCapabilities `json:"diagnostics,omitempty"`
+}
+
+// DiagnosticWorkspaceClientCapabilities defines workspace capabilities for pull diagnostics
+type DiagnosticWorkspaceClientCapabilities struct {
+	RefreshSupport bool `json:"refreshSupport,omitempty"`
 }
 
 // TextDocumentClientCapabilities defines text document specific client capabilities
 type TextDocumentClientCapabilities struct {
 	PublishDiagnostics *PublishDiagnosticsClientCapabilities `json:"publishDiagnostics,omitempty"`
+	Diagnostic         *DiagnosticClientCapabilities         `json:"diagnostic,omitempty"`
+}
+
+// DiagnosticClientCapabilities defines capabilities for pull diagnostics.
+// Its presence means the client pulls diagnostics rather than relying on
+// publishDiagnostics.
+type DiagnosticClientCapabilities struct {
+	DynamicRegistration    bool `json:"dynamicRegistration,omitempty"`
+	RelatedDocumentSupport bool `json:"relatedDocumentSupport,omitempty"`
 }
 
 // PublishDiagnosticsClientCapabilities defines capabilities for diagnostics
@@ -75,9 +107,24 @@ type ServerInfo struct {
 type ServerCapabilities struct {
 	TextDocumentSync    *TextDocumentSyncOptions `json:"textDocumentSync,omitempty"`
 	CodeActionProvider  bool                     `json:"codeActionProvider,omitempty"`
+	CodeLensProvider    *CodeLensOptions         `json:"codeLensProvider,omitempty"`
+	HoverProvider       bool                     `json:"hoverProvider,omitempty"`
+	DiagnosticProvider  *DiagnosticOptions       `json:"diagnosticProvider,omitempty"`
 	ExecuteCommandProvider *ExecuteCommandOptions `json:"executeCommandProvider,omitempty"`
 }
 
+// CodeLensOptions defines code lens capabilities
+type CodeLensOptions struct {
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+}
+
+// DiagnosticOptions defines pull diagnostics capabilities
+type DiagnosticOptions struct {
+	Identifier            string `json:"identifier,omitempty"`
+	InterFileDependencies bool   `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool   `json:"workspaceDiagnostics"`
+}
+
 // ExecuteCommandOptions defines command execution capabilities
 type ExecuteCommandOptions struct {
 	Commands []string `json:"commands"`
@@ -108,6 +155,26 @@ type DidOpenTextDocumentParams struct {
 	TextDocument TextDocumentItem `json:"textDocument"`
 }
 
+// VersionedTextDocumentIdentifier identifies a specific version of a text document
+type VersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// TextDocumentContentChangeEvent describes a change to a text document.
+// Without a Range, Text replaces the whole document.
+type TextDocumentContentChangeEvent struct {
+	Range       *Range `json:"range,omitempty"`
+	RangeLength *int   `json:"rangeLength,omitempty"` // Deprecated by the protocol; ignored
+	Text        string `json:"text"`
+}
+
+// DidChangeTextDocumentParams represents the parameters for textDocument/didChange
+type DidChangeTextDocumentParams struct {
+	TextDocument   VersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
 // DidCloseTextDocumentParams represents the parameters for textDocument/didClose
 type DidCloseTextDocumentParams struct {
 	TextDocument TextDocumentIdentifier `json:"textDocument"`
@@ -125,6 +192,68 @@ type PublishDiagnosticsParams struct {
 	Diagnostics []Diagnostic `json:"diagnostics"`
 }
 
+// Diagnostic report kinds
+const (
+	DiagnosticReportKindFull      = "full"
+	DiagnosticReportKindUnchanged = "unchanged"
+)
+
+// DocumentDiagnosticParams represents parameters for textDocument/diagnostic
+type DocumentDiagnosticParams struct {
+	TextDocument     TextDocumentIdentifier `json:"textDocument"`
+	Identifier       string                 `json:"identifier,omitempty"`
+	PreviousResultID string                 `json:"previousResultId,omitempty"`
+}
+
+// FullDocumentDiagnosticReport carries every diagnostic of a document
+type FullDocumentDiagnosticReport struct {
+	Kind     string       `json:"kind"` // DiagnosticReportKindFull
+	ResultID string       `json:"resultId,omitempty"`
+	Items    []Diagnostic `json:"items"`
+}
+
+// UnchangedDocumentDiagnosticReport tells the client its previous result
+// for a document is still current
+type UnchangedDocumentDiagnosticReport struct {
+	Kind     string `json:"kind"` // DiagnosticReportKindUnchanged
+	ResultID string `json:"resultId"`
+}
+
+// PreviousResultID is a result ID the client holds for a document
+type PreviousResultID struct {
+	URI   string `json:"uri"`
+	Value string `json:"value"`
+}
+
+// WorkspaceDiagnosticParams represents parameters for workspace/diagnostic
+type WorkspaceDiagnosticParams struct {
+	Identifier        string             `json:"identifier,omitempty"`
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+// WorkspaceFullDocumentDiagnosticReport is a full report within a
+// workspace/diagnostic response
+type WorkspaceFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+	URI     string `json:"uri"`
+	Version *int   `json:"version"` // null when no document version is known
+}
+
+// WorkspaceUnchangedDocumentDiagnosticReport is an unchanged report within
+// a workspace/diagnostic response
+type WorkspaceUnchangedDocumentDiagnosticReport struct {
+	UnchangedDocumentDiagnosticReport
+	URI     string `json:"uri"`
+	Version *int   `json:"version"` // null when no document version is known
+}
+
+// WorkspaceDiagnosticReport is the result of workspace/diagnostic. Each item
+// is a WorkspaceFullDocumentDiagnosticReport or a
+// WorkspaceUnchangedDocumentDiagnosticReport.
+type WorkspaceDiagnosticReport struct {
+	Items []interface{} `json:"items"`
+}
+
 // CodeActionParams represents parameters for textDocument/codeAction
 type CodeActionParams struct {
 	TextDocument TextDocumentIdentifier `json:"textDocument"`
@@ -148,6 +277,40 @@ type CodeAction struct {
 	Command     *Command     `json:"command,omitempty"`
 }
 
+// CodeLensParams represents parameters for textDocument/codeLens
+type CodeLensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// CodeLens represents a command shown inline above a range of source
+type CodeLens struct {
+	Range   Range    `json:"range"`
+	Command *Command `json:"command,omitempty"`
+}
+
+// HoverParams represents parameters for textDocument/hover
+type HoverParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// MarkupContent is text rendered by the client, such as Markdown
+type MarkupContent struct {
+	Kind  string `json:"kind"` // "plaintext" or "markdown"
+	Value string `json:"value"`
+}
+
+// Hover represents the result of textDocument/hover
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// CancelParams represents the parameters of $/cancelRequest
+type CancelParams struct {
+	ID interface{} `json:"id"`
+}
+
 // CodeActionKind constants
 const (
 	CodeActionKindQuickFix = "quickfix"
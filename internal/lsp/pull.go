@@ -0,0 +1,121 @@
+// internal/lsp/pull.go
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/chris-regnier/gavel/internal/cache"
+)
+
+// diagnosticResultID identifies a set of diagnostics so a client can ask
+// whether its copy is still current. Equal diagnostics give equal IDs, so a
+// re-analysis that changes nothing is reported as unchanged.
+func diagnosticResultID(diagnostics []Diagnostic) string {
+	data, err := json.Marshal(diagnostics)
+	if err != nil {
+		return ""
+	}
+	return cache.GenerateKey(string(data))[:16]
+}
+
+// documentReport returns the current diagnostics of uri as a full report,
+// or as an unchanged report when previousResultID still matches them
+func (s *Server) documentReport(uri, previousResultID string) interface{} {
+	s.resultsMu.RLock()
+	diagnostics := s.resultsCache[uri].diagnostics
+	s.resultsMu.RUnlock()
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+
+	resultID := diagnosticResultID(diagnostics)
+	if previousResultID != "" && previousResultID == resultID {
+		return UnchangedDocumentDiagnosticReport{Kind: DiagnosticReportKindUnchanged, ResultID: resultID}
+	}
+	return FullDocumentDiagnosticReport{Kind: DiagnosticReportKindFull, ResultID: resultID, Items: diagnostics}
+}
+
+// handleDocumentDiagnostic processes textDocument/diagnostic requests.
+// Diagnostics come from the last analysis; analysis itself still runs on
+// open and save, after which the client is asked to pull again.
+func (s *Server) handleDocumentDiagnostic(id interface{}, params json.RawMessage) error {
+	var diagParams DocumentDiagnosticParams
+	if err := json.Unmarshal(params, &diagParams); err != nil {
+		return s.sendResponse(id, nil, map[string]interface{}{
+			"code":    -32602,
+			"message": fmt.Sprintf("invalid params: %v", err),
+		})
+	}
+
+	return s.sendResponse(id, s.documentReport(diagParams.TextDocument.URI, diagParams.PreviousResultID), nil)
+}
+
+// handleWorkspaceDiagnostic processes workspace/diagnostic requests with a
+// report for every document Gavel has open or has results for
+func (s *Server) handleWorkspaceDiagnostic(id interface{}, params json.RawMessage) error {
+	var wsParams WorkspaceDiagnosticParams
+	if err := json.Unmarshal(params, &wsParams); err != nil {
+		return s.sendResponse(id, nil, map[string]interface{}{
+			"code":    -32602,
+			"message": fmt.Sprintf("invalid params: %v", err),
+		})
+	}
+
+	previous := make(map[string]string, len(wsParams.PreviousResultIDs))
+	for _, p := range wsParams.PreviousResultIDs {
+		previous[p.URI] = p.Value
+	}
+
+	seen := make(map[string]bool)
+	s.docMu.RLock()
+	for uri := range s.documents {
+		seen[uri] = true
+	}
+	s.docMu.RUnlock()
+	s.resultsMu.RLock()
+	for uri := range s.resultsCache {
+		seen[uri] = true
+	}
+	s.resultsMu.RUnlock()
+	uris := make([]string, 0, len(seen))
+	for uri := range seen {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	report := WorkspaceDiagnosticReport{Items: make([]interface{}, 0, len(uris))}
+	for _, uri := range uris {
+		if !s.shouldAnalyze(uri) {
+			continue
+		}
+		switch r := s.documentReport(uri, previous[uri]).(type) {
+		case FullDocumentDiagnosticReport:
+			report.Items = append(report.Items, WorkspaceFullDocumentDiagnosticReport{FullDocumentDiagnosticReport: r, URI: uri})
+		case UnchangedDocumentDiagnosticReport:
+			report.Items = append(report.Items, WorkspaceUnchangedDocumentDiagnosticReport{UnchangedDocumentDiagnosticReport: r, URI: uri})
+		}
+	}
+
+	return s.sendResponse(id, report, nil)
+}
+
+// requestDiagnosticRefresh asks a pull-model client to pull diagnostics
+// again after an analysis changed them
+func (s *Server) requestDiagnosticRefresh() error {
+	if !s.diagnosticRefresh {
+		return nil
+	}
+	request := jsonRPCMessage{
+		JSONRPC: "2.0",
+		ID:      fmt.Sprintf("diagnostic-refresh-%d", s.refreshSeq.Add(1)),
+		Method:  MethodWorkspaceDiagnosticRefresh,
+	}
+	if err := s.sendMessage(request); err != nil {
+		slog.Error("failed to request diagnostic refresh", "err", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// internal/lsp/pull_test.go
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// pullResult runs one request through a server holding diags for
+// file:///main.go and decodes the response's result into v
+func pullResult(t *testing.T, method string, params interface{}, diags []Diagnostic, v interface{}) {
+	t.Helper()
+	input := makeJSONRPCMessage(method, params, 7)
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(strings.NewReader(input)), bufio.NewWriter(&output), nil)
+	server.resultsCache["file:///main.go"] = resultsCacheEntry{results: []sarif.Result{resultAt("S2068", 3)}, diagnostics: diags}
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+
+	parts := strings.SplitN(output.String(), "\r\n\r\n", 2)
+	if len(parts) != 2 {
+		t.Fatalf("invalid response: %s", output.String())
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(parts[1]), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if err := json.Unmarshal(resp.Result, v); err != nil {
+		t.Fatalf("decoding result %s: %v", resp.Result, err)
+	}
+}
+
+func TestDocumentDiagnostic(t *testing.T) {
+	diags := []Diagnostic{{Range: Range{Start: Position{Line: 2}}, Severity: DiagnosticSeverityWarning, Source: "gavel", Message: "Hardcoded password"}}
+	doc := TextDocumentIdentifier{URI: "file:///main.go"}
+
+	var full FullDocumentDiagnosticReport
+	pullResult(t, MethodTextDocumentDiagnostic, DocumentDiagnosticParams{TextDocument: doc}, diags, &full)
+	if full.Kind != DiagnosticReportKindFull || len(full.Items) != 1 || full.ResultID == "" {
+		t.Fatalf("expected a full report with one item and a result ID, got %+v", full)
+	}
+
+	var unchanged UnchangedDocumentDiagnosticReport
+	pullResult(t, MethodTextDocumentDiagnostic, DocumentDiagnosticParams{TextDocument: doc, PreviousResultID: full.ResultID}, diags, &unchanged)
+	if unchanged.Kind != DiagnosticReportKindUnchanged || unchanged.ResultID != full.ResultID {
+		t.Errorf("expected an unchanged report for the same result ID, got %+v", unchanged)
+	}
+
+	var stale FullDocumentDiagnosticReport
+	pullResult(t, MethodTextDocumentDiagnostic, DocumentDiagnosticParams{TextDocument: doc, PreviousResultID: "stale"}, diags, &stale)
+	if stale.Kind != DiagnosticReportKindFull {
+		t.Errorf("expected a full report for an outdated result ID, got %+v", stale)
+	}
+}
+
+func TestDocumentDiagnostic_UnknownDocument(t *testing.T) {
+	var report map[string]interface{}
+	params := DocumentDiagnosticParams{TextDocument: TextDocumentIdentifier{URI: "file:///other.go"}}
+	pullResult(t, MethodTextDocumentDiagnostic, params, nil, &report)
+	items, ok := report["items"].([]interface{})
+	if report["kind"] != DiagnosticReportKindFull || !ok || len(items) != 0 {
+		t.Errorf("expected a full report with empty items, got %v", report)
+	}
+}
+
+func TestWorkspaceDiagnostic(t *testing.T) {
+	diags := []Diagnostic{{Range: Range{Start: Position{Line: 2}}, Severity: DiagnosticSeverityWarning, Source: "gavel", Message: "Hardcoded password"}}
+
+	var first struct {
+		Items []struct {
+			Kind     string       `json:"kind"`
+			URI      string       `json:"uri"`
+			ResultID string       `json:"resultId"`
+			Items    []Diagnostic `json:"items"`
+		} `json:"items"`
+	}
+	pullResult(t, MethodWorkspaceDiagnostic, WorkspaceDiagnosticParams{}, diags, &first)
+	if len(first.Items) != 1 || first.Items[0].URI != "file:///main.go" || first.Items[0].Kind != DiagnosticReportKindFull || len(first.Items[0].Items) != 1 {
+		t.Fatalf("expected one full report for main.go, got %+v", first)
+	}
+
+	var second struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	params := WorkspaceDiagnosticParams{PreviousResultIDs: []PreviousResultID{{URI: "file:///main.go", Value: first.Items[0].ResultID}}}
+	pullResult(t, MethodWorkspaceDiagnostic, params, diags, &second)
+	if len(second.Items) != 1 || second.Items[0]["kind"] != DiagnosticReportKindUnchanged {
+		t.Errorf("expected an unchanged report for main.go, got %+v", second)
+	}
+}
+
+func TestServerPullClientGetsRefresh(t *testing.T) {
+	initParams := InitializeParams{Capabilities: ClientCapabilities{
+		TextDocument: &TextDocumentClientCapabilities{Diagnostic: &DiagnosticClientCapabilities{}},
+		Workspace:    &WorkspaceClientCapabilities{Diagnostics: &DiagnosticWorkspaceClientCapabilities{RefreshSupport: true}},
+	}}
+	input := makeJSONRPCMessage(MethodInitialize, initParams, 1)
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(strings.NewReader(input)), bufio.NewWriter(&output), nil)
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	if !strings.Contains(output.String(), `"diagnosticProvider":{"identifier":"gavel"`) {
+		t.Errorf("expected the diagnostic provider capability, got %s", output.String())
+	}
+
+	output.Reset()
+	if err := server.publishDiagnostics("file:///main.go", nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	out := output.String()
+	if strings.Contains(out, MethodTextDocumentPublishDiagnostics) {
+		t.Error("pull clients should not be sent publishDiagnostics")
+	}
+	if !strings.Contains(out, MethodWorkspaceDiagnosticRefresh) {
+		t.Errorf("expected a diagnostic refresh request, got %s", out)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/chris-regnier/gavel/internal/cache"
@@ -25,6 +26,10 @@ type AnalyzeFunc func(ctx context.Context, path, content string) ([]sarif.Result
 // The channel is closed when all tiers complete. The context supports cancellation.
 type ProgressiveAnalyzeFunc func(ctx context.Context, path, content string) <-chan ProgressiveResult
 
+// RangeAnalyzeFunc analyzes lines [startLine, endLine] (1-indexed, inclusive)
+// of a file, returning only findings that start inside the range.
+type RangeAnalyzeFunc func(ctx context.Context, path, content string, startLine, endLine int) ([]sarif.Result, error)
+
 // ProgressiveResult is a single tier's findings for a file
 type ProgressiveResult struct {
 	Tier    string        // "instant", "fast", "comprehensive"
@@ -48,6 +53,7 @@ type cancelEntry struct {
 // ServerConfig holds configuration for the LSP server
 type ServerConfig struct {
 	DebounceDuration time.Duration
+	EditDebounce     time.Duration // Quiet period after unsaved edits before instant analysis
 	ParallelFiles    int
 	WatchPatterns    []string
 	IgnorePatterns   []string
@@ -57,6 +63,7 @@ type ServerConfig struct {
 func DefaultServerConfig() ServerConfig {
 	return ServerConfig{
 		DebounceDuration: 300 * time.Millisecond,
+		EditDebounce:     300 * time.Millisecond,
 		ParallelFiles:    3,
 		WatchPatterns: []string{
 			"**/*.go", "**/*.py", "**/*.ts", "**/*.tsx", "**/*.js", "**/*.jsx",
@@ -104,19 +111,31 @@ type Server struct {
 	resultsCache map[string]resultsCacheEntry
 	resultsMu    sync.RWMutex
 
-	// Per-file cancellation for in-flight progressive analysis
+	// Per-file cancellation for in-flight analysis, superseded by newer
+	// edits and saves
 	cancelFuncs map[string]cancelEntry // URI -> cancel entry with generation
 	cancelGen   uint64                 // monotonic generation counter
 	cancelMu    sync.Mutex
 
+	// Cancellation of running requests by $/cancelRequest
+	requests   map[string]context.CancelFunc // request ID -> cancel
+	requestsMu sync.Mutex
+
 	// Writer mutex protects s.writer from concurrent writes
 	writerMu sync.Mutex
 
 	// Optional progressive analysis function
 	progressiveAnalyze ProgressiveAnalyzeFunc
 
+	// Optional instant-tier analysis run on unsaved edits
+	instantAnalyze AnalyzeFunc
+
+	// Optional analysis of a line range, behind the "Analyze function" lens
+	rangeAnalyze RangeAnalyzeFunc
+
 	// Components
 	watcher      *DebouncedWatcher
+	editWatcher  *DebouncedWatcher // Debounces didChange before instant analysis
 	cacheManager cache.CacheManager
 	progress     *ProgressReporter
 	commands     *CommandHandler
@@ -125,6 +144,12 @@ type Server struct {
 	// State
 	rootURI     string
 	initialized bool
+
+	// Pull diagnostics: set when the client pulls diagnostics instead of
+	// receiving publishDiagnostics, and whether it accepts refresh requests
+	pullDiagnostics   bool
+	diagnosticRefresh bool
+	refreshSeq        atomic.Uint64
 }
 
 // NewServer creates a new LSP server with default configuration
@@ -142,6 +167,7 @@ func NewServerWithConfig(reader *bufio.Reader, writer *bufio.Writer, analyze Ana
 		contentHashes: make(map[string]string),
 		resultsCache:  make(map[string]resultsCacheEntry),
 		cancelFuncs:   make(map[string]cancelEntry),
+		requests:      make(map[string]context.CancelFunc),
 		config:        cfg,
 	}
 
@@ -158,22 +184,49 @@ func NewServerWithConfig(reader *bufio.Reader, writer *bufio.Writer, analyze Ana
 		WatchPatterns:    cfg.WatchPatterns,
 		IgnorePatterns:   cfg.IgnorePatterns,
 	}
-	s.watcher = NewDebouncedWatcherWithConfig(watcherConfig, func(files []string) {
+	s.watcher = NewDebouncedWatcherWithConfig(watcherConfig, s.analyzeDocuments)
+	// Edits use their own short debounce so diagnostics keep up with typing
+	// even when full analysis waits minutes
+	editConfig := watcherConfig
+	editConfig.DebounceDuration = cfg.EditDebounce
+	s.editWatcher = NewDebouncedWatcherWithConfig(editConfig, func(files []string) {
 		for _, uri := range files {
-			s.docMu.RLock()
-			content, ok := s.documents[uri]
-			s.docMu.RUnlock()
-
-			if ok {
-				path := uriToPath(uri)
-				go s.analyzeAndPublish(context.Background(), uri, path, content)
-			}
+			s.analyzeInstantAndPublish(context.Background(), uri)
 		}
 	})
 
 	return s
 }
 
+// ReanalyzeOpenDocuments analyzes every open document again and publishes
+// the results, even when its content is unchanged. Call it after the
+// analysis functions start using new policies or rules.
+func (s *Server) ReanalyzeOpenDocuments() {
+	s.docMu.Lock()
+	uris := make([]string, 0, len(s.documents))
+	for uri := range s.documents {
+		uris = append(uris, uri)
+		delete(s.contentHashes, uri)
+	}
+	s.docMu.Unlock()
+
+	s.analyzeDocuments(uris)
+}
+
+// analyzeDocuments starts analysis of each open document among uris
+func (s *Server) analyzeDocuments(uris []string) {
+	for _, uri := range uris {
+		s.docMu.RLock()
+		content, ok := s.documents[uri]
+		s.docMu.RUnlock()
+
+		if ok {
+			path := uriToPath(uri)
+			go s.analyzeAndPublish(context.Background(), uri, path, content)
+		}
+	}
+}
+
 // SetCacheManager sets the cache manager for the server
 func (s *Server) SetCacheManager(c cache.CacheManager) {
 	s.cacheManager = c
@@ -184,6 +237,18 @@ func (s *Server) SetProgressiveAnalyze(fn ProgressiveAnalyzeFunc) {
 	s.progressiveAnalyze = fn
 }
 
+// SetInstantAnalyze sets the function run on unsaved edits. It should be
+// cheap, such as the instant tier alone; LLM tiers wait for a save.
+func (s *Server) SetInstantAnalyze(fn AnalyzeFunc) {
+	s.instantAnalyze = fn
+}
+
+// SetRangeAnalyze sets the function the analyze-function command runs. Code
+// lenses offer the command only when it is set.
+func (s *Server) SetRangeAnalyze(fn RangeAnalyzeFunc) {
+	s.rangeAnalyze = fn
+}
+
 // jsonRPCMessage represents a JSON-RPC 2.0 message
 type jsonRPCMessage struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -256,20 +321,35 @@ func (s *Server) handleMessage(ctx context.Context) error {
 		return nil
 	case MethodTextDocumentDidOpen:
 		return s.handleDidOpen(ctx, msg.Params)
+	case MethodTextDocumentDidChange:
+		return s.handleDidChange(msg.Params)
 	case MethodTextDocumentDidSave:
 		return s.handleDidSave(ctx, msg.Params)
 	case MethodTextDocumentDidClose:
 		return s.handleDidClose(msg.Params)
 	case MethodTextDocumentCodeAction:
 		return s.handleCodeAction(ctx, msg.ID, msg.Params)
+	case MethodTextDocumentCodeLens:
+		return s.handleCodeLens(msg.ID, msg.Params)
+	case MethodTextDocumentHover:
+		return s.handleHover(msg.ID, msg.Params)
+	case MethodTextDocumentDiagnostic:
+		return s.handleDocumentDiagnostic(msg.ID, msg.Params)
+	case MethodWorkspaceDiagnostic:
+		return s.handleWorkspaceDiagnostic(msg.ID, msg.Params)
 	case MethodWorkspaceExecuteCommand:
 		return s.handleExecuteCommand(ctx, msg.ID, msg.Params)
 	case MethodWorkspaceDidChangeConfig:
 		return s.handleDidChangeConfiguration(msg.Params)
+	case MethodCancelRequest:
+		return s.handleCancelRequest(msg.Params)
 	case MethodShutdown:
 		return s.handleShutdown(msg.ID)
 	case MethodExit:
 		return io.EOF
+	case "":
+		// Response to a request the server sent, such as a diagnostic refresh
+		return nil
 	default:
 		slog.Warn("unhandled LSP method", "method", msg.Method)
 		return nil
@@ -284,21 +364,34 @@ func (s *Server) handleInitialize(id interface{}, params json.RawMessage) error
 	}
 
 	s.rootURI = initParams.RootURI
+	if td := initParams.Capabilities.TextDocument; td != nil && td.Diagnostic != nil {
+		s.pullDiagnostics = true
+	}
+	if ws := initParams.Capabilities.Workspace; ws != nil && ws.Diagnostics != nil {
+		s.diagnosticRefresh = ws.Diagnostics.RefreshSupport
+	}
 
 	result := InitializeResult{
 		Capabilities: ServerCapabilities{
 			TextDocumentSync: &TextDocumentSyncOptions{
 				OpenClose: true,
-				Change:    1, // Full sync
+				Change:    2, // Incremental sync
 				Save:      true,
 			},
 			CodeActionProvider: true,
+			CodeLensProvider:   &CodeLensOptions{},
+			HoverProvider:      true,
+			DiagnosticProvider: &DiagnosticOptions{
+				Identifier:           "gavel",
+				WorkspaceDiagnostics: true,
+			},
 			ExecuteCommandProvider: &ExecuteCommandOptions{
 				Commands: []string{
 					CommandAnalyzeFile,
 					CommandAnalyzeWorkspace,
 					CommandClearCache,
 					CommandShowRecommendation,
+					CommandAnalyzeFunction,
 				},
 			},
 		},
@@ -337,6 +430,50 @@ func (s *Server) handleDidOpen(ctx context.Context, params json.RawMessage) erro
 	return nil
 }
 
+// handleDidChange processes textDocument/didChange notification. Edits are
+// applied to the tracked buffer, and the instant tier re-runs once typing
+// pauses so diagnostics follow unsaved changes.
+func (s *Server) handleDidChange(params json.RawMessage) error {
+	var didChangeParams DidChangeTextDocumentParams
+	if err := json.Unmarshal(params, &didChangeParams); err != nil {
+		return err
+	}
+
+	uri := didChangeParams.TextDocument.URI
+
+	// Check if we should watch this file
+	if !s.shouldAnalyze(uri) {
+		return nil
+	}
+
+	s.docMu.Lock()
+	content, ok := s.documents[uri]
+	if !ok {
+		s.docMu.Unlock()
+		return fmt.Errorf("didChange for unopened document %s", uri)
+	}
+	updated, err := applyContentChanges(content, didChangeParams.ContentChanges)
+	if err != nil {
+		s.docMu.Unlock()
+		return fmt.Errorf("applying changes to %s: %w", uri, err)
+	}
+	s.documents[uri] = updated
+	s.docMu.Unlock()
+
+	// Results of an analysis still running on the old content would be
+	// stale by the time they arrive
+	if updated != content {
+		s.cancelAnalysis(uri)
+	}
+
+	// Trigger instant analysis via the edit watcher (debounced)
+	if s.instantAnalyze != nil {
+		s.editWatcher.FileChanged(uri)
+	}
+
+	return nil
+}
+
 // handleDidSave processes textDocument/didSave notification
 func (s *Server) handleDidSave(ctx context.Context, params json.RawMessage) error {
 	var didSaveParams DidSaveTextDocumentParams
@@ -374,12 +511,7 @@ func (s *Server) handleDidClose(params json.RawMessage) error {
 	uri := didCloseParams.TextDocument.URI
 
 	// Cancel any in-flight analysis
-	s.cancelMu.Lock()
-	if e, ok := s.cancelFuncs[uri]; ok {
-		e.cancel()
-		delete(s.cancelFuncs, uri)
-	}
-	s.cancelMu.Unlock()
+	s.cancelAnalysis(uri)
 
 	// Remove document from tracking
 	s.docMu.Lock()
@@ -397,10 +529,13 @@ func (s *Server) handleDidClose(params json.RawMessage) error {
 
 // handleShutdown processes the shutdown request
 func (s *Server) handleShutdown(id interface{}) error {
-	// Stop the watcher
+	// Stop the watchers
 	if s.watcher != nil {
 		s.watcher.Stop()
 	}
+	if s.editWatcher != nil {
+		s.editWatcher.Stop()
+	}
 	return s.sendResponse(id, nil, nil)
 }
 
@@ -438,7 +573,68 @@ func (s *Server) handleCodeAction(ctx context.Context, id interface{}, params js
 	return s.sendResponse(id, actions, nil)
 }
 
-// handleExecuteCommand processes workspace/executeCommand requests
+// handleCodeLens processes textDocument/codeLens requests
+func (s *Server) handleCodeLens(id interface{}, params json.RawMessage) error {
+	var clParams CodeLensParams
+	if err := json.Unmarshal(params, &clParams); err != nil {
+		return s.sendResponse(id, nil, map[string]interface{}{
+			"code":    -32602,
+			"message": fmt.Sprintf("invalid params: %v", err),
+		})
+	}
+
+	uri := clParams.TextDocument.URI
+
+	s.docMu.RLock()
+	content, ok := s.documents[uri]
+	s.docMu.RUnlock()
+	if !ok {
+		return s.sendResponse(id, []CodeLens{}, nil)
+	}
+
+	s.resultsMu.RLock()
+	diagnostics := s.resultsCache[uri].diagnostics
+	s.resultsMu.RUnlock()
+
+	lenses := GetCodeLenses(uri, uriToPath(uri), content, diagnostics)
+	if s.rangeAnalyze == nil {
+		// Without range analysis only the finding counts are shown
+		counts := lenses[:0]
+		for _, l := range lenses {
+			if l.Command.Command != CommandAnalyzeFunction {
+				counts = append(counts, l)
+			}
+		}
+		lenses = counts
+	}
+	if lenses == nil {
+		lenses = []CodeLens{}
+	}
+
+	return s.sendResponse(id, lenses, nil)
+}
+
+// handleHover processes textDocument/hover requests
+func (s *Server) handleHover(id interface{}, params json.RawMessage) error {
+	var hoverParams HoverParams
+	if err := json.Unmarshal(params, &hoverParams); err != nil {
+		return s.sendResponse(id, nil, map[string]interface{}{
+			"code":    -32602,
+			"message": fmt.Sprintf("invalid params: %v", err),
+		})
+	}
+
+	s.resultsMu.RLock()
+	results := s.resultsCache[hoverParams.TextDocument.URI].results
+	s.resultsMu.RUnlock()
+
+	// A nil hover is sent as a null result, meaning nothing to show
+	return s.sendResponse(id, GetHover(hoverParams.Position, results), nil)
+}
+
+// handleExecuteCommand processes workspace/executeCommand requests.
+// Commands can run an LLM analysis, so they run in the background where
+// $/cancelRequest can still reach them.
 func (s *Server) handleExecuteCommand(ctx context.Context, id interface{}, params json.RawMessage) error {
 	var execParams ExecuteCommandParams
 	if err := json.Unmarshal(params, &execParams); err != nil {
@@ -448,15 +644,66 @@ func (s *Server) handleExecuteCommand(ctx context.Context, id interface{}, param
 		})
 	}
 
-	result, err := s.commands.Execute(ctx, execParams)
-	if err != nil {
-		return s.sendResponse(id, nil, map[string]interface{}{
-			"code":    -32603,
-			"message": err.Error(),
-		})
+	reqCtx, done := s.trackRequest(ctx, id)
+	go func() {
+		defer done()
+
+		result, err := s.commands.Execute(reqCtx, execParams)
+		if reqCtx.Err() != nil && ctx.Err() == nil {
+			err = s.sendResponse(id, nil, map[string]interface{}{
+				"code":    errorCodeRequestCancelled,
+				"message": "request cancelled",
+			})
+		} else if err != nil {
+			err = s.sendResponse(id, nil, map[string]interface{}{
+				"code":    -32603,
+				"message": err.Error(),
+			})
+		} else {
+			err = s.sendResponse(id, result, nil)
+		}
+		if err != nil {
+			slog.Error("failed to send command response", "command", execParams.Command, "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// trackRequest returns a context for request id that $/cancelRequest
+// cancels, and a function to call once the request has been answered
+func (s *Server) trackRequest(ctx context.Context, id interface{}) (context.Context, func()) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	key := fmt.Sprint(id)
+
+	s.requestsMu.Lock()
+	s.requests[key] = cancel
+	s.requestsMu.Unlock()
+
+	return reqCtx, func() {
+		s.requestsMu.Lock()
+		delete(s.requests, key)
+		s.requestsMu.Unlock()
+		cancel()
 	}
+}
 
-	return s.sendResponse(id, result, nil)
+// handleCancelRequest processes $/cancelRequest notifications. Requests
+// that already finished, or that are answered immediately, are ignored.
+func (s *Server) handleCancelRequest(params json.RawMessage) error {
+	var cancelParams CancelParams
+	if err := json.Unmarshal(params, &cancelParams); err != nil {
+		return err
+	}
+
+	s.requestsMu.Lock()
+	cancel, ok := s.requests[fmt.Sprint(cancelParams.ID)]
+	s.requestsMu.Unlock()
+	if ok {
+		slog.Debug("cancelling request", "id", cancelParams.ID)
+		cancel()
+	}
+	return nil
 }
 
 // handleDidChangeConfiguration processes workspace/didChangeConfiguration notifications
@@ -503,6 +750,8 @@ func (s *Server) handleDidChangeConfiguration(params json.RawMessage) error {
 	}
 
 	s.watcher.UpdateConfig(newConfig)
+	newConfig.DebounceDuration = 0 // keep the edit debounce
+	s.editWatcher.UpdateConfig(newConfig)
 
 	return nil
 }
@@ -544,21 +793,30 @@ func (s *Server) analyzeAndPublish(ctx context.Context, uri, path, content strin
 	s.cancelMu.Unlock()
 
 	if s.progressiveAnalyze != nil {
-		s.analyzeProgressive(analysisCtx, uri, path, content, gen)
+		s.analyzeProgressive(analysisCtx, uri, path, content)
 	} else {
-		s.analyzeSynchronous(analysisCtx, uri, path, content, gen)
+		s.analyzeSynchronous(analysisCtx, uri, path, content)
 	}
-}
 
-func (s *Server) analyzeSynchronous(ctx context.Context, uri, path, content string, gen uint64) {
-	defer s.cleanupCancel(uri, gen)
+	// A superseded analysis did not finish, so the same content must be
+	// analyzed again if it is saved again
+	if analysisCtx.Err() != nil {
+		s.docMu.Lock()
+		if s.contentHashes[uri] == newHash {
+			delete(s.contentHashes, uri)
+		}
+		s.docMu.Unlock()
+	}
+	s.cleanupCancel(uri, gen)
+}
 
+func (s *Server) analyzeSynchronous(ctx context.Context, uri, path, content string) {
 	results, err := s.analyze(ctx, path, content)
+	if ctx.Err() != nil {
+		slog.Debug("analysis cancelled", "uri", uri)
+		return
+	}
 	if err != nil {
-		if ctx.Err() != nil {
-			slog.Debug("analysis cancelled", "uri", uri)
-			return
-		}
 		slog.Error("analysis failed", "uri", uri, "err", err)
 		return
 	}
@@ -577,13 +835,15 @@ func (s *Server) analyzeSynchronous(ctx context.Context, uri, path, content stri
 	}
 }
 
-func (s *Server) analyzeProgressive(ctx context.Context, uri, path, content string, gen uint64) {
-	defer s.cleanupCancel(uri, gen)
-
+func (s *Server) analyzeProgressive(ctx context.Context, uri, path, content string) {
 	resultCh := s.progressiveAnalyze(ctx, path, content)
 
 	var allResults []sarif.Result
 	for tierResult := range resultCh {
+		if ctx.Err() != nil {
+			// Superseded: drain the remaining tiers without publishing them
+			continue
+		}
 		if tierResult.Error != nil {
 			slog.Error("tier analysis failed", "uri", uri, "tier", tierResult.Tier, "err", tierResult.Error)
 			continue
@@ -605,6 +865,61 @@ func (s *Server) analyzeProgressive(ctx context.Context, uri, path, content stri
 	}
 }
 
+// analyzeInstantAndPublish runs the instant analysis on a document's
+// current buffer and publishes its findings alongside the fast and
+// comprehensive tier findings of the last full analysis, which are kept
+// until the next save re-runs those tiers.
+func (s *Server) analyzeInstantAndPublish(ctx context.Context, uri string) {
+	s.docMu.RLock()
+	content, ok := s.documents[uri]
+	s.docMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	results, err := s.instantAnalyze(ctx, uriToPath(uri), content)
+	if err != nil {
+		slog.Error("instant analysis failed", "uri", uri, "err", err)
+		return
+	}
+
+	// A newer edit arrived while analyzing; its own debounce publishes
+	s.docMu.RLock()
+	current, ok := s.documents[uri]
+	s.docMu.RUnlock()
+	if !ok || current != content {
+		return
+	}
+
+	s.resultsMu.Lock()
+	combined := append([]sarif.Result{}, results...)
+	for _, r := range s.resultsCache[uri].results {
+		if tier, _ := r.Properties["gavel/tier"].(string); tier != "instant" {
+			combined = append(combined, r)
+		}
+	}
+	diagnostics := SarifResultsToDiagnostics(combined)
+	s.resultsCache[uri] = resultsCacheEntry{
+		results:     combined,
+		diagnostics: diagnostics,
+	}
+	s.resultsMu.Unlock()
+
+	if err := s.publishDiagnostics(uri, diagnostics); err != nil {
+		slog.Error("failed to publish diagnostics", "uri", uri, "tier", "instant", "err", err)
+	}
+}
+
+// cancelAnalysis cancels the in-flight analysis of uri, if any
+func (s *Server) cancelAnalysis(uri string) {
+	s.cancelMu.Lock()
+	if e, ok := s.cancelFuncs[uri]; ok {
+		e.cancel()
+		delete(s.cancelFuncs, uri)
+	}
+	s.cancelMu.Unlock()
+}
+
 func (s *Server) cleanupCancel(uri string, gen uint64) {
 	s.cancelMu.Lock()
 	if e, ok := s.cancelFuncs[uri]; ok && e.gen == gen {
@@ -619,8 +934,14 @@ func (s *Server) shouldAnalyze(uri string) bool {
 	return ShouldWatchPath(uri, s.config.WatchPatterns, s.config.IgnorePatterns)
 }
 
-// publishDiagnostics sends a textDocument/publishDiagnostics notification
+// publishDiagnostics sends a textDocument/publishDiagnostics notification.
+// Clients that pull diagnostics are asked to pull again instead, so they do
+// not see every finding twice.
 func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) error {
+	if s.pullDiagnostics {
+		return s.requestDiagnosticRefresh()
+	}
+
 	params := PublishDiagnosticsParams{
 		URI:         uri,
 		Diagnostics: diagnostics,
@@ -666,15 +987,6 @@ func (s *Server) sendMessage(msg jsonRPCMessage) error {
 	return s.writer.Flush()
 }
 
-// uriToPath converts a file:// URI to a filesystem path
-func uriToPath(uri string) string {
-	// Simple implementation: strip file:// prefix
-	path := strings.TrimPrefix(uri, "file://")
-	// On Windows, paths start with /C:/ which we should convert to C:/
-	// For now, just remove the leading slash on Unix paths
-	return path
-}
-
 // mustMarshal marshals v to JSON, panicking on error
 func mustMarshal(v interface{}) json.RawMessage {
 	data, err := json.Marshal(v)
@@ -7,6 +7,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -329,3 +331,203 @@ func TestServerProgressiveMultipleTiers(t *testing.T) {
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestServerDidChangeIncremental(t *testing.T) {
+	uri := "file:///test.go"
+	didOpenParams := DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: "package main\n\nfunc main() {}\n"},
+	}
+	didChangeParams := DidChangeTextDocumentParams{
+		TextDocument: VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{
+			{Range: &Range{Start: Position{Line: 2, Character: 13}, End: Position{Line: 2, Character: 13}}, Text: " eval(x) "},
+		},
+	}
+
+	input := makeJSONRPCMessage(MethodTextDocumentDidOpen, didOpenParams, 1) +
+		makeJSONRPCMessage(MethodTextDocumentDidChange, didChangeParams, 2)
+
+	var output bytes.Buffer
+	reader := bufio.NewReader(strings.NewReader(input))
+	writer := bufio.NewWriter(&output)
+
+	var fullCalls atomic.Int32
+	server := NewServerWithConfig(reader, writer, func(ctx context.Context, path, content string) ([]sarif.Result, error) {
+		fullCalls.Add(1)
+		return []sarif.Result{{
+			RuleID:     "LLM001",
+			Message:    sarif.Message{Text: "from the last save"},
+			Properties: map[string]interface{}{"gavel/tier": "comprehensive"},
+		}}, nil
+	}, ServerConfig{DebounceDuration: 50 * time.Millisecond, EditDebounce: 50 * time.Millisecond, ParallelFiles: 1})
+
+	edited := make(chan string, 1)
+	server.SetInstantAnalyze(func(ctx context.Context, path, content string) ([]sarif.Result, error) {
+		edited <- content
+		return []sarif.Result{{
+			RuleID:     "PAT001",
+			Message:    sarif.Message{Text: "eval call"},
+			Properties: map[string]interface{}{"gavel/tier": "instant"},
+		}}, nil
+	})
+
+	// Let the full analysis of didOpen finish before the edit arrives
+	server.handleMessage(context.Background())
+	time.Sleep(150 * time.Millisecond)
+	server.handleMessage(context.Background())
+
+	select {
+	case content := <-edited:
+		if content != "package main\n\nfunc main() { eval(x) }\n" {
+			t.Errorf("instant analysis saw %q", content)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected instant analysis after didChange")
+	}
+	time.Sleep(50 * time.Millisecond)
+	server.watcher.Stop()
+	server.editWatcher.Stop()
+
+	if n := fullCalls.Load(); n != 1 {
+		t.Errorf("expected only the didOpen full analysis, got %d", n)
+	}
+
+	server.resultsMu.RLock()
+	entry := server.resultsCache[uri]
+	server.resultsMu.RUnlock()
+	var ids []string
+	for _, r := range entry.results {
+		ids = append(ids, r.RuleID)
+	}
+	if len(ids) != 2 || ids[0] != "PAT001" || ids[1] != "LLM001" {
+		t.Errorf("expected fresh instant findings plus the saved LLM findings, got %v", ids)
+	}
+}
+
+func TestServerAdvertisesIncrementalSync(t *testing.T) {
+	input := makeJSONRPCMessage(MethodInitialize, InitializeParams{}, 1)
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(strings.NewReader(input)), bufio.NewWriter(&output), nil)
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	if !strings.Contains(output.String(), `"change":2`) {
+		t.Errorf("expected incremental sync capability, got %s", output.String())
+	}
+}
+
+func TestServerDidChangeCancelsAnalysis(t *testing.T) {
+	uri := "file:///test.go"
+	change := DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "package main\n\nfunc main() {}\n"}},
+	}
+	input := makeJSONRPCMessage(MethodTextDocumentDidChange, change, 0)
+
+	started := make(chan struct{})
+	analyze := func(ctx context.Context, path, content string) ([]sarif.Result, error) {
+		close(started)
+		<-ctx.Done()
+		// An analyzer that ignores cancellation must not publish either
+		return []sarif.Result{resultAt("STALE", 1)}, nil
+	}
+
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(strings.NewReader(input)), bufio.NewWriter(&output), analyze)
+	server.documents[uri] = "package main\n"
+
+	done := make(chan struct{})
+	go func() {
+		server.analyzeAndPublish(context.Background(), uri, "/test.go", "package main\n")
+		close(done)
+	}()
+	<-started
+
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("didChange: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("analysis was not cancelled by the edit")
+	}
+
+	if strings.Contains(output.String(), MethodTextDocumentPublishDiagnostics) {
+		t.Errorf("superseded analysis published diagnostics: %s", output.String())
+	}
+	if _, ok := server.contentHashes[uri]; ok {
+		t.Error("superseded analysis should not mark its content as analyzed")
+	}
+}
+
+func TestServerCancelRequest(t *testing.T) {
+	uri := "file:///test.go"
+	input := makeJSONRPCMessage(MethodWorkspaceExecuteCommand, ExecuteCommandParams{
+		Command:   CommandAnalyzeFunction,
+		Arguments: []interface{}{uri, 1, 1},
+	}, 5) + makeJSONRPCMessage(MethodCancelRequest, CancelParams{ID: 5}, 0)
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	server := NewServer(bufio.NewReader(strings.NewReader(input)), bufio.NewWriter(pw), nil)
+	server.documents[uri] = "package main\n"
+	started := make(chan struct{})
+	server.SetRangeAnalyze(func(ctx context.Context, path, content string, startLine, endLine int) ([]sarif.Result, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("executeCommand: %v", err)
+	}
+	<-started
+	if err := server.handleMessage(context.Background()); err != nil {
+		t.Fatalf("cancelRequest: %v", err)
+	}
+
+	reader := bufio.NewReader(pr)
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	length, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+	_, _ = reader.ReadString('\n')
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	if !strings.Contains(string(body), `"code":-32800`) || !strings.Contains(string(body), `"id":5`) {
+		t.Errorf("expected a RequestCancelled error for request 5, got %s", body)
+	}
+}
+
+func TestServerReanalyzeOpenDocuments(t *testing.T) {
+	uri := "file:///test.go"
+	content := "package main\n"
+
+	var calls atomic.Int32
+	analyzed := make(chan struct{}, 2)
+	analyze := func(ctx context.Context, path, content string) ([]sarif.Result, error) {
+		calls.Add(1)
+		analyzed <- struct{}{}
+		return nil, nil
+	}
+
+	var output bytes.Buffer
+	server := NewServer(bufio.NewReader(strings.NewReader("")), bufio.NewWriter(&output), analyze)
+	server.documents[uri] = content
+	server.analyzeAndPublish(context.Background(), uri, "/test.go", content)
+	<-analyzed
+
+	// Unchanged content is normally skipped; a reload analyzes it again
+	server.ReanalyzeOpenDocuments()
+	select {
+	case <-analyzed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the open document to be analyzed again")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 analyses, got %d", n)
+	}
+}
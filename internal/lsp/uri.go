@@ -0,0 +1,98 @@
+// internal/lsp/uri.go
+package lsp
+
+import (
+	"net/url"
+	"runtime"
+	"strings"
+)
+
+// isWindows selects Windows path handling: drive letters, UNC shares,
+// backslash separators and case-insensitive pattern matching
+const isWindows = runtime.GOOS == "windows"
+
+// uriToPath converts a file:// URI to a filesystem path. Other URIs, such
+// as untitled: buffers, are returned unchanged.
+func uriToPath(uri string) string {
+	return fileURIToPath(uri, isWindows)
+}
+
+// pathToURI converts a filesystem path to a file:// URI
+func pathToURI(path string) string {
+	return pathToFileURI(path, isWindows)
+}
+
+// fileURIToPath converts a file URI to a path for Unix or, when windows is
+// set, for Windows. Percent-encoding is decoded, file:///C:/x and
+// file:///c%3A/x become C:\x, and file://server/share/x becomes the UNC
+// path \\server\share\x.
+func fileURIToPath(uri string, windows bool) string {
+	if !strings.HasPrefix(strings.ToLower(uri), "file:") {
+		return uri
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		// Malformed escapes; keep the text after the scheme as it is
+		return strings.TrimPrefix(uri[len("file:"):], "//")
+	}
+
+	host, path := u.Host, u.Path
+	if host == "localhost" {
+		host = ""
+	}
+	// file://C:/x puts the drive where the host belongs
+	if isDriveLetter(host) {
+		path = "/" + host + path
+		host = ""
+	}
+
+	if !windows {
+		if host != "" {
+			return "//" + host + path
+		}
+		return path
+	}
+
+	if host != "" {
+		return `\\` + host + strings.ReplaceAll(path, "/", `\`)
+	}
+	if len(path) >= 3 && path[0] == '/' && isDriveLetter(path[1:3]) {
+		// Drive letters are upper-cased; VS Code sends them in lower case
+		path = strings.ToUpper(path[1:2]) + path[2:]
+		if len(path) == 2 {
+			path += "/"
+		}
+	}
+	return strings.ReplaceAll(path, "/", `\`)
+}
+
+// pathToFileURI converts a Unix or, when windows is set, a Windows path
+// to a file URI, percent-encoding characters such as spaces and '#'
+func pathToFileURI(path string, windows bool) string {
+	u := url.URL{Scheme: "file"}
+	if windows {
+		path = strings.ReplaceAll(path, `\`, "/")
+		if strings.HasPrefix(path, "//") {
+			// UNC path: //server/share/x
+			rest := path[2:]
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				u.Host, path = rest[:i], rest[i:]
+			} else {
+				u.Host, path = rest, "/"
+			}
+		} else if len(path) >= 2 && isDriveLetter(path[:2]) {
+			path = "/" + path
+		}
+	}
+	u.Path = path
+	return u.String()
+}
+
+// isDriveLetter reports whether s is a Windows drive such as "C:"
+func isDriveLetter(s string) bool {
+	if len(s) != 2 || s[1] != ':' {
+		return false
+	}
+	c := s[0]
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,52 @@
+// internal/lsp/uri_test.go
+package lsp
+
+import "testing"
+
+func TestFileURIToPath(t *testing.T) {
+	tests := []struct {
+		uri     string
+		windows bool
+		want    string
+	}{
+		{"file:///home/user/main.go", false, "/home/user/main.go"},
+		{"file:///home/user/my%20project/main.go", false, "/home/user/my project/main.go"},
+		{"file://localhost/etc/hosts", false, "/etc/hosts"},
+		{"file://server/share/main.go", false, "//server/share/main.go"},
+		{"untitled:Untitled-1", false, "untitled:Untitled-1"},
+		{"file:///C:/Users/dev/main.go", true, `C:\Users\dev\main.go`},
+		{"file:///c%3A/Users/dev/My%20Repo/main.go", true, `C:\Users\dev\My Repo\main.go`},
+		{"file:///d:", true, `D:\`},
+		{"file://C:/Users/dev/main.go", true, `C:\Users\dev\main.go`},
+		{"file://server/share/src/main.go", true, `\\server\share\src\main.go`},
+		{"file:///home/user/100%.go", false, "/home/user/100%.go"},
+	}
+	for _, tt := range tests {
+		if got := fileURIToPath(tt.uri, tt.windows); got != tt.want {
+			t.Errorf("fileURIToPath(%q, windows=%v) = %q, want %q", tt.uri, tt.windows, got, tt.want)
+		}
+	}
+}
+
+func TestPathToFileURI(t *testing.T) {
+	tests := []struct {
+		path    string
+		windows bool
+		want    string
+	}{
+		{"/home/user/main.go", false, "file:///home/user/main.go"},
+		{"/home/user/my project/a#b.go", false, "file:///home/user/my%20project/a%23b.go"},
+		{`C:\Users\dev\main.go`, true, "file:///C:/Users/dev/main.go"},
+		{`C:\Users\dev\My Repo\main.go`, true, "file:///C:/Users/dev/My%20Repo/main.go"},
+		{`\\server\share\src\main.go`, true, "file://server/share/src/main.go"},
+	}
+	for _, tt := range tests {
+		got := pathToFileURI(tt.path, tt.windows)
+		if got != tt.want {
+			t.Errorf("pathToFileURI(%q, windows=%v) = %q, want %q", tt.path, tt.windows, got, tt.want)
+		}
+		if back := fileURIToPath(got, tt.windows); back != tt.path {
+			t.Errorf("round trip of %q gave %q", tt.path, back)
+		}
+	}
+}
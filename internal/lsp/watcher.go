@@ -194,13 +194,11 @@ func (w *DebouncedWatcher) ShouldWatch(path string) bool {
 // ShouldWatchPath checks if a path matches watch patterns and doesn't match ignore patterns
 func ShouldWatchPath(path string, watchPatterns, ignorePatterns []string) bool {
 	// Normalize path for pattern matching
-	normalizedPath := filepath.ToSlash(path)
-	// Strip file:// prefix if present
-	normalizedPath = strings.TrimPrefix(normalizedPath, "file://")
+	normalizedPath := normalizeWatchPath(path, isWindows)
 
 	// Check ignore patterns first
 	for _, pattern := range ignorePatterns {
-		if matchGlobPattern(normalizedPath, pattern) {
+		if matchGlobPattern(normalizedPath, normalizeWatchPath(pattern, isWindows)) {
 			return false
 		}
 	}
@@ -212,7 +210,7 @@ func ShouldWatchPath(path string, watchPatterns, ignorePatterns []string) bool {
 
 	// Check if path matches any watch pattern
 	for _, pattern := range watchPatterns {
-		if matchGlobPattern(normalizedPath, pattern) {
+		if matchGlobPattern(normalizedPath, normalizeWatchPath(pattern, isWindows)) {
 			return true
 		}
 	}
@@ -220,6 +218,19 @@ func ShouldWatchPath(path string, watchPatterns, ignorePatterns []string) bool {
 	return false
 }
 
+// normalizeWatchPath puts a path, file URI or pattern in the form patterns
+// are matched in: a decoded path with forward slashes. Windows paths are
+// also lower-cased, since its file system ignores case.
+func normalizeWatchPath(path string, windows bool) string {
+	if strings.HasPrefix(strings.ToLower(path), "file:") {
+		path = fileURIToPath(path, windows)
+	}
+	if !windows {
+		return filepath.ToSlash(path)
+	}
+	return strings.ToLower(strings.ReplaceAll(path, `\`, "/"))
+}
+
 // matchGlobPattern matches a path against a glob pattern
 // Supports ** for recursive matching and * for single-level matching
 func matchGlobPattern(path, pattern string) bool {
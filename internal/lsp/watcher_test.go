@@ -135,6 +135,7 @@ func TestShouldWatchPath(t *testing.T) {
 		{"file:///project/vendor/pkg/main.go", false},
 		{"/workspace/main.go", true},
 		{"/workspace/node_modules/main.go", false},
+		{"file:///project/my%20app/vendor/main.go", false},
 	}
 
 	for _, tt := range tests {
@@ -204,3 +205,29 @@ func TestParseDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeWatchPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		windows bool
+		want    string
+	}{
+		{"file:///project/my%20app/main.go", false, "/project/my app/main.go"},
+		{"/project/main.go", false, "/project/main.go"},
+		{"file:///c%3A/Project/Node_Modules/pkg/index.js", true, "c:/project/node_modules/pkg/index.js"},
+		{`C:\Project\src\Main.GO`, true, "c:/project/src/main.go"},
+		{`\\server\share\vendor\x.go`, true, "//server/share/vendor/x.go"},
+		{`**\Vendor\**`, true, "**/vendor/**"},
+	}
+	for _, tt := range tests {
+		if got := normalizeWatchPath(tt.path, tt.windows); got != tt.want {
+			t.Errorf("normalizeWatchPath(%q, windows=%v) = %q, want %q", tt.path, tt.windows, got, tt.want)
+		}
+	}
+
+	// Windows paths match patterns regardless of case and separator
+	path := normalizeWatchPath(`C:\Project\Node_Modules\pkg\main.go`, true)
+	if !matchGlobPattern(path, normalizeWatchPath("**/node_modules/**", true)) {
+		t.Errorf("expected %q to match the node_modules ignore pattern", path)
+	}
+}
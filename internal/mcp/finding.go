@@ -0,0 +1,270 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/chris-regnier/gavel/internal/explain"
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// defaultContextLines is how many lines get_finding_context returns on
+// either side of a finding when context_lines is unset.
+const defaultContextLines = 5
+
+func getFindingContextTool() mcp.Tool {
+	return mcp.NewTool("get_finding_context",
+		mcp.WithDescription("Get what is needed to fix one finding without re-reading its whole file: the source lines around it, "+
+			"the rule's explanation, remediation and CWE/OWASP links, and any structured fix. "+
+			"Name the finding by a fingerprint from get_result (or a unique prefix of at least 6 characters) or by file:line."),
+		mcp.WithString("fingerprint",
+			mcp.Description("Fingerprint or partial fingerprint of the finding, or file:line"),
+			mcp.Required(),
+		),
+		mcp.WithString("result_id",
+			mcp.Description("Stored result the finding is in (default: the most recent result)"),
+		),
+		mcp.WithString("rule_id",
+			mcp.Description("Only match findings of this rule, to pick one of several findings on a line"),
+		),
+		mcp.WithNumber("context_lines",
+			mcp.Description("Lines of source to include before and after the finding (default 5)"),
+		),
+	)
+}
+
+// findingSource is the current source around a finding.
+type findingSource struct {
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Code      string `json:"code"`
+	// Stale is set when the file no longer holds the finding's snippet, so
+	// the lines may have moved since the analysis.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// findingRule is the guidance the rule and the finding carry.
+type findingRule struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+	Remediation string   `json:"remediation,omitempty"`
+	HelpURI     string   `json:"help_uri,omitempty"`
+	CWE         []string `json:"cwe,omitempty"`
+	OWASP       []string `json:"owasp,omitempty"`
+	Links       []string `json:"links,omitempty"`
+}
+
+type findingContext struct {
+	ResultID    string         `json:"result_id"`
+	RuleID      string         `json:"rule_id"`
+	Level       string         `json:"level"`
+	Message     string         `json:"message"`
+	File        string         `json:"file"`
+	StartLine   int            `json:"start_line"`
+	EndLine     int            `json:"end_line"`
+	Fingerprint string         `json:"fingerprint,omitempty"`
+	Suppressed  bool           `json:"suppressed,omitempty"`
+	Source      *findingSource `json:"source,omitempty"`
+	SourceError string         `json:"source_error,omitempty"`
+	Rule        findingRule    `json:"rule"`
+	Fixes       []sarif.Fix    `json:"fixes,omitempty"`
+}
+
+func (h *handlers) handleGetFindingContext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ref := request.GetString("fingerprint", "")
+	if ref == "" {
+		return mcp.NewToolResultError("fingerprint is required"), nil
+	}
+	contextLines := request.GetInt("context_lines", defaultContextLines)
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	resultID := request.GetString("result_id", "")
+	if resultID == "" {
+		ids, err := h.cfg.Store.List(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("listing results: %v", err)), nil
+		}
+		if len(ids) == 0 {
+			return mcp.NewToolResultError("no stored results; run an analysis first"), nil
+		}
+		resultID = ids[0] // List returns newest first
+	}
+	sarifLog, err := h.cfg.Store.ReadSARIF(ctx, resultID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("reading SARIF for %s: %v", resultID, err)), nil
+	}
+	r, err := explain.Find(sarifLog, ref, request.GetString("rule_id", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("result %s: %v", resultID, err)), nil
+	}
+
+	fc := findingContext{
+		ResultID:    resultID,
+		RuleID:      r.RuleID,
+		Level:       r.Level,
+		Message:     r.Message.Text,
+		Fingerprint: r.Fingerprints[sarif.ContentFingerprintV1],
+		Suppressed:  len(r.Suppressions) > 0,
+		Rule:        h.findingRule(sarifLog, r),
+		Fixes:       r.Fixes,
+	}
+	if len(r.Locations) > 0 {
+		loc := r.Locations[0].PhysicalLocation
+		fc.File = loc.ArtifactLocation.URI
+		fc.StartLine = loc.Region.StartLine
+		fc.EndLine = max(loc.Region.EndLine, loc.Region.StartLine)
+		source, err := h.findingSource(r, contextLines)
+		if err != nil {
+			fc.SourceError = err.Error()
+		}
+		fc.Source = source
+	}
+
+	return marshalSummary(map[string]interface{}{"finding": fc})
+}
+
+// findingSource reads the lines around loc from the file as it is now. When
+// the file cannot be read, it falls back to the snippet stored with the
+// finding and returns the read error alongside.
+func (h *handlers) findingSource(r sarif.Result, contextLines int) (*findingSource, error) {
+	loc := r.Locations[0].PhysicalLocation
+	region := loc.Region
+	var stored *findingSource
+	if region.Snippet != nil && region.Snippet.Text != "" {
+		stored = &findingSource{
+			StartLine: region.StartLine,
+			EndLine:   max(region.EndLine, region.StartLine),
+			Code:      region.Snippet.Text,
+		}
+	}
+
+	path := strings.TrimPrefix(loc.ArtifactLocation.URI, "file://")
+	if path == "" || region.StartLine < 1 {
+		return stored, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(h.rootDir(), filepath.FromSlash(path))
+	}
+	if err := h.validatePath(path); err != nil {
+		return stored, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stored, fmt.Errorf("reading file: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	end := max(region.EndLine, region.StartLine)
+	if region.StartLine > len(lines) {
+		return stored, fmt.Errorf("%s has %d lines; the finding starts at line %d", loc.ArtifactLocation.URI, len(lines), region.StartLine)
+	}
+	end = min(end, len(lines))
+	from := max(region.StartLine-contextLines, 1)
+	to := min(end+contextLines, len(lines))
+	source := &findingSource{
+		StartLine: from,
+		EndLine:   to,
+		Code:      strings.Join(lines[from-1:to], "\n"),
+	}
+	// Secret snippets are stored redacted, so they never match the file
+	if stored != nil && propertyString(r.Properties, "gavel/category") != "secret" {
+		current := strings.Join(lines[region.StartLine-1:end], "\n")
+		source.Stale = strings.Join(strings.Fields(current), " ") != strings.Join(strings.Fields(stored.Code), " ")
+	}
+	return source, nil
+}
+
+// findingRule gathers the guidance for r: the finding's own explanation and
+// recommendation, then the rule descriptor of the run and the loaded rule
+// for what the finding does not carry.
+func (h *handlers) findingRule(log *sarif.Log, r sarif.Result) findingRule {
+	fr := findingRule{
+		ID:          r.RuleID,
+		Explanation: propertyString(r.Properties, "gavel/explanation"),
+		Remediation: propertyString(r.Properties, "gavel/remediation"),
+		CWE:         sarif.StringsProperty(r, "gavel/cwe"),
+		OWASP:       sarif.StringsProperty(r, "gavel/owasp"),
+		Links:       append([]string(nil), sarif.StringsProperty(r, "gavel/references")...),
+	}
+	if fr.Remediation == "" {
+		fr.Remediation = propertyString(r.Properties, "gavel/recommendation")
+	}
+
+	if len(log.Runs) > 0 {
+		for _, d := range log.Runs[0].Tool.Driver.Rules {
+			if d.ID != r.RuleID {
+				continue
+			}
+			fr.Name = d.Name
+			fr.Description = d.ShortDescription.Text
+			fr.HelpURI = d.HelpURI
+			if fr.Explanation == "" && d.FullDescription != nil {
+				fr.Explanation = d.FullDescription.Text
+			}
+			break
+		}
+	}
+
+	if loaded, err := h.currentRules(); err == nil {
+		for _, rule := range loaded {
+			if rule.ID != r.RuleID {
+				continue
+			}
+			fr.Name = firstNonEmpty(fr.Name, rule.Name)
+			fr.Explanation = firstNonEmpty(fr.Explanation, rule.Explanation)
+			fr.Remediation = firstNonEmpty(fr.Remediation, rule.Remediation)
+			if len(fr.CWE) == 0 {
+				fr.CWE = rule.CWE
+			}
+			if len(fr.OWASP) == 0 {
+				fr.OWASP = rule.OWASP
+			}
+			if len(fr.Links) == 0 {
+				fr.Links = append([]string(nil), rule.References...)
+			}
+			break
+		}
+	}
+
+	seen := make(map[string]bool, len(fr.Links))
+	for _, l := range fr.Links {
+		seen[l] = true
+	}
+	addLink := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			fr.Links = append(fr.Links, url)
+		}
+	}
+	for _, id := range fr.CWE {
+		addLink(rules.CWEURL(id))
+	}
+	for _, id := range fr.OWASP {
+		addLink(rules.OWASPURL(id))
+	}
+	return fr
+}
+
+func propertyString(props map[string]interface{}, key string) string {
+	s, _ := props[key].(string)
+	return s
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
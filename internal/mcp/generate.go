@@ -0,0 +1,39 @@
+//go:build !nobaml
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	baml_client "github.com/chris-regnier/gavel/baml_client"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// generateRule calls the GenerateRule BAML function, which uses OpenRouter.
+func generateRule(ctx context.Context, description, category, languages string) (rules.Rule, error) {
+	if os.Getenv("OPENROUTER_API_KEY") == "" {
+		return rules.Rule{}, errors.New("OPENROUTER_API_KEY environment variable required for AI generation")
+	}
+	generated, err := baml_client.GenerateRule(ctx, description, category, languages)
+	if err != nil {
+		return rules.Rule{}, err
+	}
+	return rules.Rule{
+		ID:          generated.Id,
+		Name:        generated.Name,
+		Category:    rules.RuleCategory(generated.Category),
+		RawPattern:  generated.Pattern,
+		Languages:   generated.Languages,
+		Level:       generated.Level,
+		Confidence:  generated.Confidence,
+		Message:     generated.Message,
+		Explanation: generated.Explanation,
+		Remediation: generated.Remediation,
+		Source:      rules.RuleSource(generated.Source),
+		CWE:         generated.Cwe,
+		OWASP:       generated.Owasp,
+		References:  generated.References,
+	}, nil
+}
@@ -0,0 +1,15 @@
+//go:build nobaml
+
+package mcp
+
+import (
+	"context"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// generateRule fails in builds without the BAML runtime.
+func generateRule(ctx context.Context, description, category, languages string) (rules.Rule, error) {
+	return rules.Rule{}, analyzer.LLMRuntimeError()
+}
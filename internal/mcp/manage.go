@@ -0,0 +1,462 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// ruleGenerator generates a rule from a natural-language description, like
+// `gavel create rule`. Tests replace it to avoid calling a provider.
+type ruleGenerator func(ctx context.Context, description, category, languages string) (rules.Rule, error)
+
+// ruleIDPattern restricts rule IDs to names that are safe to use
+// as file names in the rules directory.
+var ruleIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// --- Tool definitions ---
+
+func listRulesTool() mcp.Tool {
+	return mcp.NewTool("list_rules",
+		mcp.WithDescription("List the regex and AST rules of the instant analysis tier: the embedded defaults merged with user and project rules."),
+		mcp.WithString("category",
+			mcp.Description("Only list rules of this category (security, reliability, maintainability, dependency, secret)"),
+		),
+	)
+}
+
+func createRuleTool() mcp.Tool {
+	return mcp.NewTool("create_rule",
+		mcp.WithDescription("Generate a regex rule from a description, as `gavel create rule` does. "+
+			"Without confirm=true it only returns the rule and where it would be written. "+
+			"Pass the previewed rule back with confirm=true to write it to the project's .gavel/rules directory and load it."),
+		mcp.WithString("description",
+			mcp.Description("What the rule should detect, e.g. \"hardcoded JWT secrets in Go code\""),
+		),
+		mcp.WithString("rule",
+			mcp.Description("Rule file YAML to write instead of generating one, e.g. a preview returned by an earlier call"),
+		),
+		mcp.WithString("category",
+			mcp.Description("Rule category: security, reliability, or maintainability (default maintainability)"),
+		),
+		mcp.WithString("languages",
+			mcp.Description("Comma-separated target languages, e.g. \"go,python\" (default any)"),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Write the rule file. Review the preview from a call without it first."),
+		),
+	)
+}
+
+func testRuleTool() mcp.Tool {
+	return mcp.NewTool("test_rule",
+		mcp.WithDescription("Run a single rule against a file or a code snippet and return its matches as SARIF results. "+
+			"Use it to check a generated rule before confirming it."),
+		mcp.WithString("rule_id",
+			mcp.Description("ID of a loaded rule to test"),
+		),
+		mcp.WithString("rule",
+			mcp.Description("Rule file YAML (a rules: list holding one rule) to test instead of a loaded rule, e.g. the preview from create_rule"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to test the rule against"),
+		),
+		mcp.WithString("code",
+			mcp.Description("Code to test the rule against instead of a file"),
+		),
+		mcp.WithString("file_name",
+			mcp.Description("File name for code, which selects the language for language-scoped rules (default snippet.txt)"),
+		),
+	)
+}
+
+func listPoliciesTool() mcp.Tool {
+	return mcp.NewTool("list_policies",
+		mcp.WithDescription("List the configured LLM analysis policies with their severity and whether they are enabled."),
+	)
+}
+
+func togglePolicyTool() mcp.Tool {
+	return mcp.NewTool("toggle_policy",
+		mcp.WithDescription("Enable or disable a policy. Without confirm=true it only reports the change; "+
+			"with confirm=true it sets the policy's enabled flag in the project's .gavel/policies.yaml, keeping its comments, and applies it to later analyses."),
+		mcp.WithString("name",
+			mcp.Description("Policy name, as listed by list_policies"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("enabled",
+			mcp.Description("Whether the policy should be enabled"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Write the change to policies.yaml"),
+		),
+	)
+}
+
+// --- Tool handlers ---
+
+func (h *handlers) handleListRules(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	loaded, err := h.currentRules()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if category := request.GetString("category", ""); category != "" {
+		loaded = rules.ByCategory(loaded, rules.RuleCategory(category))
+	}
+
+	type ruleSummary struct {
+		ID         string   `json:"id"`
+		Name       string   `json:"name"`
+		Type       string   `json:"type"`
+		Category   string   `json:"category"`
+		Level      string   `json:"level"`
+		Confidence float64  `json:"confidence"`
+		Languages  []string `json:"languages,omitempty"`
+		Message    string   `json:"message"`
+	}
+	list := make([]ruleSummary, 0, len(loaded))
+	for _, r := range loaded {
+		list = append(list, ruleSummary{
+			ID:         r.ID,
+			Name:       r.Name,
+			Type:       string(r.Type),
+			Category:   string(r.Category),
+			Level:      r.Level,
+			Confidence: r.Confidence,
+			Languages:  r.Languages,
+			Message:    r.Message,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	return marshalSummary(map[string]interface{}{
+		"rules": list,
+		"count": len(list),
+	})
+}
+
+func (h *handlers) handleCreateRule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	data := []byte(request.GetString("rule", ""))
+	if len(data) == 0 {
+		description := request.GetString("description", "")
+		if description == "" {
+			return mcp.NewToolResultError("description or rule is required"), nil
+		}
+		generated, err := h.generate(ctx, description, request.GetString("category", "maintainability"), request.GetString("languages", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data = generated
+	}
+
+	// Parsing validates the rule and compiles its pattern exactly as
+	// loading it from the rules directory would.
+	rf, err := rules.ParseRuleFile(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("rule is invalid: %v\n\n%s", err, data)), nil
+	}
+	if len(rf.Rules) != 1 {
+		return mcp.NewToolResultError(fmt.Sprintf("rule must hold exactly one rule, got %d", len(rf.Rules))), nil
+	}
+	rule := rf.Rules[0]
+	if !ruleIDPattern.MatchString(rule.ID) {
+		return mcp.NewToolResultError(fmt.Sprintf("rule ID %q is not a valid file name", rule.ID)), nil
+	}
+
+	path := filepath.Join(h.rulesDir(), rule.ID+".yaml")
+	summary := map[string]interface{}{
+		"rule_id": rule.ID,
+		"path":    path,
+		"rule":    string(data),
+	}
+	loaded, err := h.currentRules()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for _, r := range loaded {
+		if r.ID == rule.ID {
+			summary["overrides"] = r.Name
+		}
+	}
+
+	if !request.GetBool("confirm", false) {
+		summary["status"] = "preview"
+		summary["next_step"] = "Check the rule with test_rule, then call create_rule with this rule and confirm=true to write it."
+		return marshalSummary(summary)
+	}
+
+	if err := h.validatePath(path); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s already exists", path)), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating rules directory: %v", err)), nil
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("writing rule: %v", err)), nil
+	}
+	h.addRule(rule)
+
+	summary["status"] = "created"
+	return marshalSummary(summary)
+}
+
+func (h *handlers) handleTestRule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var rule rules.Rule
+	ruleID := request.GetString("rule_id", "")
+	ruleYAML := request.GetString("rule", "")
+	switch {
+	case ruleYAML != "":
+		rf, err := rules.ParseRuleFile([]byte(ruleYAML))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if len(rf.Rules) != 1 {
+			return mcp.NewToolResultError(fmt.Sprintf("rule must hold exactly one rule, got %d", len(rf.Rules))), nil
+		}
+		rule = rf.Rules[0]
+	case ruleID != "":
+		loaded, err := h.currentRules()
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		found := false
+		for _, r := range loaded {
+			if r.ID == ruleID {
+				rule, found = r, true
+				break
+			}
+		}
+		if !found {
+			return mcp.NewToolResultError(fmt.Sprintf("no rule with ID %s", ruleID)), nil
+		}
+	default:
+		return mcp.NewToolResultError("rule_id or rule is required"), nil
+	}
+
+	art := input.Artifact{Kind: input.KindFile}
+	if path := request.GetString("path", ""); path != "" {
+		if err := h.validatePath(path); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("reading file: %v", err)), nil
+		}
+		art.Path, art.Content = path, string(content)
+	} else if code := request.GetString("code", ""); code != "" {
+		art.Path, art.Content = request.GetString("file_name", "snippet.txt"), code
+	} else {
+		return mcp.NewToolResultError("path or code is required"), nil
+	}
+
+	ta := analyzer.NewTieredAnalyzer(nil,
+		analyzer.WithInstantPatterns([]rules.Rule{rule}),
+		analyzer.WithSecretScanner(nil),
+	)
+	results := ta.RunPatternMatching(ctx, art)
+
+	return marshalSummary(map[string]interface{}{
+		"rule_id": rule.ID,
+		"file":    art.Path,
+		"matches": len(results),
+		"results": results,
+	})
+}
+
+func (h *handlers) handleListPolicies(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	type policySummary struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Severity    string `json:"severity"`
+		Enabled     bool   `json:"enabled"`
+		Provider    string `json:"provider,omitempty"`
+		Model       string `json:"model,omitempty"`
+	}
+	cfg := h.currentConfig()
+	list := make([]policySummary, 0, len(cfg.Policies))
+	for name, p := range cfg.Policies {
+		list = append(list, policySummary{
+			Name:        name,
+			Description: p.Description,
+			Severity:    p.Severity,
+			Enabled:     p.Enabled,
+			Provider:    p.Provider,
+			Model:       p.Model,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return marshalSummary(map[string]interface{}{
+		"policies": list,
+		"count":    len(list),
+	})
+}
+
+func (h *handlers) handleTogglePolicy(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("name", "")
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	enabled, err := request.RequireBool("enabled")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cfg := h.currentConfig()
+	policy, ok := cfg.Policies[name]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("unknown policy %s (configured: %s)", name, policyNames(cfg))), nil
+	}
+
+	path := h.projectConfig()
+	summary := map[string]interface{}{
+		"policy":  name,
+		"enabled": enabled,
+		"was":     policy.Enabled,
+		"path":    path,
+	}
+	if !request.GetBool("confirm", false) {
+		summary["status"] = "preview"
+		summary["next_step"] = "Call toggle_policy again with confirm=true to write the change."
+		return marshalSummary(summary)
+	}
+
+	if err := h.validatePath(path); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("creating config directory: %v", err)), nil
+	}
+	if err := config.SetPolicyEnabled(path, name, enabled); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	h.setPolicyEnabled(name, enabled)
+
+	summary["status"] = "updated"
+	return marshalSummary(summary)
+}
+
+// --- Helpers ---
+
+// generate asks the rule generator for a rule and returns it as rule file
+// YAML, in the shape `gavel create rule` prints.
+func (h *handlers) generate(ctx context.Context, description, category, languages string) ([]byte, error) {
+	if languages == "any" {
+		languages = ""
+	}
+	gen := h.generateRule
+	if gen == nil {
+		gen = generateRule
+	}
+	generated, err := gen(ctx, description, category, languages)
+	if err != nil {
+		return nil, fmt.Errorf("generating rule: %w", err)
+	}
+	data, err := yaml.Marshal(rules.RuleFile{Rules: []rules.Rule{generated}})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling rule: %w", err)
+	}
+	return data, nil
+}
+
+// currentConfig returns the server config. toggle_policy replaces it rather
+// than editing it in place, so the returned config is safe to read.
+func (h *handlers) currentConfig() *config.Config {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cfg.Config
+}
+
+// currentRules returns the loaded rules, or the embedded defaults when the
+// server was started without any.
+func (h *handlers) currentRules() ([]rules.Rule, error) {
+	if loaded := h.loadedRules(); loaded != nil {
+		return loaded, nil
+	}
+	defaults, err := rules.DefaultRules()
+	if err != nil {
+		return nil, fmt.Errorf("loading default rules: %w", err)
+	}
+	return defaults, nil
+}
+
+// loadedRules returns the rules analyses run with (nil = embedded defaults).
+func (h *handlers) loadedRules() []rules.Rule {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cfg.Rules
+}
+
+// addRule makes a newly written rule part of later analyses, replacing a
+// loaded rule with the same ID as the rules directory would on restart.
+func (h *handlers) addRule(rule rules.Rule) {
+	loaded, err := h.currentRules()
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	next := make([]rules.Rule, 0, len(loaded)+1)
+	for _, r := range loaded {
+		if r.ID != rule.ID {
+			next = append(next, r)
+		}
+	}
+	h.cfg.Rules = append(next, rule)
+}
+
+// setPolicyEnabled applies a toggle to the config later analyses use.
+func (h *handlers) setPolicyEnabled(name string, enabled bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cfg := *h.cfg.Config
+	cfg.Policies = make(map[string]config.Policy, len(h.cfg.Config.Policies))
+	for n, p := range h.cfg.Config.Policies {
+		cfg.Policies[n] = p
+	}
+	p := cfg.Policies[name]
+	p.Enabled = enabled
+	cfg.Policies[name] = p
+	h.cfg.Config = &cfg
+}
+
+// projectConfig returns the policies.yaml toggle_policy edits.
+func (h *handlers) projectConfig() string {
+	if h.cfg.ProjectConfig != "" {
+		return h.cfg.ProjectConfig
+	}
+	return filepath.Join(h.rootDir(), ".gavel", "policies.yaml")
+}
+
+// rulesDir returns the directory create_rule writes rules to.
+func (h *handlers) rulesDir() string {
+	if h.cfg.RulesDir != "" {
+		return h.cfg.RulesDir
+	}
+	return filepath.Join(filepath.Dir(h.projectConfig()), "rules")
+}
+
+// policyNames lists the configured policies for error messages.
+func policyNames(cfg *config.Config) string {
+	names := make([]string, 0, len(cfg.Policies))
+	for name := range cfg.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -10,15 +10,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/chris-regnier/gavel/internal/analyzer"
+	"github.com/chris-regnier/gavel/internal/cache"
 	"github.com/chris-regnier/gavel/internal/config"
 	"github.com/chris-regnier/gavel/internal/evaluator"
 	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/metrics"
 	"github.com/chris-regnier/gavel/internal/rules"
 	"github.com/chris-regnier/gavel/internal/service"
 	"github.com/chris-regnier/gavel/internal/store"
@@ -27,6 +30,10 @@ import (
 
 const version = "0.2.0"
 
+// defaultMaxConcurrent bounds concurrent analyze_* calls when
+// ServerConfig.MaxConcurrent is unset.
+const defaultMaxConcurrent = 4
+
 // ServerConfig holds configuration for the MCP server.
 type ServerConfig struct {
 	Config  *config.Config
@@ -34,44 +41,98 @@ type ServerConfig struct {
 	RegoDir string       // Directory for custom Rego policies (empty = default embedded policy)
 	RootDir string       // Root directory for path validation (empty = cwd)
 	Rules   []rules.Rule // Loaded regex/AST rules for the instant analysis tier (nil = use embedded defaults)
+	Version string       // Gavel build version reported by server_info (empty = "dev")
+
+	// ProjectConfig is the policies.yaml toggle_policy edits and RulesDir
+	// the directory create_rule writes to (empty = .gavel/policies.yaml
+	// under RootDir and the rules/ directory beside it).
+	ProjectConfig string
+	RulesDir      string
+
+	// MaxConcurrent bounds how many analyze_* calls run at once so
+	// concurrent agents cannot overwhelm the shared provider (<= 0 = 4).
+	MaxConcurrent int
+	// QueueTimeout limits how long a call over the limit waits for a slot
+	// before it is rejected (0 = wait until the request is cancelled).
+	QueueTimeout time.Duration
+	// RejectWhenBusy rejects calls over the limit immediately instead of
+	// queueing them.
+	RejectWhenBusy bool
+
+	// Metrics, when set, records every analysis, e.g. for a Prometheus
+	// endpoint served alongside the MCP server.
+	Metrics *metrics.Collector
+	// Pricing, when set, prices every LLM call and records it to Metrics
+	// under the caller's tenant, for per-tenant quotas over HTTP.
+	Pricing metrics.PriceTable
+
+	// Subscriptions, when set, lets clients subscribe to gavel://results
+	// and gavel://results/{id} and be notified as results are written. The
+	// server must then be served through Subscriptions.Stdio or
+	// Subscriptions.Handler.
+	Subscriptions *Subscriptions
+
+	// ResponseCache, when set, answers repeated LLM prompts from cached
+	// responses, such as a cache.DiskCache shared with analyze and lsp.
+	ResponseCache cache.AnalysisCache
 }
 
 // NewMCPServer creates a configured MCP server with all Gavel tools, resources, and prompts.
 func NewMCPServer(cfg ServerConfig) *server.MCPServer {
+	subs := cfg.Subscriptions
+	hooks := &server.Hooks{}
+	if subs != nil {
+		cfg.Store = notifyingStore{Store: cfg.Store, subs: subs}
+		hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+			subs.drop(session.SessionID())
+		})
+	}
 	s := server.NewMCPServer(
 		"gavel",
 		version,
 		server.WithToolCapabilities(true),
-		server.WithResourceCapabilities(true, false),
+		server.WithResourceCapabilities(subs != nil, false),
 		server.WithPromptCapabilities(true),
+		server.WithHooks(hooks),
 	)
+	if subs != nil {
+		subs.mu.Lock()
+		subs.server = s
+		subs.mu.Unlock()
+	}
 
 	// Build the BAML client once at startup (matching previous behavior)
 	// and feed it to the AnalyzeService via a factory closure so the same
-	// client serves every analyze_* tool call.
-	client := analyzer.NewBAMLLiveClient(cfg.Config.Provider)
+	// client serves every analyze_* tool call. Other providers, such as
+	// the fast tier's, get clients of their own.
+	newClient := func(pc config.ProviderConfig) analyzer.BAMLClient {
+		var c analyzer.BAMLClient = analyzer.NewProviderClient(pc)
+		if cfg.ResponseCache != nil {
+			c = analyzer.NewCachingClient(c, cfg.ResponseCache, pc)
+		}
+		return c
+	}
+	client := newClient(cfg.Config.Provider)
 	analyzeSvc := service.NewAnalyzeService(cfg.Store).WithClientFactory(
-		func(_ config.ProviderConfig) analyzer.BAMLClient { return client },
-	)
-
-	h := &handlers{
-		cfg:        cfg,
-		analyzeSvc: analyzeSvc,
+		func(pc config.ProviderConfig) analyzer.BAMLClient {
+			if pc == cfg.Config.Provider {
+				return client
+			}
+			return newClient(pc)
+		},
+	).WithMetrics(cfg.Metrics)
+	if cfg.Pricing != nil {
+		analyzeSvc = analyzeSvc.WithPricing(cfg.Pricing)
 	}
 
+	h := newHandlers(cfg, analyzeSvc)
+
 	// Register tools
-	s.AddTool(analyzeFileTool(), h.handleAnalyzeFile)
-	s.AddTool(analyzeDirectoryTool(), h.handleAnalyzeDirectory)
-	s.AddTool(judgeTool(), h.handleJudge)
-	s.AddTool(listResultsTool(), h.handleListResults)
-	s.AddTool(getResultTool(), h.handleGetResult)
-	s.AddTool(suppressFindingTool(), h.handleSuppressFinding)
-	s.AddTool(listSuppressionsTool(), h.handleListSuppressions)
-	s.AddTool(unsuppressFindingTool(), h.handleUnsuppressFinding)
-	s.AddTool(analyzeDiffTool(), h.handleAnalyzeDiff)
+	s.AddTools(h.tools()...)
 
 	// Register resources
 	s.AddResource(policiesResource(), h.handlePoliciesResource)
+	s.AddResource(resultsResource(), h.handleResultsResource)
 	s.AddResourceTemplate(resultTemplate(), h.handleResultTemplate)
 
 	// Register prompts
@@ -88,6 +149,78 @@ func NewMCPServer(cfg ServerConfig) *server.MCPServer {
 type handlers struct {
 	cfg        ServerConfig
 	analyzeSvc *service.AnalyzeService
+	slots      chan struct{} // analysis concurrency semaphore
+
+	generateRule ruleGenerator // nil = the GenerateRule BAML function
+
+	mu sync.RWMutex // guards cfg.Config and cfg.Rules, replaced by create_rule and toggle_policy
+}
+
+func newHandlers(cfg ServerConfig, analyzeSvc *service.AnalyzeService) *handlers {
+	maxConc := cfg.MaxConcurrent
+	if maxConc <= 0 {
+		maxConc = defaultMaxConcurrent
+	}
+	return &handlers{
+		cfg:        cfg,
+		analyzeSvc: analyzeSvc,
+		slots:      make(chan struct{}, maxConc),
+	}
+}
+
+// acquireSlot reserves one of the analysis slots, queueing or rejecting per
+// the server config. On success it returns a release func; otherwise it
+// returns a tool error explaining why the call was not run.
+func (h *handlers) acquireSlot(ctx context.Context) (func(), *mcp.CallToolResult) {
+	release := func() { <-h.slots }
+	select {
+	case h.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	busy := fmt.Sprintf("server busy: %d analyses already running; retry later", cap(h.slots))
+	if h.cfg.RejectWhenBusy {
+		return nil, mcp.NewToolResultError(busy)
+	}
+
+	var timeout <-chan time.Time
+	if h.cfg.QueueTimeout > 0 {
+		timer := time.NewTimer(h.cfg.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case h.slots <- struct{}{}:
+		return release, nil
+	case <-timeout:
+		return nil, mcp.NewToolResultError(fmt.Sprintf("%s (waited %s)", busy, h.cfg.QueueTimeout))
+	case <-ctx.Done():
+		return nil, mcp.NewToolResultError(fmt.Sprintf("cancelled while waiting for an analysis slot: %v", ctx.Err()))
+	}
+}
+
+// tools returns every tool the server registers, paired with its handler.
+// server_info reports this same list, so it always matches registration.
+func (h *handlers) tools() []server.ServerTool {
+	return []server.ServerTool{
+		{Tool: analyzeFileTool(), Handler: h.handleAnalyzeFile},
+		{Tool: analyzeDirectoryTool(), Handler: h.handleAnalyzeDirectory},
+		{Tool: judgeTool(), Handler: h.handleJudge},
+		{Tool: listResultsTool(), Handler: h.handleListResults},
+		{Tool: getResultTool(), Handler: h.handleGetResult},
+		{Tool: getFindingContextTool(), Handler: h.handleGetFindingContext},
+		{Tool: suppressFindingTool(), Handler: h.handleSuppressFinding},
+		{Tool: listSuppressionsTool(), Handler: h.handleListSuppressions},
+		{Tool: unsuppressFindingTool(), Handler: h.handleUnsuppressFinding},
+		{Tool: analyzeDiffTool(), Handler: h.handleAnalyzeDiff},
+		{Tool: listRulesTool(), Handler: h.handleListRules},
+		{Tool: createRuleTool(), Handler: h.handleCreateRule},
+		{Tool: testRuleTool(), Handler: h.handleTestRule},
+		{Tool: listPoliciesTool(), Handler: h.handleListPolicies},
+		{Tool: togglePolicyTool(), Handler: h.handleTogglePolicy},
+		{Tool: serverInfoTool(), Handler: h.handleServerInfo},
+	}
 }
 
 // --- Tool definitions ---
@@ -211,6 +344,12 @@ func analyzeDiffTool() mcp.Tool {
 	)
 }
 
+func serverInfoTool() mcp.Tool {
+	return mcp.NewTool("server_info",
+		mcp.WithDescription("Describe this Gavel server: its version, the tools it registers, and which optional features it supports. Call this first to adapt to older or newer servers."),
+	)
+}
+
 // --- Resource definitions ---
 
 func policiesResource() mcp.Resource {
@@ -221,6 +360,15 @@ func policiesResource() mcp.Resource {
 	)
 }
 
+func resultsResource() mcp.Resource {
+	return mcp.NewResource(
+		resultsURI,
+		"Analysis Results",
+		mcp.WithResourceDescription("Stored analysis results, newest first. Subscribe to it to be notified when an analysis completes."),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
 func resultTemplate() mcp.ResourceTemplate {
 	return mcp.NewResourceTemplate(
 		"gavel://results/{id}",
@@ -284,6 +432,12 @@ func (h *handlers) handleAnalyzeFile(ctx context.Context, request mcp.CallToolRe
 		{Path: path, Content: string(content), Kind: input.KindFile},
 	})
 
+	release, busy := h.acquireSlot(ctx)
+	if busy != nil {
+		return busy, nil
+	}
+	defer release()
+
 	result, err := h.analyzeSvc.Analyze(ctx, req)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("analysis failed: %v", err)), nil
@@ -327,6 +481,12 @@ func (h *handlers) handleAnalyzeDirectory(ctx context.Context, request mcp.CallT
 
 	req := h.analyzeRequest(persona, baseline, artifacts)
 
+	release, busy := h.acquireSlot(ctx)
+	if busy != nil {
+		return busy, nil
+	}
+	defer release()
+
 	result, err := h.analyzeSvc.Analyze(ctx, req)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("analysis failed: %v", err)), nil
@@ -373,7 +533,15 @@ func (h *handlers) handleJudge(ctx context.Context, request mcp.CallToolRequest)
 	suppression.Apply(supps, sarifLog)
 
 	// Evaluate with Rego
-	eval, err := evaluator.NewEvaluator(ctx, h.cfg.RegoDir)
+	evalOpts := []evaluator.EvaluatorOption{evaluator.WithDecisionTrace()}
+	cfg := h.currentConfig()
+	if cfg != nil && len(cfg.Gate.Categories) > 0 {
+		evalOpts = append(evalOpts, evaluator.WithCategoryThresholds(cfg.Gate.Categories))
+	}
+	if cfg != nil {
+		evalOpts = append(evalOpts, evaluator.WithVerdictPolicy(cfg.Verdict))
+	}
+	eval, err := evaluator.NewEvaluator(ctx, h.cfg.RegoDir, evalOpts...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("creating evaluator: %v", err)), nil
 	}
@@ -393,6 +561,7 @@ func (h *handlers) handleJudge(ctx context.Context, request mcp.CallToolRequest)
 		"decision":  verdict.Decision,
 		"reason":    verdict.Reason,
 		"relevant":  len(verdict.RelevantFindings),
+		"trace":     verdict.Trace,
 	}, "", "  ")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("marshaling verdict: %v", err)), nil
@@ -493,11 +662,17 @@ func (h *handlers) handleAnalyzeDiff(ctx context.Context, request mcp.CallToolRe
 		ChangedStart:   changedStart,
 		ChangedEnd:     changedEnd,
 		Config:         cfg,
-		Rules:          h.cfg.Rules,
+		Rules:          h.loadedRules(),
 		BaselineID:     baseline,
 		SuppressionDir: h.rootDir(),
 	}
 
+	release, busy := h.acquireSlot(ctx)
+	if busy != nil {
+		return busy, nil
+	}
+	defer release()
+
 	result, err := h.analyzeSvc.AnalyzeScoped(ctx, scopedReq)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("analysis failed: %v", err)), nil
@@ -517,6 +692,78 @@ func (h *handlers) handleAnalyzeDiff(ctx context.Context, request mcp.CallToolRe
 	return marshalSummary(summary)
 }
 
+// --- Capability handshake ---
+
+// serverInfo is the server_info response. Features are derived from the
+// registered tools and the server's configuration, so the flags cannot
+// drift from what the server actually exposes.
+type serverInfo struct {
+	Name                  string          `json:"name"`
+	GavelVersion          string          `json:"gavel_version"`
+	ServerVersion         string          `json:"server_version"`
+	Tools                 []string        `json:"tools"`
+	Features              map[string]bool `json:"features"`
+	MaxConcurrentAnalyses int             `json:"max_concurrent_analyses"`
+}
+
+func (h *handlers) handleServerInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	registered := make(map[string]bool)
+	var names []string
+	var baselines bool
+	for _, t := range h.tools() {
+		names = append(names, t.Tool.Name)
+		registered[t.Tool.Name] = true
+		if _, ok := t.Tool.InputSchema.Properties["baseline"]; ok {
+			baselines = true
+		}
+	}
+
+	gavelVersion := h.cfg.Version
+	if gavelVersion == "" {
+		gavelVersion = "dev"
+	}
+
+	info := serverInfo{
+		Name:          "gavel",
+		GavelVersion:  gavelVersion,
+		ServerVersion: version,
+		Tools:         names,
+		Features: map[string]bool{
+			"diff_analysis":       registered["analyze_diff"],
+			"baseline_comparison": baselines,
+			"suppressions":        registered["suppress_finding"],
+			"rules_listing":       registered["list_rules"],
+			"structured_fixes":    h.producesFixes(),
+		},
+		MaxConcurrentAnalyses: cap(h.slots),
+	}
+
+	out, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("marshaling server info: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// producesFixes reports whether findings can carry SARIF fixes (see `gavel
+// fix`): LLM findings can whenever a provider is configured, and instant
+// findings only from rules that define a fix.
+func (h *handlers) producesFixes() bool {
+	if h.currentConfig().Provider.Name != "" {
+		return true
+	}
+	loaded, err := h.currentRules()
+	if err != nil {
+		return false
+	}
+	for _, r := range loaded {
+		if r.Fix != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // extractChangedLines parses a unified diff and returns the overall range of changed lines.
 func extractChangedLines(diff string) (int, int) {
 	var minStart, maxEnd int
@@ -723,7 +970,7 @@ func (h *handlers) handleUnsuppressFinding(ctx context.Context, request mcp.Call
 
 func (h *handlers) handlePoliciesResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 	policies := make(map[string]interface{})
-	for name, p := range h.cfg.Config.Policies {
+	for name, p := range h.currentConfig().Policies {
 		policies[name] = map[string]interface{}{
 			"description": p.Description,
 			"severity":    p.Severity,
@@ -746,6 +993,34 @@ func (h *handlers) handlePoliciesResource(ctx context.Context, request mcp.ReadR
 	}, nil
 }
 
+func (h *handlers) handleResultsResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	ids, err := h.cfg.Store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing results: %w", err)
+	}
+	type resultEntry struct {
+		ID  string `json:"id"`
+		URI string `json:"uri"`
+	}
+	entries := make([]resultEntry, len(ids))
+	for i, id := range ids {
+		entries[i] = resultEntry{ID: id, URI: resultsURI + "/" + id}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{"results": entries}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling results: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      resultsURI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
 func (h *handlers) handleResultTemplate(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 	uri := request.Params.URI
 	// Extract ID from gavel://results/{id}
@@ -823,7 +1098,7 @@ func (h *handlers) buildAnalysisPrompt(request mcp.GetPromptRequest, persona, in
 // hardcoded "code-reviewer" default. Mirrors the behavior of every
 // analyze_* MCP handler.
 func (h *handlers) resolvePersona(request mcp.CallToolRequest) string {
-	persona := request.GetString("persona", h.cfg.Config.Persona)
+	persona := request.GetString("persona", h.currentConfig().Persona)
 	if persona == "" {
 		persona = "code-reviewer"
 	}
@@ -833,7 +1108,7 @@ func (h *handlers) resolvePersona(request mcp.CallToolRequest) string {
 // configWithPersona returns a copy of the server config with Persona
 // overridden so the AnalyzeService picks up the per-tool-call selection.
 func (h *handlers) configWithPersona(persona string) config.Config {
-	cfg := *h.cfg.Config
+	cfg := *h.currentConfig()
 	cfg.Persona = persona
 	return cfg
 }
@@ -845,7 +1120,7 @@ func (h *handlers) analyzeRequest(persona, baseline string, artifacts []input.Ar
 	return service.AnalyzeRequest{
 		Artifacts:      artifacts,
 		Config:         h.configWithPersona(persona),
-		Rules:          h.cfg.Rules,
+		Rules:          h.loadedRules(),
 		BaselineID:     baseline,
 		SuppressionDir: h.rootDir(),
 	}
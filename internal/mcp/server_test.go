@@ -1,16 +1,23 @@
 package mcp
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	mcpgo "github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/chris-regnier/gavel/internal/analyzer"
 	"github.com/chris-regnier/gavel/internal/config"
@@ -19,6 +26,7 @@ import (
 	"github.com/chris-regnier/gavel/internal/service"
 	"github.com/chris-regnier/gavel/internal/store"
 	"github.com/chris-regnier/gavel/internal/suppression"
+	"github.com/chris-regnier/gavel/internal/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -107,30 +115,20 @@ func newTestHandlers(t *testing.T, cfg *config.Config, fs store.Store, rootDir s
 	analyzeSvc := service.NewAnalyzeService(fs).WithClientFactory(
 		func(_ config.ProviderConfig) analyzer.BAMLClient { return client },
 	)
-	return &handlers{
-		cfg: ServerConfig{
-			Config:  cfg,
-			Store:   fs,
-			RootDir: rootDir,
-			Rules:   o.rules,
-		},
-		analyzeSvc: analyzeSvc,
-	}
+	return newHandlers(ServerConfig{
+		Config:  cfg,
+		Store:   fs,
+		RootDir: rootDir,
+		Rules:   o.rules,
+	}, analyzeSvc)
 }
 
 // registerAll adds every tool/resource/prompt to a test server,
 // mirroring the registration order in NewMCPServer.
 func registerAll(ts *mcptest.Server, h *handlers) {
-	ts.AddTool(analyzeFileTool(), h.handleAnalyzeFile)
-	ts.AddTool(analyzeDirectoryTool(), h.handleAnalyzeDirectory)
-	ts.AddTool(judgeTool(), h.handleJudge)
-	ts.AddTool(listResultsTool(), h.handleListResults)
-	ts.AddTool(getResultTool(), h.handleGetResult)
-	ts.AddTool(suppressFindingTool(), h.handleSuppressFinding)
-	ts.AddTool(listSuppressionsTool(), h.handleListSuppressions)
-	ts.AddTool(unsuppressFindingTool(), h.handleUnsuppressFinding)
-	ts.AddTool(analyzeDiffTool(), h.handleAnalyzeDiff)
+	ts.AddTools(h.tools()...)
 	ts.AddResource(policiesResource(), h.handlePoliciesResource)
+	ts.AddResource(resultsResource(), h.handleResultsResource)
 	ts.AddResourceTemplate(resultTemplate(), h.handleResultTemplate)
 	ts.AddPrompt(codeReviewPrompt(), h.handleCodeReviewPrompt)
 	ts.AddPrompt(securityAuditPrompt(), h.handleSecurityAuditPrompt)
@@ -230,12 +228,18 @@ func TestListResources(t *testing.T) {
 		t.Fatalf("ListResources: %v", err)
 	}
 
-	if len(result.Resources) != 1 {
-		t.Fatalf("expected 1 resource, got %d", len(result.Resources))
+	if len(result.Resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(result.Resources))
 	}
 
-	if result.Resources[0].URI != "gavel://policies" {
-		t.Errorf("expected resource URI gavel://policies, got %s", result.Resources[0].URI)
+	uris := map[string]bool{}
+	for _, r := range result.Resources {
+		uris[r.URI] = true
+	}
+	for _, uri := range []string{"gavel://policies", resultsURI} {
+		if !uris[uri] {
+			t.Errorf("missing resource: %s", uri)
+		}
 	}
 }
 
@@ -1248,3 +1252,489 @@ func TestAnalyzeFileTool_BaselinePropagates(t *testing.T) {
 	require.Len(t, stored.Runs, 1)
 	assert.Equal(t, "prev-run-guid", stored.Runs[0].BaselineGuid, "stored run should chain back to baseline guid")
 }
+
+// blockingBAMLClient counts concurrent AnalyzeCode calls and holds each one
+// until release is closed.
+type blockingBAMLClient struct {
+	release  chan struct{}
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	calls    int
+}
+
+func (c *blockingBAMLClient) AnalyzeCode(ctx context.Context, _, _, _, _ string) ([]analyzer.Finding, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.calls++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return nil, nil
+}
+
+func (c *blockingBAMLClient) stats() (inFlight, peak, calls int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inFlight, c.peak, c.calls
+}
+
+// newLimitedHandlers wires handlers around client with the given server
+// concurrency settings and returns them with a file to analyze.
+func newLimitedHandlers(t *testing.T, client analyzer.BAMLClient, serverCfg ServerConfig) (*handlers, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "main.go")
+	require.NoError(t, os.WriteFile(testFile, []byte("package main\n"), 0644))
+
+	fs := testStore(t)
+	serverCfg.Config = testConfig()
+	serverCfg.Store = fs
+	serverCfg.RootDir = tmpDir
+	analyzeSvc := service.NewAnalyzeService(fs).WithClientFactory(
+		func(_ config.ProviderConfig) analyzer.BAMLClient { return client },
+	)
+	return newHandlers(serverCfg, analyzeSvc), testFile
+}
+
+func analyzeFileRequest(path string) mcpgo.CallToolRequest {
+	req := mcpgo.CallToolRequest{}
+	req.Params.Name = "analyze_file"
+	req.Params.Arguments = map[string]any{"path": path}
+	return req
+}
+
+func TestAnalyzeConcurrency_BoundedAndQueued(t *testing.T) {
+	client := &blockingBAMLClient{release: make(chan struct{})}
+	h, testFile := newLimitedHandlers(t, client, ServerConfig{MaxConcurrent: 2})
+
+	const requests = 6
+	results := make(chan *mcpgo.CallToolResult, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			result, err := h.handleAnalyzeFile(context.Background(), analyzeFileRequest(testFile))
+			assert.NoError(t, err)
+			results <- result
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		inFlight, _, _ := client.stats()
+		return inFlight == 2
+	}, 5*time.Second, 5*time.Millisecond, "expected two analyses to start")
+
+	// Give queued requests a chance to (incorrectly) reach the provider.
+	time.Sleep(50 * time.Millisecond)
+	inFlight, _, calls := client.stats()
+	assert.Equal(t, 2, inFlight, "only MaxConcurrent analyses may reach the provider")
+	assert.Equal(t, 2, calls, "excess requests should queue, not call the provider")
+
+	close(client.release)
+	for i := 0; i < requests; i++ {
+		select {
+		case result := <-results:
+			assert.False(t, result.IsError, "queued request should succeed: %+v", result)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for queued analyses")
+		}
+	}
+
+	_, peak, calls := client.stats()
+	assert.Equal(t, 2, peak)
+	assert.Equal(t, requests, calls)
+}
+
+func TestAnalyzeConcurrency_RejectWhenBusy(t *testing.T) {
+	client := &blockingBAMLClient{release: make(chan struct{})}
+	h, testFile := newLimitedHandlers(t, client, ServerConfig{MaxConcurrent: 1, RejectWhenBusy: true})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = h.handleAnalyzeFile(context.Background(), analyzeFileRequest(testFile))
+	}()
+	require.Eventually(t, func() bool {
+		inFlight, _, _ := client.stats()
+		return inFlight == 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	result, err := h.handleAnalyzeFile(context.Background(), analyzeFileRequest(testFile))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcpgo.TextContent).Text, "server busy: 1 analyses already running")
+
+	close(client.release)
+	<-done
+}
+
+func TestAnalyzeConcurrency_QueueTimeout(t *testing.T) {
+	client := &blockingBAMLClient{release: make(chan struct{})}
+	h, testFile := newLimitedHandlers(t, client, ServerConfig{MaxConcurrent: 1, QueueTimeout: 20 * time.Millisecond})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = h.handleAnalyzeFile(context.Background(), analyzeFileRequest(testFile))
+	}()
+	require.Eventually(t, func() bool {
+		inFlight, _, _ := client.stats()
+		return inFlight == 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	result, err := h.handleAnalyzeFile(context.Background(), analyzeFileRequest(testFile))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcpgo.TextContent).Text, "waited 20ms")
+
+	close(client.release)
+	<-done
+}
+
+func TestServerInfoTool(t *testing.T) {
+	fs := testStore(t)
+	h := newHandlers(ServerConfig{Config: testConfig(), Store: fs, Version: "1.4.2", MaxConcurrent: 3}, service.NewAnalyzeService(fs))
+
+	ts := mcptest.NewUnstartedServer(t)
+	registerAll(ts, h)
+	require.NoError(t, ts.Start(context.Background()))
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	result, err := callTool(ctx, ts.Client(), "server_info", nil)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "server_info failed: %+v", result)
+
+	var info serverInfo
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcpgo.TextContent).Text), &info))
+	assert.Equal(t, "gavel", info.Name)
+	assert.Equal(t, "1.4.2", info.GavelVersion)
+	assert.Equal(t, version, info.ServerVersion)
+	assert.Equal(t, 3, info.MaxConcurrentAnalyses)
+	assert.True(t, info.Features["diff_analysis"])
+	assert.True(t, info.Features["structured_fixes"])
+	assert.True(t, info.Features["rules_listing"])
+
+	listed, err := ts.Client().ListTools(ctx, mcpgo.ListToolsRequest{})
+	require.NoError(t, err)
+	var registered []string
+	for _, tool := range listed.Tools {
+		registered = append(registered, tool.Name)
+	}
+	assert.ElementsMatch(t, registered, info.Tools, "server_info must list exactly the registered tools")
+	assert.Contains(t, info.Tools, "server_info")
+}
+
+func TestServerInfoTool_FeaturesFollowConfiguration(t *testing.T) {
+	features := func(cfg *config.Config, loaded []rules.Rule) map[string]bool {
+		t.Helper()
+		h := newHandlers(ServerConfig{Config: cfg, Store: testStore(t), Rules: loaded}, nil)
+		result, err := h.handleServerInfo(context.Background(), mcpgo.CallToolRequest{})
+		require.NoError(t, err)
+		var info serverInfo
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcpgo.TextContent).Text), &info))
+		return info.Features
+	}
+
+	noProvider := testConfig()
+	noProvider.Provider = config.ProviderConfig{}
+	plain := []rules.Rule{{ID: "R1", Type: rules.RuleTypeRegex}}
+	assert.False(t, features(noProvider, plain)["structured_fixes"],
+		"without a provider or rule fixes no finding can carry a fix")
+
+	withFix := []rules.Rule{{ID: "R1", Type: rules.RuleTypeRegex, Fix: &rules.RuleFix{Delete: true}}}
+	assert.True(t, features(noProvider, withFix)["structured_fixes"])
+	assert.True(t, features(testConfig(), plain)["structured_fixes"])
+	assert.True(t, features(testConfig(), plain)["baseline_comparison"])
+}
+
+func TestNewMCPServer_ServerInfoMatchesRegistration(t *testing.T) {
+	fs := testStore(t)
+	s := NewMCPServer(ServerConfig{Config: testConfig(), Store: fs})
+
+	var registered []string
+	for name := range s.ListTools() {
+		registered = append(registered, name)
+	}
+	var advertised []string
+	for _, tool := range newHandlers(ServerConfig{}, nil).tools() {
+		advertised = append(advertised, tool.Tool.Name)
+	}
+	assert.ElementsMatch(t, registered, advertised)
+}
+
+func toolRequest(name string, args map[string]any) mcpgo.CallToolRequest {
+	req := mcpgo.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	return req
+}
+
+func toolJSON(t *testing.T, result *mcpgo.CallToolResult) map[string]any {
+	t.Helper()
+	require.False(t, result.IsError, "tool failed: %+v", result)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcpgo.TextContent).Text), &out))
+	return out
+}
+
+func TestListRulesTool(t *testing.T) {
+	h := newHandlers(ServerConfig{Config: testConfig()}, nil)
+	ctx := context.Background()
+
+	all, err := h.handleListRules(ctx, toolRequest("list_rules", nil))
+	require.NoError(t, err)
+	defaults, err := rules.DefaultRules()
+	require.NoError(t, err)
+	assert.EqualValues(t, len(defaults), toolJSON(t, all)["count"])
+
+	security, err := h.handleListRules(ctx, toolRequest("list_rules", map[string]any{"category": "security"}))
+	require.NoError(t, err)
+	for _, r := range toolJSON(t, security)["rules"].([]any) {
+		assert.Equal(t, "security", r.(map[string]any)["category"])
+	}
+}
+
+func TestCreateRuleTool_PreviewThenConfirm(t *testing.T) {
+	root := t.TempDir()
+	h := newHandlers(ServerConfig{Config: testConfig(), RootDir: root}, nil)
+	h.generateRule = func(_ context.Context, description, category, languages string) (rules.Rule, error) {
+		assert.Equal(t, "security", category)
+		return rules.Rule{
+			ID: "CUSTOM-JWT", Name: "hardcoded-jwt-secret", Category: rules.RuleCategory(category), RawPattern: `jwtSecret\s*=\s*"`,
+			Languages: []string{"go"}, Level: "error", Confidence: 0.8, Message: "Hardcoded JWT secret", Source: "Custom",
+		}, nil
+	}
+	ctx := context.Background()
+	path := filepath.Join(root, ".gavel", "rules", "CUSTOM-JWT.yaml")
+
+	result, err := h.handleCreateRule(ctx, toolRequest("create_rule", map[string]any{
+		"description": "hardcoded JWT secrets", "category": "security",
+	}))
+	require.NoError(t, err)
+	preview := toolJSON(t, result)
+	assert.Equal(t, "preview", preview["status"])
+	assert.Equal(t, path, preview["path"])
+	assert.NoFileExists(t, path, "a preview must not write the rule")
+
+	result, err = h.handleTestRule(ctx, toolRequest("test_rule", map[string]any{
+		"rule": preview["rule"], "code": "package main\n\nvar jwtSecret = \"abc\"\n", "file_name": "main.go",
+	}))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, toolJSON(t, result)["matches"])
+
+	result, err = h.handleCreateRule(ctx, toolRequest("create_rule", map[string]any{"rule": preview["rule"], "confirm": true}))
+	require.NoError(t, err)
+	assert.Equal(t, "created", toolJSON(t, result)["status"])
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "id: CUSTOM-JWT")
+
+	// The new rule is loaded alongside the defaults
+	result, err = h.handleTestRule(ctx, toolRequest("test_rule", map[string]any{
+		"rule_id": "CUSTOM-JWT", "code": "var jwtSecret = \"abc\"", "file_name": "main.go",
+	}))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, toolJSON(t, result)["matches"])
+
+	result, err = h.handleCreateRule(ctx, toolRequest("create_rule", map[string]any{"rule": preview["rule"], "confirm": true}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "an existing rule file must not be overwritten")
+}
+
+func TestCreateRuleTool_RejectsUnsafeID(t *testing.T) {
+	h := newHandlers(ServerConfig{Config: testConfig(), RootDir: t.TempDir()}, nil)
+	rule := "rules:\n  - id: ../escape\n    name: x\n    category: security\n    pattern: x\n    level: error\n    confidence: 0.5\n    message: x\n"
+
+	result, err := h.handleCreateRule(context.Background(), toolRequest("create_rule", map[string]any{"rule": rule, "confirm": true}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestTogglePolicyTool(t *testing.T) {
+	root := t.TempDir()
+	configPath := filepath.Join(root, ".gavel", "policies.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o755))
+	require.NoError(t, os.WriteFile(configPath, []byte("# project policies\npolicies:\n  naming:\n    enabled: true\n"), 0o644))
+	h := newHandlers(ServerConfig{Config: testConfig(), RootDir: root}, nil)
+	ctx := context.Background()
+
+	result, err := h.handleTogglePolicy(ctx, toolRequest("toggle_policy", map[string]any{"name": "naming", "enabled": false}))
+	require.NoError(t, err)
+	assert.Equal(t, "preview", toolJSON(t, result)["status"])
+	assert.True(t, h.currentConfig().Policies["naming"].Enabled, "a preview must not change the policy")
+
+	result, err = h.handleTogglePolicy(ctx, toolRequest("toggle_policy", map[string]any{"name": "naming", "enabled": false, "confirm": true}))
+	require.NoError(t, err)
+	assert.Equal(t, "updated", toolJSON(t, result)["status"])
+	assert.False(t, h.currentConfig().Policies["naming"].Enabled)
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# project policies")
+	assert.Contains(t, string(data), "enabled: false")
+
+	result, err = h.handleListPolicies(ctx, toolRequest("list_policies", nil))
+	require.NoError(t, err)
+	enabled := make(map[string]bool)
+	for _, p := range toolJSON(t, result)["policies"].([]any) {
+		enabled[p.(map[string]any)["name"].(string)] = p.(map[string]any)["enabled"].(bool)
+	}
+	assert.Equal(t, map[string]bool{"error-handling": true, "naming": false, "disabled-policy": false}, enabled)
+
+	result, err = h.handleTogglePolicy(ctx, toolRequest("toggle_policy", map[string]any{"name": "unknown", "enabled": true, "confirm": true}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestGetFindingContextTool(t *testing.T) {
+	root := t.TempDir()
+	var src strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&src, "line %d\n", i)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte(src.String()), 0o644))
+
+	fs := testStore(t)
+	sarifLog := sarif.NewLog("gavel", version)
+	sarifLog.Runs[0].Tool.Driver.Rules = []sarif.ReportingDescriptor{
+		{ID: "S2068", Name: "hardcoded-credentials", ShortDescription: sarif.Message{Text: "Hardcoded credentials"}},
+	}
+	finding := sarif.Result{
+		RuleID:  "S2068",
+		Level:   "error",
+		Message: sarif.Message{Text: "Hardcoded password"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: "main.go"},
+			Region:           sarif.Region{StartLine: 10, EndLine: 11, Snippet: &sarif.ArtifactContent{Text: "line 10\nline 11"}},
+		}}},
+		Properties: map[string]interface{}{
+			"gavel/remediation": "Load the password from the environment",
+			"gavel/cwe":         []string{"CWE-798"},
+		},
+		Fixes: []sarif.Fix{{Description: sarif.Message{Text: "Read from env"}}},
+	}
+	sarif.SetContentFingerprint(&finding)
+	sarifLog.Runs[0].Results = []sarif.Result{finding}
+	id, err := fs.WriteSARIF(context.Background(), sarifLog)
+	require.NoError(t, err)
+
+	h := newHandlers(ServerConfig{Config: testConfig(), Store: fs, RootDir: root}, nil)
+	fingerprint := finding.Fingerprints[sarif.ContentFingerprintV1]
+	result, err := h.handleGetFindingContext(context.Background(), toolRequest("get_finding_context", map[string]any{
+		"fingerprint": fingerprint[:8], "context_lines": 2,
+	}))
+	require.NoError(t, err)
+
+	var out struct {
+		Finding findingContext `json:"finding"`
+	}
+	require.False(t, result.IsError, "tool failed: %+v", result)
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcpgo.TextContent).Text), &out))
+	fc := out.Finding
+	assert.Equal(t, id, fc.ResultID, "the most recent result is used by default")
+	assert.Equal(t, fingerprint, fc.Fingerprint)
+	require.NotNil(t, fc.Source)
+	assert.Equal(t, 8, fc.Source.StartLine)
+	assert.Equal(t, 13, fc.Source.EndLine)
+	assert.Equal(t, "line 8\nline 9\nline 10\nline 11\nline 12\nline 13", fc.Source.Code)
+	assert.False(t, fc.Source.Stale)
+	assert.Equal(t, "hardcoded-credentials", fc.Rule.Name)
+	assert.Equal(t, "Load the password from the environment", fc.Rule.Remediation)
+	assert.Contains(t, fc.Rule.Links, "https://cwe.mitre.org/data/definitions/798.html")
+	assert.Len(t, fc.Fixes, 1)
+
+	// An edited file is reported as stale
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte(strings.Repeat("changed\n", 20)), 0o644))
+	result, err = h.handleGetFindingContext(context.Background(), toolRequest("get_finding_context", map[string]any{
+		"fingerprint": "main.go:10", "result_id": id,
+	}))
+	require.NoError(t, err)
+	assert.True(t, toolJSON(t, result)["finding"].(map[string]any)["source"].(map[string]any)["stale"].(bool))
+
+	result, err = h.handleGetFindingContext(context.Background(), toolRequest("get_finding_context", map[string]any{"fingerprint": "ffffffff"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestSubscriptions_NotifyOnResultWritten(t *testing.T) {
+	fs := testStore(t)
+	subs := NewSubscriptions()
+	s := NewMCPServer(ServerConfig{Config: testConfig(), Store: fs, Subscriptions: subs})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	go subs.Stdio(ctx, server.NewStdioServer(s), stdinR, stdoutW)
+	lines := bufio.NewScanner(stdoutR)
+	send := func(msg string) {
+		_, err := io.WriteString(stdinW, msg+"\n")
+		require.NoError(t, err)
+	}
+	next := func() map[string]any {
+		require.True(t, lines.Scan(), "expected a message")
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &m))
+		return m
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	initResp := next()
+	assert.Equal(t, true, initResp["result"].(map[string]any)["capabilities"].(map[string]any)["resources"].(map[string]any)["subscribe"])
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"gavel://results"}}`)
+	resp := next()
+	assert.EqualValues(t, 2, resp["id"])
+	assert.Nil(t, resp["error"])
+
+	send(`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"gavel://policies"}}`)
+	assert.NotNil(t, next()["error"], "only results can be subscribed to")
+
+	id, err := notifyingStore{Store: fs, subs: subs}.WriteSARIF(ctx, sarif.NewLog("gavel", version))
+	require.NoError(t, err)
+	note := next()
+	assert.Equal(t, "notifications/resources/updated", note["method"])
+	assert.Equal(t, "gavel://results", note["params"].(map[string]any)["uri"])
+
+	// Reading the list resource shows the new result
+	send(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"gavel://results"}}`)
+	read := next()
+	assert.Contains(t, read["result"].(map[string]any)["contents"].([]any)[0].(map[string]any)["text"], id)
+
+	send(`{"jsonrpc":"2.0","id":5,"method":"resources/unsubscribe","params":{"uri":"gavel://results"}}`)
+	assert.Nil(t, next()["error"])
+	subs.mu.Lock()
+	assert.Empty(t, subs.sessions[stdioSessionID].uris)
+	subs.mu.Unlock()
+}
+
+func TestSubscriptions_HTTPScopesNotificationsToTenant(t *testing.T) {
+	subs := NewSubscriptions()
+	handled := false
+	h := subs.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { handled = true }))
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"gavel://results"}}`))
+	req.Header.Set(server.HeaderKeySessionID, "session-a")
+	req = req.WithContext(tenant.NewContext(req.Context(), "acme"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.False(t, handled, "subscribe must be answered before the MCP server")
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, w.Body.String())
+	assert.Equal(t, "acme", subs.sessions["session-a"].tenant)
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, handled, "other requests must reach the MCP server")
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+	"github.com/chris-regnier/gavel/internal/tenant"
+)
+
+// resultsURI is the resource listing every stored result. Subscribing to it
+// notifies a client of each analysis that completes.
+const resultsURI = "gavel://results"
+
+// stdioSessionID is the ID mcp-go gives the single stdio session.
+const stdioSessionID = "stdio"
+
+const (
+	methodSubscribe   = "resources/subscribe"
+	methodUnsubscribe = "resources/unsubscribe"
+)
+
+// Subscriptions implements MCP resource subscriptions for stored results.
+// mcp-go answers resources/subscribe with "method not found", so a server
+// built with Subscriptions must be served through Stdio or Handler, which
+// answer subscribe and unsubscribe requests and pass every other message to
+// the server. When a result is written to the store, each session
+// subscribed to gavel://results or to the result's own URI is sent a
+// notifications/resources/updated message. Over HTTP, sessions only hear
+// about results written by their own tenant.
+type Subscriptions struct {
+	mu       sync.Mutex
+	server   *server.MCPServer
+	sessions map[string]*subscriber
+}
+
+type subscriber struct {
+	tenant string
+	uris   map[string]bool
+}
+
+// NewSubscriptions returns an empty subscription registry, to be passed to
+// NewMCPServer in ServerConfig.Subscriptions.
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{sessions: make(map[string]*subscriber)}
+}
+
+// Stdio serves stdio's MCP server over stdin and stdout like
+// server.StdioServer.Listen, answering subscription requests on the way.
+func (s *Subscriptions) Stdio(ctx context.Context, stdio *server.StdioServer, stdin io.Reader, stdout io.Writer) error {
+	out := &lockedWriter{w: stdout}
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(stdin)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if resp, ok := s.handle(stdioSessionID, "", line); ok {
+					out.Write(append(resp, '\n'))
+				} else if _, werr := pw.Write(line); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return stdio.Listen(ctx, pr, out)
+}
+
+// Handler wraps the Streamable HTTP handler next, answering subscription
+// requests for the session named by the Mcp-Session-Id header.
+func (s *Subscriptions) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "reading request body", http.StatusBadRequest)
+			return
+		}
+		sessionID := r.Header.Get(server.HeaderKeySessionID)
+		if resp, ok := s.handle(sessionID, tenant.FromContext(r.Context()), body); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(resp)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handle answers msg if it is a subscription request, returning the
+// JSON-RPC response and true, or false for any other message.
+func (s *Subscriptions) handle(sessionID, tenantID string, msg []byte) ([]byte, bool) {
+	var req struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(msg, &req) != nil || (req.Method != methodSubscribe && req.Method != methodUnsubscribe) {
+		return nil, false
+	}
+
+	resp := map[string]interface{}{"jsonrpc": mcp.JSONRPC_VERSION, "id": req.ID}
+	switch {
+	case sessionID == "":
+		resp["error"] = map[string]interface{}{"code": mcp.INVALID_REQUEST, "message": "subscriptions require a session"}
+	case req.Params.URI != resultsURI && !strings.HasPrefix(req.Params.URI, resultsURI+"/"):
+		resp["error"] = map[string]interface{}{"code": mcp.INVALID_PARAMS, "message": "only " + resultsURI + " and " + resultsURI + "/{id} can be subscribed to"}
+	case req.Method == methodSubscribe:
+		s.subscribe(sessionID, tenantID, req.Params.URI)
+		resp["result"] = struct{}{}
+	default:
+		s.unsubscribe(sessionID, req.Params.URI)
+		resp["result"] = struct{}{}
+	}
+	data, _ := json.Marshal(resp)
+	return data, true
+}
+
+func (s *Subscriptions) subscribe(sessionID, tenantID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.sessions[sessionID]
+	if !ok {
+		sub = &subscriber{tenant: tenantID, uris: make(map[string]bool)}
+		s.sessions[sessionID] = sub
+	}
+	sub.uris[uri] = true
+}
+
+func (s *Subscriptions) unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub, ok := s.sessions[sessionID]; ok {
+		delete(sub.uris, uri)
+	}
+}
+
+// drop forgets the subscriptions of a session that has ended.
+func (s *Subscriptions) drop(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+}
+
+// notify tells the sessions subscribed to the results list or to result id
+// that the result was written.
+func (s *Subscriptions) notify(tenantID, id string) {
+	uri := resultsURI + "/" + id
+	s.mu.Lock()
+	srv := s.server
+	targets := make(map[string][]string)
+	for sessionID, sub := range s.sessions {
+		if sub.tenant != tenantID {
+			continue
+		}
+		for _, u := range []string{resultsURI, uri} {
+			if sub.uris[u] {
+				targets[sessionID] = append(targets[sessionID], u)
+			}
+		}
+	}
+	s.mu.Unlock()
+	if srv == nil {
+		return
+	}
+
+	for sessionID, uris := range targets {
+		for _, u := range uris {
+			err := srv.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": u})
+			if errors.Is(err, server.ErrSessionNotFound) {
+				s.drop(sessionID)
+				break
+			}
+			if err != nil {
+				slog.Debug("sending resource update", "session", sessionID, "uri", u, "error", err)
+			}
+		}
+	}
+}
+
+// notifyingStore reports every result written through it to subscriptions.
+type notifyingStore struct {
+	store.Store
+	subs *Subscriptions
+}
+
+func (s notifyingStore) WriteSARIF(ctx context.Context, doc *sarif.Log) (string, error) {
+	id, err := s.Store.WriteSARIF(ctx, doc)
+	if err == nil {
+		s.subs.notify(tenant.FromContext(ctx), id)
+	}
+	return id, err
+}
+
+// lockedWriter serializes writes, so responses written by Subscriptions do
+// not interleave with the stdio server's own.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
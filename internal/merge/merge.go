@@ -0,0 +1,353 @@
+// Package merge combines SARIF logs into one Gavel run so a single verdict
+// can be evaluated over them: the logs written by sharded `gavel analyze
+// --shard` runs, earlier runs over the same code, or Gavel's results
+// together with other scanners' SARIF.
+package merge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// PropShard is the run property analyze --shard records its shard in, as
+// "INDEX/COUNT".
+const PropShard = "gavel/shard"
+
+// PropShards is the run property a merged log records its shard count in.
+const PropShards = "gavel/shards"
+
+// PropTools is the run property a merged log records the tool of every
+// merged run in, since the merged run has a single driver.
+const PropTools = "gavel/tools"
+
+// gavelTool is the driver name of runs written by Gavel.
+const gavelTool = "gavel"
+
+// Tool identifies the tool that produced a merged run.
+type Tool struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+}
+
+// Summary counts what Logs merged.
+type Summary struct {
+	Logs       int      `json:"logs"`
+	Runs       int      `json:"runs"`
+	Shards     int      `json:"shards,omitempty"`
+	Tools      []string `json:"tools"`
+	Duplicates int      `json:"duplicates"`
+	Results    int      `json:"results"`
+}
+
+// LoadFiles reads the SARIF logs at paths.
+func LoadFiles(paths []string) ([]*sarif.Log, error) {
+	logs := make([]*sarif.Log, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var log sarif.Log
+		if err := json.Unmarshal(data, &log); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", p, err)
+		}
+		logs = append(logs, &log)
+	}
+	return logs, nil
+}
+
+// source is one run of the logs being merged, with the 1-based position
+// of its log for error messages.
+type source struct {
+	log int
+	run *sarif.Run
+}
+
+// Logs merges every run of logs into one Gavel run. The merged run takes
+// its driver and run properties from the first Gavel run, or is a new
+// Gavel run at version when there is none; the tool of each merged run is
+// listed in gavel/tools, and results from other tools are tagged with
+// theirs in gavel/tool.
+//
+// When the Gavel runs come from shards, the shard set must be complete,
+// with every shard present exactly once, and all shards must have been
+// analyzed with the same settings (their gavel/provenance must match).
+// Other Gavel runs may differ; the merged run keeps gavel/provenance only
+// when they all agree.
+//
+// Rules are combined by ID, and results reported more than once are kept
+// once using the analyzer's tier-aware deduplication: per rule, file and
+// start line, the result from the highest tier wins. The cost, partial and
+// warning run properties are combined across Gavel runs.
+func Logs(logs []*sarif.Log, version string) (*sarif.Log, Summary, error) {
+	if len(logs) == 0 {
+		return nil, Summary{}, fmt.Errorf("no logs to merge")
+	}
+	var sources, gavelRuns []source
+	for i, log := range logs {
+		if log == nil || len(log.Runs) == 0 {
+			return nil, Summary{}, fmt.Errorf("log %d has no runs", i+1)
+		}
+		for j := range log.Runs {
+			src := source{log: i + 1, run: &log.Runs[j]}
+			sources = append(sources, src)
+			if src.run.Tool.Driver.Name == gavelTool {
+				gavelRuns = append(gavelRuns, src)
+			}
+		}
+	}
+
+	shards, err := checkShards(gavelRuns)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	sameSettings, err := checkProvenance(gavelRuns)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	if shards > 0 && !sameSettings {
+		return nil, Summary{}, fmt.Errorf("shards were analyzed with different settings (gavel/provenance differs)")
+	}
+
+	merged := sarif.NewLog(gavelTool, version)
+	run := &merged.Runs[0]
+	run.Properties = make(map[string]interface{})
+	if len(gavelRuns) > 0 {
+		first := gavelRuns[0].run
+		run.Tool = first.Tool
+		run.Tool.Driver.Rules = nil
+		for k, v := range first.Properties {
+			run.Properties[k] = v
+		}
+	}
+	delete(run.Properties, PropShard)
+	delete(run.Properties, PropShards)
+	delete(run.Properties, PropTools)
+	delete(run.Properties, "gavel/cost")
+	delete(run.Properties, "gavel/partial")
+	delete(run.Properties, "gavel/warnings")
+	if !sameSettings {
+		delete(run.Properties, sarif.PropProvenance)
+	}
+
+	seenRules := make(map[string]bool)
+	seenTools := make(map[Tool]bool)
+	var tools []Tool
+	var names []string
+	var results []sarif.Result
+	var warnings []string
+	partial := false
+	var costs []map[string]interface{}
+	for _, src := range sources {
+		r := src.run
+		driver := r.Tool.Driver
+		tool := Tool{Name: driver.Name, Version: driver.Version, InformationURI: driver.InformationURI}
+		if !seenTools[tool] {
+			seenTools[tool] = true
+			tools = append(tools, tool)
+			if !contains(names, tool.Name) {
+				names = append(names, tool.Name)
+			}
+		}
+		for _, rule := range driver.Rules {
+			if !seenRules[rule.ID] {
+				seenRules[rule.ID] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+			}
+		}
+		run.Invocations = append(run.Invocations, r.Invocations...)
+
+		if driver.Name != gavelTool {
+			for _, res := range r.Results {
+				results = append(results, tagTool(res, driver.Name))
+			}
+			continue
+		}
+		results = append(results, r.Results...)
+		if p, _ := r.Properties["gavel/partial"].(bool); p {
+			partial = true
+		}
+		warnings = append(warnings, stringList(r.Properties["gavel/warnings"])...)
+		if c, ok := r.Properties["gavel/cost"].(map[string]interface{}); ok {
+			costs = append(costs, c)
+		}
+	}
+	run.Results = sarif.DeduplicateByTier(results)
+	run.Taxonomies = sarif.BuildTaxonomies(run.Tool.Driver.Rules)
+	run.Properties[PropTools] = tools
+
+	if partial {
+		run.Properties["gavel/partial"] = true
+	}
+	if len(warnings) > 0 {
+		run.Properties["gavel/warnings"] = warnings
+	}
+	if len(costs) > 0 {
+		run.Properties["gavel/cost"] = sumCosts(costs)
+	}
+	if shards > 0 {
+		run.Properties[PropShards] = shards
+	}
+
+	return merged, Summary{
+		Logs:       len(logs),
+		Runs:       len(sources),
+		Shards:     shards,
+		Tools:      names,
+		Duplicates: len(results) - len(run.Results),
+		Results:    len(run.Results),
+	}, nil
+}
+
+// tagTool returns a copy of res recording the tool that reported it in
+// gavel/tool, unless it already names one.
+func tagTool(res sarif.Result, tool string) sarif.Result {
+	props := make(map[string]interface{}, len(res.Properties)+1)
+	for k, v := range res.Properties {
+		props[k] = v
+	}
+	if _, ok := props["gavel/tool"]; !ok {
+		props["gavel/tool"] = tool
+	}
+	res.Properties = props
+	return res
+}
+
+// checkShards validates the gavel/shard properties of Gavel runs and
+// returns the shard count, or 0 when no run is a shard.
+func checkShards(runs []source) (int, error) {
+	values := make([]string, len(runs))
+	sharded := 0
+	for i, src := range runs {
+		if v, ok := src.run.Properties[PropShard].(string); ok {
+			values[i] = v
+			sharded++
+		}
+	}
+	if sharded == 0 {
+		return 0, nil
+	}
+
+	count := 0
+	seen := make(map[int]bool)
+	for i, value := range values {
+		n := runs[i].log
+		if value == "" {
+			return 0, fmt.Errorf("log %d is not a shard but other logs are; merge only the logs of one sharded run", n)
+		}
+		shard, err := input.ParseShard(value)
+		if err != nil {
+			return 0, fmt.Errorf("log %d: %w", n, err)
+		}
+		if count != 0 && shard.Count != count {
+			return 0, fmt.Errorf("log %d is shard %s, but other logs come from a run split %d ways", n, shard, count)
+		}
+		count = shard.Count
+		if seen[shard.Index] {
+			return 0, fmt.Errorf("shard %s appears more than once", shard)
+		}
+		seen[shard.Index] = true
+	}
+	for i := 1; i <= count; i++ {
+		if !seen[i] {
+			return 0, fmt.Errorf("shard %d/%d is missing; its files would be unreported", i, count)
+		}
+	}
+	return count, nil
+}
+
+// checkProvenance reports whether every Gavel run that records its
+// provenance was produced with the same config, rules, persona and model.
+func checkProvenance(runs []source) (bool, error) {
+	var want []byte
+	for _, src := range runs {
+		prov, ok := src.run.Properties[sarif.PropProvenance]
+		if !ok {
+			continue
+		}
+		got, err := json.Marshal(prov)
+		if err != nil {
+			return false, fmt.Errorf("log %d: encoding provenance: %w", src.log, err)
+		}
+		if want == nil {
+			want = got
+			continue
+		}
+		if string(got) != string(want) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// sumCosts adds up gavel/cost properties: the total, and the calls, tokens
+// and cost of each model. maxUSD is a per-run limit, so the first is kept.
+func sumCosts(costs []map[string]interface{}) map[string]interface{} {
+	total := 0.0
+	byModel := make(map[string]interface{})
+	for _, c := range costs {
+		total += number(c["estimatedUSD"])
+		models, _ := c["byModel"].(map[string]interface{})
+		for key, v := range models {
+			m, _ := v.(map[string]interface{})
+			acc, _ := byModel[key].(map[string]interface{})
+			if acc == nil {
+				acc = map[string]interface{}{"calls": 0.0, "tokensIn": 0.0, "tokensOut": 0.0, "estimatedUSD": 0.0}
+				byModel[key] = acc
+			}
+			for _, field := range []string{"calls", "tokensIn", "tokensOut", "estimatedUSD"} {
+				acc[field] = number(acc[field]) + number(m[field])
+			}
+		}
+	}
+	merged := map[string]interface{}{"estimatedUSD": total, "byModel": byModel}
+	if limit, ok := costs[0]["maxUSD"]; ok {
+		merged["maxUSD"] = limit
+	}
+	return merged
+}
+
+// number reads a JSON number decoded into an interface{}.
+func number(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
+
+// stringList reads a JSON array of strings decoded into an interface{}.
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		var out []string
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// contains reports whether list holds s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func shardLog(shard string, results ...sarif.Result) *sarif.Log {
+	log := sarif.NewLog("gavel", "0.1.0")
+	log.Runs[0].Tool.Driver.Rules = []sarif.ReportingDescriptor{{ID: "S1"}, {ID: "S2"}}
+	log.Runs[0].Results = results
+	log.Runs[0].Properties = map[string]interface{}{
+		"gavel/inputScope":   "directory",
+		sarif.PropProvenance: map[string]interface{}{"configHash": "sha256:abc"},
+	}
+	if shard != "" {
+		log.Runs[0].Properties[PropShard] = shard
+	}
+	return log
+}
+
+func finding(ruleID, uri string) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   "warning",
+		Message: sarif.Message{Text: ruleID},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region:           sarif.Region{StartLine: 1},
+		}}},
+	}
+}
+
+func TestLogsMergesShards(t *testing.T) {
+	a := shardLog("1/2", finding("S1", "a.go"))
+	a.Runs[0].Properties["gavel/cost"] = map[string]interface{}{
+		"estimatedUSD": 0.5,
+		"byModel":      map[string]interface{}{"anthropic/m": map[string]interface{}{"calls": 2.0, "tokensIn": 100.0, "tokensOut": 10.0, "estimatedUSD": 0.5}},
+	}
+	b := shardLog("2/2", finding("S2", "b.go"), finding("S1", "c.go"))
+	b.Runs[0].Tool.Driver.Rules = append(b.Runs[0].Tool.Driver.Rules, sarif.ReportingDescriptor{ID: "S3"})
+	b.Runs[0].Properties["gavel/partial"] = true
+	b.Runs[0].Properties["gavel/warnings"] = []interface{}{"budget ran out"}
+	b.Runs[0].Properties["gavel/cost"] = map[string]interface{}{
+		"estimatedUSD": 0.25,
+		"byModel":      map[string]interface{}{"anthropic/m": map[string]interface{}{"calls": 1.0, "tokensIn": 50.0, "tokensOut": 5.0, "estimatedUSD": 0.25}},
+	}
+
+	merged, summary, err := Logs([]*sarif.Log{b, a}, "0.2.0")
+	if err != nil {
+		t.Fatalf("Logs: %v", err)
+	}
+	if summary.Logs != 2 || summary.Shards != 2 || summary.Results != 3 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	run := merged.Runs[0]
+	if len(run.Results) != 3 {
+		t.Errorf("expected 3 results, got %d", len(run.Results))
+	}
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Errorf("expected rules combined by ID, got %+v", run.Tool.Driver.Rules)
+	}
+	if _, ok := run.Properties[PropShard]; ok {
+		t.Error("expected the per-shard property to be dropped")
+	}
+	if run.Properties[PropShards] != 2 || run.Properties["gavel/inputScope"] != "directory" {
+		t.Errorf("unexpected run properties %v", run.Properties)
+	}
+	if run.Properties["gavel/partial"] != true {
+		t.Error("expected a partial shard to make the merged run partial")
+	}
+	cost := run.Properties["gavel/cost"].(map[string]interface{})
+	if cost["estimatedUSD"] != 0.75 {
+		t.Errorf("expected costs summed to 0.75, got %v", cost["estimatedUSD"])
+	}
+	model := cost["byModel"].(map[string]interface{})["anthropic/m"].(map[string]interface{})
+	if model["calls"] != 3.0 {
+		t.Errorf("expected 3 calls, got %v", model["calls"])
+	}
+}
+
+func TestLogsRejectsIncompleteShards(t *testing.T) {
+	tests := []struct {
+		name string
+		logs []*sarif.Log
+		want string
+	}{
+		{"missing", []*sarif.Log{shardLog("1/3"), shardLog("3/3")}, "shard 2/3 is missing"},
+		{"duplicate", []*sarif.Log{shardLog("1/2"), shardLog("1/2")}, "more than once"},
+		{"counts", []*sarif.Log{shardLog("1/2"), shardLog("2/3")}, "split 2 ways"},
+		{"mixed", []*sarif.Log{shardLog("1/1"), shardLog("")}, "not a shard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Logs(tt.logs, "0.2.0")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLogsRejectsDifferentSettings(t *testing.T) {
+	a, b := shardLog("1/2"), shardLog("2/2")
+	b.Runs[0].Properties[sarif.PropProvenance] = map[string]interface{}{"configHash": "sha256:def"}
+	if _, _, err := Logs([]*sarif.Log{a, b}, "0.2.0"); err == nil || !strings.Contains(err.Error(), "different settings") {
+		t.Errorf("expected a provenance mismatch error, got %v", err)
+	}
+}
+
+func TestLogsDeduplicatesByTier(t *testing.T) {
+	older := finding("S1", "a.go")
+	older.Properties = map[string]interface{}{"gavel/tier": "instant"}
+	newer := finding("S1", "a.go")
+	newer.Properties = map[string]interface{}{"gavel/tier": "comprehensive"}
+
+	a := shardLog("", older, finding("S2", "a.go"))
+	b := shardLog("", newer)
+	b.Runs[0].Properties[sarif.PropProvenance] = map[string]interface{}{"configHash": "sha256:def"}
+
+	merged, summary, err := Logs([]*sarif.Log{a, b}, "0.2.0")
+	if err != nil {
+		t.Fatalf("Logs: %v", err)
+	}
+	if summary.Duplicates != 1 || summary.Results != 2 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	run := merged.Runs[0]
+	if run.Results[0].Properties["gavel/tier"] != "comprehensive" {
+		t.Errorf("expected the comprehensive result to win, got %v", run.Results[0].Properties)
+	}
+	if _, ok := run.Properties[sarif.PropProvenance]; ok {
+		t.Error("expected provenance to be dropped when runs disagree")
+	}
+}
+
+func TestLogsKeepsOtherTools(t *testing.T) {
+	gavel := shardLog("", finding("S1", "a.go"))
+	other := &sarif.Log{Version: sarif.Version, Runs: []sarif.Run{
+		{
+			Tool:    sarif.Tool{Driver: sarif.Driver{Name: "gosec", Version: "2.20.0", Rules: []sarif.ReportingDescriptor{{ID: "G101"}}}},
+			Results: []sarif.Result{finding("G101", "b.go")},
+		},
+		{
+			Tool:    sarif.Tool{Driver: sarif.Driver{Name: "semgrep", InformationURI: "https://semgrep.dev"}},
+			Results: []sarif.Result{finding("rule.x", "c.go")},
+		},
+	}}
+
+	merged, summary, err := Logs([]*sarif.Log{other, gavel}, "0.2.0")
+	if err != nil {
+		t.Fatalf("Logs: %v", err)
+	}
+	if summary.Runs != 3 || strings.Join(summary.Tools, ",") != "gosec,semgrep,gavel" {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	run := merged.Runs[0]
+	if run.Tool.Driver.Name != "gavel" || run.Tool.Driver.Version != "0.1.0" {
+		t.Errorf("expected the Gavel run's driver, got %+v", run.Tool.Driver)
+	}
+	if run.Properties["gavel/inputScope"] != "directory" {
+		t.Errorf("expected the Gavel run's properties, got %v", run.Properties)
+	}
+	tools := run.Properties[PropTools].([]Tool)
+	if len(tools) != 3 || tools[0] != (Tool{Name: "gosec", Version: "2.20.0"}) || tools[1].InformationURI != "https://semgrep.dev" {
+		t.Errorf("unexpected gavel/tools %+v", tools)
+	}
+	if run.Results[0].Properties["gavel/tool"] != "gosec" || run.Results[1].Properties["gavel/tool"] != "semgrep" {
+		t.Errorf("expected results tagged with their tool, got %v / %v", run.Results[0].Properties, run.Results[1].Properties)
+	}
+	if _, ok := run.Results[2].Properties["gavel/tool"]; ok {
+		t.Error("expected Gavel's own results to be left untagged")
+	}
+	if other.Runs[0].Results[0].Properties != nil {
+		t.Error("expected the input log to be left unchanged")
+	}
+}
+
+func TestLogsWithoutGavelRun(t *testing.T) {
+	other := &sarif.Log{Version: sarif.Version, Runs: []sarif.Run{{
+		Tool:    sarif.Tool{Driver: sarif.Driver{Name: "gosec"}},
+		Results: []sarif.Result{finding("G101", "b.go")},
+	}}}
+	merged, _, err := Logs([]*sarif.Log{other}, "0.2.0")
+	if err != nil {
+		t.Fatalf("Logs: %v", err)
+	}
+	if d := merged.Runs[0].Tool.Driver; d.Name != "gavel" || d.Version != "0.2.0" {
+		t.Errorf("expected a new Gavel driver, got %+v", d)
+	}
+
+	if _, _, err := Logs([]*sarif.Log{{Version: sarif.Version}}, "0.2.0"); err == nil {
+		t.Error("expected an error for a log with no runs")
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sarif.json")
+	if err := os.WriteFile(path, []byte(`{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"gavel"}},"results":[],"properties":{"gavel/shard":"1/1"}}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := LoadFiles([]string{path})
+	if err != nil {
+		t.Fatalf("LoadFiles: %v", err)
+	}
+	merged, summary, err := Logs(logs, "0.2.0")
+	if err != nil {
+		t.Fatalf("Logs: %v", err)
+	}
+	if summary.Shards != 1 || merged.Runs[0].Tool.Driver.Name != "gavel" {
+		t.Errorf("unexpected merge of a single shard: %+v", summary)
+	}
+
+	if _, err := LoadFiles([]string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// Price is what a model charges, in US dollars per 1,000 tokens.
+type Price struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+// Cost returns the price of a call with the given token counts.
+func (p Price) Cost(tokensIn, tokensOut int) float64 {
+	return float64(tokensIn)/1000*p.InputPer1K + float64(tokensOut)/1000*p.OutputPer1K
+}
+
+// PriceTable maps "provider/model", or "provider" for every model of a
+// provider, to a price.
+type PriceTable map[string]Price
+
+// NewPriceTable builds a table from the pricing section of the config.
+// Local Ollama models are free unless the config says otherwise.
+func NewPriceTable(pricing map[string]config.ModelPrice) PriceTable {
+	t := PriceTable{"ollama": {}}
+	for key, p := range pricing {
+		t[key] = Price{InputPer1K: p.InputPer1K, OutputPer1K: p.OutputPer1K}
+	}
+	return t
+}
+
+// Lookup returns the price of model on provider, preferring an entry for
+// the model over one for the whole provider.
+func (t PriceTable) Lookup(provider, model string) (Price, bool) {
+	if p, ok := t[provider+"/"+model]; ok {
+		return p, true
+	}
+	p, ok := t[provider]
+	return p, ok
+}
+
+// Budget caps the estimated spend of a run. Callers reserve the expected
+// cost of a call before making it and settle the actual cost afterwards.
+// Once a reservation is refused the budget is exceeded and refuses every
+// later one, so a run stops rather than squeezing in smaller calls.
+type Budget struct {
+	mu       sync.Mutex
+	limit    float64
+	spent    float64
+	exceeded bool
+}
+
+// NewBudget creates a budget of limit US dollars.
+func NewBudget(limit float64) *Budget {
+	return &Budget{limit: limit}
+}
+
+// Reserve sets aside cost for a call, reporting false without reserving
+// anything when it would take spending past the limit.
+func (b *Budget) Reserve(cost float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.exceeded || b.spent+cost > b.limit {
+		b.exceeded = true
+		return false
+	}
+	b.spent += cost
+	return true
+}
+
+// Settle replaces a reservation with the call's actual cost.
+func (b *Budget) Settle(reserved, actual float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent += actual - reserved
+}
+
+// Limit returns the budget in US dollars.
+func (b *Budget) Limit() float64 {
+	return b.limit
+}
+
+// Spent returns the estimated spend so far, including open reservations.
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent
+}
+
+// Exceeded reports whether a reservation has been refused.
+func (b *Budget) Exceeded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exceeded
+}
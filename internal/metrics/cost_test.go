@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+func TestPriceTable_Lookup(t *testing.T) {
+	table := NewPriceTable(map[string]config.ModelPrice{
+		"anthropic":                  {InputPer1K: 0.003, OutputPer1K: 0.015},
+		"anthropic/claude-haiku":     {InputPer1K: 0.001, OutputPer1K: 0.005},
+		"openrouter/google/gemini-x": {InputPer1K: 0.002},
+	})
+
+	if p, ok := table.Lookup("anthropic", "claude-haiku"); !ok || p.InputPer1K != 0.001 {
+		t.Errorf("model entry: got %+v, %v", p, ok)
+	}
+	if p, ok := table.Lookup("anthropic", "claude-sonnet"); !ok || p.InputPer1K != 0.003 {
+		t.Errorf("provider entry: got %+v, %v", p, ok)
+	}
+	if _, ok := table.Lookup("openrouter", "google/gemini-x"); !ok {
+		t.Error("model names containing slashes should match")
+	}
+	if p, ok := table.Lookup("ollama", "qwen"); !ok || p != (Price{}) {
+		t.Errorf("ollama should default to free, got %+v, %v", p, ok)
+	}
+	if _, ok := table.Lookup("openai", "gpt"); ok {
+		t.Error("expected no price for an unconfigured provider")
+	}
+
+	if got := (Price{InputPer1K: 0.003, OutputPer1K: 0.015}).Cost(2000, 1000); math.Abs(got-0.021) > 1e-9 {
+		t.Errorf("Cost = %v, want 0.021", got)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(1.0)
+	if !b.Reserve(0.6) {
+		t.Fatal("first reservation should fit")
+	}
+	b.Settle(0.6, 0.2)
+	if got := b.Spent(); math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("Spent = %v after settling, want 0.2", got)
+	}
+	if b.Reserve(0.9) {
+		t.Error("reservation past the limit should be refused")
+	}
+	if !b.Exceeded() {
+		t.Error("budget should be exceeded after a refusal")
+	}
+	if b.Reserve(0.01) {
+		t.Error("an exceeded budget should refuse every later reservation")
+	}
+	if got := b.Spent(); math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("refused reservations should not be charged, spent = %v", got)
+	}
+}
+
+func TestCollector_CostByModel(t *testing.T) {
+	c := NewCollector()
+	c.Record(AnalysisEvent{Provider: "anthropic", Model: "claude", TokensIn: 100, TokensOut: 10, CostUSD: 0.5})
+	c.Record(AnalysisEvent{Provider: "anthropic", Model: "claude", TokensIn: 50, CostUSD: 0.25})
+	c.Record(AnalysisEvent{Provider: "ollama", Model: "qwen", TokensIn: 10})
+	c.Record(AnalysisEvent{Tier: TierInstant})
+
+	stats := c.GetStats()
+	if math.Abs(stats.TotalCostUSD-0.75) > 1e-9 {
+		t.Errorf("TotalCostUSD = %v, want 0.75", stats.TotalCostUSD)
+	}
+	ms := stats.ByModel["anthropic/claude"]
+	if ms == nil || ms.Calls != 2 || ms.TokensIn != 150 || ms.TokensOut != 10 {
+		t.Errorf("anthropic/claude stats = %+v", ms)
+	}
+	if len(stats.ByModel) != 2 {
+		t.Errorf("expected 2 models, got %v", stats.ByModel)
+	}
+
+	c.Reset()
+	if stats := c.GetStats(); stats.ByModel != nil || stats.TotalCostUSD != 0 {
+		t.Errorf("Reset should clear cost stats, got %+v", stats.ByModel)
+	}
+}
@@ -104,6 +104,18 @@ func (e *Exporter) WriteReport(w io.Writer) error {
 	fmt.Fprintf(w, "Avg In:      %.0f\n", stats.AvgTokensIn)
 	fmt.Fprintf(w, "Avg Out:     %.0f\n\n", stats.AvgTokensOut)
 
+	if len(stats.ByModel) > 0 {
+		fmt.Fprintf(w, "=== Cost (estimated) ===\n")
+		fmt.Fprintf(w, "Total: $%.4f\n", stats.TotalCostUSD)
+		for model, ms := range stats.ByModel {
+			fmt.Fprintf(w, "%s:\n", model)
+			fmt.Fprintf(w, "  Calls:      %d\n", ms.Calls)
+			fmt.Fprintf(w, "  Tokens:     %d in, %d out\n", ms.TokensIn, ms.TokensOut)
+			fmt.Fprintf(w, "  Cost:       $%.4f\n", ms.CostUSD)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
 	if len(stats.ByTier) > 0 {
 		fmt.Fprintf(w, "=== By Tier ===\n")
 		for tier, tierStats := range stats.ByTier {
@@ -124,7 +136,7 @@ func (e *Exporter) WriteCSV(w io.Writer) error {
 	// Header
 	fmt.Fprintf(w, "id,timestamp,type,tier,file_path,file_size,line_count,chunk_count,policy_count,")
 	fmt.Fprintf(w, "queue_duration_ms,analysis_duration_ms,total_duration_ms,")
-	fmt.Fprintf(w, "finding_count,error_count,tokens_in,tokens_out,")
+	fmt.Fprintf(w, "finding_count,error_count,tokens_in,tokens_out,cost_usd,")
 	fmt.Fprintf(w, "cache_result,provider,model,error\n")
 
 	for _, e := range events {
@@ -144,11 +156,12 @@ func (e *Exporter) WriteCSV(w io.Writer) error {
 			e.AnalysisDuration.Milliseconds(),
 			e.TotalDuration.Milliseconds(),
 		)
-		fmt.Fprintf(w, "%d,%d,%d,%d,",
+		fmt.Fprintf(w, "%d,%d,%d,%d,%.6f,",
 			e.FindingCount,
 			e.ErrorCount,
 			e.TokensIn,
 			e.TokensOut,
+			e.CostUSD,
 		)
 		fmt.Fprintf(w, "%s,%s,%s,%s\n",
 			e.CacheResult,
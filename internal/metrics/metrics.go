@@ -71,6 +71,7 @@ type AnalysisEvent struct {
 	ErrorCount   int     `json:"error_count"`
 	TokensIn     int     `json:"tokens_in"`
 	TokensOut    int     `json:"tokens_out"`
+	CostUSD      float64 `json:"cost_usd,omitempty"` // estimated from the price table
 
 	// Cache
 	CacheResult CacheResult `json:"cache_result"`
@@ -80,6 +81,9 @@ type AnalysisEvent struct {
 	Provider string `json:"provider"`
 	Model    string `json:"model"`
 
+	// Tenant the analysis was made for, when served to several
+	Tenant string `json:"tenant,omitempty"`
+
 	// Error tracking
 	Error string `json:"error,omitempty"`
 }
@@ -165,6 +169,10 @@ type AggregateStats struct {
 	AvgTokensIn    float64 `json:"avg_tokens_in"`
 	AvgTokensOut   float64 `json:"avg_tokens_out"`
 
+	// Estimated cost
+	TotalCostUSD float64               `json:"total_cost_usd"`
+	ByModel      map[string]*ModelStats `json:"by_model,omitempty"`
+
 	// By tier breakdown
 	ByTier map[string]*TierStats `json:"by_tier"`
 
@@ -180,6 +188,34 @@ type TierStats struct {
 	ErrorRate             float64 `json:"error_rate"`
 }
 
+// ModelStats holds the usage and estimated cost of one provider and model,
+// keyed "provider/model"
+type ModelStats struct {
+	Calls     int64   `json:"calls"`
+	TokensIn  int64   `json:"tokens_in"`
+	TokensOut int64   `json:"tokens_out"`
+	CostUSD   float64 `json:"cost_usd"`
+}
+
+// Usage is what one tenant used on one UTC day
+type Usage struct {
+	Calls     int64   `json:"calls"`
+	TokensIn  int64   `json:"tokens_in"`
+	TokensOut int64   `json:"tokens_out"`
+	CostUSD   float64 `json:"cost_usd"`
+}
+
+// Tokens returns the tokens used in both directions
+func (u Usage) Tokens() int64 {
+	return u.TokensIn + u.TokensOut
+}
+
+// usageKey identifies a tenant's usage on a day ("2006-01-02", UTC)
+type usageKey struct {
+	tenant string
+	day    string
+}
+
 // atomicCounters holds atomic counters for real-time stats
 type atomicCounters struct {
 	totalAnalyses atomic.Int64
@@ -197,6 +233,9 @@ type Collector struct {
 	mu       sync.RWMutex
 	events   []AnalysisEvent
 	counters atomicCounters
+	byModel  map[string]*ModelStats // guarded by mu
+	usage    map[usageKey]*Usage    // guarded by mu; today's and yesterday's only
+	prom     *promMetrics           // set by WithPrometheus
 
 	// Configuration
 	maxEvents   int
@@ -258,12 +297,36 @@ func (c *Collector) Record(event AnalysisEvent) {
 		c.counters.cacheStale.Add(1)
 	}
 
+	if c.prom != nil {
+		c.prom.observe(event)
+	}
+
 	// Store event
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.events = append(c.events, event)
 
+	if event.Provider != "" || event.Model != "" {
+		if c.byModel == nil {
+			c.byModel = make(map[string]*ModelStats)
+		}
+		key := event.Provider + "/" + event.Model
+		ms := c.byModel[key]
+		if ms == nil {
+			ms = &ModelStats{}
+			c.byModel[key] = ms
+		}
+		ms.Calls++
+		ms.TokensIn += int64(event.TokensIn)
+		ms.TokensOut += int64(event.TokensOut)
+		ms.CostUSD += event.CostUSD
+	}
+
+	if event.Tenant != "" {
+		c.addUsage(event)
+	}
+
 	// Prune old events if needed
 	if len(c.events) > c.maxEvents {
 		// Remove oldest 10%
@@ -272,6 +335,44 @@ func (c *Collector) Record(event AnalysisEvent) {
 	}
 }
 
+// addUsage adds event to its tenant's usage for the day, dropping days
+// before the previous one. Callers hold mu.
+func (c *Collector) addUsage(event AnalysisEvent) {
+	day := event.Timestamp.UTC()
+	key := usageKey{tenant: event.Tenant, day: day.Format(time.DateOnly)}
+	if c.usage == nil {
+		c.usage = make(map[usageKey]*Usage)
+	}
+	u := c.usage[key]
+	if u == nil {
+		u = &Usage{}
+		c.usage[key] = u
+		oldest := day.AddDate(0, 0, -1).Format(time.DateOnly)
+		for k := range c.usage {
+			if k.day < oldest {
+				delete(c.usage, k)
+			}
+		}
+	}
+	u.Calls++
+	u.TokensIn += int64(event.TokensIn)
+	u.TokensOut += int64(event.TokensOut)
+	u.CostUSD += event.CostUSD
+}
+
+// TenantUsage returns what tenant used on the UTC day containing t. Usage
+// is kept for the current and previous day only, and unlike events is not
+// pruned, so it can enforce daily quotas.
+func (c *Collector) TenantUsage(tenant string, t time.Time) Usage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if u := c.usage[usageKey{tenant: tenant, day: t.UTC().Format(time.DateOnly)}]; u != nil {
+		return *u
+	}
+	return Usage{}
+}
+
 // GetStats computes aggregate statistics from collected events
 func (c *Collector) GetStats() AggregateStats {
 	c.mu.RLock()
@@ -294,6 +395,16 @@ func (c *Collector) GetStats() AggregateStats {
 		WindowEnd:     now,
 	}
 
+	// Per-model usage and cost
+	if len(c.byModel) > 0 {
+		stats.ByModel = make(map[string]*ModelStats, len(c.byModel))
+		for key, ms := range c.byModel {
+			copied := *ms
+			stats.ByModel[key] = &copied
+			stats.TotalCostUSD += ms.CostUSD
+		}
+	}
+
 	// Calculate cache hit rate
 	totalCacheOps := stats.CacheHits + stats.CacheMisses + stats.CacheStale
 	if totalCacheOps > 0 {
@@ -398,6 +509,8 @@ func (c *Collector) Reset() {
 
 	c.events = c.events[:0]
 	c.counters = atomicCounters{}
+	c.byModel = nil
+	c.usage = nil
 	c.startTime = time.Now()
 }
 
@@ -206,6 +206,31 @@ func TestCollector_Pruning(t *testing.T) {
 	}
 }
 
+func TestCollector_TenantUsage(t *testing.T) {
+	c := NewCollector(WithMaxEvents(10))
+	day := time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ { // more than are retained as events
+		c.Record(AnalysisEvent{Timestamp: day, Tenant: "acme", TokensIn: 100, TokensOut: 10, CostUSD: 0.01})
+	}
+	c.Record(AnalysisEvent{Timestamp: day, Tenant: "other", TokensIn: 5})
+	c.Record(AnalysisEvent{Timestamp: day, TokensIn: 1000}) // no tenant
+
+	got := c.TenantUsage("acme", day.Add(30*time.Minute).In(time.FixedZone("CET", 3600)))
+	if got.Calls != 20 || got.Tokens() != 2200 || got.CostUSD < 0.199 || got.CostUSD > 0.201 {
+		t.Errorf("unexpected usage %+v", got)
+	}
+	if got := c.TenantUsage("acme", day.AddDate(0, 0, 1)); got != (Usage{}) {
+		t.Errorf("expected no usage the next day, got %+v", got)
+	}
+
+	// Recording two days later drops the first day
+	c.Record(AnalysisEvent{Timestamp: day.AddDate(0, 0, 2), Tenant: "acme", TokensIn: 1})
+	if got := c.TenantUsage("acme", day); got != (Usage{}) {
+		t.Errorf("expected old usage to be dropped, got %+v", got)
+	}
+}
+
 func TestRecorder_StartAnalysis(t *testing.T) {
 	c := NewCollector()
 	r := NewRecorder(c, "ollama", "qwen2.5-coder:7b")
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// promMetrics mirrors recorded events into Prometheus counters and
+// histograms. Events are observed as they are recorded, so the series stay
+// monotonic even after the Collector prunes old events.
+type promMetrics struct {
+	registry *prometheus.Registry
+	analyses *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	findings *prometheus.CounterVec
+	cache    *prometheus.CounterVec
+	tokens   *prometheus.CounterVec
+}
+
+// WithPrometheus makes the collector's metrics available to Prometheus
+// through PrometheusHandler.
+func WithPrometheus() CollectorOption {
+	return func(c *Collector) {
+		c.prom = newPromMetrics()
+	}
+}
+
+func newPromMetrics() *promMetrics {
+	m := &promMetrics{
+		registry: prometheus.NewRegistry(),
+		analyses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gavel_analyses_total",
+			Help: "Analyses performed, by tier.",
+		}, []string{"tier"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gavel_analysis_errors_total",
+			Help: "Analyses that failed, by tier. Fast and comprehensive tier errors are provider errors.",
+		}, []string{"tier"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gavel_analysis_duration_seconds",
+			Help:    "Time spent analyzing a file, by tier.",
+			Buckets: []float64{0.001, 0.005, 0.025, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"tier"}),
+		findings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gavel_findings_total",
+			Help: "Findings reported, by tier.",
+		}, []string{"tier"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gavel_cache_lookups_total",
+			Help: "Analysis cache lookups, by tier and result (hit, miss or stale).",
+		}, []string{"tier", "result"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gavel_llm_tokens_total",
+			Help: "Estimated LLM tokens, by tier and direction (in or out).",
+		}, []string{"tier", "direction"}),
+	}
+	m.registry.MustRegister(
+		m.analyses, m.errors, m.duration, m.findings, m.cache, m.tokens,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+func (m *promMetrics) observe(e AnalysisEvent) {
+	tier := e.Tier.String()
+	m.analyses.WithLabelValues(tier).Inc()
+	if e.Error != "" {
+		m.errors.WithLabelValues(tier).Inc()
+	}
+	m.duration.WithLabelValues(tier).Observe(e.AnalysisDuration.Seconds())
+	m.findings.WithLabelValues(tier).Add(float64(e.FindingCount))
+	if e.CacheResult != "" {
+		m.cache.WithLabelValues(tier, string(e.CacheResult)).Inc()
+	}
+	if e.TokensIn > 0 {
+		m.tokens.WithLabelValues(tier, "in").Add(float64(e.TokensIn))
+	}
+	if e.TokensOut > 0 {
+		m.tokens.WithLabelValues(tier, "out").Add(float64(e.TokensOut))
+	}
+}
+
+// PrometheusHandler serves the collector's metrics in the Prometheus
+// exposition format. It returns nil unless the collector was created with
+// WithPrometheus.
+func (c *Collector) PrometheusHandler() http.Handler {
+	if c.prom == nil {
+		return nil
+	}
+	return promhttp.HandlerFor(c.prom.registry, promhttp.HandlerOpts{})
+}
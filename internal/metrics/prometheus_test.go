@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, c *Collector) string {
+	t.Helper()
+	h := c.PrometheusHandler()
+	if h == nil {
+		t.Fatal("expected a Prometheus handler")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestPrometheus_Observe(t *testing.T) {
+	c := NewCollector(WithPrometheus(), WithMaxEvents(10))
+	for i := 0; i < 20; i++ {
+		c.Record(AnalysisEvent{Tier: TierInstant, AnalysisDuration: time.Millisecond, FindingCount: 1, CacheResult: CacheMiss})
+	}
+	c.Record(AnalysisEvent{
+		Tier:             TierComprehensive,
+		AnalysisDuration: 2 * time.Second,
+		TokensIn:         1200,
+		TokensOut:        300,
+		CacheResult:      CacheHit,
+	})
+	c.Record(AnalysisEvent{Tier: TierFast, Error: "timeout"})
+
+	body := scrape(t, c)
+	for _, want := range []string{
+		`gavel_analyses_total{tier="instant"} 20`,
+		`gavel_findings_total{tier="instant"} 20`,
+		`gavel_analysis_errors_total{tier="fast"} 1`,
+		`gavel_analysis_duration_seconds_count{tier="comprehensive"} 1`,
+		`gavel_cache_lookups_total{result="hit",tier="comprehensive"} 1`,
+		`gavel_cache_lookups_total{result="miss",tier="instant"} 20`,
+		`gavel_llm_tokens_total{direction="in",tier="comprehensive"} 1200`,
+		`gavel_llm_tokens_total{direction="out",tier="comprehensive"} 300`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape missing %q", want)
+		}
+	}
+}
+
+func TestPrometheus_Disabled(t *testing.T) {
+	if NewCollector().PrometheusHandler() != nil {
+		t.Error("expected no handler without WithPrometheus")
+	}
+}
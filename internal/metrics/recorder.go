@@ -89,6 +89,24 @@ func (b *AnalysisBuilder) WithCacheResult(result CacheResult, key string) *Analy
 	return b
 }
 
+// WithCost records the estimated cost of the analysis in US dollars
+func (b *AnalysisBuilder) WithCost(usd float64) *AnalysisBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.event.CostUSD = usd
+	return b
+}
+
+// WithTenant records the tenant the analysis was made for
+func (b *AnalysisBuilder) WithTenant(tenant string) *AnalysisBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.event.Tenant = tenant
+	return b
+}
+
 // MarkStarted marks the analysis as started (dequeued)
 func (b *AnalysisBuilder) MarkStarted() *AnalysisBuilder {
 	b.timing.Start()
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+// gateRuleSummaries describes the gate rules of the default and built-in
+// verdict policies in words. Rules of custom policies are shown by name.
+var gateRuleSummaries = map[string]string{
+	"reject-high-confidence-error": "an error-level finding is above the reject confidence",
+	"reject-error":                 "an error-level finding is present",
+	"reject-warnings":              "too many warning-level findings",
+	"reject-cwe":                   "a finding is in a rejected CWE class",
+	"finding-threshold":            "more findings than --fail-on/--max-findings allow",
+	"category-threshold":           "a category is over its gate threshold",
+	"merge-no-actionable-results":  "no actionable findings",
+	"default-review":               "findings need a look, but none met a reject rule",
+	"unknown":                      "the policy does not record why",
+}
+
+// verdictExplanation is a verdict's decision trace prepared for display.
+type verdictExplanation struct {
+	Rule       string
+	Summary    string
+	AlsoFired  []string
+	Thresholds []store.TraceThreshold
+	Blocking   []store.TraceTrigger
+}
+
+// explainVerdict returns the explanation of v, or nil when v carries no
+// decision trace.
+func explainVerdict(v *store.Verdict) *verdictExplanation {
+	if v == nil || v.Trace == nil {
+		return nil
+	}
+	t := v.Trace
+	e := &verdictExplanation{
+		Rule:       t.Rule,
+		Summary:    gateRuleSummaries[t.Rule],
+		Thresholds: t.Thresholds,
+		Blocking:   t.Triggers,
+	}
+	if t.Rule == "reject-high-confidence-error" && t.RejectConfidenceThreshold > 0 {
+		e.Summary = fmt.Sprintf("an error-level finding has confidence above %.2f", t.RejectConfidenceThreshold)
+	}
+	for _, r := range t.Fired {
+		if r != t.Rule {
+			e.AlsoFired = append(e.AlsoFired, r)
+		}
+	}
+	return e
+}
+
+// triggerLocation returns "file:line", "file", or "" for a trigger.
+func triggerLocation(t store.TraceTrigger) string {
+	switch {
+	case t.File != "" && t.Line > 0:
+		return fmt.Sprintf("%s:%d", t.File, t.Line)
+	default:
+		return t.File
+	}
+}
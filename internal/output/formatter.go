@@ -1,10 +1,10 @@
 // Package output provides formatters for rendering Gavel analysis results
-// in different output formats (JSON, SARIF, Markdown, pretty terminal).
+// in different output formats (JSON, SARIF, Markdown, pretty terminal,
+// GitLab Code Quality, JUnit XML, HTML, and Go templates). Further formats can be added
+// with Register.
 package output
 
 import (
-	"fmt"
-
 	"github.com/chris-regnier/gavel/internal/analyzer"
 	"github.com/chris-regnier/gavel/internal/sarif"
 	"github.com/chris-regnier/gavel/internal/store"
@@ -23,6 +23,49 @@ type AnalysisOutput struct {
 	Stats    *analyzer.TieredAnalyzerStats // optional, nil if not collected
 }
 
+// FindingsSummary tallies findings by severity for machine-readable
+// formats. It is always populated, so consumers can key off Total == 0
+// as an explicit "no findings" signal rather than an absent field.
+type FindingsSummary struct {
+	Total    int `json:"total"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Notes    int `json:"notes"`
+	Files    int `json:"files"`
+}
+
+// logResults returns the results of the first run in log, or an empty
+// (non-nil) slice when there are none so JSON encodes it as [].
+func logResults(log *sarif.Log) []sarif.Result {
+	if log == nil || len(log.Runs) == 0 || log.Runs[0].Results == nil {
+		return []sarif.Result{}
+	}
+	return log.Runs[0].Results
+}
+
+// summarizeResults counts results by level and distinct file.
+func summarizeResults(results []sarif.Result) FindingsSummary {
+	s := FindingsSummary{Total: len(results)}
+	files := make(map[string]struct{})
+	for _, r := range results {
+		switch r.Level {
+		case "error":
+			s.Errors++
+		case "warning":
+			s.Warnings++
+		case "note":
+			s.Notes++
+		}
+		if len(r.Locations) > 0 {
+			if uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "" {
+				files[uri] = struct{}{}
+			}
+		}
+	}
+	s.Files = len(files)
+	return s
+}
+
 // ResolveFormat determines the output format to use. If flagValue is non-empty,
 // it is returned directly. Otherwise, "pretty" is returned for TTY output and
 // "json" for non-TTY (piped) output.
@@ -35,21 +78,3 @@ func ResolveFormat(flagValue string, stdoutIsTTY bool) string {
 	}
 	return "json"
 }
-
-// NewFormatter returns a Formatter for the given format name.
-// Supported formats: "json", "sarif", "markdown", "pretty".
-// Returns an error for unknown format names.
-func NewFormatter(format string) (Formatter, error) {
-	switch format {
-	case "json":
-		return &JSONFormatter{}, nil
-	case "sarif":
-		return &SARIFFormatter{}, nil
-	case "markdown":
-		return &MarkdownFormatter{}, nil
-	case "pretty":
-		return &PrettyFormatter{}, nil
-	default:
-		return nil, fmt.Errorf("unknown output format: %q (supported: json, sarif, markdown, pretty)", format)
-	}
-}
@@ -1,7 +1,11 @@
 package output
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/chris-regnier/gavel/internal/store"
 )
 
 // --- ResolveFormat tests ---
@@ -54,7 +58,7 @@ func TestResolveFormat_AutoDetect(t *testing.T) {
 // --- NewFormatter tests ---
 
 func TestNewFormatter_ValidFormats(t *testing.T) {
-	validFormats := []string{"json", "sarif", "markdown", "pretty"}
+	validFormats := []string{"json", "sarif", "markdown", "pretty", "gitlab", "junit", "html"}
 	for _, f := range validFormats {
 		t.Run(f, func(t *testing.T) {
 			formatter, err := NewFormatter(f)
@@ -69,7 +73,7 @@ func TestNewFormatter_ValidFormats(t *testing.T) {
 }
 
 func TestNewFormatter_InvalidFormat(t *testing.T) {
-	invalidFormats := []string{"xml", "csv", "html", "", "unknown"}
+	invalidFormats := []string{"xml", "csv", "", "unknown"}
 	for _, f := range invalidFormats {
 		t.Run(f, func(t *testing.T) {
 			formatter, err := NewFormatter(f)
@@ -82,3 +86,83 @@ func TestNewFormatter_InvalidFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFormatter_TemplateRequiresFile(t *testing.T) {
+	if _, err := NewFormatter("template"); err == nil {
+		t.Fatal("NewFormatter(\"template\") without a template file expected error, got nil")
+	}
+}
+
+// --- Register tests ---
+
+type staticFormatter string
+
+func (f staticFormatter) Format(*AnalysisOutput) ([]byte, error) {
+	return []byte(f), nil
+}
+
+func TestRegister(t *testing.T) {
+	err := Register("test-static", func(o FormatterConfig) (Formatter, error) {
+		return staticFormatter("sorted by " + string(o.SortBy)), nil
+	})
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "test-static")
+		registryMu.Unlock()
+	})
+
+	formatter, err := NewFormatter("test-static", WithSortBy(SortPriority))
+	if err != nil {
+		t.Fatalf("NewFormatter returned error: %v", err)
+	}
+	out, _ := formatter.Format(&AnalysisOutput{})
+	if string(out) != "sorted by priority" {
+		t.Errorf("Format() = %q, want the registered formatter's output with options applied", out)
+	}
+
+	found := false
+	for _, name := range Formats() {
+		found = found || name == "test-static"
+	}
+	if !found {
+		t.Errorf("Formats() = %v, want it to include test-static", Formats())
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	factory := func(FormatterConfig) (Formatter, error) { return staticFormatter(""), nil }
+	if err := Register("json", factory); err == nil {
+		t.Error("Register(\"json\") expected error for a built-in format, got nil")
+	}
+	if err := Register("", factory); err == nil {
+		t.Error("Register(\"\") expected error, got nil")
+	}
+}
+
+func TestNewFormatter_TemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "slack.tmpl"), []byte("{{.Verdict.Decision}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	formatter, err := NewFormatter("slack", WithTemplateDir(dir))
+	if err != nil {
+		t.Fatalf("NewFormatter(\"slack\") returned error: %v", err)
+	}
+	out, err := formatter.Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "merge"}})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if string(out) != "merge" {
+		t.Errorf("Format() = %q, want %q", out, "merge")
+	}
+
+	for _, name := range []string{"teams", "../slack", "slack.tmpl"} {
+		if _, err := NewFormatter(name, WithTemplateDir(dir)); err == nil {
+			t.Errorf("NewFormatter(%q) expected error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// GitLabFormatter renders findings as a GitLab Code Quality report, the
+// JSON artifact GitLab CI shows in merge request widgets and diffs
+// (artifacts:reports:codequality). Suppressed and informational findings
+// and findings the baseline marks absent are left out. A Taxonomy filter
+// drops the findings it does not select.
+type GitLabFormatter struct {
+	Taxonomy TaxonomyFilter
+}
+
+// codeQualityIssue is one entry of a GitLab Code Quality report.
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// Format produces the report as an indented JSON array, empty when there
+// are no findings.
+func (f *GitLabFormatter) Format(result *AnalysisOutput) ([]byte, error) {
+	if result == nil || result.SARIFLog == nil {
+		return nil, fmt.Errorf("gitlab formatter: SARIF log is required")
+	}
+	result = redactOutput(f.Taxonomy.filterOutput(result))
+
+	issues := []codeQualityIssue{}
+	seen := make(map[string]int)
+	for _, run := range result.SARIFLog.Runs {
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 || r.BaselineState == sarif.BaselineStateAbsent || r.Kind == sarif.KindInformational {
+				continue
+			}
+			file := codeQualityPath(resultFilePath(r))
+			begin, end := 1, 0
+			if len(r.Locations) > 0 {
+				region := r.Locations[0].PhysicalLocation.Region
+				if region.StartLine > 0 {
+					begin = region.StartLine
+				}
+				if region.EndLine > begin {
+					end = region.EndLine
+				}
+			}
+
+			// GitLab matches issues between pipelines by fingerprint, so it
+			// must be unique within a report and should survive line shifts.
+			fp := codeQualityFingerprint(r, file)
+			seen[fp]++
+			if n := seen[fp]; n > 1 {
+				fp = codeQualityHash(fp, fmt.Sprint(n))
+			}
+
+			description := r.Message.Text
+			if rec := resultRecommendation(r); rec != "" {
+				description += " " + rec
+			}
+			issues = append(issues, codeQualityIssue{
+				Description: description,
+				CheckName:   r.RuleID,
+				Fingerprint: fp,
+				Severity:    codeQualitySeverity(r.Level),
+				Location: codeQualityLocation{
+					Path:  file,
+					Lines: codeQualityLines{Begin: begin, End: end},
+				},
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("gitlab formatter: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// codeQualitySeverity maps a SARIF level to a Code Quality severity.
+func codeQualitySeverity(level string) string {
+	switch level {
+	case "error":
+		return "critical"
+	case "warning":
+		return "major"
+	case "note":
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+// codeQualityPath converts a SARIF artifact URI to the repository-relative
+// path GitLab expects.
+func codeQualityPath(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	p := strings.TrimPrefix(uri, "file://")
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+}
+
+// codeQualityFingerprint keys r by file and its content fingerprint when
+// one was recorded, falling back to its line and message.
+func codeQualityFingerprint(r sarif.Result, file string) string {
+	if fp := r.Fingerprints[sarif.ContentFingerprintV1]; fp != "" {
+		return codeQualityHash(r.RuleID, file, fp)
+	}
+	line := 0
+	if len(r.Locations) > 0 {
+		line = r.Locations[0].PhysicalLocation.Region.StartLine
+	}
+	return codeQualityHash(r.RuleID, file, fmt.Sprint(line), r.Message.Text)
+}
+
+func codeQualityHash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return fmt.Sprintf("%x", sum[:16])
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func gitLabResult(ruleID, level, uri string, start, end int, msg string) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   level,
+		Message: sarif.Message{Text: msg},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+			Region:           sarif.Region{StartLine: start, EndLine: end},
+		}}},
+	}
+}
+
+func TestGitLabFormatter_Format(t *testing.T) {
+	secret := gitLabResult("S2068", "error", "./internal/auth.go", 12, 14, "Hardcoded password")
+	secret.Fingerprints = map[string]string{sarif.ContentFingerprintV1: "abc"}
+	// The same snippet elsewhere in the file shares a content fingerprint.
+	secretCopy := gitLabResult("S2068", "error", "internal/auth.go", 40, 40, "Hardcoded password")
+	secretCopy.Fingerprints = map[string]string{sarif.ContentFingerprintV1: "abc"}
+	suppressed := gitLabResult("S1135", "note", "main.go", 3, 3, "TODO")
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	fixed := gitLabResult("S109", "warning", "main.go", 9, 9, "Magic number")
+	fixed.BaselineState = sarif.BaselineStateAbsent
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		secret,
+		secretCopy,
+		gitLabResult("error-handling", "warning", "main.go", 7, 7, "Error ignored"),
+		suppressed,
+		fixed,
+	}
+
+	out, err := (&GitLabFormatter{}).Format(&AnalysisOutput{SARIFLog: log})
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, out)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %d: %s", len(issues), out)
+	}
+
+	first := issues[0]
+	if first.CheckName != "S2068" || first.Severity != "critical" {
+		t.Errorf("check/severity = %s/%s", first.CheckName, first.Severity)
+	}
+	if first.Location.Path != "internal/auth.go" || first.Location.Lines != (codeQualityLines{Begin: 12, End: 14}) {
+		t.Errorf("location = %+v", first.Location)
+	}
+	if issues[1].Location.Lines != (codeQualityLines{Begin: 40}) {
+		t.Errorf("single-line issue lines = %+v", issues[1].Location.Lines)
+	}
+	if issues[2].Severity != "major" {
+		t.Errorf("warning severity = %s, want major", issues[2].Severity)
+	}
+
+	fps := map[string]bool{}
+	for _, is := range issues {
+		if is.Fingerprint == "" || fps[is.Fingerprint] {
+			t.Errorf("fingerprint %q is empty or repeated", is.Fingerprint)
+		}
+		fps[is.Fingerprint] = true
+	}
+
+	// Moving a finding with a content fingerprint keeps its fingerprint.
+	moved := secret
+	moved.Locations = []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+		ArtifactLocation: sarif.ArtifactLocation{URI: "internal/auth.go"},
+		Region:           sarif.Region{StartLine: 20, EndLine: 22},
+	}}}
+	if codeQualityFingerprint(moved, "internal/auth.go") != first.Fingerprint {
+		t.Error("fingerprint changed when the finding moved")
+	}
+}
+
+func TestGitLabFormatter_Empty(t *testing.T) {
+	out, err := (&GitLabFormatter{}).Format(&AnalysisOutput{SARIFLog: sarif.NewLog("gavel", "test")})
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if string(out) != "[]\n" {
+		t.Errorf("empty report = %q, want []", out)
+	}
+	if _, err := (&GitLabFormatter{}).Format(&AnalysisOutput{}); err == nil {
+		t.Error("expected an error without a SARIF log")
+	}
+}
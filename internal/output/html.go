@@ -0,0 +1,153 @@
+package output
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"sort"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+//go:embed html.tmpl
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// HTMLFormatter renders analysis output as a standalone HTML report, with
+// its CSS and script inline, for audits where no SARIF viewer is at hand.
+// The report opens with the verdict, groups findings by file, and lets the
+// reader filter them by severity and expand each one's explanation,
+// recommendation and CWE and OWASP links. Suppressed findings are shown,
+// marked and hidden by default; findings the baseline marks absent are
+// left out. Findings are ordered by line, or with SortBy set to
+// SortPriority, as PrettyFormatter orders them. A Taxonomy filter drops the
+// findings it does not select.
+type HTMLFormatter struct {
+	SortBy   SortMode
+	Taxonomy TaxonomyFilter
+}
+
+// htmlData is what html.tmpl renders.
+type htmlData struct {
+	Verdict *store.Verdict
+	Tool    string
+	Summary FindingsSummary
+	Files   []htmlFile
+}
+
+type htmlFile struct {
+	Path     string
+	Findings []htmlFinding
+}
+
+type htmlFinding struct {
+	RuleID         string
+	Level          string
+	Lines          string
+	Message        string
+	Confidence     string
+	Explanation    string
+	Recommendation string
+	Links          []htmlLink
+	Suppressed     bool
+}
+
+type htmlLink struct {
+	Text string
+	URL  string
+}
+
+// Format produces the report.
+func (f *HTMLFormatter) Format(result *AnalysisOutput) ([]byte, error) {
+	if result == nil || result.SARIFLog == nil {
+		return nil, fmt.Errorf("html formatter: SARIF log is required")
+	}
+	result = redactOutput(f.Taxonomy.filterOutput(result))
+
+	var results []sarif.Result
+	for _, r := range logResults(result.SARIFLog) {
+		if r.BaselineState != sarif.BaselineStateAbsent {
+			results = append(results, r)
+		}
+	}
+
+	data := htmlData{Verdict: result.Verdict, Summary: summarizeResults(results)}
+	if len(result.SARIFLog.Runs) > 0 {
+		driver := result.SARIFLog.Runs[0].Tool.Driver
+		data.Tool = driver.Name
+		if driver.Version != "" {
+			data.Tool += " " + driver.Version
+		}
+	}
+
+	byFile := make(map[string][]sarif.Result)
+	for _, r := range results {
+		file := resultFilePath(r)
+		byFile[file] = append(byFile[file], r)
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	if f.SortBy == SortPriority {
+		for file, fr := range byFile {
+			byFile[file] = SortByPriority(fr)
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			return priorityLess(byFile[files[i]][0], byFile[files[j]][0])
+		})
+	} else {
+		for _, fr := range byFile {
+			sort.SliceStable(fr, func(i, j int) bool {
+				return prettyStartLine(fr[i]) < prettyStartLine(fr[j])
+			})
+		}
+	}
+
+	for _, file := range files {
+		hf := htmlFile{Path: file}
+		if hf.Path == "" {
+			hf.Path = "(no file)"
+		}
+		for _, r := range byFile[file] {
+			hf.Findings = append(hf.Findings, newHTMLFinding(r))
+		}
+		data.Files = append(data.Files, hf)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("html formatter: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newHTMLFinding(r sarif.Result) htmlFinding {
+	hf := htmlFinding{
+		RuleID:         r.RuleID,
+		Level:          r.Level,
+		Lines:          resultLineRange(r),
+		Message:        r.Message.Text,
+		Confidence:     resultConfidence(r),
+		Explanation:    stringProperty(r, "gavel/explanation"),
+		Recommendation: resultRecommendation(r),
+		Suppressed:     len(r.Suppressions) > 0,
+	}
+	for _, id := range TaxonomyCWE.IDs(r) {
+		hf.Links = append(hf.Links, htmlLink{Text: id, URL: rules.CWEURL(id)})
+	}
+	for _, id := range TaxonomyOWASP.IDs(r) {
+		hf.Links = append(hf.Links, htmlLink{Text: "OWASP " + id, URL: rules.OWASPURL(id)})
+	}
+	return hf
+}
+
+func stringProperty(r sarif.Result, key string) string {
+	s, _ := r.Properties[key].(string)
+	return s
+}
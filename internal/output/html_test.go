@@ -0,0 +1,97 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+func TestHTMLFormatter_Format(t *testing.T) {
+	log := testMarkdownLog()
+	log.Runs[0].Results[0].Properties["gavel/cwe"] = []string{"CWE-798"}
+	log.Runs[0].Results[0].Properties["gavel/owasp"] = []interface{}{"A07:2021"}
+	log.Runs[0].Results[0].Properties["gavel/explanation"] = "Anyone with the source has the <password>."
+
+	out, err := (&HTMLFormatter{}).Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "reject", Reason: "high-confidence error"},
+		SARIFLog: log,
+	})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	html := string(out)
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<div class="banner reject"><strong>reject</strong>high-confidence error</div>`,
+		"<summary>config/db.go",
+		`data-level="error"`,
+		`<a href="https://cwe.mitre.org/data/definitions/798.html"`,
+		`<a href="https://owasp.org/Top10/A07_2021-Identification_and_Authentication_Failures/"`,
+		"Anyone with the source has the &lt;password&gt;.",
+		"Use environment variables or a secrets manager.",
+		"confidence 0.95",
+		`data-filter="warning"`,
+		"<style>",
+		"<script>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<link") || strings.Contains(html, "src=") {
+		t.Error("report should not load external resources")
+	}
+}
+
+func TestHTMLFormatter_GroupsAndOrders(t *testing.T) {
+	log := sarif.NewLog("gavel", "test")
+	suppressed := gitLabResult("S1135", "note", "b.go", 30, 30, "TODO")
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	fixed := gitLabResult("S109", "warning", "b.go", 9, 9, "Magic number")
+	fixed.BaselineState = sarif.BaselineStateAbsent
+	log.Runs[0].Results = []sarif.Result{
+		gitLabResult("late", "warning", "b.go", 20, 20, "second in b.go"),
+		gitLabResult("early", "warning", "b.go", 2, 2, "first in b.go"),
+		gitLabResult("only", "error", "a.go", 5, 5, "in a.go"),
+		suppressed,
+		fixed,
+	}
+
+	out, err := (&HTMLFormatter{}).Format(&AnalysisOutput{SARIFLog: log})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	html := string(out)
+
+	if strings.Contains(html, "Magic number") {
+		t.Error("report lists a finding the baseline marks absent")
+	}
+	if !strings.Contains(html, "data-suppressed") {
+		t.Error("report does not mark the suppressed finding")
+	}
+	if strings.Contains(html, `class="banner`) {
+		t.Error("report shows a verdict banner without a verdict")
+	}
+	order := []string{"in a.go", "first in b.go", "second in b.go", "TODO"}
+	last := -1
+	for _, msg := range order {
+		i := strings.Index(html, msg)
+		if i < last {
+			t.Errorf("%q is out of order; want files by path and findings by line", msg)
+		}
+		last = i
+	}
+}
+
+func TestHTMLFormatter_NoFindings(t *testing.T) {
+	out, err := (&HTMLFormatter{}).Format(&AnalysisOutput{SARIFLog: sarif.NewLog("gavel", "test")})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if !strings.Contains(string(out), "<p>No findings.</p>") {
+		t.Errorf("report for a clean run does not say so:\n%s", out)
+	}
+}
@@ -3,10 +3,28 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
 )
 
-// JSONFormatter renders analysis output as indented JSON of the verdict.
-type JSONFormatter struct{}
+// JSONFormatter renders analysis output as indented JSON of the verdict,
+// alongside the findings and a severity summary. With a Taxonomy filter the
+// findings are narrowed to it and grouped under "taxonomy".
+type JSONFormatter struct {
+	Taxonomy TaxonomyFilter
+}
+
+// jsonPayload is the document emitted by JSONFormatter. The verdict's
+// fields are inlined at the top level; Findings and Summary are always
+// present so a clean run serializes as {"findings":[],"summary":{...}}
+// rather than an ambiguous empty payload.
+type jsonPayload struct {
+	*store.Verdict
+	Findings []sarif.Result  `json:"findings"`
+	Summary  FindingsSummary `json:"summary"`
+	Taxonomy []TaxonomyGroup `json:"taxonomy,omitempty"`
+}
 
 // Format serializes the verdict as pretty-printed JSON with a trailing newline
 // for shell friendliness (e.g. piping to jq).
@@ -14,7 +32,15 @@ func (f *JSONFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 	if result == nil || result.Verdict == nil {
 		return nil, fmt.Errorf("json formatter: verdict is required")
 	}
-	data, err := json.MarshalIndent(result.Verdict, "", "  ")
+	result = redactOutput(f.Taxonomy.filterOutput(result))
+	findings := logResults(result.SARIFLog)
+	payload := jsonPayload{
+		Verdict:  result.Verdict,
+		Findings: findings,
+		Summary:  summarizeResults(findings),
+		Taxonomy: f.Taxonomy.Group(findings),
+	}
+	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
 		return nil, err
 	}
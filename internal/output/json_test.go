@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/chris-regnier/gavel/internal/sarif"
 	"github.com/chris-regnier/gavel/internal/store"
 )
 
@@ -153,3 +154,71 @@ func TestJSONFormatter_WithMetadata(t *testing.T) {
 		t.Errorf("metadata.model = %q, want %q", meta["model"], "claude-sonnet-4")
 	}
 }
+
+func TestJSONFormatter_NoFindings(t *testing.T) {
+	f := &JSONFormatter{}
+
+	for _, tc := range []struct {
+		name string
+		log  *sarif.Log
+	}{
+		{"nil SARIF log", nil},
+		{"run with nil results", &sarif.Log{Runs: []sarif.Run{{}}}},
+		{"run with empty results", &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{}}}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := f.Format(&AnalysisOutput{
+				Verdict:  &store.Verdict{Decision: "merge", Reason: "no findings"},
+				SARIFLog: tc.log,
+			})
+			if err != nil {
+				t.Fatalf("Format() returned error: %v", err)
+			}
+
+			var parsed map[string]json.RawMessage
+			if err := json.Unmarshal(out, &parsed); err != nil {
+				t.Fatalf("output is not valid JSON: %v\noutput: %s", err, out)
+			}
+			if got := string(parsed["findings"]); got != "[]" {
+				t.Errorf("findings = %s, want []", got)
+			}
+			if got := string(parsed["decision"]); got != `"merge"` {
+				t.Errorf("decision = %s, want \"merge\"", got)
+			}
+
+			var summary FindingsSummary
+			if err := json.Unmarshal(parsed["summary"], &summary); err != nil {
+				t.Fatalf("summary is missing or malformed: %v", err)
+			}
+			if summary != (FindingsSummary{}) {
+				t.Errorf("summary = %+v, want all zero counts", summary)
+			}
+		})
+	}
+}
+
+func TestJSONFormatter_SummaryCounts(t *testing.T) {
+	f := &JSONFormatter{}
+	out, err := f.Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "reject"},
+		SARIFLog: testSARIFLog(),
+	})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+
+	var parsed struct {
+		Findings []sarif.Result  `json:"findings"`
+		Summary  FindingsSummary `json:"summary"`
+	}
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(parsed.Findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(parsed.Findings))
+	}
+	want := FindingsSummary{Total: 1, Errors: 1, Files: 1}
+	if parsed.Summary != want {
+		t.Errorf("summary = %+v, want %+v", parsed.Summary, want)
+	}
+}
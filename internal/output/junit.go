@@ -0,0 +1,166 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// JUnitFormatter renders findings as a JUnit XML report, which Jenkins,
+// GitLab and CircleCI show in their test report views. Each file is a
+// <testsuite> and each finding a <testcase> with a <failure>; suppressed
+// findings are reported as skipped, and findings the baseline marks absent
+// are left out. A Taxonomy filter drops the findings it does not select.
+type JUnitFormatter struct {
+	Taxonomy TaxonomyFilter
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// junitNoFile names the suite of findings that have no location.
+const junitNoFile = "gavel"
+
+// Format produces the report, with suites ordered by file and test cases
+// by line. A run without findings yields an empty <testsuites>.
+func (f *JUnitFormatter) Format(result *AnalysisOutput) ([]byte, error) {
+	if result == nil || result.SARIFLog == nil {
+		return nil, fmt.Errorf("junit formatter: SARIF log is required")
+	}
+	result = redactOutput(f.Taxonomy.filterOutput(result))
+
+	byFile := make(map[string][]sarif.Result)
+	for _, run := range result.SARIFLog.Runs {
+		for _, r := range run.Results {
+			if r.BaselineState == sarif.BaselineStateAbsent {
+				continue
+			}
+			file := codeQualityPath(resultFilePath(r))
+			if file == "" {
+				file = junitNoFile
+			}
+			byFile[file] = append(byFile[file], r)
+		}
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	report := junitTestSuites{Name: "gavel"}
+	for _, file := range files {
+		results := byFile[file]
+		sort.SliceStable(results, func(i, j int) bool {
+			return prettyStartLine(results[i]) < prettyStartLine(results[j])
+		})
+
+		suite := junitTestSuite{Name: file}
+		for _, r := range results {
+			tc := junitTestCase{
+				Name:      junitCaseName(r),
+				Classname: file,
+				Line:      prettyStartLine(r),
+			}
+			if file != junitNoFile {
+				tc.File = file
+			}
+			if len(r.Suppressions) > 0 {
+				tc.Skipped = &junitSkipped{Message: junitSkipMessage(r.Suppressions[0])}
+				suite.Skipped++
+			} else {
+				tc.Failure = &junitFailure{
+					Message: r.Message.Text,
+					Type:    r.Level,
+					Text:    junitFailureText(r, file),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("junit formatter: %w", err)
+	}
+	out := append([]byte(xml.Header), data...)
+	return append(out, '\n'), nil
+}
+
+// junitCaseName names a test case by rule and line, e.g. "S2068 (line 12)".
+func junitCaseName(r sarif.Result) string {
+	if line := prettyStartLine(r); line > 0 {
+		return fmt.Sprintf("%s (line %d)", r.RuleID, line)
+	}
+	return r.RuleID
+}
+
+func junitSkipMessage(s sarif.SARIFSuppression) string {
+	if s.Justification != "" {
+		return "suppressed: " + s.Justification
+	}
+	return "suppressed"
+}
+
+// junitFailureText is the failure body test report views show expanded:
+// the location, confidence, explanation and recommendation.
+func junitFailureText(r sarif.Result, file string) string {
+	var lines []string
+	location := file
+	if lr := resultLineRange(r); lr != "" && file != junitNoFile {
+		location += ":" + lr
+	}
+	lines = append(lines, fmt.Sprintf("%s [%s] %s", r.RuleID, r.Level, location))
+	if c := resultConfidence(r); c != "" {
+		lines = append(lines, "Confidence: "+c)
+	}
+	if explanation, ok := r.Properties["gavel/explanation"].(string); ok && explanation != "" {
+		lines = append(lines, "", explanation)
+	}
+	if rec := resultRecommendation(r); rec != "" {
+		lines = append(lines, "", "Recommendation: "+rec)
+	}
+	return strings.Join(lines, "\n")
+}
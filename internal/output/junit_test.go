@@ -0,0 +1,111 @@
+package output
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func TestJUnitFormatter_Format(t *testing.T) {
+	secret := gitLabResult("S2068", "error", "./internal/auth.go", 12, 14, "Hardcoded password")
+	secret.Properties = map[string]interface{}{
+		"gavel/confidence":     0.95,
+		"gavel/recommendation": "Read it from the environment.",
+	}
+	suppressed := gitLabResult("S1135", "note", "main.go", 3, 3, "TODO")
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external", Justification: "tracked in #12"}}
+	fixed := gitLabResult("S109", "warning", "main.go", 9, 9, "Magic number")
+	fixed.BaselineState = sarif.BaselineStateAbsent
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		gitLabResult("error-handling", "warning", "main.go", 7, 7, "Error ignored"),
+		secret,
+		suppressed,
+		fixed,
+	}
+
+	out, err := (&JUnitFormatter{}).Format(&AnalysisOutput{SARIFLog: log})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if !strings.HasPrefix(string(out), xml.Header) {
+		t.Errorf("output does not start with the XML header:\n%s", out)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(out, &report); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, out)
+	}
+	if report.Tests != 3 || report.Failures != 2 || report.Skipped != 1 {
+		t.Errorf("totals = %d tests, %d failures, %d skipped; want 3, 2, 1", report.Tests, report.Failures, report.Skipped)
+	}
+	if len(report.Suites) != 2 || report.Suites[0].Name != "internal/auth.go" || report.Suites[1].Name != "main.go" {
+		t.Fatalf("suites = %+v, want internal/auth.go then main.go", report.Suites)
+	}
+
+	auth := report.Suites[0].Cases[0]
+	if auth.Name != "S2068 (line 12)" || auth.Classname != "internal/auth.go" || auth.Line != 12 {
+		t.Errorf("auth test case = %+v", auth)
+	}
+	if auth.Failure == nil || auth.Failure.Type != "error" || auth.Failure.Message != "Hardcoded password" {
+		t.Fatalf("auth failure = %+v", auth.Failure)
+	}
+	for _, want := range []string{"internal/auth.go:12-14", "Confidence: 0.95", "Recommendation: Read it from the environment."} {
+		if !strings.Contains(auth.Failure.Text, want) {
+			t.Errorf("failure text %q does not contain %q", auth.Failure.Text, want)
+		}
+	}
+
+	// main.go's cases are ordered by line, the suppressed TODO first
+	main := report.Suites[1]
+	if main.Tests != 2 || main.Failures != 1 || main.Skipped != 1 {
+		t.Errorf("main.go suite = %d tests, %d failures, %d skipped; want 2, 1, 1", main.Tests, main.Failures, main.Skipped)
+	}
+	if main.Cases[0].Skipped == nil || main.Cases[0].Skipped.Message != "suppressed: tracked in #12" {
+		t.Errorf("suppressed case = %+v, want skipped with its justification", main.Cases[0])
+	}
+	if main.Cases[1].Failure == nil || main.Cases[1].Name != "error-handling (line 7)" {
+		t.Errorf("second main.go case = %+v", main.Cases[1])
+	}
+}
+
+func TestJUnitFormatter_NoFindings(t *testing.T) {
+	out, err := (&JUnitFormatter{}).Format(&AnalysisOutput{SARIFLog: sarif.NewLog("gavel", "test")})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(out, &report); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, out)
+	}
+	if report.Tests != 0 || len(report.Suites) != 0 {
+		t.Errorf("report = %+v, want an empty testsuites element", report)
+	}
+}
+
+func TestJUnitFormatter_NoLocation(t *testing.T) {
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{{RuleID: "project-wide", Level: "note", Message: sarif.Message{Text: "No LICENSE"}}}
+
+	out, err := (&JUnitFormatter{}).Format(&AnalysisOutput{SARIFLog: log})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(out, &report); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, out)
+	}
+	tc := report.Suites[0].Cases[0]
+	if report.Suites[0].Name != junitNoFile || tc.Name != "project-wide" || tc.File != "" || tc.Line != 0 {
+		t.Errorf("test case = %+v in suite %q", tc, report.Suites[0].Name)
+	}
+}
+
+func TestJUnitFormatter_RequiresLog(t *testing.T) {
+	if _, err := (&JUnitFormatter{}).Format(&AnalysisOutput{}); err == nil {
+		t.Error("expected error without a SARIF log, got nil")
+	}
+}
@@ -5,13 +5,21 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/chris-regnier/gavel/internal/codeowners"
 	"github.com/chris-regnier/gavel/internal/sarif"
 )
 
 // MarkdownFormatter renders analysis output as GitHub-Flavored Markdown
 // suitable for PR comments. Uses collapsible <details> sections for findings
-// and severity emojis for quick visual scanning.
-type MarkdownFormatter struct{}
+// and severity emojis for quick visual scanning. Findings are ordered by
+// severity then file, or by fix priority when SortBy is SortPriority.
+// Findings carrying CODEOWNERS owners are also tallied per owner. A
+// Taxonomy filter narrows the findings and adds a table grouping them by
+// taxonomy ID.
+type MarkdownFormatter struct {
+	SortBy   SortMode
+	Taxonomy TaxonomyFilter
+}
 
 // severityPriority returns a sort priority for SARIF severity levels.
 // Lower values sort first: error (0) > warning (1) > note (2).
@@ -56,6 +64,20 @@ func decisionBanner(decision string) string {
 	}
 }
 
+// decisionTitle returns the decision as a heading word, e.g. "Review".
+func decisionTitle(decision string) string {
+	switch decision {
+	case "merge":
+		return "Merge"
+	case "reject":
+		return "Reject"
+	case "review":
+		return "Review"
+	default:
+		return decision
+	}
+}
+
 // resultFilePath extracts the file URI from the first location of a SARIF result.
 func resultFilePath(r sarif.Result) string {
 	if len(r.Locations) > 0 {
@@ -109,6 +131,7 @@ func (f *MarkdownFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 	if result.Verdict == nil {
 		return nil, fmt.Errorf("markdown formatter: verdict is required")
 	}
+	result = redactOutput(f.Taxonomy.filterOutput(result))
 
 	var b strings.Builder
 
@@ -145,6 +168,10 @@ func (f *MarkdownFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 		len(results),
 		len(fileSet)))
 
+	if e := explainVerdict(result.Verdict); e != nil {
+		writeMarkdownExplanation(&b, result.Verdict.Decision, e)
+	}
+
 	if len(results) == 0 {
 		// No findings case.
 		b.WriteString("\nNo findings detected.\n")
@@ -161,16 +188,39 @@ func (f *MarkdownFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 			}
 		}
 
-		// Sort results: by severity priority first, then by file path.
-		sorted := make([]sarif.Result, len(results))
-		copy(sorted, results)
-		sort.SliceStable(sorted, func(i, j int) bool {
-			pi, pj := severityPriority(sorted[i].Level), severityPriority(sorted[j].Level)
-			if pi != pj {
-				return pi < pj
+		if groups := f.Taxonomy.Group(results); len(groups) > 0 {
+			b.WriteString(fmt.Sprintf("\n### Findings by %s\n", strings.ToUpper(string(f.Taxonomy.Taxonomy))))
+			b.WriteString("| Category | Count | Rules |\n")
+			b.WriteString("|----------|-------|-------|\n")
+			for _, g := range groups {
+				b.WriteString(fmt.Sprintf("| %s | %d | %s |\n", g.ID, g.Count, strings.Join(g.RuleIDs, ", ")))
 			}
-			return resultFilePath(sorted[i]) < resultFilePath(sorted[j])
-		})
+		}
+
+		if groups := codeowners.GroupResults(results); len(groups) > 0 {
+			b.WriteString("\n### Findings by Owner\n")
+			b.WriteString("| Owner | Findings | Errors | Warnings | Notes |\n")
+			b.WriteString("|-------|----------|--------|----------|-------|\n")
+			for _, g := range groups {
+				b.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d |\n", g.Owner, g.Findings, g.Errors, g.Warnings, g.Notes))
+			}
+		}
+
+		// Sort results: by severity priority first, then by file path.
+		var sorted []sarif.Result
+		if f.SortBy == SortPriority {
+			sorted = SortByPriority(results)
+		} else {
+			sorted = make([]sarif.Result, len(results))
+			copy(sorted, results)
+			sort.SliceStable(sorted, func(i, j int) bool {
+				pi, pj := severityPriority(sorted[i].Level), severityPriority(sorted[j].Level)
+				if pi != pj {
+					return pi < pj
+				}
+				return resultFilePath(sorted[i]) < resultFilePath(sorted[j])
+			})
+		}
 
 		// Findings section.
 		b.WriteString("\n### Findings\n\n")
@@ -199,6 +249,14 @@ func (f *MarkdownFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 				b.WriteString(fmt.Sprintf("**Confidence:** %s\n", confidence))
 			}
 
+			if tier, ok := r.Properties["gavel/tier"].(string); ok && tier != "" {
+				b.WriteString(fmt.Sprintf("**Tier:** %s\n", tier))
+			}
+
+			if owners := codeowners.Of(r); len(owners) > 0 {
+				b.WriteString(fmt.Sprintf("**Owners:** %s\n", strings.Join(owners, " ")))
+			}
+
 			if fp != "" {
 				if lineRange != "" {
 					b.WriteString(fmt.Sprintf("**File:** `%s` lines %s\n", fp, lineRange))
@@ -229,6 +287,43 @@ func (f *MarkdownFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 	return []byte(b.String()), nil
 }
 
+// writeMarkdownExplanation writes the "Why" section: the gate rule that
+// decided, the thresholds it compared, and the findings that blocked.
+func writeMarkdownExplanation(b *strings.Builder, decision string, e *verdictExplanation) {
+	b.WriteString(fmt.Sprintf("\n### Why %s\n\n", decisionTitle(decision)))
+	if e.Summary != "" {
+		b.WriteString(fmt.Sprintf("**Rule:** `%s` — %s\n", e.Rule, e.Summary))
+	} else {
+		b.WriteString(fmt.Sprintf("**Rule:** `%s`\n", e.Rule))
+	}
+	if len(e.AlsoFired) > 0 {
+		b.WriteString(fmt.Sprintf("**Also fired:** `%s`\n", strings.Join(e.AlsoFired, "`, `")))
+	}
+
+	if len(e.Thresholds) > 0 {
+		b.WriteString("\n| Threshold | Count | Limit | |\n")
+		b.WriteString("|-----------|-------|-------|-|\n")
+		for _, t := range e.Thresholds {
+			state := ":white_check_mark: within limit"
+			if t.Exceeded {
+				state = ":x: exceeded"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %d | %d | %s |\n", t.Name, t.Count, t.Limit, state))
+		}
+	}
+
+	if len(e.Blocking) > 0 {
+		b.WriteString("\n**Blocking findings:**\n")
+		for _, t := range e.Blocking {
+			loc := ""
+			if l := triggerLocation(t); l != "" {
+				loc = fmt.Sprintf("`%s` ", l)
+			}
+			b.WriteString(fmt.Sprintf("- %s%s **%s** %s (%.2f): %s\n", loc, severityEmoji(t.Level), t.Level, t.RuleID, t.Confidence, truncate(t.Message, 120)))
+		}
+	}
+}
+
 // truncate shortens a string to maxLen characters, appending "..." if truncated.
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -35,6 +35,7 @@ func testMarkdownLog() *sarif.Log {
 					Properties: map[string]any{
 						"gavel/confidence":     0.95,
 						"gavel/recommendation": "Use environment variables or a secrets manager.",
+						"gavel/tier":           "instant",
 					},
 				},
 				{
@@ -243,6 +244,11 @@ func TestMarkdownFormatter_HasCollapsibleFindings(t *testing.T) {
 	if !strings.Contains(output, "Use environment variables or a secrets manager.") {
 		t.Error("output missing recommendation text")
 	}
+
+	// Check for the tier that reported the finding.
+	if !strings.Contains(output, "**Tier:** instant\n") {
+		t.Error("output missing tier label")
+	}
 }
 
 func TestMarkdownFormatter_SortsBySeverity(t *testing.T) {
@@ -445,3 +451,74 @@ func TestMarkdownFormatter_FileLocationInSummary(t *testing.T) {
 		t.Error("output missing file path internal/handler.go")
 	}
 }
+
+func TestMarkdownFormatter_ExplainsVerdict(t *testing.T) {
+	out, err := (&MarkdownFormatter{}).Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "reject", Trace: testTrace()},
+		SARIFLog: testMarkdownLog(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"### Why Reject",
+		"**Rule:** `reject-high-confidence-error` — an error-level finding has confidence above 0.85",
+		"**Also fired:** `finding-threshold`",
+		"| max_findings | 3 | 1 | :x: exceeded |",
+		"- `config/db.go:42` :red_circle: **error** SEC001 (0.95): Hardcoded secret detected",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMarkdownFormatter_GroupsByOwner(t *testing.T) {
+	log := testMarkdownLog()
+	log.Runs[0].Results[0].Properties["gavel/owners"] = []any{"@acme/backend", "@dev"}
+	log.Runs[0].Results[1].Properties["gavel/owners"] = []any{"@acme/backend"}
+
+	out, err := (&MarkdownFormatter{}).Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "review"}, SARIFLog: log})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+	md := string(out)
+	for _, want := range []string{
+		"### Findings by Owner",
+		"| @acme/backend | 2 | 1 | 1 | 0 |",
+		"| @dev | 1 | 1 | 0 | 0 |",
+		"| (unowned) | 3 | 0 | 2 | 1 |",
+		"**Owners:** @acme/backend @dev",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("output missing %q:\n%s", want, md)
+		}
+	}
+
+	plain, _ := (&MarkdownFormatter{}).Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "review"}, SARIFLog: testMarkdownLog()})
+	if strings.Contains(string(plain), "Findings by Owner") {
+		t.Error("expected no owner table without CODEOWNERS data")
+	}
+}
+
+func TestMarkdownFormatter_NoFindingsWithoutLog(t *testing.T) {
+	f := &MarkdownFormatter{}
+	out, err := f.Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "merge"}})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+	output := string(out)
+
+	if !strings.Contains(output, "## Gavel Analysis Summary") {
+		t.Error("output missing '## Gavel Analysis Summary' header when there is no SARIF log")
+	}
+	if !strings.Contains(output, ":white_check_mark: Merge") {
+		t.Error("output missing merge decision when there is no SARIF log")
+	}
+	if !strings.Contains(output, "No findings detected.") {
+		t.Error("output missing 'No findings detected.' text when there is no SARIF log")
+	}
+	if strings.Contains(output, "<details>") {
+		t.Error("output should not contain collapsible findings when there are none")
+	}
+}
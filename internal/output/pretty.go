@@ -8,20 +8,30 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/chris-regnier/gavel/internal/codeowners"
 	"github.com/chris-regnier/gavel/internal/sarif"
 )
 
 // PrettyFormatter renders analysis output as colored, human-readable
 // terminal output suitable for interactive use. Output is grouped by file,
 // sorted alphabetically, with findings sorted by line number within each file.
+// With SortBy set to SortPriority, files are instead ordered by their most
+// actionable finding and findings within a file by fix priority.
+// A Taxonomy filter drops the findings it does not select. Findings carrying
+// CODEOWNERS owners are tallied per owner after the file listing. Each
+// finding is labeled with the tier that reported it.
 // Respects the NO_COLOR environment variable (https://no-color.org/).
-type PrettyFormatter struct{}
+type PrettyFormatter struct {
+	SortBy   SortMode
+	Taxonomy TaxonomyFilter
+}
 
 // Format produces pretty terminal output from the analysis results.
 func (f *PrettyFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 	if result == nil {
 		return nil, fmt.Errorf("pretty formatter: result is required")
 	}
+	result = redactOutput(f.Taxonomy.filterOutput(result))
 
 	noColor := os.Getenv("NO_COLOR") != ""
 
@@ -81,6 +91,30 @@ func (f *PrettyFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 	if persona != "" {
 		fmt.Fprintf(&b, "  Persona: %s\n", persona)
 	}
+	if e := explainVerdict(result.Verdict); e != nil {
+		why := e.Rule
+		if e.Summary != "" {
+			why += " — " + e.Summary
+		}
+		fmt.Fprintf(&b, "  Why: %s\n", why)
+		if len(e.AlsoFired) > 0 {
+			fmt.Fprintf(&b, "  Also fired: %s\n", strings.Join(e.AlsoFired, ", "))
+		}
+		for _, t := range e.Thresholds {
+			state := dimStyle.Render("within limit")
+			if t.Exceeded {
+				state = errorStyle.Render("exceeded")
+			}
+			fmt.Fprintf(&b, "  Threshold: %s %d/%d %s\n", t.Name, t.Count, t.Limit, state)
+		}
+		if len(e.Blocking) > 0 {
+			b.WriteString("  Blocking findings:\n")
+			for _, t := range e.Blocking {
+				fmt.Fprintf(&b, "    %s  %s  %s  %s %s\n", triggerLocation(t), t.Level, t.RuleID, t.Message,
+					dimStyle.Render(fmt.Sprintf("(%.2f)", t.Confidence)))
+			}
+		}
+	}
 	b.WriteString("\n")
 
 	if len(results) == 0 {
@@ -100,16 +134,29 @@ func (f *PrettyFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 		}
 		sort.Strings(fileOrder)
 
+		if f.SortBy == SortPriority {
+			// Priority-sort each file's findings, then order files by
+			// their first (most actionable) finding.
+			for file, fr := range fileResults {
+				fileResults[file] = SortByPriority(fr)
+			}
+			sort.SliceStable(fileOrder, func(i, j int) bool {
+				return priorityLess(fileResults[fileOrder[i]][0], fileResults[fileOrder[j]][0])
+			})
+		}
+
 		for _, file := range fileOrder {
 			b.WriteString("  " + fileStyle.Render(file) + "\n")
 
-			// Sort findings by start line within this file.
 			fr := fileResults[file]
-			sort.Slice(fr, func(i, j int) bool {
-				li := prettyStartLine(fr[i])
-				lj := prettyStartLine(fr[j])
-				return li < lj
-			})
+			if f.SortBy != SortPriority {
+				// Sort findings by start line within this file.
+				sort.Slice(fr, func(i, j int) bool {
+					li := prettyStartLine(fr[i])
+					lj := prettyStartLine(fr[j])
+					return li < lj
+				})
+			}
 
 			for _, r := range fr {
 				line := prettyStartLine(r)
@@ -131,6 +178,9 @@ func (f *PrettyFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 					if c, ok := r.Properties["gavel/confidence"].(float64); ok {
 						conf = dimStyle.Render(fmt.Sprintf("(%.2f)", c))
 					}
+					if tier, ok := r.Properties["gavel/tier"].(string); ok && tier != "" {
+						conf = strings.TrimSpace(conf + " " + dimStyle.Render("["+tier+"]"))
+					}
 				}
 
 				fmt.Fprintf(&b, "    %-6s %s  %-7s  %-30s %s\n",
@@ -140,6 +190,20 @@ func (f *PrettyFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 		}
 	}
 
+	// Per-owner tally, when findings carry CODEOWNERS owners.
+	if groups := codeowners.GroupResults(results); len(groups) > 0 {
+		b.WriteString("  " + headerStyle.Render("Owners") + "\n")
+		width := 0
+		for _, g := range groups {
+			width = max(width, len(g.Owner))
+		}
+		for _, g := range groups {
+			fmt.Fprintf(&b, "    %-*s  %3d  %s\n", width, g.Owner, g.Findings,
+				dimStyle.Render(fmt.Sprintf("%dE %dW %dN", g.Errors, g.Warnings, g.Notes)))
+		}
+		b.WriteString("\n")
+	}
+
 	// Summary footer.
 	b.WriteString("  " + dimStyle.Render(separator) + "\n")
 
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -34,6 +35,7 @@ func testPrettyLog() *sarif.Log {
 					}},
 					Properties: map[string]any{
 						"gavel/confidence": 0.95,
+						"gavel/tier":       "comprehensive",
 					},
 				},
 				{
@@ -231,6 +233,12 @@ func TestPrettyFormatter_NoFindings(t *testing.T) {
 	if !strings.Contains(output, "No findings") {
 		t.Error("output missing 'No findings' text for empty results")
 	}
+	if !strings.Contains(output, "Decision: merge") {
+		t.Error("output missing decision for no-findings case")
+	}
+	if !strings.Contains(output, "0 findings") {
+		t.Error("output missing explicit '0 findings' count")
+	}
 }
 
 func TestPrettyFormatter_NilResult(t *testing.T) {
@@ -301,6 +309,26 @@ func TestPrettyFormatter_ContainsConfidence(t *testing.T) {
 	}
 }
 
+func TestPrettyFormatter_LabelsTier(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	f := &PrettyFormatter{}
+	out, err := f.Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "review"},
+		SARIFLog: testPrettyLog(),
+	})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+
+	if !strings.Contains(string(out), "Hardcoded secret detected      (0.95) [comprehensive]") {
+		t.Errorf("expected the finding to be labeled with its tier:\n%s", out)
+	}
+	if strings.Count(string(out), "[comprehensive]") != 1 {
+		t.Error("expected findings without a tier to be unlabeled")
+	}
+}
+
 func TestPrettyFormatter_ContainsPersona(t *testing.T) {
 	t.Setenv("NO_COLOR", "1")
 
@@ -342,3 +370,65 @@ func TestPrettyFormatter_NilSARIFLog(t *testing.T) {
 		t.Error("output missing decision 'merge' when SARIFLog is nil")
 	}
 }
+
+// testTrace is a reject trace with a threshold and a blocking finding.
+func testTrace() *store.DecisionTrace {
+	return &store.DecisionTrace{
+		Decision:                  "reject",
+		Rule:                      "reject-high-confidence-error",
+		Fired:                     []string{"finding-threshold", "reject-high-confidence-error"},
+		RejectConfidenceThreshold: 0.85,
+		Thresholds:                []store.TraceThreshold{{Name: "max_findings", Count: 3, Limit: 1, Exceeded: true}},
+		Triggers: []store.TraceTrigger{{
+			RuleID: "SEC001", Level: "error", Confidence: 0.95,
+			File: "config/db.go", Line: 42, Message: "Hardcoded secret detected",
+		}},
+	}
+}
+
+func TestPrettyFormatter_ExplainsVerdict(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	out, err := (&PrettyFormatter{}).Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "reject", Trace: testTrace()},
+		SARIFLog: testPrettyLog(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Why: reject-high-confidence-error — an error-level finding has confidence above 0.85",
+		"Also fired: finding-threshold",
+		"Threshold: max_findings 3/1 exceeded",
+		"config/db.go:42  error  SEC001  Hardcoded secret detected (0.95)",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _ = (&PrettyFormatter{}).Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "review"}, SARIFLog: testPrettyLog()})
+	if strings.Contains(string(out), "Why:") {
+		t.Error("expected no explanation without a trace")
+	}
+}
+
+func TestPrettyFormatter_GroupsByOwner(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	log := testPrettyLog()
+	for i := range log.Runs[0].Results {
+		if log.Runs[0].Results[i].Properties == nil {
+			log.Runs[0].Results[i].Properties = map[string]any{}
+		}
+		log.Runs[0].Results[i].Properties["gavel/owners"] = []string{"@acme/backend"}
+	}
+	out, err := (&PrettyFormatter{}).Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "review"}, SARIFLog: log})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+	n := len(log.Runs[0].Results)
+	if want := fmt.Sprintf("    @acme/backend  %3d", n); !strings.Contains(string(out), "  Owners\n") || !strings.Contains(string(out), want) {
+		t.Errorf("output missing the owner tally %q:\n%s", want, out)
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/secrets"
+)
+
+// redactOutput returns result with secrets masked in the text of every
+// finding, in both the SARIF log and the verdict: messages, snippets and
+// context snippets. The instant tier's secrets findings arrive masked
+// already; this catches credentials quoted by other findings, such as an
+// LLM finding's snippet of a hard-coded password. result is not modified.
+func redactOutput(result *AnalysisOutput) *AnalysisOutput {
+	if result == nil {
+		return nil
+	}
+	redacted := *result
+	if result.SARIFLog != nil {
+		log := *result.SARIFLog
+		log.Runs = append([]sarif.Run(nil), log.Runs...)
+		for i := range log.Runs {
+			log.Runs[i].Results = redactResults(log.Runs[i].Results)
+		}
+		redacted.SARIFLog = &log
+	}
+	if result.Verdict != nil && len(result.Verdict.RelevantFindings) > 0 {
+		verdict := *result.Verdict
+		verdict.RelevantFindings = redactResults(verdict.RelevantFindings)
+		redacted.Verdict = &verdict
+	}
+	return &redacted
+}
+
+// redactResults returns copies of results with secrets masked. Results
+// without secrets are returned as is.
+func redactResults(results []sarif.Result) []sarif.Result {
+	if results == nil {
+		return nil
+	}
+	out := make([]sarif.Result, len(results))
+	for i, r := range results {
+		r.Message.Text = secrets.Redact(r.Message.Text)
+		if len(r.Locations) > 0 {
+			locs := make([]sarif.Location, len(r.Locations))
+			for j, loc := range r.Locations {
+				pl := &loc.PhysicalLocation
+				pl.Region.Snippet = redactContent(pl.Region.Snippet)
+				if pl.ContextRegion != nil {
+					ctx := *pl.ContextRegion
+					ctx.Snippet = redactContent(ctx.Snippet)
+					pl.ContextRegion = &ctx
+				}
+				locs[j] = loc
+			}
+			r.Locations = locs
+		}
+		out[i] = r
+	}
+	return out
+}
+
+func redactContent(c *sarif.ArtifactContent) *sarif.ArtifactContent {
+	if c == nil {
+		return nil
+	}
+	text := secrets.Redact(c.Text)
+	if text == c.Text {
+		return c
+	}
+	return &sarif.ArtifactContent{Text: text}
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FormatterConfig is what the options passed to NewFormatter resolve to.
+// A FormatterFactory reads the fields that apply to its format and ignores
+// the rest.
+type FormatterConfig struct {
+	SortBy   SortMode
+	Taxonomy TaxonomyFilter
+	// Template is the path of the template the "template" format renders
+	Template string
+	// TemplateDir holds <name>.tmpl files NewFormatter falls back to for
+	// format names that are not registered
+	TemplateDir string
+}
+
+// WithTemplate sets the template file the "template" format renders.
+func WithTemplate(path string) FormatterOption {
+	return func(o *FormatterConfig) {
+		o.Template = path
+	}
+}
+
+// WithTemplateDir lets NewFormatter resolve an unregistered format name to
+// <dir>/<name>.tmpl, so a project can add formats, such as a Slack message
+// or a ticket body, by dropping a template into a directory.
+func WithTemplateDir(dir string) FormatterOption {
+	return func(o *FormatterConfig) {
+		o.TemplateDir = dir
+	}
+}
+
+// FormatterFactory builds a Formatter from NewFormatter's options.
+type FormatterFactory func(o FormatterConfig) (Formatter, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]FormatterFactory{}
+)
+
+func init() {
+	builtins := map[string]FormatterFactory{
+		"json": func(o FormatterConfig) (Formatter, error) {
+			return &JSONFormatter{Taxonomy: o.Taxonomy}, nil
+		},
+		"sarif": func(o FormatterConfig) (Formatter, error) {
+			return &SARIFFormatter{Taxonomy: o.Taxonomy}, nil
+		},
+		"markdown": func(o FormatterConfig) (Formatter, error) {
+			return &MarkdownFormatter{SortBy: o.SortBy, Taxonomy: o.Taxonomy}, nil
+		},
+		"pretty": func(o FormatterConfig) (Formatter, error) {
+			return &PrettyFormatter{SortBy: o.SortBy, Taxonomy: o.Taxonomy}, nil
+		},
+		"gitlab": func(o FormatterConfig) (Formatter, error) {
+			return &GitLabFormatter{Taxonomy: o.Taxonomy}, nil
+		},
+		"junit": func(o FormatterConfig) (Formatter, error) {
+			return &JUnitFormatter{Taxonomy: o.Taxonomy}, nil
+		},
+		"html": func(o FormatterConfig) (Formatter, error) {
+			return &HTMLFormatter{SortBy: o.SortBy, Taxonomy: o.Taxonomy}, nil
+		},
+		"template": func(o FormatterConfig) (Formatter, error) {
+			if o.Template == "" {
+				return nil, fmt.Errorf("the template format needs a template file")
+			}
+			return NewTemplateFormatter(o.Template, o)
+		},
+	}
+	for name, factory := range builtins {
+		if err := Register(name, factory); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register makes a format available to NewFormatter under name. It returns
+// an error if the name is empty or already registered, including as one of
+// the built-in formats.
+func Register(name string, factory FormatterFactory) error {
+	if name == "" || factory == nil {
+		return fmt.Errorf("registering output format: name and factory are required")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("output format %q is already registered", name)
+	}
+	registry[name] = factory
+	return nil
+}
+
+// Formats returns the registered format names in sorted order.
+func Formats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewFormatter returns a Formatter for the given format name: one of the
+// built-in formats ("json", "sarif", "markdown", "pretty", "gitlab",
+// "junit", "html", "template"), a format added with Register, or, with
+// WithTemplateDir, a template in that directory. Returns an error for unknown format names.
+func NewFormatter(format string, opts ...FormatterOption) (Formatter, error) {
+	var o FormatterConfig
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[format]
+	registryMu.RUnlock()
+	if ok {
+		return factory(o)
+	}
+
+	if o.TemplateDir != "" && format != "" && !strings.ContainsAny(format, `/\.`) {
+		path := filepath.Join(o.TemplateDir, format+".tmpl")
+		if _, err := os.Stat(path); err == nil {
+			return NewTemplateFormatter(path, o)
+		}
+	}
+
+	supported := strings.Join(Formats(), ", ")
+	if o.TemplateDir != "" {
+		supported += ", or a template in " + o.TemplateDir
+	}
+	return nil, fmt.Errorf("unknown output format: %q (supported: %s)", format, supported)
+}
@@ -11,15 +11,21 @@ import (
 
 // SARIFFormatter renders analysis output as a SARIF 2.1.0 JSON document
 // enriched with GitHub Code Scanning properties (security-severity, precision,
-// partial fingerprints, and invocation metadata).
-type SARIFFormatter struct{}
+// partial fingerprints, and invocation metadata). A Taxonomy filter drops the
+// findings it does not select.
+type SARIFFormatter struct {
+	Taxonomy TaxonomyFilter
+}
 
 // Format enriches the SARIF log in-place and serializes it as indented JSON
-// with a trailing newline.
+// with a trailing newline. Runs without findings carry an explicit empty
+// results array, and the verdict decision (when present) is recorded in
+// run properties as gavel/decision.
 func (f *SARIFFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 	if result == nil || result.SARIFLog == nil {
 		return nil, fmt.Errorf("sarif formatter: SARIF log is required")
 	}
+	result = redactOutput(f.Taxonomy.filterOutput(result))
 
 	log := result.SARIFLog
 
@@ -27,6 +33,15 @@ func (f *SARIFFormatter) Format(result *AnalysisOutput) ([]byte, error) {
 	for i := range log.Runs {
 		run := &log.Runs[i]
 		enrichRun(run)
+		if run.Results == nil {
+			run.Results = []sarif.Result{}
+		}
+		if result.Verdict != nil {
+			if run.Properties == nil {
+				run.Properties = make(map[string]any)
+			}
+			run.Properties["gavel/decision"] = result.Verdict.Decision
+		}
 	}
 
 	data, err := json.MarshalIndent(log, "", "  ")
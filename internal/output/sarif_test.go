@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
 )
 
 func testSARIFLog() *sarif.Log {
@@ -427,6 +428,46 @@ func TestSARIFFormatter_HasInvocations(t *testing.T) {
 	}
 }
 
+func TestSARIFFormatter_NoFindings(t *testing.T) {
+	f := &SARIFFormatter{}
+	log := testSARIFLog()
+	log.Runs[0].Results = nil
+
+	out, err := f.Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "merge"},
+		SARIFLog: log,
+	})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+
+	var parsed struct {
+		Runs []struct {
+			Results     json.RawMessage `json:"results"`
+			Properties  map[string]any  `json:"properties"`
+			Invocations []struct {
+				ExecutionSuccessful bool `json:"executionSuccessful"`
+			} `json:"invocations"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(parsed.Runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(parsed.Runs))
+	}
+	run := parsed.Runs[0]
+	if got := string(run.Results); got != "[]" {
+		t.Errorf("results = %s, want []", got)
+	}
+	if run.Properties["gavel/decision"] != "merge" {
+		t.Errorf("gavel/decision = %v, want merge", run.Properties["gavel/decision"])
+	}
+	if len(run.Invocations) != 1 || !run.Invocations[0].ExecutionSuccessful {
+		t.Error("expected a successful invocation for a clean run")
+	}
+}
+
 func TestSARIFFormatter_NilLog(t *testing.T) {
 	f := &SARIFFormatter{}
 
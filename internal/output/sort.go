@@ -0,0 +1,111 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/chris-regnier/gavel/internal/blastradius"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// SortMode selects how formatters order findings.
+type SortMode string
+
+const (
+	// SortDefault keeps each formatter's native ordering (by file for
+	// pretty, by severity then file for markdown).
+	SortDefault SortMode = ""
+	// SortPriority orders findings by fix priority: severity first, then
+	// confidence (weighted by blast radius) descending, then file path and
+	// line.
+	SortPriority SortMode = "priority"
+)
+
+// ParseSortMode validates a --sort-by value. The empty string and
+// "default" select SortDefault.
+func ParseSortMode(s string) (SortMode, error) {
+	switch s {
+	case "", "default":
+		return SortDefault, nil
+	case string(SortPriority):
+		return SortPriority, nil
+	default:
+		return "", fmt.Errorf("unknown sort mode: %q (supported: default, priority)", s)
+	}
+}
+
+// FormatterOption configures a Formatter returned by NewFormatter.
+type FormatterOption func(*FormatterConfig)
+
+// WithSortBy sets the finding order for formatters that render a list of
+// findings (pretty, markdown, html and template). Machine-readable formats ignore
+// it.
+func WithSortBy(mode SortMode) FormatterOption {
+	return func(o *FormatterConfig) {
+		o.SortBy = mode
+	}
+}
+
+// WithTaxonomy restricts every format to the findings f selects. JSON and
+// Markdown output also group the remaining findings by f's taxonomy.
+func WithTaxonomy(f TaxonomyFilter) FormatterOption {
+	return func(o *FormatterConfig) {
+		o.Taxonomy = f
+	}
+}
+
+// SortByPriority returns a copy of results ordered by fix priority: errors
+// before warnings before notes, higher confidence first within a severity,
+// then by file path and start line for a stable reading order. Findings
+// carrying a gavel/blast-radius property have their confidence boosted by
+// blastradius.Weight, so issues in widely imported packages rank first.
+func SortByPriority(results []sarif.Result) []sarif.Result {
+	sorted := make([]sarif.Result, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return priorityLess(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// priorityLess reports whether a should be fixed before b.
+func priorityLess(a, b sarif.Result) bool {
+	if pa, pb := severityPriority(a.Level), severityPriority(b.Level); pa != pb {
+		return pa < pb
+	}
+	if ca, cb := weightedConfidence(a), weightedConfidence(b); ca != cb {
+		return ca > cb
+	}
+	if fa, fb := resultFilePath(a), resultFilePath(b); fa != fb {
+		return fa < fb
+	}
+	return prettyStartLine(a) < prettyStartLine(b)
+}
+
+// weightedConfidence scales a result's confidence by its blast radius.
+func weightedConfidence(r sarif.Result) float64 {
+	return confidenceValue(r) * blastradius.Weight(blastRadius(r))
+}
+
+// blastRadius returns the gavel/blast-radius property, or 0 when absent. The
+// value is an int when set in-process and a float64 once read back from JSON.
+func blastRadius(r sarif.Result) int {
+	switch v := r.Properties[blastradius.Property].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// confidenceValue returns the gavel/confidence property, or 0 when absent.
+func confidenceValue(r sarif.Result) float64 {
+	if r.Properties == nil {
+		return 0
+	}
+	if v, ok := r.Properties["gavel/confidence"].(float64); ok {
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,196 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/blastradius"
+	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+// priorityResult builds a result with the given level, confidence and location.
+func priorityResult(ruleID, level string, confidence float64, uri string, line int) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   level,
+		Message: sarif.Message{Text: ruleID + " message"},
+		Locations: []sarif.Location{{
+			PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{URI: uri},
+				Region:           sarif.Region{StartLine: line, EndLine: line},
+			},
+		}},
+		Properties: map[string]any{"gavel/confidence": confidence},
+	}
+}
+
+// testPriorityLog has a low-confidence error in a.go, a high-confidence error
+// in z.go, and a high-confidence warning in a.go.
+func testPriorityLog() *sarif.Log {
+	return &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		priorityResult("WARN_HI", "warning", 0.99, "a.go", 1),
+		priorityResult("ERR_LO", "error", 0.40, "a.go", 5),
+		priorityResult("ERR_HI", "error", 0.95, "z.go", 9),
+	}}}}
+}
+
+func TestParseSortMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    SortMode
+		wantErr bool
+	}{
+		{"", SortDefault, false},
+		{"default", SortDefault, false},
+		{"priority", SortPriority, false},
+		{"severity", "", true},
+	}
+	for _, tc := range tests {
+		got, err := ParseSortMode(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseSortMode(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("ParseSortMode(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	results := testPriorityLog().Runs[0].Results
+	sorted := SortByPriority(results)
+
+	want := []string{"ERR_HI", "ERR_LO", "WARN_HI"}
+	for i, id := range want {
+		if sorted[i].RuleID != id {
+			t.Errorf("sorted[%d] = %s, want %s", i, sorted[i].RuleID, id)
+		}
+	}
+	if results[0].RuleID != "WARN_HI" {
+		t.Error("SortByPriority should not reorder its input")
+	}
+}
+
+func TestSortByPriority_TiesBreakByFileThenLine(t *testing.T) {
+	sorted := SortByPriority([]sarif.Result{
+		priorityResult("B2", "warning", 0.8, "b.go", 2),
+		priorityResult("A9", "warning", 0.8, "a.go", 9),
+		priorityResult("B1", "warning", 0.8, "b.go", 1),
+	})
+	want := []string{"A9", "B1", "B2"}
+	for i, id := range want {
+		if sorted[i].RuleID != id {
+			t.Errorf("sorted[%d] = %s, want %s", i, sorted[i].RuleID, id)
+		}
+	}
+}
+
+func TestSortByPriority_BlastRadiusBoost(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	importsCore := "package x\n\nimport \"example.com/m/core\"\n\nvar _ = core.X\n"
+	var arts []input.Artifact
+	arts = append(arts,
+		input.Artifact{Path: filepath.Join(root, "core/core.go"), Content: "package core\n\nvar X = 1\n", Kind: input.KindFile},
+		input.Artifact{Path: filepath.Join(root, "leaf/leaf.go"), Content: "package leaf\n", Kind: input.KindFile},
+	)
+	for _, dir := range []string{"a", "b", "c", "d"} {
+		arts = append(arts, input.Artifact{Path: filepath.Join(root, dir, "x.go"), Content: importsCore, Kind: input.KindFile})
+	}
+
+	log := &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		priorityResult("LEAF", "warning", 0.8, filepath.Join(root, "leaf/leaf.go"), 1),
+		priorityResult("CORE", "warning", 0.7, filepath.Join(root, "core/core.go"), 1),
+	}}}}
+	if got := SortByPriority(log.Runs[0].Results); got[0].RuleID != "LEAF" {
+		t.Fatalf("expected higher-confidence LEAF first without enrichment, got %s", got[0].RuleID)
+	}
+
+	blastradius.Enrich(log, blastradius.Build(arts))
+	sorted := SortByPriority(log.Runs[0].Results)
+	if sorted[0].RuleID != "CORE" {
+		t.Errorf("expected finding in widely imported package to rank first, got %s, %s", sorted[0].RuleID, sorted[1].RuleID)
+	}
+
+	// Severity still dominates the blast-radius boost, and the property is
+	// honoured after a JSON round trip (float64).
+	errLeaf := priorityResult("ERR_LEAF", "error", 0.5, "leaf.go", 1)
+	warnCore := priorityResult("WARN_CORE", "warning", 0.9, "core.go", 1)
+	warnCore.Properties[blastradius.Property] = float64(15)
+	if got := SortByPriority([]sarif.Result{warnCore, errLeaf}); got[0].RuleID != "ERR_LEAF" {
+		t.Errorf("expected error to outrank boosted warning, got %s", got[0].RuleID)
+	}
+}
+
+func TestMarkdownFormatter_SortByPriority(t *testing.T) {
+	f, err := NewFormatter("markdown", WithSortBy(SortPriority))
+	if err != nil {
+		t.Fatalf("NewFormatter() returned error: %v", err)
+	}
+	out, err := f.Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "review"},
+		SARIFLog: testPriorityLog(),
+	})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+	output := string(out)
+
+	hi, lo, warn := strings.Index(output, "ERR_HI"), strings.Index(output, "ERR_LO"), strings.Index(output, "WARN_HI")
+	if !(hi < lo && lo < warn) {
+		t.Errorf("expected ERR_HI < ERR_LO < WARN_HI, got positions %d, %d, %d", hi, lo, warn)
+	}
+}
+
+func TestPrettyFormatter_SortByPriority(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	f, err := NewFormatter("pretty", WithSortBy(SortPriority))
+	if err != nil {
+		t.Fatalf("NewFormatter() returned error: %v", err)
+	}
+	out, err := f.Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "review"},
+		SARIFLog: testPriorityLog(),
+	})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+	output := string(out)
+
+	// z.go holds the most actionable finding, so its group comes first;
+	// within a.go the error precedes the higher-confidence warning.
+	zFile, aFile := strings.Index(output, "z.go"), strings.Index(output, "a.go")
+	if zFile > aFile {
+		t.Errorf("expected z.go group before a.go group, got positions %d, %d", zFile, aFile)
+	}
+	lo, warn := strings.Index(output, "ERR_LO"), strings.Index(output, "WARN_HI")
+	if lo > warn {
+		t.Errorf("expected ERR_LO before WARN_HI within a.go, got positions %d, %d", lo, warn)
+	}
+}
+
+func TestPrettyFormatter_DefaultSortUnchanged(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	f := &PrettyFormatter{}
+	out, err := f.Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "review"},
+		SARIFLog: testPriorityLog(),
+	})
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+	output := string(out)
+
+	if strings.Index(output, "a.go") > strings.Index(output, "z.go") {
+		t.Error("default sort should keep files alphabetical")
+	}
+	if strings.Index(output, "WARN_HI") > strings.Index(output, "ERR_LO") {
+		t.Error("default sort should keep findings in line order within a file")
+	}
+}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Taxonomy names a security classification carried on findings as a
+// gavel/<taxonomy> property listing its IDs.
+type Taxonomy string
+
+const (
+	TaxonomyCWE   Taxonomy = "cwe"   // gavel/cwe, e.g. "CWE-79"
+	TaxonomyOWASP Taxonomy = "owasp" // gavel/owasp, e.g. "A03:2021"
+)
+
+// TaxonomyFilter selects findings by taxonomy. A zero filter selects
+// everything. With only Taxonomy set it selects findings that carry any ID
+// in that taxonomy; with IDs it selects findings carrying at least one of
+// them.
+type TaxonomyFilter struct {
+	Taxonomy Taxonomy
+	IDs      []string
+}
+
+// ParseTaxonomyFilter parses a --taxonomy value: "cwe" or "owasp", optionally
+// followed by "=" and a comma-separated list of IDs, as in
+// "owasp=A03:2021,A07" or "cwe=79,CWE-89". The empty string is the zero
+// filter.
+func ParseTaxonomyFilter(s string) (TaxonomyFilter, error) {
+	if s == "" {
+		return TaxonomyFilter{}, nil
+	}
+	name, list, _ := strings.Cut(s, "=")
+	f := TaxonomyFilter{Taxonomy: Taxonomy(strings.ToLower(strings.TrimSpace(name)))}
+	if f.Taxonomy != TaxonomyCWE && f.Taxonomy != TaxonomyOWASP {
+		return TaxonomyFilter{}, fmt.Errorf("unknown taxonomy: %q (supported: cwe, owasp)", name)
+	}
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			f.IDs = append(f.IDs, f.Taxonomy.normalize(id))
+		}
+	}
+	return f, nil
+}
+
+// normalize canonicalizes an ID: CWE IDs gain the "CWE-" prefix and OWASP
+// IDs are upper-cased.
+func (t Taxonomy) normalize(id string) string {
+	id = strings.ToUpper(id)
+	if t == TaxonomyCWE && !strings.HasPrefix(id, "CWE-") {
+		return "CWE-" + id
+	}
+	return id
+}
+
+// IDs returns the taxonomy IDs recorded on r, normalized. It accepts the
+// []string the analyzer sets and the []interface{} a stored log decodes to.
+func (t Taxonomy) IDs(r sarif.Result) []string {
+	var ids []string
+	switch v := r.Properties["gavel/"+string(t)].(type) {
+	case []string:
+		for _, id := range v {
+			ids = append(ids, t.normalize(id))
+		}
+	case []interface{}:
+		for _, id := range v {
+			if s, ok := id.(string); ok {
+				ids = append(ids, t.normalize(s))
+			}
+		}
+	}
+	return ids
+}
+
+// matches reports whether id is selected by the filter's IDs. An OWASP
+// filter ID without a year, such as "A03", matches every edition of it.
+func (f TaxonomyFilter) matches(id string) bool {
+	if len(f.IDs) == 0 {
+		return true
+	}
+	for _, want := range f.IDs {
+		if id == want || strings.HasPrefix(id, want+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the results the filter selects, in their original order.
+func (f TaxonomyFilter) Filter(results []sarif.Result) []sarif.Result {
+	if f.Taxonomy == "" {
+		return results
+	}
+	kept := []sarif.Result{}
+	for _, r := range results {
+		for _, id := range f.Taxonomy.IDs(r) {
+			if f.matches(id) {
+				kept = append(kept, r)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// TaxonomyGroup is the set of findings tagged with one taxonomy ID.
+type TaxonomyGroup struct {
+	ID       string         `json:"id"`
+	Count    int            `json:"count"`
+	RuleIDs  []string       `json:"rule_ids"`
+	Findings []sarif.Result `json:"-"`
+}
+
+// Group buckets results by the filter's taxonomy, sorted by ID. A finding
+// tagged with several IDs appears in each of their groups; IDs the filter
+// does not select are left out. The zero filter yields no groups.
+func (f TaxonomyFilter) Group(results []sarif.Result) []TaxonomyGroup {
+	if f.Taxonomy == "" {
+		return nil
+	}
+	byID := make(map[string]*TaxonomyGroup)
+	for _, r := range results {
+		for _, id := range f.Taxonomy.IDs(r) {
+			if !f.matches(id) {
+				continue
+			}
+			g, ok := byID[id]
+			if !ok {
+				g = &TaxonomyGroup{ID: id}
+				byID[id] = g
+			}
+			g.Findings = append(g.Findings, r)
+			g.Count++
+			if !containsString(g.RuleIDs, r.RuleID) {
+				g.RuleIDs = append(g.RuleIDs, r.RuleID)
+			}
+		}
+	}
+	groups := make([]TaxonomyGroup, 0, len(byID))
+	for _, g := range byID {
+		sort.Strings(g.RuleIDs)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups
+}
+
+// filterOutput returns result with its first run's results narrowed by f.
+// The input is not modified; without a taxonomy it is returned as is.
+func (f TaxonomyFilter) filterOutput(result *AnalysisOutput) *AnalysisOutput {
+	if f.Taxonomy == "" || result == nil || result.SARIFLog == nil || len(result.SARIFLog.Runs) == 0 {
+		return result
+	}
+	log := *result.SARIFLog
+	log.Runs = append([]sarif.Run(nil), log.Runs...)
+	log.Runs[0].Results = f.Filter(log.Runs[0].Results)
+	filtered := *result
+	filtered.SARIFLog = &log
+	return &filtered
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package output
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+func taxonomyResult(ruleID string, cwe, owasp []string) sarif.Result {
+	r := priorityResult(ruleID, "error", 0.9, ruleID+".go", 1)
+	if cwe != nil {
+		r.Properties["gavel/cwe"] = cwe
+	}
+	if owasp != nil {
+		r.Properties["gavel/owasp"] = owasp
+	}
+	return r
+}
+
+// testTaxonomyLog has two injection findings (A03), one auth finding
+// (A07), a weak-crypto finding with CWEs but no OWASP tag, and an untagged
+// maintainability finding.
+func testTaxonomyLog() *sarif.Log {
+	return &sarif.Log{Runs: []sarif.Run{{Results: []sarif.Result{
+		taxonomyResult("S3649", []string{"CWE-89"}, []string{"A03:2021"}),
+		taxonomyResult("S2068", []string{"CWE-259", "CWE-798"}, []string{"A07:2021"}),
+		taxonomyResult("S4426", []string{"CWE-327"}, nil),
+		taxonomyResult("S2076", []string{"CWE-78"}, []string{"A03:2021"}),
+		taxonomyResult("S1135", nil, nil),
+	}}}}
+}
+
+func ruleIDs(results []sarif.Result) []string {
+	ids := []string{}
+	for _, r := range results {
+		ids = append(ids, r.RuleID)
+	}
+	return ids
+}
+
+func TestParseTaxonomyFilter(t *testing.T) {
+	f, err := ParseTaxonomyFilter("OWASP=a03:2021, A07")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (TaxonomyFilter{Taxonomy: TaxonomyOWASP, IDs: []string{"A03:2021", "A07"}}); !reflect.DeepEqual(f, want) {
+		t.Errorf("got %+v, want %+v", f, want)
+	}
+
+	f, err = ParseTaxonomyFilter("cwe=79,cwe-89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"CWE-79", "CWE-89"}; !reflect.DeepEqual(f.IDs, want) {
+		t.Errorf("expected normalized CWE IDs %v, got %v", want, f.IDs)
+	}
+
+	if _, err := ParseTaxonomyFilter("nist"); err == nil || !strings.Contains(err.Error(), "unknown taxonomy") {
+		t.Errorf("expected unknown taxonomy error, got %v", err)
+	}
+}
+
+func TestTaxonomyFilter_FilterAndGroup(t *testing.T) {
+	results := testTaxonomyLog().Runs[0].Results
+
+	// Any OWASP category: untagged and CWE-only findings are dropped.
+	owasp := TaxonomyFilter{Taxonomy: TaxonomyOWASP}
+	if got, want := ruleIDs(owasp.Filter(results)), []string{"S3649", "S2068", "S2076"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter = %v, want %v", got, want)
+	}
+	groups := owasp.Group(results)
+	if len(groups) != 2 ||
+		groups[0].ID != "A03:2021" || groups[0].Count != 2 || !reflect.DeepEqual(groups[0].RuleIDs, []string{"S2076", "S3649"}) ||
+		groups[1].ID != "A07:2021" || groups[1].Count != 1 {
+		t.Errorf("unexpected OWASP groups: %+v", groups)
+	}
+
+	// A yearless subset selects only matching findings and groups.
+	injection := TaxonomyFilter{Taxonomy: TaxonomyOWASP, IDs: []string{"A03"}}
+	if got, want := ruleIDs(injection.Filter(results)), []string{"S3649", "S2076"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter = %v, want %v", got, want)
+	}
+	if groups := injection.Group(results); len(groups) != 1 || groups[0].ID != "A03:2021" {
+		t.Errorf("expected a single A03:2021 group, got %+v", groups)
+	}
+
+	// A finding with several CWEs lands in each selected group.
+	cwe := TaxonomyFilter{Taxonomy: TaxonomyCWE, IDs: []string{"CWE-259", "CWE-798"}}
+	if groups := cwe.Group(results); len(groups) != 2 || groups[0].Findings[0].RuleID != "S2068" || groups[1].Findings[0].RuleID != "S2068" {
+		t.Errorf("expected S2068 under both CWE groups, got %+v", groups)
+	}
+
+	if got := (TaxonomyFilter{}).Filter(results); len(got) != len(results) {
+		t.Errorf("zero filter should keep all %d results, got %d", len(results), len(got))
+	}
+}
+
+func TestTaxonomyFilter_StoredProperties(t *testing.T) {
+	// A log read back from the store decodes property lists as []interface{}.
+	data, err := json.Marshal(testTaxonomyLog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	f := TaxonomyFilter{Taxonomy: TaxonomyCWE, IDs: []string{"CWE-78"}}
+	if got := ruleIDs(f.Filter(log.Runs[0].Results)); !reflect.DeepEqual(got, []string{"S2076"}) {
+		t.Errorf("expected only S2076, got %v", got)
+	}
+}
+
+func TestJSONFormatter_Taxonomy(t *testing.T) {
+	log := testTaxonomyLog()
+	f, err := NewFormatter("json", WithTaxonomy(TaxonomyFilter{Taxonomy: TaxonomyOWASP}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "reject"}, SARIFLog: log})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Findings []sarif.Result  `json:"findings"`
+		Summary  FindingsSummary `json:"summary"`
+		Taxonomy []TaxonomyGroup `json:"taxonomy"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if got := ruleIDs(payload.Findings); !reflect.DeepEqual(got, []string{"S3649", "S2068", "S2076"}) {
+		t.Errorf("expected only OWASP-tagged findings, got %v", got)
+	}
+	if payload.Summary.Total != 3 {
+		t.Errorf("expected summary to count filtered findings, got %d", payload.Summary.Total)
+	}
+	if len(payload.Taxonomy) != 2 || payload.Taxonomy[0].ID != "A03:2021" || payload.Taxonomy[0].Count != 2 {
+		t.Errorf("unexpected taxonomy groups: %+v", payload.Taxonomy)
+	}
+	if len(log.Runs[0].Results) != 5 {
+		t.Error("formatting must not modify the caller's log")
+	}
+}
+
+func TestMarkdownFormatter_Taxonomy(t *testing.T) {
+	f, err := NewFormatter("markdown", WithTaxonomy(TaxonomyFilter{Taxonomy: TaxonomyOWASP, IDs: []string{"A07"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.Format(&AnalysisOutput{Verdict: &store.Verdict{Decision: "reject"}, SARIFLog: testTaxonomyLog()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if !strings.Contains(out, "**Findings:** 1") {
+		t.Errorf("expected a single finding after filtering:\n%s", out)
+	}
+	if !strings.Contains(out, "### Findings by OWASP") || !strings.Contains(out, "| A07:2021 | 1 | S2068 |") {
+		t.Errorf("expected an OWASP grouping table:\n%s", out)
+	}
+	if strings.Contains(out, "S3649") {
+		t.Errorf("expected A03 findings to be filtered out:\n%s", out)
+	}
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// TemplateFormatter renders analysis output through a Go text/template, for
+// formats Gavel does not ship, such as a Slack message or an issue
+// tracker's ticket body. The template executes against TemplateData.
+// Findings are in SARIF order, or by fix priority when SortBy is
+// SortPriority, and narrowed by Taxonomy when it is set.
+type TemplateFormatter struct {
+	Template *template.Template
+	SortBy   SortMode
+	Taxonomy TaxonomyFilter
+}
+
+// TemplateData is what a TemplateFormatter's template executes against:
+// the AnalysisOutput's fields (.Verdict, .SARIFLog, .Stats), plus the
+// findings of the first run and a severity summary of them.
+type TemplateData struct {
+	*AnalysisOutput
+	Findings []sarif.Result
+	Summary  FindingsSummary
+}
+
+// NewTemplateFormatter parses the template at path with the functions
+// described in TemplateFuncs and returns a formatter for it, ordering and
+// filtering findings as o's SortBy and Taxonomy ask.
+func NewTemplateFormatter(path string, o FormatterConfig) (*TemplateFormatter, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs()).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	return &TemplateFormatter{Template: tmpl, SortBy: o.SortBy, Taxonomy: o.Taxonomy}, nil
+}
+
+// TemplateFuncs returns the functions available to templates, in addition
+// to text/template's built-ins:
+//
+//	file, line, lines   a finding's file, start line, and "start-end" range
+//	prop                a finding's property, e.g. prop . "gavel/confidence"
+//	confidence          a finding's gavel/confidence, 0 when absent
+//	json, xml           a value as JSON, a string escaped for XML
+//	upper, lower, trim  strings.ToUpper, ToLower and TrimSpace
+//	replace, join       strings.ReplaceAll and Join
+//	truncate            a string cut to n bytes, ending in "..."
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"file":  resultFilePath,
+		"line":  prettyStartLine,
+		"lines": resultLineRange,
+		"prop": func(r sarif.Result, key string) interface{} {
+			return r.Properties[key]
+		},
+		"confidence": confidenceValue,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"xml": func(s string) (string, error) {
+			var b bytes.Buffer
+			err := xml.EscapeText(&b, []byte(s))
+			return b.String(), err
+		},
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"trim":     strings.TrimSpace,
+		"replace":  strings.ReplaceAll,
+		"join":     strings.Join,
+		"truncate": truncate,
+	}
+}
+
+// Format executes the template.
+func (f *TemplateFormatter) Format(result *AnalysisOutput) ([]byte, error) {
+	if result == nil {
+		return nil, fmt.Errorf("template formatter: analysis output is required")
+	}
+	if f.Template == nil {
+		return nil, fmt.Errorf("template formatter: template is required")
+	}
+	result = redactOutput(f.Taxonomy.filterOutput(result))
+	findings := logResults(result.SARIFLog)
+	if f.SortBy == SortPriority {
+		findings = SortByPriority(findings)
+	}
+
+	var buf bytes.Buffer
+	data := TemplateData{AnalysisOutput: result, Findings: findings, Summary: summarizeResults(findings)}
+	if err := f.Template.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("executing template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+func writeTemplate(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "review.tmpl")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTemplateFormatter_Format(t *testing.T) {
+	path := writeTemplate(t, `{{.Verdict.Decision}} {{.Summary.Total}} {{.Summary.Errors}}
+{{range .Findings}}{{.RuleID}} {{file .}}:{{line .}} {{lines .}} {{printf "%.2f" (confidence .)}} {{prop . "gavel/recommendation"}}
+{{end}}`)
+	f, err := NewFormatter("template", WithTemplate(path))
+	if err != nil {
+		t.Fatalf("NewFormatter returned error: %v", err)
+	}
+
+	out, err := f.Format(&AnalysisOutput{
+		Verdict:  &store.Verdict{Decision: "reject"},
+		SARIFLog: testMarkdownLog(),
+	})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	lines := strings.Split(string(out), "\n")
+	if !strings.HasPrefix(lines[0], "reject ") || !strings.HasSuffix(lines[0], " 1") {
+		t.Errorf("header = %q, want the decision, total and one error", lines[0])
+	}
+	want := "SEC001 config/db.go:42 42-42 0.95 Use environment variables or a secrets manager."
+	if lines[1] != want {
+		t.Errorf("first finding = %q, want %q", lines[1], want)
+	}
+}
+
+func TestTemplateFormatter_SortByPriority(t *testing.T) {
+	path := writeTemplate(t, `{{range .Findings}}{{.Level}} {{end}}`)
+	log := testMarkdownLog()
+	results := log.Runs[0].Results
+	// Put the error last so priority order has something to do
+	log.Runs[0].Results = append(results[1:], results[0])
+
+	f, err := NewFormatter("template", WithTemplate(path), WithSortBy(SortPriority))
+	if err != nil {
+		t.Fatalf("NewFormatter returned error: %v", err)
+	}
+	out, err := f.Format(&AnalysisOutput{SARIFLog: log})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if !strings.HasPrefix(string(out), "error ") {
+		t.Errorf("Format() = %q, want the error first", out)
+	}
+}
+
+func TestTemplateFormatter_XMLEscape(t *testing.T) {
+	path := writeTemplate(t, `<failure message="{{xml .Verdict.Reason}}"/>`)
+	f, err := NewFormatter("template", WithTemplate(path))
+	if err != nil {
+		t.Fatalf("NewFormatter returned error: %v", err)
+	}
+	out, err := f.Format(&AnalysisOutput{Verdict: &store.Verdict{Reason: `a < b & "c"`}})
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	want := `<failure message="a &lt; b &amp; &#34;c&#34;"/>`
+	if string(out) != want {
+		t.Errorf("Format() = %q, want %q", out, want)
+	}
+}
+
+func TestTemplateFormatter_Errors(t *testing.T) {
+	if _, err := NewFormatter("template", WithTemplate(filepath.Join(t.TempDir(), "missing.tmpl"))); err == nil {
+		t.Error("missing template file: expected error, got nil")
+	}
+	if _, err := NewFormatter("template", WithTemplate(writeTemplate(t, "{{.Nope"))); err == nil {
+		t.Error("unparseable template: expected error, got nil")
+	}
+
+	f, err := NewFormatter("template", WithTemplate(writeTemplate(t, "{{.Verdict.Nope}}")))
+	if err != nil {
+		t.Fatalf("NewFormatter returned error: %v", err)
+	}
+	if _, err := f.Format(&AnalysisOutput{Verdict: &store.Verdict{}}); err == nil {
+		t.Error("unknown field: expected error, got nil")
+	}
+}
@@ -0,0 +1,80 @@
+// Package pathglob matches file paths against the globs used by rules'
+// include_paths and exclude_paths and by the path_overrides config section.
+//
+// Globs use forward slashes; "**" matches zero or more directories and the
+// remaining syntax is that of path.Match within a single segment. Patterns
+// are anchored at a directory boundary but not at the root, so
+// "internal/auth/**" matches both "internal/auth/login.go" and
+// "/src/repo/internal/auth/login.go".
+package pathglob
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Match reports whether the glob pattern matches p or any trailing run of
+// p's path segments.
+func Match(pattern, p string) bool {
+	pat := strings.Split(strings.Trim(normalize(pattern), "/"), "/")
+	segs := strings.Split(strings.Trim(normalize(p), "/"), "/")
+	for start := range segs {
+		if matchSegments(pat, segs[start:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that every segment of every pattern is a valid
+// path.Match pattern.
+func Validate(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("empty pattern")
+		}
+		for _, seg := range strings.Split(normalize(pattern), "/") {
+			if seg == "**" {
+				continue
+			}
+			if _, err := path.Match(seg, ""); err != nil {
+				return fmt.Errorf("pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func normalize(p string) string {
+	p = filepath.ToSlash(p)
+	for strings.HasPrefix(p, "./") {
+		p = strings.TrimPrefix(p, "./")
+	}
+	return p
+}
+
+// matchSegments matches glob segments against path segments, expanding "**"
+// to zero or more path segments.
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			rest := pat[1:]
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(rest, segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], segs[0]); !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
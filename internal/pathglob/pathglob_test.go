@@ -0,0 +1,33 @@
+package pathglob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"internal/auth/**", "internal/auth/login.go", true},
+		{"internal/auth/**", "/src/repo/internal/auth/sub/login.go", true},
+		{"internal/auth/**", "internal/authz/login.go", false},
+		{"**/*_test.go", "./pkg/a_test.go", true},
+		{"*.go", "pkg/a.go", true},
+		{"vendor/*.go", "vendor/x/a.go", false},
+	}
+	for _, tc := range cases {
+		if got := Match(tc.pattern, tc.path); got != tc.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]string{"internal/**", "*.go", "./cmd/*"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, bad := range []string{"vendor/[x/**", " "} {
+		if err := Validate([]string{bad}); err == nil {
+			t.Errorf("Validate(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package persona loads user-defined analysis personas and keeps the set
+// available to --persona alongside the built-in ones.
+//
+// A persona file holds a single persona in the shape `gavel create persona`
+// generates:
+//
+//	name: react-expert
+//	display_name: React Expert
+//	system_prompt: |
+//	  You are a React specialist...
+//
+// Personas are read from ~/.config/gavel/personas/ and then the project's
+// .gavel/personas/; a project persona replaces a user persona of the same
+// name. Built-in personas cannot be replaced.
+package persona
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Persona is a user-defined analysis persona.
+type Persona struct {
+	Name         string `yaml:"name"`
+	DisplayName  string `yaml:"display_name"`
+	SystemPrompt string `yaml:"system_prompt"`
+}
+
+// Builtin lists the personas compiled into the analyzer.
+var Builtin = []string{"code-reviewer", "code-reviewer-verbose", "architect", "security", "research-assistant", "sharp-editor"}
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// IsBuiltin reports whether name is a built-in persona.
+func IsBuiltin(name string) bool {
+	for _, b := range Builtin {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads the personas in userDir and projectDir. Missing directories are
+// not an error.
+func Load(userDir, projectDir string) ([]Persona, error) {
+	merged := make(map[string]Persona)
+	for _, dir := range []string{userDir, projectDir} {
+		personas, err := loadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range personas {
+			merged[p.Name] = p
+		}
+	}
+
+	result := make([]Persona, 0, len(merged))
+	for _, p := range merged {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func loadDir(dir string) ([]Persona, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading directory %s: %w", dir, err)
+	}
+
+	var personas []Persona
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		p, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if other, ok := seen[p.Name]; ok {
+			return nil, fmt.Errorf("persona %q is defined in both %s and %s", p.Name, other, entry.Name())
+		}
+		seen[p.Name] = entry.Name()
+		personas = append(personas, p)
+	}
+	return personas, nil
+}
+
+// Parse decodes and validates a persona file.
+func Parse(data []byte) (Persona, error) {
+	var p Persona
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return Persona{}, err
+	}
+	p.SystemPrompt = strings.TrimSpace(p.SystemPrompt)
+	switch {
+	case p.Name == "":
+		return Persona{}, fmt.Errorf("missing required field: name")
+	case !validName.MatchString(p.Name):
+		return Persona{}, fmt.Errorf("invalid name %q (use lowercase letters, digits, - and _)", p.Name)
+	case IsBuiltin(p.Name):
+		return Persona{}, fmt.Errorf("name %q is a built-in persona", p.Name)
+	case p.SystemPrompt == "":
+		return Persona{}, fmt.Errorf("missing required field: system_prompt")
+	}
+	return p, nil
+}
+
+var (
+	mu         sync.RWMutex
+	registered = map[string]Persona{}
+)
+
+// Register makes personas available by name, replacing any registered
+// earlier. Commands call it once after Load, before validating config.
+func Register(personas []Persona) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = make(map[string]Persona, len(personas))
+	for _, p := range personas {
+		registered[p.Name] = p
+	}
+}
+
+// Lookup returns the registered persona with the given name.
+func Lookup(name string) (Persona, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := registered[name]
+	return p, ok
+}
+
+// Known reports whether name is a built-in or registered persona.
+func Known(name string) bool {
+	if IsBuiltin(name) {
+		return true
+	}
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Names returns the built-in persona names followed by the registered ones
+// in alphabetical order.
+func Names() []string {
+	mu.RLock()
+	custom := make([]string, 0, len(registered))
+	for name := range registered {
+		custom = append(custom, name)
+	}
+	mu.RUnlock()
+	sort.Strings(custom)
+	return append(append([]string{}, Builtin...), custom...)
+}
@@ -0,0 +1,97 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	user := filepath.Join(root, "user")
+	project := filepath.Join(root, "project")
+	writeFile(t, user, "react.yaml", "name: react-expert\ndisplay_name: React Expert\nsystem_prompt: user prompt\n")
+	writeFile(t, user, "sql.yml", "name: sql-expert\nsystem_prompt: |\n  You review SQL.\n")
+	writeFile(t, user, "notes.txt", "not a persona")
+	writeFile(t, project, "react.yaml", "name: react-expert\ndisplay_name: React (team)\nsystem_prompt: project prompt\n")
+
+	personas, err := Load(user, project)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []Persona{
+		{Name: "react-expert", DisplayName: "React (team)", SystemPrompt: "project prompt"},
+		{Name: "sql-expert", SystemPrompt: "You review SQL."},
+	}
+	if !reflect.DeepEqual(personas, want) {
+		t.Errorf("Load = %+v, want %+v", personas, want)
+	}
+}
+
+func TestLoad_MissingDirs(t *testing.T) {
+	personas, err := Load("", filepath.Join(t.TempDir(), "absent"))
+	if err != nil || len(personas) != 0 {
+		t.Errorf("Load = %v, %v; want no personas and no error", personas, err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"display_name: X\nsystem_prompt: p\n", "missing required field: name"},
+		{"name: react-expert\n", "missing required field: system_prompt"},
+		{"name: React Expert\nsystem_prompt: p\n", "invalid name"},
+		{"name: security\nsystem_prompt: p\n", "built-in persona"},
+		{"name: [oops\n", "parsing"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFile(t, dir, "p.yaml", tt.content)
+		_, err := Load("", dir)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Load(%q) error = %v, want %q", tt.content, err, tt.wantErr)
+		}
+	}
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "name: dup\nsystem_prompt: a\n")
+	writeFile(t, dir, "b.yaml", "name: dup\nsystem_prompt: b\n")
+	if _, err := Load("", dir); err == nil || !strings.Contains(err.Error(), "defined in both") {
+		t.Errorf("expected a duplicate-name error, got %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register([]Persona{{Name: "sql-expert", SystemPrompt: "p"}})
+	defer Register(nil)
+
+	if !Known("sql-expert") || !Known("architect") || Known("nope") {
+		t.Error("Known does not reflect built-in and registered personas")
+	}
+	if p, ok := Lookup("sql-expert"); !ok || p.SystemPrompt != "p" {
+		t.Errorf("Lookup = %+v, %v", p, ok)
+	}
+	names := Names()
+	if names[len(names)-1] != "sql-expert" || names[0] != "code-reviewer" {
+		t.Errorf("Names = %v", names)
+	}
+
+	Register(nil)
+	if Known("sql-expert") {
+		t.Error("Register(nil) should clear custom personas")
+	}
+}
@@ -0,0 +1,88 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// PageSize is the number of items requested per page from list endpoints,
+// the largest page both GitHub and GitLab serve.
+const PageSize = 100
+
+// API sends JSON requests to a code review platform's REST API. The
+// platform clients supply what differs between them: how requests
+// authenticate and where a failed response explains itself.
+type API struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// SetHeaders adds the platform's authentication and version headers
+	// to every request.
+	SetHeaders func(*http.Request)
+	// ErrorMessage extracts the platform's explanation from the body of a
+	// non-2xx response, returning "" when it has none.
+	ErrorMessage func(body []byte) string
+}
+
+// Paginate GETs path with per_page and page parameters, handing each
+// page's body to decode, which returns the number of items it held. It
+// stops at the first page that is not full.
+func (a *API) Paginate(ctx context.Context, path string, decode func([]byte) (int, error)) error {
+	for page := 1; ; page++ {
+		q := url.Values{"per_page": {fmt.Sprint(PageSize)}, "page": {fmt.Sprint(page)}}
+		data, err := a.Do(ctx, http.MethodGet, path+"?"+q.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		n, err := decode(data)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		if n < PageSize {
+			return nil
+		}
+	}
+}
+
+// Do sends a request to BaseURL+path and returns the response body. A
+// non-nil body is sent as JSON. Any non-2xx status is an error, carrying
+// the platform's message when ErrorMessage finds one.
+func (a *API) Do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.BaseURL+path, r)
+	if err != nil {
+		return nil, err
+	}
+	if a.SetHeaders != nil {
+		a.SetHeaders(req)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: reading response: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if a.ErrorMessage != nil {
+			if msg := a.ErrorMessage(data); msg != "" {
+				return nil, fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, msg)
+			}
+		}
+		return nil, fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+	}
+	return data, nil
+}
@@ -0,0 +1,76 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAPI_PaginateStopsAtPartialPage(t *testing.T) {
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			t.Errorf("expected SetHeaders to run, got headers %v", r.Header)
+		}
+		if got := r.URL.Query().Get("per_page"); got != strconv.Itoa(PageSize) {
+			t.Errorf("per_page = %q, want %d", got, PageSize)
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		n := PageSize
+		if page == "2" {
+			n = 3
+		}
+		json.NewEncoder(w).Encode(make([]int, n))
+	}))
+	defer srv.Close()
+
+	api := &API{
+		BaseURL:    srv.URL,
+		HTTPClient: srv.Client(),
+		SetHeaders: func(req *http.Request) { req.Header.Set("Authorization", "token") },
+	}
+	var total int
+	err := api.Paginate(context.Background(), "/items", func(data []byte) (int, error) {
+		var page []int
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		total += len(page)
+		return len(page), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != PageSize+3 || strings.Join(pages, ",") != "1,2" {
+		t.Errorf("got %d items from pages %v, want %d from pages 1,2", total, pages, PageSize+3)
+	}
+}
+
+func TestAPI_DoReportsPlatformMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"reason":"line is outside the diff"}`))
+	}))
+	defer srv.Close()
+
+	api := &API{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	_, err := api.Do(context.Background(), http.MethodPost, "/comments", []byte(`{}`))
+	if err == nil || err.Error() != "POST /comments: status 422" {
+		t.Errorf("expected a bare status error without ErrorMessage, got %v", err)
+	}
+
+	api.ErrorMessage = func(body []byte) string {
+		var e struct{ Reason string }
+		json.Unmarshal(body, &e)
+		return e.Reason
+	}
+	_, err = api.Do(context.Background(), http.MethodPost, "/comments", []byte(`{}`))
+	if err == nil || !strings.HasSuffix(err.Error(), "status 422: line is outside the diff") {
+		t.Errorf("expected the platform's message in the error, got %v", err)
+	}
+}
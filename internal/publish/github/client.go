@@ -0,0 +1,159 @@
+// Package github publishes Gavel findings to GitHub pull requests as
+// inline review comments.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/publish"
+)
+
+// DefaultBaseURL is the GitHub REST API endpoint for github.com.
+const DefaultBaseURL = "https://api.github.com"
+
+// Client is a minimal GitHub REST API client covering the pull request
+// endpoints the publisher needs.
+type Client struct {
+	api publish.API
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL points the client at a GitHub Enterprise Server API (e.g.
+// https://github.example.com/api/v3) or a test server.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		if u != "" {
+			c.api.BaseURL = strings.TrimRight(u, "/")
+		}
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.api.HTTPClient = hc
+	}
+}
+
+// NewClient creates a client that authenticates with token.
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{api: publish.API{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		SetHeaders: func(req *http.Request) {
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+		},
+		ErrorMessage: errorMessage,
+	}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// pullFile is an entry of GET /repos/{owner}/{repo}/pulls/{number}/files.
+type pullFile struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	Patch    string `json:"patch"`
+}
+
+// reviewComment is an entry of GET /repos/{owner}/{repo}/pulls/{number}/comments.
+type reviewComment struct {
+	ID   int64  `json:"id"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+}
+
+// review is an entry of GET /repos/{owner}/{repo}/pulls/{number}/reviews.
+type review struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// draftComment is an inline comment submitted with a review.
+type draftComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// reviewRequest is the body of POST /repos/{owner}/{repo}/pulls/{number}/reviews.
+type reviewRequest struct {
+	CommitID string         `json:"commit_id,omitempty"`
+	Body     string         `json:"body,omitempty"`
+	Event    string         `json:"event"`
+	Comments []draftComment `json:"comments,omitempty"`
+}
+
+func (c *Client) listPullFiles(ctx context.Context, pr PullRequest) ([]pullFile, error) {
+	var all []pullFile
+	err := c.api.Paginate(ctx, pr.path("files"), func(data []byte) (int, error) {
+		var page []pullFile
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+func (c *Client) listReviewComments(ctx context.Context, pr PullRequest) ([]reviewComment, error) {
+	var all []reviewComment
+	err := c.api.Paginate(ctx, pr.path("comments"), func(data []byte) (int, error) {
+		var page []reviewComment
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+func (c *Client) listReviews(ctx context.Context, pr PullRequest) ([]review, error) {
+	var all []review
+	err := c.api.Paginate(ctx, pr.path("reviews"), func(data []byte) (int, error) {
+		var page []review
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+func (c *Client) createReview(ctx context.Context, pr PullRequest, req reviewRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal review: %w", err)
+	}
+	_, err = c.api.Do(ctx, http.MethodPost, pr.path("reviews"), data)
+	return err
+}
+
+// errorMessage returns the message field of a GitHub error response.
+func errorMessage(body []byte) string {
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	return apiErr.Message
+}
@@ -0,0 +1,69 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/publish"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Publish posts the findings in log to pr as one review: each finding on a
+// line the pull request changes becomes an inline comment, and summary
+// (typically the Markdown formatter's output) becomes the review body.
+// Findings that an earlier run already commented on are not repeated, and
+// when there is nothing new and a summary was already posted, no review is
+// submitted. See publish.Comments for which findings are posted.
+func Publish(ctx context.Context, c *Client, pr PullRequest, log *sarif.Log, summary string) (publish.Report, error) {
+	if err := pr.Validate(); err != nil {
+		return publish.Report{}, err
+	}
+
+	files, err := c.listPullFiles(ctx, pr)
+	if err != nil {
+		return publish.Report{}, fmt.Errorf("listing pull request files: %w", err)
+	}
+	commentable := make(map[string]map[int]bool, len(files))
+	for _, f := range files {
+		commentable[f.Filename] = publish.DiffLines(f.Patch)
+	}
+
+	existing, err := c.listReviewComments(ctx, pr)
+	if err != nil {
+		return publish.Report{}, fmt.Errorf("listing review comments: %w", err)
+	}
+	bodies := make([]string, len(existing))
+	for i, rc := range existing {
+		bodies[i] = rc.Body
+	}
+
+	comments, report := publish.Comments(log, commentable, publish.PostedFingerprints(bodies))
+	if len(comments) == 0 {
+		reviews, err := c.listReviews(ctx, pr)
+		if err != nil {
+			return report, fmt.Errorf("listing reviews: %w", err)
+		}
+		bodies = bodies[:0]
+		for _, rv := range reviews {
+			bodies = append(bodies, rv.Body)
+		}
+		if publish.HasSummary(bodies) {
+			return report, nil
+		}
+	}
+
+	req := reviewRequest{
+		CommitID: pr.HeadSHA,
+		Body:     publish.SummaryMarker + "\n" + summary,
+		Event:    "COMMENT",
+	}
+	for _, cm := range comments {
+		req.Comments = append(req.Comments, draftComment{Path: cm.Path, Line: cm.Line, Side: "RIGHT", Body: cm.Body})
+	}
+	if err := c.createReview(ctx, pr, req); err != nil {
+		return report, fmt.Errorf("creating review: %w", err)
+	}
+	report.Posted = len(comments)
+	report.Summarized = true
+	return report, nil
+}
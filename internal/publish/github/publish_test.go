@@ -0,0 +1,180 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/publish"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// fakeGitHub serves the pull request endpoints Publish uses and records
+// submitted reviews.
+type fakeGitHub struct {
+	files    []pullFile
+	comments []reviewComment
+	reviews  []review
+	created  []reviewRequest
+}
+
+func (f *fakeGitHub) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	prefix := "/repos/acme/app/pulls/7/"
+	respond := func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		// Everything fits on the first page.
+		if r.URL.Query().Get("page") != "1" {
+			v = []struct{}{}
+		}
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET "+prefix+"files", func(w http.ResponseWriter, r *http.Request) { respond(w, r, f.files) })
+	mux.HandleFunc("GET "+prefix+"comments", func(w http.ResponseWriter, r *http.Request) { respond(w, r, f.comments) })
+	mux.HandleFunc("GET "+prefix+"reviews", func(w http.ResponseWriter, r *http.Request) { respond(w, r, f.reviews) })
+	mux.HandleFunc("POST "+prefix+"reviews", func(w http.ResponseWriter, r *http.Request) {
+		var req reviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding review: %v", err)
+		}
+		f.created = append(f.created, req)
+		for _, c := range req.Comments {
+			f.comments = append(f.comments, reviewComment{Path: c.Path, Line: c.Line, Body: c.Body})
+		}
+		f.reviews = append(f.reviews, review{Body: req.Body})
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func result(ruleID, file string, line int, msg string) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   "warning",
+		Message: sarif.Message{Text: msg},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: file},
+			Region:           sarif.Region{StartLine: line, EndLine: line},
+		}}},
+	}
+}
+
+func TestPublish(t *testing.T) {
+	gh := &fakeGitHub{files: []pullFile{{
+		Filename: "internal/db.go",
+		Patch:    "@@ -10,3 +10,4 @@ func q() {\n ctx := context.Background()\n-rows := db.Query(q)\n+q := \"SELECT \" + id\n+rows := db.Query(q)\n return rows",
+	}}}
+	srv := gh.server(t)
+	client := NewClient("tok", WithBaseURL(srv.URL))
+	pr := PullRequest{Owner: "acme", Repo: "app", Number: 7, HeadSHA: "abc123"}
+
+	suppressed := result("S1135", "internal/db.go", 11, "todo")
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	baselined := result("S2068", "internal/db.go", 12, "password")
+	baselined.BaselineState = sarif.BaselineStateUnchanged
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		result("S3649", "./internal/db.go", 11, "SQL built from input"),
+		result("S109", "internal/db.go", 40, "magic number"),
+		result("S109", "other.go", 1, "magic number"),
+		suppressed,
+		baselined,
+	}
+
+	report, err := Publish(context.Background(), client, pr, log, "## Gavel Analysis Summary")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	want := publish.Report{Posted: 1, OutsideDiff: 2, Skipped: 2, Summarized: true}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	if len(gh.created) != 1 {
+		t.Fatalf("expected one review, got %d", len(gh.created))
+	}
+	rv := gh.created[0]
+	if rv.CommitID != "abc123" || rv.Event != "COMMENT" {
+		t.Errorf("review commit/event = %q/%q", rv.CommitID, rv.Event)
+	}
+	if !strings.Contains(rv.Body, publish.SummaryMarker) || !strings.Contains(rv.Body, "## Gavel Analysis Summary") {
+		t.Errorf("review body missing summary: %q", rv.Body)
+	}
+	if len(rv.Comments) != 1 {
+		t.Fatalf("expected one inline comment, got %+v", rv.Comments)
+	}
+	c := rv.Comments[0]
+	if c.Path != "internal/db.go" || c.Line != 11 || c.Side != "RIGHT" {
+		t.Errorf("comment location = %s:%d (%s)", c.Path, c.Line, c.Side)
+	}
+	if !strings.Contains(c.Body, "`S3649`: SQL built from input") || !strings.Contains(c.Body, "<!-- gavel:fingerprint=") {
+		t.Errorf("unexpected comment body %q", c.Body)
+	}
+
+	// A rerun with the same findings posts nothing new.
+	report, err = Publish(context.Background(), client, pr, log, "## Gavel Analysis Summary")
+	if err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if report.Posted != 0 || report.Duplicates != 1 || report.Summarized {
+		t.Errorf("rerun report = %+v, want only a duplicate", report)
+	}
+	if len(gh.created) != 1 {
+		t.Errorf("rerun submitted another review")
+	}
+}
+
+func TestPublish_RequiresPullRequest(t *testing.T) {
+	_, err := Publish(context.Background(), NewClient(""), PullRequest{Owner: "acme", Repo: "app"}, sarif.NewLog("gavel", "test"), "")
+	if err == nil || !strings.Contains(err.Error(), "pull request number") {
+		t.Fatalf("expected a missing number error, got %v", err)
+	}
+}
+
+func TestPublish_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+	}))
+	defer srv.Close()
+
+	pr := PullRequest{Owner: "acme", Repo: "app", Number: 7}
+	_, err := Publish(context.Background(), NewClient("tok", WithBaseURL(srv.URL)), pr, sarif.NewLog("gavel", "test"), "")
+	if err == nil || !strings.Contains(err.Error(), "status 403: Resource not accessible by integration") {
+		t.Fatalf("expected the API message in the error, got %v", err)
+	}
+}
+
+func TestPullRequestFromEnv(t *testing.T) {
+	event := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(event, []byte(`{"pull_request": {"number": 42, "head": {"sha": "deadbeef"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_REPOSITORY", "acme/app")
+	t.Setenv("GITHUB_EVENT_PATH", event)
+
+	pr, err := PullRequestFromEnv()
+	if err != nil {
+		t.Fatalf("PullRequestFromEnv: %v", err)
+	}
+	want := PullRequest{Owner: "acme", Repo: "app", Number: 42, HeadSHA: "deadbeef"}
+	if pr != want {
+		t.Errorf("PullRequestFromEnv = %+v, want %+v", pr, want)
+	}
+
+	if _, _, err := ParseRepository("acme"); err == nil {
+		t.Error("expected an error for a repository without an owner")
+	}
+}
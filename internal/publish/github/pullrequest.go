@@ -0,0 +1,83 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PullRequest identifies the pull request to publish to. HeadSHA is the
+// commit the review comments are attached to; when empty GitHub uses the
+// pull request's current head.
+type PullRequest struct {
+	Owner   string
+	Repo    string
+	Number  int
+	HeadSHA string
+}
+
+// ParseRepository splits an "owner/repo" string.
+func ParseRepository(s string) (owner, repo string, err error) {
+	owner, repo, ok := strings.Cut(s, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("repository must be owner/repo, got %q", s)
+	}
+	return owner, repo, nil
+}
+
+// PullRequestFromEnv reads the pull request from a GitHub Actions
+// environment: the repository from GITHUB_REPOSITORY and the number and
+// head commit from the event payload at GITHUB_EVENT_PATH. Fields that
+// cannot be determined are left zero for the caller to fill in.
+func PullRequestFromEnv() (PullRequest, error) {
+	var pr PullRequest
+	if repo := os.Getenv("GITHUB_REPOSITORY"); repo != "" {
+		owner, name, err := ParseRepository(repo)
+		if err != nil {
+			return pr, fmt.Errorf("GITHUB_REPOSITORY: %w", err)
+		}
+		pr.Owner, pr.Repo = owner, name
+	}
+
+	eventPath := os.Getenv("GITHUB_EVENT_PATH")
+	if eventPath == "" {
+		return pr, nil
+	}
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		return pr, fmt.Errorf("reading GitHub event: %w", err)
+	}
+	var event struct {
+		PullRequest *struct {
+			Number int `json:"number"`
+			Head   struct {
+				SHA string `json:"sha"`
+			} `json:"head"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return pr, fmt.Errorf("parsing GitHub event: %w", err)
+	}
+	if event.PullRequest != nil {
+		pr.Number = event.PullRequest.Number
+		pr.HeadSHA = event.PullRequest.Head.SHA
+	}
+	return pr, nil
+}
+
+// Validate reports whether pr identifies a pull request.
+func (pr PullRequest) Validate() error {
+	if pr.Owner == "" || pr.Repo == "" {
+		return fmt.Errorf("repository is required")
+	}
+	if pr.Number <= 0 {
+		return fmt.Errorf("pull request number is required")
+	}
+	return nil
+}
+
+// path returns the API path of a pull request sub-resource.
+func (pr PullRequest) path(resource string) string {
+	return fmt.Sprintf("/repos/%s/%s/pulls/%d/%s", pr.Owner, pr.Repo, pr.Number, resource)
+}
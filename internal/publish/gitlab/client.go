@@ -0,0 +1,175 @@
+// Package gitlab publishes Gavel findings to GitLab merge requests as
+// diff discussions.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/publish"
+)
+
+// DefaultBaseURL is the REST API endpoint for gitlab.com.
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+// Client is a minimal GitLab REST API client covering the merge request
+// endpoints the publisher needs.
+type Client struct {
+	api publish.API
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL points the client at a self-hosted GitLab API (e.g.
+// https://gitlab.example.com/api/v4) or a test server.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		if u != "" {
+			c.api.BaseURL = strings.TrimRight(u, "/")
+		}
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.api.HTTPClient = hc
+	}
+}
+
+// NewClient creates a client that authenticates with a personal, project
+// or group access token.
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{api: publish.API{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		SetHeaders: func(req *http.Request) {
+			if token != "" {
+				req.Header.Set("PRIVATE-TOKEN", token)
+			}
+		},
+		ErrorMessage: errorMessage,
+	}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// diffRefs are the commits a merge request diff is computed between.
+type diffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// mergeRequestDiff is an entry of GET /projects/:id/merge_requests/:iid/diffs.
+type mergeRequestDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// discussion is an entry of GET /projects/:id/merge_requests/:iid/discussions.
+type discussion struct {
+	ID    string `json:"id"`
+	Notes []struct {
+		Body string `json:"body"`
+	} `json:"notes"`
+}
+
+// position anchors a discussion to a line of the merge request diff.
+type position struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	StartSHA     string `json:"start_sha"`
+	HeadSHA      string `json:"head_sha"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+}
+
+// newDiscussion is the body of POST /projects/:id/merge_requests/:iid/discussions.
+type newDiscussion struct {
+	Body     string    `json:"body"`
+	Position *position `json:"position,omitempty"`
+}
+
+func (c *Client) getDiffRefs(ctx context.Context, mr MergeRequest) (diffRefs, error) {
+	var body struct {
+		DiffRefs *diffRefs `json:"diff_refs"`
+	}
+	data, err := c.api.Do(ctx, http.MethodGet, mr.path(""), nil)
+	if err != nil {
+		return diffRefs{}, err
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return diffRefs{}, fmt.Errorf("decode merge request: %w", err)
+	}
+	if body.DiffRefs == nil {
+		return diffRefs{}, fmt.Errorf("merge request has no diff yet")
+	}
+	return *body.DiffRefs, nil
+}
+
+func (c *Client) listDiffs(ctx context.Context, mr MergeRequest) ([]mergeRequestDiff, error) {
+	var all []mergeRequestDiff
+	err := c.api.Paginate(ctx, mr.path("/diffs"), func(data []byte) (int, error) {
+		var page []mergeRequestDiff
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+func (c *Client) listDiscussions(ctx context.Context, mr MergeRequest) ([]discussion, error) {
+	var all []discussion
+	err := c.api.Paginate(ctx, mr.path("/discussions"), func(data []byte) (int, error) {
+		var page []discussion
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+func (c *Client) createDiscussion(ctx context.Context, mr MergeRequest, d newDiscussion) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("marshal discussion: %w", err)
+	}
+	_, err = c.api.Do(ctx, http.MethodPost, mr.path("/discussions"), data)
+	return err
+}
+
+// errorMessage returns the explanation in a GitLab error response, which is
+// {"message": ...} or {"error": ...}, where message may be a string or an
+// object of field errors.
+func errorMessage(body []byte) string {
+	var apiErr struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	var msg string
+	if json.Unmarshal(apiErr.Message, &msg) != nil {
+		msg = string(apiErr.Message)
+	}
+	if msg == "" {
+		msg = apiErr.Error
+	}
+	return msg
+}
@@ -0,0 +1,47 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// MergeRequest identifies the merge request to publish to. Project is the
+// numeric project ID or its full path (e.g. "group/app").
+type MergeRequest struct {
+	Project string
+	IID     int
+}
+
+// MergeRequestFromEnv reads the merge request from a GitLab CI merge
+// request pipeline: the project from CI_PROJECT_ID and the merge request
+// from CI_MERGE_REQUEST_IID. Fields that cannot be determined are left
+// zero for the caller to fill in.
+func MergeRequestFromEnv() (MergeRequest, error) {
+	mr := MergeRequest{Project: os.Getenv("CI_PROJECT_ID")}
+	if iid := os.Getenv("CI_MERGE_REQUEST_IID"); iid != "" {
+		n, err := strconv.Atoi(iid)
+		if err != nil {
+			return mr, fmt.Errorf("CI_MERGE_REQUEST_IID: %w", err)
+		}
+		mr.IID = n
+	}
+	return mr, nil
+}
+
+// Validate reports whether mr identifies a merge request.
+func (mr MergeRequest) Validate() error {
+	if mr.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if mr.IID <= 0 {
+		return fmt.Errorf("merge request IID is required")
+	}
+	return nil
+}
+
+// path returns the API path of the merge request followed by suffix.
+func (mr MergeRequest) path(suffix string) string {
+	return fmt.Sprintf("/projects/%s/merge_requests/%d%s", url.PathEscape(mr.Project), mr.IID, suffix)
+}
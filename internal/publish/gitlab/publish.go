@@ -0,0 +1,82 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/publish"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// Publish posts the findings in log to mr: each finding on a line the
+// merge request changes becomes a diff discussion, and summary (typically
+// the Markdown formatter's output) is posted as a general discussion.
+// Findings that an earlier run already commented on are not repeated, and
+// when there is nothing new and a summary was already posted, the summary
+// is not posted again. See publish.Comments for which findings are posted.
+//
+// GitLab has no batch endpoint, so discussions are created one at a time;
+// if one fails, the report counts those posted before it.
+func Publish(ctx context.Context, c *Client, mr MergeRequest, log *sarif.Log, summary string) (publish.Report, error) {
+	if err := mr.Validate(); err != nil {
+		return publish.Report{}, err
+	}
+
+	refs, err := c.getDiffRefs(ctx, mr)
+	if err != nil {
+		return publish.Report{}, fmt.Errorf("reading merge request: %w", err)
+	}
+	diffs, err := c.listDiffs(ctx, mr)
+	if err != nil {
+		return publish.Report{}, fmt.Errorf("listing merge request diffs: %w", err)
+	}
+	commentable := make(map[string]map[int]bool, len(diffs))
+	oldPaths := make(map[string]string, len(diffs))
+	for _, d := range diffs {
+		if d.DeletedFile {
+			continue
+		}
+		commentable[d.NewPath] = publish.DiffLines(d.Diff)
+		oldPaths[d.NewPath] = d.OldPath
+	}
+
+	discussions, err := c.listDiscussions(ctx, mr)
+	if err != nil {
+		return publish.Report{}, fmt.Errorf("listing discussions: %w", err)
+	}
+	var bodies []string
+	for _, d := range discussions {
+		for _, n := range d.Notes {
+			bodies = append(bodies, n.Body)
+		}
+	}
+
+	comments, report := publish.Comments(log, commentable, publish.PostedFingerprints(bodies))
+	for _, cm := range comments {
+		err := c.createDiscussion(ctx, mr, newDiscussion{
+			Body: cm.Body,
+			Position: &position{
+				PositionType: "text",
+				BaseSHA:      refs.BaseSHA,
+				StartSHA:     refs.StartSHA,
+				HeadSHA:      refs.HeadSHA,
+				OldPath:      oldPaths[cm.Path],
+				NewPath:      cm.Path,
+				NewLine:      cm.Line,
+			},
+		})
+		if err != nil {
+			return report, fmt.Errorf("creating discussion on %s:%d: %w", cm.Path, cm.Line, err)
+		}
+		report.Posted++
+	}
+
+	if len(comments) == 0 && publish.HasSummary(bodies) {
+		return report, nil
+	}
+	if err := c.createDiscussion(ctx, mr, newDiscussion{Body: publish.SummaryMarker + "\n" + summary}); err != nil {
+		return report, fmt.Errorf("creating summary discussion: %w", err)
+	}
+	report.Summarized = true
+	return report, nil
+}
@@ -0,0 +1,162 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/publish"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// fakeGitLab serves the merge request endpoints Publish uses and records
+// created discussions.
+type fakeGitLab struct {
+	diffs       []mergeRequestDiff
+	discussions []discussion
+	created     []newDiscussion
+}
+
+func (f *fakeGitLab) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	// The project path is escaped, so the pattern matches the raw path.
+	prefix := "/projects/group%2Fapp/merge_requests/5"
+	check := func(r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "tok" {
+			t.Errorf("PRIVATE-TOKEN = %q", got)
+		}
+	}
+	list := func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		check(r)
+		if r.URL.Query().Get("page") != "1" {
+			v = []struct{}{}
+		}
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET "+prefix, func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		fmt.Fprint(w, `{"iid": 5, "diff_refs": {"base_sha": "b", "head_sha": "h", "start_sha": "s"}}`)
+	})
+	mux.HandleFunc("GET "+prefix+"/diffs", func(w http.ResponseWriter, r *http.Request) { list(w, r, f.diffs) })
+	mux.HandleFunc("GET "+prefix+"/discussions", func(w http.ResponseWriter, r *http.Request) { list(w, r, f.discussions) })
+	mux.HandleFunc("POST "+prefix+"/discussions", func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		var d newDiscussion
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			t.Errorf("decoding discussion: %v", err)
+		}
+		f.created = append(f.created, d)
+		var posted discussion
+		posted.Notes = append(posted.Notes, struct {
+			Body string `json:"body"`
+		}{d.Body})
+		f.discussions = append(f.discussions, posted)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "abc"}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func result(ruleID, file string, line int) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   "error",
+		Message: sarif.Message{Text: ruleID + " message"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: file},
+			Region:           sarif.Region{StartLine: line, EndLine: line},
+		}}},
+	}
+}
+
+func TestPublish(t *testing.T) {
+	gl := &fakeGitLab{diffs: []mergeRequestDiff{
+		{OldPath: "app/old.py", NewPath: "app/db.py", Diff: "@@ -1,2 +1,3 @@\n import os\n+q = \"SELECT \" + name\n cur.execute(q)"},
+		{OldPath: "gone.py", NewPath: "gone.py", Diff: "@@ -1 +0,0 @@\n-x = 1", DeletedFile: true},
+	}}
+	srv := gl.server(t)
+	client := NewClient("tok", WithBaseURL(srv.URL))
+	mr := MergeRequest{Project: "group/app", IID: 5}
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		result("S3649", "app/db.py", 2),
+		result("S109", "app/db.py", 50),
+		result("S109", "gone.py", 1),
+	}
+
+	report, err := Publish(context.Background(), client, mr, log, "## Gavel Analysis Summary")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	want := publish.Report{Posted: 1, OutsideDiff: 2, Summarized: true}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	if len(gl.created) != 2 {
+		t.Fatalf("expected an inline discussion and a summary, got %+v", gl.created)
+	}
+	inline := gl.created[0]
+	wantPos := position{PositionType: "text", BaseSHA: "b", StartSHA: "s", HeadSHA: "h", OldPath: "app/old.py", NewPath: "app/db.py", NewLine: 2}
+	if inline.Position == nil || *inline.Position != wantPos {
+		t.Errorf("position = %+v, want %+v", inline.Position, wantPos)
+	}
+	if !strings.Contains(inline.Body, "`S3649`") {
+		t.Errorf("unexpected inline body %q", inline.Body)
+	}
+	summary := gl.created[1]
+	if summary.Position != nil || !strings.HasPrefix(summary.Body, publish.SummaryMarker) {
+		t.Errorf("unexpected summary discussion %+v", summary)
+	}
+
+	// A rerun with the same findings posts nothing.
+	report, err = Publish(context.Background(), client, mr, log, "## Gavel Analysis Summary")
+	if err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if report.Posted != 0 || report.Duplicates != 1 || report.Summarized || len(gl.created) != 2 {
+		t.Errorf("rerun report = %+v after %d discussions, want only a duplicate", report, len(gl.created))
+	}
+}
+
+func TestPublish_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message": "401 Unauthorized"}`)
+	}))
+	defer srv.Close()
+
+	mr := MergeRequest{Project: "7", IID: 5}
+	_, err := Publish(context.Background(), NewClient("bad", WithBaseURL(srv.URL)), mr, sarif.NewLog("gavel", "test"), "")
+	if err == nil || !strings.Contains(err.Error(), "status 401: 401 Unauthorized") {
+		t.Fatalf("expected the API message in the error, got %v", err)
+	}
+}
+
+func TestMergeRequestFromEnv(t *testing.T) {
+	t.Setenv("CI_PROJECT_ID", "1234")
+	t.Setenv("CI_MERGE_REQUEST_IID", "17")
+	mr, err := MergeRequestFromEnv()
+	if err != nil {
+		t.Fatalf("MergeRequestFromEnv: %v", err)
+	}
+	if mr != (MergeRequest{Project: "1234", IID: 17}) {
+		t.Errorf("MergeRequestFromEnv = %+v", mr)
+	}
+
+	t.Setenv("CI_MERGE_REQUEST_IID", "")
+	mr, err = MergeRequestFromEnv()
+	if err != nil {
+		t.Fatalf("MergeRequestFromEnv: %v", err)
+	}
+	if err := mr.Validate(); err == nil || !strings.Contains(err.Error(), "IID") {
+		t.Errorf("expected a missing IID error, got %v", err)
+	}
+}
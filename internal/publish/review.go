@@ -0,0 +1,209 @@
+// Package publish holds what the code review publishers share: selecting
+// the findings to post, anchoring them to diff lines, recognizing
+// comments posted by earlier runs, and sending paginated REST requests.
+// The platform clients live in the github and gitlab subpackages.
+package publish
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chris-regnier/gavel/internal/codeowners"
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// SummaryMarker tags the summary Gavel posts, so a rerun with nothing new
+// to say does not repeat it.
+const SummaryMarker = "<!-- gavel:summary -->"
+
+// fingerprintMarker matches the hidden marker that identifies the finding
+// behind an inline comment.
+var fingerprintMarker = regexp.MustCompile(`<!-- gavel:fingerprint=([0-9a-f]+) -->`)
+
+// hunkHeader matches a unified diff hunk header, capturing the first line
+// of the new side.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// Report summarizes what a publisher did.
+type Report struct {
+	// Posted is the number of inline comments submitted.
+	Posted int `json:"posted"`
+	// Duplicates counts findings already commented on by an earlier run.
+	Duplicates int `json:"duplicates"`
+	// OutsideDiff counts findings on lines the change does not touch;
+	// platforms only accept inline comments on diff lines, so these appear
+	// in the summary alone.
+	OutsideDiff int `json:"outside_diff"`
+	// Skipped counts suppressed findings and findings that predate the
+	// baseline.
+	Skipped int `json:"skipped"`
+	// Summarized reports whether the summary was posted.
+	Summarized bool `json:"summarized"`
+}
+
+// Comment is a finding anchored to a line of the change.
+type Comment struct {
+	Path string
+	Line int
+	Body string
+	// Result is the finding the comment reports.
+	Result sarif.Result
+}
+
+// Comments selects the findings in log to post inline. commentable maps
+// each changed file to the new-side lines its diff shows (see DiffLines);
+// posted holds the fingerprints of findings earlier runs commented on (see
+// PostedFingerprints). Suppressed and informational findings and findings
+// the baseline marks unchanged or absent are left out. The returned report
+// counts every finding that was not selected, and why.
+func Comments(log *sarif.Log, commentable map[string]map[int]bool, posted map[string]bool) ([]Comment, Report) {
+	var report Report
+	var comments []Comment
+	seen := make(map[string]bool, len(posted))
+	for fp := range posted {
+		seen[fp] = true
+	}
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 ||
+				r.BaselineState == sarif.BaselineStateUnchanged ||
+				r.BaselineState == sarif.BaselineStateAbsent ||
+				r.Kind == sarif.KindInformational {
+				report.Skipped++
+				continue
+			}
+			file, line, ok := commentLocation(r, commentable)
+			if !ok {
+				report.OutsideDiff++
+				continue
+			}
+			fp := Fingerprint(r)
+			if seen[fp] {
+				report.Duplicates++
+				continue
+			}
+			seen[fp] = true
+			comments = append(comments, Comment{Path: file, Line: line, Body: commentBody(r, fp), Result: r})
+		}
+	}
+	return comments, report
+}
+
+// PostedFingerprints collects the finding fingerprints marked in the
+// bodies of existing comments.
+func PostedFingerprints(bodies []string) map[string]bool {
+	posted := make(map[string]bool)
+	for _, body := range bodies {
+		if m := fingerprintMarker.FindStringSubmatch(body); m != nil {
+			posted[m[1]] = true
+		}
+	}
+	return posted
+}
+
+// HasSummary reports whether any of bodies is a summary Gavel posted.
+func HasSummary(bodies []string) bool {
+	for _, body := range bodies {
+		if strings.Contains(body, SummaryMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentLocation returns the file and line to attach r's comment to: the
+// first line of r's region that the diff includes.
+func commentLocation(r sarif.Result, commentable map[string]map[int]bool) (string, int, bool) {
+	if len(r.Locations) == 0 {
+		return "", 0, false
+	}
+	loc := r.Locations[0].PhysicalLocation
+	file := NormalizePath(loc.ArtifactLocation.URI)
+	lines := commentable[file]
+	if lines == nil || loc.Region.StartLine <= 0 {
+		return "", 0, false
+	}
+	end := loc.Region.EndLine
+	if end < loc.Region.StartLine {
+		end = loc.Region.StartLine
+	}
+	for line := loc.Region.StartLine; line <= end; line++ {
+		if lines[line] {
+			return file, line, true
+		}
+	}
+	return "", 0, false
+}
+
+// NormalizePath converts a SARIF artifact URI to the repository-relative
+// form code review platforms use for changed files.
+func NormalizePath(uri string) string {
+	p := strings.TrimPrefix(uri, "file://")
+	p = strings.ReplaceAll(p, "\\", "/")
+	return strings.TrimPrefix(path.Clean(p), "./")
+}
+
+// DiffLines returns the new-side line numbers a unified diff shows, which
+// are the lines review platforms accept inline comments on.
+func DiffLines(diff string) map[int]bool {
+	lines := make(map[int]bool)
+	line := 0
+	for _, text := range strings.Split(diff, "\n") {
+		if m := hunkHeader.FindStringSubmatch(text); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if line == 0 || text == "" {
+			continue
+		}
+		switch text[0] {
+		case '+', ' ':
+			lines[line] = true
+			line++
+		}
+	}
+	return lines
+}
+
+// Fingerprint identifies r across runs by its rule and file, plus its
+// content fingerprint when analysis recorded one, otherwise its line and
+// message. The content fingerprint covers only the rule and the code, so
+// the file keeps identical lines in two files apart.
+func Fingerprint(r sarif.Result) string {
+	var file string
+	var line int
+	if len(r.Locations) > 0 {
+		file = NormalizePath(r.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		line = r.Locations[0].PhysicalLocation.Region.StartLine
+	}
+	key := fmt.Sprintf("%s\x00%s\x00%d\x00%s", r.RuleID, file, line, r.Message.Text)
+	if fp := r.Fingerprints[sarif.ContentFingerprintV1]; fp != "" {
+		key = fmt.Sprintf("%s\x00%s\x00%s", r.RuleID, file, fp)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%x", sum[:16])
+}
+
+// commentBody renders the inline comment for r, mentioning its CODEOWNERS
+// owners so the platform notifies them, and ending with the hidden
+// fingerprint marker used to recognize it on later runs.
+func commentBody(r sarif.Result, fp string) string {
+	var b strings.Builder
+	level := r.Level
+	if level == "" {
+		level = "note"
+	}
+	fmt.Fprintf(&b, "**%s** `%s`: %s\n", level, r.RuleID, r.Message.Text)
+	if rec, ok := r.Properties["gavel/recommendation"].(string); ok && rec != "" {
+		fmt.Fprintf(&b, "\n**Recommendation:** %s\n", rec)
+	}
+	if owners := codeowners.Of(r); len(owners) > 0 {
+		fmt.Fprintf(&b, "\n**Owners:** %s\n", strings.Join(owners, " "))
+	}
+	fmt.Fprintf(&b, "\n<!-- gavel:fingerprint=%s -->", fp)
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package publish
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func result(ruleID, file string, start, end int) sarif.Result {
+	return sarif.Result{
+		RuleID:  ruleID,
+		Level:   "warning",
+		Message: sarif.Message{Text: ruleID + " message"},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: file},
+			Region:           sarif.Region{StartLine: start, EndLine: end},
+		}}},
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -20,2 +20,3 @@\n x\n+y\n\\ No newline at end of file"
+	got := DiffLines(diff)
+	want := map[int]bool{1: true, 2: true, 3: true, 20: true, 21: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffLines = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	for in, want := range map[string]string{
+		"internal/db.go":          "internal/db.go",
+		"./internal/db.go":        "internal/db.go",
+		"file://internal/./db.go": "internal/db.go",
+		`internal\db.go`:          "internal/db.go",
+	} {
+		if got := NormalizePath(in); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	commentable := map[string]map[int]bool{"a.go": {10: true, 11: true}}
+
+	suppressed := result("S1135", "a.go", 10, 10)
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	absent := result("S2068", "a.go", 10, 10)
+	absent.BaselineState = sarif.BaselineStateAbsent
+	already := result("S109", "a.go", 11, 11)
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{
+		result("S3649", "a.go", 8, 10), // anchored to its first diff line
+		result("S3649", "a.go", 8, 10), // same finding twice
+		result("S4792", "a.go", 30, 30),
+		result("S4792", "b.go", 1, 1),
+		suppressed,
+		absent,
+		already,
+	}
+	posted := PostedFingerprints([]string{"looks fine", commentBody(already, Fingerprint(already))})
+
+	comments, report := Comments(log, commentable, posted)
+	want := Report{Duplicates: 2, OutsideDiff: 2, Skipped: 2}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	if len(comments) != 1 {
+		t.Fatalf("expected one comment, got %+v", comments)
+	}
+	c := comments[0]
+	if c.Path != "a.go" || c.Line != 10 || c.Result.RuleID != "S3649" {
+		t.Errorf("comment = %s:%d %s", c.Path, c.Line, c.Result.RuleID)
+	}
+	if !strings.Contains(c.Body, "**warning** `S3649`") || !strings.HasSuffix(c.Body, "<!-- gavel:fingerprint="+Fingerprint(c.Result)+" -->") {
+		t.Errorf("unexpected body %q", c.Body)
+	}
+}
+
+func TestFingerprint_PrefersContentFingerprint(t *testing.T) {
+	r := result("S3649", "a.go", 1, 1)
+	r.Fingerprints = map[string]string{sarif.ContentFingerprintV1: "abc123"}
+	moved := result("S3649", "a.go", 7, 7)
+	moved.Message.Text = "reworded"
+	moved.Fingerprints = map[string]string{sarif.ContentFingerprintV1: "abc123"}
+	if Fingerprint(r) != Fingerprint(moved) {
+		t.Error("with a content fingerprint, a finding that moved or was reworded should keep its fingerprint")
+	}
+
+	if Fingerprint(result("S3649", "a.go", 1, 1)) == Fingerprint(result("S3649", "a.go", 2, 2)) {
+		t.Error("without a content fingerprint, the location distinguishes findings")
+	}
+}
+
+func TestFingerprint_DistinguishesFiles(t *testing.T) {
+	a := result("S3649", "a.go", 5, 5)
+	b := result("S3649", "./b.go", 5, 5)
+	for _, r := range []*sarif.Result{&a, &b} {
+		r.Fingerprints = map[string]string{sarif.ContentFingerprintV1: "abc123"}
+	}
+	if Fingerprint(a) == Fingerprint(b) {
+		t.Fatal("the same finding on identical lines of two files should have different fingerprints")
+	}
+
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = []sarif.Result{a, b}
+	commentable := map[string]map[int]bool{"a.go": {5: true}, "b.go": {5: true}}
+	comments, report := Comments(log, commentable, PostedFingerprints([]string{commentBody(a, Fingerprint(a))}))
+	if report.Duplicates != 1 || len(comments) != 1 || comments[0].Path != "b.go" {
+		t.Errorf("expected only b.go's comment to be posted, got %+v (report %+v)", comments, report)
+	}
+}
+
+func TestHasSummary(t *testing.T) {
+	if HasSummary([]string{"lgtm"}) {
+		t.Error("unexpected summary")
+	}
+	if !HasSummary([]string{"lgtm", SummaryMarker + "\n## Gavel Analysis Summary"}) {
+		t.Error("expected the marked summary to be found")
+	}
+}
+
+func TestCommentBody_MentionsOwners(t *testing.T) {
+	r := result("S3649", "a.go", 1, 1)
+	r.Properties = map[string]interface{}{"gavel/owners": []interface{}{"@acme/backend", "dev@example.com"}}
+	if body := commentBody(r, Fingerprint(r)); !strings.Contains(body, "**Owners:** @acme/backend dev@example.com\n") {
+		t.Errorf("expected the owners mentioned, got %q", body)
+	}
+	if body := commentBody(result("S3649", "a.go", 1, 1), "fp"); strings.Contains(body, "Owners") {
+		t.Errorf("expected no owners line, got %q", body)
+	}
+}
@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -147,3 +148,161 @@ func TestDefaultRules_PatternMatching(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultRules_MagicNumberAllowlist(t *testing.T) {
+	rules, err := DefaultRules()
+	if err != nil {
+		t.Fatalf("DefaultRules() returned error: %v", err)
+	}
+
+	var rule *Rule
+	for i := range rules {
+		if rules[i].ID == "S109" {
+			rule = &rules[i]
+		}
+	}
+	if rule == nil {
+		t.Fatal("rule S109 not found")
+	}
+
+	tests := []struct {
+		input   string
+		flagged bool
+	}{
+		{"\tif n == 0 {", false},
+		{"\treturn 1", false},
+		{"\treturn -1", false},
+		{"\tif port == 443 {", false},
+		{"\tif resp.StatusCode == 404 {", false},
+		{"\tif retries > 37 {", true},
+		{"\treturn 86400", true},
+		{"\tif v > 3.5 {", false},
+		{"\treturn 2", false},
+		{"\tfor i := 0; i < 7; i++ {", false},
+		{"\tif size > 4096 {", false},
+		{"\tif x > 500 && y == 0 {", false},
+		{"\tif x > 37 && y == 0 {", true},
+		{"\tif y == 0 && x > 37 {", true},
+		{"\tcase 42:", true},
+		{"\tif flags&(1<<20) != 0 {", false},
+		{"\tok := retries > 42", false},
+		{"\tconst isLarge = size === 1500;", false},
+		{"\tconst answer = () => 37;", false},
+		{"\treturn () => 37", false},
+		{"\titems.filter(x => x.count > 37)", false},
+		{"\tfor x := range 37 {", false},
+		{"\tfor i := 0; i < 37; i++ {", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			flagged := false
+			for _, m := range rule.Pattern.FindAllStringSubmatchIndex(tc.input, -1) {
+				if rule.InScope(tc.input, m[0]) && !rule.Allowed(rule.MatchValue(tc.input, m)) {
+					flagged = true
+				}
+			}
+			if flagged != tc.flagged {
+				t.Errorf("%q: expected flagged=%v, got %v", tc.input, tc.flagged, flagged)
+			}
+		})
+	}
+}
+
+func TestDefaultRules_BlanketLintSuppression(t *testing.T) {
+	rules, err := DefaultRules()
+	if err != nil {
+		t.Fatalf("DefaultRules() returned error: %v", err)
+	}
+
+	var rule *Rule
+	for i := range rules {
+		if rules[i].ID == "G602" {
+			rule = &rules[i]
+		}
+	}
+	if rule == nil {
+		t.Fatal("rule G602 not found")
+	}
+
+	tests := []struct {
+		input   string
+		flagged bool
+	}{
+		{"\tdefer f.Close() //nolint", true},
+		{"\tdefer f.Close() // nolint // best effort", true},
+		{"\tdefer f.Close() //nolint:errcheck", false},
+		{"\tdefer f.Close() //nolint:errcheck // best effort", false},
+		{"/* eslint-disable */", true},
+		{"// eslint-disable-next-line", true},
+		{"x = y; // eslint-disable-line -- legacy", true},
+		{"/* eslint-disable no-console */", false},
+		{"// eslint-disable-next-line no-console -- legacy", false},
+		{"import os  # noqa", true},
+		{"import os  # NOQA", true},
+		{"import os  # noqa: F401", false},
+		{"import os  # noqa:F401", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := rule.Pattern.MatchString(tc.input); got != tc.flagged {
+				t.Errorf("%q: expected flagged=%v, got %v", tc.input, tc.flagged, got)
+			}
+		})
+	}
+}
+
+func TestDefaultRules_InsecureDeserialization(t *testing.T) {
+	rules, err := DefaultRules()
+	if err != nil {
+		t.Fatalf("DefaultRules() returned error: %v", err)
+	}
+	ruleMap := make(map[string]Rule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	langs := map[string][]string{
+		"S5135": {"python"},
+		"S1523": {"javascript", "typescript"},
+		"G603":  {"go"},
+	}
+	for id, want := range langs {
+		r, ok := ruleMap[id]
+		if !ok {
+			t.Fatalf("rule %s not found", id)
+		}
+		if strings.Join(r.Languages, ",") != strings.Join(want, ",") {
+			t.Errorf("rule %s: expected languages %v, got %v", id, want, r.Languages)
+		}
+	}
+
+	tests := []struct {
+		ruleID  string
+		input   string
+		flagged bool
+	}{
+		{"S5135", "obj = pickle.loads(data)", true},
+		{"S5135", "obj = cPickle.load(fh)", true},
+		{"S5135", "cfg = yaml.unsafe_load(text)", true},
+		{"S5135", "obj = json.loads(data)", false},
+		{"S5135", "cfg = yaml.safe_load(text)", false},
+		{"S1523", "const result = eval(userInput);", true},
+		{"S1523", "const fn = new Function('a', body);", true},
+		{"S1523", "const data = JSON.parse(userInput);", false},
+		{"S1523", "page.evaluate(script)", false},
+		{"S1523", "await worker.eval(code)", false},
+		{"G603", "dec := gob.NewDecoder(r.Body)", true},
+		{"G603", "err := gob.NewDecoder(conn).Decode(&msg)", true},
+		{"G603", "dec := gob.NewDecoder(bytes.NewReader(cached))", false},
+		{"G603", "err := json.NewDecoder(r.Body).Decode(&req)", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.ruleID+"/"+tc.input, func(t *testing.T) {
+			if got := ruleMap[tc.ruleID].Pattern.MatchString(tc.input); got != tc.flagged {
+				t.Errorf("%s on %q: expected flagged=%v, got %v", tc.ruleID, tc.input, tc.flagged, got)
+			}
+		})
+	}
+}
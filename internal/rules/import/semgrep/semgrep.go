@@ -0,0 +1,374 @@
+// Package semgrep converts Semgrep rules into Gavel regex rules so existing
+// Semgrep rulesets can run in the instant tier.
+//
+// Only a subset converts. A rule's matching logic must be a single
+// pattern-regex, a single-line pattern, or a pattern-either of those; rules
+// using patterns, pattern-not, pattern-inside, taint mode and the like are
+// skipped with a reason. Semgrep patterns match syntax trees while Gavel
+// rules match text, so converted patterns are approximations: tokens may be
+// separated by any whitespace, ... matches anything on the line and a
+// metavariable such as $X matches any text.
+package semgrep
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+// Skipped is a Semgrep rule that could not be converted.
+type Skipped struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// Result holds the outcome of converting one Semgrep rule file.
+type Result struct {
+	Rules   []rules.Rule
+	Skipped []Skipped
+}
+
+// rule is the part of a Semgrep rule the converter reads. Keys it does not
+// know are collected in Other so rules relying on them can be skipped.
+type rule struct {
+	ID            string                 `yaml:"id"`
+	Message       string                 `yaml:"message"`
+	Severity      string                 `yaml:"severity"`
+	Languages     []string               `yaml:"languages"`
+	Pattern       string                 `yaml:"pattern"`
+	PatternRegex  string                 `yaml:"pattern-regex"`
+	PatternEither []map[string]yaml.Node `yaml:"pattern-either"`
+	Metadata      map[string]interface{} `yaml:"metadata"`
+	Other         map[string]interface{} `yaml:",inline"`
+}
+
+// ignoredKeys are Semgrep rule keys that do not affect what a rule matches.
+var ignoredKeys = map[string]bool{"fix": true, "fix-regex": true, "min-version": true, "max-version": true, "options": true, "paths": true}
+
+// Convert converts the rules in a Semgrep rule file.
+func Convert(data []byte) (*Result, error) {
+	var file struct {
+		Rules []rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing semgrep rules: %w", err)
+	}
+
+	res := &Result{}
+	for _, sr := range file.Rules {
+		r, err := convertRule(sr)
+		if err != nil {
+			res.Skipped = append(res.Skipped, Skipped{ID: sr.ID, Reason: err.Error()})
+			continue
+		}
+		res.Rules = append(res.Rules, r)
+	}
+	return res, nil
+}
+
+func convertRule(sr rule) (rules.Rule, error) {
+	if sr.ID == "" {
+		return rules.Rule{}, fmt.Errorf("missing id")
+	}
+	for key := range sr.Other {
+		if !ignoredKeys[key] {
+			return rules.Rule{}, fmt.Errorf("unsupported key %q", key)
+		}
+	}
+
+	pattern, err := matchPattern(sr)
+	if err != nil {
+		return rules.Rule{}, err
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return rules.Rule{}, fmt.Errorf("pattern is not a valid RE2 regex: %w", err)
+	}
+
+	languages, err := convertLanguages(sr.Languages)
+	if err != nil {
+		return rules.Rule{}, err
+	}
+
+	level, ok := severityLevels[strings.ToUpper(sr.Severity)]
+	if !ok {
+		return rules.Rule{}, fmt.Errorf("unknown severity %q", sr.Severity)
+	}
+
+	message, explanation := splitMessage(sr.Message)
+	if message == "" {
+		return rules.Rule{}, fmt.Errorf("missing message")
+	}
+
+	r := rules.Rule{
+		ID:          sr.ID,
+		Name:        sr.ID[strings.LastIndex(sr.ID, ".")+1:],
+		Type:        rules.RuleTypeRegex,
+		RawPattern:  pattern,
+		Languages:   languages,
+		Level:       level,
+		Confidence:  confidenceOf(sr.Metadata),
+		Message:     message,
+		Explanation: explanation,
+		Source:      rules.SourceCustom,
+		CWE:         metadataIDs(sr.Metadata["cwe"], cwePattern),
+		OWASP:       metadataIDs(sr.Metadata["owasp"], owaspPattern),
+		References:  metadataStrings(sr.Metadata["references"]),
+	}
+	r.Category = categoryOf(sr.Metadata, len(r.CWE) > 0)
+	return r, nil
+}
+
+// matchPattern returns the regex for the rule's matching logic.
+func matchPattern(sr rule) (string, error) {
+	set := 0
+	for _, present := range []bool{sr.Pattern != "", sr.PatternRegex != "", len(sr.PatternEither) > 0} {
+		if present {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", fmt.Errorf("needs exactly one of pattern, pattern-regex or pattern-either")
+	}
+
+	switch {
+	case sr.PatternRegex != "":
+		return sr.PatternRegex, nil
+	case sr.Pattern != "":
+		return patternToRegex(sr.Pattern)
+	}
+
+	alternatives := make([]string, 0, len(sr.PatternEither))
+	for _, alt := range sr.PatternEither {
+		if len(alt) != 1 {
+			return "", fmt.Errorf("pattern-either entries must each hold one pattern")
+		}
+		for key, node := range alt {
+			var value string
+			if err := node.Decode(&value); err != nil {
+				return "", fmt.Errorf("unsupported pattern-either entry %q", key)
+			}
+			switch key {
+			case "pattern":
+				re, err := patternToRegex(value)
+				if err != nil {
+					return "", err
+				}
+				alternatives = append(alternatives, re)
+			case "pattern-regex":
+				alternatives = append(alternatives, value)
+			default:
+				return "", fmt.Errorf("unsupported pattern-either entry %q", key)
+			}
+		}
+	}
+	return "(?:" + strings.Join(alternatives, ")|(?:") + ")", nil
+}
+
+var metavariable = regexp.MustCompile(`^\$(?:\.\.\.)?[A-Z_][A-Z0-9_]*`)
+
+// patternToRegex approximates a single-line Semgrep pattern as a regex.
+func patternToRegex(pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if strings.Contains(pattern, "\n") {
+		return "", fmt.Errorf("multi-line patterns are not supported")
+	}
+	if strings.Contains(pattern, "<...") {
+		return "", fmt.Errorf("deep expression operators are not supported")
+	}
+
+	var b strings.Builder
+	prevWord := false
+	first := true
+	emit := func(re string, word bool) {
+		switch {
+		case first && word:
+			b.WriteString(`\b`)
+		case !first && prevWord && word:
+			b.WriteString(`\s+`)
+		case !first:
+			b.WriteString(`\s*`)
+		}
+		b.WriteString(re)
+		prevWord = word
+		first = false
+	}
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(pattern[i:], "..."):
+			emit(`.*?`, false)
+			i += 3
+		case c == '$':
+			m := metavariable.FindString(pattern[i:])
+			if m == "" {
+				return "", fmt.Errorf("unsupported token at %q", pattern[i:])
+			}
+			if strings.HasPrefix(m, "$...") {
+				emit(`.*?`, false)
+			} else {
+				emit(`.+?`, false)
+			}
+			i += len(m)
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(pattern[i+1:], c)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated string in pattern")
+			}
+			lit := pattern[i+1 : i+1+end]
+			if lit == "..." {
+				// Any string literal, with either quote.
+				emit(`(?:"[^"]*"|'[^']*')`, false)
+			} else {
+				emit(regexp.QuoteMeta(pattern[i:i+end+2]), false)
+			}
+			i += end + 2
+		case isWordByte(c):
+			j := i
+			for j < len(pattern) && isWordByte(pattern[j]) {
+				j++
+			}
+			emit(regexp.QuoteMeta(pattern[i:j]), true)
+			i = j
+		default:
+			emit(regexp.QuoteMeta(string(c)), false)
+			i++
+		}
+	}
+	if first {
+		return "", fmt.Errorf("empty pattern")
+	}
+	return b.String(), nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var severityLevels = map[string]string{
+	"ERROR":      "error",
+	"WARNING":    "warning",
+	"INFO":       "note",
+	"INVENTORY":  "note",
+	"EXPERIMENT": "note",
+}
+
+// languageNames maps Semgrep language names to Gavel's.
+var languageNames = map[string]string{
+	"go": "go", "golang": "go",
+	"python": "python", "py": "python", "python3": "python",
+	"javascript": "javascript", "js": "javascript",
+	"typescript": "typescript", "ts": "typescript",
+	"java":   "java",
+	"c":      "c",
+	"rust":   "rust",
+	"ruby":   "ruby",
+	"php":    "php",
+	"kotlin": "kotlin", "kt": "kotlin",
+	"csharp": "csharp", "c#": "csharp",
+}
+
+// convertLanguages maps languages to Gavel's names. The language-agnostic
+// "generic" and "regex" leave the rule unrestricted.
+func convertLanguages(langs []string) ([]string, error) {
+	var out, unsupported []string
+	seen := make(map[string]bool)
+	for _, l := range langs {
+		l = strings.ToLower(l)
+		if l == "generic" || l == "regex" || l == "none" {
+			return nil, nil
+		}
+		name, ok := languageNames[l]
+		if !ok {
+			unsupported = append(unsupported, l)
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	if len(out) == 0 && len(unsupported) > 0 {
+		return nil, fmt.Errorf("no supported languages in %s", strings.Join(unsupported, ", "))
+	}
+	return out, nil
+}
+
+// splitMessage returns the first line of a Semgrep message as the finding
+// message and the rest, if any, as the explanation.
+func splitMessage(msg string) (string, string) {
+	msg = strings.TrimSpace(msg)
+	first, rest, _ := strings.Cut(msg, "\n")
+	return strings.TrimSpace(first), strings.Join(strings.Fields(rest), " ")
+}
+
+// confidenceOf maps Semgrep's metadata.confidence to a Gavel confidence,
+// defaulting to MEDIUM.
+func confidenceOf(meta map[string]interface{}) float64 {
+	c, _ := meta["confidence"].(string)
+	switch strings.ToUpper(c) {
+	case "HIGH":
+		return 0.8
+	case "LOW":
+		return 0.4
+	default:
+		return 0.6
+	}
+}
+
+func categoryOf(meta map[string]interface{}, hasCWE bool) rules.RuleCategory {
+	c, _ := meta["category"].(string)
+	switch strings.ToLower(c) {
+	case "security":
+		return rules.CategorySecurity
+	case "correctness", "performance":
+		return rules.CategoryReliability
+	case "best-practice", "maintainability", "style":
+		return rules.CategoryMaintainability
+	}
+	if hasCWE {
+		return rules.CategorySecurity
+	}
+	return rules.CategoryMaintainability
+}
+
+var (
+	cwePattern   = regexp.MustCompile(`CWE-\d+`)
+	owaspPattern = regexp.MustCompile(`A\d{2}:\d{4}`)
+)
+
+// metadataIDs extracts the IDs matching re from a metadata value such as
+// "CWE-89: Improper Neutralization ..." or a list of them.
+func metadataIDs(v interface{}, re *regexp.Regexp) []string {
+	var ids []string
+	for _, s := range metadataStrings(v) {
+		if id := re.FindString(s); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// metadataStrings returns a metadata value that is a string or a list of
+// strings as a list.
+func metadataStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package semgrep
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/rules"
+)
+
+const sample = `
+rules:
+  - id: python.lang.security.audit.eval-detected
+    message: |
+      Detected the use of eval().
+      eval() can be dangerous if used to evaluate dynamic content.
+    severity: WARNING
+    languages: [python]
+    pattern: eval(...)
+    metadata:
+      cwe:
+        - "CWE-95: Improper Neutralization of Directives in Dynamically Evaluated Code"
+      owasp: "A03:2021 - Injection"
+      confidence: HIGH
+      category: security
+      references:
+        - https://docs.python.org/3/library/functions.html#eval
+  - id: hardcoded-aws-key
+    message: Hardcoded AWS access key
+    severity: ERROR
+    languages: [generic]
+    pattern-regex: AKIA[0-9A-Z]{16}
+  - id: js-exec
+    message: Shell command built from input
+    severity: INFO
+    languages: [js, ts]
+    pattern-either:
+      - pattern: child_process.exec($CMD)
+      - pattern: execSync("...")
+  - id: taint-rule
+    message: taint
+    severity: ERROR
+    languages: [python]
+    mode: taint
+    pattern-sources: [{pattern: input()}]
+    pattern-sinks: [{pattern: eval(...)}]
+  - id: combined
+    message: combined
+    severity: ERROR
+    languages: [go]
+    patterns:
+      - pattern: os.Exec(...)
+      - pattern-not: os.Exec("ls")
+  - id: ocaml-only
+    message: ocaml
+    severity: ERROR
+    languages: [ocaml]
+    pattern: foo()
+`
+
+func TestConvert(t *testing.T) {
+	res, err := Convert([]byte(sample))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if len(res.Rules) != 3 {
+		t.Fatalf("expected 3 converted rules, got %d (skipped %+v)", len(res.Rules), res.Skipped)
+	}
+
+	eval := res.Rules[0]
+	if eval.Name != "eval-detected" || eval.Level != "warning" || eval.Confidence != 0.8 {
+		t.Errorf("unexpected eval rule %+v", eval)
+	}
+	if eval.Message != "Detected the use of eval()." || eval.Explanation != "eval() can be dangerous if used to evaluate dynamic content." {
+		t.Errorf("message = %q, explanation = %q", eval.Message, eval.Explanation)
+	}
+	if !reflect.DeepEqual(eval.CWE, []string{"CWE-95"}) || !reflect.DeepEqual(eval.OWASP, []string{"A03:2021"}) {
+		t.Errorf("CWE = %v, OWASP = %v", eval.CWE, eval.OWASP)
+	}
+	if eval.Category != rules.CategorySecurity || !reflect.DeepEqual(eval.Languages, []string{"python"}) || len(eval.References) != 1 {
+		t.Errorf("unexpected eval rule %+v", eval)
+	}
+
+	aws := res.Rules[1]
+	if aws.RawPattern != "AKIA[0-9A-Z]{16}" || aws.Languages != nil || aws.Level != "error" || aws.Confidence != 0.6 {
+		t.Errorf("unexpected aws rule %+v", aws)
+	}
+
+	js := res.Rules[2]
+	if js.Level != "note" || !reflect.DeepEqual(js.Languages, []string{"javascript", "typescript"}) {
+		t.Errorf("unexpected js rule %+v", js)
+	}
+	re := regexp.MustCompile(js.RawPattern)
+	for _, s := range []string{`child_process.exec(cmd + arg)`, `execSync( "rm -rf /" )`} {
+		if !re.MatchString(s) {
+			t.Errorf("js pattern %q should match %q", js.RawPattern, s)
+		}
+	}
+
+	skipped := map[string]bool{}
+	for _, s := range res.Skipped {
+		skipped[s.ID] = true
+		if s.Reason == "" {
+			t.Errorf("skipped %s without a reason", s.ID)
+		}
+	}
+	for _, id := range []string{"taint-rule", "combined", "ocaml-only"} {
+		if !skipped[id] {
+			t.Errorf("expected %s to be skipped, got %+v", id, res.Skipped)
+		}
+	}
+}
+
+func TestConvert_RulesLoad(t *testing.T) {
+	res, err := Convert([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := yaml.Marshal(rules.RuleFile{Rules: res.Rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf, err := rules.ParseRuleFile(data)
+	if err != nil {
+		t.Fatalf("converted rules do not load: %v\n%s", err, data)
+	}
+	if len(rf.Rules) != len(res.Rules) {
+		t.Errorf("loaded %d rules, want %d", len(rf.Rules), len(res.Rules))
+	}
+}
+
+func TestPatternToRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{"eval(...)", []string{"eval(x)", "eval()", "x = eval (a, b)"}, []string{"evaluate(x)", "my_eval(x)"}},
+		{"$X == $X", []string{"a == a", "foo.bar==baz"}, []string{"a = b"}},
+		{`yaml.load($DATA)`, []string{"yaml.load(f)", "yaml . load( open(p) )"}, []string{"yaml.safe_load(f)"}},
+		{`return "..."`, []string{`return "x"`, `return 'y'`}, []string{"returnx", "return x"}},
+		{"hashlib.md5(...)", []string{"h = hashlib.md5(data)"}, []string{"hashlib.sha256(data)"}},
+	}
+	for _, tt := range tests {
+		expr, err := patternToRegex(tt.pattern)
+		if err != nil {
+			t.Errorf("patternToRegex(%q): %v", tt.pattern, err)
+			continue
+		}
+		re := regexp.MustCompile(expr)
+		for _, s := range tt.match {
+			if !re.MatchString(s) {
+				t.Errorf("%q (%s) should match %q", tt.pattern, expr, s)
+			}
+		}
+		for _, s := range tt.noMatch {
+			if re.MatchString(s) {
+				t.Errorf("%q (%s) should not match %q", tt.pattern, expr, s)
+			}
+		}
+	}
+
+	for _, p := range []string{"foo(\n  ...\n)", "<... $X ...>", `f("unterminated)`, "  "} {
+		if _, err := patternToRegex(p); err == nil {
+			t.Errorf("expected an error for %q", p)
+		}
+	}
+}
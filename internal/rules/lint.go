@@ -0,0 +1,224 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/pathglob"
+)
+
+// Lint severities. Errors are rules that fail to load or never run;
+// warnings are rules that load but probably do not do what was meant.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// Problem is an issue LintPaths found in a rule file. RuleID and Index are
+// unset for problems with the file as a whole.
+type Problem struct {
+	File     string `json:"file"`
+	RuleID   string `json:"rule_id,omitempty"`
+	Index    *int   `json:"index,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (p Problem) String() string {
+	loc := p.File
+	if p.Index != nil {
+		loc += fmt.Sprintf(": rule %d", *p.Index)
+		if p.RuleID != "" {
+			loc += fmt.Sprintf(" (%s)", p.RuleID)
+		}
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, p.Severity, p.Message)
+}
+
+// LintPaths checks the rule files at paths, each a YAML file or a
+// directory of them (read the way the loader reads a rules directory).
+// Unlike ParseRuleFile, which stops at the first invalid rule, it reports
+// every problem it finds, including ones the loader accepts but that leave
+// a rule inert: unknown ast_check names, unknown languages and levels, IDs
+// defined in more than one file, and IDs that replace a built-in rule.
+// It returns the number of files checked.
+func LintPaths(paths []string) (int, []Problem, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return 0, nil, fmt.Errorf("reading directory %s: %w", p, err)
+		}
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(p, entry.Name()))
+			}
+		}
+	}
+
+	defaults, err := DefaultRules()
+	if err != nil {
+		return 0, nil, fmt.Errorf("loading default rules: %w", err)
+	}
+	builtin := indexByID(defaults)
+
+	l := &linter{builtin: builtin, registry: astcheck.DefaultRegistry(), definedIn: map[string]string{}}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return 0, nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+		l.lintFile(f, data)
+	}
+	return len(files), l.problems, nil
+}
+
+type linter struct {
+	builtin   map[string]Rule
+	registry  *astcheck.Registry
+	definedIn map[string]string // rule ID -> first file defining it
+	problems  []Problem
+}
+
+func (l *linter) lintFile(file string, data []byte) {
+	var rf RuleFile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		l.problems = append(l.problems, Problem{File: file, Severity: LintError, Message: fmt.Sprintf("invalid YAML: %v", err)})
+		return
+	}
+	if len(rf.Rules) == 0 {
+		l.problems = append(l.problems, Problem{File: file, Severity: LintWarning, Message: "no rules defined (expected a top-level rules list)"})
+	}
+	seen := make(map[string]bool)
+	for i := range rf.Rules {
+		r := rf.Rules[i]
+		if r.Type == "" {
+			r.Type = RuleTypeRegex
+		}
+		add := func(severity, format string, args ...interface{}) {
+			idx := i
+			l.problems = append(l.problems, Problem{File: file, RuleID: r.ID, Index: &idx, Severity: severity, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if r.ID != "" {
+			switch {
+			case seen[r.ID]:
+				add(LintError, "duplicate rule ID %q in this file", r.ID)
+			case l.definedIn[r.ID] != "":
+				add(LintError, "rule ID %q is also defined in %s; which one is used depends on file order", r.ID, l.definedIn[r.ID])
+			default:
+				l.definedIn[r.ID] = file
+			}
+			seen[r.ID] = true
+			if _, ok := l.builtin[r.ID]; ok {
+				add(LintWarning, "rule ID %q replaces the built-in rule with that ID", r.ID)
+			}
+		}
+
+		for _, msg := range ruleErrors(&r, l.registry) {
+			add(LintError, "%s", msg)
+		}
+
+		if r.Category != "" && r.Category != CategorySecurity && r.Category != CategoryReliability && r.Category != CategoryMaintainability && r.Category != CategoryDependency && r.Category != CategorySecret {
+			add(LintWarning, "unknown category %q (known: security, reliability, maintainability, dependency, secret)", r.Category)
+		}
+		for _, lang := range r.Languages {
+			if _, _, ok := astcheck.LanguageByName(lang); !ok {
+				add(LintWarning, "unknown language %q never matches a file", lang)
+			}
+		}
+	}
+}
+
+// ruleErrors returns every reason r would fail to load or never run.
+func ruleErrors(r *Rule, registry *astcheck.Registry) []string {
+	var errs []string
+	if r.ID == "" {
+		errs = append(errs, "missing required field: id")
+	}
+	switch r.Type {
+	case RuleTypeRegex:
+		if r.RawPattern == "" {
+			errs = append(errs, "missing required field: pattern")
+		} else if _, err := regexp.Compile(r.RawPattern); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid regex pattern: %v", err))
+		}
+	case RuleTypeAST:
+		if r.ASTCheck == "" {
+			errs = append(errs, "missing required field: ast_check")
+		} else if _, ok := registry.Get(r.ASTCheck); !ok {
+			errs = append(errs, fmt.Sprintf("unknown ast_check %q (known: %s)", r.ASTCheck, strings.Join(registry.Names(), ", ")))
+		}
+	case RuleTypeASTQuery:
+		switch {
+		case r.Query == "":
+			errs = append(errs, "missing required field: query")
+		case len(r.Languages) == 0:
+			errs = append(errs, "missing required field: languages (queries are grammar-specific)")
+		default:
+			if _, err := astcheck.NewQueryCheck(r.Query, r.Languages, r.Capture, r.Message); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid query: %v", err))
+			}
+		}
+	case RuleTypeDependency:
+		if err := r.Dependency.validate(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown rule type %q (known: regex, ast, ast-query, dependency)", r.Type))
+	}
+
+	switch r.Level {
+	case "":
+		errs = append(errs, "missing required field: level")
+	case "error", "warning", "note":
+	default:
+		errs = append(errs, fmt.Sprintf("unknown level %q (known: error, warning, note)", r.Level))
+	}
+	if r.Message == "" {
+		errs = append(errs, "missing required field: message")
+	}
+	if r.Confidence <= 0 || r.Confidence > 1 {
+		errs = append(errs, fmt.Sprintf("confidence must be in range (0, 1], got %v", r.Confidence))
+	}
+	if r.Fix != nil {
+		if r.Fix.Replacement != "" && r.Fix.Delete {
+			errs = append(errs, "fix: replacement and delete are mutually exclusive")
+		}
+		if r.Fix.Replacement == "" && !r.Fix.Delete {
+			errs = append(errs, "fix: one of replacement or delete is required")
+		}
+	}
+	if err := pathglob.Validate(r.IncludePaths); err != nil {
+		errs = append(errs, fmt.Sprintf("invalid include_paths: %v", err))
+	}
+	if err := pathglob.Validate(r.ExcludePaths); err != nil {
+		errs = append(errs, fmt.Sprintf("invalid exclude_paths: %v", err))
+	}
+	for _, entry := range r.Allowlist {
+		if _, err := regexp.Compile(`^(?:` + entry + `)$`); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid allowlist entry %q: %v", entry, err))
+		}
+	}
+	if r.Scope != "" {
+		if _, err := regexp.Compile(r.Scope); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid scope: %v", err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "a.yaml", testRuleYAML)
+	writeRuleFile(t, dir, "b.yaml", `rules:
+  - id: "CUSTOM-001"
+    pattern: 'again'
+    level: "error"
+    confidence: 0.9
+    message: "Duplicate across files"
+  - id: "BAD-REGEX"
+    pattern: '(unclosed'
+    level: "fatal"
+    confidence: 1.5
+    message: "Broken"
+  - id: "BAD-AST"
+    type: ast
+    ast_check: "function-lenght"
+    languages: [go, golang]
+    level: warning
+    confidence: 0.5
+    message: "Typo in check name"
+  - id: "S2068"
+    category: "style"
+    pattern: 'password'
+    level: error
+    confidence: 0.9
+    message: "Overrides a built-in"
+`)
+	writeRuleFile(t, dir, "broken.yml", "rules: [\n")
+	writeRuleFile(t, dir, "notes.txt", "ignored")
+
+	files, problems, err := LintPaths([]string{dir})
+	if err != nil {
+		t.Fatalf("LintPaths: %v", err)
+	}
+	if files != 3 {
+		t.Errorf("expected 3 rule files, got %d", files)
+	}
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	b := filepath.Join(dir, "b.yaml")
+	want := []string{
+		b + ": rule 0 (CUSTOM-001): error: rule ID \"CUSTOM-001\" is also defined in " + filepath.Join(dir, "a.yaml"),
+		b + ": rule 1 (BAD-REGEX): error: invalid regex pattern",
+		b + ": rule 1 (BAD-REGEX): error: unknown level \"fatal\"",
+		b + ": rule 1 (BAD-REGEX): error: confidence must be in range (0, 1], got 1.5",
+		b + ": rule 2 (BAD-AST): error: unknown ast_check \"function-lenght\" (known: ",
+		b + ": rule 2 (BAD-AST): warning: unknown language \"golang\" never matches a file",
+		b + ": rule 3 (S2068): warning: rule ID \"S2068\" replaces the built-in rule",
+		b + ": rule 3 (S2068): warning: unknown category \"style\"",
+		filepath.Join(dir, "broken.yml") + ": error: invalid YAML",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d problems, got %d:\n%s", len(want), len(got), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("problem %d = %q, want prefix %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLintPaths_Clean(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "rules.yaml", testRuleYAML)
+
+	files, problems, err := LintPaths([]string{filepath.Join(dir, "rules.yaml")})
+	if err != nil {
+		t.Fatalf("LintPaths: %v", err)
+	}
+	if files != 1 || len(problems) != 0 {
+		t.Errorf("expected 1 clean file, got %d files and %v", files, problems)
+	}
+
+	if _, _, err := LintPaths([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("expected an error for a missing path")
+	}
+}
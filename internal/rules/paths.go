@@ -0,0 +1,24 @@
+package rules
+
+import "github.com/chris-regnier/gavel/internal/pathglob"
+
+// AppliesToPath reports whether the rule's include_paths and exclude_paths
+// globs allow it to run on the artifact at p. A rule with no include_paths
+// applies everywhere not excluded. Exclusions win over inclusions. See
+// package pathglob for the glob syntax.
+func (r Rule) AppliesToPath(p string) bool {
+	for _, pattern := range r.ExcludePaths {
+		if pathglob.Match(pattern, p) {
+			return false
+		}
+	}
+	if len(r.IncludePaths) == 0 {
+		return true
+	}
+	for _, pattern := range r.IncludePaths {
+		if pathglob.Match(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRule_AppliesToPath_IncludeSubtree(t *testing.T) {
+	r := Rule{IncludePaths: []string{"internal/auth/**"}}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"internal/auth/login.go", true},
+		{"internal/auth/oauth/token.go", true},
+		{"./internal/auth/login.go", true},
+		{"/src/repo/internal/auth/login.go", true},
+		{"internal/authz/policy.go", false},
+		{"internal/server/handler.go", false},
+		{"cmd/auth/main.go", false},
+	}
+	for _, tc := range tests {
+		if got := r.AppliesToPath(tc.path); got != tc.want {
+			t.Errorf("AppliesToPath(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestRule_AppliesToPath_Exclude(t *testing.T) {
+	r := Rule{
+		IncludePaths: []string{"internal/**"},
+		ExcludePaths: []string{"**/*_test.go", "internal/testdata/**"},
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"internal/auth/login.go", true},
+		{"internal/auth/login_test.go", false},
+		{"internal/testdata/fixture.go", false},
+		{"cmd/gavel/main.go", false},
+	}
+	for _, tc := range tests {
+		if got := r.AppliesToPath(tc.path); got != tc.want {
+			t.Errorf("AppliesToPath(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestRule_AppliesToPath_Unscoped(t *testing.T) {
+	if !(Rule{}).AppliesToPath("anything/at/all.go") {
+		t.Error("expected rule without path scoping to apply everywhere")
+	}
+}
+
+func TestParseRuleFile_PathScoping(t *testing.T) {
+	yaml := `rules:
+  - id: "AUTH001"
+    pattern: 'InsecureSkipVerify'
+    level: "error"
+    confidence: 0.9
+    message: "TLS verification disabled in auth code"
+    include_paths: ["internal/auth/**"]
+    exclude_paths: ["**/*_test.go"]
+`
+	rf, err := ParseRuleFile([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := rf.Rules[0]
+	if len(r.IncludePaths) != 1 || len(r.ExcludePaths) != 1 {
+		t.Fatalf("expected path globs to be parsed, got include=%v exclude=%v", r.IncludePaths, r.ExcludePaths)
+	}
+	if !r.AppliesToPath("internal/auth/tls.go") || r.AppliesToPath("internal/auth/tls_test.go") {
+		t.Error("parsed path globs not applied as expected")
+	}
+}
+
+func TestParseRuleFile_InvalidPathGlob(t *testing.T) {
+	yaml := `rules:
+  - id: "R001"
+    pattern: 'foo'
+    level: "warning"
+    confidence: 0.5
+    message: "found foo"
+    include_paths: ["internal/[auth/**"]
+`
+	_, err := ParseRuleFile([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected error for invalid include_paths glob")
+	}
+	if !strings.Contains(err.Error(), "include_paths") {
+		t.Errorf("expected include_paths error, got: %v", err)
+	}
+}
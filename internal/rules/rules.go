@@ -2,9 +2,14 @@ package rules
 
 import (
 	"fmt"
+	"path"
 	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/chris-regnier/gavel/internal/astcheck"
+	"github.com/chris-regnier/gavel/internal/pathglob"
 )
 
 type RuleCategory string
@@ -13,6 +18,8 @@ const (
 	CategorySecurity        RuleCategory = "security"
 	CategoryReliability     RuleCategory = "reliability"
 	CategoryMaintainability RuleCategory = "maintainability"
+	CategoryDependency      RuleCategory = "dependency"
+	CategorySecret          RuleCategory = "secret"
 )
 
 type RuleSource string
@@ -29,6 +36,12 @@ type RuleType string
 const (
 	RuleTypeRegex RuleType = "regex"
 	RuleTypeAST   RuleType = "ast"
+	// RuleTypeASTQuery rules embed a tree-sitter query instead of naming a
+	// built-in AST check.
+	RuleTypeASTQuery RuleType = "ast-query"
+	// RuleTypeDependency rules check the dependencies declared in package
+	// manifests and SBOMs instead of matching source text.
+	RuleTypeDependency RuleType = "dependency"
 )
 
 type Rule struct {
@@ -40,7 +53,12 @@ type Rule struct {
 	RawPattern  string       `yaml:"pattern"`
 	ASTCheck    string       `yaml:"ast_check,omitempty"`
 	ASTConfig   map[string]interface{} `yaml:"ast_config,omitempty"`
+	Query       string       `yaml:"query,omitempty"`
+	Capture     string       `yaml:"capture,omitempty"`
+	QueryCheck  *astcheck.QueryCheck `yaml:"-"`
 	Languages   []string     `yaml:"languages,omitempty"`
+	IncludePaths []string    `yaml:"include_paths,omitempty"`
+	ExcludePaths []string    `yaml:"exclude_paths,omitempty"`
 	Level       string       `yaml:"level"`
 	Confidence  float64      `yaml:"confidence"`
 	Message     string       `yaml:"message"`
@@ -50,6 +68,92 @@ type Rule struct {
 	CWE         []string     `yaml:"cwe,omitempty"`
 	OWASP       []string     `yaml:"owasp,omitempty"`
 	References  []string     `yaml:"references,omitempty"`
+
+	// Allowlist holds values that suppress a regex match. Each entry is a
+	// regular expression that must match the whole matched value: the
+	// pattern's "value" capture group when present, otherwise the full match.
+	Allowlist         []string         `yaml:"allowlist,omitempty"`
+	AllowlistPatterns []*regexp.Regexp `yaml:"-"`
+
+	// Scope, when set, limits a regex rule to matches that start on a line
+	// it matches, so a pattern can check each value of a statement while
+	// the statement kind is checked separately.
+	Scope        string         `yaml:"scope,omitempty"`
+	ScopePattern *regexp.Regexp `yaml:"-"`
+
+	// Fix, when set, attaches a SARIF fix to every finding so editors and
+	// code scanning can offer it as a quick-fix.
+	Fix *RuleFix `yaml:"fix,omitempty"`
+
+	// Dependency is the constraint a dependency rule enforces.
+	Dependency *DependencyConstraint `yaml:"dependency,omitempty"`
+}
+
+// DependencyConstraint selects dependencies by ecosystem and package name
+// and says what about them is not allowed. Without MinVersion or Licenses
+// the selected packages are banned outright; with them, a selected package
+// is reported when its version is lower than MinVersion or its license
+// matches one of Licenses.
+//
+// Packages and Licenses are glob patterns (path.Match syntax), so
+// "github.com/gorilla/*" selects every module under it and "AGPL-*" every
+// AGPL version. License patterns are matched case-insensitively against
+// each license ID in an SPDX expression.
+type DependencyConstraint struct {
+	Ecosystem  string   `yaml:"ecosystem,omitempty"` // go, npm, pypi, or a package URL type
+	Packages   []string `yaml:"packages,omitempty"`
+	MinVersion string   `yaml:"min_version,omitempty"`
+	Licenses   []string `yaml:"licenses,omitempty"`
+}
+
+// RuleFix describes the edit that resolves a rule's finding. Fixes replace
+// whole lines: the lines a finding spans are swapped for the fixed text.
+//
+// For regex rules, Replacement is expanded against the match like
+// regexp.Expand ($1, ${name}) and substituted for the matched text, keeping
+// the rest of the lines. For AST rules, ${match} in Replacement expands to
+// the matched lines. Delete removes the lines instead.
+type RuleFix struct {
+	Replacement string `yaml:"replacement,omitempty"`
+	Delete      bool   `yaml:"delete,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Allowed reports whether value matches one of the rule's allowlist entries.
+func (r Rule) Allowed(value string) bool {
+	for _, re := range r.AllowlistPatterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// InScope reports whether a match starting at offset start of content lies
+// on a line the rule's Scope matches. Rules without a scope match anywhere.
+func (r Rule) InScope(content string, start int) bool {
+	if r.ScopePattern == nil {
+		return true
+	}
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := len(content)
+	if i := strings.IndexByte(content[start:], '\n'); i >= 0 {
+		lineEnd = start + i
+	}
+	return r.ScopePattern.MatchString(content[lineStart:lineEnd])
+}
+
+// MatchValue returns the text the allowlist is checked against for a match
+// produced by FindAllStringSubmatchIndex: the "value" capture group when the
+// pattern defines one and it participated in the match, otherwise the whole
+// match.
+func (r Rule) MatchValue(content string, match []int) string {
+	if r.Pattern != nil {
+		if i := r.Pattern.SubexpIndex("value"); i > 0 && 2*i+1 < len(match) && match[2*i] >= 0 {
+			return content[match[2*i]:match[2*i+1]]
+		}
+	}
+	return content[match[0]:match[1]]
 }
 
 type RuleFile struct {
@@ -87,6 +191,35 @@ func ParseRuleFile(data []byte) (*RuleFile, error) {
 			}
 			r.Pattern = compiled
 		}
+		if r.Type == RuleTypeASTQuery {
+			check, err := astcheck.NewQueryCheck(r.Query, r.Languages, r.Capture, r.Message)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid query: %w", r.ID, err)
+			}
+			r.QueryCheck = check
+		}
+
+		if err := pathglob.Validate(r.IncludePaths); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid include_paths: %w", r.ID, err)
+		}
+		if err := pathglob.Validate(r.ExcludePaths); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid exclude_paths: %w", r.ID, err)
+		}
+
+		for _, entry := range r.Allowlist {
+			compiled, err := regexp.Compile(`^(?:` + entry + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid allowlist entry %q: %w", r.ID, entry, err)
+			}
+			r.AllowlistPatterns = append(r.AllowlistPatterns, compiled)
+		}
+		if r.Scope != "" {
+			compiled, err := regexp.Compile(r.Scope)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid scope: %w", r.ID, err)
+			}
+			r.ScopePattern = compiled
+		}
 	}
 
 	return &rf, nil
@@ -107,6 +240,17 @@ func validateRule(r *Rule) error {
 		if r.ASTCheck == "" {
 			return fmt.Errorf("missing required field: ast_check")
 		}
+	case RuleTypeASTQuery:
+		if r.Query == "" {
+			return fmt.Errorf("missing required field: query")
+		}
+		if len(r.Languages) == 0 {
+			return fmt.Errorf("missing required field: languages (queries are grammar-specific)")
+		}
+	case RuleTypeDependency:
+		if err := r.Dependency.validate(); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unknown rule type: %s", r.Type)
 	}
@@ -120,6 +264,29 @@ func validateRule(r *Rule) error {
 	if r.Confidence <= 0 || r.Confidence > 1 {
 		return fmt.Errorf("confidence must be in range (0, 1], got %v", r.Confidence)
 	}
+	if r.Fix != nil {
+		if r.Fix.Replacement != "" && r.Fix.Delete {
+			return fmt.Errorf("fix: replacement and delete are mutually exclusive")
+		}
+		if r.Fix.Replacement == "" && !r.Fix.Delete {
+			return fmt.Errorf("fix: one of replacement or delete is required")
+		}
+	}
+	return nil
+}
+
+func (c *DependencyConstraint) validate() error {
+	if c == nil {
+		return fmt.Errorf("missing required field: dependency")
+	}
+	if len(c.Packages) == 0 && len(c.Licenses) == 0 {
+		return fmt.Errorf("dependency: one of packages or licenses is required")
+	}
+	for _, p := range append(append([]string{}, c.Packages...), c.Licenses...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("dependency: invalid pattern %q: %w", p, err)
+		}
+	}
 	return nil
 }
 
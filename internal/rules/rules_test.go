@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -397,3 +398,217 @@ func TestParseRuleFile_RegexRuleDefault(t *testing.T) {
 		t.Errorf("expected default type regex, got %s", rf.Rules[0].Type)
 	}
 }
+
+func TestParseRuleFile_Allowlist(t *testing.T) {
+	yaml := `rules:
+  - id: "R001"
+    pattern: 'limit = (?P<value>\d+)'
+    level: "note"
+    confidence: 0.5
+    message: "magic limit"
+    allowlist: ["10", "1\\d\\d"]
+`
+	rf, err := ParseRuleFile([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := rf.Rules[0]
+	if len(r.AllowlistPatterns) != 2 {
+		t.Fatalf("expected 2 compiled allowlist patterns, got %d", len(r.AllowlistPatterns))
+	}
+
+	tests := []struct {
+		content string
+		allowed bool
+	}{
+		{"limit = 10", true},
+		{"limit = 150", true},
+		{"limit = 100", true},
+		{"limit = 1000", false},
+		{"limit = 7", false},
+	}
+	for _, tc := range tests {
+		match := r.Pattern.FindStringSubmatchIndex(tc.content)
+		if match == nil {
+			t.Fatalf("pattern did not match %q", tc.content)
+		}
+		if got := r.Allowed(r.MatchValue(tc.content, match)); got != tc.allowed {
+			t.Errorf("%q: expected allowed=%v, got %v", tc.content, tc.allowed, got)
+		}
+	}
+}
+
+func TestParseRuleFile_InvalidAllowlistEntry(t *testing.T) {
+	yaml := `rules:
+  - id: "R001"
+    pattern: 'foo'
+    level: "warning"
+    confidence: 0.5
+    message: "found foo"
+    allowlist: ["[invalid"]
+`
+	_, err := ParseRuleFile([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected error for invalid allowlist entry")
+	}
+	if !strings.Contains(err.Error(), "allowlist") {
+		t.Errorf("expected allowlist error, got: %v", err)
+	}
+}
+
+func TestRule_MatchValueWithoutGroup(t *testing.T) {
+	r := Rule{Pattern: regexp.MustCompile(`\d+`)}
+	content := "x = 42"
+	match := r.Pattern.FindStringSubmatchIndex(content)
+	if got := r.MatchValue(content, match); got != "42" {
+		t.Errorf("expected full match 42, got %q", got)
+	}
+}
+
+func TestParseRuleFile_Scope(t *testing.T) {
+	yaml := `rules:
+  - id: "R001"
+    pattern: '\d{3,}'
+    scope: '^\s*return\b'
+    level: "note"
+    confidence: 0.5
+    message: "magic number"
+`
+	rf, err := ParseRuleFile([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := rf.Rules[0]
+	content := "x := 500\n\treturn x + 600\n"
+	var inScope []string
+	for _, m := range r.Pattern.FindAllStringIndex(content, -1) {
+		if r.InScope(content, m[0]) {
+			inScope = append(inScope, content[m[0]:m[1]])
+		}
+	}
+	if strings.Join(inScope, ",") != "600" {
+		t.Errorf("expected only the match on the return line, got %v", inScope)
+	}
+
+	if _, err := ParseRuleFile([]byte(strings.Replace(yaml, `'^\s*return\b'`, `'[invalid'`, 1))); err == nil || !strings.Contains(err.Error(), "scope") {
+		t.Errorf("expected an invalid scope error, got %v", err)
+	}
+}
+
+func TestParseRuleFile_Fix(t *testing.T) {
+	yaml := `rules:
+  - id: "R001"
+    pattern: 'md5\.New\(\)'
+    level: "warning"
+    confidence: 0.8
+    message: "weak hash"
+    fix:
+      replacement: "sha256.New()"
+      description: "Use SHA-256"
+`
+	rf, err := ParseRuleFile([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fix := rf.Rules[0].Fix
+	if fix == nil || fix.Replacement != "sha256.New()" || fix.Description != "Use SHA-256" || fix.Delete {
+		t.Errorf("unexpected fix %+v", fix)
+	}
+
+	for name, section := range map[string]string{
+		"empty": "{}",
+		"both":  "{replacement: \"x\", delete: true}",
+	} {
+		bad := strings.Replace(yaml, "fix:\n      replacement: \"sha256.New()\"\n      description: \"Use SHA-256\"", "fix: "+section, 1)
+		if _, err := ParseRuleFile([]byte(bad)); err == nil || !strings.Contains(err.Error(), "fix:") {
+			t.Errorf("%s: expected fix validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestParseRuleFile_ASTQueryRule(t *testing.T) {
+	yaml := `rules:
+  - id: "Q001"
+    type: "ast-query"
+    languages: ["go"]
+    query: '(call_expression function: (identifier) @fn (#eq? @fn "panic")) @call'
+    capture: "call"
+    level: "warning"
+    confidence: 0.7
+    message: "avoid ${fn} in library code"
+`
+	rf, err := ParseRuleFile([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := rf.Rules[0]
+	if r.Type != RuleTypeASTQuery || r.Capture != "call" || r.QueryCheck == nil {
+		t.Errorf("expected a compiled ast-query rule, got %+v", r)
+	}
+
+	tests := []struct {
+		name, from, to, wantErr string
+	}{
+		{"missing query", "    query: '(call_expression function: (identifier) @fn (#eq? @fn \"panic\")) @call'\n", "", "query"},
+		{"missing languages", "    languages: [\"go\"]\n", "", "languages"},
+		{"invalid query", "(call_expression function:", "(call_expression nope:", "invalid query"},
+		{"unknown capture", `capture: "call"`, `capture: "other"`, "invalid query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuleFile([]byte(strings.Replace(yaml, tt.from, tt.to, 1)))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseRuleFile_DependencyRule(t *testing.T) {
+	yaml := `rules:
+  - id: "DEP001"
+    type: dependency
+    category: dependency
+    dependency:
+      ecosystem: go
+      packages: ["golang.org/x/crypto"]
+      min_version: "0.17.0"
+    level: "error"
+    confidence: 1.0
+    message: "golang.org/x/crypto is vulnerable to Terrapin"
+`
+	rf, err := ParseRuleFile([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := rf.Rules[0]
+	if r.Type != RuleTypeDependency || r.Category != CategoryDependency {
+		t.Errorf("expected type and category dependency, got %s and %s", r.Type, r.Category)
+	}
+	if r.Dependency == nil || r.Dependency.MinVersion != "0.17.0" || r.Dependency.Packages[0] != "golang.org/x/crypto" {
+		t.Errorf("dependency constraint not parsed: %+v", r.Dependency)
+	}
+}
+
+func TestParseRuleFile_DependencyRuleInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		dependency string
+		want       string
+	}{
+		"missing block":    {"", "dependency"},
+		"nothing to match": {"    dependency:\n      min_version: \"1.0\"\n", "packages or licenses"},
+		"bad pattern":      {"    dependency:\n      packages: [\"[\"]\n", "invalid pattern"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			yaml := "rules:\n  - id: \"DEP\"\n    type: dependency\n" + tc.dependency +
+				"    level: \"error\"\n    confidence: 1.0\n    message: \"bad\"\n"
+			_, err := ParseRuleFile([]byte(yaml))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %q does not mention %q", err, tc.want)
+			}
+		})
+	}
+}
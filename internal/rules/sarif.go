@@ -97,13 +97,37 @@ func resolveHelpURI(r Rule) string {
 		return r.References[0]
 	}
 	if len(r.CWE) > 0 {
-		return cweURL(r.CWE[0])
+		return CWEURL(r.CWE[0])
 	}
 	return ""
 }
 
-// cweURL returns the canonical cwe.mitre.org URL for a CWE id like "CWE-798".
-func cweURL(id string) string {
+// CWEURL returns the canonical cwe.mitre.org URL for a CWE id like "CWE-798".
+func CWEURL(id string) string {
 	num := strings.TrimPrefix(id, "CWE-")
 	return "https://cwe.mitre.org/data/definitions/" + num + ".html"
 }
+
+// owaspTop10Pages maps OWASP Top 10 2021 ids to their page names on owasp.org.
+var owaspTop10Pages = map[string]string{
+	"A01:2021": "A01_2021-Broken_Access_Control",
+	"A02:2021": "A02_2021-Cryptographic_Failures",
+	"A03:2021": "A03_2021-Injection",
+	"A04:2021": "A04_2021-Insecure_Design",
+	"A05:2021": "A05_2021-Security_Misconfiguration",
+	"A06:2021": "A06_2021-Vulnerable_and_Outdated_Components",
+	"A07:2021": "A07_2021-Identification_and_Authentication_Failures",
+	"A08:2021": "A08_2021-Software_and_Data_Integrity_Failures",
+	"A09:2021": "A09_2021-Security_Logging_and_Monitoring_Failures",
+	"A10:2021": "A10_2021-Server-Side_Request_Forgery_%28SSRF%29",
+}
+
+// OWASPURL returns the owasp.org page for an OWASP Top 10 2021 id like
+// "A03:2021", or an empty string for ids it does not know.
+func OWASPURL(id string) string {
+	page, ok := owaspTop10Pages[id]
+	if !ok {
+		return ""
+	}
+	return "https://owasp.org/Top10/" + page + "/"
+}
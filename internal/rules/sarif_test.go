@@ -211,3 +211,15 @@ func TestToSARIFDescriptor_NoRelationshipsForMinimalRule(t *testing.T) {
 		t.Errorf("expected no relationships for minimal rule, got %d", len(d.Relationships))
 	}
 }
+
+func TestReferenceURLs(t *testing.T) {
+	if got := CWEURL("CWE-89"); got != "https://cwe.mitre.org/data/definitions/89.html" {
+		t.Errorf("CWEURL = %q", got)
+	}
+	if got := OWASPURL("A03:2021"); got != "https://owasp.org/Top10/A03_2021-Injection/" {
+		t.Errorf("OWASPURL = %q", got)
+	}
+	if got := OWASPURL("A1:2017"); got != "" {
+		t.Errorf("expected no URL for an unknown id, got %q", got)
+	}
+}
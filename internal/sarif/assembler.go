@@ -1,8 +1,43 @@
 package sarif
 
+// AssembleOption configures Assemble.
+type AssembleOption func(*assembleOptions)
+
+type assembleOptions struct {
+	dedupWindow     int
+	dedupMode       DedupMode
+	dedupSimilarity float64
+	provenance      *Provenance
+}
+
+// WithDedupWindow collapses findings of the same rule in the same file whose
+// line ranges overlap or lie within n lines of each other. The default of 0
+// only collapses ranges that actually overlap.
+func WithDedupWindow(n int) AssembleOption {
+	return func(o *assembleOptions) {
+		o.dedupWindow = n
+	}
+}
+
+// WithDedupMode selects how findings are collapsed (see DedupMode). In
+// fuzzy mode, similarity is the minimum message similarity between 0 and 1
+// for findings of different rules to count as duplicates; zero selects
+// DefaultDedupSimilarity.
+func WithDedupMode(mode DedupMode, similarity float64) AssembleOption {
+	return func(o *assembleOptions) {
+		o.dedupMode = mode
+		o.dedupSimilarity = similarity
+	}
+}
+
 // Assemble creates a SARIF log from analysis results, deduplicating overlapping findings.
-func Assemble(results []Result, rules []ReportingDescriptor, inputScope, persona string) *Log {
-	deduped := dedup(results)
+func Assemble(results []Result, rules []ReportingDescriptor, inputScope, persona string, opts ...AssembleOption) *Log {
+	var o assembleOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	deduped := dedup(results, o)
 	for i := range deduped {
 		SetContentFingerprint(&deduped[i])
 	}
@@ -15,17 +50,33 @@ func Assemble(results []Result, rules []ReportingDescriptor, inputScope, persona
 		"gavel/inputScope": inputScope,
 		"gavel/persona":    persona,
 	}
+	if o.provenance != nil {
+		log.Runs[0].Properties[PropProvenance] = *o.provenance
+	}
 
 	return log
 }
 
-func dedup(results []Result) []Result {
+// dedup collapses findings of the same rule in the same file whose regions
+// are within the window of each other, keeping the higher-confidence one,
+// and in fuzzy mode also near-identical findings of different rules.
+// Results keep their input order.
+func dedup(results []Result, o assembleOptions) []Result {
+	if o.dedupMode == DedupOff {
+		return results
+	}
+
 	type key struct {
 		ruleID string
 		uri    string
 	}
 
-	best := make(map[key]Result)
+	out := make([]Result, 0, len(results))
+	// groups maps rule+file to the indexes in out of the findings kept so far.
+	groups := make(map[key][]int)
+	// files maps a file to the indexes in out of its findings, for fuzzy
+	// matching across rules.
+	files := make(map[string][]int)
 	for _, r := range results {
 		uri := ""
 		if len(r.Locations) > 0 {
@@ -33,42 +84,58 @@ func dedup(results []Result) []Result {
 		}
 		k := key{ruleID: r.RuleID, uri: uri}
 
-		existing, ok := best[k]
-		if !ok {
-			best[k] = r
-			continue
-		}
-
-		if len(r.Locations) > 0 && len(existing.Locations) > 0 {
+		merged := false
+		for _, i := range groups[k] {
+			existing := out[i]
+			if len(r.Locations) == 0 || len(existing.Locations) == 0 {
+				continue
+			}
 			rRegion := r.Locations[0].PhysicalLocation.Region
 			eRegion := existing.Locations[0].PhysicalLocation.Region
-			if regionsOverlap(rRegion, eRegion) {
+			if merged = regionsWithin(rRegion, eRegion, o.dedupWindow); merged {
 				if confidence(r) > confidence(existing) {
-					best[k] = r
+					out[i] = r
 				}
-				continue
+				break
 			}
 		}
-
-		// Non-overlapping same rule+file: keep both
-		for i := 1; ; i++ {
-			newKey := key{ruleID: r.RuleID + string(rune(i)), uri: uri}
-			if _, exists := best[newKey]; !exists {
-				best[newKey] = r
-				break
+		if !merged && o.dedupMode == DedupFuzzy && len(r.Locations) > 0 {
+			if i, ok := fuzzyMatch(out, files[uri], r, o); ok {
+				out[i] = mergeFuzzy(out[i], r)
+				if out[i].RuleID == r.RuleID {
+					// Later findings of r's rule now match the kept one
+					groups[k] = append(groups[k], i)
+				}
+				merged = true
 			}
 		}
-	}
+		if merged {
+			continue
+		}
 
-	out := make([]Result, 0, len(best))
-	for _, r := range best {
+		// Non-overlapping same rule+file: keep both
+		groups[k] = append(groups[k], len(out))
+		if len(r.Locations) > 0 {
+			files[uri] = append(files[uri], len(out))
+		}
 		out = append(out, r)
 	}
 	return out
 }
 
-func regionsOverlap(a, b Region) bool {
-	return a.StartLine <= b.EndLine && b.StartLine <= a.EndLine
+// regionsWithin reports whether a and b overlap once each is widened by
+// window lines.
+func regionsWithin(a, b Region, window int) bool {
+	return a.StartLine <= regionEnd(b)+window && b.StartLine <= regionEnd(a)+window
+}
+
+// regionEnd returns the region's end line, treating a missing end as the
+// start line.
+func regionEnd(r Region) int {
+	if r.EndLine < r.StartLine {
+		return r.StartLine
+	}
+	return r.EndLine
 }
 
 func confidence(r Result) float64 {
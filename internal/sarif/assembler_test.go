@@ -69,6 +69,66 @@ func TestAssemble_Dedup(t *testing.T) {
 	}
 }
 
+// windowResult returns a rule-a finding in foo.go spanning start..end.
+func windowResult(start, end int, conf float64) Result {
+	return Result{
+		RuleID: "rule-a", Level: "warning", Message: Message{Text: "issue"},
+		Locations: []Location{{PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: ArtifactLocation{URI: "foo.go"},
+			Region:           Region{StartLine: start, EndLine: end},
+		}}},
+		Properties: map[string]interface{}{"gavel/confidence": conf},
+	}
+}
+
+func TestAssemble_DedupWindow(t *testing.T) {
+	// 10-20 and 23-30 do not overlap, but are 3 lines apart.
+	results := []Result{windowResult(10, 20, 0.6), windowResult(23, 30, 0.8)}
+
+	log := Assemble(results, nil, "files", "architect")
+	if got := len(log.Runs[0].Results); got != 2 {
+		t.Fatalf("expected 2 results without a window, got %d", got)
+	}
+
+	log = Assemble(results, nil, "files", "architect", WithDedupWindow(3))
+	if got := len(log.Runs[0].Results); got != 1 {
+		t.Fatalf("expected results within the window to collapse to 1, got %d", got)
+	}
+	if log.Runs[0].Results[0].Properties["gavel/confidence"] != 0.8 {
+		t.Errorf("expected to keep higher confidence finding")
+	}
+}
+
+func TestAssemble_DedupWindow_OutsideWindowStaysSeparate(t *testing.T) {
+	results := []Result{windowResult(10, 20, 0.6), windowResult(30, 40, 0.8)}
+
+	log := Assemble(results, nil, "files", "architect", WithDedupWindow(5))
+	if got := len(log.Runs[0].Results); got != 2 {
+		t.Errorf("expected findings 10 lines apart to stay separate with window 5, got %d", got)
+	}
+}
+
+func TestAssemble_DedupWindow_DifferentRulesNotCollapsed(t *testing.T) {
+	other := windowResult(12, 14, 0.9)
+	other.RuleID = "rule-b"
+	results := []Result{windowResult(10, 20, 0.6), other}
+
+	log := Assemble(results, nil, "files", "architect", WithDedupWindow(10))
+	if got := len(log.Runs[0].Results); got != 2 {
+		t.Errorf("expected different rules to stay separate, got %d", got)
+	}
+}
+
+func TestAssembler_DedupWindow(t *testing.T) {
+	log := NewAssembler().
+		AddResults([]Result{windowResult(1, 2, 0.5), windowResult(4, 5, 0.5)}).
+		WithDedupWindow(2).
+		Build()
+	if got := len(log.Runs[0].Results); got != 1 {
+		t.Errorf("expected builder window to collapse findings, got %d", got)
+	}
+}
+
 func TestAssembler_AddsCacheMetadata(t *testing.T) {
 	results := []Result{
 		{
@@ -243,3 +303,78 @@ func TestAssemble_PopulatesContentFingerprints(t *testing.T) {
 		t.Errorf("expected Assemble to populate %q; fingerprints=%v", ContentFingerprintV1, r.Fingerprints)
 	}
 }
+
+// fuzzyResult returns a finding of rule in foo.go at line with the given
+// message, tier and confidence.
+func fuzzyResult(rule string, line int, msg, tier string, conf float64) Result {
+	return Result{
+		RuleID: rule, Level: "warning", Message: Message{Text: msg},
+		Locations: []Location{{PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: ArtifactLocation{URI: "foo.go"},
+			Region:           Region{StartLine: line, EndLine: line},
+		}}},
+		Properties: map[string]interface{}{"gavel/tier": tier, "gavel/confidence": conf},
+	}
+}
+
+func TestAssemble_DedupFuzzy(t *testing.T) {
+	results := []Result{
+		fuzzyResult("S2068", 10, "Hardcoded password in source code", "instant", 0.9),
+		fuzzyResult("security", 11, "A hardcoded password is stored in the source code.", "comprehensive", 0.8),
+		fuzzyResult("errors", 11, "Error returned by Close is ignored", "comprehensive", 0.8),
+	}
+
+	log := Assemble(results, nil, "files", "architect")
+	if got := len(log.Runs[0].Results); got != 3 {
+		t.Fatalf("expected strict mode to keep different rules apart, got %d", got)
+	}
+
+	log = Assemble(results, nil, "files", "architect", WithDedupMode(DedupFuzzy, 0))
+	got := log.Runs[0].Results
+	if len(got) != 2 {
+		t.Fatalf("expected the similar findings to collapse, got %d", len(got))
+	}
+	if got[0].RuleID != "security" {
+		t.Errorf("expected the comprehensive-tier finding to be kept, got %s", got[0].RuleID)
+	}
+	if dups, _ := got[0].Properties["gavel/duplicates"].([]string); len(dups) != 1 || dups[0] != "S2068" {
+		t.Errorf("expected the collapsed rule to be recorded, got %v", got[0].Properties["gavel/duplicates"])
+	}
+	if _, ok := results[1].Properties["gavel/duplicates"]; ok {
+		t.Error("fuzzy dedup must not modify the input results")
+	}
+	if got[1].RuleID != "errors" {
+		t.Errorf("expected the unrelated finding to stay, got %s", got[1].RuleID)
+	}
+}
+
+func TestAssemble_DedupFuzzy_Thresholds(t *testing.T) {
+	far := []Result{
+		fuzzyResult("S2068", 10, "Hardcoded password", "instant", 0.9),
+		fuzzyResult("security", 14, "Hardcoded password", "comprehensive", 0.8),
+	}
+	if got := len(Assemble(far, nil, "files", "architect", WithDedupMode(DedupFuzzy, 0)).Runs[0].Results); got != 2 {
+		t.Errorf("expected findings four lines apart to stay separate, got %d", got)
+	}
+	if got := len(Assemble(far, nil, "files", "architect", WithDedupWindow(4), WithDedupMode(DedupFuzzy, 0)).Runs[0].Results); got != 1 {
+		t.Errorf("expected the dedup window to widen fuzzy matching, got %d", got)
+	}
+
+	loose := []Result{
+		fuzzyResult("S2068", 10, "Hardcoded password in config", "instant", 0.9),
+		fuzzyResult("security", 10, "Password compared in non-constant time", "comprehensive", 0.8),
+	}
+	if got := len(Assemble(loose, nil, "files", "architect", WithDedupMode(DedupFuzzy, 0)).Runs[0].Results); got != 2 {
+		t.Errorf("expected dissimilar messages to stay separate, got %d", got)
+	}
+	if got := len(Assemble(loose, nil, "files", "architect", WithDedupMode(DedupFuzzy, 0.2)).Runs[0].Results); got != 1 {
+		t.Errorf("expected a lower similarity threshold to collapse them, got %d", got)
+	}
+}
+
+func TestAssemble_DedupOff(t *testing.T) {
+	results := []Result{windowResult(10, 20, 0.6), windowResult(12, 14, 0.8)}
+	if got := len(Assemble(results, nil, "files", "architect", WithDedupMode(DedupOff, 0)).Runs[0].Results); got != 2 {
+		t.Errorf("expected dedup off to keep every finding, got %d", got)
+	}
+}
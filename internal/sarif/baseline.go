@@ -91,6 +91,22 @@ func CompareBaseline(current, baseline *Log) {
 	}
 }
 
+// ResolvedResults returns the results CompareBaseline marked absent: findings
+// present in the baseline that no longer appear in the current run. The
+// returned slice is empty (never nil) when nothing was resolved.
+func ResolvedResults(log *Log) []Result {
+	resolved := []Result{}
+	if log == nil || len(log.Runs) == 0 {
+		return resolved
+	}
+	for _, r := range log.Runs[0].Results {
+		if r.BaselineState == BaselineStateAbsent {
+			resolved = append(resolved, r)
+		}
+	}
+	return resolved
+}
+
 // EnsureAutomationDetails sets a fresh automation GUID on the run if it is
 // missing. Call this before storing a new SARIF log so subsequent runs can
 // reference it via BaselineGuid. Existing GUIDs are left alone so callers
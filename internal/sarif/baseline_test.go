@@ -167,6 +167,38 @@ func TestCompareBaseline_DoesNotMutateBaseline(t *testing.T) {
 	}
 }
 
+func TestResolvedResults(t *testing.T) {
+	baseline := makeLog(
+		makeResult("SEC001", "a.go", "password := \"hunter2\"\n", 10),
+		makeResult("SEC002", "b.go", "eval(userInput)\n", 20),
+	)
+	current := makeLog(
+		makeResult("SEC001", "a.go", "password := \"hunter2\"\n", 10),
+	)
+	CompareBaseline(current, baseline)
+
+	resolved := ResolvedResults(current)
+	if len(resolved) != 1 {
+		t.Fatalf("expected 1 resolved result, got %d", len(resolved))
+	}
+	if resolved[0].RuleID != "SEC002" {
+		t.Errorf("expected SEC002 resolved, got %s", resolved[0].RuleID)
+	}
+}
+
+func TestResolvedResults_NoneResolved(t *testing.T) {
+	current := makeLog(makeResult("SEC001", "a.go", "x\n", 1))
+	CompareBaseline(current, makeLog(makeResult("SEC001", "a.go", "x\n", 1)))
+
+	resolved := ResolvedResults(current)
+	if resolved == nil || len(resolved) != 0 {
+		t.Errorf("expected empty non-nil slice, got %#v", resolved)
+	}
+	if got := ResolvedResults(nil); got == nil || len(got) != 0 {
+		t.Errorf("expected empty slice for nil log, got %#v", got)
+	}
+}
+
 func TestEnsureAutomationDetails_AssignsGUID(t *testing.T) {
 	log := makeLog()
 	EnsureAutomationDetails(log)
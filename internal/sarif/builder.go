@@ -15,6 +15,7 @@ type Assembler struct {
 	rules         []ReportingDescriptor
 	inputScope    string
 	cacheMetadata *CacheMetadata
+	dedupWindow   int
 }
 
 // NewAssembler creates a new Assembler with default values
@@ -37,20 +38,8 @@ func (a *Assembler) WithCacheMetadata(fileHash string, cfg *config.Config, bamlV
 		}
 	}
 
-	model := ""
 	provider := cfg.Provider.Name
-	switch provider {
-	case "openrouter":
-		model = cfg.Provider.OpenRouter.Model
-	case "ollama":
-		model = cfg.Provider.Ollama.Model
-	case "anthropic":
-		model = cfg.Provider.Anthropic.Model
-	case "bedrock":
-		model = cfg.Provider.Bedrock.Model
-	case "openai":
-		model = cfg.Provider.OpenAI.Model
-	}
+	model := providerModel(cfg.Provider)
 
 	a.cacheMetadata = &CacheMetadata{
 		FileHash:    fileHash,
@@ -80,10 +69,17 @@ func (a *Assembler) WithInputScope(scope string) *Assembler {
 	return a
 }
 
+// WithDedupWindow sets the line window within which same-rule findings in a
+// file are collapsed (see the package-level WithDedupWindow).
+func (a *Assembler) WithDedupWindow(n int) *Assembler {
+	a.dedupWindow = n
+	return a
+}
+
 // Build constructs the final SARIF log with all configured metadata
 func (a *Assembler) Build() *Log {
 	// Deduplicate results
-	deduped := dedup(a.results)
+	deduped := dedup(a.results, assembleOptions{dedupWindow: a.dedupWindow})
 
 	// Populate content-based fingerprints on every result so the SARIF log
 	// carries stable identifiers for baseline comparison downstream.
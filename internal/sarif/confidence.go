@@ -0,0 +1,42 @@
+package sarif
+
+// KindInformational is the SARIF result kind for findings kept for the
+// record but not acted on, such as those below the minimum confidence.
+const KindInformational = "informational"
+
+// PropOriginalLevel records the level of a finding demoted to
+// informational, since SARIF requires such results to have level "none".
+const PropOriginalLevel = "gavel/originalLevel"
+
+// DemoteBelowConfidence marks each result whose gavel/confidence is below
+// minFor(ruleID) as informational, so it stays in the log without counting
+// towards the verdict, and returns how many it marked. Results without a
+// confidence, and suppressed ones, are left alone.
+func DemoteBelowConfidence(results []Result, minFor func(ruleID string) float64) int {
+	n := 0
+	for i := range results {
+		r := &results[i]
+		if len(r.Suppressions) > 0 || r.Kind == KindInformational {
+			continue
+		}
+		conf, ok := r.Properties["gavel/confidence"].(float64)
+		if !ok {
+			continue
+		}
+		threshold := minFor(r.RuleID)
+		if threshold <= 0 || conf >= threshold {
+			continue
+		}
+
+		props := make(map[string]interface{}, len(r.Properties)+1)
+		for k, v := range r.Properties {
+			props[k] = v
+		}
+		props[PropOriginalLevel] = r.Level
+		r.Properties = props
+		r.Kind = KindInformational
+		r.Level = "none"
+		n++
+	}
+	return n
+}
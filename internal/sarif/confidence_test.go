@@ -0,0 +1,38 @@
+package sarif
+
+import "testing"
+
+func TestDemoteBelowConfidence(t *testing.T) {
+	shared := map[string]interface{}{"gavel/confidence": 0.4}
+	results := []Result{
+		{RuleID: "low", Level: "error", Properties: shared},
+		{RuleID: "high", Level: "warning", Properties: map[string]interface{}{"gavel/confidence": 0.9}},
+		{RuleID: "strict", Level: "warning", Properties: map[string]interface{}{"gavel/confidence": 0.9}},
+		{RuleID: "unknown", Level: "warning"},
+		{RuleID: "suppressed", Level: "warning", Properties: map[string]interface{}{"gavel/confidence": 0.1}, Suppressions: []SARIFSuppression{{Kind: "external"}}},
+	}
+	minFor := func(ruleID string) float64 {
+		if ruleID == "strict" {
+			return 0.95
+		}
+		return 0.5
+	}
+
+	if n := DemoteBelowConfidence(results, minFor); n != 2 {
+		t.Fatalf("expected 2 demoted findings, got %d", n)
+	}
+	for i, want := range []string{KindInformational, "", KindInformational, "", ""} {
+		if results[i].Kind != want {
+			t.Errorf("%s: kind = %q, want %q", results[i].RuleID, results[i].Kind, want)
+		}
+	}
+	if results[0].Level != "none" || results[0].Properties[PropOriginalLevel] != "error" {
+		t.Errorf("expected the level to move to %s, got %+v", PropOriginalLevel, results[0])
+	}
+	if _, ok := shared[PropOriginalLevel]; ok {
+		t.Error("demoting must not modify shared properties")
+	}
+	if n := DemoteBelowConfidence(results, minFor); n != 0 {
+		t.Errorf("expected demoting twice to be a no-op, got %d", n)
+	}
+}
@@ -0,0 +1,47 @@
+package sarif
+
+import "strconv"
+
+// tierPriority ranks the analysis tiers recorded in gavel/tier. Results
+// from tools other than Gavel have no tier and rank as instant.
+var tierPriority = map[string]int{"comprehensive": 3, "fast": 2, "instant": 1}
+
+// DeduplicateByTier keeps one result per rule, file and start line,
+// preferring the one from the highest gavel/tier (comprehensive, then fast,
+// then instant); among equals the first is kept. Results keep the order in
+// which their key first appeared. Results without a location cannot be
+// matched against others and are kept as they are.
+func DeduplicateByTier(results []Result) []Result {
+	index := make(map[string]int, len(results))
+	deduplicated := make([]Result, 0, len(results))
+
+	for _, r := range results {
+		if len(r.Locations) == 0 {
+			deduplicated = append(deduplicated, r)
+			continue
+		}
+
+		loc := r.Locations[0].PhysicalLocation
+		key := r.RuleID + "|" + loc.ArtifactLocation.URI + "|" + strconv.Itoa(loc.Region.StartLine)
+
+		if i, ok := index[key]; ok {
+			// Keep higher-tier result
+			if tierPriority[resultTier(r)] > tierPriority[resultTier(deduplicated[i])] {
+				deduplicated[i] = r
+			}
+			continue
+		}
+		index[key] = len(deduplicated)
+		deduplicated = append(deduplicated, r)
+	}
+
+	return deduplicated
+}
+
+// resultTier returns a result's gavel/tier, defaulting to instant.
+func resultTier(r Result) string {
+	if t, ok := r.Properties["gavel/tier"].(string); ok {
+		return t
+	}
+	return "instant"
+}
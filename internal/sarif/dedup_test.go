@@ -0,0 +1,56 @@
+package sarif
+
+import "testing"
+
+func tieredResult(ruleID, uri string, line int, tier string) Result {
+	r := makeResult(ruleID, uri, "x\n", line)
+	if tier != "" {
+		r.Properties = map[string]interface{}{"gavel/tier": tier}
+	}
+	return r
+}
+
+func TestDeduplicateByTier_PrefersHigherTier(t *testing.T) {
+	results := []Result{
+		tieredResult("R1", "a.go", 10, "instant"),
+		tieredResult("R2", "a.go", 12, "instant"),
+		tieredResult("R1", "a.go", 10, "comprehensive"),
+		tieredResult("R1", "a.go", 10, "fast"),
+	}
+
+	got := DeduplicateByTier(results)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(got))
+	}
+	if got[0].RuleID != "R1" || resultTier(got[0]) != "comprehensive" {
+		t.Errorf("expected comprehensive R1 first, got %s/%s", got[0].RuleID, resultTier(got[0]))
+	}
+	if got[1].RuleID != "R2" {
+		t.Errorf("expected R2 second, got %s", got[1].RuleID)
+	}
+}
+
+func TestDeduplicateByTier_KeepsFirstAmongEquals(t *testing.T) {
+	first := tieredResult("R1", "a.go", 10, "")
+	first.Message.Text = "first"
+	second := tieredResult("R1", "a.go", 10, "")
+	second.Message.Text = "second"
+
+	got := DeduplicateByTier([]Result{first, second})
+	if len(got) != 1 || got[0].Message.Text != "first" {
+		t.Fatalf("expected only the first result, got %+v", got)
+	}
+}
+
+func TestDeduplicateByTier_DistinctLocations(t *testing.T) {
+	results := []Result{
+		tieredResult("R1", "a.go", 10, "instant"),
+		tieredResult("R1", "a.go", 11, "instant"),
+		tieredResult("R1", "b.go", 10, "instant"),
+		{RuleID: "R1", Message: Message{Text: "no location"}},
+	}
+
+	if got := DeduplicateByTier(results); len(got) != 4 {
+		t.Errorf("expected all 4 results kept, got %d", len(got))
+	}
+}
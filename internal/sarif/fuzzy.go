@@ -0,0 +1,128 @@
+package sarif
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DedupMode selects how Assemble collapses duplicate findings.
+type DedupMode string
+
+const (
+	// DedupStrict collapses findings of the same rule in the same file
+	// whose ranges are within the dedup window. It is the default.
+	DedupStrict DedupMode = "strict"
+	// DedupFuzzy also collapses findings of different rules, such as an
+	// LLM finding one line off from a pattern finding, when their ranges
+	// are within the window (at least one line) and their messages are
+	// similar.
+	DedupFuzzy DedupMode = "fuzzy"
+	// DedupOff keeps every finding.
+	DedupOff DedupMode = "off"
+)
+
+// DefaultDedupSimilarity is the message similarity above which fuzzy
+// deduplication treats findings of different rules as duplicates.
+const DefaultDedupSimilarity = 0.5
+
+// fuzzyMatch returns the index in out of the kept finding, among
+// candidates, that r duplicates under fuzzy deduplication: a different
+// rule nearby with the most similar message at or above the threshold.
+func fuzzyMatch(out []Result, candidates []int, r Result, o assembleOptions) (int, bool) {
+	threshold := o.dedupSimilarity
+	if threshold <= 0 {
+		threshold = DefaultDedupSimilarity
+	}
+	window := o.dedupWindow
+	if window < 1 {
+		window = 1
+	}
+
+	best, bestScore := -1, 0.0
+	words := messageWords(r.Message.Text)
+	for _, i := range candidates {
+		existing := out[i]
+		if existing.RuleID == r.RuleID {
+			continue
+		}
+		if !regionsWithin(r.Locations[0].PhysicalLocation.Region, existing.Locations[0].PhysicalLocation.Region, window) {
+			continue
+		}
+		if score := similarity(words, messageWords(existing.Message.Text)); score >= threshold && score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best, best >= 0
+}
+
+// mergeFuzzy returns the finding to keep of kept and dup: the one from the
+// higher gavel/tier, then the more confident one, then kept. The other is
+// recorded in its gavel/duplicates.
+func mergeFuzzy(kept, dup Result) Result {
+	winner, loser := kept, dup
+	kt, dt := tierPriority[resultTier(kept)], tierPriority[resultTier(dup)]
+	if dt > kt || (dt == kt && confidence(dup) > confidence(kept)) {
+		winner, loser = dup, kept
+	}
+
+	// Copy the properties, which may be shared with a cached result
+	props := make(map[string]interface{}, len(winner.Properties)+1)
+	for k, v := range winner.Properties {
+		props[k] = v
+	}
+	dups, _ := props["gavel/duplicates"].([]string)
+	dups = append(append([]string(nil), dups...), duplicateName(loser))
+	if more, ok := loser.Properties["gavel/duplicates"].([]string); ok {
+		dups = append(dups, more...)
+	}
+	props["gavel/duplicates"] = dups
+	winner.Properties = props
+	return winner
+}
+
+// duplicateName names a collapsed finding as ingest does: tool:rule for
+// findings from other tools, otherwise the rule ID.
+func duplicateName(r Result) string {
+	if tool, ok := r.Properties["gavel/tool"].(string); ok && tool != "" {
+		return tool + ":" + r.RuleID
+	}
+	return r.RuleID
+}
+
+// stopWords are left out of message comparison, since nearly every message
+// contains some of them.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "be": true, "by": true,
+	"for": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "should": true, "that": true, "the": true, "this": true,
+	"to": true, "with": true,
+}
+
+// messageWords normalizes a message to its set of lowercase words, without
+// punctuation or stop words.
+func messageWords(msg string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(msg), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// similarity is the Dice coefficient of two word sets, from 0 for no words
+// in common to 1 for the same words.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
@@ -0,0 +1,112 @@
+package sarif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// PropProvenance is the run property holding the Provenance of a report.
+const PropProvenance = "gavel/provenance"
+
+// Provenance records what produced a report so it can be audited and its
+// inputs compared against a later run. Hashes are "sha256:" followed by the
+// hex digest of the input's canonical JSON encoding; identical inputs always
+// hash identically.
+type Provenance struct {
+	GavelVersion string `json:"gavelVersion"`
+	ConfigHash   string `json:"configHash"`
+	RulesHash    string `json:"rulesHash"`
+	PersonaHash  string `json:"personaHash"`
+	ProviderHash string `json:"providerHash"`
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+}
+
+// NewProvenance hashes the effective config, the loaded rule set (any
+// JSON-encodable value, typically []rules.Rule), and the resolved persona
+// prompt. Credentials in cfg are blanked before hashing so the config hash
+// can be reproduced without access to secrets.
+func NewProvenance(version string, cfg *config.Config, ruleSet interface{}, personaPrompt string) (Provenance, error) {
+	redacted := *cfg
+	redacted.RemoteCache.Auth.Token = ""
+	if len(cfg.Telemetry.Headers) > 0 {
+		redacted.Telemetry.Headers = make(map[string]string, len(cfg.Telemetry.Headers))
+		for k := range cfg.Telemetry.Headers {
+			redacted.Telemetry.Headers[k] = ""
+		}
+	}
+
+	configHash, err := hashJSON(redacted)
+	if err != nil {
+		return Provenance{}, fmt.Errorf("hashing config: %w", err)
+	}
+	rulesHash, err := hashJSON(ruleSet)
+	if err != nil {
+		return Provenance{}, fmt.Errorf("hashing rules: %w", err)
+	}
+	personaHash, err := hashJSON(personaPrompt)
+	if err != nil {
+		return Provenance{}, fmt.Errorf("hashing persona: %w", err)
+	}
+
+	provider := cfg.Provider.Name
+	model := providerModel(cfg.Provider)
+	providerHash, err := hashJSON(struct {
+		Provider string
+		Model    string
+	}{provider, model})
+	if err != nil {
+		return Provenance{}, fmt.Errorf("hashing provider: %w", err)
+	}
+
+	return Provenance{
+		GavelVersion: version,
+		ConfigHash:   configHash,
+		RulesHash:    rulesHash,
+		PersonaHash:  personaHash,
+		ProviderHash: providerHash,
+		Provider:     provider,
+		Model:        model,
+	}, nil
+}
+
+// WithProvenance records p in the run's gavel/provenance property.
+func WithProvenance(p Provenance) AssembleOption {
+	return func(o *assembleOptions) {
+		o.provenance = &p
+	}
+}
+
+// hashJSON returns the sha256 of v's JSON encoding. encoding/json sorts map
+// keys, so maps hash deterministically.
+func hashJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// providerModel returns the model configured for the selected provider.
+func providerModel(p config.ProviderConfig) string {
+	switch p.Name {
+	case "openrouter":
+		return p.OpenRouter.Model
+	case "ollama":
+		return p.Ollama.Model
+	case "anthropic":
+		return p.Anthropic.Model
+	case "bedrock":
+		return p.Bedrock.Model
+	case "openai":
+		return p.OpenAI.Model
+	case "llamacpp":
+		return p.LlamaCpp.Model
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package sarif
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+func provenanceConfig() *config.Config {
+	return &config.Config{
+		Provider: config.ProviderConfig{
+			Name:       "openrouter",
+			OpenRouter: config.OpenRouterConfig{Model: "anthropic/claude-sonnet-4"},
+		},
+		Persona: "code-reviewer",
+		Policies: map[string]config.Policy{
+			"shall-be-merged": {Description: "d", Severity: "error", Instruction: "i", Enabled: true},
+		},
+	}
+}
+
+var provenanceRules = []map[string]string{
+	{"id": "S2068", "pattern": `password\s*=`},
+}
+
+func mustProvenance(t *testing.T, cfg *config.Config, ruleSet interface{}, persona string) Provenance {
+	t.Helper()
+	p, err := NewProvenance("1.2.3", cfg, ruleSet, persona)
+	if err != nil {
+		t.Fatalf("NewProvenance: %v", err)
+	}
+	return p
+}
+
+func TestNewProvenance_Stable(t *testing.T) {
+	a := mustProvenance(t, provenanceConfig(), provenanceRules, "You are a reviewer.")
+	b := mustProvenance(t, provenanceConfig(), provenanceRules, "You are a reviewer.")
+	if a != b {
+		t.Errorf("expected identical inputs to produce identical provenance:\n%+v\n%+v", a, b)
+	}
+	for name, h := range map[string]string{"config": a.ConfigHash, "rules": a.RulesHash, "persona": a.PersonaHash, "provider": a.ProviderHash} {
+		if !strings.HasPrefix(h, "sha256:") || len(h) != len("sha256:")+64 {
+			t.Errorf("%s hash %q is not a sha256 digest", name, h)
+		}
+	}
+	if a.GavelVersion != "1.2.3" || a.Provider != "openrouter" || a.Model != "anthropic/claude-sonnet-4" {
+		t.Errorf("unexpected version/provider/model: %+v", a)
+	}
+}
+
+func TestNewProvenance_ChangesWithInputs(t *testing.T) {
+	base := mustProvenance(t, provenanceConfig(), provenanceRules, "You are a reviewer.")
+
+	cfg := provenanceConfig()
+	cfg.DedupWindow = 3
+	if p := mustProvenance(t, cfg, provenanceRules, "You are a reviewer."); p.ConfigHash == base.ConfigHash {
+		t.Error("expected config hash to change with config")
+	}
+
+	changed := []map[string]string{{"id": "S2068", "pattern": `passwd\s*=`}}
+	p := mustProvenance(t, provenanceConfig(), changed, "You are a reviewer.")
+	if p.RulesHash == base.RulesHash {
+		t.Error("expected rules hash to change with rules")
+	}
+	if p.ConfigHash != base.ConfigHash {
+		t.Error("expected config hash to ignore rule changes")
+	}
+
+	if p := mustProvenance(t, provenanceConfig(), provenanceRules, "You are an architect."); p.PersonaHash == base.PersonaHash {
+		t.Error("expected persona hash to change with persona prompt")
+	}
+
+	cfg = provenanceConfig()
+	cfg.Provider.OpenRouter.Model = "openai/gpt-4o"
+	p = mustProvenance(t, cfg, provenanceRules, "You are a reviewer.")
+	if p.ProviderHash == base.ProviderHash || p.ConfigHash == base.ConfigHash {
+		t.Error("expected provider and config hashes to change with model")
+	}
+}
+
+func TestNewProvenance_IgnoresCredentials(t *testing.T) {
+	base := mustProvenance(t, provenanceConfig(), provenanceRules, "")
+
+	cfg := provenanceConfig()
+	cfg.RemoteCache.Auth.Token = "secret-token"
+	cfg.Telemetry.Headers = map[string]string{"Authorization": "Bearer secret"}
+	p := mustProvenance(t, cfg, provenanceRules, "")
+
+	withHeaderKey := provenanceConfig()
+	withHeaderKey.Telemetry.Headers = map[string]string{"Authorization": ""}
+	want := mustProvenance(t, withHeaderKey, provenanceRules, "")
+
+	if p.ConfigHash != want.ConfigHash {
+		t.Error("expected credential values to be excluded from the config hash")
+	}
+	if p.ConfigHash == base.ConfigHash {
+		t.Error("expected header names to still contribute to the config hash")
+	}
+	if cfg.RemoteCache.Auth.Token != "secret-token" || cfg.Telemetry.Headers["Authorization"] != "Bearer secret" {
+		t.Error("NewProvenance must not modify the caller's config")
+	}
+}
+
+func TestAssemble_WithProvenance(t *testing.T) {
+	p := mustProvenance(t, provenanceConfig(), provenanceRules, "You are a reviewer.")
+	log := Assemble(nil, nil, "directory", "code-reviewer", WithProvenance(p))
+
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Runs []struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	raw, ok := decoded.Runs[0].Properties[PropProvenance]
+	if !ok {
+		t.Fatalf("expected %s run property, got %v", PropProvenance, decoded.Runs[0].Properties)
+	}
+	var got Provenance
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != p {
+		t.Errorf("provenance round trip mismatch:\ngot  %+v\nwant %+v", got, p)
+	}
+
+	if _, ok := Assemble(nil, nil, "directory", "code-reviewer").Runs[0].Properties[PropProvenance]; ok {
+		t.Error("expected no provenance without WithProvenance")
+	}
+}
@@ -10,13 +10,23 @@ type Log struct {
 }
 
 type Run struct {
-	Tool              Tool                   `json:"tool"`
-	Results           []Result               `json:"results"`
-	Taxonomies        []ToolComponent        `json:"taxonomies,omitempty"`
-	Invocations       []Invocation           `json:"invocations,omitempty"`
-	AutomationDetails *RunAutomationDetails  `json:"automationDetails,omitempty"`
-	BaselineGuid      string                 `json:"baselineGuid,omitempty"`
-	Properties        map[string]interface{} `json:"properties,omitempty"`
+	Tool              Tool                  `json:"tool"`
+	Results           []Result              `json:"results"`
+	Taxonomies        []ToolComponent       `json:"taxonomies,omitempty"`
+	Invocations       []Invocation          `json:"invocations,omitempty"`
+	AutomationDetails *RunAutomationDetails `json:"automationDetails,omitempty"`
+	BaselineGuid      string                `json:"baselineGuid,omitempty"`
+	// VersionControlProvenance records the revision that was analyzed.
+	VersionControlProvenance []VersionControlDetails `json:"versionControlProvenance,omitempty"`
+	Properties               map[string]interface{}  `json:"properties,omitempty"`
+}
+
+// VersionControlDetails identifies the repository revision a run analyzed,
+// per SARIF 2.1.0 §3.23.
+type VersionControlDetails struct {
+	RepositoryURI string `json:"repositoryUri,omitempty"`
+	RevisionID    string `json:"revisionId,omitempty"`
+	Branch        string `json:"branch,omitempty"`
 }
 
 // RunAutomationDetails identifies a single analysis run, per SARIF 2.1.0
@@ -102,6 +112,7 @@ type MultiformatMessage struct {
 
 type Result struct {
 	RuleID              string                 `json:"ruleId"`
+	Kind                string                 `json:"kind,omitempty"` // Unset means "fail"; see KindInformational
 	Level               string                 `json:"level"`
 	Message             Message                `json:"message"`
 	Locations           []Location             `json:"locations,omitempty"`
@@ -197,6 +208,25 @@ func NewLog(toolName, toolVersion string) *Log {
 	}
 }
 
+// StringsProperty reads the string list property key of r, which is a
+// []string on results built in this process and a []interface{} on results
+// decoded from JSON. Non-string items are dropped.
+func StringsProperty(r Result, key string) []string {
+	switch v := r.Properties[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
 // CacheMetadata represents metadata for content-addressable caching
 type CacheMetadata struct {
 	FileHash    string
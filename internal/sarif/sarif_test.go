@@ -292,3 +292,23 @@ func TestResult_OmitsFixesWhenEmpty(t *testing.T) {
 		t.Errorf("expected fixes field to be omitted when empty, got: %s", string(data))
 	}
 }
+
+func TestStringsProperty(t *testing.T) {
+	r := Result{Properties: map[string]interface{}{
+		"built":   []string{"CWE-79"},
+		"decoded": []interface{}{"CWE-89", 3, "CWE-20"},
+		"scalar":  "CWE-1",
+	}}
+	if got := StringsProperty(r, "built"); len(got) != 1 || got[0] != "CWE-79" {
+		t.Errorf("built = %v", got)
+	}
+	if got := StringsProperty(r, "decoded"); len(got) != 2 || got[0] != "CWE-89" || got[1] != "CWE-20" {
+		t.Errorf("decoded = %v, want the string items", got)
+	}
+	if got := StringsProperty(r, "scalar"); got != nil {
+		t.Errorf("scalar = %v, want nil", got)
+	}
+	if got := StringsProperty(Result{}, "missing"); got != nil {
+		t.Errorf("missing = %v, want nil", got)
+	}
+}
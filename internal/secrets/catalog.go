@@ -0,0 +1,95 @@
+package secrets
+
+import "regexp"
+
+// Detector recognizes one provider's credential format.
+type Detector struct {
+	// ID names the detector in rule IDs ("secret/<ID>") and verifier
+	// registrations.
+	ID string
+	// Description says what was found, e.g. "AWS access key ID".
+	Description string
+	// Pattern matches the credential. When it has a group named "secret",
+	// only that group is the secret; otherwise the whole match is.
+	Pattern *regexp.Regexp
+	// Confidence is the confidence reported for a match.
+	Confidence float64
+}
+
+// secret returns the byte range of the secret within a match, as returned
+// by FindAllStringSubmatchIndex.
+func (d Detector) secret(match []int) (int, int) {
+	if i := d.Pattern.SubexpIndex("secret"); i > 0 && match[2*i] >= 0 {
+		return match[2*i], match[2*i+1]
+	}
+	return match[0], match[1]
+}
+
+// catalog holds the built-in detectors. Patterns are anchored on the fixed
+// prefixes and lengths each provider documents, so matches are rarely
+// anything but credentials.
+var catalog = []Detector{
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+		Confidence:  0.9,
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Pattern:     regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})\b`),
+		Confidence:  0.9,
+	},
+	{
+		ID:          "gcp-api-key",
+		Description: "Google Cloud API key",
+		Pattern:     regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+		Confidence:  0.9,
+	},
+	{
+		ID:          "gcp-service-account-key",
+		Description: "Google Cloud service account key",
+		Pattern:     regexp.MustCompile(`"private_key_id"\s*:\s*"(?P<secret>[0-9a-f]{40})"`),
+		Confidence:  0.9,
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,255}\b`),
+		Confidence:  0.95,
+	},
+	{
+		ID:          "github-fine-grained-token",
+		Description: "GitHub fine-grained personal access token",
+		Pattern:     regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{82}\b`),
+		Confidence:  0.95,
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		Pattern:     regexp.MustCompile(`\bxox[abprs]-[0-9A-Za-z]{10,48}(?:-[0-9A-Za-z]{4,48}){0,4}\b`),
+		Confidence:  0.9,
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack incoming webhook URL",
+		Pattern:     regexp.MustCompile(`https://hooks\.slack\.com/(?:services|workflows)/(?P<secret>T[0-9A-Z]+/[0-9A-Z]+/[0-9A-Za-z]+)`),
+		Confidence:  0.9,
+	},
+	{
+		ID:          "private-key",
+		Description: "private key",
+		// The body runs to the END line, or to the end of the text when the
+		// text is an excerpt, such as a finding's snippet
+		Pattern:    regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----(?P<secret>[\s\S]*?)(?:-----END (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----|\z)`),
+		Confidence: 0.9,
+	},
+}
+
+// Catalog returns the built-in provider detectors: AWS access keys, Google
+// Cloud API and service account keys, GitHub tokens, Slack tokens and
+// webhooks, and PEM private keys.
+func Catalog() []Detector {
+	return append([]Detector(nil), catalog...)
+}
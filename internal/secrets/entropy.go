@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Default entropy thresholds, in bits per character. Random base64 strings
+// of 32 or more characters score above 4.5, while identifiers and prose
+// rarely do; hex strings top out at 4 bits, and hashes are common enough
+// that hex candidates also need a keyword on their line.
+const (
+	DefaultEntropyThreshold    = 4.5
+	DefaultHexEntropyThreshold = 3.0
+)
+
+// EntropyDetectorID is the detector ID of high-entropy string findings.
+const EntropyDetectorID = "high-entropy-string"
+
+// minCandidateLength is the shortest string checked for entropy.
+const minCandidateLength = 20
+
+var (
+	// candidatePattern finds the values of string literals and assignments:
+	// runs of base64 or hex characters after a quote, "=" or ":".
+	candidatePattern = regexp.MustCompile(`(?:["'` + "`" + `]|[=:]\s*)([A-Za-z0-9+/=_\-]{20,})`)
+	hexPattern       = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	keywordPattern   = regexp.MustCompile(`(?i)(?:secret|token|passw(?:or)?d|pwd|credential|api_?key|access_?key|private_?key|auth)`)
+	// hashLabelPattern matches a hash algorithm label just before or at the
+	// start of a candidate, as in lock file integrity fields and go.sum.
+	hashLabelPattern  = regexp.MustCompile(`(?i)(?:sha(?:1|224|256|384|512)|md5|h1)[:-]\s*$`)
+	hashPrefixPattern = regexp.MustCompile(`(?i)^(?:sha(?:1|224|256|384|512)|md5)-`)
+)
+
+// noEntropyFiles are files made of checksums, where every line would be a
+// high-entropy finding.
+var noEntropyFiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+	"Gemfile.lock":      true,
+	"composer.lock":     true,
+}
+
+// ShannonEntropy returns the Shannon entropy of s in bits per character.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// entropyFindings reports string literals and assigned values whose entropy
+// suggests a random credential. Ranges already claimed by a catalog
+// detector are skipped.
+func (s *Scanner) entropyFindings(filePath, content string, claimed [][2]int) []Finding {
+	if filePath != "" && noEntropyFiles[path.Base(strings.ReplaceAll(filePath, "\\", "/"))] {
+		return nil
+	}
+
+	var findings []Finding
+	for _, m := range candidatePattern.FindAllStringSubmatchIndex(content, -1) {
+		start, end := m[2], m[3]
+		value := content[start:end]
+		if overlaps(claimed, start, end) {
+			continue
+		}
+		lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+		lineEnd := strings.IndexByte(content[end:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += end
+		}
+		line := content[lineStart:lineEnd]
+		if hashLabelPattern.MatchString(content[lineStart:start]) || hashPrefixPattern.MatchString(value) {
+			continue
+		}
+
+		keyword := keywordPattern.MatchString(line)
+		entropy := ShannonEntropy(value)
+		confidence := 0.0
+		if hexPattern.MatchString(value) {
+			if keyword && entropy >= s.hexEntropyThreshold {
+				confidence = 0.6
+			}
+		} else if entropy >= s.entropyThreshold {
+			confidence = 0.5
+			if keyword {
+				confidence = 0.75
+			}
+		}
+		if confidence == 0 {
+			continue
+		}
+
+		findings = append(findings, s.newFinding(content, start, end, Detector{
+			ID:          EntropyDetectorID,
+			Description: "high-entropy string",
+			Confidence:  confidence,
+		}, entropy))
+	}
+	return findings
+}
+
+func overlaps(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
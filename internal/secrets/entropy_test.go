@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"abab", 1},
+		{"abcd", 2},
+	}
+	for _, tt := range tests {
+		if got := ShannonEntropy(tt.in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ShannonEntropy(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScan_Entropy(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		content    string
+		confidence float64 // 0 means no finding
+	}{
+		{"random literal with keyword", "app.go", `apiKey := "q8Z3vK1xT9mR4wYb7LpN2sHd6FjC0eGu"`, 0.75},
+		{"random literal without keyword", "app.go", `x := "q8Z3vK1xT9mR4wYb7LpN2sHd6FjC0eGu"`, 0.5},
+		{"env assignment", ".env", "SESSION_SECRET=q8Z3vK1xT9mR4wYb7LpN2sHd6FjC0eGu", 0.75},
+		{"hex with keyword", "app.py", `secret = "9f86d081884c7d659a2feaa0c55ad015"`, 0.6},
+		{"hex without keyword", "app.py", `digest = "9f86d081884c7d659a2feaa0c55ad015"`, 0},
+		{"identifier", "app.go", `name := "TestTieredAnalyzerSkipsDisabledRules"`, 0},
+		{"short", "app.go", `token := "q8Z3vK1xT9mR4wYb"`, 0},
+		{"hash label", "package.json", `"integrity": "sha512-q8Z3vK1xT9mR4wYb7LpN2sHd6FjC0eGu"`, 0},
+		{"go.sum", "go.sum", "example.com/m v1.0.0 h1:q8Z3vK1xT9mR4wYb7LpN2sHd6FjC0eGu=", 0},
+	}
+	s := NewScanner()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := s.Scan(context.Background(), tt.path, tt.content)
+			if tt.confidence == 0 {
+				if len(findings) != 0 {
+					t.Fatalf("got %v, want none", detectorIDs(findings))
+				}
+				return
+			}
+			if len(findings) != 1 || findings[0].DetectorID != EntropyDetectorID {
+				t.Fatalf("got %v, want one %s finding", detectorIDs(findings), EntropyDetectorID)
+			}
+			if findings[0].Confidence != tt.confidence {
+				t.Errorf("confidence = %v, want %v", findings[0].Confidence, tt.confidence)
+			}
+		})
+	}
+}
+
+func TestScan_EntropySkipsCatalogMatches(t *testing.T) {
+	findings := NewScanner().Scan(context.Background(), "", `token = "`+testGitHub+`"`)
+	if len(findings) != 1 || findings[0].DetectorID != "github-token" {
+		t.Errorf("got %v, want only github-token", detectorIDs(findings))
+	}
+}
+
+func TestScan_EntropyDisabled(t *testing.T) {
+	findings := NewScanner(WithEntropyThreshold(0)).Scan(context.Background(), "", `apiKey := "q8Z3vK1xT9mR4wYb7LpN2sHd6FjC0eGu"`)
+	if len(findings) != 0 {
+		t.Errorf("got %v, want none", detectorIDs(findings))
+	}
+}
@@ -0,0 +1,220 @@
+// Package secrets finds credentials in source text: provider tokens with
+// documented formats (AWS, Google Cloud, GitHub, Slack, private keys) and
+// string literals random enough to be keys. Findings can be checked against
+// the issuing service by registered verifiers, and Redact masks secrets in
+// text shown to users.
+package secrets
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Finding is one secret found in a text.
+type Finding struct {
+	DetectorID  string
+	Description string
+	// Secret is the credential itself. It must not be shown unmasked; use
+	// Mask or Redact.
+	Secret string
+	// Start and End are the byte offsets of Secret in the scanned text.
+	Start, End int
+	// Line and Column are the 1-indexed position of Start.
+	Line, Column int
+	// Entropy is Secret's Shannon entropy in bits per character.
+	Entropy      float64
+	Confidence   float64
+	Verification Verification
+}
+
+// Scanner finds secrets with the catalog detectors and entropy analysis.
+// It is safe for concurrent use.
+type Scanner struct {
+	detectors           []Detector
+	entropyThreshold    float64
+	hexEntropyThreshold float64
+	verifiers           map[string]Verifier
+	verifyTimeout       time.Duration
+
+	verified sync.Map // secret -> Verification, so each secret is checked once
+}
+
+// Option configures a Scanner.
+type Option func(*Scanner)
+
+// WithDetectors replaces the catalog detectors.
+func WithDetectors(detectors []Detector) Option {
+	return func(s *Scanner) {
+		s.detectors = detectors
+	}
+}
+
+// WithEntropyThreshold sets the entropy, in bits per character, above which
+// a base64-like string is reported. Zero or less disables entropy analysis.
+func WithEntropyThreshold(bits float64) Option {
+	return func(s *Scanner) {
+		s.entropyThreshold = bits
+	}
+}
+
+// WithVerifier verifies findings of the detector with the given ID.
+func WithVerifier(detectorID string, v Verifier) Option {
+	return func(s *Scanner) {
+		if s.verifiers == nil {
+			s.verifiers = make(map[string]Verifier)
+		}
+		s.verifiers[detectorID] = v
+	}
+}
+
+// WithVerifyTimeout bounds each verifier call. The default is 5 seconds.
+func WithVerifyTimeout(d time.Duration) Option {
+	return func(s *Scanner) {
+		s.verifyTimeout = d
+	}
+}
+
+// NewScanner creates a scanner with the catalog detectors and the default
+// entropy thresholds, and no verifiers.
+func NewScanner(opts ...Option) *Scanner {
+	s := &Scanner{
+		detectors:           Catalog(),
+		entropyThreshold:    DefaultEntropyThreshold,
+		hexEntropyThreshold: DefaultHexEntropyThreshold,
+		verifyTimeout:       5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Scan returns the secrets in content, ordered by position. filePath, when
+// set, lets Scan skip entropy analysis of checksum files such as go.sum;
+// catalog detectors still run. Findings whose detector has a verifier are
+// verified before Scan returns.
+func (s *Scanner) Scan(ctx context.Context, filePath, content string) []Finding {
+	findings := s.find(filePath, content)
+	for i := range findings {
+		findings[i].Verification = s.verify(ctx, findings[i])
+	}
+	return findings
+}
+
+// find implements Scan without verification.
+func (s *Scanner) find(filePath, content string) []Finding {
+	var findings []Finding
+	var claimed [][2]int
+	for _, d := range s.detectors {
+		for _, m := range d.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := d.secret(m)
+			if strings.TrimSpace(content[start:end]) == "" {
+				continue
+			}
+			claimed = append(claimed, [2]int{m[0], m[1]})
+			findings = append(findings, s.newFinding(content, start, end, d, ShannonEntropy(content[start:end])))
+		}
+	}
+	if s.entropyThreshold > 0 {
+		findings = append(findings, s.entropyFindings(filePath, content, claimed)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+func (s *Scanner) newFinding(content string, start, end int, d Detector, entropy float64) Finding {
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	return Finding{
+		DetectorID:  d.ID,
+		Description: d.Description,
+		Secret:      content[start:end],
+		Start:       start,
+		End:         end,
+		Line:        strings.Count(content[:start], "\n") + 1,
+		Column:      start - lineStart + 1,
+		Entropy:     entropy,
+		Confidence:  d.Confidence,
+	}
+}
+
+// verify runs f's verifier, if any, remembering the outcome per secret.
+func (s *Scanner) verify(ctx context.Context, f Finding) Verification {
+	v, ok := s.verifiers[f.DetectorID]
+	if !ok {
+		return Unverified
+	}
+	if prev, ok := s.verified.Load(f.Secret); ok {
+		return prev.(Verification)
+	}
+	if s.verifyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.verifyTimeout)
+		defer cancel()
+	}
+	live, err := v.Verify(ctx, f.Secret)
+	if err != nil {
+		return Unverified // not remembered, so a later scan can retry
+	}
+	result := Revoked
+	if live {
+		result = Live
+	}
+	s.verified.Store(f.Secret, result)
+	return result
+}
+
+// Redact returns text with every secret s finds masked. Verifiers are not
+// called.
+func (s *Scanner) Redact(text string) string {
+	return RedactSecrets(text, s.find("", text))
+}
+
+// RedactSecrets returns text with the secrets of findings, which must come
+// from scanning text, masked.
+func RedactSecrets(text string, findings []Finding) string {
+	if len(findings) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		if f.Start < last || f.End > len(text) {
+			continue
+		}
+		b.WriteString(text[last:f.Start])
+		b.WriteString(Mask(f.Secret))
+		last = f.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Mask hides a secret, keeping its first four characters when it is long
+// enough that they give nothing away, so readers can still tell which
+// credential a finding refers to. Each line is masked separately, so a
+// multi-line secret such as a private key spans the same lines; at most 16
+// asterisks stand in for a line, keeping long keys from flooding output.
+func Mask(secret string) string {
+	lines := strings.Split(secret, "\n")
+	for i, line := range lines {
+		keep := 0
+		if len(line) >= 16 && i == 0 {
+			keep = 4
+		}
+		lines[i] = line[:keep] + strings.Repeat("*", min(len(line)-keep, maxMaskLength))
+	}
+	return strings.Join(lines, "\n")
+}
+
+const maxMaskLength = 16
+
+var defaultScanner = NewScanner()
+
+// Redact masks the secrets the catalog detectors and default entropy
+// analysis find in text.
+func Redact(text string) string {
+	return defaultScanner.Redact(text)
+}
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Verifier checks with the issuing service whether a secret is live. It
+// returns false for a secret the service rejects, and an error when it
+// cannot tell, such as on a network failure or rate limit.
+type Verifier interface {
+	Verify(ctx context.Context, secret string) (bool, error)
+}
+
+// VerifierFunc adapts a function to the Verifier interface.
+type VerifierFunc func(ctx context.Context, secret string) (bool, error)
+
+// Verify calls f.
+func (f VerifierFunc) Verify(ctx context.Context, secret string) (bool, error) {
+	return f(ctx, secret)
+}
+
+// Verification is the outcome of verifying a finding.
+type Verification string
+
+const (
+	// Unverified means no verifier ran or it could not reach a verdict.
+	Unverified Verification = ""
+	// Live means the issuing service accepted the secret.
+	Live Verification = "live"
+	// Revoked means the issuing service rejected the secret.
+	Revoked Verification = "revoked"
+)
+
+// GitHubVerifier verifies GitHub tokens by requesting the authenticated
+// user. baseURL is the API root; empty means https://api.github.com.
+func GitHubVerifier(client *http.Client, baseURL string) Verifier {
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+	return VerifierFunc(func(ctx context.Context, secret string) (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/user", nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Authorization", "Bearer "+secret)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		resp, err := httpClient(client).Do(req)
+		if err != nil {
+			return false, fmt.Errorf("verifying GitHub token: %w", err)
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return true, nil
+		case http.StatusUnauthorized:
+			return false, nil
+		}
+		return false, fmt.Errorf("verifying GitHub token: unexpected status %s", resp.Status)
+	})
+}
+
+// SlackVerifier verifies Slack tokens with the auth.test method. baseURL is
+// the API root; empty means https://slack.com/api.
+func SlackVerifier(client *http.Client, baseURL string) Verifier {
+	if baseURL == "" {
+		baseURL = "https://slack.com/api"
+	}
+	return VerifierFunc(func(ctx context.Context, secret string) (bool, error) {
+		form := url.Values{"token": {secret}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/auth.test", strings.NewReader(form.Encode()))
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := httpClient(client).Do(req)
+		if err != nil {
+			return false, fmt.Errorf("verifying Slack token: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return false, fmt.Errorf("verifying Slack token: unexpected status %s", resp.Status)
+		}
+		var body struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return false, fmt.Errorf("verifying Slack token: %w", err)
+		}
+		switch {
+		case body.OK:
+			return true, nil
+		case body.Error == "invalid_auth" || body.Error == "token_revoked" || body.Error == "account_inactive" || body.Error == "token_expired":
+			return false, nil
+		}
+		return false, fmt.Errorf("verifying Slack token: %s", body.Error)
+	})
+}
+
+// DefaultVerifiers returns verifiers for the catalog detectors whose
+// services offer a side-effect-free check: GitHub and Slack tokens.
+func DefaultVerifiers(client *http.Client) map[string]Verifier {
+	github := GitHubVerifier(client, "")
+	return map[string]Verifier{
+		"github-token":              github,
+		"github-fine-grained-token": github,
+		"slack-token":               SlackVerifier(client, ""),
+	}
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubVerifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" {
+			t.Errorf("path = %s, want /user", r.URL.Path)
+		}
+		switch r.Header.Get("Authorization") {
+		case "Bearer live":
+			w.WriteHeader(http.StatusOK)
+		case "Bearer revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	v := GitHubVerifier(srv.Client(), srv.URL)
+	if ok, err := v.Verify(context.Background(), "live"); err != nil || !ok {
+		t.Errorf("live token: got %v, %v", ok, err)
+	}
+	if ok, err := v.Verify(context.Background(), "revoked"); err != nil || ok {
+		t.Errorf("revoked token: got %v, %v", ok, err)
+	}
+	if _, err := v.Verify(context.Background(), "limited"); err == nil {
+		t.Error("expected an error for an unexpected status")
+	}
+}
+
+func TestSlackVerifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth.test" {
+			t.Errorf("path = %s, want /auth.test", r.URL.Path)
+		}
+		switch r.FormValue("token") {
+		case "live":
+			w.Write([]byte(`{"ok":true}`))
+		case "revoked":
+			w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error":"ratelimited"}`))
+		}
+	}))
+	defer srv.Close()
+
+	v := SlackVerifier(srv.Client(), srv.URL)
+	if ok, err := v.Verify(context.Background(), "live"); err != nil || !ok {
+		t.Errorf("live token: got %v, %v", ok, err)
+	}
+	if ok, err := v.Verify(context.Background(), "revoked"); err != nil || ok {
+		t.Errorf("revoked token: got %v, %v", ok, err)
+	}
+	if _, err := v.Verify(context.Background(), "other"); err == nil {
+		t.Error("expected an error for an unknown auth.test error")
+	}
+}
@@ -2,9 +2,11 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -106,7 +108,15 @@ func (h *Handlers) HandleAnalyze(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
-// HandleAnalyzeStream handles POST /v1/analyze/stream (SSE).
+// sseKeepAlive is how often HandleAnalyzeStream writes a comment while no
+// event is ready.
+var sseKeepAlive = 15 * time.Second
+
+// HandleAnalyzeStream handles POST /v1/analyze/stream (SSE). A "result" event
+// carries one file's findings from one tier as soon as the tier finishes the
+// file, so instant-tier findings arrive before comprehensive analysis ends.
+// A "tier" event follows each tier with its aggregated findings, and the
+// stream ends with "complete" or "error".
 func (h *Handlers) HandleAnalyzeStream(w http.ResponseWriter, r *http.Request) {
 	if !h.acquireSlot(w) {
 		return
@@ -124,16 +134,37 @@ func (h *Handlers) HandleAnalyzeStream(w http.ResponseWriter, r *http.Request) {
 	sse := NewSSEWriter(w)
 	sse.SetHeaders()
 
-	tierCh, resultCh, errCh := h.analyze.AnalyzeStream(r.Context(), service.AnalyzeRequest{
+	// Cancel analysis when the client goes away or a write fails
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	eventCh, resultCh, errCh := h.analyze.AnalyzeStream(ctx, service.AnalyzeRequest{
 		Artifacts:  toArtifacts(req.Artifacts),
 		Config:     req.Config,
 		Rules:      req.Rules,
 		BaselineID: req.BaselineID,
 	})
 
-	// Stream tier results
-	for tr := range tierCh {
-		if err := sse.WriteEvent("tier", tr); err != nil {
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	// Stream per-file and per-tier results
+	for done := false; !done; {
+		var err error
+		select {
+		case ev, ok := <-eventCh:
+			switch {
+			case !ok:
+				done = true
+			case ev.File != nil:
+				err = sse.WriteEvent("result", ev.File)
+			case ev.Tier != nil:
+				err = sse.WriteEvent("tier", ev.Tier)
+			}
+		case <-keepAlive.C:
+			err = sse.WriteComment("keep-alive")
+		}
+		if err != nil {
 			slog.Error("SSE write failed", "error", err)
 			return
 		}
@@ -3,22 +3,72 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+
+	"github.com/chris-regnier/gavel/internal/tenant"
 )
 
 type contextKey string
 
-const tenantKey contextKey = "tenant"
+const subjectKey contextKey = "subject"
+
+// ErrUnknownToken is returned by a TokenVerifier for a token it does not
+// recognize, as opposed to one it recognizes but rejects.
+var ErrUnknownToken = errors.New("unknown token")
+
+// Identity is who a verified token was issued to.
+type Identity struct {
+	// Tenant scopes the caller's results, usage and quotas.
+	Tenant string
+	// Subject identifies the token itself, for per-token rate limits.
+	Subject string
+}
+
+// TokenVerifier verifies a bearer token.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (Identity, error)
+}
+
+// StaticKeys verifies API keys against a key-to-tenant map.
+type StaticKeys map[string]string
+
+// VerifyToken returns the tenant of a known key. The subject is derived from
+// a hash of the key, so it can be logged without revealing the key.
+func (k StaticKeys) VerifyToken(_ context.Context, token string) (Identity, error) {
+	t, ok := k[token]
+	if !ok {
+		return Identity{}, ErrUnknownToken
+	}
+	sum := sha256.Sum256([]byte(token))
+	return Identity{Tenant: t, Subject: "key:" + hex.EncodeToString(sum[:6])}, nil
+}
 
 // TenantFromContext extracts the tenant ID from the request context.
 func TenantFromContext(ctx context.Context) string {
-	v, _ := ctx.Value(tenantKey).(string)
+	return tenant.FromContext(ctx)
+}
+
+// SubjectFromContext extracts the authenticated token's subject from the
+// request context.
+func SubjectFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(subjectKey).(string)
 	return v
 }
 
 // Auth returns middleware that validates Bearer tokens against a key-to-tenant map.
 func Auth(keys map[string]string) func(http.Handler) http.Handler {
+	return Authenticate(StaticKeys(keys))
+}
+
+// Authenticate returns middleware that accepts a Bearer token when any of
+// verifiers does, in order, and records the token's tenant and subject in
+// the request context.
+func Authenticate(verifiers ...TokenVerifier) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
@@ -33,14 +83,21 @@ func Auth(keys map[string]string) func(http.Handler) http.Handler {
 				return
 			}
 
-			tenant, ok := keys[token]
-			if !ok {
-				http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+			for _, v := range verifiers {
+				id, err := v.VerifyToken(r.Context(), token)
+				if errors.Is(err, ErrUnknownToken) {
+					continue
+				}
+				if err != nil {
+					slog.Debug("rejected token", "error", err, "request_id", RequestIDFromContext(r.Context()))
+					break
+				}
+				ctx := tenant.NewContext(r.Context(), id.Tenant)
+				ctx = context.WithValue(ctx, subjectKey, id.Subject)
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
-
-			ctx := context.WithValue(r.Context(), tenantKey, tenant)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			http.Error(w, `{"error":"invalid credentials"}`, http.StatusUnauthorized)
 		})
 	}
 }
@@ -0,0 +1,169 @@
+// internal/server/middleware/limits.go
+package middleware
+
+import (
+	"hash/fnv"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/metrics"
+	"github.com/chris-regnier/gavel/internal/tenant"
+)
+
+// LimitsFunc returns the limits that apply to a tenant.
+type LimitsFunc func(tenant string) config.TenantLimits
+
+// now is the clock used for rate limits and quotas, replaced in tests.
+var now = time.Now
+
+// bucket is a token bucket refilled at a fixed rate.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // When the bucket will have refilled to its burst
+}
+
+const (
+	// rateLimitShards spreads buckets over independently locked maps so
+	// concurrent requests rarely wait on each other.
+	rateLimitShards = 32
+	// sweepInterval is how often a shard drops buckets that have refilled;
+	// a full bucket behaves exactly like a new one, so nothing is lost.
+	sweepInterval = time.Minute
+)
+
+type rateShard struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// rateLimiter holds a token bucket per key.
+type rateLimiter struct {
+	shards [rateLimitShards]rateShard
+}
+
+func newRateLimiter() *rateLimiter {
+	l := &rateLimiter{}
+	for i := range l.shards {
+		l.shards[i].buckets = make(map[string]*bucket)
+	}
+	return l
+}
+
+// allow takes a token from key's bucket at time t, returning false and the
+// seconds until one is available when the bucket is empty.
+func (l *rateLimiter) allow(key string, burst, rate float64, t time.Time) (bool, float64) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	shard := &l.shards[h.Sum32()%rateLimitShards]
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if t.Sub(shard.swept) >= sweepInterval {
+		for k, b := range shard.buckets {
+			if !t.Before(b.full) {
+				delete(shard.buckets, k)
+			}
+		}
+		shard.swept = t
+	}
+
+	b, ok := shard.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: t}
+		shard.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+t.Sub(b.last).Seconds()*rate)
+	b.last = t
+	allowed := b.tokens >= 1
+	var wait float64
+	if allowed {
+		b.tokens--
+	} else {
+		wait = (1 - b.tokens) / rate
+	}
+	b.full = t.Add(time.Duration((burst - b.tokens) / rate * float64(time.Second)))
+	return allowed, wait
+}
+
+// len returns the number of buckets held.
+func (l *rateLimiter) len() int {
+	n := 0
+	for i := range l.shards {
+		l.shards[i].mu.Lock()
+		n += len(l.shards[i].buckets)
+		l.shards[i].mu.Unlock()
+	}
+	return n
+}
+
+// RateLimit returns middleware that allows each token the tenant's
+// requests_per_minute, with bursts of up to burst requests, answering 429
+// with a Retry-After header beyond that. Requests are keyed by the
+// authenticated subject, or the client address without authentication.
+// Buckets that have refilled are dropped, so idle clients hold no memory.
+func RateLimit(limits LimitsFunc) func(http.Handler) http.Handler {
+	return rateLimit(newRateLimiter(), limits)
+}
+
+func rateLimit(limiter *rateLimiter, limits LimitsFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := limits(tenant.FromContext(r.Context()))
+			if l.RequestsPerMinute <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			burst := float64(l.Burst)
+			if burst <= 0 {
+				burst = float64(l.RequestsPerMinute)
+			}
+			rate := float64(l.RequestsPerMinute) / 60 // per second
+
+			key := SubjectFromContext(r.Context())
+			if key == "" {
+				key, _, _ = net.SplitHostPort(r.RemoteAddr)
+			}
+
+			if allowed, wait := limiter.allow(key, burst, rate, now()); !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait))))
+				http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Quota returns middleware that rejects requests from a tenant that has
+// used its daily_tokens or daily_cost_usd for the current UTC day, as
+// recorded by collector, answering 429 with a Retry-After header of the
+// time until midnight UTC. A request admitted under quota runs to
+// completion, so a tenant may overrun its quota by one request's usage.
+func Quota(collector *metrics.Collector, limits LimitsFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := tenant.FromContext(r.Context())
+			l := limits(id)
+			if collector == nil || (l.DailyTokens <= 0 && l.DailyCostUSD <= 0) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			t := now()
+			u := collector.TenantUsage(id, t)
+			if (l.DailyTokens > 0 && u.Tokens() >= l.DailyTokens) || (l.DailyCostUSD > 0 && u.CostUSD >= l.DailyCostUSD) {
+				midnight := t.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(midnight.Sub(t).Seconds()))))
+				http.Error(w, `{"error":"daily quota exceeded"}`, http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// internal/server/middleware/limits_test.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/metrics"
+	"github.com/chris-regnier/gavel/internal/tenant"
+)
+
+// fakeClock sets now for the duration of a test.
+func fakeClock(t *testing.T, start time.Time) *time.Time {
+	t.Helper()
+	clock := start
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+	return &clock
+}
+
+func requestAs(tenantID, subject string) *http.Request {
+	req := httptest.NewRequest("POST", "/v1/analyze", nil)
+	ctx := tenant.NewContext(req.Context(), tenantID)
+	ctx = context.WithValue(ctx, subjectKey, subject)
+	return req.WithContext(ctx)
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := fakeClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	auth := config.ServerAuthConfig{
+		Limits:  config.TenantLimits{RequestsPerMinute: 60, Burst: 2},
+		Tenants: map[string]config.TenantLimits{"free": {RequestsPerMinute: 1, Burst: 1}},
+	}
+	handler := RateLimit(auth.LimitsFor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	status := func(tenantID, subject string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, requestAs(tenantID, subject))
+		return w.Code
+	}
+
+	// A burst of two, then one request per second
+	for i, want := range []int{200, 200, 429} {
+		if got := status("acme", "key:a"); got != want {
+			t.Errorf("request %d: got %d, want %d", i, got, want)
+		}
+	}
+	if got := status("acme", "key:b"); got != 200 {
+		t.Errorf("expected another token of the tenant to have its own bucket, got %d", got)
+	}
+	*clock = clock.Add(time.Second)
+	if got := status("acme", "key:a"); got != 200 {
+		t.Errorf("expected a request after a second to be allowed, got %d", got)
+	}
+
+	// The per-tenant override allows one request a minute
+	if got := status("free", "key:f"); got != 200 {
+		t.Errorf("expected the first request to be allowed, got %d", got)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, requestAs("free", "key:f"))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected 429 with Retry-After 60, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimit_DropsRefilledBuckets(t *testing.T) {
+	clock := fakeClock(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	auth := config.ServerAuthConfig{Limits: config.TenantLimits{RequestsPerMinute: 60, Burst: 10}}
+	limiter := newRateLimiter()
+	handler := rateLimit(limiter, auth.LimitsFor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 100; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), requestAs("acme", fmt.Sprintf("key:%d", i)))
+	}
+	if n := limiter.len(); n != 100 {
+		t.Fatalf("expected a bucket per client, got %d", n)
+	}
+
+	// Each bucket refills within a second, so once the sweep interval has
+	// passed, traffic to a shard clears its idle buckets
+	*clock = clock.Add(sweepInterval)
+	for i := 0; i < 100; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), requestAs("acme", fmt.Sprintf("new:%d", i)))
+	}
+	if n := limiter.len(); n != 100 {
+		t.Errorf("expected only the new clients' buckets to remain, got %d", n)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	day := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	fakeClock(t, day)
+	collector := metrics.NewCollector()
+	auth := config.ServerAuthConfig{
+		Limits:  config.TenantLimits{DailyTokens: 1000},
+		Tenants: map[string]config.TenantLimits{"pro": {DailyCostUSD: 1}},
+	}
+	handler := Quota(collector, auth.LimitsFor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(tenantID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, requestAs(tenantID, ""))
+		return w
+	}
+
+	if w := serve("acme"); w.Code != 200 {
+		t.Fatalf("expected 200 under quota, got %d", w.Code)
+	}
+	collector.Record(metrics.AnalysisEvent{Timestamp: day, Tenant: "acme", TokensIn: 900, TokensOut: 100})
+	w := serve("acme")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3600" {
+		t.Errorf("expected 429 until midnight, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// pro has a cost quota and inherits the token quota
+	collector.Record(metrics.AnalysisEvent{Timestamp: day, Tenant: "pro", TokensIn: 10, CostUSD: 0.5})
+	if w := serve("pro"); w.Code != 200 {
+		t.Errorf("expected pro under quota, got %d", w.Code)
+	}
+	collector.Record(metrics.AnalysisEvent{Timestamp: day, Tenant: "pro", TokensIn: 10, CostUSD: 0.5})
+	if w := serve("pro"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected pro over its cost quota, got %d", w.Code)
+	}
+}
@@ -0,0 +1,358 @@
+// internal/server/middleware/oidc.go
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+// clockSkew is how far token times may be off from the server's clock.
+const clockSkew = time.Minute
+
+// jwksRefreshInterval is how often an unknown key ID may trigger a refetch
+// of the issuer's keys, so forged key IDs cannot hammer the provider.
+const jwksRefreshInterval = time.Minute
+
+// OIDCVerifier verifies JWTs issued by an OpenID Connect provider: the
+// signature against the provider's published keys (RS*, PS* and ES*
+// algorithms), the issuer, audience, expiry and not-before claims. The
+// tenant is read from a configurable claim.
+type OIDCVerifier struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu       sync.Mutex
+	jwksURL  string
+	keys     map[string]crypto.PublicKey
+	fetched  time.Time
+	fetching chan struct{} // Closed when the fetch in flight completes
+}
+
+// NewOIDCVerifier returns a verifier for tokens issued by cfg.Issuer. Keys
+// are fetched on first use and again when a token names an unknown key.
+func NewOIDCVerifier(cfg config.OIDCConfig) *OIDCVerifier {
+	return &OIDCVerifier{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+		jwksURL: cfg.JWKSURL,
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// VerifyToken returns ErrUnknownToken for tokens that are not JWTs, so
+// static API keys can be tried alongside it.
+func (v *OIDCVerifier) VerifyToken(ctx context.Context, token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, ErrUnknownToken
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, ErrUnknownToken
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, fmt.Errorf("decoding claims: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return Identity{}, err
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return Identity{}, err
+	}
+
+	claim := v.cfg.TenantClaimName()
+	t, _ := claims[claim].(string)
+	if t == "" {
+		return Identity{}, fmt.Errorf("token has no %q claim", claim)
+	}
+	sub, _ := claims["sub"].(string)
+	return Identity{Tenant: t, Subject: "oidc:" + sub}, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkClaims validates the registered claims.
+func (v *OIDCVerifier) checkClaims(claims map[string]interface{}) error {
+	if iss, _ := claims["iss"].(string); iss != v.cfg.Issuer {
+		return fmt.Errorf("unexpected issuer %q", iss)
+	}
+	if !hasAudience(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("token is not for audience %q", v.cfg.Audience)
+	}
+	now := v.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return errors.New("token has expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token is not valid yet")
+	}
+	return nil
+}
+
+func hasAudience(aud interface{}, want string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == want
+	case []interface{}:
+		for _, s := range a {
+			if s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifySignature checks sig over signed with key using alg. Symmetric and
+// "none" algorithms are rejected, as the provider's public keys must sign.
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	}
+	if hash == 0 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(k, hash, digest, sig)
+		case "PS":
+			return rsa.VerifyPSS(k, hash, digest, sig, nil)
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] == "ES" {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			if len(sig) != 2*size {
+				return errors.New("invalid ECDSA signature length")
+			}
+			r := new(big.Int).SetBytes(sig[:size])
+			s := new(big.Int).SetBytes(sig[size:])
+			if !ecdsa.Verify(k, digest, r, s) {
+				return errors.New("invalid signature")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("algorithm %q does not match the signing key", alg)
+}
+
+// key returns the signing key named kid, refetching the issuer's keys when
+// it is unknown. A token without a kid may use the only key published. The
+// fetch runs without holding the lock, and concurrent callers wait for the
+// one in flight rather than starting their own.
+func (v *OIDCVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	for {
+		v.mu.Lock()
+		if k := v.lookup(kid); k != nil {
+			v.mu.Unlock()
+			return k, nil
+		}
+		if !v.fetched.IsZero() && v.now().Sub(v.fetched) < jwksRefreshInterval {
+			v.mu.Unlock()
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if wait := v.fetching; wait != nil {
+			v.mu.Unlock()
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		v.fetching = done
+		jwksURL := v.jwksURL
+		v.mu.Unlock()
+
+		keys, jwksURL, err := v.fetchKeys(ctx, jwksURL)
+
+		v.mu.Lock()
+		v.fetching, v.fetched = nil, v.now()
+		close(done)
+		if err != nil {
+			v.mu.Unlock()
+			return nil, fmt.Errorf("fetching signing keys: %w", err)
+		}
+		v.keys, v.jwksURL = keys, jwksURL
+		k := v.lookup(kid)
+		v.mu.Unlock()
+		if k == nil {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return k, nil
+	}
+}
+
+func (v *OIDCVerifier) lookup(kid string) crypto.PublicKey {
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k
+		}
+	}
+	return v.keys[kid]
+}
+
+// fetchKeys reads the issuer's JSON Web Key Set from jwksURL, discovering
+// the URL from the OpenID configuration when it is empty. It returns the
+// keys and the URL they were read from.
+func (v *OIDCVerifier) fetchKeys(ctx context.Context, jwksURL string) (map[string]crypto.PublicKey, string, error) {
+	if jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, strings.TrimSuffix(v.cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, "", err
+		}
+		if discovery.JWKSURI == "" {
+			return nil, "", errors.New("OpenID configuration has no jwks_uri")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, jwksURL, &set); err != nil {
+		return nil, "", err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+	return keys, jwksURL, nil
+}
+
+func (v *OIDCVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jwk is one key of a JSON Web Key Set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	num := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("invalid key parameter")
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := num(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := num(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := num(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := num(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
@@ -0,0 +1,227 @@
+// internal/server/middleware/oidc_test.go
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/config"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// testIssuer serves OpenID discovery and a JWKS holding an RSA and an EC key.
+type testIssuer struct {
+	*httptest.Server
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	jwksHits atomic.Int32
+	// block, when set, holds key requests until it is closed
+	block chan struct{}
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": iss.URL, "jwks_uri": iss.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		iss.jwksHits.Add(1)
+		if iss.block != nil {
+			<-iss.block
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+			{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+		}})
+	})
+	iss.Server = httptest.NewServer(mux)
+	t.Cleanup(iss.Close)
+	return iss
+}
+
+// sign returns a JWT for claims signed with the key kid names.
+func (iss *testIssuer) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	var err error
+	switch alg {
+	case "RS256":
+		sig, err = rsa.SignPKCS1v15(rand.Reader, iss.rsaKey, crypto.SHA256, digest[:])
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, iss.ecKey, digest[:])
+		if err == nil {
+			sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(sig)
+}
+
+func TestOIDCVerifier(t *testing.T) {
+	iss := newTestIssuer(t)
+	now := time.Now()
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": iss.URL, "aud": []string{"gavel", "other"}, "sub": "user-1", "org": "acme",
+			"exp": now.Add(time.Hour).Unix(), "iat": now.Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	v := NewOIDCVerifier(config.OIDCConfig{Issuer: iss.URL, Audience: "gavel", TenantClaim: "org"})
+	ctx := context.Background()
+
+	for _, alg := range []struct{ alg, kid string }{{"RS256", "rsa-1"}, {"ES256", "ec-1"}} {
+		id, err := v.VerifyToken(ctx, iss.sign(t, alg.alg, alg.kid, claims(nil)))
+		if err != nil {
+			t.Fatalf("%s: %v", alg.alg, err)
+		}
+		if id.Tenant != "acme" || id.Subject != "oidc:user-1" {
+			t.Errorf("%s: unexpected identity %+v", alg.alg, id)
+		}
+	}
+	if hits := iss.jwksHits.Load(); hits != 1 {
+		t.Errorf("expected the keys to be fetched once, got %d", hits)
+	}
+
+	for name, token := range map[string]string{
+		"expired":        iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})),
+		"no expiry":      iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"exp": nil})),
+		"not yet valid":  iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})),
+		"wrong issuer":   iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+		"wrong audience": iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"aud": "other"})),
+		"other client":   iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"aud": []string{"other-client"}})),
+		"no audience":    iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"aud": nil})),
+		"no tenant":      iss.sign(t, "RS256", "rsa-1", claims(map[string]interface{}{"org": nil})),
+		"wrong key":      iss.sign(t, "RS256", "ec-1", claims(nil)),
+		"encryption key": iss.sign(t, "RS256", "enc-1", claims(nil)),
+		"tampered":       strings.Replace(iss.sign(t, "RS256", "rsa-1", claims(nil)), ".", "."+b64([]byte(`{"org":"evil"}`))+"x", 1),
+		"alg none":       b64([]byte(`{"alg":"none","kid":"rsa-1"}`)) + "." + b64([]byte(`{"org":"acme"}`)) + ".",
+	} {
+		if _, err := v.VerifyToken(ctx, token); err == nil || errors.Is(err, ErrUnknownToken) {
+			t.Errorf("%s: expected the token to be rejected, got %v", name, err)
+		}
+	}
+
+	if _, err := v.VerifyToken(ctx, "static-api-key"); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("expected a non-JWT to be unknown, got %v", err)
+	}
+}
+
+func TestOIDCVerifier_SlowKeyFetch(t *testing.T) {
+	iss := newTestIssuer(t)
+	v := NewOIDCVerifier(config.OIDCConfig{Issuer: iss.URL, Audience: "gavel"})
+	ctx := context.Background()
+	token := func(kid string) string {
+		return iss.sign(t, "RS256", kid, map[string]interface{}{"iss": iss.URL, "aud": "gavel", "sub": "u", "exp": time.Now().Add(time.Hour).Unix()})
+	}
+	if _, err := v.VerifyToken(ctx, token("rsa-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown key IDs after the refresh interval refetch, slowly
+	clock := time.Now().Add(2 * jwksRefreshInterval)
+	v.now = func() time.Time { return clock }
+	iss.block = make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v.VerifyToken(ctx, token("rotated"))
+		}()
+	}
+	for iss.jwksHits.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := v.VerifyToken(ctx, token("rsa-1"))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a known key to verify during the fetch, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("verifying with a known key waited for the key fetch")
+	}
+
+	close(iss.block)
+	wg.Wait()
+	if hits := iss.jwksHits.Load(); hits != 2 {
+		t.Errorf("expected concurrent lookups to share one fetch, got %d fetches", hits)
+	}
+}
+
+func TestAuthenticate_StaticKeysAndOIDC(t *testing.T) {
+	iss := newTestIssuer(t)
+	mw := Authenticate(StaticKeys{"key-1": "tenant-a"}, NewOIDCVerifier(config.OIDCConfig{Issuer: iss.URL, Audience: "gavel"}))
+	var got Identity
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = Identity{Tenant: TenantFromContext(r.Context()), Subject: SubjectFromContext(r.Context())}
+	}))
+
+	jwt := iss.sign(t, "RS256", "rsa-1", map[string]interface{}{"iss": iss.URL, "aud": "gavel", "sub": "svc-7", "exp": time.Now().Add(time.Minute).Unix()})
+	for token, want := range map[string]string{"key-1": "tenant-a", jwt: "svc-7", "nope": ""} {
+		got = Identity{}
+		req := httptest.NewRequest("GET", "/v1/results", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if want == "" {
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("expected 401 for an unknown token, got %d", w.Code)
+			}
+			continue
+		}
+		if w.Code != http.StatusOK || got.Tenant != want || got.Subject == "" {
+			t.Errorf("token %.10s: got %d, %+v", token, w.Code, got)
+		}
+	}
+}
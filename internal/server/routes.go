@@ -5,6 +5,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 
+	"github.com/chris-regnier/gavel/internal/metrics"
 	"github.com/chris-regnier/gavel/internal/server/middleware"
 	"github.com/chris-regnier/gavel/internal/service"
 	"github.com/chris-regnier/gavel/internal/store"
@@ -16,7 +17,13 @@ type RouterConfig struct {
 	JudgeService   *service.JudgeService
 	Store          store.Store
 	AuthKeys       map[string]string // API key -> tenant ID
-	MaxConcurrent  int
+	// Verifiers accept bearer tokens besides AuthKeys, such as OIDC JWTs.
+	Verifiers []middleware.TokenVerifier
+	// Limits, when set, rate-limits each token and enforces daily quotas
+	// on analysis from the usage Metrics records per tenant.
+	Limits        middleware.LimitsFunc
+	MaxConcurrent int
+	Metrics       *metrics.Collector // Served at /metrics when created with metrics.WithPrometheus
 }
 
 // NewRouter creates a configured chi router with all routes and middleware.
@@ -44,14 +51,31 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 	r.Get("/v1/health", h.HandleHealth)
 	r.Get("/v1/ready", h.HandleReady)
 
+	// Prometheus scrape endpoint (no auth)
+	if cfg.Metrics != nil {
+		if ph := cfg.Metrics.PrometheusHandler(); ph != nil {
+			r.Handle("/metrics", ph)
+		}
+	}
+
 	// Authenticated API routes
 	r.Group(func(r chi.Router) {
+		verifiers := cfg.Verifiers
 		if len(cfg.AuthKeys) > 0 {
-			r.Use(middleware.Auth(cfg.AuthKeys))
+			verifiers = append([]middleware.TokenVerifier{middleware.StaticKeys(cfg.AuthKeys)}, verifiers...)
+		}
+		if len(verifiers) > 0 {
+			r.Use(middleware.Authenticate(verifiers...))
+		}
+
+		analyze := r
+		if cfg.Limits != nil {
+			r.Use(middleware.RateLimit(cfg.Limits))
+			analyze = r.With(middleware.Quota(cfg.Metrics, cfg.Limits))
 		}
 
-		r.Post("/v1/analyze", h.HandleAnalyze)
-		r.Post("/v1/analyze/stream", h.HandleAnalyzeStream)
+		analyze.Post("/v1/analyze", h.HandleAnalyze)
+		analyze.Post("/v1/analyze/stream", h.HandleAnalyzeStream)
 		r.Post("/v1/judge", h.HandleJudge)
 		r.Get("/v1/results", h.HandleListResults)
 		r.Get("/v1/results/{id}", h.HandleGetResult)
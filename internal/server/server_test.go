@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/chris-regnier/gavel/internal/analyzer"
 	"github.com/chris-regnier/gavel/internal/config"
+	"github.com/chris-regnier/gavel/internal/metrics"
 	"github.com/chris-regnier/gavel/internal/server"
 	"github.com/chris-regnier/gavel/internal/service"
 	"github.com/chris-regnier/gavel/internal/store"
@@ -162,9 +164,13 @@ func TestIntegration_AnalyzeStream(t *testing.T) {
 		}
 	}
 
+	hasResult := false
 	hasTier := false
 	hasComplete := false
 	for _, e := range events {
+		if e == "result" && !hasTier {
+			hasResult = true
+		}
 		if e == "tier" {
 			hasTier = true
 		}
@@ -173,6 +179,9 @@ func TestIntegration_AnalyzeStream(t *testing.T) {
 		}
 	}
 
+	if !hasResult {
+		t.Error("expected a 'result' SSE event before the first 'tier' event")
+	}
 	if !hasTier {
 		t.Error("expected at least one 'tier' SSE event")
 	}
@@ -180,3 +189,67 @@ func TestIntegration_AnalyzeStream(t *testing.T) {
 		t.Error("expected a 'complete' SSE event")
 	}
 }
+
+func TestIntegration_Metrics(t *testing.T) {
+	fs := store.NewFileStore(t.TempDir())
+	collector := metrics.NewCollector(metrics.WithPrometheus())
+	analyzeSvc := service.NewAnalyzeService(fs).WithMetrics(collector).WithClientFactory(
+		func(_ config.ProviderConfig) analyzer.BAMLClient {
+			return &mockBAMLClient{}
+		},
+	)
+	ts := httptest.NewServer(server.NewRouter(server.RouterConfig{
+		AnalyzeService: analyzeSvc,
+		JudgeService:   service.NewJudgeService(fs, ""),
+		Store:          fs,
+		AuthKeys:       map[string]string{"test-key": "test-tenant"},
+		Metrics:        collector,
+	}))
+	defer ts.Close()
+
+	body := `{
+		"artifacts": [{"path": "test.go", "content": "package main\n", "kind": "file"}],
+		"config": {
+			"provider": {"name": "test"},
+			"persona": "code-reviewer",
+			"policies": {"test": {"enabled": true, "description": "Test", "severity": "warning"}}
+		}
+	}`
+	req, _ := http.NewRequest("POST", ts.URL+"/v1/analyze", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer test-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// Scrapes need no API key
+	resp, err = http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	scraped, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{`gavel_analyses_total{tier="comprehensive"} 1`, `gavel_llm_tokens_total{direction="in",tier="comprehensive"}`} {
+		if !strings.Contains(string(scraped), want) {
+			t.Errorf("scrape missing %q", want)
+		}
+	}
+}
+
+func TestIntegration_MetricsDisabled(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without metrics, got %d", resp.StatusCode)
+	}
+}
@@ -43,3 +43,15 @@ func (s *SSEWriter) WriteEvent(event string, data interface{}) error {
 
 	return nil
 }
+
+// WriteComment writes an SSE comment line, which clients ignore. It keeps
+// idle connections from being closed by proxies during long analyses.
+func (s *SSEWriter) WriteComment(text string) error {
+	if _, err := fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return fmt.Errorf("writing SSE comment: %w", err)
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
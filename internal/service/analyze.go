@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
 	"github.com/chris-regnier/gavel/internal/analyzer"
 	"github.com/chris-regnier/gavel/internal/config"
 	"github.com/chris-regnier/gavel/internal/input"
+	"github.com/chris-regnier/gavel/internal/metrics"
 	"github.com/chris-regnier/gavel/internal/rules"
 	"github.com/chris-regnier/gavel/internal/sarif"
 	"github.com/chris-regnier/gavel/internal/store"
@@ -23,6 +23,9 @@ type ClientFactory func(cfg config.ProviderConfig) analyzer.BAMLClient
 type AnalyzeService struct {
 	store         store.Store
 	clientFactory ClientFactory
+	version       string
+	metrics       *metrics.Collector
+	prices        metrics.PriceTable
 }
 
 // NewAnalyzeService creates an AnalyzeService with the default BAML client factory.
@@ -30,7 +33,7 @@ func NewAnalyzeService(s store.Store) *AnalyzeService {
 	return &AnalyzeService{
 		store: s,
 		clientFactory: func(cfg config.ProviderConfig) analyzer.BAMLClient {
-			return analyzer.NewBAMLLiveClient(cfg)
+			return analyzer.NewProviderClient(cfg)
 		},
 	}
 }
@@ -41,6 +44,38 @@ func (s *AnalyzeService) WithClientFactory(f ClientFactory) *AnalyzeService {
 	return s
 }
 
+// WithVersion sets the Gavel version recorded in each report's provenance.
+func (s *AnalyzeService) WithVersion(v string) *AnalyzeService {
+	s.version = v
+	return s
+}
+
+// WithMetrics records every analysis to c, such as one exported to Prometheus.
+func (s *AnalyzeService) WithMetrics(c *metrics.Collector) *AnalyzeService {
+	s.metrics = c
+	return s
+}
+
+// WithPricing prices every LLM call at t and records its tokens and cost to
+// the metrics collector under the tenant carried by the request context, so
+// per-tenant quotas can be enforced. Prices come from the operator, not the
+// request, so callers cannot price their own calls.
+func (s *AnalyzeService) WithPricing(t metrics.PriceTable) *AnalyzeService {
+	s.prices = t
+	return s
+}
+
+// newClient is the client factory for a request: the configured factory,
+// with cost tracking when pricing is set.
+func (s *AnalyzeService) newClient(cfg config.ProviderConfig) analyzer.BAMLClient {
+	client := s.clientFactory(cfg)
+	if s.prices == nil {
+		return client
+	}
+	price, _ := s.prices.Lookup(cfg.Name, cfg.ModelName())
+	return analyzer.NewCostTrackingClient(client, cfg.Name, cfg.ModelName(), price, nil, s.metrics)
+}
+
 // Analyze runs all tiers synchronously and stores the SARIF result.
 func (s *AnalyzeService) Analyze(ctx context.Context, req AnalyzeRequest) (*AnalyzeResult, error) {
 	personaPrompt, err := buildPersonaPrompt(ctx, req.Config)
@@ -48,13 +83,15 @@ func (s *AnalyzeService) Analyze(ctx context.Context, req AnalyzeRequest) (*Anal
 		return nil, err
 	}
 
-	ta := analyzer.NewTieredAnalyzer(s.clientFactory(req.Config.Provider), tieredOptions(req.Rules)...)
+	ta := analyzer.NewTieredAnalyzer(s.newClient(req.Config.Provider), s.tieredOptions(req.Config, req.Rules)...)
 	results, err := ta.Analyze(ctx, req.Artifacts, req.Config.Policies, personaPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("analyzing: %w", err)
 	}
 
-	sarifLog := sarif.Assemble(results, BuildDescriptors(req.Config.Policies, req.Rules), scopeFromArtifacts(req.Artifacts), req.Config.Persona)
+	sarifLog := sarif.Assemble(results, BuildDescriptors(req.Config.Policies, req.Rules), scopeFromArtifacts(req.Artifacts), req.Config.Persona, s.assembleOptions(req.Config, req.Rules, personaPrompt)...)
+	sarif.DemoteBelowConfidence(sarifLog.Runs[0].Results, req.Config.MinConfidenceFor)
+	ta.RecordEscalation(sarifLog)
 
 	baselineSummary, err := s.applyBaseline(ctx, sarifLog, req.BaselineID)
 	if err != nil {
@@ -91,38 +128,24 @@ func (s *AnalyzeService) AnalyzeScoped(ctx context.Context, req ScopedAnalyzeReq
 		return nil, err
 	}
 
-	ta := analyzer.NewTieredAnalyzer(s.clientFactory(req.Config.Provider), tieredOptions(req.Rules)...)
+	ta := analyzer.NewTieredAnalyzer(s.newClient(req.Config.Provider), s.tieredOptions(req.Config, req.Rules)...)
 
-	// Instant tier on the full file, then filter to the changed range.
-	fullArtifact := input.Artifact{Path: req.Artifact.Path, Content: req.Artifact.Content, Kind: input.KindFile}
-	instantResults := filterByLineRange(ta.RunPatternMatching(fullArtifact), req.ChangedStart, req.ChangedEnd)
-
-	// Comprehensive tier on a window around the changed range.
+	// Instant tier on the full file, LLM tiers on a window around the
+	// changed range; AnalyzeRange maps everything back to real file
+	// line numbers and drops findings outside the range.
 	contextWindow := req.ContextWindow
 	if contextWindow <= 0 {
-		contextWindow = 10
+		contextWindow = analyzer.DefaultRangeContext
 	}
-	scopedContent, scopeStart := windowedContent(req.Artifact.Content, req.ChangedStart, req.ChangedEnd, contextWindow)
-	scopedArtifact := []input.Artifact{{Path: req.Artifact.Path, Content: scopedContent, Kind: input.KindFile}}
-	comprehensiveResults, err := ta.Analyze(ctx, scopedArtifact, req.Config.Policies, personaPrompt)
+	fullArtifact := input.Artifact{Path: req.Artifact.Path, Content: req.Artifact.Content, Kind: input.KindFile}
+	allResults, err := ta.AnalyzeRange(ctx, fullArtifact, req.ChangedStart, req.ChangedEnd, contextWindow, req.Config.Policies, personaPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("analyzing: %w", err)
 	}
 
-	// The LLM saw the scope starting at line 1; shift back to the
-	// real file line numbers, then drop anything outside the changed
-	// range. The instant tier already used real line numbers.
-	offset := scopeStart - 1
-	for i := range comprehensiveResults {
-		if len(comprehensiveResults[i].Locations) > 0 {
-			comprehensiveResults[i].Locations[0].PhysicalLocation.Region.StartLine += offset
-			comprehensiveResults[i].Locations[0].PhysicalLocation.Region.EndLine += offset
-		}
-	}
-	comprehensiveResults = filterByLineRange(comprehensiveResults, req.ChangedStart, req.ChangedEnd)
-
-	allResults := append(instantResults, comprehensiveResults...)
-	sarifLog := sarif.Assemble(allResults, BuildDescriptors(req.Config.Policies, req.Rules), "diff", req.Config.Persona)
+	sarifLog := sarif.Assemble(allResults, BuildDescriptors(req.Config.Policies, req.Rules), "diff", req.Config.Persona, s.assembleOptions(req.Config, req.Rules, personaPrompt)...)
+	sarif.DemoteBelowConfidence(sarifLog.Runs[0].Results, req.Config.MinConfidenceFor)
+	ta.RecordEscalation(sarifLog)
 
 	baselineSummary, err := s.applyBaseline(ctx, sarifLog, req.BaselineID)
 	if err != nil {
@@ -176,19 +199,22 @@ func (s *AnalyzeService) applyBaseline(ctx context.Context, sarifLog *sarif.Log,
 	return summary, nil
 }
 
-// AnalyzeStream runs analysis progressively, emitting per-tier results on a channel.
+// AnalyzeStream runs analysis progressively, emitting each file's results
+// per tier as they complete and, when a tier has finished every file, the
+// tier's aggregate, on the event channel.
 // The error channel is for fatal errors only (invalid config, all providers unreachable).
-// Tier-level failures are reported as TierResult with an Error field.
+// Tier-level failures are reported as events with an Error field.
 // The result channel receives exactly one value when the stream completes.
-func (s *AnalyzeService) AnalyzeStream(ctx context.Context, req AnalyzeRequest) (<-chan TierResult, <-chan AnalyzeResult, <-chan error) {
-	tierCh := make(chan TierResult, 10)
+// Events stop when ctx is canceled, so a consumer may stop reading then.
+func (s *AnalyzeService) AnalyzeStream(ctx context.Context, req AnalyzeRequest) (<-chan StreamEvent, <-chan AnalyzeResult, <-chan error) {
+	eventCh := make(chan StreamEvent, 10)
 	resultCh := make(chan AnalyzeResult, 1)
 	errCh := make(chan error, 1)
 
 	go func() {
 		defer close(errCh)    // runs 3rd (last)
 		defer close(resultCh) // runs 2nd
-		defer close(tierCh)   // runs 1st
+		defer close(eventCh)  // runs 1st
 
 		personaPrompt, err := buildPersonaPrompt(ctx, req.Config)
 		if err != nil {
@@ -196,10 +222,19 @@ func (s *AnalyzeService) AnalyzeStream(ctx context.Context, req AnalyzeRequest)
 			return
 		}
 
-		ta := analyzer.NewTieredAnalyzer(s.clientFactory(req.Config.Provider), tieredOptions(req.Rules)...)
+		emit := func(ev StreamEvent) bool {
+			select {
+			case eventCh <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ta := analyzer.NewTieredAnalyzer(s.newClient(req.Config.Provider), s.tieredOptions(req.Config, req.Rules)...)
 		progressive := ta.AnalyzeProgressive(ctx, req.Artifacts, req.Config.Policies, personaPrompt)
 
-		// Aggregate TieredResults by tier for SSE events
+		// Aggregate TieredResults by tier for the tier events
 		currentTier := ""
 		var currentResults []sarif.Result
 		var allResults []sarif.Result
@@ -210,28 +245,38 @@ func (s *AnalyzeService) AnalyzeStream(ctx context.Context, req AnalyzeRequest)
 			tierName := tr.Tier.String()
 
 			// When tier changes, flush the previous tier's aggregated results
-			if currentTier != "" && tierName != currentTier {
-				tierCh <- TierResult{
+			if currentTier != "" && tierName != currentTier && tierSeen {
+				emit(StreamEvent{Tier: &TierResult{
 					Tier:      currentTier,
 					Results:   currentResults,
 					ElapsedMs: time.Since(tierStart).Milliseconds(),
-				}
+				}})
 				currentResults = nil
 				tierStart = time.Now()
-				tierSeen = false
 			}
 			currentTier = tierName
 			tierSeen = true
 
+			file := &FileResult{
+				Tier:      tierName,
+				File:      tr.FilePath,
+				Results:   tr.Results,
+				ElapsedMs: tr.Duration.Milliseconds(),
+				FromCache: tr.FromCache,
+			}
 			if tr.Error != nil {
-				tierCh <- TierResult{
+				file.Error = tr.Error.Error()
+				file.Results = nil
+				emit(StreamEvent{File: file})
+				emit(StreamEvent{Tier: &TierResult{
 					Tier:      tierName,
 					ElapsedMs: time.Since(tierStart).Milliseconds(),
 					Error:     tr.Error.Error(),
-				}
+				}})
 				tierSeen = false
 				continue
 			}
+			emit(StreamEvent{File: file})
 
 			currentResults = append(currentResults, tr.Results...)
 			allResults = append(allResults, tr.Results...)
@@ -239,15 +284,21 @@ func (s *AnalyzeService) AnalyzeStream(ctx context.Context, req AnalyzeRequest)
 
 		// Flush final tier (always emit if the tier was seen, even with no results)
 		if currentTier != "" && tierSeen {
-			tierCh <- TierResult{
+			emit(StreamEvent{Tier: &TierResult{
 				Tier:      currentTier,
 				Results:   currentResults,
 				ElapsedMs: time.Since(tierStart).Milliseconds(),
-			}
+			}})
+		}
+		if ctx.Err() != nil {
+			errCh <- ctx.Err()
+			return
 		}
 
 		// Store final SARIF
-		sarifLog := sarif.Assemble(allResults, BuildDescriptors(req.Config.Policies, req.Rules), scopeFromArtifacts(req.Artifacts), req.Config.Persona)
+		sarifLog := sarif.Assemble(allResults, BuildDescriptors(req.Config.Policies, req.Rules), scopeFromArtifacts(req.Artifacts), req.Config.Persona, s.assembleOptions(req.Config, req.Rules, personaPrompt)...)
+		sarif.DemoteBelowConfidence(sarifLog.Runs[0].Results, req.Config.MinConfidenceFor)
+		ta.RecordEscalation(sarifLog)
 
 		baselineSummary, baselineErr := s.applyBaseline(ctx, sarifLog, req.BaselineID)
 		if baselineErr != nil {
@@ -271,7 +322,7 @@ func (s *AnalyzeService) AnalyzeStream(ctx context.Context, req AnalyzeRequest)
 		}
 	}()
 
-	return tierCh, resultCh, errCh
+	return eventCh, resultCh, errCh
 }
 
 // buildPersonaPrompt resolves the persona prompt and, when StrictFilter
@@ -293,11 +344,37 @@ func buildPersonaPrompt(ctx context.Context, cfg config.Config) (string, error)
 	return prompt, nil
 }
 
-func tieredOptions(loadedRules []rules.Rule) []analyzer.TieredAnalyzerOption {
-	if len(loadedRules) == 0 {
-		return nil
+func (s *AnalyzeService) tieredOptions(cfg config.Config, loadedRules []rules.Rule) []analyzer.TieredAnalyzerOption {
+	opts := []analyzer.TieredAnalyzerOption{
+		analyzer.WithPolicyRoutes(analyzer.PolicyRoutes(&cfg, s.newClient)),
+	}
+	opts = append(opts, analyzer.FastTierOptions(&cfg, s.newClient)...)
+	if len(loadedRules) > 0 {
+		opts = append(opts, analyzer.WithInstantPatterns(loadedRules))
+	}
+	if d := cfg.Provider.RequestTimeoutDuration(); d > 0 {
+		opts = append(opts, analyzer.WithTieredRequestTimeout(d))
+	}
+	if n := cfg.Provider.RequestTokenBudget(); n > 0 {
+		opts = append(opts, analyzer.WithTieredTokenBudget(n))
+	}
+	if n := cfg.Provider.BatchTokens; n > 0 {
+		opts = append(opts, analyzer.WithTieredBatching(n))
 	}
-	return []analyzer.TieredAnalyzerOption{analyzer.WithInstantPatterns(loadedRules)}
+	if len(cfg.PathOverrides) > 0 {
+		opts = append(opts, analyzer.WithPathOverrides(cfg.PathOverrides))
+	}
+	opts = append(opts, analyzer.WithSecretScanner(analyzer.SecretScanner(cfg.Secrets)))
+	if cfg.ParseErrors.Action != "" || cfg.ParseErrors.Retries > 0 {
+		opts = append(opts, analyzer.WithParseErrorPolicy(analyzer.ParseErrorAction(cfg.ParseErrors.Action), cfg.ParseErrors.Retries))
+	}
+	if cfg.Escalation.Enabled {
+		opts = append(opts, analyzer.WithEscalation(cfg.Escalation))
+	}
+	if s.metrics != nil {
+		opts = append(opts, analyzer.WithMetricsCollector(s.metrics))
+	}
+	return opts
 }
 
 // applySuppressions loads .gavel/suppressions.yaml from rootDir and
@@ -331,39 +408,6 @@ func countFindings(sarifLog *sarif.Log) int {
 	return len(sarifLog.Runs[0].Results)
 }
 
-// filterByLineRange keeps only results whose first location's StartLine
-// falls within [start, end] inclusive. Results without a location are
-// dropped.
-func filterByLineRange(results []sarif.Result, start, end int) []sarif.Result {
-	var out []sarif.Result
-	for _, r := range results {
-		if len(r.Locations) == 0 {
-			continue
-		}
-		line := r.Locations[0].PhysicalLocation.Region.StartLine
-		if line >= start && line <= end {
-			out = append(out, r)
-		}
-	}
-	return out
-}
-
-// windowedContent returns the substring of content covering
-// [start-window, end+window] (clamped to the file bounds), along with
-// the 1-indexed line where the window begins. Lines are split on "\n".
-func windowedContent(content string, start, end, window int) (string, int) {
-	lines := strings.Split(content, "\n")
-	scopeStart := start - window
-	if scopeStart < 1 {
-		scopeStart = 1
-	}
-	scopeEnd := end + window
-	if scopeEnd > len(lines) {
-		scopeEnd = len(lines)
-	}
-	return strings.Join(lines[scopeStart-1:scopeEnd], "\n"), scopeStart
-}
-
 // BuildDescriptors assembles SARIF reportingDescriptors from both enabled
 // policies and loaded rules. Rule descriptors carry help/helpUri populated
 // from the rule's remediation, CWE, and reference metadata.
@@ -384,6 +428,21 @@ func BuildDescriptors(policies map[string]config.Policy, loadedRules []rules.Rul
 	return descriptors
 }
 
+// assembleOptions returns the SARIF assembly options for a request: the
+// configured deduplication and, unless hashing fails, the run's provenance.
+func (s *AnalyzeService) assembleOptions(cfg config.Config, loadedRules []rules.Rule, personaPrompt string) []sarif.AssembleOption {
+	opts := []sarif.AssembleOption{
+		sarif.WithDedupWindow(cfg.DedupWindow),
+		sarif.WithDedupMode(sarif.DedupMode(cfg.Dedup), cfg.DedupSimilarity),
+	}
+	prov, err := sarif.NewProvenance(s.version, &cfg, loadedRules, personaPrompt)
+	if err != nil {
+		slog.Warn("omitting report provenance", "err", err)
+		return opts
+	}
+	return append(opts, sarif.WithProvenance(prov))
+}
+
 // scopeFromArtifacts determines the input scope string from artifact kinds.
 func scopeFromArtifacts(artifacts []input.Artifact) string {
 	for _, a := range artifacts {
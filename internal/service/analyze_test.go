@@ -257,16 +257,35 @@ func TestAnalyzeService_AnalyzeStream(t *testing.T) {
 		},
 	}
 
-	tierCh, resultCh, errCh := svc.AnalyzeStream(context.Background(), req)
+	eventCh, resultCh, errCh := svc.AnalyzeStream(context.Background(), req)
 
 	var tiers []TierResult
-	for tr := range tierCh {
-		tiers = append(tiers, tr)
+	var files []FileResult
+	for ev := range eventCh {
+		switch {
+		case ev.File != nil:
+			if len(tiers) == 0 && len(files) > 0 && files[0].Tier != ev.File.Tier {
+				t.Error("expected a tier event before the next tier's file results")
+			}
+			files = append(files, *ev.File)
+		case ev.Tier != nil:
+			tiers = append(tiers, *ev.Tier)
+		default:
+			t.Error("stream event has neither a file nor a tier")
+		}
 	}
 
 	if len(tiers) == 0 {
 		t.Fatal("expected at least one tier result")
 	}
+	if len(files) == 0 {
+		t.Fatal("expected at least one file result")
+	}
+	for _, f := range files {
+		if f.File != "test.go" || f.Tier == "" {
+			t.Errorf("unexpected file result %+v", f)
+		}
+	}
 
 	for _, tr := range tiers {
 		if tr.Tier == "" {
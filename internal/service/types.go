@@ -62,6 +62,27 @@ type TierResult struct {
 	Error     string         `json:"error,omitempty"`
 }
 
+// FileResult is one tier's results for one file, emitted as soon as the
+// tier finishes the file.
+type FileResult struct {
+	Tier      string         `json:"tier"`
+	File      string         `json:"file"`
+	Results   []sarif.Result `json:"results"`
+	ElapsedMs int64          `json:"elapsed_ms"`
+	FromCache bool           `json:"from_cache,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// StreamEvent is one event of AnalyzeStream: exactly one of File and Tier
+// is set.
+type StreamEvent struct {
+	// File is set when a tier has finished a file.
+	File *FileResult
+	// Tier is set when a tier has finished every file, and aggregates the
+	// tier's results.
+	Tier *TierResult
+}
+
 // BaselineSummary breaks down how many results in an AnalyzeResult fell
 // into each baselineState bucket. It is zero-valued when baseline
 // comparison was not performed.
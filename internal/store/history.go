@@ -0,0 +1,464 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+// historyFile is the SQLite index kept alongside the stored results.
+const historyFile = "history.db"
+
+// idTimeLayout is the timestamp prefix of a result ID, see generateID.
+const idTimeLayout = "2006-01-02T15-04-05Z"
+
+// History is a queryable index over the runs in a FileStore, keyed by
+// result ID, time and commit. It is a cache: the result directories stay
+// the source of truth, and Sync brings the index up to date with them.
+type History struct {
+	db *sql.DB
+	fs *FileStore
+}
+
+// RunRecord summarizes one stored run. Counts cover actionable findings:
+// those not suppressed, informational or resolved since a baseline.
+type RunRecord struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	Commit     string    `json:"commit,omitempty"`
+	Branch     string    `json:"branch,omitempty"`
+	Decision   string    `json:"decision,omitempty"`
+	Findings   int       `json:"findings"`
+	Errors     int       `json:"errors"`
+	Warnings   int       `json:"warnings"`
+	Notes      int       `json:"notes"`
+	Suppressed int       `json:"suppressed"`
+}
+
+// FindingRecord is an actionable finding of an indexed run.
+type FindingRecord struct {
+	RuleID  string `json:"rule_id"`
+	Level   string `json:"level"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	// Key matches the finding across runs: its content fingerprint, or its
+	// rule and location when it has none.
+	Key string `json:"-"`
+}
+
+// HistoryQuery selects runs. Zero fields do not filter.
+type HistoryQuery struct {
+	Since  time.Time
+	Branch string
+	Limit  int
+}
+
+// HistoryDiff compares the findings of two runs.
+type HistoryDiff struct {
+	From       RunRecord       `json:"from"`
+	To         RunRecord       `json:"to"`
+	Introduced []FindingRecord `json:"introduced"`
+	Fixed      []FindingRecord `json:"fixed"`
+	Unchanged  int             `json:"unchanged"`
+}
+
+// RuleTrend is a rule's finding count in each of a series of runs.
+type RuleTrend struct {
+	RuleID string `json:"rule_id"`
+	Counts []int  `json:"counts"`
+}
+
+// History opens the index in the store's directory, creating it if needed,
+// and syncs it with the stored runs. The caller must Close it.
+func (s *FileStore) History(ctx context.Context) (*History, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(s.dir, historyFile)+"?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("open history: %w", err)
+	}
+	h := &History{db: db, fs: s}
+	if err := h.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate history: %w", err)
+	}
+	if _, err := h.Sync(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// Close closes the index.
+func (h *History) Close() error {
+	return h.db.Close()
+}
+
+func (h *History) migrate() error {
+	_, err := h.db.Exec(`
+		CREATE TABLE IF NOT EXISTS runs (
+			id         TEXT PRIMARY KEY,
+			time       TEXT NOT NULL,
+			commit_sha TEXT NOT NULL DEFAULT '',
+			branch     TEXT NOT NULL DEFAULT '',
+			decision   TEXT NOT NULL DEFAULT '',
+			findings   INTEGER NOT NULL DEFAULT 0,
+			errors     INTEGER NOT NULL DEFAULT 0,
+			warnings   INTEGER NOT NULL DEFAULT 0,
+			notes      INTEGER NOT NULL DEFAULT 0,
+			suppressed INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_runs_time ON runs(time);
+		CREATE INDEX IF NOT EXISTS idx_runs_commit ON runs(commit_sha);
+
+		CREATE TABLE IF NOT EXISTS findings (
+			run_id  TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+			rule_id TEXT NOT NULL,
+			level   TEXT NOT NULL,
+			file    TEXT NOT NULL DEFAULT '',
+			line    INTEGER NOT NULL DEFAULT 0,
+			message TEXT NOT NULL DEFAULT '',
+			key     TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_findings_run ON findings(run_id, rule_id);
+	`)
+	return err
+}
+
+// Sync indexes stored runs missing from the index and drops runs whose
+// result directories were deleted. It returns how many runs were added.
+// Runs whose SARIF cannot be read are skipped and retried on the next
+// sync.
+func (h *History) Sync(ctx context.Context) (int, error) {
+	ids, err := h.fs.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing results: %w", err)
+	}
+	onDisk := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		onDisk[id] = true
+	}
+
+	rows, err := h.db.QueryContext(ctx, "SELECT id FROM runs")
+	if err != nil {
+		return 0, err
+	}
+	indexed := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		indexed[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback on error path; commit handles the success path
+
+	for id := range indexed {
+		if !onDisk[id] {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM runs WHERE id = ?", id); err != nil {
+				return 0, err
+			}
+		}
+	}
+	added := 0
+	for _, id := range ids {
+		if indexed[id] {
+			continue
+		}
+		log, err := h.fs.ReadSARIF(ctx, id)
+		if err != nil {
+			continue
+		}
+		if err := h.index(ctx, tx, id, log); err != nil {
+			return 0, fmt.Errorf("indexing %s: %w", id, err)
+		}
+		added++
+	}
+	return added, tx.Commit()
+}
+
+// index records one run and its actionable findings.
+func (h *History) index(ctx context.Context, tx *sql.Tx, id string, log *sarif.Log) error {
+	rec := RunRecord{ID: id, Time: h.runTime(id)}
+	if v, err := h.fs.ReadVerdict(ctx, id); err == nil {
+		rec.Decision = v.Decision
+	}
+
+	var findings []FindingRecord
+	for _, run := range log.Runs {
+		if rec.Commit == "" && len(run.VersionControlProvenance) > 0 {
+			rec.Commit = run.VersionControlProvenance[0].RevisionID
+			rec.Branch = run.VersionControlProvenance[0].Branch
+		}
+		for _, r := range run.Results {
+			if len(r.Suppressions) > 0 {
+				rec.Suppressed++
+				continue
+			}
+			if r.Kind == sarif.KindInformational || r.BaselineState == sarif.BaselineStateAbsent {
+				continue
+			}
+			switch r.Level {
+			case "error":
+				rec.Errors++
+			case "warning":
+				rec.Warnings++
+			default:
+				rec.Notes++
+			}
+			findings = append(findings, findingRecord(r))
+		}
+	}
+	rec.Findings = len(findings)
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO runs (id, time, commit_sha, branch, decision, findings, errors, warnings, notes, suppressed)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.ID, rec.Time.UTC().Format(time.RFC3339), rec.Commit, rec.Branch, rec.Decision,
+		rec.Findings, rec.Errors, rec.Warnings, rec.Notes, rec.Suppressed); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO findings (run_id, rule_id, level, file, line, message, key) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, f := range findings {
+		if _, err := stmt.ExecContext(ctx, id, f.RuleID, f.Level, f.File, f.Line, f.Message, f.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runTime reads the time a run was stored from its ID, falling back to the
+// SARIF file's modification time for IDs not made by generateID.
+func (h *History) runTime(id string) time.Time {
+	if len(id) >= len(idTimeLayout) {
+		if t, err := time.Parse(idTimeLayout, id[:len(idTimeLayout)]); err == nil {
+			return t
+		}
+	}
+	if info, err := os.Stat(filepath.Join(h.fs.resultDir(id), "sarif.json")); err == nil {
+		return info.ModTime().UTC().Truncate(time.Second)
+	}
+	return time.Time{}
+}
+
+func findingRecord(r sarif.Result) FindingRecord {
+	f := FindingRecord{RuleID: r.RuleID, Level: r.Level, Message: r.Message.Text}
+	if len(r.Locations) > 0 {
+		f.File = r.Locations[0].PhysicalLocation.ArtifactLocation.URI
+		f.Line = r.Locations[0].PhysicalLocation.Region.StartLine
+	}
+	if fp := r.Fingerprints[sarif.ContentFingerprintV1]; fp != "" {
+		f.Key = fp
+	} else {
+		f.Key = fmt.Sprintf("%s|%s|%d", f.RuleID, f.File, f.Line)
+	}
+	return f
+}
+
+const runColumns = "id, time, commit_sha, branch, decision, findings, errors, warnings, notes, suppressed"
+
+func scanRuns(rows *sql.Rows) ([]RunRecord, error) {
+	defer rows.Close()
+	var out []RunRecord
+	for rows.Next() {
+		var rec RunRecord
+		var t string
+		if err := rows.Scan(&rec.ID, &t, &rec.Commit, &rec.Branch, &rec.Decision,
+			&rec.Findings, &rec.Errors, &rec.Warnings, &rec.Notes, &rec.Suppressed); err != nil {
+			return nil, err
+		}
+		rec.Time, _ = time.Parse(time.RFC3339, t)
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// Runs returns the runs q selects, newest first.
+func (h *History) Runs(ctx context.Context, q HistoryQuery) ([]RunRecord, error) {
+	query := "SELECT " + runColumns + " FROM runs WHERE 1=1"
+	var args []interface{}
+	if !q.Since.IsZero() {
+		query += " AND time >= ?"
+		args = append(args, q.Since.UTC().Format(time.RFC3339))
+	}
+	if q.Branch != "" {
+		query += " AND branch = ?"
+		args = append(args, q.Branch)
+	}
+	query += " ORDER BY time DESC, id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+	rows, err := h.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRuns(rows)
+}
+
+// Resolve finds the run ref names: a result ID, or a commit SHA or prefix
+// of at least 7 characters, which names the newest run of that commit.
+func (h *History) Resolve(ctx context.Context, ref string) (RunRecord, error) {
+	rows, err := h.db.QueryContext(ctx, "SELECT "+runColumns+" FROM runs WHERE id = ?", ref)
+	if err != nil {
+		return RunRecord{}, err
+	}
+	runs, err := scanRuns(rows)
+	if err != nil {
+		return RunRecord{}, err
+	}
+	if len(runs) == 0 && len(ref) >= 7 {
+		rows, err := h.db.QueryContext(ctx,
+			"SELECT "+runColumns+" FROM runs WHERE commit_sha LIKE ? ORDER BY time DESC, id DESC",
+			strings.ToLower(ref)+"%")
+		if err != nil {
+			return RunRecord{}, err
+		}
+		if runs, err = scanRuns(rows); err != nil {
+			return RunRecord{}, err
+		}
+		commits := map[string]bool{}
+		for _, r := range runs {
+			commits[r.Commit] = true
+		}
+		if len(commits) > 1 {
+			return RunRecord{}, fmt.Errorf("commit prefix %s is ambiguous", ref)
+		}
+	}
+	if len(runs) == 0 {
+		return RunRecord{}, fmt.Errorf("no run matches %s", ref)
+	}
+	return runs[0], nil
+}
+
+// Findings returns the actionable findings of the run with id, ordered by
+// file, line and rule.
+func (h *History) Findings(ctx context.Context, id string) ([]FindingRecord, error) {
+	rows, err := h.db.QueryContext(ctx,
+		"SELECT rule_id, level, file, line, message, key FROM findings WHERE run_id = ? ORDER BY file, line, rule_id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []FindingRecord
+	for rows.Next() {
+		var f FindingRecord
+		if err := rows.Scan(&f.RuleID, &f.Level, &f.File, &f.Line, &f.Message, &f.Key); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// Diff reports the findings introduced and fixed between the runs from and
+// to, each resolved as by Resolve. A finding present in both runs counts
+// once per occurrence, so a second copy of an existing finding is
+// introduced.
+func (h *History) Diff(ctx context.Context, from, to string) (HistoryDiff, error) {
+	var d HistoryDiff
+	var err error
+	if d.From, err = h.Resolve(ctx, from); err != nil {
+		return d, err
+	}
+	if d.To, err = h.Resolve(ctx, to); err != nil {
+		return d, err
+	}
+	before, err := h.Findings(ctx, d.From.ID)
+	if err != nil {
+		return d, err
+	}
+	after, err := h.Findings(ctx, d.To.ID)
+	if err != nil {
+		return d, err
+	}
+
+	remaining := map[string]int{}
+	for _, f := range before {
+		remaining[f.Key]++
+	}
+	d.Introduced, d.Fixed = []FindingRecord{}, []FindingRecord{}
+	for _, f := range after {
+		if remaining[f.Key] > 0 {
+			remaining[f.Key]--
+			d.Unchanged++
+			continue
+		}
+		d.Introduced = append(d.Introduced, f)
+	}
+	for _, f := range before {
+		if remaining[f.Key] > 0 {
+			remaining[f.Key]--
+			d.Fixed = append(d.Fixed, f)
+		}
+	}
+	return d, nil
+}
+
+// RuleTrends counts each rule's findings in runs, given oldest first. The
+// trends are sorted by their count in the last run, highest first, then by
+// rule ID.
+func (h *History) RuleTrends(ctx context.Context, runs []RunRecord) ([]RuleTrend, error) {
+	byRule := map[string][]int{}
+	for i, run := range runs {
+		rows, err := h.db.QueryContext(ctx, "SELECT rule_id, COUNT(*) FROM findings WHERE run_id = ? GROUP BY rule_id", run.ID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var rule string
+			var n int
+			if err := rows.Scan(&rule, &n); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if byRule[rule] == nil {
+				byRule[rule] = make([]int, len(runs))
+			}
+			byRule[rule][i] = n
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	trends := make([]RuleTrend, 0, len(byRule))
+	for rule, counts := range byRule {
+		trends = append(trends, RuleTrend{RuleID: rule, Counts: counts})
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		a, b := trends[i].Counts[len(runs)-1], trends[j].Counts[len(runs)-1]
+		if a != b {
+			return a > b
+		}
+		return trends[i].RuleID < trends[j].RuleID
+	})
+	return trends, nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+)
+
+func historyFinding(rule, level, file string, line int, fingerprint string) sarif.Result {
+	r := sarif.Result{
+		RuleID:  rule,
+		Level:   level,
+		Message: sarif.Message{Text: rule},
+		Locations: []sarif.Location{{PhysicalLocation: sarif.PhysicalLocation{
+			ArtifactLocation: sarif.ArtifactLocation{URI: file},
+			Region:           sarif.Region{StartLine: line},
+		}}},
+	}
+	if fingerprint != "" {
+		r.Fingerprints = map[string]string{sarif.ContentFingerprintV1: fingerprint}
+	}
+	return r
+}
+
+// writeRun stores a run under a fixed ID, as WriteSARIF would.
+func writeRun(t *testing.T, dir, id, commit, decision string, results ...sarif.Result) {
+	t.Helper()
+	log := sarif.NewLog("gavel", "test")
+	log.Runs[0].Results = results
+	if commit != "" {
+		log.Runs[0].VersionControlProvenance = []sarif.VersionControlDetails{{RevisionID: commit, Branch: "main"}}
+	}
+	os.MkdirAll(filepath.Join(dir, id), 0755)
+	data, _ := json.Marshal(log)
+	if err := os.WriteFile(filepath.Join(dir, id, "sarif.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if decision != "" {
+		NewFileStore(dir).WriteVerdict(context.Background(), id, &Verdict{Decision: decision})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	suppressed := historyFinding("sql-injection", "error", "db.go", 30, "s1")
+	suppressed.Suppressions = []sarif.SARIFSuppression{{Kind: "external"}}
+	writeRun(t, dir, "2026-03-01T10-00-00Z-aaaaaa", "1111111aaaa", "reject",
+		historyFinding("sql-injection", "error", "db.go", 10, "fp-sql"),
+		historyFinding("error-handling", "warning", "db.go", 20, "fp-err"),
+		historyFinding("error-handling", "warning", "api.go", 5, ""),
+	)
+	writeRun(t, dir, "2026-03-02T10-00-00Z-bbbbbb", "2222222bbbb", "review",
+		historyFinding("error-handling", "warning", "db.go", 24, "fp-err"), // moved, same fingerprint
+		historyFinding("error-handling", "warning", "api.go", 5, ""),
+		historyFinding("magic-number", "note", "api.go", 9, "fp-magic"),
+		suppressed,
+	)
+
+	h, err := NewFileStore(dir).History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	runs, err := h.Runs(ctx, HistoryQuery{})
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("Runs = %+v, %v", runs, err)
+	}
+	latest := runs[0]
+	if latest.ID != "2026-03-02T10-00-00Z-bbbbbb" || latest.Commit != "2222222bbbb" || latest.Branch != "main" || latest.Decision != "review" {
+		t.Errorf("unexpected latest run %+v", latest)
+	}
+	if latest.Findings != 3 || latest.Warnings != 2 || latest.Notes != 1 || latest.Suppressed != 1 {
+		t.Errorf("unexpected counts %+v", latest)
+	}
+	if !latest.Time.Equal(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time = %v", latest.Time)
+	}
+	if since, _ := h.Runs(ctx, HistoryQuery{Since: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}); len(since) != 1 {
+		t.Errorf("expected --since to select one run, got %d", len(since))
+	}
+
+	d, err := h.Diff(ctx, "1111111", "2026-03-02T10-00-00Z-bbbbbb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Introduced) != 1 || d.Introduced[0].RuleID != "magic-number" || len(d.Fixed) != 1 || d.Fixed[0].RuleID != "sql-injection" || d.Unchanged != 2 {
+		t.Errorf("unexpected diff %+v", d)
+	}
+	if _, err := h.Diff(ctx, "123", "2222222"); err == nil {
+		t.Error("expected an unknown run to be reported")
+	}
+
+	trends, err := h.RuleTrends(ctx, []RunRecord{runs[1], runs[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RuleTrend{{"error-handling", []int{2, 2}}, {"magic-number", []int{0, 1}}, {"sql-injection", []int{1, 0}}}
+	if len(trends) != len(want) {
+		t.Fatalf("RuleTrends = %+v", trends)
+	}
+	for i := range want {
+		if trends[i].RuleID != want[i].RuleID || trends[i].Counts[0] != want[i].Counts[0] || trends[i].Counts[1] != want[i].Counts[1] {
+			t.Errorf("RuleTrends[%d] = %+v, want %+v", i, trends[i], want[i])
+		}
+	}
+}
+
+func TestHistory_SyncTracksTheStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeRun(t, dir, "2026-03-01T10-00-00Z-aaaaaa", "", "")
+
+	h, err := NewFileStore(dir).History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	writeRun(t, dir, "2026-03-02T10-00-00Z-bbbbbb", "", "")
+	os.RemoveAll(filepath.Join(dir, "2026-03-01T10-00-00Z-aaaaaa"))
+	if added, err := h.Sync(ctx); err != nil || added != 1 {
+		t.Fatalf("Sync = %d, %v", added, err)
+	}
+	runs, _ := h.Runs(ctx, HistoryQuery{})
+	if len(runs) != 1 || runs[0].ID != "2026-03-02T10-00-00Z-bbbbbb" {
+		t.Errorf("expected only the new run to remain, got %+v", runs)
+	}
+	if ids, _ := NewFileStore(dir).List(ctx); len(ids) != 1 {
+		t.Errorf("expected the index not to be listed as a result, got %v", ids)
+	}
+}
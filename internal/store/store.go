@@ -11,6 +11,47 @@ type Verdict struct {
 	Reason           string                 `json:"reason"`
 	RelevantFindings []sarif.Result         `json:"relevant_findings,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	// Trace is the gate's decision path, populated only when decision
+	// tracing is enabled on the evaluator.
+	Trace *DecisionTrace `json:"trace,omitempty"`
+}
+
+// DecisionTrace is a machine-readable record of how the gate reached a
+// verdict: the rule that fired, the counts it evaluated, the thresholds it
+// compared them against, and the findings that triggered it.
+type DecisionTrace struct {
+	Decision string `json:"decision"`
+	// Rule names the gate rule that produced the decision, or "unknown"
+	// when the policy does not define a decision_trace.
+	Rule string `json:"rule"`
+	// Fired lists every gate rule whose conditions held, including Rule;
+	// a reject can have several causes.
+	Fired []string `json:"fired,omitempty"`
+	// Policies lists the Rego modules that were evaluated.
+	Policies                  []string         `json:"policies"`
+	Inputs                    map[string]int   `json:"inputs"`
+	RejectConfidenceThreshold float64          `json:"reject_confidence_threshold,omitempty"`
+	Thresholds                []TraceThreshold `json:"thresholds,omitempty"`
+	Triggers                  []TraceTrigger   `json:"triggers,omitempty"`
+}
+
+// TraceThreshold records a count the gate compared against a limit, such
+// as the findings counted by --max-findings or a category's max_errors.
+type TraceThreshold struct {
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+	Limit    int    `json:"limit"`
+	Exceeded bool   `json:"exceeded"`
+}
+
+// TraceTrigger identifies a finding that satisfied the firing gate rule.
+type TraceTrigger struct {
+	RuleID     string  `json:"rule_id"`
+	Level      string  `json:"level"`
+	Confidence float64 `json:"confidence"`
+	File       string  `json:"file,omitempty"`
+	Line       int     `json:"line,omitempty"`
+	Message    string  `json:"message,omitempty"`
 }
 
 type Store interface {
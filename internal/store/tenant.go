@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/tenant"
+)
+
+// TenantFileStore keeps each tenant's results in a FileStore of its own,
+// in a directory under dir named after the tenant carried by the request
+// context (see tenant.Dir), so one tenant cannot list or read another's
+// results. Requests without a tenant use dir itself. Result IDs naming
+// another directory, such as "../other/ID", are rejected.
+type TenantFileStore struct {
+	dir    string
+	mu     sync.Mutex
+	stores map[string]*FileStore
+}
+
+// NewTenantFileStore returns a TenantFileStore rooted at dir.
+func NewTenantFileStore(dir string) *TenantFileStore {
+	return &TenantFileStore{dir: dir, stores: make(map[string]*FileStore)}
+}
+
+func (s *TenantFileStore) store(ctx context.Context) *FileStore {
+	id := tenant.FromContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fs, ok := s.stores[id]
+	if !ok {
+		fs = NewFileStore(tenant.Dir(s.dir, id))
+		s.stores[id] = fs
+	}
+	return fs
+}
+
+func (s *TenantFileStore) WriteSARIF(ctx context.Context, doc *sarif.Log) (string, error) {
+	return s.store(ctx).WriteSARIF(ctx, doc)
+}
+
+// checkID rejects IDs that are not a single path element.
+func checkID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid result ID %q", id)
+	}
+	return nil
+}
+
+func (s *TenantFileStore) WriteVerdict(ctx context.Context, sarifID string, verdict *Verdict) error {
+	if err := checkID(sarifID); err != nil {
+		return err
+	}
+	return s.store(ctx).WriteVerdict(ctx, sarifID, verdict)
+}
+
+func (s *TenantFileStore) ReadSARIF(ctx context.Context, id string) (*sarif.Log, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+	return s.store(ctx).ReadSARIF(ctx, id)
+}
+
+func (s *TenantFileStore) ReadVerdict(ctx context.Context, sarifID string) (*Verdict, error) {
+	if err := checkID(sarifID); err != nil {
+		return nil, err
+	}
+	return s.store(ctx).ReadVerdict(ctx, sarifID)
+}
+
+func (s *TenantFileStore) List(ctx context.Context) ([]string, error) {
+	return s.store(ctx).List(ctx)
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chris-regnier/gavel/internal/sarif"
+	"github.com/chris-regnier/gavel/internal/tenant"
+)
+
+func TestTenantFileStore_IsolatesTenants(t *testing.T) {
+	dir := t.TempDir()
+	s := NewTenantFileStore(dir)
+	acme := tenant.NewContext(context.Background(), "acme")
+	other := tenant.NewContext(context.Background(), "other")
+
+	id, err := s.WriteSARIF(acme, sarif.NewLog("gavel", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(tenant.Dir(dir, "acme")).ReadSARIF(acme, id); err != nil {
+		t.Errorf("expected the result under the tenant's directory: %v", err)
+	}
+
+	if ids, _ := s.List(acme); len(ids) != 1 || ids[0] != id {
+		t.Errorf("expected acme to list %s, got %v", id, ids)
+	}
+	if ids, _ := s.List(other); len(ids) != 0 {
+		t.Errorf("expected other to list nothing, got %v", ids)
+	}
+	if _, err := s.ReadSARIF(other, id); err == nil {
+		t.Error("expected other to be unable to read acme's result")
+	}
+	if _, err := s.ReadSARIF(other, filepath.Join("..", filepath.Base(tenant.Dir(dir, "acme")), id)); err == nil {
+		t.Error("expected a path-like ID to be rejected")
+	}
+
+	// IDs that differ only in characters unsafe in paths stay apart
+	pipe := tenant.NewContext(context.Background(), "auth0|123")
+	under := tenant.NewContext(context.Background(), "auth0_123")
+	if _, err := s.WriteSARIF(pipe, sarif.NewLog("gavel", "test")); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := s.List(under); len(ids) != 0 {
+		t.Errorf("expected auth0_123 to list nothing, got %v", ids)
+	}
+}
@@ -0,0 +1,25 @@
+package store
+
+import (
+	"fmt"
+	"os"
+)
+
+// CheckWritable reports whether files can be created in dir, creating the
+// directory if needed. It writes and removes a probe file, which catches
+// read-only mounts that permission bits alone do not reveal.
+func CheckWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".gavel-write-probe-*")
+	if err != nil {
+		return fmt.Errorf("writing to %s: %w", dir, err)
+	}
+	name := f.Name()
+	f.Close()
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("removing probe file %s: %w", name, err)
+	}
+	return nil
+}
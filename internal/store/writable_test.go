@@ -0,0 +1,33 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "results")
+	if err := CheckWritable(dir); err != nil {
+		t.Fatalf("expected a creatable directory to be writable, got %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the probe file to be removed, found %v", entries)
+	}
+}
+
+func TestCheckWritable_Unwritable(t *testing.T) {
+	// A path beneath a regular file can never be created, even as root,
+	// which makes it a portable stand-in for a read-only mount.
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckWritable(filepath.Join(blocker, "results")); err == nil {
+		t.Error("expected an error for a directory that cannot be created")
+	}
+}
@@ -0,0 +1,43 @@
+// Package tenant carries the tenant a request is made for through a
+// context, so stores, metrics and cost tracking can attribute and isolate
+// work without depending on the HTTP layer that authenticated it.
+package tenant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+)
+
+// maxDirIDLen is the longest tenant ID whose hex encoding is used as a
+// directory name; longer IDs are hashed to stay within file name limits.
+const maxDirIDLen = 100
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the tenant ID id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ID carried by ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Dir returns the directory under base that holds id's data. The name is
+// the hex encoding of id, or "sha256-" and its hash for long IDs, so no two
+// tenant IDs share a directory and none can name a path outside base. An
+// empty id returns base.
+func Dir(base, id string) string {
+	if id == "" {
+		return base
+	}
+	if len(id) > maxDirIDLen {
+		sum := sha256.Sum256([]byte(id))
+		return filepath.Join(base, "sha256-"+hex.EncodeToString(sum[:]))
+	}
+	return filepath.Join(base, hex.EncodeToString([]byte(id)))
+}
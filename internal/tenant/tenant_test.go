@@ -0,0 +1,50 @@
+package tenant
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != "" {
+		t.Errorf("expected no tenant, got %q", got)
+	}
+	if got := FromContext(NewContext(context.Background(), "acme")); got != "acme" {
+		t.Errorf("expected acme, got %q", got)
+	}
+}
+
+func TestDir(t *testing.T) {
+	base := filepath.Join("var", "results")
+	for id, want := range map[string]string{
+		"":          base,
+		"acme":      filepath.Join(base, "61636d65"),
+		"../../etc": filepath.Join(base, "2e2e2f2e2e2f657463"),
+		"..":        filepath.Join(base, "2e2e"),
+	} {
+		if got := Dir(base, id); got != want {
+			t.Errorf("Dir(%q) = %q, want %q", id, got, want)
+		}
+	}
+
+	long := strings.Repeat("x", maxDirIDLen+1)
+	if got := filepath.Base(Dir(base, long)); !strings.HasPrefix(got, "sha256-") || len(got) != len("sha256-")+64 {
+		t.Errorf("expected a long ID to be hashed, got %q", got)
+	}
+}
+
+func TestDir_Distinct(t *testing.T) {
+	base := filepath.Join("var", "results")
+	for _, pair := range [][2]string{
+		{"auth0|123", "auth0_123"},
+		{"a/b", "a_b"},
+		{"user@example.com", "user_example.com"},
+		{"..", "__"},
+	} {
+		if a, b := Dir(base, pair[0]), Dir(base, pair[1]); a == b {
+			t.Errorf("Dir(%q) and Dir(%q) are both %q", pair[0], pair[1], a)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Package trends renders the run history kept by store.History: finding
+// counts over time, the findings introduced and fixed between two runs, and
+// per-rule trend tables, as terminal output or Markdown.
+package trends
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+// Output formats.
+const (
+	Pretty   = "pretty"
+	Markdown = "markdown"
+)
+
+// maxBar is the width of the largest bar in the pretty history chart.
+const maxBar = 30
+
+type styles struct {
+	header, dim, bar, added, removed lipgloss.Style
+}
+
+// newStyles returns the pretty styles, all plain when NO_COLOR is set.
+func newStyles() styles {
+	if os.Getenv("NO_COLOR") != "" {
+		plain := lipgloss.NewStyle()
+		return styles{plain, plain, plain, plain, plain}
+	}
+	return styles{
+		header:  lipgloss.NewStyle().Bold(true),
+		dim:     lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+		bar:     lipgloss.NewStyle().Foreground(lipgloss.Color("12")),
+		added:   lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+		removed: lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+	}
+}
+
+func checkFormat(format string) error {
+	if format != Pretty && format != Markdown {
+		return fmt.Errorf("unknown format %q: use pretty, markdown or json", format)
+	}
+	return nil
+}
+
+// shortCommit abbreviates a commit SHA, or returns "-" for runs without one.
+func shortCommit(sha string) string {
+	if sha == "" {
+		return "-"
+	}
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// RenderRuns lists runs, newest first as store.History.Runs returns them,
+// with their finding counts. The pretty format draws each run's count as a
+// bar so changes over time stand out.
+func RenderRuns(runs []store.RunRecord, format string) (string, error) {
+	if err := checkFormat(format); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if format == Markdown {
+		b.WriteString("## Gavel History\n\n")
+		if len(runs) == 0 {
+			b.WriteString("No runs recorded.\n")
+			return b.String(), nil
+		}
+		b.WriteString("| Time (UTC) | Commit | Branch | Decision | Findings | Errors | Warnings | Notes |\n")
+		b.WriteString("|---|---|---|---|---:|---:|---:|---:|\n")
+		for _, r := range runs {
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s | %d | %d | %d | %d |\n",
+				formatTime(r.Time), shortCommit(r.Commit), orDash(r.Branch), orDash(r.Decision), r.Findings, r.Errors, r.Warnings, r.Notes)
+		}
+		return b.String(), nil
+	}
+
+	s := newStyles()
+	b.WriteString("\n  " + s.header.Render("Gavel History") + "\n\n")
+	if len(runs) == 0 {
+		b.WriteString("  No runs recorded.\n\n")
+		return b.String(), nil
+	}
+	most := 0
+	for _, r := range runs {
+		most = max(most, r.Findings)
+	}
+	for _, r := range runs {
+		bar := ""
+		if most > 0 {
+			bar = strings.Repeat("█", (r.Findings*maxBar+most-1)/most)
+		}
+		fmt.Fprintf(&b, "  %s  %-7s  %-8s %4d  %s %s\n",
+			s.dim.Render(formatTime(r.Time)), shortCommit(r.Commit), orDash(r.Decision), r.Findings,
+			s.bar.Render(bar), s.dim.Render(fmt.Sprintf("%dE %dW %dN", r.Errors, r.Warnings, r.Notes)))
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// RenderDiff shows the findings introduced and fixed between two runs.
+func RenderDiff(d store.HistoryDiff, format string) (string, error) {
+	if err := checkFormat(format); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	summary := fmt.Sprintf("%d introduced, %d fixed, %d unchanged", len(d.Introduced), len(d.Fixed), d.Unchanged)
+	if format == Markdown {
+		fmt.Fprintf(&b, "## Findings from `%s` to `%s`\n\n", runLabel(d.From), runLabel(d.To))
+		b.WriteString(summary + "\n")
+		for _, section := range []struct {
+			title    string
+			findings []store.FindingRecord
+		}{{"Introduced", d.Introduced}, {"Fixed", d.Fixed}} {
+			if len(section.findings) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n| Rule | Level | Location | Message |\n|---|---|---|---|\n", section.title)
+			for _, f := range section.findings {
+				fmt.Fprintf(&b, "| `%s` | %s | `%s` | %s |\n", f.RuleID, f.Level, location(f), escapeCell(f.Message))
+			}
+		}
+		return b.String(), nil
+	}
+
+	s := newStyles()
+	fmt.Fprintf(&b, "\n  %s %s → %s\n", s.header.Render("Findings"), runLabel(d.From), runLabel(d.To))
+	b.WriteString("  " + s.dim.Render(summary) + "\n\n")
+	for _, f := range d.Introduced {
+		fmt.Fprintf(&b, "  %s %-8s %s  %s\n", s.added.Render("+"), f.Level, f.RuleID, s.dim.Render(location(f)))
+	}
+	for _, f := range d.Fixed {
+		fmt.Fprintf(&b, "  %s %-8s %s  %s\n", s.removed.Render("-"), f.Level, f.RuleID, s.dim.Render(location(f)))
+	}
+	if len(d.Introduced)+len(d.Fixed) > 0 {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// runLabel names a run by its short commit, or by its ID when it has none.
+func runLabel(r store.RunRecord) string {
+	if r.Commit != "" {
+		return shortCommit(r.Commit)
+	}
+	return r.ID
+}
+
+func location(f store.FindingRecord) string {
+	if f.File == "" {
+		return "-"
+	}
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+}
+
+// RenderRules shows each rule's finding count in runs, given oldest first,
+// with the change from the first run to the last.
+func RenderRules(trends []store.RuleTrend, runs []store.RunRecord, format string) (string, error) {
+	if err := checkFormat(format); err != nil {
+		return "", err
+	}
+	labels := make([]string, len(runs))
+	for i, r := range runs {
+		labels[i] = shortCommit(r.Commit)
+		if r.Commit == "" {
+			labels[i] = r.Time.UTC().Format("01-02 15:04")
+		}
+	}
+
+	var b strings.Builder
+	if format == Markdown {
+		b.WriteString("## Rule Trends\n\n")
+		if len(trends) == 0 {
+			b.WriteString("No findings recorded.\n")
+			return b.String(), nil
+		}
+		b.WriteString("| Rule | " + strings.Join(labels, " | ") + " | Change |\n")
+		b.WriteString("|---|" + strings.Repeat("---:|", len(runs)) + "---:|\n")
+		for _, t := range trends {
+			cells := make([]string, len(t.Counts))
+			for i, n := range t.Counts {
+				cells[i] = fmt.Sprint(n)
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", t.RuleID, strings.Join(cells, " | "), change(t))
+		}
+		return b.String(), nil
+	}
+
+	s := newStyles()
+	b.WriteString("\n  " + s.header.Render("Rule Trends") + "\n\n")
+	if len(trends) == 0 {
+		b.WriteString("  No findings recorded.\n\n")
+		return b.String(), nil
+	}
+	width := len("Rule")
+	for _, t := range trends {
+		width = max(width, len(t.RuleID))
+	}
+	col := 7
+	for _, l := range labels {
+		col = max(col, len(l))
+	}
+	header := fmt.Sprintf("  %-*s", width, "Rule")
+	for _, l := range labels {
+		header += fmt.Sprintf("  %*s", col, l)
+	}
+	b.WriteString(s.dim.Render(header+"  Change") + "\n")
+	for _, t := range trends {
+		fmt.Fprintf(&b, "  %-*s", width, t.RuleID)
+		for _, n := range t.Counts {
+			fmt.Fprintf(&b, "  %*d", col, n)
+		}
+		c := change(t)
+		switch {
+		case strings.HasPrefix(c, "+"):
+			c = s.added.Render(c)
+		case strings.HasPrefix(c, "-"):
+			c = s.removed.Render(c)
+		}
+		b.WriteString("  " + c + "\n")
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// change is a trend's last count minus its first, signed.
+func change(t store.RuleTrend) string {
+	if len(t.Counts) == 0 {
+		return "0"
+	}
+	delta := t.Counts[len(t.Counts)-1] - t.Counts[0]
+	if delta > 0 {
+		return fmt.Sprintf("+%d", delta)
+	}
+	return fmt.Sprint(delta)
+}
+
+// ParseSince reads a --since value relative to now: a date (2026-03-01), an
+// RFC 3339 time, a number of days (30d) or a Go duration (12h).
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		if _, err := fmt.Sscanf(days, "%d", &n); err == nil && n >= 0 && fmt.Sprint(n) == days {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a date (2026-03-01), days (30d) or a duration (12h)", s)
+}
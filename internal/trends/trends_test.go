@@ -0,0 +1,93 @@
+package trends
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chris-regnier/gavel/internal/store"
+)
+
+var testRuns = []store.RunRecord{
+	{ID: "2026-03-02T10-00-00Z-bbbbbb", Time: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Commit: "2222222bbbb", Branch: "main", Decision: "review", Findings: 2, Warnings: 2},
+	{ID: "2026-03-01T10-00-00Z-aaaaaa", Time: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Decision: "reject", Findings: 4, Errors: 1, Warnings: 3},
+}
+
+func assertContains(t *testing.T, out string, wants ...string) {
+	t.Helper()
+	for _, want := range wants {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderRuns(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	out, err := RenderRuns(testRuns, Pretty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, out,
+		"2026-03-02 10:00  2222222  review      2  "+strings.Repeat("█", 15)+" 0E 2W 0N",
+		"2026-03-01 10:00  -        reject      4  "+strings.Repeat("█", 30)+" 1E 3W 0N",
+	)
+
+	md, _ := RenderRuns(testRuns, Markdown)
+	assertContains(t, md, "| 2026-03-02 10:00 | `2222222` | main | review | 2 | 0 | 2 | 0 |", "| 2026-03-01 10:00 | `-` | - | reject | 4 | 1 | 3 | 0 |")
+
+	if _, err := RenderRuns(testRuns, "html"); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	d := store.HistoryDiff{
+		From:       testRuns[1],
+		To:         testRuns[0],
+		Introduced: []store.FindingRecord{{RuleID: "magic-number", Level: "note", File: "api.go", Line: 9, Message: "a | b"}},
+		Fixed:      []store.FindingRecord{{RuleID: "sql-injection", Level: "error", File: "db.go", Line: 10}},
+		Unchanged:  2,
+	}
+	out, _ := RenderDiff(d, Pretty)
+	assertContains(t, out, "Findings 2026-03-01T10-00-00Z-aaaaaa → 2222222", "1 introduced, 1 fixed, 2 unchanged", "+ note     magic-number  api.go:9", "- error    sql-injection  db.go:10")
+
+	md, _ := RenderDiff(d, Markdown)
+	assertContains(t, md, "### Introduced", "| `magic-number` | note | `api.go:9` | a \\| b |", "### Fixed")
+}
+
+func TestRenderRules(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	oldestFirst := []store.RunRecord{testRuns[1], testRuns[0]}
+	rules := []store.RuleTrend{{RuleID: "error-handling", Counts: []int{3, 2}}, {RuleID: "magic-number", Counts: []int{0, 1}}}
+
+	out, _ := RenderRules(rules, oldestFirst, Pretty)
+	assertContains(t, out, "Rule            03-01 10:00      2222222  Change", "error-handling            3            2  -1", "magic-number              0            1  +1")
+
+	md, _ := RenderRules(rules, oldestFirst, Markdown)
+	assertContains(t, md, "| Rule | 03-01 10:00 | 2222222 | Change |", "|---|---:|---:|---:|", "| `magic-number` | 0 | 1 | +1 |")
+
+	empty, _ := RenderRules(nil, oldestFirst, Markdown)
+	assertContains(t, empty, "No findings recorded.")
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"":                     {},
+		"2026-03-01":           time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2026-03-01T08:00:00Z": time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+		"7d":                   time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC),
+		"36h":                  time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+	} {
+		if got, err := ParseSince(in, now); err != nil || !got.Equal(want) {
+			t.Errorf("ParseSince(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"yesterday", "-3d", "3.5d"} {
+		if _, err := ParseSince(bad, now); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}